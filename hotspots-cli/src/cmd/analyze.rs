@@ -1,6 +1,11 @@
+use crate::cmd::{dead_code, god_functions, history, outliers, summary, watch};
+use crate::exit;
 use crate::output::{explain, policy};
-use crate::util::{find_repo_root, write_html_report};
-use crate::{OutputFormat, OutputLevel, OutputMode};
+use crate::util::{find_repo_root, write_html_report, TOOL_VERSION};
+use crate::{
+    AnonNaming, CcMode, ChurnMetric, GroupBy, JunitGranularity, OutputFormat, OutputLevel,
+    OutputMode, SeverityLevel, SortKey, SqlDialect, SummarySort,
+};
 use anyhow::Context;
 use hotspots_core::delta::Delta;
 use hotspots_core::gate::{check_gate, GateConfig, GateVerdict};
@@ -18,7 +23,18 @@ pub(crate) struct AnalyzeArgs {
     pub policy: bool,
     pub top: Option<usize>,
     pub min_lrs: Option<f64>,
-    pub config_path: Option<PathBuf>,
+    /// Show only functions at or above this severity.
+    pub min_severity: Option<SeverityLevel>,
+    /// Configuration loaded for `path` (see `load_config`)
+    pub config: hotspots_core::ResolvedConfig,
+    pub include: Vec<String>,
+    pub exclude: Vec<String>,
+    pub no_gitignore: bool,
+    /// File listing the paths to analyze instead of walking `path`; `-` = stdin.
+    pub files_from: Option<PathBuf>,
+    /// Analyze only files changed since this ref; `Some(None)` = the
+    /// merge-base with the default branch.
+    pub changed: Option<Option<String>>,
     pub output: Option<PathBuf>,
     pub explain: bool,
     pub force: bool,
@@ -45,6 +61,142 @@ pub(crate) struct AnalyzeArgs {
     /// Rank via Gini-gated cold-start routing (F62/F63) instead of a trained ranker.
     /// Explicit opt-in only; reads no fix-commit label data.
     pub cold_start: bool,
+    /// Previous results file; when set, emit a minimal JSON diff instead of the full report.
+    pub diff_against: Option<PathBuf>,
+    /// Cap on emitted function records; the output records truncation metadata.
+    pub max_results: Option<usize>,
+    /// Resolver file glob for `--mode resolvers`.
+    pub resolver_glob: Option<String>,
+    /// GraphQL schema file for `--mode resolvers`.
+    pub schema: Option<PathBuf>,
+    /// Treat a shallow git history as an error instead of a warning.
+    pub strict: bool,
+    /// Follow symlinks and count each canonical file once.
+    pub dedup_symlinks: bool,
+    /// Report only functions in each file's public API.
+    pub public_only: bool,
+    /// Also analyze test files and test functions.
+    pub include_tests: bool,
+    /// Compute Halstead metrics for each function.
+    pub halstead: bool,
+    /// Count source, comment, and blank lines of each function.
+    pub line_counts: bool,
+    /// Count each function's callers into `metrics.fi`.
+    pub fan_in: bool,
+    /// Order reports by this key instead of LRS.
+    pub sort: Option<SortKey>,
+    /// Functions to skip before `--top`.
+    pub offset: Option<usize>,
+    /// Order the --sort key ascending.
+    pub asc: bool,
+    /// Order the --sort key descending.
+    pub desc: bool,
+    /// Exit 1 if any function declares more parameters than this.
+    pub max_params: Option<u32>,
+    /// Report violations but exit 0.
+    pub exit_zero: bool,
+    /// Testcase granularity for `--format junit`; None = one testcase per function.
+    pub junit_granularity: Option<JunitGranularity>,
+    /// Roll reports up into components instead of listing functions.
+    pub group_by: Option<GroupBy>,
+    /// Socket of a running `hotspots daemon` to analyze through instead of in-process.
+    pub daemon_socket: Option<PathBuf>,
+    /// Delta mode: print only regressed functions and exit 1 if there are any.
+    pub regressions_only: bool,
+    /// Delta mode: explain regressions by the constructs that changed their CC.
+    pub explain_diff: bool,
+    /// Dialect for `.sql` files; None = the config's, else detected per file.
+    pub sql_dialect: Option<SqlDialect>,
+    /// How switch statements count toward CC; None = the config's.
+    pub cc_mode: Option<CcMode>,
+    /// Leave Go method calls out of FO, overriding config `fo_methods`.
+    pub no_fo_methods: bool,
+    /// Churn window for `--mode churn`, e.g. `90d`; None = 90 days.
+    pub since: Option<String>,
+    /// Complexity that `--mode churn` multiplies churn by; None = CC.
+    pub churn_metric: Option<ChurnMetric>,
+    /// Write all function metrics here as a baseline instead of reporting.
+    pub save_baseline: Option<PathBuf>,
+    /// Baseline file; when set, report only regressions against it.
+    pub baseline: Option<PathBuf>,
+    /// Previous results to print every function's metric changes against.
+    pub compare: Option<PathBuf>,
+    /// With `compare`, hide unchanged functions.
+    pub changed_only: bool,
+    /// Re-analyze on file changes until interrupted.
+    pub watch: bool,
+    /// Bypass the on-disk analysis cache.
+    pub no_cache: bool,
+    /// Delete the analysis cache before analyzing.
+    pub clear_cache: bool,
+    /// Append aggregate metrics for HEAD to the history file.
+    pub record: bool,
+    /// Print the trend over the last N recordings.
+    pub trend: Option<usize>,
+    /// List dead-code candidates instead of a report.
+    pub dead_code: bool,
+    /// List statistical outliers instead of a report.
+    pub outliers: bool,
+    /// List god functions instead of a report.
+    pub god_functions: bool,
+    /// Print one line per file instead of a report.
+    pub summary: bool,
+    /// What the per-file summary ranks files by.
+    pub summary_sort: Option<SummarySort>,
+    /// Report Go closures separately and leave nested function bodies out of
+    /// their parent's metrics.
+    pub separate_closures: bool,
+    /// How separately reported Go closures are named; None = config / index.
+    pub anon_naming: Option<AnonNaming>,
+    /// Break NS down by kind of exit.
+    pub ns_breakdown: bool,
+    /// Print only offending functions, one per line, and nothing when clean.
+    pub quiet: bool,
+    /// Hide analysis and touch-cache progress on stderr.
+    pub no_progress: bool,
+}
+
+/// `path` made absolute. Collecting components drops `.` segments
+/// (`hotspots analyze .`), so function paths match those of snapshots loaded
+/// from disk.
+fn absolute_path(path: &Path) -> anyhow::Result<PathBuf> {
+    let path = if path.is_relative() {
+        std::env::current_dir()?.join(path)
+    } else {
+        path.to_path_buf()
+    };
+    Ok(path.components().collect())
+}
+
+/// Load the configuration `analyze` uses for `path`: `config_path` if given,
+/// else the nearest `.hotspots.toml` from `path` up to its repository root,
+/// else JSON config in that root. Loaded once, before flag validation, which
+/// depends on the config's `format`.
+pub(crate) fn load_config(
+    path: &Path,
+    config_path: Option<&Path>,
+) -> anyhow::Result<hotspots_core::ResolvedConfig> {
+    let path = absolute_path(path)?;
+    let project_root = find_repo_root(&path).unwrap_or_else(|_| path.clone());
+    hotspots_core::config::load_and_resolve_from(&project_root, config_path, &path)
+        .context("failed to load configuration")
+        .map_err(exit::usage)
+}
+
+/// `--format` if given, else the `format` key of `config`, else text
+pub(crate) fn resolve_format(
+    format: Option<OutputFormat>,
+    config: &hotspots_core::ResolvedConfig,
+) -> anyhow::Result<OutputFormat> {
+    if let Some(format) = format {
+        return Ok(format);
+    }
+    match &config.format {
+        // Names are validated against the same list when the config loads
+        Some(name) => <OutputFormat as clap::ValueEnum>::from_str(name, true)
+            .map_err(|e| exit::usage(anyhow::anyhow!("invalid format in config: {}", e))),
+        None => Ok(OutputFormat::Text),
+    }
 }
 
 /// Validate flag combinations that are mode/format-specific.
@@ -53,6 +205,7 @@ pub(crate) fn validate_analyze_flags(args: &AnalyzeArgs) -> anyhow::Result<()> {
         mode,
         format,
         policy,
+        top,
         explain,
         per_function_touches,
         no_persist,
@@ -62,6 +215,43 @@ pub(crate) fn validate_analyze_flags(args: &AnalyzeArgs) -> anyhow::Result<()> {
         include_models,
         explain_patterns,
         cold_start,
+        diff_against,
+        max_results,
+        resolver_glob,
+        schema,
+        junit_granularity,
+        daemon_socket,
+        regressions_only,
+        explain_diff,
+        public_only,
+        group_by,
+        since,
+        churn_metric,
+        save_baseline,
+        baseline,
+        compare,
+        changed_only,
+        sort,
+        offset,
+        asc,
+        desc,
+        max_params,
+        fan_in,
+        watch,
+        record,
+        trend,
+        dead_code,
+        outliers,
+        god_functions,
+        summary,
+        summary_sort,
+        files_from,
+        changed,
+        dedup_symlinks,
+        quiet,
+        min_severity,
+        separate_closures,
+        anon_naming,
         ..
     } = args;
     if *cold_start && mode.is_some() {
@@ -73,7 +263,9 @@ pub(crate) fn validate_analyze_flags(args: &AnalyzeArgs) -> anyhow::Result<()> {
     if *explain && mode.is_some() && *mode != Some(OutputMode::Snapshot) {
         anyhow::bail!("--explain is not compatible with --mode delta or --mode models");
     }
-    if *per_function_touches && mode.is_none() {
+    if *per_function_touches
+        && matches!(mode, None | Some(OutputMode::Resolvers | OutputMode::Churn))
+    {
         anyhow::bail!(
             "--per-function-touches is only valid with --mode snapshot, --mode delta, or --mode models"
         );
@@ -82,7 +274,10 @@ pub(crate) fn validate_analyze_flags(args: &AnalyzeArgs) -> anyhow::Result<()> {
         if mode.is_none() {
             anyhow::bail!("--no-persist is only valid with --mode snapshot or --mode delta");
         }
-        if *mode == Some(OutputMode::Models) {
+        if matches!(
+            mode,
+            Some(OutputMode::Models | OutputMode::Resolvers | OutputMode::Churn)
+        ) {
             anyhow::bail!("--no-persist is only valid with --mode snapshot or --mode delta");
         }
         if *force {
@@ -110,6 +305,25 @@ pub(crate) fn validate_analyze_flags(args: &AnalyzeArgs) -> anyhow::Result<()> {
     {
         anyhow::bail!("--mode models supports --format text or --format json");
     }
+    if *mode == Some(OutputMode::Resolvers)
+        && !matches!(format, OutputFormat::Text | OutputFormat::Json)
+    {
+        anyhow::bail!("--mode resolvers supports --format text or --format json");
+    }
+    if (resolver_glob.is_some() || schema.is_some()) && *mode != Some(OutputMode::Resolvers) {
+        anyhow::bail!("--resolver-glob and --schema are only valid with --mode resolvers");
+    }
+    if *mode == Some(OutputMode::Churn)
+        && !matches!(format, OutputFormat::Text | OutputFormat::Json)
+    {
+        anyhow::bail!("--mode churn supports --format text or --format json");
+    }
+    if (since.is_some() || churn_metric.is_some()) && *mode != Some(OutputMode::Churn) {
+        anyhow::bail!("--since and --churn-metric are only valid with --mode churn");
+    }
+    if let Some(window) = since {
+        hotspots_core::churn::parse_window(window)?;
+    }
     if *include_models
         && (*mode != Some(OutputMode::Snapshot)
             || !matches!(format, OutputFormat::Json | OutputFormat::Html))
@@ -122,11 +336,396 @@ pub(crate) fn validate_analyze_flags(args: &AnalyzeArgs) -> anyhow::Result<()> {
     if matches!(format, OutputFormat::Sarif) && *mode != Some(OutputMode::Snapshot) {
         anyhow::bail!("--format sarif requires --mode snapshot");
     }
+    if matches!(format, OutputFormat::Junit) && (mode.is_some() || *cold_start) {
+        anyhow::bail!("--format junit is not compatible with --mode or --cold-start");
+    }
+    if group_by.is_some() {
+        if mode.is_some() || *cold_start {
+            anyhow::bail!("--group-by is not compatible with --mode or --cold-start");
+        }
+        if !matches!(format, OutputFormat::Text | OutputFormat::Json) {
+            anyhow::bail!("--group-by requires --format text or json");
+        }
+        if diff_against.is_some() || max_results.is_some() || *explain_patterns {
+            anyhow::bail!(
+                "--group-by is not compatible with --diff-against, --max-results, or --explain-patterns"
+            );
+        }
+    }
+    if *public_only && mode.is_some() {
+        anyhow::bail!(
+            "--public-only is not compatible with --mode (snapshots must cover every function)"
+        );
+    }
+    if matches!(format, OutputFormat::Treemap) && (mode.is_some() || *cold_start) {
+        anyhow::bail!("--format treemap is not compatible with --mode or --cold-start");
+    }
+    if matches!(format, OutputFormat::Markdown) && (mode.is_some() || *cold_start) {
+        anyhow::bail!("--format markdown is not compatible with --mode or --cold-start");
+    }
+    if matches!(format, OutputFormat::Csv) && (mode.is_some() || *cold_start) {
+        anyhow::bail!("--format csv is not compatible with --mode or --cold-start");
+    }
+    if matches!(format, OutputFormat::Tree) && (mode.is_some() || *cold_start) {
+        anyhow::bail!("--format tree is not compatible with --mode or --cold-start");
+    }
+    if matches!(format, OutputFormat::Gitlab) && (mode.is_some() || *cold_start) {
+        anyhow::bail!("--format gitlab is not compatible with --mode or --cold-start");
+    }
+    if junit_granularity.is_some() && !matches!(format, OutputFormat::Junit) {
+        anyhow::bail!("--junit-granularity requires --format junit");
+    }
+    if anon_naming.is_some() && !*separate_closures {
+        anyhow::bail!("--anon-naming requires --separate-closures");
+    }
+    if *regressions_only {
+        if *mode != Some(OutputMode::Delta) {
+            anyhow::bail!("--regressions-only is only valid with --mode delta");
+        }
+        if !matches!(format, OutputFormat::Text) {
+            anyhow::bail!("--regressions-only is only valid with --format text");
+        }
+        if *policy {
+            anyhow::bail!("--regressions-only and --policy are mutually exclusive");
+        }
+    }
+    if *explain_diff && *mode != Some(OutputMode::Delta) {
+        anyhow::bail!("--explain-diff is only valid with --mode delta");
+    }
+    if daemon_socket.is_some() {
+        if mode.is_some() || *cold_start {
+            anyhow::bail!("--daemon-socket is not compatible with --mode or --cold-start");
+        }
+        if *explain_patterns {
+            anyhow::bail!("--daemon-socket is not compatible with --explain-patterns");
+        }
+    }
+    if diff_against.is_some() {
+        if mode.is_some() || *cold_start {
+            anyhow::bail!("--diff-against is not compatible with --mode or --cold-start");
+        }
+        if !matches!(format, OutputFormat::Json) {
+            anyhow::bail!("--diff-against requires --format json");
+        }
+    }
+    if save_baseline.is_some() || baseline.is_some() {
+        if mode.is_some() || *cold_start {
+            anyhow::bail!(
+                "--save-baseline and --baseline are not compatible with --mode or --cold-start"
+            );
+        }
+        if diff_against.is_some() || max_results.is_some() || group_by.is_some() {
+            anyhow::bail!(
+                "--save-baseline and --baseline are not compatible with --diff-against, --max-results, or --group-by"
+            );
+        }
+    }
+    if *changed_only && compare.is_none() {
+        anyhow::bail!("--changed-only requires --compare");
+    }
+    if compare.is_some() {
+        // Every function is listed, so nothing may narrow or replace the list,
+        // and a partial file list would show the rest as removed
+        if mode.is_some() || *cold_start {
+            anyhow::bail!("--compare is not compatible with --mode or --cold-start");
+        }
+        if !matches!(format, OutputFormat::Text | OutputFormat::Json) {
+            anyhow::bail!("--compare requires --format text or json");
+        }
+        if diff_against.is_some()
+            || save_baseline.is_some()
+            || baseline.is_some()
+            || max_results.is_some()
+            || group_by.is_some()
+            || max_params.is_some()
+            || min_severity.is_some()
+            || sort.is_some()
+            || offset.is_some()
+        {
+            anyhow::bail!(
+                "--compare is not compatible with --diff-against, --save-baseline, --baseline, --max-results, --group-by, --max-params, --min-severity, --sort, or --offset"
+            );
+        }
+        if files_from.is_some()
+            || changed.is_some()
+            || *watch
+            || *record
+            || trend.is_some()
+            || *dead_code
+            || *outliers
+            || *god_functions
+            || *summary
+            || *quiet
+        {
+            anyhow::bail!(
+                "--compare is not compatible with --files-from, --changed, --watch, --record, --trend, --dead-code, --outliers, --god-functions, --summary, or --quiet"
+            );
+        }
+    }
+    // --sort by a report metric, --asc / --desc, and --offset page through
+    // the default report in text or JSON
+    let ranked =
+        offset.is_some() || *asc || *desc || sort.is_some_and(|key| key.metric().is_some());
+    if sort.is_some() || ranked {
+        if mode.is_some() || *cold_start {
+            anyhow::bail!(
+                "--sort, --offset, --asc, and --desc are not compatible with --mode or --cold-start"
+            );
+        }
+        if sort.is_some_and(|key| key.metric().is_none()) {
+            if !matches!(format, OutputFormat::Json) {
+                anyhow::bail!("--sort maintainability, fi, and risk-score require --format json");
+            }
+            if *asc || *desc {
+                anyhow::bail!(
+                    "--asc and --desc only apply to --sort cc, nd, fo, ns, cognitive, and risk"
+                );
+            }
+        }
+        if !matches!(format, OutputFormat::Text | OutputFormat::Json) {
+            anyhow::bail!("--sort, --offset, --asc, and --desc require --format text or json");
+        }
+        if diff_against.is_some()
+            || group_by.is_some()
+            || save_baseline.is_some()
+            || baseline.is_some()
+            || *watch
+            || *record
+            || trend.is_some()
+            || *dead_code
+            || *outliers
+            || *god_functions
+            || *summary
+        {
+            anyhow::bail!(
+                "--sort, --offset, --asc, and --desc are not compatible with --diff-against, --group-by, --save-baseline, --baseline, --watch, --record, --trend, --dead-code, --outliers, --god-functions, or --summary"
+            );
+        }
+    }
+    if max_params.is_some() {
+        if mode.is_some() || *cold_start {
+            anyhow::bail!("--max-params is not compatible with --mode or --cold-start");
+        }
+        if diff_against.is_some()
+            || group_by.is_some()
+            || save_baseline.is_some()
+            || baseline.is_some()
+        {
+            anyhow::bail!(
+                "--max-params is not compatible with --diff-against, --group-by, --save-baseline, or --baseline"
+            );
+        }
+    }
+    if *watch {
+        if mode.is_some() || *cold_start {
+            anyhow::bail!("--watch is not compatible with --mode or --cold-start");
+        }
+        if !matches!(format, OutputFormat::Text) {
+            anyhow::bail!("--watch requires --format text");
+        }
+        if daemon_socket.is_some()
+            || group_by.is_some()
+            || save_baseline.is_some()
+            || baseline.is_some()
+            || max_params.is_some()
+        {
+            anyhow::bail!(
+                "--watch is not compatible with --daemon-socket, --group-by, --save-baseline, --baseline, or --max-params"
+            );
+        }
+    }
+    if *record || trend.is_some() {
+        if mode.is_some() || *cold_start || *watch {
+            anyhow::bail!(
+                "--record and --trend are not compatible with --mode, --cold-start, or --watch"
+            );
+        }
+        if !matches!(format, OutputFormat::Text | OutputFormat::Json) {
+            anyhow::bail!("--record and --trend require --format text or json");
+        }
+        if *trend == Some(0) {
+            anyhow::bail!("--trend must be at least 1");
+        }
+    }
+    if *dead_code {
+        if mode.is_some() || *cold_start || *watch || *record || trend.is_some() || *public_only {
+            anyhow::bail!(
+                "--dead-code is not compatible with --mode, --cold-start, --watch, --record, --trend, or --public-only"
+            );
+        }
+        if !matches!(format, OutputFormat::Text | OutputFormat::Json) {
+            anyhow::bail!("--dead-code requires --format text or json");
+        }
+    }
+    if *outliers {
+        if mode.is_some() || *cold_start || *watch || *record || trend.is_some() || *dead_code {
+            anyhow::bail!(
+                "--outliers is not compatible with --mode, --cold-start, --watch, --record, --trend, or --dead-code"
+            );
+        }
+        if !matches!(format, OutputFormat::Text | OutputFormat::Json) {
+            anyhow::bail!("--outliers requires --format text or json");
+        }
+    }
+    if *summary {
+        if mode.is_some()
+            || *cold_start
+            || *watch
+            || *record
+            || trend.is_some()
+            || *dead_code
+            || *outliers
+            || group_by.is_some()
+        {
+            anyhow::bail!(
+                "--summary is not compatible with --mode, --cold-start, --watch, --record, --trend, --dead-code, --outliers, or --group-by"
+            );
+        }
+        if !matches!(format, OutputFormat::Text | OutputFormat::Json) {
+            anyhow::bail!("--summary requires --format text or json");
+        }
+    }
+    if *god_functions {
+        if mode.is_some()
+            || *cold_start
+            || *watch
+            || *record
+            || trend.is_some()
+            || *dead_code
+            || *outliers
+            || *summary
+            || group_by.is_some()
+        {
+            anyhow::bail!(
+                "--god-functions is not compatible with --mode, --cold-start, --watch, --record, --trend, --dead-code, --outliers, --summary, or --group-by"
+            );
+        }
+        if !matches!(format, OutputFormat::Text | OutputFormat::Json) {
+            anyhow::bail!("--god-functions requires --format text or json");
+        }
+    }
+    if summary_sort.is_some() && !*summary {
+        anyhow::bail!("--summary-sort requires --summary");
+    }
+    if *quiet {
+        if mode.is_some() || *cold_start || *watch || *record || trend.is_some() {
+            anyhow::bail!(
+                "--quiet is not compatible with --mode, --cold-start, --watch, --record, or --trend"
+            );
+        }
+        if !matches!(format, OutputFormat::Text) {
+            anyhow::bail!("--quiet requires --format text");
+        }
+        // Every offending function is listed, in file order
+        if top.is_some() || sort.is_some() || offset.is_some() || *asc || *desc {
+            anyhow::bail!(
+                "--quiet is not compatible with --top, --sort, --offset, --asc, or --desc"
+            );
+        }
+        if daemon_socket.is_some()
+            || diff_against.is_some()
+            || group_by.is_some()
+            || save_baseline.is_some()
+            || baseline.is_some()
+            || *dead_code
+            || *outliers
+            || *god_functions
+            || *summary
+        {
+            anyhow::bail!(
+                "--quiet is not compatible with --daemon-socket, --diff-against, --group-by, --save-baseline, --baseline, --dead-code, --outliers, --god-functions, or --summary"
+            );
+        }
+    }
+    if min_severity.is_some() {
+        // Severity narrows a function list; rollups and baselines need every
+        // function, and the other views list something else
+        if mode.is_some() || *cold_start {
+            anyhow::bail!("--min-severity is not compatible with --mode or --cold-start");
+        }
+        if group_by.is_some()
+            || save_baseline.is_some()
+            || baseline.is_some()
+            || *watch
+            || *record
+            || trend.is_some()
+            || *dead_code
+            || *outliers
+            || *god_functions
+            || *summary
+        {
+            anyhow::bail!(
+                "--min-severity is not compatible with --group-by, --save-baseline, --baseline, --watch, --record, --trend, --dead-code, --outliers, --god-functions, or --summary"
+            );
+        }
+    }
+    if files_from.is_some() {
+        // Results for part of the repo would mislead anything that persists
+        // or compares whole-repo results, or that needs every caller
+        if mode.is_some() || *cold_start || *watch || *record || trend.is_some() {
+            anyhow::bail!(
+                "--files-from is not compatible with --mode, --cold-start, --watch, --record, or --trend"
+            );
+        }
+        if daemon_socket.is_some() || save_baseline.is_some() || *dead_code || *dedup_symlinks {
+            anyhow::bail!(
+                "--files-from is not compatible with --daemon-socket, --save-baseline, --dead-code, or --dedup-symlinks"
+            );
+        }
+    }
+    if changed.is_some() {
+        // Same partial-repo limits as --files-from
+        if files_from.is_some() {
+            anyhow::bail!("--changed is not compatible with --files-from");
+        }
+        if mode.is_some() || *cold_start || *watch || *record || trend.is_some() {
+            anyhow::bail!(
+                "--changed is not compatible with --mode, --cold-start, --watch, --record, or --trend"
+            );
+        }
+        if daemon_socket.is_some() || save_baseline.is_some() || *dead_code || *dedup_symlinks {
+            anyhow::bail!(
+                "--changed is not compatible with --daemon-socket, --save-baseline, --dead-code, or --dedup-symlinks"
+            );
+        }
+    }
+    if matches!(format, OutputFormat::Jsonl) && mode.is_none() && !*cold_start {
+        // Streamed file by file, so nothing can be ranked or collected first
+        if top.is_some() || daemon_socket.is_some() || save_baseline.is_some() || *fan_in {
+            anyhow::bail!(
+                "--format jsonl without --mode is not compatible with --top, --daemon-socket, --save-baseline, or --fan-in"
+            );
+        }
+    }
+    if baseline.is_some() && !matches!(format, OutputFormat::Text | OutputFormat::Json) {
+        anyhow::bail!("--baseline requires --format text or json");
+    }
+    if let Some(n) = max_results {
+        if *n == 0 {
+            anyhow::bail!("--max-results must be at least 1");
+        }
+        if !matches!(format, OutputFormat::Json) {
+            anyhow::bail!("--max-results requires --format json");
+        }
+        if *cold_start || diff_against.is_some() {
+            anyhow::bail!("--max-results is not compatible with --cold-start or --diff-against");
+        }
+        match mode {
+            None => {}
+            Some(OutputMode::Snapshot) if *all_functions => {}
+            Some(OutputMode::Snapshot) => {
+                anyhow::bail!("--max-results with --mode snapshot requires --all-functions")
+            }
+            Some(_) => {
+                anyhow::bail!("--max-results is only valid with --mode snapshot or without --mode")
+            }
+        }
+    }
     Ok(())
 }
 
 pub(crate) fn handle_analyze(args: AnalyzeArgs) -> anyhow::Result<()> {
-    validate_analyze_flags(&args)?;
+    validate_analyze_flags(&args).map_err(exit::usage)?;
 
     let AnalyzeArgs {
         path,
@@ -135,7 +734,13 @@ pub(crate) fn handle_analyze(args: AnalyzeArgs) -> anyhow::Result<()> {
         policy,
         top,
         min_lrs,
-        config_path,
+        min_severity,
+        config: mut resolved_config,
+        include,
+        exclude,
+        no_gitignore,
+        files_from,
+        changed,
         output,
         explain,
         force,
@@ -153,6 +758,52 @@ pub(crate) fn handle_analyze(args: AnalyzeArgs) -> anyhow::Result<()> {
         callgraph_skip_above,
         skip_gate,
         cold_start,
+        diff_against,
+        max_results,
+        resolver_glob,
+        schema,
+        strict,
+        dedup_symlinks,
+        public_only,
+        include_tests,
+        halstead,
+        line_counts,
+        fan_in,
+        sort,
+        max_params,
+        exit_zero,
+        junit_granularity,
+        group_by,
+        daemon_socket,
+        regressions_only,
+        explain_diff,
+        sql_dialect,
+        cc_mode,
+        no_fo_methods,
+        since,
+        churn_metric,
+        save_baseline,
+        baseline,
+        compare,
+        changed_only,
+        watch,
+        no_cache,
+        clear_cache,
+        record,
+        trend,
+        dead_code,
+        outliers,
+        god_functions,
+        summary,
+        summary_sort,
+        separate_closures,
+        anon_naming,
+        ns_breakdown,
+        quiet,
+        no_progress,
+        offset,
+        asc,
+        desc,
     } = args;
 
     // Configure the global rayon thread pool before any parallel work begins.
@@ -164,26 +815,93 @@ pub(crate) fn handle_analyze(args: AnalyzeArgs) -> anyhow::Result<()> {
             .build_global();
     }
 
-    let normalized_path = if path.is_relative() {
-        std::env::current_dir()?.join(&path)
-    } else {
-        path
-    };
+    let normalized_path = absolute_path(&path)?;
 
     if !normalized_path.exists() {
-        anyhow::bail!("Path does not exist: {}", normalized_path.display());
+        bail_usage!("Path does not exist: {}", normalized_path.display());
     }
 
     let project_root = find_repo_root(&normalized_path).unwrap_or_else(|_| normalized_path.clone());
-    let resolved_config =
-        hotspots_core::config::load_and_resolve(&project_root, config_path.as_deref())
-            .context("failed to load configuration")?;
+    if dedup_symlinks {
+        resolved_config.dedup_symlinks = true;
+    }
+    if public_only {
+        resolved_config.public_only = true;
+    }
+    if include_tests {
+        resolved_config.include_tests = true;
+    }
+    if no_gitignore {
+        resolved_config.gitignore = false;
+    }
+    if let Some(list) = files_from {
+        resolved_config.files = Some(read_file_list(&list)?);
+    }
+    if let Some(base) = changed {
+        resolved_config.files = Some(changed_files(&normalized_path, base.as_deref())?);
+    }
+    // The maintainability index is derived from Halstead volume
+    if halstead || sort == Some(SortKey::Maintainability) {
+        resolved_config.halstead = true;
+    }
+    if line_counts {
+        resolved_config.line_counts = true;
+    }
+    if explain {
+        resolved_config.nd_lines = true;
+        resolved_config.cc_lines = true;
+    }
+    if separate_closures {
+        resolved_config.separate_closures = true;
+    }
+    if ns_breakdown {
+        resolved_config.ns_breakdown = true;
+    }
+    if let Some(root) = resolved_config.root.as_deref().filter(|_| clear_cache) {
+        hotspots_core::analysis_cache::clear_analysis_cache(root)?;
+    }
+    // Watch mode keeps its own in-memory cache
+    resolved_config.analysis_cache = !no_cache && !watch;
+    if fan_in || sort == Some(SortKey::Fi) {
+        resolved_config.fan_in = true;
+    }
+    resolved_config
+        .apply_pattern_flags(&include, &exclude)
+        .context("invalid --include/--exclude pattern")
+        .map_err(exit::usage)?;
+    if let Some(dialect) = sql_dialect {
+        resolved_config.sql_dialect = Some(match dialect {
+            SqlDialect::Postgres => hotspots_core::language::SqlDialect::Postgres,
+            SqlDialect::Tsql => hotspots_core::language::SqlDialect::Tsql,
+        });
+    }
+    if let Some(mode) = cc_mode {
+        resolved_config.cc_mode = match mode {
+            CcMode::Cases => hotspots_core::metrics::CcMode::Cases,
+            CcMode::Statement => hotspots_core::metrics::CcMode::Statement,
+            CcMode::Mccabe => hotspots_core::metrics::CcMode::Mccabe,
+        };
+    }
+    if no_fo_methods {
+        resolved_config.fo_methods = false;
+    }
+    if let Some(naming) = anon_naming {
+        resolved_config.anon_naming = match naming {
+            AnonNaming::Index => hotspots_core::language::AnonNaming::Index,
+            AnonNaming::Line => hotspots_core::language::AnonNaming::Line,
+            AnonNaming::Context => hotspots_core::language::AnonNaming::Context,
+        };
+    }
 
-    if let Some(ref p) = resolved_config.config_path {
+    if let Some(p) = resolved_config.config_path.as_ref().filter(|_| !quiet) {
         eprintln!("Using config: {}", p.display());
     }
 
     let effective_min_lrs = min_lrs.or(resolved_config.min_lrs);
+    let severity_filter = min_severity.map(|level| hotspots_core::severity::SeverityFilter {
+        min: level.severity(),
+        scale: resolved_config.severity,
+    });
     let effective_top = top.or(resolved_config.top_n);
     let touch_args = TouchArgs {
         no_per_function: no_per_function_touches,
@@ -204,6 +922,39 @@ pub(crate) fn handle_analyze(args: AnalyzeArgs) -> anyhow::Result<()> {
             &resolved_config,
             effective_touch_mode,
             effective_top,
+            strict,
+            !no_progress,
+        );
+    }
+
+    if mode == Some(OutputMode::Resolvers) {
+        return handle_resolvers_mode(
+            &normalized_path,
+            &resolved_config,
+            ResolverOptions {
+                format,
+                min_lrs: effective_min_lrs,
+                top: effective_top,
+                resolver_glob,
+                schema,
+                show_progress: !no_progress,
+            },
+        );
+    }
+
+    if mode == Some(OutputMode::Churn) {
+        return handle_churn_mode(
+            &normalized_path,
+            &resolved_config,
+            ChurnOptions {
+                format,
+                min_lrs: effective_min_lrs,
+                top: effective_top,
+                since,
+                metric: churn_metric,
+                strict,
+                show_progress: !no_progress,
+            },
         );
     }
 
@@ -230,18 +981,108 @@ pub(crate) fn handle_analyze(args: AnalyzeArgs) -> anyhow::Result<()> {
                 callgraph_skip_above,
                 skip_touch_metrics: touch_args.skip,
                 skip_gate,
+                max_results,
+                strict,
+                regressions_only,
+                explain_diff,
+                check_thresholds: false,
+                exit_zero,
+                show_progress: !no_progress,
             },
         );
         return result;
     }
 
+    if record || trend.is_some() {
+        return history::run(
+            &normalized_path,
+            &project_root,
+            &resolved_config,
+            history::HistoryOptions {
+                record,
+                trend,
+                format,
+            },
+        );
+    }
+
+    if dead_code {
+        return dead_code::run(&normalized_path, &resolved_config, format);
+    }
+
+    if outliers {
+        return outliers::run(&normalized_path, &resolved_config, format);
+    }
+
+    if god_functions {
+        return god_functions::run(&normalized_path, &resolved_config, format);
+    }
+
+    if summary {
+        let sort = match summary_sort {
+            Some(SummarySort::Max) => hotspots_core::summary::SummarySort::Max,
+            Some(SummarySort::Total) | None => hotspots_core::summary::SummarySort::Total,
+        };
+        return summary::run(&normalized_path, &resolved_config, format, sort);
+    }
+
+    if quiet {
+        return handle_quiet(
+            &normalized_path,
+            &resolved_config,
+            effective_min_lrs,
+            severity_filter,
+            max_params,
+            exit_zero,
+        );
+    }
+
+    if watch {
+        return watch::run(
+            &normalized_path,
+            &resolved_config,
+            watch::WatchOptions {
+                min_lrs: effective_min_lrs,
+                top: effective_top,
+            },
+        );
+    }
+
     // If a trained ranker exists, promote to snapshot mode so activity_risk
     // fields are populated and the ranker can be applied. The ranker has no
-    // effect in the default LRS-only path.
+    // effect in the default LRS-only path. --files-from and --changed analyze
+    // part of the repo, which a ranked snapshot would not describe.
+    // --diff-against, --baseline, and --compare compare plain reports,
+    // --max-results caps the plain report, JUnit, GitLab,
+    // treemap, Markdown, --group-by, and --save-baseline output are built from
+    // plain reports, JSONL streams them, and --min-severity filters them, so
+    // all of them stay on the default path.
     let repo_root_for_ranker =
         find_repo_root(&normalized_path).unwrap_or_else(|_| normalized_path.clone());
     let ranker_path = snapshot::hotspots_dir(&repo_root_for_ranker).join("ranker.json");
-    if ranker_path.exists() {
+    if ranker_path.exists()
+        && diff_against.is_none()
+        && max_results.is_none()
+        && !matches!(
+            format,
+            OutputFormat::Junit
+                | OutputFormat::Treemap
+                | OutputFormat::Jsonl
+                | OutputFormat::Markdown
+                | OutputFormat::Csv
+                | OutputFormat::Tree
+                | OutputFormat::Gitlab
+        )
+        && daemon_socket.is_none()
+        && resolved_config.files.is_none()
+        && group_by.is_none()
+        && save_baseline.is_none()
+        && baseline.is_none()
+        && compare.is_none()
+        && sort.is_none()
+        && max_params.is_none()
+        && severity_filter.is_none()
+    {
         let result = handle_mode_output(
             &normalized_path,
             OutputMode::Snapshot,
@@ -264,6 +1105,13 @@ pub(crate) fn handle_analyze(args: AnalyzeArgs) -> anyhow::Result<()> {
                 callgraph_skip_above,
                 skip_touch_metrics: touch_args.skip,
                 skip_gate,
+                max_results: None,
+                strict,
+                regressions_only: false,
+                explain_diff: false,
+                check_thresholds: true,
+                exit_zero,
+                show_progress: !no_progress,
             },
         );
         return result;
@@ -272,14 +1120,80 @@ pub(crate) fn handle_analyze(args: AnalyzeArgs) -> anyhow::Result<()> {
     // Default behavior (no --mode): simple text/JSON output
     handle_default_output(
         &normalized_path,
-        format,
-        explain_patterns,
-        effective_min_lrs,
-        effective_top,
         &resolved_config,
+        DefaultOutputOptions {
+            format,
+            explain_patterns,
+            min_lrs: effective_min_lrs,
+            min_severity: severity_filter,
+            top: effective_top,
+            diff_against: diff_against.as_deref(),
+            max_results,
+            junit_granularity,
+            group_by,
+            daemon_socket: daemon_socket.as_deref(),
+            pattern_flags: (&include, &exclude),
+            save_baseline: save_baseline.as_deref(),
+            baseline: baseline.as_deref(),
+            compare: compare.as_deref(),
+            changed_only,
+            sort,
+            offset,
+            ascending: asc,
+            ranked: sort.is_some() || offset.is_some() || asc || desc,
+            max_params,
+            exit_zero,
+            output: output.as_deref(),
+            show_progress: !no_progress,
+        },
     )
 }
 
+/// `--files-from`: the paths listed in `list` (`-` = stdin), one per line,
+/// relative to the working directory. Blank lines are ignored, and paths
+/// that do not exist are skipped with a warning, so a list of staged files
+/// that includes deletions still works.
+fn read_file_list(list: &Path) -> anyhow::Result<Vec<PathBuf>> {
+    use std::io::Read;
+
+    let mut text = String::new();
+    if list == Path::new("-") {
+        std::io::stdin()
+            .read_to_string(&mut text)
+            .context("failed to read file list from stdin")?;
+    } else {
+        text = std::fs::read_to_string(list)
+            .with_context(|| format!("failed to read file list {}", list.display()))?;
+    }
+
+    let cwd = std::env::current_dir()?;
+    let mut files = Vec::new();
+    for line in text.lines().map(str::trim).filter(|l| !l.is_empty()) {
+        // Normalized like the analyzed path, so config patterns match
+        let file: PathBuf = cwd.join(line).components().collect();
+        if file.is_file() {
+            files.push(file);
+        } else {
+            eprintln!("warning: skipping {}: not an existing file", line);
+        }
+    }
+    Ok(files)
+}
+
+/// `--changed`: the files under `path` that differ from `base`, by default
+/// the merge-base of HEAD with the default branch
+fn changed_files(path: &Path, base: Option<&str>) -> anyhow::Result<Vec<PathBuf>> {
+    let repo_root = find_repo_root(path)
+        .context("--changed requires a git repository")
+        .map_err(exit::usage)?;
+    let base = match base {
+        Some(base) => base.to_string(),
+        None => git::default_branch_merge_base(&repo_root).map_err(exit::usage)?,
+    };
+    let files = git::changed_files_at(&repo_root, &base).map_err(exit::usage)?;
+    Ok(files.into_iter().filter(|f| f.starts_with(path)).collect())
+}
+
 struct TouchArgs {
     no_per_function: bool,
     per_function: bool,
@@ -299,108 +1213,878 @@ fn handle_cold_start(
     resolved_config: &hotspots_core::ResolvedConfig,
     touch_mode: TouchMode,
     top: Option<usize>,
+    strict: bool,
+    show_progress: bool,
+) -> anyhow::Result<()> {
+    let repo_root = find_repo_root(path)?;
+    check_history_depth(&repo_root, strict)?;
+    let analysis_progress = make_analysis_progress(show_progress);
+    let reports = analyze_with_progress(
+        path,
+        AnalysisOptions {
+            min_lrs: None,
+            top_n: None,
+        },
+        Some(resolved_config),
+        Some(analysis_progress.as_ref()),
+    )?;
+
+    let mut snapshot = build_enriched_snapshot(
+        &repo_root,
+        resolved_config,
+        reports,
+        touch_mode,
+        None,
+        true, // skip touch metrics — not part of the cold-start feature set
+        show_progress,
+    )
+    .context("failed to build snapshot for cold-start ranking")?;
+    snapshot.populate_history_signals(&repo_root);
+    snapshot.populate_authors_90d(&repo_root);
+
+    let result = hotspots_core::trainer::cold_start_rank(&snapshot);
+
+    let route_label = match result.route {
+        hotspots_core::trainer::ColdStartRoute::Formula => "formula",
+        hotspots_core::trainer::ColdStartRoute::Anomaly => "anomaly",
+        hotspots_core::trainer::ColdStartRoute::UniformPrior => "uniform-prior",
+    };
+    println!("cold-start route: {route_label}");
+
+    if result.ranked.is_empty() {
+        println!("(uniform prior — no ranking to show; all files equally likely)");
+        return Ok(());
+    }
+
+    let limit = match top {
+        Some(0) => result.ranked.len(),
+        Some(n) => n,
+        None => 20,
+    };
+    for scored in result.ranked.iter().take(limit) {
+        println!("{:.4}  {}", scored.score, scored.function_id);
+    }
+
+    Ok(())
+}
+
+struct DefaultOutputOptions<'a> {
+    format: OutputFormat,
+    explain_patterns: bool,
+    min_lrs: Option<f64>,
+    /// `--min-severity`
+    min_severity: Option<hotspots_core::severity::SeverityFilter>,
+    top: Option<usize>,
+    diff_against: Option<&'a Path>,
+    max_results: Option<usize>,
+    junit_granularity: Option<JunitGranularity>,
+    group_by: Option<GroupBy>,
+    daemon_socket: Option<&'a Path>,
+    /// `--include` and `--exclude`, for the daemon to apply to its config
+    pattern_flags: (&'a [String], &'a [String]),
+    save_baseline: Option<&'a Path>,
+    baseline: Option<&'a Path>,
+    /// `--compare`, and whether `--changed-only` hides unchanged functions
+    compare: Option<&'a Path>,
+    changed_only: bool,
+    sort: Option<SortKey>,
+    /// `--offset`: functions skipped before `--top`
+    offset: Option<usize>,
+    /// `--asc`
+    ascending: bool,
+    /// Any of `--sort`, `--offset`, `--asc`, or `--desc`: text output is one
+    /// ranked table instead of per-file groups
+    ranked: bool,
+    max_params: Option<u32>,
+    exit_zero: bool,
+    /// `--output` for `--format html`
+    output: Option<&'a Path>,
+    show_progress: bool,
+}
+
+fn handle_default_output(
+    path: &Path,
+    resolved_config: &hotspots_core::ResolvedConfig,
+    opts: DefaultOutputOptions,
+) -> anyhow::Result<()> {
+    let DefaultOutputOptions {
+        format,
+        explain_patterns,
+        min_lrs,
+        min_severity,
+        top,
+        diff_against,
+        max_results,
+        junit_granularity,
+        group_by,
+        daemon_socket,
+        pattern_flags,
+        save_baseline,
+        baseline,
+        compare,
+        changed_only,
+        sort,
+        offset,
+        ascending,
+        ranked,
+        max_params,
+        exit_zero,
+        output,
+        show_progress,
+    } = opts;
+    if matches!(format, OutputFormat::Jsonl) {
+        return stream_jsonl_reports(
+            path,
+            resolved_config,
+            min_lrs,
+            min_severity,
+            explain_patterns,
+            max_params,
+            exit_zero,
+        );
+    }
+    let explicit_top = top.or(resolved_config.top_n);
+    // 0 is the sentinel for "show all"; otherwise default to 20 for text and tree output
+    let limit = match explicit_top {
+        Some(0) => usize::MAX,
+        Some(n) => n,
+        None => 20,
+    };
+    let top_n = if matches!(format, OutputFormat::Text | OutputFormat::Tree) {
+        Some(limit).filter(|&n| n != usize::MAX)
+    } else {
+        explicit_top.filter(|&n| n != 0)
+    };
+    // Rollups sum over every function, so filters apply to components instead;
+    // baselines and comparisons must cover every function too, or filtered-out
+    // ones would come back as new or removed. --max-params checks every function, then filters below.
+    // The Markdown summary line counts every function, and its table filters.
+    let markdown = matches!(format, OutputFormat::Markdown);
+    let unfiltered = group_by.is_some()
+        || save_baseline.is_some()
+        || baseline.is_some()
+        || compare.is_some()
+        || markdown;
+    // --top is applied below: the threshold gate covers every function past
+    // --min-lrs, and the top N by LRS are not the top N by another key
+    let options = AnalysisOptions {
+        min_lrs: if unfiltered || max_params.is_some() {
+            None
+        } else {
+            min_lrs
+        },
+        top_n: None,
+    };
+    let mut reports = match daemon_socket {
+        Some(socket) => analyze_via_daemon(socket, path, resolved_config, pattern_flags, options)?,
+        None => {
+            let analysis_progress = make_analysis_progress(show_progress);
+            analyze_with_progress(
+                path,
+                options,
+                Some(resolved_config),
+                Some(analysis_progress.as_ref()),
+            )?
+        }
+    };
+
+    let too_many_params: Vec<hotspots_core::FunctionRiskReport> = match max_params {
+        Some(max) => reports
+            .iter()
+            .filter(|r| r.metrics.params > max)
+            .cloned()
+            .collect(),
+        None => Vec::new(),
+    };
+    // A risk-score severity scale rates functions by the score --sort
+    // risk-score uses, churn included
+    if resolved_config.severity.metric == hotspots_core::severity::SeverityMetric::RiskScore
+        && (min_severity.is_some() || matches!(format, OutputFormat::Tree | OutputFormat::Html))
+    {
+        annotate_risk_scores_with_churn(&mut reports, path, &resolved_config.risk_score_weights);
+    }
+    let shown = |r: &hotspots_core::FunctionRiskReport| {
+        !min_lrs.is_some_and(|min| r.lrs < min) && min_severity.map_or(true, |f| f.keeps(r))
+    };
+    if !unfiltered {
+        reports.retain(shown);
+    }
+    let mut violations = ThresholdViolations::default();
+    violations.record(
+        reports.iter().filter(|r| shown(r)),
+        &resolved_config.sarif_rules,
+    );
+
+    match sort {
+        Some(SortKey::Maintainability) => sort_by_maintainability(&mut reports),
+        Some(SortKey::Fi) => sort_by_fan_in(&mut reports),
+        Some(SortKey::RiskScore) => {
+            sort_by_risk_score(&mut reports, path, &resolved_config.risk_score_weights)
+        }
+        // Reports arrive in LRS order; re-sort only for another metric or --asc
+        Some(key) => {
+            if let Some(metric) = key.metric() {
+                hotspots_core::report::sort_reports_by(&mut reports, metric, ascending);
+            }
+        }
+        None if ascending => hotspots_core::report::sort_reports_by(
+            &mut reports,
+            hotspots_core::report::SortMetric::Lrs,
+            true,
+        ),
+        None => {}
+    }
+
+    // Functions cut by --top, for the "... N more" footer
+    let mut more = 0;
+    if !unfiltered {
+        if let Some(skip) = offset {
+            reports.drain(..skip.min(reports.len()));
+        }
+        if let Some(n) = top_n {
+            more = reports.len().saturating_sub(n);
+            reports.truncate(n);
+        }
+    }
+
+    if explain_patterns {
+        populate_pattern_details(&mut reports, resolved_config);
+    }
+
+    if let Some(baseline_path) = save_baseline {
+        return save_report_baseline(baseline_path, path, reports);
+    }
+    if let Some(baseline_path) = baseline {
+        return print_baseline_regressions(baseline_path, path, reports, format, exit_zero);
+    }
+    if let Some(prev_path) = compare {
+        return print_comparison(prev_path, path, reports, format, changed_only);
+    }
+
+    if let Some(GroupBy::Component) = group_by {
+        let mut rollups = hotspots_core::components::component_rollups(&reports);
+        if let Some(min) = min_lrs {
+            rollups.retain(|r| r.max_lrs >= min);
+        }
+        // Same defaults as function lists: 20 for text, all for JSON
+        rollups.truncate(match format {
+            OutputFormat::Text => limit,
+            _ => explicit_top.filter(|&n| n != 0).unwrap_or(usize::MAX),
+        });
+        match format {
+            OutputFormat::Json => println!(
+                "{}",
+                hotspots_core::components::render_components_json(&rollups)
+            ),
+            _ => print!(
+                "{}",
+                hotspots_core::components::render_components_text(&rollups)
+            ),
+        }
+    } else if let Some(GroupBy::Dir) = group_by {
+        // Every function counts toward its directory, so --top and --min-lrs
+        // do not apply
+        let root = if path.is_dir() {
+            path
+        } else {
+            path.parent().unwrap_or(path)
+        };
+        let tree = hotspots_core::dirs::dir_rollups(&reports, root);
+        match format {
+            OutputFormat::Json => println!("{}", hotspots_core::dirs::render_dirs_json(&tree)),
+            _ => print!("{}", hotspots_core::dirs::render_dirs_text(&tree)),
+        }
+    } else {
+        match format {
+            OutputFormat::Text => {
+                let color =
+                    std::io::stdout().is_terminal() && std::env::var_os("NO_COLOR").is_none();
+                if ranked {
+                    print!(
+                        "{}",
+                        hotspots_core::render_text_ranked(&reports, offset.unwrap_or(0), more)
+                    );
+                } else {
+                    print!(
+                        "{}",
+                        hotspots_core::render_text_grouped(&reports, limit, more, color)
+                    );
+                }
+            }
+            OutputFormat::Json => match (diff_against, max_results) {
+                (Some(prev_path), _) => print_report_diff(prev_path, path, reports)?,
+                (None, Some(n)) => println!(
+                    "{}",
+                    hotspots_core::render_json_capped(&reports, n, TOOL_VERSION)
+                ),
+                (None, None) => println!(
+                    "{}",
+                    hotspots_core::render_json_report(&reports, TOOL_VERSION)
+                ),
+            },
+            OutputFormat::Html => {
+                let base = find_repo_root(path).unwrap_or_else(|_| path.to_path_buf());
+                let thresholds = hotspots_core::risk::RiskThresholds {
+                    moderate: resolved_config.moderate_threshold,
+                    high: resolved_config.high_threshold,
+                    critical: resolved_config.critical_threshold,
+                };
+                let html = hotspots_core::html::render_html_reports(
+                    &reports,
+                    &base,
+                    &thresholds,
+                    &resolved_config.severity,
+                );
+                let output_path = output.unwrap_or(Path::new(".hotspots/report.html"));
+                write_html_report(output_path, &html)?;
+                eprintln!("HTML report written to: {}", output_path.display());
+            }
+            OutputFormat::Jsonl => unreachable!("streamed by stream_jsonl_reports"),
+            OutputFormat::Sarif => bail_usage!("SARIF format requires --mode snapshot"),
+            OutputFormat::Junit => {
+                let base = find_repo_root(path).unwrap_or_else(|_| path.to_path_buf());
+                let granularity = match junit_granularity {
+                    Some(JunitGranularity::Metric) => {
+                        hotspots_core::junit::JunitGranularity::Metric
+                    }
+                    Some(JunitGranularity::Function) | None => {
+                        hotspots_core::junit::JunitGranularity::Function
+                    }
+                };
+                print!(
+                    "{}",
+                    hotspots_core::junit::render_junit(
+                        &reports,
+                        &base,
+                        &resolved_config.sarif_rules,
+                        granularity
+                    )
+                );
+            }
+            OutputFormat::Treemap => {
+                let base = find_repo_root(path).unwrap_or_else(|_| path.to_path_buf());
+                println!(
+                    "{}",
+                    hotspots_core::treemap::render_treemap(&reports, &base)
+                );
+            }
+            OutputFormat::Gitlab => {
+                let base = find_repo_root(path).unwrap_or_else(|_| path.to_path_buf());
+                println!(
+                    "{}",
+                    hotspots_core::gitlab::render_gitlab(
+                        &reports,
+                        &base,
+                        &resolved_config.sarif_rules,
+                        &resolved_config.severity
+                    )
+                );
+            }
+            OutputFormat::Markdown => {
+                let base = find_repo_root(path).unwrap_or_else(|_| path.to_path_buf());
+                print!(
+                    "{}",
+                    hotspots_core::markdown::render_markdown(
+                        &reports,
+                        &base,
+                        min_lrs,
+                        min_severity.as_ref(),
+                        limit
+                    )
+                );
+            }
+            OutputFormat::Csv => {
+                let base = find_repo_root(path).unwrap_or_else(|_| path.to_path_buf());
+                let columns = hotspots_core::csv::CsvColumns {
+                    fan_in: resolved_config.fan_in,
+                    halstead: resolved_config.halstead,
+                    line_counts: resolved_config.line_counts,
+                    risk_score: sort == Some(SortKey::RiskScore),
+                };
+                print!(
+                    "{}",
+                    hotspots_core::csv::render_csv(&reports, &base, columns)
+                );
+            }
+            OutputFormat::Tree => {
+                let base = find_repo_root(path).unwrap_or_else(|_| path.to_path_buf());
+                let color =
+                    std::io::stdout().is_terminal() && std::env::var_os("NO_COLOR").is_none();
+                print!(
+                    "{}",
+                    hotspots_core::tree::render_tree(
+                        &reports,
+                        &base,
+                        &resolved_config.severity,
+                        more,
+                        color
+                    )
+                );
+            }
+        }
+    }
+    if let Some(max) = max_params {
+        if !too_many_params.is_empty() {
+            report_too_many_params(&too_many_params, max);
+        }
+    }
+    violations.report();
+    if !too_many_params.is_empty() || !violations.is_empty() {
+        exit::violations(exit_zero);
+    }
+    Ok(())
+}
+
+/// `--format jsonl` without `--mode`: write each file's reports, one JSON
+/// object per line, as soon as the file is analyzed, flushing after every
+/// file so consumers can start before the analysis ends
+fn stream_jsonl_reports(
+    path: &Path,
+    resolved_config: &hotspots_core::ResolvedConfig,
+    min_lrs: Option<f64>,
+    min_severity: Option<hotspots_core::severity::SeverityFilter>,
+    explain_patterns: bool,
+    max_params: Option<u32>,
+    exit_zero: bool,
+) -> anyhow::Result<()> {
+    use std::io::Write;
+
+    let mut out = std::io::BufWriter::new(std::io::stdout());
+    let mut too_many_params = Vec::new();
+    let mut violations = ThresholdViolations::default();
+    // --min-lrs and --min-severity are applied per file below, so --max-params
+    // sees every function
+    hotspots_core::analyze_streaming(
+        path,
+        AnalysisOptions {
+            min_lrs: None,
+            top_n: None,
+        },
+        Some(resolved_config),
+        |mut reports| {
+            if let Some(max) = max_params {
+                too_many_params.extend(reports.iter().filter(|r| r.metrics.params > max).cloned());
+            }
+            reports.retain(|r| {
+                !min_lrs.is_some_and(|min| r.lrs < min) && min_severity.map_or(true, |f| f.keeps(r))
+            });
+            violations.record(&reports, &resolved_config.sarif_rules);
+            if explain_patterns {
+                populate_pattern_details(&mut reports, resolved_config);
+            }
+            for report in &reports {
+                writeln!(
+                    out,
+                    "{}",
+                    hotspots_core::render_jsonl_line(report, TOOL_VERSION)
+                )?;
+            }
+            out.flush().context("failed to write JSONL")
+        },
+    )?;
+    if let Some(max) = max_params {
+        if !too_many_params.is_empty() {
+            report_too_many_params(&too_many_params, max);
+        }
+    }
+    violations.report();
+    if !too_many_params.is_empty() || !violations.is_empty() {
+        exit::violations(exit_zero);
+    }
+    Ok(())
+}
+
+/// `--quiet`: print nothing for a clean run, else one line per offending
+/// function, `file:line function cc=N`, naming each metric at or above its
+/// threshold (and `params=N` over `--max-params`), in file order. No
+/// progress or summary is shown, so a git hook stays silent unless it fails.
+fn handle_quiet(
+    path: &Path,
+    resolved_config: &hotspots_core::ResolvedConfig,
+    min_lrs: Option<f64>,
+    min_severity: Option<hotspots_core::severity::SeverityFilter>,
+    max_params: Option<u32>,
+    exit_zero: bool,
 ) -> anyhow::Result<()> {
-    let repo_root = find_repo_root(path)?;
-    let analysis_progress = make_analysis_progress();
-    let reports = analyze_with_progress(
+    let mut reports = analyze_with_progress(
         path,
         AnalysisOptions {
             min_lrs: None,
             top_n: None,
         },
         Some(resolved_config),
-        Some(analysis_progress.as_ref()),
-    )?;
-
-    let mut snapshot = build_enriched_snapshot(
-        &repo_root,
-        resolved_config,
-        reports,
-        touch_mode,
         None,
-        true, // skip touch metrics — not part of the cold-start feature set
-    )
-    .context("failed to build snapshot for cold-start ranking")?;
-    snapshot.populate_history_signals(&repo_root);
-    snapshot.populate_authors_90d(&repo_root);
+    )?;
+    reports.sort_by(|a, b| (&a.file, a.line).cmp(&(&b.file, b.line)));
+
+    let cwd = std::env::current_dir().ok();
+    let mut offending = 0;
+    for report in &reports {
+        // --min-lrs and --min-severity narrow the threshold gate, as without
+        // --quiet
+        let mut offenses: Vec<String> = if min_lrs.is_some_and(|min| report.lrs < min)
+            || min_severity.is_some_and(|f| !f.keeps(report))
+        {
+            Vec::new()
+        } else {
+            hotspots_core::junit::breaches(report, &resolved_config.sarif_rules)
+                .iter()
+                .map(|b| format!("{}={}", b.metric, b.value))
+                .collect()
+        };
+        if max_params.is_some_and(|max| report.metrics.params > max) {
+            offenses.push(format!("params={}", report.metrics.params));
+        }
+        if offenses.is_empty() {
+            continue;
+        }
+        offending += 1;
+        let file = cwd
+            .as_ref()
+            .and_then(|cwd| Path::new(&report.file).strip_prefix(cwd).ok())
+            .map_or_else(|| report.file.clone(), |p| p.display().to_string());
+        println!(
+            "{}:{} {} {}",
+            file,
+            report.line,
+            report.function,
+            offenses.join(" ")
+        );
+    }
+    if offending > 0 {
+        exit::violations(exit_zero);
+    }
+    Ok(())
+}
 
-    let result = hotspots_core::trainer::cold_start_rank(&snapshot);
+/// `--max-params`: list the functions over the limit on stderr, so the
+/// report on stdout stays parseable
+fn report_too_many_params(offenders: &[hotspots_core::FunctionRiskReport], max: u32) {
+    eprintln!(
+        "{} function(s) declare more than {} parameters:",
+        offenders.len(),
+        max
+    );
+    for r in offenders {
+        eprintln!(
+            "  {}:{} {} ({} params)",
+            r.file, r.line, r.function, r.metrics.params
+        );
+    }
+}
 
-    let route_label = match result.route {
-        hotspots_core::trainer::ColdStartRoute::Formula => "formula",
-        hotspots_core::trainer::ColdStartRoute::Anomaly => "anomaly",
-        hotspots_core::trainer::ColdStartRoute::UniformPrior => "uniform-prior",
-    };
-    println!("cold-start route: {route_label}");
+/// Functions at or above a per-metric threshold (config key `sarif`, the
+/// checks `--format junit` fails), gating `analyze` without `--mode`
+#[derive(Default)]
+struct ThresholdViolations {
+    functions: usize,
+    /// `(metric, threshold, functions breaching it)` in rule order
+    by_metric: Vec<(&'static str, u32, usize)>,
+}
 
-    if result.ranked.is_empty() {
-        println!("(uniform prior — no ranking to show; all files equally likely)");
-        return Ok(());
+impl ThresholdViolations {
+    fn record<'a>(
+        &mut self,
+        reports: impl IntoIterator<Item = &'a hotspots_core::FunctionRiskReport>,
+        rules: &hotspots_core::sarif::MetricRules,
+    ) {
+        if self.by_metric.is_empty() {
+            self.by_metric = rules
+                .iter()
+                .map(|(metric, rule)| (metric, rule.threshold, 0))
+                .collect();
+        }
+        for report in reports {
+            let breaches = hotspots_core::junit::breaches(report, rules);
+            if breaches.is_empty() {
+                continue;
+            }
+            self.functions += 1;
+            for breach in breaches {
+                if let Some(entry) = self.by_metric.iter_mut().find(|e| e.0 == breach.metric) {
+                    entry.2 += 1;
+                }
+            }
+        }
     }
 
-    let limit = match top {
-        Some(0) => result.ranked.len(),
-        Some(n) => n,
-        None => 20,
-    };
-    for scored in result.ranked.iter().take(limit) {
-        println!("{:.4}  {}", scored.score, scored.function_id);
+    fn is_empty(&self) -> bool {
+        self.functions == 0
     }
 
-    Ok(())
+    /// Summarize on stderr, so the report on stdout stays parseable
+    fn report(&self) {
+        if self.is_empty() {
+            return;
+        }
+        let counts: Vec<String> = self
+            .by_metric
+            .iter()
+            .filter(|e| e.2 > 0)
+            .map(|(metric, threshold, n)| format!("{metric} >= {threshold} ({n})"))
+            .collect();
+        eprintln!(
+            "{} function(s) exceed configured thresholds: {}",
+            self.functions,
+            counts.join(", ")
+        );
+    }
 }
 
-fn handle_default_output(
+/// `--daemon-socket`: have a running `hotspots daemon` analyze `path`. The
+/// daemon resolves the same config file, so results match in-process analysis.
+#[cfg(unix)]
+fn analyze_via_daemon(
+    socket: &Path,
     path: &Path,
-    format: OutputFormat,
-    explain_patterns: bool,
-    min_lrs: Option<f64>,
-    top: Option<usize>,
     resolved_config: &hotspots_core::ResolvedConfig,
-) -> anyhow::Result<()> {
-    let analysis_progress = make_analysis_progress();
-    let explicit_top = top.or(resolved_config.top_n);
-    // 0 is the sentinel for "show all"; otherwise default to 20 for text output
-    let limit = match explicit_top {
-        Some(0) => usize::MAX,
-        Some(n) => n,
-        None => 20,
-    };
-    let mut reports = analyze_with_progress(
-        path,
-        AnalysisOptions {
-            min_lrs,
-            top_n: if matches!(format, OutputFormat::Text) {
-                Some(limit).filter(|&n| n != usize::MAX)
-            } else {
-                explicit_top.filter(|&n| n != 0)
-            },
+    (include, exclude): (&[String], &[String]),
+    options: AnalysisOptions,
+) -> anyhow::Result<Vec<hotspots_core::FunctionRiskReport>> {
+    use hotspots_core::daemon::{self, Request};
+
+    let response = daemon::request(
+        socket,
+        &Request::AnalyzePath {
+            path: path.to_path_buf(),
+            root: Some(find_repo_root(path).unwrap_or_else(|_| path.to_path_buf())),
+            config: resolved_config.config_path.clone(),
+            min_lrs: options.min_lrs,
+            top_n: options.top_n,
+            dedup_symlinks: resolved_config.dedup_symlinks,
+            public_only: resolved_config.public_only,
+            include_tests: resolved_config.include_tests,
+            no_gitignore: !resolved_config.gitignore,
+            halstead: resolved_config.halstead,
+            line_counts: resolved_config.line_counts,
+            nd_lines: resolved_config.nd_lines,
+            cc_lines: resolved_config.cc_lines,
+            separate_closures: resolved_config.separate_closures,
+            ns_breakdown: resolved_config.ns_breakdown,
+            fan_in: resolved_config.fan_in,
+            sql_dialect: resolved_config.sql_dialect,
+            cc_mode: Some(resolved_config.cc_mode),
+            no_fo_methods: !resolved_config.fo_methods,
+            anon_naming: Some(resolved_config.anon_naming),
+            include: include.to_vec(),
+            exclude: exclude.to_vec(),
         },
-        Some(resolved_config),
-        Some(analysis_progress.as_ref()),
     )?;
+    Ok(response.reports.unwrap_or_default())
+}
 
-    if explain_patterns {
-        populate_pattern_details(&mut reports, resolved_config);
+#[cfg(not(unix))]
+fn analyze_via_daemon(
+    _socket: &Path,
+    _path: &Path,
+    _resolved_config: &hotspots_core::ResolvedConfig,
+    _pattern_flags: (&[String], &[String]),
+    _options: AnalysisOptions,
+) -> anyhow::Result<Vec<hotspots_core::FunctionRiskReport>> {
+    anyhow::bail!("--daemon-socket requires unix domain sockets (not available on this platform)")
+}
+
+/// `--sort maintainability`: lowest maintainability index first. Functions
+/// without one (languages without Halstead metrics) go last. Ties are broken
+/// by file, line, and name.
+fn sort_by_maintainability(reports: &mut [hotspots_core::FunctionRiskReport]) {
+    reports.sort_by(|a, b| {
+        match (a.metrics.maintainability, b.metrics.maintainability) {
+            (Some(x), Some(y)) => x.partial_cmp(&y).unwrap_or(std::cmp::Ordering::Equal),
+            (Some(_), None) => std::cmp::Ordering::Less,
+            (None, Some(_)) => std::cmp::Ordering::Greater,
+            (None, None) => std::cmp::Ordering::Equal,
+        }
+        .then_with(|| hotspots_core::report::location_order(a, b))
+    });
+}
+
+/// `--sort fi`: most callers first, ties by file, line, and name
+fn sort_by_fan_in(reports: &mut [hotspots_core::FunctionRiskReport]) {
+    reports.sort_by(|a, b| {
+        b.metrics
+            .fi
+            .cmp(&a.metrics.fi)
+            .then_with(|| hotspots_core::report::location_order(a, b))
+    });
+}
+
+/// `--sort risk-score`: highest composite risk score first, scored by
+/// [`annotate_risk_scores_with_churn`]
+fn sort_by_risk_score(
+    reports: &mut [hotspots_core::FunctionRiskReport],
+    path: &Path,
+    weights: &hotspots_core::risk::RiskScoreWeights,
+) {
+    // A risk-score severity scale may have scored them already
+    if reports.iter().any(|r| r.risk_score.is_none()) {
+        annotate_risk_scores_with_churn(reports, path, weights);
+    }
+    reports.sort_by(|a, b| {
+        b.risk_score
+            .partial_cmp(&a.risk_score)
+            .unwrap_or(std::cmp::Ordering::Equal)
+            .then_with(|| hotspots_core::report::location_order(a, b))
+    });
+}
+
+/// Fill in each report's composite risk score. Churn over the default
+/// `--mode churn` window counts when it is weighted and `path` is in a git
+/// repository.
+fn annotate_risk_scores_with_churn(
+    reports: &mut [hotspots_core::FunctionRiskReport],
+    path: &Path,
+    weights: &hotspots_core::risk::RiskScoreWeights,
+) {
+    use hotspots_core::churn;
+
+    let repo_root = find_repo_root(path).ok().filter(|_| weights.churn > 0.0);
+    let churn_by_function: std::collections::HashMap<(String, u32), usize> = match &repo_root {
+        Some(root) => churn::compute_churn_report(
+            Some(root),
+            reports,
+            churn::DEFAULT_WINDOW_DAYS,
+            churn::ChurnMetric::Cc,
+        )
+        .functions
+        .into_iter()
+        .map(|f| ((f.file, f.line), f.churn))
+        .collect(),
+        None => std::collections::HashMap::new(),
+    };
+    let churn_known = repo_root.is_some();
+    hotspots_core::report::annotate_risk_scores(reports, weights, |r| {
+        churn_known.then(|| {
+            let key = (r.file.clone(), r.line);
+            churn_by_function.get(&key).copied().unwrap_or(0)
+        })
+    });
+}
+
+/// `--diff-against`: print only the functions that changed since `prev_path`.
+fn print_report_diff(
+    prev_path: &Path,
+    path: &Path,
+    reports: Vec<hotspots_core::FunctionRiskReport>,
+) -> anyhow::Result<()> {
+    let diff = diff_against_previous(prev_path, path, reports)?;
+    println!("{}", diff.to_json(TOOL_VERSION)?);
+    Ok(())
+}
+
+/// `--save-baseline`: write every function's metrics, with repo-relative paths
+/// so the baseline still matches from another checkout
+fn save_report_baseline(
+    baseline_path: &Path,
+    path: &Path,
+    mut reports: Vec<hotspots_core::FunctionRiskReport>,
+) -> anyhow::Result<()> {
+    let repo_root = find_repo_root(path).unwrap_or_else(|_| path.to_path_buf());
+    let paths = snapshot::RepoPaths::new(&repo_root);
+    for report in &mut reports {
+        report.file = paths.portable(&report.file);
+    }
+    std::fs::write(
+        baseline_path,
+        format!("{}\n", hotspots_core::render_json(&reports)),
+    )
+    .with_context(|| format!("failed to write baseline: {}", baseline_path.display()))?;
+    eprintln!(
+        "Saved baseline of {} functions to {}",
+        reports.len(),
+        baseline_path.display()
+    );
+    Ok(())
+}
+
+/// `--baseline`: print the functions that regressed against the baseline and
+/// exit 1 if there are any; a clean text run prints nothing
+fn print_baseline_regressions(
+    baseline_path: &Path,
+    path: &Path,
+    reports: Vec<hotspots_core::FunctionRiskReport>,
+    format: OutputFormat,
+    exit_zero: bool,
+) -> anyhow::Result<()> {
+    let diff = diff_against_previous(baseline_path, path, reports)?;
+    let regressions = diff.regressions();
+    match format {
+        OutputFormat::Json => println!("{}", delta::render_regressions_json(&regressions)?),
+        _ => print!("{}", delta::render_regressions_text(&regressions)),
+    }
+    if !regressions.is_empty() {
+        exit::violations(exit_zero);
     }
+    Ok(())
+}
 
+/// `--compare`: print every function's metrics and their change since
+/// `prev_path`
+fn print_comparison(
+    prev_path: &Path,
+    path: &Path,
+    reports: Vec<hotspots_core::FunctionRiskReport>,
+    format: OutputFormat,
+    changed_only: bool,
+) -> anyhow::Result<()> {
+    let (previous, current) = load_previous_results(prev_path, path, reports)?;
+    let entries = delta::compare_functions(&previous, &current);
     match format {
-        OutputFormat::Text => {
-            let color = std::io::stdout().is_terminal() && std::env::var_os("NO_COLOR").is_none();
-            print!(
-                "{}",
-                hotspots_core::render_text_grouped(&reports, limit, color)
-            );
-        }
-        OutputFormat::Json => println!("{}", hotspots_core::render_json(&reports)),
-        OutputFormat::Html | OutputFormat::Jsonl => {
-            anyhow::bail!("HTML/JSONL format requires --mode snapshot or --mode delta");
-        }
-        OutputFormat::Sarif => anyhow::bail!("SARIF format requires --mode snapshot"),
+        OutputFormat::Json => println!(
+            "{}",
+            hotspots_core::compare::render_comparison_json(&entries, changed_only)?
+        ),
+        _ => print!(
+            "{}",
+            hotspots_core::compare::render_comparison(&entries, changed_only)
+        ),
     }
     Ok(())
 }
 
+/// Diff the current reports against a previous results file, matching
+/// functions by `file::name` on repo-relative paths
+fn diff_against_previous(
+    prev_path: &Path,
+    path: &Path,
+    reports: Vec<hotspots_core::FunctionRiskReport>,
+) -> anyhow::Result<delta::ReportDiff> {
+    let (previous, current) = load_previous_results(prev_path, path, reports)?;
+    Ok(delta::ReportDiff::new(&previous, &current))
+}
+
+/// The functions of a previous results file and of the current reports, both
+/// with repo-relative paths
+fn load_previous_results(
+    prev_path: &Path,
+    path: &Path,
+    reports: Vec<hotspots_core::FunctionRiskReport>,
+) -> anyhow::Result<(
+    Vec<snapshot::FunctionSnapshot>,
+    Vec<snapshot::FunctionSnapshot>,
+)> {
+    let prev_json = std::fs::read_to_string(prev_path)
+        .with_context(|| format!("failed to read {}", prev_path.display()))?;
+    let mut previous = delta::parse_previous_results(&prev_json).with_context(|| {
+        format!(
+            "failed to load previous results from {}",
+            prev_path.display()
+        )
+    })?;
+    let mut current: Vec<snapshot::FunctionSnapshot> = reports
+        .into_iter()
+        .map(snapshot::FunctionSnapshot::from)
+        .collect();
+    // Match on repo-relative paths so a results file from another OS or
+    // checkout location still lines up
+    let repo_root = find_repo_root(path).unwrap_or_else(|_| path.to_path_buf());
+    let paths = snapshot::RepoPaths::new(&repo_root);
+    for function in previous.iter_mut().chain(current.iter_mut()) {
+        function.map_path(|p| paths.portable(p));
+    }
+    Ok((previous, current))
+}
+
 fn populate_pattern_details(
     reports: &mut [hotspots_core::FunctionRiskReport],
     resolved_config: &hotspots_core::ResolvedConfig,
@@ -412,9 +2096,10 @@ fn populate_pattern_details(
             fo: report.metrics.fo as usize,
             ns: report.metrics.ns as usize,
             loc: report.metrics.loc as usize,
+            arrow_depth: Some(report.arrow_depth),
         };
         let t2 = hotspots_core::patterns::Tier2Input {
-            fan_in: None,
+            fan_in: resolved_config.fan_in.then_some(report.metrics.fi as usize),
             scc_size: None,
             churn_lines: None,
             days_since_last_change: None,
@@ -447,6 +2132,15 @@ pub(crate) struct ModeOutputOptions {
     pub callgraph_skip_above: Option<usize>,
     pub skip_touch_metrics: bool,
     pub skip_gate: bool,
+    pub max_results: Option<usize>,
+    pub strict: bool,
+    pub regressions_only: bool,
+    pub explain_diff: bool,
+    /// Apply the threshold gate of `analyze` without `--mode`
+    pub check_thresholds: bool,
+    pub exit_zero: bool,
+    /// Show analysis and touch-cache progress on stderr
+    pub show_progress: bool,
 }
 
 pub(crate) fn handle_mode_output(
@@ -456,7 +2150,8 @@ pub(crate) fn handle_mode_output(
     opts: ModeOutputOptions,
 ) -> anyhow::Result<()> {
     let repo_root = find_repo_root(path)?;
-    let analysis_progress = make_analysis_progress();
+    check_history_depth(&repo_root, opts.strict)?;
+    let analysis_progress = make_analysis_progress(opts.show_progress);
     let reports = analyze_with_progress(
         path,
         AnalysisOptions {
@@ -470,12 +2165,25 @@ pub(crate) fn handle_mode_output(
 
     match mode {
         OutputMode::Snapshot => {
-            handle_snapshot_mode(path, &repo_root, resolved_config, reports, pr_context, opts)
+            let mut violations = ThresholdViolations::default();
+            if opts.check_thresholds {
+                violations.record(&reports, &resolved_config.sarif_rules);
+            }
+            let exit_zero = opts.exit_zero;
+            handle_snapshot_mode(path, &repo_root, resolved_config, reports, pr_context, opts)?;
+            violations.report();
+            if !violations.is_empty() {
+                exit::violations(exit_zero);
+            }
+            Ok(())
         }
         OutputMode::Delta => {
             handle_delta_mode(&repo_root, resolved_config, reports, pr_context, opts)
         }
         OutputMode::Models => handle_models_mode(path, &repo_root, resolved_config, reports, opts),
+        OutputMode::Resolvers | OutputMode::Churn => {
+            unreachable!("dispatched by handle_analyze")
+        }
     }
 }
 
@@ -500,9 +2208,11 @@ fn handle_snapshot_mode(
         source_url,
         callgraph_skip_above,
         skip_touch_metrics,
+        show_progress,
         skip_gate,
         top,
         output,
+        max_results,
         ..
     } = opts;
     let mut snapshot = build_snapshot_via_db(
@@ -512,6 +2222,7 @@ fn handle_snapshot_mode(
         touch_mode,
         callgraph_skip_above,
         skip_touch_metrics,
+        show_progress,
     )
     .context("failed to build enriched snapshot")?;
 
@@ -616,6 +2327,9 @@ fn handle_snapshot_mode(
                 high: resolved_config.high_threshold,
                 critical: resolved_config.critical_threshold,
             },
+            sarif_rules: resolved_config.sarif_rules,
+            max_results,
+            budgets: resolved_config.budgets.clone(),
         },
         repo_root,
         path,
@@ -637,6 +2351,10 @@ fn handle_delta_mode(
         touch_mode,
         callgraph_skip_above,
         skip_touch_metrics,
+        show_progress,
+        regressions_only,
+        explain_diff,
+        exit_zero,
         ..
     } = opts;
     let snapshot = build_enriched_snapshot(
@@ -646,14 +2364,31 @@ fn handle_delta_mode(
         touch_mode,
         callgraph_skip_above,
         skip_touch_metrics,
+        show_progress,
     )
     .context("failed to build enriched snapshot")?;
 
-    let delta_val = if pr_context.is_pr {
+    let mut delta_val = if pr_context.is_pr {
         compute_pr_delta(repo_root, &snapshot)?
     } else {
         delta::compute_delta(repo_root, &snapshot)?
     };
+    delta_val.apply_exemptions(&resolved_config.exempt, repo_root);
+    if explain_diff {
+        delta_val.explain_regressions();
+    }
+
+    if regressions_only {
+        if delta_val.baseline {
+            eprintln!("No baseline snapshot to compare against; nothing can have regressed");
+        }
+        let regressions = delta_val.regressions();
+        print!("{}", delta::render_regressions_text(&regressions));
+        if !regressions.is_empty() {
+            exit::violations(exit_zero);
+        }
+        return Ok(());
+    }
 
     let delta_with_extras = enrich_delta(repo_root, resolved_config, &snapshot, delta_val, policy)?;
 
@@ -664,7 +2399,7 @@ fn handle_delta_mode(
         output,
         source_url.as_deref(),
     )? {
-        std::process::exit(1);
+        exit::violations(exit_zero);
     }
     Ok(())
 }
@@ -744,6 +2479,7 @@ fn handle_models_mode(
         touch_mode,
         callgraph_skip_above,
         skip_touch_metrics,
+        show_progress,
         ..
     } = opts;
     let snapshot = build_enriched_snapshot(
@@ -753,6 +2489,7 @@ fn handle_models_mode(
         touch_mode,
         callgraph_skip_above,
         skip_touch_metrics,
+        show_progress,
     )
     .context("failed to build enriched snapshot")?;
     let model_map = hotspots_core::models::compute_model_risk_map(path, repo_root, &snapshot, top)
@@ -770,7 +2507,178 @@ fn handle_models_mode(
                 hotspots_core::models::render_model_risk_json(&model_map)?
             );
         }
-        OutputFormat::Html | OutputFormat::Jsonl | OutputFormat::Sarif => {
+        OutputFormat::Html
+        | OutputFormat::Jsonl
+        | OutputFormat::Sarif
+        | OutputFormat::Junit
+        | OutputFormat::Treemap
+        | OutputFormat::Markdown
+        | OutputFormat::Csv
+        | OutputFormat::Tree
+        | OutputFormat::Gitlab => {
+            unreachable!("validated by validate_analyze_flags")
+        }
+    }
+    Ok(())
+}
+
+/// Warn when the clone is shallow, since churn and touch metrics would silently
+/// read as near zero. `--strict` turns the warning into an error. Complexity
+/// output is unaffected either way.
+fn check_history_depth(repo_root: &Path, strict: bool) -> anyhow::Result<()> {
+    // Non-git directories are reported by the git context extraction itself.
+    let Ok(depth) = git::history_depth(repo_root) else {
+        return Ok(());
+    };
+    if let Some(msg) = depth.warning() {
+        if strict {
+            anyhow::bail!("{msg}\n(--strict treats insufficient git history as an error)");
+        }
+        eprintln!("warning: {msg}");
+    }
+    Ok(())
+}
+
+struct ResolverOptions {
+    format: OutputFormat,
+    min_lrs: Option<f64>,
+    top: Option<usize>,
+    resolver_glob: Option<String>,
+    schema: Option<PathBuf>,
+    show_progress: bool,
+}
+
+/// `--mode resolvers`: group function reports by the GraphQL `Type.field` they
+/// implement. Needs no git history, so it runs outside the snapshot pipeline.
+fn handle_resolvers_mode(
+    path: &Path,
+    resolved_config: &hotspots_core::ResolvedConfig,
+    opts: ResolverOptions,
+) -> anyhow::Result<()> {
+    let ResolverOptions {
+        format,
+        min_lrs,
+        top,
+        resolver_glob,
+        schema,
+        show_progress,
+    } = opts;
+    let schema_sdl = schema
+        .map(|p| {
+            std::fs::read_to_string(&p)
+                .with_context(|| format!("failed to read schema: {}", p.display()))
+        })
+        .transpose()?;
+    let analysis_progress = make_analysis_progress(show_progress);
+    let reports = analyze_with_progress(
+        path,
+        AnalysisOptions {
+            min_lrs,
+            top_n: None,
+        },
+        Some(resolved_config),
+        Some(analysis_progress.as_ref()),
+    )?;
+    let resolver_map = hotspots_core::graphql::compute_resolver_map(
+        path,
+        &reports,
+        resolver_glob.as_deref(),
+        schema_sdl.as_deref(),
+    )
+    .context("failed to compute resolver map")?;
+    match format {
+        OutputFormat::Text => {
+            print!(
+                "{}",
+                hotspots_core::graphql::render_resolver_map_text(&resolver_map, top)
+            );
+        }
+        OutputFormat::Json => {
+            println!(
+                "{}",
+                hotspots_core::graphql::render_resolver_map_json(&resolver_map)?
+            );
+        }
+        OutputFormat::Html
+        | OutputFormat::Jsonl
+        | OutputFormat::Sarif
+        | OutputFormat::Junit
+        | OutputFormat::Treemap
+        | OutputFormat::Markdown
+        | OutputFormat::Csv
+        | OutputFormat::Tree
+        | OutputFormat::Gitlab => {
+            unreachable!("validated by validate_analyze_flags")
+        }
+    }
+    Ok(())
+}
+
+struct ChurnOptions {
+    format: OutputFormat,
+    min_lrs: Option<f64>,
+    top: Option<usize>,
+    since: Option<String>,
+    metric: Option<ChurnMetric>,
+    strict: bool,
+    show_progress: bool,
+}
+
+/// `--mode churn`: rank functions by complexity × the commits that touched
+/// them. Reads git history directly rather than through snapshots, and
+/// reports zero churn outside a git repository.
+fn handle_churn_mode(
+    path: &Path,
+    resolved_config: &hotspots_core::ResolvedConfig,
+    opts: ChurnOptions,
+) -> anyhow::Result<()> {
+    use hotspots_core::churn;
+
+    let ChurnOptions {
+        format,
+        min_lrs,
+        top,
+        since,
+        metric,
+        strict,
+        show_progress,
+    } = opts;
+    let window_days = match since {
+        Some(window) => churn::parse_window(&window)?,
+        None => churn::DEFAULT_WINDOW_DAYS,
+    };
+    let metric = match metric {
+        Some(ChurnMetric::Cognitive) => churn::ChurnMetric::Cognitive,
+        Some(ChurnMetric::Cc) | None => churn::ChurnMetric::Cc,
+    };
+    let repo_root = find_repo_root(path).ok();
+    match &repo_root {
+        Some(root) => check_history_depth(root, strict)?,
+        None => eprintln!("warning: not in a git repository; churn is 0 for every function"),
+    }
+    let analysis_progress = make_analysis_progress(show_progress);
+    let reports = analyze_with_progress(
+        path,
+        AnalysisOptions {
+            min_lrs,
+            top_n: None,
+        },
+        Some(resolved_config),
+        Some(analysis_progress.as_ref()),
+    )?;
+    let report = churn::compute_churn_report(repo_root.as_deref(), &reports, window_days, metric);
+    match format {
+        OutputFormat::Text => print!("{}", churn::render_churn_text(&report, top)),
+        OutputFormat::Json => println!("{}", churn::render_churn_json(&report, top)?),
+        OutputFormat::Html
+        | OutputFormat::Jsonl
+        | OutputFormat::Sarif
+        | OutputFormat::Junit
+        | OutputFormat::Treemap
+        | OutputFormat::Markdown
+        | OutputFormat::Csv
+        | OutputFormat::Tree
+        | OutputFormat::Gitlab => {
             unreachable!("validated by validate_analyze_flags")
         }
     }
@@ -790,6 +2698,9 @@ struct SnapshotOutputOpts {
     include_models: bool,
     source_url: Option<String>,
     risk_thresholds: hotspots_core::risk::RiskThresholds,
+    sarif_rules: hotspots_core::sarif::MetricRules,
+    max_results: Option<usize>,
+    budgets: std::collections::BTreeMap<String, usize>,
 }
 
 fn emit_snapshot_output(
@@ -804,6 +2715,14 @@ fn emit_snapshot_output(
         OutputFormat::Text => emit_text_output(snapshot, repo_root, opts),
         OutputFormat::Html => emit_html_output(snapshot, repo_root, analysis_path, opts),
         OutputFormat::Sarif => emit_sarif_output(snapshot, repo_root, opts),
+        OutputFormat::Junit
+        | OutputFormat::Treemap
+        | OutputFormat::Markdown
+        | OutputFormat::Csv
+        | OutputFormat::Tree
+        | OutputFormat::Gitlab => {
+            unreachable!("validated by validate_analyze_flags")
+        }
     }
 }
 
@@ -819,6 +2738,7 @@ fn emit_json_output(
         co_change_window_days,
         co_change_min_count,
         output,
+        max_results,
         ..
     } = opts;
     let aggregates = hotspots_core::aggregates::compute_snapshot_aggregates_with_models(
@@ -830,6 +2750,9 @@ fn emit_json_output(
     );
     if all_functions {
         snapshot.aggregates = Some(aggregates);
+        if let Some(n) = max_results {
+            snapshot.cap_functions(n);
+        }
         write_json_snapshot(snapshot, output)
     } else {
         let agent_output = hotspots_core::aggregates::compute_agent_snapshot_output(
@@ -845,7 +2768,7 @@ fn emit_jsonl_output(snapshot: &mut Snapshot) -> anyhow::Result<()> {
     let stdout = std::io::stdout();
     let mut out = std::io::BufWriter::new(stdout.lock());
     snapshot
-        .write_jsonl_to(&mut out)
+        .write_jsonl_to(&mut out, TOOL_VERSION)
         .context("failed to write snapshot JSONL")
 }
 
@@ -861,8 +2784,12 @@ fn emit_text_output(
         total_function_count,
         co_change_window_days,
         co_change_min_count,
+        budgets,
         ..
     } = opts;
+    if level == Some(OutputLevel::Budget) && budgets.is_empty() {
+        bail_usage!("--level budget requires \"budgets\" in the config file");
+    }
     let aggregates = hotspots_core::aggregates::compute_snapshot_aggregates(
         snapshot,
         repo_root,
@@ -873,11 +2800,18 @@ fn emit_text_output(
         explain::print_file_risk_output(&aggregates.file_risk, top)?;
     } else if level == Some(OutputLevel::Module) {
         explain::print_module_output(&aggregates.modules, top)?;
+    } else if level == Some(OutputLevel::Budget) {
+        let usage = hotspots_core::aggregates::compute_budget_usage(
+            &aggregates.file_risk,
+            &budgets,
+            repo_root,
+        );
+        explain::print_budget_output(&usage, top)?;
     } else if explain {
         let color = std::io::stdout().is_terminal() && std::env::var_os("NO_COLOR").is_none();
         explain::print_explain_output(snapshot, total_function_count, color)?;
     } else {
-        anyhow::bail!(
+        bail_usage!(
             "text format without --explain is not supported for snapshot mode (use --format json or add --explain)"
         );
     }
@@ -929,7 +2863,12 @@ fn emit_sarif_output(
     repo_root: &Path,
     opts: SnapshotOutputOpts,
 ) -> anyhow::Result<()> {
-    let sarif = hotspots_core::sarif::render_sarif(snapshot, repo_root);
+    let sarif = hotspots_core::sarif::render_sarif(
+        snapshot,
+        repo_root,
+        &opts.risk_thresholds,
+        &opts.sarif_rules,
+    );
     if let Some(output_path) = opts.output {
         if let Some(parent) = output_path.parent() {
             std::fs::create_dir_all(parent)
@@ -951,7 +2890,7 @@ fn apply_top_n(
     level: Option<OutputLevel>,
     top: Option<usize>,
 ) {
-    let is_aggregate_level = level == Some(OutputLevel::File) || level == Some(OutputLevel::Module);
+    let is_aggregate_level = level.is_some();
     let is_text = matches!(format, OutputFormat::Text);
     if !is_aggregate_level && (top.is_some() || (is_text && explain)) {
         snapshot.functions.sort_by(|a, b| {
@@ -960,6 +2899,7 @@ fn apply_top_n(
             b_score
                 .partial_cmp(&a_score)
                 .unwrap_or(std::cmp::Ordering::Equal)
+                .then_with(|| a.function_id.cmp(&b.function_id))
         });
         // 0 = show all; None in text+explain defaults to 20
         let limit = match top {
@@ -1075,7 +3015,7 @@ fn write_json_snapshot(snapshot: &Snapshot, output: Option<PathBuf>) -> anyhow::
     if let Some(output_path) = output {
         write_snapshot_json_file(&output_path, |out| {
             snapshot
-                .write_json_to(out)
+                .write_json_to(out, TOOL_VERSION)
                 .context("failed to write snapshot JSON")
         })?;
         eprintln!("JSON report written to: {}", output_path.display());
@@ -1083,7 +3023,7 @@ fn write_json_snapshot(snapshot: &Snapshot, output: Option<PathBuf>) -> anyhow::
         let stdout = std::io::stdout();
         let mut out = std::io::BufWriter::new(stdout.lock());
         snapshot
-            .write_json_to(&mut out)
+            .write_json_to(&mut out, TOOL_VERSION)
             .context("failed to write snapshot JSON")?;
     }
     Ok(())
@@ -1096,7 +3036,7 @@ fn write_json_agent(
     if let Some(output_path) = output {
         write_snapshot_json_file(&output_path, |out| {
             agent_output
-                .write_json_to(out)
+                .write_json_to(out, TOOL_VERSION)
                 .context("failed to write agent snapshot JSON")
         })?;
         eprintln!("JSON report written to: {}", output_path.display());
@@ -1104,7 +3044,7 @@ fn write_json_agent(
         let stdout = std::io::stdout();
         let mut out = std::io::BufWriter::new(stdout.lock());
         agent_output
-            .write_json_to(&mut out)
+            .write_json_to(&mut out, TOOL_VERSION)
             .context("failed to write agent snapshot JSON")?;
     }
     Ok(())
@@ -1126,10 +3066,10 @@ fn emit_delta_output(
 
     match format {
         OutputFormat::Json => {
-            println!("{}", delta_val.to_json()?);
+            println!("{}", delta_val.to_json(TOOL_VERSION)?);
         }
         OutputFormat::Jsonl => {
-            anyhow::bail!("JSONL format is not supported for delta mode (use --mode snapshot)");
+            bail_usage!("JSONL format is not supported for delta mode (use --mode snapshot)");
         }
         OutputFormat::Text => {
             emit_delta_text(delta_val, with_policy)?;
@@ -1138,7 +3078,15 @@ fn emit_delta_output(
             emit_delta_html(delta_val, source_url, output)?;
         }
         OutputFormat::Sarif => {
-            anyhow::bail!("SARIF format is not supported for delta mode (use --mode snapshot)");
+            bail_usage!("SARIF format is not supported for delta mode (use --mode snapshot)");
+        }
+        OutputFormat::Junit
+        | OutputFormat::Treemap
+        | OutputFormat::Markdown
+        | OutputFormat::Csv
+        | OutputFormat::Tree
+        | OutputFormat::Gitlab => {
+            unreachable!("validated by validate_analyze_flags")
         }
     }
 
@@ -1147,7 +3095,7 @@ fn emit_delta_output(
 
 fn emit_delta_text(delta_val: &Delta, with_policy: bool) -> anyhow::Result<()> {
     if !with_policy {
-        anyhow::bail!(
+        bail_usage!(
             "text format is not supported for delta mode without --policy (use --format json)"
         );
     }
@@ -1222,6 +3170,7 @@ pub(crate) fn build_snapshot_via_db(
     touch_mode: TouchMode,
     callgraph_skip_above: Option<usize>,
     skip_touch_metrics: bool,
+    show_progress: bool,
 ) -> anyhow::Result<Snapshot> {
     use hotspots_core::db::TempDb;
     use hotspots_core::snapshot::{AnalysisInfo, CommitInfo, SNAPSHOT_SCHEMA_VERSION};
@@ -1233,7 +3182,9 @@ pub(crate) fn build_snapshot_via_db(
     let commit_info = CommitInfo::from(git_context.clone());
     let sha = commit_info.sha.clone();
 
-    // Phase 1: write reports to DB, then free the Vec (~23 MB).
+    // Phase 1: write reports to DB, then free the Vec (~23 MB). File-level
+    // decision counts do not go through the DB.
+    let top_level_decisions = snapshot::top_level_decisions_by_file(&reports);
     let db = TempDb::new().context("failed to create pipeline TempDb")?;
     db.insert_reports(&commit_info, &reports)
         .context("failed to insert reports into pipeline DB")?;
@@ -1299,6 +3250,9 @@ pub(crate) fn build_snapshot_via_db(
         functions,
         summary: None,
         aggregates: None,
+        truncated: None,
+        total_functions: None,
+        top_level_decisions,
     };
 
     // Phase 5: remaining enrichment (touch, activity risk, percentiles, driver, quadrant).
@@ -1311,7 +3265,7 @@ pub(crate) fn build_snapshot_via_db(
             TouchMode::PerFunction | TouchMode::Hybrid { .. }
         );
         let progress = if needs_progress {
-            Some(make_progress_reporter(total_functions))
+            Some(make_progress_reporter(total_functions, show_progress))
         } else {
             None
         };
@@ -1339,6 +3293,7 @@ pub(crate) fn build_enriched_snapshot(
     touch_mode: TouchMode,
     callgraph_skip_above: Option<usize>,
     skip_touch_metrics: bool,
+    show_progress: bool,
 ) -> anyhow::Result<Snapshot> {
     let git_context =
         git::extract_git_context_at(repo_root).context("failed to extract git context")?;
@@ -1392,7 +3347,7 @@ pub(crate) fn build_enriched_snapshot(
             TouchMode::PerFunction | TouchMode::Hybrid { .. }
         );
         let progress = if needs_progress {
-            Some(make_progress_reporter(total_functions))
+            Some(make_progress_reporter(total_functions, show_progress))
         } else {
             None
         };
@@ -1416,9 +3371,9 @@ pub(crate) fn build_enriched_snapshot(
         .build())
 }
 
-fn make_progress_reporter(total: usize) -> Box<dyn Fn(usize, usize)> {
+fn make_progress_reporter(total: usize, show: bool) -> Box<dyn Fn(usize, usize)> {
     use std::io::IsTerminal;
-    if total == 0 {
+    if total == 0 || !show {
         return Box::new(|_i: usize, _total: usize| {});
     }
     if std::io::stderr().is_terminal() {
@@ -1476,7 +3431,7 @@ pub(crate) fn analyze_and_persist_at_ref(
         min_lrs: None,
         top_n: None,
     };
-    let progress = make_analysis_progress();
+    let progress = make_analysis_progress(true);
     let reports = analyze_with_progress(
         &worktree.path,
         options,
@@ -1496,6 +3451,7 @@ pub(crate) fn analyze_and_persist_at_ref(
         TouchMode::File,
         None,
         false,
+        true,
     )
     .with_context(|| format!("enrichment failed for ref {sha}"))?;
 
@@ -1548,8 +3504,11 @@ fn resolve_touch_mode(
     }
 }
 
-pub(crate) fn make_analysis_progress() -> Box<dyn Fn(usize, usize) + Send + Sync> {
+pub(crate) fn make_analysis_progress(show: bool) -> Box<dyn Fn(usize, usize) + Send + Sync> {
     use std::io::IsTerminal;
+    if !show {
+        return Box::new(|_done: usize, _total: usize| {});
+    }
     if !std::io::stderr().is_terminal() {
         let last_print = std::sync::Mutex::new(std::time::Instant::now());
         return Box::new(move |done: usize, total: usize| {
@@ -0,0 +1,119 @@
+use crate::exit;
+use crate::OutputFormat;
+use anyhow::Context;
+use hotspots_core::bench::BenchReport;
+use std::path::PathBuf;
+
+pub(crate) struct BenchArgs {
+    pub path: PathBuf,
+    pub format: OutputFormat,
+    pub iterations: usize,
+    pub baseline: Option<PathBuf>,
+    pub max_regression: f64,
+}
+
+pub(crate) fn handle_bench(args: BenchArgs) -> anyhow::Result<()> {
+    let BenchArgs {
+        path,
+        format,
+        iterations,
+        baseline,
+        max_regression,
+    } = args;
+
+    if !path.exists() {
+        bail_usage!("Path does not exist: {}", path.display());
+    }
+    if max_regression < 0.0 {
+        bail_usage!("--max-regression must be non-negative (got {max_regression})");
+    }
+    // Read the baseline before timing so a bad path fails fast.
+    let baseline = match baseline {
+        Some(p) => {
+            let json = std::fs::read_to_string(&p)
+                .with_context(|| format!("failed to read baseline: {}", p.display()))?;
+            Some(
+                BenchReport::from_json(&json)
+                    .with_context(|| format!("failed to parse baseline: {}", p.display()))?,
+            )
+        }
+        None => None,
+    };
+
+    let report = hotspots_core::bench::run(&path, iterations)?;
+
+    match format {
+        OutputFormat::Json => {
+            let json = report
+                .to_json()
+                .context("failed to serialize bench report to JSON")?;
+            println!("{}", json);
+        }
+        OutputFormat::Text => print_bench_text_output(&report),
+        OutputFormat::Html
+        | OutputFormat::Jsonl
+        | OutputFormat::Sarif
+        | OutputFormat::Junit
+        | OutputFormat::Treemap
+        | OutputFormat::Markdown
+        | OutputFormat::Csv
+        | OutputFormat::Tree
+        | OutputFormat::Gitlab => {
+            bail_usage!(
+                "HTML/JSONL/SARIF/JUnit/treemap/Markdown/CSV/tree/GitLab format is not supported for bench"
+            );
+        }
+    }
+
+    if let Some(baseline) = baseline {
+        let regressions = report.regressions(&baseline, max_regression);
+        if !regressions.is_empty() {
+            eprintln!("Throughput regression vs baseline:");
+            for msg in &regressions {
+                eprintln!("  {msg}");
+            }
+            std::process::exit(exit::VIOLATIONS);
+        }
+        eprintln!("No throughput regression vs baseline (limit {max_regression:.1}%).");
+    }
+
+    Ok(())
+}
+
+fn print_bench_text_output(report: &BenchReport) {
+    println!(
+        "Bench: {} file(s), {} function(s), {} iteration(s)",
+        report.files, report.functions, report.iterations
+    );
+    println!("{}", "=".repeat(80));
+    println!(
+        "{:<20} {:>8} {:>10} {:>12} {:>12} {:>12}",
+        "Language", "Files", "Functions", "Time (ms)", "Files/s", "Functions/s"
+    );
+    println!("{}", "-".repeat(80));
+    for lang in &report.languages {
+        println!(
+            "{:<20} {:>8} {:>10} {:>12.2} {:>12.1} {:>12.1}",
+            lang.language,
+            lang.files,
+            lang.functions,
+            lang.elapsed_secs * 1000.0,
+            lang.files_per_sec,
+            lang.functions_per_sec
+        );
+    }
+    println!("{}", "-".repeat(80));
+    println!(
+        "{:<20} {:>8} {:>10} {:>12.2} {:>12.1} {:>12.1}",
+        "Total",
+        report.files,
+        report.functions,
+        report.elapsed_secs * 1000.0,
+        report.files_per_sec,
+        report.functions_per_sec
+    );
+    match report.peak_rss_kb {
+        Some(kb) => println!("\nPeak memory (RSS): {:.1} MB", kb as f64 / 1024.0),
+        None => println!("\nPeak memory (RSS): n/a (Linux only)"),
+    }
+}
@@ -4,7 +4,7 @@ use hotspots_core::snapshot;
 
 pub(crate) fn handle_compact(level: u32, dry_run: bool) -> anyhow::Result<()> {
     if level > 2 {
-        anyhow::bail!("compaction level must be 0, 1, or 2 (got {})", level);
+        bail_usage!("compaction level must be 0, 1, or 2 (got {})", level);
     }
 
     let repo_root = find_repo_root(&std::env::current_dir()?)?;
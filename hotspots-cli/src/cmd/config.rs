@@ -1,3 +1,5 @@
+use crate::exit;
+use crate::util::find_repo_root;
 use anyhow::Context;
 use hotspots_core::config;
 use hotspots_core::config::PolicyMode;
@@ -33,11 +35,18 @@ pub(crate) enum ConfigAction {
     },
 }
 
+/// The config `hotspots analyze .` would load here: the `.hotspots.toml`
+/// search walks up from the working directory to its repository root
+fn load_from_working_dir(path: Option<&std::path::Path>) -> anyhow::Result<config::ResolvedConfig> {
+    let working_dir = std::env::current_dir()?;
+    let project_root = find_repo_root(&working_dir).unwrap_or_else(|_| working_dir.clone());
+    config::load_and_resolve_from(&project_root, path, &working_dir)
+}
+
 pub(crate) fn handle_config(action: ConfigAction) -> anyhow::Result<()> {
     match action {
         ConfigAction::Validate { path } => {
-            let project_root = std::env::current_dir()?;
-            let resolved = config::load_and_resolve(&project_root, path.as_deref());
+            let resolved = load_from_working_dir(path.as_deref());
             match resolved {
                 Ok(config) => {
                     if let Some(ref p) = config.config_path {
@@ -48,14 +57,14 @@ pub(crate) fn handle_config(action: ConfigAction) -> anyhow::Result<()> {
                 }
                 Err(e) => {
                     eprintln!("Config validation failed: {:#}", e);
-                    std::process::exit(1);
+                    std::process::exit(exit::USAGE);
                 }
             }
         }
         ConfigAction::Show { path } => {
-            let project_root = std::env::current_dir()?;
-            let resolved = config::load_and_resolve(&project_root, path.as_deref())
-                .context("failed to load configuration")?;
+            let resolved = load_from_working_dir(path.as_deref())
+                .context("failed to load configuration")
+                .map_err(exit::usage)?;
 
             println!("Configuration:");
             if let Some(ref p) = resolved.config_path {
@@ -75,6 +84,19 @@ pub(crate) fn handle_config(action: ConfigAction) -> anyhow::Result<()> {
             println!("  high: {}", resolved.high_threshold);
             println!("  critical: {}", resolved.critical_threshold);
             println!();
+            let severity = &resolved.severity;
+            println!("Severity ({}):", severity.metric.as_str());
+            println!("  low: {}", severity.cutoffs.low);
+            println!("  medium: {}", severity.cutoffs.medium);
+            println!("  high: {}", severity.cutoffs.high);
+            println!("  critical: {}", severity.cutoffs.critical);
+            println!();
+            let god = &resolved.god_functions;
+            println!("God functions (at least {} of):", god.min_matched);
+            for criterion in &god.criteria {
+                println!("  {}: {}", criterion.metric, criterion.threshold);
+            }
+            println!();
             println!("Filters:");
             println!(
                 "  min_lrs: {}",
@@ -90,6 +112,7 @@ pub(crate) fn handle_config(action: ConfigAction) -> anyhow::Result<()> {
                     .map(|v| v.to_string())
                     .unwrap_or_else(|| "none".to_string())
             );
+            println!("  format: {}", resolved.format.as_deref().unwrap_or("text"));
             println!(
                 "  include: {}",
                 if resolved.include.is_some() {
@@ -118,6 +141,25 @@ pub(crate) fn handle_config(action: ConfigAction) -> anyhow::Result<()> {
                 policy_mode_str(resolved.excessive_risk_regression_mode),
                 reason_suffix(resolved.excessive_risk_regression_reason.as_deref())
             );
+            if !resolved.nd_counts.is_empty() {
+                println!();
+                println!("ND counts:");
+                for (language, counts) in &resolved.nd_counts {
+                    let names: Vec<&str> = counts.constructs().iter().map(|c| c.name()).collect();
+                    println!("  {}: [{}]", language, names.join(", "));
+                }
+            }
+            if !resolved.exempt.is_empty() {
+                println!();
+                println!("Exempt:");
+                for exempt in &resolved.exempt {
+                    println!(
+                        "  {}{}",
+                        exempt.function_id,
+                        reason_suffix(exempt.reason.as_deref())
+                    );
+                }
+            }
         }
     }
     Ok(())
@@ -0,0 +1,130 @@
+use crate::exit;
+use crate::util::find_repo_root;
+use crate::OutputFormat;
+use anyhow::Context;
+use hotspots_core::coverage::CoverageReport;
+use std::path::PathBuf;
+
+pub(crate) struct CoverageArgs {
+    pub path: PathBuf,
+    pub format: OutputFormat,
+    pub config_path: Option<PathBuf>,
+    /// Fail below this fraction of attempted source files analyzed.
+    pub min_coverage: Option<f64>,
+}
+
+pub(crate) fn handle_coverage(args: CoverageArgs) -> anyhow::Result<()> {
+    let CoverageArgs {
+        path,
+        format,
+        config_path,
+        min_coverage,
+    } = args;
+
+    if let Some(min) = min_coverage {
+        if !(0.0..=1.0).contains(&min) {
+            bail_usage!("--min-coverage must be between 0.0 and 1.0, got {}", min);
+        }
+    }
+
+    // Absolute paths so config globs match the same way they do for analyze
+    let path: PathBuf = if path.is_relative() {
+        std::env::current_dir()?.join(&path)
+    } else {
+        path
+    }
+    .components()
+    .collect();
+    if !path.exists() {
+        bail_usage!("Path does not exist: {}", path.display());
+    }
+
+    let project_root = find_repo_root(&path).unwrap_or_else(|_| path.clone());
+    let resolved_config =
+        hotspots_core::config::load_and_resolve_from(&project_root, config_path.as_deref(), &path)
+            .context("failed to load configuration")
+            .map_err(exit::usage)?;
+    if let Some(ref p) = resolved_config.config_path {
+        eprintln!("Using config: {}", p.display());
+    }
+
+    let report = hotspots_core::coverage::run(&path, Some(&resolved_config))?;
+
+    match format {
+        OutputFormat::Json => {
+            let json = report
+                .to_json()
+                .context("failed to serialize coverage report to JSON")?;
+            println!("{}", json);
+        }
+        OutputFormat::Text => print_coverage_text_output(&report),
+        OutputFormat::Html
+        | OutputFormat::Jsonl
+        | OutputFormat::Sarif
+        | OutputFormat::Junit
+        | OutputFormat::Treemap
+        | OutputFormat::Markdown
+        | OutputFormat::Csv
+        | OutputFormat::Tree
+        | OutputFormat::Gitlab => {
+            bail_usage!(
+                "HTML/JSONL/SARIF/JUnit/treemap/Markdown/CSV/tree/GitLab format is not supported for coverage"
+            );
+        }
+    }
+
+    // After printing, so a failing run still shows the full report
+    if let Some(min) = min_coverage {
+        report.check_min_coverage(min)?;
+    }
+    Ok(())
+}
+
+fn print_coverage_text_output(report: &CoverageReport) {
+    println!(
+        "Coverage: {} of {} file(s) analyzed ({:.1}%), {} function(s)",
+        report.files_analyzed, report.files_seen, report.analyzed_pct, report.functions
+    );
+    println!(
+        "Source files parsed: {} of {} ({:.1}%)",
+        report.files_analyzed,
+        report.files_analyzed + report.files_skipped.parse_error,
+        report.source_analyzed_pct
+    );
+    println!("{}", "=".repeat(60));
+    println!(
+        "{:<20} {:>8} {:>10} {:>10}",
+        "Language", "Files", "Analyzed", "Functions"
+    );
+    println!("{}", "-".repeat(60));
+    for lang in &report.languages {
+        println!(
+            "{:<20} {:>8} {:>10} {:>10}",
+            lang.language, lang.files, lang.files_analyzed, lang.functions
+        );
+    }
+
+    let skipped = &report.files_skipped;
+    println!("\nSkipped: {} file(s)", skipped.total());
+    for (reason, count) in [
+        ("ignored", skipped.ignored),
+        ("generated", skipped.generated),
+        ("too big", skipped.too_big),
+        ("unsupported", skipped.unsupported),
+        ("parse error", skipped.parse_error),
+    ] {
+        println!("  {:<18} {:>8}", reason, count);
+    }
+
+    if !report.unsupported_extensions.is_empty() {
+        println!("\nUnsupported extensions:");
+        for ext in &report.unsupported_extensions {
+            let name = if ext.extension.is_empty() {
+                "(none)".to_string()
+            } else {
+                format!(".{}", ext.extension)
+            };
+            println!("  {:<18} {:>8}", name, ext.files);
+        }
+    }
+}
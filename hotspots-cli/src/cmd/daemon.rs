@@ -0,0 +1,18 @@
+use std::path::PathBuf;
+
+/// Run `hotspots daemon` until a client sends `shutdown`.
+#[cfg(unix)]
+pub(crate) fn handle_daemon(socket: PathBuf) -> anyhow::Result<()> {
+    use hotspots_core::daemon;
+
+    let listener = daemon::bind(&socket)?;
+    eprintln!("hotspots daemon listening on {}", socket.display());
+    let result = daemon::Daemon::new().serve(&listener);
+    let _ = std::fs::remove_file(&socket);
+    result
+}
+
+#[cfg(not(unix))]
+pub(crate) fn handle_daemon(_socket: PathBuf) -> anyhow::Result<()> {
+    anyhow::bail!("hotspots daemon requires unix domain sockets (not available on this platform)")
+}
@@ -0,0 +1,27 @@
+use crate::util::find_repo_root;
+use crate::OutputFormat;
+use hotspots_core::dead_code;
+use hotspots_core::{AnalysisOptions, ResolvedConfig};
+use std::path::Path;
+
+/// `analyze --dead-code`: list functions with no callers among the analyzed
+/// ones that are neither public API nor entry points.
+pub(crate) fn run(
+    path: &Path,
+    resolved_config: &ResolvedConfig,
+    format: OutputFormat,
+) -> anyhow::Result<()> {
+    // Callers can be anywhere, whatever --top and --min-lrs say
+    let options = AnalysisOptions {
+        min_lrs: None,
+        top_n: None,
+    };
+    let reports = hotspots_core::analyze_with_progress(path, options, Some(resolved_config), None)?;
+    let base = find_repo_root(path).unwrap_or_else(|_| path.to_path_buf());
+    let dead = dead_code::find_dead_code(&reports, &resolved_config.entry_points, &base);
+    match format {
+        OutputFormat::Json => println!("{}", dead_code::render_dead_code_json(&dead)?),
+        _ => print!("{}", dead_code::render_dead_code_text(&dead)),
+    }
+    Ok(())
+}
@@ -1,5 +1,6 @@
 use crate::cmd::analyze::analyze_and_persist_at_ref;
-use crate::util::{find_repo_root, write_html_report};
+use crate::exit;
+use crate::util::{find_repo_root, write_html_report, TOOL_VERSION};
 use crate::OutputFormat;
 use anyhow::Context;
 use hotspots_core::delta::Delta;
@@ -43,7 +44,8 @@ pub(crate) fn handle_diff(args: DiffArgs) -> anyhow::Result<()> {
 
     let resolved_config =
         hotspots_core::config::load_and_resolve(&repo_root, config_path.as_deref())
-            .context("failed to load configuration")?;
+            .context("failed to load configuration")
+            .map_err(exit::usage)?;
 
     // Resolve both refs to full SHAs
     let base_sha = git::resolve_ref_to_sha(&repo_root, &base)
@@ -77,16 +79,17 @@ pub(crate) fn handle_diff(args: DiffArgs) -> anyhow::Result<()> {
                 }
             }
             if any_failed {
-                std::process::exit(2);
+                std::process::exit(exit::INTERNAL);
             }
             eprintln!("\nOnce both snapshots exist, re-run: hotspots diff {base} {head}");
-            std::process::exit(3);
+            std::process::exit(exit::USAGE);
         }
     };
 
     // Compute delta
     let mut delta_val = Delta::new(&head_snapshot, Some(&base_snapshot))
         .context("failed to compute delta between snapshots")?;
+    delta_val.apply_exemptions(&resolved_config.exempt, &repo_root);
 
     // Attach delta aggregates (file-level summaries used by HTML renderer)
     let current_co_change = head_snapshot
@@ -125,6 +128,7 @@ pub(crate) fn handle_diff(args: DiffArgs) -> anyhow::Result<()> {
             score(b)
                 .partial_cmp(&score(a))
                 .unwrap_or(std::cmp::Ordering::Equal)
+                .then_with(|| a.function_id.cmp(&b.function_id))
         });
         delta_val.deltas.truncate(n);
     }
@@ -146,7 +150,7 @@ pub(crate) fn handle_diff(args: DiffArgs) -> anyhow::Result<()> {
     // Render output
     let has_blocking_failures = emit_diff_output(&delta_val, format, policy, output)?;
     if has_blocking_failures {
-        std::process::exit(1);
+        std::process::exit(exit::VIOLATIONS);
     }
 
     Ok(())
@@ -209,11 +213,11 @@ fn emit_diff_output(
             write_or_print(output, &text)?;
         }
         OutputFormat::Json => {
-            let json = delta_val.to_json()?;
+            let json = delta_val.to_json(TOOL_VERSION)?;
             write_or_print(output, &json)?;
         }
         OutputFormat::Jsonl => {
-            let jsonl = delta_val.to_jsonl()?;
+            let jsonl = delta_val.to_jsonl(TOOL_VERSION)?;
             write_or_print(output, &jsonl)?;
         }
         OutputFormat::Html => {
@@ -223,9 +227,15 @@ fn emit_diff_output(
             write_html_report(&output_path, &html)?;
             eprintln!("HTML report written to: {}", output_path.display());
         }
-        OutputFormat::Sarif => {
-            anyhow::bail!(
-                "--format sarif is not supported for diff (use --format json or --format html)"
+        OutputFormat::Sarif
+        | OutputFormat::Junit
+        | OutputFormat::Treemap
+        | OutputFormat::Markdown
+        | OutputFormat::Csv
+        | OutputFormat::Tree
+        | OutputFormat::Gitlab => {
+            bail_usage!(
+                "--format sarif/junit/treemap/markdown/csv/tree/gitlab is not supported for diff (use --format json or --format html)"
             );
         }
     }
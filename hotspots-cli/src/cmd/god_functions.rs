@@ -0,0 +1,27 @@
+use crate::util::find_repo_root;
+use crate::OutputFormat;
+use hotspots_core::god_functions;
+use hotspots_core::{AnalysisOptions, ResolvedConfig};
+use std::path::Path;
+
+/// `analyze --god-functions`: list functions that meet the config's god
+/// function rule.
+pub(crate) fn run(
+    path: &Path,
+    resolved_config: &ResolvedConfig,
+    format: OutputFormat,
+) -> anyhow::Result<()> {
+    // Every function is checked, whatever --top and --min-lrs say
+    let options = AnalysisOptions {
+        min_lrs: None,
+        top_n: None,
+    };
+    let reports = hotspots_core::analyze_with_progress(path, options, Some(resolved_config), None)?;
+    let base = find_repo_root(path).unwrap_or_else(|_| path.to_path_buf());
+    let report = god_functions::find_god_functions(&reports, &resolved_config.god_functions, &base);
+    match format {
+        OutputFormat::Json => println!("{}", god_functions::render_god_functions_json(&report)?),
+        _ => print!("{}", god_functions::render_god_functions_text(&report)),
+    }
+    Ok(())
+}
@@ -0,0 +1,58 @@
+use crate::exit;
+use crate::OutputFormat;
+use anyhow::Context;
+use hotspots_core::metric_history::{self, Recording};
+use hotspots_core::{git, AnalysisOptions, ResolvedConfig};
+use std::path::Path;
+
+pub(crate) struct HistoryOptions {
+    pub record: bool,
+    pub trend: Option<usize>,
+    pub format: OutputFormat,
+}
+
+/// `analyze --record` / `--trend N`: append HEAD's aggregate metrics to the
+/// project's history file, then print the trend over the last N recordings.
+pub(crate) fn run(
+    path: &Path,
+    project_root: &Path,
+    resolved_config: &ResolvedConfig,
+    opts: HistoryOptions,
+) -> anyhow::Result<()> {
+    if opts.record {
+        let git_context = git::extract_git_context_at(project_root)
+            .context("--record needs a git repository: recordings are keyed by commit")
+            .map_err(exit::usage)?;
+        // Aggregates cover every function, whatever --top and --min-lrs say
+        let options = AnalysisOptions {
+            min_lrs: None,
+            top_n: None,
+        };
+        let reports =
+            hotspots_core::analyze_with_progress(path, options, Some(resolved_config), None)?;
+        let recording = Recording::new(
+            &git_context.head_sha,
+            git_context.timestamp,
+            reports,
+            project_root,
+        );
+        metric_history::append_recording(project_root, &recording)?;
+        eprintln!(
+            "Recorded {}: {} functions, total CC {} in {}",
+            recording.commit.get(..8).unwrap_or(&recording.commit),
+            recording.functions,
+            recording.total_cc,
+            metric_history::HISTORY_FILE
+        );
+    }
+
+    if let Some(last) = opts.trend {
+        let recordings = metric_history::read_recordings(project_root)?;
+        let entries = metric_history::trend(&recordings, last);
+        match opts.format {
+            OutputFormat::Json => println!("{}", metric_history::render_trend_json(&entries)?),
+            _ => print!("{}", metric_history::render_trend_text(&entries)),
+        }
+    }
+    Ok(())
+}
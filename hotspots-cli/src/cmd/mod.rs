@@ -1,8 +1,17 @@
 pub(crate) mod analyze;
+pub(crate) mod bench;
 pub(crate) mod compact;
 pub(crate) mod config;
+pub(crate) mod coverage;
+pub(crate) mod daemon;
+pub(crate) mod dead_code;
 pub(crate) mod diff;
+pub(crate) mod god_functions;
+pub(crate) mod history;
 pub(crate) mod init;
+pub(crate) mod outliers;
 pub(crate) mod prune;
+pub(crate) mod summary;
 pub(crate) mod train;
 pub(crate) mod trends;
+pub(crate) mod watch;
@@ -0,0 +1,27 @@
+use crate::util::find_repo_root;
+use crate::OutputFormat;
+use hotspots_core::outliers;
+use hotspots_core::{AnalysisOptions, ResolvedConfig};
+use std::path::Path;
+
+/// `analyze --outliers`: list functions that are statistical outliers on a
+/// metric across the analyzed ones.
+pub(crate) fn run(
+    path: &Path,
+    resolved_config: &ResolvedConfig,
+    format: OutputFormat,
+) -> anyhow::Result<()> {
+    // The distribution covers every function, whatever --top and --min-lrs say
+    let options = AnalysisOptions {
+        min_lrs: None,
+        top_n: None,
+    };
+    let reports = hotspots_core::analyze_with_progress(path, options, Some(resolved_config), None)?;
+    let base = find_repo_root(path).unwrap_or_else(|_| path.to_path_buf());
+    let report = outliers::find_outliers(&reports, &base);
+    match format {
+        OutputFormat::Json => println!("{}", outliers::render_outliers_json(&report)?),
+        _ => print!("{}", outliers::render_outliers_text(&report)),
+    }
+    Ok(())
+}
@@ -7,7 +7,7 @@ pub(crate) fn handle_prune(
     dry_run: bool,
 ) -> anyhow::Result<()> {
     if !unreachable {
-        anyhow::bail!("--unreachable flag must be specified to prune snapshots");
+        bail_usage!("--unreachable flag must be specified to prune snapshots");
     }
 
     let repo_root = find_repo_root(&std::env::current_dir()?)?;
@@ -0,0 +1,28 @@
+use crate::util::find_repo_root;
+use crate::OutputFormat;
+use hotspots_core::summary::{self, SummarySort};
+use hotspots_core::{AnalysisOptions, ResolvedConfig};
+use std::path::Path;
+
+/// `analyze --summary`: print one line per file with its function count,
+/// complexity totals, and riskiest function.
+pub(crate) fn run(
+    path: &Path,
+    resolved_config: &ResolvedConfig,
+    format: OutputFormat,
+    sort: SummarySort,
+) -> anyhow::Result<()> {
+    // Per-file totals cover every function, whatever --top and --min-lrs say
+    let options = AnalysisOptions {
+        min_lrs: None,
+        top_n: None,
+    };
+    let reports = hotspots_core::analyze_with_progress(path, options, Some(resolved_config), None)?;
+    let base = find_repo_root(path).unwrap_or_else(|_| path.to_path_buf());
+    let summaries = summary::file_summaries(&reports, &base, sort);
+    match format {
+        OutputFormat::Json => println!("{}", summary::render_summary_json(&summaries)?),
+        _ => print!("{}", summary::render_summary_text(&summaries)),
+    }
+    Ok(())
+}
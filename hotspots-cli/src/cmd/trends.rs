@@ -19,7 +19,7 @@ pub(crate) fn handle_trends(
     };
 
     if !normalized_path.exists() {
-        anyhow::bail!("Path does not exist: {}", normalized_path.display());
+        bail_usage!("Path does not exist: {}", normalized_path.display());
     }
 
     let repo_root = find_repo_root(&normalized_path)?;
@@ -36,8 +36,18 @@ pub(crate) fn handle_trends(
         OutputFormat::Text => {
             print_trends_text_output(&trends)?;
         }
-        OutputFormat::Html | OutputFormat::Jsonl | OutputFormat::Sarif => {
-            anyhow::bail!("HTML/JSONL/SARIF format is not supported for trends analysis");
+        OutputFormat::Html
+        | OutputFormat::Jsonl
+        | OutputFormat::Sarif
+        | OutputFormat::Junit
+        | OutputFormat::Treemap
+        | OutputFormat::Markdown
+        | OutputFormat::Csv
+        | OutputFormat::Tree
+        | OutputFormat::Gitlab => {
+            bail_usage!(
+                "HTML/JSONL/SARIF/JUnit/treemap/Markdown/CSV/tree/GitLab format is not supported for trends analysis"
+            );
         }
     }
 
@@ -0,0 +1,187 @@
+use anyhow::Context;
+use hotspots_core::incremental::{self, CacheStats, IncrementalCache};
+use hotspots_core::{AnalysisOptions, FunctionRiskReport, ResolvedConfig};
+use notify::{EventKind, RecursiveMode, Watcher};
+use std::collections::BTreeSet;
+use std::io::IsTerminal;
+use std::path::{Path, PathBuf};
+use std::sync::mpsc::{self, Receiver};
+use std::time::Duration;
+
+/// Quiet period after the last change before re-analyzing, so a burst of
+/// saves (or an editor's write-then-rename) triggers a single run
+const DEBOUNCE: Duration = Duration::from_millis(200);
+
+pub(crate) struct WatchOptions {
+    pub min_lrs: Option<f64>,
+    pub top: Option<usize>,
+}
+
+/// `analyze --watch`: analyze `path`, then re-analyze and reprint the top-N
+/// list whenever a source file under it changes, until interrupted.
+pub(crate) fn run(
+    path: &Path,
+    resolved_config: &ResolvedConfig,
+    opts: WatchOptions,
+) -> anyhow::Result<()> {
+    // 0 is the sentinel for "show all"; otherwise default to 20, as without --watch
+    let limit = match opts.top {
+        Some(0) => usize::MAX,
+        Some(n) => n,
+        None => 20,
+    };
+
+    let (tx, rx) = mpsc::channel();
+    let mut watcher =
+        notify::recommended_watcher(move |event: notify::Result<notify::Event>| match event {
+            Ok(event) if !matches!(event.kind, EventKind::Access(_)) => {
+                for changed in event.paths {
+                    let _ = tx.send(changed);
+                }
+            }
+            Ok(_) => {}
+            Err(e) => eprintln!("warning: file watcher error: {e}"),
+        })
+        .context("failed to start file watcher")?;
+    watcher
+        .watch(path, RecursiveMode::Recursive)
+        .with_context(|| format!("failed to watch {}", path.display()))?;
+
+    let cache = IncrementalCache::new();
+    let mut changed = BTreeSet::new();
+    loop {
+        let options = AnalysisOptions {
+            min_lrs: opts.min_lrs,
+            top_n: Some(limit).filter(|&n| n != usize::MAX),
+        };
+        // A failed run (say, the path was removed mid-save) waits for the next change
+        match cache.analyze_path(path, resolved_config, options) {
+            Ok((reports, stats)) => print_update(path, &reports, stats, &changed, limit),
+            Err(e) => eprintln!("error: {e:#}"),
+        }
+        match next_changes(&rx, DEBOUNCE, |p| {
+            incremental::is_analyzed_path(p, resolved_config)
+        }) {
+            Some(paths) => changed = paths,
+            None => return Ok(()),
+        }
+    }
+}
+
+/// Block until a relevant path changes, then keep collecting changes until
+/// none arrive for `quiet`. None once the watcher has shut down.
+fn next_changes(
+    rx: &Receiver<PathBuf>,
+    quiet: Duration,
+    relevant: impl Fn(&Path) -> bool,
+) -> Option<BTreeSet<PathBuf>> {
+    let mut changed = BTreeSet::new();
+    while changed.is_empty() {
+        let path = rx.recv().ok()?;
+        if relevant(&path) {
+            changed.insert(path);
+        }
+    }
+    while let Ok(path) = rx.recv_timeout(quiet) {
+        if relevant(&path) {
+            changed.insert(path);
+        }
+    }
+    Some(changed)
+}
+
+fn print_update(
+    path: &Path,
+    reports: &[FunctionRiskReport],
+    stats: CacheStats,
+    changed: &BTreeSet<PathBuf>,
+    limit: usize,
+) {
+    let terminal = std::io::stdout().is_terminal();
+    if terminal {
+        // Clear the screen so the list updates in place
+        print!("\x1b[2J\x1b[H");
+    }
+    if !changed.is_empty() {
+        let names: Vec<String> = changed.iter().map(|p| p.display().to_string()).collect();
+        println!("Changed: {}", names.join(", "));
+        println!();
+    }
+    let color = terminal && std::env::var_os("NO_COLOR").is_none();
+    print!(
+        "{}",
+        hotspots_core::render_text_grouped(reports, limit, 0, color)
+    );
+    println!(
+        "Watching {} ({} of {} files re-analyzed) · Ctrl-C to stop",
+        path.display(),
+        stats.files - stats.cache_hits,
+        stats.files
+    );
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    fn is_ts(path: &Path) -> bool {
+        path.extension().is_some_and(|e| e == "ts")
+    }
+
+    #[test]
+    fn test_next_changes_debounces_and_filters() {
+        let (tx, rx) = mpsc::channel();
+        tx.send(PathBuf::from("notes.txt")).unwrap();
+        tx.send(PathBuf::from("a.ts")).unwrap();
+        tx.send(PathBuf::from("a.ts")).unwrap();
+        tx.send(PathBuf::from("b.ts")).unwrap();
+        let changed = next_changes(&rx, Duration::from_millis(20), is_ts).unwrap();
+        assert_eq!(
+            changed.into_iter().collect::<Vec<_>>(),
+            [PathBuf::from("a.ts"), PathBuf::from("b.ts")]
+        );
+
+        drop(tx);
+        assert_eq!(next_changes(&rx, Duration::from_millis(20), is_ts), None);
+    }
+
+    /// A simulated change event re-analyzes only the edited file
+    #[test]
+    fn test_file_change_reanalyzes_changed_file() {
+        let dir = tempfile::tempdir().unwrap();
+        let a = dir.path().join("a.ts");
+        let b = dir.path().join("b.ts");
+        std::fs::write(&a, "function a(x: number) { return x; }\n").unwrap();
+        std::fs::write(&b, "function b(x: number) { return x; }\n").unwrap();
+
+        let resolved = ResolvedConfig::defaults().unwrap();
+        let cache = IncrementalCache::new();
+        let analyze = || {
+            let options = AnalysisOptions {
+                min_lrs: None,
+                top_n: None,
+            };
+            cache.analyze_path(dir.path(), &resolved, options).unwrap()
+        };
+        let (_, stats) = analyze();
+        assert_eq!((stats.files, stats.cache_hits), (2, 0));
+
+        std::fs::write(
+            &a,
+            "function a(x: number) { if (x > 0) { return x; } return -x; }\n",
+        )
+        .unwrap();
+        let (tx, rx) = mpsc::channel();
+        tx.send(a.clone()).unwrap();
+        let changed = next_changes(&rx, Duration::from_millis(20), |p| {
+            incremental::is_analyzed_path(p, &resolved)
+        })
+        .unwrap();
+        assert_eq!(changed.into_iter().collect::<Vec<_>>(), [a]);
+
+        let (reports, stats) = analyze();
+        assert_eq!((stats.files, stats.cache_hits), (2, 1));
+        let report = reports.iter().find(|r| r.function == "a").unwrap();
+        assert_eq!(report.metrics.cc, 2);
+    }
+}
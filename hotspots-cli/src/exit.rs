@@ -0,0 +1,89 @@
+//! Process exit codes
+//!
+//! | Code | Meaning |
+//! |---|---|
+//! | 0 | Clean, or `--exit-zero` |
+//! | 1 | Violations: thresholds, blocking policies, regressions |
+//! | 2 | Usage error: bad arguments or flag combinations, invalid config |
+//! | 3 | Internal error, including panics |
+//!
+//! Errors returned from a command exit 3 unless marked with [`usage`] (or
+//! raised with [`bail_usage!`]). Clap exits 2 for argument parse errors on
+//! its own.
+
+pub(crate) const CLEAN: i32 = 0;
+pub(crate) const VIOLATIONS: i32 = 1;
+pub(crate) const USAGE: i32 = 2;
+pub(crate) const INTERNAL: i32 = 3;
+
+/// An error caused by how hotspots was invoked rather than by hotspots itself.
+/// Displays as the wrapped error, so marking one changes only the exit code.
+#[derive(Debug)]
+pub(crate) struct UsageError(anyhow::Error);
+
+impl std::fmt::Display for UsageError {
+    fn fmt(&self, f: &mut std::fmt::Formatter<'_>) -> std::fmt::Result {
+        // Only the top message: `source` continues the chain
+        write!(f, "{}", self.0)
+    }
+}
+
+impl std::error::Error for UsageError {
+    fn source(&self) -> Option<&(dyn std::error::Error + 'static)> {
+        self.0.source()
+    }
+}
+
+/// Mark `e` as a usage error, for `.map_err(exit::usage)`
+pub(crate) fn usage(e: anyhow::Error) -> anyhow::Error {
+    anyhow::Error::new(UsageError(e))
+}
+
+/// `anyhow::bail!` for usage errors
+macro_rules! bail_usage {
+    ($($arg:tt)*) => {
+        return Err($crate::exit::usage(anyhow::anyhow!($($arg)*)))
+    };
+}
+
+/// Exit code for an error a command returned
+pub(crate) fn code_for(e: &anyhow::Error) -> i32 {
+    if e.chain().any(|cause| cause.is::<UsageError>()) {
+        USAGE
+    } else {
+        INTERNAL
+    }
+}
+
+/// End a run that found violations: exit 1, or 0 under `--exit-zero`
+pub(crate) fn violations(exit_zero: bool) -> ! {
+    std::process::exit(if exit_zero { CLEAN } else { VIOLATIONS })
+}
+
+/// Exit 3 after a panic, once the default hook has printed its message,
+/// instead of Rust's 101
+pub(crate) fn install_panic_hook() {
+    let default_hook = std::panic::take_hook();
+    std::panic::set_hook(Box::new(move |info| {
+        default_hook(info);
+        std::process::exit(INTERNAL);
+    }));
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn test_usage_errors_exit_2_even_under_context() {
+        let e = usage(anyhow::anyhow!("--policy requires --mode delta"));
+        assert_eq!(e.to_string(), "--policy requires --mode delta");
+        assert_eq!(code_for(&e), USAGE);
+        assert_eq!(code_for(&e.context("while analyzing")), USAGE);
+    }
+
+    #[test]
+    fn test_other_errors_exit_3() {
+        assert_eq!(code_for(&anyhow::anyhow!("disk full")), INTERNAL);
+    }
+}
@@ -6,6 +6,8 @@
 // - Deterministic output ordering
 // - Identical input yields byte-for-byte identical output
 
+#[macro_use]
+mod exit;
 mod cmd;
 mod output;
 mod util;
@@ -21,6 +23,10 @@ use std::path::PathBuf;
 )]
 #[command(version = env!("HOTSPOTS_VERSION"))]
 struct Cli {
+    /// Send `analyze` requests to a running `hotspots daemon` on this unix socket
+    #[arg(long, value_name = "PATH")]
+    daemon_socket: Option<PathBuf>,
+
     #[command(subcommand)]
     command: Commands,
 }
@@ -32,11 +38,11 @@ enum Commands {
         /// Path to source file or directory
         path: PathBuf,
 
-        /// Output format
-        #[arg(long, default_value = "text")]
-        format: OutputFormat,
+        /// Output format (default: the config file's `format`, else text)
+        #[arg(long)]
+        format: Option<OutputFormat>,
 
-        /// Output mode (snapshot, delta, or models)
+        /// Output mode (snapshot, delta, models, resolvers, or churn)
         #[arg(long)]
         mode: Option<OutputMode>,
 
@@ -52,10 +58,41 @@ enum Commands {
         #[arg(long)]
         min_lrs: Option<f64>,
 
+        /// Show only functions at or above this severity, rated on the config's
+        /// `[severity]` scale (default: LRS with cutoffs 1.5 / 3 / 6 / 9)
+        #[arg(long, value_name = "LEVEL")]
+        min_severity: Option<SeverityLevel>,
+
         /// Path to config file (default: auto-discover)
         #[arg(long)]
         config: Option<PathBuf>,
 
+        /// Analyze only files matching GLOB, relative to the repo root (repeatable;
+        /// replaces the config's `include`)
+        #[arg(long, value_name = "GLOB")]
+        include: Vec<String>,
+
+        /// Skip files matching GLOB, relative to the repo root (repeatable; added to
+        /// the config's `exclude` and `.hotspotsignore`)
+        #[arg(long, value_name = "GLOB")]
+        exclude: Vec<String>,
+
+        /// Also analyze paths ignored by `.gitignore` files
+        #[arg(long)]
+        no_gitignore: bool,
+
+        /// Analyze only the files listed in FILE, one path per line (`-` reads stdin),
+        /// instead of walking PATH; missing files are skipped with a warning. Requires
+        /// no --mode
+        #[arg(long, value_name = "FILE")]
+        files_from: Option<PathBuf>,
+
+        /// Analyze only the files that differ from REF (`git diff --name-only`), by
+        /// default the merge-base with the default branch. Deleted files are skipped
+        /// and renamed files analyzed at their new path. Requires no --mode
+        #[arg(long, value_name = "REF", num_args = 0..=1)]
+        changed: Option<Option<String>>,
+
         /// Output file path (for HTML format, default: .hotspots/report.html)
         #[arg(long)]
         output: Option<PathBuf>,
@@ -72,7 +109,9 @@ enum Commands {
         #[arg(long)]
         no_persist: bool,
 
-        /// Output level for text format: file shows a ranked file risk table
+        /// Output level for text format: file shows a ranked file risk table,
+        /// module shows module instability, budget shows headroom against the
+        /// config `budgets`
         #[arg(long, value_name = "LEVEL")]
         level: Option<OutputLevel>,
 
@@ -138,6 +177,252 @@ enum Commands {
         /// not an automatic fallback when `hotspots train` fails its label threshold.
         #[arg(long)]
         cold_start: bool,
+
+        /// Emit only what changed since a previous `--format json` results file:
+        /// added, removed, and changed functions with old and new metrics
+        #[arg(long, value_name = "PREV_JSON")]
+        diff_against: Option<PathBuf>,
+
+        /// Save every function's metrics to PATH as a baseline for --baseline
+        /// (repo-relative paths, so the file can be committed)
+        #[arg(long, value_name = "PATH", conflicts_with = "baseline")]
+        save_baseline: Option<PathBuf>,
+
+        /// Report only functions that regressed against a --save-baseline file (or
+        /// any previous `--format json` results); exit 1 if any did
+        #[arg(long, value_name = "PATH")]
+        baseline: Option<PathBuf>,
+
+        /// Print every function with its current metrics and the signed change since
+        /// PREV_JSON (any previous `--format json` results, baseline, or snapshot),
+        /// marking added and removed functions. Requires --format text or json
+        #[arg(long, value_name = "PREV_JSON")]
+        compare: Option<PathBuf>,
+
+        /// Hide functions whose metrics did not change (requires --compare)
+        #[arg(long)]
+        changed_only: bool,
+
+        /// Emit at most N function records (riskiest first) in JSON output, with
+        /// `truncated` and `total_functions` so consumers know data was cut.
+        /// Requires --format json; with --mode snapshot also requires --all-functions
+        #[arg(long, value_name = "N")]
+        max_results: Option<usize>,
+
+        /// Glob (relative to PATH) selecting GraphQL resolver files; every top-level
+        /// object literal in a matching file is read as a resolver map (--mode resolvers)
+        #[arg(long, value_name = "GLOB")]
+        resolver_glob: Option<String>,
+
+        /// GraphQL schema (SDL) used to flag resolvers whose Type.field it does not
+        /// declare (--mode resolvers)
+        #[arg(long, value_name = "SDL")]
+        schema: Option<PathBuf>,
+
+        /// Fail instead of warning when git history is shallow (e.g. `clone --depth 1`)
+        /// and churn/touch metrics would be misleading
+        #[arg(long)]
+        strict: bool,
+
+        /// Follow symlinks and analyze each underlying file once, reporting it under
+        /// one path with the others listed as `aliases` (overrides config)
+        #[arg(long)]
+        dedup_symlinks: bool,
+
+        /// Report only public API functions: exported (JS/TS), `pub` (Rust),
+        /// `public` (Java/C#), capitalized (Go), not `_`-prefixed (Python). Requires no --mode
+        #[arg(long)]
+        public_only: bool,
+
+        /// Also analyze test code, which is skipped by default: `*.test.*`/`*.spec.*`
+        /// and `__tests__` (JS/TS), `test_*.py`/`*_test.py`/`conftest.py` (Python),
+        /// `*_test.go` and `TestXxx(t *testing.T)` (Go), `#[test]`/`#[cfg(test)]` (Rust)
+        #[arg(long)]
+        include_tests: bool,
+
+        /// Compute Halstead metrics (operators, operands, volume, difficulty, effort)
+        /// for Go, Java, Python, C#, C, C++, Swift, PHP, Scala, Dart, Elixir, Lua,
+        /// Bash, Zig, Haskell, and Perl functions; shown in JSON output
+        #[arg(long)]
+        halstead: bool,
+
+        /// Split each function's LOC into source, comment, and blank lines for Go,
+        /// Java, Python, C#, C, C++, Swift, PHP, Scala, Dart, Elixir, Lua, Bash,
+        /// Zig, Haskell, and Perl functions; shown in JSON output
+        #[arg(long)]
+        line_counts: bool,
+
+        /// Count each function's callers among the analyzed functions (fan-in),
+        /// shown as `fi` in JSON output; not available with --format jsonl streaming
+        #[arg(long)]
+        fan_in: bool,
+
+        /// Order functions by KEY instead of LRS: `cc`, `nd`, `fo`, `ns`, `cognitive`, or
+        /// `risk` (LRS) list the highest first (see --asc); text output becomes one
+        /// ranked table. `maintainability` lists the lowest maintainability index first
+        /// (implies --halstead), `fi` the most-called first (implies --fan-in),
+        /// `risk-score` the highest composite risk score first (weights from config
+        /// `risk_score`); these three need --format json. No --mode
+        #[arg(long, value_enum, value_name = "KEY")]
+        sort: Option<SortKey>,
+
+        /// Skip the first N functions of the list (after --sort, before --top), to page
+        /// through it with --top; text or JSON output, no --mode
+        #[arg(long, value_name = "N")]
+        offset: Option<usize>,
+
+        /// List the lowest values of the --sort key (LRS without --sort) first
+        #[arg(long, conflicts_with = "desc")]
+        asc: bool,
+
+        /// List the highest values of the --sort key (LRS without --sort) first, the
+        /// default for cc, nd, fo, ns, cognitive, and risk
+        #[arg(long)]
+        desc: bool,
+
+        /// Exit 1 if any function declares more than N parameters (receivers such as
+        /// `self` excluded), listing them on stderr after the report (no --mode)
+        #[arg(long, value_name = "N")]
+        max_params: Option<u32>,
+
+        /// Report threshold violations, blocking policy failures, and regressions
+        /// but exit 0 anyway; errors still exit 2 or 3
+        #[arg(long)]
+        exit_zero: bool,
+
+        /// JUnit testcase granularity: `function` (one testcase per function in a
+        /// testsuite per file, failing on any metric over threshold) or `metric`
+        /// (one per function and metric).
+        /// Requires --format junit [default: function]
+        #[arg(long, value_enum)]
+        junit_granularity: Option<JunitGranularity>,
+
+        /// Roll function metrics up into larger units: `component` sums CC per Vue
+        /// single-file component and React function component; `dir` prints a tree of
+        /// directories with total CC, mean CC, and max ND (text or json, no --mode)
+        #[arg(long, value_enum, value_name = "UNIT")]
+        group_by: Option<GroupBy>,
+
+        /// Print only functions that regressed against the delta baseline, as a
+        /// Markdown list for PR comments; exit 1 if any did (requires --mode delta)
+        #[arg(long)]
+        regressions_only: bool,
+
+        /// Explain each regressed function's CC change by construct, e.g.
+        /// "+1 if, +1 &&", from the baseline's CC breakdown (requires --mode delta)
+        #[arg(long)]
+        explain_diff: bool,
+
+        /// Churn window for --mode churn: commits in the N days (`90d`) or weeks
+        /// (`12w`) before HEAD count toward a function's churn [default: 90d]
+        #[arg(long, value_name = "WINDOW")]
+        since: Option<String>,
+
+        /// Complexity that --mode churn multiplies churn by [default: cc]
+        #[arg(long, value_enum)]
+        churn_metric: Option<ChurnMetric>,
+
+        /// SQL dialect for `.sql` stored procedures: `postgres` (PL/pgSQL) or `tsql`.
+        /// Overrides config `sql_dialect` [default: detected per file]
+        #[arg(long, value_enum)]
+        sql_dialect: Option<SqlDialect>,
+
+        /// How switch statements count toward CC: `cases` (one per clause),
+        /// `statement` (one per switch), or `mccabe` (one per edge out of the
+        /// switch beyond the first). Overrides config `cc_mode` [default: cases]
+        #[arg(long, value_enum)]
+        cc_mode: Option<CcMode>,
+
+        /// Leave Go method calls (`c.Add(x)`) out of FO, counting only free and
+        /// package function calls. Overrides config `fo_methods`
+        #[arg(long)]
+        no_fo_methods: bool,
+
+        /// Keep running: re-analyze changed files (debounced, unchanged files reuse
+        /// cached results) and reprint the top-N list on every change. Text output,
+        /// no --mode; stop with Ctrl-C
+        #[arg(long)]
+        watch: bool,
+
+        /// Analyze every file, neither loading nor updating the analysis cache
+        /// (`.hotspots/analysis-cache.json.zst`, which lets unchanged files skip parsing)
+        #[arg(long)]
+        no_cache: bool,
+
+        /// Delete the analysis cache before analyzing, so every file is reparsed
+        #[arg(long)]
+        clear_cache: bool,
+
+        /// Append HEAD's function count, total CC, and riskiest function to
+        /// `.hotspots-history.jsonl` at the project root instead of printing a report
+        #[arg(long)]
+        record: bool,
+
+        /// Print how total CC and the riskiest function changed over the last N
+        /// recordings in `.hotspots-history.jsonl` (after recording, with --record)
+        #[arg(long, value_name = "N")]
+        trend: Option<usize>,
+
+        /// List dead-code candidates instead of a report: functions with no callers
+        /// among the analyzed ones that are not public API or entry points
+        /// (`main`, `init`, tests, or the config's `entry_points`). Calls are
+        /// matched by name, so review before deleting
+        #[arg(long)]
+        dead_code: bool,
+
+        /// List statistical outliers instead of a report: functions above the mean
+        /// plus two standard deviations of LOC, CC, cognitive, ND, FO, or NS across
+        /// the analyzed functions, with their z-score and percentile
+        #[arg(long)]
+        outliers: bool,
+
+        /// List god functions instead of a report: functions that meet several size
+        /// and branching thresholds at once (default: LOC >= 60, CC >= 15, and
+        /// FO >= 10; see the config's `god_functions`), with the thresholds each met
+        #[arg(long)]
+        god_functions: bool,
+
+        /// Print one line per file instead of one per function: function count,
+        /// total and mean CC, max CC, max ND, and the file's riskiest function
+        /// (text or json, no --mode)
+        #[arg(long)]
+        summary: bool,
+
+        /// What --summary ranks files by: `total` CC or the `max` CC of any one
+        /// function [default: total]
+        #[arg(long, value_enum, value_name = "KEY")]
+        summary_sort: Option<SummarySort>,
+
+        /// Report each Go closure as a function of its own (`Outer.go`) and leave
+        /// closure and nested function bodies out of the enclosing function's metrics
+        /// (Go, JavaScript, TypeScript)
+        #[arg(long)]
+        separate_closures: bool,
+
+        /// How --separate-closures names Go closures: `context` (after the variable,
+        /// call, or `go`/`defer`/`return` they belong to, e.g. `Outer.go`), `line`
+        /// (`Outer.line15`), or `index` (`Outer.func1`, as the Go runtime does).
+        /// JavaScript and TypeScript closures stay `<anonymous>`. Overrides config
+        /// `anon_naming` [default: context]
+        #[arg(long, value_enum)]
+        anon_naming: Option<AnonNaming>,
+
+        /// Break NS down by kind of exit (`return`, `throw`, `break`, `continue`,
+        /// `defer`, `goto`) into `metrics.ns_breakdown` in JSON output
+        #[arg(long)]
+        ns_breakdown: bool,
+
+        /// Print nothing when clean; otherwise only the offending functions, one per
+        /// line (`file:line function cc=N`), and exit 1. For git hooks; pairs with
+        /// --files-from. Requires no --mode and text format
+        #[arg(long)]
+        quiet: bool,
+
+        /// Hide the progress shown on stderr while files are analyzed (a bar on a
+        /// terminal, periodic `Analyzing: N/M files` lines otherwise). Progress never
+        /// goes to stdout, so it does not affect any --format
+        #[arg(long)]
+        no_progress: bool,
     },
     /// Prune unreachable snapshots
     Prune {
@@ -281,6 +566,51 @@ enum Commands {
         #[arg(long, short = 'q', default_value = "false")]
         quiet: bool,
     },
+    /// Measure parser/analyzer throughput and peak memory on a path
+    Bench {
+        /// Path to source file or directory
+        path: PathBuf,
+
+        /// Output format (text or json)
+        #[arg(long, default_value = "text")]
+        format: OutputFormat,
+
+        /// Number of sequential passes over the files; throughput is the per-pass mean
+        #[arg(long, default_value = "3")]
+        iterations: usize,
+
+        /// Previous `hotspots bench --format json` output to compare against
+        #[arg(long, value_name = "BENCH_JSON")]
+        baseline: Option<PathBuf>,
+
+        /// Exit 1 when throughput drops by more than this percent vs --baseline
+        #[arg(long, default_value = "20")]
+        max_regression: f64,
+    },
+    /// Report how many files were analyzed vs skipped, and why
+    Coverage {
+        /// Path to source file or directory
+        path: PathBuf,
+
+        /// Output format (text or json)
+        #[arg(long, default_value = "text")]
+        format: OutputFormat,
+
+        /// Path to config file (default: auto-discover)
+        #[arg(long)]
+        config: Option<PathBuf>,
+
+        /// Exit 1 if less than this fraction (0.0-1.0) of the source files analysis
+        /// attempted were analyzed, i.e. too many failed to parse
+        #[arg(long, value_name = "FRACTION")]
+        min_coverage: Option<f64>,
+    },
+    /// Serve analysis requests on a unix socket, keeping results cached between requests
+    Daemon {
+        /// Unix socket path to listen on
+        #[arg(long, value_name = "PATH")]
+        socket: PathBuf,
+    },
 }
 
 #[derive(Clone, Copy, clap::ValueEnum)]
@@ -290,6 +620,102 @@ pub(crate) enum OutputFormat {
     Html,
     Jsonl,
     Sarif,
+    Junit,
+    Treemap,
+    Markdown,
+    Csv,
+    Tree,
+    Gitlab,
+}
+
+#[derive(Clone, Copy, PartialEq, clap::ValueEnum)]
+pub(crate) enum JunitGranularity {
+    Function,
+    Metric,
+}
+
+#[derive(Clone, Copy, PartialEq, clap::ValueEnum)]
+pub(crate) enum GroupBy {
+    Component,
+    Dir,
+}
+
+#[derive(Clone, Copy, PartialEq, clap::ValueEnum)]
+pub(crate) enum SummarySort {
+    Total,
+    Max,
+}
+
+#[derive(Clone, Copy, PartialEq, clap::ValueEnum)]
+pub(crate) enum SortKey {
+    Risk,
+    Cc,
+    Nd,
+    Fo,
+    Ns,
+    Cognitive,
+    Maintainability,
+    Fi,
+    RiskScore,
+}
+
+impl SortKey {
+    /// The report metric this key orders by; None for the keys that need
+    /// extra data (maintainability index, fan-in, composite risk score)
+    pub(crate) fn metric(self) -> Option<hotspots_core::report::SortMetric> {
+        use hotspots_core::report::SortMetric;
+        match self {
+            SortKey::Risk => Some(SortMetric::Lrs),
+            SortKey::Cc => Some(SortMetric::Cc),
+            SortKey::Nd => Some(SortMetric::Nd),
+            SortKey::Fo => Some(SortMetric::Fo),
+            SortKey::Ns => Some(SortMetric::Ns),
+            SortKey::Cognitive => Some(SortMetric::Cognitive),
+            SortKey::Maintainability | SortKey::Fi | SortKey::RiskScore => None,
+        }
+    }
+}
+
+#[derive(Clone, Copy, PartialEq, clap::ValueEnum)]
+pub(crate) enum SeverityLevel {
+    Ok,
+    Low,
+    Medium,
+    High,
+    Critical,
+}
+
+impl SeverityLevel {
+    pub(crate) fn severity(self) -> hotspots_core::severity::Severity {
+        use hotspots_core::severity::Severity;
+        match self {
+            SeverityLevel::Ok => Severity::Ok,
+            SeverityLevel::Low => Severity::Low,
+            SeverityLevel::Medium => Severity::Medium,
+            SeverityLevel::High => Severity::High,
+            SeverityLevel::Critical => Severity::Critical,
+        }
+    }
+}
+
+#[derive(Clone, Copy, PartialEq, clap::ValueEnum)]
+pub(crate) enum SqlDialect {
+    Postgres,
+    Tsql,
+}
+
+#[derive(Clone, Copy, PartialEq, clap::ValueEnum)]
+pub(crate) enum CcMode {
+    Cases,
+    Statement,
+    Mccabe,
+}
+
+#[derive(Clone, Copy, PartialEq, clap::ValueEnum)]
+pub(crate) enum AnonNaming {
+    Index,
+    Line,
+    Context,
 }
 
 #[derive(Clone, Copy, PartialEq, clap::ValueEnum)]
@@ -297,16 +723,40 @@ pub(crate) enum OutputMode {
     Snapshot,
     Delta,
     Models,
+    Resolvers,
+    Churn,
+}
+
+#[derive(Clone, Copy, PartialEq, clap::ValueEnum)]
+pub(crate) enum ChurnMetric {
+    Cc,
+    Cognitive,
 }
 
 #[derive(Clone, Copy, PartialEq, clap::ValueEnum)]
 pub(crate) enum OutputLevel {
     File,
     Module,
+    Budget,
 }
 
-fn main() -> anyhow::Result<()> {
+fn main() {
+    exit::install_panic_hook();
     let cli = Cli::parse();
+    let code = match run(cli) {
+        Ok(()) => exit::CLEAN,
+        Err(e) => {
+            eprintln!("Error: {:?}", e);
+            exit::code_for(&e)
+        }
+    };
+    std::process::exit(code);
+}
+
+fn run(cli: Cli) -> anyhow::Result<()> {
+    if cli.daemon_socket.is_some() && !matches!(cli.command, Commands::Analyze { .. }) {
+        bail_usage!("--daemon-socket is only supported with the analyze command");
+    }
 
     match cli.command {
         Commands::Analyze {
@@ -316,7 +766,13 @@ fn main() -> anyhow::Result<()> {
             policy,
             top,
             min_lrs,
+            min_severity,
             config: config_path,
+            include,
+            exclude,
+            no_gitignore,
+            files_from,
+            changed,
             output,
             explain,
             force,
@@ -334,32 +790,132 @@ fn main() -> anyhow::Result<()> {
             hybrid_touches,
             skip_gate,
             cold_start,
-        } => cmd::analyze::handle_analyze(AnalyzeArgs {
-            path,
-            format,
-            mode,
-            policy,
-            top,
-            min_lrs,
-            config_path,
-            output,
-            explain,
-            force,
-            no_persist,
-            level,
-            per_function_touches,
-            no_per_function_touches,
-            skip_touch_metrics,
-            all_functions,
-            include_models,
-            explain_patterns,
-            source_url,
-            jobs,
-            callgraph_skip_above,
-            hybrid_touches,
-            skip_gate,
-            cold_start,
-        })?,
+            diff_against,
+            max_results,
+            resolver_glob,
+            schema,
+            strict,
+            dedup_symlinks,
+            public_only,
+            include_tests,
+            halstead,
+            line_counts,
+            fan_in,
+            sort,
+            max_params,
+            exit_zero,
+            junit_granularity,
+            group_by,
+            regressions_only,
+            explain_diff,
+            sql_dialect,
+            cc_mode,
+            no_fo_methods,
+            since,
+            churn_metric,
+            save_baseline,
+            baseline,
+            compare,
+            changed_only,
+            watch,
+            no_cache,
+            clear_cache,
+            record,
+            trend,
+            dead_code,
+            outliers,
+            god_functions,
+            summary,
+            summary_sort,
+            separate_closures,
+            anon_naming,
+            ns_breakdown,
+            quiet,
+            no_progress,
+            offset,
+            asc,
+            desc,
+        } => {
+            let config = cmd::analyze::load_config(&path, config_path.as_deref())?;
+            cmd::analyze::handle_analyze(AnalyzeArgs {
+                format: cmd::analyze::resolve_format(format, &config)?,
+                config,
+                path,
+                mode,
+                policy,
+                top,
+                min_lrs,
+                min_severity,
+                include,
+                exclude,
+                no_gitignore,
+                files_from,
+                changed,
+                output,
+                explain,
+                force,
+                no_persist,
+                level,
+                per_function_touches,
+                no_per_function_touches,
+                skip_touch_metrics,
+                all_functions,
+                include_models,
+                explain_patterns,
+                source_url,
+                jobs,
+                callgraph_skip_above,
+                hybrid_touches,
+                skip_gate,
+                cold_start,
+                diff_against,
+                max_results,
+                resolver_glob,
+                schema,
+                strict,
+                dedup_symlinks,
+                public_only,
+                include_tests,
+                halstead,
+                line_counts,
+                fan_in,
+                sort,
+                max_params,
+                exit_zero,
+                junit_granularity,
+                group_by,
+                daemon_socket: cli.daemon_socket,
+                regressions_only,
+                explain_diff,
+                sql_dialect,
+                cc_mode,
+                no_fo_methods,
+                since,
+                churn_metric,
+                save_baseline,
+                baseline,
+                compare,
+                changed_only,
+                watch,
+                no_cache,
+                clear_cache,
+                record,
+                trend,
+                dead_code,
+                outliers,
+                god_functions,
+                summary,
+                summary_sort,
+                separate_closures,
+                anon_naming,
+                ns_breakdown,
+                quiet,
+                no_progress,
+                offset,
+                asc,
+                desc,
+            })?
+        }
         Commands::Prune {
             unreachable,
             older_than,
@@ -418,6 +974,31 @@ fn main() -> anyhow::Result<()> {
             yes,
             quiet,
         })?,
+        Commands::Bench {
+            path,
+            format,
+            iterations,
+            baseline,
+            max_regression,
+        } => cmd::bench::handle_bench(cmd::bench::BenchArgs {
+            path,
+            format,
+            iterations,
+            baseline,
+            max_regression,
+        })?,
+        Commands::Coverage {
+            path,
+            format,
+            config,
+            min_coverage,
+        } => cmd::coverage::handle_coverage(cmd::coverage::CoverageArgs {
+            path,
+            format,
+            config_path: config,
+            min_coverage,
+        })?,
+        Commands::Daemon { socket } => cmd::daemon::handle_daemon(socket)?,
     }
 
     Ok(())
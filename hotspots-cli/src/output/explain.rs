@@ -25,10 +25,13 @@ pub(crate) fn print_file_risk_output(
     for (i, view) in file_risk.iter().take(display_count).enumerate() {
         println!("#{} {}", i + 1, view.file);
         println!(
-            "   Functions: {} | LOC: {} | Max CC: {} | Avg CC: {:.1}",
-            view.function_count, view.loc, view.max_cc, view.avg_cc
+            "   Functions: {} | LOC: {} | Max CC: {} | Avg CC: {:.1} | File CC: {}",
+            view.function_count, view.loc, view.max_cc, view.avg_cc, view.file_cc
         );
         println!("   Risk Score: {:.2}", view.file_risk_score);
+        if let Some(mi) = view.maintainability {
+            println!("   Maintainability: {:.1}", mi);
+        }
         if view.file_churn > 0 {
             println!("   Churn: {} lines changed (30 days)", view.file_churn);
         }
@@ -104,6 +107,52 @@ pub(crate) fn print_module_output(
     Ok(())
 }
 
+/// Print complexity budget usage, least headroom first.
+pub(crate) fn print_budget_output(
+    usage: &[hotspots_core::aggregates::BudgetUsage],
+    top: Option<usize>,
+) -> anyhow::Result<()> {
+    let total = usage.len();
+    let display_count = top.map(|n| n.min(total)).unwrap_or(total);
+    let title = if display_count < total {
+        format!("Top {} Modules by Budget Used", display_count)
+    } else {
+        "Complexity Budgets".to_string()
+    };
+
+    println!("{}", title);
+    println!("{}", "=".repeat(80));
+    println!();
+    println!(
+        "{:<3} {:<40} {:>5} {:>8} {:>8} {:>9} {:>6}",
+        "#", "module", "files", "total_cc", "budget", "remaining", "used"
+    );
+    println!("{}", "-".repeat(85));
+
+    for (i, u) in usage.iter().take(display_count).enumerate() {
+        println!(
+            "{:<3} {:<40} {:>5} {:>8} {:>8} {:>9} {:>5.0}%",
+            i + 1,
+            truncate_string(&u.module, 40),
+            u.file_count,
+            u.total_cc,
+            u.budget,
+            u.remaining,
+            u.used_pct,
+        );
+    }
+
+    println!("{}", "-".repeat(85));
+    println!("Showing {}/{} budgets", display_count, total);
+
+    let over_count = usage.iter().filter(|u| u.remaining < 0).count();
+    if over_count > 0 {
+        println!("Over budget: {}", over_count);
+    }
+
+    Ok(())
+}
+
 /// Print human-readable risk explanations for top functions.
 pub(crate) fn print_explain_output(
     snapshot: &hotspots_core::snapshot::Snapshot,
@@ -195,6 +244,18 @@ pub(crate) fn print_explain_output(
             if let Some(exp) = &f.explanation {
                 println!("         \u{2726} {}", exp);
             }
+            if let Some(nd_line) = f.metrics.nd_line {
+                println!(
+                    "         deepest nesting (ND {}): {}:{}",
+                    f.metrics.nd,
+                    rel_path(&f.file),
+                    nd_line
+                );
+            }
+            print!(
+                "{}",
+                hotspots_core::report::render_cc_lines(f.line, &f.metrics)
+            );
         }
         println!();
     };
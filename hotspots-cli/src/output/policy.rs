@@ -153,6 +153,9 @@ fn write_failing_functions_section(
             delta_lrs,
             policies.join(", ")
         )?;
+        if let Some(ref explanation) = entry.explanation {
+            writeln!(out, "  CC change: {}", explanation)?;
+        }
     }
     Ok(())
 }
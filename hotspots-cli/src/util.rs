@@ -1,6 +1,10 @@
 use anyhow::Context;
 use std::path::{Path, PathBuf};
 
+/// Version of this hotspots binary (from `git describe`, see build.rs),
+/// written as `tool_version` in JSON and JSONL output.
+pub(crate) const TOOL_VERSION: &str = env!("HOTSPOTS_VERSION");
+
 /// Truncate a string to at most `max_len` characters, appending `...` if truncated.
 pub(crate) fn truncate_string(s: &str, max_len: usize) -> String {
     if s.len() <= max_len {
@@ -0,0 +1,118 @@
+//! `--compare` tests
+//!
+//! Saves the `--format json` results of one version of a project, edits it,
+//! and compares the new analysis against the saved results, as a reviewer
+//! checking a pull request would.
+
+use std::path::Path;
+use std::process::{Command, Output};
+use tempfile::TempDir;
+
+const BEFORE: &str = r#"function keep(a: number): number {
+  return a;
+}
+
+function add(a: number, b: number): number {
+  return a + b;
+}
+
+function gone(): number {
+  return 1;
+}
+"#;
+
+const AFTER: &str = r#"function keep(a: number): number {
+  return a;
+}
+
+function add(a: number, b: number): number {
+  if (a > b) {
+    return a;
+  }
+  return a + b;
+}
+"#;
+
+const ADDED: &str = "export function added(): number {\n  return 2;\n}\n";
+
+fn hotspots(dir: &Path, args: &[&str]) -> Output {
+    Command::new(env!("CARGO_BIN_EXE_hotspots"))
+        .args(args)
+        .current_dir(dir)
+        .output()
+        .expect("failed to run hotspots")
+}
+
+/// A project analyzed at BEFORE into `before.json`, then edited to AFTER
+fn edited_project() -> TempDir {
+    let dir = TempDir::new().unwrap();
+    std::fs::create_dir(dir.path().join("src")).unwrap();
+    std::fs::write(dir.path().join("src/main.ts"), BEFORE).unwrap();
+    let before = hotspots(dir.path(), &["analyze", ".", "--format", "json"]);
+    assert!(before.status.success());
+    std::fs::write(dir.path().join("before.json"), before.stdout).unwrap();
+
+    std::fs::write(dir.path().join("src/main.ts"), AFTER).unwrap();
+    std::fs::write(dir.path().join("src/added.ts"), ADDED).unwrap();
+    dir
+}
+
+#[test]
+fn test_compare_lists_every_function_with_deltas() {
+    let dir = edited_project();
+    let output = hotspots(dir.path(), &["analyze", ".", "--compare", "before.json"]);
+    assert!(output.status.success());
+    let stdout = String::from_utf8(output.stdout).unwrap();
+    let lines: Vec<&str> = stdout.lines().collect();
+
+    assert!(lines[0].starts_with("+ src/added.ts::added "), "{stdout}");
+    assert!(lines[1].starts_with("~ src/main.ts::add "), "{stdout}");
+    assert!(lines[1].contains("  cc: 2 (+1)  nd: 1 (+1)  "), "{stdout}");
+    assert!(lines[2].starts_with("- src/main.ts::gone "), "{stdout}");
+    assert!(lines[3].starts_with("  src/main.ts::keep "), "{stdout}");
+    assert!(!lines[3].contains('('), "{stdout}");
+    assert!(stdout.ends_with("\n1 added, 1 removed, 1 changed, 1 unchanged\n"));
+}
+
+#[test]
+fn test_compare_changed_only_json() {
+    let dir = edited_project();
+    let output = hotspots(
+        dir.path(),
+        &[
+            "analyze",
+            ".",
+            "--compare",
+            "before.json",
+            "--changed-only",
+            "--format",
+            "json",
+        ],
+    );
+    assert!(output.status.success());
+    let stdout = String::from_utf8(output.stdout).unwrap();
+    assert!(!stdout.contains("keep"), "{stdout}");
+    let added = stdout
+        .find("\"function_id\": \"src/added.ts::added\"")
+        .unwrap();
+    let changed = stdout
+        .find("\"function_id\": \"src/main.ts::add\"")
+        .unwrap();
+    let removed = stdout
+        .find("\"function_id\": \"src/main.ts::gone\"")
+        .unwrap();
+    assert!(added < changed && changed < removed, "{stdout}");
+    for status in ["new", "modified", "deleted"] {
+        assert!(
+            stdout.contains(&format!("\"status\": \"{status}\"")),
+            "{stdout}"
+        );
+    }
+}
+
+#[test]
+fn test_changed_only_requires_compare() {
+    let dir = edited_project();
+    let output = hotspots(dir.path(), &["analyze", ".", "--changed-only"]);
+    assert_eq!(output.status.code(), Some(2));
+}
@@ -0,0 +1,120 @@
+//! Output determinism tests
+//!
+//! Runs the built `hotspots` binary over the same files listed in different
+//! orders and checks that every format is byte-identical, and identical to
+//! walking the directory, so CI artifacts are reproducible whatever order the
+//! filesystem or a file list yields files in.
+
+use std::io::Write;
+use std::path::Path;
+use std::process::{Command, Stdio};
+use tempfile::TempDir;
+
+const FLAT: &str = "function add(a: number, b: number): number {\n  return a + b;\n}\n";
+
+/// ND 5, at the default `nd` threshold
+const NESTED: &str = r#"function nested(a: number): number {
+  if (a > 0) {
+    if (a > 1) {
+      if (a > 2) {
+        if (a > 3) {
+          if (a > 4) {
+            return a;
+          }
+        }
+      }
+    }
+  }
+  return 0;
+}
+"#;
+
+const PYTHON: &str = "def scale(x):\n    if x > 0:\n        return x * 2\n    return 0\n";
+
+const GO: &str =
+    "package main\n\nfunc clamp(x int) int {\n\tif x < 0 {\n\t\treturn 0\n\t}\n\treturn x\n}\n";
+
+/// Identical functions in several files tie on every metric, so only the
+/// tie-breaking keeps their order stable
+const FILES: &[(&str, &str)] = &[
+    ("src/nested.ts", NESTED),
+    ("src/add.ts", FLAT),
+    ("lib/add.ts", FLAT),
+    ("lib/nested.ts", NESTED),
+    ("util.py", PYTHON),
+    ("cmd/main.go", GO),
+    ("z.ts", FLAT),
+];
+
+const FORMATS: &[&str] = &[
+    "text", "json", "jsonl", "junit", "markdown", "csv", "gitlab", "tree", "html",
+];
+
+/// Exit code and output of one run: stdout, or the written report for HTML
+fn run(dir: &Path, args: &[&str], file_list: Option<&str>) -> (Option<i32>, Vec<u8>) {
+    let report = dir.join(".hotspots/report.html");
+    let _ = std::fs::remove_file(&report);
+
+    let mut command = Command::new(env!("CARGO_BIN_EXE_hotspots"));
+    command
+        .args(["analyze", ".", "--no-cache", "--no-progress"])
+        .args(args)
+        .current_dir(dir)
+        .stdin(Stdio::piped())
+        .stdout(Stdio::piped())
+        .stderr(Stdio::piped());
+    if file_list.is_some() {
+        command.args(["--files-from", "-"]);
+    }
+    let mut child = command.spawn().expect("failed to run hotspots");
+    let mut stdin = child.stdin.take().unwrap();
+    stdin.write_all(file_list.unwrap_or("").as_bytes()).unwrap();
+    drop(stdin);
+    let output = child.wait_with_output().unwrap();
+
+    let out = if args.contains(&"html") {
+        std::fs::read(&report).expect("HTML report was not written")
+    } else {
+        output.stdout
+    };
+    (output.status.code(), out)
+}
+
+#[test]
+fn test_output_independent_of_file_order() {
+    let dir = TempDir::new().unwrap();
+    for (name, source) in FILES {
+        let path = dir.path().join(name);
+        std::fs::create_dir_all(path.parent().unwrap()).unwrap();
+        std::fs::write(path, source).unwrap();
+    }
+
+    let names: Vec<&str> = FILES.iter().map(|(name, _)| *name).collect();
+    let mut orders = Vec::new();
+    for rotation in 0..names.len() {
+        let mut order = names.clone();
+        order.rotate_left(rotation);
+        orders.push(order.clone());
+        order.reverse();
+        orders.push(order);
+    }
+    // A file listed twice is analyzed once
+    let mut repeated = names.clone();
+    repeated.push(names[0]);
+    orders.push(repeated);
+
+    let mut runs: Vec<Vec<&str>> = FORMATS.iter().map(|f| vec!["--format", *f]).collect();
+    runs.push(vec!["--quiet"]);
+
+    for args in &runs {
+        let walked = run(dir.path(), args, None);
+        assert!(!walked.1.is_empty(), "no output for {args:?}");
+        for order in &orders {
+            let listed = run(dir.path(), args, Some(&order.join("\n")));
+            assert!(
+                listed == walked,
+                "{args:?} output differs when files are listed as {order:?}"
+            );
+        }
+    }
+}
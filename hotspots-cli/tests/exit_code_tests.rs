@@ -0,0 +1,198 @@
+//! Exit code tests
+//!
+//! Runs the built `hotspots` binary and checks each exit code of the CI
+//! contract: 0 clean, 1 violations, 2 usage error, 3 internal error.
+
+use std::path::Path;
+use std::process::Command;
+use tempfile::TempDir;
+
+const FLAT: &str = "function add(a: number, b: number): number {\n  return a + b;\n}\n";
+
+/// ND 5, at the default `nd` threshold
+const NESTED: &str = r#"function nested(a: number): number {
+  if (a > 0) {
+    if (a > 1) {
+      if (a > 2) {
+        if (a > 3) {
+          if (a > 4) {
+            return a;
+          }
+        }
+      }
+    }
+  }
+  return 0;
+}
+"#;
+
+fn project(source: &str) -> TempDir {
+    let dir = TempDir::new().unwrap();
+    std::fs::write(dir.path().join("main.ts"), source).unwrap();
+    dir
+}
+
+fn exit_code(dir: &Path, args: &[&str]) -> i32 {
+    let output = Command::new(env!("CARGO_BIN_EXE_hotspots"))
+        .args(args)
+        .current_dir(dir)
+        .output()
+        .expect("failed to run hotspots");
+    output
+        .status
+        .code()
+        .expect("hotspots was killed by a signal")
+}
+
+#[test]
+fn test_clean_run_exits_0() {
+    let dir = project(FLAT);
+    assert_eq!(exit_code(dir.path(), &["analyze", "."]), 0);
+    assert_eq!(
+        exit_code(dir.path(), &["analyze", ".", "--format", "json"]),
+        0
+    );
+}
+
+#[test]
+fn test_threshold_violation_exits_1() {
+    let dir = project(NESTED);
+    assert_eq!(exit_code(dir.path(), &["analyze", "."]), 1);
+    assert_eq!(
+        exit_code(dir.path(), &["analyze", ".", "--format", "jsonl"]),
+        1
+    );
+}
+
+#[test]
+fn test_exit_zero_reports_violations_but_exits_0() {
+    let dir = project(NESTED);
+    assert_eq!(exit_code(dir.path(), &["analyze", ".", "--exit-zero"]), 0);
+    assert_eq!(
+        exit_code(
+            dir.path(),
+            &["analyze", ".", "--max-params", "0", "--exit-zero"]
+        ),
+        0
+    );
+}
+
+/// `nested` has LRS between 6 and 9: high severity on the default scale
+#[test]
+fn test_min_severity_narrows_the_gate() {
+    let dir = project(NESTED);
+    assert_eq!(
+        exit_code(dir.path(), &["analyze", ".", "--min-severity", "high"]),
+        1
+    );
+    assert_eq!(
+        exit_code(dir.path(), &["analyze", ".", "--min-severity", "critical"]),
+        0
+    );
+}
+
+#[test]
+fn test_disabled_rule_is_not_a_violation() {
+    let dir = project(NESTED);
+    std::fs::write(
+        dir.path().join(".hotspotsrc.json"),
+        r#"{"sarif": {"nd": {"level": "none"}}}"#,
+    )
+    .unwrap();
+    assert_eq!(exit_code(dir.path(), &["analyze", "."]), 0);
+}
+
+#[test]
+fn test_usage_errors_exit_2() {
+    let dir = project(FLAT);
+    // Rejected by the argument parser
+    assert_eq!(
+        exit_code(dir.path(), &["analyze", ".", "--no-such-flag"]),
+        2
+    );
+    // Rejected by flag validation
+    assert_eq!(exit_code(dir.path(), &["analyze", ".", "--policy"]), 2);
+    assert_eq!(exit_code(dir.path(), &["analyze", "missing"]), 2);
+    assert_eq!(
+        exit_code(
+            dir.path(),
+            &["analyze", ".", "--sort", "cc", "--asc", "--desc"]
+        ),
+        2
+    );
+    assert_eq!(
+        exit_code(dir.path(), &["analyze", ".", "--sort", "fi", "--asc"]),
+        2
+    );
+    assert_eq!(
+        exit_code(
+            dir.path(),
+            &["analyze", ".", "--offset", "1", "--mode", "snapshot"]
+        ),
+        2
+    );
+    assert_eq!(
+        exit_code(
+            dir.path(),
+            &[
+                "analyze",
+                ".",
+                "--min-severity",
+                "high",
+                "--mode",
+                "snapshot"
+            ]
+        ),
+        2
+    );
+
+    std::fs::write(dir.path().join(".hotspotsrc.json"), "{ not json").unwrap();
+    assert_eq!(exit_code(dir.path(), &["analyze", "."]), 2);
+}
+
+#[test]
+fn test_internal_error_exits_3() {
+    let dir = project(FLAT);
+    // The report's parent directory is a regular file, so writing it fails
+    std::fs::write(dir.path().join("taken"), "").unwrap();
+    assert_eq!(
+        exit_code(
+            dir.path(),
+            &[
+                "analyze",
+                ".",
+                "--format",
+                "html",
+                "--output",
+                "taken/report.html"
+            ]
+        ),
+        3
+    );
+}
+
+#[test]
+fn test_record_outside_git_exits_2() {
+    let dir = project(FLAT);
+    assert_eq!(exit_code(dir.path(), &["analyze", ".", "--record"]), 2);
+    assert!(!dir.path().join(".hotspots-history.jsonl").exists());
+    // Nothing recorded yet is not an error
+    assert_eq!(exit_code(dir.path(), &["analyze", ".", "--trend", "5"]), 0);
+}
+
+#[test]
+fn test_changed_outside_git_exits_2() {
+    let dir = project(FLAT);
+    assert_eq!(exit_code(dir.path(), &["analyze", ".", "--changed"]), 2);
+    assert_eq!(
+        exit_code(dir.path(), &["analyze", ".", "--changed", "HEAD~1"]),
+        2
+    );
+    assert_eq!(
+        exit_code(
+            dir.path(),
+            &["analyze", ".", "--changed", "--files-from", "-"]
+        ),
+        2
+    );
+}
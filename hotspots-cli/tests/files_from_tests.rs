@@ -0,0 +1,87 @@
+//! `--files-from` tests
+//!
+//! Runs the built `hotspots` binary with a file list piped through stdin, as a
+//! pre-commit hook passing its staged files would.
+
+use std::io::Write;
+use std::process::{Command, Output, Stdio};
+use tempfile::TempDir;
+
+const FLAT: &str = "function add(a: number, b: number): number {\n  return a + b;\n}\n";
+
+/// ND 5, at the default `nd` threshold
+const NESTED: &str = r#"function nested(a: number): number {
+  if (a > 0) {
+    if (a > 1) {
+      if (a > 2) {
+        if (a > 3) {
+          if (a > 4) {
+            return a;
+          }
+        }
+      }
+    }
+  }
+  return 0;
+}
+"#;
+
+fn analyze_with_stdin(dir: &TempDir, stdin: &str) -> Output {
+    let mut child = Command::new(env!("CARGO_BIN_EXE_hotspots"))
+        .args(["analyze", ".", "--format", "json", "--files-from", "-"])
+        .current_dir(dir.path())
+        .stdin(Stdio::piped())
+        .stdout(Stdio::piped())
+        .stderr(Stdio::piped())
+        .spawn()
+        .expect("failed to run hotspots");
+    child
+        .stdin
+        .take()
+        .unwrap()
+        .write_all(stdin.as_bytes())
+        .unwrap();
+    child.wait_with_output().unwrap()
+}
+
+#[test]
+fn test_files_from_stdin_analyzes_only_listed_files() {
+    let dir = TempDir::new().unwrap();
+    std::fs::create_dir(dir.path().join("src")).unwrap();
+    std::fs::write(dir.path().join("src/add.ts"), FLAT).unwrap();
+    std::fs::write(dir.path().join("src/nested.ts"), NESTED).unwrap();
+    std::fs::write(dir.path().join("README.md"), "# Project\n").unwrap();
+
+    // Unsupported and deleted files, as in a list of staged files
+    let output = analyze_with_stdin(&dir, "src/add.ts\nREADME.md\n\nsrc/deleted.ts\n");
+    let stdout = String::from_utf8(output.stdout).unwrap();
+    let stderr = String::from_utf8(output.stderr).unwrap();
+
+    // nested.ts would breach the ND threshold and exit 1 if it were analyzed
+    assert_eq!(output.status.code(), Some(0), "stderr: {stderr}");
+    assert!(stdout.contains("\"function\": \"add\""), "{stdout}");
+    assert!(!stdout.contains("nested"), "{stdout}");
+    assert!(
+        stderr.contains("warning: skipping src/deleted.ts"),
+        "{stderr}"
+    );
+    assert!(!stderr.contains("README.md"), "{stderr}");
+}
+
+#[test]
+fn test_files_from_stdin_keeps_language_routing() {
+    let dir = TempDir::new().unwrap();
+    std::fs::write(dir.path().join("add.ts"), FLAT).unwrap();
+    std::fs::write(
+        dir.path().join("util.py"),
+        "def scale(x):\n    return x * 2\n",
+    )
+    .unwrap();
+
+    let output = analyze_with_stdin(&dir, "util.py\nadd.ts\n");
+    let stdout = String::from_utf8(output.stdout).unwrap();
+
+    assert_eq!(output.status.code(), Some(0));
+    assert!(stdout.contains("\"language\": \"Python\""), "{stdout}");
+    assert!(stdout.contains("\"language\": \"TypeScript\""), "{stdout}");
+}
@@ -0,0 +1,39 @@
+//! Progress output tests
+//!
+//! Runs the built `hotspots` binary with stderr captured (not a terminal), where
+//! progress is printed as `Analyzing: N/M files` lines.
+
+use std::process::{Command, Output};
+use tempfile::TempDir;
+
+const FLAT: &str = "function add(a: number, b: number): number {\n  return a + b;\n}\n";
+
+fn run(dir: &TempDir, extra: &[&str]) -> Output {
+    Command::new(env!("CARGO_BIN_EXE_hotspots"))
+        .args(["analyze", ".", "--format", "json"])
+        .args(extra)
+        .current_dir(dir.path())
+        .output()
+        .expect("failed to run hotspots")
+}
+
+#[test]
+fn test_no_progress_hides_progress_lines() {
+    let dir = TempDir::new().unwrap();
+    std::fs::write(dir.path().join("add.ts"), FLAT).unwrap();
+    std::fs::write(dir.path().join("sub.ts"), FLAT.replace("add", "sub")).unwrap();
+
+    let with_progress = run(&dir, &[]);
+    let stderr = String::from_utf8(with_progress.stderr).unwrap();
+    assert!(stderr.contains("Analyzing: 2/2 files"), "{stderr}");
+
+    let without_progress = run(&dir, &["--no-progress"]);
+    let stderr = String::from_utf8(without_progress.stderr).unwrap();
+    assert!(!stderr.contains("Analyzing"), "{stderr}");
+
+    // Progress only ever goes to stderr
+    assert_eq!(with_progress.status.code(), Some(0));
+    assert_eq!(without_progress.status.code(), Some(0));
+    assert!(!without_progress.stdout.is_empty());
+    assert_eq!(with_progress.stdout, without_progress.stdout);
+}
@@ -0,0 +1,73 @@
+//! `--quiet` tests
+//!
+//! Runs the built `hotspots` binary as a git hook would and checks that a
+//! clean run prints nothing and a failing one only the offending functions.
+
+use std::process::{Command, Output};
+use tempfile::TempDir;
+
+const FLAT: &str = "function add(a: number, b: number): number {\n  return a + b;\n}\n";
+
+/// ND 5, at the default `nd` threshold
+const NESTED: &str = r#"function nested(a: number): number {
+  if (a > 0) {
+    if (a > 1) {
+      if (a > 2) {
+        if (a > 3) {
+          if (a > 4) {
+            return a;
+          }
+        }
+      }
+    }
+  }
+  return 0;
+}
+"#;
+
+fn run_quiet(files: &[(&str, &str)]) -> Output {
+    let dir = TempDir::new().unwrap();
+    for (name, source) in files {
+        std::fs::write(dir.path().join(name), source).unwrap();
+    }
+    Command::new(env!("CARGO_BIN_EXE_hotspots"))
+        .args(["analyze", ".", "--quiet"])
+        .current_dir(dir.path())
+        .output()
+        .expect("failed to run hotspots")
+}
+
+#[test]
+fn test_quiet_clean_run_prints_nothing() {
+    let output = run_quiet(&[("main.ts", FLAT)]);
+
+    assert_eq!(output.status.code(), Some(0));
+    assert_eq!(output.stdout.len(), 0, "{:?}", output.stdout);
+    let stderr = String::from_utf8(output.stderr).unwrap();
+    assert!(!stderr.contains("Analyzing"), "{stderr}");
+}
+
+#[test]
+fn test_quiet_lists_only_offending_functions() {
+    let output = run_quiet(&[("add.ts", FLAT), ("nested.ts", NESTED)]);
+    let stdout = String::from_utf8(output.stdout).unwrap();
+
+    assert_eq!(output.status.code(), Some(1));
+    let lines: Vec<&str> = stdout.lines().collect();
+    assert_eq!(lines.len(), 1, "{stdout}");
+    assert!(lines[0].starts_with("nested.ts:1 nested "), "{stdout}");
+    assert!(lines[0].split(' ').any(|m| m == "nd=5"), "{stdout}");
+}
+
+#[test]
+fn test_quiet_rejects_other_formats() {
+    let dir = TempDir::new().unwrap();
+    std::fs::write(dir.path().join("main.ts"), FLAT).unwrap();
+    let output = Command::new(env!("CARGO_BIN_EXE_hotspots"))
+        .args(["analyze", ".", "--quiet", "--format", "json"])
+        .current_dir(dir.path())
+        .output()
+        .expect("failed to run hotspots");
+
+    assert_eq!(output.status.code(), Some(2));
+}
@@ -0,0 +1,61 @@
+//! Parser/analyzer throughput on the per-language fixtures.
+//!
+//! Run with `cargo bench -p hotspots-core`. Each language gets a group with two
+//! benchmarks over the same workload: `files` reports files/sec and `functions`
+//! reports functions/sec. Sources are loaded before timing, so only parsing and
+//! analysis are measured (see `hotspots_core::bench`).
+
+use criterion::{criterion_group, criterion_main, BenchmarkId, Criterion, Throughput};
+use hotspots_core::bench::{analyze_file, load_files, BenchFile};
+use std::path::PathBuf;
+
+const LANGUAGES: &[&str] = &[
+    "bash", "c", "cpp", "csharp", "dart", "elixir", "go", "haskell", "java", "js", "jsx", "lua",
+    "perl", "php", "python", "rust", "scala", "sql", "swift", "tsx", "vue", "zig",
+];
+
+fn fixtures_dir(name: &str) -> PathBuf {
+    PathBuf::from(env!("CARGO_MANIFEST_DIR"))
+        .parent()
+        .unwrap()
+        .join("tests")
+        .join("fixtures")
+        .join(name)
+}
+
+fn analyze_all(files: &[BenchFile]) -> usize {
+    files
+        .iter()
+        .enumerate()
+        .map(|(i, f)| analyze_file(f, i))
+        .sum()
+}
+
+fn bench_languages(c: &mut Criterion) {
+    // "all" covers the whole fixture tree, including the top-level TypeScript fixtures.
+    let groups = LANGUAGES
+        .iter()
+        .map(|&lang| (lang, fixtures_dir(lang)))
+        .chain(std::iter::once(("all", fixtures_dir(""))));
+    for (lang, dir) in groups {
+        let files = load_files(&dir).expect("fixtures should load");
+        if files.is_empty() {
+            continue;
+        }
+        let functions = analyze_all(&files);
+
+        let mut group = c.benchmark_group(lang);
+        group.throughput(Throughput::Elements(files.len() as u64));
+        group.bench_function(BenchmarkId::new("files", files.len()), |b| {
+            b.iter(|| analyze_all(&files))
+        });
+        group.throughput(Throughput::Elements(functions as u64));
+        group.bench_function(BenchmarkId::new("functions", functions), |b| {
+            b.iter(|| analyze_all(&files))
+        });
+        group.finish();
+    }
+}
+
+criterion_group!(benches, bench_languages);
+criterion_main!(benches);
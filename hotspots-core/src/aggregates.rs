@@ -11,7 +11,7 @@ use crate::delta::Delta;
 use crate::risk::RiskBand;
 use crate::snapshot::{FunctionSnapshot, Snapshot};
 use serde::{Deserialize, Serialize};
-use std::collections::HashMap;
+use std::collections::{BTreeMap, HashMap};
 
 /// File-level aggregates for a snapshot
 #[derive(Debug, Clone, Serialize, Deserialize, PartialEq)]
@@ -39,6 +39,14 @@ pub struct DirectoryAggregates {
 /// file_risk_score derived from:
 ///   max_cc × 0.4 + avg_cc × 0.3 + log2(function_count + 1) × 0.2 + churn_factor × 0.1
 /// where churn_factor = (file_churn / 100).min(10.0)
+///
+/// `file_cc` treats the whole file as a single unit, matching legacy tools that
+/// report one CC per file: `1 + Σ(cc_i − 1)` over every function in the file,
+/// plus the decision points outside every function (module-scope `if`, loops,
+/// `&&`, `||`, ...).
+///
+/// `maintainability` is the mean maintainability index of the file's functions
+/// that have one (computed with `--halstead`); omitted when none do.
 #[derive(Debug, Clone, Serialize, Deserialize, PartialEq)]
 #[serde(rename_all = "snake_case")]
 pub struct FileRiskView {
@@ -47,9 +55,13 @@ pub struct FileRiskView {
     pub loc: usize,
     pub max_cc: usize,
     pub avg_cc: f64,
+    #[serde(default)]
+    pub file_cc: usize,
     pub critical_count: usize,
     pub file_churn: u64,
     pub file_risk_score: f64,
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub maintainability: Option<f64>,
 }
 
 /// Module (directory) instability metric (Robert Martin's Ca/Ce)
@@ -71,6 +83,23 @@ pub struct ModuleInstability {
     pub module_risk: String,
 }
 
+/// Complexity budget usage for one configured module (directory) or file
+///
+/// `total_cc` sums `file_cc` over every file under `module`; `remaining` goes
+/// negative once the budget is exceeded.
+#[derive(Debug, Clone, Serialize, Deserialize, PartialEq)]
+#[serde(rename_all = "snake_case")]
+pub struct BudgetUsage {
+    /// Budget path relative to repo root (`.` = whole repo)
+    pub module: String,
+    pub budget: usize,
+    pub total_cc: usize,
+    pub remaining: i64,
+    /// total_cc as a percentage of budget
+    pub used_pct: f64,
+    pub file_count: usize,
+}
+
 /// Snapshot aggregates container
 #[derive(Debug, Clone, Serialize, Deserialize, PartialEq)]
 #[serde(rename_all = "snake_case")]
@@ -233,15 +262,24 @@ pub struct AgentSnapshotOutput {
 }
 
 impl AgentSnapshotOutput {
-    /// Serialize to pretty-printed JSON string.
-    pub fn to_json(&self) -> anyhow::Result<String> {
-        serde_json::to_string_pretty(self).map_err(|e| anyhow::anyhow!("{}", e))
+    /// Serialize to pretty-printed JSON string, with `tool_version` after
+    /// its fields.
+    pub fn to_json(&self, tool_version: &str) -> anyhow::Result<String> {
+        serde_json::to_string_pretty(&crate::report::WithToolVersion::new(self, tool_version))
+            .map_err(|e| anyhow::anyhow!("{}", e))
     }
 
     /// Write pretty-printed JSON directly to `writer` without an intermediate String.
-    pub fn write_json_to<W: std::io::Write>(&self, writer: &mut W) -> anyhow::Result<()> {
-        serde_json::to_writer_pretty(writer as &mut dyn std::io::Write, self)
-            .map_err(|e| anyhow::anyhow!("{}", e))?;
+    pub fn write_json_to<W: std::io::Write>(
+        &self,
+        writer: &mut W,
+        tool_version: &str,
+    ) -> anyhow::Result<()> {
+        serde_json::to_writer_pretty(
+            writer as &mut dyn std::io::Write,
+            &crate::report::WithToolVersion::new(self, tool_version),
+        )
+        .map_err(|e| anyhow::anyhow!("{}", e))?;
         writeln!(writer).map_err(|e| anyhow::anyhow!("{}", e))
     }
 }
@@ -609,15 +647,30 @@ pub fn compute_directory_aggregates(
 
 /// Compute file risk views from snapshot functions
 ///
+/// `top_level_decisions` maps a file to its decision points outside every
+/// function (see `Snapshot::top_level_decisions`); files missing from it have
+/// none.
+///
 /// Ranked descending by `file_risk_score`. Score formula:
 ///   max_cc × 0.4 + avg_cc × 0.3 + log2(function_count + 1) × 0.2 + churn_factor × 0.1
-pub fn compute_file_risk_views(functions: &[FunctionSnapshot]) -> Vec<FileRiskView> {
-    // Accumulate (sum_cc, max_cc, count, critical_count, loc, file_churn) per file
-    let mut file_data: HashMap<String, (usize, usize, usize, usize, usize, u64)> = HashMap::new();
+pub fn compute_file_risk_views(
+    functions: &[FunctionSnapshot],
+    top_level_decisions: &BTreeMap<String, usize>,
+) -> Vec<FileRiskView> {
+    // Accumulate (sum_cc, max_cc, count, critical_count, loc, file_churn, sum_cc_extra) per file
+    type FileAcc = (usize, usize, usize, usize, usize, u64, usize);
+    let mut file_data: HashMap<String, FileAcc> = HashMap::new();
+    // (sum, count) of function maintainability indexes per file
+    let mut file_mi: HashMap<&str, (f64, usize)> = HashMap::new();
     for func in functions {
+        if let Some(mi) = func.metrics.maintainability {
+            let e = file_mi.entry(func.file.as_str()).or_insert((0.0, 0));
+            e.0 += mi;
+            e.1 += 1;
+        }
         let e = file_data
             .entry(func.file.clone())
-            .or_insert((0, 0, 0, 0, 0, 0));
+            .or_insert((0, 0, 0, 0, 0, 0, 0));
         e.0 += func.metrics.cc as usize;
         e.1 = e.1.max(func.metrics.cc as usize);
         e.2 += 1;
@@ -629,12 +682,16 @@ pub fn compute_file_risk_views(functions: &[FunctionSnapshot]) -> Vec<FileRiskVi
             let lines = (churn.lines_added + churn.lines_deleted) as u64;
             e.5 = e.5.max(lines);
         }
+        e.6 += (func.metrics.cc as usize).saturating_sub(1);
     }
 
     let mut views: Vec<FileRiskView> = file_data
         .into_iter()
         .map(
-            |(file, (sum_cc, max_cc, function_count, critical_count, loc, file_churn))| {
+            |(
+                file,
+                (sum_cc, max_cc, function_count, critical_count, loc, file_churn, cc_extra),
+            )| {
                 let avg_cc = if function_count > 0 {
                     sum_cc as f64 / function_count as f64
                 } else {
@@ -645,15 +702,21 @@ pub fn compute_file_risk_views(functions: &[FunctionSnapshot]) -> Vec<FileRiskVi
                     + avg_cc * 0.3
                     + (function_count as f64 + 1.0).log2() * 0.2
                     + churn_factor * 0.1;
+                let maintainability = file_mi
+                    .get(file.as_str())
+                    .map(|&(sum, count)| (sum / count as f64 * 100.0).round() / 100.0);
+                let top_level = top_level_decisions.get(&file).copied().unwrap_or(0);
                 FileRiskView {
                     file,
                     function_count,
                     loc,
                     max_cc,
                     avg_cc: (avg_cc * 100.0).round() / 100.0,
+                    file_cc: 1 + cc_extra + top_level,
                     critical_count,
                     file_churn,
                     file_risk_score: (score * 100.0).round() / 100.0,
+                    maintainability,
                 }
             },
         )
@@ -808,6 +871,62 @@ fn compute_module_instability_from_edges(
     modules
 }
 
+/// Compute complexity budget usage from file risk views
+///
+/// A file counts toward a budget when its repo-relative path equals the budget
+/// path or lies under it. Sorted by least headroom first (highest `used_pct`),
+/// then by module path.
+pub fn compute_budget_usage(
+    file_risk: &[FileRiskView],
+    budgets: &BTreeMap<String, usize>,
+    repo_root: &std::path::Path,
+) -> Vec<BudgetUsage> {
+    let files: Vec<(String, usize)> = file_risk
+        .iter()
+        .filter_map(|view| {
+            normalize_path_relative_to_repo(&view.file, repo_root).map(|rel| (rel, view.file_cc))
+        })
+        .collect();
+
+    let mut usage: Vec<BudgetUsage> = budgets
+        .iter()
+        .map(|(module, &budget)| {
+            let under_module = |rel: &str| {
+                module == "."
+                    || rel == module
+                    || rel
+                        .strip_prefix(module.as_str())
+                        .is_some_and(|rest| rest.starts_with('/'))
+            };
+            let (file_count, total_cc) = files
+                .iter()
+                .filter(|(rel, _)| under_module(rel))
+                .fold((0, 0), |(count, cc), (_, file_cc)| {
+                    (count + 1, cc + file_cc)
+                });
+            BudgetUsage {
+                module: module.clone(),
+                budget,
+                total_cc,
+                remaining: budget as i64 - total_cc as i64,
+                used_pct: if budget > 0 {
+                    total_cc as f64 / budget as f64 * 100.0
+                } else {
+                    0.0
+                },
+                file_count,
+            }
+        })
+        .collect();
+
+    usage.sort_by(|a, b| {
+        b.used_pct
+            .total_cmp(&a.used_pct)
+            .then_with(|| a.module.cmp(&b.module))
+    });
+    usage
+}
+
 /// Compute module instability from snapshot functions (computes import edges internally).
 ///
 /// Exposed as a public API for callers that don't have pre-computed edges.
@@ -858,9 +977,7 @@ pub fn compute_snapshot_aggregates_with_models(
 ) -> SnapshotAggregates {
     let files = compute_file_aggregates(&snapshot.functions);
     let directories = compute_directory_aggregates(&files, repo_root);
-    let file_risk = compute_file_risk_views(&snapshot.functions);
 
-    // Compute import edges once — shared by module instability and co-change annotation
     let mut unique_files: Vec<String> = snapshot
         .functions
         .iter()
@@ -869,6 +986,10 @@ pub fn compute_snapshot_aggregates_with_models(
         .into_iter()
         .collect();
     unique_files.sort();
+
+    let file_risk = compute_file_risk_views(&snapshot.functions, &snapshot.top_level_decisions);
+
+    // Compute import edges once — shared by module instability and co-change annotation
     let files_as_str: Vec<&str> = unique_files.iter().map(|s| s.as_str()).collect();
     let mut all_edges = crate::imports::resolve_file_deps(&files_as_str, repo_root);
     all_edges.extend(crate::imports::resolve_cargo_workspace_edges(
@@ -1090,8 +1211,24 @@ mod tests {
                 cc: 1,
                 nd: 0,
                 fo: 0,
+                fi: 0,
                 ns: 0,
                 loc: 10,
+                signature_complexity: 0,
+                params: 0,
+                guard_clauses: 0,
+                max_condition_ops: 0,
+                cognitive: 0,
+                halstead: None,
+                maintainability: None,
+                sloc: None,
+                comment_lines: None,
+                blank_lines: None,
+                nd_line: None,
+                cc_lines: None,
+                is_async: false,
+                await_in_loop: 0,
+                ns_breakdown: None,
             },
             lrs,
             band: crate::risk::RiskBand::parse(band).unwrap_or(crate::risk::RiskBand::Low),
@@ -1121,6 +1258,8 @@ mod tests {
             age_days: None,
             last_touch_days: None,
             explanation: None,
+            structure: None,
+            cc_breakdown: None,
         }
     }
 
@@ -1191,6 +1330,97 @@ mod tests {
         assert_eq!(src_dir.high_plus_count, 1);
     }
 
+    #[test]
+    fn test_file_risk_views_file_cc() {
+        let mut a = create_test_function("src/a.ts", "a", 1.0, "low");
+        a.metrics.cc = 3;
+        let mut b = create_test_function("src/a.ts", "b", 1.0, "low");
+        b.metrics.cc = 4;
+        let c = create_test_function("src/a.ts", "c", 1.0, "low");
+        let d = create_test_function("src/b.ts", "d", 1.0, "low");
+
+        let views = compute_file_risk_views(&[a, b, c, d], &BTreeMap::new());
+
+        let a_view = views.iter().find(|v| v.file == "src/a.ts").unwrap();
+        // 1 + (3-1) + (4-1) + (1-1), not the plain sum of 8
+        assert_eq!(a_view.file_cc, 6);
+        let b_view = views.iter().find(|v| v.file == "src/b.ts").unwrap();
+        assert_eq!(b_view.file_cc, 1);
+
+        // Module-level branching is added on top
+        let mut a = create_test_function("src/a.ts", "a", 1.0, "low");
+        a.metrics.cc = 3;
+        let top_level = BTreeMap::from([("src/a.ts".to_string(), 2)]);
+        let views = compute_file_risk_views(&[a], &top_level);
+        assert_eq!(views[0].file_cc, 5);
+    }
+
+    #[test]
+    fn test_file_risk_views_maintainability() {
+        let mut a = create_test_function("src/a.ts", "a", 1.0, "low");
+        a.metrics.maintainability = Some(40.0);
+        let mut b = create_test_function("src/a.ts", "b", 1.0, "low");
+        b.metrics.maintainability = Some(61.0);
+        // Functions without an index do not pull the mean down
+        let c = create_test_function("src/a.ts", "c", 1.0, "low");
+        let d = create_test_function("src/b.ts", "d", 1.0, "low");
+
+        let views = compute_file_risk_views(&[a, b, c, d], &BTreeMap::new());
+
+        let a_view = views.iter().find(|v| v.file == "src/a.ts").unwrap();
+        assert_eq!(a_view.maintainability, Some(50.5));
+        let b_view = views.iter().find(|v| v.file == "src/b.ts").unwrap();
+        assert_eq!(b_view.maintainability, None);
+    }
+
+    #[test]
+    fn test_budget_usage_near_and_over_budget() {
+        let mut functions = Vec::new();
+        // src/api: two files, file CC 12 + 7 = 19
+        for (name, cc) in [("a", 6), ("b", 7)] {
+            let mut f = create_test_function("/repo/src/api/routes.ts", name, 1.0, "low");
+            f.metrics.cc = cc;
+            functions.push(f);
+        }
+        let mut f = create_test_function("/repo/src/api/auth/token.ts", "verify", 1.0, "low");
+        f.metrics.cc = 7;
+        functions.push(f);
+        // src/core: file CC 30
+        let mut f = create_test_function("/repo/src/core/engine.ts", "run", 1.0, "low");
+        f.metrics.cc = 30;
+        functions.push(f);
+        // Sibling whose name shares the `src/api` prefix must not count toward it
+        let mut f = create_test_function("/repo/src/api_v2/client.ts", "call", 1.0, "low");
+        f.metrics.cc = 50;
+        functions.push(f);
+
+        let views = compute_file_risk_views(&functions, &BTreeMap::new());
+        let budgets = BTreeMap::from([
+            ("src/api".to_string(), 20),
+            ("src/core".to_string(), 25),
+            ("src/core/engine.ts".to_string(), 100),
+        ]);
+        let usage = compute_budget_usage(&views, &budgets, std::path::Path::new("/repo"));
+
+        let modules: Vec<&str> = usage.iter().map(|u| u.module.as_str()).collect();
+        // Least headroom first: over budget, near budget, plenty left
+        assert_eq!(modules, vec!["src/core", "src/api", "src/core/engine.ts"]);
+
+        let over = &usage[0];
+        assert_eq!(over.total_cc, 30);
+        assert_eq!(over.remaining, -5);
+        assert!((over.used_pct - 120.0).abs() < 1e-9);
+
+        let near = &usage[1];
+        assert_eq!(near.file_count, 2);
+        assert_eq!(near.total_cc, 19);
+        assert_eq!(near.remaining, 1);
+        assert!((near.used_pct - 95.0).abs() < 1e-9);
+
+        assert_eq!(usage[2].file_count, 1);
+        assert_eq!(usage[2].remaining, 70);
+    }
+
     #[test]
     fn test_is_high_plus() {
         assert!(is_high_plus(crate::risk::RiskBand::High));
@@ -9,74 +9,268 @@ use anyhow::{Context, Result};
 use std::path::Path;
 use swc_common::{sync::Lrc, SourceMap};
 
-/// Analyze a source file (TypeScript, JavaScript, Go, or Rust)
+/// Analyze a source file in any supported language
 pub fn analyze_file(
     path: &Path,
     source_map: &Lrc<SourceMap>,
     file_index: usize,
     options: &crate::AnalysisOptions,
 ) -> Result<Vec<report::FunctionRiskReport>> {
-    analyze_file_with_config(path, source_map, file_index, options, None, None, None)
+    analyze_file_with_config(path, source_map, file_index, options, None)
 }
 
-/// Analyze a file with optional custom weights, thresholds, and pattern thresholds
+/// Reports for one file, how many syntax errors its parser recovered from,
+/// and its decision points outside functions
+#[derive(Debug, Default)]
+pub struct FileAnalysis {
+    pub reports: Vec<report::FunctionRiskReport>,
+    /// Syntax errors in the file; functions containing one are not reported
+    pub parse_errors: usize,
+    /// Decision points outside every function body (see
+    /// `ParsedModule::top_level_decisions`), also set on each report
+    pub top_level_decisions: usize,
+}
+
+/// Analyze a file with weights, risk thresholds, pattern thresholds, SQL
+/// dialect, ND constructs, `public_only`, `include_tests`, and `halstead` taken
+/// from `config` (defaults when `None`, which keeps test functions)
 pub fn analyze_file_with_config(
     path: &Path,
     source_map: &Lrc<SourceMap>,
     file_index: usize,
     options: &crate::AnalysisOptions,
-    weights: Option<&risk::LrsWeights>,
-    thresholds: Option<&risk::RiskThresholds>,
-    pattern_thresholds: Option<&crate::patterns::Thresholds>,
+    config: Option<&crate::config::ResolvedConfig>,
 ) -> Result<Vec<report::FunctionRiskReport>> {
-    let default_weights = risk::LrsWeights::default();
-    let default_thresholds = risk::RiskThresholds::default();
-    let default_pattern_thresholds = crate::patterns::Thresholds::default();
-    let w = weights.unwrap_or(&default_weights);
-    let t = thresholds.unwrap_or(&default_thresholds);
-    let pt = pattern_thresholds.unwrap_or(&default_pattern_thresholds);
+    analyze_file_detailed(path, source_map, file_index, options, config).map(|a| a.reports)
+}
 
+/// Like [`analyze_file_with_config`], also counting the file's syntax errors
+pub fn analyze_file_detailed(
+    path: &Path,
+    source_map: &Lrc<SourceMap>,
+    file_index: usize,
+    options: &crate::AnalysisOptions,
+    config: Option<&crate::config::ResolvedConfig>,
+) -> Result<FileAnalysis> {
     let src = std::fs::read_to_string(path)
         .with_context(|| format!("Failed to read file: {}", path.display()))?;
+    analyze_source_detailed(path, &src, source_map, file_index, options, config)
+}
 
-    let (max_line, long_line_count) = long_line_stats(&src, 1000);
-    if long_line_count >= 3 {
-        eprintln!(
-            "warning: skipping {} — looks minified or machine-generated \
-             ({} lines exceed 1000 chars, max: {})",
-            path.display(),
-            long_line_count,
-            max_line
-        );
-        return Ok(vec![]);
-    } else if looks_vendored(path) {
-        eprintln!(
-            "warning: skipping {} — path suggests vendored or generated third-party code",
-            path.display()
-        );
-        return Ok(vec![]);
-    }
+/// Like [`analyze_file_with_config`], for source already read from `path`
+pub fn analyze_source_with_config(
+    path: &Path,
+    src: &str,
+    source_map: &Lrc<SourceMap>,
+    file_index: usize,
+    options: &crate::AnalysisOptions,
+    config: Option<&crate::config::ResolvedConfig>,
+) -> Result<Vec<report::FunctionRiskReport>> {
+    analyze_source_detailed(path, src, source_map, file_index, options, config).map(|a| a.reports)
+}
 
-    let language = Language::from_path(path)
-        .ok_or_else(|| anyhow::anyhow!("Unsupported file type: {}", path.display()))?;
-    let parser = create_parser(language, source_map)?;
-    let module = parser.parse(&src, &path.to_string_lossy())?;
-    let functions = module.discover_functions(file_index, &src);
+pub(crate) fn analyze_source_detailed(
+    path: &Path,
+    src: &str,
+    source_map: &Lrc<SourceMap>,
+    file_index: usize,
+    options: &crate::AnalysisOptions,
+    config: Option<&crate::config::ResolvedConfig>,
+) -> Result<FileAnalysis> {
+    let weights = config.map_or_else(risk::LrsWeights::default, |c| risk::LrsWeights {
+        cc: c.weight_cc,
+        nd: c.weight_nd,
+        fo: c.weight_fo,
+        ns: c.weight_ns,
+    });
+    let thresholds = config.map_or_else(risk::RiskThresholds::default, |c| risk::RiskThresholds {
+        moderate: c.moderate_threshold,
+        high: c.high_threshold,
+        critical: c.critical_threshold,
+    });
+    let default_pattern_thresholds = crate::patterns::Thresholds::default();
+    let pattern_thresholds = config.map_or(&default_pattern_thresholds, |c| &c.pattern_thresholds);
+
+    let func_cfg = FunctionAnalysisConfig {
+        options,
+        weights: &weights,
+        thresholds: &thresholds,
+        pattern_thresholds,
+        sql_dialect: config.and_then(|c| c.sql_dialect),
+        nd_counts: config
+            .zip(Language::from_path(path))
+            .map_or_else(metrics::NdCounts::default, |(c, language)| {
+                c.nd_counts_for(language)
+            }),
+        cc_mode: config.map_or(metrics::CcMode::default(), |c| c.cc_mode),
+        fo_methods: config.map_or(true, |c| c.fo_methods),
+        public_only: config.is_some_and(|c| c.public_only),
+        include_tests: config.map_or(true, |c| c.include_tests),
+        halstead: config.is_some_and(|c| c.halstead),
+        line_counts: config.is_some_and(|c| c.line_counts),
+        nd_lines: config.is_some_and(|c| c.nd_lines),
+        cc_lines: config.is_some_and(|c| c.cc_lines),
+        separate_closures: config.is_some_and(|c| c.separate_closures),
+        anon_naming: config.map_or(language::AnonNaming::default(), |c| c.anon_naming),
+        ns_breakdown: config.is_some_and(|c| c.ns_breakdown),
+        source_map,
+    };
+    analyze_source_with(path, src, file_index, &func_cfg)
+}
 
+/// Analyze in-memory source as if it had been read from `path`, with default
+/// weights and thresholds.
+///
+/// The language is detected from `path`'s extension; the file itself is never
+/// read. This is the entry point for benchmarks, which load sources up front so
+/// that only parsing and analysis are timed.
+pub fn analyze_source(
+    path: &Path,
+    src: &str,
+    source_map: &Lrc<SourceMap>,
+    file_index: usize,
+    options: &crate::AnalysisOptions,
+) -> Result<Vec<report::FunctionRiskReport>> {
     let func_cfg = FunctionAnalysisConfig {
         options,
-        weights: w,
-        thresholds: t,
-        pattern_thresholds: pt,
+        weights: &risk::LrsWeights::default(),
+        thresholds: &risk::RiskThresholds::default(),
+        pattern_thresholds: &crate::patterns::Thresholds::default(),
+        sql_dialect: None,
+        nd_counts: metrics::NdCounts::default(),
+        cc_mode: metrics::CcMode::default(),
+        fo_methods: true,
+        public_only: false,
+        include_tests: true,
+        halstead: false,
+        line_counts: false,
+        nd_lines: false,
+        cc_lines: false,
+        separate_closures: false,
+        anon_naming: language::AnonNaming::default(),
+        ns_breakdown: false,
         source_map,
     };
+    analyze_source_with(path, src, file_index, &func_cfg).map(|a| a.reports)
+}
+
+/// Minified/vendored skip checks, parsing, discovery, and per-function analysis
+/// for one source text.
+///
+/// The source is normalized first (see `normalize_source`), so a CRLF file or
+/// one starting with a BOM reports the same lines as its plain LF checkout.
+///
+/// A parser that recovers from syntax errors (tree-sitter) still yields the
+/// functions around them, but not the ones containing them: their metrics
+/// would describe whatever the parser salvaged. The file's other functions
+/// are reported, with a warning naming the file.
+fn analyze_source_with(
+    path: &Path,
+    src: &str,
+    file_index: usize,
+    func_cfg: &FunctionAnalysisConfig,
+) -> Result<FileAnalysis> {
+    let normalized = language::span::normalize_source(src);
+    let src = normalized.as_ref();
+    match source_skip(path, src) {
+        Some(SourceSkip::Minified {
+            long_lines,
+            max_line,
+        }) => {
+            eprintln!(
+                "warning: skipping {} — looks minified or machine-generated \
+                 ({} lines exceed 1000 chars, max: {})",
+                path.display(),
+                long_lines,
+                max_line
+            );
+            return Ok(FileAnalysis::default());
+        }
+        Some(SourceSkip::Vendored) => {
+            eprintln!(
+                "warning: skipping {} — path suggests vendored or generated third-party code",
+                path.display()
+            );
+            return Ok(FileAnalysis::default());
+        }
+        None => {}
+    }
+
+    let language = Language::from_path(path)
+        .ok_or_else(|| anyhow::anyhow!("Unsupported file type: {}", path.display()))?;
+    let parser = create_parser(
+        language,
+        func_cfg.source_map,
+        func_cfg.sql_dialect,
+        func_cfg.separate_closures,
+        func_cfg.anon_naming,
+    )?;
+    let module = parser.parse(src, &path.to_string_lossy())?;
+    let functions = module.discover_functions(file_index, src);
+    let syntax_errors = module.syntax_errors();
+    let top_level_decisions = module.top_level_decisions();
+
     let mut reports = Vec::new();
+    let mut broken_functions = 0;
     for function in &functions {
-        if let Some(report) = analyze_function(function, path, language, &func_cfg) {
+        // Overlap rather than containment: recovery can also wrap a whole
+        // function in an `ERROR` node
+        let span = &function.span;
+        if syntax_errors
+            .iter()
+            .any(|e| e.start <= span.end && span.start <= e.end)
+        {
+            broken_functions += 1;
+            continue;
+        }
+        if func_cfg.public_only && !function.is_public {
+            continue;
+        }
+        if !func_cfg.include_tests && crate::test_code::is_test_function(function, src, language) {
+            continue;
+        }
+        if let Some(mut report) = analyze_function(function, path, src, language, func_cfg) {
+            report.file_top_level_decisions = top_level_decisions;
             reports.push(report);
         }
     }
-    Ok(reports)
+    if !syntax_errors.is_empty() {
+        eprintln!(
+            "warning: {} has {} syntax error(s); skipped {} function(s) containing them",
+            path.display(),
+            syntax_errors.len(),
+            broken_functions
+        );
+    }
+    Ok(FileAnalysis {
+        reports,
+        parse_errors: syntax_errors.len(),
+        top_level_decisions,
+    })
+}
+
+/// Why a readable source file is left out of analysis
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub(crate) enum SourceSkip {
+    /// At least 3 lines over 1000 chars
+    Minified { long_lines: usize, max_line: usize },
+    /// Path under a vendored or generated-asset directory (see `looks_vendored`)
+    Vendored,
+}
+
+/// Minified/vendored check applied to every file before parsing
+pub(crate) fn source_skip(path: &Path, src: &str) -> Option<SourceSkip> {
+    let (max_line, long_lines) = long_line_stats(src, 1000);
+    if long_lines >= 3 {
+        Some(SourceSkip::Minified {
+            long_lines,
+            max_line,
+        })
+    } else if looks_vendored(path) {
+        Some(SourceSkip::Vendored)
+    } else {
+        None
+    }
 }
 
 /// Returns the length of the longest line and the count of lines exceeding `threshold` chars.
@@ -120,18 +314,31 @@ fn looks_vendored(path: &Path) -> bool {
 }
 
 /// Instantiates the correct parser for the given language.
+///
+/// `sql_dialect` is only consulted for SQL files; `None` auto-detects per file.
+/// `separate_closures` only for Go and ECMAScript (see `--separate-closures`),
+/// and `anon_naming` only for Go closures.
 fn create_parser(
     language: Language,
     source_map: &Lrc<SourceMap>,
+    sql_dialect: Option<language::SqlDialect>,
+    separate_closures: bool,
+    anon_naming: language::AnonNaming,
 ) -> Result<Box<dyn LanguageParser>> {
     let parser: Box<dyn LanguageParser> = match language {
         Language::TypeScript
         | Language::TypeScriptReact
         | Language::JavaScript
-        | Language::JavaScriptReact => {
-            Box::new(language::ECMAScriptParser::new(source_map.clone()))
-        }
-        Language::Go => Box::new(language::GoParser::new().context("Failed to create Go parser")?),
+        | Language::JavaScriptReact => Box::new(
+            language::ECMAScriptParser::new(source_map.clone())
+                .with_separate_closures(separate_closures),
+        ),
+        Language::Go => Box::new(
+            language::GoParser::new()
+                .context("Failed to create Go parser")?
+                .with_separate_closures(separate_closures)
+                .with_anon_naming(anon_naming),
+        ),
         Language::Java => {
             Box::new(language::JavaParser::new().context("Failed to create Java parser")?)
         }
@@ -139,13 +346,49 @@ fn create_parser(
             Box::new(language::PythonParser::new().context("Failed to create Python parser")?)
         }
         Language::Rust => Box::new(language::RustParser),
-        Language::Vue => Box::new(language::VueParser::new(source_map.clone())),
+        Language::Vue => Box::new(
+            language::VueParser::new(source_map.clone()).with_separate_closures(separate_closures),
+        ),
         Language::CSharp => {
             Box::new(language::CSharpParser::new().context("Failed to create C# parser")?)
         }
         Language::C | Language::CHeader => {
             Box::new(language::CParser::new().context("Failed to create C parser")?)
         }
+        Language::Cpp => {
+            Box::new(language::CppParser::new().context("Failed to create C++ parser")?)
+        }
+        Language::Sql => Box::new(language::SqlParser::new(sql_dialect)),
+        Language::Swift => {
+            Box::new(language::SwiftParser::new().context("Failed to create Swift parser")?)
+        }
+        Language::Php => {
+            Box::new(language::PhpParser::new().context("Failed to create PHP parser")?)
+        }
+        Language::Scala => {
+            Box::new(language::ScalaParser::new().context("Failed to create Scala parser")?)
+        }
+        Language::Dart => {
+            Box::new(language::DartParser::new().context("Failed to create Dart parser")?)
+        }
+        Language::Elixir => {
+            Box::new(language::ElixirParser::new().context("Failed to create Elixir parser")?)
+        }
+        Language::Lua => {
+            Box::new(language::LuaParser::new().context("Failed to create Lua parser")?)
+        }
+        Language::Bash => {
+            Box::new(language::BashParser::new().context("Failed to create Bash parser")?)
+        }
+        Language::Zig => {
+            Box::new(language::ZigParser::new().context("Failed to create Zig parser")?)
+        }
+        Language::Haskell => {
+            Box::new(language::HaskellParser::new().context("Failed to create Haskell parser")?)
+        }
+        Language::Perl => {
+            Box::new(language::PerlParser::new().context("Failed to create Perl parser")?)
+        }
     };
     Ok(parser)
 }
@@ -155,6 +398,31 @@ struct FunctionAnalysisConfig<'a> {
     weights: &'a risk::LrsWeights,
     thresholds: &'a risk::RiskThresholds,
     pattern_thresholds: &'a crate::patterns::Thresholds,
+    sql_dialect: Option<language::SqlDialect>,
+    nd_counts: metrics::NdCounts,
+    /// How switch statements count toward CC
+    cc_mode: metrics::CcMode,
+    /// Count Go method calls toward FO
+    fo_methods: bool,
+    /// Skip functions outside the file's public API
+    public_only: bool,
+    /// Keep test functions (see `test_code`)
+    include_tests: bool,
+    /// Compute Halstead metrics (a second walk over each function's tokens)
+    halstead: bool,
+    /// Classify each function's lines as source, comment, or blank
+    line_counts: bool,
+    /// Record the line where each function's ND is reached
+    nd_lines: bool,
+    /// Record the first line and decision-point lines of each function
+    cc_lines: bool,
+    /// Discover Go closures and strip nested function bodies from their
+    /// parents (see `--separate-closures`)
+    separate_closures: bool,
+    /// How those Go closures are named (see `--anon-naming`)
+    anon_naming: language::AnonNaming,
+    /// Report NS per kind of exit
+    ns_breakdown: bool,
     source_map: &'a Lrc<SourceMap>,
 }
 
@@ -163,6 +431,7 @@ struct FunctionAnalysisConfig<'a> {
 fn analyze_function(
     function: &FunctionNode,
     path: &Path,
+    src: &str,
     language: Language,
     config: &FunctionAnalysisConfig<'_>,
 ) -> Option<report::FunctionRiskReport> {
@@ -182,7 +451,11 @@ fn analyze_function(
         return None;
     }
 
-    let raw_metrics = metrics::extract_metrics(function, &cfg);
+    let mut raw_metrics = metrics::extract_metrics_with(function, &cfg, config.nd_counts);
+    raw_metrics.cc = metrics::cc_with_mode(&raw_metrics, &cfg, config.cc_mode);
+    if !config.fo_methods {
+        metrics::exclude_method_calls(&mut raw_metrics, function);
+    }
     let (risk_components, lrs, band) = risk::analyze_risk_with_config(&raw_metrics, w, t);
 
     if options.min_lrs.is_some_and(|min| lrs < min) {
@@ -195,6 +468,7 @@ fn analyze_function(
         fo: raw_metrics.fo,
         ns: raw_metrics.ns,
         loc: raw_metrics.loc,
+        arrow_depth: Some(raw_metrics.arrow_depth),
     };
     let t2 = crate::patterns::Tier2Input {
         fan_in: None,
@@ -205,8 +479,13 @@ fn analyze_function(
         is_entrypoint: false,
     };
     let patterns = crate::patterns::classify(&t1, &t2, pt);
+    let nd_position = raw_metrics.nd_position;
+    let decisions = config.cc_lines.then(|| raw_metrics.decisions.clone());
+    let ns_breakdown = config
+        .ns_breakdown
+        .then(|| raw_metrics.ns_breakdown.clone());
 
-    Some(report::FunctionRiskReport::new(
+    let mut report = report::FunctionRiskReport::new(
         function,
         path.to_string_lossy().to_string(),
         language,
@@ -218,5 +497,28 @@ fn analyze_function(
             patterns,
         },
         source_map,
-    ))
+    );
+    if config.halstead {
+        report.metrics.halstead = crate::halstead::function_halstead(function);
+        report.metrics.maintainability = report.metrics.halstead.map(|h| {
+            crate::halstead::maintainability_index(h.volume, report.metrics.cc, report.metrics.loc)
+        });
+    }
+    if config.line_counts {
+        if let Some(lines) = crate::lines::function_line_counts(function) {
+            report.metrics.sloc = Some(lines.sloc);
+            report.metrics.comment_lines = Some(lines.comment_lines);
+            report.metrics.blank_lines = Some(lines.blank_lines);
+        }
+    }
+    if config.nd_lines {
+        report.metrics.nd_line =
+            nd_position.map(|position| metrics::nest_line(function, position, source_map));
+    }
+    // Without a breakdown the decision points are unknown, not absent
+    if let Some(decisions) = decisions.filter(|_| report.cc_breakdown.is_some()) {
+        report.metrics.cc_lines = Some(report::CcLines::new(function, &decisions, src, source_map));
+    }
+    report.metrics.ns_breakdown = ns_breakdown;
+    Some(report)
 }
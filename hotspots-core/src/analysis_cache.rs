@@ -0,0 +1,316 @@
+//! On-disk cache of per-file analysis results, so unchanged files are not
+//! reparsed between runs.
+//!
+//! Entries are keyed by the file's path as analyzed and hold the hash of the
+//! content they were computed from; a file whose content hash matches is
+//! loaded from the cache instead of parsed. The whole cache is discarded when
+//! the analyzer version or any config that changes per-file results differs
+//! from the run that wrote it (see [`cache_key`]).
+//!
+//! Only cleanly analyzed files are cached: files with syntax errors, and
+//! files skipped as minified or vendored, are analyzed every run so their
+//! warnings are repeated. Results are cached before `min_lrs` filtering and
+//! fan-in, which are applied per run.
+//!
+//! Global invariants enforced:
+//! - Results are identical to uncached analysis of the same input
+//! - A corrupt or unreadable cache is a cold start, never an error
+
+use std::collections::HashMap;
+use std::path::{Path, PathBuf};
+
+use anyhow::{Context, Result};
+use serde::{Deserialize, Serialize};
+use sha2::{Digest, Sha256};
+use swc_common::{sync::Lrc, SourceMap};
+
+use crate::analysis::{self, FileAnalysis};
+use crate::config::ResolvedConfig;
+use crate::metrics::CcBreakdown;
+use crate::report::FunctionRiskReport;
+use crate::AnalysisOptions;
+
+/// Per-file results, and the run configuration they are valid for.
+#[derive(Debug, Default, Serialize, Deserialize)]
+pub struct AnalysisCache {
+    /// [`cache_key`] of the run that wrote the cache
+    key: String,
+    files: HashMap<String, CachedFile>,
+}
+
+/// Unfiltered results for one file, valid while its content hash matches.
+#[derive(Debug, Clone, Serialize, Deserialize)]
+pub struct CachedFile {
+    hash: String,
+    reports: Vec<CachedReport>,
+    #[serde(default)]
+    top_level_decisions: usize,
+}
+
+impl CachedFile {
+    /// Entry for `analysis` (unfiltered), computed from `src`.
+    pub fn new(src: &str, analysis: &FileAnalysis) -> Self {
+        Self {
+            hash: content_hash(src),
+            reports: analysis.reports.iter().map(CachedReport::from).collect(),
+            top_level_decisions: analysis.top_level_decisions,
+        }
+    }
+}
+
+/// How [`analyze_file`] obtained a file's results.
+pub(crate) enum Outcome {
+    /// Loaded from the cache
+    Hit,
+    /// Analyzed; the entry to cache, None when the file is not cacheable
+    Miss(Option<CachedFile>),
+}
+
+/// A report with the fields `FunctionRiskReport` does not serialize.
+#[derive(Debug, Clone, Serialize, Deserialize)]
+struct CachedReport {
+    report: FunctionRiskReport,
+    #[serde(default, skip_serializing_if = "Vec::is_empty")]
+    callees: Vec<String>,
+    #[serde(default)]
+    arrow_depth: usize,
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    cc_breakdown: Option<CcBreakdown>,
+    #[serde(default)]
+    is_public: bool,
+}
+
+impl From<&FunctionRiskReport> for CachedReport {
+    fn from(report: &FunctionRiskReport) -> Self {
+        Self {
+            report: report.clone(),
+            callees: report.callees.clone(),
+            arrow_depth: report.arrow_depth,
+            cc_breakdown: report.cc_breakdown.clone(),
+            is_public: report.is_public,
+        }
+    }
+}
+
+impl From<CachedReport> for FunctionRiskReport {
+    fn from(cached: CachedReport) -> Self {
+        FunctionRiskReport {
+            callees: cached.callees,
+            arrow_depth: cached.arrow_depth,
+            cc_breakdown: cached.cc_breakdown,
+            is_public: cached.is_public,
+            ..cached.report
+        }
+    }
+}
+
+impl AnalysisCache {
+    /// An empty cache for runs under `resolved`.
+    pub fn new(resolved: &ResolvedConfig) -> Self {
+        Self {
+            key: cache_key(resolved),
+            files: HashMap::new(),
+        }
+    }
+
+    /// Cached results for `path` if they were computed from `src`.
+    pub fn lookup(&self, path: &Path, src: &str) -> Option<FileAnalysis> {
+        let entry = self.files.get(path.to_string_lossy().as_ref())?;
+        (entry.hash == content_hash(src)).then(|| FileAnalysis {
+            reports: entry
+                .reports
+                .iter()
+                .cloned()
+                .map(|cached| FunctionRiskReport {
+                    file_top_level_decisions: entry.top_level_decisions,
+                    ..FunctionRiskReport::from(cached)
+                })
+                .collect(),
+            parse_errors: 0,
+            top_level_decisions: entry.top_level_decisions,
+        })
+    }
+
+    /// Store `entry` as the results for `path`.
+    pub fn insert(&mut self, path: &Path, entry: CachedFile) {
+        self.files.insert(path.to_string_lossy().to_string(), entry);
+    }
+
+    /// Drop entries for files that no longer exist.
+    pub fn retain_existing(&mut self) {
+        self.files.retain(|path, _| Path::new(path).is_file());
+    }
+
+    /// Number of cached files.
+    pub fn len(&self) -> usize {
+        self.files.len()
+    }
+
+    pub fn is_empty(&self) -> bool {
+        self.files.is_empty()
+    }
+}
+
+/// Read and analyze `path` like [`analysis::analyze_file_detailed`], loading
+/// its results from `cache` when its content is unchanged.
+pub(crate) fn analyze_file(
+    cache: &AnalysisCache,
+    path: &Path,
+    file_index: usize,
+    options: &AnalysisOptions,
+    config: Option<&ResolvedConfig>,
+) -> Result<(FileAnalysis, Outcome)> {
+    let src = std::fs::read_to_string(path)
+        .with_context(|| format!("Failed to read file: {}", path.display()))?;
+    let below_min = |r: &FunctionRiskReport| options.min_lrs.is_some_and(|min| r.lrs < min);
+    if let Some(mut analysis) = cache.lookup(path, &src) {
+        analysis.reports.retain(|r| !below_min(r));
+        return Ok((analysis, Outcome::Hit));
+    }
+
+    let cm: Lrc<SourceMap> = Default::default();
+    let unfiltered = AnalysisOptions {
+        min_lrs: None,
+        top_n: options.top_n,
+    };
+    let mut analysis =
+        analysis::analyze_source_detailed(path, &src, &cm, file_index, &unfiltered, config)?;
+    let cacheable = analysis.parse_errors == 0 && analysis::source_skip(path, &src).is_none();
+    let entry = cacheable.then(|| CachedFile::new(&src, &analysis));
+    analysis.reports.retain(|r| !below_min(r));
+    Ok((analysis, Outcome::Miss(entry)))
+}
+
+/// Version of the per-file metric algorithms. Bump it with any change that
+/// alters a function's metrics for the same source and config, so caches
+/// written by builds with the same release number are not reused.
+const METRICS_VERSION: u32 = 1;
+
+fn cache_path(project_root: &Path) -> PathBuf {
+    crate::snapshot::hotspots_dir(project_root).join("analysis-cache.json.zst")
+}
+
+/// Everything a cached result depends on besides the file's content: the
+/// analyzer and metrics versions, the config that changes per-file results, and the
+/// working directory (anonymous function names embed the display path).
+fn cache_key(resolved: &ResolvedConfig) -> String {
+    let cwd = std::env::current_dir().unwrap_or_default();
+    format!(
+        "{}|{}|{}|{}",
+        env!("CARGO_PKG_VERSION"),
+        METRICS_VERSION,
+        crate::incremental::config_key(resolved),
+        cwd.display()
+    )
+}
+
+/// SHA-256 of a file's content, hex-encoded.
+fn content_hash(src: &str) -> String {
+    format!("{:x}", Sha256::digest(src.as_bytes()))
+}
+
+/// Load the analysis cache for `project_root`, for runs under `resolved`.
+///
+/// A missing cache, one written by another analyzer version or config, or
+/// one that fails to load (with a warning) yields an empty cache.
+pub fn read_analysis_cache(project_root: &Path, resolved: &ResolvedConfig) -> AnalysisCache {
+    let path = cache_path(project_root);
+    if !path.exists() {
+        return AnalysisCache::new(resolved);
+    }
+    match load_compressed_json(&path) {
+        Ok(cache) if cache.key == cache_key(resolved) => cache,
+        Ok(_) => AnalysisCache::new(resolved),
+        Err(e) => {
+            eprintln!("warning: failed to load analysis cache (proceeding cold): {e}");
+            AnalysisCache::new(resolved)
+        }
+    }
+}
+
+fn load_compressed_json(path: &Path) -> Result<AnalysisCache> {
+    let compressed = std::fs::read(path)
+        .with_context(|| format!("failed to read analysis cache: {}", path.display()))?;
+    let bytes = zstd::decode_all(compressed.as_slice())
+        .with_context(|| format!("failed to decompress analysis cache: {}", path.display()))?;
+    let json = std::str::from_utf8(&bytes).context("analysis cache is not valid UTF-8")?;
+    serde_json::from_str(json).context("failed to parse analysis cache JSON")
+}
+
+/// Write the analysis cache to disk (zstd level 3).
+pub fn write_analysis_cache(project_root: &Path, cache: &AnalysisCache) -> Result<()> {
+    let path = cache_path(project_root);
+    if let Some(parent) = path.parent() {
+        std::fs::create_dir_all(parent)
+            .with_context(|| format!("failed to create directory: {}", parent.display()))?;
+    }
+    let json = serde_json::to_string(cache).context("failed to serialize analysis cache")?;
+    let compressed =
+        zstd::encode_all(json.as_bytes(), 3).context("failed to compress analysis cache")?;
+    std::fs::write(&path, &compressed)
+        .with_context(|| format!("failed to write analysis cache: {}", path.display()))
+}
+
+/// Delete the analysis cache for `project_root`. Returns whether one existed.
+pub fn clear_analysis_cache(project_root: &Path) -> Result<bool> {
+    let path = cache_path(project_root);
+    match std::fs::remove_file(&path) {
+        Ok(()) => Ok(true),
+        Err(e) if e.kind() == std::io::ErrorKind::NotFound => Ok(false),
+        Err(e) => {
+            Err(e).with_context(|| format!("failed to remove analysis cache: {}", path.display()))
+        }
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn test_lookup_requires_matching_content() {
+        let dir = tempfile::tempdir().unwrap();
+        let file = dir.path().join("a.ts");
+        let src = "if (globalThis.debug) { console.log(1); }\n\
+                   function a(x: number) { if (x) { return 1; } return 0; }\n";
+        std::fs::write(&file, src).unwrap();
+
+        let resolved = ResolvedConfig::defaults().unwrap();
+        let options = AnalysisOptions {
+            min_lrs: None,
+            top_n: None,
+        };
+        let mut cache = AnalysisCache::new(&resolved);
+        let (analysis, outcome) =
+            analyze_file(&cache, &file, 0, &options, Some(&resolved)).unwrap();
+        let Outcome::Miss(Some(entry)) = outcome else {
+            panic!("expected a cacheable miss");
+        };
+        cache.insert(&file, entry);
+        write_analysis_cache(dir.path(), &cache).unwrap();
+
+        let loaded = read_analysis_cache(dir.path(), &resolved);
+        let (hit, outcome) = analyze_file(&loaded, &file, 0, &options, Some(&resolved)).unwrap();
+        assert!(matches!(outcome, Outcome::Hit));
+        assert_eq!(hit.reports.len(), 1);
+        assert_eq!(hit.reports[0].metrics, analysis.reports[0].metrics);
+        assert_eq!(
+            hit.reports[0].cc_breakdown,
+            analysis.reports[0].cc_breakdown
+        );
+        assert_eq!(hit.top_level_decisions, 1);
+        assert_eq!(hit.reports[0].file_top_level_decisions, 1);
+        std::fs::write(&file, "function a() {}\n").unwrap();
+        let (_, outcome) = analyze_file(&loaded, &file, 0, &options, Some(&resolved)).unwrap();
+        assert!(matches!(outcome, Outcome::Miss(Some(_))));
+
+        // A config that changes results invalidates every entry
+        let mut weighted = ResolvedConfig::defaults().unwrap();
+        weighted.weight_cc += 1.0;
+        assert!(read_analysis_cache(dir.path(), &weighted).is_empty());
+
+        assert!(clear_analysis_cache(dir.path()).unwrap());
+        assert!(!clear_analysis_cache(dir.path()).unwrap());
+        assert!(read_analysis_cache(dir.path(), &resolved).is_empty());
+    }
+}
@@ -0,0 +1,189 @@
+//! Stable API for embedding hotspots in another Rust program
+//!
+//! The other public modules exist to serve the CLI and change as it does.
+//! The items here are the supported surface: they only grow (new fields come
+//! with defaults on [`AnalyzeOptions`]) and are not renamed or removed within
+//! a major version.
+//!
+//! ```no_run
+//! use hotspots_core::{analyze_path, AnalyzeOptions};
+//! use std::path::Path;
+//!
+//! for file in analyze_path(Path::new("src"), &AnalyzeOptions::default())? {
+//!     for f in &file.functions {
+//!         println!("{}:{} {} cc={}", file.path.display(), f.start_line, f.name, f.cc);
+//!     }
+//! }
+//! # Ok::<(), anyhow::Error>(())
+//! ```
+
+use crate::language::Language;
+use crate::report::FunctionRiskReport;
+use crate::{AnalysisOptions, ResolvedConfig};
+use anyhow::Result;
+use std::collections::BTreeMap;
+use std::path::{Path, PathBuf};
+use swc_common::{sync::Lrc, SourceMap};
+
+/// Options for [`analyze_path`]
+#[derive(Debug, Default, Clone, Copy)]
+pub struct AnalyzeOptions<'a> {
+    /// Leave out functions whose LRS is below this value
+    pub min_lrs: Option<f64>,
+    /// Weights, thresholds, include/exclude globs, and ND constructs from a
+    /// loaded `.hotspotsrc.json` (see [`crate::config`]); defaults when `None`
+    pub config: Option<&'a ResolvedConfig>,
+}
+
+/// Functions found in one source file
+#[derive(Debug, Clone, PartialEq)]
+pub struct FileReport {
+    pub path: PathBuf,
+    pub language: Language,
+    /// Ordered by start line
+    pub functions: Vec<FunctionMetrics>,
+}
+
+/// Complexity metrics of one function
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub struct FunctionMetrics {
+    /// Function name; anonymous functions are `<anonymous>@<file>:<line>`
+    pub name: String,
+    /// 1-based line the function starts on
+    pub start_line: u32,
+    /// 1-based line the function ends on
+    pub end_line: u32,
+    /// Cyclomatic complexity
+    pub cc: u32,
+    /// Maximum nesting depth
+    pub nd: u32,
+    /// Fan-out: distinct functions called
+    pub fo: u32,
+    /// Non-structured exits (early `return`, `break`, `continue`, `throw`)
+    pub ns: u32,
+}
+
+impl FunctionMetrics {
+    fn from_report(report: &FunctionRiskReport) -> Self {
+        let start_line = report.line.max(1);
+        FunctionMetrics {
+            name: report.function.clone(),
+            start_line,
+            end_line: start_line + report.metrics.loc.saturating_sub(1),
+            cc: report.metrics.cc,
+            nd: report.metrics.nd,
+            fo: report.metrics.fo,
+            ns: report.metrics.ns,
+        }
+    }
+}
+
+/// Analyze a file, or every supported file under a directory.
+///
+/// Directories are walked the way the CLI walks them (`node_modules`, `target`,
+/// hidden directories, and the like are skipped). Files that cannot be read or
+/// parsed are skipped with a warning on stderr. Reports are ordered by path.
+pub fn analyze_path(path: &Path, opts: &AnalyzeOptions) -> Result<Vec<FileReport>> {
+    let reports = crate::analyze_with_config(
+        path,
+        AnalysisOptions {
+            min_lrs: opts.min_lrs,
+            top_n: None,
+        },
+        opts.config,
+    )?;
+
+    let mut files: BTreeMap<&str, FileReport> = BTreeMap::new();
+    for report in &reports {
+        files
+            .entry(&report.file)
+            .or_insert_with(|| FileReport {
+                path: PathBuf::from(&report.file),
+                language: report.language,
+                functions: Vec::new(),
+            })
+            .functions
+            .push(FunctionMetrics::from_report(report));
+    }
+    Ok(files
+        .into_values()
+        .map(|mut file| {
+            sort_functions(&mut file.functions);
+            file
+        })
+        .collect())
+}
+
+/// Analyze in-memory source in `language` with default settings.
+///
+/// Fails when the source cannot be parsed. Functions are ordered by start line.
+pub fn analyze_source(language: Language, src: &str) -> Result<Vec<FunctionMetrics>> {
+    // Language detection goes by extension, so give the source a file name
+    let path = PathBuf::from(format!("source.{}", language.extensions()[0]));
+    let source_map: Lrc<SourceMap> = Default::default();
+    let reports = crate::analysis::analyze_source(
+        &path,
+        src,
+        &source_map,
+        0,
+        &AnalysisOptions {
+            min_lrs: None,
+            top_n: None,
+        },
+    )?;
+    let mut functions: Vec<FunctionMetrics> =
+        reports.iter().map(FunctionMetrics::from_report).collect();
+    sort_functions(&mut functions);
+    Ok(functions)
+}
+
+fn sort_functions(functions: &mut [FunctionMetrics]) {
+    functions.sort_by(|a, b| {
+        (a.start_line, &a.name, a.end_line).cmp(&(b.start_line, &b.name, b.end_line))
+    });
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn test_every_language_has_a_detectable_extension() {
+        for language in [
+            Language::TypeScript,
+            Language::TypeScriptReact,
+            Language::JavaScript,
+            Language::JavaScriptReact,
+            Language::Go,
+            Language::Java,
+            Language::Python,
+            Language::Rust,
+            Language::Vue,
+            Language::CSharp,
+            Language::C,
+            Language::CHeader,
+            Language::Cpp,
+            Language::Sql,
+            Language::Swift,
+            Language::Php,
+            Language::Scala,
+            Language::Dart,
+            Language::Elixir,
+            Language::Lua,
+            Language::Bash,
+            Language::Zig,
+            Language::Haskell,
+            Language::Perl,
+        ] {
+            let path = PathBuf::from(format!("source.{}", language.extensions()[0]));
+            assert_eq!(Language::from_path(&path), Some(language));
+        }
+    }
+
+    #[test]
+    fn test_analyze_source_empty() {
+        assert!(analyze_source(Language::Go, "package main\n")
+            .unwrap()
+            .is_empty());
+    }
+}
@@ -21,9 +21,26 @@ pub struct FunctionId {
 pub struct FunctionNode {
     pub id: FunctionId,
     pub name: Option<String>,
+    /// Class, struct, or receiver type the function is a method of (Go, Rust,
+    /// Java, Python, JavaScript/TypeScript); None for free functions and in
+    /// other languages
+    pub owner: Option<String>,
     pub span: SourceSpan,
     pub body: FunctionBody,
     pub suppression_reason: Option<String>,
+    /// Signature complexity, computed at discovery for ECMAScript functions
+    /// because `FunctionBody::ECMAScript` keeps only the body. Rust and C#
+    /// derive it from their source in `metrics`; 0 elsewhere.
+    pub signature_complexity: usize,
+    /// Declared parameter count, computed at discovery (see `params`); 0 for
+    /// SQL
+    pub params: usize,
+    /// Part of the file's public API under the language's visibility rules
+    /// (exported, `pub`, `public`, capitalized, ...); see `public_only`
+    pub is_public: bool,
+    /// Declared `async` (JavaScript/TypeScript functions, methods, and
+    /// arrows); false in other languages
+    pub is_async: bool,
 }
 
 impl FunctionNode {
@@ -0,0 +1,319 @@
+//! Parser and analyzer throughput measurement (`hotspots bench`)
+//!
+//! This is the one module that reads the clock. Timings are reported, never fed
+//! back into analysis, so the determinism invariants in `lib.rs` still hold for
+//! everything `analyze` emits.
+//!
+//! Sources are loaded into memory before timing starts and analyzed on a single
+//! thread, so the numbers measure parse + CFG + metrics cost per language and
+//! stay comparable across machines with different core counts.
+
+use crate::language::Language;
+use crate::{analysis, AnalysisOptions};
+use anyhow::{Context, Result};
+use serde::{Deserialize, Serialize};
+use std::collections::BTreeMap;
+use std::path::{Path, PathBuf};
+use std::time::{Duration, Instant};
+use swc_common::{sync::Lrc, SourceMap};
+
+/// Schema version for `hotspots bench --format json` output.
+pub const BENCH_SCHEMA_VERSION: u32 = 1;
+
+/// A source file loaded into memory ahead of timing.
+pub struct BenchFile {
+    pub path: PathBuf,
+    pub language: Language,
+    pub source: String,
+}
+
+/// Throughput for one language.
+#[derive(Debug, Clone, Serialize, Deserialize)]
+pub struct LanguageThroughput {
+    pub language: String,
+    pub files: usize,
+    pub functions: usize,
+    pub bytes: u64,
+    /// Mean wall time for one pass over this language's files.
+    pub elapsed_secs: f64,
+    pub files_per_sec: f64,
+    pub functions_per_sec: f64,
+}
+
+/// Result of a `hotspots bench` run.
+#[derive(Debug, Clone, Serialize, Deserialize)]
+pub struct BenchReport {
+    pub schema_version: u32,
+    pub tool_version: String,
+    pub iterations: usize,
+    pub files: usize,
+    pub functions: usize,
+    pub bytes: u64,
+    /// Mean wall time for one pass over all files.
+    pub elapsed_secs: f64,
+    pub files_per_sec: f64,
+    pub functions_per_sec: f64,
+    /// Peak resident set size of the process in KiB. Linux only (`VmHWM`).
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub peak_rss_kb: Option<u64>,
+    /// Per-language breakdown, sorted by language name.
+    pub languages: Vec<LanguageThroughput>,
+}
+
+/// Load every supported source file under `path`, using the same discovery
+/// rules as `analyze`.
+pub fn load_files(path: &Path) -> Result<Vec<BenchFile>> {
+    crate::collect_source_files(path, true)?
+        .into_iter()
+        .filter_map(|p| Language::from_path(&p).map(|lang| (p, lang)))
+        .map(|(path, language)| {
+            let source = std::fs::read_to_string(&path)
+                .with_context(|| format!("Failed to read file: {}", path.display()))?;
+            Ok(BenchFile {
+                path,
+                language,
+                source,
+            })
+        })
+        .collect()
+}
+
+/// Analyze one file and return the number of functions reported.
+///
+/// Files that fail to parse count as zero functions, matching how `analyze`
+/// skips them.
+pub fn analyze_file(file: &BenchFile, file_index: usize) -> usize {
+    let cm: Lrc<SourceMap> = Default::default();
+    let options = AnalysisOptions {
+        min_lrs: None,
+        top_n: None,
+    };
+    analysis::analyze_source(&file.path, &file.source, &cm, file_index, &options)
+        .map(|reports| reports.len())
+        .unwrap_or(0)
+}
+
+/// Run `iterations` sequential passes over every file under `path`.
+pub fn run(path: &Path, iterations: usize) -> Result<BenchReport> {
+    if iterations == 0 {
+        anyhow::bail!("iterations must be at least 1");
+    }
+    let files = load_files(path)?;
+    if files.is_empty() {
+        anyhow::bail!("no supported source files found under {}", path.display());
+    }
+
+    // language name → (files, functions, bytes, total elapsed across iterations)
+    type LangAcc = (usize, usize, u64, Duration);
+    let mut per_language: BTreeMap<&'static str, LangAcc> = BTreeMap::new();
+    for iteration in 0..iterations {
+        for (file_index, file) in files.iter().enumerate() {
+            let start = Instant::now();
+            let functions = analyze_file(file, file_index);
+            let elapsed = start.elapsed();
+
+            let entry =
+                per_language
+                    .entry(file.language.name())
+                    .or_insert((0, 0, 0, Duration::ZERO));
+            if iteration == 0 {
+                entry.0 += 1;
+                entry.1 += functions;
+                entry.2 += file.source.len() as u64;
+            }
+            entry.3 += elapsed;
+        }
+    }
+
+    let languages: Vec<LanguageThroughput> = per_language
+        .into_iter()
+        .map(|(language, (files, functions, bytes, total))| {
+            let elapsed_secs = total.as_secs_f64() / iterations as f64;
+            LanguageThroughput {
+                language: language.to_string(),
+                files,
+                functions,
+                bytes,
+                elapsed_secs,
+                files_per_sec: per_sec(files, elapsed_secs),
+                functions_per_sec: per_sec(functions, elapsed_secs),
+            }
+        })
+        .collect();
+
+    let file_count: usize = languages.iter().map(|l| l.files).sum();
+    let functions: usize = languages.iter().map(|l| l.functions).sum();
+    let elapsed_secs: f64 = languages.iter().map(|l| l.elapsed_secs).sum();
+    Ok(BenchReport {
+        schema_version: BENCH_SCHEMA_VERSION,
+        tool_version: env!("CARGO_PKG_VERSION").to_string(),
+        iterations,
+        files: file_count,
+        functions,
+        bytes: languages.iter().map(|l| l.bytes).sum(),
+        elapsed_secs,
+        files_per_sec: per_sec(file_count, elapsed_secs),
+        functions_per_sec: per_sec(functions, elapsed_secs),
+        peak_rss_kb: peak_rss_kb(),
+        languages,
+    })
+}
+
+fn per_sec(count: usize, secs: f64) -> f64 {
+    if secs > 0.0 {
+        count as f64 / secs
+    } else {
+        0.0
+    }
+}
+
+/// Peak resident set size in KiB, read from `/proc/self/status`.
+fn peak_rss_kb() -> Option<u64> {
+    let status = std::fs::read_to_string("/proc/self/status").ok()?;
+    status
+        .lines()
+        .find_map(|line| line.strip_prefix("VmHWM:"))?
+        .trim()
+        .trim_end_matches("kB")
+        .trim()
+        .parse()
+        .ok()
+}
+
+impl BenchReport {
+    /// Compare throughput against a baseline run.
+    ///
+    /// Returns one message per metric whose throughput dropped by more than
+    /// `max_regression_pct` percent: overall files/sec and functions/sec, plus
+    /// files/sec for every language present in both reports. Empty means no
+    /// regression.
+    pub fn regressions(&self, baseline: &BenchReport, max_regression_pct: f64) -> Vec<String> {
+        let mut out = Vec::new();
+        let mut check = |label: &str, current: f64, base: f64| {
+            if base <= 0.0 {
+                return;
+            }
+            let drop_pct = (base - current) / base * 100.0;
+            if drop_pct > max_regression_pct {
+                out.push(format!(
+                    "{label}: {current:.1}/s vs baseline {base:.1}/s ({drop_pct:.1}% slower, limit {max_regression_pct:.1}%)"
+                ));
+            }
+        };
+        check("files", self.files_per_sec, baseline.files_per_sec);
+        check(
+            "functions",
+            self.functions_per_sec,
+            baseline.functions_per_sec,
+        );
+        for lang in &self.languages {
+            if let Some(base) = baseline
+                .languages
+                .iter()
+                .find(|b| b.language == lang.language)
+            {
+                check(
+                    &format!("{} files", lang.language),
+                    lang.files_per_sec,
+                    base.files_per_sec,
+                );
+            }
+        }
+        out
+    }
+
+    pub fn to_json(&self) -> Result<String> {
+        Ok(serde_json::to_string_pretty(self)?)
+    }
+
+    pub fn from_json(json: &str) -> Result<Self> {
+        Ok(serde_json::from_str(json)?)
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    fn fixtures_dir(name: &str) -> PathBuf {
+        PathBuf::from(env!("CARGO_MANIFEST_DIR"))
+            .parent()
+            .unwrap()
+            .join("tests")
+            .join("fixtures")
+            .join(name)
+    }
+
+    fn report(files_per_sec: f64, functions_per_sec: f64, go_fps: f64) -> BenchReport {
+        BenchReport {
+            schema_version: BENCH_SCHEMA_VERSION,
+            tool_version: "0.0.0".to_string(),
+            iterations: 1,
+            files: 10,
+            functions: 100,
+            bytes: 1000,
+            elapsed_secs: 1.0,
+            files_per_sec,
+            functions_per_sec,
+            peak_rss_kb: None,
+            languages: vec![LanguageThroughput {
+                language: "Go".to_string(),
+                files: 10,
+                functions: 100,
+                bytes: 1000,
+                elapsed_secs: 1.0,
+                files_per_sec: go_fps,
+                functions_per_sec,
+            }],
+        }
+    }
+
+    #[test]
+    fn test_run_counts_files_and_functions() {
+        let report = run(&fixtures_dir("go"), 1).unwrap();
+        assert!(report.files > 0);
+        assert!(report.functions > 0);
+        assert_eq!(report.languages.len(), 1);
+        assert_eq!(report.languages[0].language, "Go");
+        assert_eq!(report.languages[0].files, report.files);
+    }
+
+    #[test]
+    fn test_run_counts_are_per_pass() {
+        let one = run(&fixtures_dir("python"), 1).unwrap();
+        let three = run(&fixtures_dir("python"), 3).unwrap();
+        assert_eq!(one.files, three.files);
+        assert_eq!(one.functions, three.functions);
+        assert_eq!(three.iterations, 3);
+    }
+
+    #[test]
+    fn test_run_rejects_zero_iterations() {
+        assert!(run(&fixtures_dir("go"), 0).is_err());
+    }
+
+    #[test]
+    fn test_regressions_within_limit() {
+        let base = report(100.0, 1000.0, 100.0);
+        let current = report(95.0, 950.0, 95.0);
+        assert!(current.regressions(&base, 10.0).is_empty());
+    }
+
+    #[test]
+    fn test_regressions_flags_slowdown() {
+        let base = report(100.0, 1000.0, 100.0);
+        let current = report(80.0, 1000.0, 50.0);
+        let msgs = current.regressions(&base, 10.0);
+        assert_eq!(msgs.len(), 2);
+        assert!(msgs[0].starts_with("files:"));
+        assert!(msgs[1].starts_with("Go files:"));
+    }
+
+    #[test]
+    fn test_report_json_roundtrip() {
+        let r = report(1.0, 2.0, 3.0);
+        let back = BenchReport::from_json(&r.to_json().unwrap()).unwrap();
+        assert_eq!(back.files_per_sec, 1.0);
+        assert_eq!(back.languages[0].language, "Go");
+    }
+}
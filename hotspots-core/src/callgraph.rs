@@ -4,6 +4,12 @@
 //! - Fan-in/fan-out (structural coupling)
 //! - PageRank (importance/centrality)
 //! - Betweenness centrality (critical paths)
+//! - Recursion (self-calls and strongly connected components)
+//!
+//! Calls are resolved by name across every analyzed file (see
+//! `build_call_graph`). A callee that matches no analyzed function is an
+//! external call: it adds no edge, and is only counted per caller
+//! ([`CallGraph::external_calls`]).
 //!
 //! ## Limitations (by design)
 //!
@@ -18,7 +24,7 @@
 //! architecture. Advanced call tracking (including external dependencies and runtime
 //! analysis) is reserved for future cloud/pro versions.
 
-use std::collections::{HashMap, VecDeque};
+use std::collections::{BTreeMap, HashMap, HashSet, VecDeque};
 
 /// Call graph for a codebase.
 ///
@@ -31,6 +37,11 @@ pub struct CallGraph {
     ids: Vec<String>,
     id_to_idx: HashMap<String, u32>,
     adj: Vec<Vec<u32>>,
+    /// Nodes that call themselves. Kept apart from `adj` so self-calls do not
+    /// count toward fan-in, fan-out, or centrality.
+    self_calls: HashSet<u32>,
+    /// Distinct callee names of each node that matched no analyzed function
+    external_calls: Vec<usize>,
     /// Total callee names found in ASTs across all functions
     pub total_callee_names: usize,
     /// Callee names that resolved to a known internal function ID
@@ -57,6 +68,8 @@ impl CallGraph {
             ids: Vec::new(),
             id_to_idx: HashMap::new(),
             adj: Vec::new(),
+            self_calls: HashSet::new(),
+            external_calls: Vec::new(),
             total_callee_names: 0,
             resolved_callee_names: 0,
         }
@@ -71,6 +84,7 @@ impl CallGraph {
         self.id_to_idx.insert(id.clone(), idx);
         self.ids.push(id);
         self.adj.push(Vec::new());
+        self.external_calls.push(0);
         idx
     }
 
@@ -103,6 +117,17 @@ impl CallGraph {
         self.adj[caller_idx as usize].push(callee_idx);
     }
 
+    /// Record that the (already interned) node `idx` calls itself.
+    pub fn add_self_call(&mut self, idx: u32) {
+        self.self_calls.insert(idx);
+    }
+
+    /// Record that the (already interned) node `idx` calls a name that matches
+    /// no analyzed function.
+    pub fn add_external_call(&mut self, idx: u32) {
+        self.external_calls[idx as usize] += 1;
+    }
+
     /// Iterate over all interned function IDs in the graph.
     pub fn all_ids(&self) -> impl Iterator<Item = &str> {
         self.ids.iter().map(|s| s.as_str())
@@ -136,6 +161,16 @@ impl CallGraph {
         }
     }
 
+    /// Number of distinct callees of a function that match no analyzed
+    /// function: standard library and dependency calls, and calls that name
+    /// matching cannot resolve.
+    pub fn external_calls(&self, function_id: &str) -> usize {
+        match self.id_to_idx.get(function_id) {
+            None => 0,
+            Some(&idx) => self.external_calls[idx as usize],
+        }
+    }
+
     /// Calculate PageRank for all functions.
     ///
     /// Uses Vec<f64> indexed by node index with swap-buffer iteration — no per-iteration
@@ -417,6 +452,33 @@ impl CallGraph {
             .collect()
     }
 
+    /// Find recursive functions: those that call themselves, and those in a
+    /// strongly connected component with other functions (mutual recursion).
+    ///
+    /// Returns a map from each recursive function's ID to the IDs of its
+    /// cycle's members, itself included, sorted. Functions not in a cycle are
+    /// absent.
+    pub fn find_cycles(&self) -> HashMap<String, Vec<String>> {
+        let scc_info = self.find_strongly_connected_components();
+        let mut members: BTreeMap<usize, Vec<&str>> = BTreeMap::new();
+        for (i, id) in self.ids.iter().enumerate() {
+            let (scc_id, scc_size) = scc_info[id];
+            if scc_size > 1 || self.self_calls.contains(&(i as u32)) {
+                members.entry(scc_id).or_default().push(id);
+            }
+        }
+
+        let mut cycles = HashMap::new();
+        for mut ids in members.into_values() {
+            ids.sort_unstable();
+            let cycle: Vec<String> = ids.iter().map(|id| id.to_string()).collect();
+            for id in ids {
+                cycles.insert(id.to_string(), cycle.clone());
+            }
+        }
+        cycles
+    }
+
     /// Compute dependency depth for all functions.
     ///
     /// Returns a map from function ID to depth (0 = entry point, None = unreachable).
@@ -608,6 +670,45 @@ mod tests {
         assert_eq!(graph.edge_count(), 0);
     }
 
+    #[test]
+    fn test_find_cycles() {
+        let mut graph = CallGraph::new();
+
+        // A -> B -> C -> B (mutual), D calls itself, E -> A (no cycle)
+        graph.add_edge("A".to_string(), "B".to_string());
+        graph.add_edge("B".to_string(), "C".to_string());
+        graph.add_edge("C".to_string(), "B".to_string());
+        let d = graph.intern("D".to_string());
+        graph.add_self_call(d);
+        graph.add_edge("E".to_string(), "A".to_string());
+
+        let cycles = graph.find_cycles();
+        assert_eq!(cycles.len(), 3);
+        assert_eq!(cycles["B"], ["B", "C"]);
+        assert_eq!(cycles["C"], ["B", "C"]);
+        assert_eq!(cycles["D"], ["D"]);
+        assert!(!cycles.contains_key("A"));
+
+        // Self-calls are not edges
+        assert_eq!(graph.fan_in("D"), 0);
+        assert_eq!(graph.fan_out("D"), 0);
+    }
+
+    #[test]
+    fn test_external_calls() {
+        let mut graph = CallGraph::new();
+        graph.add_edge("A".to_string(), "B".to_string());
+        let a = graph.intern("A".to_string());
+        graph.add_external_call(a);
+        graph.add_external_call(a);
+
+        assert_eq!(graph.external_calls("A"), 2);
+        assert_eq!(graph.external_calls("B"), 0);
+        assert_eq!(graph.external_calls("missing"), 0);
+        // External calls are not edges
+        assert_eq!(graph.fan_out("A"), 1);
+    }
+
     #[test]
     fn test_fan_in_fan_out() {
         let mut graph = CallGraph::new();
@@ -8,7 +8,7 @@
 
 pub mod builder;
 
-use std::collections::BTreeSet;
+use std::collections::{BTreeMap, BTreeSet};
 
 /// CFG node identifier
 #[derive(Debug, Clone, Copy, PartialEq, Eq, PartialOrd, Ord, Hash)]
@@ -58,6 +58,10 @@ pub struct Cfg {
     pub edges: Vec<CfgEdge>,
     pub entry: NodeId,
     pub exit: NodeId,
+    /// The condition node each switch statement dispatches from, keyed by the
+    /// statement's start byte. `--cc-mode` uses its out-edges to recount the
+    /// switch (see [`crate::metrics::CcMode`]).
+    pub switches: BTreeMap<usize, NodeId>,
 }
 
 impl Cfg {
@@ -77,6 +81,7 @@ impl Cfg {
             edges: Vec::new(),
             entry: entry_node.id,
             exit: exit_node.id,
+            switches: BTreeMap::new(),
         }
     }
 
@@ -434,6 +434,9 @@ impl CfgBuilder {
         // Switch expression evaluation (implied)
         let switch_node = self.cfg.add_node(NodeKind::Condition);
         self.cfg.add_edge(from_node, switch_node);
+        self.cfg
+            .switches
+            .insert(switch_stmt.span.lo.0 as usize, switch_node);
 
         // The join node is created lazily: it comes into existence on the first
         // `break` statement or when a case falls through past the end.  This
@@ -0,0 +1,389 @@
+//! Churn-weighted hotspots (`--mode churn`)
+//!
+//! A hotspot is complex code that keeps changing. For every function this
+//! counts the commits in a time window whose diff hunks overlap the
+//! function's lines (its churn), and multiplies that by its CC or cognitive
+//! complexity:
+//!
+//! - hunks are attributed by line range: a change to lines 40-50 only counts
+//!   for functions overlapping 40-50
+//! - function ranges are mapped back through each commit's hunks, newest
+//!   first, so a commit still matches a function whose lines have moved
+//!   since; a function stops collecting churn at the commit that added it
+//! - uncommitted changes are mapped out first and never count as churn
+//! - history follows renames (`git log --follow`)
+//! - outside a git repository every churn is 0
+//!
+//! Global invariants enforced:
+//! - hotspot_score = complexity × churn
+//! - Deterministic output ordering (score descending, then file, line)
+
+use crate::git::{self, DiffHunk};
+use crate::report::FunctionRiskReport;
+use crate::risk::RiskBand;
+use crate::snapshot::RepoPaths;
+use anyhow::Result;
+use rayon::prelude::*;
+use serde::Serialize;
+use std::collections::BTreeMap;
+use std::path::Path;
+
+/// Default `--since` window, in days
+pub const DEFAULT_WINDOW_DAYS: u64 = 90;
+
+/// Complexity that churn is multiplied by
+#[derive(Debug, Clone, Copy, PartialEq, Eq, Serialize)]
+#[serde(rename_all = "lowercase")]
+pub enum ChurnMetric {
+    Cc,
+    Cognitive,
+}
+
+/// Churn and hotspot score of one function
+#[derive(Debug, Clone, Serialize)]
+pub struct FunctionChurn {
+    pub file: String,
+    pub function: String,
+    pub line: u32,
+    pub end_line: u32,
+    pub cc: u32,
+    pub cognitive: u32,
+    pub lrs: f64,
+    pub band: RiskBand,
+    /// Commits in the window whose hunks overlap the function
+    pub churn: usize,
+    /// Complexity (per `metric`) × churn
+    pub hotspot_score: u64,
+}
+
+/// Functions ranked by hotspot score
+#[derive(Debug, Clone, Serialize)]
+pub struct ChurnReport {
+    pub window_days: u64,
+    pub metric: ChurnMetric,
+    /// False when the analyzed path is not in a git repository, so every
+    /// churn is 0
+    pub git: bool,
+    pub functions: Vec<FunctionChurn>,
+}
+
+/// Parse a `--since` window: days (`90d` or `90`) or weeks (`12w`)
+pub fn parse_window(window: &str) -> Result<u64> {
+    let (number, days_per_unit) = match window.strip_suffix('w') {
+        Some(weeks) => (weeks, 7),
+        None => (window.strip_suffix('d').unwrap_or(window), 1),
+    };
+    match number.parse::<u64>() {
+        Ok(n) if n > 0 => Ok(n * days_per_unit),
+        _ => anyhow::bail!("invalid window {window:?}: expected days (90d) or weeks (12w)"),
+    }
+}
+
+/// Score `reports` by churn over the `window_days` before the `HEAD` commit.
+///
+/// `repo_root` is None when the analyzed path is not in a git repository;
+/// churn is then 0 for every function, as it is for untracked files.
+pub fn compute_churn_report(
+    repo_root: Option<&Path>,
+    reports: &[FunctionRiskReport],
+    window_days: u64,
+    metric: ChurnMetric,
+) -> ChurnReport {
+    // Anchored at HEAD's commit time, not the wall clock, so re-running on
+    // the same commit gives the same result
+    let head = repo_root.and_then(|root| {
+        git::extract_git_context_at(root)
+            .ok()
+            .map(|ctx| (root, ctx.timestamp, RepoPaths::new(root)))
+    });
+
+    let mut by_file: BTreeMap<&str, Vec<&FunctionRiskReport>> = BTreeMap::new();
+    for report in reports {
+        by_file
+            .entry(report.file.as_str())
+            .or_default()
+            .push(report);
+    }
+    let by_file: Vec<(&str, Vec<&FunctionRiskReport>)> = by_file.into_iter().collect();
+
+    let mut functions: Vec<FunctionChurn> = by_file
+        .par_iter()
+        .flat_map_iter(|(file, reports)| {
+            let history = head.as_ref().map(|(root, until, paths)| {
+                let rel = paths.portable(file);
+                let since = until - window_days as i64 * 24 * 60 * 60;
+                let commits =
+                    git::file_hunk_history_at(root, &rel, since, *until).unwrap_or_default();
+                let uncommitted = git::working_tree_hunks_at(root, &rel).unwrap_or_default();
+                (uncommitted, commits)
+            });
+            reports.iter().map(move |report| {
+                let end_line = report.line + report.metrics.loc.saturating_sub(1);
+                let churn = history.as_ref().map_or(0, |(uncommitted, commits)| {
+                    range_before(report.line, end_line, uncommitted)
+                        .map_or(0, |(start, end)| range_churn(start, end, commits))
+                });
+                let complexity = match metric {
+                    ChurnMetric::Cc => report.metrics.cc,
+                    ChurnMetric::Cognitive => report.metrics.cognitive,
+                };
+                FunctionChurn {
+                    file: report.file.clone(),
+                    function: report.function.clone(),
+                    line: report.line,
+                    end_line,
+                    cc: report.metrics.cc,
+                    cognitive: report.metrics.cognitive,
+                    lrs: report.lrs,
+                    band: report.band,
+                    churn,
+                    hotspot_score: u64::from(complexity) * churn as u64,
+                }
+            })
+        })
+        .collect();
+
+    functions.sort_by(|a, b| {
+        b.hotspot_score
+            .cmp(&a.hotspot_score)
+            .then_with(|| a.file.cmp(&b.file))
+            .then_with(|| a.line.cmp(&b.line))
+            .then_with(|| a.function.cmp(&b.function))
+    });
+
+    ChurnReport {
+        window_days,
+        metric,
+        git: head.is_some(),
+        functions,
+    }
+}
+
+/// Commits (newest first, as hunk lists) overlapping lines `[start, end]` of
+/// the newest version, following the range back through each commit
+fn range_churn(start: u32, end: u32, commits: &[Vec<DiffHunk>]) -> usize {
+    let mut range = (start, end);
+    let mut churn = 0;
+    for hunks in commits {
+        if touches(range, hunks) {
+            churn += 1;
+        }
+        match range_before(range.0, range.1, hunks) {
+            Some(before) => range = before,
+            // The commit added the whole range: nothing older can touch it
+            None => break,
+        }
+    }
+    churn
+}
+
+/// Whether any hunk adds, changes, or removes a line inside `[start, end]`
+fn touches((start, end): (u32, u32), hunks: &[DiffHunk]) -> bool {
+    hunks.iter().any(|h| {
+        if h.new_len == 0 {
+            // Lines removed between new_start and new_start + 1
+            start <= h.new_start && h.new_start < end
+        } else {
+            h.new_start <= end && start < h.new_start + h.new_len
+        }
+    })
+}
+
+/// Lines `[start, end]` as they were numbered before `hunks` were applied, or
+/// None when the hunks added every line of the range
+fn range_before(start: u32, end: u32, hunks: &[DiffHunk]) -> Option<(u32, u32)> {
+    let start = line_before(start, hunks, false);
+    let end = line_before(end, hunks, true);
+    (start <= end).then_some((start, end))
+}
+
+/// A line's number before `hunks` were applied. A line inside a hunk maps to
+/// the first (`is_end == false`) or last old line of that hunk.
+fn line_before(line: u32, hunks: &[DiffHunk], is_end: bool) -> u32 {
+    // A zero-length side sits after its start line
+    let begin = |start: u32, len: u32| if len == 0 { start + 1 } else { start };
+    let mut before = line;
+    for h in hunks {
+        let new_begin = begin(h.new_start, h.new_len);
+        if new_begin > line {
+            break;
+        }
+        let old_begin = begin(h.old_start, h.old_len);
+        before = if line >= new_begin + h.new_len {
+            line - (new_begin + h.new_len) + old_begin + h.old_len
+        } else if is_end {
+            (old_begin + h.old_len).saturating_sub(1)
+        } else {
+            old_begin
+        };
+    }
+    before
+}
+
+/// Render the report as a text table, at most `top` functions
+pub fn render_churn_text(report: &ChurnReport, top: Option<usize>) -> String {
+    use std::fmt::Write;
+
+    let mut out = String::new();
+    if !report.git {
+        let _ = writeln!(out, "Not a git repository: churn is 0 for every function.");
+    }
+    let complexity = match report.metric {
+        ChurnMetric::Cc => "CC",
+        ChurnMetric::Cognitive => "Cog",
+    };
+    let _ = writeln!(
+        out,
+        "Hotspots: {complexity} × commits touching the function in the last {} days",
+        report.window_days
+    );
+    let _ = writeln!(
+        out,
+        "{:<40} {:>7} {:>5} {:>6}  File",
+        "Function", "Score", complexity, "Churn"
+    );
+    let _ = writeln!(out, "{}", "-".repeat(80));
+    let limit = top.unwrap_or(report.functions.len());
+    for f in report.functions.iter().take(limit) {
+        let value = match report.metric {
+            ChurnMetric::Cc => f.cc,
+            ChurnMetric::Cognitive => f.cognitive,
+        };
+        let _ = writeln!(
+            out,
+            "{:<40} {:>7} {:>5} {:>6}  {}:{}",
+            f.function, f.hotspot_score, value, f.churn, f.file, f.line
+        );
+    }
+    out
+}
+
+/// Render the report as pretty-printed JSON, at most `top` functions
+pub fn render_churn_json(report: &ChurnReport, top: Option<usize>) -> Result<String> {
+    match top {
+        Some(n) if n < report.functions.len() => {
+            let mut report = report.clone();
+            report.functions.truncate(n);
+            Ok(serde_json::to_string_pretty(&report)?)
+        }
+        _ => Ok(serde_json::to_string_pretty(report)?),
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    fn hunk(old_start: u32, old_len: u32, new_start: u32, new_len: u32) -> DiffHunk {
+        DiffHunk {
+            old_start,
+            old_len,
+            new_start,
+            new_len,
+        }
+    }
+
+    #[test]
+    fn test_parse_window() {
+        assert_eq!(parse_window("90d").unwrap(), 90);
+        assert_eq!(parse_window("30").unwrap(), 30);
+        assert_eq!(parse_window("12w").unwrap(), 84);
+        assert!(parse_window("0d").is_err());
+        assert!(parse_window("3m").is_err());
+        assert!(parse_window("").is_err());
+    }
+
+    #[test]
+    fn test_only_overlapping_hunks_count() {
+        // A change to lines 40-50 touches 45-60 but not 1-30 or 51-70
+        let commits = vec![vec![hunk(40, 11, 40, 11)]];
+        assert_eq!(range_churn(45, 60, &commits), 1);
+        assert_eq!(range_churn(1, 30, &commits), 0);
+        assert_eq!(range_churn(51, 70, &commits), 0);
+    }
+
+    #[test]
+    fn test_lines_map_back_through_insertions_and_deletions() {
+        // Newest commit inserts 5 lines after line 2, which pushed the
+        // function at old 20-30 to 25-35; the older commit changed line 21
+        let commits = vec![vec![hunk(2, 0, 3, 5)], vec![hunk(21, 1, 21, 1)]];
+        assert_eq!(range_before(25, 35, &commits[0]), Some((20, 30)));
+        assert_eq!(range_churn(25, 35, &commits), 1);
+
+        // A deletion of old lines 5-7 shifts later lines up by 3
+        let deletion = [hunk(5, 3, 4, 0)];
+        assert_eq!(range_before(10, 12, &deletion), Some((13, 15)));
+        assert!(touches((3, 8), &deletion));
+        // Removed just before the range starts
+        assert!(!touches((5, 8), &deletion));
+    }
+
+    #[test]
+    fn test_churn_stops_at_the_commit_that_added_the_function() {
+        // The newest commit added lines 10-20; the older one changed line 12
+        // of a file that did not have the function yet
+        let commits = vec![vec![hunk(9, 0, 10, 11)], vec![hunk(12, 1, 12, 1)]];
+        assert_eq!(range_before(10, 20, &commits[0]), None);
+        assert_eq!(range_churn(10, 20, &commits), 1);
+    }
+
+    #[test]
+    fn test_no_git_means_zero_churn() {
+        use crate::report::{MetricsReport, RiskReport};
+        let report = FunctionRiskReport {
+            file: "/tmp/a.ts".to_string(),
+            function: "f".to_string(),
+            owner: None,
+            line: 1,
+            language: crate::language::Language::TypeScript,
+            metrics: MetricsReport {
+                cc: 7,
+                nd: 0,
+                fo: 0,
+                fi: 0,
+                ns: 0,
+                loc: 10,
+                signature_complexity: 0,
+                params: 0,
+                guard_clauses: 0,
+                max_condition_ops: 0,
+                cognitive: 9,
+                halstead: None,
+                maintainability: None,
+                sloc: None,
+                comment_lines: None,
+                blank_lines: None,
+                nd_line: None,
+                cc_lines: None,
+                is_async: false,
+                await_in_loop: 0,
+                ns_breakdown: None,
+            },
+            risk: RiskReport {
+                r_cc: 0.0,
+                r_nd: 0.0,
+                r_fo: 0.0,
+                r_ns: 0.0,
+            },
+            lrs: 3.0,
+            band: RiskBand::Low,
+            risk_score: None,
+            suppression_reason: None,
+            patterns: vec![],
+            pattern_details: None,
+            callees: vec![],
+            explanation: None,
+            arrow_depth: 0,
+            aliases: vec![],
+            structure: None,
+            cc_breakdown: None,
+            is_public: false,
+            file_top_level_decisions: 0,
+        };
+        let churn = compute_churn_report(None, &[report], 90, ChurnMetric::Cognitive);
+        assert!(!churn.git);
+        assert_eq!(churn.functions[0].churn, 0);
+        assert_eq!(churn.functions[0].hotspot_score, 0);
+        assert_eq!(churn.functions[0].end_line, 10);
+    }
+}
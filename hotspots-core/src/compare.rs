@@ -0,0 +1,237 @@
+//! Metric comparison against a previous results file (`--compare`)
+//!
+//! Lists every function with its current metrics and the signed change from
+//! the previous results, so a reviewer sees what a change did to each
+//! function's complexity rather than only whether it regressed:
+//!
+//! ```text
+//! ~ src/api.ts::handler  lrs: 6.20 (+1.10)  cc: 12 (+3)  nd: 2  fo: 4 (+1)  ns: 1  loc: 40 (+8)  moderate → high
+//! + src/api.ts::retry    lrs: 3.10  cc: 5  nd: 1  fo: 2  ns: 0  loc: 12
+//! - src/old.ts::parse    lrs: 2.00  cc: 3  nd: 1  fo: 0  ns: 0  loc: 9  (likely renamed to src/new.ts::parse)
+//!   src/api.ts::close    lrs: 1.00  cc: 1  nd: 0  fo: 0  ns: 0  loc: 3
+//! ```
+//!
+//! `+` marks an added function, `-` a removed one (shown with its previous
+//! metrics), and `~` a changed one. A metric's change is shown only when it
+//! is not zero.
+//!
+//! Global invariants enforced:
+//! - Entries keep the order of [`crate::delta::compare_functions`]
+//!   (`function_id`)
+//! - Unchanged functions are counted even when hidden
+
+use crate::delta::{FunctionDeltaEntry, FunctionState, FunctionStatus};
+use anyhow::{Context, Result};
+
+/// Render `entries` one function per line, followed by a count of each
+/// status. `changed_only` hides unchanged functions.
+pub fn render_comparison(entries: &[FunctionDeltaEntry], changed_only: bool) -> String {
+    use std::fmt::Write;
+
+    if entries.is_empty() {
+        return "No functions found.\n".to_string();
+    }
+    let shown: Vec<&FunctionDeltaEntry> = entries
+        .iter()
+        .filter(|e| !changed_only || e.status != FunctionStatus::Unchanged)
+        .collect();
+    let id_width = shown
+        .iter()
+        .map(|e| e.function_id.chars().count())
+        .max()
+        .unwrap_or(0);
+
+    let mut out = String::new();
+    for entry in &shown {
+        let (marker, state, before) = match (&entry.status, &entry.before, &entry.after) {
+            (FunctionStatus::New, _, Some(after)) => ('+', after, None),
+            (FunctionStatus::Deleted, Some(before), _) => ('-', before, None),
+            (FunctionStatus::Modified, before, Some(after)) => ('~', after, before.as_ref()),
+            (FunctionStatus::Unchanged, _, Some(after)) => (' ', after, None),
+            _ => continue,
+        };
+        let _ = write!(
+            out,
+            "{} {:<id_width$}  {}",
+            marker,
+            entry.function_id,
+            metrics_line(state, before)
+        );
+        if let Some(before) = before.filter(|b| b.band != state.band) {
+            let _ = write!(out, "  {} → {}", before.band.as_str(), state.band.as_str());
+        }
+        if let Some(new_id) = &entry.rename_hint {
+            let _ = write!(out, "  (likely renamed to {})", new_id);
+        }
+        out.push('\n');
+    }
+
+    let count = |status: FunctionStatus| entries.iter().filter(|e| e.status == status).count();
+    if !shown.is_empty() {
+        out.push('\n');
+    }
+    let _ = writeln!(
+        out,
+        "{} added, {} removed, {} changed, {} unchanged",
+        count(FunctionStatus::New),
+        count(FunctionStatus::Deleted),
+        count(FunctionStatus::Modified),
+        count(FunctionStatus::Unchanged)
+    );
+    out
+}
+
+/// Render `entries` as a pretty-printed JSON array of delta entries.
+/// `changed_only` leaves out unchanged functions.
+pub fn render_comparison_json(
+    entries: &[FunctionDeltaEntry],
+    changed_only: bool,
+) -> Result<String> {
+    let shown: Vec<&FunctionDeltaEntry> = entries
+        .iter()
+        .filter(|e| !changed_only || e.status != FunctionStatus::Unchanged)
+        .collect();
+    serde_json::to_string_pretty(&shown).context("failed to serialize comparison to JSON")
+}
+
+/// `state`'s metrics, each followed by its change from `before` when there
+/// is one
+fn metrics_line(state: &FunctionState, before: Option<&FunctionState>) -> String {
+    let m = &state.metrics;
+    let lrs = match before.map(|b| state.lrs - b.lrs) {
+        // Hidden when it rounds to zero at the precision shown
+        Some(d) if (d * 100.0).round() != 0.0 => format!("{:.2} ({:+.2})", state.lrs, d),
+        _ => format!("{:.2}", state.lrs),
+    };
+    let count = |now: u32, then: Option<u32>| match then.map(|t| now as i64 - t as i64) {
+        Some(d) if d != 0 => format!("{} ({:+})", now, d),
+        _ => now.to_string(),
+    };
+    let b = before.map(|b| &b.metrics);
+    format!(
+        "lrs: {}  cc: {}  nd: {}  fo: {}  ns: {}  loc: {}",
+        lrs,
+        count(m.cc, b.map(|b| b.cc)),
+        count(m.nd, b.map(|b| b.nd)),
+        count(m.fo, b.map(|b| b.fo)),
+        count(m.ns, b.map(|b| b.ns)),
+        count(m.loc, b.map(|b| b.loc)),
+    )
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::delta::compare_functions;
+    use crate::language::Language;
+    use crate::report::{FunctionRiskReport, MetricsReport, RiskReport};
+    use crate::risk::RiskBand;
+    use crate::snapshot::FunctionSnapshot;
+
+    fn function(file: &str, name: &str, cc: u32, lrs: f64, band: RiskBand) -> FunctionSnapshot {
+        FunctionSnapshot::from(FunctionRiskReport {
+            file: file.to_string(),
+            function: name.to_string(),
+            owner: None,
+            line: 1,
+            language: Language::TypeScript,
+            metrics: MetricsReport {
+                cc,
+                cognitive: 0,
+                nd: 1,
+                fo: 2,
+                fi: 0,
+                ns: 0,
+                loc: 10,
+                signature_complexity: 0,
+                params: 0,
+                guard_clauses: 0,
+                max_condition_ops: 0,
+                halstead: None,
+                maintainability: None,
+                sloc: None,
+                comment_lines: None,
+                blank_lines: None,
+                nd_line: None,
+                cc_lines: None,
+                is_async: false,
+                await_in_loop: 0,
+                ns_breakdown: None,
+            },
+            risk: RiskReport {
+                r_cc: 0.0,
+                r_nd: 0.0,
+                r_fo: 0.0,
+                r_ns: 0.0,
+            },
+            lrs,
+            band,
+            risk_score: None,
+            suppression_reason: None,
+            patterns: vec![],
+            pattern_details: None,
+            callees: vec![],
+            explanation: None,
+            arrow_depth: 0,
+            aliases: vec![],
+            structure: None,
+            cc_breakdown: None,
+            is_public: false,
+            file_top_level_decisions: 0,
+        })
+    }
+
+    fn before_and_after() -> Vec<FunctionDeltaEntry> {
+        let before = vec![
+            function("src/a.ts", "handler", 9, 4.5, RiskBand::Moderate),
+            function("src/a.ts", "close", 1, 1.0, RiskBand::Low),
+            function("src/old.ts", "legacy", 3, 2.0, RiskBand::Low),
+        ];
+        let after = vec![
+            function("src/a.ts", "handler", 12, 6.25, RiskBand::High),
+            function("src/a.ts", "close", 1, 1.0, RiskBand::Low),
+            function("src/a.ts", "retry", 5, 3.1, RiskBand::Moderate),
+        ];
+        compare_functions(&before, &after)
+    }
+
+    #[test]
+    fn test_every_function_with_signed_deltas() {
+        let out = render_comparison(&before_and_after(), false);
+        assert_eq!(
+            out,
+            "  src/a.ts::close     lrs: 1.00  cc: 1  nd: 1  fo: 2  ns: 0  loc: 10\n\
+             ~ src/a.ts::handler   lrs: 6.25 (+1.75)  cc: 12 (+3)  nd: 1  fo: 2  ns: 0  loc: 10  moderate → high\n\
+             + src/a.ts::retry     lrs: 3.10  cc: 5  nd: 1  fo: 2  ns: 0  loc: 10\n\
+             - src/old.ts::legacy  lrs: 2.00  cc: 3  nd: 1  fo: 2  ns: 0  loc: 10\n\
+             \n\
+             1 added, 1 removed, 1 changed, 1 unchanged\n"
+        );
+    }
+
+    #[test]
+    fn test_changed_only_hides_unchanged() {
+        let entries = before_and_after();
+        let out = render_comparison(&entries, true);
+        assert!(!out.contains("close"), "{out}");
+        assert!(out.starts_with("~ src/a.ts::handler   lrs: 6.25"), "{out}");
+        assert!(out.ends_with("1 added, 1 removed, 1 changed, 1 unchanged\n"));
+
+        let json: Vec<serde_json::Value> =
+            serde_json::from_str(&render_comparison_json(&entries, true).unwrap()).unwrap();
+        let statuses: Vec<&str> = json.iter().map(|e| e["status"].as_str().unwrap()).collect();
+        assert_eq!(statuses, vec!["modified", "new", "deleted"]);
+        assert_eq!(json[0]["delta"]["cc"], 3);
+    }
+
+    #[test]
+    fn test_nothing_changed_and_nothing_found() {
+        let same = vec![function("src/a.ts", "f", 2, 1.5, RiskBand::Low)];
+        let entries = compare_functions(&same, &same);
+        assert_eq!(
+            render_comparison(&entries, true),
+            "0 added, 0 removed, 0 changed, 1 unchanged\n"
+        );
+        assert_eq!(render_comparison(&[], false), "No functions found.\n");
+    }
+}
@@ -0,0 +1,301 @@
+//! Component-level complexity rollups (`--group-by component`)
+//!
+//! Front-end teams review components, not individual callbacks. A rollup sums
+//! the CC of every function that belongs to one component:
+//!
+//! - Vue: a single-file component is one component, named after the file;
+//!   every function in its `<script>` (methods, computed, watchers, handlers,
+//!   `setup`) belongs to it
+//! - React: a top-level function with a PascalCase name in a `.jsx`/`.tsx`
+//!   file (`function Card()` or `const Card = () => ...`) is a function
+//!   component; it and every function nested in it (handlers, effects, render
+//!   callbacks) belong to it
+//!
+//! Functions outside any component are not rolled up, and class components
+//! are not recognized.
+//!
+//! Global invariants enforced:
+//! - Component CC = sum of its functions' CC
+//! - Deterministic output ordering (CC descending, then file, line)
+
+use crate::language::Language;
+use crate::report::FunctionRiskReport;
+use serde::Serialize;
+use std::collections::BTreeMap;
+use std::path::Path;
+
+/// Framework whose component boundaries were recognized
+#[derive(Debug, Clone, Copy, PartialEq, Eq, Serialize)]
+#[serde(rename_all = "lowercase")]
+pub enum Framework {
+    Vue,
+    React,
+}
+
+/// Complexity of one component
+#[derive(Debug, Clone, Serialize)]
+pub struct ComponentRollup {
+    pub file: String,
+    pub component: String,
+    pub framework: Framework,
+    /// First line of the component (its function for React, its first
+    /// function for Vue)
+    pub line: u32,
+    /// Number of functions rolled up, the React component function included
+    pub functions: usize,
+    /// Component complexity: the sum of its functions' CC
+    pub cc: u32,
+    /// Highest CC among its functions
+    pub max_cc: u32,
+    /// Highest LRS among its functions
+    pub max_lrs: f64,
+}
+
+/// Roll function reports up into components, most complex first.
+///
+/// Pass unfiltered reports: functions dropped by `--top` or `--min-lrs` would
+/// be missing from the sums.
+pub fn component_rollups(reports: &[FunctionRiskReport]) -> Vec<ComponentRollup> {
+    let mut by_file: BTreeMap<&str, Vec<&FunctionRiskReport>> = BTreeMap::new();
+    for report in reports {
+        by_file
+            .entry(report.file.as_str())
+            .or_default()
+            .push(report);
+    }
+
+    let mut rollups = Vec::new();
+    for (file, mut functions) in by_file {
+        functions.sort_by_key(|f| f.line);
+        match functions[0].language {
+            Language::Vue => {
+                let name = Path::new(file)
+                    .file_stem()
+                    .map(|s| s.to_string_lossy().into_owned())
+                    .unwrap_or_else(|| file.to_string());
+                rollups.push(rollup(file, name, Framework::Vue, &functions));
+            }
+            Language::JavaScriptReact | Language::TypeScriptReact => {
+                rollups.extend(react_components(file, &functions));
+            }
+            _ => {}
+        }
+    }
+
+    rollups.sort_by(|a, b| {
+        b.cc.cmp(&a.cc)
+            .then_with(|| a.file.cmp(&b.file))
+            .then_with(|| a.line.cmp(&b.line))
+    });
+    rollups
+}
+
+/// Function components in one file; `functions` are sorted by line
+fn react_components(file: &str, functions: &[&FunctionRiskReport]) -> Vec<ComponentRollup> {
+    let mut components = Vec::new();
+    let mut i = 0;
+    while i < functions.len() {
+        let outer = functions[i];
+        let end = end_line(outer);
+        // Everything starting inside `outer` is nested in it
+        let nested = functions[i + 1..]
+            .iter()
+            .take_while(|f| f.line <= end)
+            .count();
+        if is_component_name(&outer.function) {
+            components.push(rollup(
+                file,
+                outer.function.clone(),
+                Framework::React,
+                &functions[i..=i + nested],
+            ));
+        }
+        i += nested + 1;
+    }
+    components
+}
+
+/// ECMAScript LOC is the function's line span, so it locates the last line
+fn end_line(report: &FunctionRiskReport) -> u32 {
+    report.line + report.metrics.loc.saturating_sub(1)
+}
+
+/// React requires components to start with an upper-case letter
+fn is_component_name(name: &str) -> bool {
+    name.chars().next().is_some_and(|c| c.is_ascii_uppercase())
+}
+
+fn rollup(
+    file: &str,
+    component: String,
+    framework: Framework,
+    functions: &[&FunctionRiskReport],
+) -> ComponentRollup {
+    ComponentRollup {
+        file: file.to_string(),
+        component,
+        framework,
+        line: functions.first().map_or(0, |f| f.line),
+        functions: functions.len(),
+        cc: functions.iter().map(|f| f.metrics.cc).sum(),
+        max_cc: functions.iter().map(|f| f.metrics.cc).max().unwrap_or(0),
+        max_lrs: functions.iter().map(|f| f.lrs).fold(0.0, f64::max),
+    }
+}
+
+/// Render rollups as a text table
+pub fn render_components_text(rollups: &[ComponentRollup]) -> String {
+    use std::fmt::Write;
+
+    if rollups.is_empty() {
+        return "No Vue or React components found.\n".to_string();
+    }
+    let mut out = String::new();
+    let _ = writeln!(
+        out,
+        "{:<30} {:<6} {:>9} {:>5} {:>7} {:>8}  File",
+        "Component", "Kind", "Functions", "CC", "Max CC", "Max LRS"
+    );
+    let _ = writeln!(out, "{}", "-".repeat(80));
+    for r in rollups {
+        let kind = match r.framework {
+            Framework::Vue => "vue",
+            Framework::React => "react",
+        };
+        let _ = writeln!(
+            out,
+            "{:<30} {:<6} {:>9} {:>5} {:>7} {:>8.2}  {}:{}",
+            r.component, kind, r.functions, r.cc, r.max_cc, r.max_lrs, r.file, r.line
+        );
+    }
+    out
+}
+
+/// Render rollups as pretty-printed JSON
+pub fn render_components_json(rollups: &[ComponentRollup]) -> String {
+    serde_json::to_string_pretty(rollups).unwrap_or_else(|_| "[]".to_string())
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::report::{MetricsReport, RiskReport};
+    use crate::risk::RiskBand;
+
+    fn make_report(
+        file: &str,
+        language: Language,
+        function: &str,
+        line: u32,
+        loc: u32,
+        cc: u32,
+    ) -> FunctionRiskReport {
+        FunctionRiskReport {
+            file: file.to_string(),
+            function: function.to_string(),
+            owner: None,
+            line,
+            language,
+            metrics: MetricsReport {
+                cc,
+                nd: 0,
+                fo: 0,
+                fi: 0,
+                ns: 0,
+                loc,
+                signature_complexity: 0,
+                params: 0,
+                guard_clauses: 0,
+                max_condition_ops: 0,
+                cognitive: 0,
+                halstead: None,
+                maintainability: None,
+                sloc: None,
+                comment_lines: None,
+                blank_lines: None,
+                nd_line: None,
+                cc_lines: None,
+                is_async: false,
+                await_in_loop: 0,
+                ns_breakdown: None,
+            },
+            risk: RiskReport {
+                r_cc: 0.0,
+                r_nd: 0.0,
+                r_fo: 0.0,
+                r_ns: 0.0,
+            },
+            lrs: f64::from(cc),
+            band: RiskBand::Low,
+            risk_score: None,
+            suppression_reason: None,
+            patterns: vec![],
+            pattern_details: None,
+            callees: vec![],
+            explanation: None,
+            arrow_depth: 0,
+            aliases: vec![],
+            structure: None,
+            cc_breakdown: None,
+            is_public: false,
+            file_top_level_decisions: 0,
+        }
+    }
+
+    #[test]
+    fn test_react_components_own_nested_functions() {
+        let file = "/repo/Cart.tsx";
+        let tsx = Language::TypeScriptReact;
+        let reports = vec![
+            make_report(file, tsx, "formatPrice", 1, 3, 2),
+            make_report(file, tsx, "Cart", 5, 20, 3),
+            make_report(file, tsx, "handleRemove", 7, 5, 4),
+            make_report(file, tsx, "<anonymous>@Cart.tsx:15", 15, 1, 1),
+            make_report(file, tsx, "CartRow", 26, 4, 1),
+        ];
+        let rollups = component_rollups(&reports);
+
+        let names: Vec<(&str, u32, usize)> = rollups
+            .iter()
+            .map(|r| (r.component.as_str(), r.cc, r.functions))
+            .collect();
+        // formatPrice is outside any component
+        assert_eq!(names, vec![("Cart", 8, 3), ("CartRow", 1, 1)]);
+        assert_eq!(rollups[0].max_cc, 4);
+        assert_eq!(rollups[0].line, 5);
+        assert_eq!(rollups[0].framework, Framework::React);
+    }
+
+    #[test]
+    fn test_vue_file_is_one_component() {
+        let file = "/repo/UserCard.vue";
+        let reports = vec![
+            make_report(file, Language::Vue, "validate", 20, 8, 4),
+            make_report(file, Language::Vue, "data", 10, 5, 1),
+            make_report("/repo/util.ts", Language::TypeScript, "Helper", 1, 3, 9),
+        ];
+        let rollups = component_rollups(&reports);
+        assert_eq!(rollups.len(), 1);
+        assert_eq!(rollups[0].component, "UserCard");
+        assert_eq!(rollups[0].framework, Framework::Vue);
+        assert_eq!(rollups[0].cc, 5);
+        assert_eq!(rollups[0].line, 10);
+    }
+
+    #[test]
+    fn test_json_shape() {
+        let reports = vec![make_report(
+            "/repo/App.jsx",
+            Language::JavaScriptReact,
+            "App",
+            1,
+            3,
+            2,
+        )];
+        let json: serde_json::Value =
+            serde_json::from_str(&render_components_json(&component_rollups(&reports))).unwrap();
+        assert_eq!(json[0]["component"], "App");
+        assert_eq!(json[0]["framework"], "react");
+        assert_eq!(json[0]["cc"], 2);
+    }
+}
@@ -1,39 +1,27 @@
 //! Configuration file support for Hotspots
 //!
-//! Loads project-specific configuration from JSON files.
+//! Loads project-specific configuration from JSON or TOML files.
 //!
-//! Search order:
+//! Search order (the first file found is used; files are never merged):
 //! 1. Explicit path (--config CLI flag)
-//! 2. `.hotspotsrc.json` in project root
-//! 3. `hotspots.config.json` in project root
-//! 4. `"hotspots"` key in `package.json`
+//! 2. Nearest `.hotspots.toml`, walking up from the analyzed path to the
+//!    repository root
+//! 3. `.hotspotsrc.json` in project root
+//! 4. `hotspots.config.json` in project root
+//! 5. `"hotspots"` key in `package.json`
 //!
 //! All fields are optional. CLI flags take precedence over config file values.
 
 use anyhow::{Context, Result};
 use globset::{Glob, GlobSet, GlobSetBuilder};
 use serde::{Deserialize, Serialize};
+use std::collections::BTreeMap;
 use std::path::{Path, PathBuf};
 
-/// Default exclude patterns always applied (merged with any user-specified excludes).
+/// Default exclude patterns always applied (merged with any user-specified
+/// excludes). Test files are excluded separately (see `ResolvedConfig::tests`).
 const DEFAULT_EXCLUDES: &[&str] = &[
-    // Test files
-    "**/*.test.ts",
-    "**/*.test.tsx",
-    "**/*.test.js",
-    "**/*.test.jsx",
-    "**/*.spec.ts",
-    "**/*.spec.tsx",
-    "**/*.spec.js",
-    "**/*.spec.jsx",
-    "**/__tests__/**",
-    "**/__mocks__/**",
-    "**/__snapshots__/**",
-    // Python test conventions
-    "**/test_*.py",
-    "**/*_test.py",
-    // Go test and generated conventions
-    "**/*_test.go",
+    // Go vendored and generated conventions
     "**/vendor/**",
     "**/*.pb.go",
     "**/zz_generated*.go",
@@ -75,7 +63,19 @@ const DEFAULT_EXCLUDES: &[&str] = &[
     "**/contrib/**",
 ];
 
-/// Hotspots configuration loaded from a JSON config file
+/// Output format names accepted by the `format` key
+const OUTPUT_FORMATS: &[&str] = &[
+    "text", "json", "jsonl", "html", "sarif", "junit", "treemap", "markdown", "csv", "tree",
+    "gitlab",
+];
+
+/// File name of the TOML config, discovered by walking up from the working directory
+pub const TOML_CONFIG_FILE: &str = ".hotspots.toml";
+
+/// Gitignore-syntax file in the project root listing paths not to analyze
+pub const IGNORE_FILE: &str = ".hotspotsignore";
+
+/// Hotspots configuration loaded from a JSON or TOML config file
 #[derive(Debug, Clone, Default, Serialize, Deserialize)]
 #[serde(deny_unknown_fields)]
 pub struct HotspotsConfig {
@@ -83,10 +83,31 @@ pub struct HotspotsConfig {
     #[serde(default)]
     pub include: Vec<String>,
 
-    /// Glob patterns for files to exclude (default: test files, node_modules, dist)
+    /// Glob patterns for files to exclude (default: node_modules, dist, and other
+    /// vendored or generated code; test files are excluded separately)
     #[serde(default)]
     pub exclude: Vec<String>,
 
+    /// Follow symlinks during discovery and analyze each underlying file once,
+    /// listing the other paths as aliases (default: false, symlinks are skipped)
+    #[serde(default)]
+    pub dedup_symlinks: Option<bool>,
+    /// SQL dialect for `.sql` files: "postgres" or "tsql" (default: detected per file)
+    #[serde(default)]
+    pub sql_dialect: Option<String>,
+    /// How switch statements count toward CC: "cases", "statement", or
+    /// "mccabe" (default: "cases")
+    #[serde(default)]
+    pub cc_mode: Option<String>,
+    /// Count Go method calls such as `c.Add(x)` toward FO, not just free and
+    /// package functions (default: true)
+    #[serde(default)]
+    pub fo_methods: Option<bool>,
+    /// How Go closures reported with `--separate-closures` are named:
+    /// "context", "line", or "index" (default: "context")
+    #[serde(default)]
+    pub anon_naming: Option<String>,
+
     /// Custom risk band thresholds
     #[serde(default)]
     pub thresholds: Option<ThresholdConfig>,
@@ -107,10 +128,20 @@ pub struct HotspotsConfig {
     #[serde(default)]
     pub top: Option<usize>,
 
+    /// Output format for `analyze` when `--format` is not given: "text",
+    /// "json", "jsonl", "html", "sarif", "junit", "treemap", "markdown",
+    /// "csv", "tree", or "gitlab" (default: text)
+    #[serde(default)]
+    pub format: Option<String>,
+
     /// Activity risk scoring weights
     #[serde(default)]
     pub scoring: Option<ScoringWeightsConfig>,
 
+    /// Composite risk score weights (`--sort risk-score`)
+    #[serde(default)]
+    pub risk_score: Option<RiskScoreWeightConfig>,
+
     /// Number of days back to look for co-change pairs (default: 90)
     #[serde(default)]
     pub co_change_window_days: Option<u64>,
@@ -163,6 +194,179 @@ pub struct HotspotsConfig {
     /// Per-repo severity overrides for blocking policies.
     #[serde(default)]
     pub policy: Option<PolicyConfig>,
+
+    /// Per-metric SARIF rule thresholds and levels.
+    #[serde(default)]
+    pub sarif: Option<SarifConfig>,
+
+    /// Severity levels: the metric they bucket and their cutoffs
+    /// (`--min-severity`, tree and HTML coloring)
+    #[serde(default)]
+    pub severity: Option<SeverityConfig>,
+
+    /// What `--god-functions` flags: per-metric thresholds and how many a
+    /// function must meet
+    #[serde(default)]
+    pub god_functions: Option<GodFunctionsConfig>,
+
+    /// Functions exempt from gating (policies and `--regressions-only`), keyed
+    /// by qualified function id (`path/to/file.ts::name`). Their metrics are
+    /// still reported.
+    #[serde(default)]
+    pub exempt: Vec<ExemptEntry>,
+
+    /// Per-module complexity budgets: a repo-relative directory or file path
+    /// mapped to the maximum total file CC allowed under it (see `--level budget`).
+    #[serde(default)]
+    pub budgets: BTreeMap<String, usize>,
+
+    /// Constructs that increment ND: one list for every language, or lists keyed
+    /// by language with an optional `default` (default: all constructs).
+    #[serde(default)]
+    pub nd_counts: Option<NdCountsConfig>,
+
+    /// Function-name glob patterns treated as entry points by `--dead-code`,
+    /// in addition to `main`, `init`, and test, benchmark, example, and fuzz
+    /// functions
+    #[serde(default)]
+    pub entry_points: Vec<String>,
+}
+
+/// `nd_counts` setting: construct names from `if`, `for`, `while`, `switch`,
+/// `try`, `match`.
+///
+/// ```json
+/// "nd_counts": ["if", "for", "while", "try"]
+/// "nd_counts": { "default": ["if", "for", "while"], "go": ["if", "for", "switch"] }
+/// ```
+#[derive(Debug, Clone, Serialize, Deserialize)]
+#[serde(untagged)]
+pub enum NdCountsConfig {
+    All(Vec<String>),
+    PerLanguage(BTreeMap<String, Vec<String>>),
+}
+
+/// Keys accepted in the per-language form of `nd_counts`
+const ND_COUNTS_KEYS: &[&str] = &[
+    "default",
+    "typescript",
+    "javascript",
+    "vue",
+    "go",
+    "java",
+    "python",
+    "rust",
+    "csharp",
+    "c",
+    "cpp",
+    "swift",
+    "php",
+    "scala",
+    "dart",
+    "elixir",
+    "lua",
+    "bash",
+    "zig",
+    "haskell",
+    "perl",
+];
+
+/// `nd_counts` key for a language; React variants share their base language's
+/// key and C headers share C's
+fn nd_counts_key(language: crate::language::Language) -> &'static str {
+    use crate::language::Language;
+    match language {
+        Language::TypeScript | Language::TypeScriptReact => "typescript",
+        Language::JavaScript | Language::JavaScriptReact => "javascript",
+        Language::Vue => "vue",
+        Language::Go => "go",
+        Language::Java => "java",
+        Language::Python => "python",
+        Language::Rust => "rust",
+        Language::CSharp => "csharp",
+        Language::C | Language::CHeader => "c",
+        Language::Cpp => "cpp",
+        Language::Sql => "sql",
+        Language::Swift => "swift",
+        Language::Php => "php",
+        Language::Scala => "scala",
+        Language::Dart => "dart",
+        Language::Elixir => "elixir",
+        Language::Lua => "lua",
+        Language::Bash => "bash",
+        Language::Zig => "zig",
+        Language::Haskell => "haskell",
+        Language::Perl => "perl",
+    }
+}
+
+fn parse_nd_counts(field: &str, names: &[String]) -> Result<crate::metrics::NdCounts> {
+    use crate::metrics::{NdCounts, NestingConstruct};
+    let constructs = names
+        .iter()
+        .map(|name| {
+            NestingConstruct::from_name(name).ok_or_else(|| {
+                let valid: Vec<&str> = NestingConstruct::ALL.iter().map(|c| c.name()).collect();
+                anyhow::anyhow!(
+                    "{} has unknown construct \"{}\" (expected one of: {})",
+                    field,
+                    name,
+                    valid.join(", ")
+                )
+            })
+        })
+        .collect::<Result<Vec<_>>>()?;
+    Ok(NdCounts::from_constructs(&constructs))
+}
+
+/// One entry of the `exempt` list: either a bare function id or an object
+/// carrying the reason for the exemption.
+///
+/// ```json
+/// "exempt": [
+///   "src/legacy/parser.ts::parseAll",
+///   { "function": "src/api/router.ts::dispatch", "reason": "rewrite tracked in #412" }
+/// ]
+/// ```
+#[derive(Debug, Clone, Serialize, Deserialize)]
+#[serde(untagged)]
+pub enum ExemptEntry {
+    Name(String),
+    Detailed(ExemptFunctionConfig),
+}
+
+/// Object form of an [`ExemptEntry`]
+#[derive(Debug, Clone, Serialize, Deserialize)]
+#[serde(deny_unknown_fields)]
+pub struct ExemptFunctionConfig {
+    /// Qualified function id, repo-relative (`path/to/file.ts::name`)
+    pub function: String,
+    /// Why the function is exempt; must be non-empty when given
+    pub reason: Option<String>,
+}
+
+impl ExemptEntry {
+    fn function(&self) -> &str {
+        match self {
+            ExemptEntry::Name(function) => function,
+            ExemptEntry::Detailed(d) => &d.function,
+        }
+    }
+
+    fn reason(&self) -> Option<&str> {
+        match self {
+            ExemptEntry::Name(_) => None,
+            ExemptEntry::Detailed(d) => d.reason.as_deref(),
+        }
+    }
+}
+
+/// A resolved `exempt` entry
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub struct ExemptFunction {
+    /// Function id in portable form (repo-relative, `/` separators)
+    pub function_id: String,
+    pub reason: Option<String>,
 }
 
 /// Severity for a blocking policy, as configured per-repo.
@@ -219,6 +423,32 @@ pub struct PolicyConfig {
     pub excessive_risk_regression_reason: Option<String>,
 }
 
+/// Per-metric SARIF rules (`hotspots/cc`, `hotspots/nd`, ...).
+///
+/// Each entry overrides the threshold and/or level of one rule; omitted
+/// entries keep the defaults from `sarif::MetricRules::default()`.
+#[derive(Debug, Clone, Default, Serialize, Deserialize)]
+#[serde(deny_unknown_fields)]
+pub struct SarifConfig {
+    pub cc: Option<SarifRuleConfig>,
+    pub nd: Option<SarifRuleConfig>,
+    pub fo: Option<SarifRuleConfig>,
+    pub ns: Option<SarifRuleConfig>,
+    pub loc: Option<SarifRuleConfig>,
+    pub signature_complexity: Option<SarifRuleConfig>,
+    pub max_condition_ops: Option<SarifRuleConfig>,
+}
+
+/// Threshold and level for one SARIF metric rule
+#[derive(Debug, Clone, Default, Serialize, Deserialize)]
+#[serde(deny_unknown_fields)]
+pub struct SarifRuleConfig {
+    /// Emit a result when the metric is at or above this value (must be >= 1)
+    pub threshold: Option<u32>,
+    /// SARIF level: "none" | "note" | "warning" | "error"
+    pub level: Option<String>,
+}
+
 /// Custom risk band thresholds
 #[derive(Debug, Clone, Serialize, Deserialize)]
 #[serde(deny_unknown_fields)]
@@ -231,6 +461,44 @@ pub struct ThresholdConfig {
     pub critical: Option<f64>,
 }
 
+/// Severity levels. Each cutoff is the lowest value of its level; defaults
+/// depend on the metric (see `severity`).
+#[derive(Debug, Clone, Default, Serialize, Deserialize)]
+#[serde(deny_unknown_fields)]
+pub struct SeverityConfig {
+    /// "lrs", "risk_score", "cc", "nd", "fo", "ns", or "cognitive" (default: "lrs")
+    pub metric: Option<String>,
+    /// Lowest value of `low` (default for LRS: 1.5)
+    pub low: Option<f64>,
+    /// Lowest value of `medium` (default for LRS: 3.0)
+    pub medium: Option<f64>,
+    /// Lowest value of `high` (default for LRS: 6.0)
+    pub high: Option<f64>,
+    /// Lowest value of `critical` (default for LRS: 9.0)
+    pub critical: Option<f64>,
+}
+
+/// `--god-functions` rule. A threshold is met at or above its value; 0
+/// drops the metric from the rule.
+#[derive(Debug, Clone, Default, Serialize, Deserialize)]
+#[serde(deny_unknown_fields)]
+pub struct GodFunctionsConfig {
+    /// Lines of code (default: 60)
+    pub loc: Option<u32>,
+    /// Cyclomatic complexity (default: 15)
+    pub cc: Option<u32>,
+    /// Fan-out (default: 10)
+    pub fo: Option<u32>,
+    /// Nesting depth (default: not checked)
+    pub nd: Option<u32>,
+    /// Non-structured exits (default: not checked)
+    pub ns: Option<u32>,
+    /// Cognitive complexity (default: not checked)
+    pub cognitive: Option<u32>,
+    /// Thresholds a function must meet (default: all of them)
+    pub min_matched: Option<usize>,
+}
+
 /// Custom metric weights for LRS calculation
 #[derive(Debug, Clone, Serialize, Deserialize)]
 #[serde(deny_unknown_fields)]
@@ -245,6 +513,22 @@ pub struct WeightConfig {
     pub ns: Option<f64>,
 }
 
+/// Custom weights for the composite risk score
+#[derive(Debug, Clone, Serialize, Deserialize)]
+#[serde(deny_unknown_fields)]
+pub struct RiskScoreWeightConfig {
+    /// Weight for cyclomatic complexity (default: 1.0)
+    pub cc: Option<f64>,
+    /// Weight for nesting depth (default: 0.8)
+    pub nd: Option<f64>,
+    /// Weight for fan-out (default: 0.6)
+    pub fo: Option<f64>,
+    /// Weight for non-structured exits (default: 0.7)
+    pub ns: Option<f64>,
+    /// Weight for churn, when in a git repository (default: 1.0)
+    pub churn: Option<f64>,
+}
+
 /// Weights for activity-weighted risk scoring
 #[derive(Debug, Clone, Serialize, Deserialize)]
 #[serde(deny_unknown_fields)]
@@ -271,6 +555,7 @@ pub struct ScoringWeightsConfig {
 #[derive(Debug, Clone, Serialize, Deserialize)]
 #[serde(deny_unknown_fields)]
 pub struct PatternThresholdsConfig {
+    pub arrow_code_depth: Option<usize>,
     pub complex_branching_cc: Option<usize>,
     pub complex_branching_nd: Option<usize>,
     pub deeply_nested_nd: Option<usize>,
@@ -319,6 +604,68 @@ pub struct ResolvedConfig {
     pub include: Option<GlobSet>,
     /// Compiled exclude patterns
     pub exclude: GlobSet,
+    /// User exclude patterns (config and `--exclude`), compiled into `exclude`
+    /// along with the defaults
+    pub exclude_patterns: Vec<String>,
+    /// Compiled test file patterns (`test_code::TEST_FILE_PATTERNS`),
+    /// excluded unless `include_tests`
+    pub tests: GlobSet,
+    /// Analyze test files and test functions. Not a config key: set by
+    /// `--include-tests`
+    pub include_tests: bool,
+    /// Files to analyze instead of walking the analyzed path, still subject
+    /// to the patterns above. Not a config key: set by `--files-from` and
+    /// `--changed`
+    pub files: Option<Vec<PathBuf>>,
+    /// `.hotspotsignore` patterns from the project root
+    pub ignore: Option<crate::gitignore::IgnoreRules>,
+    /// Directory patterns are matched relative to: the project root when
+    /// loaded for a project (None = match paths as given)
+    pub root: Option<PathBuf>,
+    /// Follow symlinks and count each canonical file once
+    pub dedup_symlinks: bool,
+    /// Report only functions that are part of a file's public API. Not a
+    /// config key: set by `analyze --public-only`, which never persists
+    /// snapshots, so history always covers every function
+    pub public_only: bool,
+    /// Skip paths ignored by `.gitignore` files during discovery. Not a config
+    /// key: cleared by `--no-gitignore`
+    pub gitignore: bool,
+    /// Compute Halstead metrics for each function. Not a config key: set by
+    /// `--halstead`
+    pub halstead: bool,
+    /// Count each function's callers among the analyzed functions into
+    /// `metrics.fi`. Not a config key: set by `--fan-in`
+    pub fan_in: bool,
+    /// Count source, comment, and blank lines of each function. Not a config
+    /// key: set by `--line-counts`
+    pub line_counts: bool,
+    /// Record the line where each function's nesting depth is reached into
+    /// `metrics.nd_line`. Not a config key: set by `--explain`
+    pub nd_lines: bool,
+    /// Record each function's first line and the lines of its CC decision
+    /// points into `metrics.cc_lines`. Not a config key: set by `--explain`
+    pub cc_lines: bool,
+    /// Report Go closures as functions of their own and leave nested function
+    /// bodies out of the enclosing function's metrics. Not a config key: set
+    /// by `--separate-closures`
+    pub separate_closures: bool,
+    /// Break each function's NS down by kind of exit into
+    /// `metrics.ns_breakdown`. Not a config key: set by `--ns-breakdown`
+    pub ns_breakdown: bool,
+    /// Reuse results for files unchanged since the last run from the on-disk
+    /// analysis cache under `root` (see `analysis_cache`). Not a config key:
+    /// set by `analyze` unless `--no-cache`
+    pub analysis_cache: bool,
+    /// SQL dialect for `.sql` files (None = detect per file)
+    pub sql_dialect: Option<crate::language::SqlDialect>,
+    /// How switch statements count toward CC
+    pub cc_mode: crate::metrics::CcMode,
+    /// Count Go method calls toward FO (see
+    /// [`crate::metrics::exclude_method_calls`]); cleared by `--no-fo-methods`
+    pub fo_methods: bool,
+    /// How Go closures reported with `separate_closures` are named
+    pub anon_naming: crate::language::AnonNaming,
     /// Risk band thresholds
     pub moderate_threshold: f64,
     pub high_threshold: f64,
@@ -337,6 +684,8 @@ pub struct ResolvedConfig {
     /// Filters
     pub min_lrs: Option<f64>,
     pub top_n: Option<usize>,
+    /// Default `analyze` output format name (None = text)
+    pub format: Option<String>,
     /// Co-change mining parameters
     pub co_change_window_days: u64,
     pub co_change_min_count: usize,
@@ -354,8 +703,16 @@ pub struct ResolvedConfig {
     pub callgraph_skip_above: usize,
     /// Activity risk scoring weights
     pub scoring_weights: crate::scoring::ScoringWeights,
+    /// Composite risk score weights
+    pub risk_score_weights: crate::risk::RiskScoreWeights,
     /// Pattern detection thresholds
     pub pattern_thresholds: crate::patterns::Thresholds,
+    /// Per-metric SARIF rules
+    pub sarif_rules: crate::sarif::MetricRules,
+    /// Severity levels
+    pub severity: crate::severity::SeverityScale,
+    /// `--god-functions` rule
+    pub god_functions: crate::god_functions::GodFunctionRule,
     /// Severity for the `critical-introduction` policy (default: Block)
     pub critical_introduction_mode: PolicyMode,
     /// Reason given for downgrading `critical_introduction_mode` below Block (None if Block)
@@ -364,6 +721,16 @@ pub struct ResolvedConfig {
     pub excessive_risk_regression_mode: PolicyMode,
     /// Reason given for downgrading `excessive_risk_regression_mode` below Block (None if Block)
     pub excessive_risk_regression_reason: Option<String>,
+    /// Functions exempt from gating
+    pub exempt: Vec<ExemptFunction>,
+    /// Complexity budgets keyed by portable directory or file path
+    pub budgets: BTreeMap<String, usize>,
+    /// ND construct sets keyed by `nd_counts` language key or `default` (see
+    /// [`ResolvedConfig::nd_counts_for`])
+    pub nd_counts: BTreeMap<String, crate::metrics::NdCounts>,
+    /// Compiled entry-point patterns for `--dead-code`: the defaults plus the
+    /// `entry_points` key
+    pub entry_points: GlobSet,
     /// Path the config was loaded from (None if defaults)
     pub config_path: Option<PathBuf>,
 }
@@ -383,14 +750,34 @@ impl HotspotsConfig {
         if let Some(ref s) = self.scoring {
             validate_scoring(s)?;
         }
+        if let Some(ref r) = self.risk_score {
+            validate_risk_score(r)?;
+        }
         if let Some(ref p) = self.patterns {
             validate_pattern_thresholds(p)?;
         }
         if let Some(ref p) = self.policy {
             validate_policy_config(p)?;
         }
+        if let Some(ref s) = self.sarif {
+            validate_sarif_config(s)?;
+        }
+        if let Some(ref s) = self.severity {
+            s.resolve()?;
+        }
+        if let Some(ref g) = self.god_functions {
+            g.resolve()?;
+        }
+        validate_exempt(&self.exempt)?;
+        validate_budgets(&self.budgets)?;
+        resolve_nd_counts(self.nd_counts.as_ref())?;
         validate_scalar_fields(self)?;
-        validate_glob_patterns(&self.include, &self.exclude)
+        validate_glob_patterns(&self.include, &self.exclude)?;
+        for pattern in &self.entry_points {
+            Glob::new(pattern)
+                .with_context(|| format!("invalid entry_points pattern: {}", pattern))?;
+        }
+        Ok(())
     }
 }
 
@@ -423,6 +810,15 @@ fn validate_scalar_fields(c: &HotspotsConfig) -> Result<()> {
             anyhow::bail!("betweenness_approx_k must be at least 1");
         }
     }
+    if let Some(ref f) = c.format {
+        if !OUTPUT_FORMATS.contains(&f.as_str()) {
+            anyhow::bail!(
+                "format must be one of {} (got \"{}\")",
+                OUTPUT_FORMATS.join(", "),
+                f
+            );
+        }
+    }
     Ok(())
 }
 
@@ -502,6 +898,72 @@ fn require_reason_if_downgraded(field: &str, mode: PolicyMode, reason: Option<&s
     }
 }
 
+fn validate_exempt(exempt: &[ExemptEntry]) -> Result<()> {
+    for entry in exempt {
+        let function = entry.function();
+        match function.split_once("::") {
+            Some((file, name)) if !file.trim().is_empty() && !name.trim().is_empty() => {}
+            _ => anyhow::bail!(
+                "exempt entry \"{}\" must be a qualified function id (\"path/to/file::name\")",
+                function
+            ),
+        }
+        if let ExemptEntry::Detailed(d) = entry {
+            if d.reason.as_deref().is_some_and(|r| r.trim().is_empty()) {
+                anyhow::bail!("exempt entry \"{}\" has an empty reason", function);
+            }
+        }
+    }
+    Ok(())
+}
+
+fn validate_budgets(budgets: &BTreeMap<String, usize>) -> Result<()> {
+    for (path, &budget) in budgets {
+        if path.trim().is_empty() {
+            anyhow::bail!("budgets keys must be non-empty paths");
+        }
+        if budget == 0 {
+            anyhow::bail!("budgets.\"{}\" must be at least 1", path);
+        }
+    }
+    Ok(())
+}
+
+/// Parse `nd_counts` into construct sets keyed by language (`default` for the
+/// list form)
+fn resolve_nd_counts(
+    config: Option<&NdCountsConfig>,
+) -> Result<BTreeMap<String, crate::metrics::NdCounts>> {
+    let mut resolved = BTreeMap::new();
+    match config {
+        None => {}
+        Some(NdCountsConfig::All(names)) => {
+            resolved.insert("default".to_string(), parse_nd_counts("nd_counts", names)?);
+        }
+        Some(NdCountsConfig::PerLanguage(by_language)) => {
+            for (key, names) in by_language {
+                if !ND_COUNTS_KEYS.contains(&key.as_str()) {
+                    anyhow::bail!(
+                        "nd_counts has unknown language \"{}\" (expected one of: {})",
+                        key,
+                        ND_COUNTS_KEYS.join(", ")
+                    );
+                }
+                let counts = parse_nd_counts(&format!("nd_counts.{key}"), names)?;
+                resolved.insert(key.clone(), counts);
+            }
+        }
+    }
+    Ok(resolved)
+}
+
+/// Portable form of a configured path or function id: `/` separators, no
+/// leading `./`
+fn normalize_config_path(path: &str) -> String {
+    let path = path.trim().replace('\\', "/");
+    path.strip_prefix("./").unwrap_or(&path).to_string()
+}
+
 fn validate_weights(w: &WeightConfig) -> Result<()> {
     for (name, val) in [("cc", w.cc), ("nd", w.nd), ("fo", w.fo), ("ns", w.ns)] {
         if let Some(v) = val {
@@ -516,6 +978,26 @@ fn validate_weights(w: &WeightConfig) -> Result<()> {
     Ok(())
 }
 
+fn validate_risk_score(r: &RiskScoreWeightConfig) -> Result<()> {
+    for (name, val) in [
+        ("cc", r.cc),
+        ("nd", r.nd),
+        ("fo", r.fo),
+        ("ns", r.ns),
+        ("churn", r.churn),
+    ] {
+        if let Some(v) = val {
+            if v < 0.0 {
+                anyhow::bail!("risk_score.{} must be non-negative (got {})", name, v);
+            }
+            if v > 10.0 {
+                anyhow::bail!("risk_score.{} must be at most 10.0 (got {})", name, v);
+            }
+        }
+    }
+    Ok(())
+}
+
 fn validate_warning_thresholds(wt: &WarningThresholdConfig) -> Result<()> {
     let watch_min = wt.watch_min.unwrap_or(2.5);
     let watch_max = wt.watch_max.unwrap_or(3.0);
@@ -592,9 +1074,162 @@ fn validate_scoring(s: &ScoringWeightsConfig) -> Result<()> {
     Ok(())
 }
 
+fn validate_sarif_config(s: &SarifConfig) -> Result<()> {
+    for (name, rule) in s.entries() {
+        let Some(rule) = rule else { continue };
+        if rule.threshold == Some(0) {
+            anyhow::bail!("sarif.{}.threshold must be at least 1 (got 0)", name);
+        }
+        if let Some(ref level) = rule.level {
+            if crate::sarif::SarifLevel::parse(level).is_none() {
+                anyhow::bail!(
+                    "sarif.{}.level must be one of \"none\", \"note\", \"warning\", \"error\" (got \"{}\")",
+                    name,
+                    level
+                );
+            }
+        }
+    }
+    Ok(())
+}
+
+impl SarifConfig {
+    fn entries(&self) -> [(&'static str, Option<&SarifRuleConfig>); 7] {
+        [
+            ("cc", self.cc.as_ref()),
+            ("nd", self.nd.as_ref()),
+            ("fo", self.fo.as_ref()),
+            ("ns", self.ns.as_ref()),
+            ("loc", self.loc.as_ref()),
+            ("signature_complexity", self.signature_complexity.as_ref()),
+            ("max_condition_ops", self.max_condition_ops.as_ref()),
+        ]
+    }
+
+    /// Apply overrides on top of the default rules. Assumes `validate_sarif_config` passed.
+    fn resolve(&self) -> crate::sarif::MetricRules {
+        let d = crate::sarif::MetricRules::default();
+        let apply = |rule: Option<&SarifRuleConfig>, default: crate::sarif::MetricRule| {
+            let Some(rule) = rule else { return default };
+            crate::sarif::MetricRule {
+                threshold: rule.threshold.unwrap_or(default.threshold),
+                level: rule
+                    .level
+                    .as_deref()
+                    .and_then(crate::sarif::SarifLevel::parse)
+                    .unwrap_or(default.level),
+            }
+        };
+        crate::sarif::MetricRules {
+            cc: apply(self.cc.as_ref(), d.cc),
+            nd: apply(self.nd.as_ref(), d.nd),
+            fo: apply(self.fo.as_ref(), d.fo),
+            ns: apply(self.ns.as_ref(), d.ns),
+            loc: apply(self.loc.as_ref(), d.loc),
+            signature_complexity: apply(self.signature_complexity.as_ref(), d.signature_complexity),
+            max_condition_ops: apply(self.max_condition_ops.as_ref(), d.max_condition_ops),
+        }
+    }
+}
+
+impl SeverityConfig {
+    /// Apply overrides on top of the metric's default cutoffs, rejecting an
+    /// unknown metric and cutoffs that are negative or out of order
+    fn resolve(&self) -> Result<crate::severity::SeverityScale> {
+        use crate::severity::{SeverityCutoffs, SeverityMetric, SeverityScale};
+
+        let metric = match self.metric.as_deref() {
+            None => SeverityMetric::Lrs,
+            Some(name) => SeverityMetric::parse(name).ok_or_else(|| {
+                anyhow::anyhow!(
+                    "severity.metric must be one of {} (got \"{}\")",
+                    SeverityMetric::NAMES
+                        .iter()
+                        .map(|n| format!("\"{n}\""))
+                        .collect::<Vec<_>>()
+                        .join(", "),
+                    name
+                )
+            })?,
+        };
+        let d = SeverityCutoffs::defaults_for(metric);
+        let cutoffs = SeverityCutoffs {
+            low: self.low.unwrap_or(d.low),
+            medium: self.medium.unwrap_or(d.medium),
+            high: self.high.unwrap_or(d.high),
+            critical: self.critical.unwrap_or(d.critical),
+        };
+        let levels = [
+            ("low", cutoffs.low),
+            ("medium", cutoffs.medium),
+            ("high", cutoffs.high),
+            ("critical", cutoffs.critical),
+        ];
+        for (name, value) in levels {
+            if value < 0.0 {
+                anyhow::bail!("severity.{} must be non-negative (got {})", name, value);
+            }
+        }
+        for pair in levels.windows(2) {
+            let ((lower, lower_value), (upper, upper_value)) = (pair[0], pair[1]);
+            if lower_value >= upper_value {
+                anyhow::bail!(
+                    "severity.{} ({}) must be less than severity.{} ({})",
+                    lower,
+                    lower_value,
+                    upper,
+                    upper_value
+                );
+            }
+        }
+        Ok(SeverityScale { metric, cutoffs })
+    }
+}
+
+impl GodFunctionsConfig {
+    /// Apply overrides on top of the default rule, rejecting a rule with no
+    /// thresholds or a `min_matched` it cannot satisfy
+    fn resolve(&self) -> Result<crate::god_functions::GodFunctionRule> {
+        use crate::god_functions::Criterion;
+
+        let thresholds = [
+            ("loc", self.loc.unwrap_or(60)),
+            ("cc", self.cc.unwrap_or(15)),
+            ("fo", self.fo.unwrap_or(10)),
+            ("nd", self.nd.unwrap_or(0)),
+            ("ns", self.ns.unwrap_or(0)),
+            ("cognitive", self.cognitive.unwrap_or(0)),
+        ];
+        let criteria: Vec<Criterion> = thresholds
+            .iter()
+            .filter(|&&(_, threshold)| threshold > 0)
+            .map(|&(metric, threshold)| Criterion {
+                metric: metric.to_string(),
+                threshold,
+            })
+            .collect();
+        if criteria.is_empty() {
+            anyhow::bail!("god_functions must keep at least one threshold above 0");
+        }
+        let min_matched = self.min_matched.unwrap_or(criteria.len());
+        if min_matched == 0 || min_matched > criteria.len() {
+            anyhow::bail!(
+                "god_functions.min_matched must be between 1 and {} (the number of thresholds), got {}",
+                criteria.len(),
+                min_matched
+            );
+        }
+        Ok(crate::god_functions::GodFunctionRule {
+            criteria,
+            min_matched,
+        })
+    }
+}
+
 fn validate_pattern_thresholds(p: &PatternThresholdsConfig) -> Result<()> {
     // All thresholds must be at least 1 when specified
     let usize_fields: &[(&str, Option<usize>)] = &[
+        ("arrow_code_depth", p.arrow_code_depth),
         ("complex_branching_cc", p.complex_branching_cc),
         ("complex_branching_nd", p.complex_branching_nd),
         ("deeply_nested_nd", p.deeply_nested_nd),
@@ -642,24 +1277,9 @@ impl HotspotsConfig {
         let include = if self.include.is_empty() {
             None
         } else {
-            let mut builder = GlobSetBuilder::new();
-            for pattern in &self.include {
-                builder.add(Glob::new(pattern)?);
-            }
-            Some(builder.build()?)
-        };
-
-        // Compile exclude patterns: defaults always apply; user patterns are additive.
-        let exclude = {
-            let mut builder = GlobSetBuilder::new();
-            for pattern in DEFAULT_EXCLUDES {
-                builder.add(Glob::new(pattern)?);
-            }
-            for pattern in &self.exclude {
-                builder.add(Glob::new(pattern)?);
-            }
-            builder.build()?
+            Some(build_glob_set(&self.include)?)
         };
+        let exclude = build_exclude_set(&self.exclude)?;
 
         let (moderate, high, critical) = match &self.thresholds {
             Some(t) => (
@@ -709,10 +1329,25 @@ impl HotspotsConfig {
             None => crate::scoring::ScoringWeights::default(),
         };
 
+        let risk_score_weights = match &self.risk_score {
+            Some(r) => {
+                let defaults = crate::risk::RiskScoreWeights::default();
+                crate::risk::RiskScoreWeights {
+                    cc: r.cc.unwrap_or(defaults.cc),
+                    nd: r.nd.unwrap_or(defaults.nd),
+                    fo: r.fo.unwrap_or(defaults.fo),
+                    ns: r.ns.unwrap_or(defaults.ns),
+                    churn: r.churn.unwrap_or(defaults.churn),
+                }
+            }
+            None => crate::risk::RiskScoreWeights::default(),
+        };
+
         let pattern_thresholds = match &self.patterns {
             Some(p) => {
                 let d = crate::patterns::Thresholds::default();
                 crate::patterns::Thresholds {
+                    arrow_code_depth: p.arrow_code_depth.unwrap_or(d.arrow_code_depth),
                     complex_branching_cc: p.complex_branching_cc.unwrap_or(d.complex_branching_cc),
                     complex_branching_nd: p.complex_branching_nd.unwrap_or(d.complex_branching_nd),
                     deeply_nested_nd: p.deeply_nested_nd.unwrap_or(d.deeply_nested_nd),
@@ -767,6 +1402,12 @@ impl HotspotsConfig {
         Ok(ResolvedConfig {
             include,
             exclude,
+            exclude_patterns: self.exclude.clone(),
+            tests: build_test_file_set()?,
+            include_tests: false,
+            files: None,
+            ignore: None,
+            root: None,
             moderate_threshold: moderate,
             high_threshold: high,
             critical_threshold: critical,
@@ -781,15 +1422,106 @@ impl HotspotsConfig {
             rapid_growth_percent,
             min_lrs: self.min_lrs,
             top_n: self.top,
+            format: self.format.clone(),
             scoring_weights,
+            risk_score_weights,
             pattern_thresholds,
+            sarif_rules: self
+                .sarif
+                .as_ref()
+                .map(SarifConfig::resolve)
+                .unwrap_or_default(),
+            severity: self
+                .severity
+                .as_ref()
+                .map(SeverityConfig::resolve)
+                .transpose()?
+                .unwrap_or_default(),
+            god_functions: self
+                .god_functions
+                .as_ref()
+                .map(GodFunctionsConfig::resolve)
+                .transpose()?
+                .unwrap_or_default(),
             critical_introduction_mode,
             critical_introduction_reason,
             excessive_risk_regression_mode,
             excessive_risk_regression_reason,
+            exempt: self
+                .exempt
+                .iter()
+                .map(|entry| ExemptFunction {
+                    function_id: normalize_config_path(entry.function()),
+                    reason: entry.reason().map(str::to_string),
+                })
+                .collect(),
+            budgets: self
+                .budgets
+                .iter()
+                .map(|(path, &budget)| {
+                    let path = normalize_config_path(path);
+                    let path = match path.trim_end_matches('/') {
+                        "" => ".".to_string(),
+                        trimmed => trimmed.to_string(),
+                    };
+                    (path, budget)
+                })
+                .collect(),
+            nd_counts: resolve_nd_counts(self.nd_counts.as_ref())?,
+            entry_points: build_entry_point_set(&self.entry_points)?,
             co_change_window_days: self.co_change_window_days.unwrap_or(90),
             co_change_min_count: self.co_change_min_count.unwrap_or(3),
             per_function_touches: self.per_function_touches.unwrap_or(false),
+            dedup_symlinks: self.dedup_symlinks.unwrap_or(false),
+            fo_methods: self.fo_methods.unwrap_or(true),
+            public_only: false,
+            gitignore: true,
+            halstead: false,
+            fan_in: false,
+            line_counts: false,
+            nd_lines: false,
+            cc_lines: false,
+            separate_closures: false,
+            ns_breakdown: false,
+            analysis_cache: false,
+            sql_dialect: self
+                .sql_dialect
+                .as_deref()
+                .map(|s| {
+                    crate::language::SqlDialect::from_name(s).ok_or_else(|| {
+                        anyhow::anyhow!(
+                            "sql_dialect must be one of \"postgres\", \"tsql\" (got \"{}\")",
+                            s
+                        )
+                    })
+                })
+                .transpose()?,
+            cc_mode: self
+                .cc_mode
+                .as_deref()
+                .map(|s| {
+                    crate::metrics::CcMode::from_name(s).ok_or_else(|| {
+                        anyhow::anyhow!(
+                            "cc_mode must be one of \"cases\", \"statement\", \"mccabe\" (got \"{}\")",
+                            s
+                        )
+                    })
+                })
+                .transpose()?
+                .unwrap_or_default(),
+            anon_naming: self
+                .anon_naming
+                .as_deref()
+                .map(|s| {
+                    crate::language::AnonNaming::from_name(s).ok_or_else(|| {
+                        anyhow::anyhow!(
+                            "anon_naming must be one of \"index\", \"line\", \"context\" (got \"{}\")",
+                            s
+                        )
+                    })
+                })
+                .transpose()?
+                .unwrap_or_default(),
             hybrid_touch_threshold: self.hybrid_touch_threshold,
             driver_threshold_percentile: self.driver_threshold_percentile.unwrap_or(75),
             betweenness_exact_threshold: self.betweenness_exact_threshold.unwrap_or(2000),
@@ -800,15 +1532,94 @@ impl HotspotsConfig {
     }
 }
 
+fn build_glob_set(patterns: &[String]) -> Result<GlobSet> {
+    let mut builder = GlobSetBuilder::new();
+    for pattern in patterns {
+        builder.add(Glob::new(pattern)?);
+    }
+    Ok(builder.build()?)
+}
+
+/// Compile exclude patterns: defaults always apply; user patterns are additive.
+fn build_exclude_set(patterns: &[String]) -> Result<GlobSet> {
+    let mut builder = GlobSetBuilder::new();
+    for pattern in DEFAULT_EXCLUDES {
+        builder.add(Glob::new(pattern)?);
+    }
+    for pattern in patterns {
+        builder.add(Glob::new(pattern)?);
+    }
+    Ok(builder.build()?)
+}
+
+/// Compile the built-in test file patterns
+fn build_test_file_set() -> Result<GlobSet> {
+    let mut builder = GlobSetBuilder::new();
+    for pattern in crate::test_code::TEST_FILE_PATTERNS {
+        builder.add(Glob::new(pattern)?);
+    }
+    Ok(builder.build()?)
+}
+
+/// Compile entry-point patterns: defaults always apply; user patterns are
+/// additive.
+fn build_entry_point_set(patterns: &[String]) -> Result<GlobSet> {
+    let mut builder = GlobSetBuilder::new();
+    for pattern in crate::dead_code::DEFAULT_ENTRY_POINTS {
+        builder.add(Glob::new(pattern)?);
+    }
+    for pattern in patterns {
+        builder.add(Glob::new(pattern)?);
+    }
+    Ok(builder.build()?)
+}
+
 impl ResolvedConfig {
-    /// Check if a file path should be included based on include/exclude patterns
+    /// Apply `--include`/`--exclude`: include patterns replace the config's,
+    /// exclude patterns add to them
+    pub fn apply_pattern_flags(&mut self, include: &[String], exclude: &[String]) -> Result<()> {
+        validate_glob_patterns(include, exclude)?;
+        if !include.is_empty() {
+            self.include = Some(build_glob_set(include)?);
+        }
+        if !exclude.is_empty() {
+            self.exclude_patterns.extend(exclude.iter().cloned());
+            self.exclude = build_exclude_set(&self.exclude_patterns)?;
+        }
+        Ok(())
+    }
+
+    /// Constructs that increment ND for `language`: its own `nd_counts` entry,
+    /// else `default`, else all constructs
+    pub fn nd_counts_for(&self, language: crate::language::Language) -> crate::metrics::NdCounts {
+        self.nd_counts
+            .get(nd_counts_key(language))
+            .or_else(|| self.nd_counts.get("default"))
+            .copied()
+            .unwrap_or_default()
+    }
+
+    /// Check if a file path should be included based on include/exclude patterns,
+    /// test file patterns, and `.hotspotsignore`, matched against its path
+    /// relative to `root`
     pub fn should_include(&self, path: &Path) -> bool {
+        let path = self
+            .root
+            .as_deref()
+            .and_then(|root| path.strip_prefix(root).ok())
+            .unwrap_or(path);
         let path_str = path.to_string_lossy();
 
         // Check exclude first
         if self.exclude.is_match(path_str.as_ref()) {
             return false;
         }
+        if !self.include_tests && self.tests.is_match(path_str.as_ref()) {
+            return false;
+        }
+        if self.ignore.as_ref().is_some_and(|i| i.is_ignored(path)) {
+            return false;
+        }
 
         // If include patterns exist, file must match at least one
         if let Some(ref include) = self.include {
@@ -858,13 +1669,32 @@ pub fn discover_config(project_root: &Path) -> Result<Option<(HotspotsConfig, Pa
     Ok(None)
 }
 
-/// Load config from an explicit file path
+/// Find the nearest `.hotspots.toml` in `start` or one of its ancestors, up to
+/// and including `root`
+///
+/// The walk never leaves `root`: a file above the repository belongs to some
+/// other project. When `start` is outside `root`, only `root` is searched.
+pub fn discover_toml_config(start: &Path, root: &Path) -> Option<PathBuf> {
+    let start = if start.starts_with(root) { start } else { root };
+    start
+        .ancestors()
+        .take_while(|dir| dir.starts_with(root))
+        .map(|dir| dir.join(TOML_CONFIG_FILE))
+        .find(|path| path.is_file())
+}
+
+/// Load config from an explicit file path (TOML if it ends in `.toml`, else JSON)
 pub fn load_config_file(path: &Path) -> Result<HotspotsConfig> {
     let content = std::fs::read_to_string(path)
         .with_context(|| format!("failed to read config file: {}", path.display()))?;
 
-    let config: HotspotsConfig = serde_json::from_str(&content)
-        .with_context(|| format!("failed to parse config file: {}", path.display()))?;
+    let config: HotspotsConfig = if path.extension().is_some_and(|ext| ext == "toml") {
+        toml::from_str(&content)
+            .with_context(|| format!("failed to parse config file: {}", path.display()))?
+    } else {
+        serde_json::from_str(&content)
+            .with_context(|| format!("failed to parse config file: {}", path.display()))?
+    };
 
     config
         .validate()
@@ -896,13 +1726,30 @@ fn load_from_package_json(path: &Path) -> Result<Option<HotspotsConfig>> {
 
 /// Load and resolve config for a project
 ///
-/// If `config_path` is provided, loads from that file.
-/// Otherwise, discovers config from the project root.
-/// Returns default config if nothing is found.
+/// If `config_path` is provided, loads from that file. Otherwise uses a
+/// `.hotspots.toml` in the project root, then discovers JSON config there.
+/// Returns default config if nothing is found. Patterns are matched relative
+/// to `project_root`, whose `.hotspotsignore` (if any) is loaded too.
 pub fn load_and_resolve(project_root: &Path, config_path: Option<&Path>) -> Result<ResolvedConfig> {
+    load_and_resolve_from(project_root, config_path, project_root)
+}
+
+/// [`load_and_resolve`] for analyzing `start`, a path inside `project_root`:
+/// the `.hotspots.toml` search walks up from `start` and stops at
+/// `project_root` (see [`discover_toml_config`])
+///
+/// The search never depends on the process working directory, so a daemon
+/// serving several repositories resolves each request's own config.
+pub fn load_and_resolve_from(
+    project_root: &Path,
+    config_path: Option<&Path>,
+    start: &Path,
+) -> Result<ResolvedConfig> {
     let (config, source_path) = if let Some(path) = config_path {
         let config = load_config_file(path)?;
         (config, Some(path.to_path_buf()))
+    } else if let Some(path) = discover_toml_config(start, project_root) {
+        (load_config_file(&path)?, Some(path))
     } else {
         match discover_config(project_root)? {
             Some((config, path)) => (config, Some(path)),
@@ -912,6 +1759,10 @@ pub fn load_and_resolve(project_root: &Path, config_path: Option<&Path>) -> Resu
 
     let mut resolved = config.resolve()?;
     resolved.config_path = source_path;
+    if project_root.is_dir() {
+        resolved.ignore = crate::gitignore::IgnoreRules::load(&project_root.join(IGNORE_FILE))?;
+        resolved.root = Some(project_root.to_path_buf());
+    }
     Ok(resolved)
 }
 
@@ -1035,6 +1886,34 @@ mod tests {
         assert!(resolved.should_include(Path::new("src/networking.c")));
     }
 
+    #[test]
+    fn test_should_include_test_files() {
+        let mut resolved = ResolvedConfig::defaults().unwrap();
+        let tests = [
+            "src/foo.test.ts",
+            "src/foo.spec.jsx",
+            "src/__tests__/foo.ts",
+            "tests/test_parser.py",
+            "pkg/parser_test.py",
+            "tests/conftest.py",
+            "pkg/parser_test.go",
+        ];
+        for path in tests {
+            assert!(!resolved.should_include(Path::new(path)), "{}", path);
+        }
+        // Named like tests only in part
+        assert!(resolved.should_include(Path::new("src/testing.ts")));
+        assert!(resolved.should_include(Path::new("src/contest.py")));
+        assert!(resolved.should_include(Path::new("pkg/testutil/assert.go")));
+
+        resolved.include_tests = true;
+        for path in tests {
+            assert!(resolved.should_include(Path::new(path)), "{}", path);
+        }
+        // Other default excludes still apply
+        assert!(!resolved.should_include(Path::new("node_modules/pkg/index.test.js")));
+    }
+
     #[test]
     fn test_should_include_custom_patterns() {
         let config: HotspotsConfig = serde_json::from_str(
@@ -1156,6 +2035,271 @@ mod tests {
         assert_eq!(resolved.config_path, Some(config_path));
     }
 
+    #[test]
+    fn test_toml_config_sections() {
+        let dir = tempfile::tempdir().unwrap();
+        let config_path = dir.path().join(".hotspots.toml");
+        fs::write(
+            &config_path,
+            r#"
+include = ["src/**"]
+exclude = ["src/legacy/**"]
+top = 20
+format = "json"
+
+[thresholds]
+critical = 12.0
+
+[sarif.cc]
+threshold = 15
+level = "error"
+"#,
+        )
+        .unwrap();
+
+        let resolved = load_config_file(&config_path).unwrap().resolve().unwrap();
+        assert!(resolved.should_include(Path::new("src/app.ts")));
+        assert!(!resolved.should_include(Path::new("src/legacy/old.ts")));
+        assert!(!resolved.should_include(Path::new("lib/util.ts")));
+        assert_eq!(resolved.top_n, Some(20));
+        assert_eq!(resolved.format.as_deref(), Some("json"));
+        assert_eq!(resolved.critical_threshold, 12.0);
+        assert_eq!(resolved.sarif_rules.cc.threshold, 15);
+    }
+
+    #[test]
+    fn test_severity_section() {
+        use crate::severity::{SeverityCutoffs, SeverityMetric, SeverityScale};
+
+        let resolved = HotspotsConfig::default().resolve().unwrap();
+        assert_eq!(resolved.severity, SeverityScale::default());
+
+        // Unset cutoffs come from the chosen metric's defaults
+        let dir = tempfile::tempdir().unwrap();
+        let config_path = dir.path().join(".hotspots.toml");
+        fs::write(&config_path, "[severity]\nmetric = \"cc\"\ncritical = 30\n").unwrap();
+        let resolved = load_config_file(&config_path).unwrap().resolve().unwrap();
+        assert_eq!(resolved.severity.metric, SeverityMetric::Cc);
+        assert_eq!(
+            resolved.severity.cutoffs,
+            SeverityCutoffs {
+                critical: 30.0,
+                ..SeverityCutoffs::defaults_for(SeverityMetric::Cc)
+            }
+        );
+    }
+
+    #[test]
+    fn test_reject_invalid_severity() {
+        for json in [
+            r#"{"severity": {"metric": "loc"}}"#,
+            r#"{"severity": {"low": -1.0}}"#,
+            r#"{"severity": {"medium": 6.0}}"#,
+            r#"{"severity": {"high": 9.0}}"#,
+        ] {
+            let config: HotspotsConfig = serde_json::from_str(json).unwrap();
+            assert!(config.validate().is_err(), "accepted {json}");
+        }
+        let err = serde_json::from_str::<HotspotsConfig>(r#"{"severity": {"high": 9.0}}"#)
+            .unwrap()
+            .validate()
+            .unwrap_err();
+        assert_eq!(
+            err.to_string(),
+            "severity.high (9) must be less than severity.critical (9)"
+        );
+    }
+
+    #[test]
+    fn test_anon_naming() {
+        use crate::language::AnonNaming;
+        let resolved = HotspotsConfig::default().resolve().unwrap();
+        assert_eq!(resolved.anon_naming, AnonNaming::Context);
+
+        let config: HotspotsConfig = serde_json::from_str(r#"{"anon_naming": "index"}"#).unwrap();
+        assert_eq!(config.resolve().unwrap().anon_naming, AnonNaming::Index);
+
+        let config: HotspotsConfig = serde_json::from_str(r#"{"anon_naming": "name"}"#).unwrap();
+        assert_eq!(
+            config.resolve().unwrap_err().to_string(),
+            "anon_naming must be one of \"index\", \"line\", \"context\" (got \"name\")"
+        );
+    }
+
+    #[test]
+    fn test_god_functions_section() {
+        use crate::god_functions::GodFunctionRule;
+
+        let resolved = HotspotsConfig::default().resolve().unwrap();
+        assert_eq!(resolved.god_functions, GodFunctionRule::default());
+
+        // 0 drops a default threshold; nd joins the rule
+        let dir = tempfile::tempdir().unwrap();
+        let config_path = dir.path().join(".hotspots.toml");
+        fs::write(
+            &config_path,
+            "[god_functions]\nloc = 100\nfo = 0\nnd = 4\nmin_matched = 2\n",
+        )
+        .unwrap();
+        let rule = load_config_file(&config_path)
+            .unwrap()
+            .resolve()
+            .unwrap()
+            .god_functions;
+        assert_eq!(rule.describe(), "loc >= 100, cc >= 15, nd >= 4");
+        assert_eq!(rule.min_matched, 2);
+    }
+
+    #[test]
+    fn test_reject_invalid_god_functions() {
+        for json in [
+            r#"{"god_functions": {"loc": 0, "cc": 0, "fo": 0}}"#,
+            r#"{"god_functions": {"min_matched": 0}}"#,
+            r#"{"god_functions": {"min_matched": 4}}"#,
+            r#"{"god_functions": {"lines": 80}}"#,
+        ] {
+            let accepted = serde_json::from_str::<HotspotsConfig>(json)
+                .map_err(anyhow::Error::from)
+                .and_then(|c| c.validate());
+            assert!(accepted.is_err(), "accepted {json}");
+        }
+        let err =
+            serde_json::from_str::<HotspotsConfig>(r#"{"god_functions": {"min_matched": 4}}"#)
+                .unwrap()
+                .validate()
+                .unwrap_err();
+        assert_eq!(
+            err.to_string(),
+            "god_functions.min_matched must be between 1 and 3 (the number of thresholds), got 4"
+        );
+    }
+
+    #[test]
+    fn test_reject_unknown_toml_format() {
+        let dir = tempfile::tempdir().unwrap();
+        let config_path = dir.path().join(".hotspots.toml");
+        fs::write(&config_path, "format = \"yaml\"\n").unwrap();
+        assert!(load_config_file(&config_path).is_err());
+    }
+
+    #[test]
+    fn test_toml_config_discovered_from_subdirectory() {
+        let dir = tempfile::tempdir().unwrap();
+        let nested = dir.path().join("packages").join("app");
+        fs::create_dir_all(&nested).unwrap();
+        fs::write(dir.path().join(".hotspots.toml"), "top = 5\n").unwrap();
+        // The walk-up result wins over JSON config in the project root
+        fs::write(dir.path().join(".hotspotsrc.json"), r#"{"top": 9}"#).unwrap();
+
+        let resolved = load_and_resolve_from(dir.path(), None, &nested).unwrap();
+        assert_eq!(resolved.top_n, Some(5));
+        assert_eq!(
+            resolved.config_path,
+            Some(dir.path().join(".hotspots.toml"))
+        );
+    }
+
+    #[test]
+    fn test_toml_config_above_repo_root_is_ignored() {
+        let dir = tempfile::tempdir().unwrap();
+        let repo = dir.path().join("repo");
+        let nested = repo.join("src");
+        fs::create_dir_all(&nested).unwrap();
+        fs::write(dir.path().join(".hotspots.toml"), "top = 5\n").unwrap();
+
+        let resolved = load_and_resolve_from(&repo, None, &nested).unwrap();
+        assert_eq!(resolved.top_n, None);
+        assert_eq!(resolved.config_path, None);
+
+        // Nor when the analyzed path lies outside the root
+        let resolved = load_and_resolve_from(&repo, None, dir.path()).unwrap();
+        assert_eq!(resolved.config_path, None);
+
+        // The repo's own file is found from below
+        fs::write(repo.join(".hotspots.toml"), "top = 7\n").unwrap();
+        let resolved = load_and_resolve_from(&repo, None, &nested).unwrap();
+        assert_eq!(resolved.top_n, Some(7));
+    }
+
+    #[test]
+    fn test_explicit_config_overrides_toml_config() {
+        let dir = tempfile::tempdir().unwrap();
+        fs::write(dir.path().join(".hotspots.toml"), "top = 5\n").unwrap();
+        let config_path = dir.path().join("custom.json");
+        fs::write(&config_path, r#"{"top": 7}"#).unwrap();
+
+        let resolved = load_and_resolve_from(dir.path(), Some(&config_path), dir.path()).unwrap();
+        assert_eq!(resolved.top_n, Some(7));
+    }
+
+    #[test]
+    fn test_cli_values_override_toml_config() {
+        let dir = tempfile::tempdir().unwrap();
+        fs::write(
+            dir.path().join(".hotspots.toml"),
+            "top = 5\nmin_lrs = 3.0\nformat = \"json\"\n",
+        )
+        .unwrap();
+        let resolved = load_and_resolve_from(dir.path(), None, dir.path()).unwrap();
+
+        // How `analyze` merges its flags: a given flag replaces the file value
+        let (cli_top, cli_min_lrs): (Option<usize>, Option<f64>) = (Some(10), None);
+        assert_eq!(cli_top.or(resolved.top_n), Some(10));
+        assert_eq!(cli_min_lrs.or(resolved.min_lrs), Some(3.0));
+        assert_eq!(Some("text").or(resolved.format.as_deref()), Some("text"));
+    }
+
+    #[test]
+    fn test_patterns_match_project_relative_paths() {
+        let dir = tempfile::tempdir().unwrap();
+        fs::write(
+            dir.path().join(".hotspotsrc.json"),
+            r#"{"include": ["src/**"]}"#,
+        )
+        .unwrap();
+        fs::write(
+            dir.path().join(".hotspotsignore"),
+            "# generated clients\n**/client/*.ts\n!**/client/index.ts\n",
+        )
+        .unwrap();
+
+        let resolved = load_and_resolve_from(dir.path(), None, dir.path()).unwrap();
+        let root = dir.path();
+        assert!(resolved.should_include(&root.join("src/api/app.ts")));
+        assert!(!resolved.should_include(&root.join("lib/app.ts")));
+        assert!(!resolved.should_include(&root.join("src/a/b/client/api.ts")));
+        assert!(resolved.should_include(&root.join("src/a/b/client/index.ts")));
+    }
+
+    #[test]
+    fn test_pattern_flags_override_config() {
+        let json = r#"{"include": ["src/**"], "exclude": ["src/legacy/**"]}"#;
+        let config: HotspotsConfig = serde_json::from_str(json).unwrap();
+        let mut resolved = config.resolve().unwrap();
+        resolved
+            .apply_pattern_flags(&["lib/**".to_string()], &["**/*_gen.go".to_string()])
+            .unwrap();
+
+        // --include replaces the config's includes
+        assert!(resolved.should_include(Path::new("lib/util.go")));
+        assert!(!resolved.should_include(Path::new("src/app.go")));
+        // --exclude adds to the config's excludes and the defaults
+        assert!(!resolved.should_include(Path::new("lib/deep/api_gen.go")));
+        assert!(!resolved.should_include(Path::new("lib/util_test.go")));
+        resolved
+            .apply_pattern_flags(&["src/**".to_string()], &[])
+            .unwrap();
+        assert!(!resolved.should_include(Path::new("src/legacy/old.go")));
+    }
+
+    #[test]
+    fn test_reject_invalid_pattern_flag() {
+        let mut resolved = ResolvedConfig::defaults().unwrap();
+        assert!(resolved
+            .apply_pattern_flags(&[], &["src/[a-".to_string()])
+            .is_err());
+    }
+
     #[test]
     fn test_partial_weights_use_defaults_for_rest() {
         let json = r#"{"weights": {"cc": 2.0}}"#;
@@ -1326,4 +2470,158 @@ mod tests {
         let config: HotspotsConfig = serde_json::from_str(json).unwrap();
         assert!(config.validate().is_err());
     }
+
+    #[test]
+    fn test_risk_score_weights_from_toml() {
+        let config: HotspotsConfig =
+            toml::from_str("[risk_score]\nnd = 2.0\nchurn = 0.0\n").unwrap();
+        config.validate().unwrap();
+        let resolved = config.resolve().unwrap();
+        assert_eq!(resolved.risk_score_weights.nd, 2.0);
+        assert_eq!(resolved.risk_score_weights.churn, 0.0);
+        let defaults = crate::risk::RiskScoreWeights::default();
+        assert_eq!(resolved.risk_score_weights.cc, defaults.cc);
+
+        let json = r#"{"risk_score": {"fo": -1.0}}"#;
+        let config: HotspotsConfig = serde_json::from_str(json).unwrap();
+        assert!(config.validate().is_err());
+    }
+
+    #[test]
+    fn test_sarif_rules_from_config() {
+        let json = r#"{
+            "sarif": {
+                "cc": { "threshold": 12, "level": "error" },
+                "loc": { "level": "none" },
+                "signature_complexity": { "threshold": 4 }
+            }
+        }"#;
+        let config: HotspotsConfig = serde_json::from_str(json).unwrap();
+        let resolved = config.resolve().unwrap();
+        let defaults = crate::sarif::MetricRules::default();
+        assert_eq!(resolved.sarif_rules.cc.threshold, 12);
+        assert_eq!(
+            resolved.sarif_rules.cc.level,
+            crate::sarif::SarifLevel::Error
+        );
+        assert_eq!(resolved.sarif_rules.loc.threshold, defaults.loc.threshold);
+        assert_eq!(
+            resolved.sarif_rules.loc.level,
+            crate::sarif::SarifLevel::None
+        );
+        assert_eq!(resolved.sarif_rules.nd, defaults.nd);
+        assert_eq!(resolved.sarif_rules.signature_complexity.threshold, 4);
+        assert_eq!(
+            resolved.sarif_rules.signature_complexity.level,
+            defaults.signature_complexity.level
+        );
+    }
+
+    #[test]
+    fn test_reject_invalid_sarif_rule() {
+        let json = r#"{"sarif": {"nd": {"level": "fatal"}}}"#;
+        let config: HotspotsConfig = serde_json::from_str(json).unwrap();
+        assert!(config.validate().is_err());
+
+        let json = r#"{"sarif": {"fo": {"threshold": 0}}}"#;
+        let config: HotspotsConfig = serde_json::from_str(json).unwrap();
+        assert!(config.validate().is_err());
+    }
+
+    #[test]
+    fn test_exempt_entries_resolve() {
+        let json = r#"{
+            "exempt": [
+                "./src/legacy/parser.ts::parseAll",
+                { "function": "src\\api\\router.ts::dispatch", "reason": "rewrite tracked in #412" }
+            ]
+        }"#;
+        let config: HotspotsConfig = serde_json::from_str(json).unwrap();
+        let resolved = config.resolve().unwrap();
+        assert_eq!(
+            resolved.exempt,
+            vec![
+                ExemptFunction {
+                    function_id: "src/legacy/parser.ts::parseAll".to_string(),
+                    reason: None,
+                },
+                ExemptFunction {
+                    function_id: "src/api/router.ts::dispatch".to_string(),
+                    reason: Some("rewrite tracked in #412".to_string()),
+                },
+            ]
+        );
+    }
+
+    #[test]
+    fn test_reject_invalid_exempt_entry() {
+        let json = r#"{"exempt": ["parseAll"]}"#;
+        let config: HotspotsConfig = serde_json::from_str(json).unwrap();
+        assert!(config.validate().is_err());
+
+        let json = r#"{"exempt": [{"function": "src/a.ts::f", "reason": "  "}]}"#;
+        let config: HotspotsConfig = serde_json::from_str(json).unwrap();
+        assert!(config.validate().is_err());
+
+        let json = r#"{"exempt": [{"function": "src/a.ts::f", "why": "legacy"}]}"#;
+        assert!(serde_json::from_str::<HotspotsConfig>(json).is_err());
+    }
+
+    #[test]
+    fn test_budgets_resolve() {
+        let json = r#"{"budgets": {"./src/api/": 200, "src\\core\\engine.ts": 40}}"#;
+        let config: HotspotsConfig = serde_json::from_str(json).unwrap();
+        let resolved = config.resolve().unwrap();
+        let budgets: Vec<(&str, usize)> = resolved
+            .budgets
+            .iter()
+            .map(|(path, &budget)| (path.as_str(), budget))
+            .collect();
+        assert_eq!(budgets, vec![("src/api", 200), ("src/core/engine.ts", 40)]);
+
+        let json = r#"{"budgets": {"src/api": 0}}"#;
+        let config: HotspotsConfig = serde_json::from_str(json).unwrap();
+        assert!(config.validate().is_err());
+    }
+
+    #[test]
+    fn test_nd_counts_resolve() {
+        use crate::language::Language;
+        use crate::metrics::{NdCounts, NestingConstruct};
+
+        let resolved = HotspotsConfig::default().resolve().unwrap();
+        assert_eq!(resolved.nd_counts_for(Language::Go), NdCounts::default());
+
+        let json = r#"{"nd_counts": ["if", "for", "while", "try"]}"#;
+        let config: HotspotsConfig = serde_json::from_str(json).unwrap();
+        let resolved = config.resolve().unwrap();
+        let counts = resolved.nd_counts_for(Language::Java);
+        assert!(counts.counts(NestingConstruct::Try));
+        assert!(!counts.counts(NestingConstruct::Switch));
+
+        let json = r#"{"nd_counts": {"default": ["if"], "go": ["if", "switch"]}}"#;
+        let config: HotspotsConfig = serde_json::from_str(json).unwrap();
+        let resolved = config.resolve().unwrap();
+        assert_eq!(
+            resolved.nd_counts_for(Language::Go).constructs(),
+            vec![NestingConstruct::If, NestingConstruct::Switch]
+        );
+        assert_eq!(
+            resolved
+                .nd_counts_for(Language::TypeScriptReact)
+                .constructs(),
+            vec![NestingConstruct::If]
+        );
+    }
+
+    #[test]
+    fn test_reject_invalid_nd_counts() {
+        for json in [
+            r#"{"nd_counts": ["if", "unless"]}"#,
+            r#"{"nd_counts": {"cobol": ["if"]}}"#,
+        ] {
+            let config: HotspotsConfig = serde_json::from_str(json).unwrap();
+            assert!(config.validate().is_err(), "{json}");
+        }
+    }
 }
@@ -0,0 +1,454 @@
+//! Analysis coverage report (`hotspots coverage`)
+//!
+//! Answers "how much of the repo did we actually understand?". Every regular
+//! file under the path is classified as analyzed or skipped, with the skip
+//! reason, so blind spots such as a large share of unsupported-language files
+//! are visible before anyone trusts the risk ranking.
+//!
+//! `--min-coverage` turns the report into a gate against parse failures: it
+//! fails when too few of the source files analysis attempted (analyzed or
+//! `parse_error`) were analyzed. Deliberate skips (ignored, generated,
+//! minified, unsupported) do not count against it.
+//!
+//! Global invariants enforced:
+//! - `files_analyzed + files_skipped.total() == files_seen`
+//! - Deterministic output ordering (languages by name, extensions by count
+//!   then name)
+
+use crate::analysis::{self, SourceSkip};
+use crate::config::ResolvedConfig;
+use crate::language::Language;
+use crate::AnalysisOptions;
+use anyhow::{Context, Result};
+use serde::{Deserialize, Serialize};
+use std::collections::BTreeMap;
+use std::path::{Path, PathBuf};
+use swc_common::{sync::Lrc, SourceMap};
+
+/// Schema version for `hotspots coverage --format json` output.
+pub const COVERAGE_SCHEMA_VERSION: u32 = 1;
+
+/// Skipped-file counts by reason
+#[derive(Debug, Clone, Copy, Default, PartialEq, Eq, Serialize, Deserialize)]
+pub struct SkippedFiles {
+    /// Left out by config include/exclude globs (including the default test
+    /// and build excludes) or a TypeScript declaration file (`.d.ts`)
+    pub ignored: usize,
+    /// Path suggests vendored or generated third-party code
+    pub generated: usize,
+    /// Minified: at least 3 lines over 1000 characters
+    pub too_big: usize,
+    /// Extension of a language Hotspots does not analyze
+    pub unsupported: usize,
+    /// Supported language, but unreadable or failed to parse
+    pub parse_error: usize,
+}
+
+impl SkippedFiles {
+    pub fn total(&self) -> usize {
+        self.ignored + self.generated + self.too_big + self.unsupported + self.parse_error
+    }
+
+    fn count(&mut self, reason: SkipReason) {
+        let counter = match reason {
+            SkipReason::Ignored => &mut self.ignored,
+            SkipReason::Generated => &mut self.generated,
+            SkipReason::TooBig => &mut self.too_big,
+            SkipReason::Unsupported => &mut self.unsupported,
+            SkipReason::ParseError => &mut self.parse_error,
+        };
+        *counter += 1;
+    }
+}
+
+/// Files and functions for one supported language
+#[derive(Debug, Clone, PartialEq, Eq, Serialize, Deserialize)]
+pub struct LanguageCoverage {
+    pub language: String,
+    /// Files with this language's extensions, analyzed or not
+    pub files: usize,
+    pub files_analyzed: usize,
+    /// Functions reported from the analyzed files
+    pub functions: usize,
+}
+
+/// Unsupported files sharing an extension
+#[derive(Debug, Clone, PartialEq, Eq, Serialize, Deserialize)]
+pub struct ExtensionCount {
+    /// Lowercased extension without the dot; empty for files without one
+    pub extension: String,
+    pub files: usize,
+}
+
+/// Result of a `hotspots coverage` run.
+#[derive(Debug, Clone, Serialize, Deserialize)]
+pub struct CoverageReport {
+    pub schema_version: u32,
+    pub files_seen: usize,
+    pub files_analyzed: usize,
+    pub files_skipped: SkippedFiles,
+    /// `files_analyzed` as a percentage of `files_seen` (0 when nothing was seen)
+    pub analyzed_pct: f64,
+    /// `files_analyzed` as a percentage of the source files analysis attempted,
+    /// `files_analyzed + files_skipped.parse_error` (100 when there were none)
+    pub source_analyzed_pct: f64,
+    pub functions: usize,
+    /// Supported languages present under the path, sorted by name
+    pub languages: Vec<LanguageCoverage>,
+    /// Extensions behind `files_skipped.unsupported`, most common first
+    pub unsupported_extensions: Vec<ExtensionCount>,
+}
+
+/// Why a file was skipped; one variant per [`SkippedFiles`] counter
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+enum SkipReason {
+    Ignored,
+    Generated,
+    TooBig,
+    Unsupported,
+    ParseError,
+}
+
+/// What happened to one file
+#[derive(Debug, Clone, Copy)]
+enum Outcome {
+    Analyzed { functions: usize },
+    Skipped(SkipReason),
+}
+
+/// Classify every file under `path` (a file or directory).
+///
+/// Directories that discovery never enters (`node_modules`, `target`, dot
+/// directories, ...), paths ignored by `.gitignore` (unless the config's
+/// `gitignore` is off), and symlinks are not walked, so their files are not
+/// counted as seen.
+pub fn run(path: &Path, config: Option<&ResolvedConfig>) -> Result<CoverageReport> {
+    use rayon::prelude::*;
+
+    let mut files = Vec::new();
+    if path.is_file() {
+        files.push(path.to_path_buf());
+    } else if path.is_dir() {
+        let gitignore = config.map_or(true, |c| c.gitignore);
+        let mut gitignores = crate::gitignore::GitignoreStack::for_walk(path, gitignore);
+        collect_all_files(path, &mut gitignores, &mut files)?;
+    } else {
+        anyhow::bail!("Path does not exist: {}", path.display());
+    }
+    files.sort();
+
+    let outcomes: Vec<(Option<Language>, Outcome)> = files
+        .par_iter()
+        .enumerate()
+        .map(|(file_index, file)| classify(file, file_index, config))
+        .collect();
+
+    let mut skipped = SkippedFiles::default();
+    let mut languages: BTreeMap<&'static str, LanguageCoverage> = BTreeMap::new();
+    let mut extensions: BTreeMap<String, usize> = BTreeMap::new();
+    for (file, (language, outcome)) in files.iter().zip(outcomes) {
+        if let Outcome::Skipped(reason) = outcome {
+            skipped.count(reason);
+        }
+        let Some(language) = language else {
+            *extensions.entry(extension_of(file)).or_default() += 1;
+            continue;
+        };
+        let entry = languages
+            .entry(language.name())
+            .or_insert_with(|| LanguageCoverage {
+                language: language.name().to_string(),
+                files: 0,
+                files_analyzed: 0,
+                functions: 0,
+            });
+        entry.files += 1;
+        if let Outcome::Analyzed { functions } = outcome {
+            entry.files_analyzed += 1;
+            entry.functions += functions;
+        }
+    }
+
+    let languages: Vec<LanguageCoverage> = languages.into_values().collect();
+    let files_analyzed: usize = languages.iter().map(|l| l.files_analyzed).sum();
+    let mut unsupported_extensions: Vec<ExtensionCount> = extensions
+        .into_iter()
+        .map(|(extension, files)| ExtensionCount { extension, files })
+        .collect();
+    unsupported_extensions.sort_by(|a, b| b.files.cmp(&a.files));
+
+    let source_files = files_analyzed + skipped.parse_error;
+    Ok(CoverageReport {
+        schema_version: COVERAGE_SCHEMA_VERSION,
+        files_seen: files.len(),
+        files_analyzed,
+        files_skipped: skipped,
+        analyzed_pct: if files.is_empty() {
+            0.0
+        } else {
+            files_analyzed as f64 / files.len() as f64 * 100.0
+        },
+        source_analyzed_pct: if source_files == 0 {
+            100.0
+        } else {
+            files_analyzed as f64 / source_files as f64 * 100.0
+        },
+        functions: languages.iter().map(|l| l.functions).sum(),
+        languages,
+        unsupported_extensions,
+    })
+}
+
+/// Apply discovery, config filtering, skip checks, and analysis to one file
+fn classify(
+    file: &Path,
+    file_index: usize,
+    config: Option<&ResolvedConfig>,
+) -> (Option<Language>, Outcome) {
+    let Some(language) = Language::from_path(file) else {
+        return (None, Outcome::Skipped(SkipReason::Unsupported));
+    };
+    let is_declaration = file
+        .file_name()
+        .and_then(|n| n.to_str())
+        .is_some_and(|n| !crate::is_supported_source_file(n));
+    if is_declaration || config.is_some_and(|c| !c.should_include(file)) {
+        return (Some(language), Outcome::Skipped(SkipReason::Ignored));
+    }
+    let Ok(src) = std::fs::read_to_string(file) else {
+        return (Some(language), Outcome::Skipped(SkipReason::ParseError));
+    };
+    let outcome = match analysis::source_skip(file, &src) {
+        Some(SourceSkip::Minified { .. }) => Outcome::Skipped(SkipReason::TooBig),
+        Some(SourceSkip::Vendored) => Outcome::Skipped(SkipReason::Generated),
+        None => {
+            let cm: Lrc<SourceMap> = Default::default();
+            let options = AnalysisOptions {
+                min_lrs: None,
+                top_n: None,
+            };
+            match analysis::analyze_source_with_config(
+                file, &src, &cm, file_index, &options, config,
+            ) {
+                Ok(reports) => Outcome::Analyzed {
+                    functions: reports.len(),
+                },
+                Err(_) => Outcome::Skipped(SkipReason::ParseError),
+            }
+        }
+    };
+    (Some(language), outcome)
+}
+
+/// Every regular file under `dir`, pruning the same directories as discovery
+fn collect_all_files(
+    dir: &Path,
+    gitignores: &mut crate::gitignore::GitignoreStack,
+    files: &mut Vec<PathBuf>,
+) -> Result<()> {
+    for entry_result in std::fs::read_dir(dir)
+        .with_context(|| format!("Failed to read directory: {}", dir.display()))?
+    {
+        let path = entry_result?.path();
+        let metadata = std::fs::symlink_metadata(&path)
+            .with_context(|| format!("Failed to read metadata: {}", path.display()))?;
+        if gitignores.is_ignored(&path, metadata.is_dir()) {
+            continue;
+        }
+        if metadata.is_dir() {
+            if !path
+                .file_name()
+                .and_then(|n| n.to_str())
+                .is_some_and(crate::is_skipped_dir)
+            {
+                let entered = gitignores.enter(&path);
+                collect_all_files(&path, gitignores, files)?;
+                gitignores.leave(entered);
+            }
+        } else if metadata.is_file() {
+            files.push(path);
+        }
+    }
+    Ok(())
+}
+
+fn extension_of(path: &Path) -> String {
+    path.extension()
+        .map(|e| e.to_string_lossy().to_lowercase())
+        .unwrap_or_default()
+}
+
+impl CoverageReport {
+    pub fn to_json(&self) -> Result<String> {
+        Ok(serde_json::to_string_pretty(self)?)
+    }
+
+    /// Fail when less than `min` (a fraction, 0.0 to 1.0) of the source files
+    /// analysis attempted were analyzed (`--min-coverage`)
+    pub fn check_min_coverage(&self, min: f64) -> Result<()> {
+        if !(0.0..=1.0).contains(&min) {
+            anyhow::bail!("--min-coverage must be between 0.0 and 1.0, got {}", min);
+        }
+        if self.source_analyzed_pct < min * 100.0 {
+            anyhow::bail!(
+                "analysis coverage {:.1}% is below --min-coverage {:.1}% ({} of {} source files failed to parse)",
+                self.source_analyzed_pct,
+                min * 100.0,
+                self.files_skipped.parse_error,
+                self.files_analyzed + self.files_skipped.parse_error
+            );
+        }
+        Ok(())
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::config::HotspotsConfig;
+
+    fn write(root: &Path, rel: &str, contents: &str) {
+        let path = root.join(rel);
+        std::fs::create_dir_all(path.parent().unwrap()).unwrap();
+        std::fs::write(path, contents).unwrap();
+    }
+
+    /// One file per outcome, plus a pruned directory that must not be seen
+    fn fixture() -> tempfile::TempDir {
+        let dir = tempfile::tempdir().unwrap();
+        let root = dir.path();
+        write(
+            root,
+            "src/app.ts",
+            "function a(x: number) { if (x) { return 1; } return 0; }\nfunction b() {}\n",
+        );
+        write(root, "src/lib.py", "def f(x):\n    return x\n");
+        write(root, "src/broken.ts", "function (( {\n");
+        write(root, "src/app.test.ts", "function t() {}\n");
+        write(root, "src/types.d.ts", "declare const x: number;\n");
+        write(root, "third_party/dep/index.js", "function dep() {}\n");
+        let long_line = format!("var x = \"{}\";\n", "a".repeat(1100));
+        write(root, "src/bundle.js", &long_line.repeat(3));
+        write(root, "src/Main.kt", "fun main() {}\n");
+        write(root, "src/Util.kt", "fun util() {}\n");
+        write(root, "README.md", "# readme\n");
+        write(root, "node_modules/pkg/index.js", "function pkg() {}\n");
+        dir
+    }
+
+    #[test]
+    fn test_counts_reconcile() {
+        let dir = fixture();
+        let config = HotspotsConfig::default().resolve().unwrap();
+        let report = run(dir.path(), Some(&config)).unwrap();
+
+        assert_eq!(report.files_seen, 10);
+        assert_eq!(
+            report.files_analyzed + report.files_skipped.total(),
+            report.files_seen
+        );
+        assert_eq!(report.files_analyzed, 2);
+        assert_eq!(
+            report.files_skipped,
+            SkippedFiles {
+                ignored: 2,
+                generated: 1,
+                too_big: 1,
+                unsupported: 3,
+                parse_error: 1,
+            }
+        );
+        assert!((report.analyzed_pct - 20.0).abs() < 1e-9);
+        // 2 analyzed of 3 attempted (broken.ts failed to parse)
+        assert!((report.source_analyzed_pct - 200.0 / 3.0).abs() < 1e-9);
+        assert_eq!(report.functions, 3);
+
+        let per_language = |name: &str| {
+            report
+                .languages
+                .iter()
+                .find(|l| l.language == name)
+                .unwrap_or_else(|| panic!("missing {name}"))
+        };
+        let ts = per_language("TypeScript");
+        assert_eq!((ts.files, ts.files_analyzed, ts.functions), (4, 1, 2));
+        let js = per_language("JavaScript");
+        assert_eq!((js.files, js.files_analyzed), (2, 0));
+        assert_eq!(
+            report.languages.iter().map(|l| l.files).sum::<usize>()
+                + report.files_skipped.unsupported,
+            report.files_seen
+        );
+
+        let extensions: Vec<(&str, usize)> = report
+            .unsupported_extensions
+            .iter()
+            .map(|e| (e.extension.as_str(), e.files))
+            .collect();
+        assert_eq!(extensions, vec![("kt", 2), ("md", 1)]);
+    }
+
+    #[test]
+    fn test_without_config_nothing_is_ignored_by_globs() {
+        let dir = fixture();
+        let report = run(dir.path(), None).unwrap();
+        // Only the declaration file is ignored; the test file is analyzed
+        assert_eq!(report.files_skipped.ignored, 1);
+        assert_eq!(report.files_analyzed, 3);
+        assert_eq!(
+            report.files_analyzed + report.files_skipped.total(),
+            report.files_seen
+        );
+    }
+
+    #[test]
+    fn test_missing_path_is_an_error() {
+        assert!(run(Path::new("/nonexistent/hotspots-coverage"), None).is_err());
+    }
+
+    #[test]
+    fn test_min_coverage_fails_when_parse_errors_shrink_the_analyzed_set() {
+        let dir = tempfile::tempdir().unwrap();
+        let root = dir.path();
+        write(root, "src/ok.ts", "function ok() { return 1; }\n");
+        for i in 0..3 {
+            write(root, &format!("src/broken{i}.ts"), "function (( {\n");
+        }
+        // Deliberate skips do not count against coverage
+        write(root, "README.md", "# readme\n");
+        write(root, "vendor/lib.js", "function lib() {}\n");
+
+        let report = run(root, None).unwrap();
+        assert_eq!(report.files_skipped.parse_error, 3);
+        assert!((report.source_analyzed_pct - 25.0).abs() < 1e-9);
+
+        let err = report.check_min_coverage(0.9).unwrap_err().to_string();
+        assert!(err.contains("25.0%"), "{err}");
+        assert!(err.contains("3 of 4 source files"), "{err}");
+        assert!(report.check_min_coverage(0.25).is_ok());
+        assert!(report.check_min_coverage(0.0).is_ok());
+    }
+
+    #[test]
+    fn test_min_coverage_passes_without_parse_errors() {
+        let dir = tempfile::tempdir().unwrap();
+        write(dir.path(), "src/ok.ts", "function ok() { return 1; }\n");
+        write(dir.path(), "notes.txt", "unsupported\n");
+        let report = run(dir.path(), None).unwrap();
+        assert_eq!(report.source_analyzed_pct, 100.0);
+        assert!(report.check_min_coverage(1.0).is_ok());
+
+        // No source files at all: nothing failed to parse
+        let empty = tempfile::tempdir().unwrap();
+        let report = run(empty.path(), None).unwrap();
+        assert!(report.check_min_coverage(0.9).is_ok());
+    }
+
+    #[test]
+    fn test_min_coverage_rejects_out_of_range_threshold() {
+        let dir = tempfile::tempdir().unwrap();
+        let report = run(dir.path(), None).unwrap();
+        assert!(report.check_min_coverage(90.0).is_err());
+        assert!(report.check_min_coverage(-0.1).is_err());
+    }
+}
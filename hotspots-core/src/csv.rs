@@ -0,0 +1,248 @@
+//! CSV output (`--format csv`)
+//!
+//! One row per function, for spreadsheets and data warehouses. The header is
+//! stable: [`BASE_COLUMNS`] always lead, in that order, and each optional
+//! metric appends its columns after them, in a fixed order, only when it is
+//! enabled ([`CsvColumns`]). A run's header therefore depends on its flags,
+//! never on which functions were found. Fields containing a comma, a double
+//! quote, or a line break are quoted per RFC 4180 (`"` doubled inside).
+//!
+//! Global invariants enforced:
+//! - Deterministic output ordering (follows the input report order)
+
+use crate::report::FunctionRiskReport;
+use std::path::Path;
+
+/// Columns present in every CSV report, in order
+pub const BASE_COLUMNS: &[&str] = &[
+    "file",
+    "function",
+    "start_line",
+    "end_line",
+    "cc",
+    "nd",
+    "fo",
+    "ns",
+    "cognitive",
+    "loc",
+    "params",
+    "lrs",
+    "band",
+];
+
+/// Optional metrics to add columns for. Columns follow the base ones in the
+/// order of these fields; a value a function lacks (e.g. Halstead metrics in a
+/// language without them) is an empty field.
+#[derive(Debug, Clone, Copy, Default)]
+pub struct CsvColumns {
+    /// `fi` (`--fan-in`)
+    pub fan_in: bool,
+    /// `halstead_volume`, `halstead_difficulty`, `halstead_effort`,
+    /// `maintainability` (`--halstead`)
+    pub halstead: bool,
+    /// `sloc`, `comment_lines`, `blank_lines` (`--line-counts`)
+    pub line_counts: bool,
+    /// `risk_score` (`--sort risk-score`)
+    pub risk_score: bool,
+}
+
+impl CsvColumns {
+    /// The full header for these columns
+    pub fn header(&self) -> Vec<&'static str> {
+        let mut header = BASE_COLUMNS.to_vec();
+        if self.fan_in {
+            header.push("fi");
+        }
+        if self.halstead {
+            header.extend([
+                "halstead_volume",
+                "halstead_difficulty",
+                "halstead_effort",
+                "maintainability",
+            ]);
+        }
+        if self.line_counts {
+            header.extend(["sloc", "comment_lines", "blank_lines"]);
+        }
+        if self.risk_score {
+            header.push("risk_score");
+        }
+        header
+    }
+}
+
+/// Render `reports` as CSV: the header, then one row per report. Paths are
+/// relative to `base` when they fall under it.
+pub fn render_csv(reports: &[FunctionRiskReport], base: &Path, columns: CsvColumns) -> String {
+    let mut out = csv_line(columns.header());
+    for r in reports {
+        let m = &r.metrics;
+        let end_line = r.line + m.loc.saturating_sub(1);
+        let mut row = vec![
+            crate::treemap::relative_path(&r.file, base),
+            r.function.clone(),
+            r.line.to_string(),
+            end_line.to_string(),
+            m.cc.to_string(),
+            m.nd.to_string(),
+            m.fo.to_string(),
+            m.ns.to_string(),
+            m.cognitive.to_string(),
+            m.loc.to_string(),
+            m.params.to_string(),
+            r.lrs.to_string(),
+            r.band.as_str().to_string(),
+        ];
+        if columns.fan_in {
+            row.push(m.fi.to_string());
+        }
+        if columns.halstead {
+            let h = m.halstead.as_ref();
+            row.push(optional(h.map(|h| h.volume)));
+            row.push(optional(h.map(|h| h.difficulty)));
+            row.push(optional(h.map(|h| h.effort)));
+            row.push(optional(m.maintainability));
+        }
+        if columns.line_counts {
+            row.push(optional(m.sloc));
+            row.push(optional(m.comment_lines));
+            row.push(optional(m.blank_lines));
+        }
+        if columns.risk_score {
+            row.push(optional(r.risk_score));
+        }
+        out.push_str(&csv_line(row));
+    }
+    out
+}
+
+fn optional<T: ToString>(value: Option<T>) -> String {
+    value.map_or_else(String::new, |v| v.to_string())
+}
+
+/// One CSV record, newline-terminated
+fn csv_line<S: AsRef<str>>(fields: impl IntoIterator<Item = S>) -> String {
+    let fields: Vec<String> = fields
+        .into_iter()
+        .map(|f| escape_field(f.as_ref()))
+        .collect();
+    format!("{}\n", fields.join(","))
+}
+
+/// Quote a field that contains a comma, double quote, or line break
+fn escape_field(field: &str) -> String {
+    if field.contains([',', '"', '\n', '\r']) {
+        format!("\"{}\"", field.replace('"', "\"\""))
+    } else {
+        field.to_string()
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::language::Language;
+    use crate::report::{MetricsReport, RiskReport};
+    use crate::risk::RiskBand;
+
+    fn make_report(file: &str, function: &str) -> FunctionRiskReport {
+        FunctionRiskReport {
+            file: file.to_string(),
+            function: function.to_string(),
+            owner: None,
+            line: 10,
+            language: Language::Rust,
+            metrics: MetricsReport {
+                cc: 3,
+                cognitive: 2,
+                nd: 1,
+                fo: 4,
+                fi: 0,
+                ns: 1,
+                loc: 6,
+                signature_complexity: 0,
+                params: 2,
+                guard_clauses: 0,
+                max_condition_ops: 0,
+                halstead: None,
+                maintainability: None,
+                sloc: Some(5),
+                comment_lines: Some(1),
+                blank_lines: Some(0),
+                nd_line: None,
+                cc_lines: None,
+                is_async: false,
+                await_in_loop: 0,
+                ns_breakdown: None,
+            },
+            risk: RiskReport {
+                r_cc: 2.0,
+                r_nd: 1.0,
+                r_fo: 2.3,
+                r_ns: 1.0,
+            },
+            lrs: 4.5,
+            band: RiskBand::Moderate,
+            risk_score: None,
+            suppression_reason: None,
+            patterns: vec![],
+            pattern_details: None,
+            callees: vec![],
+            explanation: None,
+            arrow_depth: 0,
+            aliases: vec![],
+            structure: None,
+            cc_breakdown: None,
+            is_public: false,
+            file_top_level_decisions: 0,
+        }
+    }
+
+    #[test]
+    fn test_fields_with_commas_and_quotes_are_quoted() {
+        let reports = [
+            make_report("/repo/src/map.rs", "Map<K, V>::get"),
+            make_report("/repo/src/say.rs", "say \"hi\""),
+        ];
+        let out = render_csv(&reports, Path::new("/repo"), CsvColumns::default());
+        let lines: Vec<&str> = out.lines().collect();
+        assert_eq!(lines[0], BASE_COLUMNS.join(","));
+        assert_eq!(
+            lines[1],
+            "src/map.rs,\"Map<K, V>::get\",10,15,3,1,4,1,2,6,2,4.5,moderate"
+        );
+        assert_eq!(
+            lines[2],
+            "src/say.rs,\"say \"\"hi\"\"\",10,15,3,1,4,1,2,6,2,4.5,moderate"
+        );
+    }
+
+    #[test]
+    fn test_optional_columns_follow_flags() {
+        let columns = CsvColumns {
+            halstead: true,
+            line_counts: true,
+            ..Default::default()
+        };
+        let header = columns.header();
+        assert_eq!(&header[..BASE_COLUMNS.len()], BASE_COLUMNS);
+        assert_eq!(
+            &header[BASE_COLUMNS.len()..],
+            [
+                "halstead_volume",
+                "halstead_difficulty",
+                "halstead_effort",
+                "maintainability",
+                "sloc",
+                "comment_lines",
+                "blank_lines"
+            ]
+        );
+
+        // No Halstead metrics for this function: empty fields, same width
+        let out = render_csv(&[make_report("a.rs", "f")], Path::new("/repo"), columns);
+        let row = out.lines().nth(1).unwrap();
+        assert!(row.ends_with(",moderate,,,,,5,1,0"), "{row}");
+        assert_eq!(row.split(',').count(), header.len());
+    }
+}
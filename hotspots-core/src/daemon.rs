@@ -0,0 +1,400 @@
+//! Long-lived analysis server (`hotspots daemon`)
+//!
+//! Editors and other long-lived integrations pay process start-up and full
+//! re-analysis on every invocation. The daemon listens on a unix socket and
+//! keeps per-file results cached between requests; a file is re-analyzed only
+//! when its modification time, size, or the scoring config changes.
+//!
+//! Protocol (version [`PROTOCOL_VERSION`]): newline-delimited JSON. Each line a
+//! client writes is one [`Request`], tagged by `method`; the daemon answers each
+//! with exactly one [`Response`] line. A connection may carry any number of
+//! requests. Requests are served one connection at a time, and each analysis
+//! still runs file-parallel.
+//!
+//! ```text
+//! → {"method":"analyze_path","path":"/repo/src","top_n":20}
+//! ← {"protocol":1,"ok":true,"reports":[...],"stats":{"files":42,"cache_hits":40}}
+//! → {"method":"analyze_stdin","path":"src/new.ts","source":"export function f() {}"}
+//! ← {"protocol":1,"ok":true,"reports":[...]}
+//! → {"method":"shutdown"}
+//! ← {"protocol":1,"ok":true}
+//! ```
+//!
+//! Global invariants enforced:
+//! - Responses are identical to in-process analysis of the same input
+//! - Deterministic output ordering
+
+use crate::incremental::IncrementalCache;
+use crate::language::{AnonNaming, SqlDialect};
+use crate::metrics::CcMode;
+use crate::report::{sort_reports, FunctionRiskReport};
+use crate::{analysis, AnalysisOptions};
+use anyhow::{Context, Result};
+use serde::{Deserialize, Serialize};
+use std::io::{BufRead, BufReader, Write};
+use std::os::unix::net::{UnixListener, UnixStream};
+use std::path::{Path, PathBuf};
+use swc_common::{sync::Lrc, SourceMap};
+
+pub use crate::incremental::CacheStats;
+
+/// Wire protocol version, echoed in every response.
+pub const PROTOCOL_VERSION: u32 = 1;
+
+/// A single client request.
+#[derive(Debug, Clone, Serialize, Deserialize, PartialEq)]
+#[serde(tag = "method", rename_all = "snake_case")]
+pub enum Request {
+    /// Analyze a file or directory on the daemon's filesystem.
+    AnalyzePath {
+        /// Absolute path to analyze
+        path: PathBuf,
+        /// Directory config discovery starts from (defaults to `path`)
+        #[serde(default, skip_serializing_if = "Option::is_none")]
+        root: Option<PathBuf>,
+        /// Explicit config file, as with `--config`
+        #[serde(default, skip_serializing_if = "Option::is_none")]
+        config: Option<PathBuf>,
+        #[serde(default, skip_serializing_if = "Option::is_none")]
+        min_lrs: Option<f64>,
+        #[serde(default, skip_serializing_if = "Option::is_none")]
+        top_n: Option<usize>,
+        /// Override for the config's `dedup_symlinks`
+        #[serde(default, skip_serializing_if = "std::ops::Not::not")]
+        dedup_symlinks: bool,
+        /// Override for the config's `public_only`
+        #[serde(default, skip_serializing_if = "std::ops::Not::not")]
+        public_only: bool,
+        /// Also analyze test files and functions, as with `--include-tests`
+        #[serde(default, skip_serializing_if = "std::ops::Not::not")]
+        include_tests: bool,
+        /// Also analyze paths ignored by `.gitignore`, as with `--no-gitignore`
+        #[serde(default, skip_serializing_if = "std::ops::Not::not")]
+        no_gitignore: bool,
+        /// Compute Halstead metrics, as with `--halstead`
+        #[serde(default, skip_serializing_if = "std::ops::Not::not")]
+        halstead: bool,
+        /// Count source, comment, and blank lines, as with `--line-counts`
+        #[serde(default, skip_serializing_if = "std::ops::Not::not")]
+        line_counts: bool,
+        /// Record the line of each function's deepest nest, as with `--explain`
+        #[serde(default, skip_serializing_if = "std::ops::Not::not")]
+        nd_lines: bool,
+        /// Record the lines behind each function's CC, as with `--explain`
+        #[serde(default, skip_serializing_if = "std::ops::Not::not")]
+        cc_lines: bool,
+        /// Report closures separately, as with `--separate-closures`
+        #[serde(default, skip_serializing_if = "std::ops::Not::not")]
+        separate_closures: bool,
+        /// Break NS down by kind of exit, as with `--ns-breakdown`
+        #[serde(default, skip_serializing_if = "std::ops::Not::not")]
+        ns_breakdown: bool,
+        /// Count callers into `metrics.fi`, as with `--fan-in`
+        #[serde(default, skip_serializing_if = "std::ops::Not::not")]
+        fan_in: bool,
+        /// Override for the config's `sql_dialect`
+        #[serde(default, skip_serializing_if = "Option::is_none")]
+        sql_dialect: Option<SqlDialect>,
+        /// Override for the config's `cc_mode`
+        #[serde(default, skip_serializing_if = "Option::is_none")]
+        cc_mode: Option<CcMode>,
+        /// Leave Go method calls out of FO, as with `--no-fo-methods`
+        #[serde(default, skip_serializing_if = "std::ops::Not::not")]
+        no_fo_methods: bool,
+        /// Override for the config's `anon_naming`
+        #[serde(default, skip_serializing_if = "Option::is_none")]
+        anon_naming: Option<AnonNaming>,
+        /// Patterns replacing the config's `include`, as with `--include`
+        #[serde(default, skip_serializing_if = "Vec::is_empty")]
+        include: Vec<String>,
+        /// Patterns added to the config's `exclude`, as with `--exclude`
+        #[serde(default, skip_serializing_if = "Vec::is_empty")]
+        exclude: Vec<String>,
+    },
+    /// Analyze source text sent in the request, with default weights and
+    /// thresholds. `path` selects the language and is reported as the file;
+    /// it is never read.
+    AnalyzeStdin { path: PathBuf, source: String },
+    /// Liveness check; answers with an empty successful response.
+    Ping,
+    /// Answer, then stop accepting connections.
+    Shutdown,
+}
+
+/// The daemon's answer to one [`Request`].
+#[derive(Debug, Clone, Serialize, Deserialize)]
+pub struct Response {
+    pub protocol: u32,
+    pub ok: bool,
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub error: Option<String>,
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub reports: Option<Vec<FunctionRiskReport>>,
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub stats: Option<CacheStats>,
+}
+
+impl Response {
+    fn success() -> Self {
+        Response {
+            protocol: PROTOCOL_VERSION,
+            ok: true,
+            error: None,
+            reports: None,
+            stats: None,
+        }
+    }
+
+    fn failure(err: &anyhow::Error) -> Self {
+        Response {
+            ok: false,
+            error: Some(format!("{err:#}")),
+            ..Response::success()
+        }
+    }
+}
+
+/// Daemon state: the per-file result cache.
+#[derive(Default)]
+pub struct Daemon {
+    cache: IncrementalCache,
+}
+
+impl Daemon {
+    pub fn new() -> Self {
+        Self::default()
+    }
+
+    /// Serve connections on `listener` until a `shutdown` request arrives.
+    pub fn serve(&self, listener: &UnixListener) -> Result<()> {
+        for stream in listener.incoming() {
+            let stream = stream.context("failed to accept daemon connection")?;
+            match self.serve_connection(stream) {
+                Ok(true) => return Ok(()),
+                Ok(false) => {}
+                Err(e) => eprintln!("warning: daemon connection failed: {e:#}"),
+            }
+        }
+        Ok(())
+    }
+
+    /// Answer requests on one connection until EOF. Returns true after `shutdown`.
+    fn serve_connection(&self, stream: UnixStream) -> Result<bool> {
+        let mut writer = stream.try_clone()?;
+        for line in BufReader::new(stream).lines() {
+            let line = line?;
+            if line.trim().is_empty() {
+                continue;
+            }
+            let (response, shutdown) = match serde_json::from_str::<Request>(&line) {
+                Ok(request) => {
+                    let shutdown = request == Request::Shutdown;
+                    (self.handle(request), shutdown)
+                }
+                Err(e) => (
+                    Response::failure(&anyhow::anyhow!("invalid request: {e}")),
+                    false,
+                ),
+            };
+            serde_json::to_writer(&mut writer, &response)?;
+            writer.write_all(b"\n")?;
+            writer.flush()?;
+            if shutdown {
+                return Ok(true);
+            }
+        }
+        Ok(false)
+    }
+
+    /// Answer one request.
+    pub fn handle(&self, request: Request) -> Response {
+        let result = match request {
+            Request::AnalyzePath {
+                path,
+                root,
+                config,
+                min_lrs,
+                top_n,
+                dedup_symlinks,
+                public_only,
+                include_tests,
+                no_gitignore,
+                halstead,
+                line_counts,
+                nd_lines,
+                cc_lines,
+                separate_closures,
+                ns_breakdown,
+                fan_in,
+                sql_dialect,
+                cc_mode,
+                no_fo_methods,
+                anon_naming,
+                include,
+                exclude,
+            } => {
+                let root = root.unwrap_or_else(|| path.clone());
+                crate::config::load_and_resolve_from(&root, config.as_deref(), &path)
+                    .context("failed to load configuration")
+                    .and_then(|mut resolved| {
+                        resolved.dedup_symlinks |= dedup_symlinks;
+                        resolved.public_only |= public_only;
+                        resolved.include_tests |= include_tests;
+                        resolved.gitignore &= !no_gitignore;
+                        resolved.halstead |= halstead;
+                        resolved.line_counts |= line_counts;
+                        resolved.nd_lines |= nd_lines;
+                        resolved.cc_lines |= cc_lines;
+                        resolved.separate_closures |= separate_closures;
+                        resolved.ns_breakdown |= ns_breakdown;
+                        resolved.fan_in |= fan_in;
+                        resolved.sql_dialect = sql_dialect.or(resolved.sql_dialect);
+                        resolved.cc_mode = cc_mode.unwrap_or(resolved.cc_mode);
+                        resolved.fo_methods &= !no_fo_methods;
+                        resolved.anon_naming = anon_naming.unwrap_or(resolved.anon_naming);
+                        resolved.apply_pattern_flags(&include, &exclude)?;
+                        self.cache.analyze_path(
+                            &path,
+                            &resolved,
+                            AnalysisOptions { min_lrs, top_n },
+                        )
+                    })
+                    .map(|(reports, stats)| Response {
+                        reports: Some(reports),
+                        stats: Some(stats),
+                        ..Response::success()
+                    })
+            }
+            Request::AnalyzeStdin { path, source } => {
+                let cm: Lrc<SourceMap> = Default::default();
+                let options = AnalysisOptions {
+                    min_lrs: None,
+                    top_n: None,
+                };
+                analysis::analyze_source(&path, &source, &cm, 0, &options).map(|reports| Response {
+                    reports: Some(sort_reports(reports)),
+                    ..Response::success()
+                })
+            }
+            Request::Ping | Request::Shutdown => Ok(Response::success()),
+        };
+        result.unwrap_or_else(|e| Response::failure(&e))
+    }
+}
+
+/// Bind the daemon socket at `socket`.
+///
+/// A leftover socket file from a daemon that is no longer running is replaced;
+/// a live one is an error.
+pub fn bind(socket: &Path) -> Result<UnixListener> {
+    if socket.exists() {
+        if UnixStream::connect(socket).is_ok() {
+            anyhow::bail!("a daemon is already listening on {}", socket.display());
+        }
+        std::fs::remove_file(socket)
+            .with_context(|| format!("failed to remove stale socket {}", socket.display()))?;
+    }
+    UnixListener::bind(socket).with_context(|| format!("failed to bind {}", socket.display()))
+}
+
+/// Send one request to the daemon at `socket` and wait for its response.
+///
+/// Failed requests (`ok: false`) and protocol mismatches are returned as errors.
+pub fn request(socket: &Path, request: &Request) -> Result<Response> {
+    let stream = UnixStream::connect(socket)
+        .with_context(|| format!("failed to connect to daemon at {}", socket.display()))?;
+    let mut writer = stream.try_clone()?;
+    serde_json::to_writer(&mut writer, request)?;
+    writer.write_all(b"\n")?;
+    writer.flush()?;
+
+    let mut line = String::new();
+    BufReader::new(stream)
+        .read_line(&mut line)
+        .context("failed to read daemon response")?;
+    if line.is_empty() {
+        anyhow::bail!("daemon at {} closed the connection", socket.display());
+    }
+    let response: Response =
+        serde_json::from_str(&line).context("failed to parse daemon response")?;
+    if response.protocol != PROTOCOL_VERSION {
+        anyhow::bail!(
+            "daemon speaks protocol {}, client expects {}",
+            response.protocol,
+            PROTOCOL_VERSION
+        );
+    }
+    if !response.ok {
+        anyhow::bail!(
+            "daemon error: {}",
+            response.error.as_deref().unwrap_or("unknown error")
+        );
+    }
+    Ok(response)
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn test_request_wire_format() {
+        let req: Request =
+            serde_json::from_str(r#"{"method":"analyze_path","path":"/repo/src","top_n":5}"#)
+                .unwrap();
+        assert_eq!(
+            req,
+            Request::AnalyzePath {
+                path: PathBuf::from("/repo/src"),
+                root: None,
+                config: None,
+                min_lrs: None,
+                top_n: Some(5),
+                dedup_symlinks: false,
+                public_only: false,
+                include_tests: false,
+                no_gitignore: false,
+                halstead: false,
+                line_counts: false,
+                nd_lines: false,
+                cc_lines: false,
+                separate_closures: false,
+                ns_breakdown: false,
+                fan_in: false,
+                sql_dialect: None,
+                cc_mode: None,
+                no_fo_methods: false,
+                anon_naming: None,
+                include: Vec::new(),
+                exclude: Vec::new(),
+            }
+        );
+        assert_eq!(
+            serde_json::to_string(&Request::Shutdown).unwrap(),
+            r#"{"method":"shutdown"}"#
+        );
+    }
+
+    #[test]
+    fn test_analyze_stdin_uses_path_for_language() {
+        let response = Daemon::new().handle(Request::AnalyzeStdin {
+            path: PathBuf::from("virtual.ts"),
+            source: "function f(a: number) { if (a > 1) { return 1; } return 0; }".to_string(),
+        });
+        assert!(response.ok, "{:?}", response.error);
+        let reports = response.reports.unwrap();
+        assert_eq!(reports.len(), 1);
+        assert_eq!(reports[0].function, "f");
+        assert_eq!(reports[0].file, "virtual.ts");
+    }
+
+    #[test]
+    fn test_unsupported_language_is_error_response() {
+        let response = Daemon::new().handle(Request::AnalyzeStdin {
+            path: PathBuf::from("notes.txt"),
+            source: String::new(),
+        });
+        assert!(!response.ok);
+        assert!(response.error.unwrap().contains("Unsupported file type"));
+    }
+}
@@ -74,6 +74,11 @@ CREATE TABLE IF NOT EXISTS functions (
     driver_detail           TEXT,
     quadrant                TEXT,
     patterns                TEXT,
+    cc_breakdown            TEXT,
+    cognitive               INTEGER,
+    recursive               INTEGER,
+    cycle                   TEXT,
+    external_calls          INTEGER,
     FOREIGN KEY (commit_sha) REFERENCES commits(sha),
     UNIQUE (commit_sha, function_id)
 );
@@ -87,9 +92,35 @@ CREATE INDEX IF NOT EXISTS idx_functions_lrs    ON functions(commit_sha, lrs DES
 // Shared helpers
 // ---------------------------------------------------------------------------
 
-/// Apply the schema DDL to an open connection.
+/// `functions` columns added after the table was first released, as (name,
+/// type). `CREATE TABLE IF NOT EXISTS` leaves older databases without them.
+const ADDED_FUNCTION_COLUMNS: &[(&str, &str)] = &[
+    ("cc_breakdown", "TEXT"),
+    ("cognitive", "INTEGER"),
+    ("recursive", "INTEGER"),
+    ("cycle", "TEXT"),
+    ("external_calls", "INTEGER"),
+];
+
+/// Apply the schema DDL to an open connection, adding any columns an older
+/// database is missing.
 fn apply_schema(conn: &Connection) -> Result<()> {
-    conn.execute_batch(SCHEMA).context("failed to apply schema")
+    conn.execute_batch(SCHEMA)
+        .context("failed to apply schema")?;
+    let existing: Vec<String> = {
+        let mut stmt = conn.prepare("PRAGMA table_info(functions)")?;
+        let names = stmt.query_map([], |row| row.get::<_, String>(1))?;
+        names.collect::<rusqlite::Result<_>>()?
+    };
+    for (name, column_type) in ADDED_FUNCTION_COLUMNS {
+        if !existing.iter().any(|c| c == name) {
+            conn.execute_batch(&format!(
+                "ALTER TABLE functions ADD COLUMN {name} {column_type}"
+            ))
+            .with_context(|| format!("failed to add column functions.{name}"))?;
+        }
+    }
+    Ok(())
 }
 
 /// Insert a commit row, ignoring conflicts (idempotent).
@@ -131,7 +162,8 @@ fn insert_functions(conn: &Connection, snapshot: &Snapshot) -> Result<()> {
             scc_id, scc_size, is_entrypoint, dependency_depth, neighbor_churn,
             activity_risk, risk_factors,
             is_top_10_pct, is_top_5_pct, is_top_1_pct,
-            driver, driver_detail, quadrant, patterns
+            driver, driver_detail, quadrant, patterns, cc_breakdown, cognitive,
+            recursive, cycle, external_calls
         ) VALUES (
             ?1,?2,?3,?4,?5,
             ?6,?7,?8,?9,?10,?11,?12,?13,
@@ -141,7 +173,8 @@ fn insert_functions(conn: &Connection, snapshot: &Snapshot) -> Result<()> {
             ?22,?23,?24,?25,?26,
             ?27,?28,
             ?29,?30,?31,
-            ?32,?33,?34,?35
+            ?32,?33,?34,?35,?36,?37,
+            ?38,?39,?40
         )",
     )?;
 
@@ -151,6 +184,10 @@ fn insert_functions(conn: &Connection, snapshot: &Snapshot) -> Result<()> {
             .as_ref()
             .and_then(|rf| serde_json::to_string(rf).ok());
         let patterns_json = serde_json::to_string(&func.patterns).unwrap_or_default();
+        let cc_breakdown_json = func
+            .cc_breakdown
+            .as_ref()
+            .and_then(|b| serde_json::to_string(b).ok());
 
         let (churn_added, churn_deleted) = func
             .churn
@@ -185,6 +222,17 @@ fn insert_functions(conn: &Connection, snapshot: &Snapshot) -> Result<()> {
                 )
             })
             .unwrap_or((None, None, None, None, None, None, None, None, None));
+        let (recursive, cycle_json, external_calls) = func
+            .callgraph
+            .as_ref()
+            .map(|cg| {
+                (
+                    Some(cg.recursive as i64),
+                    serde_json::to_string(&cg.cycle).ok(),
+                    Some(cg.external_calls as i64),
+                )
+            })
+            .unwrap_or((None, None, None));
 
         let (top10, top5, top1) = func
             .percentile
@@ -234,6 +282,11 @@ fn insert_functions(conn: &Connection, snapshot: &Snapshot) -> Result<()> {
             func.driver_detail,
             func.quadrant,
             patterns_json,
+            cc_breakdown_json,
+            func.metrics.cognitive as i64,
+            recursive,
+            cycle_json,
+            external_calls,
         ])
         .context("failed to insert function row")?;
     }
@@ -254,7 +307,8 @@ fn load_functions(conn: &Connection, sha: &str) -> Result<Vec<FunctionSnapshot>>
                 scc_id, scc_size, is_entrypoint, dependency_depth, neighbor_churn,
                 activity_risk, risk_factors,
                 is_top_10_pct, is_top_5_pct, is_top_1_pct,
-                driver, driver_detail, quadrant, patterns
+                driver, driver_detail, quadrant, patterns, cc_breakdown, cognitive,
+                recursive, cycle, external_calls
          FROM functions
          WHERE commit_sha = ?1
          ORDER BY function_id",
@@ -297,6 +351,10 @@ fn load_functions(conn: &Connection, sha: &str) -> Result<Vec<FunctionSnapshot>>
         let is_entrypoint: Option<i64> = row.get(22)?;
         let dep_depth: Option<i64> = row.get(23)?;
         let nbr_churn: Option<i64> = row.get(24)?;
+        // NULL for rows written before the columns existed
+        let recursive: Option<i64> = row.get(36)?;
+        let cycle_json: Option<String> = row.get(37)?;
+        let external_calls: Option<i64> = row.get(38)?;
         let callgraph = fan_in
             .zip(fan_out)
             .zip(pagerank)
@@ -314,6 +372,12 @@ fn load_functions(conn: &Connection, sha: &str) -> Result<Vec<FunctionSnapshot>>
                 is_entrypoint: ep != 0,
                 dependency_depth: dep_depth.map(|d| d as usize),
                 neighbor_churn: nbr_churn.map(|n| n as usize),
+                recursive: recursive.is_some_and(|r| r != 0),
+                cycle: cycle_json
+                    .as_deref()
+                    .and_then(|j| serde_json::from_str(j).ok())
+                    .unwrap_or_default(),
+                external_calls: external_calls.unwrap_or(0) as usize,
             });
 
         let activity_risk: Option<f64> = row.get(25)?;
@@ -335,6 +399,9 @@ fn load_functions(conn: &Connection, sha: &str) -> Result<Vec<FunctionSnapshot>>
         let driver_detail: Option<String> = row.get(31)?;
         let quadrant: Option<String> = row.get(32)?;
         let patterns_json: Option<String> = row.get(33)?;
+        let cc_breakdown_json: Option<String> = row.get(34)?;
+        // NULL for rows written before the column existed
+        let cognitive: Option<i64> = row.get(35)?;
 
         Ok((
             function_id,
@@ -342,6 +409,7 @@ fn load_functions(conn: &Connection, sha: &str) -> Result<Vec<FunctionSnapshot>>
             line,
             language,
             cc,
+            cognitive,
             nd,
             fo,
             ns,
@@ -360,6 +428,7 @@ fn load_functions(conn: &Connection, sha: &str) -> Result<Vec<FunctionSnapshot>>
             driver_detail,
             quadrant,
             patterns_json,
+            cc_breakdown_json,
         ))
     })?;
 
@@ -371,6 +440,7 @@ fn load_functions(conn: &Connection, sha: &str) -> Result<Vec<FunctionSnapshot>>
             line,
             language,
             cc,
+            cognitive,
             nd,
             fo,
             ns,
@@ -389,6 +459,7 @@ fn load_functions(conn: &Connection, sha: &str) -> Result<Vec<FunctionSnapshot>>
             driver_detail,
             quadrant,
             patterns_json,
+            cc_breakdown_json,
         ) = row.context("failed to read function row")?;
 
         let risk_factors = risk_factors_json
@@ -400,6 +471,10 @@ fn load_functions(conn: &Connection, sha: &str) -> Result<Vec<FunctionSnapshot>>
             .and_then(|s| serde_json::from_str(s).ok())
             .unwrap_or_default();
 
+        let cc_breakdown = cc_breakdown_json
+            .as_deref()
+            .and_then(|s| serde_json::from_str(s).ok());
+
         let language = crate::language::Language::from_name(&language)
             .unwrap_or(crate::language::Language::TypeScript);
         let band = crate::risk::RiskBand::parse(&band).unwrap_or(crate::risk::RiskBand::Low);
@@ -410,10 +485,27 @@ fn load_functions(conn: &Connection, sha: &str) -> Result<Vec<FunctionSnapshot>>
             language,
             metrics: MetricsReport {
                 cc: cc as u32,
+                cognitive: cognitive.unwrap_or(0) as u32,
                 nd: nd as u32,
                 fo: fo as u32,
+                fi: 0,
                 ns: ns as u32,
                 loc: loc as u32,
+                // Not stored in the database
+                signature_complexity: 0,
+                params: 0,
+                guard_clauses: 0,
+                max_condition_ops: 0,
+                halstead: None,
+                maintainability: None,
+                sloc: None,
+                comment_lines: None,
+                blank_lines: None,
+                nd_line: None,
+                cc_lines: None,
+                is_async: false,
+                await_in_loop: 0,
+                ns_breakdown: None,
             },
             lrs,
             band,
@@ -443,6 +535,8 @@ fn load_functions(conn: &Connection, sha: &str) -> Result<Vec<FunctionSnapshot>>
             age_days: None,
             last_touch_days: None,
             explanation: None,
+            structure: None,
+            cc_breakdown,
         });
     }
 
@@ -486,8 +580,9 @@ impl TempDb {
         let mut stmt = self.conn.prepare(
             "INSERT OR REPLACE INTO functions (
                 commit_sha, function_id, file, line, language,
-                cc, nd, fo, ns, loc, lrs, band, suppression_reason, callees
-            ) VALUES (?1,?2,?3,?4,?5,?6,?7,?8,?9,?10,?11,?12,?13,?14)",
+                cc, nd, fo, ns, loc, lrs, band, suppression_reason, callees, cc_breakdown,
+                cognitive
+            ) VALUES (?1,?2,?3,?4,?5,?6,?7,?8,?9,?10,?11,?12,?13,?14,?15,?16)",
         )?;
 
         for report in reports {
@@ -500,6 +595,10 @@ impl TempDb {
             let function_id = format!("{}::{}", normalized_file, function_symbol);
             let callees_json =
                 serde_json::to_string(&report.callees).unwrap_or_else(|_| "[]".to_string());
+            let cc_breakdown_json = report
+                .cc_breakdown
+                .as_ref()
+                .and_then(|b| serde_json::to_string(b).ok());
             stmt.execute(params![
                 sha,
                 function_id,
@@ -515,6 +614,8 @@ impl TempDb {
                 report.band.as_str(),
                 report.suppression_reason,
                 callees_json,
+                cc_breakdown_json,
+                report.metrics.cognitive as i64,
             ])
             .context("failed to insert report row")?;
         }
@@ -604,6 +705,7 @@ impl TempDb {
             graph.betweenness_centrality()
         };
         let scc_info = graph.find_strongly_connected_components();
+        let cycles = graph.find_cycles();
         let depths = graph.compute_dependency_depth();
         let fan_in_map = graph.build_fan_in_map();
 
@@ -628,8 +730,9 @@ impl TempDb {
             "UPDATE functions
              SET fan_in = ?1, fan_out = ?2, pagerank = ?3, betweenness = ?4,
                  scc_id = ?5, scc_size = ?6, is_entrypoint = ?7,
-                 dependency_depth = ?8, neighbor_churn = ?9
-             WHERE commit_sha = ?10 AND function_id = ?11",
+                 dependency_depth = ?8, neighbor_churn = ?9,
+                 recursive = ?10, cycle = ?11, external_calls = ?12
+             WHERE commit_sha = ?13 AND function_id = ?14",
         )?;
 
         // Iterate over all graph nodes (not just rows) so we only UPDATE functions
@@ -641,6 +744,7 @@ impl TempDb {
                 .callees_of(function_id)
                 .map(|callees| callees.filter_map(|c| churn_map.get(c)).sum::<usize>())
                 .filter(|&v| v > 0);
+            let cycle = cycles.get(function_id);
 
             stmt.execute(params![
                 fan_in_map.get(function_id).copied().unwrap_or(0) as i64,
@@ -652,6 +756,9 @@ impl TempDb {
                 graph.is_entry_point(function_id) as i64,
                 dep_depth.map(|d| d as i64),
                 neighbor_churn.map(|n| n as i64),
+                cycle.is_some() as i64,
+                serde_json::to_string(cycle.map_or(&[][..], Vec::as_slice))?,
+                graph.external_calls(function_id) as i64,
                 sha,
                 function_id,
             ])
@@ -856,6 +963,9 @@ impl SnapshotDb {
             functions,
             summary: None,
             aggregates: None,
+            truncated: None,
+            total_functions: None,
+            top_level_decisions: Default::default(),
         }))
     }
 
@@ -915,6 +1025,7 @@ mod tests {
         let reports = vec![FunctionRiskReport {
             file: "src/foo.ts".to_string(),
             function: "handler".to_string(),
+            owner: None,
             line: 10,
             language: crate::language::Language::TypeScript,
             metrics: ReportMetrics {
@@ -923,6 +1034,14 @@ mod tests {
                 fo: 2,
                 ns: 0,
                 loc: 20,
+                params: 0,
+                halstead: None,
+                maintainability: None,
+                sloc: None,
+                comment_lines: None,
+                blank_lines: None,
+                nd_line: None,
+                cc_lines: None,
             },
             risk: RiskReport {
                 r_cc: 1.0,
@@ -932,11 +1051,18 @@ mod tests {
             },
             lrs: 2.0,
             band: crate::risk::RiskBand::Low,
+            risk_score: None,
             callees: vec![],
             suppression_reason: None,
             patterns: vec![],
             pattern_details: None,
             explanation: None,
+            arrow_depth: 0,
+            aliases: vec![],
+            structure: None,
+            cc_breakdown: None,
+            is_public: false,
+            file_top_level_decisions: 0,
         }];
         Snapshot::new(ctx, reports)
     }
@@ -1006,6 +1132,35 @@ mod tests {
         assert_eq!(loaded.functions.len(), 1, "duplicate rows on re-insert");
     }
 
+    #[test]
+    fn test_snapshot_db_adds_columns_to_old_schema() {
+        use crate::metrics::{CcBreakdown, DecisionKind};
+
+        // A database written before `cc_breakdown` and `cognitive` existed
+        let dir = tempfile::tempdir().unwrap();
+        let path = dir.path().join("s.db");
+        let old_schema = SCHEMA
+            .replace("    cc_breakdown            TEXT,\n", "")
+            .replace("    cognitive               INTEGER,\n", "");
+        Connection::open(&path)
+            .unwrap()
+            .execute_batch(&old_schema)
+            .unwrap();
+
+        let db = SnapshotDb::open(&path).unwrap();
+        let mut snapshot = make_snapshot();
+        let mut breakdown = CcBreakdown::default();
+        breakdown.add(DecisionKind::If, 2);
+        breakdown.add(DecisionKind::And, 1);
+        snapshot.functions[0].cc_breakdown = Some(breakdown.clone());
+        snapshot.functions[0].metrics.cognitive = 4;
+        db.insert(&snapshot).unwrap();
+
+        let loaded = db.load("deadbeef").unwrap().expect("should exist");
+        assert_eq!(loaded.functions[0].cc_breakdown, Some(breakdown));
+        assert_eq!(loaded.functions[0].metrics.cognitive, 4);
+    }
+
     #[test]
     fn test_snapshot_db_multi_commit_ordering() {
         // Two commits at different timestamps — all_shas must return oldest first.
@@ -1049,6 +1204,7 @@ mod tests {
         let report = FunctionRiskReport {
             file: "src/svc.ts".to_string(),
             function: "processRequest".to_string(),
+            owner: None,
             line: 42,
             language: crate::language::Language::TypeScript,
             metrics: ReportMetrics {
@@ -1057,6 +1213,14 @@ mod tests {
                 fo: 5,
                 ns: 2,
                 loc: 100,
+                params: 0,
+                halstead: None,
+                maintainability: None,
+                sloc: None,
+                comment_lines: None,
+                blank_lines: None,
+                nd_line: None,
+                cc_lines: None,
             },
             risk: RiskReport {
                 r_cc: 2.0,
@@ -1066,11 +1230,18 @@ mod tests {
             },
             lrs: 8.0,
             band: crate::risk::RiskBand::High,
+            risk_score: None,
             callees: vec!["doA".to_string(), "doB".to_string()],
             suppression_reason: None,
             patterns: vec!["complex_branching".to_string()],
             pattern_details: None,
             explanation: None,
+            arrow_depth: 0,
+            aliases: vec![],
+            structure: None,
+            cc_breakdown: None,
+            is_public: false,
+            file_top_level_decisions: 0,
         };
         let mut snapshot = Snapshot::new(ctx, vec![report]);
 
@@ -1093,6 +1264,9 @@ mod tests {
             is_entrypoint: true,
             dependency_depth: Some(2),
             neighbor_churn: Some(12),
+            recursive: true,
+            cycle: vec!["src/a.ts::doA".to_string(), "src/a.ts::main".to_string()],
+            external_calls: 3,
         });
         f.activity_risk = Some(9.5);
         f.risk_factors = Some(RiskFactors {
@@ -1136,6 +1310,9 @@ mod tests {
         assert!(cg.is_entrypoint);
         assert_eq!(cg.dependency_depth, Some(2));
         assert_eq!(cg.neighbor_churn, Some(12));
+        assert!(cg.recursive);
+        assert_eq!(cg.cycle, ["src/a.ts::doA", "src/a.ts::main"]);
+        assert_eq!(cg.external_calls, 3);
 
         assert!((lf.activity_risk.unwrap() - 9.5).abs() < 1e-9);
 
@@ -1186,6 +1363,7 @@ mod tests {
             .map(|i| FunctionRiskReport {
                 file: format!("src/f{i}.ts"),
                 function: format!("fn{i}"),
+                owner: None,
                 line: i,
                 language: crate::language::Language::TypeScript,
                 metrics: ReportMetrics {
@@ -1194,6 +1372,14 @@ mod tests {
                     fo: 0,
                     ns: 0,
                     loc: 10,
+                    params: 0,
+                    halstead: None,
+                    maintainability: None,
+                    sloc: None,
+                    comment_lines: None,
+                    blank_lines: None,
+                    nd_line: None,
+                    cc_lines: None,
                 },
                 risk: RiskReport {
                     r_cc: i as f64,
@@ -1203,11 +1389,18 @@ mod tests {
                 },
                 lrs: i as f64,
                 band: crate::risk::RiskBand::Low,
+                risk_score: None,
                 callees: vec![],
                 suppression_reason: None,
                 patterns: vec![],
                 pattern_details: None,
                 explanation: None,
+                arrow_depth: 0,
+                aliases: vec![],
+                structure: None,
+                cc_breakdown: None,
+                is_public: false,
+                file_top_level_decisions: 0,
             })
             .collect();
 
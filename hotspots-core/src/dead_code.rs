@@ -0,0 +1,268 @@
+//! Dead-code candidates (`analyze --dead-code`)
+//!
+//! A function is a candidate when no analyzed function calls it, it is not
+//! part of its file's public API (the per-language rules `--public-only`
+//! uses), and its name matches no entry-point pattern ([`DEFAULT_ENTRY_POINTS`]
+//! plus the `entry_points` config key).
+//!
+//! Calls are matched by name, on the last segment of qualified names
+//! (`s.helper`, `Type::helper`, `$this->helper`), so a call to any function
+//! of the same name counts as a caller. Calls from outside function bodies
+//! (module-level code) are not seen, and functions reached only through
+//! reflection, interface dispatch under another name, or as values (callbacks,
+//! registered handlers) have no call by name: those are listed anyway, so
+//! candidates need review before deletion. Anonymous and suppressed functions,
+//! and Go closures discovered with `--separate-closures` (`Outer.go`,
+//! `Outer.func1`), are never listed.
+//!
+//! Global invariants enforced:
+//! - Deterministic output ordering (file, then line)
+
+use crate::language::Language;
+use crate::report::FunctionRiskReport;
+use anyhow::{Context, Result};
+use globset::GlobSet;
+use serde::Serialize;
+use std::collections::{HashMap, HashSet};
+use std::path::Path;
+
+/// Function-name patterns always treated as entry points: program and
+/// package initialization, and test, benchmark, example, and fuzz functions
+pub const DEFAULT_ENTRY_POINTS: &[&str] = &[
+    "main",
+    "init",
+    "test*",
+    "Test*",
+    "Benchmark*",
+    "Example*",
+    "Fuzz*",
+];
+
+/// A function with no callers that is neither public nor an entry point
+#[derive(Debug, Clone, Serialize, PartialEq)]
+#[serde(rename_all = "snake_case")]
+pub struct DeadFunction {
+    /// Path relative to the analysis base
+    pub file: String,
+    pub function: String,
+    pub line: u32,
+    pub loc: u32,
+}
+
+/// The dead-code candidates among `reports` (every analyzed function,
+/// unfiltered), sorted by file and line. Paths are relative to `base` when
+/// they fall under it.
+pub fn find_dead_code(
+    reports: &[FunctionRiskReport],
+    entry_points: &GlobSet,
+    base: &Path,
+) -> Vec<DeadFunction> {
+    let mut defined: HashMap<&str, usize> = HashMap::new();
+    for r in reports {
+        *defined.entry(last_segment(&r.function)).or_default() += 1;
+    }
+    let mut called: HashSet<&str> = HashSet::new();
+    for r in reports {
+        let own = last_segment(&r.function);
+        for callee in &r.callees {
+            let name = last_segment(callee);
+            // The only function of its name calling that name is recursion
+            if name == own && defined.get(own) == Some(&1) {
+                continue;
+            }
+            called.insert(name);
+        }
+    }
+
+    let go_functions: HashSet<(&str, &str)> = reports
+        .iter()
+        .filter(|r| r.language == Language::Go)
+        .map(|r| (r.file.as_str(), r.function.as_str()))
+        .collect();
+
+    let mut dead: Vec<DeadFunction> = reports
+        .iter()
+        .filter(|r| {
+            !r.is_public
+                && r.suppression_reason.is_none()
+                && !r.function.starts_with("<anonymous>")
+                && !is_go_closure(r, &go_functions)
+                && !called.contains(last_segment(&r.function))
+                && !entry_points.is_match(&r.function)
+                && !entry_points.is_match(last_segment(&r.function))
+        })
+        .map(|r| DeadFunction {
+            file: crate::treemap::relative_path(&r.file, base),
+            function: r.function.clone(),
+            line: r.line,
+            loc: r.metrics.loc,
+        })
+        .collect();
+    dead.sort_by(|a, b| (&a.file, a.line).cmp(&(&b.file, b.line)));
+    dead
+}
+
+/// A closure discovered by `--separate-closures`: whatever `--anon-naming`
+/// picks, it is named `parent.segment` after a function in the same file
+/// (`Serve.go`, `Serve.func1.1`), where a method's receiver is a type
+/// (`Server.Stop`)
+fn is_go_closure(r: &FunctionRiskReport, go_functions: &HashSet<(&str, &str)>) -> bool {
+    r.language == Language::Go
+        && r.function
+            .rsplit_once('.')
+            .is_some_and(|(parent, _)| go_functions.contains(&(r.file.as_str(), parent)))
+}
+
+/// Last segment of a qualified name: `helper` for `s.helper`, `Type::helper`,
+/// or `$this->helper`
+fn last_segment(name: &str) -> &str {
+    name.rsplit(['.', ':', '>']).next().unwrap_or(name)
+}
+
+/// Render candidates as a JSON array
+pub fn render_dead_code_json(dead: &[DeadFunction]) -> Result<String> {
+    serde_json::to_string_pretty(dead).context("failed to serialize dead-code candidates")
+}
+
+/// Render candidates as text: one `path:line  name  (N lines)` row each,
+/// then the by-name caveat.
+pub fn render_dead_code_text(dead: &[DeadFunction]) -> String {
+    if dead.is_empty() {
+        return "No dead-code candidates: every private function has a caller or is an entry point.\n"
+            .to_string();
+    }
+    let mut out = format!(
+        "{} dead-code candidate(s): no callers among the analyzed functions, not public, not an entry point\n\n",
+        dead.len()
+    );
+    let locations: Vec<String> = dead
+        .iter()
+        .map(|d| format!("{}:{}", d.file, d.line))
+        .collect();
+    let width = locations.iter().map(String::len).max().unwrap_or(0);
+    for (d, location) in dead.iter().zip(&locations) {
+        out.push_str(&format!(
+            "  {location:<width$}  {}  ({} lines)\n",
+            d.function, d.loc
+        ));
+    }
+    out.push_str(
+        "\nCalls are matched by name. Functions reached only through reflection, interface dispatch, or as callbacks are listed too; review before deleting.\n",
+    );
+    out
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::report::{MetricsReport, RiskReport};
+    use crate::risk::RiskBand;
+    use globset::{Glob, GlobSetBuilder};
+
+    fn make_report(function: &str, is_public: bool, callees: &[&str]) -> FunctionRiskReport {
+        FunctionRiskReport {
+            file: "/repo/src/a.rs".to_string(),
+            function: function.to_string(),
+            owner: None,
+            line: 1,
+            language: Language::Rust,
+            metrics: MetricsReport {
+                cc: 1,
+                cognitive: 0,
+                nd: 0,
+                fo: callees.len() as u32,
+                fi: 0,
+                ns: 0,
+                loc: 3,
+                signature_complexity: 0,
+                params: 0,
+                guard_clauses: 0,
+                max_condition_ops: 0,
+                halstead: None,
+                maintainability: None,
+                sloc: None,
+                comment_lines: None,
+                blank_lines: None,
+                nd_line: None,
+                cc_lines: None,
+                is_async: false,
+                await_in_loop: 0,
+                ns_breakdown: None,
+            },
+            risk: RiskReport {
+                r_cc: 1.0,
+                r_nd: 0.0,
+                r_fo: 0.0,
+                r_ns: 0.0,
+            },
+            lrs: 1.0,
+            band: RiskBand::Low,
+            risk_score: None,
+            suppression_reason: None,
+            patterns: vec![],
+            pattern_details: None,
+            callees: callees.iter().map(|c| c.to_string()).collect(),
+            explanation: None,
+            arrow_depth: 0,
+            aliases: vec![],
+            structure: None,
+            cc_breakdown: None,
+            is_public,
+            file_top_level_decisions: 0,
+        }
+    }
+
+    fn entry_points(patterns: &[&str]) -> GlobSet {
+        let mut builder = GlobSetBuilder::new();
+        for pattern in patterns {
+            builder.add(Glob::new(pattern).unwrap());
+        }
+        builder.build().unwrap()
+    }
+
+    #[test]
+    fn test_candidates_exclude_called_public_and_entry_points() {
+        let reports = vec![
+            make_report("main", false, &["Parser::parse"]),
+            make_report("Parser::parse", false, &["self.tokenize"]),
+            make_report("tokenize", false, &[]),
+            make_report("Api::handle", true, &[]),
+            make_report("fallback", false, &[]),
+            make_report("countdown", false, &["countdown"]),
+            make_report("setup_fixture", false, &[]),
+        ];
+        let dead = find_dead_code(
+            &reports,
+            &entry_points(&["main", "setup_*"]),
+            Path::new("/repo"),
+        );
+        let names: Vec<&str> = dead.iter().map(|d| d.function.as_str()).collect();
+        // Recursion alone does not keep `countdown` alive
+        assert_eq!(names, ["fallback", "countdown"]);
+        assert_eq!(dead[0].file, "src/a.rs");
+
+        let text = render_dead_code_text(&dead);
+        assert!(text.starts_with("2 dead-code candidate(s)"));
+        assert!(text.contains("  src/a.rs:1  fallback  (3 lines)\n"));
+        assert!(render_dead_code_text(&[]).starts_with("No dead-code candidates"));
+    }
+
+    #[test]
+    fn test_go_closures_are_not_candidates() {
+        let go_report = |function: &str, is_public: bool| FunctionRiskReport {
+            file: "/repo/src/a.go".to_string(),
+            language: Language::Go,
+            ..make_report(function, is_public, &[])
+        };
+        let reports = vec![
+            go_report("Serve", true),
+            go_report("Serve.go", false),
+            go_report("Serve.func1", false),
+            go_report("Serve.go.check", false),
+            go_report("Server.stop", false),
+        ];
+        let dead = find_dead_code(&reports, &entry_points(&[]), Path::new("/repo"));
+        let names: Vec<&str> = dead.iter().map(|d| d.function.as_str()).collect();
+        assert_eq!(names, ["Server.stop"]);
+    }
+}
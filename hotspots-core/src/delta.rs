@@ -8,18 +8,27 @@
 //! - Function matching by function_id (file moves are delete + add)
 //! - Status based on metrics/LRS/band changes, not file/line movements
 
+use crate::config::ExemptFunction;
+use crate::metrics::CcBreakdown;
 use crate::policy::PolicyResults;
-use crate::report::MetricsReport;
+use crate::report::{FunctionRiskReport, MetricsReport, Versioned, WithToolVersion};
 use crate::risk::RiskBand;
-use crate::snapshot::{FunctionSnapshot, Snapshot};
+use crate::snapshot::{FunctionSnapshot, RepoPaths, Snapshot};
 use anyhow::{Context, Result};
 use serde::{Deserialize, Serialize};
-use std::collections::HashMap;
+use std::collections::{HashMap, HashSet};
 use std::path::Path;
 
 /// Schema version for deltas
 const DELTA_SCHEMA_VERSION: u32 = 1;
 
+/// Schema version for report diffs (`--diff-against`)
+const REPORT_DIFF_SCHEMA_VERSION: u32 = 1;
+
+/// LRS increase at which a modified function counts as regressed even without
+/// a band change (also the `excessive-risk-regression` policy threshold)
+pub const REGRESSION_LRS_THRESHOLD: f64 = 1.0;
+
 /// Function change status
 #[derive(Debug, Clone, Serialize, Deserialize, PartialEq, Eq)]
 #[serde(rename_all = "lowercase")]
@@ -37,6 +46,9 @@ pub struct FunctionState {
     pub metrics: MetricsReport,
     pub lrs: f64,
     pub band: RiskBand,
+    /// CC breakdown from the snapshot, for `--explain-diff`. Not serialized.
+    #[serde(skip)]
+    pub cc_breakdown: Option<CcBreakdown>,
 }
 
 /// Numeric delta for a function
@@ -78,6 +90,14 @@ pub struct FunctionDeltaEntry {
     /// Set by second-pass heuristic; absent when exact match was found or no match possible.
     #[serde(skip_serializing_if = "Option::is_none")]
     pub rename_hint: Option<String>,
+    /// Set when the function is on the config `exempt` list (empty string
+    /// if no reason was given). Exempt functions are reported but never gate.
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub exempt_reason: Option<String>,
+    /// What changed in the CC breakdown ("+1 if, +1 &&, +2 switch cases").
+    /// Set by `--explain-diff` on regressed functions only.
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub explanation: Option<String>,
 }
 
 /// Commit info in delta
@@ -123,16 +143,12 @@ impl Delta {
             return Ok(build_baseline_delta(current, parent_sha));
         }
         let parent_snap = parent.unwrap();
-        let parent_funcs: HashMap<&str, &FunctionSnapshot> = parent_snap
-            .functions
-            .iter()
-            .map(|f| (f.function_id.as_str(), f))
-            .collect();
         let current_funcs: HashMap<&str, &FunctionSnapshot> = current
             .functions
             .iter()
             .map(|f| (f.function_id.as_str(), f))
             .collect();
+        let parent_funcs = match_parent_functions(&parent_snap.functions, &current_funcs);
         // Collect all function_ids (union of parent and current), sorted deterministically
         let mut all_ids: Vec<&str> = parent_funcs
             .keys()
@@ -157,17 +173,21 @@ impl Delta {
         })
     }
 
-    /// Serialize delta to JSON string (deterministic ordering)
-    pub fn to_json(&self) -> Result<String> {
-        serde_json::to_string_pretty(self).context("failed to serialize delta to JSON")
+    /// Serialize delta to JSON string (deterministic ordering), with
+    /// `tool_version` after its fields
+    pub fn to_json(&self, tool_version: &str) -> Result<String> {
+        serde_json::to_string_pretty(&WithToolVersion::new(self, tool_version))
+            .context("failed to serialize delta to JSON")
     }
 
-    /// Serialize delta entries as newline-delimited JSON (one entry per line).
-    pub fn to_jsonl(&self) -> Result<String> {
+    /// Serialize delta entries as newline-delimited JSON (one entry per line),
+    /// each with the delta's `schema_version` and `tool_version`.
+    pub fn to_jsonl(&self, tool_version: &str) -> Result<String> {
         let mut lines = Vec::with_capacity(self.deltas.len());
         for entry in &self.deltas {
             lines.push(
-                serde_json::to_string(entry).context("failed to serialize delta entry to JSON")?,
+                serde_json::to_string(&Versioned::new(self.schema_version, tool_version, entry))
+                    .context("failed to serialize delta entry to JSON")?,
             );
         }
         Ok(lines.join("\n"))
@@ -189,6 +209,171 @@ impl Delta {
 
         Ok(delta)
     }
+
+    /// Mark entries on the config `exempt` list (see [`FunctionDeltaEntry::exempt_reason`]).
+    ///
+    /// Exempt ids are portable (repo-relative); entry ids are matched in
+    /// portable form against `repo_root`. A Go method also matches its
+    /// legacy unqualified id (`store.go::Get` for `store.go::Store.Get`),
+    /// as in [`compare_functions`].
+    pub fn apply_exemptions(&mut self, exempt: &[ExemptFunction], repo_root: &Path) {
+        if exempt.is_empty() {
+            return;
+        }
+        let paths = RepoPaths::new(repo_root);
+        let reasons: HashMap<&str, &str> = exempt
+            .iter()
+            .map(|e| (e.function_id.as_str(), e.reason.as_deref().unwrap_or("")))
+            .collect();
+        let ids: Vec<String> = self
+            .deltas
+            .iter()
+            .map(|entry| paths.portable_id(&entry.function_id))
+            .collect();
+        let legacy_ids: HashMap<&str, String> =
+            go_methods_by_legacy_id(&ids.iter().map(String::as_str).collect())
+                .into_iter()
+                .map(|(legacy_id, id)| (id, legacy_id))
+                .collect();
+        for (entry, id) in self.deltas.iter_mut().zip(&ids) {
+            let reason = reasons.get(id.as_str()).or_else(|| {
+                let legacy_id = legacy_ids.get(id.as_str())?;
+                reasons.get(legacy_id.as_str())
+            });
+            if let Some(reason) = reason {
+                entry.exempt_reason = Some(reason.to_string());
+            }
+        }
+    }
+
+    /// Functions that got worse than the parent, for `--regressions-only`.
+    ///
+    /// A modified function regresses when its band worsens or its LRS rises by
+    /// at least [`REGRESSION_LRS_THRESHOLD`]; a new function regresses when it
+    /// lands in the high or critical band. Suppressed and exempt functions never
+    /// regress, and a baseline delta (nothing to compare against) has no regressions.
+    /// Ordered by LRS increase (a new function's full LRS), largest first, then
+    /// by function_id.
+    pub fn regressions(&self) -> Vec<&FunctionDeltaEntry> {
+        if self.baseline {
+            return Vec::new();
+        }
+        sorted_regressions(self.deltas.iter())
+    }
+
+    /// Explain each regression's CC change, for `--explain-diff`.
+    ///
+    /// Sets `explanation` on the functions [`Delta::regressions`] returns (see
+    /// [`explain_cc_change`]).
+    pub fn explain_regressions(&mut self) {
+        if self.baseline {
+            return;
+        }
+        for entry in self.deltas.iter_mut().filter(|e| is_regression(e)) {
+            entry.explanation = explain_cc_change(entry);
+        }
+    }
+}
+
+/// Whether `entry` got worse than its parent (see [`Delta::regressions`])
+fn is_regression(entry: &FunctionDeltaEntry) -> bool {
+    if entry.suppression_reason.is_some() || entry.exempt_reason.is_some() {
+        return false;
+    }
+    match (&entry.status, &entry.before, &entry.after) {
+        (FunctionStatus::Modified, Some(before), Some(after)) => {
+            after.band > before.band || after.lrs - before.lrs >= REGRESSION_LRS_THRESHOLD
+        }
+        (FunctionStatus::New, None, Some(after)) => after.band >= RiskBand::High,
+        _ => false,
+    }
+}
+
+/// Describe how the CC breakdown changed between `before` and `after`
+/// ("+1 if, +1 &&"). A new function is compared against an empty breakdown.
+///
+/// None when either side has no breakdown (snapshots written before it was
+/// recorded, SQL) or no decision point changed.
+fn explain_cc_change(entry: &FunctionDeltaEntry) -> Option<String> {
+    let after = entry.after.as_ref()?.cc_breakdown.as_ref()?;
+    let empty = CcBreakdown::default();
+    let before = match &entry.before {
+        Some(state) => state.cc_breakdown.as_ref()?,
+        None => &empty,
+    };
+    after.describe_change(before)
+}
+
+/// The regressions among `entries`, largest LRS increase first
+fn sorted_regressions<'a>(
+    entries: impl Iterator<Item = &'a FunctionDeltaEntry>,
+) -> Vec<&'a FunctionDeltaEntry> {
+    let mut regressed: Vec<&FunctionDeltaEntry> = entries.filter(|e| is_regression(e)).collect();
+    regressed.sort_by(|a, b| {
+        regression_size(b)
+            .total_cmp(&regression_size(a))
+            .then_with(|| a.function_id.cmp(&b.function_id))
+    });
+    regressed
+}
+
+fn regression_size(entry: &FunctionDeltaEntry) -> f64 {
+    let after = entry.after.as_ref().map_or(0.0, |s| s.lrs);
+    let before = entry.before.as_ref().map_or(0.0, |s| s.lrs);
+    after - before
+}
+
+/// Render regressions as a pretty-printed JSON array of delta entries
+pub fn render_regressions_json(regressions: &[&FunctionDeltaEntry]) -> Result<String> {
+    serde_json::to_string_pretty(regressions).context("failed to serialize regressions to JSON")
+}
+
+/// Render regressions as a Markdown list ready to paste into a PR comment.
+///
+/// Returns an empty string when there are none, so a clean run prints nothing.
+pub fn render_regressions_text(regressions: &[&FunctionDeltaEntry]) -> String {
+    if regressions.is_empty() {
+        return String::new();
+    }
+    let noun = if regressions.len() == 1 {
+        "function"
+    } else {
+        "functions"
+    };
+    let mut out = format!("**Hotspots: {} regressed {}**\n\n", regressions.len(), noun);
+    for entry in regressions {
+        let mut line = match (&entry.before, &entry.after) {
+            (Some(before), Some(after)) => {
+                let band = if after.band != before.band {
+                    format!("{} → {}", before.band.as_str(), after.band.as_str())
+                } else {
+                    after.band.as_str().to_string()
+                };
+                format!(
+                    "- `{}` {}, LRS {:.2} → {:.2} ({:+.2})",
+                    entry.function_id,
+                    band,
+                    before.lrs,
+                    after.lrs,
+                    after.lrs - before.lrs
+                )
+            }
+            (None, Some(after)) => format!(
+                "- `{}` new, {}, LRS {:.2}",
+                entry.function_id,
+                after.band.as_str(),
+                after.lrs
+            ),
+            _ => continue,
+        };
+        if let Some(explanation) = &entry.explanation {
+            line.push_str(" — ");
+            line.push_str(explanation);
+        }
+        out.push_str(&line);
+        out.push('\n');
+    }
+    out
 }
 
 fn validate_snapshot_versions(current: &Snapshot, parent: Option<&Snapshot>) -> Result<()> {
@@ -223,11 +408,14 @@ fn build_baseline_delta(current: &Snapshot, parent_sha: String) -> Delta {
                 metrics: func.metrics.clone(),
                 lrs: func.lrs,
                 band: func.band,
+                cc_breakdown: func.cc_breakdown.clone(),
             }),
             delta: None,
             band_transition: None,
             suppression_reason: func.suppression_reason.clone(),
             rename_hint: None,
+            exempt_reason: None,
+            explanation: None,
         })
         .collect();
     Delta {
@@ -243,6 +431,65 @@ fn build_baseline_delta(current: &Snapshot, parent_sha: String) -> Delta {
     }
 }
 
+/// Key the parent's functions by the id of the current function each one
+/// matches: the same id, or for a Go method recorded under its legacy id
+/// the method it became (see [`go_methods_by_legacy_id`]). Parent functions
+/// that match nothing keep their own id.
+fn match_parent_functions<'a>(
+    parent: &'a [FunctionSnapshot],
+    current_funcs: &HashMap<&'a str, &'a FunctionSnapshot>,
+) -> HashMap<&'a str, &'a FunctionSnapshot> {
+    let mut parent_funcs: HashMap<&str, &FunctionSnapshot> =
+        parent.iter().map(|f| (f.function_id.as_str(), f)).collect();
+    let current_ids: HashSet<&str> = current_funcs.keys().copied().collect();
+    for (legacy_id, id) in go_methods_by_legacy_id(&current_ids) {
+        if parent_funcs.contains_key(id) {
+            continue;
+        }
+        if let Some(func) = parent_funcs.remove(legacy_id.as_str()) {
+            parent_funcs.insert(id, func);
+        }
+    }
+    parent_funcs
+}
+
+/// The Go methods among `ids` by the id each had before methods were named
+/// after their receiver type (`store.go::Get` for `store.go::Store.Get`),
+/// for legacy ids that belong to exactly one method and to no function in
+/// `ids`
+fn go_methods_by_legacy_id<'a>(ids: &HashSet<&'a str>) -> HashMap<String, &'a str> {
+    let mut methods: HashMap<String, Option<&str>> = HashMap::new();
+    for &id in ids {
+        if let Some(legacy_id) = legacy_go_method_id(id, ids) {
+            methods
+                .entry(legacy_id)
+                .and_modify(|method| *method = None)
+                .or_insert(Some(id));
+        }
+    }
+    methods
+        .into_iter()
+        .filter(|(legacy_id, _)| !ids.contains(legacy_id.as_str()))
+        .filter_map(|(legacy_id, method)| Some((legacy_id, method?)))
+        .collect()
+}
+
+/// The legacy id of a Go method id, or None for any other id. Closures
+/// (`Run.func1`, `Run.check`, `Store.Get.func1`) are told apart by their
+/// parent function being among `ids`, since a Go type and a function
+/// cannot share a name.
+fn legacy_go_method_id(function_id: &str, ids: &HashSet<&str>) -> Option<String> {
+    let (file, name) = function_id.split_once("::")?;
+    if !file.ends_with(".go") {
+        return None;
+    }
+    let (receiver, method) = name.split_once('.')?;
+    if method.contains('.') || ids.contains(format!("{file}::{receiver}").as_str()) {
+        return None;
+    }
+    Some(format!("{file}::{method}"))
+}
+
 fn compute_function_deltas(
     all_ids: &[&str],
     parent_funcs: &HashMap<&str, &FunctionSnapshot>,
@@ -279,16 +526,20 @@ fn compute_function_deltas(
                         metrics: parent.metrics.clone(),
                         lrs: parent.lrs,
                         band: parent.band,
+                        cc_breakdown: parent.cc_breakdown.clone(),
                     }),
                     after: Some(FunctionState {
                         metrics: current.metrics.clone(),
                         lrs: current.lrs,
                         band: current.band,
+                        cc_breakdown: current.cc_breakdown.clone(),
                     }),
                     delta,
                     band_transition,
                     suppression_reason: current.suppression_reason.clone(),
                     rename_hint: None,
+                    exempt_reason: None,
+                    explanation: None,
                 });
             }
             (Some(parent), None) => {
@@ -299,12 +550,15 @@ fn compute_function_deltas(
                         metrics: parent.metrics.clone(),
                         lrs: parent.lrs,
                         band: parent.band,
+                        cc_breakdown: parent.cc_breakdown.clone(),
                     }),
                     after: None,
                     delta: Some(compute_delete_delta(parent)),
                     band_transition: None,
                     suppression_reason: parent.suppression_reason.clone(),
                     rename_hint: None,
+                    exempt_reason: None,
+                    explanation: None,
                 });
             }
             (None, Some(current)) => {
@@ -316,11 +570,14 @@ fn compute_function_deltas(
                         metrics: current.metrics.clone(),
                         lrs: current.lrs,
                         band: current.band,
+                        cc_breakdown: current.cc_breakdown.clone(),
                     }),
                     delta: None,
                     band_transition: None,
                     suppression_reason: current.suppression_reason.clone(),
                     rename_hint: None,
+                    exempt_reason: None,
+                    explanation: None,
                 });
             }
             (None, None) => {
@@ -499,11 +756,127 @@ pub fn compute_delta(repo_root: &Path, current: &Snapshot) -> Result<Delta> {
     Delta::new(current, parent.as_ref())
 }
 
+/// Minimal diff between a previous results file and the current analysis
+///
+/// Used by `hotspots analyze --diff-against <prev.json>` for consumers that poll
+/// and only want what changed. Functions are matched by `function_id` exactly as
+/// in snapshot deltas, including the rename/move heuristic (`rename_hint` on
+/// removed entries). Unchanged functions are counted but not listed.
+#[derive(Debug, Clone, Serialize, Deserialize, PartialEq)]
+#[serde(rename_all = "snake_case")]
+pub struct ReportDiff {
+    pub schema_version: u32,
+    pub added: Vec<FunctionDeltaEntry>,
+    pub removed: Vec<FunctionDeltaEntry>,
+    pub changed: Vec<FunctionDeltaEntry>,
+    pub unchanged_count: usize,
+}
+
+impl ReportDiff {
+    /// Diff two function lists (previous → current)
+    ///
+    /// Entries within each list are sorted by `function_id`.
+    pub fn new(previous: &[FunctionSnapshot], current: &[FunctionSnapshot]) -> Self {
+        let deltas = compare_functions(previous, current);
+        let mut diff = ReportDiff {
+            schema_version: REPORT_DIFF_SCHEMA_VERSION,
+            added: Vec::new(),
+            removed: Vec::new(),
+            changed: Vec::new(),
+            unchanged_count: 0,
+        };
+        for entry in deltas {
+            match entry.status {
+                FunctionStatus::New => diff.added.push(entry),
+                FunctionStatus::Deleted => diff.removed.push(entry),
+                FunctionStatus::Modified => diff.changed.push(entry),
+                FunctionStatus::Unchanged => diff.unchanged_count += 1,
+            }
+        }
+        diff
+    }
+
+    /// Functions that regressed against the previous results, for `--baseline`
+    ///
+    /// Same rule and order as [`Delta::regressions`]: a changed function
+    /// regresses when its band worsens or its LRS rises by at least
+    /// [`REGRESSION_LRS_THRESHOLD`], an added one when it lands in the high or
+    /// critical band. Removed functions never regress.
+    pub fn regressions(&self) -> Vec<&FunctionDeltaEntry> {
+        sorted_regressions(self.added.iter().chain(&self.changed))
+    }
+
+    /// True when nothing was added, removed, or changed
+    pub fn is_empty(&self) -> bool {
+        self.added.is_empty() && self.removed.is_empty() && self.changed.is_empty()
+    }
+
+    /// Serialize diff to JSON string (deterministic ordering), with
+    /// `tool_version` after its fields
+    pub fn to_json(&self, tool_version: &str) -> Result<String> {
+        serde_json::to_string_pretty(&WithToolVersion::new(self, tool_version))
+            .context("failed to serialize report diff to JSON")
+    }
+}
+
+/// Every function of two function lists (previous → current), one entry
+/// each, unchanged ones included
+///
+/// Functions are matched by `function_id` as in [`ReportDiff::new`], with
+/// the same rename hints on removed entries. Entries are sorted by
+/// `function_id`.
+pub fn compare_functions(
+    previous: &[FunctionSnapshot],
+    current: &[FunctionSnapshot],
+) -> Vec<FunctionDeltaEntry> {
+    let current_funcs: HashMap<&str, &FunctionSnapshot> = current
+        .iter()
+        .map(|f| (f.function_id.as_str(), f))
+        .collect();
+    let parent_funcs = match_parent_functions(previous, &current_funcs);
+    let mut all_ids: Vec<&str> = parent_funcs
+        .keys()
+        .chain(current_funcs.keys())
+        .copied()
+        .collect::<std::collections::HashSet<_>>()
+        .into_iter()
+        .collect();
+    all_ids.sort();
+    let mut deltas = compute_function_deltas(&all_ids, &parent_funcs, &current_funcs);
+    apply_rename_hints(&mut deltas, &parent_funcs, &current_funcs);
+    deltas
+}
+
+/// Parse a previous results file for `--diff-against`, `--baseline`, and
+/// `--compare`
+///
+/// Accepts the report document written by `hotspots analyze --format json`,
+/// the flat report array of `--save-baseline` (and of `--format json` before
+/// it was versioned), or a full snapshot (`--mode snapshot --format json
+/// --all-functions`).
+pub fn parse_previous_results(json: &str) -> Result<Vec<FunctionSnapshot>> {
+    let mut value: serde_json::Value =
+        serde_json::from_str(json).context("previous results file is not valid JSON")?;
+    // A snapshot has a `commit`; the report document only `functions`
+    let reports = match value.as_object_mut() {
+        Some(document) if !document.contains_key("commit") => document.remove("functions"),
+        Some(_) => None,
+        None => Some(value),
+    };
+    if let Some(reports) = reports {
+        let reports: Vec<FunctionRiskReport> = serde_json::from_value(reports)
+            .context("failed to parse previous results as a report array")?;
+        return Ok(reports.into_iter().map(FunctionSnapshot::from).collect());
+    }
+    Ok(Snapshot::from_json(json)?.functions)
+}
+
 #[cfg(test)]
 mod tests {
     use super::*;
     use crate::git::GitContext;
     use crate::language::Language;
+    use crate::metrics::DecisionKind;
     use crate::report::{FunctionRiskReport, MetricsReport};
     use crate::risk::RiskBand;
     use crate::snapshot::Snapshot;
@@ -531,14 +904,31 @@ mod tests {
         let report = FunctionRiskReport {
             file: "src/foo.ts".to_string(),
             function: "handler".to_string(),
+            owner: None,
             line: 42,
             language: Language::TypeScript,
             metrics: MetricsReport {
                 cc,
                 nd: 2,
                 fo: 3,
+                fi: 0,
                 ns: 1,
                 loc: 10,
+                signature_complexity: 0,
+                params: 0,
+                guard_clauses: 0,
+                max_condition_ops: 0,
+                cognitive: 0,
+                halstead: None,
+                maintainability: None,
+                sloc: None,
+                comment_lines: None,
+                blank_lines: None,
+                nd_line: None,
+                cc_lines: None,
+                is_async: false,
+                await_in_loop: 0,
+                ns_breakdown: None,
             },
             risk: crate::report::RiskReport {
                 r_cc: 2.0,
@@ -548,11 +938,18 @@ mod tests {
             },
             lrs,
             band: RiskBand::parse(band).unwrap_or(RiskBand::Low),
+            risk_score: None,
             suppression_reason: None,
             patterns: vec![],
             pattern_details: None,
             callees: vec![],
             explanation: None,
+            arrow_depth: 0,
+            aliases: vec![],
+            structure: None,
+            cc_breakdown: None,
+            is_public: false,
+            file_top_level_decisions: 0,
         };
 
         Snapshot::new(git_context, vec![report])
@@ -642,4 +1039,324 @@ mod tests {
         assert!(delta.deltas[0].before.is_some());
         assert!(delta.deltas[0].after.is_none());
     }
+
+    /// A function with the given id and otherwise the test defaults
+    fn function(function_id: &str) -> FunctionSnapshot {
+        let mut function = create_test_snapshot("abc123", "", 5, 4.8, "moderate")
+            .functions
+            .remove(0);
+        function.file = function_id.split("::").next().unwrap().to_string();
+        function.function_id = function_id.to_string();
+        function
+    }
+
+    #[test]
+    fn test_go_methods_match_their_legacy_ids() {
+        let previous = vec![
+            function("main.go::check"),
+            function("main.go::run"),
+            function("store.go::Get"),
+            function("store.go::Put"),
+        ];
+        let current = vec![
+            function("main.go::run"),
+            // A closure of `run`, not a method of a type `run`
+            function("main.go::run.check"),
+            function("store.go::Store.Get"),
+            function("store.go::Store.Get.func1"),
+            // Two methods share the legacy id, so neither matches it
+            function("store.go::Store.Put"),
+            function("store.go::Buffer.Put"),
+        ];
+
+        let entries = compare_functions(&previous, &current);
+        let statuses: Vec<(&str, &FunctionStatus)> = entries
+            .iter()
+            .map(|e| (e.function_id.as_str(), &e.status))
+            .collect();
+        assert_eq!(
+            statuses,
+            vec![
+                ("main.go::check", &FunctionStatus::Deleted),
+                ("main.go::run", &FunctionStatus::Unchanged),
+                ("main.go::run.check", &FunctionStatus::New),
+                ("store.go::Buffer.Put", &FunctionStatus::New),
+                ("store.go::Put", &FunctionStatus::Deleted),
+                ("store.go::Store.Get", &FunctionStatus::Unchanged),
+                ("store.go::Store.Get.func1", &FunctionStatus::New),
+                ("store.go::Store.Put", &FunctionStatus::New),
+            ]
+        );
+
+        // An exempt entry written before the rename still applies
+        let mut delta = delta_of(entries);
+        let exempt = vec![ExemptFunction {
+            function_id: "store.go::Get".to_string(),
+            reason: Some("hot path".to_string()),
+        }];
+        delta.apply_exemptions(&exempt, Path::new("/repo"));
+        let exempted: Vec<&str> = delta
+            .deltas
+            .iter()
+            .filter(|e| e.exempt_reason.is_some())
+            .map(|e| e.function_id.as_str())
+            .collect();
+        assert_eq!(exempted, vec!["store.go::Store.Get"]);
+    }
+
+    fn state(lrs: f64, band: RiskBand) -> FunctionState {
+        FunctionState {
+            metrics: MetricsReport {
+                cc: 1,
+                nd: 0,
+                fo: 0,
+                fi: 0,
+                ns: 0,
+                loc: 1,
+                signature_complexity: 0,
+                params: 0,
+                guard_clauses: 0,
+                max_condition_ops: 0,
+                cognitive: 0,
+                halstead: None,
+                maintainability: None,
+                sloc: None,
+                comment_lines: None,
+                blank_lines: None,
+                nd_line: None,
+                cc_lines: None,
+                is_async: false,
+                await_in_loop: 0,
+                ns_breakdown: None,
+            },
+            lrs,
+            band,
+            cc_breakdown: None,
+        }
+    }
+
+    fn entry(id: &str, before: Option<FunctionState>, after: FunctionState) -> FunctionDeltaEntry {
+        FunctionDeltaEntry {
+            function_id: id.to_string(),
+            status: if before.is_some() {
+                FunctionStatus::Modified
+            } else {
+                FunctionStatus::New
+            },
+            before,
+            after: Some(after),
+            delta: None,
+            band_transition: None,
+            suppression_reason: None,
+            rename_hint: None,
+            exempt_reason: None,
+            explanation: None,
+        }
+    }
+
+    fn delta_of(deltas: Vec<FunctionDeltaEntry>) -> Delta {
+        Delta {
+            schema_version: DELTA_SCHEMA_VERSION,
+            commit: DeltaCommitInfo {
+                sha: "current123".to_string(),
+                parent: "parent123".to_string(),
+            },
+            baseline: false,
+            deltas,
+            policy: None,
+            aggregates: None,
+        }
+    }
+
+    #[test]
+    fn test_regressions_selects_worsened_functions() {
+        let mut suppressed = entry(
+            "src/a.ts::legacy",
+            Some(state(2.0, RiskBand::Low)),
+            state(9.5, RiskBand::Critical),
+        );
+        suppressed.suppression_reason = Some("legacy".to_string());
+        let delta = delta_of(vec![
+            // Band worsens by a small LRS step
+            entry(
+                "src/a.ts::band",
+                Some(state(5.9, RiskBand::Moderate)),
+                state(6.1, RiskBand::High),
+            ),
+            // Same band, large LRS increase
+            entry(
+                "src/a.ts::jump",
+                Some(state(3.1, RiskBand::Moderate)),
+                state(5.5, RiskBand::Moderate),
+            ),
+            // Same band, small increase: not a regression
+            entry(
+                "src/a.ts::drift",
+                Some(state(3.1, RiskBand::Moderate)),
+                state(3.5, RiskBand::Moderate),
+            ),
+            entry("src/b.ts::fresh", None, state(9.2, RiskBand::Critical)),
+            entry("src/b.ts::small", None, state(2.0, RiskBand::Low)),
+            suppressed,
+        ]);
+
+        let ids: Vec<&str> = delta
+            .regressions()
+            .iter()
+            .map(|e| e.function_id.as_str())
+            .collect();
+        assert_eq!(
+            ids,
+            vec!["src/b.ts::fresh", "src/a.ts::jump", "src/a.ts::band"]
+        );
+    }
+
+    #[test]
+    fn test_exempt_functions_are_marked_but_never_regress() {
+        let mut delta = delta_of(vec![
+            entry(
+                "/repo/src/a.ts::parse",
+                None,
+                state(9.5, RiskBand::Critical),
+            ),
+            entry(
+                "/repo/src/b.ts::route",
+                None,
+                state(9.1, RiskBand::Critical),
+            ),
+            entry(
+                "/repo/src/c.ts::render",
+                None,
+                state(9.3, RiskBand::Critical),
+            ),
+        ]);
+        let exempt = vec![
+            ExemptFunction {
+                function_id: "src/a.ts::parse".to_string(),
+                reason: Some("generated grammar".to_string()),
+            },
+            ExemptFunction {
+                function_id: "src/b.ts::route".to_string(),
+                reason: None,
+            },
+        ];
+        delta.apply_exemptions(&exempt, Path::new("/repo"));
+
+        // Still reported, flagged as exempt
+        assert_eq!(delta.deltas.len(), 3);
+        assert_eq!(
+            delta.deltas[0].exempt_reason.as_deref(),
+            Some("generated grammar")
+        );
+        assert_eq!(delta.deltas[1].exempt_reason.as_deref(), Some(""));
+        assert_eq!(delta.deltas[2].exempt_reason, None);
+
+        let ids: Vec<&str> = delta
+            .regressions()
+            .iter()
+            .map(|e| e.function_id.as_str())
+            .collect();
+        assert_eq!(ids, vec!["/repo/src/c.ts::render"]);
+    }
+
+    #[test]
+    fn test_regressions_empty_for_baseline() {
+        let current = create_test_snapshot("abc123", "", 30, 12.0, "critical");
+        let delta = Delta::new(&current, None).expect("should create baseline delta");
+        assert!(delta.regressions().is_empty());
+        assert_eq!(render_regressions_text(&delta.regressions()), "");
+    }
+
+    #[test]
+    fn test_render_regressions_text() {
+        let delta = delta_of(vec![
+            entry(
+                "src/a.ts::band",
+                Some(state(5.9, RiskBand::Moderate)),
+                state(6.1, RiskBand::High),
+            ),
+            entry("src/b.ts::fresh", None, state(9.2, RiskBand::Critical)),
+        ]);
+        assert_eq!(
+            render_regressions_text(&delta.regressions()),
+            "**Hotspots: 2 regressed functions**\n\n\
+             - `src/b.ts::fresh` new, critical, LRS 9.20\n\
+             - `src/a.ts::band` moderate → high, LRS 5.90 → 6.10 (+0.20)\n"
+        );
+    }
+
+    fn with_breakdown(mut state: FunctionState, kinds: &[(DecisionKind, u32)]) -> FunctionState {
+        let mut breakdown = CcBreakdown::default();
+        for &(kind, n) in kinds {
+            breakdown.add(kind, n);
+        }
+        state.cc_breakdown = Some(breakdown);
+        state
+    }
+
+    #[test]
+    fn test_explain_regressions() {
+        let mut delta = delta_of(vec![
+            entry(
+                "src/a.ts::band",
+                Some(with_breakdown(
+                    state(5.9, RiskBand::Moderate),
+                    &[(DecisionKind::If, 2)],
+                )),
+                with_breakdown(
+                    state(6.1, RiskBand::High),
+                    &[(DecisionKind::If, 3), (DecisionKind::And, 1)],
+                ),
+            ),
+            entry(
+                "src/b.ts::fresh",
+                None,
+                with_breakdown(
+                    state(9.2, RiskBand::Critical),
+                    &[(DecisionKind::Case, 2), (DecisionKind::Loop, 1)],
+                ),
+            ),
+            // Not a regression: left unexplained
+            entry(
+                "src/c.ts::drift",
+                Some(with_breakdown(state(3.1, RiskBand::Moderate), &[])),
+                with_breakdown(state(3.5, RiskBand::Moderate), &[(DecisionKind::If, 1)]),
+            ),
+            // Baseline written before breakdowns were recorded
+            entry(
+                "src/d.ts::old",
+                Some(state(5.9, RiskBand::Moderate)),
+                with_breakdown(state(6.1, RiskBand::High), &[(DecisionKind::If, 1)]),
+            ),
+        ]);
+        delta.explain_regressions();
+
+        let explanations: Vec<Option<&str>> = delta
+            .deltas
+            .iter()
+            .map(|e| e.explanation.as_deref())
+            .collect();
+        assert_eq!(
+            explanations,
+            vec![
+                Some("+1 if, +1 &&"),
+                Some("+1 loop, +2 switch cases"),
+                None,
+                None,
+            ]
+        );
+        assert_eq!(
+            render_regressions_text(&delta.regressions()),
+            "**Hotspots: 3 regressed functions**\n\n\
+             - `src/b.ts::fresh` new, critical, LRS 9.20 — +1 loop, +2 switch cases\n\
+             - `src/a.ts::band` moderate → high, LRS 5.90 → 6.10 (+0.20) — +1 if, +1 &&\n\
+             - `src/d.ts::old` moderate → high, LRS 5.90 → 6.10 (+0.20)\n"
+        );
+        // Not serialized on the states, only as the entry's explanation
+        let json: serde_json::Value =
+            serde_json::from_str(&delta.to_json("1.2.3").unwrap()).unwrap();
+        assert_eq!(json["deltas"][0]["explanation"], "+1 if, +1 &&");
+        assert!(json["deltas"][0]["after"].get("cc_breakdown").is_none());
+        assert!(json["deltas"][2].get("explanation").is_none());
+    }
 }
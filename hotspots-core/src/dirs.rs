@@ -0,0 +1,260 @@
+//! Directory-level complexity rollups (`--group-by dir`)
+//!
+//! Sums function metrics per directory so the worst subsystem stands out, not
+//! just the worst function. A directory's rollup covers every function in its
+//! files and in all of its subdirectories, so the root node covers the whole
+//! analysis.
+//!
+//! Global invariants enforced:
+//! - A directory's function count and total CC equal those of its own files
+//!   plus those of its subdirectories
+//! - Deterministic output ordering (total CC descending, then name)
+
+use crate::report::FunctionRiskReport;
+use serde::Serialize;
+use std::collections::BTreeMap;
+use std::path::{Component, Path};
+
+/// Complexity of one directory and everything below it
+#[derive(Debug, Clone, Serialize)]
+pub struct DirRollup {
+    /// Path relative to the analyzed root (`.` for the root itself)
+    pub path: String,
+    /// Number of functions rolled up
+    pub functions: usize,
+    /// Sum of the functions' CC
+    pub total_cc: u32,
+    /// `total_cc / functions`; 0 without functions
+    pub mean_cc: f64,
+    /// Deepest nesting among the functions
+    pub max_nd: u32,
+    /// Subdirectories that contain functions, most complex first
+    #[serde(skip_serializing_if = "Vec::is_empty")]
+    pub children: Vec<DirRollup>,
+}
+
+/// Accumulator for one directory while the tree is built
+#[derive(Default)]
+struct DirNode {
+    functions: usize,
+    total_cc: u32,
+    max_nd: u32,
+    children: BTreeMap<String, DirNode>,
+}
+
+impl DirNode {
+    fn add(&mut self, report: &FunctionRiskReport) {
+        self.functions += 1;
+        self.total_cc += report.metrics.cc;
+        self.max_nd = self.max_nd.max(report.metrics.nd);
+    }
+
+    fn into_rollup(self, path: String) -> DirRollup {
+        let mut children: Vec<DirRollup> = self
+            .children
+            .into_iter()
+            .map(|(name, child)| {
+                let child_path = if path == "." {
+                    name
+                } else {
+                    format!("{path}/{name}")
+                };
+                child.into_rollup(child_path)
+            })
+            .collect();
+        children.sort_by(|a, b| b.total_cc.cmp(&a.total_cc).then(a.path.cmp(&b.path)));
+        DirRollup {
+            path,
+            functions: self.functions,
+            total_cc: self.total_cc,
+            mean_cc: if self.functions > 0 {
+                f64::from(self.total_cc) / self.functions as f64
+            } else {
+                0.0
+            },
+            max_nd: self.max_nd,
+            children,
+        }
+    }
+}
+
+/// Roll function reports up into a directory tree rooted at `root`.
+///
+/// Files outside `root` are placed by their full parent path. Pass unfiltered
+/// reports: functions dropped by `--top` or `--min-lrs` would be missing from
+/// the sums.
+pub fn dir_rollups(reports: &[FunctionRiskReport], root: &Path) -> DirRollup {
+    let mut tree = DirNode::default();
+    for report in reports {
+        let file = Path::new(&report.file);
+        let dir = file.parent().unwrap_or(Path::new(""));
+        let relative = dir.strip_prefix(root).unwrap_or(dir);
+
+        tree.add(report);
+        let mut node = &mut tree;
+        for component in relative.components() {
+            let Component::Normal(name) = component else {
+                continue;
+            };
+            node = node
+                .children
+                .entry(name.to_string_lossy().into_owned())
+                .or_default();
+            node.add(report);
+        }
+    }
+    tree.into_rollup(".".to_string())
+}
+
+/// Render the tree as an indented text table
+pub fn render_dirs_text(root: &DirRollup) -> String {
+    use std::fmt::Write;
+
+    if root.functions == 0 {
+        return "No functions found.\n".to_string();
+    }
+    let mut out = String::new();
+    let _ = writeln!(
+        out,
+        "{:<50} {:>9} {:>8} {:>8} {:>6}",
+        "Directory", "Functions", "Total CC", "Mean CC", "Max ND"
+    );
+    let _ = writeln!(out, "{}", "-".repeat(85));
+    write_row(&mut out, root, ".", "");
+    out
+}
+
+/// Write `node` labelled `label`, then its children under `prefix`
+fn write_row(out: &mut String, node: &DirRollup, label: &str, prefix: &str) {
+    use std::fmt::Write;
+
+    let _ = writeln!(
+        out,
+        "{:<50} {:>9} {:>8} {:>8.2} {:>6}",
+        label, node.functions, node.total_cc, node.mean_cc, node.max_nd
+    );
+    for (i, child) in node.children.iter().enumerate() {
+        let last = i + 1 == node.children.len();
+        let name = child.path.rsplit('/').next().unwrap_or(&child.path);
+        let (branch, indent) = if last {
+            ("└── ", "    ")
+        } else {
+            ("├── ", "│   ")
+        };
+        write_row(
+            out,
+            child,
+            &format!("{prefix}{branch}{name}"),
+            &format!("{prefix}{indent}"),
+        );
+    }
+}
+
+/// Render the tree as pretty-printed JSON
+pub fn render_dirs_json(root: &DirRollup) -> String {
+    serde_json::to_string_pretty(root).unwrap_or_else(|_| "{}".to_string())
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::language::Language;
+    use crate::report::{MetricsReport, RiskReport};
+    use crate::risk::RiskBand;
+
+    fn make_report(file: &str, cc: u32, nd: u32) -> FunctionRiskReport {
+        FunctionRiskReport {
+            file: file.to_string(),
+            function: "f".to_string(),
+            owner: None,
+            line: 1,
+            language: Language::TypeScript,
+            metrics: MetricsReport {
+                cc,
+                nd,
+                fo: 0,
+                fi: 0,
+                ns: 0,
+                loc: 1,
+                signature_complexity: 0,
+                params: 0,
+                guard_clauses: 0,
+                max_condition_ops: 0,
+                cognitive: 0,
+                halstead: None,
+                maintainability: None,
+                sloc: None,
+                comment_lines: None,
+                blank_lines: None,
+                nd_line: None,
+                cc_lines: None,
+                is_async: false,
+                await_in_loop: 0,
+                ns_breakdown: None,
+            },
+            risk: RiskReport {
+                r_cc: 0.0,
+                r_nd: 0.0,
+                r_fo: 0.0,
+                r_ns: 0.0,
+            },
+            lrs: 1.0,
+            band: RiskBand::Low,
+            risk_score: None,
+            suppression_reason: None,
+            patterns: vec![],
+            pattern_details: None,
+            callees: vec![],
+            explanation: None,
+            arrow_depth: 0,
+            aliases: vec![],
+            structure: None,
+            cc_breakdown: None,
+            is_public: false,
+            file_top_level_decisions: 0,
+        }
+    }
+
+    #[test]
+    fn test_rollups_include_descendants() {
+        let reports = vec![
+            make_report("/repo/main.ts", 2, 1),
+            make_report("/repo/src/a.ts", 3, 2),
+            make_report("/repo/src/deep/b.ts", 5, 4),
+            make_report("/repo/lib/c.ts", 1, 0),
+        ];
+        let root = dir_rollups(&reports, Path::new("/repo"));
+        assert_eq!((root.functions, root.total_cc, root.max_nd), (4, 11, 4));
+        assert_eq!(root.mean_cc, 2.75);
+
+        let paths: Vec<&str> = root.children.iter().map(|c| c.path.as_str()).collect();
+        assert_eq!(paths, vec!["src", "lib"]);
+        let src = &root.children[0];
+        assert_eq!((src.functions, src.total_cc, src.max_nd), (2, 8, 4));
+        assert_eq!(src.children[0].path, "src/deep");
+        assert_eq!(src.children[0].total_cc, 5);
+    }
+
+    #[test]
+    fn test_render_text_tree() {
+        let reports = vec![
+            make_report("/repo/src/a.ts", 3, 1),
+            make_report("/repo/src/deep/b.ts", 1, 0),
+            make_report("/repo/lib/c.ts", 1, 0),
+        ];
+        let text = render_dirs_text(&dir_rollups(&reports, Path::new("/repo")));
+        // The label column is 50 characters wide
+        let labels: Vec<String> = text
+            .lines()
+            .skip(2)
+            .map(|line| {
+                line.chars()
+                    .take(50)
+                    .collect::<String>()
+                    .trim_end()
+                    .to_string()
+            })
+            .collect();
+        assert_eq!(labels, vec![".", "├── src", "│   └── deep", "└── lib"]);
+    }
+}
@@ -11,6 +11,27 @@
 //! - Class methods (`ClassMethod`)
 //! - Object literal methods (`MethodProp`)
 //!
+//! Function and arrow expressions without a name of their own take one from
+//! where they are bound, so reports and baseline IDs stay readable and stable:
+//! - `const x = () => {}` and `x = function() {}` → `x`
+//! - `{ key: () => {} }` and class property `key = () => {}` → `key`
+//! - `obj.key = function() {}`, including `exports.key` → `key`
+//! - `export default function() {}`, `export default () => {}`, and
+//!   `module.exports = function() {}` → `default`
+//!
+//! Anything else (callbacks, IIFEs) stays anonymous.
+//!
+//! Methods of a named class, and functions bound to its properties
+//! (`key = () => {}`), have the class as their owner (`FunctionNode::owner`).
+//! Object literal methods and methods of anonymous classes have none.
+//!
+//! A function is public (`FunctionNode::is_public`) when it is defined directly
+//! in an exported top-level declaration — `export function`, `export const`,
+//! `export default`, or a declaration named in `export { ... }` — and not
+//! nested inside another function. Methods of an exported class are public
+//! unless marked `private` or `protected`. CommonJS `module.exports` is not
+//! recognized.
+//!
 //! Ignored constructs (automatically excluded as they have no function bodies):
 //! - Interfaces
 //! - Type aliases
@@ -19,6 +40,7 @@
 
 use crate::ast::{FunctionId, FunctionNode};
 use crate::language::{span::span_with_location, FunctionBody};
+use std::collections::HashSet;
 use swc_ecma_ast::*;
 use swc_ecma_visit::{Visit, VisitWith};
 
@@ -37,6 +59,10 @@ pub fn discover_functions(
         local_index: 0,
         source_map,
         pending_name: None,
+        class_name: None,
+        pending_owner: None,
+        exported_names: HashSet::new(),
+        public_context: false,
     };
 
     module.visit_with(&mut collector);
@@ -68,23 +94,111 @@ struct FunctionCollector<'a> {
     functions: Vec<FunctionNode>,
     local_index: usize,
     source_map: &'a swc_common::SourceMap,
-    /// Name of the variable a function/arrow expression is being assigned to
-    /// (e.g. `const Foo = () => {...}`), set while visiting the declarator's
-    /// init expression so the function picks it up instead of `<anonymous>`.
+    /// Name of the binding a function/arrow expression is being assigned to
+    /// (e.g. `const Foo = () => {...}`), set while visiting the binding's
+    /// value so the function picks it up instead of `<anonymous>`.
     pending_name: Option<String>,
+    /// Name of the class whose body is being visited; None in anonymous
+    /// classes
+    class_name: Option<String>,
+    /// Class a property's function value is being bound in, taken like
+    /// `pending_name`
+    pending_owner: Option<String>,
+    /// Local names exported by `export { ... }` or `export default name`
+    exported_names: HashSet<String>,
+    /// Set while visiting an exported top-level item, outside any function
+    /// body; functions discovered meanwhile are public
+    public_context: bool,
+}
+
+impl<'a> FunctionCollector<'a> {
+    /// Visit a function's children; functions nested in it are never public
+    fn visit_nested<N: VisitWith<Self>>(&mut self, node: &N) {
+        let public_context = std::mem::replace(&mut self.public_context, false);
+        node.visit_children_with(self);
+        self.public_context = public_context;
+    }
+
+    /// Visit a binding's children, naming its value `name` if the value is a
+    /// function or arrow expression
+    fn visit_binding<N: VisitWith<Self>>(&mut self, node: &N, name: Option<String>, value: &Expr) {
+        if matches!(value, Expr::Fn(_) | Expr::Arrow(_)) {
+            self.pending_name = name;
+        }
+        node.visit_children_with(self);
+        self.pending_name = None;
+    }
 }
 
 impl<'a> Visit for FunctionCollector<'a> {
-    fn visit_var_declarator(&mut self, decl: &VarDeclarator) {
-        if let (Pat::Ident(ident), Some(init)) = (&decl.name, &decl.init) {
-            if matches!(&**init, Expr::Fn(_) | Expr::Arrow(_)) {
-                self.pending_name = Some(ident.id.sym.to_string());
+    fn visit_module(&mut self, module: &Module) {
+        self.exported_names = exported_local_names(module);
+        for item in &module.body {
+            self.public_context = is_exported_item(item, &self.exported_names);
+            item.visit_with(self);
+        }
+        self.public_context = false;
+    }
+
+    fn visit_class_prop(&mut self, prop: &ClassProp) {
+        // `private handler = () => {...}` is not public even in an exported class
+        let public_context = self.public_context;
+        self.public_context &= !is_hidden(prop.accessibility);
+        match &prop.value {
+            Some(value) => {
+                if matches!(&**value, Expr::Fn(_) | Expr::Arrow(_)) {
+                    self.pending_owner = self.class_name.clone();
+                }
+                self.visit_binding(prop, prop_name(&prop.key), value);
+                self.pending_owner = None;
             }
+            None => prop.visit_children_with(self),
         }
+        self.public_context = public_context;
+    }
+
+    fn visit_class_decl(&mut self, decl: &ClassDecl) {
+        let class_name = self.class_name.replace(decl.ident.sym.to_string());
         decl.visit_children_with(self);
+        self.class_name = class_name;
+    }
+
+    fn visit_class_expr(&mut self, expr: &ClassExpr) {
+        let name = expr.ident.as_ref().map(|id| id.sym.to_string());
+        let class_name = std::mem::replace(&mut self.class_name, name);
+        expr.visit_children_with(self);
+        self.class_name = class_name;
+    }
+
+    fn visit_var_declarator(&mut self, decl: &VarDeclarator) {
+        match (&decl.name, &decl.init) {
+            (Pat::Ident(ident), Some(init)) => {
+                self.visit_binding(decl, Some(ident.id.sym.to_string()), init)
+            }
+            _ => decl.visit_children_with(self),
+        }
+    }
+
+    fn visit_key_value_prop(&mut self, prop: &KeyValueProp) {
+        self.visit_binding(prop, prop_name(&prop.key), &prop.value);
+    }
+
+    fn visit_assign_expr(&mut self, assign: &AssignExpr) {
+        self.visit_binding(assign, assign_target_name(&assign.left), &assign.right);
+    }
+
+    fn visit_export_default_decl(&mut self, export: &ExportDefaultDecl) {
+        if let DefaultDecl::Fn(FnExpr { ident: None, .. }) = &export.decl {
+            self.pending_name = Some(DEFAULT_EXPORT_NAME.to_string());
+        }
+        export.visit_children_with(self);
         self.pending_name = None;
     }
 
+    fn visit_export_default_expr(&mut self, export: &ExportDefaultExpr) {
+        self.visit_binding(export, Some(DEFAULT_EXPORT_NAME.to_string()), &export.expr);
+    }
+
     fn visit_fn_decl(&mut self, decl: &FnDecl) {
         // Extract function name from declaration
         let name = Some(decl.ident.sym.to_string());
@@ -99,25 +213,35 @@ impl<'a> Visit for FunctionCollector<'a> {
                     local_index: self.local_index,
                 },
                 name,
+                owner: None,
                 span: span_with_location(decl.function.span, self.source_map),
                 body: FunctionBody::ecmascript(body),
                 suppression_reason: None,
+                signature_complexity: crate::signature::ecmascript_function_signature(
+                    &decl.function,
+                ),
+                params: crate::params::ecmascript_function_params(&decl.function),
+                is_public: self.public_context,
+                is_async: decl.function.is_async,
             });
             self.local_index += 1;
         }
 
         // Continue visiting children
-        decl.visit_children_with(self);
+        self.visit_nested(decl);
     }
 
     fn visit_fn_expr(&mut self, expr: &FnExpr) {
-        // Extract function name; fall back to the variable it's assigned to
-        // (e.g. `const Foo = function() {...}`)
+        // Extract function name; fall back to the binding it's assigned to
+        // (e.g. `const Foo = function() {...}`). Always take the pending name
+        // so a function nested in a named expression cannot claim it.
+        let pending_name = self.pending_name.take();
+        let owner = self.pending_owner.take();
         let name = expr
             .ident
             .as_ref()
             .map(|id| id.sym.to_string())
-            .or_else(|| self.pending_name.take());
+            .or(pending_name);
 
         // Extract body
         let body = expr.function.body.clone();
@@ -129,21 +253,29 @@ impl<'a> Visit for FunctionCollector<'a> {
                     local_index: self.local_index,
                 },
                 name,
+                owner,
                 span: span_with_location(expr.function.span, self.source_map),
                 body: FunctionBody::ecmascript(body),
                 suppression_reason: None,
+                signature_complexity: crate::signature::ecmascript_function_signature(
+                    &expr.function,
+                ),
+                params: crate::params::ecmascript_function_params(&expr.function),
+                is_public: self.public_context,
+                is_async: expr.function.is_async,
             });
             self.local_index += 1;
         }
 
         // Continue visiting children
-        expr.visit_children_with(self);
+        self.visit_nested(expr);
     }
 
     fn visit_arrow_expr(&mut self, arrow: &ArrowExpr) {
-        // Use the variable it's assigned to (e.g. `const Foo = () => {...}`),
+        // Use the binding it's assigned to (e.g. `const Foo = () => {...}`),
         // falling back to <anonymous>@file:line in the name extraction
         let name = self.pending_name.take();
+        let owner = self.pending_owner.take();
 
         match &*arrow.body {
             BlockStmtOrExpr::BlockStmt(ref body) => {
@@ -153,9 +285,14 @@ impl<'a> Visit for FunctionCollector<'a> {
                         local_index: self.local_index,
                     },
                     name,
+                    owner,
                     span: span_with_location(arrow.span, self.source_map),
                     body: FunctionBody::ecmascript(body.clone()),
                     suppression_reason: None,
+                    signature_complexity: crate::signature::ecmascript_arrow_signature(arrow),
+                    params: crate::params::ecmascript_arrow_params(arrow),
+                    is_public: self.public_context,
+                    is_async: arrow.is_async,
                 });
                 self.local_index += 1;
             }
@@ -178,28 +315,25 @@ impl<'a> Visit for FunctionCollector<'a> {
                         local_index: self.local_index,
                     },
                     name,
+                    owner,
                     span: span_with_location(arrow.span, self.source_map),
                     body: FunctionBody::ecmascript(body),
                     suppression_reason: None,
+                    signature_complexity: crate::signature::ecmascript_arrow_signature(arrow),
+                    params: crate::params::ecmascript_arrow_params(arrow),
+                    is_public: self.public_context,
+                    is_async: arrow.is_async,
                 });
                 self.local_index += 1;
             }
         }
 
         // Continue visiting children
-        arrow.visit_children_with(self);
+        self.visit_nested(arrow);
     }
 
     fn visit_class_method(&mut self, method: &ClassMethod) {
-        let name = match &method.key {
-            PropName::Ident(ident) => Some(ident.sym.to_string()),
-            PropName::Str(str_lit) => {
-                // Wtf8Atom to String via to_atom_lossy (borrows when possible)
-                Some(str_lit.value.to_atom_lossy().to_string())
-            }
-            PropName::Num(num) => Some(num.to_string()),
-            _ => None,
-        };
+        let name = prop_name(&method.key);
 
         let body = method.function.body.clone();
 
@@ -210,27 +344,26 @@ impl<'a> Visit for FunctionCollector<'a> {
                     local_index: self.local_index,
                 },
                 name,
+                owner: self.class_name.clone(),
                 span: span_with_location(method.span, self.source_map),
                 body: FunctionBody::ecmascript(body),
                 suppression_reason: None,
+                signature_complexity: crate::signature::ecmascript_function_signature(
+                    &method.function,
+                ),
+                params: crate::params::ecmascript_function_params(&method.function),
+                is_public: self.public_context && !is_hidden(method.accessibility),
+                is_async: method.function.is_async,
             });
             self.local_index += 1;
         }
 
         // Continue visiting children
-        method.visit_children_with(self);
+        self.visit_nested(method);
     }
 
     fn visit_method_prop(&mut self, method: &MethodProp) {
-        let name = match &method.key {
-            PropName::Ident(ident) => Some(ident.sym.to_string()),
-            PropName::Str(str_lit) => {
-                // Wtf8Atom to String via to_atom_lossy (borrows when possible)
-                Some(str_lit.value.to_atom_lossy().to_string())
-            }
-            PropName::Num(num) => Some(num.to_string()),
-            _ => None,
-        };
+        let name = prop_name(&method.key);
 
         let body = method.function.body.clone();
 
@@ -241,18 +374,119 @@ impl<'a> Visit for FunctionCollector<'a> {
                     local_index: self.local_index,
                 },
                 name,
+                owner: None,
                 span: span_with_location(method.function.span, self.source_map),
                 body: FunctionBody::ecmascript(body),
                 suppression_reason: None,
+                signature_complexity: crate::signature::ecmascript_function_signature(
+                    &method.function,
+                ),
+                params: crate::params::ecmascript_function_params(&method.function),
+                is_public: self.public_context,
+                is_async: method.function.is_async,
             });
             self.local_index += 1;
         }
 
         // Continue visiting children
-        method.visit_children_with(self);
+        self.visit_nested(method);
+    }
+}
+
+/// Name given to anonymous default exports (`export default function() {}`,
+/// `module.exports = ...`)
+const DEFAULT_EXPORT_NAME: &str = "default";
+
+/// Name of a method or property key; None for computed keys
+fn prop_name(key: &PropName) -> Option<String> {
+    match key {
+        PropName::Ident(ident) => Some(ident.sym.to_string()),
+        PropName::Str(str_lit) => {
+            // Wtf8Atom to String via to_atom_lossy (borrows when possible)
+            Some(str_lit.value.to_atom_lossy().to_string())
+        }
+        PropName::Num(num) => Some(num.to_string()),
+        _ => None,
+    }
+}
+
+/// Name for a function assigned to `target`: the variable, or the property
+/// of a member expression (`exports.parse` → `parse`). `module.exports`
+/// itself is the default export.
+fn assign_target_name(target: &AssignTarget) -> Option<String> {
+    match target {
+        AssignTarget::Simple(SimpleAssignTarget::Ident(ident)) => Some(ident.id.sym.to_string()),
+        AssignTarget::Simple(SimpleAssignTarget::Member(member)) => {
+            let MemberProp::Ident(prop) = &member.prop else {
+                return None;
+            };
+            let is_module_exports = &*prop.sym == "exports"
+                && matches!(&*member.obj, Expr::Ident(obj) if &*obj.sym == "module");
+            if is_module_exports {
+                Some(DEFAULT_EXPORT_NAME.to_string())
+            } else {
+                Some(prop.sym.to_string())
+            }
+        }
+        _ => None,
+    }
+}
+
+/// Whether a top-level item is exported, directly or by name
+fn is_exported_item(item: &ModuleItem, exported_names: &HashSet<String>) -> bool {
+    match item {
+        ModuleItem::ModuleDecl(
+            ModuleDecl::ExportDecl(_)
+            | ModuleDecl::ExportDefaultDecl(_)
+            | ModuleDecl::ExportDefaultExpr(_),
+        ) => true,
+        ModuleItem::Stmt(Stmt::Decl(Decl::Fn(decl))) => exported_names.contains(&*decl.ident.sym),
+        ModuleItem::Stmt(Stmt::Decl(Decl::Class(decl))) => {
+            exported_names.contains(&*decl.ident.sym)
+        }
+        ModuleItem::Stmt(Stmt::Decl(Decl::Var(decl))) => decl.decls.iter().any(
+            |d| matches!(&d.name, Pat::Ident(ident) if exported_names.contains(&*ident.id.sym)),
+        ),
+        _ => false,
     }
 }
 
+/// Local names exported after their declaration: `export { a, b as c }`
+/// (without `from`) and `export default a`
+fn exported_local_names(module: &Module) -> HashSet<String> {
+    let mut names = HashSet::new();
+    for item in &module.body {
+        match item {
+            ModuleItem::ModuleDecl(ModuleDecl::ExportNamed(named)) if named.src.is_none() => {
+                for specifier in &named.specifiers {
+                    if let ExportSpecifier::Named(ExportNamedSpecifier {
+                        orig: ModuleExportName::Ident(ident),
+                        ..
+                    }) = specifier
+                    {
+                        names.insert(ident.sym.to_string());
+                    }
+                }
+            }
+            ModuleItem::ModuleDecl(ModuleDecl::ExportDefaultExpr(export)) => {
+                if let Expr::Ident(ident) = &*export.expr {
+                    names.insert(ident.sym.to_string());
+                }
+            }
+            _ => {}
+        }
+    }
+    names
+}
+
+/// `private` and `protected` class members are not public API
+fn is_hidden(accessibility: Option<Accessibility>) -> bool {
+    matches!(
+        accessibility,
+        Some(Accessibility::Private | Accessibility::Protected)
+    )
+}
+
 #[cfg(test)]
 #[path = "discover/tests.rs"]
 mod tests;
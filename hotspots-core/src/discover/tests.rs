@@ -136,4 +136,164 @@ mod discover_tests {
             "Should have one statement (return)"
         );
     }
+
+    #[test]
+    fn test_discover_public_marks_exports_only() {
+        let src = r#"
+            export function exported() { const inner = () => 1; return inner(); }
+            function local() { return 1; }
+            function laterExported() { return 2; }
+            export { laterExported };
+            export const arrow = () => 3;
+            export class Service {
+                run() { return 4; }
+                private helper() { return 5; }
+            }
+        "#;
+        let functions = parse_and_discover(src, 0);
+        let public: Vec<(&str, bool)> = functions
+            .iter()
+            .map(|f| (f.name.as_deref().unwrap_or("<anonymous>"), f.is_public))
+            .collect();
+        assert_eq!(
+            public,
+            vec![
+                ("exported", true),
+                ("inner", false),
+                ("local", false),
+                ("laterExported", true),
+                ("arrow", true),
+                ("run", true),
+                ("helper", false),
+            ]
+        );
+    }
+
+    fn names(src: &str) -> Vec<String> {
+        parse_and_discover(src, 0)
+            .into_iter()
+            .map(|f| f.name.unwrap_or_else(|| "<anonymous>".to_string()))
+            .collect()
+    }
+
+    #[test]
+    fn test_discover_default_export_named_default() {
+        assert_eq!(
+            names("export default function() { return 1; }"),
+            vec!["default"]
+        );
+        assert_eq!(names("export default () => 1;"), vec!["default"]);
+        // A declared name wins
+        assert_eq!(
+            names("export default function main() { return 1; }"),
+            vec!["main"]
+        );
+    }
+
+    #[test]
+    fn test_discover_named_from_object_key_and_class_property() {
+        let src = r#"
+            const handlers = {
+                onClick: () => 1,
+                "on-hover": function() { return 2; },
+                submit() { return 3; },
+            };
+            class View {
+                render = () => 4;
+            }
+        "#;
+        assert_eq!(names(src), vec!["onClick", "on-hover", "submit", "render"]);
+    }
+
+    #[test]
+    fn test_discover_method_owners() {
+        let src = r#"
+            class View {
+                render() { return 1; }
+                onClick = () => 2;
+                label = format(() => 3);
+                build() { return { make() { return 4; } }; }
+            }
+            const Anonymous = class { run() { return 5; } };
+            function free() { return 6; }
+        "#;
+        let functions = parse_and_discover(src, 0);
+        let owners: Vec<(&str, Option<&str>)> = functions
+            .iter()
+            .map(|f| {
+                (
+                    f.name.as_deref().unwrap_or("<anonymous>"),
+                    f.owner.as_deref(),
+                )
+            })
+            .collect();
+        // Callbacks and object literal methods inside a class are not its methods
+        assert_eq!(
+            owners,
+            vec![
+                ("render", Some("View")),
+                ("onClick", Some("View")),
+                ("<anonymous>", None),
+                ("build", Some("View")),
+                ("make", None),
+                ("run", None),
+                ("free", None),
+            ]
+        );
+    }
+
+    #[test]
+    fn test_discover_named_from_assignment() {
+        let src = r#"
+            module.exports = function() { return 1; };
+            module.exports.parse = function() { return 2; };
+            exports.render = () => 3;
+            handler = () => 4;
+            items.forEach((item) => item);
+        "#;
+        assert_eq!(
+            names(src),
+            vec!["default", "parse", "render", "handler", "<anonymous>"]
+        );
+    }
+
+    #[test]
+    fn test_discover_nested_function_does_not_take_binding_name() {
+        // `outer` belongs to the named function expression, not the arrow in it
+        let src = "const outer = function named() { return () => 1; };";
+        assert_eq!(names(src), vec!["named", "<anonymous>"]);
+    }
+
+    #[test]
+    fn test_discover_async_flag() {
+        let src = r#"
+            async function load() { return 1; }
+            function plain() { return 2; }
+            const fetchAll = async () => 3;
+            const run = async function() { return 4; };
+            class Store {
+                async save() { return 5; }
+            }
+            const api = { async get() { return 6; } };
+            load().then((value) => value);
+        "#;
+        let functions = parse_and_discover(src, 0);
+        let flags: Vec<(&str, bool)> = functions
+            .iter()
+            .map(|f| (f.name.as_deref().unwrap_or("<anonymous>"), f.is_async))
+            .collect();
+        // A `.then()` callback is a function of its own, not part of `load`
+        assert_eq!(
+            flags,
+            vec![
+                ("load", true),
+                ("plain", false),
+                ("fetchAll", true),
+                ("run", true),
+                ("save", true),
+                ("get", true),
+                ("<anonymous>", false),
+            ]
+        );
+    }
 }
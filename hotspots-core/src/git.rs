@@ -61,6 +61,17 @@ pub struct BatchedTouchMetrics {
     pub days_since_last_change: std::collections::HashMap<String, u32>,
 }
 
+/// One hunk of a zero-context (`-U0`) diff, as given by its `@@ -a,b +c,d @@`
+/// header. A zero length means the hunk only adds (`old_len`) or only removes
+/// (`new_len`) lines, and the start is then the line *before* the change.
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub struct DiffHunk {
+    pub old_start: u32,
+    pub old_len: u32,
+    pub new_start: u32,
+    pub new_len: u32,
+}
+
 /// Environment variables git uses to locate a repository, bypassing normal
 /// cwd-based discovery entirely when set. If the calling process inherits
 /// these (e.g. hotspots-core is invoked from within a git hook, where git
@@ -431,6 +442,47 @@ pub fn create_worktree(repo_root: &Path, sha: &str) -> Result<TempWorktree> {
     })
 }
 
+/// How much commit history is available locally.
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub struct HistoryDepth {
+    /// The clone was made with `--depth` (history is truncated).
+    pub shallow: bool,
+    /// Commits reachable from HEAD.
+    pub commits: usize,
+}
+
+impl HistoryDepth {
+    /// Warning text for a shallow clone, or `None` when history is complete.
+    ///
+    /// Churn, touch counts, and recency are all read from `git log`, so on a
+    /// `--depth 1` clone they silently come out near zero.
+    pub fn warning(&self) -> Option<String> {
+        if !self.shallow {
+            return None;
+        }
+        Some(format!(
+            "shallow git history ({} commit(s) available): churn, touch counts, and recency \
+             need full history and will read as near zero. Complexity metrics are unaffected. \
+             Fetch full history with `git fetch --unshallow` \
+             (GitHub Actions: `fetch-depth: 0` on actions/checkout).",
+            self.commits
+        ))
+    }
+}
+
+/// Report whether the repository at `repo_root` is a shallow clone and how many
+/// commits are reachable from HEAD.
+pub fn history_depth(repo_root: &Path) -> Result<HistoryDepth> {
+    let shallow = git_at(repo_root, &["rev-parse", "--is-shallow-repository"])
+        .context("failed to check for shallow clone")?
+        == "true";
+    let commits = git_at(repo_root, &["rev-list", "--count", "HEAD"])
+        .context("failed to count commits")?
+        .parse::<usize>()
+        .context("failed to parse commit count")?;
+    Ok(HistoryDepth { shallow, commits })
+}
+
 /// Find the merge-base SHA and Unix timestamp between HEAD and main/master.
 /// Returns None when already on main (merge-base == HEAD) or no divergence found.
 pub fn find_merge_base(repo_root: &Path) -> Option<(String, i64)> {
@@ -455,6 +507,61 @@ pub fn find_merge_base(repo_root: &Path) -> Option<(String, i64)> {
     None
 }
 
+/// Merge-base of HEAD and the repository's default branch: `origin/HEAD`
+/// when the remote's default is known, else the first of `main`, `master`,
+/// `origin/main`, and `origin/master` that exists. On the default branch
+/// itself this is HEAD.
+pub fn default_branch_merge_base(repo_root: &Path) -> Result<String> {
+    let mut candidates = Vec::new();
+    if let Ok(remote_head) = git_at(
+        repo_root,
+        &["symbolic-ref", "--short", "refs/remotes/origin/HEAD"],
+    ) {
+        candidates.push(remote_head);
+    }
+    candidates.extend(
+        ["main", "master", "origin/main", "origin/master"]
+            .iter()
+            .map(|b| b.to_string()),
+    );
+    candidates
+        .iter()
+        .find_map(|branch| git_at(repo_root, &["merge-base", "HEAD", branch]).ok())
+        .filter(|sha| !sha.is_empty())
+        .context("could not find the default branch (tried origin/HEAD, main, master, origin/main, origin/master); pass a ref: --changed <REF>")
+}
+
+/// Files that differ between `base` and the working tree, as absolute paths
+/// under `repo_root`, from `git diff --name-only` with rename detection: a
+/// renamed file is listed under its new path, and deleted files are left out.
+/// Untracked files are not included.
+pub fn changed_files_at(repo_root: &Path, base: &str) -> Result<Vec<std::path::PathBuf>> {
+    let output = git_at(
+        repo_root,
+        &[
+            "diff",
+            "--name-only",
+            "-z",
+            "-M",
+            "--diff-filter=d",
+            "--no-ext-diff",
+            base,
+            "--",
+        ],
+    )
+    .with_context(|| format!("failed to list files changed since '{base}'"))?;
+    Ok(parse_changed_files(&output)
+        .into_iter()
+        .map(|file| repo_root.join(file))
+        .filter(|file| file.is_file())
+        .collect())
+}
+
+/// Paths in `git diff --name-only -z` output, in order
+fn parse_changed_files(output: &str) -> Vec<&str> {
+    output.split('\0').filter(|p| !p.is_empty()).collect()
+}
+
 /// Days since the last change to `file` at or before `before_sha`.
 /// Returns None if the file has no history before that SHA.
 pub fn days_since_last_change_at_sha(
@@ -789,6 +896,97 @@ pub fn function_touch_metrics_at(
     Ok((touch_count, days_since))
 }
 
+/// Diff hunks of every commit that changed `file` in `[since, until]`,
+/// newest commit first.
+///
+/// Follows the file across renames (`git log --follow`), so older hunks refer
+/// to whatever the file was called at the time. Merge commits contribute no
+/// hunks.
+///
+/// # Arguments
+///
+/// * `repo_path` - Path to git repository
+/// * `file` - Relative path to file from repository root
+/// * `since` - Unix timestamp of the window start
+/// * `until` - Unix timestamp of the window end
+pub fn file_hunk_history_at(
+    repo_path: &Path,
+    file: &str,
+    since: i64,
+    until: i64,
+) -> Result<Vec<Vec<DiffHunk>>> {
+    let since_arg = format!("--since={}", since);
+    let until_arg = format!("--until={}", until);
+    let output = git_at(
+        repo_path,
+        &[
+            "log",
+            "--follow",
+            "--format=COMMIT %H",
+            "-p",
+            "-U0",
+            "--no-color",
+            "--no-ext-diff",
+            &since_arg,
+            &until_arg,
+            "--",
+            file,
+        ],
+    )?;
+
+    let mut commits: Vec<Vec<DiffHunk>> = Vec::new();
+    for line in output.lines() {
+        if line.starts_with("COMMIT ") {
+            commits.push(Vec::new());
+        } else if let (Some(hunks), Some(hunk)) = (commits.last_mut(), parse_hunk_header(line)) {
+            hunks.push(hunk);
+        }
+    }
+    Ok(commits)
+}
+
+/// Diff hunks between `HEAD` and the working tree copy of `file`, i.e. its
+/// uncommitted changes
+pub fn working_tree_hunks_at(repo_path: &Path, file: &str) -> Result<Vec<DiffHunk>> {
+    let output = git_at(
+        repo_path,
+        &[
+            "diff",
+            "-U0",
+            "--no-color",
+            "--no-ext-diff",
+            "HEAD",
+            "--",
+            file,
+        ],
+    )?;
+    Ok(output.lines().filter_map(parse_hunk_header).collect())
+}
+
+/// Parse a unified diff hunk header (`@@ -12,3 +12,0 @@ fn context`).
+///
+/// An omitted length means 1. With `-U0` every line starting with `@@` is a
+/// header: content lines start with `+`, `-`, or `\`.
+pub fn parse_hunk_header(line: &str) -> Option<DiffHunk> {
+    let rest = line.strip_prefix("@@ -")?;
+    let (ranges, _) = rest.split_once(" @@")?;
+    let (old, new) = ranges.split_once(" +")?;
+    let range = |r: &str| -> Option<(u32, u32)> {
+        match r.split_once(',') {
+            Some((start, len)) => Some((start.parse().ok()?, len.parse().ok()?)),
+            None => Some((r.parse().ok()?, 1)),
+        }
+    };
+    let (old_start, old_len) = range(old)?;
+    let (new_start, new_len) = range(new)?;
+    Some(DiffHunk {
+        old_start,
+        old_len,
+        new_start,
+        new_len,
+    })
+}
+
 /// Count how many commits touched a file in the last 30 days
 ///
 /// Counts commits relative to a specific timestamp (typically the commit timestamp),
@@ -1311,4 +1509,40 @@ mod tests {
             "should return None when not in a git repo"
         );
     }
+
+    #[test]
+    fn test_parse_hunk_header() {
+        assert_eq!(
+            parse_hunk_header("@@ -12,3 +14,0 @@ fn handle() {"),
+            Some(DiffHunk {
+                old_start: 12,
+                old_len: 3,
+                new_start: 14,
+                new_len: 0,
+            })
+        );
+        // Omitted lengths are 1
+        assert_eq!(
+            parse_hunk_header("@@ -7 +7 @@"),
+            Some(DiffHunk {
+                old_start: 7,
+                old_len: 1,
+                new_start: 7,
+                new_len: 1,
+            })
+        );
+        assert_eq!(parse_hunk_header("+@@ -1 +1 @@"), None);
+        assert_eq!(parse_hunk_header("diff --git a/x.ts b/x.ts"), None);
+    }
+
+    #[test]
+    fn test_parse_changed_files() {
+        // NUL-separated, so names with spaces or newlines come through intact
+        let output = "src/api.ts\0src/new name.ts\0weird\nname.go\0";
+        assert_eq!(
+            parse_changed_files(output),
+            vec!["src/api.ts", "src/new name.ts", "weird\nname.go"]
+        );
+        assert!(parse_changed_files("").is_empty());
+    }
 }
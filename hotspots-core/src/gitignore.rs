@@ -0,0 +1,338 @@
+//! Gitignore-syntax pattern files (`.hotspotsignore`, `.gitignore`)
+//!
+//! Follows git's rules:
+//! - Blank lines and `#` comments are skipped; `\#` and `\!` escape a leading
+//!   `#` or `!`
+//! - `!` re-includes a path an earlier pattern ignored; the last matching
+//!   pattern wins
+//! - A trailing `/` matches directories only
+//! - A pattern with a `/` at the start or in the middle is anchored to the
+//!   file's directory; otherwise it matches at any depth
+//! - `*` and `?` stop at `/`, `**` crosses it
+//! - A file under an ignored directory cannot be re-included
+
+use anyhow::{Context, Result};
+use globset::{GlobBuilder, GlobMatcher};
+use std::path::{Path, PathBuf};
+
+/// One pattern line
+#[derive(Debug, Clone)]
+struct Rule {
+    matcher: GlobMatcher,
+    negated: bool,
+    dir_only: bool,
+}
+
+/// Patterns of one ignore file, matched against paths relative to the file's
+/// directory
+#[derive(Debug, Clone, Default)]
+pub struct IgnoreRules {
+    rules: Vec<Rule>,
+}
+
+impl IgnoreRules {
+    /// Parse the contents of an ignore file
+    pub fn parse(content: &str) -> Result<Self> {
+        let mut rules = Vec::new();
+        for line in content.lines() {
+            if let Some(rule) = parse_line(line)? {
+                rules.push(rule);
+            }
+        }
+        Ok(IgnoreRules { rules })
+    }
+
+    /// Read and parse the ignore file at `path`; `None` if there is none
+    pub fn load(path: &Path) -> Result<Option<Self>> {
+        if !path.is_file() {
+            return Ok(None);
+        }
+        let content = std::fs::read_to_string(path)
+            .with_context(|| format!("failed to read {}", path.display()))?;
+        IgnoreRules::parse(&content)
+            .with_context(|| format!("invalid pattern in {}", path.display()))
+            .map(Some)
+    }
+
+    /// Whether the file at relative `path` is ignored, by a pattern matching
+    /// it or one of its parent directories
+    pub fn is_ignored(&self, path: &Path) -> bool {
+        let mut prefix = PathBuf::new();
+        let mut components = path.components().peekable();
+        while let Some(component) = components.next() {
+            prefix.push(component);
+            let is_dir = components.peek().is_some();
+            if self.verdict(&prefix, is_dir) == Some(true) {
+                return true;
+            }
+        }
+        false
+    }
+
+    /// `Some(true)` if the last pattern matching `path` ignores it,
+    /// `Some(false)` if it re-includes it, `None` if none match
+    pub fn verdict(&self, path: &Path, is_dir: bool) -> Option<bool> {
+        self.rules
+            .iter()
+            .rev()
+            .find(|r| (is_dir || !r.dir_only) && r.matcher.is_match(path))
+            .map(|r| !r.negated)
+    }
+}
+
+/// A `.gitignore` in effect during a walk
+#[derive(Debug)]
+struct GitignoreFile {
+    /// Walked directory the patterns apply below: the file's own, or the
+    /// walk's start for a file above it
+    base: PathBuf,
+    /// `base` relative to the file's directory (empty unless above the start)
+    prefix: PathBuf,
+    rules: IgnoreRules,
+}
+
+/// The `.gitignore` files in effect during a directory walk, outermost first.
+/// A deeper file's patterns take precedence over a shallower one's.
+/// Unreadable or invalid files are skipped with a warning, as git skips bad
+/// patterns.
+#[derive(Debug, Default)]
+pub struct GitignoreStack {
+    enabled: bool,
+    files: Vec<GitignoreFile>,
+}
+
+impl GitignoreStack {
+    /// Stack for a walk starting at `dir`: the `.gitignore` files in `dir` and
+    /// its ancestors up to the enclosing repository's root, plus that
+    /// repository's `.git/info/exclude`. Outside a repository only `dir`'s own
+    /// `.gitignore` applies. With `enabled` false nothing is ever ignored.
+    pub fn for_walk(dir: &Path, enabled: bool) -> Self {
+        let mut stack = GitignoreStack {
+            enabled,
+            files: Vec::new(),
+        };
+        if !enabled {
+            return stack;
+        }
+        let Ok(absolute) = std::fs::canonicalize(dir) else {
+            stack.enter(dir);
+            return stack;
+        };
+
+        // Innermost first while walking up; reversed below
+        let mut in_repo = false;
+        for ancestor in absolute.ancestors() {
+            let prefix = absolute.strip_prefix(ancestor).unwrap_or(Path::new(""));
+            let mut push = |path: PathBuf| {
+                if let Some(rules) = load_or_warn(&path) {
+                    stack.files.push(GitignoreFile {
+                        base: dir.to_path_buf(),
+                        prefix: prefix.to_path_buf(),
+                        rules,
+                    });
+                }
+            };
+            push(ancestor.join(".gitignore"));
+            if ancestor.join(".git").exists() {
+                push(ancestor.join(".git").join("info").join("exclude"));
+                in_repo = true;
+                break;
+            }
+        }
+        if !in_repo {
+            stack.files.retain(|f| f.prefix.as_os_str().is_empty());
+        }
+        stack.files.reverse();
+        stack
+    }
+
+    /// Push `dir`'s `.gitignore` before walking into it. Returns whether one
+    /// was pushed, to pass to [`GitignoreStack::leave`] afterwards.
+    pub fn enter(&mut self, dir: &Path) -> bool {
+        if !self.enabled {
+            return false;
+        }
+        match load_or_warn(&dir.join(".gitignore")) {
+            Some(rules) => {
+                self.files.push(GitignoreFile {
+                    base: dir.to_path_buf(),
+                    prefix: PathBuf::new(),
+                    rules,
+                });
+                true
+            }
+            None => false,
+        }
+    }
+
+    /// Pop what the matching [`GitignoreStack::enter`] pushed
+    pub fn leave(&mut self, entered: bool) {
+        if entered {
+            self.files.pop();
+        }
+    }
+
+    /// Whether the walked entry at `path` is ignored. Its parent directories
+    /// are not checked: the walk never enters an ignored directory.
+    pub fn is_ignored(&self, path: &Path, is_dir: bool) -> bool {
+        self.files.iter().rev().find_map(|f| {
+            let relative = path.strip_prefix(&f.base).ok()?;
+            f.rules.verdict(&f.prefix.join(relative), is_dir)
+        }) == Some(true)
+    }
+}
+
+fn load_or_warn(path: &Path) -> Option<IgnoreRules> {
+    IgnoreRules::load(path).unwrap_or_else(|e| {
+        eprintln!("warning: skipping {}: {:#}", path.display(), e);
+        None
+    })
+}
+
+fn parse_line(line: &str) -> Result<Option<Rule>> {
+    let mut pattern = line.trim_end();
+    if pattern.is_empty() || pattern.starts_with('#') {
+        return Ok(None);
+    }
+    let negated = pattern.starts_with('!');
+    if negated {
+        pattern = &pattern[1..];
+    } else if pattern.starts_with("\\#") || pattern.starts_with("\\!") {
+        pattern = &pattern[1..];
+    }
+    let dir_only = pattern.ends_with('/');
+    let pattern = pattern.trim_end_matches('/');
+    if pattern.is_empty() {
+        return Ok(None);
+    }
+
+    let glob = match pattern.strip_prefix('/') {
+        Some(anchored) => anchored.to_string(),
+        None if pattern.contains('/') => pattern.to_string(),
+        None => format!("**/{}", pattern),
+    };
+    let matcher = GlobBuilder::new(&glob)
+        .literal_separator(true)
+        .build()
+        .with_context(|| format!("invalid pattern: {}", line))?
+        .compile_matcher();
+    Ok(Some(Rule {
+        matcher,
+        negated,
+        dir_only,
+    }))
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    fn ignored(rules: &str, path: &str) -> bool {
+        IgnoreRules::parse(rules)
+            .unwrap()
+            .is_ignored(Path::new(path))
+    }
+
+    #[test]
+    fn test_unanchored_pattern_matches_at_any_depth() {
+        let rules = "*.generated.ts\n";
+        assert!(ignored(rules, "api.generated.ts"));
+        assert!(ignored(rules, "src/client/api.generated.ts"));
+        assert!(!ignored(rules, "src/client/api.ts"));
+    }
+
+    #[test]
+    fn test_double_star_recursion() {
+        let rules = "src/**/fixtures\nlegacy/**\n";
+        assert!(ignored(rules, "src/fixtures/a.go"));
+        assert!(ignored(rules, "src/a/b/c/fixtures/deep/a.go"));
+        assert!(!ignored(rules, "lib/fixtures/a.go"));
+        assert!(ignored(rules, "legacy/a/b/old.py"));
+        assert!(!ignored(rules, "src/legacy/old.py"));
+    }
+
+    #[test]
+    fn test_single_star_stops_at_separator() {
+        let rules = "src/*.ts\n";
+        assert!(ignored(rules, "src/a.ts"));
+        assert!(!ignored(rules, "src/nested/a.ts"));
+    }
+
+    #[test]
+    fn test_anchored_and_directory_only_patterns() {
+        let rules = "/build\nthird_party/\n";
+        assert!(ignored(rules, "build/out.js"));
+        assert!(!ignored(rules, "src/build/out.js"));
+        assert!(ignored(rules, "lib/third_party/zlib/deflate.c"));
+        // A file named like a directory-only pattern is kept
+        assert!(!ignored(rules, "src/third_party"));
+    }
+
+    #[test]
+    fn test_negation_last_match_wins() {
+        let rules = "*.pb.go\n!keep.pb.go\n";
+        assert!(ignored(rules, "api/service.pb.go"));
+        assert!(!ignored(rules, "api/keep.pb.go"));
+
+        let rules = "!keep.pb.go\n*.pb.go\n";
+        assert!(ignored(rules, "api/keep.pb.go"));
+    }
+
+    #[test]
+    fn test_negation_cannot_reinclude_under_ignored_directory() {
+        let rules = "gen/\n!gen/keep.ts\n";
+        assert!(ignored(rules, "gen/keep.ts"));
+
+        // Ignoring the contents rather than the directory leaves room to re-include
+        let rules = "gen/*\n!gen/keep.ts\n";
+        assert!(!ignored(rules, "gen/keep.ts"));
+        assert!(ignored(rules, "gen/other.ts"));
+    }
+
+    #[test]
+    fn test_comments_blank_lines_and_escapes() {
+        let rules = "# generated code\n\n\\#weird.ts\n\\!bang.ts\n";
+        assert!(!ignored(rules, "generated/code.ts"));
+        assert!(ignored(rules, "#weird.ts"));
+        assert!(ignored(rules, "src/!bang.ts"));
+    }
+
+    #[test]
+    fn test_stack_applies_repository_gitignore_above_walk_start() {
+        let repo = tempfile::tempdir().unwrap();
+        std::fs::create_dir_all(repo.path().join(".git").join("info")).unwrap();
+        std::fs::write(repo.path().join(".gitignore"), "/src/gen/\n").unwrap();
+        std::fs::write(
+            repo.path().join(".git").join("info").join("exclude"),
+            "*.local.ts\n",
+        )
+        .unwrap();
+        let src = repo.path().join("src");
+        std::fs::create_dir_all(&src).unwrap();
+
+        let stack = GitignoreStack::for_walk(&src, true);
+        assert!(stack.is_ignored(&src.join("gen"), true));
+        assert!(stack.is_ignored(&src.join("app.local.ts"), false));
+        assert!(!stack.is_ignored(&src.join("app.ts"), false));
+        assert!(!stack.is_ignored(&src.join("lib").join("gen"), true));
+
+        let disabled = GitignoreStack::for_walk(&src, false);
+        assert!(!disabled.is_ignored(&src.join("gen"), true));
+    }
+
+    #[test]
+    fn test_stack_ignores_gitignore_above_start_outside_repository() {
+        let dir = tempfile::tempdir().unwrap();
+        std::fs::write(dir.path().join(".gitignore"), "*.ts\n").unwrap();
+        let src = dir.path().join("src");
+        std::fs::create_dir_all(&src).unwrap();
+
+        let stack = GitignoreStack::for_walk(&src, true);
+        assert!(!stack.is_ignored(&src.join("app.ts"), false));
+    }
+
+    #[test]
+    fn test_invalid_pattern_is_an_error() {
+        assert!(IgnoreRules::parse("src/[a-\n").is_err());
+    }
+}
@@ -0,0 +1,241 @@
+//! GitLab Code Quality output (`--format gitlab`)
+//!
+//! GitLab merge requests show the issues in a `gl-code-quality-report.json`
+//! artifact. Each function gets one issue per metric at or above its
+//! per-metric threshold (the checks `--format junit` fails, config key
+//! `sarif`); metrics whose level is `none` and suppressed functions raise
+//! none. An issue's severity is the function's severity level:
+//!   ok → info, low → minor, medium → major, high → critical,
+//!   critical → blocker
+//!
+//! GitLab tracks an issue across pipelines by its fingerprint, a hash of the
+//! file, the function's owner and name, and the rule. It does not depend on
+//! the line or the metric's value, so an issue keeps its identity while the
+//! function moves or gets worse.
+//!
+//! Global invariants enforced:
+//! - Deterministic output ordering (follows the input report order, then
+//!   rule order)
+//! - Fingerprints are stable across runs and platforms
+
+use crate::junit::breaches;
+use crate::report::FunctionRiskReport;
+use crate::sarif::MetricRules;
+use crate::severity::{Severity, SeverityScale};
+use serde::Serialize;
+use std::path::Path;
+
+/// One Code Quality issue
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+pub struct CodeQualityIssue {
+    pub description: String,
+    /// Rule id, as in SARIF: `hotspots/<metric>`
+    pub check_name: String,
+    pub fingerprint: String,
+    /// `info`, `minor`, `major`, `critical`, or `blocker`
+    pub severity: &'static str,
+    pub location: IssueLocation,
+}
+
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+pub struct IssueLocation {
+    /// Path relative to the repository root
+    pub path: String,
+    pub lines: IssueLines,
+}
+
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+pub struct IssueLines {
+    pub begin: u32,
+}
+
+/// GitLab's name for a severity level
+fn gitlab_severity(severity: Severity) -> &'static str {
+    match severity {
+        Severity::Ok => "info",
+        Severity::Low => "minor",
+        Severity::Medium => "major",
+        Severity::High => "critical",
+        Severity::Critical => "blocker",
+    }
+}
+
+/// 64-bit FNV-1a, as 16 hex digits. std's `DefaultHasher` may change
+/// between Rust releases, which would reopen every tracked issue.
+fn fnv1a(parts: &[&str]) -> String {
+    let mut hash: u64 = 0xcbf2_9ce4_8422_2325;
+    for part in parts {
+        // NUL separates the parts, so ("ab", "c") and ("a", "bc") differ
+        for &byte in part.as_bytes().iter().chain(&[0]) {
+            hash ^= u64::from(byte);
+            hash = hash.wrapping_mul(0x0000_0100_0000_01b3);
+        }
+    }
+    format!("{hash:016x}")
+}
+
+/// Issues for the threshold breaches in `reports`. Paths are relative to
+/// `base` when they fall under it.
+pub fn code_quality_issues(
+    reports: &[FunctionRiskReport],
+    base: &Path,
+    rules: &MetricRules,
+    scale: &SeverityScale,
+) -> Vec<CodeQualityIssue> {
+    let mut issues = Vec::new();
+    for report in reports {
+        let path = crate::treemap::relative_path(&report.file, base);
+        let severity = gitlab_severity(scale.severity(report));
+        let owner = report.owner.as_deref().unwrap_or("");
+        for breach in breaches(report, rules) {
+            let check_name = format!("hotspots/{}", breach.metric);
+            issues.push(CodeQualityIssue {
+                description: format!(
+                    "{} has {} {} (threshold {})",
+                    report.function, breach.metric, breach.value, breach.threshold
+                ),
+                fingerprint: fnv1a(&[&path, owner, &report.function, &check_name]),
+                check_name,
+                severity,
+                location: IssueLocation {
+                    path: path.clone(),
+                    lines: IssueLines { begin: report.line },
+                },
+            });
+        }
+    }
+    issues
+}
+
+/// Render the issues for `reports` as a Code Quality report: a
+/// pretty-printed JSON array, `[]` when nothing breaches a threshold
+pub fn render_gitlab(
+    reports: &[FunctionRiskReport],
+    base: &Path,
+    rules: &MetricRules,
+    scale: &SeverityScale,
+) -> String {
+    let issues = code_quality_issues(reports, base, rules, scale);
+    serde_json::to_string_pretty(&issues).unwrap_or_else(|_| "[]".to_string())
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::language::Language;
+    use crate::report::{MetricsReport, RiskReport};
+    use crate::risk::RiskBand;
+
+    fn make_report(function: &str, line: u32, cc: u32, fo: u32, lrs: f64) -> FunctionRiskReport {
+        FunctionRiskReport {
+            file: "/repo/src/a.ts".to_string(),
+            function: function.to_string(),
+            owner: None,
+            line,
+            language: Language::TypeScript,
+            metrics: MetricsReport {
+                cc,
+                cognitive: 0,
+                nd: 1,
+                fo,
+                fi: 0,
+                ns: 0,
+                loc: 10,
+                signature_complexity: 0,
+                params: 0,
+                guard_clauses: 0,
+                max_condition_ops: 0,
+                halstead: None,
+                maintainability: None,
+                sloc: None,
+                comment_lines: None,
+                blank_lines: None,
+                nd_line: None,
+                cc_lines: None,
+                is_async: false,
+                await_in_loop: 0,
+                ns_breakdown: None,
+            },
+            risk: RiskReport {
+                r_cc: 0.0,
+                r_nd: 0.0,
+                r_fo: 0.0,
+                r_ns: 0.0,
+            },
+            lrs,
+            band: RiskBand::High,
+            risk_score: None,
+            suppression_reason: None,
+            patterns: vec![],
+            pattern_details: None,
+            callees: vec![],
+            explanation: None,
+            arrow_depth: 0,
+            aliases: vec![],
+            structure: None,
+            cc_breakdown: None,
+            is_public: false,
+            file_top_level_decisions: 0,
+        }
+    }
+
+    fn issues(reports: &[FunctionRiskReport]) -> Vec<CodeQualityIssue> {
+        code_quality_issues(
+            reports,
+            Path::new("/repo"),
+            &MetricRules::default(),
+            &SeverityScale::default(),
+        )
+    }
+
+    #[test]
+    fn test_one_issue_per_breached_metric() {
+        let reports = vec![
+            make_report("small", 1, 2, 1, 1.0),
+            make_report("handler", 20, 30, 25, 9.5),
+        ];
+        let issues = issues(&reports);
+        let checks: Vec<&str> = issues.iter().map(|i| i.check_name.as_str()).collect();
+        assert_eq!(checks, ["hotspots/cc", "hotspots/fo"]);
+
+        let cc = &issues[0];
+        assert_eq!(cc.description, "handler has cc 30 (threshold 15)");
+        assert_eq!(cc.severity, "blocker");
+        assert_eq!(cc.location.path, "src/a.ts");
+        assert_eq!(cc.location.lines.begin, 20);
+        assert_eq!(cc.fingerprint.len(), 16);
+        assert_ne!(cc.fingerprint, issues[1].fingerprint);
+    }
+
+    #[test]
+    fn test_fingerprint_ignores_line_and_value() {
+        let before = issues(&[make_report("handler", 20, 30, 1, 9.5)]);
+        let after = issues(&[make_report("handler", 42, 45, 1, 9.5)]);
+        assert_eq!(before[0].fingerprint, after[0].fingerprint);
+
+        let mut method = make_report("handler", 20, 30, 1, 9.5);
+        method.owner = Some("Server".to_string());
+        assert_ne!(issues(&[method])[0].fingerprint, before[0].fingerprint);
+    }
+
+    #[test]
+    fn test_severity_follows_the_severity_scale() {
+        for (lrs, expected) in [
+            (1.0, "info"),
+            (2.0, "minor"),
+            (4.0, "major"),
+            (7.0, "critical"),
+            (9.0, "blocker"),
+        ] {
+            let issues = issues(&[make_report("f", 1, 30, 1, lrs)]);
+            assert_eq!(issues[0].severity, expected, "lrs {lrs}");
+        }
+    }
+
+    #[test]
+    fn test_fnv1a_separates_parts() {
+        assert_ne!(fnv1a(&["ab", "c"]), fnv1a(&["a", "bc"]));
+        // Pinned, so a change to the hash is caught before it reopens issues
+        assert_eq!(fnv1a(&[]), "cbf29ce484222325");
+    }
+}
@@ -0,0 +1,359 @@
+//! God functions (`analyze --god-functions`)
+//!
+//! A god function does too much at once: it is long, branches a lot, and
+//! calls out to many other functions. Any one of those alone is common and
+//! often harmless — a long table of assignments, a flat `switch`, a function
+//! that wires collaborators together — so a god function is flagged only when
+//! it crosses several size and branching thresholds together.
+//!
+//! The rule is a set of criteria (a metric and its threshold, met at or
+//! above the threshold) and how many of them a function must meet. The
+//! default criteria are LOC ≥ 60, CC ≥ 15, and FO ≥ 10, all required; the
+//! `god_functions` config section changes the thresholds, adds ND, NS, or
+//! cognitive complexity, and lowers the number required. Each match is
+//! reported with the criteria it met, so it is clear why it was flagged.
+//!
+//! Global invariants enforced:
+//! - Deterministic output ordering (criteria met descending, LOC descending,
+//!   file, line, function)
+
+use crate::report::FunctionRiskReport;
+use anyhow::{Context, Result};
+use serde::Serialize;
+use std::path::Path;
+
+/// Metrics a criterion can use, in report order
+pub const GOD_FUNCTION_METRICS: &[&str] = &["loc", "cc", "fo", "nd", "ns", "cognitive"];
+
+/// One threshold of the rule: met when the metric is at or above it
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+pub struct Criterion {
+    pub metric: String,
+    pub threshold: u32,
+}
+
+/// What makes a god function: its criteria (in [`GOD_FUNCTION_METRICS`]
+/// order) and how many a function must meet
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+pub struct GodFunctionRule {
+    pub criteria: Vec<Criterion>,
+    /// Between 1 and the number of criteria
+    pub min_matched: usize,
+}
+
+impl Default for GodFunctionRule {
+    fn default() -> Self {
+        let criteria = [("loc", 60), ("cc", 15), ("fo", 10)]
+            .iter()
+            .map(|&(metric, threshold)| Criterion {
+                metric: metric.to_string(),
+                threshold,
+            })
+            .collect::<Vec<_>>();
+        GodFunctionRule {
+            min_matched: criteria.len(),
+            criteria,
+        }
+    }
+}
+
+impl GodFunctionRule {
+    /// The criteria in words: "loc >= 60, cc >= 15, fo >= 10"
+    pub fn describe(&self) -> String {
+        self.criteria
+            .iter()
+            .map(|c| format!("{} >= {}", c.metric, c.threshold))
+            .collect::<Vec<_>>()
+            .join(", ")
+    }
+
+    /// "all of" or "at least N of", for the criteria list
+    fn quantifier(&self) -> String {
+        if self.min_matched == self.criteria.len() {
+            "all of".to_string()
+        } else {
+            format!("at least {} of", self.min_matched)
+        }
+    }
+}
+
+/// A criterion a function met, with the function's value
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+pub struct MetCriterion {
+    pub metric: String,
+    pub value: u32,
+    pub threshold: u32,
+}
+
+/// A function that met enough criteria
+#[derive(Debug, Clone, PartialEq, Serialize)]
+pub struct GodFunction {
+    /// Path relative to the analysis base
+    pub file: String,
+    pub function: String,
+    pub line: u32,
+    pub lrs: f64,
+    pub met: Vec<MetCriterion>,
+}
+
+/// God functions of an analyzed set
+#[derive(Debug, Clone, PartialEq, Serialize)]
+pub struct GodFunctionReport {
+    /// Number of analyzed functions
+    pub functions: usize,
+    pub rule: GodFunctionRule,
+    pub god_functions: Vec<GodFunction>,
+}
+
+/// Value of `metric` (one of [`GOD_FUNCTION_METRICS`]) for a function
+fn metric_value(report: &FunctionRiskReport, metric: &str) -> u32 {
+    let m = &report.metrics;
+    match metric {
+        "loc" => m.loc,
+        "cc" => m.cc,
+        "fo" => m.fo,
+        "nd" => m.nd,
+        "ns" => m.ns,
+        "cognitive" => m.cognitive,
+        _ => 0,
+    }
+}
+
+/// The functions of `reports` that meet `rule`. Paths are relative to
+/// `base` when they fall under it.
+pub fn find_god_functions(
+    reports: &[FunctionRiskReport],
+    rule: &GodFunctionRule,
+    base: &Path,
+) -> GodFunctionReport {
+    let mut god_functions: Vec<GodFunction> = reports
+        .iter()
+        .filter_map(|r| {
+            let met: Vec<MetCriterion> = rule
+                .criteria
+                .iter()
+                .map(|c| MetCriterion {
+                    metric: c.metric.clone(),
+                    value: metric_value(r, &c.metric),
+                    threshold: c.threshold,
+                })
+                .filter(|m| m.value >= m.threshold)
+                .collect();
+            (met.len() >= rule.min_matched).then(|| GodFunction {
+                file: crate::treemap::relative_path(&r.file, base),
+                function: r.function.clone(),
+                line: r.line,
+                lrs: r.lrs,
+                met,
+            })
+        })
+        .collect();
+    god_functions.sort_by(|a, b| {
+        b.met
+            .len()
+            .cmp(&a.met.len())
+            .then_with(|| loc_of(b).cmp(&loc_of(a)))
+            .then_with(|| (&a.file, a.line, &a.function).cmp(&(&b.file, b.line, &b.function)))
+    });
+
+    GodFunctionReport {
+        functions: reports.len(),
+        rule: rule.clone(),
+        god_functions,
+    }
+}
+
+/// LOC of a match, for ordering; 0 when LOC is not one of its met criteria
+fn loc_of(g: &GodFunction) -> u32 {
+    g.met
+        .iter()
+        .find(|m| m.metric == "loc")
+        .map_or(0, |m| m.value)
+}
+
+/// Render the report as JSON
+pub fn render_god_functions_json(report: &GodFunctionReport) -> Result<String> {
+    serde_json::to_string_pretty(report).context("failed to serialize god functions")
+}
+
+/// Render the report as text: a heading with the rule, then one
+/// `path:line  name  criteria met` row per god function
+pub fn render_god_functions_text(report: &GodFunctionReport) -> String {
+    let rule = &report.rule;
+    if report.god_functions.is_empty() {
+        return format!(
+            "No god functions: no function meets {} {} across {} analyzed function(s).\n",
+            rule.quantifier(),
+            rule.describe(),
+            report.functions
+        );
+    }
+    let mut out = format!(
+        "{} god function(s) meeting {} {} across {} analyzed function(s)\n\n",
+        report.god_functions.len(),
+        rule.quantifier(),
+        rule.describe(),
+        report.functions
+    );
+    let locations: Vec<String> = report
+        .god_functions
+        .iter()
+        .map(|g| format!("{}:{}", g.file, g.line))
+        .collect();
+    let width = locations.iter().map(String::len).max().unwrap_or(0);
+    for (g, location) in report.god_functions.iter().zip(&locations) {
+        let met = g
+            .met
+            .iter()
+            .map(|m| format!("{} {} >= {}", m.metric, m.value, m.threshold))
+            .collect::<Vec<_>>()
+            .join(", ");
+        out.push_str(&format!(
+            "  {location:<width$}  {}  LRS {:.2}  {}\n",
+            g.function, g.lrs, met
+        ));
+    }
+    out
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::language::Language;
+    use crate::report::{MetricsReport, RiskReport};
+    use crate::risk::RiskBand;
+
+    fn make_report(function: &str, line: u32, loc: u32, cc: u32, fo: u32) -> FunctionRiskReport {
+        FunctionRiskReport {
+            file: "/repo/src/a.ts".to_string(),
+            function: function.to_string(),
+            owner: None,
+            line,
+            language: Language::TypeScript,
+            metrics: MetricsReport {
+                cc,
+                cognitive: 0,
+                nd: 2,
+                fo,
+                fi: 0,
+                ns: 0,
+                loc,
+                signature_complexity: 0,
+                params: 0,
+                guard_clauses: 0,
+                max_condition_ops: 0,
+                halstead: None,
+                maintainability: None,
+                sloc: None,
+                comment_lines: None,
+                blank_lines: None,
+                nd_line: None,
+                cc_lines: None,
+                is_async: false,
+                await_in_loop: 0,
+                ns_breakdown: None,
+            },
+            risk: RiskReport {
+                r_cc: 0.0,
+                r_nd: 0.0,
+                r_fo: 0.0,
+                r_ns: 0.0,
+            },
+            lrs: 5.0,
+            band: RiskBand::Moderate,
+            risk_score: None,
+            suppression_reason: None,
+            patterns: vec![],
+            pattern_details: None,
+            callees: vec![],
+            explanation: None,
+            arrow_depth: 0,
+            aliases: vec![],
+            structure: None,
+            cc_breakdown: None,
+            is_public: false,
+            file_top_level_decisions: 0,
+        }
+    }
+
+    fn reports() -> Vec<FunctionRiskReport> {
+        vec![
+            make_report("long_only", 1, 200, 2, 3),
+            make_report("god", 300, 80, 20, 12),
+            make_report("at_thresholds", 500, 60, 15, 10),
+            make_report("long_and_calls", 700, 90, 4, 15),
+        ]
+    }
+
+    #[test]
+    fn test_all_criteria_required_by_default() {
+        let report =
+            find_god_functions(&reports(), &GodFunctionRule::default(), Path::new("/repo"));
+        let names: Vec<&str> = report
+            .god_functions
+            .iter()
+            .map(|g| g.function.as_str())
+            .collect();
+        assert_eq!(names, ["god", "at_thresholds"]);
+        assert_eq!(
+            report.god_functions[0].met,
+            vec![
+                MetCriterion {
+                    metric: "loc".to_string(),
+                    value: 80,
+                    threshold: 60
+                },
+                MetCriterion {
+                    metric: "cc".to_string(),
+                    value: 20,
+                    threshold: 15
+                },
+                MetCriterion {
+                    metric: "fo".to_string(),
+                    value: 12,
+                    threshold: 10
+                },
+            ]
+        );
+
+        let text = render_god_functions_text(&report);
+        assert!(text.starts_with(
+            "2 god function(s) meeting all of loc >= 60, cc >= 15, fo >= 10 across 4 analyzed function(s)\n\n"
+        ));
+        assert!(text
+            .contains("  src/a.ts:300  god  LRS 5.00  loc 80 >= 60, cc 20 >= 15, fo 12 >= 10\n"));
+    }
+
+    #[test]
+    fn test_fewer_criteria_required() {
+        let rule = GodFunctionRule {
+            min_matched: 2,
+            ..GodFunctionRule::default()
+        };
+        let report = find_god_functions(&reports(), &rule, Path::new("/repo"));
+        let names: Vec<(&str, usize)> = report
+            .god_functions
+            .iter()
+            .map(|g| (g.function.as_str(), g.met.len()))
+            .collect();
+        // Three criteria met come first, then by LOC
+        assert_eq!(
+            names,
+            [("god", 3), ("at_thresholds", 3), ("long_and_calls", 2)]
+        );
+        assert!(render_god_functions_text(&report).contains("meeting at least 2 of loc >= 60"));
+    }
+
+    #[test]
+    fn test_no_god_functions() {
+        let report = find_god_functions(
+            &[make_report("small", 1, 5, 1, 0)],
+            &GodFunctionRule::default(),
+            Path::new("/repo"),
+        );
+        assert_eq!(
+            render_god_functions_text(&report),
+            "No god functions: no function meets all of loc >= 60, cc >= 15, fo >= 10 across 1 analyzed function(s).\n"
+        );
+    }
+}
@@ -0,0 +1,549 @@
+//! GraphQL resolver map (`--mode resolvers`).
+//!
+//! Resolvers are ordinary functions, so this module adds no metrics of its own.
+//! It finds which analyzed functions implement which GraphQL `Type.field` and
+//! groups their existing risk reports by type:
+//!
+//! - JS/TS: object literals shaped like a resolver map
+//!   (`{ Query: { user() {...}, users: async () => {...} } }`) bound to a
+//!   variable whose name contains `resolver`, or any top-level object in a file
+//!   matching `--resolver-glob`.
+//! - Go: gqlgen-style methods (`func (r *queryResolver) User(...)` →
+//!   `Query.user`).
+//!
+//! When a schema is given, each field records whether the SDL declares it.
+
+use crate::language::span::span_with_location;
+use crate::language::Language;
+use crate::report::{FunctionRiskReport, MetricsReport};
+use crate::risk::RiskBand;
+use anyhow::{Context, Result};
+use serde::{Deserialize, Serialize};
+use std::collections::{BTreeMap, BTreeSet, HashMap, HashSet};
+use std::path::Path;
+use swc_common::{sync::Lrc, SourceMap, Span, Spanned};
+use swc_ecma_ast::*;
+use swc_ecma_visit::{Visit, VisitWith};
+
+#[derive(Debug, Clone, Serialize, Deserialize, PartialEq)]
+#[serde(rename_all = "snake_case")]
+pub struct ResolverMap {
+    pub types: Vec<ResolverType>,
+}
+
+#[derive(Debug, Clone, Serialize, Deserialize, PartialEq)]
+#[serde(rename_all = "snake_case")]
+pub struct ResolverType {
+    #[serde(rename = "type")]
+    pub type_name: String,
+    pub max_lrs: f64,
+    pub total_lrs: f64,
+    pub fields: Vec<ResolverField>,
+}
+
+#[derive(Debug, Clone, Serialize, Deserialize, PartialEq)]
+#[serde(rename_all = "snake_case")]
+pub struct ResolverField {
+    pub field: String,
+    pub function: String,
+    pub file: String,
+    pub line: u32,
+    pub language: Language,
+    pub metrics: MetricsReport,
+    pub lrs: f64,
+    pub band: RiskBand,
+    /// Whether the schema declares `type.field`; absent when no schema was given.
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub in_schema: Option<bool>,
+}
+
+/// A `Type.field` binding found in source, covering lines `start_line..=end_line`.
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub struct ResolverBinding {
+    pub type_name: String,
+    pub field: String,
+    pub file: String,
+    pub start_line: u32,
+    pub end_line: u32,
+}
+
+/// Build the resolver map for every function in `reports`.
+///
+/// `resolver_glob` restricts scanning to matching files (relative to
+/// `source_root`) and treats every top-level object literal in them as a
+/// resolver map. `schema_sdl` is the GraphQL schema text, if any.
+pub fn compute_resolver_map(
+    source_root: &Path,
+    reports: &[FunctionRiskReport],
+    resolver_glob: Option<&str>,
+    schema_sdl: Option<&str>,
+) -> Result<ResolverMap> {
+    let matcher = resolver_glob
+        .map(|g| {
+            globset::Glob::new(g)
+                .map(|g| g.compile_matcher())
+                .with_context(|| format!("invalid --resolver-glob pattern: {g}"))
+        })
+        .transpose()?;
+
+    let mut bindings = Vec::new();
+    for path in crate::collect_source_files(source_root, true)? {
+        let Some(language) = Language::from_path(&path) else {
+            continue;
+        };
+        let rel = path.strip_prefix(source_root).unwrap_or(&path);
+        let in_glob = match &matcher {
+            Some(m) => m.is_match(rel) || m.is_match(&path),
+            None => false,
+        };
+        if matcher.is_some() && !in_glob {
+            continue;
+        }
+        let source = std::fs::read_to_string(&path)
+            .with_context(|| format!("failed to read {}", path.display()))?;
+        // Lines must match the analyzed functions' (see `normalize_source`)
+        let source = crate::language::span::normalize_source(&source);
+        let file = path.to_string_lossy().to_string();
+        bindings.extend(extract_bindings(&source, language, &file, in_glob));
+    }
+
+    let schema = schema_sdl.map(parse_schema_fields);
+    Ok(group_resolvers(reports, &bindings, schema.as_ref()))
+}
+
+/// Find resolver bindings in one source file.
+pub fn extract_bindings(
+    source: &str,
+    language: Language,
+    file: &str,
+    in_glob: bool,
+) -> Vec<ResolverBinding> {
+    match language {
+        Language::TypeScript
+        | Language::TypeScriptReact
+        | Language::JavaScript
+        | Language::JavaScriptReact => extract_ecmascript_bindings(source, file, in_glob),
+        Language::Go => extract_go_bindings(source, file),
+        _ => vec![],
+    }
+}
+
+fn extract_ecmascript_bindings(source: &str, file: &str, in_glob: bool) -> Vec<ResolverBinding> {
+    let cm: Lrc<SourceMap> = Default::default();
+    // Files that fail to parse are skipped here just as analysis skips them.
+    let Ok(module) = crate::parser::parse_source(source, &cm, file) else {
+        return vec![];
+    };
+    let file_name = Path::new(file)
+        .file_name()
+        .map(|n| n.to_string_lossy().to_lowercase())
+        .unwrap_or_default();
+    let mut collector = ResolverMapCollector {
+        source_map: &cm,
+        file,
+        any_object: in_glob,
+        default_export: in_glob || file_name.contains("resolver"),
+        bindings: Vec::new(),
+    };
+    module.visit_with(&mut collector);
+    collector.bindings
+}
+
+struct ResolverMapCollector<'a> {
+    source_map: &'a SourceMap,
+    file: &'a str,
+    /// Treat every variable-bound object literal as a resolver map.
+    any_object: bool,
+    /// Treat `export default { ... }` as a resolver map.
+    default_export: bool,
+    bindings: Vec<ResolverBinding>,
+}
+
+impl Visit for ResolverMapCollector<'_> {
+    fn visit_var_declarator(&mut self, decl: &VarDeclarator) {
+        if let (Pat::Ident(ident), Some(init)) = (&decl.name, &decl.init) {
+            let named_resolver = ident.id.sym.to_lowercase().contains("resolver");
+            if named_resolver || self.any_object {
+                if let Expr::Object(obj) = unwrap_expr(init) {
+                    self.collect_map(obj);
+                }
+            }
+        }
+        decl.visit_children_with(self);
+    }
+
+    fn visit_export_default_expr(&mut self, export: &ExportDefaultExpr) {
+        if self.default_export {
+            if let Expr::Object(obj) = unwrap_expr(&export.expr) {
+                self.collect_map(obj);
+            }
+        }
+        export.visit_children_with(self);
+    }
+}
+
+impl ResolverMapCollector<'_> {
+    /// Record `Type.field` for each `Type: { field... }` entry in `map`.
+    fn collect_map(&mut self, map: &ObjectLit) {
+        for prop in &map.props {
+            let PropOrSpread::Prop(prop) = prop else {
+                continue;
+            };
+            let Prop::KeyValue(kv) = &**prop else {
+                continue;
+            };
+            let Some(type_name) = prop_name(&kv.key) else {
+                continue;
+            };
+            // GraphQL type names are PascalCase; this skips config-like keys.
+            if !type_name.starts_with(|c: char| c.is_ascii_uppercase()) {
+                continue;
+            }
+            let Expr::Object(fields) = unwrap_expr(&kv.value) else {
+                continue;
+            };
+            for field in &fields.props {
+                let PropOrSpread::Prop(field) = field else {
+                    continue;
+                };
+                let (key, end) = match &**field {
+                    Prop::Method(m) => (&m.key, m.function.span),
+                    Prop::KeyValue(kv) if is_resolver_value(&kv.value) => {
+                        (&kv.key, kv.value.span())
+                    }
+                    _ => continue,
+                };
+                if let Some(field_name) = prop_name(key) {
+                    self.push(&type_name, field_name, key.span(), end);
+                }
+            }
+        }
+    }
+
+    /// Record a binding spanning from the field key through the end of its value.
+    fn push(&mut self, type_name: &str, field: String, key: Span, value: Span) {
+        self.bindings.push(ResolverBinding {
+            type_name: type_name.to_string(),
+            field,
+            file: self.file.to_string(),
+            start_line: span_with_location(key, self.source_map).start_line,
+            end_line: span_with_location(value, self.source_map).end_line,
+        });
+    }
+}
+
+/// Strip parentheses and TS type assertions (`as Resolvers`, `satisfies`, `as const`).
+fn unwrap_expr(expr: &Expr) -> &Expr {
+    match expr {
+        Expr::Paren(e) => unwrap_expr(&e.expr),
+        Expr::TsAs(e) => unwrap_expr(&e.expr),
+        Expr::TsSatisfies(e) => unwrap_expr(&e.expr),
+        Expr::TsConstAssertion(e) => unwrap_expr(&e.expr),
+        _ => expr,
+    }
+}
+
+/// A field value that holds resolver code: a function, or a subscription-style
+/// object (`{ subscribe() {...}, resolve() {...} }`).
+fn is_resolver_value(expr: &Expr) -> bool {
+    matches!(
+        unwrap_expr(expr),
+        Expr::Fn(_) | Expr::Arrow(_) | Expr::Object(_)
+    )
+}
+
+fn prop_name(key: &PropName) -> Option<String> {
+    match key {
+        PropName::Ident(ident) => Some(ident.sym.to_string()),
+        PropName::Str(s) => Some(s.value.to_atom_lossy().to_string()),
+        _ => None,
+    }
+}
+
+/// gqlgen methods: `func (r *queryResolver) CreateUser(...)` → `Query.createUser`.
+fn extract_go_bindings(source: &str, file: &str) -> Vec<ResolverBinding> {
+    let Ok(re) =
+        regex::Regex::new(r"(?m)^func\s*\(\s*\w*\s*\*?([a-z]\w*)Resolver\s*\)\s*([A-Z]\w*)\s*\(")
+    else {
+        return vec![];
+    };
+    re.captures_iter(source)
+        .map(|caps| {
+            let line = source[..caps.get(0).map_or(0, |m| m.start())]
+                .bytes()
+                .filter(|b| *b == b'\n')
+                .count() as u32
+                + 1;
+            ResolverBinding {
+                type_name: with_first_char(&caps[1], char::to_ascii_uppercase),
+                field: with_first_char(&caps[2], char::to_ascii_lowercase),
+                file: file.to_string(),
+                start_line: line,
+                end_line: line,
+            }
+        })
+        .collect()
+}
+
+fn with_first_char(s: &str, f: fn(&char) -> char) -> String {
+    let mut chars = s.chars();
+    match chars.next() {
+        Some(first) => std::iter::once(f(&first)).chain(chars).collect(),
+        None => String::new(),
+    }
+}
+
+/// Parse `(type, field)` pairs from GraphQL SDL.
+///
+/// Covers `type`, `extend type`, and `interface` definitions. Argument lists,
+/// descriptions, and comments are skipped; this is not a validating parser.
+pub fn parse_schema_fields(sdl: &str) -> BTreeSet<(String, String)> {
+    let cleaned = strip_sdl_noise(sdl);
+    let mut out = BTreeSet::new();
+    let (Ok(type_re), Ok(field_re)) = (
+        regex::Regex::new(r"\b(?:type|interface)\s+([A-Za-z_]\w*)[^{}]*\{([^{}]*)\}"),
+        regex::Regex::new(r"([A-Za-z_]\w*)\s*:"),
+    ) else {
+        return out;
+    };
+    for caps in type_re.captures_iter(&cleaned) {
+        for field in field_re.captures_iter(&caps[2]) {
+            out.insert((caps[1].to_string(), field[1].to_string()));
+        }
+    }
+    out
+}
+
+/// Drop comments, string/block descriptions, and parenthesised argument lists.
+fn strip_sdl_noise(sdl: &str) -> String {
+    let mut out = String::with_capacity(sdl.len());
+    let mut rest = sdl;
+    let mut paren_depth = 0usize;
+    while let Some(c) = rest.chars().next() {
+        if let Some(after) = rest.strip_prefix("\"\"\"") {
+            rest = after.find("\"\"\"").map_or("", |end| &after[end + 3..]);
+            continue;
+        }
+        match c {
+            '#' => {
+                rest = rest.find('\n').map_or("", |end| &rest[end..]);
+                continue;
+            }
+            '"' => {
+                let after = &rest[1..];
+                rest = after.find('"').map_or("", |end| &after[end + 1..]);
+                continue;
+            }
+            '(' => paren_depth += 1,
+            ')' => paren_depth = paren_depth.saturating_sub(1),
+            _ if paren_depth == 0 => out.push(c),
+            _ => {}
+        }
+        rest = &rest[c.len_utf8()..];
+    }
+    out
+}
+
+/// Attach each binding to the outermost function it covers and group by type.
+pub fn group_resolvers(
+    reports: &[FunctionRiskReport],
+    bindings: &[ResolverBinding],
+    schema: Option<&BTreeSet<(String, String)>>,
+) -> ResolverMap {
+    let mut by_file: HashMap<&str, Vec<&FunctionRiskReport>> = HashMap::new();
+    for report in reports {
+        by_file
+            .entry(report.file.as_str())
+            .or_default()
+            .push(report);
+    }
+
+    let mut seen = HashSet::new();
+    let mut by_type: BTreeMap<&str, Vec<ResolverField>> = BTreeMap::new();
+    for binding in bindings {
+        let Some(report) = by_file.get(binding.file.as_str()).and_then(|candidates| {
+            candidates
+                .iter()
+                .filter(|r| (binding.start_line..=binding.end_line).contains(&r.line))
+                .min_by(|a, b| {
+                    a.line
+                        .cmp(&b.line)
+                        .then_with(|| a.function.cmp(&b.function))
+                })
+        }) else {
+            continue;
+        };
+        if !seen.insert((report.file.as_str(), report.line, report.function.as_str())) {
+            continue;
+        }
+        by_type
+            .entry(binding.type_name.as_str())
+            .or_default()
+            .push(ResolverField {
+                field: binding.field.clone(),
+                function: report.function.clone(),
+                file: report.file.clone(),
+                line: report.line,
+                language: report.language,
+                metrics: report.metrics.clone(),
+                lrs: report.lrs,
+                band: report.band,
+                in_schema: schema
+                    .map(|s| s.contains(&(binding.type_name.clone(), binding.field.clone()))),
+            });
+    }
+
+    let mut types: Vec<ResolverType> = by_type
+        .into_iter()
+        .map(|(type_name, mut fields)| {
+            fields.sort_by(|a, b| {
+                b.lrs
+                    .partial_cmp(&a.lrs)
+                    .unwrap_or(std::cmp::Ordering::Equal)
+                    .then_with(|| a.field.cmp(&b.field))
+            });
+            ResolverType {
+                type_name: type_name.to_string(),
+                max_lrs: fields.first().map_or(0.0, |f| f.lrs),
+                total_lrs: fields.iter().map(|f| f.lrs).sum(),
+                fields,
+            }
+        })
+        .collect();
+    types.sort_by(|a, b| {
+        b.max_lrs
+            .partial_cmp(&a.max_lrs)
+            .unwrap_or(std::cmp::Ordering::Equal)
+            .then_with(|| a.type_name.cmp(&b.type_name))
+    });
+    ResolverMap { types }
+}
+
+pub fn render_resolver_map_text(map: &ResolverMap, top: Option<usize>) -> String {
+    let mut output = String::new();
+    output.push_str("GraphQL Resolver Map\n");
+    output.push_str(&"=".repeat(80));
+    output.push_str("\n\n");
+
+    if map.types.is_empty() {
+        output.push_str("No resolvers found.\n");
+        return output;
+    }
+
+    for ty in map.types.iter().take(top.unwrap_or(map.types.len())) {
+        output.push_str(&format!(
+            "{:<28} {:>3} resolver(s)  max LRS {:>6.2}  total {:>7.2}\n",
+            ty.type_name,
+            ty.fields.len(),
+            ty.max_lrs,
+            ty.total_lrs
+        ));
+        for field in &ty.fields {
+            let schema_note = match field.in_schema {
+                Some(false) => "  (not in schema)",
+                _ => "",
+            };
+            output.push_str(&format!(
+                "     {:<32} {}:{}  LRS {:>6.2}  CC {:<3} [{}]{}\n",
+                format!("{}.{}", ty.type_name, field.field),
+                field.file,
+                field.line,
+                field.lrs,
+                field.metrics.cc,
+                field.band.as_str(),
+                schema_note
+            ));
+        }
+        output.push('\n');
+    }
+    output.push_str("Types ranked by: highest resolver LRS\n");
+    output
+}
+
+pub fn render_resolver_map_json(map: &ResolverMap) -> Result<String> {
+    serde_json::to_string_pretty(map).context("failed to render resolver map JSON")
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn test_ecmascript_bindings_from_named_map() {
+        let src = r#"
+const config = { Query: { ignored() { return 1; } } };
+export const resolvers = {
+  Query: {
+    user(_parent, args) { return args.id; },
+    users: async () => [],
+  },
+  Mutation: {
+    createUser: function (_p, args) { return args; },
+  },
+  lowercase: { skipped() {} },
+};
+"#;
+        let bindings = extract_bindings(src, Language::TypeScript, "r.ts", false);
+        let names: Vec<String> = bindings
+            .iter()
+            .map(|b| format!("{}.{}", b.type_name, b.field))
+            .collect();
+        assert_eq!(
+            names,
+            vec!["Query.user", "Query.users", "Mutation.createUser"]
+        );
+        assert_eq!(bindings[0].start_line, 5);
+    }
+
+    #[test]
+    fn test_glob_files_accept_any_object() {
+        let src = "const map = { User: { name: (u) => u.name } };\n";
+        assert!(extract_bindings(src, Language::JavaScript, "a.js", false).is_empty());
+        assert_eq!(
+            extract_bindings(src, Language::JavaScript, "a.js", true).len(),
+            1
+        );
+    }
+
+    #[test]
+    fn test_go_gqlgen_bindings() {
+        let src = "package graph\n\nfunc (r *queryResolver) User(ctx context.Context) (*User, error) {\n\treturn nil, nil\n}\n\nfunc (r *userResolver) FullName(ctx context.Context) (string, error) {\n\treturn \"\", nil\n}\n\nfunc helper() {}\n";
+        let bindings = extract_bindings(src, Language::Go, "schema.resolvers.go", false);
+        assert_eq!(bindings.len(), 2);
+        assert_eq!(bindings[0].type_name, "Query");
+        assert_eq!(bindings[0].field, "user");
+        assert_eq!(bindings[0].start_line, 3);
+        assert_eq!(bindings[1].type_name, "User");
+        assert_eq!(bindings[1].field, "fullName");
+    }
+
+    #[test]
+    fn test_parse_schema_fields() {
+        let sdl = r#"
+# comment: not a field
+"""The root query"""
+type Query {
+  "Fetch one user"
+  user(id: ID!, filter: UserFilter): User
+  users: [User!]!
+}
+extend type Query { me: User }
+type User implements Node @key(fields: "id") {
+  id: ID!
+  name: String @deprecated(reason: "use fullName")
+}
+"#;
+        let fields = parse_schema_fields(sdl);
+        let expected: BTreeSet<(String, String)> = [
+            ("Query", "me"),
+            ("Query", "user"),
+            ("Query", "users"),
+            ("User", "id"),
+            ("User", "name"),
+        ]
+        .iter()
+        .map(|(t, f)| (t.to_string(), f.to_string()))
+        .collect();
+        assert_eq!(fields, expected);
+    }
+}
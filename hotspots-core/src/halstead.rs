@@ -0,0 +1,539 @@
+//! Halstead metrics from tree-sitter tokens
+//!
+//! Every token of a function — signature and body — is an operator or an
+//! operand. Operands are identifiers and literals, listed per language as
+//! node kinds that count as one token even where the grammar splits them
+//! (a string literal with escape sequences, for example). Every other token
+//! (keywords, operators, punctuation) is an operator. Comments and
+//! whitespace-only tokens such as Go's newline terminators do not count.
+//! Tokens with the same text are the same operator or operand.
+//!
+//! From n1 / n2 distinct and N1 / N2 total operators / operands:
+//!
+//! - volume V = (N1 + N2) × log2(n1 + n2)
+//! - difficulty D = (n1 / 2) × (N2 / n2)
+//! - effort E = D × V
+//!
+//! The maintainability index combines volume with CC and LOC, using the
+//! 0–100 variant from Visual Studio:
+//!
+//! MI = max(0, min(100, (171 − 5.2 × ln(V) − 0.23 × CC − 16.2 × ln(LOC)) × 100 / 171))
+//!
+//! Lower is harder to maintain. A function with no tokens (V = 0) scores 100.
+//!
+//! Supported: Go, Java, Python, C#, C, C++, Swift, PHP, Scala, Dart, Elixir,
+//! Lua, Bash, Zig, Haskell, and Perl. TypeScript, JavaScript, Vue, Rust (parsed
+//! with SWC and syn), and SQL report none.
+//!
+//! Global invariants enforced:
+//! - Formatting, comments, and whitespace must not affect results
+//! - Deterministic metric calculation
+
+use crate::ast::FunctionNode;
+use crate::language::tree_sitter_utils::{
+    with_cached_bash_tree, with_cached_c_tree, with_cached_cpp_tree, with_cached_csharp_tree,
+    with_cached_dart_tree, with_cached_elixir_tree, with_cached_go_tree, with_cached_haskell_tree,
+    with_cached_java_tree, with_cached_lua_tree, with_cached_perl_tree, with_cached_php_tree,
+    with_cached_python_tree, with_cached_scala_tree, with_cached_swift_tree, with_cached_zig_tree,
+};
+use crate::language::FunctionBody;
+use serde::{Deserialize, Serialize};
+use std::collections::HashSet;
+use tree_sitter::Node;
+
+/// Halstead metrics of one function
+#[derive(Debug, Clone, Copy, PartialEq, Serialize, Deserialize)]
+pub struct HalsteadMetrics {
+    /// n1
+    pub distinct_operators: u32,
+    /// n2
+    pub distinct_operands: u32,
+    /// N1
+    pub total_operators: u32,
+    /// N2
+    pub total_operands: u32,
+    pub volume: f64,
+    pub difficulty: f64,
+    pub effort: f64,
+}
+
+impl HalsteadMetrics {
+    /// Derive volume, difficulty, and effort from token counts. An empty
+    /// vocabulary has volume 0, and no operands means difficulty 0.
+    pub fn from_counts(
+        distinct_operators: u32,
+        distinct_operands: u32,
+        total_operators: u32,
+        total_operands: u32,
+    ) -> Self {
+        let vocabulary = distinct_operators + distinct_operands;
+        let length = total_operators + total_operands;
+        let volume = if vocabulary > 0 {
+            f64::from(length) * f64::from(vocabulary).log2()
+        } else {
+            0.0
+        };
+        let difficulty = if distinct_operands > 0 {
+            f64::from(distinct_operators) / 2.0 * f64::from(total_operands)
+                / f64::from(distinct_operands)
+        } else {
+            0.0
+        };
+        HalsteadMetrics {
+            distinct_operators,
+            distinct_operands,
+            total_operators,
+            total_operands,
+            volume,
+            difficulty,
+            effort: difficulty * volume,
+        }
+    }
+}
+
+/// Maintainability index from Halstead volume, CC, and LOC, clamped to
+/// [0, 100] (see the module docs for the formula)
+pub fn maintainability_index(volume: f64, cc: u32, loc: u32) -> f64 {
+    // ln(0) is -inf, which the clamp turns into 100
+    let raw = 171.0 - 5.2 * volume.ln() - 0.23 * f64::from(cc) - 16.2 * f64::from(loc).ln();
+    (raw * 100.0 / 171.0).clamp(0.0, 100.0)
+}
+
+/// Operand node kinds for Go
+const GO_OPERANDS: &[&str] = &[
+    "identifier",
+    "blank_identifier",
+    "field_identifier",
+    "package_identifier",
+    "type_identifier",
+    "label_name",
+    "int_literal",
+    "float_literal",
+    "imaginary_literal",
+    "rune_literal",
+    "interpreted_string_literal",
+    "raw_string_literal",
+    "true",
+    "false",
+    "nil",
+    "iota",
+];
+
+/// Operand node kinds for Java
+const JAVA_OPERANDS: &[&str] = &[
+    "identifier",
+    "type_identifier",
+    "decimal_integer_literal",
+    "hex_integer_literal",
+    "octal_integer_literal",
+    "binary_integer_literal",
+    "decimal_floating_point_literal",
+    "hex_floating_point_literal",
+    "character_literal",
+    "string_literal",
+    "text_block",
+    "true",
+    "false",
+    "null_literal",
+];
+
+/// Operand node kinds for Python
+const PYTHON_OPERANDS: &[&str] = &[
+    "identifier",
+    "integer",
+    "float",
+    "string",
+    "true",
+    "false",
+    "none",
+];
+
+/// Operand node kinds for C#
+const CSHARP_OPERANDS: &[&str] = &[
+    "identifier",
+    "integer_literal",
+    "real_literal",
+    "character_literal",
+    "string_literal",
+    "verbatim_string_literal",
+    "raw_string_literal",
+    "interpolated_string_expression",
+    "boolean_literal",
+    "null_literal",
+];
+
+/// Operand node kinds for C
+const C_OPERANDS: &[&str] = &[
+    "identifier",
+    "field_identifier",
+    "type_identifier",
+    "statement_identifier",
+    "number_literal",
+    "char_literal",
+    "string_literal",
+    "concatenated_string",
+    "true",
+    "false",
+    "null",
+];
+
+/// Operand node kinds for C++
+const CPP_OPERANDS: &[&str] = &[
+    "identifier",
+    "field_identifier",
+    "type_identifier",
+    "namespace_identifier",
+    "statement_identifier",
+    "number_literal",
+    "char_literal",
+    "string_literal",
+    "raw_string_literal",
+    "concatenated_string",
+    "true",
+    "false",
+    "null",
+    "nullptr",
+    "this",
+];
+
+/// Operand node kinds for Swift
+const SWIFT_OPERANDS: &[&str] = &[
+    "simple_identifier",
+    "type_identifier",
+    "integer_literal",
+    "real_literal",
+    "hex_literal",
+    "oct_literal",
+    "bin_literal",
+    "boolean_literal",
+    "line_string_literal",
+    "multi_line_string_literal",
+    "raw_string_literal",
+    "nil",
+];
+
+/// Operand node kinds for PHP; a `$name` variable is one operand
+const PHP_OPERANDS: &[&str] = &[
+    "name",
+    "variable_name",
+    "integer",
+    "float",
+    "string",
+    "encapsed_string",
+    "heredoc",
+    "nowdoc",
+    "boolean",
+    "null",
+];
+
+/// Operand node kinds for Scala; an interpolated string is one operand
+const SCALA_OPERANDS: &[&str] = &[
+    "identifier",
+    "type_identifier",
+    "integer_literal",
+    "floating_point_literal",
+    "boolean_literal",
+    "character_literal",
+    "string",
+    "interpolated_string_expression",
+    "symbol_literal",
+    "null_literal",
+];
+
+/// Operand node kinds for Dart; a string with interpolation is one operand
+const DART_OPERANDS: &[&str] = &[
+    "identifier",
+    "type_identifier",
+    "decimal_integer_literal",
+    "decimal_floating_point_literal",
+    "hex_integer_literal",
+    "string_literal",
+    "symbol_literal",
+    "true",
+    "false",
+    "null_literal",
+    "this",
+    "super",
+];
+
+/// Operand node kinds for Elixir; a string or sigil with interpolation is
+/// one operand, and module names (`Enum`) and atoms (`:ok`) are operands
+const ELIXIR_OPERANDS: &[&str] = &[
+    "identifier",
+    "alias",
+    "integer",
+    "float",
+    "char",
+    "string",
+    "charlist",
+    "sigil",
+    "atom",
+    "quoted_atom",
+    "boolean",
+    "nil",
+];
+
+/// Operand node kinds for Lua; a string is one operand, and `...` is an
+/// operand like any other value
+const LUA_OPERANDS: &[&str] = &[
+    "identifier",
+    "number",
+    "string",
+    "true",
+    "false",
+    "nil",
+    "vararg_expression",
+];
+
+/// Operand node kinds for Bash; command names and arguments are words, a
+/// quoted string is one operand, and `$` of an expansion is an operator
+const BASH_OPERANDS: &[&str] = &[
+    "word",
+    "variable_name",
+    "special_variable_name",
+    "string",
+    "raw_string",
+    "ansi_c_string",
+    "number",
+    "heredoc_body",
+];
+
+/// Operand node kinds for Zig; a builtin's name (`@intCast`) is an operand
+/// like any function name
+const ZIG_OPERANDS: &[&str] = &[
+    "identifier",
+    "builtin_identifier",
+    "integer",
+    "float",
+    "string",
+    "multiline_string",
+    "character",
+    "true",
+    "false",
+    "null",
+    "undefined",
+];
+
+/// Operand node kinds for Haskell; a data constructor (`Just`) and a type
+/// name in an annotation are operands like any variable, and `_` is one
+const HASKELL_OPERANDS: &[&str] = &[
+    "variable",
+    "constructor",
+    "name",
+    "integer",
+    "float",
+    "char",
+    "string",
+    "wildcard",
+];
+
+/// Operand node kinds for Perl; a variable counts with its sigil, so `$x`,
+/// `@x`, and `%x` are distinct operands, and a called sub's name is one
+const PERL_OPERANDS: &[&str] = &[
+    "scalar",
+    "array",
+    "hash",
+    "number",
+    "string_literal",
+    "interpolated_string_literal",
+    "quoted_word_list",
+    "bareword",
+    "autoquoted_bareword",
+    "function",
+    "method",
+];
+
+/// Halstead metrics of `function`, or None for languages without a
+/// tree-sitter grammar (see the module docs) and when the source no longer
+/// parses.
+pub fn function_halstead(function: &FunctionNode) -> Option<HalsteadMetrics> {
+    let (start, end) = (function.span.start, function.span.end);
+    match &function.body {
+        FunctionBody::Go { source, .. } => with_cached_go_tree(source, |root| {
+            count_tokens(root, start, end, source, GO_OPERANDS)
+        }),
+        FunctionBody::Java { source, .. } => with_cached_java_tree(source, |root| {
+            count_tokens(root, start, end, source, JAVA_OPERANDS)
+        }),
+        FunctionBody::Python { source, .. } => with_cached_python_tree(source, |root| {
+            count_tokens(root, start, end, source, PYTHON_OPERANDS)
+        }),
+        FunctionBody::CSharp { source, .. } => with_cached_csharp_tree(source, |root| {
+            count_tokens(root, start, end, source, CSHARP_OPERANDS)
+        }),
+        FunctionBody::C { source, .. } => with_cached_c_tree(source, |root| {
+            count_tokens(root, start, end, source, C_OPERANDS)
+        }),
+        FunctionBody::Cpp { source, .. } => with_cached_cpp_tree(source, |root| {
+            count_tokens(root, start, end, source, CPP_OPERANDS)
+        }),
+        FunctionBody::Swift { source, .. } => with_cached_swift_tree(source, |root| {
+            count_tokens(root, start, end, source, SWIFT_OPERANDS)
+        }),
+        FunctionBody::Php { source, .. } => with_cached_php_tree(source, |root| {
+            count_tokens(root, start, end, source, PHP_OPERANDS)
+        }),
+        FunctionBody::Scala { source, .. } => with_cached_scala_tree(source, |root| {
+            count_tokens(root, start, end, source, SCALA_OPERANDS)
+        }),
+        FunctionBody::Dart { source, .. } => with_cached_dart_tree(source, |root| {
+            count_tokens(root, start, end, source, DART_OPERANDS)
+        }),
+        FunctionBody::Elixir { source, .. } => with_cached_elixir_tree(source, |root| {
+            count_tokens(root, start, end, source, ELIXIR_OPERANDS)
+        }),
+        FunctionBody::Lua { source, .. } => with_cached_lua_tree(source, |root| {
+            count_tokens(root, start, end, source, LUA_OPERANDS)
+        }),
+        FunctionBody::Bash { source, .. } => with_cached_bash_tree(source, |root| {
+            count_tokens(root, start, end, source, BASH_OPERANDS)
+        }),
+        FunctionBody::Zig { source, .. } => with_cached_zig_tree(source, |root| {
+            count_tokens(root, start, end, source, ZIG_OPERANDS)
+        }),
+        FunctionBody::Haskell { source, .. } => with_cached_haskell_tree(source, |root| {
+            count_tokens(root, start, end, source, HASKELL_OPERANDS)
+        }),
+        FunctionBody::Perl { source, .. } => with_cached_perl_tree(source, |root| {
+            count_tokens(root, start, end, source, PERL_OPERANDS)
+        }),
+        _ => None,
+    }
+}
+
+/// Count the tokens of `start..end`
+fn count_tokens(
+    root: Node,
+    start: usize,
+    end: usize,
+    source: &str,
+    operand_kinds: &[&str],
+) -> Option<HalsteadMetrics> {
+    #[derive(Default)]
+    struct Counts<'s> {
+        operators: HashSet<&'s str>,
+        operands: HashSet<&'s str>,
+        total_operators: u32,
+        total_operands: u32,
+    }
+
+    fn walk<'s>(
+        node: Node,
+        source: &'s str,
+        range: (usize, usize),
+        operand_kinds: &[&str],
+        counts: &mut Counts<'s>,
+    ) {
+        if node.kind().contains("comment")
+            || node.end_byte() <= range.0
+            || node.start_byte() >= range.1
+        {
+            return;
+        }
+        let text = &source[node.start_byte()..node.end_byte()];
+        if operand_kinds.contains(&node.kind()) {
+            counts.operands.insert(text);
+            counts.total_operands += 1;
+        } else if node.child_count() == 0 {
+            if !text.trim().is_empty() {
+                counts.operators.insert(text);
+                counts.total_operators += 1;
+            }
+        } else {
+            let mut cursor = node.walk();
+            for child in node.children(&mut cursor) {
+                walk(child, source, range, operand_kinds, counts);
+            }
+        }
+    }
+
+    // A Dart signature and its body are siblings, so the node can be their
+    // parent; only tokens inside start..end count
+    let function = root.descendant_for_byte_range(start, end)?;
+    let mut counts = Counts::default();
+    walk(function, source, (start, end), operand_kinds, &mut counts);
+    Some(HalsteadMetrics::from_counts(
+        counts.operators.len() as u32,
+        counts.operands.len() as u32,
+        counts.total_operators,
+        counts.total_operands,
+    ))
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::language::parser::LanguageParser;
+    use crate::language::{GoParser, PythonParser};
+
+    fn first_function(parser: &dyn LanguageParser, source: &str, filename: &str) -> FunctionNode {
+        let module = parser.parse(source, filename).unwrap();
+        module.discover_functions(0, source).remove(0)
+    }
+
+    #[test]
+    fn test_from_counts() {
+        let h = HalsteadMetrics::from_counts(10, 6, 13, 9);
+        assert_eq!(h.volume, 88.0);
+        assert_eq!(h.difficulty, 7.5);
+        assert_eq!(h.effort, 660.0);
+    }
+
+    #[test]
+    fn test_from_counts_empty() {
+        let h = HalsteadMetrics::from_counts(0, 0, 0, 0);
+        assert_eq!((h.volume, h.difficulty, h.effort), (0.0, 0.0, 0.0));
+    }
+
+    #[test]
+    fn test_maintainability_index_clamped() {
+        // Empty and tiny functions would score above 100
+        assert_eq!(maintainability_index(0.0, 1, 0), 100.0);
+        assert_eq!(maintainability_index(0.5, 1, 1), 100.0);
+        // Huge ones would score below 0
+        assert_eq!(maintainability_index(1e9, 500, 100_000), 0.0);
+        // In range: (171 − 5.2 × ln(88) − 0.23 × 3 − 16.2 × ln(7)) × 100 / 171
+        let mi = maintainability_index(88.0, 3, 7);
+        assert!((mi - 67.5463).abs() < 1e-3, "{mi}");
+    }
+
+    #[test]
+    fn test_go_tokens() {
+        // func ( ) { := = } / Simple x 1 _ x
+        let source = "package p\n\nfunc Simple() {\n\tx := 1\n\t_ = x\n}\n";
+        let function = first_function(&GoParser::new().unwrap(), source, "p.go");
+        let h = function_halstead(&function).unwrap();
+        assert_eq!(
+            (
+                h.distinct_operators,
+                h.distinct_operands,
+                h.total_operators,
+                h.total_operands
+            ),
+            (7, 4, 7, 5)
+        );
+    }
+
+    #[test]
+    fn test_comments_and_formatting_ignored() {
+        let compact = "def f(a):\n    return a + 1\n";
+        let spread = "def f(a):  # doc\n    # note\n    return (a\n            + 1)\n";
+        let a = function_halstead(&first_function(
+            &PythonParser::new().unwrap(),
+            compact,
+            "m.py",
+        ))
+        .unwrap();
+        let b = function_halstead(&first_function(
+            &PythonParser::new().unwrap(),
+            spread,
+            "m.py",
+        ))
+        .unwrap();
+        assert_eq!(a.total_operands, b.total_operands);
+        assert_eq!(a.distinct_operands, b.distinct_operands);
+        // The parentheses add two tokens but no new operator
+        assert_eq!(a.distinct_operators, b.distinct_operators);
+        assert_eq!(a.total_operators + 2, b.total_operators);
+    }
+}
@@ -6,8 +6,11 @@
 use crate::aggregates::SnapshotAggregates;
 use crate::delta::{Delta, FunctionDeltaEntry, FunctionStatus};
 use crate::policy::{PolicyId, PolicyResults};
+use crate::report::FunctionRiskReport;
 use crate::risk::{RiskBand, RiskThresholds};
+use crate::severity::{Severity, SeverityScale};
 use crate::snapshot::{CommitInfo, FunctionSnapshot, Snapshot, SnapshotSummary};
+use std::path::Path;
 
 /// Render a snapshot as an HTML report.
 ///
@@ -255,6 +258,489 @@ pub fn render_html_delta(delta: &Delta, source_url: Option<&str>) -> String {
     )
 }
 
+/// Render analysis reports (`--format html` without `--mode`) as an HTML page.
+///
+/// Functions are grouped under one collapsible row per file, worst file first;
+/// clicking a file expands its functions. The table sorts by any column and
+/// filters by band and name, LRS cells are shaded from green to red as they
+/// approach `thresholds.critical`, and each function's severity on `scale`
+/// is colored by level. File paths are shown relative to `base` when they
+/// fall under it.
+pub fn render_html_reports(
+    reports: &[FunctionRiskReport],
+    base: &Path,
+    thresholds: &RiskThresholds,
+    scale: &SeverityScale,
+) -> String {
+    let groups = group_reports_by_file(reports, base);
+    let title = html_escape(&base.display().to_string());
+
+    format!(
+        r#"<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <title>Hotspots Report - {title}</title>
+    <style>{css}{reports_css}</style>
+</head>
+<body>
+    <div class="container">
+        <header>
+            <h1>Hotspots Report</h1>
+            <div class="meta">
+                <span>Path: <code class="monospace">{title}</code></span> •
+                <span>{functions} functions in {files} files</span>
+            </div>
+        </header>
+        {summary}
+        {table}
+        {footer}
+    </div>
+    <script>{js}</script>
+</body>
+</html>"#,
+        title = title,
+        css = inline_css(),
+        reports_css = reports_css(),
+        js = reports_javascript(),
+        functions = reports.len(),
+        files = groups.len(),
+        summary = render_reports_summary(reports),
+        table = render_reports_table(&groups, reports.len(), thresholds, scale),
+        footer = render_footer(),
+    )
+}
+
+/// One file's reports, in the order they were given
+struct FileGroup<'a> {
+    path: String,
+    functions: Vec<&'a FunctionRiskReport>,
+}
+
+/// Group reports by file, files ordered by their first report (so by worst
+/// LRS when reports arrive sorted)
+fn group_reports_by_file<'a>(reports: &'a [FunctionRiskReport], base: &Path) -> Vec<FileGroup<'a>> {
+    let mut index: std::collections::HashMap<&str, usize> = std::collections::HashMap::new();
+    let mut groups: Vec<FileGroup<'a>> = Vec::new();
+    for report in reports {
+        let i = *index.entry(report.file.as_str()).or_insert_with(|| {
+            groups.push(FileGroup {
+                path: crate::treemap::relative_path(&report.file, base),
+                functions: Vec::new(),
+            });
+            groups.len() - 1
+        });
+        groups[i].functions.push(report);
+    }
+    groups
+}
+
+fn render_reports_summary(reports: &[FunctionRiskReport]) -> String {
+    let count = |band: RiskBand| reports.iter().filter(|r| r.band == band).count();
+
+    format!(
+        r#"<div class="summary">
+    <div class="summary-card">
+        <h3>Total Functions</h3>
+        <div class="value">{total}</div>
+    </div>
+    <div class="summary-card">
+        <h3>Critical</h3>
+        <div class="value band-critical">{critical}</div>
+    </div>
+    <div class="summary-card">
+        <h3>High</h3>
+        <div class="value band-high">{high}</div>
+    </div>
+    <div class="summary-card">
+        <h3>Moderate</h3>
+        <div class="value band-moderate">{moderate}</div>
+    </div>
+</div>
+"#,
+        total = reports.len(),
+        critical = count(RiskBand::Critical),
+        high = count(RiskBand::High),
+        moderate = count(RiskBand::Moderate),
+    )
+}
+
+fn render_reports_table(
+    groups: &[FileGroup],
+    total: usize,
+    thresholds: &RiskThresholds,
+    scale: &SeverityScale,
+) -> String {
+    let groups: String = groups
+        .iter()
+        .map(|g| render_file_group(g, thresholds, scale))
+        .collect();
+
+    format!(
+        r#"<section class="section">
+    <h2>Files and Functions</h2>
+    <div class="filters">
+        <div class="filter-group">
+            <label for="report-band-filter">Risk Band</label>
+            <select id="report-band-filter">
+                <option value="all">All Bands</option>
+                <option value="critical">Critical</option>
+                <option value="high">High</option>
+                <option value="moderate">Moderate</option>
+                <option value="low">Low</option>
+            </select>
+        </div>
+        <div class="filter-group">
+            <label for="report-search">Search</label>
+            <input type="text" id="report-search" placeholder="Function or file name...">
+        </div>
+    </div>
+    <p class="report-note">Showing <span id="report-visible-count">{total}</span> of {total} functions. Click a file to show its functions.</p>
+    <table id="report-table">
+        <thead>
+            <tr>
+                <th class="sortable" data-column="name">File / Function</th>
+                <th class="sortable" data-column="line">Line</th>
+                <th class="sortable desc" data-column="lrs" title="Local Risk Score; for a file, its worst function">LRS</th>
+                <th class="sortable" data-column="bandRank" title="Risk band based on LRS: low / moderate / high / critical">Band</th>
+                <th class="sortable" data-column="severityRank" title="Severity by {metric}: ok / low / medium / high / critical; for a file, its worst function">Severity</th>
+                <th class="sortable" data-column="cc" title="Cyclomatic Complexity; for a file, the total">CC</th>
+                <th class="sortable" data-column="nd" title="Nesting Depth; for a file, the deepest">ND</th>
+                <th class="sortable" data-column="fo" title="Fan-out; for a file, the total">FO</th>
+                <th class="sortable" data-column="ns" title="Number of Statements; for a file, the total">NS</th>
+                <th class="sortable" data-column="loc" title="Lines of code; for a file, the total">LOC</th>
+            </tr>
+        </thead>
+        {groups}
+    </table>
+    <div class="heat-legend">
+        <span>LRS 0</span>
+        <span class="heat-legend-bar" style="{low}"></span>
+        <span class="heat-legend-bar" style="{mid}"></span>
+        <span class="heat-legend-bar" style="{critical}"></span>
+        <span>{critical_lrs} (critical) and above</span>
+    </div>
+</section>"#,
+        total = total,
+        groups = groups,
+        metric = scale.metric.as_str(),
+        low = heat_style(0.0, thresholds.critical),
+        mid = heat_style(thresholds.critical / 2.0, thresholds.critical),
+        critical = heat_style(thresholds.critical, thresholds.critical),
+        critical_lrs = thresholds.critical,
+    )
+}
+
+/// A `<tbody>` holding the file's summary row and its (initially hidden)
+/// function rows. The summary row carries the file's worst LRS, band,
+/// severity, and ND, and its total CC, FO, NS, and LOC, which sorting uses
+/// for the file.
+fn render_file_group(
+    group: &FileGroup,
+    thresholds: &RiskThresholds,
+    scale: &SeverityScale,
+) -> String {
+    let functions = &group.functions;
+    let max_lrs = functions.iter().map(|f| f.lrs).fold(0.0, f64::max);
+    let band = functions
+        .iter()
+        .map(|f| f.band)
+        .max()
+        .unwrap_or(RiskBand::Low);
+    let severity = functions
+        .iter()
+        .map(|&f| scale.severity(f))
+        .max()
+        .unwrap_or(Severity::Ok);
+    let sum = |metric: fn(&FunctionRiskReport) -> u32| -> u32 {
+        functions.iter().map(|&f| metric(f)).sum()
+    };
+    let (cc, fo, ns, loc) = (
+        sum(|f| f.metrics.cc),
+        sum(|f| f.metrics.fo),
+        sum(|f| f.metrics.ns),
+        sum(|f| f.metrics.loc),
+    );
+    let nd = functions.iter().map(|f| f.metrics.nd).max().unwrap_or(0);
+    let rows: String = functions
+        .iter()
+        .map(|f| render_function_row(f, thresholds, scale))
+        .collect();
+
+    format!(
+        "<tbody class=\"file-group\" data-name=\"{name}\" data-lrs=\"{lrs:.2}\" \
+         data-band-rank=\"{rank}\" data-severity-rank=\"{severity_rank}\" data-cc=\"{cc}\" \
+         data-nd=\"{nd}\" data-fo=\"{fo}\" data-ns=\"{ns}\" data-loc=\"{loc}\">\n\
+         <tr class=\"file-row\" tabindex=\"0\" aria-expanded=\"false\">\n\
+         <td class=\"monospace\"><span class=\"file-toggle\" aria-hidden=\"true\">▸</span> \
+         {name} <span class=\"file-count\">({count} {noun})</span></td>\n\
+         <td></td>\n\
+         <td class=\"heat\" style=\"{heat}\">{lrs:.2}</td>\n\
+         <td><span class=\"band-{band}\">{band}</span></td>\n\
+         <td><span class=\"severity-{severity}\">{severity}</span></td>\n\
+         <td>{cc}</td>\n\
+         <td>{nd}</td>\n\
+         <td>{fo}</td>\n\
+         <td>{ns}</td>\n\
+         <td>{loc}</td>\n\
+         </tr>\n\
+         {rows}</tbody>\n",
+        name = html_escape(&group.path),
+        count = functions.len(),
+        noun = if functions.len() == 1 {
+            "function"
+        } else {
+            "functions"
+        },
+        lrs = max_lrs,
+        rank = band as u8,
+        band = band.as_str(),
+        severity_rank = severity as u8,
+        severity = severity.as_str(),
+        heat = heat_style(max_lrs, thresholds.critical),
+        cc = cc,
+        nd = nd,
+        fo = fo,
+        ns = ns,
+        loc = loc,
+        rows = rows,
+    )
+}
+
+fn render_function_row(
+    f: &FunctionRiskReport,
+    thresholds: &RiskThresholds,
+    scale: &SeverityScale,
+) -> String {
+    let severity = scale.severity(f);
+    format!(
+        "<tr class=\"function-row\" hidden data-name=\"{function}\" data-line=\"{line}\" \
+         data-lrs=\"{lrs:.2}\" data-band=\"{band}\" data-band-rank=\"{rank}\" \
+         data-severity-rank=\"{severity_rank}\" data-cc=\"{cc}\" data-nd=\"{nd}\" \
+         data-fo=\"{fo}\" data-ns=\"{ns}\" data-loc=\"{loc}\">\n\
+         <td class=\"function-name monospace\">{function}</td>\n\
+         <td>{line}</td>\n\
+         <td class=\"heat\" style=\"{heat}\">{lrs:.2}</td>\n\
+         <td><span class=\"band-{band}\">{band}</span></td>\n\
+         <td><span class=\"severity-{severity}\">{severity}</span></td>\n\
+         <td>{cc}</td>\n\
+         <td>{nd}</td>\n\
+         <td>{fo}</td>\n\
+         <td>{ns}</td>\n\
+         <td>{loc}</td>\n\
+         </tr>\n",
+        function = html_escape(&f.function),
+        line = f.line,
+        lrs = f.lrs,
+        band = f.band.as_str(),
+        rank = f.band as u8,
+        severity_rank = severity as u8,
+        severity = severity.as_str(),
+        heat = heat_style(f.lrs, thresholds.critical),
+        cc = f.metrics.cc,
+        nd = f.metrics.nd,
+        fo = f.metrics.fo,
+        ns = f.metrics.ns,
+        loc = f.metrics.loc,
+    )
+}
+
+/// Background shading an LRS from green (0) through yellow to red (at or
+/// above `critical`)
+fn heat_style(lrs: f64, critical: f64) -> String {
+    let scale = if critical > 0.0 {
+        (lrs / critical).clamp(0.0, 1.0)
+    } else {
+        1.0
+    };
+    format!(
+        "background-color: hsl({:.0}, 85%, 82%)",
+        120.0 * (1.0 - scale)
+    )
+}
+
+/// Styles the function report adds to `inline_css`
+fn reports_css() -> &'static str {
+    r#"
+/* Function report */
+.severity-ok {
+    color: #9ca3af;
+}
+
+.severity-low {
+    color: #22c55e;
+    font-weight: 600;
+}
+
+.severity-medium {
+    color: #eab308;
+    font-weight: 600;
+}
+
+.severity-high {
+    color: #f97316;
+    font-weight: 600;
+}
+
+.severity-critical {
+    color: #ef4444;
+    font-weight: 600;
+}
+
+.report-note {
+    color: #6b7280;
+    font-size: 0.875rem;
+    margin-bottom: 0.5rem;
+}
+
+.file-row {
+    cursor: pointer;
+    background: #f9fafb;
+    font-weight: 600;
+}
+
+.file-row:focus {
+    outline: 2px solid #3b82f6;
+    outline-offset: -2px;
+}
+
+.file-toggle {
+    display: inline-block;
+    transition: transform 0.15s;
+}
+
+.file-row[aria-expanded="true"] .file-toggle {
+    transform: rotate(90deg);
+}
+
+.file-count {
+    color: #6b7280;
+    font-weight: 400;
+}
+
+.function-name {
+    padding-left: 2rem;
+    overflow-wrap: anywhere;
+}
+
+td.heat {
+    font-weight: 600;
+    text-align: right;
+}
+
+.heat-legend {
+    display: flex;
+    align-items: center;
+    gap: 0.5rem;
+    margin-top: 0.75rem;
+    color: #6b7280;
+    font-size: 0.8125rem;
+}
+
+.heat-legend-bar {
+    display: inline-block;
+    width: 2rem;
+    height: 0.75rem;
+    border-radius: 0.25rem;
+}
+"#
+}
+
+/// Sorting, filtering, and file expansion for the function report
+fn reports_javascript() -> &'static str {
+    r#"
+(function() {
+    const table = document.getElementById('report-table');
+    const bandFilter = document.getElementById('report-band-filter');
+    const search = document.getElementById('report-search');
+    const visibleCount = document.getElementById('report-visible-count');
+    let sortColumn = 'lrs';
+    let sortDirection = 'desc';
+
+    // Numbers compare numerically, anything else as text; rows without the
+    // column (files have no line) keep their order
+    function compare(a, b) {
+        const aVal = a.dataset[sortColumn] || '';
+        const bVal = b.dataset[sortColumn] || '';
+        const aNum = parseFloat(aVal);
+        const bNum = parseFloat(bVal);
+        const order = (!isNaN(aNum) && !isNaN(bNum)) ? aNum - bNum : aVal.localeCompare(bVal);
+        return sortDirection === 'asc' ? order : -order;
+    }
+
+    // Files sort by their summary values, and functions within each file
+    function sortTable(column) {
+        if (sortColumn === column) {
+            sortDirection = sortDirection === 'asc' ? 'desc' : 'asc';
+        } else {
+            sortColumn = column;
+            sortDirection = column === 'name' ? 'asc' : 'desc';
+        }
+        table.querySelectorAll('th.sortable').forEach(th => {
+            th.classList.remove('asc', 'desc');
+            if (th.dataset.column === column) th.classList.add(sortDirection);
+        });
+        const groups = Array.from(table.tBodies);
+        groups.sort(compare);
+        groups.forEach(group => {
+            const rows = Array.from(group.querySelectorAll('tr.function-row'));
+            rows.sort(compare);
+            rows.forEach(row => group.appendChild(row));
+            table.appendChild(group);
+        });
+    }
+
+    // A function shows when it matches the filters and its file is expanded;
+    // a file shows when any of its functions match. Changing a filter expands
+    // the files with matches.
+    function applyFilters(expandMatches) {
+        const band = bandFilter.value;
+        const term = search.value.trim().toLowerCase();
+        const filtering = band !== 'all' || term !== '';
+        let visible = 0;
+        Array.from(table.tBodies).forEach(group => {
+            const fileRow = group.rows[0];
+            const fileMatch = group.dataset.name.toLowerCase().includes(term);
+            const rows = Array.from(group.querySelectorAll('tr.function-row'));
+            const matched = rows.filter(row =>
+                (band === 'all' || row.dataset.band === band) &&
+                (fileMatch || row.dataset.name.toLowerCase().includes(term)));
+            if (expandMatches && filtering && matched.length > 0) {
+                fileRow.setAttribute('aria-expanded', 'true');
+            }
+            const expanded = fileRow.getAttribute('aria-expanded') === 'true';
+            rows.forEach(row => { row.hidden = !expanded || !matched.includes(row); });
+            group.hidden = matched.length === 0;
+            visible += matched.length;
+        });
+        visibleCount.textContent = visible;
+    }
+
+    function toggle(fileRow) {
+        const expanded = fileRow.getAttribute('aria-expanded') === 'true';
+        fileRow.setAttribute('aria-expanded', expanded ? 'false' : 'true');
+        applyFilters(false);
+    }
+
+    table.querySelectorAll('tr.file-row').forEach(row => {
+        row.addEventListener('click', () => toggle(row));
+        row.addEventListener('keydown', e => {
+            if (e.key === 'Enter' || e.key === ' ') {
+                e.preventDefault();
+                toggle(row);
+            }
+        });
+    });
+    table.querySelectorAll('th.sortable').forEach(th => {
+        th.addEventListener('click', () => sortTable(th.dataset.column));
+    });
+    bandFilter.addEventListener('change', () => applyFilters(true));
+    search.addEventListener('input', () => applyFilters(true));
+})();
+"#
+}
+
 /// Inline CSS styles
 fn inline_css() -> &'static str {
     r#"
@@ -1148,6 +1634,7 @@ footer a {
 .pattern-cell { display: flex; flex-wrap: wrap; gap: 3px; align-items: center; }
 
 /* Tier 1 — structural (warm palette) */
+.pattern-arrow_code        { background: #fefce8; color: #a16207; border-color: #fde047; }
 .pattern-complex_branching { background: #fffbeb; color: #b45309; border-color: #fde68a; }
 .pattern-deeply_nested     { background: #fff7ed; color: #c2410c; border-color: #fed7aa; }
 .pattern-exit_heavy        { background: #f5f3ff; color: #7c3aed; border-color: #ddd6fe; }
@@ -1187,6 +1674,8 @@ footer a {
 .pattern-chip-name  { font-size: 0.7rem; font-weight: 700; font-family: monospace; margin-top: 0.25rem; }
 .pattern-chip-desc  { font-size: 0.65rem; color: #9ca3af; margin-top: 0.15rem; }
 
+.pattern-chip-arrow_code        { border-left-color: #a16207; background: #fefce8; }
+.pattern-chip-arrow_code        .pattern-chip-count { color: #a16207; }
 .pattern-chip-complex_branching { border-left-color: #b45309; background: #fffbeb; }
 .pattern-chip-complex_branching .pattern-chip-count { color: #b45309; }
 .pattern-chip-deeply_nested     { border-left-color: #c2410c; background: #fff7ed; }
@@ -1564,6 +2053,7 @@ th.sortable.desc::after {
     .scatter-axis-desc { color:#6b7280; }
 
     /* Pattern badges — dark mode */
+    .pattern-arrow_code        { background: #262000; color: #facc15; border-color: #a16207; }
     .pattern-complex_branching { background: #2d1b00; color: #fbbf24; border-color: #92400e; }
     .pattern-deeply_nested     { background: #3a1500; color: #fb923c; border-color: #c2410c; }
     .pattern-exit_heavy        { background: #1e0050; color: #c4b5fd; border-color: #6d28d9; }
@@ -1583,6 +2073,8 @@ th.sortable.desc::after {
     .pattern-breakdown h2      { color: #f9fafb; }
     .pattern-breakdown-subtitle { color: #9ca3af; }
     .pattern-chip-desc         { color: #6b7280; }
+    .pattern-chip-arrow_code        { background: #262000; }
+    .pattern-chip-arrow_code        .pattern-chip-count { color: #facc15; }
     .pattern-chip-complex_branching { background: #2d1b00; }
     .pattern-chip-complex_branching .pattern-chip-count { color: #fbbf24; }
     .pattern-chip-deeply_nested     { background: #3a1500; }
@@ -2535,6 +3027,7 @@ fn render_pattern_breakdown(functions: &[FunctionSnapshot]) -> String {
 
 fn pattern_description(id: &str) -> &'static str {
     match id {
+        "arrow_code" => "Body buried in a nesting chain",
         "complex_branching" => "High cyclomatic complexity and nesting",
         "deeply_nested" => "Nesting depth \u{2265} 5 levels",
         "exit_heavy" => "Many early returns",
@@ -3978,7 +4471,7 @@ fn render_source_banner(url: Option<&str>) -> String {
 }
 
 /// Format Unix timestamp as human-readable UTC string ("YYYY-MM-DD HH:MM UTC")
-fn format_timestamp(timestamp: i64) -> String {
+pub(crate) fn format_timestamp(timestamp: i64) -> String {
     let secs = if timestamp < 0 {
         0u64
     } else {
@@ -4130,3 +4623,168 @@ fn source_href(file: &str, line: u32) -> String {
         encoded
     }
 }
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::language::Language;
+    use crate::report::{MetricsReport, RiskReport};
+
+    fn make_report(file: &str, function: &str, line: u32, lrs: f64) -> FunctionRiskReport {
+        FunctionRiskReport {
+            file: file.to_string(),
+            function: function.to_string(),
+            owner: None,
+            line,
+            language: Language::TypeScript,
+            metrics: MetricsReport {
+                cc: 4,
+                nd: 2,
+                fo: 1,
+                fi: 0,
+                ns: 1,
+                loc: 12,
+                signature_complexity: 0,
+                params: 0,
+                guard_clauses: 0,
+                max_condition_ops: 0,
+                cognitive: 0,
+                halstead: None,
+                maintainability: None,
+                sloc: None,
+                comment_lines: None,
+                blank_lines: None,
+                nd_line: None,
+                cc_lines: None,
+                is_async: false,
+                await_in_loop: 0,
+                ns_breakdown: None,
+            },
+            risk: RiskReport {
+                r_cc: 0.0,
+                r_nd: 0.0,
+                r_fo: 0.0,
+                r_ns: 0.0,
+            },
+            lrs,
+            band: crate::risk::assign_risk_band(lrs),
+            risk_score: None,
+            suppression_reason: None,
+            patterns: vec![],
+            pattern_details: None,
+            callees: vec![],
+            explanation: None,
+            arrow_depth: 0,
+            aliases: vec![],
+            structure: None,
+            cc_breakdown: None,
+            is_public: false,
+            file_top_level_decisions: 0,
+        }
+    }
+
+    /// Check that every element is closed, in order. Void elements need no
+    /// closing tag, and `<script>` / `<style>` bodies are skipped as text.
+    fn assert_well_formed(html: &str) {
+        const VOID: &[&str] = &["meta", "input", "br", "img", "link"];
+        let mut open: Vec<String> = Vec::new();
+        let mut rest = html;
+        while let Some(start) = rest.find('<') {
+            rest = &rest[start + 1..];
+            let end = rest.find('>').expect("unterminated tag");
+            let tag = &rest[..end];
+            rest = &rest[end + 1..];
+            if tag.starts_with('!') {
+                continue;
+            }
+            if let Some(name) = tag.strip_prefix('/') {
+                assert_eq!(open.pop().as_deref(), Some(name), "mismatched </{name}>");
+                continue;
+            }
+            let name = tag.split_whitespace().next().unwrap_or("");
+            assert!(
+                !name.is_empty() && name.chars().all(|c| c.is_ascii_alphanumeric()),
+                "malformed tag <{tag}>"
+            );
+            if name == "script" || name == "style" {
+                let close = format!("</{name}>");
+                let body_end = rest.find(&close).expect("unclosed raw text element");
+                rest = &rest[body_end + close.len()..];
+            } else if !VOID.contains(&name) {
+                open.push(name.to_string());
+            }
+        }
+        assert!(open.is_empty(), "unclosed elements: {:?}", open);
+    }
+
+    #[test]
+    fn test_reports_html_groups_functions_by_file() {
+        let reports = vec![
+            make_report("/repo/src/api.ts", "handle", 5, 9.5),
+            make_report("/repo/src/util.ts", "clamp", 3, 4.0),
+            make_report("/repo/src/api.ts", "route", 20, 2.0),
+        ];
+        let html = render_html_reports(
+            &reports,
+            Path::new("/repo"),
+            &RiskThresholds::default(),
+            &SeverityScale::default(),
+        );
+
+        assert_well_formed(&html);
+        assert_eq!(html.matches("<tr class=\"file-row\"").count(), 2);
+        assert_eq!(html.matches("<tr class=\"function-row\"").count(), 3);
+        assert!(html.contains("3 functions in 2 files"));
+
+        // Files in order of their worst function, paths relative to the base
+        let api = html.find("data-name=\"src/api.ts\"").unwrap();
+        let util = html.find("data-name=\"src/util.ts\"").unwrap();
+        assert!(api < util);
+        assert!(html.contains("(2 functions)"));
+        assert!(html.contains("(1 function)"));
+
+        // The file row summarizes its worst function and total CC
+        let api_group = &html[api..util];
+        assert!(api_group.contains("data-lrs=\"9.50\""));
+        assert!(api_group.contains("data-band-rank=\"3\""));
+        assert!(api_group.contains("data-severity-rank=\"4\""));
+        assert!(api_group.contains("data-cc=\"8\""));
+        assert!(api_group.contains("data-name=\"handle\""));
+        assert!(api_group.contains("data-name=\"route\""));
+
+        // Each function's severity on the default LRS scale
+        assert!(api_group.contains("<span class=\"severity-critical\">critical</span>"));
+        assert!(api_group.contains("<span class=\"severity-low\">low</span>"));
+        assert!(html[util..].contains("<span class=\"severity-medium\">medium</span>"));
+    }
+
+    #[test]
+    fn test_reports_html_escapes_names() {
+        let reports = vec![
+            make_report("/repo/a&b.go", "Container[T].Get", 3, 1.0),
+            make_report("/repo/a&b.go", "Box<T>::new", 9, 1.0),
+            make_report("/repo/a&b.go", "say\"hi\"", 12, 1.0),
+        ];
+        let html = render_html_reports(
+            &reports,
+            Path::new("/repo"),
+            &RiskThresholds::default(),
+            &SeverityScale::default(),
+        );
+
+        assert_well_formed(&html);
+        assert!(html.contains("data-name=\"Container[T].Get\""));
+        assert!(html.contains(">Box&lt;T&gt;::new</td>"));
+        assert!(!html.contains("Box<T>"));
+        assert!(html.contains("data-name=\"say&quot;hi&quot;\""));
+        assert!(html.contains("data-name=\"a&amp;b.go\""));
+    }
+
+    #[test]
+    fn test_heat_style_runs_green_to_red() {
+        assert_eq!(heat_style(0.0, 9.0), "background-color: hsl(120, 85%, 82%)");
+        assert_eq!(heat_style(4.5, 9.0), "background-color: hsl(60, 85%, 82%)");
+        // Saturates at the critical threshold
+        assert_eq!(heat_style(20.0, 9.0), "background-color: hsl(0, 85%, 82%)");
+    }
+}
@@ -27,7 +27,18 @@ pub fn extract_raw_imports(source: &str, language: Language) -> Vec<String> {
         | Language::JavaScriptReact
         | Language::Vue => extract_ecmascript_imports(source),
         Language::CSharp => extract_csharp_imports(source),
-        Language::C | Language::CHeader => vec![], // #include resolution not implemented
+        Language::C | Language::CHeader | Language::Cpp => vec![], // #include resolution not implemented
+        Language::Sql => vec![],                                   // SQL has no imports
+        Language::Swift => vec![],   // module imports do not name files
+        Language::Php => vec![],     // namespace `use` resolution not implemented
+        Language::Scala => vec![],   // package imports do not name files
+        Language::Dart => vec![],    // package URI resolution not implemented
+        Language::Elixir => vec![],  // module aliases do not name files
+        Language::Lua => vec![],     // require() module path resolution not implemented
+        Language::Bash => vec![],    // `source` path resolution not implemented
+        Language::Zig => vec![],     // @import() path resolution not implemented
+        Language::Haskell => vec![], // module imports not resolved to files
+        Language::Perl => vec![],    // `use` module path resolution not implemented
     }
 }
 
@@ -89,9 +100,11 @@ fn extract_go_imports(source: &str) -> Vec<String> {
     static BLOCK_RE: std::sync::OnceLock<Regex> = std::sync::OnceLock::new();
     let block_re = BLOCK_RE.get_or_init(|| Regex::new(r#"import\s*\(([^)]*)\)"#).unwrap());
 
-    // Path inside block or single import
+    // Path inside block or single import, with its optional alias. Blank imports
+    // (`_ "pkg"`) only run the package's init(); none of its names come into
+    // scope, so they are dropped rather than becoming call-resolution edges.
     static PATH_RE: std::sync::OnceLock<Regex> = std::sync::OnceLock::new();
-    let path_re = PATH_RE.get_or_init(|| Regex::new(r#"(?:\w+\s+)?"([^"]+)""#).unwrap());
+    let path_re = PATH_RE.get_or_init(|| Regex::new(r#"(?:(\w+)\s+)?"([^"]+)""#).unwrap());
 
     let mut imports = Vec::new();
 
@@ -102,21 +115,27 @@ fn extract_go_imports(source: &str) -> Vec<String> {
         block_ranges.push(m.range());
         let block = &cap[1];
         for pc in path_re.captures_iter(block) {
-            imports.push(pc[1].to_string());
+            if pc.get(1).is_some_and(|alias| alias.as_str() == "_") {
+                continue;
+            }
+            imports.push(pc[2].to_string());
         }
     }
 
     // Single-line imports outside blocks
     static SINGLE_RE: std::sync::OnceLock<Regex> = std::sync::OnceLock::new();
     let single_re =
-        SINGLE_RE.get_or_init(|| Regex::new(r#"(?m)^import\s+(?:\w+\s+)?"([^"]+)""#).unwrap());
+        SINGLE_RE.get_or_init(|| Regex::new(r#"(?m)^import\s+(?:(\w+)\s+)?"([^"]+)""#).unwrap());
     for cap in single_re.captures_iter(source) {
         let m = cap.get(0).unwrap();
         // Skip if inside a block import we already processed
         if block_ranges.iter().any(|r| r.contains(&m.start())) {
             continue;
         }
-        imports.push(cap[1].to_string());
+        if cap.get(1).is_some_and(|alias| alias.as_str() == "_") {
+            continue;
+        }
+        imports.push(cap[2].to_string());
     }
 
     dedup(imports)
@@ -290,7 +309,18 @@ fn resolve_import(
         Language::Python => resolve_python(raw, importing_file, all_files_set, repo_root),
         Language::Java => resolve_java(raw, all_files_set),
         Language::CSharp => resolve_java(raw, all_files_set), // namespace-style, same strategy
-        Language::C | Language::CHeader => None,              // #include resolution not implemented
+        Language::C | Language::CHeader | Language::Cpp => None, // #include resolution not implemented
+        Language::Sql => None,
+        Language::Swift => None,
+        Language::Php => None,
+        Language::Scala => None,
+        Language::Dart => None,
+        Language::Elixir => None,
+        Language::Lua => None,
+        Language::Bash => None,
+        Language::Zig => None,
+        Language::Haskell => None,
+        Language::Perl => None,
     }
 }
 
@@ -887,6 +917,26 @@ import (
         assert!(imports.contains(&"github.com/user/project/pkg/utils".to_string()));
     }
 
+    #[test]
+    fn test_extract_go_imports_skips_blank_imports() {
+        let src = r#"
+import _ "github.com/user/project/pkg/migrations"
+import (
+    _ "net/http/pprof"
+    log "github.com/user/project/pkg/logging"
+    "github.com/user/project/pkg/utils"
+)
+"#;
+        let imports = extract_go_imports(src);
+        assert_eq!(
+            imports,
+            vec![
+                "github.com/user/project/pkg/logging".to_string(),
+                "github.com/user/project/pkg/utils".to_string(),
+            ]
+        );
+    }
+
     #[test]
     fn test_extract_rust_imports() {
         let src = r#"
@@ -0,0 +1,233 @@
+//! Incremental analysis cache shared by `hotspots daemon` and `analyze --watch`
+//!
+//! Results are cached per file and reused while the file's modification time,
+//! size, and the scoring config are unchanged, so re-analyzing a tree after an
+//! edit only parses the files that changed.
+//!
+//! Global invariants enforced:
+//! - Results are identical to in-process analysis of the same input
+//! - Deterministic output ordering
+
+use crate::config::ResolvedConfig;
+use crate::report::{sort_reports, FunctionRiskReport};
+use crate::{analysis, AnalysisOptions};
+use anyhow::Result;
+use serde::{Deserialize, Serialize};
+use std::collections::HashMap;
+use std::path::{Path, PathBuf};
+use std::sync::Mutex;
+use std::time::SystemTime;
+use swc_common::{sync::Lrc, SourceMap};
+
+/// How much of an `analyze_path` request was served from cache.
+#[derive(Debug, Clone, Copy, Serialize, Deserialize, PartialEq, Eq)]
+pub struct CacheStats {
+    /// Source files considered
+    pub files: usize,
+    /// Files whose results were reused without re-analysis
+    pub cache_hits: usize,
+}
+
+/// Unfiltered results for one file, valid while its stamp and config match.
+struct CachedFile {
+    modified: SystemTime,
+    len: u64,
+    config_key: String,
+    reports: Vec<FunctionRiskReport>,
+}
+
+/// Per-file analysis results, keyed by path.
+#[derive(Default)]
+pub struct IncrementalCache {
+    files: Mutex<HashMap<PathBuf, CachedFile>>,
+}
+
+impl IncrementalCache {
+    pub fn new() -> Self {
+        Self::default()
+    }
+
+    /// Analyze `path` like [`crate::analyze_with_progress`], reusing cached
+    /// per-file results where the file and config are unchanged.
+    pub fn analyze_path(
+        &self,
+        path: &Path,
+        resolved: &ResolvedConfig,
+        options: AnalysisOptions,
+    ) -> Result<(Vec<FunctionRiskReport>, CacheStats)> {
+        use rayon::prelude::*;
+
+        if !path.exists() {
+            anyhow::bail!("Path does not exist: {}", path.display());
+        }
+        // Symlink dedup rewrites paths across files, so it bypasses the cache
+        if resolved.dedup_symlinks && resolved.files.is_none() {
+            let files = crate::collect_source_files(path, resolved.gitignore)?.len();
+            let reports = crate::analyze_with_config(path, options, Some(resolved))?;
+            let stats = CacheStats {
+                files,
+                cache_hits: 0,
+            };
+            return Ok((reports, stats));
+        }
+
+        let files = crate::discover_source_files(path, Some(resolved))?;
+        let config_key = config_key(resolved);
+
+        // Stamp every file, and take cached results that are still valid
+        let mut fresh: Vec<(usize, PathBuf, Option<(SystemTime, u64)>)> = Vec::new();
+        let mut per_file: Vec<Option<Vec<FunctionRiskReport>>> = vec![None; files.len()];
+        {
+            let cache = self.files.lock().expect("incremental cache poisoned");
+            for (i, file) in files.iter().enumerate() {
+                let stamp = file_stamp(file);
+                match (stamp, cache.get(file)) {
+                    (Some((modified, len)), Some(entry))
+                        if entry.modified == modified
+                            && entry.len == len
+                            && entry.config_key == config_key =>
+                    {
+                        per_file[i] = Some(entry.reports.clone());
+                    }
+                    _ => fresh.push((i, file.clone(), stamp)),
+                }
+            }
+        }
+        let cache_hits = files.len() - fresh.len();
+
+        // Cache unfiltered results; min_lrs and top_n are applied per request
+        let unfiltered = AnalysisOptions {
+            min_lrs: None,
+            top_n: None,
+        };
+        let analyzed: Vec<_> = fresh
+            .par_iter()
+            .map(|(i, file, _)| {
+                let cm: Lrc<SourceMap> = Default::default();
+                analysis::analyze_file_with_config(file, &cm, *i, &unfiltered, Some(resolved))
+            })
+            .collect();
+
+        let mut cache = self.files.lock().expect("incremental cache poisoned");
+        for ((i, file, stamp), result) in fresh.into_iter().zip(analyzed) {
+            match result {
+                Ok(reports) => {
+                    if let Some((modified, len)) = stamp {
+                        cache.insert(
+                            file,
+                            CachedFile {
+                                modified,
+                                len,
+                                config_key: config_key.clone(),
+                                reports: reports.clone(),
+                            },
+                        );
+                    }
+                    per_file[i] = Some(reports);
+                }
+                Err(e) => {
+                    eprintln!("warning: skipping file {}: {}", file.display(), e);
+                    cache.remove(&file);
+                }
+            }
+        }
+        drop(cache);
+
+        let mut reports: Vec<FunctionRiskReport> =
+            per_file.into_iter().flatten().flatten().collect();
+        // Fan-in spans files, so it is never cached
+        if resolved.fan_in {
+            let root = resolved.root.as_deref().unwrap_or(Path::new("."));
+            crate::annotate_fan_in(&mut reports, root, &resolved.pattern_thresholds)?;
+        }
+        reports.retain(|r| options.min_lrs.map_or(true, |min| r.lrs >= min));
+        reports = sort_reports(reports);
+        if let Some(n) = options.top_n {
+            reports.truncate(n);
+        }
+        let stats = CacheStats {
+            files: files.len(),
+            cache_hits,
+        };
+        Ok((reports, stats))
+    }
+}
+
+/// Whether a change to `path` can affect results under `resolved`: a supported
+/// source file, outside always-skipped directories, that passes the config's
+/// include/exclude filters. Gitignore rules are left to the next analysis.
+pub fn is_analyzed_path(path: &Path, resolved: &ResolvedConfig) -> bool {
+    let supported = path
+        .file_name()
+        .and_then(|n| n.to_str())
+        .is_some_and(crate::is_supported_source_file);
+    let skipped = path
+        .components()
+        .filter_map(|c| c.as_os_str().to_str())
+        .any(crate::is_skipped_dir);
+    supported && !skipped && resolved.should_include(path)
+}
+
+/// Modification time and size, or None when the file cannot be stat'ed.
+fn file_stamp(path: &Path) -> Option<(SystemTime, u64)> {
+    let meta = std::fs::metadata(path).ok()?;
+    Some((meta.modified().ok()?, meta.len()))
+}
+
+/// Everything in the config that changes per-file results.
+pub(crate) fn config_key(resolved: &ResolvedConfig) -> String {
+    format!(
+        "{:?}",
+        (
+            [
+                resolved.weight_cc,
+                resolved.weight_nd,
+                resolved.weight_fo,
+                resolved.weight_ns,
+                resolved.moderate_threshold,
+                resolved.high_threshold,
+                resolved.critical_threshold,
+            ],
+            &resolved.pattern_thresholds,
+            resolved.sql_dialect,
+            resolved.cc_mode,
+            resolved.anon_naming,
+            &resolved.nd_counts,
+            [
+                resolved.public_only,
+                resolved.include_tests,
+                resolved.halstead,
+                resolved.line_counts,
+                resolved.nd_lines,
+                resolved.cc_lines,
+                resolved.separate_closures,
+                resolved.ns_breakdown,
+                resolved.fo_methods,
+            ],
+        )
+    )
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn test_is_analyzed_path() {
+        let resolved = ResolvedConfig::defaults().unwrap();
+        assert!(is_analyzed_path(Path::new("src/app.ts"), &resolved));
+        assert!(!is_analyzed_path(Path::new("README.md"), &resolved));
+        assert!(!is_analyzed_path(
+            Path::new("node_modules/pkg/index.js"),
+            &resolved
+        ));
+    }
+
+    #[test]
+    fn test_config_key_covers_fo_methods() {
+        let mut resolved = ResolvedConfig::defaults().unwrap();
+        let key = config_key(&resolved);
+        resolved.fo_methods = false;
+        assert_ne!(config_key(&resolved), key);
+    }
+}
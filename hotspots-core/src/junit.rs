@@ -0,0 +1,431 @@
+//! JUnit XML output (`--format junit`)
+//!
+//! Threshold checks become testcases so CI test reporters can track them:
+//! a check fails when a metric is at or above its per-metric threshold (the
+//! same rules SARIF uses, config key `sarif`). Metrics whose level is `none`
+//! are not checked. Suppressed functions are reported as skipped.
+//!
+//! Global invariants enforced:
+//! - Deterministic output ordering (follows the input report order)
+
+use crate::report::{FunctionRiskReport, MetricsReport};
+use crate::sarif::{MetricRules, SarifLevel};
+use std::path::Path;
+
+/// How testcases map onto functions and metrics.
+#[derive(Debug, Clone, Copy, PartialEq, Eq, Default)]
+pub enum JunitGranularity {
+    /// One testcase per function, grouped into a testsuite per file; it fails
+    /// if any metric breaches its threshold.
+    #[default]
+    Function,
+    /// One testcase per (function, metric), grouped into a testsuite per metric.
+    Metric,
+}
+
+struct TestCase {
+    name: String,
+    classname: String,
+    file: String,
+    line: u32,
+    /// `(type, message)` when the check failed
+    failure: Option<(String, String)>,
+    skipped: Option<String>,
+}
+
+struct TestSuite {
+    name: String,
+    cases: Vec<TestCase>,
+}
+
+impl TestSuite {
+    fn failures(&self) -> usize {
+        self.cases.iter().filter(|c| c.failure.is_some()).count()
+    }
+
+    fn skipped(&self) -> usize {
+        self.cases.iter().filter(|c| c.skipped.is_some()).count()
+    }
+}
+
+/// A metric at or above its per-metric threshold
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub struct Breach {
+    pub metric: &'static str,
+    pub value: u32,
+    pub threshold: u32,
+}
+
+/// The checks `report` fails: metrics at or above their threshold, skipping
+/// rules whose level is `none`. A suppressed function fails none.
+pub fn breaches(report: &FunctionRiskReport, rules: &MetricRules) -> Vec<Breach> {
+    if report.suppression_reason.is_some() {
+        return Vec::new();
+    }
+    rules
+        .iter()
+        .filter(|(_, rule)| rule.level != SarifLevel::None)
+        .map(|(metric, rule)| Breach {
+            metric,
+            value: metric_value(&report.metrics, metric),
+            threshold: rule.threshold,
+        })
+        .filter(|b| b.value >= b.threshold)
+        .collect()
+}
+
+/// Render reports as a JUnit XML document.
+///
+/// File paths are shown relative to `base` when they fall under it.
+pub fn render_junit(
+    reports: &[FunctionRiskReport],
+    base: &Path,
+    rules: &MetricRules,
+    granularity: JunitGranularity,
+) -> String {
+    let checked: Vec<(&'static str, u32)> = rules
+        .iter()
+        .filter(|(_, rule)| rule.level != SarifLevel::None)
+        .map(|(metric, rule)| (metric, rule.threshold))
+        .collect();
+
+    let suites = match granularity {
+        JunitGranularity::Function => {
+            // Suites in order of each file's first function
+            let mut suites: Vec<TestSuite> = Vec::new();
+            for report in reports {
+                let case = function_case(report, base, rules);
+                match suites.iter_mut().find(|s| s.name == case.classname) {
+                    Some(suite) => suite.cases.push(case),
+                    None => suites.push(TestSuite {
+                        name: case.classname.clone(),
+                        cases: vec![case],
+                    }),
+                }
+            }
+            suites
+        }
+        JunitGranularity::Metric => checked
+            .iter()
+            .map(|&(metric, threshold)| TestSuite {
+                name: format!("hotspots.{metric}"),
+                cases: reports
+                    .iter()
+                    .map(|r| metric_case(r, base, metric, threshold))
+                    .collect(),
+            })
+            .collect(),
+    };
+
+    render_suites(&suites)
+}
+
+fn function_case(report: &FunctionRiskReport, base: &Path, rules: &MetricRules) -> TestCase {
+    let file = display_path(&report.file, base);
+    let breaches = breaches(report, rules);
+    let failure = (!breaches.is_empty()).then(|| {
+        let kinds: Vec<&str> = breaches.iter().map(|b| b.metric).collect();
+        let message: Vec<String> = breaches
+            .iter()
+            .map(|b| format!("{} {} >= {}", b.metric, b.value, b.threshold))
+            .collect();
+        (kinds.join(","), message.join("; "))
+    });
+    TestCase {
+        name: format!("{file}::{}", report.function),
+        classname: file.clone(),
+        file,
+        line: report.line,
+        failure,
+        skipped: report.suppression_reason.clone(),
+    }
+}
+
+fn metric_case(report: &FunctionRiskReport, base: &Path, metric: &str, threshold: u32) -> TestCase {
+    let file = display_path(&report.file, base);
+    let value = metric_value(&report.metrics, metric);
+    let failure = (report.suppression_reason.is_none() && value >= threshold).then(|| {
+        (
+            metric.to_string(),
+            format!("{metric} {value} >= {threshold}"),
+        )
+    });
+    TestCase {
+        name: format!("{file}::{}::{metric}", report.function),
+        classname: format!("hotspots.{metric}"),
+        file,
+        line: report.line,
+        failure,
+        skipped: report.suppression_reason.clone(),
+    }
+}
+
+fn metric_value(metrics: &MetricsReport, metric: &str) -> u32 {
+    match metric {
+        "cc" => metrics.cc,
+        "nd" => metrics.nd,
+        "fo" => metrics.fo,
+        "ns" => metrics.ns,
+        "signature_complexity" => metrics.signature_complexity,
+        "max_condition_ops" => metrics.max_condition_ops,
+        _ => metrics.loc,
+    }
+}
+
+fn display_path(file: &str, base: &Path) -> String {
+    Path::new(file)
+        .strip_prefix(base)
+        .map(|p| p.to_string_lossy().replace('\\', "/"))
+        .unwrap_or_else(|_| file.to_string())
+}
+
+fn render_suites(suites: &[TestSuite]) -> String {
+    let tests: usize = suites.iter().map(|s| s.cases.len()).sum();
+    let failures: usize = suites.iter().map(TestSuite::failures).sum();
+    let skipped: usize = suites.iter().map(TestSuite::skipped).sum();
+
+    let mut out = String::new();
+    out.push_str("<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n");
+    out.push_str(&format!(
+        "<testsuites name=\"hotspots\" tests=\"{tests}\" failures=\"{failures}\" skipped=\"{skipped}\">\n"
+    ));
+    for suite in suites {
+        out.push_str(&format!(
+            "  <testsuite name=\"{}\" tests=\"{}\" failures=\"{}\" skipped=\"{}\">\n",
+            xml_escape(&suite.name),
+            suite.cases.len(),
+            suite.failures(),
+            suite.skipped()
+        ));
+        for case in &suite.cases {
+            let open = format!(
+                "    <testcase name=\"{}\" classname=\"{}\" file=\"{}\" line=\"{}\"",
+                xml_escape(&case.name),
+                xml_escape(&case.classname),
+                xml_escape(&case.file),
+                case.line
+            );
+            match (&case.failure, &case.skipped) {
+                (_, Some(reason)) => out.push_str(&format!(
+                    "{open}>\n      <skipped message=\"{}\"/>\n    </testcase>\n",
+                    xml_escape(reason)
+                )),
+                (Some((kind, message)), None) => out.push_str(&format!(
+                    "{open}>\n      <failure type=\"{}\" message=\"{}\"/>\n    </testcase>\n",
+                    xml_escape(kind),
+                    xml_escape(message)
+                )),
+                (None, None) => out.push_str(&format!("{open}/>\n")),
+            }
+        }
+        out.push_str("  </testsuite>\n");
+    }
+    out.push_str("</testsuites>\n");
+    out
+}
+
+/// Escape XML special characters for attribute values. Control characters
+/// XML 1.0 cannot represent at all become U+FFFD.
+fn xml_escape(s: &str) -> String {
+    let mut out = String::with_capacity(s.len());
+    for c in s.chars() {
+        match c {
+            '&' => out.push_str("&amp;"),
+            '<' => out.push_str("&lt;"),
+            '>' => out.push_str("&gt;"),
+            '"' => out.push_str("&quot;"),
+            '\'' => out.push_str("&apos;"),
+            '\t' | '\n' | '\r' => out.push(c),
+            c if c < '\u{20}' => out.push('\u{FFFD}'),
+            c => out.push(c),
+        }
+    }
+    out
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::language::Language;
+    use crate::report::RiskReport;
+    use crate::risk::RiskBand;
+
+    fn make_report(function: &str, cc: u32, nd: u32) -> FunctionRiskReport {
+        FunctionRiskReport {
+            file: "/repo/src/a.ts".to_string(),
+            function: function.to_string(),
+            owner: None,
+            line: 10,
+            language: Language::TypeScript,
+            metrics: MetricsReport {
+                cc,
+                nd,
+                fo: 1,
+                fi: 0,
+                ns: 0,
+                loc: 12,
+                signature_complexity: 0,
+                params: 0,
+                guard_clauses: 0,
+                max_condition_ops: 0,
+                cognitive: 0,
+                halstead: None,
+                maintainability: None,
+                sloc: None,
+                comment_lines: None,
+                blank_lines: None,
+                nd_line: None,
+                cc_lines: None,
+                is_async: false,
+                await_in_loop: 0,
+                ns_breakdown: None,
+            },
+            risk: RiskReport {
+                r_cc: 1.0,
+                r_nd: 1.0,
+                r_fo: 0.0,
+                r_ns: 0.0,
+            },
+            lrs: 5.0,
+            band: RiskBand::Moderate,
+            risk_score: None,
+            suppression_reason: None,
+            patterns: vec![],
+            pattern_details: None,
+            callees: vec![],
+            explanation: None,
+            arrow_depth: 0,
+            aliases: vec![],
+            structure: None,
+            cc_breakdown: None,
+            is_public: false,
+            file_top_level_decisions: 0,
+        }
+    }
+
+    fn count(xml: &str, needle: &str) -> usize {
+        xml.matches(needle).count()
+    }
+
+    #[test]
+    fn test_metric_granularity_case_per_checked_metric() {
+        // cc 20 >= 15 and nd 6 >= 5 breach; fo, ns, loc, signature_complexity,
+        // max_condition_ops pass
+        let reports = vec![make_report("handler", 20, 6)];
+        let xml = render_junit(
+            &reports,
+            Path::new("/repo"),
+            &MetricRules::default(),
+            JunitGranularity::Metric,
+        );
+        assert_eq!(count(&xml, "<testcase "), 7);
+        assert_eq!(count(&xml, "<failure "), 2);
+        assert_eq!(count(&xml, "<testsuite "), 7);
+        assert!(xml.contains("<testcase name=\"src/a.ts::handler::cc\" classname=\"hotspots.cc\""));
+        assert!(xml.contains("<failure type=\"nd\" message=\"nd 6 &gt;= 5\"/>"));
+        assert!(xml.contains("tests=\"7\" failures=\"2\" skipped=\"0\""));
+    }
+
+    #[test]
+    fn test_function_granularity_single_case() {
+        let reports = vec![make_report("handler", 20, 6), make_report("ok", 1, 0)];
+        let xml = render_junit(
+            &reports,
+            Path::new("/repo"),
+            &MetricRules::default(),
+            JunitGranularity::Function,
+        );
+        assert_eq!(count(&xml, "<testcase "), 2);
+        assert_eq!(count(&xml, "<failure "), 1);
+        assert!(xml.contains("<failure type=\"cc,nd\" message=\"cc 20 &gt;= 15; nd 6 &gt;= 5\"/>"));
+        assert!(xml.contains("<testcase name=\"src/a.ts::ok\" classname=\"src/a.ts\" file=\"src/a.ts\" line=\"10\"/>"));
+    }
+
+    #[test]
+    fn test_function_granularity_suite_per_file_is_well_formed() {
+        let mut other = make_report("Map<K, V>::get \"x\" & 'y'\u{1}", 20, 0);
+        other.file = "/repo/src/b.ts".to_string();
+        let reports = vec![
+            make_report("handler", 20, 6),
+            other,
+            make_report("ok", 1, 0),
+        ];
+        let xml = render_junit(
+            &reports,
+            Path::new("/repo"),
+            &MetricRules::default(),
+            JunitGranularity::Function,
+        );
+
+        let doc = roxmltree::Document::parse(&xml).expect("well-formed XML");
+        let root = doc.root_element();
+        assert_eq!(root.attribute("tests"), Some("3"));
+        assert_eq!(root.attribute("failures"), Some("2"));
+        let suites: Vec<_> = root.children().filter(|n| n.is_element()).collect();
+        let names: Vec<_> = suites.iter().map(|s| s.attribute("name")).collect();
+        assert_eq!(names, [Some("src/a.ts"), Some("src/b.ts")]);
+        assert_eq!(suites[0].attribute("tests"), Some("2"));
+        assert_eq!(suites[0].attribute("failures"), Some("1"));
+
+        let case = suites[1]
+            .children()
+            .find(|n| n.has_tag_name("testcase"))
+            .unwrap();
+        assert_eq!(
+            case.attribute("name"),
+            Some("src/b.ts::Map<K, V>::get \"x\" & 'y'\u{FFFD}")
+        );
+        let failure = case.children().find(|n| n.has_tag_name("failure"));
+        assert_eq!(
+            failure.and_then(|f| f.attribute("message")),
+            Some("cc 20 >= 15")
+        );
+    }
+
+    #[test]
+    fn test_disabled_metric_not_checked() {
+        let mut rules = MetricRules::default();
+        rules.nd.level = SarifLevel::None;
+        let xml = render_junit(
+            &[make_report("handler", 20, 6)],
+            Path::new("/repo"),
+            &rules,
+            JunitGranularity::Metric,
+        );
+        assert_eq!(count(&xml, "<testcase "), 6);
+        assert!(!xml.contains("hotspots.nd"));
+    }
+
+    #[test]
+    fn test_suppressed_function_skipped() {
+        let mut report = make_report("legacy", 30, 8);
+        report.suppression_reason = Some("legacy <code>".to_string());
+        let xml = render_junit(
+            &[report],
+            Path::new("/repo"),
+            &MetricRules::default(),
+            JunitGranularity::Function,
+        );
+        assert_eq!(count(&xml, "<failure "), 0);
+        assert!(xml.contains("<skipped message=\"legacy &lt;code&gt;\"/>"));
+    }
+
+    #[test]
+    fn test_breaches_of_checked_metrics() {
+        let mut rules = MetricRules::default();
+        rules.nd.level = SarifLevel::None;
+        assert_eq!(
+            breaches(&make_report("handler", 20, 6), &rules),
+            vec![Breach {
+                metric: "cc",
+                value: 20,
+                threshold: 15
+            }]
+        );
+        assert!(breaches(&make_report("ok", 1, 0), &rules).is_empty());
+
+        let mut suppressed = make_report("legacy", 30, 8);
+        suppressed.suppression_reason = Some("legacy".to_string());
+        assert!(breaches(&suppressed, &rules).is_empty());
+    }
+}
@@ -0,0 +1,344 @@
+//! Bash CFG builder implementation
+//!
+//! `;&` fall-through between `case` clauses is not modeled, as with `switch`
+//! in the C builder.
+
+use crate::ast::FunctionNode;
+use crate::cfg::{Cfg, NodeId, NodeKind};
+use crate::language::bash::{
+    body_statements, branch_statements, case_item_statements, case_items, command_name,
+    find_function, group_statements, if_alternatives, is_catch_all, loop_body, pipeline_commands,
+};
+use crate::language::cfg_builder::{CfgBuilder, CfgState};
+use crate::language::tree_sitter_utils::with_cached_bash_tree;
+use tree_sitter::Node;
+
+/// Bash CFG builder
+pub struct BashCfgBuilder;
+
+impl CfgBuilder for BashCfgBuilder {
+    fn build(&self, function: &FunctionNode) -> Cfg {
+        let (_body_node_id, source) = function.body.as_bash();
+
+        let result = with_cached_bash_tree(source, |root| {
+            let func_node = find_function(root, function.span.start)?;
+            let mut builder = BashCfgBuilderState {
+                flow: CfgState::new(),
+                loop_depths: Vec::new(),
+                subshell_stack: Vec::new(),
+                source,
+            };
+            builder.visit_statements(&body_statements(func_node));
+            Some(builder.flow.finish())
+        });
+
+        result.unwrap_or_else(CfgState::straight_line)
+    }
+}
+
+struct BashCfgBuilderState<'s> {
+    flow: CfgState,
+    /// How many subshells enclosed each enclosing loop, innermost last: a
+    /// jump out of a subshell only leaves the subshell
+    loop_depths: Vec<usize>,
+    /// Joins after the enclosing subshells, innermost last: where their
+    /// `exit` and `return` go
+    subshell_stack: Vec<NodeId>,
+    source: &'s str,
+}
+
+impl BashCfgBuilderState<'_> {
+    fn visit_statements(&mut self, statements: &[Node]) {
+        for stmt in statements {
+            self.visit_node(stmt);
+        }
+    }
+
+    fn visit_node(&mut self, node: &Node) {
+        match node.kind() {
+            "if_statement" => self.visit_if(node),
+            "while_statement" | "for_statement" | "c_style_for_statement" => self.visit_loop(node),
+            "case_statement" => self.visit_case(node),
+            "compound_statement" => self.visit_statements(&group_statements(*node)),
+            "subshell" => self.visit_subshell(&group_statements(*node)),
+            "pipeline" => {
+                for command in pipeline_commands(*node) {
+                    self.visit_subshell(&[command]);
+                }
+            }
+            "redirected_statement" => match node.child_by_field_name("body") {
+                Some(body) => self.visit_node(&body),
+                None => self.flow.statement(),
+            },
+            "command" => match command_name(*node, self.source) {
+                Some("return") | Some("exit") => self.visit_return(),
+                Some("break") => self.visit_jump(CfgState::jump_to_break),
+                Some("continue") => self.visit_jump(CfgState::jump_to_continue),
+                _ => self.flow.statement(),
+            },
+            // Function definitions are discovered on their own
+            _ => self.flow.statement(),
+        }
+    }
+
+    fn visit_branch(&mut self, from: NodeId, statements: &[Node], join: &mut Option<NodeId>) {
+        self.flow.start_branch(from);
+        self.visit_statements(statements);
+        self.flow.fall_through(join);
+    }
+
+    /// `if` / `elif` / `else`: each `elif` is a further condition tested
+    /// when the previous one is false
+    fn visit_if(&mut self, node: &Node) {
+        let Some(mut condition_node) = self.flow.add_after(NodeKind::Condition) else {
+            return;
+        };
+
+        let mut join_node = None;
+        self.visit_branch(condition_node, &branch_statements(*node), &mut join_node);
+
+        let mut has_else = false;
+        for clause in if_alternatives(*node) {
+            if clause.kind() == "elif_clause" {
+                let next_condition = self.flow.cfg.add_node(NodeKind::Condition);
+                self.flow.cfg.add_edge(condition_node, next_condition);
+                condition_node = next_condition;
+            } else {
+                has_else = true;
+            }
+            self.visit_branch(condition_node, &branch_statements(clause), &mut join_node);
+        }
+        if !has_else {
+            self.flow.skip_branches(condition_node, &mut join_node);
+        }
+        self.flow.current_node = join_node;
+    }
+
+    /// `while`, `until`, `for`, `select`, and C-style `for`
+    fn visit_loop(&mut self, node: &Node) {
+        let Some(header) = self.flow.start_loop() else {
+            return;
+        };
+        self.loop_depths.push(self.subshell_stack.len());
+        self.visit_statements(&loop_body(*node));
+        self.loop_depths.pop();
+        self.flow.end_loop(header);
+    }
+
+    /// `case`: one branch per pattern clause, and a path past the `case` when
+    /// no clause is a catch-all `*)`
+    fn visit_case(&mut self, node: &Node) {
+        let Some(case_node) = self.flow.add_after(NodeKind::Condition) else {
+            return;
+        };
+
+        let mut join_node = None;
+        let mut has_catch_all = false;
+        for item in case_items(*node) {
+            has_catch_all |= is_catch_all(item, self.source);
+            self.visit_branch(case_node, &case_item_statements(item), &mut join_node);
+        }
+        if !has_catch_all {
+            self.flow.skip_branches(case_node, &mut join_node);
+        }
+        self.flow.current_node = join_node;
+    }
+
+    /// `( ... )` or one command of a pipeline: runs in its own process, so
+    /// `exit` and `return` inside it continue after the subshell
+    fn visit_subshell(&mut self, statements: &[Node]) {
+        if self.flow.add_after(NodeKind::Statement).is_none() {
+            return;
+        }
+        let end = self.flow.cfg.add_node(NodeKind::Join);
+
+        self.subshell_stack.push(end);
+        self.visit_statements(statements);
+        self.flow.fall_through_to(end);
+        self.subshell_stack.pop();
+
+        self.flow.current_node = Some(end);
+    }
+
+    /// `return` and `exit` leave the function, or the innermost subshell
+    fn visit_return(&mut self) {
+        match self.subshell_stack.last() {
+            Some(&end) => self.flow.jump(end),
+            None => self.flow.jump_to_exit(),
+        }
+    }
+
+    /// `break` or `continue` the innermost loop. Outside a loop, or across a
+    /// subshell boundary, it leaves like `return`.
+    fn visit_jump(&mut self, jump: fn(&mut CfgState)) {
+        if self.loop_depths.last() == Some(&self.subshell_stack.len()) {
+            jump(&mut self.flow);
+        } else {
+            self.visit_return();
+        }
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::language::parser::LanguageParser;
+    use crate::language::BashParser;
+
+    /// CC of the first function in `source`
+    fn cc(source: &str) -> usize {
+        let module = BashParser::new().unwrap().parse(source, "test.sh").unwrap();
+        let function = module
+            .discover_functions(0, source)
+            .into_iter()
+            .next()
+            .expect("No function found in test source");
+        let cfg = BashCfgBuilder.build(&function);
+        assert!(
+            cfg.validate().is_ok(),
+            "CFG must be valid: {:?}",
+            cfg.validate()
+        );
+        // CC = E - N + 2
+        (cfg.edge_count() as isize - cfg.node_count() as isize + 2).max(1) as usize
+    }
+
+    #[test]
+    fn test_simple_function() {
+        assert_eq!(cc("greet() {\n  echo \"hello $1\"\n}\n"), 1);
+        assert_eq!(cc("function noop { :; }\n"), 1);
+    }
+
+    #[test]
+    fn test_elif_chain() {
+        let source = r#"
+sign() {
+  if [ "$1" -gt 0 ]; then
+    echo 1
+  elif [ "$1" -lt 0 ]; then
+    echo -1
+  else
+    echo 0
+  fi
+}
+"#;
+        assert_eq!(cc(source), 3);
+    }
+
+    #[test]
+    fn test_elif_without_else() {
+        let source = r#"
+label() {
+  local s=other
+  if [ "$1" = 1 ]; then
+    s=one
+  elif [ "$1" = 2 ]; then
+    s=two
+  elif [ "$1" = 3 ]; then
+    s=three
+  fi
+  echo "$s"
+}
+"#;
+        assert_eq!(cc(source), 4);
+    }
+
+    #[test]
+    fn test_loops() {
+        let source = r#"
+loops() {
+  for f in "$@"; do
+    [ -f "$f" ] || continue
+    echo "$f"
+  done
+  local n=0
+  while [ "$n" -lt 10 ]; do
+    n=$((n + 1))
+    if [ "$n" -eq 5 ]; then break; fi
+  done
+  until [ "$n" -le 0 ]; do
+    n=$((n - 1))
+  done
+  for ((i = 0; i < 3; i++)); do
+    echo "$i"
+  done
+}
+"#;
+        assert_eq!(cc(source), 6);
+    }
+
+    #[test]
+    fn test_case_with_catch_all() {
+        let source = r#"
+dispatch() {
+  case "$1" in
+    start) run_start ;;
+    stop|halt) run_stop ;;
+    *) usage; return 1 ;;
+  esac
+}
+"#;
+        assert_eq!(cc(source), 3);
+    }
+
+    #[test]
+    fn test_case_without_catch_all() {
+        let source = r#"
+dispatch() {
+  case "$1" in
+    start) run_start ;;
+    stop) run_stop ;;
+  esac
+  echo done
+}
+"#;
+        assert_eq!(cc(source), 3);
+    }
+
+    #[test]
+    fn test_subshell_exit_stays_in_subshell() {
+        let source = r#"
+build() {
+  (
+    cd "$1" || exit 1
+    if [ ! -f Makefile ]; then
+      exit 0
+    fi
+    make
+  )
+  echo built
+}
+"#;
+        assert_eq!(cc(source), 2);
+    }
+
+    #[test]
+    fn test_pipeline_commands_run_in_subshells() {
+        let source = r#"
+count() {
+  grep -v '^#' "$1" | while read -r line; do
+    if [ "$line" = stop ]; then
+      exit 1
+    fi
+    echo "$line"
+  done
+  echo finished
+}
+"#;
+        assert_eq!(cc(source), 3);
+    }
+
+    #[test]
+    fn test_nested_definitions_are_not_entered() {
+        let source = r#"
+outer() {
+  inner() {
+    if [ -n "$1" ]; then return 0; fi
+    return 1
+  }
+  inner "$@"
+}
+"#;
+        assert_eq!(cc(source), 1);
+    }
+}
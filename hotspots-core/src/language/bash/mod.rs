@@ -0,0 +1,195 @@
+//! Bash language support
+//!
+//! Parses shell scripts (`.sh`, `.bash`) using tree-sitter-bash; code at the
+//! top level of a script is not a function and is not measured.
+
+pub mod cfg_builder;
+pub mod parser;
+
+pub use cfg_builder::BashCfgBuilder;
+pub use parser::BashParser;
+
+use crate::language::tree_sitter_utils::find_function_by_start;
+use tree_sitter::Node;
+
+/// Node kinds of a discovered function
+pub(crate) const FUNCTION_KINDS: &[&str] = &["function_definition"];
+
+/// Commands that leave the function, the script, or a loop
+pub(crate) const EXIT_COMMANDS: &[&str] = &["return", "exit", "break", "continue"];
+
+/// Nodes that run their contents in a subshell
+pub(crate) const SUBSHELL_KINDS: &[&str] = &[
+    "subshell",
+    "pipeline",
+    "command_substitution",
+    "process_substitution",
+];
+
+/// The function starting at `start_byte`
+pub(crate) fn find_function(root: Node<'_>, start_byte: usize) -> Option<Node<'_>> {
+    find_function_by_start(root, start_byte, FUNCTION_KINDS)
+}
+
+/// Function definitions, which are discovered on their own and are not part
+/// of the enclosing function's metrics
+pub(crate) fn is_nested_definition(node: Node<'_>) -> bool {
+    FUNCTION_KINDS.contains(&node.kind())
+}
+
+/// Source text of `node`
+pub(crate) fn text<'a>(node: Node<'_>, source: &'a str) -> &'a str {
+    &source[node.start_byte()..node.end_byte()]
+}
+
+/// The name a simple command runs (`echo`, `return`, `$cmd`), or None for
+/// any other node
+pub(crate) fn command_name<'a>(node: Node<'_>, source: &'a str) -> Option<&'a str> {
+    if node.kind() != "command" {
+        return None;
+    }
+    let name = node.child_by_field_name("name")?;
+    Some(text(name, source).trim())
+}
+
+/// Statements of a group (`{ ... }`, `( ... )`, `do ... done`), skipping
+/// comments, or the node itself for a single statement
+pub(crate) fn group_statements(node: Node<'_>) -> Vec<Node<'_>> {
+    if !matches!(node.kind(), "compound_statement" | "subshell" | "do_group") {
+        return vec![node];
+    }
+    let mut cursor = node.walk();
+    let statements = node
+        .named_children(&mut cursor)
+        .filter(|child| !child.kind().contains("comment"))
+        .collect();
+    statements
+}
+
+/// The statements a function body runs
+pub(crate) fn body_statements(func_node: Node<'_>) -> Vec<Node<'_>> {
+    func_node
+        .child_by_field_name("body")
+        .map(group_statements)
+        .unwrap_or_default()
+}
+
+/// The statements after `then` of an `if` or `elif`, or all statements of an
+/// `else`, up to the first `elif` / `else` clause
+pub(crate) fn branch_statements(clause: Node<'_>) -> Vec<Node<'_>> {
+    let mut statements = Vec::new();
+    let mut after_then = clause.kind() == "else_clause";
+    let mut cursor = clause.walk();
+    for child in clause.children(&mut cursor) {
+        match child.kind() {
+            "then" => after_then = true,
+            "elif_clause" | "else_clause" => break,
+            kind if after_then && child.is_named() && !kind.contains("comment") => {
+                statements.push(child)
+            }
+            _ => {}
+        }
+    }
+    statements
+}
+
+/// The `elif` and `else` clauses of an `if` statement, in order
+pub(crate) fn if_alternatives(node: Node<'_>) -> Vec<Node<'_>> {
+    let mut cursor = node.walk();
+    let alternatives = node
+        .named_children(&mut cursor)
+        .filter(|child| matches!(child.kind(), "elif_clause" | "else_clause"))
+        .collect();
+    alternatives
+}
+
+/// The body of a loop: its `do ... done` group, or `{ ... }` for a C-style
+/// `for`
+pub(crate) fn loop_body_node(node: Node<'_>) -> Option<Node<'_>> {
+    if let Some(body) = node.child_by_field_name("body") {
+        return Some(body);
+    }
+    let mut cursor = node.walk();
+    let body = node
+        .named_children(&mut cursor)
+        .find(|child| matches!(child.kind(), "do_group" | "compound_statement"));
+    body
+}
+
+/// Statements of a loop's body
+pub(crate) fn loop_body(node: Node<'_>) -> Vec<Node<'_>> {
+    loop_body_node(node)
+        .map(group_statements)
+        .unwrap_or_default()
+}
+
+/// The pattern clauses of a `case` statement
+pub(crate) fn case_items(node: Node<'_>) -> Vec<Node<'_>> {
+    let mut cursor = node.walk();
+    let items = node
+        .named_children(&mut cursor)
+        .filter(|child| child.kind() == "case_item")
+        .collect();
+    items
+}
+
+/// Statements of a `case` clause: the ones after its `)`
+pub(crate) fn case_item_statements(item: Node<'_>) -> Vec<Node<'_>> {
+    let mut statements = Vec::new();
+    let mut after_paren = false;
+    let mut cursor = item.walk();
+    for child in item.children(&mut cursor) {
+        if child.kind() == ")" && !after_paren {
+            after_paren = true;
+        } else if after_paren && child.is_named() && !child.kind().contains("comment") {
+            statements.push(child);
+        }
+    }
+    statements
+}
+
+/// Whether a `case` clause matches anything (`*)`), so that no value falls
+/// past the `case`
+pub(crate) fn is_catch_all(item: Node<'_>, source: &str) -> bool {
+    let mut cursor = item.walk();
+    let catch_all = item
+        .children_by_field_name("value", &mut cursor)
+        .any(|pattern| text(pattern, source) == "*");
+    catch_all
+}
+
+/// The children of `node` the CFG descends into: the body of a function,
+/// group, or loop, the branches of an `if` or `case`, and each command of a
+/// pipeline. Anything else, such as the commands joined by `&&` and `||`, is
+/// part of a single statement.
+pub(crate) fn cfg_children(node: Node<'_>) -> Vec<Node<'_>> {
+    match node.kind() {
+        "function_definition" | "redirected_statement" => {
+            node.child_by_field_name("body").into_iter().collect()
+        }
+        "while_statement" | "for_statement" | "c_style_for_statement" => {
+            loop_body_node(node).into_iter().collect()
+        }
+        "compound_statement" | "subshell" | "do_group" => group_statements(node),
+        "if_statement" => {
+            let mut children = branch_statements(node);
+            children.extend(if_alternatives(node));
+            children
+        }
+        "elif_clause" | "else_clause" => branch_statements(node),
+        "case_statement" => case_items(node),
+        "case_item" => case_item_statements(node),
+        "pipeline" => pipeline_commands(node),
+        _ => Vec::new(),
+    }
+}
+
+/// The commands of a pipeline, each of which runs in its own subshell
+pub(crate) fn pipeline_commands(node: Node<'_>) -> Vec<Node<'_>> {
+    let mut cursor = node.walk();
+    let commands = node
+        .named_children(&mut cursor)
+        .filter(|child| !child.kind().contains("comment"))
+        .collect();
+    commands
+}
@@ -0,0 +1,250 @@
+//! Bash language parser using tree-sitter
+
+use crate::ast::FunctionNode;
+use crate::language::bash::{is_nested_definition, text};
+use crate::language::parser::{LanguageParser, ParsedModule};
+use crate::language::tree_sitter_utils::syntax_errors;
+use anyhow::{Context, Result};
+use tree_sitter::{Node, Parser, Tree};
+
+/// Bash parser using tree-sitter
+pub struct BashParser;
+
+impl BashParser {
+    /// Create a new Bash parser
+    pub fn new() -> Result<Self> {
+        let mut parser = Parser::new();
+        let language = tree_sitter_bash::LANGUAGE;
+        parser
+            .set_language(&language.into())
+            .context("Failed to set Bash language for parser")?;
+        Ok(BashParser)
+    }
+}
+
+impl Default for BashParser {
+    fn default() -> Self {
+        Self::new().expect("Failed to create Bash parser")
+    }
+}
+
+impl LanguageParser for BashParser {
+    fn parse(&self, source: &str, filename: &str) -> Result<Box<dyn ParsedModule>> {
+        let mut parser = Parser::new();
+        let language = tree_sitter_bash::LANGUAGE;
+        parser
+            .set_language(&language.into())
+            .context("Failed to set Bash language")?;
+
+        let tree = parser
+            .parse(source, None)
+            .ok_or_else(|| anyhow::anyhow!("Failed to parse Bash file: {}", filename))?;
+
+        Ok(Box::new(BashModule {
+            tree,
+            source: source.to_string(),
+        }))
+    }
+}
+
+/// Parsed Bash module
+struct BashModule {
+    tree: Tree,
+    source: String,
+}
+
+impl ParsedModule for BashModule {
+    fn discover_functions(&self, file_index: usize, _source: &str) -> Vec<FunctionNode> {
+        let root = self.tree.root_node();
+        let mut functions = Vec::new();
+        discover_functions_recursive(root, &self.source, file_index, true, &mut functions);
+        functions.sort_by_key(|f| f.span.start);
+        functions
+    }
+
+    fn syntax_errors(&self) -> Vec<std::ops::Range<usize>> {
+        syntax_errors(self.tree.root_node())
+    }
+
+    fn top_level_decisions(&self) -> usize {
+        crate::metrics::bash_top_level_decisions(&self.tree.root_node(), &self.source)
+    }
+}
+
+/// Recursively discover function definitions in the Bash AST, including ones
+/// defined inside other functions.
+///
+/// `top_level` is whether `node` is outside every function body: only
+/// functions defined there exist once the script has been sourced.
+fn discover_functions_recursive(
+    node: Node,
+    source: &str,
+    file_index: usize,
+    top_level: bool,
+    functions: &mut Vec<FunctionNode>,
+) {
+    let is_function = is_nested_definition(node);
+    if is_function {
+        let function_node = extract_function(node, source, file_index, functions.len(), top_level);
+        functions.push(function_node);
+    }
+
+    let mut cursor = node.walk();
+    for child in node.children(&mut cursor) {
+        discover_functions_recursive(
+            child,
+            source,
+            file_index,
+            top_level && !is_function,
+            functions,
+        );
+    }
+}
+
+/// Extract a FunctionNode from a function definition. A leading underscore
+/// (`_helper`) is the shell convention for a private function.
+fn extract_function(
+    node: Node,
+    source: &str,
+    file_index: usize,
+    local_index: usize,
+    top_level: bool,
+) -> FunctionNode {
+    use crate::ast::FunctionId;
+    use crate::language::{FunctionBody, SourceSpan};
+
+    let name = node
+        .child_by_field_name("name")
+        .map(|name| text(name, source).to_string());
+    let is_public = name.as_deref().is_some_and(|name| !name.starts_with('_'));
+
+    let span = SourceSpan::new(
+        node.start_byte(),
+        node.end_byte(),
+        node.start_position().row as u32 + 1, // tree-sitter uses 0-indexed rows
+        node.end_position().row as u32 + 1,   // tree-sitter uses 0-indexed rows
+        SourceSpan::column_of(source, node.start_byte()),
+    );
+
+    let body = FunctionBody::Bash {
+        body_node: node.id(),
+        source: source.to_string(),
+    };
+
+    FunctionNode {
+        id: FunctionId {
+            file_index,
+            local_index,
+        },
+        name,
+        owner: None,
+        span,
+        body,
+        suppression_reason: None, // Will be extracted separately
+        signature_complexity: 0,
+        params: crate::params::bash_params(node, source),
+        is_public: top_level && is_public,
+        is_async: false,
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    fn discover(source: &str) -> Vec<FunctionNode> {
+        let parser = BashParser::new().unwrap();
+        let module = parser.parse(source, "test.sh").unwrap();
+        module.discover_functions(0, source)
+    }
+
+    fn names(functions: &[FunctionNode]) -> Vec<&str> {
+        functions
+            .iter()
+            .map(|f| f.name.as_deref().unwrap_or(""))
+            .collect()
+    }
+
+    #[test]
+    fn test_create_parser() {
+        assert!(BashParser::new().is_ok());
+    }
+
+    #[test]
+    fn test_parse_both_definition_forms() {
+        let functions = discover(
+            r#"#!/usr/bin/env bash
+greet() {
+  echo "hello $1"
+}
+
+function deploy {
+  echo "deploying"
+}
+
+function cleanup() {
+  rm -rf "$tmp"
+}
+"#,
+        );
+        assert_eq!(names(&functions), vec!["greet", "deploy", "cleanup"]);
+        assert_eq!(functions[0].span.start_line, 2);
+        assert_eq!(functions[0].span.end_line, 4);
+        assert_eq!(functions[1].span.start_line, 6);
+    }
+
+    #[test]
+    fn test_parse_without_shebang() {
+        let with = discover("#!/bin/sh\nf() { echo a; }\n");
+        let without = discover("f() { echo a; }\n");
+        assert_eq!(names(&with), vec!["f"]);
+        assert_eq!(names(&without), vec!["f"]);
+        assert_eq!(with[0].span.start_line, 2);
+        assert_eq!(without[0].span.start_line, 1);
+    }
+
+    #[test]
+    fn test_parse_nested_functions() {
+        let functions = discover(
+            r#"outer() {
+  inner() {
+    echo "$1"
+  }
+  inner "$@"
+}
+"#,
+        );
+        assert_eq!(names(&functions), vec!["outer", "inner"]);
+    }
+
+    #[test]
+    fn test_parse_visibility() {
+        let functions = discover(
+            r#"main() { _setup; }
+_setup() { :; }
+wrapper() {
+  later() { :; }
+}
+"#,
+        );
+        let public: Vec<(&str, bool)> = functions
+            .iter()
+            .map(|f| (f.name.as_deref().unwrap(), f.is_public))
+            .collect();
+        assert_eq!(
+            public,
+            vec![
+                ("main", true),
+                ("_setup", false),
+                ("wrapper", true),
+                ("later", false),
+            ]
+        );
+    }
+
+    #[test]
+    fn test_parse_empty_file() {
+        assert!(discover("").is_empty());
+        assert!(discover("#!/bin/bash\nset -euo pipefail\necho done\n").is_empty());
+    }
+}
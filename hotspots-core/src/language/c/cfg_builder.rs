@@ -19,14 +19,7 @@ impl CfgBuilder for CCfgBuilder {
             let func_node =
                 find_function_by_start(root, function.span.start, &["function_definition"])?;
             let body_node = find_child_by_kind(func_node, "compound_statement")?;
-            let mut builder = CCfgBuilderState::new();
-            builder.build_from_block(&body_node, source);
-            if let Some(last) = builder.current_node {
-                if last != builder.cfg.exit {
-                    builder.cfg.add_edge(last, builder.cfg.exit);
-                }
-            }
-            Some(builder.cfg)
+            Some(build_body_cfg(&body_node, source))
         });
 
         result.unwrap_or_else(|| {
@@ -37,6 +30,20 @@ impl CfgBuilder for CCfgBuilder {
     }
 }
 
+/// Build the CFG of a C or C++ function body. tree-sitter-cpp extends the C
+/// grammar, so one builder serves both; the C++-only statements (`try`,
+/// range-based `for`, `throw`, `co_return`) never occur in C trees.
+pub(crate) fn build_body_cfg(body_node: &Node, source: &str) -> Cfg {
+    let mut builder = CCfgBuilderState::new();
+    builder.visit_body(body_node, source);
+    if let Some(last) = builder.current_node {
+        if last != builder.cfg.exit {
+            builder.cfg.add_edge(last, builder.cfg.exit);
+        }
+    }
+    builder.cfg
+}
+
 struct LoopContext {
     break_target: Option<NodeId>,
     continue_target: NodeId,
@@ -72,10 +79,11 @@ impl CCfgBuilderState {
         match node.kind() {
             "if_statement" => self.visit_if(node, source),
             "while_statement" => self.visit_while(node, source),
-            "for_statement" => self.visit_for(node, source),
+            "for_statement" | "for_range_loop" => self.visit_for(node, source),
             "do_statement" => self.visit_do_while(node, source),
             "switch_statement" => self.visit_switch(node, source),
-            "return_statement" => self.visit_return(),
+            "try_statement" => self.visit_try(node, source),
+            "return_statement" | "co_return_statement" | "throw_statement" => self.visit_return(),
             "break_statement" => self.visit_break(),
             "continue_statement" => self.visit_continue(),
             "goto_statement" => self.visit_goto(),
@@ -300,6 +308,7 @@ impl CCfgBuilderState {
 
         let switch_node = self.cfg.add_node(NodeKind::Condition);
         self.cfg.add_edge(from_node, switch_node);
+        self.cfg.switches.insert(node.start_byte(), switch_node);
 
         self.loop_stack.push(LoopContext {
             break_target: None,
@@ -339,6 +348,41 @@ impl CCfgBuilderState {
         self.current_node = Some(join_node);
     }
 
+    /// C++ `try { ... } catch (...) { ... }`: the body and each `catch` are
+    /// branches that meet after the statement
+    fn visit_try(&mut self, node: &Node, source: &str) {
+        let Some(from_node) = self.current_node else {
+            return;
+        };
+
+        let try_node = self.cfg.add_node(NodeKind::Condition);
+        self.cfg.add_edge(from_node, try_node);
+
+        // join_node is created lazily — only if at least one branch falls through.
+        let mut join_node: Option<NodeId> = None;
+        let mut bodies: Vec<Node> = node.child_by_field_name("body").into_iter().collect();
+        let mut cursor = node.walk();
+        bodies.extend(
+            node.children(&mut cursor)
+                .filter(|child| child.kind() == "catch_clause")
+                .filter_map(|catch| catch.child_by_field_name("body")),
+        );
+        for body in bodies {
+            let start = self.cfg.add_node(NodeKind::Statement);
+            self.cfg.add_edge(try_node, start);
+            self.current_node = Some(start);
+            self.visit_body(&body, source);
+            if let Some(end) = self.current_node {
+                if end != self.cfg.exit {
+                    let j = *join_node.get_or_insert_with(|| self.cfg.add_node(NodeKind::Join));
+                    self.cfg.add_edge(end, j);
+                }
+            }
+        }
+
+        self.current_node = join_node;
+    }
+
     fn visit_return(&mut self) {
         if let Some(from_node) = self.current_node {
             self.cfg.add_edge(from_node, self.cfg.exit);
@@ -438,6 +482,7 @@ mod tests {
                 local_index: 0,
             },
             name: Some("test_func".to_string()),
+            owner: None,
             span: SourceSpan::new(
                 func_node.start_byte(),
                 func_node.end_byte(),
@@ -450,6 +495,10 @@ mod tests {
                 source: source.to_string(),
             },
             suppression_reason: None,
+            signature_complexity: 0,
+            params: 0,
+            is_public: false,
+            is_async: false,
         }
     }
 
@@ -1,7 +1,10 @@
 //! C language support
 //!
 //! Parses C source files using tree-sitter-c. C has a flat AST (no classes),
-//! so function discovery is a single-level walk over `function_definition` nodes.
+//! so function discovery is a walk over `function_definition` nodes, including
+//! those inside `#if` / `#ifdef` blocks. The preprocessor is not run:
+//! prototypes and function-like macros are not functions, and every branch
+//! of a conditional is analyzed.
 
 pub mod cfg_builder;
 pub mod parser;
@@ -2,7 +2,7 @@
 
 use crate::ast::FunctionNode;
 use crate::language::parser::{LanguageParser, ParsedModule};
-use crate::language::tree_sitter_utils::find_child_by_kind;
+use crate::language::tree_sitter_utils::{find_child_by_kind, syntax_errors};
 use anyhow::{Context, Result};
 use tree_sitter::{Node, Parser, Tree};
 
@@ -58,6 +58,10 @@ impl ParsedModule for CModule {
         functions.sort_by_key(|f| f.span.start);
         functions
     }
+
+    fn syntax_errors(&self) -> Vec<std::ops::Range<usize>> {
+        syntax_errors(self.tree.root_node())
+    }
 }
 
 fn discover_functions_recursive(
@@ -66,7 +70,7 @@ fn discover_functions_recursive(
     file_index: usize,
     functions: &mut Vec<FunctionNode>,
 ) {
-    if node.kind() == "function_definition" {
+    if node.kind() == "function_definition" && has_function_declarator(node) {
         if let Some(function_node) = extract_function(node, source, file_index, functions.len()) {
             functions.push(function_node);
         }
@@ -90,6 +94,17 @@ fn extract_function(
     // C function_definition has a declarator child containing the function name
     let name = extract_function_name(node, source);
 
+    // `static` functions have internal linkage; everything else is visible
+    // to other translation units
+    let is_static = {
+        let mut cursor = node.walk();
+        let found = node.children(&mut cursor).any(|child| {
+            child.kind() == "storage_class_specifier"
+                && &source[child.start_byte()..child.end_byte()] == "static"
+        });
+        found
+    };
+
     // Body is a compound_statement
     let body_node = find_child_by_kind(node, "compound_statement")?;
 
@@ -98,7 +113,7 @@ fn extract_function(
         node.end_byte(),
         node.start_position().row as u32 + 1,
         node.end_position().row as u32 + 1,
-        node.start_position().column as u32,
+        SourceSpan::column_of(source, node.start_byte()),
     );
 
     let body = FunctionBody::C {
@@ -112,12 +127,37 @@ fn extract_function(
             local_index,
         },
         name,
+        owner: None,
         span,
         body,
         suppression_reason: None,
+        signature_complexity: 0,
+        params: crate::params::c_params(node, source),
+        is_public: !is_static,
+        is_async: false,
     })
 }
 
+/// Whether a `function_definition` really declares a function. The grammar
+/// accepts any declarator before a body, so macro-heavy code such as
+/// `MODULE_INIT startup { ... }` parses as a definition of `startup` with
+/// no parameter list; those are skipped.
+fn has_function_declarator(node: Node) -> bool {
+    let mut declarator = node.child_by_field_name("declarator");
+    while let Some(d) = declarator {
+        match d.kind() {
+            "function_declarator" => return true,
+            "pointer_declarator" | "parenthesized_declarator" | "attributed_declarator" => {
+                declarator = d
+                    .child_by_field_name("declarator")
+                    .or_else(|| d.named_child(0));
+            }
+            _ => return false,
+        }
+    }
+    false
+}
+
 /// Extract function name from a C function_definition node.
 ///
 /// C grammar: function_definition → type declarator compound_statement
@@ -226,4 +266,46 @@ int real_function(void) { return 0; }
         assert_eq!(functions.len(), 1);
         assert_eq!(functions[0].name, Some("real_function".to_string()));
     }
+
+    #[test]
+    fn test_parse_macros_are_not_functions() {
+        let parser = CParser::new().unwrap();
+        // Function-like macros, and a macro that makes a block look like a
+        // definition without a parameter list
+        let source = r#"
+#define SQUARE(x) ((x) * (x))
+#define SWAP(a, b) do { int t = a; a = b; b = t; } while (0)
+
+MODULE_INIT startup {
+    register_all();
+}
+
+int area(int w, int h) {
+    return SQUARE(w) * h;
+}
+"#;
+        let module = parser.parse(source, "test.c").unwrap();
+        let functions = module.discover_functions(0, source);
+        assert_eq!(functions.len(), 1);
+        assert_eq!(functions[0].name, Some("area".to_string()));
+    }
+
+    #[test]
+    fn test_parse_definitions_inside_conditionals() {
+        let parser = CParser::new().unwrap();
+        let source = r#"
+#ifdef _WIN32
+int path_sep(void) { return '\\'; }
+#else
+int path_sep(void) { return '/'; }
+#endif
+"#;
+        let module = parser.parse(source, "test.c").unwrap();
+        let functions = module.discover_functions(0, source);
+        // Both branches are analyzed; the preprocessor is not run
+        assert_eq!(functions.len(), 2);
+        assert!(functions
+            .iter()
+            .all(|f| f.name.as_deref() == Some("path_sep")));
+    }
 }
@@ -1,7 +1,7 @@
 //! Language-agnostic CFG builder traits
 
 use crate::ast::FunctionNode;
-use crate::cfg::Cfg;
+use crate::cfg::{Cfg, NodeId, NodeKind};
 
 /// Language-agnostic CFG builder interface
 ///
@@ -34,6 +34,239 @@ pub fn get_builder_for_function(function: &FunctionNode) -> Box<dyn CfgBuilder>
         FunctionBody::Rust { .. } => Box::new(super::rust::RustCfgBuilder),
         FunctionBody::CSharp { .. } => Box::new(super::csharp::CSharpCfgBuilder),
         FunctionBody::C { .. } => Box::new(super::c::CCfgBuilder),
+        FunctionBody::Cpp { .. } => Box::new(super::cpp::CppCfgBuilder),
+        FunctionBody::Swift { .. } => Box::new(super::swift::SwiftCfgBuilder),
+        FunctionBody::Php { .. } => Box::new(super::php::PhpCfgBuilder),
+        FunctionBody::Scala { .. } => Box::new(super::scala::ScalaCfgBuilder),
+        FunctionBody::Dart { .. } => Box::new(super::dart::DartCfgBuilder),
+        FunctionBody::Elixir { .. } => Box::new(super::elixir::ElixirCfgBuilder),
+        FunctionBody::Lua { .. } => Box::new(super::lua::LuaCfgBuilder),
+        FunctionBody::Bash { .. } => Box::new(super::bash::BashCfgBuilder),
+        FunctionBody::Zig { .. } => Box::new(super::zig::ZigCfgBuilder),
+        FunctionBody::Haskell { .. } => Box::new(super::haskell::HaskellCfgBuilder),
+        FunctionBody::Perl { .. } => Box::new(super::perl::PerlCfgBuilder),
+        FunctionBody::Sql { .. } => Box::new(super::sql::SqlCfgBuilder),
+    }
+}
+
+/// A loop or `switch` that `break` and `continue` inside it jump to
+struct LoopContext {
+    /// Join after the loop, created by the first `break` or when it ends
+    break_target: Option<NodeId>,
+    continue_target: NodeId,
+}
+
+/// Lowering of branches, loops, and jumps shared by the tree-sitter CFG
+/// builders. `current_node` is where control leaves the statements visited
+/// so far; `None` after a jump, so unreachable code adds no edges.
+pub(crate) struct CfgState {
+    pub(crate) cfg: Cfg,
+    pub(crate) current_node: Option<NodeId>,
+    loop_stack: Vec<LoopContext>,
+}
+
+impl CfgState {
+    pub(crate) fn new() -> Self {
+        let cfg = Cfg::new();
+        let entry = cfg.entry;
+        CfgState {
+            cfg,
+            current_node: Some(entry),
+            loop_stack: Vec::new(),
+        }
+    }
+
+    /// Entry straight to exit, for a body the builder cannot find
+    pub(crate) fn straight_line() -> Cfg {
+        let mut cfg = Cfg::new();
+        cfg.add_edge(cfg.entry, cfg.exit);
+        cfg
+    }
+
+    /// The graph, with the end of the body connected to the exit
+    pub(crate) fn finish(mut self) -> Cfg {
+        let exit = self.cfg.exit;
+        self.fall_through_to(exit);
+        self.cfg
+    }
+
+    /// Add a node of `kind` after the current node and return it; `None`
+    /// when the code is unreachable
+    pub(crate) fn add_after(&mut self, kind: NodeKind) -> Option<NodeId> {
+        let from = self.current_node?;
+        let node = self.cfg.add_node(kind);
+        self.cfg.add_edge(from, node);
+        self.current_node = Some(node);
+        Some(node)
+    }
+
+    pub(crate) fn statement(&mut self) {
+        self.add_after(NodeKind::Statement);
+    }
+
+    /// Start a branch at a fresh node reached from `from`
+    pub(crate) fn start_branch(&mut self, from: NodeId) {
+        let start = self.cfg.add_node(NodeKind::Statement);
+        self.cfg.add_edge(from, start);
+        self.current_node = Some(start);
+    }
+
+    /// Connect the end of the current branch to `join`, which is created on
+    /// first use, so a join exists only if some branch falls through
+    pub(crate) fn fall_through(&mut self, join: &mut Option<NodeId>) {
+        if let Some(end) = self.current_node {
+            if end != self.cfg.exit {
+                let target = *join.get_or_insert_with(|| self.cfg.add_node(NodeKind::Join));
+                self.cfg.add_edge(end, target);
+            }
+        }
+    }
+
+    /// The path that skips every branch of `decision`, such as an `if`
+    /// without `else`
+    pub(crate) fn skip_branches(&mut self, decision: NodeId, join: &mut Option<NodeId>) {
+        self.current_node = Some(decision);
+        self.fall_through(join);
+    }
+
+    /// Connect the end of the current branch to `target`, if it falls through
+    pub(crate) fn fall_through_to(&mut self, target: NodeId) {
+        if let Some(end) = self.current_node {
+            if end != self.cfg.exit {
+                self.cfg.add_edge(end, target);
+            }
+        }
+    }
+
+    /// Jump to `target`; the code after the jump is unreachable
+    pub(crate) fn jump(&mut self, target: NodeId) {
+        if let Some(from) = self.current_node.take() {
+            self.cfg.add_edge(from, target);
+        }
+    }
+
+    /// `return`, `throw`, and calls that never return
+    pub(crate) fn jump_to_exit(&mut self) {
+        let exit = self.cfg.exit;
+        self.jump(exit);
+    }
+
+    /// Enter a loop or `switch` whose `continue` goes to `continue_target`
+    pub(crate) fn push_loop(&mut self, continue_target: NodeId) {
+        self.loop_stack.push(LoopContext {
+            break_target: None,
+            continue_target,
+        });
+    }
+
+    /// Enter a `switch` in which `continue` goes to the enclosing loop, as in
+    /// Dart
+    pub(crate) fn push_switch(&mut self) {
+        let continue_target = self
+            .loop_stack
+            .last()
+            .map_or(self.cfg.exit, |ctx| ctx.continue_target);
+        self.push_loop(continue_target);
+    }
+
+    /// Leave the innermost loop or `switch` and return its break join
+    pub(crate) fn pop_loop(&mut self) -> NodeId {
+        match self.loop_stack.pop() {
+            Some(LoopContext {
+                break_target: Some(join),
+                ..
+            }) => join,
+            _ => self.cfg.add_node(NodeKind::Join),
+        }
+    }
+
+    /// Whether a loop or `switch` encloses the current node
+    pub(crate) fn in_loop(&self) -> bool {
+        !self.loop_stack.is_empty()
+    }
+
+    /// Break join of the innermost loop or `switch`
+    pub(crate) fn break_target(&mut self) -> Option<NodeId> {
+        let cfg = &mut self.cfg;
+        let ctx = self.loop_stack.last_mut()?;
+        Some(
+            *ctx.break_target
+                .get_or_insert_with(|| cfg.add_node(NodeKind::Join)),
+        )
+    }
+
+    /// Connect the end of a `switch` case to the `switch`'s break join, if it
+    /// falls through
+    pub(crate) fn fall_through_to_break(&mut self) {
+        if self.current_node.is_some() {
+            if let Some(join) = self.break_target() {
+                self.fall_through_to(join);
+            }
+        }
+    }
+
+    /// `break` out of the innermost loop or `switch`; ignored outside one
+    pub(crate) fn jump_to_break(&mut self) {
+        if self.current_node.is_some() {
+            if let Some(target) = self.break_target() {
+                self.jump(target);
+            }
+        }
+    }
+
+    /// `continue` the innermost loop; ignored outside one
+    pub(crate) fn jump_to_continue(&mut self) {
+        if let Some(target) = self.loop_stack.last().map(|ctx| ctx.continue_target) {
+            self.jump(target);
+        }
+    }
+
+    /// Enter a loop tested before each iteration: a header that decides
+    /// between the body, started here, and the code after the loop. Returns
+    /// the header, for [`CfgState::end_loop`].
+    pub(crate) fn start_loop(&mut self) -> Option<NodeId> {
+        let header = self.add_after(NodeKind::LoopHeader)?;
+        self.push_loop(header);
+        self.start_branch(header);
+        Some(header)
+    }
+
+    /// Close the loop `header` decides: the end of the body goes back to the
+    /// header, and the code after the loop starts at its break join
+    pub(crate) fn end_loop(&mut self, header: NodeId) {
+        self.fall_through_to(header);
+        self.end_switch(header);
+    }
+
+    /// Enter a loop tested after each iteration (`do ... while`): the body,
+    /// started here, runs before the header decides whether to go round
+    /// again. Returns the body's start and the header, for
+    /// [`CfgState::end_post_test_loop`].
+    pub(crate) fn start_post_test_loop(&mut self) -> Option<(NodeId, NodeId)> {
+        let body = self.add_after(NodeKind::Statement)?;
+        let header = self.cfg.add_node(NodeKind::LoopHeader);
+        self.push_loop(header);
+        Some((body, header))
+    }
+
+    pub(crate) fn end_post_test_loop(&mut self, (body, header): (NodeId, NodeId)) {
+        self.cfg.add_edge(header, body);
+        self.end_loop(header);
+    }
+
+    /// Close a `switch` entered with [`CfgState::push_loop`]: the path past
+    /// `decision` when no case matches meets the cases that leave it at its
+    /// break join, where the code after it starts
+    pub(crate) fn end_switch(&mut self, decision: NodeId) {
+        let join = self.pop_loop();
+        self.cfg.add_edge(decision, join);
+        self.current_node = Some(join);
+    }
+
+    /// Close a `switch` with a `default` case: only the cases that leave it
+    /// reach the code after it, so there is no join if every case returns
+    pub(crate) fn end_switch_with_default(&mut self) {
+        self.current_node = self.loop_stack.pop().and_then(|ctx| ctx.break_target);
     }
 }
 
@@ -50,6 +283,7 @@ mod tests {
                 local_index: 0,
             },
             name: Some("test".to_string()),
+            owner: None,
             span: SourceSpan::new(0, 10, 1, 1, 0),
             body: FunctionBody::ecmascript(swc_ecma_ast::BlockStmt {
                 span: swc_common::DUMMY_SP,
@@ -57,6 +291,10 @@ mod tests {
                 stmts: vec![],
             }),
             suppression_reason: None,
+            signature_complexity: 0,
+            params: 0,
+            is_public: false,
+            is_async: false,
         }
     }
 
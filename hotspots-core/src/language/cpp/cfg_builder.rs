@@ -0,0 +1,117 @@
+//! C++ CFG builder implementation
+
+use crate::ast::FunctionNode;
+use crate::cfg::Cfg;
+use crate::language::c::cfg_builder::build_body_cfg;
+use crate::language::cfg_builder::{CfgBuilder, CfgState};
+use crate::language::cpp::FUNCTION_KINDS;
+use crate::language::tree_sitter_utils::{find_function_by_start, with_cached_cpp_tree};
+
+/// C++ CFG builder
+pub struct CppCfgBuilder;
+
+impl CfgBuilder for CppCfgBuilder {
+    fn build(&self, function: &FunctionNode) -> Cfg {
+        let (_body_node_id, source) = function.body.as_cpp();
+
+        let result = with_cached_cpp_tree(source, |root| {
+            let func_node = find_function_by_start(root, function.span.start, FUNCTION_KINDS)?;
+            let body_node = func_node.child_by_field_name("body")?;
+            Some(build_body_cfg(&body_node, source))
+        });
+
+        result.unwrap_or_else(CfgState::straight_line)
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::language::cpp::CppParser;
+    use crate::language::parser::LanguageParser;
+
+    /// CC of each function in `source`, in source order
+    fn cc(source: &str) -> Vec<usize> {
+        let module = CppParser::new().unwrap().parse(source, "test.cpp").unwrap();
+        module
+            .discover_functions(0, source)
+            .iter()
+            .map(|function| {
+                let cfg = CppCfgBuilder.build(function);
+                assert!(
+                    cfg.validate().is_ok(),
+                    "CFG must be valid: {:?}",
+                    cfg.validate()
+                );
+                // CC = E - N + 2
+                (cfg.edge_count() as isize - cfg.node_count() as isize + 2).max(1) as usize
+            })
+            .collect()
+    }
+
+    #[test]
+    fn test_simple_function() {
+        assert_eq!(cc("int add(int a, int b) { return a + b; }\n"), vec![1]);
+    }
+
+    #[test]
+    fn test_each_catch_adds_one() {
+        let source = r#"
+int parse(const std::string& s) {
+    try {
+        return std::stoi(s);
+    } catch (const std::invalid_argument&) {
+        return 0;
+    } catch (...) {
+        throw;
+    }
+}
+"#;
+        assert_eq!(cc(source), vec![3]);
+    }
+
+    #[test]
+    fn test_range_for_is_a_loop() {
+        let source = r#"
+int sum(const std::vector<int>& xs) {
+    int total = 0;
+    for (int x : xs) {
+        if (x < 0) continue;
+        total += x;
+    }
+    return total;
+}
+"#;
+        assert_eq!(cc(source), vec![3]);
+    }
+
+    #[test]
+    fn test_goto_adds_one() {
+        let source = r#"
+int cleanup(int x) {
+    if (x < 0) goto fail;
+    return x;
+fail:
+    return -1;
+}
+"#;
+        assert_eq!(cc(source), vec![3]);
+    }
+
+    #[test]
+    fn test_lambda_body_is_its_own_cfg() {
+        let source = r#"
+void apply(std::vector<int>& xs) {
+    auto clamp = [](int x) {
+        if (x < 0) {
+            return 0;
+        }
+        return x;
+    };
+    std::transform(xs.begin(), xs.end(), xs.begin(), clamp);
+}
+"#;
+        // The lambda's `if` is not a branch of `apply`
+        assert_eq!(cc(source), vec![1, 2]);
+    }
+}
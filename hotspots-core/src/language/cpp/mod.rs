@@ -0,0 +1,13 @@
+//! C++ language support
+//!
+//! Parses C++ source files using tree-sitter-cpp, which extends the C
+//! grammar, so the CFG is built by the C builder.
+
+pub mod cfg_builder;
+pub mod parser;
+
+pub use cfg_builder::CppCfgBuilder;
+pub use parser::CppParser;
+
+/// Node kinds that can be a discovered function
+pub(crate) const FUNCTION_KINDS: &[&str] = &["function_definition", "lambda_expression"];
@@ -0,0 +1,444 @@
+//! C++ language parser using tree-sitter
+
+use crate::ast::FunctionNode;
+use crate::language::cpp::FUNCTION_KINDS;
+use crate::language::parser::{LanguageParser, ParsedModule};
+use crate::language::tree_sitter_utils::syntax_errors;
+use anyhow::{Context, Result};
+use tree_sitter::{Node, Parser, Tree};
+
+/// C++ parser using tree-sitter
+pub struct CppParser;
+
+impl CppParser {
+    /// Create a new C++ parser
+    pub fn new() -> Result<Self> {
+        let mut parser = Parser::new();
+        let language = tree_sitter_cpp::LANGUAGE;
+        parser
+            .set_language(&language.into())
+            .context("Failed to set C++ language for parser")?;
+        Ok(CppParser)
+    }
+}
+
+impl Default for CppParser {
+    fn default() -> Self {
+        Self::new().expect("Failed to create C++ parser")
+    }
+}
+
+impl LanguageParser for CppParser {
+    fn parse(&self, source: &str, filename: &str) -> Result<Box<dyn ParsedModule>> {
+        let mut parser = Parser::new();
+        let language = tree_sitter_cpp::LANGUAGE;
+        parser
+            .set_language(&language.into())
+            .context("Failed to set C++ language")?;
+
+        let tree = parser
+            .parse(source, None)
+            .ok_or_else(|| anyhow::anyhow!("Failed to parse C++ file: {}", filename))?;
+
+        Ok(Box::new(CppModule {
+            tree,
+            source: source.to_string(),
+        }))
+    }
+}
+
+/// Parsed C++ module
+struct CppModule {
+    tree: Tree,
+    source: String,
+}
+
+impl ParsedModule for CppModule {
+    fn discover_functions(&self, file_index: usize, _source: &str) -> Vec<FunctionNode> {
+        let root = self.tree.root_node();
+        let mut functions = Vec::new();
+        discover_functions_recursive(root, &self.source, file_index, &mut functions);
+        functions.sort_by_key(|f| f.span.start);
+        functions
+    }
+
+    fn syntax_errors(&self) -> Vec<std::ops::Range<usize>> {
+        syntax_errors(self.tree.root_node())
+    }
+}
+
+/// Recursively discover functions in the C++ AST. Namespaces, class bodies,
+/// templates, and function bodies are walked like any other node, so inline
+/// members, template definitions, and lambdas are all found.
+fn discover_functions_recursive(
+    node: Node,
+    source: &str,
+    file_index: usize,
+    functions: &mut Vec<FunctionNode>,
+) {
+    if FUNCTION_KINDS.contains(&node.kind()) {
+        if let Some(function_node) = extract_function(node, source, file_index, functions.len()) {
+            functions.push(function_node);
+        }
+    }
+
+    let mut cursor = node.walk();
+    for child in node.children(&mut cursor) {
+        discover_functions_recursive(child, source, file_index, functions);
+    }
+}
+
+/// Extract a FunctionNode from a function definition or lambda
+fn extract_function(
+    node: Node,
+    source: &str,
+    file_index: usize,
+    local_index: usize,
+) -> Option<FunctionNode> {
+    use crate::ast::FunctionId;
+    use crate::language::{FunctionBody, SourceSpan};
+
+    // `= default`, `= delete`, and pure virtual declarations have no body
+    let body_node = node.child_by_field_name("body")?;
+
+    let (name, is_public) = if node.kind() == "function_definition" {
+        // Like C, a block after a declarator with no parameter list is a
+        // macro, not a function
+        let declarator = function_declarator(node)?;
+        let name = qualified_name(node, &declared_name(declarator, source), source);
+        (Some(name), is_visible(node, source))
+    } else {
+        (bound_name(node, source), false)
+    };
+
+    let span = SourceSpan::new(
+        node.start_byte(),
+        node.end_byte(),
+        node.start_position().row as u32 + 1, // tree-sitter uses 0-indexed rows
+        node.end_position().row as u32 + 1,   // tree-sitter uses 0-indexed rows
+        SourceSpan::column_of(source, node.start_byte()),
+    );
+
+    let body = FunctionBody::Cpp {
+        body_node: body_node.id(),
+        source: source.to_string(),
+    };
+
+    Some(FunctionNode {
+        id: FunctionId {
+            file_index,
+            local_index,
+        },
+        name,
+        owner: None,
+        span,
+        body,
+        suppression_reason: None, // Will be extracted separately
+        signature_complexity: 0,
+        params: crate::params::cpp_params(node, source),
+        is_public,
+        is_async: false,
+    })
+}
+
+/// The declarator that names a function definition: its function
+/// declarator, looking through pointer and reference declarators
+/// (`const T& get()`), or for a conversion operator (`operator bool()`)
+/// the `operator_cast` itself, possibly qualified
+fn function_declarator(node: Node) -> Option<Node> {
+    let mut declarator = node.child_by_field_name("declarator");
+    while let Some(d) = declarator {
+        match d.kind() {
+            "function_declarator" | "operator_cast" | "qualified_identifier" => return Some(d),
+            "pointer_declarator"
+            | "reference_declarator"
+            | "parenthesized_declarator"
+            | "attributed_declarator" => {
+                // Reference declarators have no `declarator` field
+                declarator = d
+                    .child_by_field_name("declarator")
+                    .or_else(|| d.named_child(d.named_child_count().saturating_sub(1)));
+            }
+            _ => return None,
+        }
+    }
+    None
+}
+
+/// Name written in a function's declarator: `area`, `Shape::area`,
+/// `~Shape`, `operator==`, `operator bool`
+fn declared_name(declarator: Node, source: &str) -> String {
+    match declarator.kind() {
+        "function_declarator" => declarator
+            .child_by_field_name("declarator")
+            .map(|name| name_text(name, source))
+            .unwrap_or_default(),
+        _ => name_text(declarator, source),
+    }
+}
+
+/// Source text of a (possibly qualified) name with template arguments
+/// dropped (`Stack<T>::push` → `Stack::push`) and whitespace collapsed
+fn name_text(node: Node, source: &str) -> String {
+    match node.kind() {
+        "qualified_identifier" => {
+            let name = node
+                .child_by_field_name("name")
+                .map(|name| name_text(name, source))
+                .unwrap_or_default();
+            match node.child_by_field_name("scope") {
+                Some(scope) => format!("{}::{}", name_text(scope, source), name),
+                None => name,
+            }
+        }
+        "template_type" | "template_function" => node
+            .child_by_field_name("name")
+            .map(|name| name_text(name, source))
+            .unwrap_or_default(),
+        "operator_cast" => {
+            // `operator bool` without its `()` declarator
+            let end = node
+                .child_by_field_name("declarator")
+                .map_or(node.end_byte(), |d| d.start_byte());
+            collapse_whitespace(&source[node.start_byte()..end])
+        }
+        _ => collapse_whitespace(&source[node.start_byte()..node.end_byte()]),
+    }
+}
+
+fn collapse_whitespace(text: &str) -> String {
+    text.split_whitespace().collect::<Vec<_>>().join(" ")
+}
+
+/// Prefix `name` with the namespaces and classes enclosing `node`, outermost
+/// first. Anonymous namespaces add nothing.
+fn qualified_name(node: Node, name: &str, source: &str) -> String {
+    let mut scopes = Vec::new();
+    let mut ancestor = node.parent();
+    while let Some(a) = ancestor {
+        if matches!(
+            a.kind(),
+            "namespace_definition" | "class_specifier" | "struct_specifier" | "union_specifier"
+        ) {
+            if let Some(scope) = a.child_by_field_name("name") {
+                scopes.push(name_text(scope, source));
+            }
+        }
+        ancestor = a.parent();
+    }
+    scopes.reverse();
+    scopes.push(name.to_string());
+    scopes.join("::")
+}
+
+/// Whether a function definition is visible outside its translation unit
+/// or class. Inline members follow the access specifier in effect (classes
+/// default to `private`, structs and unions to `public`). Other functions
+/// are visible unless `static` or in an anonymous namespace; an out-of-line
+/// member definition does not repeat its access, so it counts as visible.
+fn is_visible(node: Node, source: &str) -> bool {
+    // A member template is wrapped in its template_declaration
+    let member = match node.parent() {
+        Some(parent) if parent.kind() == "template_declaration" => parent,
+        _ => node,
+    };
+    if let Some(list) = member
+        .parent()
+        .filter(|p| p.kind() == "field_declaration_list")
+    {
+        let mut sibling = member.prev_sibling();
+        while let Some(s) = sibling {
+            if s.kind() == "access_specifier" {
+                let access = &source[s.start_byte()..s.end_byte()];
+                return access.trim_end_matches(':').trim_end() == "public";
+            }
+            sibling = s.prev_sibling();
+        }
+        return list
+            .parent()
+            .map_or(true, |class| class.kind() != "class_specifier");
+    }
+
+    let mut cursor = node.walk();
+    let is_static = node.children(&mut cursor).any(|child| {
+        child.kind() == "storage_class_specifier"
+            && &source[child.start_byte()..child.end_byte()] == "static"
+    });
+    let mut ancestor = node.parent();
+    while let Some(a) = ancestor {
+        if a.kind() == "namespace_definition" && a.child_by_field_name("name").is_none() {
+            return false;
+        }
+        ancestor = a.parent();
+    }
+    !is_static
+}
+
+/// Name a lambda after the variable it initializes or is assigned to
+/// (`auto cmp = [](...) { ... }` → `cmp`); others stay anonymous
+fn bound_name(node: Node, source: &str) -> Option<String> {
+    let parent = node.parent()?;
+    let target = match parent.kind() {
+        "init_declarator" => parent.child_by_field_name("declarator")?,
+        "assignment_expression" => parent.child_by_field_name("left")?,
+        _ => return None,
+    };
+    if target.kind() != "identifier" {
+        return None;
+    }
+    Some(source[target.start_byte()..target.end_byte()].to_string())
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    fn discover(source: &str) -> Vec<FunctionNode> {
+        let parser = CppParser::new().unwrap();
+        let module = parser.parse(source, "test.cpp").unwrap();
+        module.discover_functions(0, source)
+    }
+
+    fn names(functions: &[FunctionNode]) -> Vec<&str> {
+        functions
+            .iter()
+            .map(|f| f.name.as_deref().unwrap_or(""))
+            .collect()
+    }
+
+    #[test]
+    fn test_create_parser() {
+        assert!(CppParser::new().is_ok());
+    }
+
+    #[test]
+    fn test_parse_qualified_names() {
+        let functions = discover(
+            r#"
+namespace geo {
+namespace detail {
+int clamp(int x) { return x < 0 ? 0 : x; }
+}
+
+class Shape {
+public:
+    Shape() = default;
+    virtual ~Shape() {}
+    virtual double area() const = 0;
+    bool operator==(const Shape& other) const { return this == &other; }
+    explicit operator bool() const { return true; }
+};
+
+double Shape::perimeter() const { return 0.0; }
+}
+
+double geo::Circle::area() const { return 3.14 * r_ * r_; }
+"#,
+        );
+        assert_eq!(
+            names(&functions),
+            vec![
+                "geo::detail::clamp",
+                "geo::Shape::~Shape",
+                "geo::Shape::operator==",
+                "geo::Shape::operator bool",
+                "geo::Shape::perimeter",
+                "geo::Circle::area",
+            ]
+        );
+    }
+
+    #[test]
+    fn test_parse_templates_once() {
+        let functions = discover(
+            r#"
+template <typename T>
+T maximum(T a, T b) { return a > b ? a : b; }
+
+template <typename T>
+class Stack {
+public:
+    void push(const T& value) { items_.push_back(value); }
+};
+
+template <typename T>
+void Stack<T>::clear() { items_.clear(); }
+
+template int maximum<int>(int, int);
+
+int use() { return maximum(1, 2) + static_cast<int>(maximum(1.0, 2.0)); }
+"#,
+        );
+        // Explicit instantiations have no body; uses are not definitions
+        assert_eq!(
+            names(&functions),
+            vec!["maximum", "Stack::push", "Stack::clear", "use"]
+        );
+    }
+
+    #[test]
+    fn test_parse_lambdas() {
+        let functions = discover(
+            r#"
+void sortAll(std::vector<int>& v) {
+    auto ascending = [](int a, int b) { return a < b; };
+    std::sort(v.begin(), v.end(), ascending);
+    std::sort(v.begin(), v.end(), [](int a, int b) { return a > b; });
+}
+"#,
+        );
+        assert_eq!(names(&functions), vec!["sortAll", "ascending", ""]);
+        assert!(functions[2].name.is_none());
+        assert!(!functions[1].is_public);
+    }
+
+    #[test]
+    fn test_parse_visibility() {
+        let functions = discover(
+            r#"
+static int helper() { return 0; }
+namespace { int hidden() { return 0; } }
+int api() { return 0; }
+
+class Service {
+    void implicitPrivate() {}
+public:
+    void run() {}
+    static Service create() { return Service(); }
+protected:
+    void hook() {}
+};
+
+struct Point {
+    int sum() const { return x + y; }
+};
+
+void Service::stop() {}
+"#,
+        );
+        let public: Vec<(&str, bool)> = functions
+            .iter()
+            .map(|f| (f.name.as_deref().unwrap(), f.is_public))
+            .collect();
+        assert_eq!(
+            public,
+            vec![
+                ("helper", false),
+                ("hidden", false),
+                ("api", true),
+                ("Service::implicitPrivate", false),
+                ("Service::run", true),
+                ("Service::create", true),
+                ("Service::hook", false),
+                ("Point::sum", true),
+                ("Service::stop", true),
+            ]
+        );
+    }
+
+    #[test]
+    fn test_parse_empty_file() {
+        assert!(discover("").is_empty());
+        assert!(discover("int add(int a, int b);\nclass Shape;\n").is_empty());
+    }
+}
@@ -260,6 +260,7 @@ impl CSharpCfgBuilderState {
 
         let switch_node = self.cfg.add_node(NodeKind::Condition);
         self.cfg.add_edge(current, switch_node);
+        self.cfg.switches.insert(node.start_byte(), switch_node);
 
         let join = self.cfg.add_node(NodeKind::Statement);
 
@@ -438,12 +439,17 @@ mod tests {
                 local_index: 0,
             },
             name: Some("test".to_string()),
+            owner: None,
             span: SourceSpan::new(start_byte, end_byte, 1, 1, 0),
             body: FunctionBody::CSharp {
                 body_node: 0,
                 source: source.to_string(),
             },
             suppression_reason: None,
+            signature_complexity: 0,
+            params: 0,
+            is_public: false,
+            is_async: false,
         }
     }
 
@@ -5,3 +5,12 @@ mod parser;
 
 pub use cfg_builder::CSharpCfgBuilder;
 pub use parser::CSharpParser;
+
+/// Node kinds that can be a discovered function
+pub(crate) const FUNCTION_KINDS: &[&str] = &[
+    "method_declaration",
+    "constructor_declaration",
+    "local_function_statement",
+    "operator_declaration",
+    "conversion_operator_declaration",
+];
@@ -1,8 +1,9 @@
 //! C# language parser using tree-sitter
 
 use crate::ast::FunctionNode;
+use crate::language::csharp::FUNCTION_KINDS;
 use crate::language::parser::{LanguageParser, ParsedModule};
-use crate::language::tree_sitter_utils::find_child_by_kind;
+use crate::language::tree_sitter_utils::{find_child_by_kind, syntax_errors};
 use anyhow::{Context, Result};
 use tree_sitter::{Node, Parser, Tree};
 
@@ -57,6 +58,14 @@ impl ParsedModule for CSharpModule {
         functions.sort_by_key(|f| f.span.start);
         functions
     }
+
+    fn syntax_errors(&self) -> Vec<std::ops::Range<usize>> {
+        syntax_errors(self.tree.root_node())
+    }
+
+    fn top_level_decisions(&self) -> usize {
+        crate::metrics::csharp_top_level_decisions(&self.tree.root_node())
+    }
 }
 
 fn discover_functions_recursive(
@@ -65,18 +74,10 @@ fn discover_functions_recursive(
     file_index: usize,
     functions: &mut Vec<FunctionNode>,
 ) {
-    match node.kind() {
-        "method_declaration"
-        | "constructor_declaration"
-        | "local_function_statement"
-        | "operator_declaration"
-        | "conversion_operator_declaration" => {
-            if let Some(function_node) = extract_function(node, source, file_index, functions.len())
-            {
-                functions.push(function_node);
-            }
+    if FUNCTION_KINDS.contains(&node.kind()) {
+        if let Some(function_node) = extract_function(node, source, file_index, functions.len()) {
+            functions.push(function_node);
         }
-        _ => {}
     }
 
     let mut cursor = node.walk();
@@ -95,6 +96,7 @@ fn extract_function(
     use crate::language::{FunctionBody, SourceSpan};
 
     let name = extract_function_name(node, source);
+    let is_public = is_public_member(node, source);
 
     let body_node = find_child_by_kind(node, "block")?;
 
@@ -103,7 +105,7 @@ fn extract_function(
         node.end_byte(),
         node.start_position().row as u32 + 1,
         node.end_position().row as u32 + 1,
-        node.start_position().column as u32,
+        SourceSpan::column_of(source, node.start_byte()),
     );
 
     let body = FunctionBody::CSharp {
@@ -117,12 +119,43 @@ fn extract_function(
             local_index,
         },
         name,
+        owner: None,
         span,
         body,
         suppression_reason: None,
+        signature_complexity: 0,
+        params: crate::params::csharp_params(node, source),
+        is_public,
+        is_async: false,
     })
 }
 
+/// A member is public API when declared `public`, or when it is an interface
+/// member without an access modifier (interface members default to public).
+/// Local functions never are.
+fn is_public_member(node: Node, source: &str) -> bool {
+    if node.kind() == "local_function_statement" {
+        return false;
+    }
+    let mut cursor = node.walk();
+    let modifiers: Vec<&str> = node
+        .children(&mut cursor)
+        .filter(|child| child.kind() == "modifier")
+        .map(|child| &source[child.start_byte()..child.end_byte()])
+        .collect();
+    if modifiers.contains(&"public") {
+        return true;
+    }
+    let in_interface = node
+        .parent()
+        .and_then(|body| body.parent())
+        .is_some_and(|decl| decl.kind() == "interface_declaration");
+    in_interface
+        && !modifiers
+            .iter()
+            .any(|m| matches!(*m, "private" | "protected" | "internal"))
+}
+
 fn extract_function_name(node: Node, source: &str) -> Option<String> {
     // method_declaration and local_function_statement use "identifier"
     // constructor_declaration uses "identifier"
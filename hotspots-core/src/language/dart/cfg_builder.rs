@@ -0,0 +1,304 @@
+//! Dart CFG builder implementation
+//!
+//! Decisions inside expressions and closures are counted by the metrics
+//! extractor instead.
+
+use crate::ast::FunctionNode;
+use crate::cfg::{Cfg, NodeId, NodeKind};
+use crate::language::cfg_builder::{CfgBuilder, CfgState};
+use crate::language::dart::{
+    block_statements, body_statements, case_statements, finally_block, find_function,
+    function_body, if_branches, loop_body, switch_clauses, try_parts,
+};
+use crate::language::tree_sitter_utils::with_cached_dart_tree;
+use tree_sitter::Node;
+
+/// Dart CFG builder
+pub struct DartCfgBuilder;
+
+impl CfgBuilder for DartCfgBuilder {
+    fn build(&self, function: &FunctionNode) -> Cfg {
+        let (_body_node_id, source) = function.body.as_dart();
+
+        let result = with_cached_dart_tree(source, |root| {
+            let func_node = find_function(root, function.span.start)?;
+            let body = function_body(func_node)?;
+            let mut builder = DartCfgBuilderState {
+                flow: CfgState::new(),
+            };
+            builder.visit_statements(&body_statements(body));
+            Some(builder.flow.finish())
+        });
+
+        result.unwrap_or_else(CfgState::straight_line)
+    }
+}
+
+struct DartCfgBuilderState {
+    flow: CfgState,
+}
+
+impl DartCfgBuilderState {
+    fn visit_statements(&mut self, statements: &[Node]) {
+        for stmt in statements {
+            self.visit_node(stmt);
+        }
+    }
+
+    fn visit_node(&mut self, node: &Node) {
+        match node.kind() {
+            "if_statement" => self.visit_if(node),
+            "for_statement" | "while_statement" => self.visit_loop(node),
+            "do_statement" => self.visit_do_while(node),
+            "switch_statement" => self.visit_switch(node),
+            "try_statement" => self.visit_try(node),
+            "return_statement" | "rethrow_expression" => self.flow.jump_to_exit(),
+            "expression_statement" if is_throw(*node) => self.flow.jump_to_exit(),
+            "break_statement" => self.flow.jump_to_break(),
+            "continue_statement" => self.flow.jump_to_continue(),
+            "block" => self.visit_statements(&block_statements(*node)),
+            // Local functions are discovered on their own
+            _ => self.flow.statement(),
+        }
+    }
+
+    fn visit_branch(&mut self, from: NodeId, statements: &[Node], join: &mut Option<NodeId>) {
+        self.flow.start_branch(from);
+        self.visit_statements(statements);
+        self.flow.fall_through(join);
+    }
+
+    /// `if` / `else`; an `else if` is an `if` inside the `else` branch
+    fn visit_if(&mut self, node: &Node) {
+        let Some(condition_node) = self.flow.add_after(NodeKind::Condition) else {
+            return;
+        };
+
+        let mut join_node = None;
+        let (consequence, alternative) = if_branches(*node);
+        let consequence = consequence.map(block_statements).unwrap_or_default();
+        self.visit_branch(condition_node, &consequence, &mut join_node);
+        match alternative {
+            Some(alternative) => self.visit_branch(
+                condition_node,
+                &block_statements(alternative),
+                &mut join_node,
+            ),
+            None => self.flow.skip_branches(condition_node, &mut join_node),
+        }
+        self.flow.current_node = join_node;
+    }
+
+    /// `for`, `for-in`, `await for`, and `while`
+    fn visit_loop(&mut self, node: &Node) {
+        let Some(header) = self.flow.start_loop() else {
+            return;
+        };
+        if let Some(body) = loop_body(*node) {
+            self.visit_statements(&block_statements(body));
+        }
+        self.flow.end_loop(header);
+    }
+
+    fn visit_do_while(&mut self, node: &Node) {
+        let Some(body_loop) = self.flow.start_post_test_loop() else {
+            return;
+        };
+        if let Some(body) = loop_body(*node) {
+            self.visit_statements(&block_statements(body));
+        }
+        self.flow.end_post_test_loop(body_loop);
+    }
+
+    /// Each `case` is a branch, and `default` is the path taken when no case
+    /// matches. Dart cases do not fall through, so a trailing `break` only
+    /// leaves the `switch`.
+    fn visit_switch(&mut self, node: &Node) {
+        let Some(switch_node) = self.flow.add_after(NodeKind::Condition) else {
+            return;
+        };
+        self.flow
+            .cfg
+            .switches
+            .insert(node.start_byte(), switch_node);
+
+        self.flow.push_switch();
+        let mut has_default = false;
+        for clause in switch_clauses(*node) {
+            has_default |= clause.kind() == "switch_statement_default";
+            self.flow.start_branch(switch_node);
+            self.visit_statements(&case_statements(clause));
+            self.flow.fall_through_to_break();
+        }
+        if has_default {
+            self.flow.end_switch_with_default();
+        } else {
+            self.flow.end_switch(switch_node);
+        }
+    }
+
+    /// `try { ... } on T catch (e) { ... } finally { ... }`: the body and
+    /// each handler are branches; `finally` runs after whichever one
+    /// completes
+    fn visit_try(&mut self, node: &Node) {
+        let Some(try_node) = self.flow.add_after(NodeKind::Condition) else {
+            return;
+        };
+
+        let mut join_node = None;
+        let (body, handlers) = try_parts(*node);
+        let body = body.map(block_statements).unwrap_or_default();
+        self.visit_branch(try_node, &body, &mut join_node);
+        for handler in handlers {
+            self.visit_branch(try_node, &block_statements(handler), &mut join_node);
+        }
+
+        self.flow.current_node = join_node;
+        if let Some(finally) = finally_block(*node) {
+            self.visit_statements(&block_statements(finally));
+        }
+    }
+}
+
+/// Whether an expression statement throws (`throw e;`, `rethrow;`)
+fn is_throw(node: Node) -> bool {
+    node.named_child(0)
+        .is_some_and(|child| matches!(child.kind(), "throw_expression" | "rethrow_expression"))
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::language::parser::LanguageParser;
+    use crate::language::DartParser;
+
+    /// CC of the first function in `source`
+    fn cc(source: &str) -> usize {
+        let module = DartParser::new()
+            .unwrap()
+            .parse(source, "test.dart")
+            .unwrap();
+        let function = module
+            .discover_functions(0, source)
+            .into_iter()
+            .next()
+            .expect("No function found in test source");
+        let cfg = DartCfgBuilder.build(&function);
+        assert!(
+            cfg.validate().is_ok(),
+            "CFG must be valid: {:?}",
+            cfg.validate()
+        );
+        // CC = E - N + 2
+        (cfg.edge_count() as isize - cfg.node_count() as isize + 2).max(1) as usize
+    }
+
+    #[test]
+    fn test_simple_function() {
+        assert_eq!(cc("int f(int x) => x + 1;"), 1);
+        assert_eq!(cc("int f(int x) {\n  return x + 1;\n}\n"), 1);
+    }
+
+    #[test]
+    fn test_else_if_chain() {
+        let source = r#"
+int sign(int x) {
+  if (x > 0) {
+    return 1;
+  } else if (x < 0) {
+    return -1;
+  } else {
+    return 0;
+  }
+}
+"#;
+        assert_eq!(cc(source), 3);
+    }
+
+    #[test]
+    fn test_switch_counts_each_case() {
+        let source = r#"
+String name(int x) {
+  switch (x) {
+    case 1:
+      return 'one';
+    case 2:
+    case 3:
+      return 'few';
+    default:
+      return 'many';
+  }
+}
+"#;
+        assert_eq!(cc(source), 4);
+    }
+
+    #[test]
+    fn test_switch_without_default() {
+        let source = r#"
+void log(int level) {
+  switch (level) {
+    case 0:
+      print('debug');
+      break;
+    case 1:
+      print('info');
+  }
+  print('done');
+}
+"#;
+        assert_eq!(cc(source), 3);
+    }
+
+    #[test]
+    fn test_loops() {
+        let source = r#"
+void loops(List<int> items) {
+  for (final item in items) {
+    if (item < 0) continue;
+    print(item);
+  }
+  var i = 0;
+  while (i < 10) {
+    i++;
+  }
+  do {
+    i--;
+  } while (i > 0);
+}
+"#;
+        assert_eq!(cc(source), 5);
+    }
+
+    #[test]
+    fn test_try_counts_each_handler() {
+        let source = r#"
+String load(String path) {
+  try {
+    return read(path);
+  } on FormatException {
+    return '';
+  } on IOException catch (e) {
+    rethrow;
+  } finally {
+    close(path);
+  }
+}
+"#;
+        assert_eq!(cc(source), 3);
+    }
+
+    #[test]
+    fn test_nested_definitions_are_not_entered() {
+        let source = r#"
+int outer(List<int> xs) {
+  int inner(int x) {
+    if (x > 0) return x;
+    return 0;
+  }
+  return xs.map(inner).fold(0, (a, b) => a + b);
+}
+"#;
+        assert_eq!(cc(source), 1);
+    }
+}
@@ -0,0 +1,240 @@
+//! Dart language support
+//!
+//! Parses Dart (and Flutter) source files using tree-sitter-dart; anonymous
+//! closures belong to the enclosing function.
+
+pub mod cfg_builder;
+pub mod parser;
+
+pub use cfg_builder::DartCfgBuilder;
+pub use parser::DartParser;
+
+use crate::language::tree_sitter_utils::find_child_by_kind;
+use tree_sitter::Node;
+
+/// Node kinds that start a discovered function: a signature followed by a
+/// `function_body`, or a local function
+pub(crate) const FUNCTION_KINDS: &[&str] = &[
+    "function_signature",
+    "method_signature",
+    "getter_signature",
+    "setter_signature",
+    "lambda_expression",
+];
+
+/// Whether `node` is a discovered function: a signature that has a body and
+/// is not part of a larger signature, or a local function
+pub(crate) fn is_function(node: Node<'_>) -> bool {
+    match node.kind() {
+        "lambda_expression" => true,
+        kind if FUNCTION_KINDS.contains(&kind) => {
+            !node.parent().is_some_and(|parent| {
+                matches!(parent.kind(), "method_signature" | "lambda_expression")
+            }) && function_body(node).is_some()
+        }
+        _ => false,
+    }
+}
+
+/// Where a function's declaration starts: at its first annotation
+/// (`@override`), so that a `// hotspots-ignore` comment above the
+/// annotations applies, or at the signature
+pub(crate) fn declaration_start(func_node: Node<'_>) -> usize {
+    let mut start = func_node.start_byte();
+    let mut sibling = func_node.prev_named_sibling();
+    while let Some(node) =
+        sibling.filter(|node| matches!(node.kind(), "annotation" | "marker_annotation"))
+    {
+        start = node.start_byte();
+        sibling = node.prev_named_sibling();
+    }
+    start
+}
+
+/// The function whose declaration starts at `start_byte` (see
+/// [`declaration_start`])
+pub(crate) fn find_function(root: Node<'_>, start_byte: usize) -> Option<Node<'_>> {
+    // The signature starts at or after `start_byte` (after any annotations)
+    if root.end_byte() <= start_byte {
+        return None;
+    }
+    if is_function(root) && declaration_start(root) == start_byte {
+        return Some(root);
+    }
+    let mut cursor = root.walk();
+    for child in root.children(&mut cursor) {
+        if let Some(found) = find_function(child, start_byte) {
+            return Some(found);
+        }
+    }
+    None
+}
+
+/// Local functions, which are discovered on their own and are not part of
+/// the enclosing function's control flow
+pub(crate) fn is_nested_definition(node: Node<'_>) -> bool {
+    matches!(
+        node.kind(),
+        "local_function_declaration" | "lambda_expression"
+    )
+}
+
+/// Anonymous closures, part of the enclosing function
+pub(crate) fn is_closure(node: Node<'_>) -> bool {
+    node.kind() == "function_expression"
+}
+
+/// The `function_body` of a discovered function: the next sibling of a
+/// signature, or the `body` of a local function. None for declarations
+/// without a body (abstract and `external` members).
+pub(crate) fn function_body(func_node: Node<'_>) -> Option<Node<'_>> {
+    if func_node.kind() == "lambda_expression" {
+        return func_node
+            .child_by_field_name("body")
+            .or_else(|| find_child_by_kind(func_node, "function_body"));
+    }
+    let mut sibling = func_node.next_named_sibling();
+    while let Some(node) = sibling {
+        match node.kind() {
+            "function_body" => return Some(node),
+            kind if kind.contains("comment") => sibling = node.next_named_sibling(),
+            _ => return None,
+        }
+    }
+    None
+}
+
+/// Statements of a block, or the node itself for a statement without braces
+pub(crate) fn block_statements(node: Node<'_>) -> Vec<Node<'_>> {
+    if node.kind() != "block" {
+        return vec![node];
+    }
+    let mut cursor = node.walk();
+    let statements = node
+        .named_children(&mut cursor)
+        .filter(|child| !child.kind().contains("comment"))
+        .collect();
+    statements
+}
+
+/// Statements of a `function_body`: its block's statements, or the
+/// expression after `=>`
+pub(crate) fn body_statements(body: Node<'_>) -> Vec<Node<'_>> {
+    let mut cursor = body.walk();
+    let children: Vec<Node> = body
+        .named_children(&mut cursor)
+        .filter(|child| !child.kind().contains("comment"))
+        .collect();
+    match children.iter().find(|child| child.kind() == "block") {
+        Some(block) => block_statements(*block),
+        None => children,
+    }
+}
+
+/// The `then` and `else` statements of an `if` statement
+pub(crate) fn if_branches(node: Node<'_>) -> (Option<Node<'_>>, Option<Node<'_>>) {
+    if let Some(consequence) = node.child_by_field_name("consequence") {
+        return (Some(consequence), node.child_by_field_name("alternative"));
+    }
+    let mut cursor = node.walk();
+    let mut statements = node.named_children(&mut cursor).filter(|child| {
+        child.kind() != "parenthesized_expression" && !child.kind().contains("comment")
+    });
+    (statements.next(), statements.next())
+}
+
+/// The body of a loop: the statement after the header, or the one after
+/// `do`
+pub(crate) fn loop_body(node: Node<'_>) -> Option<Node<'_>> {
+    if let Some(body) = node.child_by_field_name("body") {
+        return Some(body);
+    }
+    let mut cursor = node.walk();
+    let statements: Vec<Node> = node
+        .named_children(&mut cursor)
+        .filter(|child| {
+            !matches!(child.kind(), "parenthesized_expression" | "for_loop_parts")
+                && !child.kind().contains("comment")
+        })
+        .collect();
+    if node.kind() == "do_statement" {
+        statements.first().copied()
+    } else {
+        statements.last().copied()
+    }
+}
+
+/// Statements of a `case` or `default` clause of a `switch` statement: the
+/// ones after its `:`
+pub(crate) fn case_statements(clause: Node<'_>) -> Vec<Node<'_>> {
+    let mut statements = Vec::new();
+    let mut after_colon = false;
+    let mut cursor = clause.walk();
+    for child in clause.children(&mut cursor) {
+        if child.kind() == ":" {
+            after_colon = true;
+        } else if after_colon && child.is_named() && !child.kind().contains("comment") {
+            statements.push(child);
+        }
+    }
+    statements
+}
+
+/// The `case` and `default` clauses of a `switch` statement
+pub(crate) fn switch_clauses(switch: Node<'_>) -> Vec<Node<'_>> {
+    let Some(block) = switch
+        .child_by_field_name("body")
+        .or_else(|| find_child_by_kind(switch, "switch_block"))
+    else {
+        return Vec::new();
+    };
+    let mut cursor = block.walk();
+    let clauses = block
+        .named_children(&mut cursor)
+        .filter(|child| {
+            matches!(
+                child.kind(),
+                "switch_statement_case" | "switch_statement_default"
+            )
+        })
+        .collect();
+    clauses
+}
+
+/// Whether a switch expression arm counts as a branch: every arm except a
+/// bare `_` wildcard, which like `default` only catches the rest
+pub(crate) fn is_counted_arm(arm: Node<'_>, source: &str) -> bool {
+    let text = &source[arm.start_byte()..arm.end_byte()];
+    let pattern = text.split("=>").next().unwrap_or(text);
+    pattern.trim() != "_"
+}
+
+/// The `try` body and the handler blocks of a `try` statement, one per
+/// `on` / `catch` clause. The grammar puts `on Type`, `catch (e)`, and the
+/// handler block side by side under the `try_statement`, so every block
+/// after the first one is a handler.
+pub(crate) fn try_parts(try_node: Node<'_>) -> (Option<Node<'_>>, Vec<Node<'_>>) {
+    let mut body = None;
+    let mut handlers = Vec::new();
+    let mut cursor = try_node.walk();
+    for child in try_node.named_children(&mut cursor) {
+        let block = match child.kind() {
+            "block" => Some(child),
+            // A clause holding its own handler block
+            "catch_clause" | "on_part" => find_child_by_kind(child, "block"),
+            _ => None,
+        };
+        match (block, body) {
+            (Some(block), None) => body = Some(block),
+            (Some(block), Some(_)) => handlers.push(block),
+            (None, _) => {}
+        }
+    }
+    (body, handlers)
+}
+
+/// The `finally` block of a `try` statement
+pub(crate) fn finally_block(try_node: Node<'_>) -> Option<Node<'_>> {
+    let finally = find_child_by_kind(try_node, "finally_clause")?;
+    find_child_by_kind(finally, "block")
+}
@@ -0,0 +1,371 @@
+//! Dart language parser using tree-sitter
+
+use crate::ast::FunctionNode;
+use crate::language::dart::{declaration_start, function_body, is_function};
+use crate::language::parser::{LanguageParser, ParsedModule};
+use crate::language::tree_sitter_utils::syntax_errors;
+use anyhow::{Context, Result};
+use tree_sitter::{Node, Parser, Tree};
+
+/// Dart parser using tree-sitter
+pub struct DartParser;
+
+impl DartParser {
+    /// Create a new Dart parser
+    pub fn new() -> Result<Self> {
+        let mut parser = Parser::new();
+        let language = tree_sitter_dart::LANGUAGE;
+        parser
+            .set_language(&language.into())
+            .context("Failed to set Dart language for parser")?;
+        Ok(DartParser)
+    }
+}
+
+impl Default for DartParser {
+    fn default() -> Self {
+        Self::new().expect("Failed to create Dart parser")
+    }
+}
+
+impl LanguageParser for DartParser {
+    fn parse(&self, source: &str, filename: &str) -> Result<Box<dyn ParsedModule>> {
+        let mut parser = Parser::new();
+        let language = tree_sitter_dart::LANGUAGE;
+        parser
+            .set_language(&language.into())
+            .context("Failed to set Dart language")?;
+
+        let tree = parser
+            .parse(source, None)
+            .ok_or_else(|| anyhow::anyhow!("Failed to parse Dart file: {}", filename))?;
+
+        Ok(Box::new(DartModule {
+            tree,
+            source: source.to_string(),
+        }))
+    }
+}
+
+/// Parsed Dart module
+struct DartModule {
+    tree: Tree,
+    source: String,
+}
+
+impl ParsedModule for DartModule {
+    fn discover_functions(&self, file_index: usize, _source: &str) -> Vec<FunctionNode> {
+        let root = self.tree.root_node();
+        let mut functions = Vec::new();
+        discover_functions_recursive(root, &self.source, file_index, true, &mut functions);
+        functions.sort_by_key(|f| f.span.start);
+        functions
+    }
+
+    fn syntax_errors(&self) -> Vec<std::ops::Range<usize>> {
+        syntax_errors(self.tree.root_node())
+    }
+}
+
+/// Recursively discover functions in the Dart AST. Class, mixin, extension,
+/// and enum bodies are walked like any other node, so members and local
+/// functions are all found.
+///
+/// `public` is whether declarations here are visible outside the library:
+/// true at the top level and in the body of a type whose name does not start
+/// with `_`, false inside function bodies.
+fn discover_functions_recursive(
+    node: Node,
+    source: &str,
+    file_index: usize,
+    public: bool,
+    functions: &mut Vec<FunctionNode>,
+) {
+    if is_function(node) {
+        if let Some(function_node) =
+            extract_function(node, source, file_index, functions.len(), public)
+        {
+            functions.push(function_node);
+        }
+    }
+
+    let inner_public = match node.kind() {
+        "function_body" | "lambda_expression" => false,
+        "class_definition" | "mixin_declaration" | "extension_declaration" | "enum_declaration" => {
+            public
+                && !node
+                    .child_by_field_name("name")
+                    .is_some_and(|name| source[name.start_byte()..name.end_byte()].starts_with('_'))
+        }
+        _ => public,
+    };
+    let mut cursor = node.walk();
+    for child in node.children(&mut cursor) {
+        discover_functions_recursive(child, source, file_index, inner_public, functions);
+    }
+}
+
+/// Extract a FunctionNode from a signature and its body, or a local function
+fn extract_function(
+    node: Node,
+    source: &str,
+    file_index: usize,
+    local_index: usize,
+    public: bool,
+) -> Option<FunctionNode> {
+    use crate::ast::FunctionId;
+    use crate::language::{FunctionBody, SourceSpan};
+
+    let body_node = function_body(node)?;
+    let name = function_name(node, source);
+    // Library-private names start with `_`, as do private named
+    // constructors (`Cache._internal`)
+    let is_public = node.kind() != "lambda_expression"
+        && public
+        && !name
+            .as_deref()
+            .is_some_and(|name| name.split('.').any(|part| part.starts_with('_')));
+
+    let start = declaration_start(node);
+    let start_position = if start == node.start_byte() {
+        node.start_position()
+    } else {
+        node.parent()
+            .and_then(|parent| parent.descendant_for_byte_range(start, start))
+            .map_or(node.start_position(), |annotation| {
+                annotation.start_position()
+            })
+    };
+    let span = SourceSpan::new(
+        start,
+        body_node.end_byte(),
+        start_position.row as u32 + 1, // tree-sitter uses 0-indexed rows
+        body_node.end_position().row as u32 + 1, // tree-sitter uses 0-indexed rows
+        SourceSpan::column_of(source, start),
+    );
+
+    let body = FunctionBody::Dart {
+        body_node: body_node.id(),
+        source: source.to_string(),
+    };
+
+    Some(FunctionNode {
+        id: FunctionId {
+            file_index,
+            local_index,
+        },
+        name,
+        owner: None,
+        span,
+        body,
+        suppression_reason: None, // Will be extracted separately
+        signature_complexity: 0,
+        params: crate::params::dart_params(node),
+        is_public,
+        is_async: false,
+    })
+}
+
+/// Signatures a `method_signature` wraps
+const MEMBER_SIGNATURE_KINDS: &[&str] = &[
+    "function_signature",
+    "getter_signature",
+    "setter_signature",
+    "constructor_signature",
+    "factory_constructor_signature",
+    "redirecting_factory_constructor_signature",
+    "operator_signature",
+];
+
+/// Name of a function: the declared name, `Class` or `Class.named` for a
+/// constructor, and `operator ==` for an operator
+fn function_name(node: Node, source: &str) -> Option<String> {
+    let signature = match node.kind() {
+        "method_signature" | "lambda_expression" => {
+            let mut cursor = node.walk();
+            let inner = node
+                .named_children(&mut cursor)
+                .find(|child| MEMBER_SIGNATURE_KINDS.contains(&child.kind()));
+            inner?
+        }
+        _ => node,
+    };
+    let text = |n: Node| source[n.start_byte()..n.end_byte()].to_string();
+    match signature.kind() {
+        "constructor_signature"
+        | "factory_constructor_signature"
+        | "redirecting_factory_constructor_signature"
+        | "operator_signature" => {
+            // Everything before the parameters, less leading keywords and
+            // the operator's return type
+            let head = text(signature);
+            let head = head.split('(').next().unwrap_or_default();
+            let head = match head.find("operator") {
+                Some(at) => &head[at..],
+                None => head
+                    .trim_start_matches("const ")
+                    .trim_start_matches("factory ")
+                    .trim_start(),
+            };
+            let name = head.split_whitespace().collect::<Vec<_>>().join(" ");
+            (!name.is_empty()).then_some(name)
+        }
+        _ => signature.child_by_field_name("name").map(text),
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    fn discover(source: &str) -> Vec<FunctionNode> {
+        let parser = DartParser::new().unwrap();
+        let module = parser.parse(source, "test.dart").unwrap();
+        module.discover_functions(0, source)
+    }
+
+    fn names(functions: &[FunctionNode]) -> Vec<&str> {
+        functions
+            .iter()
+            .map(|f| f.name.as_deref().unwrap_or(""))
+            .collect()
+    }
+
+    #[test]
+    fn test_create_parser() {
+        assert!(DartParser::new().is_ok());
+    }
+
+    #[test]
+    fn test_parse_top_level_functions() {
+        let functions = discover(
+            r#"int add(int a, int b) {
+  return a + b;
+}
+
+int twice(int x) => x * 2;
+"#,
+        );
+        assert_eq!(names(&functions), vec!["add", "twice"]);
+        assert_eq!(functions[0].span.start_line, 1);
+        assert_eq!(functions[0].span.end_line, 3);
+        assert_eq!(functions[1].span.start_line, 5);
+    }
+
+    #[test]
+    fn test_parse_class_members() {
+        let functions = discover(
+            r#"abstract class Shape {
+  double get area;
+  String describe() => 'shape of area $area';
+}
+
+class Counter {
+  int _count = 0;
+
+  Counter(this._count) {
+    assert(_count >= 0);
+  }
+
+  int get count => _count;
+
+  set count(int value) {
+    _count = value;
+  }
+
+  void increment() {
+    _count++;
+  }
+
+  Counter operator +(Counter other) => Counter(_count + other._count);
+}
+"#,
+        );
+        // The abstract getter has no body
+        assert_eq!(
+            names(&functions),
+            vec![
+                "describe",
+                "Counter",
+                "count",
+                "count",
+                "increment",
+                "operator +"
+            ]
+        );
+    }
+
+    #[test]
+    fn test_parse_annotated_method_starts_at_annotation() {
+        let functions = discover(
+            r#"class Home extends StatelessWidget {
+  // hotspots-ignore: generated layout
+  @override
+  Widget build(BuildContext context) {
+    return const Text('home');
+  }
+}
+"#,
+        );
+        assert_eq!(names(&functions), vec!["build"]);
+        assert_eq!(functions[0].span.start_line, 3);
+    }
+
+    #[test]
+    fn test_parse_local_functions_but_not_closures() {
+        let functions = discover(
+            r#"int total(List<int> xs) {
+  int square(int x) => x * x;
+  final doubled = xs.map((x) => x * 2);
+  return doubled.map(square).fold(0, (a, b) => a + b);
+}
+"#,
+        );
+        // Closures passed as arguments are part of `total`
+        assert_eq!(names(&functions), vec!["total", "square"]);
+    }
+
+    #[test]
+    fn test_parse_visibility() {
+        let functions = discover(
+            r#"void helper() {}
+
+void _internal() {}
+
+class Service {
+  Service._create();
+  void run() {
+    void step() {}
+    step();
+  }
+  void _hidden() {}
+}
+
+class _Private {
+  void util() {}
+}
+"#,
+        );
+        let public: Vec<(&str, bool)> = functions
+            .iter()
+            .map(|f| (f.name.as_deref().unwrap(), f.is_public))
+            .collect();
+        assert_eq!(
+            public,
+            vec![
+                ("helper", true),
+                ("_internal", false),
+                ("run", true),
+                ("step", false),
+                ("_hidden", false),
+                ("util", false),
+            ]
+        );
+    }
+
+    #[test]
+    fn test_parse_empty_file() {
+        assert!(discover("").is_empty());
+        assert!(discover("import 'package:flutter/material.dart';\n").is_empty());
+    }
+}
@@ -7,19 +7,32 @@ use crate::cfg::Cfg;
 use anyhow::Result;
 use regex::Regex;
 use swc_common::{sync::Lrc, SourceMap};
-use swc_ecma_ast::Module;
+use swc_ecma_ast::{ArrowExpr, BlockStmt, BlockStmtOrExpr, Function, Module};
+use swc_ecma_visit::{VisitMut, VisitMutWith};
 
 /// ECMAScript parser using SWC
 ///
 /// Parses TypeScript and JavaScript files using the SWC compiler infrastructure.
 pub struct ECMAScriptParser {
     source_map: Lrc<SourceMap>,
+    separate_closures: bool,
 }
 
 impl ECMAScriptParser {
     /// Create a new ECMAScript parser with the given source map
     pub fn new(source_map: Lrc<SourceMap>) -> Self {
-        ECMAScriptParser { source_map }
+        ECMAScriptParser {
+            source_map,
+            separate_closures: false,
+        }
+    }
+
+    /// Leave the bodies of nested functions and arrows out of each
+    /// function's body (`--separate-closures`). They are discovered as
+    /// functions of their own either way.
+    pub fn with_separate_closures(mut self, separate_closures: bool) -> Self {
+        self.separate_closures = separate_closures;
+        self
     }
 }
 
@@ -30,6 +43,7 @@ impl LanguageParser for ECMAScriptParser {
         Ok(Box::new(ECMAScriptModule {
             module,
             source_map: self.source_map.clone(),
+            separate_closures: self.separate_closures,
         }))
     }
 }
@@ -38,11 +52,58 @@ impl LanguageParser for ECMAScriptParser {
 struct ECMAScriptModule {
     module: Module,
     source_map: Lrc<SourceMap>,
+    separate_closures: bool,
 }
 
 impl ParsedModule for ECMAScriptModule {
     fn discover_functions(&self, file_index: usize, source: &str) -> Vec<FunctionNode> {
-        crate::discover::discover_functions(&self.module, file_index, source, &self.source_map)
+        let mut functions =
+            crate::discover::discover_functions(&self.module, file_index, source, &self.source_map);
+        if self.separate_closures {
+            for function in &mut functions {
+                strip_nested_functions(function.body.as_ecmascript_mut());
+            }
+        }
+        functions
+    }
+
+    fn top_level_decisions(&self) -> usize {
+        crate::metrics::ecmascript_top_level_decisions(&self.module)
+    }
+}
+
+/// Empty the body of every function and arrow nested in `body`, so that
+/// metrics of the enclosing function no longer see their operators, calls,
+/// or exits. Each nested function keeps its own full body.
+fn strip_nested_functions(body: &mut BlockStmt) {
+    body.visit_mut_children_with(&mut NestedFunctionStripper);
+}
+
+struct NestedFunctionStripper;
+
+impl NestedFunctionStripper {
+    fn empty_block(span: swc_common::Span) -> BlockStmt {
+        BlockStmt {
+            span,
+            ctxt: Default::default(),
+            stmts: Vec::new(),
+        }
+    }
+}
+
+impl VisitMut for NestedFunctionStripper {
+    fn visit_mut_function(&mut self, function: &mut Function) {
+        if let Some(body) = &mut function.body {
+            *body = Self::empty_block(body.span);
+        }
+    }
+
+    fn visit_mut_arrow_expr(&mut self, arrow: &mut ArrowExpr) {
+        let span = match &*arrow.body {
+            BlockStmtOrExpr::BlockStmt(block) => block.span,
+            BlockStmtOrExpr::Expr(expr) => swc_common::Spanned::span(&**expr),
+        };
+        arrow.body = Box::new(BlockStmtOrExpr::BlockStmt(Self::empty_block(span)));
     }
 }
 
@@ -118,6 +179,12 @@ impl VueParser {
             inner: ECMAScriptParser::new(source_map),
         }
     }
+
+    /// See [`ECMAScriptParser::with_separate_closures`]
+    pub fn with_separate_closures(mut self, separate_closures: bool) -> Self {
+        self.inner = self.inner.with_separate_closures(separate_closures);
+        self
+    }
 }
 
 impl LanguageParser for VueParser {
@@ -158,6 +225,10 @@ impl ParsedModule for VueParsedModule {
         }
         functions
     }
+
+    fn top_level_decisions(&self) -> usize {
+        self.inner.top_level_decisions()
+    }
 }
 
 #[cfg(test)]
@@ -244,6 +315,37 @@ mod tests {
         assert_eq!(functions.len(), 2);
     }
 
+    #[test]
+    fn test_ecmascript_parser_separate_closures() {
+        let source_map: Lrc<SourceMap> = Default::default();
+        let parser = ECMAScriptParser::new(source_map).with_separate_closures(true);
+
+        let source = r#"
+function outer(items) {
+  items.forEach((item) => { if (item) { save(item); } });
+  const check = function () { return ready; };
+  return items.map((item) => item.id);
+}
+"#;
+        let module = parser.parse(source, "test.ts").unwrap();
+        let functions = module.discover_functions(0, source);
+
+        assert_eq!(functions.len(), 4);
+        let fan_out = |function: &FunctionNode| {
+            let cfg = ECMAScriptCfgBuilder.build(function);
+            crate::metrics::extract_metrics(function, &cfg).callee_names
+        };
+        // `save` is called from the arrow only
+        assert_eq!(fan_out(&functions[0]), vec!["items.forEach", "items.map"]);
+        assert_eq!(fan_out(&functions[1]), vec!["save"]);
+        // Each nested function keeps its own body
+        let nested: Vec<usize> = functions[1..]
+            .iter()
+            .map(|f| f.body.as_ecmascript().stmts.len())
+            .collect();
+        assert_eq!(nested, vec![1, 1, 1]);
+    }
+
     #[test]
     fn test_ecmascript_parser_parse_error() {
         let source_map: Lrc<SourceMap> = Default::default();
@@ -0,0 +1,372 @@
+//! Elixir CFG builder implementation
+
+use crate::ast::FunctionNode;
+use crate::cfg::{Cfg, NodeId, NodeKind};
+use crate::language::cfg_builder::{CfgBuilder, CfgState};
+use crate::language::elixir::{
+    block_statements, call_name, clause_block, do_block, do_statements, else_statements,
+    find_function, is_nested_definition, match_clauses, stab_clauses, stab_statements, EXIT_CALLS,
+};
+use crate::language::tree_sitter_utils::with_cached_elixir_tree;
+use tree_sitter::Node;
+
+/// Calls that branch or loop
+const CONTROL_CALLS: &[&str] = &[
+    "if", "unless", "case", "cond", "receive", "with", "try", "for",
+];
+
+/// Elixir CFG builder
+pub struct ElixirCfgBuilder;
+
+impl CfgBuilder for ElixirCfgBuilder {
+    fn build(&self, function: &FunctionNode) -> Cfg {
+        let (_body_node_id, source) = function.body.as_elixir();
+
+        let result = with_cached_elixir_tree(source, |root| {
+            let clauses = find_function(root, function.span.start, source)?;
+            let mut builder = ElixirCfgBuilderState {
+                flow: CfgState::new(),
+                source,
+            };
+            builder.visit_clauses(&clauses);
+            Some(builder.flow.finish())
+        });
+
+        result.unwrap_or_else(CfgState::straight_line)
+    }
+}
+
+struct ElixirCfgBuilderState<'s> {
+    flow: CfgState,
+    source: &'s str,
+}
+
+impl ElixirCfgBuilderState<'_> {
+    /// The clauses of a function: one body, or a branch per clause chosen by
+    /// matching the arguments against each head in turn
+    fn visit_clauses(&mut self, clauses: &[Node]) {
+        if let [clause] = clauses {
+            self.visit_statements(&do_statements(*clause, self.source));
+            return;
+        }
+        let Some(dispatch) = self.flow.add_after(NodeKind::Condition) else {
+            return;
+        };
+
+        let mut join_node = None;
+        for clause in clauses {
+            let statements = do_statements(*clause, self.source);
+            self.visit_branch(dispatch, &statements, &mut join_node);
+        }
+        self.flow.current_node = join_node;
+    }
+
+    fn visit_statements(&mut self, statements: &[Node]) {
+        for stmt in statements {
+            self.visit_node(stmt);
+        }
+    }
+
+    fn visit_node(&mut self, node: &Node) {
+        match call_name(*node, self.source) {
+            Some("if" | "unless") => self.visit_if(node),
+            Some("case" | "cond" | "receive") => self.visit_match(node),
+            Some("with") => self.visit_with(node),
+            Some("try") => self.visit_try(node),
+            Some("for") => self.visit_loop(node),
+            Some(name) if EXIT_CALLS.contains(&name) => {
+                // `raise format(case ...)` branches before it raises
+                self.visit_nested(node);
+                self.flow.jump_to_exit();
+            }
+            _ if node.kind() == "anonymous_function" => self.flow.statement(),
+            _ if is_nested_definition(*node, self.source) => self.flow.statement(),
+            _ => {
+                if !self.visit_nested(node) {
+                    self.flow.statement();
+                }
+            }
+        }
+    }
+
+    /// Whether `node` is a call the CFG branches, loops, or exits at
+    fn is_control(&self, node: Node) -> bool {
+        call_name(node, self.source)
+            .is_some_and(|name| CONTROL_CALLS.contains(&name) || EXIT_CALLS.contains(&name))
+    }
+
+    /// Visit the control calls inside `node`, in source order, so that
+    /// `label = case status do ... end` branches. Anonymous functions and
+    /// nested definitions are not entered: their bodies run when they are
+    /// called. Returns whether there were any.
+    fn visit_nested(&mut self, node: &Node) -> bool {
+        let mut found = false;
+        let mut cursor = node.walk();
+        for child in node.named_children(&mut cursor) {
+            if self.is_control(child) {
+                self.visit_node(&child);
+                found = true;
+            } else if child.kind() != "anonymous_function"
+                && !is_nested_definition(child, self.source)
+            {
+                found |= self.visit_nested(&child);
+            }
+        }
+        found
+    }
+
+    fn visit_branch(&mut self, from: NodeId, statements: &[Node], join: &mut Option<NodeId>) {
+        self.flow.start_branch(from);
+        self.visit_statements(statements);
+        self.flow.fall_through(join);
+    }
+
+    /// `if` / `unless` with an optional `else`, in block or keyword form
+    fn visit_if(&mut self, node: &Node) {
+        let Some(condition_node) = self.flow.add_after(NodeKind::Condition) else {
+            return;
+        };
+
+        let mut join_node = None;
+        let consequence = do_statements(*node, self.source);
+        self.visit_branch(condition_node, &consequence, &mut join_node);
+        match else_statements(*node, self.source) {
+            Some(alternative) => self.visit_branch(condition_node, &alternative, &mut join_node),
+            None => self.flow.skip_branches(condition_node, &mut join_node),
+        }
+        self.flow.current_node = join_node;
+    }
+
+    /// `case`, `cond`, and `receive`: each `->` clause is a branch, as is each
+    /// clause of a `receive`'s `after`. A `case` that no clause matches raises
+    /// rather than choosing a path, but the match node still gets an edge to
+    /// the join so that every clause adds one to CC.
+    fn visit_match(&mut self, node: &Node) {
+        let Some(match_node) = self.flow.add_after(NodeKind::Condition) else {
+            return;
+        };
+
+        let mut join_node = None;
+        let mut clauses: Vec<Node> = do_block(*node).map(stab_clauses).unwrap_or_default();
+        clauses.extend(clause_block(*node, "after_block").map_or_else(Vec::new, stab_clauses));
+        for clause in clauses {
+            self.visit_branch(match_node, &stab_statements(clause), &mut join_node);
+        }
+        self.flow.skip_branches(match_node, &mut join_node);
+        self.flow.current_node = join_node;
+    }
+
+    /// `with`: the `do` body runs when every `<-` matches. Each `<-` that
+    /// fails to match leaves the `with`, through the `else` clauses when there
+    /// are any, so each one is an edge to the join and each `else` clause is
+    /// a branch.
+    fn visit_with(&mut self, node: &Node) {
+        let Some(with_node) = self.flow.add_after(NodeKind::Condition) else {
+            return;
+        };
+
+        let mut join_node = None;
+        let body = do_statements(*node, self.source);
+        self.visit_branch(with_node, &body, &mut join_node);
+        if let Some(else_block) = clause_block(*node, "else_block") {
+            for clause in stab_clauses(else_block) {
+                self.visit_branch(with_node, &stab_statements(clause), &mut join_node);
+            }
+        }
+        for _ in match_clauses(*node, self.source) {
+            self.flow.skip_branches(with_node, &mut join_node);
+        }
+        self.flow.current_node = join_node;
+    }
+
+    /// `for` comprehensions
+    fn visit_loop(&mut self, node: &Node) {
+        let Some(header) = self.flow.start_loop() else {
+            return;
+        };
+        self.visit_statements(&do_statements(*node, self.source));
+        self.flow.end_loop(header);
+    }
+
+    /// `try ... rescue ... catch ... else ... after ... end`: the body and
+    /// each `rescue`, `catch`, and `else` clause are branches; `after` runs
+    /// after whichever one completes
+    fn visit_try(&mut self, node: &Node) {
+        let Some(try_node) = self.flow.add_after(NodeKind::Condition) else {
+            return;
+        };
+
+        let mut join_node = None;
+        let body = do_statements(*node, self.source);
+        self.visit_branch(try_node, &body, &mut join_node);
+        for kind in ["rescue_block", "catch_block", "else_block"] {
+            if let Some(block) = clause_block(*node, kind) {
+                for clause in stab_clauses(block) {
+                    self.visit_branch(try_node, &stab_statements(clause), &mut join_node);
+                }
+            }
+        }
+
+        self.flow.current_node = join_node;
+        if let Some(after) = clause_block(*node, "after_block") {
+            self.visit_statements(&block_statements(after));
+        }
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::language::parser::LanguageParser;
+    use crate::language::ElixirParser;
+
+    /// CC of the first function in `source`
+    fn cc(source: &str) -> usize {
+        let module = ElixirParser::new()
+            .unwrap()
+            .parse(source, "test.ex")
+            .unwrap();
+        let function = module
+            .discover_functions(0, source)
+            .into_iter()
+            .next()
+            .expect("No function found in test source");
+        let cfg = ElixirCfgBuilder.build(&function);
+        assert!(
+            cfg.validate().is_ok(),
+            "CFG must be valid: {:?}",
+            cfg.validate()
+        );
+        // CC = E - N + 2
+        (cfg.edge_count() as isize - cfg.node_count() as isize + 2).max(1) as usize
+    }
+
+    #[test]
+    fn test_straight_line() {
+        let source = "defmodule M do\n  def f(x) do\n    y = x + 1\n    y * 2\n  end\nend\n";
+        assert_eq!(cc(source), 1);
+    }
+
+    #[test]
+    fn test_if_else_and_unless() {
+        let source = r#"defmodule M do
+  def f(x) do
+    label = if x > 0, do: "pos", else: "neg"
+    unless x == 0 do
+      IO.puts(label)
+    end
+  end
+end
+"#;
+        assert_eq!(cc(source), 3);
+    }
+
+    #[test]
+    fn test_case_counts_every_clause() {
+        let source = r#"defmodule M do
+  def f(result) do
+    case result do
+      {:ok, value} -> value
+      {:error, :not_found} -> nil
+      _ -> raise "unexpected"
+    end
+  end
+end
+"#;
+        assert_eq!(cc(source), 4);
+    }
+
+    #[test]
+    fn test_cond() {
+        let source = r#"defmodule M do
+  def f(x) do
+    cond do
+      x < 0 -> :negative
+      x == 0 -> :zero
+      true -> :positive
+    end
+  end
+end
+"#;
+        assert_eq!(cc(source), 4);
+    }
+
+    #[test]
+    fn test_with_counts_matches_and_else_clauses() {
+        let source = r#"defmodule M do
+  def f(params) do
+    with {:ok, user} <- fetch(params),
+         {:ok, order} <- place(user) do
+      {:ok, order}
+    else
+      {:error, reason} -> {:error, reason}
+      :timeout -> {:error, :timeout}
+    end
+  end
+end
+"#;
+        // Two `<-` matches and two `else` clauses
+        assert_eq!(cc(source), 5);
+    }
+
+    #[test]
+    fn test_multi_clause_function() {
+        let source = r#"defmodule M do
+  def size(nil), do: 0
+  def size([]), do: 0
+  def size(list) do
+    if list == [], do: 0, else: length(list)
+  end
+end
+"#;
+        // Three clauses and an `if`
+        assert_eq!(cc(source), 4);
+    }
+
+    #[test]
+    fn test_try_rescue_after() {
+        let source = r#"defmodule M do
+  def f(path) do
+    try do
+      File.read!(path)
+    rescue
+      e in File.Error -> {:error, e}
+    catch
+      :exit, _ -> :exited
+    after
+      cleanup(path)
+    end
+  end
+end
+"#;
+        assert_eq!(cc(source), 3);
+    }
+
+    #[test]
+    fn test_raise_exits() {
+        let source = r#"defmodule M do
+  def f(x) do
+    if x < 0 do
+      raise ArgumentError, "negative"
+    end
+    x
+  end
+end
+"#;
+        assert_eq!(cc(source), 2);
+    }
+
+    #[test]
+    fn test_anonymous_functions_are_not_entered() {
+        let source = r#"defmodule M do
+  def f(xs) do
+    Enum.map(xs, fn
+      x when x > 0 -> if x > 10, do: :big, else: :small
+      _ -> :none
+    end)
+  end
+end
+"#;
+        assert_eq!(cc(source), 1);
+    }
+}
@@ -0,0 +1,303 @@
+//! Elixir language support
+//!
+//! Parses Elixir source files (`.ex`, `.exs`) using tree-sitter-elixir; the
+//! clauses of a multi-clause function are reported as one function.
+
+pub mod cfg_builder;
+pub mod parser;
+
+pub use cfg_builder::ElixirCfgBuilder;
+pub use parser::ElixirParser;
+
+use tree_sitter::Node;
+
+/// Calls that define a function
+pub(crate) const DEFINITION_KEYWORDS: &[&str] = &["def", "defp", "defmacro", "defmacrop"];
+
+/// Calls that raise or end the process, leaving the function early
+pub(crate) const EXIT_CALLS: &[&str] = &["raise", "reraise", "throw", "exit"];
+
+/// Blocks that follow a `do` block inside it: `else`, `rescue`, `catch`,
+/// and `after`
+const CLAUSE_BLOCK_KINDS: &[&str] = &["else_block", "rescue_block", "catch_block", "after_block"];
+
+/// Source text of `node`
+pub(crate) fn text<'a>(node: Node<'_>, source: &'a str) -> &'a str {
+    &source[node.start_byte()..node.end_byte()]
+}
+
+/// The name a local call calls (`def`, `case`, `validate`), or None for a
+/// remote call (`Repo.insert`) or any other node
+pub(crate) fn call_name<'a>(node: Node<'_>, source: &'a str) -> Option<&'a str> {
+    if node.kind() != "call" {
+        return None;
+    }
+    let target = node
+        .child_by_field_name("target")
+        .or_else(|| node.named_child(0))?;
+    (target.kind() == "identifier").then(|| text(target, source))
+}
+
+/// The `arguments` of a call
+pub(crate) fn call_arguments(call: Node<'_>) -> Option<Node<'_>> {
+    let mut cursor = call.walk();
+    let arguments = call
+        .named_children(&mut cursor)
+        .find(|child| child.kind() == "arguments");
+    arguments
+}
+
+/// The `do ... end` block of a call
+pub(crate) fn do_block(call: Node<'_>) -> Option<Node<'_>> {
+    let mut cursor = call.walk();
+    let block = call
+        .named_children(&mut cursor)
+        .find(|child| child.kind() == "do_block");
+    block
+}
+
+/// The value of a keyword argument such as `do:` or `else:` (`if ok, do: a,
+/// else: b`)
+pub(crate) fn keyword_value<'a>(call: Node<'a>, key: &str, source: &str) -> Option<Node<'a>> {
+    let arguments = call_arguments(call)?;
+    let mut cursor = arguments.walk();
+    let keywords: Vec<Node> = arguments
+        .named_children(&mut cursor)
+        .filter(|child| child.kind() == "keywords")
+        .collect();
+    keywords.into_iter().find_map(|keywords| {
+        let mut cursor = keywords.walk();
+        let pairs: Vec<Node> = keywords.named_children(&mut cursor).collect();
+        pairs.into_iter().find_map(|pair| {
+            let key_node = pair
+                .child_by_field_name("key")
+                .or_else(|| pair.named_child(0))?;
+            let name = text(key_node, source).trim().trim_end_matches(':');
+            (name == key)
+                .then(|| {
+                    pair.child_by_field_name("value")
+                        .or_else(|| pair.named_child(1))
+                })
+                .flatten()
+        })
+    })
+}
+
+/// Whether `node` is a clause of a function definition: a `def`-family call
+/// with a body
+pub(crate) fn is_function_clause(node: Node<'_>, source: &str) -> bool {
+    call_name(node, source).is_some_and(|name| DEFINITION_KEYWORDS.contains(&name))
+        && (do_block(node).is_some() || keyword_value(node, "do", source).is_some())
+}
+
+/// Whether `node` is a `when` guard expression (`x when x > 0`)
+fn is_when(node: Node<'_>, source: &str) -> bool {
+    node.kind() == "binary_operator"
+        && node
+            .child_by_field_name("operator")
+            .is_some_and(|op| text(op, source) == "when")
+}
+
+/// The head of a function clause, without its guard: `fetch(id)` in
+/// `def fetch(id) when is_integer(id) do`
+pub(crate) fn clause_head<'a>(clause: Node<'a>, source: &str) -> Option<Node<'a>> {
+    let head = call_arguments(clause)?.named_child(0)?;
+    if is_when(head, source) {
+        return head.child_by_field_name("left");
+    }
+    Some(head)
+}
+
+/// Name and arity of a function clause: `("fetch", 1)` for `def fetch(id)`,
+/// `("now", 0)` for `def now`
+pub(crate) fn clause_signature<'a>(clause: Node<'_>, source: &'a str) -> Option<(&'a str, usize)> {
+    let head = clause_head(clause, source)?;
+    match head.kind() {
+        "identifier" => Some((text(head, source), 0)),
+        "call" => {
+            let name = call_name(head, source)?;
+            let arity = call_arguments(head).map_or(0, |arguments| {
+                let mut cursor = arguments.walk();
+                let count = arguments
+                    .named_children(&mut cursor)
+                    .filter(|child| !child.kind().contains("comment"))
+                    .count();
+                count
+            });
+            Some((name, arity))
+        }
+        _ => None,
+    }
+}
+
+/// What groups clauses into one function: the definition keyword, name, and
+/// arity
+fn clause_key<'a>(clause: Node<'_>, source: &'a str) -> Option<(&'a str, &'a str, usize)> {
+    let (name, arity) = clause_signature(clause, source)?;
+    Some((call_name(clause, source)?, name, arity))
+}
+
+/// The function clause after `clause` if it continues the same function
+fn next_clause<'a>(clause: Node<'a>, source: &str) -> Option<Node<'a>> {
+    let mut sibling = clause.next_named_sibling();
+    while let Some(node) = sibling.filter(|node| node.kind() == "comment") {
+        sibling = node.next_named_sibling();
+    }
+    let next = sibling?;
+    (is_function_clause(next, source)
+        && clause_key(next, source).is_some()
+        && clause_key(next, source) == clause_key(clause, source))
+    .then_some(next)
+}
+
+/// Whether a function clause is the first clause of its function
+pub(crate) fn is_first_clause(clause: Node<'_>, source: &str) -> bool {
+    let mut sibling = clause.prev_named_sibling();
+    while let Some(node) = sibling.filter(|node| node.kind() == "comment") {
+        sibling = node.prev_named_sibling();
+    }
+    !sibling.is_some_and(|prev| {
+        is_function_clause(prev, source) && next_clause(prev, source) == Some(clause)
+    })
+}
+
+/// Every clause of the function whose first clause is `first`
+pub(crate) fn function_clauses<'a>(first: Node<'a>, source: &str) -> Vec<Node<'a>> {
+    let mut clauses = vec![first];
+    let mut clause = first;
+    while let Some(next) = next_clause(clause, source) {
+        clauses.push(next);
+        clause = next;
+    }
+    clauses
+}
+
+/// The clauses of the function whose first clause starts at `start_byte`
+pub(crate) fn find_function<'a>(
+    root: Node<'a>,
+    start_byte: usize,
+    source: &str,
+) -> Option<Vec<Node<'a>>> {
+    if root.start_byte() > start_byte || root.end_byte() <= start_byte {
+        return None;
+    }
+    if root.start_byte() == start_byte && is_function_clause(root, source) {
+        return Some(function_clauses(root, source));
+    }
+    let mut cursor = root.walk();
+    for child in root.children(&mut cursor) {
+        if let Some(found) = find_function(child, start_byte, source) {
+            return Some(found);
+        }
+    }
+    None
+}
+
+/// Function definitions nested in a function (inside a `quote` block of a
+/// macro), which are discovered on their own
+pub(crate) fn is_nested_definition(node: Node<'_>, source: &str) -> bool {
+    is_function_clause(node, source)
+}
+
+/// Expressions of a block, flattening `body` nodes and leaving out comments,
+/// `->` clauses, and the `else` / `rescue` / `catch` / `after` blocks
+pub(crate) fn block_statements(block: Node<'_>) -> Vec<Node<'_>> {
+    let mut statements = Vec::new();
+    let mut cursor = block.walk();
+    for child in block.named_children(&mut cursor) {
+        match child.kind() {
+            "body" => statements.extend(block_statements(child)),
+            "comment" | "stab_clause" => {}
+            kind if CLAUSE_BLOCK_KINDS.contains(&kind) => {}
+            _ => statements.push(child),
+        }
+    }
+    statements
+}
+
+/// Expressions a call runs for its `do`: the `do` block's, or the `do:`
+/// value
+pub(crate) fn do_statements<'a>(call: Node<'a>, source: &str) -> Vec<Node<'a>> {
+    match do_block(call) {
+        Some(block) => block_statements(block),
+        None => keyword_value(call, "do", source).into_iter().collect(),
+    }
+}
+
+/// A block that follows the `do` block (`else_block`, `rescue_block`, ...)
+pub(crate) fn clause_block<'a>(call: Node<'a>, kind: &str) -> Option<Node<'a>> {
+    let block = do_block(call)?;
+    let mut cursor = block.walk();
+    let found = block
+        .named_children(&mut cursor)
+        .find(|child| child.kind() == kind);
+    found.or_else(|| {
+        let mut cursor = call.walk();
+        let found = call
+            .named_children(&mut cursor)
+            .find(|child| child.kind() == kind);
+        found
+    })
+}
+
+/// Expressions of an `if` / `unless` `else`: the `else` block's, or the
+/// `else:` value. None without an `else`.
+pub(crate) fn else_statements<'a>(call: Node<'a>, source: &str) -> Option<Vec<Node<'a>>> {
+    match clause_block(call, "else_block") {
+        Some(block) => Some(block_statements(block)),
+        None => keyword_value(call, "else", source).map(|value| vec![value]),
+    }
+}
+
+/// The `->` clauses of a block or an anonymous function
+pub(crate) fn stab_clauses(node: Node<'_>) -> Vec<Node<'_>> {
+    let mut cursor = node.walk();
+    let clauses = node
+        .named_children(&mut cursor)
+        .filter(|child| child.kind() == "stab_clause")
+        .collect();
+    clauses
+}
+
+/// Expressions after the `->` of a clause
+pub(crate) fn stab_statements(clause: Node<'_>) -> Vec<Node<'_>> {
+    clause
+        .child_by_field_name("right")
+        .map(block_statements)
+        .unwrap_or_default()
+}
+
+/// The `<-` matches of a `with` or `for` (`{:ok, user} <- fetch(id)`)
+pub(crate) fn match_clauses<'a>(call: Node<'a>, source: &str) -> Vec<Node<'a>> {
+    let Some(arguments) = call_arguments(call) else {
+        return Vec::new();
+    };
+    let mut cursor = arguments.walk();
+    let clauses = arguments
+        .named_children(&mut cursor)
+        .filter(|child| {
+            child.kind() == "binary_operator"
+                && child
+                    .child_by_field_name("operator")
+                    .is_some_and(|op| text(op, source) == "<-")
+        })
+        .collect();
+    clauses
+}
+
+/// The filters of a `for` comprehension: arguments other than its `<-`
+/// generators and its options (`into:`, `uniq:`)
+pub(crate) fn comprehension_filters<'a>(call: Node<'a>, source: &str) -> Vec<Node<'a>> {
+    let Some(arguments) = call_arguments(call) else {
+        return Vec::new();
+    };
+    let generators = match_clauses(call, source);
+    let mut cursor = arguments.walk();
+    let filters = arguments
+        .named_children(&mut cursor)
+        .filter(|child| {
+            !generators.contains(child) && !matches!(child.kind(), "keywords" | "comment")
+        })
+        .collect();
+    filters
+}
@@ -0,0 +1,271 @@
+//! Elixir language parser using tree-sitter
+
+use crate::ast::FunctionNode;
+use crate::language::elixir::{
+    call_name, clause_signature, function_clauses, is_first_clause, is_function_clause,
+};
+use crate::language::parser::{LanguageParser, ParsedModule};
+use crate::language::tree_sitter_utils::syntax_errors;
+use anyhow::{Context, Result};
+use tree_sitter::{Node, Parser, Tree};
+
+/// Elixir parser using tree-sitter
+pub struct ElixirParser;
+
+impl ElixirParser {
+    /// Create a new Elixir parser
+    pub fn new() -> Result<Self> {
+        let mut parser = Parser::new();
+        let language = tree_sitter_elixir::LANGUAGE;
+        parser
+            .set_language(&language.into())
+            .context("Failed to set Elixir language for parser")?;
+        Ok(ElixirParser)
+    }
+}
+
+impl Default for ElixirParser {
+    fn default() -> Self {
+        Self::new().expect("Failed to create Elixir parser")
+    }
+}
+
+impl LanguageParser for ElixirParser {
+    fn parse(&self, source: &str, filename: &str) -> Result<Box<dyn ParsedModule>> {
+        let mut parser = Parser::new();
+        let language = tree_sitter_elixir::LANGUAGE;
+        parser
+            .set_language(&language.into())
+            .context("Failed to set Elixir language")?;
+
+        let tree = parser
+            .parse(source, None)
+            .ok_or_else(|| anyhow::anyhow!("Failed to parse Elixir file: {}", filename))?;
+
+        Ok(Box::new(ElixirModule {
+            tree,
+            source: source.to_string(),
+        }))
+    }
+}
+
+/// Parsed Elixir module
+struct ElixirModule {
+    tree: Tree,
+    source: String,
+}
+
+impl ParsedModule for ElixirModule {
+    fn discover_functions(&self, file_index: usize, _source: &str) -> Vec<FunctionNode> {
+        let root = self.tree.root_node();
+        let mut functions = Vec::new();
+        discover_functions_recursive(root, &self.source, file_index, &mut functions);
+        functions.sort_by_key(|f| f.span.start);
+        functions
+    }
+
+    fn syntax_errors(&self) -> Vec<std::ops::Range<usize>> {
+        syntax_errors(self.tree.root_node())
+    }
+}
+
+/// Recursively discover functions in the Elixir AST. `defmodule`, `defimpl`,
+/// and `quote` blocks are walked like any other node, so definitions in
+/// nested modules and in macros are all found. Each function is found at its
+/// first clause.
+fn discover_functions_recursive(
+    node: Node,
+    source: &str,
+    file_index: usize,
+    functions: &mut Vec<FunctionNode>,
+) {
+    if is_function_clause(node, source) && is_first_clause(node, source) {
+        if let Some(function_node) = extract_function(node, source, file_index, functions.len()) {
+            functions.push(function_node);
+        }
+    }
+
+    let mut cursor = node.walk();
+    for child in node.children(&mut cursor) {
+        discover_functions_recursive(child, source, file_index, functions);
+    }
+}
+
+/// Extract a FunctionNode from the first clause of a function, spanning all
+/// of its clauses
+fn extract_function(
+    node: Node,
+    source: &str,
+    file_index: usize,
+    local_index: usize,
+) -> Option<FunctionNode> {
+    use crate::ast::FunctionId;
+    use crate::language::{FunctionBody, SourceSpan};
+
+    let (name, _) = clause_signature(node, source)?;
+    let clauses = function_clauses(node, source);
+    let last = clauses.last().copied().unwrap_or(node);
+
+    let span = SourceSpan::new(
+        node.start_byte(),
+        last.end_byte(),
+        node.start_position().row as u32 + 1, // tree-sitter uses 0-indexed rows
+        last.end_position().row as u32 + 1,   // tree-sitter uses 0-indexed rows
+        SourceSpan::column_of(source, node.start_byte()),
+    );
+
+    let body = FunctionBody::Elixir {
+        body_node: node.id(),
+        source: source.to_string(),
+    };
+
+    Some(FunctionNode {
+        id: FunctionId {
+            file_index,
+            local_index,
+        },
+        name: Some(name.to_string()),
+        owner: None,
+        span,
+        body,
+        suppression_reason: None, // Will be extracted separately
+        signature_complexity: 0,
+        params: crate::params::elixir_params(node, source),
+        // `defp` and `defmacrop` are private to their module
+        is_public: matches!(call_name(node, source), Some("def" | "defmacro")),
+        is_async: false,
+    })
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    fn discover(source: &str) -> Vec<FunctionNode> {
+        let parser = ElixirParser::new().unwrap();
+        let module = parser.parse(source, "test.ex").unwrap();
+        module.discover_functions(0, source)
+    }
+
+    fn names(functions: &[FunctionNode]) -> Vec<&str> {
+        functions
+            .iter()
+            .map(|f| f.name.as_deref().unwrap_or(""))
+            .collect()
+    }
+
+    #[test]
+    fn test_create_parser() {
+        assert!(ElixirParser::new().is_ok());
+    }
+
+    #[test]
+    fn test_parse_module_functions() {
+        let functions = discover(
+            r#"defmodule Math do
+  def add(a, b) do
+    a + b
+  end
+
+  def twice(x), do: x * 2
+
+  defp now, do: System.monotonic_time()
+end
+"#,
+        );
+        assert_eq!(names(&functions), vec!["add", "twice", "now"]);
+        assert_eq!(functions[0].span.start_line, 2);
+        assert_eq!(functions[0].span.end_line, 4);
+        assert_eq!(functions[1].span.start_line, 6);
+        assert_eq!(
+            functions.iter().map(|f| f.params).collect::<Vec<_>>(),
+            vec![2, 1, 0]
+        );
+    }
+
+    #[test]
+    fn test_parse_multi_clause_function_is_one_function() {
+        let functions = discover(
+            r#"defmodule Fib do
+  def fib(0), do: 0
+  def fib(1), do: 1
+  # the general case
+  def fib(n) when n > 1 do
+    fib(n - 1) + fib(n - 2)
+  end
+
+  def fib(n, memo), do: Map.get(memo, n)
+end
+"#,
+        );
+        // Clauses of fib/1 are grouped; fib/2 is another function
+        assert_eq!(names(&functions), vec!["fib", "fib"]);
+        assert_eq!(functions[0].span.start_line, 2);
+        assert_eq!(functions[0].span.end_line, 7);
+        assert_eq!(functions[0].params, 1);
+        assert_eq!(functions[1].span.start_line, 9);
+        assert_eq!(functions[1].params, 2);
+    }
+
+    #[test]
+    fn test_parse_bodiless_head_is_not_a_clause() {
+        let functions = discover(
+            r#"defmodule Paginate do
+  def page(query, opts \\ [])
+  def page(query, []), do: query
+  def page(query, opts), do: limit(query, opts)
+end
+"#,
+        );
+        // The default-argument head has no body
+        assert_eq!(names(&functions), vec!["page"]);
+        assert_eq!(functions[0].span.start_line, 3);
+        assert_eq!(functions[0].span.end_line, 4);
+    }
+
+    #[test]
+    fn test_parse_visibility() {
+        let functions = discover(
+            r#"defmodule Service do
+  def run(x), do: step(x)
+  defp step(x), do: x
+  defmacro trace(expr), do: expr
+  defmacrop hidden(expr), do: expr
+end
+"#,
+        );
+        let public: Vec<(&str, bool)> = functions
+            .iter()
+            .map(|f| (f.name.as_deref().unwrap(), f.is_public))
+            .collect();
+        assert_eq!(
+            public,
+            vec![
+                ("run", true),
+                ("step", false),
+                ("trace", true),
+                ("hidden", false),
+            ]
+        );
+    }
+
+    #[test]
+    fn test_parse_anonymous_functions_are_not_functions() {
+        let functions = discover(
+            r#"defmodule Totals do
+  def total(xs) do
+    square = fn x -> x * x end
+    xs |> Enum.map(square) |> Enum.reduce(0, &(&1 + &2))
+  end
+end
+"#,
+        );
+        assert_eq!(names(&functions), vec!["total"]);
+    }
+
+    #[test]
+    fn test_parse_empty_file() {
+        assert!(discover("").is_empty());
+        assert!(discover("defmodule Empty do\nend\n").is_empty());
+    }
+}
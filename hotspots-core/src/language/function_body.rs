@@ -1,5 +1,6 @@
 //! Language-agnostic function body representation
 
+use crate::language::sql::SqlDialect;
 use swc_ecma_ast::BlockStmt;
 
 /// Language-agnostic function body
@@ -75,6 +76,138 @@ pub enum FunctionBody {
         /// The source code (needed to reconstruct the tree)
         source: String,
     },
+
+    /// C++ function body
+    ///
+    /// Contains the tree-sitter node ID for the compound_statement body of a
+    /// function or lambda and the source code.
+    Cpp {
+        /// The tree-sitter node ID for the function body
+        body_node: usize,
+        /// The source code (needed to reconstruct the tree)
+        source: String,
+    },
+
+    /// Swift function body
+    ///
+    /// Contains the tree-sitter node ID for the `function_body` (or, for
+    /// property accessors, the accessor node itself) and the source code.
+    Swift {
+        /// The tree-sitter node ID for the function body block
+        body_node: usize,
+        /// The source code (needed to reconstruct the tree)
+        source: String,
+    },
+
+    /// PHP function body
+    ///
+    /// Contains the tree-sitter node ID for the function's `body` field (a
+    /// block, or an arrow function's expression) and the source code.
+    Php {
+        /// The tree-sitter node ID for the function body
+        body_node: usize,
+        /// The source code (needed to reconstruct the tree)
+        source: String,
+    },
+
+    /// Scala function body
+    ///
+    /// Contains the tree-sitter node ID for the function's body (a block, an
+    /// indented block, or the expression after `=`) and the source code.
+    Scala {
+        /// The tree-sitter node ID for the function body
+        body_node: usize,
+        /// The source code (needed to reconstruct the tree)
+        source: String,
+    },
+
+    /// Dart function body
+    ///
+    /// Contains the tree-sitter node ID for the function's `function_body`
+    /// (a block or `=> expression`) and the source code.
+    Dart {
+        /// The tree-sitter node ID for the function body
+        body_node: usize,
+        /// The source code (needed to reconstruct the tree)
+        source: String,
+    },
+
+    /// Elixir function body
+    ///
+    /// Contains the tree-sitter node ID for the function's first clause (a
+    /// `def` call) and the source code.
+    Elixir {
+        /// The tree-sitter node ID for the first clause
+        body_node: usize,
+        /// The source code (needed to reconstruct the tree)
+        source: String,
+    },
+
+    /// Lua function body
+    ///
+    /// Contains the tree-sitter node ID for the function (a declaration or an
+    /// anonymous `function ... end`) and the source code.
+    Lua {
+        /// The tree-sitter node ID for the function
+        body_node: usize,
+        /// The source code (needed to reconstruct the tree)
+        source: String,
+    },
+
+    /// Bash function body
+    ///
+    /// Contains the tree-sitter node ID for the function definition and the
+    /// source code.
+    Bash {
+        /// The tree-sitter node ID for the function
+        body_node: usize,
+        /// The source code (needed to reconstruct the tree)
+        source: String,
+    },
+
+    /// Zig function body
+    ///
+    /// Contains the tree-sitter node ID for the function declaration and the
+    /// source code.
+    Zig {
+        /// The tree-sitter node ID for the function
+        body_node: usize,
+        /// The source code (needed to reconstruct the tree)
+        source: String,
+    },
+
+    /// Haskell function body
+    ///
+    /// Contains the tree-sitter node ID for the function's first equation and
+    /// the source code.
+    Haskell {
+        /// The tree-sitter node ID for the first equation
+        body_node: usize,
+        /// The source code (needed to reconstruct the tree)
+        source: String,
+    },
+
+    /// Perl sub body
+    ///
+    /// Contains the tree-sitter node ID for the named or anonymous sub and
+    /// the source code.
+    Perl {
+        /// The tree-sitter node ID for the sub
+        body_node: usize,
+        /// The source code (needed to reconstruct the tree)
+        source: String,
+    },
+
+    /// SQL stored function or procedure body
+    ///
+    /// Contains the procedural body text, re-tokenized on demand when
+    /// extracting metrics, and the dialect it was parsed as.
+    Sql {
+        /// The body source (dollar-quoted text or everything after `AS`)
+        source: String,
+        /// The dialect used for keyword counting
+        dialect: SqlDialect,
+    },
 }
 
 impl FunctionBody {
@@ -118,6 +251,66 @@ impl FunctionBody {
         matches!(self, FunctionBody::C { .. })
     }
 
+    /// Check if this is a C++ function body
+    pub fn is_cpp(&self) -> bool {
+        matches!(self, FunctionBody::Cpp { .. })
+    }
+
+    /// Check if this is a Swift function body
+    pub fn is_swift(&self) -> bool {
+        matches!(self, FunctionBody::Swift { .. })
+    }
+
+    /// Check if this is a PHP function body
+    pub fn is_php(&self) -> bool {
+        matches!(self, FunctionBody::Php { .. })
+    }
+
+    /// Check if this is a Scala function body
+    pub fn is_scala(&self) -> bool {
+        matches!(self, FunctionBody::Scala { .. })
+    }
+
+    /// Check if this is a Dart function body
+    pub fn is_dart(&self) -> bool {
+        matches!(self, FunctionBody::Dart { .. })
+    }
+
+    /// Check if this is an Elixir function body
+    pub fn is_elixir(&self) -> bool {
+        matches!(self, FunctionBody::Elixir { .. })
+    }
+
+    /// Check if this is a Lua function body
+    pub fn is_lua(&self) -> bool {
+        matches!(self, FunctionBody::Lua { .. })
+    }
+
+    /// Check if this is a Bash function body
+    pub fn is_bash(&self) -> bool {
+        matches!(self, FunctionBody::Bash { .. })
+    }
+
+    /// Check if this is a Zig function body
+    pub fn is_zig(&self) -> bool {
+        matches!(self, FunctionBody::Zig { .. })
+    }
+
+    /// Check if this is a Haskell function body
+    pub fn is_haskell(&self) -> bool {
+        matches!(self, FunctionBody::Haskell { .. })
+    }
+
+    /// Check if this is a Perl sub body
+    pub fn is_perl(&self) -> bool {
+        matches!(self, FunctionBody::Perl { .. })
+    }
+
+    /// Check if this is a SQL function body
+    pub fn is_sql(&self) -> bool {
+        matches!(self, FunctionBody::Sql { .. })
+    }
+
     /// Get the ECMAScript body, if this is one
     ///
     /// # Panics
@@ -214,6 +407,153 @@ impl FunctionBody {
             _ => panic!("FunctionBody is not C"),
         }
     }
+
+    /// Get the C++ body node ID and source, if this is a C++ function
+    ///
+    /// # Panics
+    ///
+    /// Panics if this is not a C++ body. Use `is_cpp()` to check first.
+    pub fn as_cpp(&self) -> (usize, &str) {
+        match self {
+            FunctionBody::Cpp { body_node, source } => (*body_node, source.as_str()),
+            _ => panic!("FunctionBody is not C++"),
+        }
+    }
+
+    /// Get the Swift body node ID and source, if this is a Swift function
+    ///
+    /// # Panics
+    ///
+    /// Panics if this is not a Swift body. Use `is_swift()` to check first.
+    pub fn as_swift(&self) -> (usize, &str) {
+        match self {
+            FunctionBody::Swift { body_node, source } => (*body_node, source.as_str()),
+            _ => panic!("FunctionBody is not Swift"),
+        }
+    }
+
+    /// Get the PHP body node ID and source, if this is a PHP function
+    ///
+    /// # Panics
+    ///
+    /// Panics if this is not a PHP body. Use `is_php()` to check first.
+    pub fn as_php(&self) -> (usize, &str) {
+        match self {
+            FunctionBody::Php { body_node, source } => (*body_node, source.as_str()),
+            _ => panic!("FunctionBody is not PHP"),
+        }
+    }
+
+    /// Get the Scala body node ID and source, if this is a Scala function
+    ///
+    /// # Panics
+    ///
+    /// Panics if this is not a Scala body. Use `is_scala()` to check first.
+    pub fn as_scala(&self) -> (usize, &str) {
+        match self {
+            FunctionBody::Scala { body_node, source } => (*body_node, source.as_str()),
+            _ => panic!("FunctionBody is not Scala"),
+        }
+    }
+
+    /// Get the Dart body node ID and source, if this is a Dart function
+    ///
+    /// # Panics
+    ///
+    /// Panics if this is not a Dart body. Use `is_dart()` to check first.
+    pub fn as_dart(&self) -> (usize, &str) {
+        match self {
+            FunctionBody::Dart { body_node, source } => (*body_node, source.as_str()),
+            _ => panic!("FunctionBody is not Dart"),
+        }
+    }
+
+    /// Get the Elixir first-clause node ID and source, if this is an Elixir
+    /// function
+    ///
+    /// # Panics
+    ///
+    /// Panics if this is not an Elixir body. Use `is_elixir()` to check first.
+    pub fn as_elixir(&self) -> (usize, &str) {
+        match self {
+            FunctionBody::Elixir { body_node, source } => (*body_node, source.as_str()),
+            _ => panic!("FunctionBody is not Elixir"),
+        }
+    }
+
+    /// Get the Lua function node ID and source, if this is a Lua function
+    ///
+    /// # Panics
+    ///
+    /// Panics if this is not a Lua body. Use `is_lua()` to check first.
+    pub fn as_lua(&self) -> (usize, &str) {
+        match self {
+            FunctionBody::Lua { body_node, source } => (*body_node, source.as_str()),
+            _ => panic!("FunctionBody is not Lua"),
+        }
+    }
+
+    /// Get the Bash function node ID and source, if this is a Bash function
+    ///
+    /// # Panics
+    ///
+    /// Panics if this is not a Bash body. Use `is_bash()` to check first.
+    pub fn as_bash(&self) -> (usize, &str) {
+        match self {
+            FunctionBody::Bash { body_node, source } => (*body_node, source.as_str()),
+            _ => panic!("FunctionBody is not Bash"),
+        }
+    }
+
+    /// Get the Zig function node ID and source, if this is a Zig function
+    ///
+    /// # Panics
+    ///
+    /// Panics if this is not a Zig body. Use `is_zig()` to check first.
+    pub fn as_zig(&self) -> (usize, &str) {
+        match self {
+            FunctionBody::Zig { body_node, source } => (*body_node, source.as_str()),
+            _ => panic!("FunctionBody is not Zig"),
+        }
+    }
+
+    /// Get the Haskell equation node ID and source, if this is a Haskell
+    /// function
+    ///
+    /// # Panics
+    ///
+    /// Panics if this is not a Haskell body. Use `is_haskell()` to check
+    /// first.
+    pub fn as_haskell(&self) -> (usize, &str) {
+        match self {
+            FunctionBody::Haskell { body_node, source } => (*body_node, source.as_str()),
+            _ => panic!("FunctionBody is not Haskell"),
+        }
+    }
+
+    /// Get the Perl sub node ID and source, if this is a Perl sub
+    ///
+    /// # Panics
+    ///
+    /// Panics if this is not a Perl body. Use `is_perl()` to check first.
+    pub fn as_perl(&self) -> (usize, &str) {
+        match self {
+            FunctionBody::Perl { body_node, source } => (*body_node, source.as_str()),
+            _ => panic!("FunctionBody is not Perl"),
+        }
+    }
+
+    /// Get the SQL body source and dialect, if this is a SQL function
+    ///
+    /// # Panics
+    ///
+    /// Panics if this is not a SQL body. Use `is_sql()` to check first.
+    pub fn as_sql(&self) -> (&str, SqlDialect) {
+        match self {
+            FunctionBody::Sql { source, dialect } => (source.as_str(), *dialect),
+            _ => panic!("FunctionBody is not SQL"),
+        }
+    }
 }
 
 // Implement From for easy conversion
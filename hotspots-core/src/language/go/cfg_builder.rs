@@ -3,6 +3,7 @@
 use crate::ast::FunctionNode;
 use crate::cfg::{Cfg, NodeId, NodeKind};
 use crate::language::cfg_builder::CfgBuilder;
+use crate::language::go::FUNCTION_KINDS;
 use crate::language::tree_sitter_utils::{
     find_child_by_kind, find_function_by_start, with_cached_go_tree,
 };
@@ -18,11 +19,7 @@ impl CfgBuilder for GoCfgBuilder {
         let (_body_node_id, source) = function.body.as_go();
 
         let result = with_cached_go_tree(source, |root| {
-            let func_node = find_function_by_start(
-                root,
-                function.span.start,
-                &["function_declaration", "method_declaration"],
-            )?;
+            let func_node = find_function_by_start(root, function.span.start, FUNCTION_KINDS)?;
             let body_node = find_child_by_kind(func_node, "block")?;
             let mut builder = GoCfgBuilderState::new();
             builder.build_from_block(&body_node, source);
@@ -219,6 +216,7 @@ impl GoCfgBuilderState {
         // Switch condition
         let condition_node = self.cfg.add_node(NodeKind::Condition);
         self.cfg.add_edge(from_node, condition_node);
+        self.cfg.switches.insert(node.start_byte(), condition_node);
 
         // Join node after switch
         let join_node = self.cfg.add_node(NodeKind::Join);
@@ -266,6 +264,7 @@ impl GoCfgBuilderState {
         // Select condition (non-deterministic choice)
         let condition_node = self.cfg.add_node(NodeKind::Condition);
         self.cfg.add_edge(from_node, condition_node);
+        self.cfg.switches.insert(node.start_byte(), condition_node);
 
         // Join node after select
         let join_node = self.cfg.add_node(NodeKind::Join);
@@ -382,12 +381,17 @@ mod tests {
                 local_index: 0,
             },
             name: Some("test".to_string()),
+            owner: None,
             span: SourceSpan::new(0, source.len(), 1, 1, 0),
             body: FunctionBody::Go {
                 body_node: 0,
                 source: source.to_string(),
             },
             suppression_reason: None,
+            signature_complexity: 0,
+            params: 0,
+            is_public: false,
+            is_async: false,
         }
     }
 
@@ -2,9 +2,55 @@
 //!
 //! This module provides Go language parsing, function discovery, and CFG building
 //! using the tree-sitter-go parser.
+//!
+//! Functions are function and method declarations; with `--separate-closures`,
+//! function literals too, named after their parent by [`AnonNaming`].
 
 pub mod cfg_builder;
 pub mod parser;
 
+use serde::{Deserialize, Serialize};
+
 pub use cfg_builder::GoCfgBuilder;
 pub use parser::GoParser;
+
+/// How closures reported with `--separate-closures` are named after their
+/// parent (`anon_naming`, `--anon-naming`). Names under one parent that would
+/// repeat get `#2`, `#3`, ... in source order.
+///
+/// A closure's name is its identity in baselines, deltas, and history, so the
+/// schemes differ in what renames it:
+/// - `Context` (the default: `Outer.go`, `Outer.check`, `Outer.Slice`) names
+///   a closure after where it sits: the variable or field it is assigned to,
+///   the call it is passed to, or the `go`, `defer`, or `return` statement it
+///   belongs to (`func` when there is none). Only adding or removing a closure
+///   with the same name under the same parent renames others.
+/// - `Index` (`Outer.func1`, `Outer.func1.1`) matches the Go runtime's names
+///   in stack traces, but adding or removing a closure renames every later
+///   one in the same function.
+/// - `Line` (`Outer.line15`) is unaffected by other closures, but any edit
+///   above the closure that adds or removes lines renames it.
+#[derive(Debug, Clone, Copy, Default, PartialEq, Eq, Hash, Serialize, Deserialize)]
+#[serde(rename_all = "lowercase")]
+pub enum AnonNaming {
+    Index,
+    Line,
+    #[default]
+    Context,
+}
+
+impl AnonNaming {
+    /// Parse from the config / CLI name ("index", "line", or "context")
+    pub fn from_name(s: &str) -> Option<Self> {
+        match s {
+            "index" => Some(AnonNaming::Index),
+            "line" => Some(AnonNaming::Line),
+            "context" => Some(AnonNaming::Context),
+            _ => None,
+        }
+    }
+}
+
+/// Node kinds that can be a discovered function
+pub(crate) const FUNCTION_KINDS: &[&str] =
+    &["function_declaration", "method_declaration", "func_literal"];
@@ -1,13 +1,17 @@
 //! Go language parser using tree-sitter
 
+use super::AnonNaming;
 use crate::ast::FunctionNode;
 use crate::language::parser::{LanguageParser, ParsedModule};
-use crate::language::tree_sitter_utils::find_child_by_kind;
+use crate::language::tree_sitter_utils::{find_child_by_kind, syntax_errors};
 use anyhow::{Context, Result};
 use tree_sitter::{Node, Parser, Tree};
 
 /// Go parser using tree-sitter
-pub struct GoParser;
+pub struct GoParser {
+    separate_closures: bool,
+    anon_naming: AnonNaming,
+}
 
 impl GoParser {
     /// Create a new Go parser
@@ -18,7 +22,25 @@ impl GoParser {
         parser
             .set_language(&language.into())
             .context("Failed to set Go language for parser")?;
-        Ok(GoParser)
+        Ok(GoParser {
+            separate_closures: false,
+            anon_naming: AnonNaming::default(),
+        })
+    }
+
+    /// Discover function literals inside functions as functions of their own,
+    /// named after their parent (`Outer.go`, `Outer.check`), and leave their
+    /// bodies out of the enclosing function (`--separate-closures`)
+    pub fn with_separate_closures(mut self, separate_closures: bool) -> Self {
+        self.separate_closures = separate_closures;
+        self
+    }
+
+    /// Name closures by `anon_naming` instead of the context they sit in
+    /// (see [`AnonNaming`])
+    pub fn with_anon_naming(mut self, anon_naming: AnonNaming) -> Self {
+        self.anon_naming = anon_naming;
+        self
     }
 }
 
@@ -45,6 +67,8 @@ impl LanguageParser for GoParser {
         Ok(Box::new(GoModule {
             tree,
             source: source.to_string(),
+            separate_closures: self.separate_closures,
+            anon_naming: self.anon_naming,
         }))
     }
 }
@@ -53,6 +77,8 @@ impl LanguageParser for GoParser {
 struct GoModule {
     tree: Tree,
     source: String,
+    separate_closures: bool,
+    anon_naming: AnonNaming,
 }
 
 impl ParsedModule for GoModule {
@@ -61,49 +87,273 @@ impl ParsedModule for GoModule {
         let mut functions = Vec::new();
 
         // Walk the tree to find function declarations
-        discover_functions_recursive(root, &self.source, file_index, &mut functions);
+        discover_functions_recursive(
+            root,
+            &self.source,
+            file_index,
+            self.separate_closures.then_some(self.anon_naming),
+            &mut functions,
+        );
 
         // Sort by source position for determinism
         functions.sort_by_key(|f| f.span.start);
 
         functions
     }
+
+    fn syntax_errors(&self) -> Vec<std::ops::Range<usize>> {
+        syntax_errors(self.tree.root_node())
+    }
 }
 
-/// Recursively discover function declarations in the Go AST
+/// Recursively discover function declarations in the Go AST. With
+/// `closures` (`--separate-closures`), function literals inside them are
+/// discovered too, named by that scheme (see [`discover_closures`]).
 fn discover_functions_recursive(
     node: Node,
     source: &str,
     file_index: usize,
+    closures: Option<AnonNaming>,
     functions: &mut Vec<FunctionNode>,
 ) {
     // Check if this node is a function declaration
     if node.kind() == "function_declaration" || node.kind() == "method_declaration" {
-        if let Some(function_node) = extract_function(node, source, file_index, functions.len()) {
+        let owner = node
+            .child_by_field_name("receiver")
+            .and_then(|receiver| receiver_type_name(receiver, source));
+        let name = extract_function_name(node, owner, source);
+        // Exported identifiers start with an upper-case letter; a method's
+        // name follows its receiver type
+        let is_public = name
+            .as_deref()
+            .and_then(|n| n.rsplit('.').next())
+            .and_then(|n| n.chars().next())
+            .is_some_and(char::is_uppercase);
+        let prefix = name.clone().unwrap_or_default();
+        if let Some(mut function_node) = extract_function(
+            node,
+            name,
+            is_public,
+            source,
+            file_index,
+            functions.len(),
+            closures.is_some(),
+        ) {
+            function_node.owner = owner.map(str::to_string);
             functions.push(function_node);
         }
+        if let Some(naming) = closures {
+            let scope = ClosureScope {
+                parent: &prefix,
+                nested: false,
+                naming,
+            };
+            discover_closures(node, scope, source, file_index, functions);
+        }
+        // Declarations do not nest; function literals were handled above
+        return;
     }
 
     // Recurse into children
     let mut cursor = node.walk();
     for child in node.children(&mut cursor) {
-        discover_functions_recursive(child, source, file_index, functions);
+        discover_functions_recursive(child, source, file_index, closures, functions);
+    }
+}
+
+/// The function a closure is named after, and how
+#[derive(Clone, Copy)]
+struct ClosureScope<'a> {
+    /// Name of the enclosing declaration or closure
+    parent: &'a str,
+    /// Whether the parent is itself a closure
+    nested: bool,
+    naming: AnonNaming,
+}
+
+/// Discover the function literals directly under `node` (not inside another
+/// literal), in source order, each named `parent.segment`. With
+/// [`AnonNaming::Index`] the segment numbers them as the Go runtime does:
+/// `Outer.func1` for a declaration's closures and `Outer.func1.1` for those
+/// nested in `Outer.func1`.
+fn discover_closures(
+    node: Node,
+    scope: ClosureScope,
+    source: &str,
+    file_index: usize,
+    functions: &mut Vec<FunctionNode>,
+) {
+    let mut segments: Vec<String> = Vec::new();
+    for (i, literal) in closures_under(node).into_iter().enumerate() {
+        let segment = match scope.naming {
+            AnonNaming::Index if scope.nested => (i + 1).to_string(),
+            AnonNaming::Index => format!("func{}", i + 1),
+            AnonNaming::Line => format!("line{}", literal.start_position().row + 1),
+            AnonNaming::Context => {
+                closure_context(literal, source).unwrap_or_else(|| "func".to_string())
+            }
+        };
+        // Two closures on one line, or in the same context
+        let repeats = segments.iter().filter(|s| **s == segment).count();
+        let name = match repeats {
+            0 => format!("{}.{}", scope.parent, segment),
+            n => format!("{}.{}#{}", scope.parent, segment, n + 1),
+        };
+        segments.push(segment);
+        if let Some(function_node) = extract_function(
+            literal,
+            Some(name.clone()),
+            false,
+            source,
+            file_index,
+            functions.len(),
+            true,
+        ) {
+            functions.push(function_node);
+        }
+        let nested = ClosureScope {
+            parent: &name,
+            nested: true,
+            naming: scope.naming,
+        };
+        discover_closures(literal, nested, source, file_index, functions);
     }
 }
 
-/// Extract a FunctionNode from a tree-sitter function_declaration or method_declaration
+/// What [`AnonNaming::Context`] names a closure after: the `go`, `defer`, or
+/// `return` statement it belongs to, the variable or field it is assigned
+/// to, the struct field it initializes, or the call it is an argument of.
+/// A closure called in place takes the context of the call.
+fn closure_context(literal: Node, source: &str) -> Option<String> {
+    let mut node = literal;
+    let mut parent = node.parent()?;
+    while matches!(
+        parent.kind(),
+        "parenthesized_expression" | "literal_element"
+    ) {
+        node = parent;
+        parent = node.parent()?;
+    }
+    match parent.kind() {
+        "go_statement" => Some("go".to_string()),
+        "defer_statement" => Some("defer".to_string()),
+        "return_statement" => Some("return".to_string()),
+        // Called in place: `go func() { ... }()`, `v := func() T { ... }()`
+        "call_expression" if parent.child_by_field_name("function") == Some(node) => {
+            closure_context(parent, source)
+        }
+        "argument_list" => {
+            let call = parent.parent()?;
+            identifier_name(call.child_by_field_name("function")?, source)
+        }
+        "expression_list" => {
+            let statement = parent.parent()?;
+            if statement.kind() == "return_statement" {
+                return Some("return".to_string());
+            }
+            let mut cursor = parent.walk();
+            let position = parent
+                .named_children(&mut cursor)
+                .position(|child| child == node)?;
+            let target = match statement.kind() {
+                "short_var_declaration" | "assignment_statement" => {
+                    let left = statement.child_by_field_name("left")?;
+                    let mut cursor = left.walk();
+                    let target = left.named_children(&mut cursor).nth(position);
+                    target?
+                }
+                "var_spec" => {
+                    let mut cursor = statement.walk();
+                    let target = statement
+                        .children_by_field_name("name", &mut cursor)
+                        .nth(position);
+                    target?
+                }
+                _ => return None,
+            };
+            // The blank identifier says nothing about the closure
+            identifier_name(target, source).filter(|name| name != "_")
+        }
+        "keyed_element" => {
+            let key = parent.named_child(0)?;
+            if key == node {
+                return None;
+            }
+            let key = match key.kind() {
+                "literal_element" => key.named_child(0)?,
+                _ => key,
+            };
+            identifier_name(key, source)
+        }
+        _ => None,
+    }
+}
+
+/// `name` for an identifier, `field` for a selector `x.field`; None for any
+/// other expression
+fn identifier_name(node: Node, source: &str) -> Option<String> {
+    match node.kind() {
+        "identifier" | "field_identifier" => {
+            Some(source[node.start_byte()..node.end_byte()].to_string())
+        }
+        "selector_expression" => identifier_name(node.child_by_field_name("field")?, source),
+        _ => None,
+    }
+}
+
+/// Function literals under `node`, not counting those nested in another literal
+fn closures_under(node: Node) -> Vec<Node> {
+    fn collect<'a>(node: Node<'a>, literals: &mut Vec<Node<'a>>) {
+        let mut cursor = node.walk();
+        for child in node.children(&mut cursor) {
+            if child.kind() == "func_literal" {
+                literals.push(child);
+            } else {
+                collect(child, literals);
+            }
+        }
+    }
+    let mut literals = Vec::new();
+    collect(node, &mut literals);
+    literals
+}
+
+/// `source` with the inside of each closure body under `node` replaced by
+/// spaces (newlines kept), so that a function's metrics leave out its
+/// closures while byte offsets and line numbers stay put
+fn blank_closure_bodies(node: Node, source: &str) -> String {
+    let mut bytes = source.as_bytes().to_vec();
+    for literal in closures_under(node) {
+        let Some(body) = literal.child_by_field_name("body") else {
+            continue;
+        };
+        // Keep the braces so the literal still parses
+        let inside = body.start_byte() + 1..body.end_byte().saturating_sub(1);
+        for byte in &mut bytes[inside] {
+            if !matches!(*byte, b'\n' | b'\r') {
+                *byte = b' ';
+            }
+        }
+    }
+    // Whole UTF-8 sequences were replaced, so the result is still UTF-8
+    String::from_utf8(bytes).unwrap_or_else(|_| source.to_string())
+}
+
+/// Extract a FunctionNode from a tree-sitter function_declaration,
+/// method_declaration, or func_literal. With `separate_closures`, its body
+/// source leaves out the closures inside it.
 fn extract_function(
     node: Node,
+    name: Option<String>,
+    is_public: bool,
     source: &str,
     file_index: usize,
     local_index: usize,
+    separate_closures: bool,
 ) -> Option<FunctionNode> {
     use crate::ast::FunctionId;
     use crate::language::{FunctionBody, SourceSpan};
 
-    // Get function name
-    let name = extract_function_name(node, source);
-
     // Get function body (block node)
     let body_node = find_child_by_kind(node, "block")?;
 
@@ -113,13 +363,17 @@ fn extract_function(
         node.end_byte(),
         node.start_position().row as u32 + 1, // tree-sitter uses 0-indexed rows
         node.end_position().row as u32 + 1,   // tree-sitter uses 0-indexed rows
-        node.start_position().column as u32,
+        SourceSpan::column_of(source, node.start_byte()),
     );
 
     // Create FunctionBody::Go variant (placeholder for now)
     let body = FunctionBody::Go {
         body_node: body_node.id(),
-        source: source.to_string(),
+        source: if separate_closures {
+            blank_closure_bodies(node, source)
+        } else {
+            source.to_string()
+        },
     };
 
     Some(FunctionNode {
@@ -128,23 +382,44 @@ fn extract_function(
             local_index,
         },
         name,
+        owner: None,
         span,
         body,
         suppression_reason: None, // Will be extracted separately
+        signature_complexity: 0,
+        params: crate::params::go_params(node),
+        is_public,
+        is_async: false,
     })
 }
 
-/// Extract function name from a function_declaration or method_declaration node
-fn extract_function_name(node: Node, source: &str) -> Option<String> {
-    // For function_declaration: look for "identifier" child
-    // For method_declaration: look for "field_identifier" child
-    if let Some(name_node) = find_child_by_kind(node, "identifier")
-        .or_else(|| find_child_by_kind(node, "field_identifier"))
-    {
-        let name = &source[name_node.start_byte()..name_node.end_byte()];
-        return Some(name.to_string());
+/// Extract function name from a function_declaration or method_declaration
+/// node. Methods are named after their receiver type (`owner`, see
+/// [`receiver_type_name`]) like the Go runtime names them: `Container.Add`
+/// for `func (c *Container[T]) Add(...)`.
+fn extract_function_name(node: Node, owner: Option<&str>, source: &str) -> Option<String> {
+    let name_node = node.child_by_field_name("name")?;
+    let name = &source[name_node.start_byte()..name_node.end_byte()];
+    match owner {
+        Some(receiver) => Some(format!("{receiver}.{name}")),
+        None => Some(name.to_string()),
     }
-    None
+}
+
+/// The type a method's receiver list declares, stripped of the pointer and
+/// type parameters: `Container` for `(c *Container[K, V])` or `(Container[T])`
+fn receiver_type_name<'a>(receiver: Node, source: &'a str) -> Option<&'a str> {
+    let mut cursor = receiver.walk();
+    let declaration = receiver
+        .named_children(&mut cursor)
+        .find(|child| child.kind() == "parameter_declaration")?;
+    let ty = declaration.child_by_field_name("type")?;
+    let text = source[ty.start_byte()..ty.end_byte()]
+        .trim_start_matches(['*', '(', ' '])
+        .split(['[', ')'])
+        .next()?
+        .trim();
+    (!text.is_empty()).then_some(text)
 }
 
 #[cfg(test)]
@@ -206,7 +481,196 @@ func (m MyStruct) Method() {
         let functions = module.discover_functions(0, source);
 
         assert_eq!(functions.len(), 1);
-        assert_eq!(functions[0].name, Some("Method".to_string()));
+        assert_eq!(functions[0].name, Some("MyStruct.Method".to_string()));
+        assert_eq!(functions[0].owner, Some("MyStruct".to_string()));
+    }
+
+    #[test]
+    fn test_go_parser_generic_receivers() {
+        let parser = GoParser::new().unwrap();
+        let source = r#"
+package main
+
+func (c *Container[T]) Add(item T) {}
+func (c Container[T]) Len() int { return 0 }
+func (p *Pair[K, V]) swap() {}
+func (*Stack[T]) Push(item T) {}
+func Map[T, U any](xs []T, f func(T) U) []U { return nil }
+"#;
+        let module = parser.parse(source, "test.go").unwrap();
+        let functions = module.discover_functions(0, source);
+        let names: Vec<&str> = functions
+            .iter()
+            .map(|f| f.name.as_deref().unwrap())
+            .collect();
+
+        assert_eq!(
+            names,
+            vec![
+                "Container.Add",
+                "Container.Len",
+                "Pair.swap",
+                "Stack.Push",
+                "Map"
+            ]
+        );
+        let owners: Vec<Option<&str>> = functions.iter().map(|f| f.owner.as_deref()).collect();
+        assert_eq!(
+            owners,
+            vec![
+                Some("Container"),
+                Some("Container"),
+                Some("Pair"),
+                Some("Stack"),
+                None
+            ]
+        );
+        let public: Vec<bool> = functions.iter().map(|f| f.is_public).collect();
+        assert_eq!(public, vec![true, true, false, true, true]);
+        // Receivers and type parameters are not parameters
+        let params: Vec<usize> = functions.iter().map(|f| f.params).collect();
+        assert_eq!(params, vec![1, 0, 0, 1, 2]);
+    }
+
+    #[test]
+    fn test_go_parser_closures_folded_by_default() {
+        let parser = GoParser::new().unwrap();
+        let source = r#"
+package main
+
+func Run() {
+    go func() { work() }()
+}
+"#;
+        let module = parser.parse(source, "test.go").unwrap();
+        let functions = module.discover_functions(0, source);
+
+        assert_eq!(functions.len(), 1);
+        assert_eq!(functions[0].body.as_go().1, source);
+    }
+
+    #[test]
+    fn test_go_parser_separate_closures() {
+        let parser = GoParser::new().unwrap().with_separate_closures(true);
+        let source = r#"
+package main
+
+var handler = func() {}
+
+func Run(jobs []int) {
+    for _, job := range jobs {
+        go func(j int) {
+            retry := func() { work(j) }
+            retry()
+        }(job)
+    }
+    done := func() {}
+    done()
+}
+
+func (s *Server) Serve() {
+    s.handle(func() {})
+}
+"#;
+        let module = parser.parse(source, "test.go").unwrap();
+        let functions = module.discover_functions(0, source);
+        let names: Vec<&str> = functions
+            .iter()
+            .map(|f| f.name.as_deref().unwrap())
+            .collect();
+
+        // Package-level literals are not inside a function
+        assert_eq!(
+            names,
+            vec![
+                "Run",
+                "Run.go",
+                "Run.go.retry",
+                "Run.done",
+                "Server.Serve",
+                "Server.Serve.handle"
+            ]
+        );
+        let public: Vec<bool> = functions.iter().map(|f| f.is_public).collect();
+        assert_eq!(public, vec![true, false, false, false, true, false]);
+        assert_eq!(functions[1].params, 1);
+
+        // Each body leaves out the closures nested in it, keeping offsets
+        let run_source = functions[0].body.as_go().1;
+        assert_eq!(run_source.len(), source.len());
+        assert!(!run_source.contains("retry"));
+        assert!(run_source.contains("done()"));
+        let goroutine_source = functions[1].body.as_go().1;
+        assert!(goroutine_source.contains("retry()"));
+        assert!(!goroutine_source.contains("work(j)"));
+        assert!(functions[2].body.as_go().1.contains("work(j)"));
+    }
+
+    #[test]
+    fn test_go_parser_anon_naming() {
+        let source = r#"
+package main
+
+func Run(jobs []int) {
+    done := func() {}
+    defer func() { done() }()
+    for _, job := range jobs {
+        go func(j int) {
+            retry := func() { work(j) }
+            retry()
+        }(job)
+    }
+    s.handle(func() {}); s.handle(func() {})
+    opts := Options{OnError: func(err error) {}}
+    v := (func() int { return 1 })()
+    _ = func() {}
+    use(opts, v)
+}
+"#;
+        let names = |naming: AnonNaming| -> Vec<String> {
+            let parser = GoParser::new()
+                .unwrap()
+                .with_separate_closures(true)
+                .with_anon_naming(naming);
+            let module = parser.parse(source, "test.go").unwrap();
+            module
+                .discover_functions(0, source)
+                .into_iter()
+                .map(|f| f.name.unwrap())
+                .collect()
+        };
+
+        assert_eq!(
+            names(AnonNaming::Context),
+            vec![
+                "Run",
+                "Run.done",
+                "Run.defer",
+                "Run.go",
+                "Run.go.retry",
+                "Run.handle",
+                "Run.handle#2",
+                "Run.OnError",
+                "Run.v",
+                "Run.func",
+            ]
+        );
+        assert_eq!(
+            names(AnonNaming::Line),
+            vec![
+                "Run",
+                "Run.line5",
+                "Run.line6",
+                "Run.line8",
+                "Run.line8.line9",
+                "Run.line13",
+                "Run.line13#2",
+                "Run.line14",
+                "Run.line15",
+                "Run.line16",
+            ]
+        );
+        assert_eq!(names(AnonNaming::Index)[3..5], ["Run.func3", "Run.func3.1"]);
     }
 
     #[test]
@@ -0,0 +1,386 @@
+//! Haskell CFG builder implementation
+
+use crate::ast::FunctionNode;
+use crate::cfg::{Cfg, NodeId, NodeKind};
+use crate::language::cfg_builder::{CfgBuilder, CfgState};
+use crate::language::haskell::{
+    case_alternatives, case_scrutinee, conditional_parts, find_function, has_guards,
+    is_catch_all_alternative, is_catch_all_guard, is_closure, is_equation, is_exit,
+    is_nested_definition, monadic_conditional, right_hand_sides, where_bindings, Rhs,
+};
+use crate::language::tree_sitter_utils::with_cached_haskell_tree;
+use tree_sitter::Node;
+
+/// Haskell CFG builder
+pub struct HaskellCfgBuilder;
+
+impl CfgBuilder for HaskellCfgBuilder {
+    fn build(&self, function: &FunctionNode) -> Cfg {
+        let (_body_node_id, source) = function.body.as_haskell();
+
+        let result = with_cached_haskell_tree(source, |root| {
+            let equations = find_function(root, function.span.start, source)?;
+            let mut builder = HaskellCfgBuilderState {
+                flow: CfgState::new(),
+                source,
+            };
+            builder.visit_equations(&equations);
+            Some(builder.flow.finish())
+        });
+
+        result.unwrap_or_else(CfgState::straight_line)
+    }
+}
+
+struct HaskellCfgBuilderState<'s> {
+    flow: CfgState,
+    source: &'s str,
+}
+
+impl HaskellCfgBuilderState<'_> {
+    /// The equations of a function: one body, or a branch per equation chosen
+    /// by matching the arguments against each equation's patterns in turn
+    fn visit_equations(&mut self, equations: &[Node]) {
+        if let [equation] = equations {
+            self.visit_binding(equation);
+            return;
+        }
+        let Some(dispatch) = self.flow.add_after(NodeKind::Condition) else {
+            return;
+        };
+
+        let mut join_node = None;
+        for equation in equations {
+            self.flow.start_branch(dispatch);
+            self.visit_binding(equation);
+            self.flow.fall_through(&mut join_node);
+        }
+        self.flow.current_node = join_node;
+    }
+
+    /// An equation or `case` alternative: the values its `where` clause
+    /// binds, then its right-hand sides
+    fn visit_binding(&mut self, node: &Node) {
+        for binding in where_bindings(*node) {
+            if is_equation(binding) && !is_nested_definition(binding, self.source) {
+                self.visit_binding(&binding);
+            }
+        }
+        self.visit_right_hand_sides(&right_hand_sides(*node));
+    }
+
+    /// Right-hand sides: one expression, or a chain of guards tried in turn,
+    /// each a branch. A guard that always holds (`otherwise`) is the chain's
+    /// `else`; without one, the chain can fall past every guard.
+    fn visit_right_hand_sides(&mut self, sides: &[Rhs]) {
+        if !sides.iter().any(|side| side.guards.is_some()) {
+            for side in sides {
+                if let Some(body) = side.body {
+                    self.visit_expression(&body);
+                }
+            }
+            return;
+        }
+
+        let mut join_node = None;
+        for side in sides {
+            let Some(from_node) = self.flow.current_node else {
+                break;
+            };
+            match side.guards {
+                Some(guards) if !is_catch_all_guard(guards, self.source) => {
+                    // A `case` inside a guard runs before the guard decides
+                    self.visit_nested(&guards);
+                    let Some(condition_node) = self.flow.add_after(NodeKind::Condition) else {
+                        break;
+                    };
+                    self.flow.start_branch(condition_node);
+                    if let Some(body) = side.body {
+                        self.visit_expression(&body);
+                    }
+                    self.flow.fall_through(&mut join_node);
+                    self.flow.current_node = Some(condition_node);
+                }
+                _ => {
+                    self.flow.start_branch(from_node);
+                    if let Some(body) = side.body {
+                        self.visit_expression(&body);
+                    }
+                    self.flow.fall_through(&mut join_node);
+                    self.flow.current_node = None;
+                }
+            }
+        }
+        // No guard held: fall past the chain
+        self.flow.fall_through(&mut join_node);
+        self.flow.current_node = join_node;
+    }
+
+    fn visit_expression(&mut self, node: &Node) {
+        if node.kind() == "conditional" {
+            self.visit_if(node);
+        } else if node.kind() == "case" {
+            self.visit_case(node);
+        } else if node.kind() == "multi_way_if" {
+            self.visit_right_hand_sides(&right_hand_sides(*node));
+        } else if let Some((condition, action)) = monadic_conditional(*node, self.source) {
+            self.visit_when(&condition, &action);
+        } else if is_exit(*node, self.source) {
+            // `error (case ...)` branches before it raises
+            self.visit_nested(node);
+            self.flow.jump_to_exit();
+        } else if is_closure(*node, self.source) || is_nested_definition(*node, self.source) {
+            self.flow.statement();
+        } else if node.kind() == "bind" && is_equation(*node) && has_guards(*node) {
+            self.visit_binding(node);
+        } else if !self.visit_nested(node) {
+            self.flow.statement();
+        }
+    }
+
+    /// Whether `node` is an expression the CFG branches or exits at
+    fn is_control(&self, node: Node) -> bool {
+        matches!(node.kind(), "conditional" | "case" | "multi_way_if")
+            || monadic_conditional(node, self.source).is_some()
+            || is_exit(node, self.source)
+            || (node.kind() == "bind" && is_equation(node) && has_guards(node))
+    }
+
+    /// Visit the constructs inside `node`, in source order, so that
+    /// `label x = "n=" ++ case x of ...` branches. Lambdas, functions bound by
+    /// `let`, and nested definitions are not entered: their bodies run when
+    /// they are called. Returns whether there were any.
+    fn visit_nested(&mut self, node: &Node) -> bool {
+        let mut found = false;
+        let mut cursor = node.walk();
+        for child in node.named_children(&mut cursor) {
+            if self.is_control(child) {
+                self.visit_expression(&child);
+                found = true;
+            } else if !is_closure(child, self.source) && !is_nested_definition(child, self.source) {
+                found |= self.visit_nested(&child);
+            }
+        }
+        found
+    }
+
+    /// `if ... then ... else ...`
+    fn visit_if(&mut self, node: &Node) {
+        let (condition, consequence, alternative) = conditional_parts(*node);
+        if let Some(condition) = condition {
+            self.visit_nested(&condition);
+        }
+        let Some(condition_node) = self.flow.add_after(NodeKind::Condition) else {
+            return;
+        };
+
+        let mut join_node = None;
+        for branch in [consequence, alternative].into_iter().flatten() {
+            self.flow.start_branch(condition_node);
+            self.visit_expression(&branch);
+            self.flow.fall_through(&mut join_node);
+        }
+        if alternative.is_none() {
+            self.flow.skip_branches(condition_node, &mut join_node);
+        }
+
+        self.flow.current_node = join_node;
+    }
+
+    /// `case`: each alternative is a branch. Without a final catch-all
+    /// alternative (`_ ->`) no alternative may match, so the match node also
+    /// gets an edge to the join and every alternative adds one to CC.
+    fn visit_case(&mut self, node: &Node) {
+        if let Some(scrutinee) = case_scrutinee(*node) {
+            self.visit_nested(&scrutinee);
+        }
+        let Some(match_node) = self.flow.add_after(NodeKind::Condition) else {
+            return;
+        };
+
+        let mut join_node = None;
+        let alternatives = case_alternatives(*node);
+        for alternative in &alternatives {
+            self.flow.start_branch(match_node);
+            self.visit_binding(alternative);
+            self.flow.fall_through(&mut join_node);
+        }
+
+        let exhaustive = alternatives
+            .last()
+            .is_some_and(|last| is_catch_all_alternative(*last, self.source));
+        if !exhaustive {
+            self.flow.skip_branches(match_node, &mut join_node);
+        }
+        self.flow.current_node = join_node;
+    }
+
+    /// `when cond action` and `unless cond action`: the action runs or is
+    /// skipped
+    fn visit_when(&mut self, condition: &Node, action: &Node) {
+        self.visit_nested(condition);
+        let Some(condition_node) = self.flow.add_after(NodeKind::Condition) else {
+            return;
+        };
+
+        let mut join_node = None;
+        self.flow.start_branch(condition_node);
+        self.visit_expression(action);
+        self.flow.fall_through(&mut join_node);
+        self.flow.skip_branches(condition_node, &mut join_node);
+        self.flow.current_node = join_node;
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::language::parser::LanguageParser;
+    use crate::language::HaskellParser;
+
+    /// CC of the first function in `source`
+    fn cc(source: &str) -> usize {
+        let module = HaskellParser::new()
+            .unwrap()
+            .parse(source, "test.hs")
+            .unwrap();
+        let function = module
+            .discover_functions(0, source)
+            .into_iter()
+            .next()
+            .expect("No function found in test source");
+        let cfg = HaskellCfgBuilder.build(&function);
+        assert!(
+            cfg.validate().is_ok(),
+            "CFG must be valid: {:?}",
+            cfg.validate()
+        );
+        // CC = E - N + 2
+        (cfg.edge_count() as isize - cfg.node_count() as isize + 2).max(1) as usize
+    }
+
+    #[test]
+    fn test_straight_line() {
+        let source = "double :: Int -> Int\ndouble x = let y = x + x in y\n";
+        assert_eq!(cc(source), 1);
+    }
+
+    #[test]
+    fn test_if_then_else_and_else_if() {
+        let source = r#"sign :: Int -> String
+sign x = if x > 0 then "pos" else if x < 0 then "neg" else "zero"
+"#;
+        assert_eq!(cc(source), 3);
+    }
+
+    #[test]
+    fn test_guards_with_otherwise() {
+        let source = r#"classify :: Int -> String
+classify n
+  | n < 0 = "negative"
+  | n == 0 = "zero"
+  | otherwise = "positive"
+"#;
+        // Two guards; `otherwise` is the else
+        assert_eq!(cc(source), 3);
+    }
+
+    #[test]
+    fn test_guards_without_otherwise_fall_past() {
+        let source = r#"clamp :: Int -> Int
+clamp n
+  | n < 0 = 0
+  | n > 100 = 100
+clamp n = n
+"#;
+        // Two equations, two guards
+        assert_eq!(cc(source), 4);
+    }
+
+    #[test]
+    fn test_multi_equation_function() {
+        let source = r#"len :: [a] -> Int
+len [] = 0
+len [_] = 1
+len (_:xs) = 1 + len xs
+"#;
+        assert_eq!(cc(source), 3);
+    }
+
+    #[test]
+    fn test_case_with_and_without_catch_all() {
+        let with_default = r#"describe :: Maybe Int -> String
+describe m = case m of
+  Just 0 -> "zero"
+  Just n -> show n
+  _ -> "none"
+"#;
+        assert_eq!(cc(with_default), 3);
+
+        let without_default = r#"describe :: Maybe Int -> String
+describe m = case m of
+  Just n -> show n
+  Nothing -> "none"
+"#;
+        assert_eq!(cc(without_default), 3);
+    }
+
+    #[test]
+    fn test_guarded_case_alternative() {
+        let source = r#"describe :: Maybe Int -> String
+describe m = case m of
+  Just n
+    | n > 0 -> "positive"
+    | otherwise -> "other"
+  Nothing -> "none"
+"#;
+        // Two alternatives without a catch-all, one guard
+        assert_eq!(cc(source), 4);
+    }
+
+    #[test]
+    fn test_do_block_with_when() {
+        let source = r#"main :: IO ()
+main = do
+  line <- getLine
+  when (null line) $ putStrLn "empty"
+  unless (null line) exitFailure
+  putStrLn line
+"#;
+        assert_eq!(cc(source), 3);
+    }
+
+    #[test]
+    fn test_error_exits() {
+        let source = r#"safeDiv :: Int -> Int -> Int
+safeDiv _ 0 = error "divide by zero"
+safeDiv a b = a `div` b
+"#;
+        assert_eq!(cc(source), 2);
+    }
+
+    #[test]
+    fn test_where_values_are_visited() {
+        let source = r#"score :: Int -> Int
+score n = base + bonus
+  where
+    base = n * 2
+    bonus
+      | n > 10 = 5
+      | otherwise = 0
+"#;
+        assert_eq!(cc(source), 2);
+    }
+
+    #[test]
+    fn test_lambdas_and_where_functions_are_not_entered() {
+        let source = r#"positives :: [Int] -> [Int]
+positives = filter keep . map (\x -> if x > 0 then x else 0)
+  where
+    keep x
+      | x > 0 = True
+      | otherwise = False
+"#;
+        assert_eq!(cc(source), 1);
+    }
+}
@@ -0,0 +1,469 @@
+//! Haskell language support
+//!
+//! Parses Haskell source files (`.hs`) using tree-sitter-haskell; the
+//! equations of a multi-equation function are reported as one function.
+
+pub mod cfg_builder;
+pub mod parser;
+
+pub use cfg_builder::HaskellCfgBuilder;
+pub use parser::HaskellParser;
+
+use tree_sitter::Node;
+
+/// Node kinds of an equation: `function` has argument patterns, `bind` has
+/// none
+const EQUATION_KINDS: &[&str] = &["function", "bind"];
+
+/// Scopes whose bindings are discovered as functions: the top level of a
+/// module and the bodies of `class` and `instance` declarations
+const DECLARATION_SCOPES: &[&str] = &[
+    "haskell",
+    "declarations",
+    "class_declarations",
+    "instance_declarations",
+];
+
+/// Expressions that define a function value; their code runs when it is
+/// called
+const CLOSURE_KINDS: &[&str] = &["lambda", "lambda_case", "lambda_cases"];
+
+/// Functions that raise or end the program when applied, leaving the
+/// function early
+pub(crate) const EXIT_FUNCTIONS: &[&str] = &[
+    "error",
+    "errorWithoutStackTrace",
+    "throw",
+    "throwIO",
+    "ioError",
+    "exitWith",
+];
+
+/// Values that raise or end the program where they are evaluated
+pub(crate) const EXIT_VALUES: &[&str] = &["undefined", "exitFailure", "exitSuccess"];
+
+/// `Control.Monad` functions that run an action only when a condition holds
+pub(crate) const MONADIC_CONDITIONALS: &[&str] = &["when", "unless"];
+
+/// Guards that always hold, ending a guard chain like an `else`
+const CATCH_ALL_GUARDS: &[&str] = &["otherwise", "True"];
+
+/// Source text of `node`
+pub(crate) fn text<'a>(node: Node<'_>, source: &'a str) -> &'a str {
+    &source[node.start_byte()..node.end_byte()]
+}
+
+/// Whether `node` is a comment, a Haddock comment, or a pragma
+fn is_comment(node: Node<'_>) -> bool {
+    node.kind().contains("comment") || matches!(node.kind(), "haddock" | "pragma" | "cpp")
+}
+
+/// Named children of `node`, without comments
+fn code_children(node: Node<'_>) -> Vec<Node<'_>> {
+    let mut cursor = node.walk();
+    let children = node
+        .named_children(&mut cursor)
+        .filter(|child| !is_comment(*child))
+        .collect();
+    children
+}
+
+/// Whether `node` is an equation: a binding with `=` and, for a function,
+/// argument patterns. A `do` statement `x <- action` is a `bind` too, but
+/// not an equation.
+pub(crate) fn is_equation(node: Node<'_>) -> bool {
+    if !EQUATION_KINDS.contains(&node.kind()) {
+        return false;
+    }
+    let mut cursor = node.walk();
+    let is_equation = !node.children(&mut cursor).any(|child| child.kind() == "<-");
+    is_equation
+}
+
+/// The name an equation defines: `area` in `area (Circle r) = ...`, `<+>`
+/// in `(<+>) a b = ...` and `a <+> b = ...`, or None for a pattern binding
+/// (`(q, r) = divMod n d`)
+pub(crate) fn equation_name<'a>(equation: Node<'_>, source: &'a str) -> Option<&'a str> {
+    let name = equation.child_by_field_name("name").or_else(|| {
+        // An operator defined infix (`a <+> b = ...`, ``x `op` y = ...``)
+        equation
+            .child_by_field_name("infix")
+            .and_then(|infix| infix.child_by_field_name("operator"))
+    })?;
+    let name = text(name, source)
+        .trim()
+        .trim_start_matches('(')
+        .trim_end_matches(')')
+        .trim()
+        .trim_matches('`');
+    (!name.is_empty()).then_some(name)
+}
+
+/// Number of arguments an equation matches: its patterns, or the two
+/// operands of an operator defined infix
+pub(crate) fn equation_arity(equation: Node<'_>) -> usize {
+    if equation.child_by_field_name("infix").is_some() {
+        return 2;
+    }
+    equation
+        .child_by_field_name("patterns")
+        .map_or(0, |patterns| code_children(patterns).len())
+}
+
+/// Whether `node` is a `where` clause's bindings, as opposed to those of a
+/// `let`
+fn is_where_bindings(node: Node<'_>) -> bool {
+    node.kind() == "local_binds"
+        && (node
+            .prev_sibling()
+            .is_some_and(|prev| prev.kind() == "where")
+            || node
+                .parent()
+                .is_some_and(|parent| !matches!(parent.kind(), "let" | "let_in")))
+}
+
+/// Whether `node` is the first equation of a function that is discovered on
+/// its own or one of its later equations: a named binding at the top level
+/// or in a `class` or `instance` body, or a binding with arguments in a
+/// `where` clause
+pub(crate) fn is_definition(node: Node<'_>, source: &str) -> bool {
+    if !is_equation(node) || equation_name(node, source).is_none() {
+        return false;
+    }
+    node.parent().is_some_and(|scope| {
+        DECLARATION_SCOPES.contains(&scope.kind())
+            || (is_where_bindings(scope) && node.kind() == "function")
+    })
+}
+
+/// Functions defined inside a function (in its `where` clause), which are
+/// discovered on their own
+pub(crate) fn is_nested_definition(node: Node<'_>, source: &str) -> bool {
+    is_definition(node, source)
+}
+
+/// Whether `node` is a function bound by `let`, which is part of the
+/// function that binds it
+pub(crate) fn is_local_function(node: Node<'_>, source: &str) -> bool {
+    node.kind() == "function" && is_equation(node) && !is_definition(node, source)
+}
+
+/// Whether `node` defines a function value its enclosing function does not
+/// run itself: a lambda, `\case`, or a function bound by `let`
+pub(crate) fn is_closure(node: Node<'_>, source: &str) -> bool {
+    CLOSURE_KINDS.contains(&node.kind()) || is_local_function(node, source)
+}
+
+/// The equation after `equation` if it continues the same function
+fn next_equation<'a>(equation: Node<'a>, source: &str) -> Option<Node<'a>> {
+    let mut sibling = equation.next_named_sibling();
+    while let Some(node) = sibling.filter(|node| is_comment(*node)) {
+        sibling = node.next_named_sibling();
+    }
+    let next = sibling?;
+    (is_equation(next)
+        && equation_name(next, source).is_some()
+        && equation_name(next, source) == equation_name(equation, source))
+    .then_some(next)
+}
+
+/// Whether an equation is the first equation of its function
+pub(crate) fn is_first_equation(equation: Node<'_>, source: &str) -> bool {
+    let mut sibling = equation.prev_named_sibling();
+    while let Some(node) = sibling.filter(|node| is_comment(*node)) {
+        sibling = node.prev_named_sibling();
+    }
+    !sibling.is_some_and(|prev| is_equation(prev) && next_equation(prev, source) == Some(equation))
+}
+
+/// Every equation of the function whose first equation is `first`
+pub(crate) fn function_equations<'a>(first: Node<'a>, source: &str) -> Vec<Node<'a>> {
+    let mut equations = vec![first];
+    let mut equation = first;
+    while let Some(next) = next_equation(equation, source) {
+        equations.push(next);
+        equation = next;
+    }
+    equations
+}
+
+/// The equations of the function whose first equation starts at
+/// `start_byte`
+pub(crate) fn find_function<'a>(
+    root: Node<'a>,
+    start_byte: usize,
+    source: &str,
+) -> Option<Vec<Node<'a>>> {
+    if root.start_byte() > start_byte || root.end_byte() <= start_byte {
+        return None;
+    }
+    if root.start_byte() == start_byte && is_definition(root, source) {
+        return Some(function_equations(root, source));
+    }
+    let mut cursor = root.walk();
+    for child in root.children(&mut cursor) {
+        if let Some(found) = find_function(child, start_byte, source) {
+            return Some(found);
+        }
+    }
+    None
+}
+
+/// One right-hand side of an equation, `case` alternative, or multi-way
+/// `if`: an optional guard and the expression it chooses
+#[derive(Clone, Copy)]
+pub(crate) struct Rhs<'a> {
+    pub guards: Option<Node<'a>>,
+    pub body: Option<Node<'a>>,
+}
+
+/// The right-hand sides of an equation (`= x`, or one per guard), a `case`
+/// alternative (`-> x`), or a multi-way `if`, in order
+pub(crate) fn right_hand_sides(node: Node<'_>) -> Vec<Rhs<'_>> {
+    let mut sides = Vec::new();
+    let mut after_arrow = false;
+    let mut cursor = node.walk();
+    for child in node.children(&mut cursor) {
+        match child.kind() {
+            "match" => {
+                let guards = code_children(child)
+                    .into_iter()
+                    .find(|part| part.kind() == "guards");
+                let body = child.child_by_field_name("expression").or_else(|| {
+                    code_children(child)
+                        .into_iter()
+                        .filter(|part| part.kind() != "guards")
+                        .last()
+                });
+                sides.push(Rhs { guards, body });
+            }
+            "=" | "->" => after_arrow = true,
+            "where" => after_arrow = false,
+            _ if after_arrow && child.is_named() && !is_comment(child) => {
+                sides.push(Rhs {
+                    guards: None,
+                    body: Some(child),
+                });
+                after_arrow = false;
+            }
+            _ => {}
+        }
+    }
+    sides
+}
+
+/// The conditions of a guard: one, or several separated by commas
+/// (`| x > 0, even x`), each a boolean, a pattern guard (`Just y <- m`), or
+/// a `let`
+pub(crate) fn guard_conditions(guards: Node<'_>) -> Vec<Node<'_>> {
+    code_children(guards)
+}
+
+/// Whether a guard always holds (`| otherwise`, `| True`)
+pub(crate) fn is_catch_all_guard(guards: Node<'_>, source: &str) -> bool {
+    matches!(
+        guard_conditions(guards).as_slice(),
+        [only] if CATCH_ALL_GUARDS.contains(&text(*only, source).trim())
+    )
+}
+
+/// Whether some right-hand side of `node` is guarded
+pub(crate) fn has_guards(node: Node<'_>) -> bool {
+    right_hand_sides(node)
+        .iter()
+        .any(|side| side.guards.is_some())
+}
+
+/// Whether right-hand sides always choose one of their expressions: there
+/// are no guards, or the last guard always holds
+pub(crate) fn is_exhaustive(sides: &[Rhs], source: &str) -> bool {
+    sides.last().map_or(true, |last| {
+        last.guards.map_or(true, |g| is_catch_all_guard(g, source))
+    })
+}
+
+/// Bindings of the `where` clause of an equation or `case` alternative
+pub(crate) fn where_bindings(node: Node<'_>) -> Vec<Node<'_>> {
+    let mut cursor = node.walk();
+    let scopes: Vec<Node> = node
+        .children(&mut cursor)
+        .flat_map(|child| {
+            if child.kind() == "match" {
+                code_children(child)
+            } else {
+                vec![child]
+            }
+        })
+        .filter(|child| is_where_bindings(*child))
+        .collect();
+    scopes.into_iter().flat_map(code_children).collect()
+}
+
+/// The condition, `then` branch, and `else` branch of an `if`
+pub(crate) fn conditional_parts(
+    node: Node<'_>,
+) -> (Option<Node<'_>>, Option<Node<'_>>, Option<Node<'_>>) {
+    let parts = code_children(node);
+    let field = |name: &str, index: usize| {
+        node.child_by_field_name(name)
+            .or_else(|| parts.get(index).copied())
+    };
+    (field("if", 0), field("then", 1), field("else", 2))
+}
+
+/// Whether `node` is an `if` that is the `else` branch of another `if`: an
+/// `else if`, which continues its chain rather than nesting in it
+pub(crate) fn is_else_if(node: Node<'_>) -> bool {
+    node.kind() == "conditional"
+        && node
+            .parent()
+            .filter(|parent| parent.kind() == "conditional")
+            .and_then(|parent| conditional_parts(parent).2)
+            .is_some_and(|otherwise| otherwise.id() == node.id())
+}
+
+/// Whether `node` chooses among alternatives: `case`, `\case`, or
+/// `\cases`
+pub(crate) fn is_case(node: Node<'_>) -> bool {
+    matches!(node.kind(), "case" | "lambda_case" | "lambda_cases")
+}
+
+/// The expression a `case` matches on
+pub(crate) fn case_scrutinee(node: Node<'_>) -> Option<Node<'_>> {
+    if node.kind() != "case" {
+        return None;
+    }
+    code_children(node)
+        .into_iter()
+        .find(|child| child.kind() != "alternatives")
+}
+
+/// The alternatives of a `case`, `\case`, or `\cases`
+pub(crate) fn case_alternatives(node: Node<'_>) -> Vec<Node<'_>> {
+    code_children(node)
+        .into_iter()
+        .flat_map(|child| match child.kind() {
+            "alternatives" => code_children(child),
+            "alternative" => vec![child],
+            _ => Vec::new(),
+        })
+        .filter(|child| child.kind() == "alternative")
+        .collect()
+}
+
+/// Whether a `case` alternative matches every value that reaches it: an
+/// irrefutable pattern (`_`, `x`) without guards, or with a last guard that
+/// always holds
+pub(crate) fn is_catch_all_alternative(alternative: Node<'_>, source: &str) -> bool {
+    let pattern = alternative
+        .child_by_field_name("pattern")
+        .or_else(|| code_children(alternative).into_iter().next());
+    pattern.is_some_and(|p| matches!(p.kind(), "wildcard" | "variable") || text(p, source) == "_")
+        && is_exhaustive(&right_hand_sides(alternative), source)
+}
+
+/// The operator of an infix expression (`&&`, `$`, `` `elem` ``)
+pub(crate) fn infix_operator<'a>(node: Node<'_>, source: &'a str) -> Option<&'a str> {
+    if node.kind() != "infix" {
+        return None;
+    }
+    let operator = node.child_by_field_name("operator")?;
+    Some(text(operator, source).trim())
+}
+
+/// The operands of an infix expression
+fn infix_operands(node: Node<'_>) -> (Option<Node<'_>>, Option<Node<'_>>) {
+    (
+        node.child_by_field_name("left_operand"),
+        node.child_by_field_name("right_operand"),
+    )
+}
+
+/// The function and argument of an application: `f x`, or `f $ x`
+pub(crate) fn application(node: Node<'_>, source: &str) -> Option<(Node<'_>, Node<'_>)> {
+    match node.kind() {
+        "apply" => Some((
+            node.child_by_field_name("function")?,
+            node.child_by_field_name("argument")?,
+        )),
+        _ if matches!(infix_operator(node, source), Some("$" | "$!")) => {
+            match infix_operands(node) {
+                (Some(function), Some(argument)) => Some((function, argument)),
+                _ => None,
+            }
+        }
+        _ => None,
+    }
+}
+
+/// The name of a variable, qualified (`Map.lookup`) or not; None for any
+/// other expression
+pub(crate) fn variable_name<'a>(node: Node<'_>, source: &'a str) -> Option<&'a str> {
+    matches!(node.kind(), "variable" | "qualified").then(|| text(node, source).trim())
+}
+
+/// The last component of a name: `lookup` in `Map.lookup`
+pub(crate) fn unqualified(name: &str) -> &str {
+    name.rsplit('.').next().unwrap_or(name)
+}
+
+/// The condition and action of `when cond action` / `unless cond $ do ...`
+pub(crate) fn monadic_conditional<'a>(
+    node: Node<'a>,
+    source: &str,
+) -> Option<(Node<'a>, Node<'a>)> {
+    let (function, action) = application(node, source)?;
+    if function.kind() != "apply" {
+        return None;
+    }
+    let (name, condition) = application(function, source)?;
+    variable_name(name, source)
+        .is_some_and(|name| MONADIC_CONDITIONALS.contains(&unqualified(name)))
+        .then_some((condition, action))
+}
+
+/// Whether `node` is the function applied by its parent application
+fn is_applied(node: Node<'_>, source: &str) -> bool {
+    node.parent()
+        .and_then(|parent| application(parent, source))
+        .is_some_and(|(function, _)| function.id() == node.id())
+}
+
+/// Whether `node` raises or ends the program: `error "..."`, `throwIO e`,
+/// `exitWith code`, or `undefined` / `exitFailure`
+pub(crate) fn is_exit(node: Node<'_>, source: &str) -> bool {
+    let (head, exits) = match application(node, source) {
+        Some((function, _)) => (function, EXIT_FUNCTIONS),
+        None if is_applied(node, source) => return false,
+        None => (node, EXIT_VALUES),
+    };
+    variable_name(head, source).is_some_and(|name| exits.contains(&unqualified(name)))
+}
+
+/// The statements of a `do` block, or the expression itself for anything
+/// else
+pub(crate) fn do_statements(node: Node<'_>) -> Vec<Node<'_>> {
+    let node = unwrap_parens(node);
+    if node.kind() != "do" {
+        return vec![node];
+    }
+    code_children(node)
+        .into_iter()
+        .map(|statement| match statement.kind() {
+            "exp" => statement.named_child(0).unwrap_or(statement),
+            _ => statement,
+        })
+        .collect()
+}
+
+/// The expression inside parentheses
+pub(crate) fn unwrap_parens(node: Node<'_>) -> Node<'_> {
+    let mut node = node;
+    while node.kind() == "parens" {
+        match code_children(node).as_slice() {
+            [inner] => node = *inner,
+            _ => break,
+        }
+    }
+    node
+}
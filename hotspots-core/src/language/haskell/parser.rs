@@ -0,0 +1,436 @@
+//! Haskell language parser using tree-sitter
+
+use crate::ast::FunctionNode;
+use crate::language::haskell::{
+    equation_name, function_equations, is_definition, is_first_equation, text,
+};
+use crate::language::parser::{LanguageParser, ParsedModule};
+use crate::language::tree_sitter_utils::syntax_errors;
+use anyhow::{Context, Result};
+use std::collections::HashSet;
+use tree_sitter::{Node, Parser, Tree};
+
+/// Haskell parser using tree-sitter
+pub struct HaskellParser;
+
+impl HaskellParser {
+    /// Create a new Haskell parser
+    pub fn new() -> Result<Self> {
+        let mut parser = Parser::new();
+        let language = tree_sitter_haskell::LANGUAGE;
+        parser
+            .set_language(&language.into())
+            .context("Failed to set Haskell language for parser")?;
+        Ok(HaskellParser)
+    }
+}
+
+impl Default for HaskellParser {
+    fn default() -> Self {
+        Self::new().expect("Failed to create Haskell parser")
+    }
+}
+
+impl LanguageParser for HaskellParser {
+    fn parse(&self, source: &str, filename: &str) -> Result<Box<dyn ParsedModule>> {
+        let mut parser = Parser::new();
+        let language = tree_sitter_haskell::LANGUAGE;
+        parser
+            .set_language(&language.into())
+            .context("Failed to set Haskell language")?;
+
+        let tree = parser
+            .parse(source, None)
+            .ok_or_else(|| anyhow::anyhow!("Failed to parse Haskell file: {}", filename))?;
+
+        Ok(Box::new(HaskellModule {
+            tree,
+            source: source.to_string(),
+        }))
+    }
+}
+
+/// Parsed Haskell module
+struct HaskellModule {
+    tree: Tree,
+    source: String,
+}
+
+impl ParsedModule for HaskellModule {
+    fn discover_functions(&self, file_index: usize, _source: &str) -> Vec<FunctionNode> {
+        let root = self.tree.root_node();
+        let exports = exported_names(root, &self.source);
+        let mut functions = Vec::new();
+        discover_functions_recursive(
+            root,
+            &self.source,
+            file_index,
+            exports.as_ref(),
+            &mut functions,
+        );
+        functions.sort_by_key(|f| f.span.start);
+        functions
+    }
+
+    fn syntax_errors(&self) -> Vec<std::ops::Range<usize>> {
+        syntax_errors(self.tree.root_node())
+    }
+}
+
+/// Recursively discover functions in the Haskell AST: top-level bindings,
+/// `class` and `instance` methods, and functions in `where` clauses, however
+/// deep. Each function is found at its first equation.
+fn discover_functions_recursive(
+    node: Node,
+    source: &str,
+    file_index: usize,
+    exports: Option<&HashSet<String>>,
+    functions: &mut Vec<FunctionNode>,
+) {
+    if is_definition(node, source) && is_first_equation(node, source) {
+        if let Some(function_node) =
+            extract_function(node, source, file_index, exports, functions.len())
+        {
+            functions.push(function_node);
+        }
+    }
+
+    let mut cursor = node.walk();
+    for child in node.children(&mut cursor) {
+        discover_functions_recursive(child, source, file_index, exports, functions);
+    }
+}
+
+/// Extract a FunctionNode from the first equation of a function, spanning
+/// all of its equations
+fn extract_function(
+    node: Node,
+    source: &str,
+    file_index: usize,
+    exports: Option<&HashSet<String>>,
+    local_index: usize,
+) -> Option<FunctionNode> {
+    use crate::ast::FunctionId;
+    use crate::language::{FunctionBody, SourceSpan};
+
+    let name = equation_name(node, source)?;
+    let equations = function_equations(node, source);
+    let last = equations.last().copied().unwrap_or(node);
+
+    let span = SourceSpan::new(
+        node.start_byte(),
+        last.end_byte(),
+        node.start_position().row as u32 + 1, // tree-sitter uses 0-indexed rows
+        last.end_position().row as u32 + 1,   // tree-sitter uses 0-indexed rows
+        SourceSpan::column_of(source, node.start_byte()),
+    );
+
+    let body = FunctionBody::Haskell {
+        body_node: node.id(),
+        source: source.to_string(),
+    };
+
+    let scope = Scope::of(node, source);
+    let is_public = match &scope {
+        Scope::Module => exports.map_or(true, |names| names.contains(name)),
+        Scope::Class(_) | Scope::Instance(_) => true,
+        Scope::Where => false,
+    };
+
+    Some(FunctionNode {
+        id: FunctionId {
+            file_index,
+            local_index,
+        },
+        name: Some(name.to_string()),
+        owner: scope.owner(),
+        span,
+        body,
+        suppression_reason: None, // Will be extracted separately
+        signature_complexity: 0,
+        params: crate::params::haskell_params(node),
+        is_public,
+        is_async: false,
+    })
+}
+
+/// Where a function is defined
+enum Scope {
+    /// The top level of the module
+    Module,
+    /// A `class` declaration, by class name
+    Class(Option<String>),
+    /// An `instance` declaration, by instance type
+    Instance(Option<String>),
+    /// The `where` clause of another function
+    Where,
+}
+
+impl Scope {
+    fn of(node: Node, source: &str) -> Scope {
+        let Some(parent) = node.parent() else {
+            return Scope::Module;
+        };
+        match parent.kind() {
+            "class_declarations" => Scope::Class(
+                parent
+                    .parent()
+                    .and_then(|class| declaration_head(class, source).0),
+            ),
+            "instance_declarations" => Scope::Instance(
+                parent
+                    .parent()
+                    .and_then(|instance| declaration_head(instance, source).1),
+            ),
+            "local_binds" => Scope::Where,
+            _ => Scope::Module,
+        }
+    }
+
+    /// The class a default method belongs to, or the type an instance method
+    /// is for
+    fn owner(self) -> Option<String> {
+        match self {
+            Scope::Class(name) | Scope::Instance(name) => name,
+            Scope::Module | Scope::Where => None,
+        }
+    }
+}
+
+/// The class name and type of a `class` or `instance` head, between its
+/// keyword and `where`, without a context: `("Show", "Tree a")` for
+/// `instance Show a => Show (Tree a) where`
+fn declaration_head(node: Node, source: &str) -> (Option<String>, Option<String>) {
+    let mut cursor = node.walk();
+    let where_start = node
+        .children(&mut cursor)
+        .find(|child| child.kind() == "where" || child.kind().ends_with("_declarations"))
+        .map_or(node.end_byte(), |keyword| keyword.start_byte());
+    let head = source[node.start_byte()..where_start]
+        .trim_start()
+        .trim_start_matches("class")
+        .trim_start_matches("instance");
+    let head = head.rsplit("=>").next().unwrap_or(head).trim();
+    let (class, rest) = match head.split_once(char::is_whitespace) {
+        Some((class, rest)) => (class, rest.trim()),
+        None => (head, ""),
+    };
+    let instance_type = rest
+        .strip_prefix('(')
+        .and_then(|inner| inner.strip_suffix(')'))
+        .unwrap_or(rest)
+        .trim();
+    let non_empty = |s: &str| (!s.is_empty()).then(|| s.to_string());
+    (non_empty(class), non_empty(instance_type))
+}
+
+/// Names in the module's export list (`module Shapes (Shape (..), area)
+/// where`), or None when there is no list and every top-level binding is
+/// exported
+fn exported_names(root: Node, source: &str) -> Option<HashSet<String>> {
+    fn find_exports(node: Node, depth: usize) -> Option<Node> {
+        if node.kind() == "exports" {
+            return Some(node);
+        }
+        if depth == 0 {
+            return None;
+        }
+        let mut cursor = node.walk();
+        let children: Vec<Node> = node.named_children(&mut cursor).collect();
+        children
+            .into_iter()
+            .filter(|child| matches!(child.kind(), "header" | "exports"))
+            .find_map(|child| find_exports(child, depth - 1))
+    }
+
+    fn collect(node: Node, source: &str, names: &mut HashSet<String>) {
+        if matches!(node.kind(), "variable" | "operator") {
+            names.insert(text(node, source).trim().to_string());
+            return;
+        }
+        let mut cursor = node.walk();
+        for child in node.named_children(&mut cursor) {
+            collect(child, source, names);
+        }
+    }
+
+    let exports = find_exports(root, 2)?;
+    let mut names = HashSet::new();
+    collect(exports, source, &mut names);
+    Some(names)
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    fn discover(source: &str) -> Vec<FunctionNode> {
+        let parser = HaskellParser::new().unwrap();
+        let module = parser.parse(source, "test.hs").unwrap();
+        module.discover_functions(0, source)
+    }
+
+    fn names(functions: &[FunctionNode]) -> Vec<&str> {
+        functions
+            .iter()
+            .map(|f| f.name.as_deref().unwrap_or(""))
+            .collect()
+    }
+
+    #[test]
+    fn test_create_parser() {
+        assert!(HaskellParser::new().is_ok());
+    }
+
+    #[test]
+    fn test_parse_top_level_bindings() {
+        let functions = discover(
+            r#"module Main where
+
+-- | Sum of squares
+sumSquares :: [Int] -> Int
+sumSquares xs = sum (map square xs)
+
+square :: Int -> Int
+square x = x * x
+
+main :: IO ()
+main = print (sumSquares [1, 2, 3])
+"#,
+        );
+        assert_eq!(names(&functions), vec!["sumSquares", "square", "main"]);
+        // The signature is not part of the function
+        assert_eq!(functions[0].span.start_line, 5);
+        assert_eq!(functions[0].span.end_line, 5);
+        assert_eq!(
+            functions.iter().map(|f| f.params).collect::<Vec<_>>(),
+            vec![1, 1, 0]
+        );
+    }
+
+    #[test]
+    fn test_parse_multi_equation_function_is_one_function() {
+        let functions = discover(
+            r#"fib :: Int -> Integer
+fib 0 = 0
+fib 1 = 1
+-- the general case
+fib n
+  | n < 0 = error "negative"
+  | otherwise = fib (n - 1) + fib (n - 2)
+
+size :: [a] -> Int
+size [] = 0
+size (_:xs) = 1 + size xs
+"#,
+        );
+        assert_eq!(names(&functions), vec!["fib", "size"]);
+        assert_eq!(functions[0].span.start_line, 2);
+        assert_eq!(functions[0].span.end_line, 7);
+        assert_eq!(functions[0].params, 1);
+        assert_eq!(functions[1].span.start_line, 10);
+        assert_eq!(functions[1].span.end_line, 11);
+    }
+
+    #[test]
+    fn test_parse_where_functions() {
+        let functions = discover(
+            r#"mean :: [Double] -> Double
+mean xs = total / count
+  where
+    total = sum xs
+    count = fromIntegral (len xs)
+    len [] = 0
+    len (_:ys) = 1 + len ys
+
+reverse' :: [a] -> [a]
+reverse' = go []
+  where go acc [] = acc
+        go acc (y:ys) = go (y : acc) ys
+"#,
+        );
+        // `total` and `count` are values of `mean`; `len` and `go` take
+        // arguments
+        assert_eq!(names(&functions), vec!["mean", "len", "reverse'", "go"]);
+        let public: Vec<bool> = functions.iter().map(|f| f.is_public).collect();
+        assert_eq!(public, vec![true, false, true, false]);
+        assert_eq!(functions[1].span.start_line, 6);
+        assert_eq!(functions[1].span.end_line, 7);
+    }
+
+    #[test]
+    fn test_parse_let_functions_are_not_functions() {
+        let functions = discover(
+            r#"process :: [Int] -> [Int]
+process xs =
+  let double x = x * 2
+      keep x = x > 0
+  in map double (filter keep xs)
+"#,
+        );
+        assert_eq!(names(&functions), vec!["process"]);
+    }
+
+    #[test]
+    fn test_parse_export_list_visibility() {
+        let functions = discover(
+            r#"module Shapes (Shape (..), area, (<+>)) where
+
+data Shape = Circle Double | Square Double
+
+area :: Shape -> Double
+area (Circle r) = pi * r * r
+area (Square s) = s * s
+
+perimeter :: Shape -> Double
+perimeter (Circle r) = 2 * pi * r
+perimeter (Square s) = 4 * s
+
+(<+>) :: Shape -> Shape -> Double
+a <+> b = area a + area b
+"#,
+        );
+        let public: Vec<(&str, bool)> = functions
+            .iter()
+            .map(|f| (f.name.as_deref().unwrap(), f.is_public))
+            .collect();
+        assert_eq!(
+            public,
+            vec![("area", true), ("perimeter", false), ("<+>", true)]
+        );
+        assert_eq!(functions[2].params, 2);
+    }
+
+    #[test]
+    fn test_parse_class_and_instance_methods() {
+        let functions = discover(
+            r#"class Describe a where
+  describe :: a -> String
+  describe _ = "thing"
+
+data Tree a = Leaf | Node (Tree a) a (Tree a)
+
+instance Show a => Describe (Tree a) where
+  describe Leaf = "leaf"
+  describe (Node _ x _) = "node " ++ show x
+"#,
+        );
+        let owners: Vec<(&str, Option<&str>, bool)> = functions
+            .iter()
+            .map(|f| (f.name.as_deref().unwrap(), f.owner.as_deref(), f.is_public))
+            .collect();
+        assert_eq!(
+            owners,
+            vec![
+                ("describe", Some("Describe"), true),
+                ("describe", Some("Tree a"), true),
+            ]
+        );
+    }
+
+    #[test]
+    fn test_parse_empty_file() {
+        assert!(discover("").is_empty());
+        assert!(discover("module Empty where\n\nimport Data.List\n").is_empty());
+    }
+}
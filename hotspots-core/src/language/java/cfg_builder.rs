@@ -311,6 +311,7 @@ impl JavaCfgBuilderState {
         // Create switch node (decision point)
         let switch_node = self.cfg.add_node(NodeKind::Condition);
         self.cfg.add_edge(current, switch_node);
+        self.cfg.switches.insert(node.start_byte(), switch_node);
 
         // Join point after switch
         let join = self.cfg.add_node(NodeKind::Statement);
@@ -542,12 +543,17 @@ mod tests {
                 local_index: 0,
             },
             name: Some("test".to_string()),
+            owner: None,
             span: SourceSpan::new(start_byte, end_byte, 1, 1, 0),
             body: FunctionBody::Java {
                 body_node: 0,
                 source: source.to_string(),
             },
             suppression_reason: None,
+            signature_complexity: 0,
+            params: 0,
+            is_public: false,
+            is_async: false,
         }
     }
 
@@ -2,7 +2,7 @@
 
 use crate::ast::FunctionNode;
 use crate::language::parser::{LanguageParser, ParsedModule};
-use crate::language::tree_sitter_utils::find_child_by_kind;
+use crate::language::tree_sitter_utils::{find_child_by_kind, syntax_errors};
 use anyhow::{Context, Result};
 use tree_sitter::{Node, Parser, Tree};
 
@@ -68,6 +68,10 @@ impl ParsedModule for JavaModule {
 
         functions
     }
+
+    fn syntax_errors(&self) -> Vec<std::ops::Range<usize>> {
+        syntax_errors(self.tree.root_node())
+    }
 }
 
 /// Recursively discover function declarations in the Java AST
@@ -106,6 +110,8 @@ fn extract_function(
 
     // Get function/constructor name
     let name = extract_function_name(node, source);
+    let owner = enclosing_type_name(node, source);
+    let is_public = is_public_member(node);
 
     // Get function body (block node or constructor_body)
     // Constructors use "constructor_body", methods use "block"
@@ -118,7 +124,7 @@ fn extract_function(
         node.end_byte(),
         node.start_position().row as u32 + 1, // tree-sitter uses 0-indexed rows
         node.end_position().row as u32 + 1,   // tree-sitter uses 0-indexed rows
-        node.start_position().column as u32,
+        SourceSpan::column_of(source, node.start_byte()),
     );
 
     // Create FunctionBody::Java variant
@@ -133,12 +139,59 @@ fn extract_function(
             local_index,
         },
         name,
+        owner,
         span,
         body,
         suppression_reason: None, // Will be extracted separately
+        signature_complexity: 0,
+        params: crate::params::java_params(node),
+        is_public,
+        is_async: false,
     })
 }
 
+/// A method or constructor is public API when declared `public`, or when it
+/// is an interface member without `private` (interface members are
+/// implicitly public)
+fn is_public_member(node: Node) -> bool {
+    let has_modifier = |keyword: &str| {
+        find_child_by_kind(node, "modifiers").is_some_and(|modifiers| {
+            let mut cursor = modifiers.walk();
+            let found = modifiers
+                .children(&mut cursor)
+                .any(|child| child.kind() == keyword);
+            found
+        })
+    };
+    if has_modifier("public") {
+        return true;
+    }
+    let in_interface = node
+        .parent()
+        .is_some_and(|parent| parent.kind() == "interface_body");
+    in_interface && !has_modifier("private")
+}
+
+/// The class, interface, enum, or record a method or constructor is declared
+/// in, inner types included; None in an anonymous class
+fn enclosing_type_name(node: Node, source: &str) -> Option<String> {
+    let mut current = node.parent();
+    while let Some(ancestor) = current {
+        match ancestor.kind() {
+            "class_declaration"
+            | "interface_declaration"
+            | "enum_declaration"
+            | "record_declaration" => {
+                let name = ancestor.child_by_field_name("name")?;
+                return Some(source[name.start_byte()..name.end_byte()].to_string());
+            }
+            "object_creation_expression" => return None,
+            _ => current = ancestor.parent(),
+        }
+    }
+    None
+}
+
 /// Extract function name from a method_declaration or constructor_declaration node
 fn extract_function_name(node: Node, source: &str) -> Option<String> {
     // Java method declarations have an "identifier" child for the method name
@@ -239,6 +292,32 @@ public class Outer {
         assert_eq!(functions.len(), 2);
         assert_eq!(functions[0].name, Some("outerMethod".to_string()));
         assert_eq!(functions[1].name, Some("innerMethod".to_string()));
+        assert_eq!(functions[0].owner, Some("Outer".to_string()));
+        assert_eq!(functions[1].owner, Some("Inner".to_string()));
+    }
+
+    #[test]
+    fn test_parse_anonymous_class_methods_have_no_owner() {
+        let parser = JavaParser::new().unwrap();
+        let source = r#"
+public class Outer {
+    public Runnable task() {
+        return new Runnable() {
+            public void run() {
+                return;
+            }
+        };
+    }
+}
+"#;
+        let module = parser.parse(source, "test.java");
+        assert!(module.is_ok());
+
+        let functions = module.unwrap().discover_functions(0, source);
+        assert_eq!(functions.len(), 2);
+        assert_eq!(functions[0].owner, Some("Outer".to_string()));
+        assert_eq!(functions[1].name, Some("run".to_string()));
+        assert_eq!(functions[1].owner, None);
     }
 
     #[test]
@@ -262,6 +341,7 @@ public interface MyInterface {
         assert_eq!(functions.len(), 2);
         assert_eq!(functions[0].name, Some("defaultMethod".to_string()));
         assert_eq!(functions[1].name, Some("staticMethod".to_string()));
+        assert_eq!(functions[0].owner, Some("MyInterface".to_string()));
     }
 
     #[test]
@@ -0,0 +1,233 @@
+//! Lua CFG builder implementation
+//!
+//! `goto` is a plain statement, since its target is not resolved.
+
+use crate::ast::FunctionNode;
+use crate::cfg::{Cfg, NodeId, NodeKind};
+use crate::language::cfg_builder::{CfgBuilder, CfgState};
+use crate::language::lua::{
+    alternative_statements, block_statements, find_function, if_alternatives, is_error_call,
+};
+use crate::language::tree_sitter_utils::with_cached_lua_tree;
+use tree_sitter::Node;
+
+/// Lua CFG builder
+pub struct LuaCfgBuilder;
+
+impl CfgBuilder for LuaCfgBuilder {
+    fn build(&self, function: &FunctionNode) -> Cfg {
+        let (_body_node_id, source) = function.body.as_lua();
+
+        let result = with_cached_lua_tree(source, |root| {
+            let func_node = find_function(root, function.span.start)?;
+            let mut builder = LuaCfgBuilderState {
+                flow: CfgState::new(),
+                source,
+            };
+            builder.visit_statements(&block_statements(func_node.child_by_field_name("body")));
+            Some(builder.flow.finish())
+        });
+
+        result.unwrap_or_else(CfgState::straight_line)
+    }
+}
+
+struct LuaCfgBuilderState<'s> {
+    flow: CfgState,
+    source: &'s str,
+}
+
+impl LuaCfgBuilderState<'_> {
+    fn visit_statements(&mut self, statements: &[Node]) {
+        for stmt in statements {
+            self.visit_node(stmt);
+        }
+    }
+
+    fn visit_node(&mut self, node: &Node) {
+        match node.kind() {
+            "if_statement" => self.visit_if(node),
+            "for_statement" | "while_statement" => self.visit_loop(node),
+            "repeat_statement" => self.visit_repeat(node),
+            "do_statement" => {
+                self.visit_statements(&block_statements(node.child_by_field_name("body")))
+            }
+            "return_statement" => self.flow.jump_to_exit(),
+            "break_statement" => self.flow.jump_to_break(),
+            "function_call" if is_error_call(*node, self.source) => self.flow.jump_to_exit(),
+            // Function definitions are discovered on their own
+            _ => self.flow.statement(),
+        }
+    }
+
+    fn visit_branch(&mut self, from: NodeId, statements: &[Node], join: &mut Option<NodeId>) {
+        self.flow.start_branch(from);
+        self.visit_statements(statements);
+        self.flow.fall_through(join);
+    }
+
+    /// `if` / `elseif` / `else`: each `elseif` is a further condition tested
+    /// when the previous one is false
+    fn visit_if(&mut self, node: &Node) {
+        let Some(mut condition_node) = self.flow.add_after(NodeKind::Condition) else {
+            return;
+        };
+
+        let mut join_node = None;
+        let consequence = block_statements(node.child_by_field_name("consequence"));
+        self.visit_branch(condition_node, &consequence, &mut join_node);
+
+        let mut has_else = false;
+        for clause in if_alternatives(*node) {
+            if clause.kind() == "elseif_statement" {
+                let next_condition = self.flow.cfg.add_node(NodeKind::Condition);
+                self.flow.cfg.add_edge(condition_node, next_condition);
+                condition_node = next_condition;
+            } else {
+                has_else = true;
+            }
+            let statements = alternative_statements(clause);
+            self.visit_branch(condition_node, &statements, &mut join_node);
+        }
+        if !has_else {
+            self.flow.skip_branches(condition_node, &mut join_node);
+        }
+        self.flow.current_node = join_node;
+    }
+
+    /// Numeric and generic `for`, and `while`
+    fn visit_loop(&mut self, node: &Node) {
+        let Some(header) = self.flow.start_loop() else {
+            return;
+        };
+        self.visit_statements(&block_statements(node.child_by_field_name("body")));
+        self.flow.end_loop(header);
+    }
+
+    /// `repeat ... until cond`
+    fn visit_repeat(&mut self, node: &Node) {
+        let Some(body_loop) = self.flow.start_post_test_loop() else {
+            return;
+        };
+        self.visit_statements(&block_statements(node.child_by_field_name("body")));
+        self.flow.end_post_test_loop(body_loop);
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::language::parser::LanguageParser;
+    use crate::language::LuaParser;
+
+    /// CC of the first function in `source`
+    fn cc(source: &str) -> usize {
+        let module = LuaParser::new().unwrap().parse(source, "test.lua").unwrap();
+        let function = module
+            .discover_functions(0, source)
+            .into_iter()
+            .next()
+            .expect("No function found in test source");
+        let cfg = LuaCfgBuilder.build(&function);
+        assert!(
+            cfg.validate().is_ok(),
+            "CFG must be valid: {:?}",
+            cfg.validate()
+        );
+        // CC = E - N + 2
+        (cfg.edge_count() as isize - cfg.node_count() as isize + 2).max(1) as usize
+    }
+
+    #[test]
+    fn test_simple_function() {
+        assert_eq!(cc("function f(x)\n  return x + 1\nend\n"), 1);
+        assert_eq!(cc("local f = function() end\n"), 1);
+    }
+
+    #[test]
+    fn test_elseif_chain() {
+        let source = r#"
+function sign(x)
+  if x > 0 then
+    return 1
+  elseif x < 0 then
+    return -1
+  else
+    return 0
+  end
+end
+"#;
+        assert_eq!(cc(source), 3);
+    }
+
+    #[test]
+    fn test_elseif_without_else() {
+        let source = r#"
+function label(x)
+  local s = "other"
+  if x == 1 then
+    s = "one"
+  elseif x == 2 then
+    s = "two"
+  elseif x == 3 then
+    s = "three"
+  end
+  return s
+end
+"#;
+        assert_eq!(cc(source), 4);
+    }
+
+    #[test]
+    fn test_loops() {
+        let source = r#"
+function loops(items)
+  for i = 1, 10 do
+    print(i)
+  end
+  for _, item in ipairs(items) do
+    if item < 0 then break end
+    print(item)
+  end
+  local n = 0
+  while n < 10 do
+    n = n + 1
+  end
+  repeat
+    n = n - 1
+  until n <= 0
+end
+"#;
+        assert_eq!(cc(source), 6);
+    }
+
+    #[test]
+    fn test_error_call_exits() {
+        let source = r#"
+function check(x)
+  if not x then
+    error("missing")
+  end
+  do
+    print(x)
+  end
+  return x
+end
+"#;
+        assert_eq!(cc(source), 2);
+    }
+
+    #[test]
+    fn test_nested_definitions_are_not_entered() {
+        let source = r#"
+function outer(xs)
+  local function inner(x)
+    if x > 0 then return x end
+    return 0
+  end
+  return map(xs, function(x) if x then return 1 end end)
+end
+"#;
+        assert_eq!(cc(source), 1);
+    }
+}
@@ -0,0 +1,79 @@
+//! Lua language support
+//!
+//! Parses Lua source files using tree-sitter-lua. Every function, anonymous
+//! ones included, is reported on its own and covers its own body only.
+
+pub mod cfg_builder;
+pub mod parser;
+
+pub use cfg_builder::LuaCfgBuilder;
+pub use parser::LuaParser;
+
+use crate::language::tree_sitter_utils::find_function_by_start;
+use tree_sitter::Node;
+
+/// Node kinds of a discovered function. The grammar may alias
+/// `local function` to `function_declaration`, so both are listed.
+pub(crate) const FUNCTION_KINDS: &[&str] = &[
+    "function_declaration",
+    "local_function_declaration",
+    "function_definition",
+];
+
+/// The function starting at `start_byte`
+pub(crate) fn find_function(root: Node<'_>, start_byte: usize) -> Option<Node<'_>> {
+    find_function_by_start(root, start_byte, FUNCTION_KINDS)
+}
+
+/// Function definitions, which are discovered on their own and are not part
+/// of the enclosing function's metrics
+pub(crate) fn is_nested_definition(node: Node<'_>) -> bool {
+    FUNCTION_KINDS.contains(&node.kind())
+}
+
+/// Whether a function is declared `local function`
+pub(crate) fn is_local_declaration(func_node: Node<'_>) -> bool {
+    func_node.kind() == "local_function_declaration"
+        || func_node
+            .child(0)
+            .is_some_and(|first| first.kind() == "local")
+}
+
+/// Statements of a block, skipping comments; none for an empty body
+pub(crate) fn block_statements(block: Option<Node<'_>>) -> Vec<Node<'_>> {
+    let Some(block) = block else {
+        return Vec::new();
+    };
+    let mut cursor = block.walk();
+    let statements = block
+        .named_children(&mut cursor)
+        .filter(|child| !child.kind().contains("comment"))
+        .collect();
+    statements
+}
+
+/// The `elseif` and `else` clauses of an `if` statement, in order
+pub(crate) fn if_alternatives(node: Node<'_>) -> Vec<Node<'_>> {
+    let mut cursor = node.walk();
+    let alternatives = node
+        .children_by_field_name("alternative", &mut cursor)
+        .collect();
+    alternatives
+}
+
+/// The statements an `elseif` or `else` clause runs
+pub(crate) fn alternative_statements(clause: Node<'_>) -> Vec<Node<'_>> {
+    block_statements(
+        clause
+            .child_by_field_name("consequence")
+            .or_else(|| clause.child_by_field_name("body")),
+    )
+}
+
+/// Whether `node` is a call to `error`, which raises
+pub(crate) fn is_error_call(node: Node<'_>, source: &str) -> bool {
+    node.kind() == "function_call"
+        && node
+            .child_by_field_name("name")
+            .is_some_and(|name| &source[name.start_byte()..name.end_byte()] == "error")
+}
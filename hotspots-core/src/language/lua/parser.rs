@@ -0,0 +1,332 @@
+//! Lua language parser using tree-sitter
+
+use crate::ast::FunctionNode;
+use crate::language::lua::{is_local_declaration, is_nested_definition};
+use crate::language::parser::{LanguageParser, ParsedModule};
+use crate::language::tree_sitter_utils::syntax_errors;
+use anyhow::{Context, Result};
+use tree_sitter::{Node, Parser, Tree};
+
+/// Lua parser using tree-sitter
+pub struct LuaParser;
+
+impl LuaParser {
+    /// Create a new Lua parser
+    pub fn new() -> Result<Self> {
+        let mut parser = Parser::new();
+        let language = tree_sitter_lua::LANGUAGE;
+        parser
+            .set_language(&language.into())
+            .context("Failed to set Lua language for parser")?;
+        Ok(LuaParser)
+    }
+}
+
+impl Default for LuaParser {
+    fn default() -> Self {
+        Self::new().expect("Failed to create Lua parser")
+    }
+}
+
+impl LanguageParser for LuaParser {
+    fn parse(&self, source: &str, filename: &str) -> Result<Box<dyn ParsedModule>> {
+        let mut parser = Parser::new();
+        let language = tree_sitter_lua::LANGUAGE;
+        parser
+            .set_language(&language.into())
+            .context("Failed to set Lua language")?;
+
+        let tree = parser
+            .parse(source, None)
+            .ok_or_else(|| anyhow::anyhow!("Failed to parse Lua file: {}", filename))?;
+
+        Ok(Box::new(LuaModule {
+            tree,
+            source: source.to_string(),
+        }))
+    }
+}
+
+/// Parsed Lua module
+struct LuaModule {
+    tree: Tree,
+    source: String,
+}
+
+impl ParsedModule for LuaModule {
+    fn discover_functions(&self, file_index: usize, _source: &str) -> Vec<FunctionNode> {
+        let root = self.tree.root_node();
+        let mut functions = Vec::new();
+        discover_functions_recursive(root, &self.source, file_index, true, &mut functions);
+        functions.sort_by_key(|f| f.span.start);
+        functions
+    }
+
+    fn syntax_errors(&self) -> Vec<std::ops::Range<usize>> {
+        syntax_errors(self.tree.root_node())
+    }
+
+    fn top_level_decisions(&self) -> usize {
+        crate::metrics::lua_top_level_decisions(&self.tree.root_node())
+    }
+}
+
+/// Recursively discover functions in the Lua AST, including functions
+/// defined inside other functions.
+///
+/// `top_level` is whether `node` is outside every function body: only
+/// functions declared there can be reached from other modules.
+fn discover_functions_recursive(
+    node: Node,
+    source: &str,
+    file_index: usize,
+    top_level: bool,
+    functions: &mut Vec<FunctionNode>,
+) {
+    let is_function = is_nested_definition(node);
+    if is_function {
+        let function_node = extract_function(node, source, file_index, functions.len(), top_level);
+        functions.push(function_node);
+    }
+
+    let mut cursor = node.walk();
+    for child in node.children(&mut cursor) {
+        discover_functions_recursive(
+            child,
+            source,
+            file_index,
+            top_level && !is_function,
+            functions,
+        );
+    }
+}
+
+/// Extract a FunctionNode from a function declaration or definition
+fn extract_function(
+    node: Node,
+    source: &str,
+    file_index: usize,
+    local_index: usize,
+    top_level: bool,
+) -> FunctionNode {
+    use crate::ast::FunctionId;
+    use crate::language::{FunctionBody, SourceSpan};
+
+    let (name, is_public) = match node.kind() {
+        "function_definition" => anonymous_function_name(node, source),
+        _ => {
+            let name = node
+                .child_by_field_name("name")
+                .map(|name| source[name.start_byte()..name.end_byte()].to_string());
+            (name, !is_local_declaration(node))
+        }
+    };
+
+    let span = SourceSpan::new(
+        node.start_byte(),
+        node.end_byte(),
+        node.start_position().row as u32 + 1, // tree-sitter uses 0-indexed rows
+        node.end_position().row as u32 + 1,   // tree-sitter uses 0-indexed rows
+        SourceSpan::column_of(source, node.start_byte()),
+    );
+
+    let body = FunctionBody::Lua {
+        body_node: node.id(),
+        source: source.to_string(),
+    };
+
+    FunctionNode {
+        id: FunctionId {
+            file_index,
+            local_index,
+        },
+        name,
+        owner: None,
+        span,
+        body,
+        suppression_reason: None, // Will be extracted separately
+        signature_complexity: 0,
+        params: crate::params::lua_params(node),
+        is_public: top_level && is_public,
+        is_async: false,
+    }
+}
+
+/// Name of an anonymous function, and whether it is visible outside the
+/// module when declared at the top level: the variable it is assigned to
+/// (`M.handler = function() ... end`, public unless `local`), or the table
+/// field it is stored in (`{ run = function() ... end }`, public). A callback
+/// passed as an argument has no name.
+fn anonymous_function_name(node: Node, source: &str) -> (Option<String>, bool) {
+    let text = |n: Node| source[n.start_byte()..n.end_byte()].to_string();
+    let Some(parent) = node.parent() else {
+        return (None, false);
+    };
+    match parent.kind() {
+        "field" => {
+            let name = parent
+                .child_by_field_name("name")
+                .filter(|name| name.kind() == "identifier")
+                .map(text);
+            let is_public = name.is_some();
+            (name, is_public)
+        }
+        "expression_list" => {
+            let Some(assignment) = parent
+                .parent()
+                .filter(|p| p.kind() == "assignment_statement")
+            else {
+                return (None, false);
+            };
+            // `a, b = function() end, function() end` pairs values with
+            // variables by position
+            let mut cursor = parent.walk();
+            let index = parent
+                .named_children(&mut cursor)
+                .position(|value| value.id() == node.id());
+            let mut cursor = assignment.walk();
+            let variables = assignment
+                .named_children(&mut cursor)
+                .find(|child| child.kind() == "variable_list");
+            let name = index.zip(variables).and_then(|(index, variables)| {
+                let mut cursor = variables.walk();
+                let variable = variables
+                    .named_children(&mut cursor)
+                    .filter(|v| !v.kind().contains("comment") && v.kind() != "attribute")
+                    .nth(index);
+                variable.map(text)
+            });
+            let is_local = assignment
+                .parent()
+                .is_some_and(|p| p.kind() == "variable_declaration");
+            let is_public = name.is_some() && !is_local;
+            (name, is_public)
+        }
+        _ => (None, false),
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    fn discover(source: &str) -> Vec<FunctionNode> {
+        let parser = LuaParser::new().unwrap();
+        let module = parser.parse(source, "test.lua").unwrap();
+        module.discover_functions(0, source)
+    }
+
+    fn names(functions: &[FunctionNode]) -> Vec<&str> {
+        functions
+            .iter()
+            .map(|f| f.name.as_deref().unwrap_or(""))
+            .collect()
+    }
+
+    #[test]
+    fn test_create_parser() {
+        assert!(LuaParser::new().is_ok());
+    }
+
+    #[test]
+    fn test_parse_declarations() {
+        let functions = discover(
+            r#"function add(a, b)
+  return a + b
+end
+
+local function twice(x)
+  return x * 2
+end
+
+function M.util.trim(s)
+  return s
+end
+
+function Account:deposit(v)
+  self.balance = self.balance + v
+end
+"#,
+        );
+        assert_eq!(
+            names(&functions),
+            vec!["add", "twice", "M.util.trim", "Account:deposit"]
+        );
+        assert_eq!(functions[0].span.start_line, 1);
+        assert_eq!(functions[0].span.end_line, 3);
+        assert_eq!(functions[1].span.start_line, 5);
+    }
+
+    #[test]
+    fn test_parse_anonymous_functions() {
+        let functions = discover(
+            r#"M.handler = function(event)
+  return event
+end
+
+local square = function(x) return x * x end
+
+local ops = {
+  add = function(a, b) return a + b end,
+}
+
+table.sort(items, function(a, b) return a < b end)
+"#,
+        );
+        // The callback passed to `table.sort` has no name
+        assert_eq!(names(&functions), vec!["M.handler", "square", "add", ""]);
+    }
+
+    #[test]
+    fn test_parse_nested_functions() {
+        let functions = discover(
+            r#"function outer(xs)
+  local function inner(x)
+    return x + 1
+  end
+  return map(xs, function(x) return inner(x) end)
+end
+"#,
+        );
+        assert_eq!(names(&functions), vec!["outer", "inner", ""]);
+    }
+
+    #[test]
+    fn test_parse_visibility() {
+        let functions = discover(
+            r#"function M.run() end
+
+local function helper() end
+
+M.on_load = function() end
+
+local cache = function() end
+
+function M.setup()
+  M.late = function() end
+end
+"#,
+        );
+        let public: Vec<(&str, bool)> = functions
+            .iter()
+            .map(|f| (f.name.as_deref().unwrap(), f.is_public))
+            .collect();
+        assert_eq!(
+            public,
+            vec![
+                ("M.run", true),
+                ("helper", false),
+                ("M.on_load", true),
+                ("cache", false),
+                ("M.setup", true),
+                ("M.late", false),
+            ]
+        );
+    }
+
+    #[test]
+    fn test_parse_empty_file() {
+        assert!(discover("").is_empty());
+        assert!(discover("local json = require(\"json\")\n").is_empty());
+    }
+}
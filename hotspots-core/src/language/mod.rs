@@ -3,34 +3,58 @@
 //! This module provides language-agnostic interfaces for parsing and analyzing
 //! source code across multiple programming languages.
 
+pub mod bash;
 pub mod c;
 pub mod cfg_builder;
+pub mod cpp;
 pub mod csharp;
+pub mod dart;
 pub mod ecmascript;
+pub mod elixir;
 pub mod function_body;
 pub mod go;
+pub mod haskell;
 pub mod java;
+pub mod lua;
 pub mod parser;
+pub mod perl;
+pub mod php;
 pub mod python;
 pub mod rust;
+pub mod scala;
 pub mod span;
+pub mod sql;
+pub mod swift;
 pub mod tree_sitter_utils;
+pub mod zig;
 
 use std::path::Path;
 
 use serde::{Deserialize, Serialize};
 
+pub use bash::{BashCfgBuilder, BashParser};
 pub use c::{CCfgBuilder, CParser};
 pub use cfg_builder::{get_builder_for_function, CfgBuilder};
+pub use cpp::{CppCfgBuilder, CppParser};
 pub use csharp::{CSharpCfgBuilder, CSharpParser};
+pub use dart::{DartCfgBuilder, DartParser};
 pub use ecmascript::{ECMAScriptCfgBuilder, ECMAScriptParser, VueParser};
+pub use elixir::{ElixirCfgBuilder, ElixirParser};
 pub use function_body::FunctionBody;
-pub use go::{GoCfgBuilder, GoParser};
+pub use go::{AnonNaming, GoCfgBuilder, GoParser};
+pub use haskell::{HaskellCfgBuilder, HaskellParser};
 pub use java::{JavaCfgBuilder, JavaParser};
+pub use lua::{LuaCfgBuilder, LuaParser};
 pub use parser::{LanguageParser, ParsedModule};
+pub use perl::{PerlCfgBuilder, PerlParser};
+pub use php::{PhpCfgBuilder, PhpParser};
 pub use python::{PythonCfgBuilder, PythonParser};
 pub use rust::{RustCfgBuilder, RustParser};
+pub use scala::{ScalaCfgBuilder, ScalaParser};
 pub use span::SourceSpan;
+pub use sql::{SqlCfgBuilder, SqlDialect, SqlParser};
+pub use swift::{SwiftCfgBuilder, SwiftParser};
+pub use zig::{ZigCfgBuilder, ZigParser};
 
 /// Supported programming languages
 #[derive(Debug, Clone, Copy, PartialEq, Eq, Hash)]
@@ -59,6 +83,30 @@ pub enum Language {
     C,
     /// C header (.h)
     CHeader,
+    /// C++ (.cpp, .cc, .cxx, .hpp)
+    Cpp,
+    /// SQL stored functions and procedures (.sql)
+    Sql,
+    /// Swift (.swift)
+    Swift,
+    /// PHP (.php, .phtml)
+    Php,
+    /// Scala (.scala, .sc)
+    Scala,
+    /// Dart (.dart)
+    Dart,
+    /// Elixir (.ex, .exs)
+    Elixir,
+    /// Lua (.lua)
+    Lua,
+    /// Bash and POSIX shell (.sh, .bash)
+    Bash,
+    /// Zig (.zig)
+    Zig,
+    /// Haskell (.hs)
+    Haskell,
+    /// Perl (.pl, .pm)
+    Perl,
 }
 
 impl Language {
@@ -99,6 +147,30 @@ impl Language {
             // C
             "c" => Some(Language::C),
             "h" => Some(Language::CHeader),
+            // C++
+            "cpp" | "cc" | "cxx" | "hpp" => Some(Language::Cpp),
+            // SQL
+            "sql" => Some(Language::Sql),
+            // Swift
+            "swift" => Some(Language::Swift),
+            // PHP
+            "php" | "phtml" => Some(Language::Php),
+            // Scala
+            "scala" | "sc" => Some(Language::Scala),
+            // Dart
+            "dart" => Some(Language::Dart),
+            // Elixir
+            "ex" | "exs" => Some(Language::Elixir),
+            // Lua
+            "lua" => Some(Language::Lua),
+            // Bash
+            "sh" | "bash" => Some(Language::Bash),
+            // Zig
+            "zig" => Some(Language::Zig),
+            // Haskell
+            "hs" => Some(Language::Haskell),
+            // Perl
+            "pl" | "pm" => Some(Language::Perl),
             // Unknown
             _ => None,
         }
@@ -159,6 +231,18 @@ impl Language {
             Language::CSharp => "C#",
             Language::C => "C",
             Language::CHeader => "C Header",
+            Language::Cpp => "C++",
+            Language::Sql => "SQL",
+            Language::Swift => "Swift",
+            Language::Php => "PHP",
+            Language::Scala => "Scala",
+            Language::Dart => "Dart",
+            Language::Elixir => "Elixir",
+            Language::Lua => "Lua",
+            Language::Bash => "Bash",
+            Language::Zig => "Zig",
+            Language::Haskell => "Haskell",
+            Language::Perl => "Perl",
         }
     }
 
@@ -194,6 +278,18 @@ impl Language {
             Language::CSharp => &["cs"],
             Language::C => &["c"],
             Language::CHeader => &["h"],
+            Language::Cpp => &["cpp", "cc", "cxx", "hpp"],
+            Language::Sql => &["sql"],
+            Language::Swift => &["swift"],
+            Language::Php => &["php", "phtml"],
+            Language::Scala => &["scala", "sc"],
+            Language::Dart => &["dart"],
+            Language::Elixir => &["ex", "exs"],
+            Language::Lua => &["lua"],
+            Language::Bash => &["sh", "bash"],
+            Language::Zig => &["zig"],
+            Language::Haskell => &["hs"],
+            Language::Perl => &["pl", "pm"],
         }
     }
 
@@ -212,6 +308,18 @@ impl Language {
             "C#" => Some(Language::CSharp),
             "C" => Some(Language::C),
             "C Header" => Some(Language::CHeader),
+            "C++" => Some(Language::Cpp),
+            "SQL" => Some(Language::Sql),
+            "Swift" => Some(Language::Swift),
+            "PHP" => Some(Language::Php),
+            "Scala" => Some(Language::Scala),
+            "Dart" => Some(Language::Dart),
+            "Elixir" => Some(Language::Elixir),
+            "Lua" => Some(Language::Lua),
+            "Bash" => Some(Language::Bash),
+            "Zig" => Some(Language::Zig),
+            "Haskell" => Some(Language::Haskell),
+            "Perl" => Some(Language::Perl),
             _ => None,
         }
     }
@@ -274,7 +382,7 @@ mod tests {
 
     #[test]
     fn test_from_extension_unknown() {
-        assert_eq!(Language::from_extension("cpp"), None);
+        assert_eq!(Language::from_extension("kt"), None);
         assert_eq!(Language::from_extension(""), None);
     }
 
@@ -284,6 +392,161 @@ mod tests {
         assert_eq!(Language::from_extension("h"), Some(Language::CHeader));
     }
 
+    #[test]
+    fn test_from_extension_cpp() {
+        for ext in ["cpp", "cc", "cxx", "hpp"] {
+            assert_eq!(Language::from_extension(ext), Some(Language::Cpp));
+        }
+        // `.h` stays C: it is shared by both languages
+        assert_eq!(Language::from_extension("h"), Some(Language::CHeader));
+        assert_eq!(
+            Language::from_name(Language::Cpp.name()),
+            Some(Language::Cpp)
+        );
+    }
+
+    #[test]
+    fn test_from_extension_sql() {
+        assert_eq!(Language::from_extension("sql"), Some(Language::Sql));
+        assert_eq!(
+            Language::from_name(Language::Sql.name()),
+            Some(Language::Sql)
+        );
+    }
+
+    #[test]
+    fn test_from_extension_swift() {
+        assert_eq!(Language::from_extension("swift"), Some(Language::Swift));
+        assert_eq!(
+            Language::from_path(Path::new("Sources/App/Model.swift")),
+            Some(Language::Swift)
+        );
+        assert_eq!(
+            Language::from_name(Language::Swift.name()),
+            Some(Language::Swift)
+        );
+    }
+
+    #[test]
+    fn test_from_extension_php() {
+        assert_eq!(Language::from_extension("php"), Some(Language::Php));
+        assert_eq!(
+            Language::from_path(Path::new("resources/views/layout.phtml")),
+            Some(Language::Php)
+        );
+        assert_eq!(
+            Language::from_name(Language::Php.name()),
+            Some(Language::Php)
+        );
+    }
+
+    #[test]
+    fn test_from_extension_scala() {
+        assert_eq!(Language::from_extension("scala"), Some(Language::Scala));
+        assert_eq!(
+            Language::from_path(Path::new("scripts/build.sc")),
+            Some(Language::Scala)
+        );
+        assert_eq!(
+            Language::from_name(Language::Scala.name()),
+            Some(Language::Scala)
+        );
+    }
+
+    #[test]
+    fn test_from_extension_dart() {
+        assert_eq!(Language::from_extension("dart"), Some(Language::Dart));
+        assert_eq!(
+            Language::from_path(Path::new("lib/widgets/home.dart")),
+            Some(Language::Dart)
+        );
+        assert_eq!(
+            Language::from_name(Language::Dart.name()),
+            Some(Language::Dart)
+        );
+    }
+
+    #[test]
+    fn test_from_extension_elixir() {
+        assert_eq!(Language::from_extension("ex"), Some(Language::Elixir));
+        assert_eq!(Language::from_extension("exs"), Some(Language::Elixir));
+        assert_eq!(
+            Language::from_path(Path::new("lib/shop_web/live/cart_live.ex")),
+            Some(Language::Elixir)
+        );
+        assert_eq!(
+            Language::from_name(Language::Elixir.name()),
+            Some(Language::Elixir)
+        );
+    }
+
+    #[test]
+    fn test_from_extension_lua() {
+        assert_eq!(Language::from_extension("lua"), Some(Language::Lua));
+        assert_eq!(
+            Language::from_path(Path::new("scripts/ai/patrol.lua")),
+            Some(Language::Lua)
+        );
+        assert_eq!(
+            Language::from_name(Language::Lua.name()),
+            Some(Language::Lua)
+        );
+    }
+
+    #[test]
+    fn test_from_extension_bash() {
+        assert_eq!(Language::from_extension("sh"), Some(Language::Bash));
+        assert_eq!(Language::from_extension("bash"), Some(Language::Bash));
+        assert_eq!(
+            Language::from_path(Path::new("scripts/deploy.sh")),
+            Some(Language::Bash)
+        );
+        assert_eq!(
+            Language::from_name(Language::Bash.name()),
+            Some(Language::Bash)
+        );
+    }
+
+    #[test]
+    fn test_from_extension_zig() {
+        assert_eq!(Language::from_extension("zig"), Some(Language::Zig));
+        assert_eq!(
+            Language::from_path(Path::new("src/main.zig")),
+            Some(Language::Zig)
+        );
+        assert_eq!(
+            Language::from_name(Language::Zig.name()),
+            Some(Language::Zig)
+        );
+    }
+
+    #[test]
+    fn test_from_extension_haskell() {
+        assert_eq!(Language::from_extension("hs"), Some(Language::Haskell));
+        assert_eq!(
+            Language::from_path(Path::new("src/Data/Tree.hs")),
+            Some(Language::Haskell)
+        );
+        assert_eq!(
+            Language::from_name(Language::Haskell.name()),
+            Some(Language::Haskell)
+        );
+    }
+
+    #[test]
+    fn test_from_extension_perl() {
+        assert_eq!(Language::from_extension("pl"), Some(Language::Perl));
+        assert_eq!(Language::from_extension("pm"), Some(Language::Perl));
+        assert_eq!(
+            Language::from_path(Path::new("lib/App/Config.pm")),
+            Some(Language::Perl)
+        );
+        assert_eq!(
+            Language::from_name(Language::Perl.name()),
+            Some(Language::Perl)
+        );
+    }
+
     #[test]
     fn test_from_path() {
         assert_eq!(
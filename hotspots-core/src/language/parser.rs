@@ -2,6 +2,7 @@
 
 use crate::ast::FunctionNode;
 use anyhow::Result;
+use std::ops::Range;
 
 /// Language-agnostic parser interface
 ///
@@ -39,6 +40,24 @@ pub trait ParsedModule {
     ///
     /// Vector of function nodes sorted by source position
     fn discover_functions(&self, file_index: usize, source: &str) -> Vec<FunctionNode>;
+
+    /// Byte ranges of the syntax errors the parser recovered from, in source
+    /// order
+    ///
+    /// Parsers that reject a file with a syntax error outright, instead of
+    /// recovering, report none.
+    fn syntax_errors(&self) -> Vec<Range<usize>> {
+        Vec::new()
+    }
+
+    /// Decision points outside every function body, such as a module-level
+    /// `if` or loop, counted toward a file's `file_cc`
+    ///
+    /// Languages whose files hold no statements outside functions report
+    /// none.
+    fn top_level_decisions(&self) -> usize {
+        0
+    }
 }
 
 #[cfg(test)]
@@ -62,6 +81,7 @@ mod tests {
                         local_index: i,
                     },
                     name: Some(format!("test_fn_{}", i)),
+                    owner: None,
                     span: SourceSpan::new(i * 10, (i + 1) * 10, (i + 1) as u32, (i + 1) as u32, 0),
                     body: FunctionBody::ecmascript(swc_ecma_ast::BlockStmt {
                         span: swc_common::DUMMY_SP,
@@ -69,6 +89,10 @@ mod tests {
                         stmts: vec![],
                     }),
                     suppression_reason: None,
+                    signature_complexity: 0,
+                    params: 0,
+                    is_public: false,
+                    is_async: false,
                 })
                 .collect()
         }
@@ -0,0 +1,274 @@
+//! Perl CFG builder implementation
+//!
+//! `goto` is a plain statement, since its target is not resolved; `last` and
+//! `next` jump in the innermost loop, whatever their label.
+
+use crate::ast::FunctionNode;
+use crate::cfg::{Cfg, NodeId, NodeKind};
+use crate::language::cfg_builder::{CfgBuilder, CfgState};
+use crate::language::perl::{
+    block_statements, body_statements, find_function, if_clauses, jump, modified_expression,
+    statement_expression, ClauseKind, Jump, LOOP_KINDS, POSTFIX_CONDITIONAL, POSTFIX_LOOP_KINDS,
+};
+use crate::language::tree_sitter_utils::with_cached_perl_tree;
+use tree_sitter::Node;
+
+/// Perl CFG builder
+pub struct PerlCfgBuilder;
+
+impl CfgBuilder for PerlCfgBuilder {
+    fn build(&self, function: &FunctionNode) -> Cfg {
+        let (_body_node_id, source) = function.body.as_perl();
+
+        let result = with_cached_perl_tree(source, |root| {
+            let func_node = find_function(root, function.span.start)?;
+            let mut builder = PerlCfgBuilderState {
+                flow: CfgState::new(),
+                source,
+            };
+            builder.visit_statements(&body_statements(func_node));
+            Some(builder.flow.finish())
+        });
+
+        result.unwrap_or_else(CfgState::straight_line)
+    }
+}
+
+struct PerlCfgBuilderState<'s> {
+    flow: CfgState,
+    source: &'s str,
+}
+
+impl PerlCfgBuilderState<'_> {
+    fn visit_statements(&mut self, statements: &[Node]) {
+        for stmt in statements {
+            self.visit_node(stmt);
+        }
+    }
+
+    fn visit_node(&mut self, node: &Node) {
+        match node.kind() {
+            "conditional_statement" => self.visit_if(node),
+            kind if LOOP_KINDS.contains(&kind) => {
+                let body = body_statements(*node);
+                self.visit_loop(|builder| builder.visit_statements(&body));
+            }
+            "block_statement" => self.visit_statements(&body_statements(*node)),
+            "block" => self.visit_statements(&block_statements(Some(*node))),
+            "expression_statement" => match statement_expression(*node) {
+                Some(expression) => self.visit_expression(expression),
+                None => self.flow.statement(),
+            },
+            // Sub definitions are discovered on their own
+            _ => self.flow.statement(),
+        }
+    }
+
+    /// An expression statement: a jump, a statement modifier, or a plain
+    /// statement
+    fn visit_expression(&mut self, expression: Node) {
+        let kind = expression.kind();
+        if kind == POSTFIX_CONDITIONAL {
+            self.visit_postfix_conditional(expression);
+        } else if POSTFIX_LOOP_KINDS.contains(&kind) {
+            self.visit_loop(|builder| builder.visit_modified(expression));
+        } else {
+            match jump(expression, self.source) {
+                Some(Jump::Return | Jump::Raise) => self.flow.jump_to_exit(),
+                Some(Jump::Last) => self.visit_jump(CfgState::jump_to_break),
+                Some(Jump::Next) => self.visit_jump(CfgState::jump_to_continue),
+                Some(Jump::Goto) | None => self.flow.statement(),
+            }
+        }
+    }
+
+    /// The statement a modifier applies to, as one statement
+    fn visit_modified(&mut self, postfix: Node) {
+        match modified_expression(postfix) {
+            Some(expression) => self.visit_expression(expression),
+            None => self.flow.statement(),
+        }
+    }
+
+    /// `if` / `unless` with `elsif` and `else`: each `elsif` is a further
+    /// condition tested when the previous one fails
+    fn visit_if(&mut self, node: &Node) {
+        let Some(mut condition_node) = self.flow.add_after(NodeKind::Condition) else {
+            return;
+        };
+
+        let mut join_node = None;
+        let mut has_else = false;
+        for clause in if_clauses(*node) {
+            let statements = block_statements(clause.block);
+            match clause.kind {
+                ClauseKind::If => {}
+                ClauseKind::Elsif => {
+                    let next_condition = self.flow.cfg.add_node(NodeKind::Condition);
+                    self.flow.cfg.add_edge(condition_node, next_condition);
+                    condition_node = next_condition;
+                }
+                ClauseKind::Else => has_else = true,
+            }
+            self.flow.start_branch(condition_node);
+            self.visit_statements(&statements);
+            self.flow.fall_through(&mut join_node);
+        }
+        if !has_else {
+            self.flow.skip_branches(condition_node, &mut join_node);
+        }
+        self.flow.current_node = join_node;
+    }
+
+    /// `... if cond` / `... unless cond`: a branch that runs the statement or
+    /// skips it
+    fn visit_postfix_conditional(&mut self, postfix: Node) {
+        let Some(condition_node) = self.flow.add_after(NodeKind::Condition) else {
+            return;
+        };
+
+        let mut join_node = None;
+        self.visit_modified(postfix);
+        self.flow.fall_through(&mut join_node);
+        self.flow.skip_branches(condition_node, &mut join_node);
+        self.flow.current_node = join_node;
+    }
+
+    /// A loop statement or loop modifier
+    fn visit_loop(&mut self, visit_body: impl FnOnce(&mut Self)) {
+        let Some(header) = self.flow.start_loop() else {
+            return;
+        };
+        visit_body(self);
+        self.flow.end_loop(header);
+    }
+
+    /// `last` or `next`, made by `jump` in the innermost loop. Outside a loop
+    /// it leaves the sub.
+    fn visit_jump(&mut self, jump: fn(&mut CfgState)) {
+        if self.flow.in_loop() {
+            jump(&mut self.flow);
+        } else {
+            self.flow.jump_to_exit();
+        }
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::language::parser::LanguageParser;
+    use crate::language::PerlParser;
+
+    /// CC of the first sub in `source`
+    fn cc(source: &str) -> usize {
+        let module = PerlParser::new().unwrap().parse(source, "test.pl").unwrap();
+        let function = module
+            .discover_functions(0, source)
+            .into_iter()
+            .next()
+            .expect("No sub found in test source");
+        let cfg = PerlCfgBuilder.build(&function);
+        assert!(
+            cfg.validate().is_ok(),
+            "CFG must be valid: {:?}",
+            cfg.validate()
+        );
+        // CC = E - N + 2
+        (cfg.edge_count() as isize - cfg.node_count() as isize + 2).max(1) as usize
+    }
+
+    #[test]
+    fn test_simple_sub() {
+        assert_eq!(
+            cc("sub f {\n    my $x = shift;\n    return $x + 1;\n}\n"),
+            1
+        );
+        assert_eq!(cc("my $f = sub { };\n"), 1);
+    }
+
+    #[test]
+    fn test_elsif_chain() {
+        let source = r#"
+sub sign {
+    my $x = shift;
+    if ($x > 0) {
+        return 1;
+    } elsif ($x < 0) {
+        return -1;
+    } else {
+        return 0;
+    }
+}
+"#;
+        assert_eq!(cc(source), 3);
+    }
+
+    #[test]
+    fn test_unless_without_else() {
+        let source = r#"
+sub label {
+    my $x = shift;
+    my $s = "other";
+    unless ($x) {
+        $s = "none";
+    }
+    return $s;
+}
+"#;
+        assert_eq!(cc(source), 2);
+    }
+
+    #[test]
+    fn test_loops() {
+        let source = r#"
+sub loops {
+    my @items = @_;
+    for (my $i = 0; $i < 10; $i++) {
+        step($i);
+    }
+    foreach my $item (@items) {
+        last if $item < 0;
+        step($item);
+    }
+    my $n = 0;
+    while ($n < 10) {
+        $n++;
+    }
+    until ($n <= 0) {
+        $n--;
+    }
+}
+"#;
+        assert_eq!(cc(source), 6);
+    }
+
+    #[test]
+    fn test_statement_modifiers() {
+        let source = r#"
+sub check {
+    my $x = shift;
+    return 0 unless defined $x;
+    die "negative" if $x < 0;
+    step($_) for 1 .. $x;
+    $x-- while $x > 10;
+    return $x;
+}
+"#;
+        assert_eq!(cc(source), 5);
+    }
+
+    #[test]
+    fn test_nested_subs_are_not_entered() {
+        let source = r#"
+sub outer {
+    my $inner = sub {
+        if ($_[0] > 0) { return $_[0] }
+        return 0;
+    };
+    return apply(sub { $_[0] ? 1 : 0 }, @_);
+}
+"#;
+        assert_eq!(cc(source), 1);
+    }
+}
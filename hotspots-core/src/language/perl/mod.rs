@@ -0,0 +1,241 @@
+//! Perl language support
+//!
+//! Parses Perl source files (`.pl`, `.pm`) using tree-sitter-perl. Every
+//! `sub`, anonymous ones included, is reported on its own; the constructs
+//! counted are listed in docs/REFERENCE.md.
+
+pub mod cfg_builder;
+pub mod parser;
+
+pub use cfg_builder::PerlCfgBuilder;
+pub use parser::PerlParser;
+
+use crate::language::tree_sitter_utils::{find_child_by_kind, find_function_by_start};
+use tree_sitter::Node;
+
+/// Node kinds of a discovered sub
+pub(crate) const FUNCTION_KINDS: &[&str] = &[
+    "subroutine_declaration_statement",
+    "anonymous_subroutine_expression",
+];
+
+/// Loop statements: `while` / `until`, `foreach`, and C-style `for`
+pub(crate) const LOOP_KINDS: &[&str] = &["loop_statement", "for_statement", "cstyle_for_statement"];
+
+/// Statement modifiers that repeat their statement: `... while cond`,
+/// `... until cond`, and `... for list`
+pub(crate) const POSTFIX_LOOP_KINDS: &[&str] =
+    &["postfix_loop_expression", "postfix_for_expression"];
+
+/// Statement modifier that runs its statement at most once: `... if cond`
+/// and `... unless cond`
+pub(crate) const POSTFIX_CONDITIONAL: &str = "postfix_conditional_expression";
+
+/// Subs that raise or end the program (matched on the last `::` segment)
+const RAISE_FUNCTIONS: &[&str] = &["die", "croak", "confess", "exit"];
+
+/// The sub starting at `start_byte`
+pub(crate) fn find_function(root: Node<'_>, start_byte: usize) -> Option<Node<'_>> {
+    find_function_by_start(root, start_byte, FUNCTION_KINDS)
+}
+
+/// Sub definitions, which are discovered on their own and are not part of
+/// the enclosing sub's metrics
+pub(crate) fn is_nested_definition(node: Node<'_>) -> bool {
+    FUNCTION_KINDS.contains(&node.kind())
+}
+
+/// Source text of `node`
+pub(crate) fn node_text<'s>(node: Node<'_>, source: &'s str) -> &'s str {
+    &source[node.start_byte()..node.end_byte()]
+}
+
+/// Whether `node` is a comment or POD documentation
+fn is_comment(node: Node<'_>) -> bool {
+    node.kind().contains("comment") || node.kind() == "pod"
+}
+
+/// Statements of a block, skipping comments; none for an empty body
+pub(crate) fn block_statements(block: Option<Node<'_>>) -> Vec<Node<'_>> {
+    let Some(block) = block else {
+        return Vec::new();
+    };
+    let mut cursor = block.walk();
+    let statements = block
+        .named_children(&mut cursor)
+        .filter(|child| !is_comment(*child))
+        .collect();
+    statements
+}
+
+/// The block a sub, clause, or loop runs
+pub(crate) fn body_block(node: Node<'_>) -> Option<Node<'_>> {
+    node.child_by_field_name("body")
+        .or_else(|| node.child_by_field_name("block"))
+        .or_else(|| find_child_by_kind(node, "block"))
+}
+
+/// Statements of the block a sub, clause, or loop runs
+pub(crate) fn body_statements(node: Node<'_>) -> Vec<Node<'_>> {
+    block_statements(body_block(node))
+}
+
+/// The expression an expression statement evaluates
+pub(crate) fn statement_expression(statement: Node<'_>) -> Option<Node<'_>> {
+    if statement.kind() != "expression_statement" {
+        return None;
+    }
+    let mut cursor = statement.walk();
+    let expression = statement
+        .named_children(&mut cursor)
+        .find(|child| !is_comment(*child));
+    expression
+}
+
+/// The statement a statement modifier applies to: `return 0` in
+/// `return 0 if $done`
+pub(crate) fn modified_expression(postfix: Node<'_>) -> Option<Node<'_>> {
+    postfix.named_child(0)
+}
+
+/// What kind of clause of an `if` / `unless` statement
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub(crate) enum ClauseKind {
+    If,
+    Elsif,
+    Else,
+}
+
+/// One clause of an `if` / `unless` statement: where it starts and the
+/// block it runs
+#[derive(Debug, Clone, Copy)]
+pub(crate) struct Clause<'a> {
+    pub kind: ClauseKind,
+    /// The `elsif` / `else` node, or its keyword when the grammar does not
+    /// wrap the clause; the statement itself for the first clause
+    pub start: Node<'a>,
+    pub block: Option<Node<'a>>,
+}
+
+/// The clauses of an `if` / `unless` statement, in order. `elsif` and
+/// `else` may be nodes of their own or keywords followed by a block.
+pub(crate) fn if_clauses(node: Node<'_>) -> Vec<Clause<'_>> {
+    let mut clauses = Vec::new();
+    let mut pending: Option<(ClauseKind, Node)> = Some((ClauseKind::If, node));
+    let mut cursor = node.walk();
+    for child in node.children(&mut cursor) {
+        match child.kind() {
+            "elsif" | "else" if child.is_named() => {
+                let kind = if child.kind() == "elsif" {
+                    ClauseKind::Elsif
+                } else {
+                    ClauseKind::Else
+                };
+                clauses.push(Clause {
+                    kind,
+                    start: child,
+                    block: body_block(child),
+                });
+            }
+            "elsif" => pending = Some((ClauseKind::Elsif, child)),
+            "else" => pending = Some((ClauseKind::Else, child)),
+            "block" => {
+                if let Some((kind, start)) = pending.take() {
+                    clauses.push(Clause {
+                        kind,
+                        start,
+                        block: Some(child),
+                    });
+                }
+            }
+            _ => {}
+        }
+    }
+    clauses
+}
+
+/// Whether an `if` / `unless` statement has an `elsif` or `else`
+pub(crate) fn has_alternative(node: Node<'_>) -> bool {
+    node.kind() == "conditional_statement"
+        && if_clauses(node)
+            .iter()
+            .any(|clause| clause.kind != ClauseKind::If)
+}
+
+/// The `and` / `or` family operator of a binary expression: `&&`, `||`,
+/// `//`, `and`, or `or`
+pub(crate) fn logical_operator<'s>(node: Node<'_>, source: &'s str) -> Option<&'s str> {
+    let operator = node.child_by_field_name("operator")?;
+    let text = node_text(operator, source);
+    matches!(text, "&&" | "||" | "//" | "and" | "or").then_some(text)
+}
+
+/// Name a call calls: the sub (`validate`, `Data::Dumper::Dumper`,
+/// `die`), or `invocant->method` for a method call (`$self->save`)
+pub(crate) fn call_name(node: Node<'_>, source: &str) -> Option<String> {
+    match node.kind() {
+        "function_call_expression"
+        | "ambiguous_function_call_expression"
+        | "func0op_call_expression"
+        | "func1op_call_expression" => {
+            let function = node
+                .child_by_field_name("function")
+                .or_else(|| find_child_by_kind(node, "function"))?;
+            Some(node_text(function, source).trim().to_string())
+        }
+        "method_call_expression" => {
+            let method = node.child_by_field_name("method")?;
+            let invocant = node
+                .child_by_field_name("invocant")
+                .map_or("", |invocant| node_text(invocant, source));
+            Some(format!(
+                "{}->{}",
+                invocant.trim(),
+                node_text(method, source)
+            ))
+        }
+        _ => None,
+    }
+}
+
+/// Whether a called name raises or ends the program
+pub(crate) fn is_raise(name: &str) -> bool {
+    RAISE_FUNCTIONS.contains(&name.rsplit("::").next().unwrap_or(name))
+}
+
+/// How an expression leaves the normal flow of a sub
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub(crate) enum Jump {
+    /// `return`
+    Return,
+    /// `die`, `croak`, `confess`, `exit`
+    Raise,
+    /// `last`
+    Last,
+    /// `next` and `redo`
+    Next,
+    /// `goto`, whose target is not resolved
+    Goto,
+}
+
+/// The jump `node` is, if any
+pub(crate) fn jump(node: Node<'_>, source: &str) -> Option<Jump> {
+    match node.kind() {
+        "return_expression" => Some(Jump::Return),
+        "goto_expression" => Some(Jump::Goto),
+        "loopex_expression" => match node_text(node, source).split_whitespace().next() {
+            Some("last") => Some(Jump::Last),
+            Some("next" | "redo") => Some(Jump::Next),
+            _ => None,
+        },
+        _ => call_name(node, source)
+            .filter(|name| is_raise(name))
+            .map(|_| Jump::Raise),
+    }
+}
+
+/// The jump a statement is, if it is one (`return $x;`, `last;`,
+/// `die "...";`)
+pub(crate) fn statement_jump(statement: Node<'_>, source: &str) -> Option<Jump> {
+    statement_expression(statement).and_then(|expression| jump(expression, source))
+}
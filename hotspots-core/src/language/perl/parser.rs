@@ -0,0 +1,368 @@
+//! Perl language parser using tree-sitter
+
+use crate::ast::FunctionNode;
+use crate::language::parser::{LanguageParser, ParsedModule};
+use crate::language::perl::{is_nested_definition, node_text};
+use crate::language::tree_sitter_utils::{find_child_by_kind, syntax_errors};
+use anyhow::{Context, Result};
+use tree_sitter::{Node, Parser, Tree};
+
+/// Perl parser using tree-sitter
+pub struct PerlParser;
+
+impl PerlParser {
+    /// Create a new Perl parser
+    pub fn new() -> Result<Self> {
+        let mut parser = Parser::new();
+        let language = tree_sitter_perl::LANGUAGE;
+        parser
+            .set_language(&language.into())
+            .context("Failed to set Perl language for parser")?;
+        Ok(PerlParser)
+    }
+}
+
+impl Default for PerlParser {
+    fn default() -> Self {
+        Self::new().expect("Failed to create Perl parser")
+    }
+}
+
+impl LanguageParser for PerlParser {
+    fn parse(&self, source: &str, filename: &str) -> Result<Box<dyn ParsedModule>> {
+        let mut parser = Parser::new();
+        let language = tree_sitter_perl::LANGUAGE;
+        parser
+            .set_language(&language.into())
+            .context("Failed to set Perl language")?;
+
+        let tree = parser
+            .parse(source, None)
+            .ok_or_else(|| anyhow::anyhow!("Failed to parse Perl file: {}", filename))?;
+
+        Ok(Box::new(PerlModule {
+            tree,
+            source: source.to_string(),
+        }))
+    }
+}
+
+/// Parsed Perl module
+struct PerlModule {
+    tree: Tree,
+    source: String,
+}
+
+impl ParsedModule for PerlModule {
+    fn discover_functions(&self, file_index: usize, _source: &str) -> Vec<FunctionNode> {
+        let root = self.tree.root_node();
+        let mut functions = Vec::new();
+        discover_functions_recursive(root, &self.source, file_index, None, true, &mut functions);
+        functions.sort_by_key(|f| f.span.start);
+        functions
+    }
+
+    fn syntax_errors(&self) -> Vec<std::ops::Range<usize>> {
+        syntax_errors(self.tree.root_node())
+    }
+
+    fn top_level_decisions(&self) -> usize {
+        crate::metrics::perl_top_level_decisions(&self.tree.root_node(), &self.source)
+    }
+}
+
+/// Recursively discover subs under `node`, including subs defined inside
+/// other subs.
+///
+/// `package` is the package in effect: a `package Name;` statement sets it
+/// for the statements after it in the same block, and `package Name { ... }`
+/// for its block. `top_level` is whether `node` is outside every sub body:
+/// only subs defined there are part of a module's interface.
+fn discover_functions_recursive(
+    node: Node,
+    source: &str,
+    file_index: usize,
+    package: Option<&str>,
+    top_level: bool,
+    functions: &mut Vec<FunctionNode>,
+) {
+    let mut package = package.map(str::to_string);
+    let mut cursor = node.walk();
+    for child in node.children(&mut cursor) {
+        if child.kind() == "package_statement" {
+            let name = package_name(child, source);
+            match find_child_by_kind(child, "block") {
+                Some(block) => discover_functions_recursive(
+                    block,
+                    source,
+                    file_index,
+                    name.as_deref(),
+                    top_level,
+                    functions,
+                ),
+                None => package = name,
+            }
+            continue;
+        }
+        let is_function = is_nested_definition(child);
+        if is_function {
+            let function_node = extract_function(
+                child,
+                source,
+                file_index,
+                functions.len(),
+                package.as_deref(),
+                top_level,
+            );
+            functions.push(function_node);
+        }
+        discover_functions_recursive(
+            child,
+            source,
+            file_index,
+            package.as_deref(),
+            top_level && !is_function,
+            functions,
+        );
+    }
+}
+
+/// Name of the package a `package` statement declares
+fn package_name(node: Node, source: &str) -> Option<String> {
+    node.child_by_field_name("name")
+        .or_else(|| find_child_by_kind(node, "package"))
+        .map(|name| node_text(name, source).to_string())
+}
+
+/// Extract a FunctionNode from a named or anonymous sub
+fn extract_function(
+    node: Node,
+    source: &str,
+    file_index: usize,
+    local_index: usize,
+    package: Option<&str>,
+    top_level: bool,
+) -> FunctionNode {
+    use crate::ast::FunctionId;
+    use crate::language::{FunctionBody, SourceSpan};
+
+    let (name, exported) = match node.kind() {
+        "anonymous_subroutine_expression" => anonymous_sub_name(node, source),
+        _ => {
+            let name = node
+                .child_by_field_name("name")
+                .map(|name| node_text(name, source).to_string());
+            (name, true)
+        }
+    };
+    // A leading underscore marks a sub as private by convention
+    let is_public = exported
+        && name
+            .as_deref()
+            .and_then(|n| n.rsplit("::").next())
+            .is_some_and(|n| !n.starts_with('_'));
+
+    let span = SourceSpan::new(
+        node.start_byte(),
+        node.end_byte(),
+        node.start_position().row as u32 + 1, // tree-sitter uses 0-indexed rows
+        node.end_position().row as u32 + 1,   // tree-sitter uses 0-indexed rows
+        SourceSpan::column_of(source, node.start_byte()),
+    );
+
+    let body = FunctionBody::Perl {
+        body_node: node.id(),
+        source: source.to_string(),
+    };
+
+    FunctionNode {
+        id: FunctionId {
+            file_index,
+            local_index,
+        },
+        name,
+        owner: package.map(str::to_string),
+        span,
+        body,
+        suppression_reason: None, // Will be extracted separately
+        signature_complexity: 0,
+        params: crate::params::perl_params(node, source),
+        is_public: top_level && is_public,
+        is_async: false,
+    }
+}
+
+/// Name of an anonymous sub, and whether it defines a package sub: the
+/// variable it is assigned to without its sigil (`my $cb = sub { ... }`
+/// reports `cb`, lexical), the glob it is installed in (`*alias = sub
+/// { ... }` reports `alias`, a package sub), or the hash key it is stored
+/// under (`on_error => sub { ... }` reports `on_error`). A sub passed as an
+/// argument has no name.
+fn anonymous_sub_name(node: Node, source: &str) -> (Option<String>, bool) {
+    let Some(parent) = node.parent() else {
+        return (None, false);
+    };
+    if parent.kind() == "assignment_expression"
+        && parent
+            .child_by_field_name("right")
+            .is_some_and(|right| right.id() == node.id())
+    {
+        let Some(left) = parent.child_by_field_name("left") else {
+            return (None, false);
+        };
+        return match left.kind() {
+            "scalar" => (Some(variable_name(left, source)), false),
+            "glob" => (Some(variable_name(left, source)), true),
+            // `my $cb`, `our $cb`, `local $cb`
+            _ => {
+                let variable = left
+                    .child_by_field_name("variable")
+                    .or_else(|| find_child_by_kind(left, "scalar"));
+                (variable.map(|v| variable_name(v, source)), false)
+            }
+        };
+    }
+    // `key => sub { ... }`: the key comes before the fat comma
+    let key = node
+        .prev_sibling()
+        .filter(|arrow| arrow.kind() == "=>")
+        .and_then(|arrow| arrow.prev_sibling())
+        .map(|key| {
+            node_text(key, source)
+                .trim_matches(|c| c == '\'' || c == '"')
+                .to_string()
+        })
+        .filter(|key| !key.is_empty());
+    (key, false)
+}
+
+/// A variable's name without its sigil: `cb` for `$cb`, `alias` for `*alias`
+fn variable_name(variable: Node, source: &str) -> String {
+    node_text(variable, source)
+        .trim_start_matches(|c| c == '$' || c == '*')
+        .trim()
+        .to_string()
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    fn discover(source: &str) -> Vec<FunctionNode> {
+        let parser = PerlParser::new().unwrap();
+        let module = parser.parse(source, "test.pl").unwrap();
+        module.discover_functions(0, source)
+    }
+
+    fn names(functions: &[FunctionNode]) -> Vec<&str> {
+        functions
+            .iter()
+            .map(|f| f.name.as_deref().unwrap_or(""))
+            .collect()
+    }
+
+    #[test]
+    fn test_create_parser() {
+        assert!(PerlParser::new().is_ok());
+    }
+
+    #[test]
+    fn test_parse_named_subs() {
+        let functions = discover(
+            r#"use strict;
+
+sub add {
+    my ($a, $b) = @_;
+    return $a + $b;
+}
+
+sub Util::trim {
+    my $s = shift;
+    return $s;
+}
+"#,
+        );
+        assert_eq!(names(&functions), vec!["add", "Util::trim"]);
+        assert_eq!(functions[0].span.start_line, 3);
+        assert_eq!(functions[0].span.end_line, 6);
+        assert_eq!(functions[1].span.start_line, 8);
+    }
+
+    #[test]
+    fn test_parse_anonymous_subs() {
+        let functions = discover(
+            r#"my $square = sub { return $_[0] * $_[0] };
+
+*alias = sub { 1 };
+
+my %handlers = (
+    on_error => sub { warn @_ },
+);
+
+my @sorted = sort { $a <=> $b } map { $_ * 2 } apply(sub { 1 }, @items);
+"#,
+        );
+        // `sort` and `map` blocks are not subs; the argument has no name
+        assert_eq!(names(&functions), vec!["square", "alias", "on_error", ""]);
+    }
+
+    #[test]
+    fn test_parse_nested_subs() {
+        let functions = discover(
+            r#"sub outer {
+    my @xs = @_;
+    my $inner = sub { return $_[0] + 1 };
+    sub helper { return 2 }
+    return map { $inner->($_) } @xs;
+}
+"#,
+        );
+        assert_eq!(names(&functions), vec!["outer", "inner", "helper"]);
+    }
+
+    #[test]
+    fn test_parse_packages_and_visibility() {
+        let functions = discover(
+            r#"package Counter;
+
+sub new { return bless {}, shift }
+
+sub _bump { }
+
+*reset = sub { };
+
+my $log = sub { };
+
+package Counter::Util {
+    sub clamp { }
+}
+
+sub outer {
+    sub inner { }
+}
+"#,
+        );
+        let summary: Vec<(&str, Option<&str>, bool)> = functions
+            .iter()
+            .map(|f| (f.name.as_deref().unwrap(), f.owner.as_deref(), f.is_public))
+            .collect();
+        assert_eq!(
+            summary,
+            vec![
+                ("new", Some("Counter"), true),
+                ("_bump", Some("Counter"), false),
+                ("reset", Some("Counter"), true),
+                ("log", Some("Counter"), false),
+                ("clamp", Some("Counter::Util"), true),
+                ("outer", Some("Counter"), true),
+                ("inner", Some("Counter"), false),
+            ]
+        );
+    }
+
+    #[test]
+    fn test_parse_empty_file() {
+        assert!(discover("").is_empty());
+        assert!(discover("use strict;\nprint \"hello\\n\";\n").is_empty());
+    }
+}
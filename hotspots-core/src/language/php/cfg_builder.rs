@@ -0,0 +1,400 @@
+//! PHP CFG builder implementation
+
+use crate::ast::FunctionNode;
+use crate::cfg::{Cfg, NodeId, NodeKind};
+use crate::language::cfg_builder::{CfgBuilder, CfgState};
+use crate::language::php::{is_non_code, is_terminator, FUNCTION_KINDS};
+use crate::language::tree_sitter_utils::{
+    find_child_by_kind, find_function_by_start, with_cached_php_tree,
+};
+use tree_sitter::Node;
+
+/// PHP CFG builder
+pub struct PhpCfgBuilder;
+
+impl CfgBuilder for PhpCfgBuilder {
+    fn build(&self, function: &FunctionNode) -> Cfg {
+        let (_body_node_id, source) = function.body.as_php();
+
+        let result = with_cached_php_tree(source, |root| {
+            let func_node = find_function_by_start(root, function.span.start, FUNCTION_KINDS)?;
+            let body_node = func_node.child_by_field_name("body")?;
+            let mut builder = PhpCfgBuilderState {
+                flow: CfgState::new(),
+            };
+            if body_node.kind() == "compound_statement" {
+                builder.build_from_block(&body_node, source);
+            } else {
+                // An arrow function's body is the value it returns
+                builder.flow.jump_to_exit();
+            }
+            Some(builder.flow.finish())
+        });
+
+        result.unwrap_or_else(CfgState::straight_line)
+    }
+}
+
+struct PhpCfgBuilderState {
+    flow: CfgState,
+}
+
+impl PhpCfgBuilderState {
+    /// Visit the statements of a `{ ... }` block or a `:` ... `endif;` block
+    fn build_from_block(&mut self, block: &Node, source: &str) {
+        let mut cursor = block.walk();
+        for child in block.named_children(&mut cursor) {
+            if !is_non_code(child) {
+                self.visit_node(&child, source);
+            }
+        }
+    }
+
+    fn visit_node(&mut self, node: &Node, source: &str) {
+        match node.kind() {
+            "if_statement" => self.visit_if(node, source),
+            "while_statement" | "for_statement" | "foreach_statement" => {
+                self.visit_loop(node, source)
+            }
+            "do_statement" => self.visit_do_while(node, source),
+            "switch_statement" => self.visit_switch(node, source),
+            "try_statement" => self.visit_try(node, source),
+            "return_statement" => self.flow.jump_to_exit(),
+            // `break 2` is treated as `break`
+            "break_statement" => self.flow.jump_to_break(),
+            "continue_statement" => self.flow.jump_to_continue(),
+            "compound_statement" | "colon_block" => self.build_from_block(node, source),
+            _ if is_terminator(*node) => self.flow.jump_to_exit(),
+            _ => self.flow.statement(),
+        }
+    }
+
+    /// Visit a statement body: a block, or a single statement as in
+    /// `if ($x) return 1;`
+    fn visit_body(&mut self, body: Option<Node>, source: &str) {
+        if let Some(body) = body {
+            self.visit_node(&body, source);
+        }
+    }
+
+    fn visit_branch(
+        &mut self,
+        from: NodeId,
+        body: Option<Node>,
+        join: &mut Option<NodeId>,
+        source: &str,
+    ) {
+        self.flow.start_branch(from);
+        self.visit_body(body, source);
+        self.flow.fall_through(join);
+    }
+
+    /// `if` with its `elseif` / `else` clauses, which are siblings of the
+    /// body rather than nested statements
+    fn visit_if(&mut self, node: &Node, source: &str) {
+        let mut cursor = node.walk();
+        let alternatives: Vec<Node> = node
+            .children_by_field_name("alternative", &mut cursor)
+            .collect();
+        self.visit_condition(node.child_by_field_name("body"), &alternatives, source);
+    }
+
+    /// One `if` or `elseif` test: `body` runs when it holds, otherwise the
+    /// first of `alternatives`
+    fn visit_condition(&mut self, body: Option<Node>, alternatives: &[Node], source: &str) {
+        let Some(condition_node) = self.flow.add_after(NodeKind::Condition) else {
+            return;
+        };
+
+        let mut join_node = None;
+        self.visit_branch(condition_node, body, &mut join_node, source);
+
+        match alternatives.split_first() {
+            Some((else_if, rest)) if else_if.kind() == "else_if_clause" => {
+                self.flow.start_branch(condition_node);
+                self.visit_condition(else_if.child_by_field_name("body"), rest, source);
+                self.flow.fall_through(&mut join_node);
+            }
+            Some((else_clause, _)) => self.visit_branch(
+                condition_node,
+                else_clause.child_by_field_name("body"),
+                &mut join_node,
+                source,
+            ),
+            None => self.flow.skip_branches(condition_node, &mut join_node),
+        }
+
+        self.flow.current_node = join_node;
+    }
+
+    /// `while`, `for`, and `foreach`
+    fn visit_loop(&mut self, node: &Node, source: &str) {
+        let Some(header) = self.flow.start_loop() else {
+            return;
+        };
+        self.visit_body(node.child_by_field_name("body"), source);
+        self.flow.end_loop(header);
+    }
+
+    /// `do { ... } while (...)`: the body runs before the condition
+    fn visit_do_while(&mut self, node: &Node, source: &str) {
+        let Some(body_and_header) = self.flow.start_post_test_loop() else {
+            return;
+        };
+        self.visit_body(node.child_by_field_name("body"), source);
+        self.flow.end_post_test_loop(body_and_header);
+    }
+
+    /// Each `case` / `default` is a branch. `break` leaves the switch, and a
+    /// case without one is treated as ending there rather than falling into
+    /// the next.
+    fn visit_switch(&mut self, node: &Node, source: &str) {
+        let Some(switch_node) = self.flow.add_after(NodeKind::Condition) else {
+            return;
+        };
+        self.flow
+            .cfg
+            .switches
+            .insert(node.start_byte(), switch_node);
+        self.flow.push_loop(switch_node);
+
+        if let Some(body) = node.child_by_field_name("body") {
+            let mut cursor = body.walk();
+            for case in body.named_children(&mut cursor) {
+                if !matches!(case.kind(), "case_statement" | "default_statement") {
+                    continue;
+                }
+                self.flow.start_branch(switch_node);
+
+                // The statements follow the `case` value
+                let value = case.child_by_field_name("value").map(|v| v.id());
+                let mut case_cursor = case.walk();
+                for stmt in case.named_children(&mut case_cursor) {
+                    if Some(stmt.id()) != value && !is_non_code(stmt) {
+                        self.visit_node(&stmt, source);
+                    }
+                }
+                self.flow.fall_through_to_break();
+            }
+        }
+
+        self.flow.end_switch(switch_node);
+    }
+
+    /// `try { ... } catch (...) { ... } finally { ... }`: the body and each
+    /// `catch` are branches; `finally` runs after whichever one completes
+    fn visit_try(&mut self, node: &Node, source: &str) {
+        let Some(try_node) = self.flow.add_after(NodeKind::Condition) else {
+            return;
+        };
+
+        let mut join_node = None;
+        self.visit_branch(
+            try_node,
+            node.child_by_field_name("body"),
+            &mut join_node,
+            source,
+        );
+
+        let mut cursor = node.walk();
+        for catch in node.named_children(&mut cursor) {
+            if catch.kind() == "catch_clause" {
+                self.visit_branch(
+                    try_node,
+                    catch.child_by_field_name("body"),
+                    &mut join_node,
+                    source,
+                );
+            }
+        }
+
+        self.flow.current_node = join_node;
+        if let Some(finally) = find_child_by_kind(*node, "finally_clause") {
+            self.visit_body(finally.child_by_field_name("body"), source);
+        }
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::language::parser::LanguageParser;
+    use crate::language::PhpParser;
+
+    /// CC of the first function in `source`
+    fn cc(source: &str) -> usize {
+        let module = PhpParser::new().unwrap().parse(source, "test.php").unwrap();
+        let function = module
+            .discover_functions(0, source)
+            .into_iter()
+            .next()
+            .expect("No function found in test source");
+        let cfg = PhpCfgBuilder.build(&function);
+        assert!(
+            cfg.validate().is_ok(),
+            "CFG must be valid: {:?}",
+            cfg.validate()
+        );
+        // CC = E - N + 2
+        (cfg.edge_count() as isize - cfg.node_count() as isize + 2).max(1) as usize
+    }
+
+    #[test]
+    fn test_simple_function() {
+        assert_eq!(cc("<?php function f($x) { return $x + 1; }"), 1);
+    }
+
+    #[test]
+    fn test_empty_function() {
+        assert_eq!(cc("<?php function f() {}"), 1);
+    }
+
+    #[test]
+    fn test_arrow_function() {
+        assert_eq!(cc("<?php $f = fn($x) => $x * 2;"), 1);
+    }
+
+    #[test]
+    fn test_elseif_chain_all_return() {
+        // No join node when every branch returns
+        let source = r#"<?php
+function sign($x) {
+    if ($x > 0) {
+        return 1;
+    } elseif ($x < 0) {
+        return -1;
+    } else {
+        return 0;
+    }
+}
+"#;
+        assert_eq!(cc(source), 3);
+    }
+
+    #[test]
+    fn test_else_if_with_space() {
+        // `else if` is an `else` whose statement is an `if`
+        let source = r#"<?php
+function sign($x) {
+    if ($x > 0) {
+        $s = 1;
+    } else if ($x < 0) {
+        $s = -1;
+    }
+    return $s;
+}
+"#;
+        assert_eq!(cc(source), 3);
+    }
+
+    #[test]
+    fn test_alternative_syntax() {
+        let source = r#"<?php
+function label($x) {
+    if ($x > 0):
+        $s = 'positive';
+    elseif ($x < 0):
+        $s = 'negative';
+    else:
+        $s = 'zero';
+    endif;
+    return $s;
+}
+"#;
+        assert_eq!(cc(source), 3);
+    }
+
+    #[test]
+    fn test_loops() {
+        let source = r#"<?php
+function loops(array $items) {
+    foreach ($items as $item) {
+        echo $item;
+    }
+    for ($i = 0; $i < 10; $i++) {
+        echo $i;
+    }
+    while ($i > 0) {
+        $i--;
+    }
+    do {
+        $i++;
+    } while ($i < 5);
+}
+"#;
+        assert_eq!(cc(source), 5);
+    }
+
+    #[test]
+    fn test_switch_counts_each_case() {
+        let source = r#"<?php
+function name($x) {
+    switch ($x) {
+        case 1:
+            return 'one';
+        case 2:
+        case 3:
+            return 'few';
+        default:
+            return 'many';
+    }
+}
+"#;
+        assert_eq!(cc(source), 5);
+    }
+
+    #[test]
+    fn test_try_counts_each_catch() {
+        let source = r#"<?php
+function load($path) {
+    try {
+        $data = read($path);
+    } catch (NotFound $e) {
+        $data = null;
+    } catch (Exception $e) {
+        throw $e;
+    } finally {
+        close($path);
+    }
+    return $data;
+}
+"#;
+        assert_eq!(cc(source), 3);
+    }
+
+    #[test]
+    fn test_break_and_continue_in_loop() {
+        let source = r#"<?php
+function scan(array $items) {
+    foreach ($items as $item) {
+        if ($item < 0) {
+            continue;
+        }
+        if ($item > 100) {
+            break;
+        }
+        echo $item;
+    }
+}
+"#;
+        assert_eq!(cc(source), 4);
+    }
+
+    #[test]
+    fn test_html_between_statements_is_ignored() {
+        let with_html = r#"<?php
+function row($item) {
+    if ($item) { ?>
+        <tr><td>item</td></tr>
+    <?php }
+}
+"#;
+        let without_html = r#"<?php
+function row($item) {
+    if ($item) {
+    }
+}
+"#;
+        assert_eq!(cc(with_html), cc(without_html));
+    }
+}
@@ -0,0 +1,38 @@
+//! PHP language support
+//!
+//! Parses PHP source files, including templates that mix in HTML, using the
+//! HTML-aware tree-sitter-php grammar.
+
+pub mod cfg_builder;
+pub mod parser;
+
+pub use cfg_builder::PhpCfgBuilder;
+pub use parser::PhpParser;
+
+use tree_sitter::Node;
+
+/// Node kinds that can be a discovered function
+pub(crate) const FUNCTION_KINDS: &[&str] = &[
+    "function_definition",
+    "method_declaration",
+    "anonymous_function",
+    "arrow_function",
+];
+
+/// Nodes inside a body that are not PHP statements: comments, and the HTML
+/// between `?>` and `<?php`
+pub(crate) fn is_non_code(node: Node<'_>) -> bool {
+    matches!(node.kind(), "comment" | "text" | "text_interpolation")
+}
+
+/// A statement that ends the function: `exit`, or a `throw` (an expression
+/// in PHP 8, so it sits inside an `expression_statement`)
+pub(crate) fn is_terminator(node: Node<'_>) -> bool {
+    match node.kind() {
+        "exit_statement" => true,
+        "expression_statement" => node
+            .named_child(0)
+            .is_some_and(|expr| expr.kind() == "throw_expression"),
+        _ => false,
+    }
+}
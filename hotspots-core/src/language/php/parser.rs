@@ -0,0 +1,337 @@
+//! PHP language parser using tree-sitter
+
+use crate::ast::FunctionNode;
+use crate::language::parser::{LanguageParser, ParsedModule};
+use crate::language::php::FUNCTION_KINDS;
+use crate::language::tree_sitter_utils::syntax_errors;
+use anyhow::{Context, Result};
+use tree_sitter::{Node, Parser, Tree};
+
+/// PHP parser using tree-sitter
+pub struct PhpParser;
+
+impl PhpParser {
+    /// Create a new PHP parser
+    pub fn new() -> Result<Self> {
+        let mut parser = Parser::new();
+        let language = tree_sitter_php::LANGUAGE_PHP;
+        parser
+            .set_language(&language.into())
+            .context("Failed to set PHP language for parser")?;
+        Ok(PhpParser)
+    }
+}
+
+impl Default for PhpParser {
+    fn default() -> Self {
+        Self::new().expect("Failed to create PHP parser")
+    }
+}
+
+impl LanguageParser for PhpParser {
+    fn parse(&self, source: &str, filename: &str) -> Result<Box<dyn ParsedModule>> {
+        let mut parser = Parser::new();
+        let language = tree_sitter_php::LANGUAGE_PHP;
+        parser
+            .set_language(&language.into())
+            .context("Failed to set PHP language")?;
+
+        let tree = parser
+            .parse(source, None)
+            .ok_or_else(|| anyhow::anyhow!("Failed to parse PHP file: {}", filename))?;
+
+        Ok(Box::new(PhpModule {
+            tree,
+            source: source.to_string(),
+        }))
+    }
+}
+
+/// Parsed PHP module
+struct PhpModule {
+    tree: Tree,
+    source: String,
+}
+
+impl ParsedModule for PhpModule {
+    fn discover_functions(&self, file_index: usize, _source: &str) -> Vec<FunctionNode> {
+        let root = self.tree.root_node();
+        let mut functions = Vec::new();
+        discover_functions_recursive(root, &self.source, file_index, &mut functions);
+        functions.sort_by_key(|f| f.span.start);
+        functions
+    }
+
+    fn syntax_errors(&self) -> Vec<std::ops::Range<usize>> {
+        syntax_errors(self.tree.root_node())
+    }
+
+    fn top_level_decisions(&self) -> usize {
+        crate::metrics::php_top_level_decisions(&self.tree.root_node())
+    }
+}
+
+/// Recursively discover functions in the PHP AST. Class, trait, and enum
+/// bodies and function bodies are walked like any other node, so methods,
+/// nested functions, and closures are all found.
+fn discover_functions_recursive(
+    node: Node,
+    source: &str,
+    file_index: usize,
+    functions: &mut Vec<FunctionNode>,
+) {
+    if FUNCTION_KINDS.contains(&node.kind()) {
+        if let Some(function_node) = extract_function(node, source, file_index, functions.len()) {
+            functions.push(function_node);
+        }
+    }
+
+    let mut cursor = node.walk();
+    for child in node.children(&mut cursor) {
+        discover_functions_recursive(child, source, file_index, functions);
+    }
+}
+
+/// Extract a FunctionNode from a function, method, closure, or arrow function
+fn extract_function(
+    node: Node,
+    source: &str,
+    file_index: usize,
+    local_index: usize,
+) -> Option<FunctionNode> {
+    use crate::ast::FunctionId;
+    use crate::language::{FunctionBody, SourceSpan};
+
+    // Abstract and interface methods have no body
+    let body_node = node.child_by_field_name("body")?;
+
+    let (name, is_public) = match node.kind() {
+        "function_definition" => (field_text(node, "name", source), true),
+        "method_declaration" => (
+            field_text(node, "name", source),
+            is_public_method(node, source),
+        ),
+        _ => (bound_name(node, source), false),
+    };
+
+    let span = SourceSpan::new(
+        node.start_byte(),
+        node.end_byte(),
+        node.start_position().row as u32 + 1, // tree-sitter uses 0-indexed rows
+        node.end_position().row as u32 + 1,   // tree-sitter uses 0-indexed rows
+        SourceSpan::column_of(source, node.start_byte()),
+    );
+
+    let body = FunctionBody::Php {
+        body_node: body_node.id(),
+        source: source.to_string(),
+    };
+
+    Some(FunctionNode {
+        id: FunctionId {
+            file_index,
+            local_index,
+        },
+        name,
+        owner: None,
+        span,
+        body,
+        suppression_reason: None, // Will be extracted separately
+        signature_complexity: 0,
+        params: crate::params::php_params(node),
+        is_public,
+        is_async: false,
+    })
+}
+
+fn field_text(node: Node, field: &str, source: &str) -> Option<String> {
+    let child = node.child_by_field_name(field)?;
+    Some(source[child.start_byte()..child.end_byte()].to_string())
+}
+
+/// Name a closure or arrow function after the variable it is assigned to
+/// (`$handler = function () { ... }` → `handler`); others stay anonymous
+fn bound_name(node: Node, source: &str) -> Option<String> {
+    let parent = node.parent()?;
+    if parent.kind() != "assignment_expression" {
+        return None;
+    }
+    let left = parent.child_by_field_name("left")?;
+    if left.kind() != "variable_name" {
+        return None;
+    }
+    let name = &source[left.start_byte()..left.end_byte()];
+    Some(name.trim_start_matches('$').to_string())
+}
+
+/// Methods are public unless declared `private` or `protected`; PHP's
+/// default visibility is `public`
+fn is_public_method(node: Node, source: &str) -> bool {
+    let mut cursor = node.walk();
+    let hidden = node
+        .children(&mut cursor)
+        .filter(|child| child.kind() == "visibility_modifier")
+        .any(|modifier| {
+            let text = &source[modifier.start_byte()..modifier.end_byte()];
+            text.eq_ignore_ascii_case("private") || text.eq_ignore_ascii_case("protected")
+        });
+    !hidden
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    fn discover(source: &str) -> Vec<FunctionNode> {
+        let parser = PhpParser::new().unwrap();
+        let module = parser.parse(source, "test.php").unwrap();
+        module.discover_functions(0, source)
+    }
+
+    fn names(functions: &[FunctionNode]) -> Vec<&str> {
+        functions
+            .iter()
+            .map(|f| f.name.as_deref().unwrap_or(""))
+            .collect()
+    }
+
+    #[test]
+    fn test_create_parser() {
+        assert!(PhpParser::new().is_ok());
+    }
+
+    #[test]
+    fn test_parse_top_level_function() {
+        let functions = discover(
+            r#"<?php
+
+function add($a, $b)
+{
+    return $a + $b;
+}
+"#,
+        );
+        assert_eq!(names(&functions), vec!["add"]);
+        assert_eq!(functions[0].span.start_line, 3);
+    }
+
+    #[test]
+    fn test_parse_class_members() {
+        let functions = discover(
+            r#"<?php
+
+abstract class Account
+{
+    public function __construct(private int $balance) {}
+
+    public function deposit(int $amount): void
+    {
+        $this->balance += $amount;
+    }
+
+    abstract protected function audit(): void;
+}
+
+trait Loggable
+{
+    public function log(string $message): void
+    {
+        error_log($message);
+    }
+}
+
+interface Shape
+{
+    public function area(): float;
+}
+"#,
+        );
+        // Abstract and interface methods have no body
+        assert_eq!(names(&functions), vec!["__construct", "deposit", "log"]);
+    }
+
+    #[test]
+    fn test_parse_closures_and_arrow_functions() {
+        let functions = discover(
+            r#"<?php
+
+$double = fn($x) => $x * 2;
+
+$handler = function ($request) use ($app) {
+    return $app->handle($request);
+};
+
+array_map(function ($item) {
+    return $item->id;
+}, $items);
+"#,
+        );
+        assert_eq!(names(&functions), vec!["double", "handler", ""]);
+        assert!(functions[2].name.is_none());
+    }
+
+    #[test]
+    fn test_parse_mixed_html() {
+        let functions = discover(
+            r#"<!DOCTYPE html>
+<html>
+<body>
+<?php
+function greeting($name)
+{
+    return "Hello, " . $name;
+}
+?>
+<h1><?= greeting("world") ?></h1>
+<?php foreach ($rows as $row): ?>
+  <p><?= $row ?></p>
+<?php endforeach; ?>
+</body>
+</html>
+"#,
+        );
+        assert_eq!(names(&functions), vec!["greeting"]);
+        assert_eq!(functions[0].span.start_line, 5);
+    }
+
+    #[test]
+    fn test_parse_visibility() {
+        let functions = discover(
+            r#"<?php
+
+function helper() {}
+
+class Service
+{
+    function implicitlyPublic() {}
+    public static function create() {}
+    protected function hook() {}
+    private function secret() {}
+}
+
+$callback = function () {};
+"#,
+        );
+        let public: Vec<(&str, bool)> = functions
+            .iter()
+            .map(|f| (f.name.as_deref().unwrap(), f.is_public))
+            .collect();
+        assert_eq!(
+            public,
+            vec![
+                ("helper", true),
+                ("implicitlyPublic", true),
+                ("create", true),
+                ("hook", false),
+                ("secret", false),
+                ("callback", false),
+            ]
+        );
+    }
+
+    #[test]
+    fn test_parse_empty_file() {
+        assert!(discover("").is_empty());
+        assert!(discover("<p>No PHP here</p>\n").is_empty());
+    }
+}
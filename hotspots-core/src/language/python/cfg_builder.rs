@@ -531,6 +531,7 @@ mod tests {
                 local_index: 0,
             },
             name: Some("test_func".to_string()),
+            owner: None,
             span: SourceSpan::new(
                 start_byte,
                 func_node.end_byte(),
@@ -543,6 +544,10 @@ mod tests {
                 source: source.to_string(),
             },
             suppression_reason: None,
+            signature_complexity: 0,
+            params: 0,
+            is_public: false,
+            is_async: false,
         }
     }
 
@@ -2,7 +2,7 @@
 
 use crate::ast::FunctionNode;
 use crate::language::parser::{LanguageParser, ParsedModule};
-use crate::language::tree_sitter_utils::find_child_by_kind;
+use crate::language::tree_sitter_utils::{find_child_by_kind, syntax_errors};
 use anyhow::{Context, Result};
 use tree_sitter::{Node, Parser, Tree};
 
@@ -68,6 +68,14 @@ impl ParsedModule for PythonModule {
 
         functions
     }
+
+    fn syntax_errors(&self) -> Vec<std::ops::Range<usize>> {
+        syntax_errors(self.tree.root_node())
+    }
+
+    fn top_level_decisions(&self) -> usize {
+        crate::metrics::python_top_level_decisions(&self.tree.root_node())
+    }
 }
 
 /// Recursively discover function declarations in the Python AST
@@ -104,6 +112,8 @@ fn extract_function(
 
     // Get function name
     let name = extract_function_name(node, source);
+    let owner = enclosing_class_name(node, source);
+    let is_public = name.as_deref().is_some_and(is_public_name) && in_public_scope(node, source);
 
     // Get function body (block node)
     let body_node = find_child_by_kind(node, "block")?;
@@ -114,7 +124,7 @@ fn extract_function(
         node.end_byte(),
         node.start_position().row as u32 + 1, // tree-sitter uses 0-indexed rows
         node.end_position().row as u32 + 1,   // tree-sitter uses 0-indexed rows
-        node.start_position().column as u32,
+        SourceSpan::column_of(source, node.start_byte()),
     );
 
     // Create FunctionBody::Python variant
@@ -129,12 +139,61 @@ fn extract_function(
             local_index,
         },
         name,
+        owner,
         span,
         body,
         suppression_reason: None, // Will be extracted separately
+        signature_complexity: 0,
+        params: crate::params::python_params(node, source),
+        is_public,
+        is_async: false,
     })
 }
 
+/// Names without a leading underscore are public; dunder methods such as
+/// `__init__` are part of the class protocol and count as public too
+fn is_public_name(name: &str) -> bool {
+    !name.starts_with('_') || (name.len() > 4 && name.starts_with("__") && name.ends_with("__"))
+}
+
+/// True unless the definition is nested in a function or in a class with a
+/// private name
+fn in_public_scope(node: Node, source: &str) -> bool {
+    let mut current = node.parent();
+    while let Some(ancestor) = current {
+        match ancestor.kind() {
+            "function_definition" | "async_function_definition" => return false,
+            "class_definition" => {
+                let class_name = find_child_by_kind(ancestor, "identifier")
+                    .map(|n| &source[n.start_byte()..n.end_byte()]);
+                if !class_name.is_some_and(is_public_name) {
+                    return false;
+                }
+            }
+            _ => {}
+        }
+        current = ancestor.parent();
+    }
+    true
+}
+
+/// The class a method is defined in, decorated or not; None for functions
+/// nested in another function, even inside a class
+fn enclosing_class_name(node: Node, source: &str) -> Option<String> {
+    let mut current = node.parent();
+    while let Some(ancestor) = current {
+        match ancestor.kind() {
+            "function_definition" | "async_function_definition" => return None,
+            "class_definition" => {
+                let name = find_child_by_kind(ancestor, "identifier")?;
+                return Some(source[name.start_byte()..name.end_byte()].to_string());
+            }
+            _ => current = ancestor.parent(),
+        }
+    }
+    None
+}
+
 /// Extract function name from a function_definition or async_function_definition node
 fn extract_function_name(node: Node, source: &str) -> Option<String> {
     // Python function definitions have an "identifier" child for the function name
@@ -205,6 +264,43 @@ class MyClass:
         assert_eq!(functions[1].name, Some("method_two".to_string()));
     }
 
+    #[test]
+    fn test_parse_method_owners() {
+        let parser = PythonParser::new().unwrap();
+        let source = r#"
+class Outer:
+    @staticmethod
+    def build():
+        def helper():
+            return 1
+        return helper()
+
+    class Inner:
+        def run(self):
+            return 2
+
+def free():
+    return 3
+"#;
+        let module = parser.parse(source, "test.py");
+        assert!(module.is_ok());
+
+        let functions = module.unwrap().discover_functions(0, source);
+        let owners: Vec<(&str, Option<&str>)> = functions
+            .iter()
+            .map(|f| (f.name.as_deref().unwrap(), f.owner.as_deref()))
+            .collect();
+        assert_eq!(
+            owners,
+            vec![
+                ("build", Some("Outer")),
+                ("helper", None),
+                ("run", Some("Inner")),
+                ("free", None),
+            ]
+        );
+    }
+
     #[test]
     fn test_parse_nested_functions() {
         let parser = PythonParser::new().unwrap();
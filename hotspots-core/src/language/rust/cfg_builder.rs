@@ -259,11 +259,16 @@ mod tests {
                 local_index: 0,
             },
             name: Some("test".to_string()),
+            owner: None,
             span: SourceSpan::new(0, source.len(), 1, 1, 0),
             body: FunctionBody::Rust {
                 source: source.to_string(),
             },
             suppression_reason: None,
+            signature_complexity: 0,
+            params: 0,
+            is_public: false,
+            is_async: false,
         }
     }
 
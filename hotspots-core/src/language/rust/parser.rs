@@ -6,7 +6,7 @@ use crate::language::parser::{LanguageParser, ParsedModule};
 use crate::language::span::SourceSpan;
 use anyhow::{Context, Result};
 use syn::spanned::Spanned;
-use syn::{File, ImplItem, ImplItemFn, Item, ItemFn, Signature};
+use syn::{File, ImplItem, ImplItemFn, Item, ItemFn, Signature, Visibility};
 
 /// Rust parser using syn
 pub struct RustParser;
@@ -20,6 +20,30 @@ impl LanguageParser for RustParser {
     }
 }
 
+/// Free functions and methods: both carry a visibility and a signature
+trait FnItem: Spanned {
+    fn vis(&self) -> &Visibility;
+    fn sig(&self) -> &Signature;
+}
+
+impl FnItem for ItemFn {
+    fn vis(&self) -> &Visibility {
+        &self.vis
+    }
+    fn sig(&self) -> &Signature {
+        &self.sig
+    }
+}
+
+impl FnItem for ImplItemFn {
+    fn vis(&self) -> &Visibility {
+        &self.vis
+    }
+    fn sig(&self) -> &Signature {
+        &self.sig
+    }
+}
+
 /// Parsed Rust module
 struct RustModule {
     file: File,
@@ -72,12 +96,17 @@ impl RustModule {
                     None
                 };
 
+                // Trait impl methods take the trait's visibility and carry
+                // no `pub` of their own
+                let trait_impl = item_impl.trait_.is_some();
+
                 // Visit methods in impl block
                 for impl_item in &item_impl.items {
                     if let ImplItem::Fn(method) = impl_item {
                         self.extract_impl_fn(
                             method,
                             type_name.as_deref(),
+                            trait_impl,
                             file_index,
                             local_index,
                             functions,
@@ -100,10 +129,11 @@ impl RustModule {
         local_index: &mut usize,
         functions: &mut Vec<FunctionNode>,
     ) {
+        let is_public = matches!(item_fn.vis, Visibility::Public(_));
         self.extract_function_common(
-            &item_fn.sig,
             item_fn,
             name_prefix,
+            is_public,
             file_index,
             local_index,
             functions,
@@ -115,14 +145,16 @@ impl RustModule {
         &self,
         impl_fn: &ImplItemFn,
         name_prefix: Option<&str>,
+        trait_impl: bool,
         file_index: usize,
         local_index: &mut usize,
         functions: &mut Vec<FunctionNode>,
     ) {
+        let is_public = trait_impl || matches!(impl_fn.vis, Visibility::Public(_));
         self.extract_function_common(
-            &impl_fn.sig,
             impl_fn,
             name_prefix,
+            is_public,
             file_index,
             local_index,
             functions,
@@ -130,15 +162,22 @@ impl RustModule {
     }
 
     /// Common extraction logic for both functions and methods
-    fn extract_function_common<S: Spanned>(
+    ///
+    /// The span covers the whole item, outer attributes and doc comments
+    /// included, so `// hotspots-ignore` above an attribute still applies. The
+    /// body source handed to metrics starts at the visibility or `fn` keyword
+    /// instead: attributes such as `#[derive(...)]` or `#[instrument(...)]`
+    /// are not code the author wrote in the function, and must not add LOC.
+    fn extract_function_common<S: FnItem>(
         &self,
-        sig: &Signature,
         item: &S,
         name_prefix: Option<&str>,
+        is_public: bool,
         file_index: usize,
         local_index: &mut usize,
         functions: &mut Vec<FunctionNode>,
     ) {
+        let sig = item.sig();
         let name = if let Some(prefix) = name_prefix {
             format!("{}::{}", prefix, sig.ident)
         } else {
@@ -154,9 +193,18 @@ impl RustModule {
         let start_byte = self.line_column_to_byte(span_start.line, span_start.column);
         let end_byte = self.line_column_to_byte(span_end.line, span_end.column);
 
+        // Skip outer attributes: start at `pub ...` if present, else at the signature
+        let head = match item.vis() {
+            Visibility::Inherited => sig.span().start(),
+            vis => vis.span().start(),
+        };
+        let body_start = self
+            .line_column_to_byte(head.line, head.column)
+            .max(start_byte);
+
         // Extract source for the function body
-        let body_source = if start_byte < self.source.len() && end_byte <= self.source.len() {
-            self.source[start_byte..end_byte].to_string()
+        let body_source = if body_start < self.source.len() && end_byte <= self.source.len() {
+            self.source[body_start..end_byte].to_string()
         } else {
             // Fallback - use the whole function as a string
             format!("fn {}() {{}}", sig.ident)
@@ -176,11 +224,16 @@ impl RustModule {
                 local_index: *local_index,
             },
             name: Some(name),
+            owner: name_prefix.map(str::to_string),
             span,
             body: FunctionBody::Rust {
                 source: body_source,
             },
             suppression_reason: None,
+            signature_complexity: 0,
+            params: crate::params::rust_params(sig),
+            is_public,
+            is_async: false,
         });
 
         *local_index += 1;
@@ -294,6 +347,7 @@ impl Calculator {
         assert_eq!(functions.len(), 2);
         assert_eq!(functions[0].name, Some("Calculator::new".to_string()));
         assert_eq!(functions[1].name, Some("Calculator::add".to_string()));
+        assert_eq!(functions[1].owner, Some("Calculator".to_string()));
     }
 
     #[test]
@@ -345,6 +399,29 @@ fn second() {}
         assert_eq!(functions[2].name, Some("second".to_string()));
     }
 
+    #[test]
+    fn test_rust_parser_body_excludes_outer_attributes() {
+        let source = r#"
+/// Doc comment
+#[inline]
+#[cfg_attr(feature = "tracing", tracing::instrument)]
+pub fn attributed() {
+    let x = 1;
+}
+"#;
+
+        let parser = RustParser;
+        let module = parser.parse(source, "test.rs").unwrap();
+        let functions = module.discover_functions(0, source);
+
+        assert_eq!(functions.len(), 1);
+        // Span still starts at the doc comment so suppression comments above it apply
+        assert_eq!(functions[0].span.start_line, 2);
+        let body = functions[0].body.as_rust();
+        assert!(body.starts_with("pub fn attributed()"), "body: {body}");
+        assert!(!body.contains("#["));
+    }
+
     #[test]
     fn test_rust_parser_empty_file() {
         let source = "";
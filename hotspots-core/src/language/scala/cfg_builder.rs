@@ -0,0 +1,348 @@
+//! Scala CFG builder implementation
+
+use crate::ast::FunctionNode;
+use crate::cfg::{Cfg, NodeId, NodeKind};
+use crate::language::cfg_builder::{CfgBuilder, CfgState};
+use crate::language::scala::{
+    block_statements, body_statements, case_clauses, is_nested_definition, statements_after_arrow,
+    FUNCTION_KINDS,
+};
+use crate::language::tree_sitter_utils::{
+    find_child_by_kind, find_function_by_start, with_cached_scala_tree,
+};
+use tree_sitter::Node;
+
+/// Expressions that branch, loop, or leave the function
+const CONTROL_KINDS: &[&str] = &[
+    "if_expression",
+    "match_expression",
+    "for_expression",
+    "while_expression",
+    "do_while_expression",
+    "try_expression",
+    "return_expression",
+    "throw_expression",
+];
+
+/// Scala CFG builder
+pub struct ScalaCfgBuilder;
+
+impl CfgBuilder for ScalaCfgBuilder {
+    fn build(&self, function: &FunctionNode) -> Cfg {
+        let (_body_node_id, source) = function.body.as_scala();
+
+        let result = with_cached_scala_tree(source, |root| {
+            let func_node = find_function_by_start(root, function.span.start, FUNCTION_KINDS)?;
+            let mut builder = ScalaCfgBuilderState {
+                flow: CfgState::new(),
+            };
+            builder.visit_statements(&body_statements(func_node));
+            Some(builder.flow.finish())
+        });
+
+        result.unwrap_or_else(CfgState::straight_line)
+    }
+}
+
+struct ScalaCfgBuilderState {
+    flow: CfgState,
+}
+
+impl ScalaCfgBuilderState {
+    fn visit_statements(&mut self, statements: &[Node]) {
+        for stmt in statements {
+            self.visit_node(stmt);
+        }
+    }
+
+    fn visit_node(&mut self, node: &Node) {
+        match node.kind() {
+            "if_expression" => self.visit_if(node),
+            "match_expression" => self.visit_match(node),
+            "for_expression" | "while_expression" => self.visit_loop(node),
+            "do_while_expression" => self.visit_do_while(node),
+            "try_expression" => self.visit_try(node),
+            "return_expression" => {
+                // `return x match { ... }` branches before it returns
+                self.visit_nested(node);
+                self.flow.jump_to_exit();
+            }
+            "throw_expression" => self.flow.jump_to_exit(),
+            "block" | "indented_block" => self.visit_statements(&block_statements(*node)),
+            _ if is_nested_definition(*node) => self.flow.statement(),
+            _ => {
+                if !self.visit_nested(node) {
+                    self.flow.statement();
+                }
+            }
+        }
+    }
+
+    /// Visit the control expressions inside `node`, in source order, without
+    /// entering nested definitions: control flow is made of expressions, so
+    /// a statement such as `val label = x match { ... }` holds a branch.
+    /// Returns whether there were any.
+    fn visit_nested(&mut self, node: &Node) -> bool {
+        let mut found = false;
+        let mut cursor = node.walk();
+        for child in node.named_children(&mut cursor) {
+            if CONTROL_KINDS.contains(&child.kind()) {
+                self.visit_node(&child);
+                found = true;
+            } else if !is_nested_definition(child) {
+                found |= self.visit_nested(&child);
+            }
+        }
+        found
+    }
+
+    fn visit_branch(&mut self, from: NodeId, statements: &[Node], join: &mut Option<NodeId>) {
+        self.flow.start_branch(from);
+        self.visit_statements(statements);
+        self.flow.fall_through(join);
+    }
+
+    /// `if` / `else`; an `else if` is an `if` inside the `else` branch
+    fn visit_if(&mut self, node: &Node) {
+        let Some(condition_node) = self.flow.add_after(NodeKind::Condition) else {
+            return;
+        };
+
+        let mut join_node = None;
+        let consequence = node
+            .child_by_field_name("consequence")
+            .map(block_statements)
+            .unwrap_or_default();
+        self.visit_branch(condition_node, &consequence, &mut join_node);
+
+        match node.child_by_field_name("alternative") {
+            Some(alternative) => self.visit_branch(
+                condition_node,
+                &block_statements(alternative),
+                &mut join_node,
+            ),
+            None => self.flow.skip_branches(condition_node, &mut join_node),
+        }
+
+        self.flow.current_node = join_node;
+    }
+
+    /// Each `case` is a branch. A `match` that no case fits throws a
+    /// `MatchError` rather than choosing a path, but the match node still
+    /// gets an edge to the join so that every case adds one to CC.
+    fn visit_match(&mut self, node: &Node) {
+        let Some(match_node) = self.flow.add_after(NodeKind::Condition) else {
+            return;
+        };
+
+        let mut join_node = None;
+        for case in case_clauses(*node) {
+            self.visit_branch(match_node, &statements_after_arrow(case), &mut join_node);
+        }
+
+        self.flow.skip_branches(match_node, &mut join_node);
+        self.flow.current_node = join_node;
+    }
+
+    /// `while` and `for` (loops and comprehensions alike)
+    fn visit_loop(&mut self, node: &Node) {
+        let Some(header) = self.flow.start_loop() else {
+            return;
+        };
+        if let Some(body) = node.child_by_field_name("body") {
+            self.visit_statements(&block_statements(body));
+        }
+        self.flow.end_loop(header);
+    }
+
+    /// Scala 2 `do { ... } while (...)`: the body runs before the condition
+    fn visit_do_while(&mut self, node: &Node) {
+        let Some(body_and_header) = self.flow.start_post_test_loop() else {
+            return;
+        };
+        if let Some(body) = node.child_by_field_name("body") {
+            self.visit_statements(&block_statements(body));
+        }
+        self.flow.end_post_test_loop(body_and_header);
+    }
+
+    /// `try { ... } catch { case ... } finally { ... }`: the body and each
+    /// `catch` case are branches; `finally` runs after whichever one completes
+    fn visit_try(&mut self, node: &Node) {
+        let Some(try_node) = self.flow.add_after(NodeKind::Condition) else {
+            return;
+        };
+
+        let mut join_node = None;
+        let body = node
+            .child_by_field_name("body")
+            .map(block_statements)
+            .unwrap_or_default();
+        self.visit_branch(try_node, &body, &mut join_node);
+
+        if let Some(catch) = find_child_by_kind(*node, "catch_clause") {
+            let cases = case_clauses(catch);
+            if cases.is_empty() {
+                // A handler expression (`catch handler`) is one branch
+                let mut cursor = catch.walk();
+                let handler: Vec<Node> = catch.named_children(&mut cursor).collect();
+                self.visit_branch(try_node, &handler, &mut join_node);
+            }
+            for case in cases {
+                self.visit_branch(try_node, &statements_after_arrow(case), &mut join_node);
+            }
+        }
+
+        self.flow.current_node = join_node;
+        if let Some(finally) = find_child_by_kind(*node, "finally_clause") {
+            let mut cursor = finally.walk();
+            let statements: Vec<Node> = finally
+                .named_children(&mut cursor)
+                .flat_map(block_statements)
+                .collect();
+            self.visit_statements(&statements);
+        }
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::language::parser::LanguageParser;
+    use crate::language::ScalaParser;
+
+    /// CC of the first function in `source`
+    fn cc(source: &str) -> usize {
+        let module = ScalaParser::new()
+            .unwrap()
+            .parse(source, "test.scala")
+            .unwrap();
+        let function = module
+            .discover_functions(0, source)
+            .into_iter()
+            .next()
+            .expect("No function found in test source");
+        let cfg = ScalaCfgBuilder.build(&function);
+        assert!(
+            cfg.validate().is_ok(),
+            "CFG must be valid: {:?}",
+            cfg.validate()
+        );
+        // CC = E - N + 2
+        (cfg.edge_count() as isize - cfg.node_count() as isize + 2).max(1) as usize
+    }
+
+    #[test]
+    fn test_simple_function() {
+        assert_eq!(cc("def f(x: Int): Int = x + 1"), 1);
+    }
+
+    #[test]
+    fn test_bound_lambda() {
+        assert_eq!(cc("val f = (x: Int) => if (x > 0) x else -x"), 2);
+    }
+
+    #[test]
+    fn test_else_if_chain() {
+        let source = r#"
+def sign(x: Int): Int = {
+  if (x > 0) {
+    1
+  } else if (x < 0) {
+    -1
+  } else {
+    0
+  }
+}
+"#;
+        assert_eq!(cc(source), 3);
+    }
+
+    #[test]
+    fn test_braces_and_indentation_agree() {
+        let braces = r#"
+def label(x: Int): String = {
+  val s = if (x > 0) {
+    "positive"
+  } else {
+    "other"
+  }
+  s.trim
+}
+"#;
+        let indented = r#"
+def label(x: Int): String =
+  val s =
+    if x > 0 then
+      "positive"
+    else
+      "other"
+  s.trim
+"#;
+        assert_eq!(cc(braces), 2);
+        assert_eq!(cc(indented), 2);
+    }
+
+    #[test]
+    fn test_match_counts_each_case() {
+        let source = r#"
+def name(x: Int): String = x match {
+  case 1 => "one"
+  case 2 | 3 => "few"
+  case _ => "many"
+}
+"#;
+        assert_eq!(cc(source), 4);
+    }
+
+    #[test]
+    fn test_loops() {
+        let source = r#"
+def loops(items: List[Int]): Unit = {
+  for (item <- items) {
+    println(item)
+  }
+  var i = 0
+  while (i < 10) {
+    i += 1
+  }
+  do {
+    i -= 1
+  } while (i > 0)
+}
+"#;
+        assert_eq!(cc(source), 4);
+    }
+
+    #[test]
+    fn test_try_counts_each_catch_case() {
+        let source = r#"
+def load(path: String): String = {
+  try {
+    read(path)
+  } catch {
+    case _: java.io.FileNotFoundException => ""
+    case e: Exception => throw e
+  } finally {
+    close(path)
+  }
+}
+"#;
+        assert_eq!(cc(source), 3);
+    }
+
+    #[test]
+    fn test_nested_definitions_are_not_entered() {
+        let source = r#"
+def outer(xs: List[Int]): Int = {
+  def inner(x: Int): Int = if (x > 0) x else 0
+  val pick = (x: Int) => x match {
+    case 0 => 1
+    case _ => 2
+  }
+  xs.map(inner).sum
+}
+"#;
+        assert_eq!(cc(source), 1);
+    }
+}
@@ -0,0 +1,97 @@
+//! Scala language support
+//!
+//! Parses Scala 2 and Scala 3 source files, with braces or significant
+//! indentation, using tree-sitter-scala.
+
+pub mod cfg_builder;
+pub mod parser;
+
+pub use cfg_builder::ScalaCfgBuilder;
+pub use parser::ScalaParser;
+
+use tree_sitter::Node;
+
+/// Node kinds that can be a discovered function
+pub(crate) const FUNCTION_KINDS: &[&str] = &["function_definition", "lambda_expression"];
+
+/// `{ ... }` blocks and Scala 3 indented blocks
+pub(crate) const BLOCK_KINDS: &[&str] = &["block", "indented_block"];
+
+/// Whether `node` is a lambda bound to a `val` or `var`, which is discovered
+/// as a function of its own
+pub(crate) fn is_bound_lambda(node: Node<'_>) -> bool {
+    node.kind() == "lambda_expression"
+        && node
+            .parent()
+            .is_some_and(|parent| matches!(parent.kind(), "val_definition" | "var_definition"))
+}
+
+/// Definitions whose bodies are not part of the enclosing function: nested
+/// `def`s and bound lambdas (functions of their own), and local classes,
+/// objects, and traits
+pub(crate) fn is_nested_definition(node: Node<'_>) -> bool {
+    matches!(
+        node.kind(),
+        "function_definition" | "class_definition" | "object_definition" | "trait_definition"
+    ) || is_bound_lambda(node)
+}
+
+/// Statements of a block, or the expression itself for a body without braces
+/// or indentation (`def f = x + 1`)
+pub(crate) fn block_statements(node: Node<'_>) -> Vec<Node<'_>> {
+    if !BLOCK_KINDS.contains(&node.kind()) {
+        return vec![node];
+    }
+    let mut cursor = node.walk();
+    let statements = node
+        .named_children(&mut cursor)
+        .filter(|child| !child.kind().contains("comment"))
+        .collect();
+    statements
+}
+
+/// Statements after the `=>` of a lambda or a `case` clause
+pub(crate) fn statements_after_arrow(node: Node<'_>) -> Vec<Node<'_>> {
+    let mut statements = Vec::new();
+    let mut after_arrow = false;
+    let mut cursor = node.walk();
+    for child in node.children(&mut cursor) {
+        if child.kind() == "=>" {
+            after_arrow = true;
+        } else if after_arrow && child.is_named() && !child.kind().contains("comment") {
+            statements.extend(block_statements(child));
+        }
+    }
+    statements
+}
+
+/// Statements of a discovered function: the `def` body, or what follows a
+/// lambda's `=>`
+pub(crate) fn body_statements(func_node: Node<'_>) -> Vec<Node<'_>> {
+    match func_node.child_by_field_name("body") {
+        Some(body) => block_statements(body),
+        None => statements_after_arrow(func_node),
+    }
+}
+
+/// The node metrics are computed under: a `def`'s body, or the whole lambda
+pub(crate) fn body_root(func_node: Node<'_>) -> Option<Node<'_>> {
+    match func_node.kind() {
+        "lambda_expression" => Some(func_node),
+        _ => func_node.child_by_field_name("body"),
+    }
+}
+
+/// `case` clauses of a `match` or `catch`, in `{ ... }` or indented form
+pub(crate) fn case_clauses(node: Node<'_>) -> Vec<Node<'_>> {
+    let mut clauses = Vec::new();
+    let mut cursor = node.walk();
+    for child in node.named_children(&mut cursor) {
+        match child.kind() {
+            "case_clause" => clauses.push(child),
+            "case_block" | "indented_cases" => clauses.extend(case_clauses(child)),
+            _ => {}
+        }
+    }
+    clauses
+}
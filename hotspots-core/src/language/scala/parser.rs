@@ -0,0 +1,328 @@
+//! Scala language parser using tree-sitter
+
+use crate::ast::FunctionNode;
+use crate::language::parser::{LanguageParser, ParsedModule};
+use crate::language::scala::{body_root, is_bound_lambda};
+use crate::language::tree_sitter_utils::{find_child_by_kind, syntax_errors};
+use anyhow::{Context, Result};
+use tree_sitter::{Node, Parser, Tree};
+
+/// Scala parser using tree-sitter
+pub struct ScalaParser;
+
+impl ScalaParser {
+    /// Create a new Scala parser
+    pub fn new() -> Result<Self> {
+        let mut parser = Parser::new();
+        let language = tree_sitter_scala::LANGUAGE;
+        parser
+            .set_language(&language.into())
+            .context("Failed to set Scala language for parser")?;
+        Ok(ScalaParser)
+    }
+}
+
+impl Default for ScalaParser {
+    fn default() -> Self {
+        Self::new().expect("Failed to create Scala parser")
+    }
+}
+
+impl LanguageParser for ScalaParser {
+    fn parse(&self, source: &str, filename: &str) -> Result<Box<dyn ParsedModule>> {
+        let mut parser = Parser::new();
+        let language = tree_sitter_scala::LANGUAGE;
+        parser
+            .set_language(&language.into())
+            .context("Failed to set Scala language")?;
+
+        let tree = parser
+            .parse(source, None)
+            .ok_or_else(|| anyhow::anyhow!("Failed to parse Scala file: {}", filename))?;
+
+        Ok(Box::new(ScalaModule {
+            tree,
+            source: source.to_string(),
+        }))
+    }
+}
+
+/// Parsed Scala module
+struct ScalaModule {
+    tree: Tree,
+    source: String,
+}
+
+impl ParsedModule for ScalaModule {
+    fn discover_functions(&self, file_index: usize, _source: &str) -> Vec<FunctionNode> {
+        let root = self.tree.root_node();
+        let mut functions = Vec::new();
+        discover_functions_recursive(root, &self.source, file_index, true, &mut functions);
+        functions.sort_by_key(|f| f.span.start);
+        functions
+    }
+
+    fn syntax_errors(&self) -> Vec<std::ops::Range<usize>> {
+        syntax_errors(self.tree.root_node())
+    }
+
+    fn top_level_decisions(&self) -> usize {
+        crate::metrics::scala_top_level_decisions(&self.tree.root_node(), &self.source)
+    }
+}
+
+/// Recursively discover functions in the Scala AST. Class, object, trait,
+/// and function bodies are walked like any other node, so members, nested
+/// `def`s, and bound lambdas are all found.
+///
+/// `public` is whether declarations here are visible outside the file: true
+/// at the top level and in the body of a type that is not `private` or
+/// `protected`, false inside function bodies.
+fn discover_functions_recursive(
+    node: Node,
+    source: &str,
+    file_index: usize,
+    public: bool,
+    functions: &mut Vec<FunctionNode>,
+) {
+    let is_function = match node.kind() {
+        "function_definition" => true,
+        "lambda_expression" => is_bound_lambda(node),
+        _ => false,
+    };
+    if is_function {
+        if let Some(function_node) =
+            extract_function(node, source, file_index, functions.len(), public)
+        {
+            functions.push(function_node);
+        }
+    }
+
+    let inner_public = match node.kind() {
+        "function_definition" | "lambda_expression" => false,
+        "class_definition" | "object_definition" | "trait_definition" | "enum_definition" => {
+            public && !is_hidden(node, source)
+        }
+        _ => public,
+    };
+    let mut cursor = node.walk();
+    for child in node.children(&mut cursor) {
+        discover_functions_recursive(child, source, file_index, inner_public, functions);
+    }
+}
+
+/// Extract a FunctionNode from a `def` or a bound lambda
+fn extract_function(
+    node: Node,
+    source: &str,
+    file_index: usize,
+    local_index: usize,
+    public: bool,
+) -> Option<FunctionNode> {
+    use crate::ast::FunctionId;
+    use crate::language::{FunctionBody, SourceSpan};
+
+    // Abstract members are `function_declaration`s, but guard anyway
+    let body_node = body_root(node)?;
+
+    let (name, is_public) = match node.kind() {
+        "function_definition" => (
+            field_text(node, "name", source),
+            public && !is_hidden(node, source),
+        ),
+        _ => {
+            // The lambda's `val` / `var` carries the name and modifiers
+            let definition = node.parent()?;
+            let name = definition
+                .child_by_field_name("pattern")
+                .filter(|pattern| pattern.kind() == "identifier")
+                .map(|pattern| source[pattern.start_byte()..pattern.end_byte()].to_string());
+            (name, public && !is_hidden(definition, source))
+        }
+    };
+
+    let span = SourceSpan::new(
+        node.start_byte(),
+        node.end_byte(),
+        node.start_position().row as u32 + 1, // tree-sitter uses 0-indexed rows
+        node.end_position().row as u32 + 1,   // tree-sitter uses 0-indexed rows
+        SourceSpan::column_of(source, node.start_byte()),
+    );
+
+    let body = FunctionBody::Scala {
+        body_node: body_node.id(),
+        source: source.to_string(),
+    };
+
+    Some(FunctionNode {
+        id: FunctionId {
+            file_index,
+            local_index,
+        },
+        name,
+        owner: None,
+        span,
+        body,
+        suppression_reason: None, // Will be extracted separately
+        signature_complexity: 0,
+        params: crate::params::scala_params(node),
+        is_public,
+        is_async: false,
+    })
+}
+
+fn field_text(node: Node, field: &str, source: &str) -> Option<String> {
+    let child = node.child_by_field_name(field)?;
+    Some(source[child.start_byte()..child.end_byte()].to_string())
+}
+
+/// Whether a definition is `private` or `protected` (qualified or not, as in
+/// `private[pkg]`); Scala's default visibility is public
+fn is_hidden(node: Node, source: &str) -> bool {
+    let Some(modifiers) = find_child_by_kind(node, "modifiers") else {
+        return false;
+    };
+    let mut cursor = modifiers.walk();
+    let hidden = modifiers
+        .children(&mut cursor)
+        .filter(|modifier| modifier.kind() == "access_modifier")
+        .any(|modifier| {
+            let text = &source[modifier.start_byte()..modifier.end_byte()];
+            text.starts_with("private") || text.starts_with("protected")
+        });
+    hidden
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    fn discover(source: &str) -> Vec<FunctionNode> {
+        let parser = ScalaParser::new().unwrap();
+        let module = parser.parse(source, "test.scala").unwrap();
+        module.discover_functions(0, source)
+    }
+
+    fn names(functions: &[FunctionNode]) -> Vec<&str> {
+        functions
+            .iter()
+            .map(|f| f.name.as_deref().unwrap_or(""))
+            .collect()
+    }
+
+    #[test]
+    fn test_create_parser() {
+        assert!(ScalaParser::new().is_ok());
+    }
+
+    #[test]
+    fn test_parse_top_level_def() {
+        let functions = discover(
+            r#"package demo
+
+def add(a: Int, b: Int): Int = a + b
+"#,
+        );
+        assert_eq!(names(&functions), vec!["add"]);
+        assert_eq!(functions[0].span.start_line, 3);
+    }
+
+    #[test]
+    fn test_parse_class_object_and_trait_members() {
+        let functions = discover(
+            r#"class Account(private var balance: Int) {
+  def deposit(amount: Int): Unit = {
+    balance += amount
+  }
+}
+
+object Account {
+  def empty: Account = new Account(0)
+}
+
+trait Shape {
+  def area: Double
+  def describe(): String = s"shape of area $area"
+}
+"#,
+        );
+        // Abstract members have no body
+        assert_eq!(names(&functions), vec!["deposit", "empty", "describe"]);
+    }
+
+    #[test]
+    fn test_parse_indentation_syntax() {
+        let functions = discover(
+            r#"object Greeter:
+  def greet(name: String): String =
+    val prefix = "Hello, "
+    prefix + name
+
+  def shout(name: String): String =
+    greet(name).toUpperCase
+"#,
+        );
+        assert_eq!(names(&functions), vec!["greet", "shout"]);
+    }
+
+    #[test]
+    fn test_parse_bound_lambdas_only() {
+        let functions = discover(
+            r#"object Lambdas {
+  val double = (x: Int) => x * 2
+  var handler: String => Unit = msg => println(msg)
+
+  def total(xs: List[Int]): Int = xs.map(x => x + 1).sum
+}
+"#,
+        );
+        // The lambda passed to `map` is part of `total`
+        assert_eq!(names(&functions), vec!["double", "handler", "total"]);
+    }
+
+    #[test]
+    fn test_parse_visibility() {
+        let functions = discover(
+            r#"def helper(): Unit = ()
+
+class Service {
+  def run(): Unit = {
+    def step(): Unit = ()
+    step()
+  }
+  protected def hook(): Unit = ()
+  private def secret(): Unit = ()
+  private[demo] def packaged(): Unit = ()
+  val transform = (s: String) => s.trim
+}
+
+private object Internal {
+  def util(): Unit = ()
+}
+"#,
+        );
+        let public: Vec<(&str, bool)> = functions
+            .iter()
+            .map(|f| (f.name.as_deref().unwrap(), f.is_public))
+            .collect();
+        assert_eq!(
+            public,
+            vec![
+                ("helper", true),
+                ("run", true),
+                ("step", false),
+                ("hook", false),
+                ("secret", false),
+                ("packaged", false),
+                ("transform", true),
+                ("util", false),
+            ]
+        );
+    }
+
+    #[test]
+    fn test_parse_empty_file() {
+        assert!(discover("").is_empty());
+        assert!(discover("package demo\n\nimport scala.util.Try\n").is_empty());
+    }
+}
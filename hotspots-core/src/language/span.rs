@@ -1,6 +1,7 @@
 //! Language-agnostic source span representation
 
 use serde::{Deserialize, Serialize};
+use std::borrow::Cow;
 
 /// Language-agnostic source code span
 ///
@@ -16,7 +17,8 @@ pub struct SourceSpan {
     pub start_line: u32,
     /// Line number of the end (1-indexed)
     pub end_line: u32,
-    /// Column number of the start (0-indexed, in bytes)
+    /// Column number of the start (0-indexed, in characters, so a
+    /// multibyte character before the function counts once, as in an editor)
     pub start_col: u32,
 }
 
@@ -32,6 +34,14 @@ impl SourceSpan {
         }
     }
 
+    /// 0-indexed column of byte offset `offset` in `source`, in characters.
+    /// tree-sitter columns are bytes; this matches the character columns of
+    /// SWC and syn.
+    pub fn column_of(source: &str, offset: usize) -> u32 {
+        let line_start = source[..offset].rfind('\n').map_or(0, |p| p + 1);
+        source[line_start..offset].chars().count() as u32
+    }
+
     /// Get the length of the span in bytes
     pub fn len(&self) -> usize {
         self.end.saturating_sub(self.start)
@@ -91,6 +101,25 @@ pub fn span_with_location(
     }
 }
 
+/// `source` without a leading UTF-8 byte order mark and with every line
+/// break as `\n`
+///
+/// Windows checkouts end lines with `\r\n`, and files edited on several
+/// systems can mix in a lone `\r`, which editors also show as a line break
+/// but `str::lines`, tree-sitter rows, and byte-offset line counts do not. A
+/// BOM is invisible in an editor, but some grammars parse it as an error
+/// before the first function. Sources are normalized once, before parsing,
+/// so that spans, line numbers, and LOC of every language match what an
+/// editor shows, and match the same file saved as plain UTF-8 with `\n`
+/// endings. Tabs are kept. Borrowed when there is nothing to replace.
+pub fn normalize_source(source: &str) -> Cow<'_, str> {
+    let source = source.strip_prefix('\u{feff}').unwrap_or(source);
+    if !source.contains('\r') {
+        return Cow::Borrowed(source);
+    }
+    Cow::Owned(source.replace("\r\n", "\n").replace('\r', "\n"))
+}
+
 #[cfg(test)]
 mod tests {
     use super::*;
@@ -167,4 +196,28 @@ mod tests {
         assert!(span1.contains(&zero_span));
         assert!(!zero_span.overlaps(&span1)); // Zero-width doesn't overlap
     }
+
+    #[test]
+    fn test_normalize_source() {
+        let lf = "a\n\tb\n\nc\n";
+        assert!(matches!(normalize_source(lf), Cow::Borrowed(_)));
+        assert_eq!(normalize_source("a\r\n\tb\r\n\r\nc\r\n"), lf);
+        // A lone CR is a line break too, and mixed endings all become LF
+        assert_eq!(normalize_source("a\r\tb\n\r\nc\r"), lf);
+        assert_eq!(normalize_source("\u{feff}a\n\tb\n\nc\n"), lf);
+        assert_eq!(normalize_source("\u{feff}a\r\n\tb\r\n\r\nc\r\n"), lf);
+        // Only a leading BOM is one
+        assert_eq!(normalize_source("a\u{feff}"), "a\u{feff}");
+    }
+
+    #[test]
+    fn test_column_of() {
+        let source = "fn a() {}\n  fn b() {}\n/* é */ fn c() {}\n🦀 fn d() {}";
+        let column = |name: &str| SourceSpan::column_of(source, source.find(name).unwrap());
+        assert_eq!(column("fn a"), 0);
+        assert_eq!(column("fn b"), 2);
+        // `é` is two bytes and `🦀` four, but one column each
+        assert_eq!(column("fn c"), 8);
+        assert_eq!(column("fn d"), 2);
+    }
 }
@@ -0,0 +1,266 @@
+//! Minimal SQL tokenizer shared by function discovery and metric extraction
+//!
+//! Only what procedural analysis needs: words (upper-cased), quoted
+//! identifiers, string literals, dollar-quoted bodies, and single-character
+//! symbols. Comments and whitespace are dropped, so formatting never affects
+//! results.
+
+use std::ops::Range;
+
+/// Token kind
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub enum TokenKind {
+    /// Keyword or identifier, upper-cased (`@var`, `#tmp`, and `$1` included)
+    Word(String),
+    /// Quoted identifier (`"name"` or `[name]`), contents as written
+    QuotedIdent(String),
+    /// String literal (`'...'`)
+    Str,
+    /// Dollar-quoted string (`$$ ... $$` or `$tag$ ... $tag$`); `inner` is the
+    /// byte range between the delimiters
+    DollarStr { inner: Range<usize> },
+    /// Numeric literal
+    Number,
+    /// Any other single character: `(`, `)`, `;`, `.`, `,`, operators
+    Symbol(char),
+}
+
+/// A token with its byte range and 1-indexed start line
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub struct Token {
+    pub kind: TokenKind,
+    pub start: usize,
+    pub end: usize,
+    pub line: u32,
+}
+
+impl Token {
+    /// True if this is the word `kw` (upper-case)
+    pub fn is_word(&self, kw: &str) -> bool {
+        matches!(&self.kind, TokenKind::Word(w) if w == kw)
+    }
+
+    /// The upper-cased word, if this is a word
+    pub fn word(&self) -> Option<&str> {
+        match &self.kind {
+            TokenKind::Word(w) => Some(w),
+            _ => None,
+        }
+    }
+
+    /// True if this is the symbol `c`
+    pub fn is_symbol(&self, c: char) -> bool {
+        self.kind == TokenKind::Symbol(c)
+    }
+}
+
+/// Tokenize `src`. Never fails: an unterminated string or comment runs to the
+/// end of the input.
+pub fn tokenize(src: &str) -> Vec<Token> {
+    let bytes = src.as_bytes();
+    let mut tokens = Vec::new();
+    let mut i = 0;
+    let mut line = 1u32;
+
+    while i < bytes.len() {
+        let c = bytes[i];
+        let start = i;
+        let start_line = line;
+
+        if c == b'\n' {
+            line += 1;
+            i += 1;
+            continue;
+        }
+        if c.is_ascii_whitespace() {
+            i += 1;
+            continue;
+        }
+
+        // Line comment
+        if c == b'-' && bytes.get(i + 1) == Some(&b'-') {
+            while i < bytes.len() && bytes[i] != b'\n' {
+                i += 1;
+            }
+            continue;
+        }
+
+        // Block comment (nesting, as in PostgreSQL and T-SQL)
+        if c == b'/' && bytes.get(i + 1) == Some(&b'*') {
+            let mut depth = 0usize;
+            while i < bytes.len() {
+                if bytes[i] == b'/' && bytes.get(i + 1) == Some(&b'*') {
+                    depth += 1;
+                    i += 2;
+                } else if bytes[i] == b'*' && bytes.get(i + 1) == Some(&b'/') {
+                    depth -= 1;
+                    i += 2;
+                    if depth == 0 {
+                        break;
+                    }
+                } else {
+                    if bytes[i] == b'\n' {
+                        line += 1;
+                    }
+                    i += 1;
+                }
+            }
+            continue;
+        }
+
+        let kind = match c {
+            b'\'' => {
+                i = scan_quoted(bytes, i, b'\'', &mut line);
+                TokenKind::Str
+            }
+            b'"' => {
+                i = scan_quoted(bytes, i, b'"', &mut line);
+                TokenKind::QuotedIdent(unquote(&src[start..i]))
+            }
+            b'[' => {
+                i = scan_quoted(bytes, i, b']', &mut line);
+                TokenKind::QuotedIdent(unquote(&src[start..i]))
+            }
+            b'$' => match dollar_tag_len(bytes, i) {
+                Some(tag_len) => {
+                    let tag = &src[i..i + tag_len];
+                    let inner_start = i + tag_len;
+                    let inner_end = src[inner_start..]
+                        .find(tag)
+                        .map_or(src.len(), |p| inner_start + p);
+                    line += src[inner_start..inner_end].matches('\n').count() as u32;
+                    i = (inner_end + tag_len).min(src.len());
+                    TokenKind::DollarStr {
+                        inner: inner_start..inner_end,
+                    }
+                }
+                None => {
+                    i = scan_word(src, i + 1);
+                    TokenKind::Word(src[start..i].to_ascii_uppercase())
+                }
+            },
+            b'0'..=b'9' => {
+                while i < bytes.len() && (bytes[i].is_ascii_alphanumeric() || bytes[i] == b'.') {
+                    i += 1;
+                }
+                TokenKind::Number
+            }
+            _ if is_word_start(src, i) => {
+                i = scan_word(src, i);
+                TokenKind::Word(src[start..i].to_ascii_uppercase())
+            }
+            _ => {
+                let ch = src[i..].chars().next().unwrap_or('\0');
+                i += ch.len_utf8();
+                TokenKind::Symbol(ch)
+            }
+        };
+
+        tokens.push(Token {
+            kind,
+            start,
+            end: i,
+            line: start_line,
+        });
+    }
+
+    tokens
+}
+
+/// End of a quoted run starting at `start`; a doubled `close` is an escape.
+fn scan_quoted(bytes: &[u8], start: usize, close: u8, line: &mut u32) -> usize {
+    let mut i = start + 1;
+    while i < bytes.len() {
+        if bytes[i] == b'\n' {
+            *line += 1;
+        }
+        if bytes[i] == close {
+            if bytes.get(i + 1) == Some(&close) {
+                i += 2;
+                continue;
+            }
+            return i + 1;
+        }
+        i += 1;
+    }
+    bytes.len()
+}
+
+/// Strip the delimiters from a quoted identifier
+fn unquote(quoted: &str) -> String {
+    let inner = &quoted[1..];
+    inner.strip_suffix(['"', ']']).unwrap_or(inner).to_string()
+}
+
+/// Length of a dollar-quote delimiter (`$$` or `$tag$`) at `i`, if there is one
+fn dollar_tag_len(bytes: &[u8], i: usize) -> Option<usize> {
+    let mut j = i + 1;
+    if bytes.get(j).is_some_and(|b| b.is_ascii_digit()) {
+        // `$1` is a positional parameter, not a delimiter
+        return None;
+    }
+    while j < bytes.len() && (bytes[j].is_ascii_alphanumeric() || bytes[j] == b'_') {
+        j += 1;
+    }
+    (bytes.get(j) == Some(&b'$')).then_some(j + 1 - i)
+}
+
+fn is_word_start(src: &str, i: usize) -> bool {
+    src[i..]
+        .chars()
+        .next()
+        .is_some_and(|ch| ch.is_alphabetic() || matches!(ch, '_' | '@' | '#'))
+}
+
+fn scan_word(src: &str, start: usize) -> usize {
+    src[start..]
+        .char_indices()
+        .find(|&(_, ch)| !(ch.is_alphanumeric() || matches!(ch, '_' | '@' | '#' | '$')))
+        .map_or(src.len(), |(p, _)| start + p)
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    fn words(src: &str) -> Vec<String> {
+        tokenize(src)
+            .into_iter()
+            .filter_map(|t| t.word().map(str::to_string))
+            .collect()
+    }
+
+    #[test]
+    fn test_comments_and_strings_are_not_words() {
+        let src = "IF x -- IF in comment\n/* LOOP /* nested */ WHILE */ 'CASE WHEN' THEN";
+        assert_eq!(words(src), vec!["IF", "X", "THEN"]);
+    }
+
+    #[test]
+    fn test_dollar_quoted_body() {
+        let src = "AS $body$\nBEGIN\n  RETURN 1;\nEND;\n$body$ LANGUAGE plpgsql;";
+        let tokens = tokenize(src);
+        let TokenKind::DollarStr { inner } = &tokens[1].kind else {
+            panic!("expected a dollar-quoted string, got {:?}", tokens[1]);
+        };
+        assert!(src[inner.clone()].contains("RETURN 1;"));
+        // The token after the body starts on the body's closing line
+        assert!(tokens[2].is_word("LANGUAGE"));
+        assert_eq!(tokens[2].line, 5);
+    }
+
+    #[test]
+    fn test_positional_parameter_is_a_word() {
+        assert_eq!(words("SELECT $1 + @count"), vec!["SELECT", "$1", "@COUNT"]);
+    }
+
+    #[test]
+    fn test_quoted_identifiers() {
+        let tokens = tokenize("\"My Func\" [dbo]");
+        assert_eq!(
+            tokens[0].kind,
+            TokenKind::QuotedIdent("My Func".to_string())
+        );
+        assert_eq!(tokens[1].kind, TokenKind::QuotedIdent("dbo".to_string()));
+    }
+}
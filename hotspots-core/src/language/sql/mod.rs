@@ -0,0 +1,91 @@
+//! SQL language support
+//!
+//! This module discovers stored functions and procedures (`CREATE FUNCTION` /
+//! `CREATE PROCEDURE`) in `.sql` files and analyzes their procedural bodies.
+//! Parsing uses a small hand-written tokenizer rather than a full grammar:
+//! plain DDL and DML statements outside routines are ignored.
+
+pub mod cfg_builder;
+pub mod lexer;
+pub mod parser;
+
+pub use cfg_builder::SqlCfgBuilder;
+pub use parser::SqlParser;
+
+use serde::{Deserialize, Serialize};
+
+/// Procedural SQL dialect
+#[derive(Debug, Clone, Copy, PartialEq, Eq, Hash, Serialize, Deserialize)]
+#[serde(rename_all = "lowercase")]
+pub enum SqlDialect {
+    /// PostgreSQL PL/pgSQL (dollar-quoted bodies, `ELSIF`, `LOOP`, `EXCEPTION WHEN`)
+    Postgres,
+    /// Microsoft T-SQL (`AS BEGIN ... END` bodies, `GO` batches, `BEGIN CATCH`)
+    Tsql,
+}
+
+impl SqlDialect {
+    /// Parse from the config / CLI name ("postgres" or "tsql")
+    pub fn from_name(s: &str) -> Option<Self> {
+        match s {
+            "postgres" => Some(SqlDialect::Postgres),
+            "tsql" => Some(SqlDialect::Tsql),
+            _ => None,
+        }
+    }
+
+    /// Guess the dialect of a whole file.
+    ///
+    /// T-SQL when the file has a `GO` batch separator line, `CREATE OR ALTER`,
+    /// or `@`-prefixed variables; PostgreSQL otherwise.
+    pub fn detect(source: &str) -> Self {
+        Self::detect_tokens(&lexer::tokenize(source))
+    }
+
+    /// [`SqlDialect::detect`] over already-tokenized source
+    pub(crate) fn detect_tokens(tokens: &[lexer::Token]) -> Self {
+        let tsql = tokens.iter().enumerate().any(|(i, t)| {
+            let alone_on_line = |j: Option<usize>| {
+                !j.and_then(|j| tokens.get(j))
+                    .is_some_and(|other| other.line == t.line)
+            };
+            (t.is_word("GO") && alone_on_line(i.checked_sub(1)) && alone_on_line(Some(i + 1)))
+                || (t.is_word("OR") && tokens.get(i + 1).is_some_and(|n| n.is_word("ALTER")))
+                || t.word().is_some_and(|w| w.starts_with('@'))
+        });
+        if tsql {
+            SqlDialect::Tsql
+        } else {
+            SqlDialect::Postgres
+        }
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn test_detect_postgres() {
+        let src = "CREATE FUNCTION f() RETURNS int AS $$ BEGIN RETURN 1; END; $$ LANGUAGE plpgsql;";
+        assert_eq!(SqlDialect::detect(src), SqlDialect::Postgres);
+    }
+
+    #[test]
+    fn test_detect_tsql() {
+        let go = "CREATE PROCEDURE p AS\nBEGIN\n  SELECT 1;\nEND\nGO\n";
+        assert_eq!(SqlDialect::detect(go), SqlDialect::Tsql);
+        let var = "CREATE PROCEDURE p @id INT AS SELECT @id;";
+        assert_eq!(SqlDialect::detect(var), SqlDialect::Tsql);
+    }
+
+    #[test]
+    fn test_from_name() {
+        assert_eq!(
+            SqlDialect::from_name("postgres"),
+            Some(SqlDialect::Postgres)
+        );
+        assert_eq!(SqlDialect::from_name("tsql"), Some(SqlDialect::Tsql));
+        assert_eq!(SqlDialect::from_name("mysql"), None);
+    }
+}
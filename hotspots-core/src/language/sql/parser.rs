@@ -0,0 +1,312 @@
+//! SQL parser implementation: stored function and procedure discovery
+
+use super::lexer::{tokenize, Token, TokenKind};
+use super::SqlDialect;
+use crate::ast::{FunctionId, FunctionNode};
+use crate::language::function_body::FunctionBody;
+use crate::language::parser::{LanguageParser, ParsedModule};
+use crate::language::span::SourceSpan;
+use anyhow::Result;
+use std::ops::Range;
+
+/// SQL parser for stored functions and procedures
+pub struct SqlParser {
+    /// Dialect to parse as; `None` detects it per file
+    dialect: Option<SqlDialect>,
+}
+
+impl SqlParser {
+    /// Create a parser for `dialect`, or one that detects the dialect per file
+    pub fn new(dialect: Option<SqlDialect>) -> Self {
+        Self { dialect }
+    }
+}
+
+impl LanguageParser for SqlParser {
+    fn parse(&self, source: &str, _filename: &str) -> Result<Box<dyn ParsedModule>> {
+        let tokens = tokenize(source);
+        let dialect = self
+            .dialect
+            .unwrap_or_else(|| SqlDialect::detect_tokens(&tokens));
+        let routines = find_routines(source, &tokens, dialect);
+        Ok(Box::new(SqlModule { routines, dialect }))
+    }
+}
+
+/// A discovered `CREATE FUNCTION` / `CREATE PROCEDURE` statement
+struct Routine {
+    name: String,
+    span: SourceSpan,
+    /// The procedural body: the dollar-quoted text (PostgreSQL) or everything
+    /// after the top-level `AS` (T-SQL)
+    body: String,
+}
+
+/// Parsed SQL module
+struct SqlModule {
+    routines: Vec<Routine>,
+    dialect: SqlDialect,
+}
+
+impl ParsedModule for SqlModule {
+    fn discover_functions(&self, file_index: usize, _source: &str) -> Vec<FunctionNode> {
+        self.routines
+            .iter()
+            .enumerate()
+            .map(|(local_index, routine)| FunctionNode {
+                id: FunctionId {
+                    file_index,
+                    local_index,
+                },
+                name: Some(routine.name.clone()),
+                owner: None,
+                span: routine.span,
+                body: FunctionBody::Sql {
+                    source: routine.body.clone(),
+                    dialect: self.dialect,
+                },
+                suppression_reason: None,
+                signature_complexity: 0,
+                params: 0,
+                // Routines are schema objects, callable by any client with access
+                is_public: true,
+                is_async: false,
+            })
+            .collect()
+    }
+}
+
+/// Find every routine definition, in source order
+fn find_routines(source: &str, tokens: &[Token], dialect: SqlDialect) -> Vec<Routine> {
+    let mut routines = Vec::new();
+    let mut i = 0;
+
+    while i < tokens.len() {
+        let Some(name_start) = routine_header(tokens, i) else {
+            i += 1;
+            continue;
+        };
+        let (name, after_name) = qualified_name(source, tokens, name_start);
+        if name.is_empty() {
+            i = name_start;
+            continue;
+        }
+
+        let (last, body) = match dialect {
+            SqlDialect::Postgres => postgres_extent(tokens, after_name),
+            SqlDialect::Tsql => tsql_extent(tokens, after_name),
+        };
+
+        let start = tokens[i].start;
+        let end = tokens[last].end;
+        let span = SourceSpan::new(
+            start,
+            end,
+            tokens[i].line,
+            tokens[i].line + source[start..end].matches('\n').count() as u32,
+            SourceSpan::column_of(source, start),
+        );
+
+        routines.push(Routine {
+            name,
+            span,
+            body: source[body].to_string(),
+        });
+        i = last + 1;
+    }
+
+    routines
+}
+
+/// If `tokens[i]` starts `CREATE [OR REPLACE | OR ALTER] FUNCTION | PROCEDURE | PROC`,
+/// the index of the routine name
+fn routine_header(tokens: &[Token], i: usize) -> Option<usize> {
+    if !tokens[i].is_word("CREATE") {
+        return None;
+    }
+    let mut j = i + 1;
+    if tokens.get(j).is_some_and(|t| t.is_word("OR"))
+        && tokens
+            .get(j + 1)
+            .is_some_and(|t| t.is_word("REPLACE") || t.is_word("ALTER"))
+    {
+        j += 2;
+    }
+    tokens
+        .get(j)
+        .and_then(Token::word)
+        .filter(|w| matches!(*w, "FUNCTION" | "PROCEDURE" | "PROC"))
+        .map(|_| j + 1)
+}
+
+/// Read a possibly schema-qualified name (`billing.apply_discount`,
+/// `[dbo].[GetOrders]`) starting at `i`; returns the name as written, without
+/// quotes, and the index just past it.
+pub(crate) fn qualified_name(source: &str, tokens: &[Token], mut i: usize) -> (String, usize) {
+    let mut parts: Vec<&str> = Vec::new();
+    while let Some(token) = tokens.get(i) {
+        match &token.kind {
+            TokenKind::Word(_) => parts.push(&source[token.start..token.end]),
+            TokenKind::QuotedIdent(ident) => parts.push(ident),
+            _ => break,
+        }
+        i += 1;
+        if !tokens.get(i).is_some_and(|t| t.is_symbol('.')) {
+            break;
+        }
+        i += 1;
+    }
+    (parts.join("."), i)
+}
+
+/// PostgreSQL: the statement runs to the first top-level `;`; the body is its
+/// first dollar-quoted string. SQL-standard bodies (`BEGIN ATOMIC ... END`,
+/// `RETURN expr`) have no dollar quotes and use the rest of the statement.
+fn postgres_extent(tokens: &[Token], from: usize) -> (usize, Range<usize>) {
+    let mut parens = 0usize;
+    let mut blocks = 0usize;
+    let mut body: Option<Range<usize>> = None;
+    let body_or_rest = |body: Option<Range<usize>>, last: usize| {
+        body.unwrap_or_else(|| {
+            let start = tokens.get(from).map_or(tokens[last].end, |t| t.start);
+            start..tokens[last].end.max(start)
+        })
+    };
+
+    for (k, token) in tokens.iter().enumerate().skip(from) {
+        match &token.kind {
+            TokenKind::Symbol('(') => parens += 1,
+            TokenKind::Symbol(')') => parens = parens.saturating_sub(1),
+            TokenKind::Symbol(';') if parens == 0 && blocks == 0 => {
+                return (k, body_or_rest(body, k));
+            }
+            TokenKind::DollarStr { inner } if body.is_none() => body = Some(inner.clone()),
+            TokenKind::Word(w) if body.is_none() => match w.as_str() {
+                "BEGIN" => blocks += 1,
+                "CASE" if blocks > 0 => blocks += 1,
+                "END" => blocks = blocks.saturating_sub(1),
+                _ => {}
+            },
+            _ => {}
+        }
+        // A missing semicolon must not swallow the next routine
+        if k > from && routine_header(tokens, k).is_some() {
+            return (k - 1, body_or_rest(body, k - 1));
+        }
+    }
+
+    let last = tokens.len() - 1;
+    (last, body_or_rest(body, last))
+}
+
+/// T-SQL: the routine runs to the next `GO` batch separator or the next
+/// routine definition; the body starts after the first top-level `AS` that is
+/// not a parameter's `@p AS type`.
+fn tsql_extent(tokens: &[Token], from: usize) -> (usize, Range<usize>) {
+    let end = (from..tokens.len())
+        .find(|&k| is_batch_separator(tokens, k) || routine_header(tokens, k).is_some())
+        .unwrap_or(tokens.len());
+    // `from` is past the routine name, so `last` is at worst the name itself
+    let last = end - 1;
+
+    let mut parens = 0usize;
+    let mut body_start = None;
+    for (k, token) in tokens.iter().enumerate().take(end).skip(from) {
+        match &token.kind {
+            TokenKind::Symbol('(') => parens += 1,
+            TokenKind::Symbol(')') => parens = parens.saturating_sub(1),
+            TokenKind::Word(w)
+                if w == "AS"
+                    && parens == 0
+                    && !tokens[k - 1].word().is_some_and(|p| p.starts_with('@')) =>
+            {
+                body_start = Some(k + 1);
+                break;
+            }
+            _ => {}
+        }
+    }
+
+    let body = match body_start {
+        Some(k) if k <= last => tokens[k].start..tokens[last].end,
+        _ => tokens[last].end..tokens[last].end,
+    };
+    (last, body)
+}
+
+/// `GO` alone on its line
+fn is_batch_separator(tokens: &[Token], k: usize) -> bool {
+    let line = tokens[k].line;
+    tokens[k].is_word("GO")
+        && (k == 0 || tokens[k - 1].line != line)
+        && !tokens.get(k + 1).is_some_and(|next| next.line == line)
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    fn discover(source: &str, dialect: Option<SqlDialect>) -> Vec<FunctionNode> {
+        let parser = SqlParser::new(dialect);
+        let module = parser.parse(source, "test.sql").unwrap();
+        module.discover_functions(0, source)
+    }
+
+    #[test]
+    fn test_postgres_function_and_procedure() {
+        let source = r#"
+CREATE TABLE orders (id int);
+
+CREATE OR REPLACE FUNCTION billing.total(p_id int) RETURNS numeric AS $$
+BEGIN
+  RETURN 1; -- a ; inside the body does not end the statement
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE PROCEDURE archive() LANGUAGE plpgsql AS $body$
+BEGIN
+  DELETE FROM orders;
+END;
+$body$;
+"#;
+        let functions = discover(source, None);
+        assert_eq!(functions.len(), 2);
+        assert_eq!(functions[0].name.as_deref(), Some("billing.total"));
+        assert_eq!(functions[0].span.start_line, 4);
+        assert_eq!(functions[0].span.end_line, 9);
+        let (body, dialect) = functions[0].body.as_sql();
+        assert_eq!(dialect, SqlDialect::Postgres);
+        assert!(body.trim_start().starts_with("BEGIN"));
+        assert!(!body.contains("$$"));
+        assert_eq!(functions[1].name.as_deref(), Some("archive"));
+        assert_eq!(functions[1].id.local_index, 1);
+    }
+
+    #[test]
+    fn test_tsql_procedures_split_on_go() {
+        let source = "CREATE PROCEDURE [dbo].[GetOrders] @CustomerId AS INT\nAS\nBEGIN\n  SELECT 1;\n  SELECT 2;\nEND\nGO\nCREATE OR ALTER PROC dbo.Other AS SELECT 3;\n";
+        let functions = discover(source, None);
+        assert_eq!(functions.len(), 2);
+        assert_eq!(functions[0].name.as_deref(), Some("dbo.GetOrders"));
+        assert_eq!(functions[0].span.end_line, 6);
+        let (body, dialect) = functions[0].body.as_sql();
+        assert_eq!(dialect, SqlDialect::Tsql);
+        assert!(body.starts_with("BEGIN"), "body: {body}");
+        assert!(body.ends_with("END"), "body: {body}");
+        assert_eq!(functions[1].name.as_deref(), Some("dbo.Other"));
+        assert_eq!(functions[1].body.as_sql().0, "SELECT 3;");
+    }
+
+    #[test]
+    fn test_explicit_dialect_overrides_detection() {
+        let source = "CREATE FUNCTION f() RETURNS int AS $$ SELECT 1 $$ LANGUAGE sql;";
+        let functions = discover(source, Some(SqlDialect::Tsql));
+        assert_eq!(functions[0].body.as_sql().1, SqlDialect::Tsql);
+    }
+
+    #[test]
+    fn test_no_routines() {
+        let source = "CREATE TABLE t (id int);\nSELECT * FROM t WHERE id > 1 AND id < 5;\n";
+        assert!(discover(source, None).is_empty());
+    }
+}
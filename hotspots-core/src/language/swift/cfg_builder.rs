@@ -0,0 +1,337 @@
+//! Swift CFG builder implementation
+
+use crate::ast::FunctionNode;
+use crate::cfg::{Cfg, NodeId, NodeKind};
+use crate::language::cfg_builder::{CfgBuilder, CfgState};
+use crate::language::swift::{
+    blocks, body_statements, control_transfer_keyword, is_fatal_call, FUNCTION_KINDS,
+};
+use crate::language::tree_sitter_utils::{
+    find_child_by_kind, find_function_by_start, with_cached_swift_tree,
+};
+use tree_sitter::Node;
+
+/// Swift CFG builder
+pub struct SwiftCfgBuilder;
+
+impl CfgBuilder for SwiftCfgBuilder {
+    fn build(&self, function: &FunctionNode) -> Cfg {
+        let (_body_node_id, source) = function.body.as_swift();
+
+        let result = with_cached_swift_tree(source, |root| {
+            let func_node = find_function_by_start(root, function.span.start, FUNCTION_KINDS)?;
+            let mut builder = SwiftCfgBuilderState {
+                flow: CfgState::new(),
+            };
+            builder.build_from_block(body_statements(func_node), source);
+            Some(builder.flow.finish())
+        });
+
+        result.unwrap_or_else(CfgState::straight_line)
+    }
+}
+
+struct SwiftCfgBuilderState {
+    flow: CfgState,
+}
+
+impl SwiftCfgBuilderState {
+    /// Visit a `statements` node; `None` is an empty block
+    fn build_from_block(&mut self, statements: Option<Node>, source: &str) {
+        let Some(statements) = statements else {
+            return;
+        };
+        let mut cursor = statements.walk();
+        for child in statements.named_children(&mut cursor) {
+            self.visit_node(&child, source);
+        }
+    }
+
+    fn visit_node(&mut self, node: &Node, source: &str) {
+        match node.kind() {
+            "if_statement" => self.visit_if(node, source),
+            "guard_statement" => self.visit_guard(node, source),
+            "for_statement" | "while_statement" => self.visit_loop(node, source),
+            "repeat_while_statement" => self.visit_repeat_while(node, source),
+            "switch_statement" => self.visit_switch(node, source),
+            "do_statement" => self.visit_do(node, source),
+            "control_transfer_statement" => match control_transfer_keyword(*node, source) {
+                "return" | "throw" => self.flow.jump_to_exit(),
+                "break" => self.flow.jump_to_break(),
+                "continue" => self.flow.jump_to_continue(),
+                _ => self.flow.statement(),
+            },
+            _ if is_fatal_call(*node, source) => self.flow.jump_to_exit(),
+            _ => self.flow.statement(),
+        }
+    }
+
+    fn visit_branch(
+        &mut self,
+        from: NodeId,
+        statements: Option<Node>,
+        join: &mut Option<NodeId>,
+        source: &str,
+    ) {
+        self.flow.start_branch(from);
+        self.build_from_block(statements, source);
+        self.flow.fall_through(join);
+    }
+
+    fn visit_if(&mut self, node: &Node, source: &str) {
+        let Some(condition_node) = self.flow.add_after(NodeKind::Condition) else {
+            return;
+        };
+
+        let mut join_node = None;
+        let blocks = blocks(*node);
+        self.visit_branch(
+            condition_node,
+            blocks.first().copied().flatten(),
+            &mut join_node,
+            source,
+        );
+
+        if let Some(else_if) = find_child_by_kind(*node, "if_statement") {
+            self.flow.start_branch(condition_node);
+            self.visit_if(&else_if, source);
+            self.flow.fall_through(&mut join_node);
+        } else if let Some(&else_block) = blocks.get(1) {
+            self.visit_branch(condition_node, else_block, &mut join_node, source);
+        } else {
+            self.flow.skip_branches(condition_node, &mut join_node);
+        }
+
+        self.flow.current_node = join_node;
+    }
+
+    /// `guard cond else { ... }`: the else block must leave the scope, so the
+    /// only path past the guard is the condition holding
+    fn visit_guard(&mut self, node: &Node, source: &str) {
+        let Some(condition_node) = self.flow.add_after(NodeKind::Condition) else {
+            return;
+        };
+
+        let mut join_node = None;
+        self.visit_branch(
+            condition_node,
+            blocks(*node).first().copied().flatten(),
+            &mut join_node,
+            source,
+        );
+        self.flow.skip_branches(condition_node, &mut join_node);
+        self.flow.current_node = join_node;
+    }
+
+    /// `for ... in` and `while`
+    fn visit_loop(&mut self, node: &Node, source: &str) {
+        let Some(header) = self.flow.start_loop() else {
+            return;
+        };
+        self.build_from_block(blocks(*node).first().copied().flatten(), source);
+        self.flow.end_loop(header);
+    }
+
+    /// `repeat { ... } while cond`: the body runs before the condition
+    fn visit_repeat_while(&mut self, node: &Node, source: &str) {
+        let Some(body_and_header) = self.flow.start_post_test_loop() else {
+            return;
+        };
+        self.build_from_block(blocks(*node).first().copied().flatten(), source);
+        self.flow.end_post_test_loop(body_and_header);
+    }
+
+    /// Each `case` / `default` entry is a branch. Swift cases do not fall
+    /// through unless they say `fallthrough`, and `break` leaves the switch.
+    fn visit_switch(&mut self, node: &Node, source: &str) {
+        let Some(switch_node) = self.flow.add_after(NodeKind::Condition) else {
+            return;
+        };
+        self.flow
+            .cfg
+            .switches
+            .insert(node.start_byte(), switch_node);
+        self.flow.push_loop(switch_node);
+
+        let mut cursor = node.walk();
+        for entry in node.children(&mut cursor) {
+            if entry.kind() != "switch_entry" {
+                continue;
+            }
+            self.flow.start_branch(switch_node);
+            self.build_from_block(find_child_by_kind(entry, "statements"), source);
+            self.flow.fall_through_to_break();
+        }
+
+        self.flow.end_switch(switch_node);
+    }
+
+    /// `do { ... } catch { ... }`: the body and each `catch` block are
+    /// branches of the `do`
+    fn visit_do(&mut self, node: &Node, source: &str) {
+        let Some(do_node) = self.flow.add_after(NodeKind::Condition) else {
+            return;
+        };
+
+        let mut join_node = None;
+        self.visit_branch(
+            do_node,
+            blocks(*node).first().copied().flatten(),
+            &mut join_node,
+            source,
+        );
+
+        let mut cursor = node.walk();
+        for catch in node.children(&mut cursor) {
+            if catch.kind() == "catch_block" {
+                self.visit_branch(
+                    do_node,
+                    blocks(catch).first().copied().flatten(),
+                    &mut join_node,
+                    source,
+                );
+            }
+        }
+
+        self.flow.current_node = join_node;
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::language::parser::LanguageParser;
+    use crate::language::SwiftParser;
+
+    /// CC of the first function in `source`
+    fn cc(source: &str) -> usize {
+        let module = SwiftParser::new()
+            .unwrap()
+            .parse(source, "test.swift")
+            .unwrap();
+        let function = module
+            .discover_functions(0, source)
+            .into_iter()
+            .next()
+            .expect("No function found in test source");
+        let cfg = SwiftCfgBuilder.build(&function);
+        assert!(
+            cfg.validate().is_ok(),
+            "CFG must be valid: {:?}",
+            cfg.validate()
+        );
+        // CC = E - N + 2
+        (cfg.edge_count() as isize - cfg.node_count() as isize + 2).max(1) as usize
+    }
+
+    #[test]
+    fn test_simple_function() {
+        assert_eq!(cc("func f(x: Int) -> Int { return x + 1 }"), 1);
+    }
+
+    #[test]
+    fn test_empty_function() {
+        assert_eq!(cc("func f() {}"), 1);
+    }
+
+    #[test]
+    fn test_else_if_chain_all_return() {
+        // No join node when every branch returns
+        let source = r#"
+func sign(_ x: Int) -> Int {
+    if x > 0 {
+        return 1
+    } else if x < 0 {
+        return -1
+    } else {
+        return 0
+    }
+}
+"#;
+        assert_eq!(cc(source), 3);
+    }
+
+    #[test]
+    fn test_guard_is_a_branch() {
+        let source = r#"
+func parse(_ text: String?) -> Int {
+    guard let text = text else {
+        return 0
+    }
+    guard !text.isEmpty else { fatalError("empty") }
+    return text.count
+}
+"#;
+        assert_eq!(cc(source), 3);
+    }
+
+    #[test]
+    fn test_loops() {
+        let source = r#"
+func loops(_ items: [Int]) {
+    for item in items {
+        print(item)
+    }
+    var i = 0
+    while i < 10 {
+        i += 1
+    }
+    repeat {
+        i -= 1
+    } while i > 0
+}
+"#;
+        assert_eq!(cc(source), 4);
+    }
+
+    #[test]
+    fn test_switch_counts_each_entry() {
+        let source = r#"
+func name(_ x: Int) -> String {
+    switch x {
+    case 1:
+        return "one"
+    case 2, 3:
+        return "few"
+    default:
+        return "many"
+    }
+}
+"#;
+        assert_eq!(cc(source), 4);
+    }
+
+    #[test]
+    fn test_do_catch_counts_each_catch() {
+        let source = r#"
+func load() {
+    do {
+        try read()
+    } catch ParseError.empty {
+        print("empty")
+    } catch {
+        print(error)
+    }
+}
+"#;
+        assert_eq!(cc(source), 3);
+    }
+
+    #[test]
+    fn test_break_and_continue_in_loop() {
+        let source = r#"
+func scan(_ items: [Int]) {
+    for item in items {
+        if item < 0 {
+            continue
+        }
+        if item > 100 {
+            break
+        }
+        print(item)
+    }
+}
+"#;
+        assert_eq!(cc(source), 4);
+    }
+}
@@ -0,0 +1,78 @@
+//! Swift language support
+//!
+//! Parses Swift source files using tree-sitter-swift. Initializers,
+//! deinitializers, and computed property accessors are functions too.
+
+pub mod cfg_builder;
+pub mod parser;
+
+pub use cfg_builder::SwiftCfgBuilder;
+pub use parser::SwiftParser;
+
+use crate::language::tree_sitter_utils::find_child_by_kind;
+use tree_sitter::Node;
+
+/// Node kinds that can be a discovered function. A `computed_property` is one
+/// only in the read-only shorthand form (`var area: Double { w * h }`).
+pub(crate) const FUNCTION_KINDS: &[&str] = &[
+    "function_declaration",
+    "init_declaration",
+    "deinit_declaration",
+    "computed_getter",
+    "computed_setter",
+    "computed_property",
+];
+
+/// Statement lists of the `{ ... }` blocks directly under `node`, in order.
+///
+/// tree-sitter-swift inlines blocks into their statement (`if`, `guard`,
+/// loops, `do`, `catch`, accessors) without field names, and omits the
+/// `statements` node of an empty block, so an empty block is `None`.
+pub(crate) fn blocks(node: Node<'_>) -> Vec<Option<Node<'_>>> {
+    let mut blocks = Vec::new();
+    let mut open = false;
+    let mut cursor = node.walk();
+    for child in node.children(&mut cursor) {
+        match child.kind() {
+            "{" => {
+                blocks.push(None);
+                open = true;
+            }
+            "}" => open = false,
+            "statements" if open => {
+                if let Some(last) = blocks.last_mut() {
+                    *last = Some(child);
+                }
+            }
+            _ => {}
+        }
+    }
+    blocks
+}
+
+/// Statement list of a function's body; `None` when the body is empty
+pub(crate) fn body_statements(func_node: Node<'_>) -> Option<Node<'_>> {
+    let container = find_child_by_kind(func_node, "function_body").unwrap_or(func_node);
+    blocks(container).into_iter().next().flatten()
+}
+
+/// Keyword of a `control_transfer_statement`: `return`, `throw`, `break`,
+/// `continue`, or `yield`
+pub(crate) fn control_transfer_keyword<'a>(node: Node<'_>, source: &'a str) -> &'a str {
+    let Some(first) = node.child(0) else {
+        return "";
+    };
+    &source[first.start_byte()..first.end_byte()]
+}
+
+/// Calls that never return (`fatalError()`, `preconditionFailure()`)
+pub(crate) fn is_fatal_call(node: Node<'_>, source: &str) -> bool {
+    node.kind() == "call_expression"
+        && node.child(0).is_some_and(|callee| {
+            callee.kind() == "simple_identifier"
+                && matches!(
+                    &source[callee.start_byte()..callee.end_byte()],
+                    "fatalError" | "preconditionFailure"
+                )
+        })
+}
@@ -0,0 +1,375 @@
+//! Swift language parser using tree-sitter
+
+use crate::ast::FunctionNode;
+use crate::language::parser::{LanguageParser, ParsedModule};
+use crate::language::tree_sitter_utils::{find_child_by_kind, syntax_errors};
+use anyhow::{Context, Result};
+use tree_sitter::{Node, Parser, Tree};
+
+/// Swift parser using tree-sitter
+pub struct SwiftParser;
+
+impl SwiftParser {
+    /// Create a new Swift parser
+    pub fn new() -> Result<Self> {
+        let mut parser = Parser::new();
+        let language = tree_sitter_swift::LANGUAGE;
+        parser
+            .set_language(&language.into())
+            .context("Failed to set Swift language for parser")?;
+        Ok(SwiftParser)
+    }
+}
+
+impl Default for SwiftParser {
+    fn default() -> Self {
+        Self::new().expect("Failed to create Swift parser")
+    }
+}
+
+impl LanguageParser for SwiftParser {
+    fn parse(&self, source: &str, filename: &str) -> Result<Box<dyn ParsedModule>> {
+        let mut parser = Parser::new();
+        let language = tree_sitter_swift::LANGUAGE;
+        parser
+            .set_language(&language.into())
+            .context("Failed to set Swift language")?;
+
+        let tree = parser
+            .parse(source, None)
+            .ok_or_else(|| anyhow::anyhow!("Failed to parse Swift file: {}", filename))?;
+
+        Ok(Box::new(SwiftModule {
+            tree,
+            source: source.to_string(),
+        }))
+    }
+}
+
+/// Parsed Swift module
+struct SwiftModule {
+    tree: Tree,
+    source: String,
+}
+
+impl ParsedModule for SwiftModule {
+    fn discover_functions(&self, file_index: usize, _source: &str) -> Vec<FunctionNode> {
+        let root = self.tree.root_node();
+        let mut functions = Vec::new();
+        discover_functions_recursive(root, &self.source, file_index, &mut functions);
+        functions.sort_by_key(|f| f.span.start);
+        functions
+    }
+
+    fn syntax_errors(&self) -> Vec<std::ops::Range<usize>> {
+        syntax_errors(self.tree.root_node())
+    }
+
+    fn top_level_decisions(&self) -> usize {
+        crate::metrics::swift_top_level_decisions(&self.tree.root_node())
+    }
+}
+
+/// Recursively discover functions in the Swift AST. Type bodies (`class_body`,
+/// `enum_class_body`) and function bodies are walked like any other node, so
+/// methods, extension members, and nested functions are all found.
+fn discover_functions_recursive(
+    node: Node,
+    source: &str,
+    file_index: usize,
+    functions: &mut Vec<FunctionNode>,
+) {
+    let is_function = match node.kind() {
+        "function_declaration"
+        | "init_declaration"
+        | "deinit_declaration"
+        | "computed_getter"
+        | "computed_setter" => true,
+        // Read-only shorthand (`var area: Double { w * h }`): the property's
+        // own block is the getter. With explicit accessors the block holds
+        // `computed_getter` / `computed_setter` nodes instead of statements.
+        "computed_property" => find_child_by_kind(node, "statements").is_some(),
+        _ => false,
+    };
+    if is_function {
+        if let Some(function_node) = extract_function(node, source, file_index, functions.len()) {
+            functions.push(function_node);
+        }
+    }
+
+    let mut cursor = node.walk();
+    for child in node.children(&mut cursor) {
+        discover_functions_recursive(child, source, file_index, functions);
+    }
+}
+
+/// Extract a FunctionNode from a declaration or accessor node
+fn extract_function(
+    node: Node,
+    source: &str,
+    file_index: usize,
+    local_index: usize,
+) -> Option<FunctionNode> {
+    use crate::ast::FunctionId;
+    use crate::language::{FunctionBody, SourceSpan};
+
+    let (name, declaration) = match node.kind() {
+        "function_declaration" => (
+            node.child_by_field_name("name")
+                .map(|name| source[name.start_byte()..name.end_byte()].to_string()),
+            node,
+        ),
+        "init_declaration" => (Some("init".to_string()), node),
+        "deinit_declaration" => (Some("deinit".to_string()), node),
+        _ => {
+            let (name, declaration) = accessor_name(node, source)?;
+            (Some(name), declaration)
+        }
+    };
+    let is_public = is_public_declaration(declaration, source);
+
+    // `func` / `init` / `deinit` wrap their block in `function_body`;
+    // accessors hold it directly
+    let body_node = match node.kind() {
+        "function_declaration" | "init_declaration" | "deinit_declaration" => {
+            find_child_by_kind(node, "function_body")?
+        }
+        _ => node,
+    };
+
+    let span = SourceSpan::new(
+        node.start_byte(),
+        node.end_byte(),
+        node.start_position().row as u32 + 1, // tree-sitter uses 0-indexed rows
+        node.end_position().row as u32 + 1,   // tree-sitter uses 0-indexed rows
+        SourceSpan::column_of(source, node.start_byte()),
+    );
+
+    let body = FunctionBody::Swift {
+        body_node: body_node.id(),
+        source: source.to_string(),
+    };
+
+    Some(FunctionNode {
+        id: FunctionId {
+            file_index,
+            local_index,
+        },
+        name,
+        owner: None,
+        span,
+        body,
+        suppression_reason: None, // Will be extracted separately
+        signature_complexity: 0,
+        params: crate::params::swift_params(node),
+        is_public,
+        is_async: false,
+    })
+}
+
+/// Name an accessor after its property (`total.get`, `total.set`) or
+/// `subscript.get` / `subscript.set`, and return the declaration that carries
+/// its modifiers
+fn accessor_name<'a>(node: Node<'a>, source: &str) -> Option<(String, Node<'a>)> {
+    let suffix = if node.kind() == "computed_setter" {
+        "set"
+    } else {
+        "get"
+    };
+    let mut declaration = node.parent()?;
+    while !matches!(
+        declaration.kind(),
+        "property_declaration" | "subscript_declaration"
+    ) {
+        declaration = declaration.parent()?;
+    }
+    let owner = if declaration.kind() == "subscript_declaration" {
+        "subscript".to_string()
+    } else {
+        let name = declaration.child_by_field_name("name")?;
+        source[name.start_byte()..name.end_byte()].to_string()
+    };
+    Some((format!("{owner}.{suffix}"), declaration))
+}
+
+/// A declaration is public API when declared `public` or `open`; Swift's
+/// default access level is `internal`
+fn is_public_declaration(node: Node, source: &str) -> bool {
+    let Some(modifiers) = find_child_by_kind(node, "modifiers") else {
+        return false;
+    };
+    let mut cursor = modifiers.walk();
+    let public = modifiers
+        .children(&mut cursor)
+        .filter(|modifier| modifier.kind() == "visibility_modifier")
+        .any(|modifier| {
+            matches!(
+                &source[modifier.start_byte()..modifier.end_byte()],
+                "public" | "open"
+            )
+        });
+    public
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    fn discover(source: &str) -> Vec<FunctionNode> {
+        let parser = SwiftParser::new().unwrap();
+        let module = parser.parse(source, "test.swift").unwrap();
+        module.discover_functions(0, source)
+    }
+
+    fn names(functions: &[FunctionNode]) -> Vec<&str> {
+        functions
+            .iter()
+            .map(|f| f.name.as_deref().unwrap_or(""))
+            .collect()
+    }
+
+    #[test]
+    fn test_create_parser() {
+        assert!(SwiftParser::new().is_ok());
+    }
+
+    #[test]
+    fn test_parse_top_level_function() {
+        let functions = discover(
+            r#"
+func add(_ a: Int, _ b: Int) -> Int {
+    return a + b
+}
+"#,
+        );
+        assert_eq!(names(&functions), vec!["add"]);
+        assert_eq!(functions[0].span.start_line, 2);
+    }
+
+    #[test]
+    fn test_parse_type_members() {
+        let functions = discover(
+            r#"
+class Account {
+    init(balance: Int) {
+        self.balance = balance
+    }
+
+    deinit {
+        close()
+    }
+
+    func deposit(_ amount: Int) {
+        balance += amount
+    }
+}
+
+struct Point {
+    func length() -> Double {
+        return 0
+    }
+}
+
+extension Account {
+    func withdraw(_ amount: Int) {
+        balance -= amount
+    }
+}
+"#,
+        );
+        assert_eq!(
+            names(&functions),
+            vec!["init", "deinit", "deposit", "length", "withdraw"]
+        );
+    }
+
+    #[test]
+    fn test_parse_computed_properties() {
+        let functions = discover(
+            r#"
+struct Rect {
+    var width = 0.0
+    var height = 0.0
+
+    var area: Double {
+        width * height
+    }
+
+    var size: Double {
+        get {
+            return width
+        }
+        set {
+            width = newValue
+        }
+    }
+}
+"#,
+        );
+        // Stored properties are not functions
+        assert_eq!(names(&functions), vec!["area.get", "size.get", "size.set"]);
+    }
+
+    #[test]
+    fn test_parse_nested_function() {
+        let functions = discover(
+            r#"
+func outer() {
+    func inner() {
+        print("inner")
+    }
+    inner()
+}
+"#,
+        );
+        assert_eq!(names(&functions), vec!["outer", "inner"]);
+    }
+
+    #[test]
+    fn test_parse_visibility() {
+        let functions = discover(
+            r#"
+public func api() {}
+open class Base {
+    open func hook() {}
+    private func helper() {}
+    func internalByDefault() {}
+    public var total: Int { 1 }
+}
+"#,
+        );
+        let public: Vec<(&str, bool)> = functions
+            .iter()
+            .map(|f| (f.name.as_deref().unwrap(), f.is_public))
+            .collect();
+        assert_eq!(
+            public,
+            vec![
+                ("api", true),
+                ("hook", true),
+                ("helper", false),
+                ("internalByDefault", false),
+                ("total.get", true),
+            ]
+        );
+    }
+
+    #[test]
+    fn test_parse_protocol_requirements_ignored() {
+        // Requirements have no body
+        let functions = discover(
+            r#"
+protocol Shape {
+    var area: Double { get }
+    func describe() -> String
+}
+"#,
+        );
+        assert!(functions.is_empty());
+    }
+
+    #[test]
+    fn test_parse_empty_file() {
+        assert!(discover("").is_empty());
+    }
+}
@@ -1,5 +1,6 @@
 use std::cell::RefCell;
 use std::hash::{Hash, Hasher};
+use std::ops::Range;
 use tree_sitter::{Node, Tree};
 
 pub fn find_child_by_kind<'a>(node: Node<'a>, kind: &str) -> Option<Node<'a>> {
@@ -10,6 +11,28 @@ pub fn find_child_by_kind<'a>(node: Node<'a>, kind: &str) -> Option<Node<'a>> {
     result
 }
 
+/// Byte ranges of the `ERROR` and `MISSING` nodes under `node`, in source
+/// order. Errors nested inside an `ERROR` node are part of it.
+pub fn syntax_errors(node: Node) -> Vec<Range<usize>> {
+    let mut errors = Vec::new();
+    collect_syntax_errors(node, &mut errors);
+    errors
+}
+
+fn collect_syntax_errors(node: Node, errors: &mut Vec<Range<usize>>) {
+    if node.is_error() || node.is_missing() {
+        errors.push(node.start_byte()..node.end_byte());
+        return;
+    }
+    if !node.has_error() {
+        return;
+    }
+    let mut cursor = node.walk();
+    for child in node.children(&mut cursor) {
+        collect_syntax_errors(child, errors);
+    }
+}
+
 pub fn find_function_by_start<'a>(
     node: Node<'a>,
     start_byte: usize,
@@ -104,3 +127,70 @@ make_parse_cache!(
 );
 
 make_parse_cache!(C_TREE_CACHE, with_cached_c_tree, tree_sitter_c::LANGUAGE);
+
+make_parse_cache!(
+    CPP_TREE_CACHE,
+    with_cached_cpp_tree,
+    tree_sitter_cpp::LANGUAGE
+);
+
+make_parse_cache!(
+    SWIFT_TREE_CACHE,
+    with_cached_swift_tree,
+    tree_sitter_swift::LANGUAGE
+);
+
+// The PHP grammar with HTML: text outside `<?php ... ?>` is a `text` node
+make_parse_cache!(
+    PHP_TREE_CACHE,
+    with_cached_php_tree,
+    tree_sitter_php::LANGUAGE_PHP
+);
+
+make_parse_cache!(
+    SCALA_TREE_CACHE,
+    with_cached_scala_tree,
+    tree_sitter_scala::LANGUAGE
+);
+
+make_parse_cache!(
+    DART_TREE_CACHE,
+    with_cached_dart_tree,
+    tree_sitter_dart::LANGUAGE
+);
+
+make_parse_cache!(
+    ELIXIR_TREE_CACHE,
+    with_cached_elixir_tree,
+    tree_sitter_elixir::LANGUAGE
+);
+
+make_parse_cache!(
+    LUA_TREE_CACHE,
+    with_cached_lua_tree,
+    tree_sitter_lua::LANGUAGE
+);
+
+make_parse_cache!(
+    BASH_TREE_CACHE,
+    with_cached_bash_tree,
+    tree_sitter_bash::LANGUAGE
+);
+
+make_parse_cache!(
+    ZIG_TREE_CACHE,
+    with_cached_zig_tree,
+    tree_sitter_zig::LANGUAGE
+);
+
+make_parse_cache!(
+    HASKELL_TREE_CACHE,
+    with_cached_haskell_tree,
+    tree_sitter_haskell::LANGUAGE
+);
+
+make_parse_cache!(
+    PERL_TREE_CACHE,
+    with_cached_perl_tree,
+    tree_sitter_perl::LANGUAGE
+);
@@ -0,0 +1,360 @@
+//! Zig CFG builder implementation
+//!
+//! An `if` or `switch` used as a value inside a statement is counted by the
+//! metrics extractor instead.
+
+use crate::ast::FunctionNode;
+use crate::cfg::{Cfg, NodeId, NodeKind};
+use crate::language::cfg_builder::{CfgBuilder, CfgState};
+use crate::language::tree_sitter_utils::with_cached_zig_tree;
+use crate::language::zig::{
+    as_block, block_statements, branch_statements, control_branches, control_keyword,
+    find_function, function_body, is_else_prong, is_panic, jump_keyword, label, prong_body,
+    switch_prongs, unwrap_statement,
+};
+use tree_sitter::Node;
+
+/// Zig CFG builder
+pub struct ZigCfgBuilder;
+
+impl CfgBuilder for ZigCfgBuilder {
+    fn build(&self, function: &FunctionNode) -> Cfg {
+        let (_body_node_id, source) = function.body.as_zig();
+
+        let result = with_cached_zig_tree(source, |root| {
+            let func_node = find_function(root, function.span.start)?;
+            let body = function_body(func_node)?;
+            let mut builder = ZigCfgBuilderState {
+                flow: CfgState::new(),
+                target_stack: Vec::new(),
+                source,
+            };
+            builder.visit_statements(&block_statements(body));
+            Some(builder.flow.finish())
+        });
+
+        result.unwrap_or_else(CfgState::straight_line)
+    }
+}
+
+/// An enclosing loop or labeled block: a `break` or `continue` target
+struct JumpTarget<'s> {
+    label: Option<&'s str>,
+    /// `continue` target; None for a block
+    header: Option<NodeId>,
+    /// `break` target, created on first use for a block, which may end
+    /// without reaching it
+    join: Option<NodeId>,
+}
+
+struct ZigCfgBuilderState<'s> {
+    flow: CfgState,
+    target_stack: Vec<JumpTarget<'s>>,
+    source: &'s str,
+}
+
+impl<'s> ZigCfgBuilderState<'s> {
+    fn visit_statements(&mut self, statements: &[Node]) {
+        for stmt in statements {
+            self.visit_node(stmt);
+        }
+    }
+
+    fn visit_node(&mut self, stmt: &Node) {
+        let node = unwrap_statement(*stmt);
+        let stmt_label = label(*stmt, self.source).or_else(|| label(node, self.source));
+        if let Some(block) = as_block(*stmt) {
+            return self.visit_block(block, stmt_label);
+        }
+        match control_keyword(node) {
+            Some("if") => self.visit_if(&node),
+            Some("for") | Some("while") => self.visit_loop(&node, stmt_label),
+            Some("switch") => self.visit_switch(&node),
+            _ => match jump_keyword(node) {
+                Some("return") => self.flow.jump_to_exit(),
+                Some("break") => self.visit_jump(label(node, self.source), false),
+                Some("continue") => self.visit_jump(label(node, self.source), true),
+                // `@panic(...)` and `unreachable` end the function like `return`
+                _ if is_panic(*stmt, self.source) => self.flow.jump_to_exit(),
+                // Declarations, assignments, calls, `defer`, and `errdefer`
+                _ => self.flow.statement(),
+            },
+        }
+    }
+
+    fn visit_branch(&mut self, from: NodeId, statements: &[Node], join: &mut Option<NodeId>) {
+        self.flow.start_branch(from);
+        self.visit_statements(statements);
+        self.flow.fall_through(join);
+    }
+
+    /// A block; a labeled one is the target of `break :label`
+    fn visit_block(&mut self, block: Node, block_label: Option<&'s str>) {
+        let Some(block_label) = block_label else {
+            return self.visit_statements(&block_statements(block));
+        };
+        self.target_stack.push(JumpTarget {
+            label: Some(block_label),
+            header: None,
+            join: None,
+        });
+        self.visit_statements(&block_statements(block));
+        let mut join = self.target_stack.pop().and_then(|target| target.join);
+        if join.is_some() {
+            self.flow.fall_through(&mut join);
+            self.flow.current_node = join;
+        }
+    }
+
+    /// `if` / `else`: an `else if` is an `if` in the `else` branch
+    fn visit_if(&mut self, node: &Node) {
+        let Some(condition_node) = self.flow.add_after(NodeKind::Condition) else {
+            return;
+        };
+
+        let (body, otherwise) = control_branches(*node);
+        let mut join_node = None;
+        let then_statements = body.map(branch_statements).unwrap_or_default();
+        self.visit_branch(condition_node, &then_statements, &mut join_node);
+        match otherwise {
+            Some(otherwise) => self.visit_branch(
+                condition_node,
+                &branch_statements(otherwise),
+                &mut join_node,
+            ),
+            None => self.flow.skip_branches(condition_node, &mut join_node),
+        }
+        self.flow.current_node = join_node;
+    }
+
+    /// `for` and `while`, `inline` or not: the header decides between the
+    /// body and leaving the loop, through its `else` branch when it has one.
+    /// The `else` branch runs when the loop ends without a `break`.
+    fn visit_loop(&mut self, node: &Node, loop_label: Option<&'s str>) {
+        let Some(loop_header) = self.flow.add_after(NodeKind::LoopHeader) else {
+            return;
+        };
+        let join_node = self.flow.cfg.add_node(NodeKind::Join);
+
+        let (body, otherwise) = control_branches(*node);
+        self.target_stack.push(JumpTarget {
+            label: loop_label,
+            header: Some(loop_header),
+            join: Some(join_node),
+        });
+        self.flow.start_branch(loop_header);
+        self.visit_statements(&body.map(branch_statements).unwrap_or_default());
+        self.flow.fall_through_to(loop_header);
+        self.target_stack.pop();
+
+        match otherwise {
+            Some(otherwise) => {
+                let mut join = Some(join_node);
+                self.visit_branch(loop_header, &branch_statements(otherwise), &mut join);
+            }
+            None => self.flow.cfg.add_edge(loop_header, join_node),
+        }
+        self.flow.current_node = Some(join_node);
+    }
+
+    /// `switch`: one branch per prong, and a path past the `switch` when no
+    /// prong is `else`. An enum `switch` without one is exhaustive, but the
+    /// path keeps every prong a decision.
+    fn visit_switch(&mut self, node: &Node) {
+        let Some(switch_node) = self.flow.add_after(NodeKind::Condition) else {
+            return;
+        };
+
+        let mut join_node = None;
+        let mut has_else = false;
+        for prong in switch_prongs(*node) {
+            has_else |= is_else_prong(prong);
+            let statements = prong_body(prong).map(branch_statements).unwrap_or_default();
+            self.visit_branch(switch_node, &statements, &mut join_node);
+        }
+        if !has_else {
+            self.flow.skip_branches(switch_node, &mut join_node);
+        }
+        self.flow.current_node = join_node;
+    }
+
+    /// `break` or `continue`, to the loop or block named by `target_label`,
+    /// or the innermost loop when there is no label or the builder does not
+    /// know it. Outside any loop it leaves like `return`.
+    fn visit_jump(&mut self, target_label: Option<&str>, is_continue: bool) {
+        if self.flow.current_node.is_none() {
+            return;
+        }
+        let labeled = target_label.and_then(|name| {
+            self.target_stack
+                .iter()
+                .rposition(|target| target.label == Some(name))
+        });
+        let index = labeled.or_else(|| {
+            self.target_stack
+                .iter()
+                .rposition(|target| target.header.is_some())
+        });
+        let cfg = &mut self.flow.cfg;
+        let to = index.and_then(|index| {
+            let target = &mut self.target_stack[index];
+            if is_continue {
+                target.header
+            } else {
+                Some(
+                    *target
+                        .join
+                        .get_or_insert_with(|| cfg.add_node(NodeKind::Join)),
+                )
+            }
+        });
+        match to {
+            Some(to) => self.flow.jump(to),
+            None => self.flow.jump_to_exit(),
+        }
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::language::parser::LanguageParser;
+    use crate::language::ZigParser;
+
+    /// CC of the first function in `source`
+    fn cc(source: &str) -> usize {
+        let module = ZigParser::new().unwrap().parse(source, "test.zig").unwrap();
+        let function = module
+            .discover_functions(0, source)
+            .into_iter()
+            .next()
+            .expect("No function found in test source");
+        let cfg = ZigCfgBuilder.build(&function);
+        assert!(
+            cfg.validate().is_ok(),
+            "CFG must be valid: {:?}",
+            cfg.validate()
+        );
+        // CC = E - N + 2
+        (cfg.edge_count() as isize - cfg.node_count() as isize + 2).max(1) as usize
+    }
+
+    #[test]
+    fn test_simple_function() {
+        assert_eq!(
+            cc("fn add(a: i32, b: i32) i32 {\n    return a + b;\n}\n"),
+            1
+        );
+        assert_eq!(cc("fn noop() void {}\n"), 1);
+    }
+
+    #[test]
+    fn test_else_if_chain() {
+        let source = r#"
+fn sign(x: i32) i32 {
+    if (x > 0) {
+        return 1;
+    } else if (x < 0) {
+        return -1;
+    } else {
+        return 0;
+    }
+}
+"#;
+        assert_eq!(cc(source), 3);
+    }
+
+    #[test]
+    fn test_loops() {
+        let source = r#"
+fn loops(items: []const i32) i32 {
+    var total: i32 = 0;
+    for (items) |item| {
+        if (item < 0) continue;
+        total += item;
+    }
+    var i: usize = 0;
+    while (i < 10) : (i += 1) {
+        if (i == 5) break;
+    }
+    inline for (.{ 1, 2 }) |n| {
+        total += n;
+    }
+    return total;
+}
+"#;
+        assert_eq!(cc(source), 6);
+    }
+
+    #[test]
+    fn test_loop_else_and_labels() {
+        let source = r#"
+fn find(rows: []const []const u8, needle: u8) bool {
+    outer: for (rows) |row| {
+        for (row) |c| {
+            if (c == needle) break :outer;
+        }
+    } else {
+        return false;
+    }
+    return true;
+}
+"#;
+        assert_eq!(cc(source), 4);
+    }
+
+    #[test]
+    fn test_switch_with_else() {
+        let source = r#"
+fn dispatch(c: u8) u8 {
+    switch (c) {
+        'a' => return 1,
+        'b', 'c' => return 2,
+        else => return 0,
+    }
+}
+"#;
+        assert_eq!(cc(source), 3);
+    }
+
+    #[test]
+    fn test_switch_without_else() {
+        let source = r#"
+const Color = enum { red, green };
+fn paint(c: Color) void {
+    switch (c) {
+        .red => draw(1),
+        .green => draw(2),
+    }
+    draw(0);
+}
+"#;
+        assert_eq!(cc(source), 3);
+    }
+
+    #[test]
+    fn test_comptime_block_is_entered() {
+        let source = r#"
+fn check(comptime T: type) void {
+    comptime {
+        if (@sizeOf(T) > 8) @compileError("too big");
+    }
+}
+"#;
+        assert_eq!(cc(source), 2);
+    }
+
+    #[test]
+    fn test_panic_and_unreachable_exit() {
+        let source = r#"
+fn get(x: ?u8) u8 {
+    if (x) |v| {
+        return v;
+    }
+    if (x == null) @panic("missing");
+    unreachable;
+}
+"#;
+        assert_eq!(cc(source), 3);
+    }
+}
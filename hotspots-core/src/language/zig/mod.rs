@@ -0,0 +1,336 @@
+//! Zig language support
+//!
+//! Parses Zig source files using tree-sitter-zig. A function in a container
+//! is named after it (`Parser.next`); code in `test` blocks is skipped.
+
+pub mod cfg_builder;
+pub mod parser;
+
+pub use cfg_builder::ZigCfgBuilder;
+pub use parser::ZigParser;
+
+use crate::language::tree_sitter_utils::find_function_by_start;
+use tree_sitter::Node;
+
+/// Node kinds of a discovered function
+pub(crate) const FUNCTION_KINDS: &[&str] = &["function_declaration"];
+
+/// Node kinds of a container type declaration (`struct { ... }`)
+pub(crate) const CONTAINER_KINDS: &[&str] = &[
+    "struct_declaration",
+    "union_declaration",
+    "enum_declaration",
+    "opaque_declaration",
+];
+
+/// Statement kinds that only wrap another statement or expression
+const WRAPPER_KINDS: &[&str] = &["expression_statement", "labeled_statement"];
+
+/// The function starting at `start_byte`
+pub(crate) fn find_function(root: Node<'_>, start_byte: usize) -> Option<Node<'_>> {
+    find_function_by_start(root, start_byte, FUNCTION_KINDS)
+}
+
+/// Function declarations, which are discovered on their own and are not part
+/// of the enclosing function's metrics (a function can only appear inside
+/// another through a container declared in its body)
+pub(crate) fn is_nested_definition(node: Node<'_>) -> bool {
+    FUNCTION_KINDS.contains(&node.kind())
+}
+
+/// Source text of `node`
+pub(crate) fn text<'a>(node: Node<'_>, source: &'a str) -> &'a str {
+    &source[node.start_byte()..node.end_byte()]
+}
+
+/// The body block of a function declaration; None for an extern prototype
+pub(crate) fn function_body(func_node: Node<'_>) -> Option<Node<'_>> {
+    if let Some(body) = func_node.child_by_field_name("body") {
+        return Some(body);
+    }
+    let mut cursor = func_node.walk();
+    let body = func_node
+        .children(&mut cursor)
+        .find(|child| child.kind() == "block");
+    body
+}
+
+/// Whether `node` is a label (`outer:`), as a node of its own or as an
+/// identifier followed by `:`
+fn is_label(node: Node<'_>) -> bool {
+    node.kind().contains("label")
+        || (node.kind() == "identifier" && node.next_sibling().is_some_and(|s| s.kind() == ":"))
+}
+
+/// Whether `node` decorates a construct without being part of its code: a
+/// comment, a label, a capture payload (`|item|`), or the continue expression
+/// of a `while` (`: (i += 1)`)
+fn is_decoration(node: Node<'_>) -> bool {
+    node.kind().contains("comment")
+        || is_label(node)
+        || node
+            .child(0)
+            .is_some_and(|first| matches!(first.kind(), "|" | ":"))
+}
+
+/// The statement or expression a wrapper statement holds, one level down
+fn wrapped(node: Node<'_>) -> Option<Node<'_>> {
+    if !WRAPPER_KINDS.contains(&node.kind()) {
+        return None;
+    }
+    let mut cursor = node.walk();
+    let inner = node
+        .named_children(&mut cursor)
+        .filter(|child| !is_decoration(*child))
+        .last();
+    inner
+}
+
+/// The statement or expression inside any wrapper statements
+pub(crate) fn unwrap_statement(node: Node<'_>) -> Node<'_> {
+    let mut node = node;
+    while let Some(inner) = wrapped(node) {
+        node = inner;
+    }
+    node
+}
+
+/// The block a `comptime { ... }`, `nosuspend { ... }`, or labeled block
+/// wraps
+fn wrapped_block(node: Node<'_>) -> Option<Node<'_>> {
+    let mut block = None;
+    let mut cursor = node.walk();
+    for child in node.children(&mut cursor) {
+        match child.kind() {
+            "block" if block.is_none() => block = Some(child),
+            "comptime" | "nosuspend" | ":" | ";" => {}
+            _ if is_decoration(child) => {}
+            _ => return None,
+        }
+    }
+    block
+}
+
+/// The block `stmt` is, once unwrapped: a plain, labeled, or `comptime`
+/// block
+pub(crate) fn as_block(stmt: Node<'_>) -> Option<Node<'_>> {
+    let node = unwrap_statement(stmt);
+    if node.kind() == "block" {
+        return Some(node);
+    }
+    wrapped_block(node)
+}
+
+/// Statements of a block, skipping comments and its label
+pub(crate) fn block_statements(block: Node<'_>) -> Vec<Node<'_>> {
+    let mut cursor = block.walk();
+    let statements = block
+        .named_children(&mut cursor)
+        .filter(|child| !is_decoration(*child))
+        .collect();
+    statements
+}
+
+/// The statements a branch runs: those of its block, or the branch itself
+/// for a single statement or expression (`if (done) return;`)
+pub(crate) fn branch_statements(branch: Node<'_>) -> Vec<Node<'_>> {
+    match as_block(branch) {
+        Some(block) => block_statements(block),
+        None => vec![branch],
+    }
+}
+
+/// The keyword a control construct starts with, past a label and `inline`:
+/// `if`, `for`, `while`, or `switch`
+pub(crate) fn control_keyword(node: Node<'_>) -> Option<&'static str> {
+    let mut cursor = node.walk();
+    for child in node.children(&mut cursor) {
+        match child.kind() {
+            "if" => return Some("if"),
+            "for" => return Some("for"),
+            "while" => return Some("while"),
+            "switch" => return Some("switch"),
+            "inline" | ":" => {}
+            _ if is_decoration(child) => {}
+            _ => return None,
+        }
+    }
+    None
+}
+
+/// Whether `node` is an `if`, `for`, `while`, or `switch`
+pub(crate) fn is_control(node: Node<'_>) -> bool {
+    control_keyword(node).is_some()
+}
+
+/// The body of an `if`, `for`, or `while` (its `then` branch for an `if`),
+/// and its `else` branch, if any
+pub(crate) fn control_branches(node: Node<'_>) -> (Option<Node<'_>>, Option<Node<'_>>) {
+    enum Part {
+        Keyword,
+        Header,
+        Condition,
+        Body,
+        Continue,
+        Else,
+    }
+
+    let (mut body, mut otherwise) = (None, None);
+    let mut part = Part::Keyword;
+    let mut cursor = node.walk();
+    for child in node.children(&mut cursor) {
+        let kind = child.kind();
+        match part {
+            Part::Keyword if matches!(kind, "if" | "for" | "while") => part = Part::Header,
+            Part::Keyword => {}
+            Part::Header if kind == "(" => part = Part::Condition,
+            // A condition the grammar wraps together with its parentheses
+            Part::Header if child.is_named() && !is_decoration(child) => part = Part::Body,
+            Part::Header => {}
+            Part::Condition if kind == ")" => part = Part::Body,
+            Part::Condition => {}
+            Part::Body if kind == "else" => part = Part::Else,
+            Part::Body if kind == ":" => part = Part::Continue,
+            Part::Body if body.is_none() && child.is_named() && !is_decoration(child) => {
+                body = Some(child)
+            }
+            Part::Body => {}
+            Part::Continue if kind == ")" => part = Part::Body,
+            Part::Continue => {}
+            Part::Else if otherwise.is_none() && child.is_named() && !is_decoration(child) => {
+                otherwise = Some(child)
+            }
+            Part::Else => {}
+        }
+    }
+    (body, otherwise)
+}
+
+/// Whether `node` is an `if` that is the `else` branch of another `if`: an
+/// `else if`, which continues its chain rather than nesting in it
+pub(crate) fn is_else_if(node: Node<'_>) -> bool {
+    if control_keyword(node) != Some("if") {
+        return false;
+    }
+    let mut parent = node.parent();
+    while let Some(p) = parent.filter(|p| wrapped(*p).is_some()) {
+        parent = p.parent();
+    }
+    parent
+        .filter(|p| control_keyword(*p) == Some("if"))
+        .and_then(|p| control_branches(p).1)
+        .is_some_and(|otherwise| unwrap_statement(otherwise).id() == node.id())
+}
+
+/// The prongs of a `switch`
+pub(crate) fn switch_prongs(node: Node<'_>) -> Vec<Node<'_>> {
+    let mut prongs = Vec::new();
+    let mut in_braces = false;
+    let mut cursor = node.walk();
+    for child in node.children(&mut cursor) {
+        if child.kind() == "{" {
+            in_braces = true;
+        } else if in_braces && child.is_named() && !is_decoration(child) {
+            prongs.push(child);
+        }
+    }
+    prongs
+}
+
+/// Whether `node` is a `switch` prong (`.a, .b => ...`)
+pub(crate) fn is_prong(node: Node<'_>) -> bool {
+    let mut cursor = node.walk();
+    let is_prong = node.children(&mut cursor).any(|child| child.kind() == "=>");
+    is_prong
+}
+
+/// Whether a prong is the `else` prong, which takes every other value
+pub(crate) fn is_else_prong(prong: Node<'_>) -> bool {
+    let mut cursor = prong.walk();
+    for child in prong.children(&mut cursor) {
+        match child.kind() {
+            "=>" => return false,
+            "else" | "switch_else" => return true,
+            _ => {}
+        }
+    }
+    false
+}
+
+/// The expression a prong evaluates, after `=>` and its payload
+pub(crate) fn prong_body(prong: Node<'_>) -> Option<Node<'_>> {
+    let mut after_arrow = false;
+    let mut cursor = prong.walk();
+    for child in prong.children(&mut cursor) {
+        if child.kind() == "=>" {
+            after_arrow = true;
+        } else if after_arrow && child.is_named() && !is_decoration(child) {
+            return Some(child);
+        }
+    }
+    None
+}
+
+/// The jump `node` is: `return`, `break`, or `continue`
+pub(crate) fn jump_keyword(node: Node<'_>) -> Option<&'static str> {
+    match node.child(0)?.kind() {
+        "return" => Some("return"),
+        "break" => Some("break"),
+        "continue" => Some("continue"),
+        _ => None,
+    }
+}
+
+/// The label of a construct (`outer: while ...`) or the target of a jump
+/// (`break :outer`), without the colon
+pub(crate) fn label<'a>(node: Node<'_>, source: &'a str) -> Option<&'a str> {
+    let mut cursor = node.walk();
+    let label = node
+        .children(&mut cursor)
+        .find(|child| is_label(*child))
+        .map(|label| text(label, source).trim().trim_matches(':').trim());
+    label.or_else(|| {
+        // `break :outer`: the colon and the name are separate tokens
+        let mut cursor = node.walk();
+        let mut children = node.children(&mut cursor).skip(1);
+        match (children.next(), children.next()) {
+            (Some(colon), Some(name)) if colon.kind() == ":" && name.kind() == "identifier" => {
+                Some(text(name, source))
+            }
+            _ => None,
+        }
+    })
+}
+
+/// Whether `node` ends the program: `@panic(...)` or `unreachable`
+pub(crate) fn is_panic(node: Node<'_>, source: &str) -> bool {
+    let code = text(node, source).trim().trim_end_matches(';').trim_end();
+    code == "unreachable" || code.starts_with("@panic(")
+}
+
+/// The children of `node` the CFG descends into: the statements of a block,
+/// the block of a `comptime` or labeled block, the statement a wrapper
+/// holds, the body and `else` branch of an `if`, `for`, or `while`, and the
+/// prongs of a `switch` and the expression of each. Anything else, such as
+/// an `if` inside an initializer or a `return` value, is part of a single
+/// statement.
+pub(crate) fn cfg_children(node: Node<'_>) -> Vec<Node<'_>> {
+    if is_nested_definition(node) {
+        return function_body(node).into_iter().collect();
+    }
+    if node.kind() == "block" {
+        return block_statements(node);
+    }
+    if let Some(inner) = wrapped(node).or_else(|| wrapped_block(node)) {
+        return vec![inner];
+    }
+    match control_keyword(node) {
+        Some("switch") => switch_prongs(node),
+        Some(_) => {
+            let (body, otherwise) = control_branches(node);
+            body.into_iter().chain(otherwise).collect()
+        }
+        None if is_prong(node) => prong_body(node).into_iter().collect(),
+        None => Vec::new(),
+    }
+}
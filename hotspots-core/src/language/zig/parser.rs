@@ -0,0 +1,362 @@
+//! Zig language parser using tree-sitter
+
+use crate::ast::FunctionNode;
+use crate::language::parser::{LanguageParser, ParsedModule};
+use crate::language::tree_sitter_utils::syntax_errors;
+use crate::language::zig::{function_body, is_nested_definition, text, CONTAINER_KINDS};
+use anyhow::{Context, Result};
+use tree_sitter::{Node, Parser, Tree};
+
+/// Zig parser using tree-sitter
+pub struct ZigParser;
+
+impl ZigParser {
+    /// Create a new Zig parser
+    pub fn new() -> Result<Self> {
+        let mut parser = Parser::new();
+        let language = tree_sitter_zig::LANGUAGE;
+        parser
+            .set_language(&language.into())
+            .context("Failed to set Zig language for parser")?;
+        Ok(ZigParser)
+    }
+}
+
+impl Default for ZigParser {
+    fn default() -> Self {
+        Self::new().expect("Failed to create Zig parser")
+    }
+}
+
+impl LanguageParser for ZigParser {
+    fn parse(&self, source: &str, filename: &str) -> Result<Box<dyn ParsedModule>> {
+        let mut parser = Parser::new();
+        let language = tree_sitter_zig::LANGUAGE;
+        parser
+            .set_language(&language.into())
+            .context("Failed to set Zig language")?;
+
+        let tree = parser
+            .parse(source, None)
+            .ok_or_else(|| anyhow::anyhow!("Failed to parse Zig file: {}", filename))?;
+
+        Ok(Box::new(ZigModule {
+            tree,
+            source: source.to_string(),
+        }))
+    }
+}
+
+/// Parsed Zig module
+struct ZigModule {
+    tree: Tree,
+    source: String,
+}
+
+impl ParsedModule for ZigModule {
+    fn discover_functions(&self, file_index: usize, _source: &str) -> Vec<FunctionNode> {
+        let root = self.tree.root_node();
+        let mut functions = Vec::new();
+        discover_functions_recursive(root, &self.source, file_index, &mut functions);
+        functions.sort_by_key(|f| f.span.start);
+        functions
+    }
+
+    fn syntax_errors(&self) -> Vec<std::ops::Range<usize>> {
+        syntax_errors(self.tree.root_node())
+    }
+}
+
+/// Recursively discover function declarations in the Zig AST, including
+/// those inside containers. Test blocks are not searched: they are not part
+/// of the program.
+fn discover_functions_recursive(
+    node: Node,
+    source: &str,
+    file_index: usize,
+    functions: &mut Vec<FunctionNode>,
+) {
+    if node.kind() == "test_declaration" {
+        return;
+    }
+
+    if is_nested_definition(node) && function_body(node).is_some() {
+        let function_node = extract_function(node, source, file_index, functions.len());
+        functions.push(function_node);
+    }
+
+    let mut cursor = node.walk();
+    for child in node.children(&mut cursor) {
+        discover_functions_recursive(child, source, file_index, functions);
+    }
+}
+
+/// Extract a FunctionNode from a function declaration. A function in a
+/// container is named after the containers, outermost first, and owned by
+/// the innermost one. It is public when it and every declaration enclosing
+/// it are `pub` (or `export`).
+fn extract_function(
+    node: Node,
+    source: &str,
+    file_index: usize,
+    local_index: usize,
+) -> FunctionNode {
+    use crate::ast::FunctionId;
+    use crate::language::{FunctionBody, SourceSpan};
+
+    let containers = container_names(node, source);
+    let name = node
+        .child_by_field_name("name")
+        .or_else(|| {
+            let mut cursor = node.walk();
+            let name = node
+                .named_children(&mut cursor)
+                .find(|child| child.kind() == "identifier");
+            name
+        })
+        .map(|name| {
+            let mut path = containers.clone();
+            path.push(text(name, source).to_string());
+            path.join(".")
+        });
+
+    let span = SourceSpan::new(
+        node.start_byte(),
+        node.end_byte(),
+        node.start_position().row as u32 + 1, // tree-sitter uses 0-indexed rows
+        node.end_position().row as u32 + 1,   // tree-sitter uses 0-indexed rows
+        SourceSpan::column_of(source, node.start_byte()),
+    );
+
+    let body = FunctionBody::Zig {
+        body_node: node.id(),
+        source: source.to_string(),
+    };
+
+    FunctionNode {
+        id: FunctionId {
+            file_index,
+            local_index,
+        },
+        name,
+        owner: containers.last().cloned(),
+        span,
+        body,
+        suppression_reason: None, // Will be extracted separately
+        signature_complexity: 0,
+        params: crate::params::zig_params(node, source),
+        is_public: is_public(node),
+        is_async: false,
+    }
+}
+
+/// Names of the containers a function is declared in, outermost first. A
+/// container is named by the declaration it is assigned to
+/// (`const Parser = struct { ... }`); one a function returns is named after
+/// that function. Anonymous containers are skipped.
+fn container_names(node: Node, source: &str) -> Vec<String> {
+    let mut names = Vec::new();
+    let mut current = node.parent();
+    while let Some(ancestor) = current {
+        if CONTAINER_KINDS.contains(&ancestor.kind()) {
+            if let Some(name) = container_name(ancestor, source) {
+                names.push(name.to_string());
+            }
+        }
+        current = ancestor.parent();
+    }
+    names.reverse();
+    names
+}
+
+fn container_name<'a>(container: Node, source: &'a str) -> Option<&'a str> {
+    let parent = container.parent()?;
+    if parent.kind() == "variable_declaration" {
+        let mut cursor = parent.walk();
+        let name = parent
+            .named_children(&mut cursor)
+            .find(|child| child.kind() == "identifier")
+            .map(|name| text(name, source));
+        return name;
+    }
+    let mut current = Some(parent);
+    while let Some(ancestor) = current {
+        if is_nested_definition(ancestor) {
+            return ancestor
+                .child_by_field_name("name")
+                .map(|name| text(name, source));
+        }
+        if CONTAINER_KINDS.contains(&ancestor.kind()) {
+            return None;
+        }
+        current = ancestor.parent();
+    }
+    None
+}
+
+/// Whether a declaration and every declaration enclosing it are `pub` or
+/// `export`
+fn is_public(node: Node) -> bool {
+    let mut current = Some(node);
+    while let Some(decl) = current {
+        if matches!(decl.kind(), "function_declaration" | "variable_declaration") {
+            let mut cursor = decl.walk();
+            let exported = decl
+                .children(&mut cursor)
+                .any(|child| matches!(child.kind(), "pub" | "export"));
+            if !exported {
+                return false;
+            }
+        }
+        current = decl.parent();
+    }
+    true
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    fn discover(source: &str) -> Vec<FunctionNode> {
+        let parser = ZigParser::new().unwrap();
+        let module = parser.parse(source, "test.zig").unwrap();
+        module.discover_functions(0, source)
+    }
+
+    fn names(functions: &[FunctionNode]) -> Vec<&str> {
+        functions
+            .iter()
+            .map(|f| f.name.as_deref().unwrap_or(""))
+            .collect()
+    }
+
+    #[test]
+    fn test_create_parser() {
+        assert!(ZigParser::new().is_ok());
+    }
+
+    #[test]
+    fn test_parse_functions() {
+        let functions = discover(
+            r#"const std = @import("std");
+
+pub fn add(a: i32, b: i32) i32 {
+    return a + b;
+}
+
+fn helper() void {}
+
+extern fn puts(s: [*:0]const u8) c_int;
+"#,
+        );
+        assert_eq!(names(&functions), vec!["add", "helper"]);
+        assert_eq!(functions[0].span.start_line, 3);
+        assert_eq!(functions[0].span.end_line, 5);
+        assert_eq!(functions[0].owner, None);
+    }
+
+    #[test]
+    fn test_parse_container_methods() {
+        let functions = discover(
+            r#"pub const Parser = struct {
+    pos: usize,
+
+    pub fn next(self: *Parser) ?u8 {
+        return null;
+    }
+
+    const Token = union(enum) {
+        word: []const u8,
+
+        fn len(self: Token) usize {
+            return 0;
+        }
+    };
+};
+
+pub fn List(comptime T: type) type {
+    return struct {
+        items: []T,
+
+        pub fn append(self: *@This(), item: T) void {
+            _ = item;
+        }
+    };
+}
+"#,
+        );
+        let owners: Vec<(&str, Option<&str>)> = functions
+            .iter()
+            .map(|f| (f.name.as_deref().unwrap(), f.owner.as_deref()))
+            .collect();
+        assert_eq!(
+            owners,
+            vec![
+                ("Parser.next", Some("Parser")),
+                ("Parser.Token.len", Some("Token")),
+                ("List", None),
+                ("List.append", Some("List")),
+            ]
+        );
+    }
+
+    #[test]
+    fn test_parse_visibility() {
+        let functions = discover(
+            r#"pub fn api() void {}
+fn internal() void {}
+export fn c_entry() void {}
+const Hidden = struct {
+    pub fn method() void {}
+};
+pub const Shown = struct {
+    pub fn method() void {}
+    fn private() void {}
+};
+"#,
+        );
+        let public: Vec<(&str, bool)> = functions
+            .iter()
+            .map(|f| (f.name.as_deref().unwrap(), f.is_public))
+            .collect();
+        assert_eq!(
+            public,
+            vec![
+                ("api", true),
+                ("internal", false),
+                ("c_entry", true),
+                ("Hidden.method", false),
+                ("Shown.method", true),
+                ("Shown.private", false),
+            ]
+        );
+    }
+
+    #[test]
+    fn test_tests_and_comptime_are_not_functions() {
+        let functions = discover(
+            r#"comptime {
+    @setEvalBranchQuota(1000);
+}
+
+test "add" {
+    const helper = struct {
+        fn twice(x: i32) i32 {
+            return x * 2;
+        }
+    };
+    _ = helper;
+}
+
+fn real() void {}
+"#,
+        );
+        assert_eq!(names(&functions), vec!["real"]);
+    }
+
+    #[test]
+    fn test_parse_empty_file() {
+        assert!(discover("").is_empty());
+        assert!(discover("const std = @import(\"std\");\n").is_empty());
+    }
+}
@@ -10,27 +10,52 @@
 // - Deterministic traversal order must be explicit
 // - Formatting, comments, and whitespace must not affect results
 // - Identical input yields byte-for-byte identical output
+// - File discovery order must not affect results (reports are canonically sorted)
 
 pub mod aggregates;
 pub mod analysis;
+pub mod analysis_cache;
+pub mod api;
 pub mod ast;
+pub mod bench;
 pub mod callgraph;
 pub mod cfg;
+pub mod churn;
 pub mod compact;
+pub mod compare;
+pub mod components;
 pub mod config;
 pub mod coupling;
+pub mod coverage;
+pub mod csv;
+#[cfg(unix)]
+pub mod daemon;
 pub mod db;
+pub mod dead_code;
 pub mod delta;
+pub mod dirs;
 pub mod discover;
 pub mod gate;
 pub mod git;
+pub mod gitignore;
+pub mod gitlab;
+pub mod god_functions;
+pub mod graphql;
+pub mod halstead;
 pub mod history_signals;
 pub mod html;
 pub mod imports;
+pub mod incremental;
 pub mod isolation_forest;
+pub mod junit;
 pub mod language;
+pub mod lines;
+pub mod markdown;
+pub mod metric_history;
 pub mod metrics;
 pub mod models;
+pub mod outliers;
+pub mod params;
 pub mod parser;
 pub mod patterns;
 pub mod phrases;
@@ -40,16 +65,27 @@ pub mod report;
 pub mod risk;
 pub mod sarif;
 pub mod scoring;
+pub mod severity;
+pub mod signature;
 pub mod snapshot;
+pub mod summary;
 pub mod suppression;
+pub mod test_code;
 pub mod touch_cache;
 pub mod trainer;
+pub mod tree;
+pub mod treemap;
 pub mod trends;
 
+pub use api::{analyze_path, analyze_source, AnalyzeOptions, FileReport, FunctionMetrics};
 pub use callgraph::CallGraph;
 pub use config::ResolvedConfig;
 pub use git::GitContext;
-pub use report::{render_json, render_text, render_text_grouped, sort_reports, FunctionRiskReport};
+pub use language::Language;
+pub use report::{
+    render_json, render_json_capped, render_json_report, render_jsonl_line, render_text,
+    render_text_grouped, render_text_ranked, sort_reports, FunctionRiskReport,
+};
 pub use snapshot::TouchMode;
 
 use anyhow::{Context, Result};
@@ -90,28 +126,240 @@ pub fn analyze_with_progress(
     resolved_config: Option<&ResolvedConfig>,
     progress: Option<&(dyn Fn(usize, usize) + Send + Sync)>,
 ) -> anyhow::Result<Vec<FunctionRiskReport>> {
-    use rayon::prelude::*;
-    use std::sync::atomic::{AtomicUsize, Ordering};
-
-    // Build weights/thresholds from config
-    let weights = resolved_config.map(|c| risk::LrsWeights {
-        cc: c.weight_cc,
-        nd: c.weight_nd,
-        fo: c.weight_fo,
-        ns: c.weight_ns,
-    });
-    let thresholds = resolved_config.map(|c| risk::RiskThresholds {
-        moderate: c.moderate_threshold,
-        high: c.high_threshold,
-        critical: c.critical_threshold,
-    });
-    let pattern_thresholds = resolved_config.map(|c| &c.pattern_thresholds);
+    if resolved_config.is_some_and(|c| c.dedup_symlinks && c.files.is_none()) {
+        return analyze_dedup_symlinks(path, options, resolved_config, progress);
+    }
 
     // Collect and filter source files upfront so the total is known before analysis begins
-    let source_files: Vec<_> = collect_source_files(path)?
+    let source_files = discover_source_files(path, resolved_config)?;
+    analyze_files(&source_files, options, resolved_config, progress)
+}
+
+/// The source files to analyze for `path`: the config's explicit `files` if
+/// set, otherwise those found by walking `path`, either way keeping only
+/// supported files the config includes.
+///
+/// Files come back sorted, so file indices and the order files are delivered
+/// in never depend on filesystem iteration order or the order of a file list;
+/// a file listed twice is analyzed once.
+pub(crate) fn discover_source_files(
+    path: &std::path::Path,
+    resolved_config: Option<&ResolvedConfig>,
+) -> Result<Vec<std::path::PathBuf>> {
+    let files = match resolved_config.and_then(|c| c.files.as_ref()) {
+        Some(files) => {
+            let mut files: Vec<_> = files
+                .iter()
+                .filter(|f| {
+                    f.file_name()
+                        .and_then(|n| n.to_str())
+                        .is_some_and(is_supported_source_file)
+                })
+                .cloned()
+                .collect();
+            files.sort();
+            files.dedup();
+            files
+        }
+        None => collect_source_files(path, resolved_config.map_or(true, |c| c.gitignore))?,
+    };
+    Ok(files
         .into_iter()
         .filter(|f| resolved_config.map_or(true, |c| c.should_include(f)))
+        .collect())
+}
+
+/// `dedup_symlinks` variant of [`analyze_with_progress`]: follow symlinks, analyze
+/// each underlying file once, and list the other paths that reach it in
+/// `aliases`.
+fn analyze_dedup_symlinks(
+    path: &std::path::Path,
+    options: AnalysisOptions,
+    resolved_config: Option<&ResolvedConfig>,
+    progress: Option<&(dyn Fn(usize, usize) + Send + Sync)>,
+) -> anyhow::Result<Vec<FunctionRiskReport>> {
+    let gitignore = resolved_config.map_or(true, |c| c.gitignore);
+    let sources: Vec<DedupedSource> = collect_source_files_dedup(path, gitignore)?
+        .into_iter()
+        .filter(|s| resolved_config.map_or(true, |c| c.should_include(&s.path)))
         .collect();
+    let files: Vec<_> = sources.iter().map(|s| s.path.clone()).collect();
+    let mut reports = analyze_files(&files, options, resolved_config, progress)?;
+
+    let aliases = alias_map(sources);
+    for report in &mut reports {
+        if let Some(a) = aliases.get(&report.file) {
+            report.aliases = a.clone();
+        }
+    }
+    Ok(reports)
+}
+
+/// Reported path → the other paths that reach the same file, for files with
+/// any
+fn alias_map(sources: Vec<DedupedSource>) -> std::collections::HashMap<String, Vec<String>> {
+    sources
+        .into_iter()
+        .filter(|s| !s.aliases.is_empty())
+        .map(|s| {
+            let aliases = s
+                .aliases
+                .iter()
+                .map(|a| a.to_string_lossy().to_string())
+                .collect();
+            (s.path.to_string_lossy().to_string(), aliases)
+        })
+        .collect()
+}
+
+/// Like [`analyze_with_config`], but hands each file's reports to `on_file`
+/// instead of collecting them, so output can start before the last file is
+/// analyzed and memory is bounded by the files in flight.
+///
+/// Files are analyzed in parallel but delivered in the order
+/// [`analyze_with_config`] walks them (a finished file waits for those before
+/// it), each file's reports in source order; files without reports are not
+/// delivered. `options.top_n` ranks every report and is ignored, and fan-in
+/// (which needs every file's callers) is not computed. Files that fail to
+/// analyze are skipped with a warning, and the first error from `on_file`
+/// stops the analysis and is returned.
+pub fn analyze_streaming(
+    path: &std::path::Path,
+    options: AnalysisOptions,
+    resolved_config: Option<&ResolvedConfig>,
+    on_file: impl FnMut(Vec<FunctionRiskReport>) -> Result<()> + Send,
+) -> Result<()> {
+    use rayon::prelude::*;
+    use std::collections::BTreeMap;
+    use std::sync::atomic::{AtomicUsize, Ordering};
+    use std::sync::Mutex;
+
+    /// Finished files waiting for those before them
+    struct Delivery<F> {
+        next: usize,
+        finished: BTreeMap<usize, Vec<FunctionRiskReport>>,
+        on_file: F,
+        /// `on_file` failed; files still in flight are dropped
+        stopped: bool,
+    }
+
+    let gitignore = resolved_config.map_or(true, |c| c.gitignore);
+    let dedup_symlinks = resolved_config.is_some_and(|c| c.dedup_symlinks && c.files.is_none());
+    let (source_files, aliases) = if dedup_symlinks {
+        let sources: Vec<DedupedSource> = collect_source_files_dedup(path, gitignore)?
+            .into_iter()
+            .filter(|s| resolved_config.map_or(true, |c| c.should_include(&s.path)))
+            .collect();
+        let files: Vec<_> = sources.iter().map(|s| s.path.clone()).collect();
+        (files, alias_map(sources))
+    } else {
+        let files = discover_source_files(path, resolved_config)?;
+        (files, std::collections::HashMap::new())
+    };
+
+    let delivery = Mutex::new(Delivery {
+        next: 0,
+        finished: BTreeMap::new(),
+        on_file,
+        stopped: false,
+    });
+    let skipped_files = AtomicUsize::new(0);
+    let files_with_parse_errors = AtomicUsize::new(0);
+    source_files
+        .par_iter()
+        .enumerate()
+        .try_for_each(|(file_index, file_path)| -> Result<()> {
+            let cm: Lrc<SourceMap> = Default::default();
+            let mut reports = match analysis::analyze_file_detailed(
+                file_path,
+                &cm,
+                file_index,
+                &options,
+                resolved_config,
+            ) {
+                Ok(analysis) => {
+                    if analysis.parse_errors > 0 {
+                        files_with_parse_errors.fetch_add(1, Ordering::Relaxed);
+                    }
+                    analysis.reports
+                }
+                Err(e) => {
+                    eprintln!("warning: skipping file {}: {}", file_path.display(), e);
+                    skipped_files.fetch_add(1, Ordering::Relaxed);
+                    Vec::new()
+                }
+            };
+            for report in &mut reports {
+                if let Some(a) = aliases.get(&report.file) {
+                    report.aliases = a.clone();
+                }
+            }
+
+            let mut guard = delivery.lock().unwrap();
+            let delivery = &mut *guard;
+            if delivery.stopped {
+                return Ok(());
+            }
+            delivery.finished.insert(file_index, reports);
+            while let Some(reports) = delivery.finished.remove(&delivery.next) {
+                delivery.next += 1;
+                if reports.is_empty() {
+                    continue;
+                }
+                if let Err(e) = (delivery.on_file)(reports) {
+                    delivery.stopped = true;
+                    return Err(e);
+                }
+            }
+            Ok(())
+        })?;
+
+    let skipped_files = skipped_files.into_inner();
+    if skipped_files > 0 {
+        eprintln!("Skipped {} file(s) due to analysis errors", skipped_files);
+    }
+    report_parse_errors(files_with_parse_errors.into_inner());
+    Ok(())
+}
+
+/// Summary line after the per-file syntax error warnings
+fn report_parse_errors(files: usize) {
+    if files > 0 {
+        eprintln!(
+            "{} file(s) had syntax errors; functions containing them were skipped",
+            files
+        );
+    }
+}
+
+/// Analyze an explicit list of files.
+///
+/// The output is canonical: reports are ordered by [`sort_reports`] (or, with
+/// `top_n`, the same order truncated), so permuting `source_files` never
+/// changes the result. Files are not filtered by `resolved_config` include or
+/// exclude globs; `progress` behaves as in [`analyze_with_progress`].
+pub fn analyze_files(
+    source_files: &[std::path::PathBuf],
+    options: AnalysisOptions,
+    resolved_config: Option<&ResolvedConfig>,
+    progress: Option<&(dyn Fn(usize, usize) + Send + Sync)>,
+) -> anyhow::Result<Vec<FunctionRiskReport>> {
+    analyze_files_with_stats(source_files, options, resolved_config, progress)
+        .map(|(reports, _)| reports)
+}
+
+/// Like [`analyze_files`], also counting the files loaded from the analysis
+/// cache. The cache (see [`analysis_cache`]) is read and updated when
+/// `resolved_config` enables `analysis_cache` and has a project `root`.
+pub fn analyze_files_with_stats(
+    source_files: &[std::path::PathBuf],
+    options: AnalysisOptions,
+    resolved_config: Option<&ResolvedConfig>,
+    progress: Option<&(dyn Fn(usize, usize) + Send + Sync)>,
+) -> anyhow::Result<(Vec<FunctionRiskReport>, incremental::CacheStats)> {
+    use rayon::prelude::*;
+    use std::sync::atomic::{AtomicUsize, Ordering};
+
     let total_files = source_files.len();
 
     if total_files > 0 {
@@ -120,47 +368,99 @@ pub fn analyze_with_progress(
         }
     }
 
+    // Callers below min_lrs still count toward fan-in, so it is applied after
+    let file_options = AnalysisOptions {
+        min_lrs: options
+            .min_lrs
+            .filter(|_| !resolved_config.is_some_and(|c| c.fan_in)),
+        top_n: options.top_n,
+    };
+
+    let mut cache = resolved_config
+        .filter(|c| c.analysis_cache)
+        .and_then(|c| Some((c.root.as_deref()?, c)))
+        .map(|(root, c)| (root, analysis_cache::read_analysis_cache(root, c)));
+
     // Parallel file analysis: each worker creates its own SourceMap (Lrc is !Send
     // so it cannot be shared, but creating one per-task on a single thread is safe).
     let counter = AtomicUsize::new(0);
-    let mut raw_results: Vec<(usize, &std::path::Path, Result<Vec<FunctionRiskReport>>)> =
-        source_files
-            .par_iter()
-            .enumerate()
-            .map(|(file_index, file_path)| {
-                let cm: Lrc<SourceMap> = Default::default();
-                let result = analysis::analyze_file_with_config(
+    let mut raw_results: Vec<_> = source_files
+        .par_iter()
+        .enumerate()
+        .map(|(file_index, file_path)| {
+            let result = match &cache {
+                Some((_, cache)) => analysis_cache::analyze_file(
+                    cache,
                     file_path,
-                    &cm,
                     file_index,
-                    &options,
-                    weights.as_ref(),
-                    thresholds.as_ref(),
-                    pattern_thresholds,
-                );
-                let done = counter.fetch_add(1, Ordering::Relaxed) + 1;
-                if let Some(f) = progress {
-                    f(done, total_files);
+                    &file_options,
+                    resolved_config,
+                ),
+                None => {
+                    let cm: Lrc<SourceMap> = Default::default();
+                    analysis::analyze_file_detailed(
+                        file_path,
+                        &cm,
+                        file_index,
+                        &file_options,
+                        resolved_config,
+                    )
+                    .map(|a| (a, analysis_cache::Outcome::Miss(None)))
                 }
-                (file_index, file_path.as_path(), result)
-            })
-            .collect();
+            };
+            let done = counter.fetch_add(1, Ordering::Relaxed) + 1;
+            if let Some(f) = progress {
+                f(done, total_files);
+            }
+            (file_index, file_path.as_path(), result)
+        })
+        .collect();
 
     // Restore deterministic ordering (parallel workers complete out of order)
     raw_results.sort_by_key(|(idx, _, _)| *idx);
 
+    let mut cache_hits = 0;
+    let raw_results: Vec<(usize, &std::path::Path, Result<analysis::FileAnalysis>)> = raw_results
+        .into_iter()
+        .map(|(file_index, file_path, result)| {
+            let result = result.map(|(analysis, outcome)| {
+                match (outcome, &mut cache) {
+                    (analysis_cache::Outcome::Hit, _) => cache_hits += 1,
+                    (analysis_cache::Outcome::Miss(Some(entry)), Some((_, cache))) => {
+                        cache.insert(file_path, entry)
+                    }
+                    (analysis_cache::Outcome::Miss(_), _) => {}
+                }
+                analysis
+            });
+            (file_index, file_path, result)
+        })
+        .collect();
+    // A cache that cannot be written only costs the next run its speedup
+    if let Some((root, mut cache)) = cache {
+        cache.retain_existing();
+        if let Err(e) = analysis_cache::write_analysis_cache(root, &cache) {
+            eprintln!("warning: failed to write analysis cache: {e:#}");
+        }
+    }
+
     let mut skipped_files: usize = 0;
+    let mut files_with_parse_errors: usize = 0;
 
-    let final_reports = if let Some(top_n) = options.top_n {
-        // Bounded min-heap: maintain at most top_n reports keyed by lrs ascending
-        // so the root is always the lowest score seen so far.
+    // Fan-in counts callers in every file, so it needs all reports first
+    let fan_in = resolved_config.filter(|c| c.fan_in);
+    let final_reports = if let (Some(top_n), None) = (options.top_n, fan_in) {
+        // Bounded heap: maintain at most top_n reports so the root is always the
+        // report that sorts last in canonical order. Ties on lrs are broken the
+        // same way `sort_reports` breaks them, so which report is evicted never
+        // depends on arrival order.
         use std::cmp::Ordering;
         use std::collections::BinaryHeap;
 
         struct MinByLrs(FunctionRiskReport);
         impl PartialEq for MinByLrs {
             fn eq(&self, other: &Self) -> bool {
-                self.0.lrs == other.0.lrs
+                self.cmp(other) == Ordering::Equal
             }
         }
         impl Eq for MinByLrs {}
@@ -171,20 +471,18 @@ pub fn analyze_with_progress(
         }
         impl Ord for MinByLrs {
             fn cmp(&self, other: &Self) -> Ordering {
-                // Reverse so BinaryHeap (max-heap) pops the lowest lrs first
-                other
-                    .0
-                    .lrs
-                    .partial_cmp(&self.0.lrs)
-                    .unwrap_or(Ordering::Equal)
+                // Canonical order puts the lowest lrs last, so the max-heap
+                // pops it first
+                report::canonical_order(&self.0, &other.0)
             }
         }
 
         let mut heap: BinaryHeap<MinByLrs> = BinaryHeap::with_capacity(top_n + 1);
         for (_file_index, file_path, result) in raw_results {
             match result {
-                Ok(reports) => {
-                    for r in reports {
+                Ok(analysis) => {
+                    files_with_parse_errors += usize::from(analysis.parse_errors > 0);
+                    for r in analysis.reports {
                         heap.push(MinByLrs(r));
                         if heap.len() > top_n {
                             heap.pop();
@@ -198,32 +496,47 @@ pub fn analyze_with_progress(
             }
         }
 
-        let mut v: Vec<FunctionRiskReport> = heap.into_iter().map(|w| w.0).collect();
-        v.sort_by(|a, b| b.lrs.partial_cmp(&a.lrs).unwrap_or(Ordering::Equal));
-        v
+        sort_reports(heap.into_iter().map(|w| w.0).collect())
     } else {
         let mut all_reports = Vec::new();
         for (_file_index, file_path, result) in raw_results {
             match result {
-                Ok(reports) => all_reports.extend(reports),
+                Ok(analysis) => {
+                    files_with_parse_errors += usize::from(analysis.parse_errors > 0);
+                    all_reports.extend(analysis.reports);
+                }
                 Err(e) => {
                     eprintln!("warning: skipping file {}: {}", file_path.display(), e);
                     skipped_files += 1;
                 }
             }
         }
-        sort_reports(all_reports)
+        if let Some(config) = fan_in {
+            let root = config.root.as_deref().unwrap_or(std::path::Path::new("."));
+            annotate_fan_in(&mut all_reports, root, &config.pattern_thresholds)?;
+            all_reports.retain(|r| !options.min_lrs.is_some_and(|min| r.lrs < min));
+        }
+        let mut sorted = sort_reports(all_reports);
+        if let Some(top_n) = options.top_n {
+            sorted.truncate(top_n);
+        }
+        sorted
     };
 
     if skipped_files > 0 {
         eprintln!("Skipped {} file(s) due to analysis errors", skipped_files);
     }
+    report_parse_errors(files_with_parse_errors);
 
-    Ok(final_reports)
+    let stats = incremental::CacheStats {
+        files: total_files,
+        cache_hits,
+    };
+    Ok((final_reports, stats))
 }
 
 /// Check if a file is a supported source file
-fn is_supported_source_file(filename: &str) -> bool {
+pub(crate) fn is_supported_source_file(filename: &str) -> bool {
     // Skip TypeScript declaration files (.d.ts)
     if filename.ends_with(".d.ts") {
         return false;
@@ -251,7 +564,13 @@ fn is_supported_source_file(filename: &str) -> bool {
 /// - Java: .java
 /// - Python: .py, .pyw
 /// - Rust: .rs
-pub(crate) fn collect_source_files(path: &std::path::Path) -> Result<Vec<std::path::PathBuf>> {
+///
+/// With `gitignore`, paths ignored by `.gitignore` files are skipped (see
+/// [`gitignore::GitignoreStack`]); an explicit file path is always collected.
+pub(crate) fn collect_source_files(
+    path: &std::path::Path,
+    gitignore: bool,
+) -> Result<Vec<std::path::PathBuf>> {
     let mut files = Vec::new();
 
     if path.is_file() {
@@ -261,7 +580,8 @@ pub(crate) fn collect_source_files(path: &std::path::Path) -> Result<Vec<std::pa
             }
         }
     } else if path.is_dir() {
-        collect_source_files_recursive(path, &mut files)?;
+        let mut gitignores = gitignore::GitignoreStack::for_walk(path, gitignore);
+        collect_source_files_recursive(path, &mut files, &mut gitignores)?;
     }
 
     // Sort files for deterministic order
@@ -273,7 +593,7 @@ pub(crate) fn collect_source_files(path: &std::path::Path) -> Result<Vec<std::pa
 /// Returns true for directory names that should not be traversed.
 /// These are pruned at walk time before any glob matching — keep this list
 /// to things that are unambiguously never first-party source code.
-fn is_skipped_dir(name: &str) -> bool {
+pub(crate) fn is_skipped_dir(name: &str) -> bool {
     matches!(
         name,
         "node_modules"
@@ -291,15 +611,124 @@ fn is_skipped_dir(name: &str) -> bool {
     ) || name.starts_with('.')
 }
 
+/// A source file together with every other discovered path that resolves to it.
+struct DedupedSource {
+    /// Path the file is reported under: the first path (in sorted order) not
+    /// reached through a symlink, or the first path if every route is a link.
+    path: std::path::PathBuf,
+    /// Remaining paths resolving to the same canonical file, sorted.
+    aliases: Vec<std::path::PathBuf>,
+}
+
+/// Like [`collect_source_files`], but follows symlinks (the default walk skips
+/// them) and collapses paths that canonicalize to the same file.
+fn collect_source_files_dedup(
+    path: &std::path::Path,
+    gitignore: bool,
+) -> Result<Vec<DedupedSource>> {
+    // (path, reached through a symlink)
+    let mut found: Vec<(std::path::PathBuf, bool)> = Vec::new();
+    if path.is_file() {
+        if path
+            .file_name()
+            .and_then(|n| n.to_str())
+            .is_some_and(is_supported_source_file)
+        {
+            found.push((path.to_path_buf(), false));
+        }
+    } else if path.is_dir() {
+        let mut gitignores = gitignore::GitignoreStack::for_walk(path, gitignore);
+        collect_following_symlinks(path, false, &mut Vec::new(), &mut gitignores, &mut found)?;
+    }
+    found.sort();
+
+    let mut by_canonical: std::collections::BTreeMap<std::path::PathBuf, Vec<_>> =
+        std::collections::BTreeMap::new();
+    for (file, via_symlink) in found {
+        let canonical = std::fs::canonicalize(&file).unwrap_or_else(|_| file.clone());
+        by_canonical
+            .entry(canonical)
+            .or_default()
+            .push((file, via_symlink));
+    }
+
+    let mut sources: Vec<DedupedSource> = by_canonical
+        .into_values()
+        .map(|mut paths| {
+            let primary = paths.iter().position(|(_, via)| !via).unwrap_or(0);
+            let (path, _) = paths.remove(primary);
+            DedupedSource {
+                path,
+                aliases: paths.into_iter().map(|(p, _)| p).collect(),
+            }
+        })
+        .collect();
+    sources.sort_by(|a, b| a.path.cmp(&b.path));
+    Ok(sources)
+}
+
+/// Walk `dir`, following symlinked files and directories. `ancestors` holds the
+/// canonical paths of the directories being walked so link cycles terminate.
+fn collect_following_symlinks(
+    dir: &std::path::Path,
+    via_symlink: bool,
+    ancestors: &mut Vec<std::path::PathBuf>,
+    gitignores: &mut gitignore::GitignoreStack,
+    found: &mut Vec<(std::path::PathBuf, bool)>,
+) -> Result<()> {
+    let canonical = std::fs::canonicalize(dir)
+        .with_context(|| format!("Failed to resolve directory: {}", dir.display()))?;
+    if ancestors.contains(&canonical) {
+        return Ok(());
+    }
+    ancestors.push(canonical);
+
+    for entry_result in std::fs::read_dir(dir)
+        .with_context(|| format!("Failed to read directory: {}", dir.display()))?
+    {
+        let path = entry_result?.path();
+        let is_link = std::fs::symlink_metadata(&path)
+            .with_context(|| format!("Failed to read metadata: {}", path.display()))?
+            .is_symlink();
+        // Dangling links have no target metadata; skip them
+        let Ok(metadata) = std::fs::metadata(&path) else {
+            continue;
+        };
+        let name = path.file_name().and_then(|n| n.to_str());
+        if gitignores.is_ignored(&path, metadata.is_dir()) {
+            continue;
+        }
+        if metadata.is_dir() {
+            if !name.is_some_and(is_skipped_dir) {
+                let entered = gitignores.enter(&path);
+                collect_following_symlinks(
+                    &path,
+                    via_symlink || is_link,
+                    ancestors,
+                    gitignores,
+                    found,
+                )?;
+                gitignores.leave(entered);
+            }
+        } else if metadata.is_file() && name.is_some_and(is_supported_source_file) {
+            found.push((path, via_symlink || is_link));
+        }
+    }
+
+    ancestors.pop();
+    Ok(())
+}
+
 /// Process one directory entry, pushing source files or recursing into dirs
 fn process_dir_entry(
     path: std::path::PathBuf,
     metadata: std::fs::Metadata,
     files: &mut Vec<std::path::PathBuf>,
+    gitignores: &mut gitignore::GitignoreStack,
 ) -> Result<()> {
     use std::ffi::OsStr;
 
-    if metadata.is_symlink() {
+    if metadata.is_symlink() || gitignores.is_ignored(&path, metadata.is_dir()) {
         return Ok(());
     }
 
@@ -309,7 +738,9 @@ fn process_dir_entry(
                 return Ok(());
             }
         }
-        collect_source_files_recursive(&path, files)?;
+        let entered = gitignores.enter(&path);
+        collect_source_files_recursive(&path, files, gitignores)?;
+        gitignores.leave(entered);
     } else if metadata.is_file() {
         if let Some(filename) = path.file_name().and_then(|n: &OsStr| n.to_str()) {
             if is_supported_source_file(filename) {
@@ -325,6 +756,7 @@ fn process_dir_entry(
 fn collect_source_files_recursive(
     dir: &std::path::Path,
     files: &mut Vec<std::path::PathBuf>,
+    gitignores: &mut gitignore::GitignoreStack,
 ) -> Result<()> {
     for entry_result in std::fs::read_dir(dir)
         .with_context(|| format!("Failed to read directory: {}", dir.display()))?
@@ -333,7 +765,7 @@ fn collect_source_files_recursive(
         let path = entry.path();
         let metadata = std::fs::symlink_metadata(&path)
             .with_context(|| format!("Failed to read metadata: {}", path.display()))?;
-        process_dir_entry(path, metadata, files)?;
+        process_dir_entry(path, metadata, files, gitignores)?;
     }
 
     Ok(())
@@ -378,7 +810,9 @@ fn build_name_index<'r>(
 ///
 /// Priority 1: same-file callee.
 /// Priority 2: callee in a file the caller explicitly imports.
-/// Priority 3: first name match (fallback).
+/// Priority 3: callee in the caller's directory — its package in Go and Java,
+/// whose files call each other without importing.
+/// Priority 4: first name match (fallback).
 /// Returns None for self-calls or unresolved names.
 fn resolve_callee(
     callee_name: &str,
@@ -411,14 +845,142 @@ fn resolve_callee(
         }
     }
 
-    // Priority 3: first match (fallback)
+    // Priority 3: same directory (package)
+    let caller_dir = parent_dir(&normalized_caller_file);
+    for &idx in possible_indices {
+        if idx != caller_idx && parent_dir(&reports[idx].file.replace('\\', "/")) == caller_dir {
+            return Some(idx);
+        }
+    }
+
+    // Priority 4: first match (fallback)
     possible_indices
         .first()
         .copied()
         .filter(|&idx| idx != caller_idx)
 }
 
-/// Add AST-derived edges to the graph; return (total_callee_names, resolved_callee_names)
+/// Directory part of a `/`-separated path, empty for a bare file name
+fn parent_dir(file: &str) -> &str {
+    file.rsplit_once('/').map_or("", |(dir, _)| dir)
+}
+
+/// Index Go methods, reported as `Type.Method`, by method name, so selector
+/// calls (`s.walk()`, `c.store.Get()`) can find them. Takes each function's
+/// `(file, name)` in report order.
+fn go_method_index<'a>(
+    functions: impl Iterator<Item = (&'a str, &'a str)>,
+) -> std::collections::HashMap<&'a str, Vec<usize>> {
+    let mut methods: std::collections::HashMap<&str, Vec<usize>> = std::collections::HashMap::new();
+    for (i, (file, function)) in functions.enumerate() {
+        if !file.ends_with(".go") {
+            continue;
+        }
+        if let Some((_, method)) = function.split_once('.') {
+            if !method.contains('.') {
+                methods.entry(method).or_default().push(i);
+            }
+        }
+    }
+    methods
+}
+
+/// Resolve a Go selector call whose text names no function as written.
+///
+/// `pkg.F` resolves to function `F` of an analyzed package directory named
+/// `pkg`, preferring one the caller imports (aliased imports are not
+/// followed). Otherwise the selector is a method call (`s.walk`,
+/// `c.store.Get`): receiver types are not inferred, so it resolves to a
+/// `Type.Method` of that name in the caller's file, then its package
+/// directory, then a package it imports. Returns `caller_idx` for a method
+/// calling itself, and None for calls into code outside the analysis
+/// (standard library, dependencies).
+fn resolve_go_selector<'n>(
+    callee_name: &str,
+    caller_idx: usize,
+    caller_file: &str,
+    file_of: impl Fn(usize) -> String,
+    functions_named: impl Fn(&str) -> Option<&'n Vec<usize>>,
+    methods: &std::collections::HashMap<&str, Vec<usize>>,
+    imports: Option<&std::collections::HashSet<String>>,
+) -> Option<usize> {
+    let (operand, member) = callee_name.rsplit_once('.')?;
+    let caller_dir = parent_dir(caller_file);
+    let imported_dirs: std::collections::HashSet<String> = imports
+        .into_iter()
+        .flatten()
+        .map(|file| parent_dir(&file.replace('\\', "/")).to_string())
+        .collect();
+
+    // Package function: `pkg.F`
+    if !operand.contains('.') {
+        let in_package: Vec<usize> = functions_named(member)
+            .into_iter()
+            .flatten()
+            .copied()
+            .filter(|&idx| {
+                let file = file_of(idx);
+                file.ends_with(".go") && parent_dir(&file).rsplit('/').next() == Some(operand)
+            })
+            .collect();
+        if let Some(idx) = in_package
+            .iter()
+            .copied()
+            .find(|&idx| imported_dirs.contains(parent_dir(&file_of(idx))))
+            .or_else(|| in_package.first().copied())
+        {
+            return Some(idx);
+        }
+    }
+
+    // Method: same file (a namesake before the caller itself), then package,
+    // then imported packages
+    let candidates = methods.get(member)?;
+    let same_file: Vec<usize> = candidates
+        .iter()
+        .copied()
+        .filter(|&idx| file_of(idx) == caller_file)
+        .collect();
+    if let Some(&idx) = same_file.iter().find(|&&idx| idx != caller_idx) {
+        return Some(idx);
+    }
+    if same_file.contains(&caller_idx) {
+        return Some(caller_idx);
+    }
+    candidates
+        .iter()
+        .copied()
+        .find(|&idx| parent_dir(&file_of(idx)) == caller_dir)
+        .or_else(|| {
+            candidates
+                .iter()
+                .copied()
+                .find(|&idx| imported_dirs.contains(parent_dir(&file_of(idx))))
+        })
+}
+
+/// Whether calling `callee_name` from report `caller_idx` is direct recursion:
+/// the caller's own name, with no other function of that name in its file.
+fn is_self_call(
+    callee_name: &str,
+    caller_idx: usize,
+    reports: &[FunctionRiskReport],
+    name_to_idx: &std::collections::HashMap<&str, Vec<usize>>,
+) -> bool {
+    let caller = &reports[caller_idx];
+    let caller_file = caller.file.replace('\\', "/");
+    callee_name == caller.function
+        && !name_to_idx.get(callee_name).is_some_and(|indices| {
+            indices.iter().any(|&idx| {
+                idx != caller_idx && reports[idx].file.replace('\\', "/") == caller_file
+            })
+        })
+}
+
+/// Add AST-derived edges to the graph, resolving Go selector calls against
+/// qualified names (see [`resolve_go_selector`]) and counting callees that
+/// match no analyzed function as external calls; return (total_callee_names,
+/// resolved_callee_names)
 fn add_callee_edges(
     reports: &[FunctionRiskReport],
     name_to_idx: &std::collections::HashMap<&str, Vec<usize>>,
@@ -426,6 +988,11 @@ fn add_callee_edges(
     graph: &mut callgraph::CallGraph,
     report_to_graph_idx: &[u32],
 ) -> (usize, usize) {
+    let go_methods = go_method_index(
+        reports
+            .iter()
+            .map(|r| (r.file.as_str(), r.function.as_str())),
+    );
     let mut total = 0usize;
     let mut resolved = 0usize;
     for (caller_report_idx, report) in reports.iter().enumerate() {
@@ -435,6 +1002,10 @@ fn add_callee_edges(
             total += 1;
             if name_to_idx.contains_key(callee_name.as_str()) {
                 resolved += 1;
+                if is_self_call(callee_name, caller_report_idx, reports, name_to_idx) {
+                    graph.add_self_call(caller_graph_idx);
+                    continue;
+                }
                 if let Some(callee_report_idx) = resolve_callee(
                     callee_name,
                     caller_report_idx,
@@ -448,6 +1019,31 @@ fn add_callee_edges(
                         graph.add_adj(caller_graph_idx, callee_graph_idx);
                     }
                 }
+            } else if let Some(callee_report_idx) = report
+                .file
+                .ends_with(".go")
+                .then(|| {
+                    resolve_go_selector(
+                        callee_name,
+                        caller_report_idx,
+                        &report.file.replace('\\', "/"),
+                        |idx| reports[idx].file.replace('\\', "/"),
+                        |name| name_to_idx.get(name),
+                        &go_methods,
+                        import_map.get(&report.file),
+                    )
+                })
+                .flatten()
+            {
+                resolved += 1;
+                let callee_graph_idx = report_to_graph_idx[callee_report_idx];
+                if callee_report_idx == caller_report_idx {
+                    graph.add_self_call(caller_graph_idx);
+                } else if added_callees.insert(callee_graph_idx) {
+                    graph.add_adj(caller_graph_idx, callee_graph_idx);
+                }
+            } else {
+                graph.add_external_call(caller_graph_idx);
             }
         }
     }
@@ -461,7 +1057,8 @@ fn add_callee_edges(
 /// Vec before calling this.
 ///
 /// Resolution priority is identical to `build_call_graph`: same-file first, then
-/// imported-file, then first name match.
+/// imported-file, then same-directory, then first name match, then Go selector
+/// calls against qualified names.
 pub fn build_call_graph_from_db(
     db: &db::TempDb,
     sha: &str,
@@ -486,6 +1083,14 @@ pub fn build_call_graph_from_db(
             .to_string();
         name_to_idx.entry(name).or_default().push(i);
     }
+    let go_methods = go_method_index(rows.iter().map(|(function_id, file, _)| {
+        (
+            file.as_str(),
+            function_id
+                .get(file.len() + 2..)
+                .unwrap_or(function_id.as_str()),
+        )
+    }));
 
     // Build import map for import-guided resolution.
     let file_list: Vec<&str> = rows.iter().map(|(_, f, _)| f.as_str()).collect();
@@ -507,6 +1112,15 @@ pub fn build_call_graph_from_db(
             total += 1;
             if let Some(candidates) = name_to_idx.get(callee_name.as_str()) {
                 resolved += 1;
+                // Direct recursion: the caller's own name, with no namesake in its file
+                let self_call = candidates.contains(&caller_idx)
+                    && !candidates.iter().any(|&idx| {
+                        idx != caller_idx && rows[idx].1.replace('\\', "/") == caller_file_norm
+                    });
+                if self_call {
+                    graph.add_self_call(caller_graph_idx);
+                    continue;
+                }
                 // Priority 1: same file
                 let mut chosen = None;
                 for &idx in candidates {
@@ -529,7 +1143,15 @@ pub fn build_call_graph_from_db(
                         }
                     }
                 }
-                // Priority 3: first match
+                // Priority 3: same directory (package)
+                if chosen.is_none() {
+                    let caller_dir = parent_dir(&caller_file_norm);
+                    chosen = candidates.iter().copied().find(|&idx| {
+                        idx != caller_idx
+                            && parent_dir(&rows[idx].1.replace('\\', "/")) == caller_dir
+                    });
+                }
+                // Priority 4: first match
                 if chosen.is_none() {
                     chosen = candidates.iter().copied().find(|&idx| idx != caller_idx);
                 }
@@ -539,6 +1161,31 @@ pub fn build_call_graph_from_db(
                         graph.add_adj(caller_graph_idx, callee_graph_idx);
                     }
                 }
+            } else if let Some(callee_idx) = caller_file
+                .ends_with(".go")
+                .then(|| {
+                    resolve_go_selector(
+                        callee_name,
+                        caller_idx,
+                        &caller_file_norm,
+                        |idx| rows[idx].1.replace('\\', "/"),
+                        |name| name_to_idx.get(name),
+                        &go_methods,
+                        import_map.get(caller_file.as_str()),
+                    )
+                })
+                .flatten()
+            {
+                // Go selector call (`pkg.F`, `c.store.Get`)
+                resolved += 1;
+                let callee_graph_idx = row_to_graph_idx[callee_idx];
+                if callee_idx == caller_idx {
+                    graph.add_self_call(caller_graph_idx);
+                } else if added.insert(callee_graph_idx) {
+                    graph.add_adj(caller_graph_idx, callee_graph_idx);
+                }
+            } else {
+                graph.add_external_call(caller_graph_idx);
             }
         }
     }
@@ -547,6 +1194,41 @@ pub fn build_call_graph_from_db(
     Ok(graph)
 }
 
+/// Set each report's `metrics.fi` to the number of distinct reports that call
+/// it, resolving callees as [`build_call_graph`] does, and add the patterns
+/// that fan-in enables (`hub_function`, `middle_man`).
+pub fn annotate_fan_in(
+    reports: &mut [FunctionRiskReport],
+    repo_root: &std::path::Path,
+    thresholds: &patterns::Thresholds,
+) -> Result<()> {
+    let graph = build_call_graph(reports, repo_root)?;
+    let fan_in = graph.build_fan_in_map();
+    for report in reports.iter_mut() {
+        let function_id = format!("{}::{}", report.file, report.function);
+        report.metrics.fi = fan_in.get(&function_id).copied().unwrap_or(0) as u32;
+
+        let t1 = patterns::Tier1Input {
+            cc: report.metrics.cc as usize,
+            nd: report.metrics.nd as usize,
+            fo: report.metrics.fo as usize,
+            ns: report.metrics.ns as usize,
+            loc: report.metrics.loc as usize,
+            arrow_depth: Some(report.arrow_depth),
+        };
+        let t2 = patterns::Tier2Input {
+            fan_in: Some(report.metrics.fi as usize),
+            scc_size: None,
+            churn_lines: None,
+            days_since_last_change: None,
+            neighbor_churn: None,
+            is_entrypoint: graph.is_entry_point(&function_id),
+        };
+        report.patterns = patterns::classify(&t1, &t2, thresholds);
+    }
+    Ok(())
+}
+
 /// Build a call graph from AST-derived callee names in function reports.
 pub fn build_call_graph(
     reports: &[FunctionRiskReport],
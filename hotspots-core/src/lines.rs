@@ -0,0 +1,181 @@
+//! Source, comment, and blank line counts from tree-sitter tokens
+//!
+//! Each physical line of a function (its LOC) is exactly one of:
+//!
+//! - source: holds part of a token other than a comment
+//! - comment: holds part of a comment node and nothing else
+//! - blank: holds neither
+//!
+//! so `sloc + comment_lines + blank_lines = loc`. A line with code and a
+//! trailing comment is a source line. Every line a string literal spans is a
+//! source line, even one that looks like a comment or is empty inside a
+//! multi-line string.
+//!
+//! Supported: Go, Java, Python, C#, C, C++, Swift, PHP, Scala, Dart, Elixir,
+//! Lua, Bash, Zig, Haskell, and Perl. TypeScript, JavaScript, Vue, Rust (parsed
+//! with SWC and syn), and SQL report none.
+
+use crate::ast::FunctionNode;
+use crate::language::tree_sitter_utils::{
+    with_cached_bash_tree, with_cached_c_tree, with_cached_cpp_tree, with_cached_csharp_tree,
+    with_cached_dart_tree, with_cached_elixir_tree, with_cached_go_tree, with_cached_haskell_tree,
+    with_cached_java_tree, with_cached_lua_tree, with_cached_perl_tree, with_cached_php_tree,
+    with_cached_python_tree, with_cached_scala_tree, with_cached_swift_tree, with_cached_zig_tree,
+};
+use crate::language::FunctionBody;
+use tree_sitter::Node;
+
+/// Line breakdown of one function
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub struct LineCounts {
+    pub sloc: u32,
+    pub comment_lines: u32,
+    pub blank_lines: u32,
+}
+
+/// Line breakdown of `function`, or None for languages without a
+/// tree-sitter grammar (see the module docs) and when the source no longer
+/// parses.
+pub fn function_line_counts(function: &FunctionNode) -> Option<LineCounts> {
+    let (start, end) = (function.span.start, function.span.end);
+    match &function.body {
+        FunctionBody::Go { source, .. } => {
+            with_cached_go_tree(source, |root| count_lines(root, start, end, source))
+        }
+        FunctionBody::Java { source, .. } => {
+            with_cached_java_tree(source, |root| count_lines(root, start, end, source))
+        }
+        FunctionBody::Python { source, .. } => {
+            with_cached_python_tree(source, |root| count_lines(root, start, end, source))
+        }
+        FunctionBody::CSharp { source, .. } => {
+            with_cached_csharp_tree(source, |root| count_lines(root, start, end, source))
+        }
+        FunctionBody::C { source, .. } => {
+            with_cached_c_tree(source, |root| count_lines(root, start, end, source))
+        }
+        FunctionBody::Cpp { source, .. } => {
+            with_cached_cpp_tree(source, |root| count_lines(root, start, end, source))
+        }
+        FunctionBody::Swift { source, .. } => {
+            with_cached_swift_tree(source, |root| count_lines(root, start, end, source))
+        }
+        FunctionBody::Php { source, .. } => {
+            with_cached_php_tree(source, |root| count_lines(root, start, end, source))
+        }
+        FunctionBody::Scala { source, .. } => {
+            with_cached_scala_tree(source, |root| count_lines(root, start, end, source))
+        }
+        FunctionBody::Dart { source, .. } => {
+            with_cached_dart_tree(source, |root| count_lines(root, start, end, source))
+        }
+        FunctionBody::Elixir { source, .. } => {
+            with_cached_elixir_tree(source, |root| count_lines(root, start, end, source))
+        }
+        FunctionBody::Lua { source, .. } => {
+            with_cached_lua_tree(source, |root| count_lines(root, start, end, source))
+        }
+        FunctionBody::Bash { source, .. } => {
+            with_cached_bash_tree(source, |root| count_lines(root, start, end, source))
+        }
+        FunctionBody::Zig { source, .. } => {
+            with_cached_zig_tree(source, |root| count_lines(root, start, end, source))
+        }
+        FunctionBody::Haskell { source, .. } => {
+            with_cached_haskell_tree(source, |root| count_lines(root, start, end, source))
+        }
+        FunctionBody::Perl { source, .. } => {
+            with_cached_perl_tree(source, |root| count_lines(root, start, end, source))
+        }
+        _ => None,
+    }
+}
+
+/// What a line holds, in increasing precedence
+#[derive(Clone, Copy, PartialEq, PartialOrd)]
+enum Line {
+    Blank,
+    Comment,
+    Source,
+}
+
+/// Classify every line of `start..end`
+fn count_lines(root: Node, start: usize, end: usize, source: &str) -> Option<LineCounts> {
+    fn mark(lines: &mut [Line], first: usize, node: Node, kind: Line) {
+        let (from, to) = (node.start_position().row, node.end_position().row);
+        for line in &mut lines[from - first..=to - first] {
+            if *line < kind {
+                *line = kind;
+            }
+        }
+    }
+
+    fn walk(node: Node, source: &str, range: (usize, usize), lines: &mut [Line], first: usize) {
+        if node.end_byte() <= range.0 || node.start_byte() >= range.1 {
+            return;
+        }
+        if node.kind().contains("comment") {
+            mark(lines, first, node, Line::Comment);
+        } else if node.kind().contains("string") || node.child_count() == 0 {
+            // A string literal counts whole, so lines inside it are source.
+            // Whitespace-only tokens such as Go's newline terminators and
+            // zero-width ones such as Python's indent markers hold no code.
+            if !source[node.start_byte()..node.end_byte()].trim().is_empty() {
+                mark(lines, first, node, Line::Source);
+            }
+        } else {
+            let mut cursor = node.walk();
+            for child in node.children(&mut cursor) {
+                walk(child, source, range, lines, first);
+            }
+        }
+    }
+
+    // Usually one node spans the function exactly, but a Dart signature and
+    // its body are siblings, so this can be their parent; only nodes inside
+    // start..end count
+    let function = root.descendant_for_byte_range(start, end)?;
+    let first = source[..start].matches('\n').count();
+    let last = first + source[start..end].matches('\n').count();
+    let mut lines = vec![Line::Blank; last - first + 1];
+    walk(function, source, (start, end), &mut lines, first);
+    let count = |kind: Line| lines.iter().filter(|&&line| line == kind).count() as u32;
+    Some(LineCounts {
+        sloc: count(Line::Source),
+        comment_lines: count(Line::Comment),
+        blank_lines: count(Line::Blank),
+    })
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::language::parser::LanguageParser;
+    use crate::language::{GoParser, PythonParser};
+
+    fn first_function(parser: &dyn LanguageParser, source: &str, filename: &str) -> FunctionNode {
+        let module = parser.parse(source, filename).unwrap();
+        module.discover_functions(0, source).remove(0)
+    }
+
+    fn counts(c: LineCounts) -> (u32, u32, u32) {
+        (c.sloc, c.comment_lines, c.blank_lines)
+    }
+
+    #[test]
+    fn test_go_lines() {
+        let source = "package p\n\nfunc F() {\n\t// setup\n\tx := 1 // trailing\n\n\t/* block\n\t   comment */\n\t_ = x\n}\n";
+        let function = first_function(&GoParser::new().unwrap(), source, "p.go");
+        // Source: `func`, `x := 1 // trailing`, `_ = x`, `}`. Comment: `// setup`
+        // and both lines of the block comment.
+        assert_eq!(counts(function_line_counts(&function).unwrap()), (4, 3, 1));
+    }
+
+    #[test]
+    fn test_multiline_string_is_source() {
+        let source = "def f():\n    s = \"\"\"\n    # not a comment\n\n    \"\"\"\n    return s\n";
+        let function = first_function(&PythonParser::new().unwrap(), source, "m.py");
+        // Every line of the docstring-like literal, the empty one included
+        assert_eq!(counts(function_line_counts(&function).unwrap()), (6, 0, 0));
+    }
+}
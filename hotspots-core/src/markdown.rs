@@ -0,0 +1,125 @@
+//! Markdown summary output (`--format markdown`)
+//!
+//! A compact report sized for a PR comment: one summary line over every
+//! analyzed function, then a table of the worst ones. Function names and paths
+//! are code spans, escaped so Markdown-special characters (`*`, `_`, `|`,
+//! backticks) render literally and never break the table.
+//!
+//! Global invariants enforced:
+//! - Deterministic output ordering (follows the input report order)
+
+use crate::report::FunctionRiskReport;
+use crate::risk::RiskBand;
+use crate::severity::SeverityFilter;
+use std::path::Path;
+
+/// Render `reports` (riskiest first) as a Markdown summary.
+///
+/// The summary line counts every report; the table lists the first `limit`
+/// reports with LRS at or above `min_lrs` that `min_severity` keeps, and
+/// notes how many it left out. Paths are relative to `base` when they fall
+/// under it.
+pub fn render_markdown(
+    reports: &[FunctionRiskReport],
+    base: &Path,
+    min_lrs: Option<f64>,
+    min_severity: Option<&SeverityFilter>,
+    limit: usize,
+) -> String {
+    let files: std::collections::HashSet<&str> = reports.iter().map(|r| r.file.as_str()).collect();
+    let high_or_critical = reports.iter().filter(|r| r.band >= RiskBand::High).count();
+    let mut out = format!(
+        "**Hotspots:** {} analyzed, {}, {} high or critical risk\n",
+        plural(files.len(), "file"),
+        plural(reports.len(), "function"),
+        high_or_critical
+    );
+
+    let shown: Vec<&FunctionRiskReport> = reports
+        .iter()
+        .filter(|r| min_lrs.map_or(true, |min| r.lrs >= min))
+        .filter(|r| min_severity.map_or(true, |filter| filter.keeps(r)))
+        .collect();
+    if shown.is_empty() {
+        return out;
+    }
+    out.push_str("\n| # | Function | Location | LRS | Band | CC | ND | FO | NS | LOC |\n");
+    out.push_str("|--:|---|---|--:|---|--:|--:|--:|--:|--:|\n");
+    for (i, r) in shown.iter().take(limit).enumerate() {
+        let location = format!(
+            "{}:{}",
+            crate::treemap::relative_path(&r.file, base),
+            r.line
+        );
+        out.push_str(&format!(
+            "| {} | {} | {} | {:.2} | {} | {} | {} | {} | {} | {} |\n",
+            i + 1,
+            code_span(&r.function),
+            code_span(&location),
+            r.lrs,
+            r.band.as_str(),
+            r.metrics.cc,
+            r.metrics.nd,
+            r.metrics.fo,
+            r.metrics.ns,
+            r.metrics.loc
+        ));
+    }
+    if shown.len() > limit {
+        out.push_str(&format!(
+            "\n_Showing the top {} of {}._\n",
+            limit,
+            plural(shown.len(), "function")
+        ));
+    }
+    out
+}
+
+/// `text` as an inline code span that is safe inside a table cell: fenced
+/// with more backticks than any run inside it, and with `|` escaped, which
+/// GitHub requires even within code spans in tables
+fn code_span(text: &str) -> String {
+    let longest_run = text.split(|c| c != '`').map(str::len).max().unwrap_or(0);
+    let fence = "`".repeat(longest_run + 1);
+    // A space keeps a leading or trailing backtick from joining the fence
+    let pad = if text.starts_with('`') || text.ends_with('`') {
+        " "
+    } else {
+        ""
+    };
+    format!(
+        "{fence}{pad}{}{pad}{fence}",
+        text.replace('|', "\\|"),
+        fence = fence,
+        pad = pad
+    )
+}
+
+fn plural(n: usize, noun: &str) -> String {
+    if n == 1 {
+        format!("{} {}", n, noun)
+    } else {
+        format!("{} {}s", n, noun)
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn test_code_span_escapes_markdown_specials() {
+        assert_eq!(code_span("__init__"), "`__init__`");
+        assert_eq!(code_span("a|b"), "`a\\|b`");
+        assert_eq!(code_span("tick`tock"), "``tick`tock``");
+        assert_eq!(code_span("`quoted`"), "`` `quoted` ``");
+        assert_eq!(code_span("a``b"), "```a``b```");
+    }
+
+    #[test]
+    fn test_plural() {
+        assert_eq!(plural(1, "file"), "1 file");
+        assert_eq!(plural(0, "file"), "0 files");
+        assert_eq!(plural(3, "function"), "3 functions");
+    }
+}
@@ -0,0 +1,283 @@
+//! Aggregate metric history recorded per commit by `analyze --record`
+//!
+//! Each recording is one JSON line appended to `.hotspots-history.jsonl` at
+//! the project root: the commit, its timestamp, the function count, total CC,
+//! and the riskiest function. `analyze --trend N` reads the file back and
+//! reports how those moved over the last N recordings.
+//!
+//! Global invariants enforced:
+//! - Recordings are append-only and kept in file order
+//! - Timestamps come from the commit, never the clock
+
+use crate::report::{sort_reports, FunctionRiskReport};
+use crate::snapshot::RepoPaths;
+use anyhow::{Context, Result};
+use serde::{Deserialize, Serialize};
+use std::io::Write;
+use std::path::{Path, PathBuf};
+
+/// History file name, relative to the project root
+pub const HISTORY_FILE: &str = ".hotspots-history.jsonl";
+
+/// Aggregate metrics for one commit
+#[derive(Debug, Clone, Serialize, Deserialize, PartialEq)]
+#[serde(rename_all = "snake_case")]
+pub struct Recording {
+    pub commit: String,
+    /// Commit timestamp (Unix seconds)
+    pub timestamp: i64,
+    pub functions: usize,
+    pub total_cc: u64,
+    /// Riskiest unsuppressed function; None when there are none
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub worst: Option<WorstFunction>,
+}
+
+/// The function with the highest LRS in a recording
+#[derive(Debug, Clone, Serialize, Deserialize, PartialEq)]
+#[serde(rename_all = "snake_case")]
+pub struct WorstFunction {
+    /// Repo-relative path (see [`RepoPaths`])
+    pub file: String,
+    pub function: String,
+    pub lrs: f64,
+    pub cc: u32,
+}
+
+impl WorstFunction {
+    fn id(&self) -> String {
+        format!("{}::{}", self.file, self.function)
+    }
+}
+
+impl Recording {
+    /// Aggregate `reports` (every analyzed function, unfiltered) for `commit`.
+    pub fn new(
+        commit: &str,
+        timestamp: i64,
+        reports: Vec<FunctionRiskReport>,
+        repo_root: &Path,
+    ) -> Self {
+        let functions = reports.len();
+        let total_cc = reports.iter().map(|r| u64::from(r.metrics.cc)).sum();
+        let paths = RepoPaths::new(repo_root);
+        let worst = sort_reports(reports)
+            .into_iter()
+            .find(|r| r.suppression_reason.is_none())
+            .map(|r| WorstFunction {
+                file: paths.portable(&r.file),
+                function: r.function,
+                lrs: r.lrs,
+                cc: r.metrics.cc,
+            });
+        Recording {
+            commit: commit.to_string(),
+            timestamp,
+            functions,
+            total_cc,
+            worst,
+        }
+    }
+}
+
+/// Path of the history file for `project_root`
+pub fn history_path(project_root: &Path) -> PathBuf {
+    project_root.join(HISTORY_FILE)
+}
+
+/// Append `recording` to the history file, creating it on the first recording.
+pub fn append_recording(project_root: &Path, recording: &Recording) -> Result<()> {
+    let path = history_path(project_root);
+    let line = serde_json::to_string(recording).context("failed to serialize recording")?;
+    let mut file = std::fs::OpenOptions::new()
+        .create(true)
+        .append(true)
+        .open(&path)
+        .with_context(|| format!("failed to open history file: {}", path.display()))?;
+    writeln!(file, "{line}")
+        .with_context(|| format!("failed to write history file: {}", path.display()))
+}
+
+/// All recordings in file order; empty when nothing has been recorded yet.
+pub fn read_recordings(project_root: &Path) -> Result<Vec<Recording>> {
+    let path = history_path(project_root);
+    if !path.exists() {
+        return Ok(Vec::new());
+    }
+    let content = std::fs::read_to_string(&path)
+        .with_context(|| format!("failed to read history file: {}", path.display()))?;
+    content
+        .lines()
+        .enumerate()
+        .filter(|(_, line)| !line.trim().is_empty())
+        .map(|(i, line)| {
+            serde_json::from_str(line)
+                .with_context(|| format!("{}:{}: invalid recording", path.display(), i + 1))
+        })
+        .collect()
+}
+
+/// A recording and how it differs from the one before it
+#[derive(Debug, Clone, Serialize, PartialEq)]
+#[serde(rename_all = "snake_case")]
+pub struct TrendEntry {
+    #[serde(flatten)]
+    pub recording: Recording,
+    /// Change in total CC since the previous recording; None for the first
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub total_cc_delta: Option<i64>,
+    /// Change in function count since the previous recording
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub functions_delta: Option<i64>,
+    /// Whether the riskiest function is a different one than before
+    pub worst_changed: bool,
+}
+
+/// The last `last` recordings, each compared with the one before it (which
+/// may lie outside the window).
+pub fn trend(recordings: &[Recording], last: usize) -> Vec<TrendEntry> {
+    let start = recordings.len().saturating_sub(last);
+    (start..recordings.len())
+        .map(|i| {
+            let current = &recordings[i];
+            let previous = i.checked_sub(1).map(|p| &recordings[p]);
+            TrendEntry {
+                recording: current.clone(),
+                total_cc_delta: previous.map(|p| current.total_cc as i64 - p.total_cc as i64),
+                functions_delta: previous.map(|p| current.functions as i64 - p.functions as i64),
+                worst_changed: previous.is_some_and(|p| {
+                    p.worst.as_ref().map(WorstFunction::id)
+                        != current.worst.as_ref().map(WorstFunction::id)
+                }),
+            }
+        })
+        .collect()
+}
+
+/// Render a trend as JSON (an array of entries, oldest first)
+pub fn render_trend_json(entries: &[TrendEntry]) -> Result<String> {
+    serde_json::to_string_pretty(entries).context("failed to serialize trend")
+}
+
+/// Render a trend as text: one line per recording, oldest first, then the
+/// overall change across the window.
+pub fn render_trend_text(entries: &[TrendEntry]) -> String {
+    let Some(last) = entries.last() else {
+        return format!(
+            "No recordings in {HISTORY_FILE} yet. Run `hotspots analyze --record` to add one.\n"
+        );
+    };
+    let mut out = String::new();
+    for entry in entries {
+        let r = &entry.recording;
+        let delta = entry
+            .total_cc_delta
+            .map_or_else(String::new, |d| format!(" ({d:+})"));
+        out.push_str(&format!(
+            "{}  {}  {} functions, total CC {}{}",
+            short_sha(&r.commit),
+            crate::html::format_timestamp(r.timestamp),
+            r.functions,
+            r.total_cc,
+            delta
+        ));
+        if let Some(worst) = &r.worst {
+            let marker = if entry.worst_changed { " (new)" } else { "" };
+            out.push_str(&format!(
+                ", worst {} LRS {:.2}{}",
+                worst.id(),
+                worst.lrs,
+                marker
+            ));
+        }
+        out.push('\n');
+    }
+
+    let first = &entries[0];
+    if entries.len() == 1 && first.total_cc_delta.is_none() {
+        out.push_str("\nOnly one recording so far; the trend starts with the next one.\n");
+        return out;
+    }
+    // The first entry's delta reaches back to the recording before the window
+    let cc_change: i64 = entries.iter().filter_map(|e| e.total_cc_delta).sum();
+    let direction = match cc_change {
+        0 => "flat",
+        c if c > 0 => "up",
+        _ => "down",
+    };
+    out.push_str(&format!(
+        "\nTotal CC {} ({:+}) over the last {} recording(s)",
+        direction,
+        cc_change,
+        entries.len()
+    ));
+    if let Some(worst) = &last.recording.worst {
+        out.push_str(&format!("; worst function now {}", worst.id()));
+    }
+    out.push('\n');
+    out
+}
+
+fn short_sha(sha: &str) -> &str {
+    sha.get(..8).unwrap_or(sha)
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    fn recording(commit: &str, total_cc: u64, worst: &str, lrs: f64) -> Recording {
+        Recording {
+            commit: commit.to_string(),
+            timestamp: 1_700_000_000,
+            functions: 10,
+            total_cc,
+            worst: Some(WorstFunction {
+                file: "src/app.ts".to_string(),
+                function: worst.to_string(),
+                lrs,
+                cc: 5,
+            }),
+        }
+    }
+
+    #[test]
+    fn test_append_and_read_round_trip() {
+        let dir = tempfile::tempdir().unwrap();
+        assert!(read_recordings(dir.path()).unwrap().is_empty());
+
+        let first = recording("aaaaaaaaaaaa", 40, "parse", 6.5);
+        let second = recording("bbbbbbbbbbbb", 46, "render", 7.25);
+        append_recording(dir.path(), &first).unwrap();
+        append_recording(dir.path(), &second).unwrap();
+
+        let read = read_recordings(dir.path()).unwrap();
+        assert_eq!(read, [first, second]);
+    }
+
+    #[test]
+    fn test_trend_deltas() {
+        let recordings = [
+            recording("aaaaaaaaaaaa", 40, "parse", 6.5),
+            recording("bbbbbbbbbbbb", 46, "parse", 7.0),
+            recording("cccccccccccc", 43, "render", 7.25),
+        ];
+
+        let entries = trend(&recordings, 2);
+        assert_eq!(entries.len(), 2);
+        assert_eq!(entries[0].total_cc_delta, Some(6));
+        assert!(!entries[0].worst_changed);
+        assert_eq!(entries[1].total_cc_delta, Some(-3));
+        assert!(entries[1].worst_changed);
+
+        let text = render_trend_text(&entries);
+        assert!(text.contains("Total CC up (+3) over the last 2 recording(s)"));
+        assert!(text.contains("worst function now src/app.ts::render"));
+
+        // The first recording ever has nothing to compare against
+        let entries = trend(&recordings[..1], 5);
+        assert_eq!(entries[0].total_cc_delta, None);
+        assert!(render_trend_text(&entries).contains("Only one recording so far"));
+        assert!(render_trend_text(&[]).contains("No recordings"));
+    }
+}
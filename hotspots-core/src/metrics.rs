@@ -6,6 +6,10 @@
 
 use crate::ast::FunctionNode;
 use crate::cfg::Cfg;
+use crate::language::sql::lexer::{Token as SqlToken, TokenKind as SqlTokenKind};
+use crate::language::SqlDialect;
+use serde::{Deserialize, Serialize};
+use std::collections::BTreeMap;
 use swc_ecma_ast::*;
 use swc_ecma_visit::{Visit, VisitWith};
 
@@ -13,13 +17,451 @@ use swc_ecma_visit::{Visit, VisitWith};
 #[derive(Debug, Clone)]
 pub struct RawMetrics {
     pub cc: usize,
+    /// Cognitive complexity: structures cost 1 plus their nesting level (see
+    /// `ts_cognitive_complexity`). 0 for SQL.
+    pub cognitive: usize,
     pub nd: usize,
+    /// Where ND is reached: the start of the first construct, in source
+    /// order, at the deepest level. None when ND is 0 and for SQL.
+    pub nd_position: Option<NestPosition>,
     pub fo: usize,
     pub ns: usize,
+    /// The exits behind `ns`, per kind; totals `ns`.
+    pub ns_breakdown: NsBreakdown,
     pub loc: usize,
     /// Callee names extracted from AST (for tree-sitter languages).
     /// Empty for ECMAScript/Rust (which retain regex-based call graph extraction).
     pub callee_names: Vec<String>,
+    /// Arrow depth: how many nested `if`/loop levels the whole body is buried
+    /// under (see `ts_arrow_depth`). Not a reported metric — feeds the
+    /// `arrow_code` pattern.
+    pub arrow_depth: usize,
+    /// Type-parameter count plus deepest type nesting in the signature (see
+    /// `signature`). 0 for languages without rich type syntax.
+    pub signature_complexity: usize,
+    /// Leading early-exit guards in the body and in its top-level loops (see
+    /// `ts_guard_clauses`). 0 for SQL.
+    pub guard_clauses: usize,
+    /// Most `&&` / `||` operators in a single boolean expression (see
+    /// `ts_max_condition_ops`). 0 for SQL.
+    pub max_condition_ops: usize,
+    /// Decision points behind `cc`, per construct. None for SQL and when the
+    /// body could not be re-parsed.
+    pub cc_breakdown: Option<CcBreakdown>,
+    /// Where each decision point counted in `cc_breakdown` starts, in the
+    /// order the breakdown walk found them. Empty when `cc_breakdown` is None.
+    pub decisions: Vec<Decision>,
+    /// Switch statements, for recounting CC under a [`CcMode`]. Empty in
+    /// languages without them.
+    pub switches: Vec<SwitchSite>,
+    /// `await` expressions inside a loop body, which run one after another
+    /// (see `await_in_loop`). 0 outside JavaScript/TypeScript.
+    pub await_in_loop: usize,
+}
+
+/// Start of a construct as recorded by a metric walk: the construct at which
+/// ND is reached, or a CC decision point. Turned into a file line by
+/// [`nest_line`].
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub enum NestPosition {
+    /// 1-based line in the file (tree-sitter and Rust bodies)
+    Line(u32),
+    /// Position in the shared `SourceMap` (ECMAScript bodies)
+    Byte(swc_common::BytePos),
+}
+
+/// The 1-based file line of `position` inside `function`.
+///
+/// ECMAScript positions are measured from the function's own start, so the
+/// line offset of a Vue `<script>` block carries over.
+pub fn nest_line(
+    function: &FunctionNode,
+    position: NestPosition,
+    source_map: &swc_common::SourceMap,
+) -> u32 {
+    match position {
+        NestPosition::Line(line) => line,
+        NestPosition::Byte(pos) => {
+            let line_of = |pos| source_map.lookup_char_pos(pos).line as u32;
+            let start = line_of(swc_common::BytePos(function.span.start as u32));
+            function.span.start_line + line_of(pos).saturating_sub(start)
+        }
+    }
+}
+
+/// Control-structure families that can be switched in or out of ND with the
+/// `nd_counts` config key
+#[derive(Debug, Clone, Copy, PartialEq, Eq, PartialOrd, Ord)]
+pub enum NestingConstruct {
+    /// `if`, Swift `guard`, Dart collection `if`, Elixir and Perl `unless`;
+    /// Haskell guards, multi-way `if`, `when`, and `unless`
+    If,
+    /// `for`, `for…in` / `for…of`, `foreach`, Java enhanced `for`, C++
+    /// range-based `for`, Dart collection `for`, Bash `select`
+    For,
+    /// `while`, `do…while`, Swift `repeat…while`, Rust `loop`, Lua
+    /// `repeat…until`, Bash and Perl `until`
+    While,
+    /// `switch` statements and expressions, Go type switches and `select`,
+    /// Bash `case`
+    Switch,
+    /// `try` / `catch`, Swift `do` / `catch`
+    Try,
+    /// Rust, Python, PHP, and Scala `match`; Elixir `case`, `cond`, `with`,
+    /// and `receive`; Haskell `case` and `\case`
+    Match,
+}
+
+impl NestingConstruct {
+    pub const ALL: [NestingConstruct; 6] = [
+        NestingConstruct::If,
+        NestingConstruct::For,
+        NestingConstruct::While,
+        NestingConstruct::Switch,
+        NestingConstruct::Try,
+        NestingConstruct::Match,
+    ];
+
+    /// Name used in config files
+    pub fn name(self) -> &'static str {
+        match self {
+            NestingConstruct::If => "if",
+            NestingConstruct::For => "for",
+            NestingConstruct::While => "while",
+            NestingConstruct::Switch => "switch",
+            NestingConstruct::Try => "try",
+            NestingConstruct::Match => "match",
+        }
+    }
+
+    /// Parse a config name (as returned by `name()`)
+    pub fn from_name(s: &str) -> Option<Self> {
+        Self::ALL.into_iter().find(|c| c.name() == s)
+    }
+}
+
+/// Which [`NestingConstruct`]s increment ND.
+///
+/// The default counts all of them, which is the built-in behavior. Constructs
+/// outside these families (Python `with`, Java `synchronized`) always count;
+/// SQL nesting is not configurable.
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub struct NdCounts(u8);
+
+impl Default for NdCounts {
+    fn default() -> Self {
+        NdCounts::from_constructs(&NestingConstruct::ALL)
+    }
+}
+
+impl NdCounts {
+    pub fn from_constructs(constructs: &[NestingConstruct]) -> Self {
+        NdCounts(constructs.iter().fold(0, |mask, &c| mask | 1 << c as u8))
+    }
+
+    /// Whether `construct` increments ND
+    pub fn counts(self, construct: NestingConstruct) -> bool {
+        self.0 & (1 << construct as u8) != 0
+    }
+
+    /// Counted constructs, in [`NestingConstruct::ALL`] order
+    pub fn constructs(self) -> Vec<NestingConstruct> {
+        NestingConstruct::ALL
+            .into_iter()
+            .filter(|&c| self.counts(c))
+            .collect()
+    }
+}
+
+/// How switch statements count toward CC (`cc_mode`, `--cc-mode`).
+///
+/// The default, `Cases`, is the built-in count: each clause adds a decision
+/// point. `Statement` counts a switch once, however many clauses it has.
+/// `Mccabe` counts the edges out of the switch beyond the first: one per
+/// clause, plus the path past a switch that has no `default`, minus one.
+#[derive(Debug, Clone, Copy, Default, PartialEq, Eq, Serialize, Deserialize)]
+#[serde(rename_all = "lowercase")]
+pub enum CcMode {
+    #[default]
+    Cases,
+    Statement,
+    Mccabe,
+}
+
+impl CcMode {
+    /// Parse from the config / CLI name ("cases", "statement", or "mccabe")
+    pub fn from_name(s: &str) -> Option<Self> {
+        match s {
+            "cases" => Some(CcMode::Cases),
+            "statement" => Some(CcMode::Statement),
+            "mccabe" => Some(CcMode::Mccabe),
+            _ => None,
+        }
+    }
+}
+
+/// A switch statement as [`cc_with_mode`] recounts it
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub struct SwitchSite {
+    /// Start byte of the statement: its key in [`Cfg::switches`]
+    pub start: usize,
+    /// `case` / `default` clauses (labels sharing a body are one clause)
+    pub clauses: usize,
+    /// Whether some clause always runs: the switch has a `default`, or never
+    /// falls past its clauses (Go `select`, Swift `switch`)
+    pub exhaustive: bool,
+    /// Decision points CC adds for the clauses on top of the dispatch node's
+    /// edges: one per clause in JavaScript/TypeScript and Go, else 0
+    pub counted_clauses: usize,
+}
+
+/// Construct that adds a decision point to CC
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub enum DecisionKind {
+    /// `if`, `else if` / `elif` / `elseif`, Python comprehension filters,
+    /// Scala guards, Dart collection `if`, Elixir `unless`, guards (`when`),
+    /// and comprehension filters, Haskell guards other than `otherwise`,
+    /// `when`, and `unless`, Perl `unless` and `if` / `unless` statement
+    /// modifiers
+    If,
+    /// `for`, `foreach`, `while`, `do…while`, Rust `loop`, Dart collection
+    /// `for`, Lua `repeat…until`, Bash `until` and `select`, Perl `until`
+    /// and `while` / `until` / `for` statement modifiers
+    Loop,
+    /// `case` / `default` labels, switch-expression arms, Python `case`,
+    /// Bash `case` clauses other than `*)`, Zig `switch` prongs other than
+    /// `else`
+    Case,
+    /// `catch` / `except` clauses, JS/TS promise rejection handlers
+    /// (`.catch(f)`, `.then(f, g)`), and Zig `catch` and `try`
+    Catch,
+    /// Rust, PHP, and Scala `match` arms; Elixir `case`, `cond`, and
+    /// `receive` clauses, `with` matches and `else` clauses, and every
+    /// function clause after the first; Haskell `case` alternatives other
+    /// than a final catch-all, and every equation after the first
+    MatchArm,
+    /// `cond ? a : b`, Python `a if cond else b`
+    Ternary,
+    /// `&&`, Python, Elixir, Lua, Zig, and Perl `and`, the extra conditions
+    /// of a Haskell guard (`| x > 0, even x`)
+    And,
+    /// `||`, Python, Elixir, Lua, Zig, and Perl `or`
+    Or,
+    /// C# and PHP `??`; Dart `??`, `??=`, and `?.`; Zig `orelse`; Perl `//`
+    Coalesce,
+}
+
+impl DecisionKind {
+    pub const ALL: [DecisionKind; 9] = [
+        DecisionKind::If,
+        DecisionKind::Loop,
+        DecisionKind::Case,
+        DecisionKind::Catch,
+        DecisionKind::MatchArm,
+        DecisionKind::Ternary,
+        DecisionKind::And,
+        DecisionKind::Or,
+        DecisionKind::Coalesce,
+    ];
+
+    /// Key used in snapshots
+    pub fn name(self) -> &'static str {
+        match self {
+            DecisionKind::If => "if",
+            DecisionKind::Loop => "loop",
+            DecisionKind::Case => "case",
+            DecisionKind::Catch => "catch",
+            DecisionKind::MatchArm => "match_arm",
+            DecisionKind::Ternary => "ternary",
+            DecisionKind::And => "and",
+            DecisionKind::Or => "or",
+            DecisionKind::Coalesce => "coalesce",
+        }
+    }
+
+    /// How `n` of this construct read in an explanation ("2 switch cases")
+    fn describe(self, n: u32) -> String {
+        let (one, many) = match self {
+            DecisionKind::If => ("if", "ifs"),
+            DecisionKind::Loop => ("loop", "loops"),
+            DecisionKind::Case => ("switch case", "switch cases"),
+            DecisionKind::Catch => ("catch", "catches"),
+            DecisionKind::MatchArm => ("match arm", "match arms"),
+            DecisionKind::Ternary => ("ternary", "ternaries"),
+            DecisionKind::And => ("&&", "&&"),
+            DecisionKind::Or => ("||", "||"),
+            DecisionKind::Coalesce => ("??", "??"),
+        };
+        format!("{} {}", n, if n == 1 { one } else { many })
+    }
+}
+
+/// Decision points behind a function's CC, counted per [`DecisionKind`].
+///
+/// Only constructs that add to CC in the function's language are counted, so
+/// diffing two breakdowns explains a CC change construct by construct. Keys
+/// are [`DecisionKind::name`]s; kinds with no occurrences are absent.
+#[derive(Debug, Clone, Default, PartialEq, Eq, Serialize, Deserialize)]
+#[serde(transparent)]
+pub struct CcBreakdown(BTreeMap<String, u32>);
+
+impl CcBreakdown {
+    /// Record `n` occurrences of `kind`
+    pub fn add(&mut self, kind: DecisionKind, n: u32) {
+        if n > 0 {
+            *self.0.entry(kind.name().to_string()).or_insert(0) += n;
+        }
+    }
+
+    /// Occurrences of `kind`
+    pub fn get(&self, kind: DecisionKind) -> u32 {
+        self.0.get(kind.name()).copied().unwrap_or(0)
+    }
+
+    pub fn is_empty(&self) -> bool {
+        self.0.is_empty()
+    }
+
+    /// Describe what changed from `before`: "+1 if, +1 &&, +2 switch cases".
+    ///
+    /// Kinds are listed in [`DecisionKind::ALL`] order; returns None when no
+    /// count changed.
+    pub fn describe_change(&self, before: &CcBreakdown) -> Option<String> {
+        let parts: Vec<String> = DecisionKind::ALL
+            .into_iter()
+            .filter_map(|kind| {
+                let (after, before) = (self.get(kind), before.get(kind));
+                match after.cmp(&before) {
+                    std::cmp::Ordering::Greater => {
+                        Some(format!("+{}", kind.describe(after - before)))
+                    }
+                    std::cmp::Ordering::Less => Some(format!("-{}", kind.describe(before - after))),
+                    std::cmp::Ordering::Equal => None,
+                }
+            })
+            .collect();
+        if parts.is_empty() {
+            None
+        } else {
+            Some(parts.join(", "))
+        }
+    }
+}
+
+/// A CC decision point and where it starts
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub struct Decision {
+    pub kind: DecisionKind,
+    pub position: NestPosition,
+}
+
+/// What a CC breakdown walk finds: the tally per kind and where each
+/// decision point starts
+#[derive(Debug, Default)]
+struct CcTally {
+    breakdown: CcBreakdown,
+    decisions: Vec<Decision>,
+}
+
+impl CcTally {
+    /// Record one decision point of `kind` starting at `position`
+    fn add(&mut self, kind: DecisionKind, position: NestPosition) {
+        self.breakdown.add(kind, 1);
+        self.decisions.push(Decision { kind, position });
+    }
+
+    /// Record one decision point of `kind` on tree-sitter `node`'s first line
+    fn add_node(&mut self, kind: DecisionKind, node: tree_sitter::Node) {
+        self.add(
+            kind,
+            NestPosition::Line(node.start_position().row as u32 + 1),
+        );
+    }
+}
+
+/// Statement that leaves a block early, counted toward NS
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub enum ExitKind {
+    /// `return` other than a function's final statement, Rust `?`, Zig
+    /// `try`
+    Return,
+    /// `throw`, `raise`, and calls that panic or end the program (Go `panic`,
+    /// `os.Exit`, `log.Fatal*`; Rust `panic!`-style macros and `unwrap`-style
+    /// calls; Swift `fatalError()`; PHP `exit`; Elixir `exit`; Lua `error()`;
+    /// Bash `exit`; Zig `@panic()` and `unreachable`; Haskell `error`,
+    /// `throw`, `undefined`, and `exitWith`; Perl `die`, `croak`,
+    /// `confess`, and `exit`)
+    Throw,
+    /// `break`, PL/pgSQL `EXIT`, Perl `last`
+    Break,
+    /// `continue`, Perl `next` and `redo`
+    Continue,
+    /// Go `defer`
+    Defer,
+    /// `goto`
+    Goto,
+}
+
+impl ExitKind {
+    pub const ALL: [ExitKind; 6] = [
+        ExitKind::Return,
+        ExitKind::Throw,
+        ExitKind::Break,
+        ExitKind::Continue,
+        ExitKind::Defer,
+        ExitKind::Goto,
+    ];
+
+    /// Key used in `metrics.ns_breakdown`
+    pub fn name(self) -> &'static str {
+        match self {
+            ExitKind::Return => "return",
+            ExitKind::Throw => "throw",
+            ExitKind::Break => "break",
+            ExitKind::Continue => "continue",
+            ExitKind::Defer => "defer",
+            ExitKind::Goto => "goto",
+        }
+    }
+}
+
+/// Exits behind a function's NS, counted per [`ExitKind`].
+///
+/// Keys are [`ExitKind::name`]s; kinds with no occurrences are absent, so the
+/// breakdown of a function with NS 0 is empty.
+#[derive(Debug, Clone, Default, PartialEq, Eq, Serialize, Deserialize)]
+#[serde(transparent)]
+pub struct NsBreakdown(BTreeMap<String, u32>);
+
+impl NsBreakdown {
+    /// Record one occurrence of `kind`
+    pub fn add(&mut self, kind: ExitKind) {
+        *self.0.entry(kind.name().to_string()).or_insert(0) += 1;
+    }
+
+    /// Drop one occurrence of `kind`, if any
+    fn remove(&mut self, kind: ExitKind) {
+        if let Some(n) = self.0.get_mut(kind.name()) {
+            *n -= 1;
+            if *n == 0 {
+                self.0.remove(kind.name());
+            }
+        }
+    }
+
+    /// Occurrences of `kind`
+    pub fn get(&self, kind: ExitKind) -> u32 {
+        self.0.get(kind.name()).copied().unwrap_or(0)
+    }
+
+    /// All exits: the function's NS
+    pub fn total(&self) -> usize {
+        self.0.values().map(|&n| n as usize).sum()
+    }
+
+    pub fn is_empty(&self) -> bool {
+        self.0.is_empty()
+    }
 }
 
 /// Calculate lines of code (LOC) from source text
@@ -42,6 +484,11 @@ fn calculate_loc_from_node(node: &tree_sitter::Node) -> usize {
 
 /// Extract all metrics for a function
 pub fn extract_metrics(function: &FunctionNode, cfg: &Cfg) -> RawMetrics {
+    extract_metrics_with(function, cfg, NdCounts::default())
+}
+
+/// Like [`extract_metrics`], counting only the `nd_counts` constructs toward ND
+pub fn extract_metrics_with(function: &FunctionNode, cfg: &Cfg, nd_counts: NdCounts) -> RawMetrics {
     use crate::language::FunctionBody;
 
     match &function.body {
@@ -54,34 +501,88 @@ pub fn extract_metrics(function: &FunctionNode, cfg: &Cfg) -> RawMetrics {
                 + 1;
 
             let callee_names = ecmascript_extract_callees(body);
+            let (nd, nd_position) = nesting_depth(body, nd_counts);
+            let ns_breakdown = non_structured_exits(body);
+            let cc_tally = cc_breakdown(body);
             RawMetrics {
                 cc: cyclomatic_complexity(cfg, body),
-                nd: nesting_depth(body),
+                cognitive: cognitive_complexity(body),
+                nd,
+                nd_position,
                 fo: callee_names.len(),
-                ns: non_structured_exits(body),
+                ns: ns_breakdown.total(),
+                ns_breakdown,
                 loc: loc as usize,
                 callee_names,
+                arrow_depth: arrow_depth(body),
+                signature_complexity: function.signature_complexity,
+                guard_clauses: guard_clauses(body),
+                max_condition_ops: max_condition_ops(body),
+                cc_breakdown: Some(cc_tally.breakdown),
+                decisions: cc_tally.decisions,
+                switches: switch_sites(body),
+                await_in_loop: await_in_loop(body),
             }
         }
         FunctionBody::Go { .. } => {
             // Extract Go-specific metrics from tree-sitter AST
-            extract_go_metrics(function, cfg)
+            extract_go_metrics(function, cfg, nd_counts)
         }
         FunctionBody::Java { .. } => {
             // Extract Java-specific metrics from tree-sitter AST
-            extract_java_metrics(function, cfg)
+            extract_java_metrics(function, cfg, nd_counts)
         }
         FunctionBody::Python { .. } => {
             // Extract Python-specific metrics from tree-sitter AST
-            extract_python_metrics(function, cfg)
+            extract_python_metrics(function, cfg, nd_counts)
         }
         FunctionBody::Rust { .. } => {
             // Extract Rust-specific metrics from syn AST
-            extract_rust_metrics(function, cfg)
+            extract_rust_metrics(function, cfg, nd_counts)
+        }
+        FunctionBody::CSharp { .. } => extract_csharp_metrics(function, cfg, nd_counts),
+        FunctionBody::C { .. } => extract_c_metrics(function, cfg, nd_counts),
+        FunctionBody::Cpp { .. } => extract_cpp_metrics(function, cfg, nd_counts),
+        FunctionBody::Swift { .. } => extract_swift_metrics(function, cfg, nd_counts),
+        FunctionBody::Php { .. } => extract_php_metrics(function, cfg, nd_counts),
+        FunctionBody::Scala { .. } => extract_scala_metrics(function, cfg, nd_counts),
+        FunctionBody::Dart { .. } => extract_dart_metrics(function, cfg, nd_counts),
+        FunctionBody::Elixir { .. } => extract_elixir_metrics(function, cfg, nd_counts),
+        FunctionBody::Lua { .. } => extract_lua_metrics(function, cfg, nd_counts),
+        FunctionBody::Bash { .. } => extract_bash_metrics(function, cfg, nd_counts),
+        FunctionBody::Zig { .. } => extract_zig_metrics(function, cfg, nd_counts),
+        FunctionBody::Haskell { .. } => extract_haskell_metrics(function, cfg, nd_counts),
+        FunctionBody::Perl { .. } => extract_perl_metrics(function, cfg, nd_counts),
+        FunctionBody::Sql { .. } => extract_sql_metrics(function),
+    }
+}
+
+/// `raw.cc` with every switch statement recounted under `mode`.
+///
+/// A switch adds the out-edges of its dispatch node beyond the first, plus
+/// its [`SwitchSite::counted_clauses`]; that share is replaced by the mode's.
+/// A switch that adds neither (in a closure the CFG skips, in a language
+/// that counts clauses only through the CFG) stays uncounted.
+pub fn cc_with_mode(raw: &RawMetrics, cfg: &Cfg, mode: CcMode) -> usize {
+    if mode == CcMode::Cases {
+        return raw.cc;
+    }
+    let (mut removed, mut added) = (0, 0);
+    for site in &raw.switches {
+        let dispatch = cfg.switches.get(&site.start).map(|&node| {
+            let out = cfg.edges.iter().filter(|edge| edge.from == node).count();
+            out.saturating_sub(1)
+        });
+        if dispatch.is_none() && site.counted_clauses == 0 {
+            continue;
         }
-        FunctionBody::CSharp { .. } => extract_csharp_metrics(function, cfg),
-        FunctionBody::C { .. } => extract_c_metrics(function, cfg),
+        removed += dispatch.unwrap_or(0) + site.counted_clauses;
+        added += match mode {
+            CcMode::Statement => 1,
+            _ => (site.clauses + usize::from(!site.exhaustive)).saturating_sub(1),
+        };
     }
+    (raw.cc + added).saturating_sub(removed).max(1)
 }
 
 /// Calculate cyclomatic complexity from CFG alone
@@ -106,7 +607,8 @@ fn calculate_cc_from_cfg(cfg: &Cfg) -> usize {
 /// Additional increments:
 /// - Boolean short-circuit operators (&&, ||)
 /// - Each switch case
-/// - Each catch clause
+/// - Each catch clause, and each promise rejection handler (`.catch(f)`,
+///   `.then(f, g)`; see [`is_rejection_handler`])
 fn cyclomatic_complexity(cfg: &Cfg, body: &BlockStmt) -> usize {
     // Base formula: CC = E - N + 2
     let base_cc = if cfg.edge_count() > 0 && cfg.node_count() > 2 {
@@ -134,7 +636,7 @@ fn cyclomatic_complexity(cfg: &Cfg, body: &BlockStmt) -> usize {
     // Increment for switch cases
     let switch_case_count = count_switch_cases(body);
 
-    // Increment for catch clauses
+    // Increment for catch clauses and promise rejection handlers
     let catch_count = count_catch_clauses(body);
 
     base_cc + short_circuit_count + switch_case_count + catch_count
@@ -197,921 +699,7088 @@ impl Visit for CatchCounter<'_> {
         }
         try_stmt.visit_children_with(self);
     }
-}
-
-/// Calculate Nesting Depth (ND)
-///
-/// Walk AST and count maximum depth of control constructs:
-/// - if, loop, switch, try
-fn nesting_depth(body: &BlockStmt) -> usize {
-    let mut visitor = NestingDepthVisitor {
-        max_depth: 0,
-        current_depth: 0,
-    };
-    body.visit_with(&mut visitor);
-    visitor.max_depth
-}
 
-struct NestingDepthVisitor {
-    max_depth: usize,
-    current_depth: usize,
+    fn visit_call_expr(&mut self, call_expr: &CallExpr) {
+        if is_rejection_handler(call_expr) {
+            *self.count += 1;
+        }
+        call_expr.visit_children_with(self);
+    }
 }
 
-macro_rules! impl_nesting_visitor {
-    ($($method:ident, $ty:ty, $node:ident);* $(;)?) => {
-        $(
-            fn $method(&mut self, $node: &$ty) {
-                self.current_depth += 1;
-                if self.current_depth > self.max_depth {
-                    self.max_depth = self.current_depth;
-                }
-                $node.visit_children_with(self);
-                self.current_depth -= 1;
-            }
-        )*
+/// A promise rejection handler: `p.catch(f)`, or `p.then(f, g)` with its
+/// second argument. It is the promise-chain form of a `catch` clause, so it
+/// counts like one whether the error path is written with `await` and
+/// `try`/`catch` or as a chain. Matched by method name, not by type.
+fn is_rejection_handler(call_expr: &CallExpr) -> bool {
+    let Callee::Expr(callee) = &call_expr.callee else {
+        return false;
+    };
+    let Expr::Member(member) = &**callee else {
+        return false;
     };
+    let MemberProp::Ident(prop) = &member.prop else {
+        return false;
+    };
+    match &*prop.sym {
+        "catch" => !call_expr.args.is_empty(),
+        "then" => call_expr.args.len() >= 2,
+        _ => false,
+    }
 }
 
-impl Visit for NestingDepthVisitor {
-    impl_nesting_visitor!(
-        visit_if_stmt,     IfStmt,     if_stmt;
-        visit_while_stmt,  WhileStmt,  while_stmt;
-        visit_do_while_stmt, DoWhileStmt, do_while_stmt;
-        visit_for_stmt,    ForStmt,    for_stmt;
-        visit_for_in_stmt, ForInStmt,  for_in_stmt;
-        visit_for_of_stmt, ForOfStmt,  for_of_stmt;
-        visit_switch_stmt, SwitchStmt, switch_stmt;
-        visit_try_stmt,    TryStmt,    try_stmt;
-    );
+/// Switch statements of a JS/TS body; [`cyclomatic_complexity`] counts
+/// each case on top of the dispatch node's edges
+fn switch_sites(body: &BlockStmt) -> Vec<SwitchSite> {
+    struct Sites(Vec<SwitchSite>);
+
+    impl Visit for Sites {
+        fn visit_switch_stmt(&mut self, switch_stmt: &SwitchStmt) {
+            self.0.push(SwitchSite {
+                start: switch_stmt.span.lo.0 as usize,
+                clauses: switch_stmt.cases.len(),
+                exhaustive: switch_stmt.cases.iter().any(|case| case.test.is_none()),
+                counted_clauses: switch_stmt.cases.len(),
+            });
+            switch_stmt.visit_children_with(self);
+        }
+    }
+
+    let mut sites = Sites(Vec::new());
+    body.visit_with(&mut sites);
+    sites.0
 }
 
-/// Calculate Fan-Out (FO)
-///
-/// Count number of unique functions called by this function
-fn ecmascript_extract_callees(body: &BlockStmt) -> Vec<String> {
-    let mut visitor = FanOutVisitor {
-        calls: std::collections::HashSet::new(),
+/// Tally the decision points counted by [`cyclomatic_complexity`]
+fn cc_breakdown(body: &BlockStmt) -> CcTally {
+    let mut visitor = CcBreakdownVisitor {
+        tally: CcTally::default(),
+        top_level: false,
     };
     body.visit_with(&mut visitor);
-    let mut names: Vec<String> = visitor.calls.into_iter().collect();
-    names.sort();
-    names
+    visitor.tally
 }
 
-struct FanOutVisitor {
-    calls: std::collections::HashSet<String>,
+/// Decision points of a JS/TS module outside every function body: module-scope
+/// `if`, loops, `switch` cases, `catch`, `&&`, and `||`, counted toward
+/// `file_cc`
+pub(crate) fn ecmascript_top_level_decisions(module: &Module) -> usize {
+    let mut visitor = CcBreakdownVisitor {
+        tally: CcTally::default(),
+        top_level: true,
+    };
+    module.visit_with(&mut visitor);
+    visitor.tally.decisions.len()
 }
 
-impl Visit for FanOutVisitor {
-    fn visit_call_expr(&mut self, call_expr: &CallExpr) {
-        // Extract callee representation
-        let callee_str = callee_to_string(&call_expr.callee);
-        if !callee_str.is_empty() && callee_str != "<computed>" {
-            self.calls.insert(callee_str);
-        }
+struct CcBreakdownVisitor {
+    tally: CcTally,
+    /// Skip function bodies, tallying module-scope code only
+    top_level: bool,
+}
 
-        // Continue visiting children (to catch chained calls)
-        call_expr.visit_children_with(self);
+impl CcBreakdownVisitor {
+    fn add(&mut self, kind: DecisionKind, span: swc_common::Span) {
+        self.tally.add(kind, NestPosition::Byte(span.lo));
     }
 }
 
-/// Convert a callee expression to string representation
-fn callee_to_string(callee: &Callee) -> String {
-    match callee {
-        Callee::Expr(expr) => expr_to_callee_string(expr),
-        Callee::Super(_) => "super".to_string(),
-        Callee::Import(_) => "<computed>".to_string(),
+impl Visit for CcBreakdownVisitor {
+    fn visit_if_stmt(&mut self, if_stmt: &IfStmt) {
+        self.add(DecisionKind::If, if_stmt.span);
+        if_stmt.visit_children_with(self);
     }
-}
 
-/// Extract string representation from expression for callee
-///
-/// For chained calls like foo().bar().baz(), we extract:
-/// - foo (when visiting the inner CallExpr)
-/// - foo().bar (when visiting the middle CallExpr with MemberExpr callee)
-/// - foo().bar().baz (when visiting the outer CallExpr with MemberExpr callee)
-fn expr_to_callee_string(expr: &Expr) -> String {
-    match expr {
-        Expr::Ident(ident) => ident.sym.to_string(),
-        Expr::Member(member) => {
-            // For member expressions like obj.method, represent as obj.method
-            // The obj might be a call (for chained calls)
-            let obj_str = match &*member.obj {
-                Expr::Ident(id) => id.sym.to_string(),
-                Expr::Call(call) => {
-                    // Chained call - extract the callee of the inner call
-                    // This gives us the full chain like "foo().bar" when processing "foo().bar().baz"
-                    match &call.callee {
-                        Callee::Expr(callee_expr) => expr_to_callee_string(callee_expr),
-                        _ => "<computed>".to_string(),
-                    }
-                }
-                Expr::Member(member_obj) => {
-                    // Nested member expression - recursively build the chain
-                    expr_to_callee_string(&member_obj.obj)
-                }
-                _ => "<computed>".to_string(),
-            };
+    fn visit_for_stmt(&mut self, for_stmt: &ForStmt) {
+        self.add(DecisionKind::Loop, for_stmt.span);
+        for_stmt.visit_children_with(self);
+    }
 
-            let prop_str = match &member.prop {
-                MemberProp::Ident(id) => id.sym.to_string(),
-                MemberProp::PrivateName(name) => name.name.to_string(),
-                MemberProp::Computed(_) => "<computed>".to_string(),
-            };
+    fn visit_for_in_stmt(&mut self, for_in_stmt: &ForInStmt) {
+        self.add(DecisionKind::Loop, for_in_stmt.span);
+        for_in_stmt.visit_children_with(self);
+    }
 
-            if obj_str == "<computed>" || prop_str == "<computed>" {
-                "<computed>".to_string()
-            } else {
-                format!("{}.{}", obj_str, prop_str)
-            }
-        }
-        Expr::Call(_) => "<computed>".to_string(), // Should not happen - CallExpr callee should be MemberExpr or Ident
-        _ => "<computed>".to_string(),
+    fn visit_for_of_stmt(&mut self, for_of_stmt: &ForOfStmt) {
+        self.add(DecisionKind::Loop, for_of_stmt.span);
+        for_of_stmt.visit_children_with(self);
     }
-}
 
-/// Calculate Non-Structured Exits (NS)
-///
-/// Count:
-/// - Early return statements (excluding final tail return)
-/// - Break statements
-/// - Continue statements
-/// - Throw statements
-fn non_structured_exits(body: &BlockStmt) -> usize {
-    let mut visitor = NonStructuredExitVisitor {
-        count: 0,
-        return_count: 0,
-    };
-    body.visit_with(&mut visitor);
+    fn visit_while_stmt(&mut self, while_stmt: &WhileStmt) {
+        self.add(DecisionKind::Loop, while_stmt.span);
+        while_stmt.visit_children_with(self);
+    }
 
-    // Check if last statement is a return (final tail return)
-    let has_final_return = body
-        .stmts
-        .last()
-        .map(|s| matches!(s, Stmt::Return(_)))
-        .unwrap_or(false);
+    fn visit_do_while_stmt(&mut self, do_while_stmt: &DoWhileStmt) {
+        self.add(DecisionKind::Loop, do_while_stmt.span);
+        do_while_stmt.visit_children_with(self);
+    }
 
-    // Exclude final return if present
-    if has_final_return && visitor.return_count > 0 {
-        visitor.count -= 1;
+    fn visit_switch_stmt(&mut self, switch_stmt: &SwitchStmt) {
+        for case in &switch_stmt.cases {
+            self.add(DecisionKind::Case, case.span);
+        }
+        switch_stmt.visit_children_with(self);
     }
 
-    visitor.count
-}
+    fn visit_try_stmt(&mut self, try_stmt: &TryStmt) {
+        if let Some(handler) = &try_stmt.handler {
+            self.add(DecisionKind::Catch, handler.span);
+        }
+        try_stmt.visit_children_with(self);
+    }
 
-struct NonStructuredExitVisitor {
-    count: usize,
-    return_count: usize,
-}
+    fn visit_call_expr(&mut self, call_expr: &CallExpr) {
+        if is_rejection_handler(call_expr) {
+            self.add(DecisionKind::Catch, call_expr.span);
+        }
+        call_expr.visit_children_with(self);
+    }
 
-impl Visit for NonStructuredExitVisitor {
-    fn visit_return_stmt(&mut self, _return_stmt: &ReturnStmt) {
-        self.count += 1;
-        self.return_count += 1;
+    fn visit_bin_expr(&mut self, bin_expr: &BinExpr) {
+        match bin_expr.op {
+            BinaryOp::LogicalAnd => self.add(DecisionKind::And, bin_expr.span),
+            BinaryOp::LogicalOr => self.add(DecisionKind::Or, bin_expr.span),
+            _ => {}
+        }
+        bin_expr.visit_children_with(self);
     }
 
-    fn visit_break_stmt(&mut self, _break_stmt: &BreakStmt) {
-        self.count += 1;
+    fn visit_function(&mut self, function: &Function) {
+        if !self.top_level {
+            function.visit_children_with(self);
+        }
     }
 
-    fn visit_continue_stmt(&mut self, _continue_stmt: &ContinueStmt) {
-        self.count += 1;
+    fn visit_arrow_expr(&mut self, arrow: &ArrowExpr) {
+        if !self.top_level {
+            arrow.visit_children_with(self);
+        }
     }
 
-    fn visit_throw_stmt(&mut self, _throw_stmt: &ThrowStmt) {
-        self.count += 1;
+    fn visit_constructor(&mut self, constructor: &Constructor) {
+        if !self.top_level {
+            constructor.visit_children_with(self);
+        }
     }
-}
+
+    fn visit_getter_prop(&mut self, getter: &GetterProp) {
+        if !self.top_level {
+            getter.visit_children_with(self);
+        }
+    }
+
+    fn visit_setter_prop(&mut self, setter: &SetterProp) {
+        if !self.top_level {
+            setter.visit_children_with(self);
+        }
+    }
+}
+
+/// Count `await` expressions that run once per loop iteration: in the body,
+/// test, or update of a loop, so each waits for the one before it. The
+/// once-evaluated parts (a `for` initializer, the iterable of `for ... of`)
+/// and the implicit await of `for await` do not count. Nested functions are
+/// skipped: an `await` in a callback belongs to that callback.
+fn await_in_loop(body: &BlockStmt) -> usize {
+    let mut visitor = AwaitInLoopVisitor {
+        loop_depth: 0,
+        count: 0,
+    };
+    body.visit_with(&mut visitor);
+    visitor.count
+}
+
+struct AwaitInLoopVisitor {
+    loop_depth: usize,
+    count: usize,
+}
+
+impl AwaitInLoopVisitor {
+    fn in_loop<N: VisitWith<Self>>(&mut self, node: &N) {
+        self.loop_depth += 1;
+        node.visit_with(self);
+        self.loop_depth -= 1;
+    }
+}
+
+impl Visit for AwaitInLoopVisitor {
+    fn visit_await_expr(&mut self, await_expr: &AwaitExpr) {
+        if self.loop_depth > 0 {
+            self.count += 1;
+        }
+        await_expr.visit_children_with(self);
+    }
+
+    fn visit_for_stmt(&mut self, for_stmt: &ForStmt) {
+        for_stmt.init.visit_with(self);
+        self.in_loop(&for_stmt.test);
+        self.in_loop(&for_stmt.update);
+        self.in_loop(&*for_stmt.body);
+    }
+
+    fn visit_for_in_stmt(&mut self, for_in_stmt: &ForInStmt) {
+        for_in_stmt.right.visit_with(self);
+        self.in_loop(&*for_in_stmt.body);
+    }
+
+    fn visit_for_of_stmt(&mut self, for_of_stmt: &ForOfStmt) {
+        for_of_stmt.right.visit_with(self);
+        self.in_loop(&*for_of_stmt.body);
+    }
+
+    fn visit_while_stmt(&mut self, while_stmt: &WhileStmt) {
+        self.in_loop(&while_stmt.test);
+        self.in_loop(&*while_stmt.body);
+    }
+
+    fn visit_do_while_stmt(&mut self, do_while_stmt: &DoWhileStmt) {
+        self.in_loop(&*do_while_stmt.body);
+        self.in_loop(&do_while_stmt.test);
+    }
+
+    // Nested functions and arrows are analyzed on their own
+    fn visit_function(&mut self, _function: &Function) {}
+
+    fn visit_arrow_expr(&mut self, _arrow: &ArrowExpr) {}
+}
+
+/// Largest number of `&&` / `||` operators in one boolean expression:
+/// `(a && b) || (c && d)` is 3. `??` does not count.
+fn max_condition_ops(body: &BlockStmt) -> usize {
+    let mut visitor = ConditionOpsVisitor { max: 0 };
+    body.visit_with(&mut visitor);
+    visitor.max
+}
+
+fn is_logical_op(bin_expr: &BinExpr) -> bool {
+    matches!(bin_expr.op, BinaryOp::LogicalAnd | BinaryOp::LogicalOr)
+}
+
+struct ConditionOpsVisitor {
+    max: usize,
+}
+
+impl Visit for ConditionOpsVisitor {
+    fn visit_bin_expr(&mut self, bin_expr: &BinExpr) {
+        if !is_logical_op(bin_expr) {
+            bin_expr.visit_children_with(self);
+            return;
+        }
+        // The outermost operator's count covers every operator below it
+        let mut counter = BooleanOpCounter { count: 0 };
+        bin_expr.visit_with(&mut counter);
+        self.max = self.max.max(counter.count);
+    }
+}
+
+struct BooleanOpCounter {
+    count: usize,
+}
+
+impl Visit for BooleanOpCounter {
+    fn visit_bin_expr(&mut self, bin_expr: &BinExpr) {
+        if is_logical_op(bin_expr) {
+            self.count += 1;
+        }
+        bin_expr.visit_children_with(self);
+    }
+}
+
+/// Calculate Cognitive Complexity (SonarSource rules)
+///
+/// - `if`, loops, `switch`, `catch` and `?:` cost 1 plus the current nesting
+///   level, and nest whatever they contain; a `switch` costs once, not per case
+/// - `else if` and `else` cost a flat 1
+/// - A sequence of like logical operators costs 1: `a && b && c` is 1,
+///   `a && b || c` is 2
+/// - Labeled `break` / `continue` cost 1
+/// - Nested functions and arrows only raise the nesting level
+fn cognitive_complexity(body: &BlockStmt) -> usize {
+    let mut visitor = CognitiveVisitor {
+        total: 0,
+        nesting: 0,
+        logical_parent: None,
+    };
+    body.visit_with(&mut visitor);
+    visitor.total
+}
+
+struct CognitiveVisitor {
+    total: usize,
+    nesting: usize,
+    /// Operator of the logical expression directly enclosing the current one
+    /// (looking through parentheses)
+    logical_parent: Option<BinaryOp>,
+}
+
+impl CognitiveVisitor {
+    fn nested<N: VisitWith<Self>>(&mut self, node: &N) {
+        self.nesting += 1;
+        node.visit_children_with(self);
+        self.nesting -= 1;
+    }
+
+    fn structural<N: VisitWith<Self>>(&mut self, node: &N) {
+        self.total += 1 + self.nesting;
+        self.nested(node);
+    }
+
+    fn if_chain(&mut self, if_stmt: &IfStmt, else_if: bool) {
+        self.total += if else_if { 1 } else { 1 + self.nesting };
+        if_stmt.test.visit_with(self);
+        self.nesting += 1;
+        if_stmt.cons.visit_with(self);
+        self.nesting -= 1;
+        match if_stmt.alt.as_deref() {
+            Some(Stmt::If(else_if)) => self.if_chain(else_if, true),
+            Some(alt) => {
+                self.total += 1;
+                self.nesting += 1;
+                alt.visit_with(self);
+                self.nesting -= 1;
+            }
+            None => {}
+        }
+    }
+}
+
+impl Visit for CognitiveVisitor {
+    fn visit_if_stmt(&mut self, if_stmt: &IfStmt) {
+        self.if_chain(if_stmt, false);
+    }
+
+    fn visit_for_stmt(&mut self, for_stmt: &ForStmt) {
+        self.structural(for_stmt);
+    }
+
+    fn visit_for_in_stmt(&mut self, for_in_stmt: &ForInStmt) {
+        self.structural(for_in_stmt);
+    }
+
+    fn visit_for_of_stmt(&mut self, for_of_stmt: &ForOfStmt) {
+        self.structural(for_of_stmt);
+    }
+
+    fn visit_while_stmt(&mut self, while_stmt: &WhileStmt) {
+        self.structural(while_stmt);
+    }
+
+    fn visit_do_while_stmt(&mut self, do_while_stmt: &DoWhileStmt) {
+        self.structural(do_while_stmt);
+    }
+
+    fn visit_switch_stmt(&mut self, switch_stmt: &SwitchStmt) {
+        self.structural(switch_stmt);
+    }
+
+    fn visit_catch_clause(&mut self, catch_clause: &CatchClause) {
+        self.structural(catch_clause);
+    }
+
+    fn visit_cond_expr(&mut self, cond_expr: &CondExpr) {
+        self.structural(cond_expr);
+    }
+
+    fn visit_function(&mut self, function: &Function) {
+        self.nested(function);
+    }
+
+    fn visit_arrow_expr(&mut self, arrow: &ArrowExpr) {
+        self.nested(arrow);
+    }
+
+    fn visit_break_stmt(&mut self, break_stmt: &BreakStmt) {
+        if break_stmt.label.is_some() {
+            self.total += 1;
+        }
+    }
+
+    fn visit_continue_stmt(&mut self, continue_stmt: &ContinueStmt) {
+        if continue_stmt.label.is_some() {
+            self.total += 1;
+        }
+    }
+
+    fn visit_expr(&mut self, expr: &Expr) {
+        match expr {
+            Expr::Bin(_) | Expr::Paren(_) => expr.visit_children_with(self),
+            _ => {
+                // Any other expression ends the operator sequence
+                let parent = self.logical_parent.take();
+                expr.visit_children_with(self);
+                self.logical_parent = parent;
+            }
+        }
+    }
+
+    fn visit_bin_expr(&mut self, bin_expr: &BinExpr) {
+        let parent = self.logical_parent;
+        if matches!(bin_expr.op, BinaryOp::LogicalAnd | BinaryOp::LogicalOr) {
+            if parent != Some(bin_expr.op) {
+                self.total += 1;
+            }
+            self.logical_parent = Some(bin_expr.op);
+        } else {
+            self.logical_parent = None;
+        }
+        bin_expr.visit_children_with(self);
+        self.logical_parent = parent;
+    }
+}
+
+/// Calculate Nesting Depth (ND)
+///
+/// Walk AST and count maximum depth of control constructs:
+/// - if, loop, switch, try (each unless excluded by `nd_counts`)
+///
+/// Also returns where the first construct at the maximum depth starts.
+fn nesting_depth(body: &BlockStmt, nd_counts: NdCounts) -> (usize, Option<NestPosition>) {
+    let mut visitor = NestingDepthVisitor {
+        max_depth: 0,
+        current_depth: 0,
+        deepest: None,
+        nd_counts,
+    };
+    body.visit_with(&mut visitor);
+    (visitor.max_depth, visitor.deepest.map(NestPosition::Byte))
+}
+
+struct NestingDepthVisitor {
+    max_depth: usize,
+    current_depth: usize,
+    /// Start of the first construct that reached `max_depth`
+    deepest: Option<swc_common::BytePos>,
+    nd_counts: NdCounts,
+}
+
+macro_rules! impl_nesting_visitor {
+    ($($method:ident, $ty:ty, $node:ident, $construct:ident);* $(;)?) => {
+        $(
+            fn $method(&mut self, $node: &$ty) {
+                if !self.nd_counts.counts(NestingConstruct::$construct) {
+                    $node.visit_children_with(self);
+                    return;
+                }
+                self.current_depth += 1;
+                if self.current_depth > self.max_depth {
+                    self.max_depth = self.current_depth;
+                    self.deepest = Some($node.span.lo);
+                }
+                $node.visit_children_with(self);
+                self.current_depth -= 1;
+            }
+        )*
+    };
+}
+
+impl Visit for NestingDepthVisitor {
+    impl_nesting_visitor!(
+        visit_if_stmt,     IfStmt,     if_stmt,     If;
+        visit_while_stmt,  WhileStmt,  while_stmt,  While;
+        visit_do_while_stmt, DoWhileStmt, do_while_stmt, While;
+        visit_for_stmt,    ForStmt,    for_stmt,    For;
+        visit_for_in_stmt, ForInStmt,  for_in_stmt, For;
+        visit_for_of_stmt, ForOfStmt,  for_of_stmt, For;
+        visit_switch_stmt, SwitchStmt, switch_stmt, Switch;
+        visit_try_stmt,    TryStmt,    try_stmt,    Try;
+    );
+}
+
+/// Calculate arrow depth for ECMAScript (see `ts_arrow_depth` for the rules)
+fn arrow_depth(body: &BlockStmt) -> usize {
+    fn level(stmts: &[Stmt]) -> usize {
+        let last = stmts.len().saturating_sub(1);
+        let mut construct = None;
+        for (i, stmt) in stmts.iter().enumerate() {
+            match stmt {
+                Stmt::If(_)
+                | Stmt::For(_)
+                | Stmt::ForIn(_)
+                | Stmt::ForOf(_)
+                | Stmt::While(_)
+                | Stmt::DoWhile(_)
+                | Stmt::Switch(_)
+                | Stmt::Try(_) => {
+                    if construct.is_some() {
+                        return 0;
+                    }
+                    construct = Some(stmt);
+                }
+                Stmt::Return(_) | Stmt::Throw(_) | Stmt::Break(_) | Stmt::Continue(_)
+                    if i != last =>
+                {
+                    return 0;
+                }
+                _ => {}
+            }
+        }
+        let inner: &Stmt = match construct {
+            Some(Stmt::If(s)) if s.alt.is_none() => &*s.cons,
+            Some(Stmt::For(s)) => &*s.body,
+            Some(Stmt::ForIn(s)) => &*s.body,
+            Some(Stmt::ForOf(s)) => &*s.body,
+            Some(Stmt::While(s)) => &*s.body,
+            Some(Stmt::DoWhile(s)) => &*s.body,
+            _ => return 0,
+        };
+        1 + match inner {
+            Stmt::Block(block) => level(&block.stmts),
+            other => level(std::slice::from_ref(other)),
+        }
+    }
+    level(&body.stmts)
+}
+
+/// Count guard clauses for ECMAScript (see `ts_guard_clauses` for the rules)
+fn guard_clauses(body: &BlockStmt) -> usize {
+    fn is_exit(stmt: &Stmt) -> bool {
+        matches!(
+            stmt,
+            Stmt::Return(_) | Stmt::Throw(_) | Stmt::Break(_) | Stmt::Continue(_)
+        )
+    }
+    fn is_guard(stmt: &Stmt) -> bool {
+        match stmt {
+            Stmt::If(s) if s.alt.is_none() => match &*s.cons {
+                Stmt::Block(block) => block.stmts.len() == 1 && is_exit(&block.stmts[0]),
+                other => is_exit(other),
+            },
+            _ => false,
+        }
+    }
+    fn leading(stmts: &[Stmt]) -> usize {
+        let mut count = 0;
+        for stmt in stmts {
+            match stmt {
+                _ if is_guard(stmt) => count += 1,
+                Stmt::If(_)
+                | Stmt::For(_)
+                | Stmt::ForIn(_)
+                | Stmt::ForOf(_)
+                | Stmt::While(_)
+                | Stmt::DoWhile(_)
+                | Stmt::Switch(_)
+                | Stmt::Try(_) => break,
+                _ => {}
+            }
+        }
+        count
+    }
+    let loop_guards: usize = body
+        .stmts
+        .iter()
+        .filter_map(|stmt| match stmt {
+            Stmt::For(s) => Some(&*s.body),
+            Stmt::ForIn(s) => Some(&*s.body),
+            Stmt::ForOf(s) => Some(&*s.body),
+            Stmt::While(s) => Some(&*s.body),
+            Stmt::DoWhile(s) => Some(&*s.body),
+            _ => None,
+        })
+        .map(|loop_body| match loop_body {
+            Stmt::Block(block) => leading(&block.stmts),
+            other => leading(std::slice::from_ref(other)),
+        })
+        .sum();
+    leading(&body.stmts) + loop_guards
+}
+
+/// Calculate Fan-Out (FO)
+///
+/// Count number of unique functions called by this function
+fn ecmascript_extract_callees(body: &BlockStmt) -> Vec<String> {
+    let mut visitor = FanOutVisitor {
+        calls: std::collections::HashSet::new(),
+    };
+    body.visit_with(&mut visitor);
+    let mut names: Vec<String> = visitor.calls.into_iter().collect();
+    names.sort();
+    names
+}
+
+struct FanOutVisitor {
+    calls: std::collections::HashSet<String>,
+}
+
+impl Visit for FanOutVisitor {
+    fn visit_call_expr(&mut self, call_expr: &CallExpr) {
+        // Extract callee representation
+        let callee_str = callee_to_string(&call_expr.callee);
+        if !callee_str.is_empty() && callee_str != "<computed>" {
+            self.calls.insert(callee_str);
+        }
+
+        // Continue visiting children (to catch chained calls)
+        call_expr.visit_children_with(self);
+    }
+}
+
+/// Convert a callee expression to string representation
+fn callee_to_string(callee: &Callee) -> String {
+    match callee {
+        Callee::Expr(expr) => expr_to_callee_string(expr),
+        Callee::Super(_) => "super".to_string(),
+        Callee::Import(_) => "<computed>".to_string(),
+    }
+}
+
+/// Extract string representation from expression for callee
+///
+/// For chained calls like foo().bar().baz(), we extract:
+/// - foo (when visiting the inner CallExpr)
+/// - foo().bar (when visiting the middle CallExpr with MemberExpr callee)
+/// - foo().bar().baz (when visiting the outer CallExpr with MemberExpr callee)
+fn expr_to_callee_string(expr: &Expr) -> String {
+    match expr {
+        Expr::Ident(ident) => ident.sym.to_string(),
+        Expr::Member(member) => {
+            // For member expressions like obj.method, represent as obj.method
+            // The obj might be a call (for chained calls)
+            let obj_str = match &*member.obj {
+                Expr::Ident(id) => id.sym.to_string(),
+                Expr::Call(call) => {
+                    // Chained call - extract the callee of the inner call
+                    // This gives us the full chain like "foo().bar" when processing "foo().bar().baz"
+                    match &call.callee {
+                        Callee::Expr(callee_expr) => expr_to_callee_string(callee_expr),
+                        _ => "<computed>".to_string(),
+                    }
+                }
+                Expr::Member(member_obj) => {
+                    // Nested member expression - recursively build the chain
+                    expr_to_callee_string(&member_obj.obj)
+                }
+                _ => "<computed>".to_string(),
+            };
+
+            let prop_str = match &member.prop {
+                MemberProp::Ident(id) => id.sym.to_string(),
+                MemberProp::PrivateName(name) => name.name.to_string(),
+                MemberProp::Computed(_) => "<computed>".to_string(),
+            };
+
+            if obj_str == "<computed>" || prop_str == "<computed>" {
+                "<computed>".to_string()
+            } else {
+                format!("{}.{}", obj_str, prop_str)
+            }
+        }
+        Expr::Call(_) => "<computed>".to_string(), // Should not happen - CallExpr callee should be MemberExpr or Ident
+        _ => "<computed>".to_string(),
+    }
+}
+
+/// Calculate Non-Structured Exits (NS)
+///
+/// Count:
+/// - Early return statements (excluding final tail return)
+/// - Break statements
+/// - Continue statements
+/// - Throw statements
+fn non_structured_exits(body: &BlockStmt) -> NsBreakdown {
+    let mut visitor = NonStructuredExitVisitor {
+        breakdown: NsBreakdown::default(),
+    };
+    body.visit_with(&mut visitor);
+
+    // Exclude the final tail return
+    if matches!(body.stmts.last(), Some(Stmt::Return(_))) {
+        visitor.breakdown.remove(ExitKind::Return);
+    }
+
+    visitor.breakdown
+}
+
+struct NonStructuredExitVisitor {
+    breakdown: NsBreakdown,
+}
+
+impl Visit for NonStructuredExitVisitor {
+    fn visit_return_stmt(&mut self, _return_stmt: &ReturnStmt) {
+        self.breakdown.add(ExitKind::Return);
+    }
+
+    fn visit_break_stmt(&mut self, _break_stmt: &BreakStmt) {
+        self.breakdown.add(ExitKind::Break);
+    }
+
+    fn visit_continue_stmt(&mut self, _continue_stmt: &ContinueStmt) {
+        self.breakdown.add(ExitKind::Continue);
+    }
+
+    fn visit_throw_stmt(&mut self, _throw_stmt: &ThrowStmt) {
+        self.breakdown.add(ExitKind::Throw);
+    }
+}
+
+// ============================================================================
+// Shared Tree-Sitter Metric Utilities
+//
+// Generic tree-sitter traversals parameterised by language-specific node-kind
+// sets. Per-language code is reduced to providing the right kind lists.
+// ============================================================================
+
+/// Find the first immediate child of `node` whose kind matches `kind`.
+fn ts_find_child_by_kind<'a>(
+    node: tree_sitter::Node<'a>,
+    kind: &str,
+) -> Option<tree_sitter::Node<'a>> {
+    let mut cursor = node.walk();
+    let result = node
+        .children(&mut cursor)
+        .find(|child| child.kind() == kind);
+    result
+}
+
+/// Find the first descendant that belongs to `func_kinds` at `start_byte`.
+fn ts_find_function_by_start<'a>(
+    root: tree_sitter::Node<'a>,
+    start_byte: usize,
+    func_kinds: &[&str],
+) -> Option<tree_sitter::Node<'a>> {
+    if func_kinds.contains(&root.kind()) && root.start_byte() == start_byte {
+        return Some(root);
+    }
+    let mut cursor = root.walk();
+    for child in root.children(&mut cursor) {
+        if let Some(found) = ts_find_function_by_start(child, start_byte, func_kinds) {
+            return Some(found);
+        }
+    }
+    None
+}
+
+/// Calculate maximum nesting depth for the given control-structure node kinds,
+/// skipping those whose construct family `nd_counts` excludes. Also returns
+/// the line of the first node that reaches it.
+fn ts_nesting_depth(
+    body_node: &tree_sitter::Node,
+    nesting_kinds: &[&str],
+    nd_counts: NdCounts,
+) -> (usize, Option<NestPosition>) {
+    ts_nesting_depth_by(body_node, nesting_kinds, nd_counts, ts_nesting_construct)
+}
+
+/// [`ts_nesting_depth`] for a grammar whose kinds map to construct families
+/// through `construct` rather than [`ts_nesting_construct`]
+fn ts_nesting_depth_by(
+    body_node: &tree_sitter::Node,
+    nesting_kinds: &[&str],
+    nd_counts: NdCounts,
+    construct: fn(&str) -> Option<NestingConstruct>,
+) -> (usize, Option<NestPosition>) {
+    let counted: Vec<&str> = nesting_kinds
+        .iter()
+        .copied()
+        .filter(|kind| construct(kind).map_or(true, |c| nd_counts.counts(c)))
+        .collect();
+    fn recurse(
+        node: tree_sitter::Node,
+        kinds: &[&str],
+        current: usize,
+        max: &mut usize,
+        line: &mut usize,
+    ) {
+        let next = if kinds.contains(&node.kind()) {
+            let d = current + 1;
+            if d > *max {
+                *max = d;
+                *line = node.start_position().row + 1;
+            }
+            d
+        } else {
+            current
+        };
+        let mut cursor = node.walk();
+        for child in node.children(&mut cursor) {
+            recurse(child, kinds, next, max, line);
+        }
+    }
+    let mut max_depth = 0;
+    let mut line = 0;
+    recurse(*body_node, &counted, 0, &mut max_depth, &mut line);
+    let position = (max_depth > 0).then_some(NestPosition::Line(line as u32));
+    (max_depth, position)
+}
+
+/// `&&` / `||` operator tokens (`binary_expression` nodes)
+const TS_LOGICAL_OPERATORS: &[(&str, DecisionKind)] =
+    &[("&&", DecisionKind::And), ("||", DecisionKind::Or)];
+
+/// The CC decision point `node` is, if any: its kind is listed in
+/// `decision_kinds`, or it is a binary/boolean expression whose operator token
+/// is listed in `operators`.
+fn ts_decision_kind(
+    node: tree_sitter::Node,
+    decision_kinds: &[(&str, DecisionKind)],
+    operators: &[(&str, DecisionKind)],
+) -> Option<DecisionKind> {
+    fn lookup(table: &[(&str, DecisionKind)], kind: &str) -> Option<DecisionKind> {
+        table.iter().find(|(k, _)| *k == kind).map(|&(_, d)| d)
+    }
+    if let Some(kind) = lookup(decision_kinds, node.kind()) {
+        return Some(kind);
+    }
+    if !matches!(node.kind(), "binary_expression" | "boolean_operator") {
+        return None;
+    }
+    // The operator is a direct child; nested operands are separate nodes
+    let mut cursor = node.walk();
+    for child in node.children(&mut cursor) {
+        if let Some(kind) = lookup(operators, child.kind()) {
+            return Some(kind);
+        }
+    }
+    None
+}
+
+/// Tally CC decision points under `body_node` (see [`ts_decision_kind`])
+fn ts_cc_breakdown(
+    body_node: &tree_sitter::Node,
+    decision_kinds: &[(&str, DecisionKind)],
+    operators: &[(&str, DecisionKind)],
+) -> CcTally {
+    fn recurse(
+        node: tree_sitter::Node,
+        decision_kinds: &[(&str, DecisionKind)],
+        operators: &[(&str, DecisionKind)],
+        tally: &mut CcTally,
+    ) {
+        if let Some(kind) = ts_decision_kind(node, decision_kinds, operators) {
+            tally.add_node(kind, node);
+        }
+        let mut cursor = node.walk();
+        for child in node.children(&mut cursor) {
+            recurse(child, decision_kinds, operators, tally);
+        }
+    }
+    let mut tally = CcTally::default();
+    recurse(*body_node, decision_kinds, operators, &mut tally);
+    tally
+}
+
+/// Count decision points under `root` outside the subtrees of
+/// `function_kinds`, for `file_cc` (see [`ts_decision_kind`])
+fn ts_top_level_decisions(
+    root: &tree_sitter::Node,
+    decision_kinds: &[(&str, DecisionKind)],
+    operators: &[(&str, DecisionKind)],
+    function_kinds: &[&str],
+) -> usize {
+    let mut count = usize::from(ts_decision_kind(*root, decision_kinds, operators).is_some());
+    let mut cursor = root.walk();
+    for child in root.children(&mut cursor) {
+        if !function_kinds.contains(&child.kind()) {
+            count += ts_top_level_decisions(&child, decision_kinds, operators, function_kinds);
+        }
+    }
+    count
+}
+
+/// Switch statements of a tree-sitter language (see [`ts_switches`])
+struct SwitchKinds {
+    statements: &'static [&'static str],
+    clauses: &'static [&'static str],
+    /// Clause kinds, or kinds inside a clause (a `default` keyword or label),
+    /// that make it the default
+    defaults: &'static [&'static str],
+    /// Statements that never fall past their clauses
+    exhaustive: &'static [&'static str],
+}
+
+/// Switch statements under `body_node`. Clauses are the statement's children
+/// or its body's; a default is found within two levels of its clause.
+/// `counted` says whether the language's CC extras count each clause.
+fn ts_switches(
+    body_node: &tree_sitter::Node,
+    kinds: &SwitchKinds,
+    counted: bool,
+) -> Vec<SwitchSite> {
+    fn is_default(clause: tree_sitter::Node, defaults: &[&str]) -> bool {
+        let mut cursor = clause.walk();
+        let children: Vec<tree_sitter::Node> = clause.children(&mut cursor).collect();
+        defaults.contains(&clause.kind())
+            || children.into_iter().any(|child| {
+                let mut cursor = child.walk();
+                let found = defaults.contains(&child.kind())
+                    || child
+                        .children(&mut cursor)
+                        .any(|grandchild| defaults.contains(&grandchild.kind()));
+                found
+            })
+    }
+    fn recurse(
+        node: tree_sitter::Node,
+        kinds: &SwitchKinds,
+        counted: bool,
+        sites: &mut Vec<SwitchSite>,
+    ) {
+        if kinds.statements.contains(&node.kind()) {
+            let mut clauses = Vec::new();
+            let mut cursor = node.walk();
+            for child in node.named_children(&mut cursor) {
+                if kinds.clauses.contains(&child.kind()) {
+                    clauses.push(child);
+                } else {
+                    let mut inner = child.walk();
+                    clauses.extend(
+                        child
+                            .named_children(&mut inner)
+                            .filter(|clause| kinds.clauses.contains(&clause.kind())),
+                    );
+                }
+            }
+            sites.push(SwitchSite {
+                start: node.start_byte(),
+                clauses: clauses.len(),
+                exhaustive: kinds.exhaustive.contains(&node.kind())
+                    || clauses
+                        .iter()
+                        .any(|&clause| is_default(clause, kinds.defaults)),
+                counted_clauses: if counted { clauses.len() } else { 0 },
+            });
+        }
+        let mut cursor = node.walk();
+        for child in node.children(&mut cursor) {
+            recurse(child, kinds, counted, sites);
+        }
+    }
+    let mut sites = Vec::new();
+    recurse(*body_node, kinds, counted, &mut sites);
+    sites
+}
+
+/// Largest number of `&&` / `||` operators in one boolean expression under
+/// `body_node`: `(a && b) || (c && d)` is 3. Operators are recognized as in
+/// [`ts_cc_breakdown`]; `??` does not count.
+fn ts_max_condition_ops(
+    body_node: &tree_sitter::Node,
+    decision_kinds: &[(&str, DecisionKind)],
+    operators: &[(&str, DecisionKind)],
+) -> usize {
+    fn is_boolean_op(
+        node: tree_sitter::Node,
+        decision_kinds: &[(&str, DecisionKind)],
+        operators: &[(&str, DecisionKind)],
+    ) -> bool {
+        matches!(
+            ts_decision_kind(node, decision_kinds, operators),
+            Some(DecisionKind::And | DecisionKind::Or)
+        )
+    }
+    fn count(
+        node: tree_sitter::Node,
+        decision_kinds: &[(&str, DecisionKind)],
+        operators: &[(&str, DecisionKind)],
+    ) -> usize {
+        let own = usize::from(is_boolean_op(node, decision_kinds, operators));
+        let mut cursor = node.walk();
+        let nested: usize = node
+            .children(&mut cursor)
+            .map(|child| count(child, decision_kinds, operators))
+            .sum();
+        own + nested
+    }
+    fn recurse(
+        node: tree_sitter::Node,
+        decision_kinds: &[(&str, DecisionKind)],
+        operators: &[(&str, DecisionKind)],
+        max: &mut usize,
+    ) {
+        if is_boolean_op(node, decision_kinds, operators) {
+            // The outermost operator's count covers every operator below it
+            *max = (*max).max(count(node, decision_kinds, operators));
+            return;
+        }
+        let mut cursor = node.walk();
+        for child in node.children(&mut cursor) {
+            recurse(child, decision_kinds, operators, max);
+        }
+    }
+    let mut max = 0;
+    recurse(*body_node, decision_kinds, operators, &mut max);
+    max
+}
+
+/// Node kinds that drive cognitive complexity in one tree-sitter grammar
+struct CognitiveKinds {
+    /// Structures other than `if_statement` that cost 1 plus the nesting
+    /// level and nest their contents (loops, `switch`, `catch`, ternaries)
+    structural: &'static [&'static str],
+    /// Nested functions and lambdas: they nest their contents at no cost
+    nesting_only: &'static [&'static str],
+    /// Jumps that cost a flat 1 (`goto`)
+    jumps: &'static [&'static str],
+    /// `break` / `continue` kinds that cost a flat 1 when they carry a label
+    labeled_jumps: &'static [&'static str],
+    /// Logical operator tokens of `binary_expression` / `boolean_operator`
+    operators: &'static [&'static str],
+}
+
+/// Calculate cognitive complexity (SonarSource rules) under `body_node`.
+///
+/// `if_statement` and the `structural` kinds cost 1 plus the current nesting
+/// level and nest their contents; a `switch` costs once, not per case.
+/// `else if` / `elif` / `else` cost a flat 1, and so do `jumps` and labeled
+/// jumps. A sequence of like logical operators costs 1 (`a && b && c` is 1,
+/// `a && b || c` is 2).
+fn ts_cognitive_complexity(body_node: &tree_sitter::Node, kinds: &CognitiveKinds) -> usize {
+    fn recurse(
+        node: tree_sitter::Node,
+        kinds: &CognitiveKinds,
+        nesting: usize,
+        logical_parent: Option<&str>,
+        total: &mut usize,
+    ) {
+        let kind = node.kind();
+        if kind == "if_statement" {
+            if_chain(node, kinds, nesting, false, total);
+            return;
+        }
+        let mut inner = nesting;
+        let mut operator = None;
+        if kinds.structural.contains(&kind) {
+            *total += 1 + nesting;
+            inner += 1;
+        } else if kinds.nesting_only.contains(&kind) {
+            inner += 1;
+        } else if kinds.jumps.contains(&kind)
+            || (kinds.labeled_jumps.contains(&kind) && node.named_child_count() > 0)
+        {
+            *total += 1;
+        } else if matches!(kind, "binary_expression" | "boolean_operator") {
+            let mut cursor = node.walk();
+            operator = node
+                .children(&mut cursor)
+                .map(|child| child.kind())
+                .find(|k| kinds.operators.contains(k));
+            if operator.is_some() && operator != logical_parent {
+                *total += 1;
+            }
+        } else if kind == "parenthesized_expression" {
+            // Parentheses do not end an operator sequence
+            operator = logical_parent;
+        }
+        let mut cursor = node.walk();
+        for child in node.children(&mut cursor) {
+            recurse(child, kinds, inner, operator, total);
+        }
+    }
+
+    /// An `if` and its `else if` / `else` chain
+    fn if_chain(
+        node: tree_sitter::Node,
+        kinds: &CognitiveKinds,
+        nesting: usize,
+        else_if: bool,
+        total: &mut usize,
+    ) {
+        *total += if else_if { 1 } else { 1 + nesting };
+        let mut cursor = node.walk();
+        for (i, child) in node.children(&mut cursor).enumerate() {
+            match node.field_name_for_child(i as u32) {
+                // PHP names the consequence `body`
+                Some("consequence" | "body") => recurse(child, kinds, nesting + 1, None, total),
+                Some("alternative") => alternative(child, kinds, nesting, total),
+                _ => recurse(child, kinds, nesting, None, total),
+            }
+        }
+    }
+
+    fn alternative(
+        node: tree_sitter::Node,
+        kinds: &CognitiveKinds,
+        nesting: usize,
+        total: &mut usize,
+    ) {
+        match node.kind() {
+            // Python `elif` and PHP `elseif` have the same fields as `if`
+            "if_statement" | "elif_clause" | "else_if_clause" => {
+                if_chain(node, kinds, nesting, true, total)
+            }
+            "else_clause" => {
+                let mut cursor = node.walk();
+                let body: Vec<_> = node
+                    .named_children(&mut cursor)
+                    .filter(|child| !child.kind().contains("comment"))
+                    .collect();
+                match body.as_slice() {
+                    [only] if only.kind() == "if_statement" => {
+                        if_chain(*only, kinds, nesting, true, total)
+                    }
+                    _ => {
+                        *total += 1;
+                        for child in body {
+                            recurse(child, kinds, nesting + 1, None, total);
+                        }
+                    }
+                }
+            }
+            _ => {
+                *total += 1;
+                recurse(node, kinds, nesting + 1, None, total);
+            }
+        }
+    }
+
+    let mut total = 0;
+    recurse(*body_node, kinds, 0, None, &mut total);
+    total
+}
+
+/// Construct family of a tree-sitter nesting kind; `None` for kinds that
+/// always count (`with_statement`, `synchronized_statement`)
+fn ts_nesting_construct(kind: &str) -> Option<NestingConstruct> {
+    match kind {
+        "if_statement" => Some(NestingConstruct::If),
+        "for_statement" | "enhanced_for_statement" | "foreach_statement" | "for_range_loop" => {
+            Some(NestingConstruct::For)
+        }
+        "while_statement" | "do_statement" => Some(NestingConstruct::While),
+        "switch_statement"
+        | "switch_expression"
+        | "expression_switch_statement"
+        | "type_switch_statement"
+        | "select_statement" => Some(NestingConstruct::Switch),
+        "try_statement" => Some(NestingConstruct::Try),
+        "match_statement" => Some(NestingConstruct::Match),
+        _ => None,
+    }
+}
+
+/// Calculate arrow depth: how many nested `chain_kinds` constructs the whole
+/// body is buried under.
+///
+/// A level counts when its block holds exactly one control structure
+/// (`nesting_kinds`) and that structure is an `if` without `else` or a loop
+/// (`chain_kinds`); every other statement must be plain. An exit before the
+/// end of a block (`exit_kinds`) is early-return flattening, so the chain
+/// stops there. Peak ND says how deep code goes; arrow depth says whether all
+/// of it goes that deep.
+fn ts_arrow_depth(
+    body_node: &tree_sitter::Node,
+    block_kinds: &[&str],
+    nesting_kinds: &[&str],
+    chain_kinds: &[&str],
+    exit_kinds: &[&str],
+) -> usize {
+    fn level(
+        node: tree_sitter::Node,
+        block_kinds: &[&str],
+        nesting_kinds: &[&str],
+        chain_kinds: &[&str],
+        exit_kinds: &[&str],
+    ) -> usize {
+        let stmts = ts_statements(node, block_kinds);
+        let last = stmts.len().saturating_sub(1);
+        let mut construct = None;
+        for (i, stmt) in stmts.iter().enumerate() {
+            if nesting_kinds.contains(&stmt.kind()) {
+                if construct.is_some() {
+                    return 0;
+                }
+                construct = Some(*stmt);
+            } else if ts_is_exit(*stmt, exit_kinds) && i != last {
+                return 0;
+            }
+        }
+        let construct = match construct {
+            Some(c) if chain_kinds.contains(&c.kind()) => c,
+            _ => return 0,
+        };
+        if construct.child_by_field_name("alternative").is_some() {
+            return 0;
+        }
+        match construct
+            .child_by_field_name("consequence")
+            .or_else(|| construct.child_by_field_name("body"))
+        {
+            Some(inner) => 1 + level(inner, block_kinds, nesting_kinds, chain_kinds, exit_kinds),
+            None => 1,
+        }
+    }
+
+    level(
+        *body_node,
+        block_kinds,
+        nesting_kinds,
+        chain_kinds,
+        exit_kinds,
+    )
+}
+
+/// Count guard clauses: `if`s without `else` whose body is a single exit
+/// (`exit_kinds`), such as `if (!user) return;` or `if (done) continue;`.
+///
+/// Only leading guards count — those before the first other control
+/// structure (`nesting_kinds`) of a block; plain statements in between are
+/// fine. The function body is scanned, and so is the body of each loop
+/// (`chain_kinds` other than `if_statement`) directly inside it, which picks
+/// up `continue`-style guards. Deeper guards are ordinary branches.
+fn ts_guard_clauses(
+    body_node: &tree_sitter::Node,
+    block_kinds: &[&str],
+    nesting_kinds: &[&str],
+    chain_kinds: &[&str],
+    exit_kinds: &[&str],
+) -> usize {
+    let is_guard = |stmt: &tree_sitter::Node| {
+        if stmt.kind() != "if_statement" || stmt.child_by_field_name("alternative").is_some() {
+            return false;
+        }
+        stmt.child_by_field_name("consequence")
+            .or_else(|| stmt.child_by_field_name("body"))
+            .is_some_and(|cons| match ts_statements(cons, block_kinds).as_slice() {
+                [only] => ts_is_exit(*only, exit_kinds),
+                _ => false,
+            })
+    };
+    let leading = |stmts: &[tree_sitter::Node]| {
+        let mut count = 0;
+        for stmt in stmts {
+            if is_guard(stmt) {
+                count += 1;
+            } else if nesting_kinds.contains(&stmt.kind()) {
+                break;
+            }
+        }
+        count
+    };
+
+    let stmts = ts_statements(*body_node, block_kinds);
+    let loop_guards: usize = stmts
+        .iter()
+        .filter(|stmt| stmt.kind() != "if_statement" && chain_kinds.contains(&stmt.kind()))
+        .filter_map(|stmt| stmt.child_by_field_name("body"))
+        .map(|body| leading(&ts_statements(body, block_kinds)))
+        .sum();
+    leading(&stmts) + loop_guards
+}
+
+/// Whether `stmt` is an exit: one of `exit_kinds`, or an expression
+/// statement holding one (PHP's `throw` is an expression)
+fn ts_is_exit(stmt: tree_sitter::Node, exit_kinds: &[&str]) -> bool {
+    if stmt.kind() == "expression_statement" && stmt.named_child_count() == 1 {
+        return stmt
+            .named_child(0)
+            .is_some_and(|expr| exit_kinds.contains(&expr.kind()));
+    }
+    exit_kinds.contains(&stmt.kind())
+}
+
+/// Statements of a block (flattening nested statement lists), or the node
+/// itself for a brace-less body such as `if (x) foo();`.
+fn ts_statements<'a>(
+    node: tree_sitter::Node<'a>,
+    block_kinds: &[&str],
+) -> Vec<tree_sitter::Node<'a>> {
+    if !block_kinds.contains(&node.kind()) {
+        return vec![node];
+    }
+    let mut stmts = Vec::new();
+    let mut cursor = node.walk();
+    for child in node.named_children(&mut cursor) {
+        if child.kind().contains("comment") {
+            continue;
+        }
+        if block_kinds.contains(&child.kind()) {
+            stmts.extend(ts_statements(child, block_kinds));
+        } else {
+            stmts.push(child);
+        }
+    }
+    stmts
+}
+
+/// Count exits whose node kind appears in `exit_kinds`, per [`ts_exit_kind`].
+fn ts_non_structured_exits(body_node: &tree_sitter::Node, exit_kinds: &[&str]) -> NsBreakdown {
+    fn recurse(node: tree_sitter::Node, kinds: &[&str], breakdown: &mut NsBreakdown) {
+        if kinds.contains(&node.kind()) {
+            breakdown.add(ts_exit_kind(node.kind()));
+        }
+        let mut cursor = node.walk();
+        for child in node.children(&mut cursor) {
+            recurse(child, kinds, breakdown);
+        }
+    }
+    let mut breakdown = NsBreakdown::default();
+    recurse(*body_node, exit_kinds, &mut breakdown);
+    breakdown
+}
+
+/// The [`ExitKind`] of a tree-sitter exit node kind from one of the
+/// `*_EXIT_KINDS` lists; `return_statement`, `co_return_statement`, and
+/// `return_expression` are returns.
+fn ts_exit_kind(node_kind: &str) -> ExitKind {
+    match node_kind {
+        "throw_statement" | "throw_expression" | "rethrow_expression" | "raise_statement"
+        | "exit_statement" => ExitKind::Throw,
+        "break_statement" => ExitKind::Break,
+        "continue_statement" => ExitKind::Continue,
+        "goto_statement" => ExitKind::Goto,
+        _ => ExitKind::Return,
+    }
+}
+
+/// Parse `source` with `language`, locate the function starting at `start_byte`,
+/// find the first matching body child, and call `f(func_node, body_node)`.
+/// Returns `None` if the function or body cannot be found.
+fn ts_with_function_body<R>(
+    source: &str,
+    language: tree_sitter::Language,
+    start_byte: usize,
+    func_kinds: &[&str],
+    body_kinds: &[&str],
+    f: impl FnOnce(tree_sitter::Node, tree_sitter::Node) -> R,
+) -> Option<R> {
+    let mut parser = tree_sitter::Parser::new();
+    parser.set_language(&language).ok()?;
+    let tree = parser.parse(source, None)?;
+    let root = tree.root_node();
+    let func_node = ts_find_function_by_start(root, start_byte, func_kinds)?;
+    for kind in body_kinds {
+        if let Some(body_node) = ts_find_child_by_kind(func_node, kind) {
+            return Some(f(func_node, body_node));
+        }
+    }
+    None
+}
+
+// ============================================================================
+// Go Metrics Implementation
+// ============================================================================
+
+/// Control structures that count toward ND.
+const GO_NESTING_KINDS: &[&str] = &[
+    "if_statement",
+    "for_statement",
+    "switch_statement",
+    "expression_switch_statement",
+    "type_switch_statement",
+    "select_statement",
+];
+
+/// Statement-list kinds (flattened when walking a block)
+const GO_BLOCK_KINDS: &[&str] = &["block", "statement_list"];
+
+/// `if` and loops: the constructs that can form an arrow chain
+const GO_CHAIN_KINDS: &[&str] = &["if_statement", "for_statement"];
+
+/// Statements that leave the current block early
+const GO_EXIT_KINDS: &[&str] = &[
+    "return_statement",
+    "break_statement",
+    "continue_statement",
+    "goto_statement",
+];
+
+/// Switch statements (see `ts_switches`); `select` blocks until a case runs
+const GO_SWITCH_KINDS: SwitchKinds = SwitchKinds {
+    statements: &[
+        "switch_statement",
+        "expression_switch_statement",
+        "type_switch_statement",
+        "select_statement",
+    ],
+    clauses: &[
+        "expression_case",
+        "type_case",
+        "communication_case",
+        "default_case",
+    ],
+    defaults: &["default_case"],
+    exhaustive: &["select_statement"],
+};
+
+/// Decision points (see `ts_cc_breakdown`)
+const GO_DECISION_KINDS: &[(&str, DecisionKind)] = &[
+    ("if_statement", DecisionKind::If),
+    ("for_statement", DecisionKind::Loop),
+    ("expression_case", DecisionKind::Case),
+    ("default_case", DecisionKind::Case),
+    ("communication_case", DecisionKind::Case),
+    ("type_case", DecisionKind::Case),
+];
+
+/// Cognitive complexity kinds (see `ts_cognitive_complexity`)
+const GO_COGNITIVE_KINDS: CognitiveKinds = CognitiveKinds {
+    structural: &[
+        "for_statement",
+        "expression_switch_statement",
+        "type_switch_statement",
+        "select_statement",
+    ],
+    nesting_only: &["func_literal"],
+    jumps: &["goto_statement"],
+    labeled_jumps: &["break_statement", "continue_statement"],
+    operators: &["&&", "||"],
+};
+
+/// Extract metrics for Go functions using tree-sitter
+fn extract_go_metrics(function: &FunctionNode, cfg: &Cfg, nd_counts: NdCounts) -> RawMetrics {
+    let (_body_node_id, source) = function.body.as_go();
+    ts_with_function_body(
+        source,
+        tree_sitter_go::LANGUAGE.into(),
+        function.span.start,
+        crate::language::go::FUNCTION_KINDS,
+        &["block"],
+        |func_node, body_node| {
+            let callee_names = go_extract_callees(&body_node, source);
+            let (nd, nd_position) = ts_nesting_depth(&body_node, GO_NESTING_KINDS, nd_counts);
+            let ns_breakdown = go_non_structured_exits(&body_node, source);
+            let cc_tally = ts_cc_breakdown(&body_node, GO_DECISION_KINDS, TS_LOGICAL_OPERATORS);
+            RawMetrics {
+                cc: calculate_cc_from_cfg(cfg) + go_count_cc_extras(&body_node, source),
+                cognitive: ts_cognitive_complexity(&body_node, &GO_COGNITIVE_KINDS),
+                nd,
+                nd_position,
+                fo: callee_names.len(),
+                ns: ns_breakdown.total(),
+                ns_breakdown,
+                loc: calculate_loc_from_node(&func_node),
+                callee_names,
+                arrow_depth: ts_arrow_depth(
+                    &body_node,
+                    GO_BLOCK_KINDS,
+                    GO_NESTING_KINDS,
+                    GO_CHAIN_KINDS,
+                    GO_EXIT_KINDS,
+                ),
+                signature_complexity: 0,
+                guard_clauses: ts_guard_clauses(
+                    &body_node,
+                    GO_BLOCK_KINDS,
+                    GO_NESTING_KINDS,
+                    GO_CHAIN_KINDS,
+                    GO_EXIT_KINDS,
+                ),
+                max_condition_ops: ts_max_condition_ops(
+                    &body_node,
+                    GO_DECISION_KINDS,
+                    TS_LOGICAL_OPERATORS,
+                ),
+                cc_breakdown: Some(cc_tally.breakdown),
+                decisions: cc_tally.decisions,
+                switches: ts_switches(&body_node, &GO_SWITCH_KINDS, true),
+                await_in_loop: 0,
+            }
+        },
+    )
+    .unwrap_or(RawMetrics {
+        cc: 1,
+        cognitive: 0,
+        nd: 0,
+        nd_position: None,
+        fo: 0,
+        ns: 0,
+        ns_breakdown: NsBreakdown::default(),
+        loc: 0,
+        callee_names: vec![],
+        arrow_depth: 0,
+        signature_complexity: 0,
+        guard_clauses: 0,
+        max_condition_ops: 0,
+        cc_breakdown: None,
+        decisions: vec![],
+        switches: vec![],
+        await_in_loop: 0,
+    })
+}
+
+/// Extract callee names from a Go function body (function calls + go statements).
+/// Returns the unique set of callee name strings (used for both fo metric and call graph).
+fn go_extract_callees(body_node: &tree_sitter::Node, source: &str) -> Vec<String> {
+    use std::collections::HashSet;
+
+    fn collect(node: tree_sitter::Node, source: &str, calls: &mut HashSet<String>) {
+        match node.kind() {
+            "call_expression" => {
+                if let Some(func_node) = ts_find_child_by_kind(node, "identifier")
+                    .or_else(|| ts_find_child_by_kind(node, "selector_expression"))
+                {
+                    let func_text = &source[func_node.start_byte()..func_node.end_byte()];
+                    calls.insert(func_text.to_string());
+                }
+            }
+            "go_statement" => {
+                // Go statements spawn goroutines, count as fan-out
+                calls.insert(format!("<go@{}>", node.start_byte()));
+            }
+            _ => {}
+        }
+        let mut cursor = node.walk();
+        for child in node.children(&mut cursor) {
+            collect(child, source, calls);
+        }
+    }
+
+    let mut calls = HashSet::new();
+    collect(*body_node, source, &mut calls);
+    let mut result: Vec<String> = calls.into_iter().collect();
+    result.sort();
+    result
+}
+
+/// Drop method calls from `raw`'s callees, and so from its FO, keeping calls
+/// of free functions and package functions (config `fo_methods = false`,
+/// `--no-fo-methods`)
+///
+/// Only Go bodies change. A selector call `x.F()` is a package function call
+/// when `x` is a name the file imports, and a method call otherwise; receiver
+/// types are not resolved, so a local variable shadowing an import name is
+/// taken for the package.
+pub fn exclude_method_calls(raw: &mut RawMetrics, function: &FunctionNode) {
+    if !function.body.is_go() {
+        return;
+    }
+    let (_body_node_id, source) = function.body.as_go();
+    let Some(packages) = ts_with_function_body(
+        source,
+        tree_sitter_go::LANGUAGE.into(),
+        function.span.start,
+        crate::language::go::FUNCTION_KINDS,
+        &["block"],
+        |func_node, _| {
+            let mut root = func_node;
+            while let Some(parent) = root.parent() {
+                root = parent;
+            }
+            go_import_names(root, source)
+        },
+    ) else {
+        return;
+    };
+    raw.callee_names.retain(|callee| {
+        let mut segments = callee.split('.');
+        match (segments.next(), segments.next(), segments.next()) {
+            (Some(_), None, _) => true,
+            (Some(operand), Some(_), None) => packages.contains(operand),
+            _ => false,
+        }
+    });
+    raw.fo = raw.callee_names.len();
+}
+
+/// Names a Go file's imports bring into scope: the alias when there is one,
+/// else the last segment of the import path, skipping a major-version suffix
+/// (`.../yaml/v3` is `yaml`). Dot and blank imports bind no name.
+fn go_import_names(root: tree_sitter::Node, source: &str) -> std::collections::HashSet<String> {
+    use std::collections::HashSet;
+
+    fn collect(node: tree_sitter::Node, source: &str, names: &mut HashSet<String>) {
+        if node.kind() == "import_spec" {
+            match node.child_by_field_name("name") {
+                Some(name) if name.kind() == "package_identifier" => {
+                    names.insert(source[name.start_byte()..name.end_byte()].to_string());
+                }
+                Some(_) => {}
+                None => {
+                    if let Some(path) = node.child_by_field_name("path") {
+                        let path = source[path.start_byte()..path.end_byte()]
+                            .trim_matches(|c| c == '"' || c == '`');
+                        let mut segments = path.rsplit('/');
+                        let last = segments.next().unwrap_or_default();
+                        let is_version = last.len() > 1
+                            && last.starts_with('v')
+                            && last[1..].bytes().all(|b| b.is_ascii_digit());
+                        let name = match segments.next() {
+                            Some(parent) if is_version => parent,
+                            _ => last,
+                        };
+                        names.insert(name.to_string());
+                    }
+                }
+            }
+            return;
+        }
+        let mut cursor = node.walk();
+        for child in node.children(&mut cursor) {
+            if matches!(
+                child.kind(),
+                "import_declaration" | "import_spec_list" | "import_spec"
+            ) {
+                collect(child, source, names);
+            }
+        }
+    }
+
+    let mut names = HashSet::new();
+    collect(root, source, &mut names);
+    names
+}
+
+/// Calculate non-structured exits for Go function
+fn go_non_structured_exits(body_node: &tree_sitter::Node, source: &str) -> NsBreakdown {
+    fn count_exits(node: tree_sitter::Node, source: &str, breakdown: &mut NsBreakdown) {
+        match node.kind() {
+            "return_statement" => breakdown.add(ExitKind::Return),
+            "defer_statement" => breakdown.add(ExitKind::Defer),
+            "expression_statement" => {
+                if let Some(call) = ts_find_child_by_kind(node, "call_expression") {
+                    // Bare panic() call
+                    if let Some(ident) = ts_find_child_by_kind(call, "identifier") {
+                        let name = &source[ident.start_byte()..ident.end_byte()];
+                        if name == "panic" {
+                            breakdown.add(ExitKind::Throw);
+                        }
+                    }
+                    // os.Exit / log.Fatal* via selector_expression
+                    else if let Some(sel) = ts_find_child_by_kind(call, "selector_expression") {
+                        if let Some(field) = ts_find_child_by_kind(sel, "field_identifier") {
+                            let field_name = &source[field.start_byte()..field.end_byte()];
+                            if matches!(field_name, "Exit" | "Fatal" | "Fatalf" | "Fatalln") {
+                                breakdown.add(ExitKind::Throw);
+                            }
+                        }
+                    }
+                }
+            }
+            _ => {}
+        }
+
+        // Recurse into children
+        let mut cursor = node.walk();
+        for child in node.children(&mut cursor) {
+            count_exits(child, source, breakdown);
+        }
+    }
+
+    let mut breakdown = NsBreakdown::default();
+    count_exits(*body_node, source, &mut breakdown);
+
+    // Subtract 1 if the last statement is a return (final tail return)
+    // This is an approximation - would need more sophisticated AST analysis
+    let mut cursor = body_node.walk();
+    if let Some(last_child) = body_node.children(&mut cursor).last() {
+        if last_child.kind() == "return_statement" {
+            breakdown.remove(ExitKind::Return);
+        }
+    }
+
+    breakdown
+}
+
+/// Count additional cyclomatic complexity contributors for Go
+fn go_count_cc_extras(body_node: &tree_sitter::Node, _source: &str) -> usize {
+    fn count_extras(node: tree_sitter::Node, count: &mut usize) {
+        match node.kind() {
+            // Count switch/select cases
+            "expression_case" | "default_case" | "communication_case" | "type_case" => {
+                *count += 1;
+            }
+            // Count boolean operators — check the operator child directly to
+            // avoid false positives from nested logical operators in sub-expressions
+            "binary_expression" => {
+                let mut cursor = node.walk();
+                for child in node.children(&mut cursor) {
+                    if child.kind() == "&&" || child.kind() == "||" {
+                        *count += 1;
+                        break;
+                    }
+                }
+            }
+            _ => {}
+        }
+
+        // Recurse into children
+        let mut cursor = node.walk();
+        for child in node.children(&mut cursor) {
+            count_extras(child, count);
+        }
+    }
+
+    let mut count = 0;
+    count_extras(*body_node, &mut count);
+    count
+}
+
+// Note: Go metrics tests are integrated with cfg_builder tests
+
+// ============================================================================
+// Java Metrics Implementation
+// ============================================================================
+
+/// Control structures that count toward ND.
+const JAVA_NESTING_KINDS: &[&str] = &[
+    "if_statement",
+    "while_statement",
+    "do_statement",
+    "for_statement",
+    "enhanced_for_statement",
+    "switch_statement",
+    "switch_expression",
+    "try_statement",
+    "synchronized_statement",
+];
+
+/// `if` and loops: the constructs that can form an arrow chain
+const JAVA_CHAIN_KINDS: &[&str] = &[
+    "if_statement",
+    "while_statement",
+    "do_statement",
+    "for_statement",
+    "enhanced_for_statement",
+];
+
+/// Statements that leave the current block early
+const JAVA_EXIT_KINDS: &[&str] = &[
+    "return_statement",
+    "throw_statement",
+    "break_statement",
+    "continue_statement",
+];
+
+/// Switch statements (see `ts_switches`)
+const JAVA_SWITCH_KINDS: SwitchKinds = SwitchKinds {
+    statements: &["switch_statement", "switch_expression"],
+    clauses: &[
+        "switch_block_statement_group",
+        "switch_rule",
+        "switch_label",
+    ],
+    defaults: &["default"],
+    exhaustive: &["switch_expression"],
+};
+
+/// Decision points (see `ts_cc_breakdown`)
+const JAVA_DECISION_KINDS: &[(&str, DecisionKind)] = &[
+    ("if_statement", DecisionKind::If),
+    ("while_statement", DecisionKind::Loop),
+    ("do_statement", DecisionKind::Loop),
+    ("for_statement", DecisionKind::Loop),
+    ("enhanced_for_statement", DecisionKind::Loop),
+    ("switch_block_statement_group", DecisionKind::Case),
+    ("switch_rule", DecisionKind::Case),
+    ("catch_clause", DecisionKind::Catch),
+    ("ternary_expression", DecisionKind::Ternary),
+];
+
+/// Cognitive complexity kinds (see `ts_cognitive_complexity`)
+const JAVA_COGNITIVE_KINDS: CognitiveKinds = CognitiveKinds {
+    structural: &[
+        "while_statement",
+        "do_statement",
+        "for_statement",
+        "enhanced_for_statement",
+        "switch_statement",
+        "switch_expression",
+        "catch_clause",
+        "ternary_expression",
+    ],
+    // `class_body`: methods of anonymous classes
+    nesting_only: &["lambda_expression", "class_body"],
+    jumps: &[],
+    labeled_jumps: &["break_statement", "continue_statement"],
+    operators: &["&&", "||"],
+};
+
+/// Extract metrics for Java functions using tree-sitter
+fn extract_java_metrics(function: &FunctionNode, cfg: &Cfg, nd_counts: NdCounts) -> RawMetrics {
+    let (_body_node_id, source) = function.body.as_java();
+    ts_with_function_body(
+        source,
+        tree_sitter_java::LANGUAGE.into(),
+        function.span.start,
+        &["method_declaration", "constructor_declaration"],
+        &["block", "constructor_body"],
+        |func_node, body_node| {
+            let callee_names = java_extract_callees(&body_node, source);
+            let (nd, nd_position) = ts_nesting_depth(&body_node, JAVA_NESTING_KINDS, nd_counts);
+            let ns_breakdown = ts_non_structured_exits(&body_node, JAVA_EXIT_KINDS);
+            let cc_tally = ts_cc_breakdown(&body_node, JAVA_DECISION_KINDS, TS_LOGICAL_OPERATORS);
+            RawMetrics {
+                cc: calculate_cc_from_cfg(cfg) + java_count_cc_extras(&body_node, source),
+                cognitive: ts_cognitive_complexity(&body_node, &JAVA_COGNITIVE_KINDS),
+                nd,
+                nd_position,
+                fo: callee_names.len(),
+                ns: ns_breakdown.total(),
+                ns_breakdown,
+                loc: calculate_loc_from_node(&func_node),
+                callee_names,
+                arrow_depth: ts_arrow_depth(
+                    &body_node,
+                    &["block"],
+                    JAVA_NESTING_KINDS,
+                    JAVA_CHAIN_KINDS,
+                    JAVA_EXIT_KINDS,
+                ),
+                signature_complexity: 0,
+                guard_clauses: ts_guard_clauses(
+                    &body_node,
+                    &["block"],
+                    JAVA_NESTING_KINDS,
+                    JAVA_CHAIN_KINDS,
+                    JAVA_EXIT_KINDS,
+                ),
+                max_condition_ops: ts_max_condition_ops(
+                    &body_node,
+                    JAVA_DECISION_KINDS,
+                    TS_LOGICAL_OPERATORS,
+                ),
+                cc_breakdown: Some(cc_tally.breakdown),
+                decisions: cc_tally.decisions,
+                switches: ts_switches(&body_node, &JAVA_SWITCH_KINDS, false),
+                await_in_loop: 0,
+            }
+        },
+    )
+    .unwrap_or(RawMetrics {
+        cc: 1,
+        cognitive: 0,
+        nd: 0,
+        nd_position: None,
+        fo: 0,
+        ns: 0,
+        ns_breakdown: NsBreakdown::default(),
+        loc: 0,
+        callee_names: vec![],
+        arrow_depth: 0,
+        signature_complexity: 0,
+        guard_clauses: 0,
+        max_condition_ops: 0,
+        cc_breakdown: None,
+        decisions: vec![],
+        switches: vec![],
+        await_in_loop: 0,
+    })
+}
+
+/// Extract callee names from a Java function body.
+/// Returns the unique set of method invocation strings.
+fn java_extract_callees(body_node: &tree_sitter::Node, source: &str) -> Vec<String> {
+    fn collect(
+        node: tree_sitter::Node,
+        source: &str,
+        calls: &mut std::collections::HashSet<String>,
+    ) {
+        if node.kind() == "method_invocation" {
+            let method_text = &source[node.start_byte()..node.end_byte()];
+            calls.insert(method_text.to_string());
+        }
+        let mut cursor = node.walk();
+        for child in node.children(&mut cursor) {
+            collect(child, source, calls);
+        }
+    }
+
+    let mut calls = std::collections::HashSet::new();
+    collect(*body_node, source, &mut calls);
+    let mut result: Vec<String> = calls.into_iter().collect();
+    result.sort();
+    result
+}
+
+/// Count additional CC contributors in Java
+/// (ternary expressions, boolean operators)
+fn java_count_cc_extras(body_node: &tree_sitter::Node, _source: &str) -> usize {
+    fn count_extras(node: tree_sitter::Node, count: &mut usize) {
+        match node.kind() {
+            // Ternary expressions (conditional_expression) add to CC
+            "ternary_expression" => {
+                *count += 1;
+            }
+            // Binary expressions with && or || add to CC — check the operator
+            // child directly to avoid false positives from nested sub-expressions
+            "binary_expression" => {
+                let mut cursor = node.walk();
+                for child in node.children(&mut cursor) {
+                    if child.kind() == "&&" || child.kind() == "||" {
+                        *count += 1;
+                        break;
+                    }
+                }
+            }
+            _ => {}
+        }
+
+        // Recursively check children
+        let mut cursor = node.walk();
+        for child in node.children(&mut cursor) {
+            count_extras(child, count);
+        }
+    }
+
+    let mut count = 0;
+    count_extras(*body_node, &mut count);
+    count
+}
+
+// ============================================================================
+// Python Metrics Implementation
+// ============================================================================
+
+/// Control structures that count toward ND.
+const PYTHON_NESTING_KINDS: &[&str] = &[
+    "if_statement",
+    "while_statement",
+    "for_statement",
+    "try_statement",
+    "with_statement",
+    "match_statement",
+];
+
+/// `if` and loops: the constructs that can form an arrow chain
+const PYTHON_CHAIN_KINDS: &[&str] = &["if_statement", "while_statement", "for_statement"];
+
+/// Statements that leave the current block early
+const PYTHON_EXIT_KINDS: &[&str] = &[
+    "return_statement",
+    "raise_statement",
+    "break_statement",
+    "continue_statement",
+];
+
+/// Decision points (see `ts_cc_breakdown`); `if_clause` is a comprehension filter
+const PYTHON_DECISION_KINDS: &[(&str, DecisionKind)] = &[
+    ("if_statement", DecisionKind::If),
+    ("elif_clause", DecisionKind::If),
+    ("if_clause", DecisionKind::If),
+    ("while_statement", DecisionKind::Loop),
+    ("for_statement", DecisionKind::Loop),
+    ("case_clause", DecisionKind::Case),
+    ("except_clause", DecisionKind::Catch),
+    ("conditional_expression", DecisionKind::Ternary),
+];
+
+/// Boolean operator tokens (`boolean_operator` nodes)
+const PYTHON_DECISION_OPERATORS: &[(&str, DecisionKind)] =
+    &[("and", DecisionKind::And), ("or", DecisionKind::Or)];
+
+/// Decision points of a Python file outside every function and lambda body:
+/// module- and class-level `if`, loops, `try`, and operators
+pub(crate) fn python_top_level_decisions(root: &tree_sitter::Node) -> usize {
+    ts_top_level_decisions(
+        root,
+        PYTHON_DECISION_KINDS,
+        PYTHON_DECISION_OPERATORS,
+        &["function_definition", "async_function_definition", "lambda"],
+    )
+}
+
+/// Cognitive complexity kinds (see `ts_cognitive_complexity`)
+const PYTHON_COGNITIVE_KINDS: CognitiveKinds = CognitiveKinds {
+    structural: &[
+        "while_statement",
+        "for_statement",
+        "match_statement",
+        "except_clause",
+        "conditional_expression",
+    ],
+    nesting_only: &["function_definition", "lambda"],
+    jumps: &[],
+    labeled_jumps: &[],
+    operators: &["and", "or"],
+};
+
+/// Extract metrics for Python functions using tree-sitter
+fn extract_python_metrics(function: &FunctionNode, cfg: &Cfg, nd_counts: NdCounts) -> RawMetrics {
+    let (_body_node_id, source) = function.body.as_python();
+    ts_with_function_body(
+        source,
+        tree_sitter_python::LANGUAGE.into(),
+        function.span.start,
+        &["function_definition", "async_function_definition"],
+        &["block"],
+        |func_node, body_node| {
+            let callee_names = python_extract_callees(&body_node, source);
+            let (nd, nd_position) = ts_nesting_depth(&body_node, PYTHON_NESTING_KINDS, nd_counts);
+            let ns_breakdown = ts_non_structured_exits(&body_node, PYTHON_EXIT_KINDS);
+            let cc_tally =
+                ts_cc_breakdown(&body_node, PYTHON_DECISION_KINDS, PYTHON_DECISION_OPERATORS);
+            RawMetrics {
+                cc: calculate_cc_from_cfg(cfg) + python_count_cc_extras(&body_node, source),
+                cognitive: ts_cognitive_complexity(&body_node, &PYTHON_COGNITIVE_KINDS),
+                nd,
+                nd_position,
+                fo: callee_names.len(),
+                ns: ns_breakdown.total(),
+                ns_breakdown,
+                loc: calculate_loc_from_node(&func_node),
+                callee_names,
+                arrow_depth: ts_arrow_depth(
+                    &body_node,
+                    &["block"],
+                    PYTHON_NESTING_KINDS,
+                    PYTHON_CHAIN_KINDS,
+                    PYTHON_EXIT_KINDS,
+                ),
+                signature_complexity: 0,
+                guard_clauses: ts_guard_clauses(
+                    &body_node,
+                    &["block"],
+                    PYTHON_NESTING_KINDS,
+                    PYTHON_CHAIN_KINDS,
+                    PYTHON_EXIT_KINDS,
+                ),
+                max_condition_ops: ts_max_condition_ops(
+                    &body_node,
+                    PYTHON_DECISION_KINDS,
+                    PYTHON_DECISION_OPERATORS,
+                ),
+                cc_breakdown: Some(cc_tally.breakdown),
+                decisions: cc_tally.decisions,
+                switches: vec![],
+                await_in_loop: 0,
+            }
+        },
+    )
+    .unwrap_or(RawMetrics {
+        cc: 1,
+        cognitive: 0,
+        nd: 0,
+        nd_position: None,
+        fo: 0,
+        ns: 0,
+        ns_breakdown: NsBreakdown::default(),
+        loc: 0,
+        callee_names: vec![],
+        arrow_depth: 0,
+        signature_complexity: 0,
+        guard_clauses: 0,
+        max_condition_ops: 0,
+        cc_breakdown: None,
+        decisions: vec![],
+        switches: vec![],
+        await_in_loop: 0,
+    })
+}
+
+/// Extract callee names from a Python function body.
+/// Returns the unique set of call target strings.
+fn python_extract_callees(body_node: &tree_sitter::Node, source: &str) -> Vec<String> {
+    fn collect(
+        node: tree_sitter::Node,
+        source: &str,
+        calls: &mut std::collections::HashSet<String>,
+    ) {
+        if node.kind() == "call" {
+            let mut cursor = node.walk();
+            if let Some(func_node) = node.children(&mut cursor).next() {
+                let func_text = &source[func_node.start_byte()..func_node.end_byte()];
+                calls.insert(func_text.to_string());
+            };
+        }
+        let mut cursor = node.walk();
+        for child in node.children(&mut cursor) {
+            collect(child, source, calls);
+        }
+    }
+
+    let mut calls = std::collections::HashSet::new();
+    collect(*body_node, source, &mut calls);
+    let mut result: Vec<String> = calls.into_iter().collect();
+    result.sort();
+    result
+}
+
+/// Count additional CC contributors in Python
+/// (comprehensions with if-filters, boolean operators, ternary expressions)
+fn python_count_cc_extras(body_node: &tree_sitter::Node, _source: &str) -> usize {
+    fn count_extras(node: tree_sitter::Node, count: &mut usize) {
+        match node.kind() {
+            // Boolean operators (and, or) add to CC
+            "boolean_operator" => {
+                *count += 1;
+            }
+            // Ternary expressions add to CC
+            "conditional_expression" => {
+                *count += 1;
+            }
+            // Comprehensions with if-filters add to CC
+            "list_comprehension"
+            | "dictionary_comprehension"
+            | "set_comprehension"
+            | "generator_expression" => {
+                // Check if it has an if_clause child
+                let mut cursor = node.walk();
+                for child in node.children(&mut cursor) {
+                    if child.kind() == "if_clause" {
+                        *count += 1;
+                        break;
+                    }
+                }
+            }
+            _ => {}
+        }
+
+        // Recursively check children
+        let mut cursor = node.walk();
+        for child in node.children(&mut cursor) {
+            count_extras(child, count);
+        }
+    }
+
+    let mut count = 0;
+    count_extras(*body_node, &mut count);
+    count
+}
+
+// Note: Python metrics tests are integrated with cfg_builder tests
+
+// ============================================================================
+// C# Metrics Implementation
+// ============================================================================
+
+/// Control structures that count toward ND.
+const CSHARP_NESTING_KINDS: &[&str] = &[
+    "if_statement",
+    "while_statement",
+    "do_statement",
+    "for_statement",
+    "foreach_statement",
+    "switch_statement",
+    "try_statement",
+];
+
+/// `if` and loops: the constructs that can form an arrow chain
+const CSHARP_CHAIN_KINDS: &[&str] = &[
+    "if_statement",
+    "while_statement",
+    "do_statement",
+    "for_statement",
+    "foreach_statement",
+];
+
+/// Statements that leave the current block early
+const CSHARP_EXIT_KINDS: &[&str] = &[
+    "return_statement",
+    "throw_statement",
+    "break_statement",
+    "continue_statement",
+];
+
+/// Switch statements (see `ts_switches`)
+const CSHARP_SWITCH_KINDS: SwitchKinds = SwitchKinds {
+    statements: &["switch_statement"],
+    clauses: &["switch_section"],
+    defaults: &["default_switch_label", "default"],
+    exhaustive: &[],
+};
+
+/// Decision points (see `ts_cc_breakdown`)
+const CSHARP_DECISION_KINDS: &[(&str, DecisionKind)] = &[
+    ("if_statement", DecisionKind::If),
+    ("while_statement", DecisionKind::Loop),
+    ("do_statement", DecisionKind::Loop),
+    ("for_statement", DecisionKind::Loop),
+    ("foreach_statement", DecisionKind::Loop),
+    ("switch_section", DecisionKind::Case),
+    ("catch_clause", DecisionKind::Catch),
+    ("conditional_expression", DecisionKind::Ternary),
+];
+
+/// Short-circuit operator tokens, including null-coalescing
+const CSHARP_DECISION_OPERATORS: &[(&str, DecisionKind)] = &[
+    ("&&", DecisionKind::And),
+    ("||", DecisionKind::Or),
+    ("??", DecisionKind::Coalesce),
+];
+
+/// Decision points of a C# file outside every discovered function:
+/// top-level statements, field initializers, and property accessors
+pub(crate) fn csharp_top_level_decisions(root: &tree_sitter::Node) -> usize {
+    ts_top_level_decisions(
+        root,
+        CSHARP_DECISION_KINDS,
+        CSHARP_DECISION_OPERATORS,
+        crate::language::csharp::FUNCTION_KINDS,
+    )
+}
+
+/// Cognitive complexity kinds (see `ts_cognitive_complexity`); `??` is not
+/// a logical operator here
+const CSHARP_COGNITIVE_KINDS: CognitiveKinds = CognitiveKinds {
+    structural: &[
+        "while_statement",
+        "do_statement",
+        "for_statement",
+        "foreach_statement",
+        "switch_statement",
+        "switch_expression",
+        "catch_clause",
+        "conditional_expression",
+    ],
+    nesting_only: &[
+        "lambda_expression",
+        "anonymous_method_expression",
+        "local_function_statement",
+    ],
+    jumps: &["goto_statement"],
+    labeled_jumps: &[],
+    operators: &["&&", "||"],
+};
+
+/// Control structures that count toward ND.
+const C_NESTING_KINDS: &[&str] = &[
+    "if_statement",
+    "while_statement",
+    "do_statement",
+    "for_statement",
+    "switch_statement",
+];
+
+/// `if` and loops: the constructs that can form an arrow chain
+const C_CHAIN_KINDS: &[&str] = &[
+    "if_statement",
+    "while_statement",
+    "do_statement",
+    "for_statement",
+];
+
+/// Statements that leave the current block early
+const C_EXIT_KINDS: &[&str] = &[
+    "return_statement",
+    "break_statement",
+    "continue_statement",
+    "goto_statement",
+];
+
+/// Switch statements (see `ts_switches`); `default:` is a `case_statement`
+/// without a value
+const C_SWITCH_KINDS: SwitchKinds = SwitchKinds {
+    statements: &["switch_statement"],
+    clauses: &["case_statement", "default_statement"],
+    defaults: &["default_statement", "default"],
+    exhaustive: &[],
+};
+
+/// Decision points (see `ts_cc_breakdown`)
+const C_DECISION_KINDS: &[(&str, DecisionKind)] = &[
+    ("if_statement", DecisionKind::If),
+    ("while_statement", DecisionKind::Loop),
+    ("do_statement", DecisionKind::Loop),
+    ("for_statement", DecisionKind::Loop),
+    ("case_statement", DecisionKind::Case),
+    ("conditional_expression", DecisionKind::Ternary),
+];
+
+/// Cognitive complexity kinds (see `ts_cognitive_complexity`)
+const C_COGNITIVE_KINDS: CognitiveKinds = CognitiveKinds {
+    structural: &[
+        "while_statement",
+        "do_statement",
+        "for_statement",
+        "switch_statement",
+        "conditional_expression",
+    ],
+    nesting_only: &[],
+    jumps: &["goto_statement"],
+    labeled_jumps: &[],
+    operators: &["&&", "||"],
+};
+
+/// Extract metrics for C# functions using tree-sitter
+fn extract_csharp_metrics(function: &FunctionNode, cfg: &Cfg, nd_counts: NdCounts) -> RawMetrics {
+    let (_body_node_id, source) = function.body.as_csharp();
+    ts_with_function_body(
+        source,
+        tree_sitter_c_sharp::LANGUAGE.into(),
+        function.span.start,
+        &[
+            "method_declaration",
+            "constructor_declaration",
+            "local_function_statement",
+            "operator_declaration",
+            "conversion_operator_declaration",
+        ],
+        &["block"],
+        |func_node, body_node| {
+            let callee_names = csharp_extract_callees(&body_node, source);
+            let (nd, nd_position) = ts_nesting_depth(&body_node, CSHARP_NESTING_KINDS, nd_counts);
+            let ns_breakdown = ts_non_structured_exits(&body_node, CSHARP_EXIT_KINDS);
+            let cc_tally =
+                ts_cc_breakdown(&body_node, CSHARP_DECISION_KINDS, CSHARP_DECISION_OPERATORS);
+            RawMetrics {
+                cc: calculate_cc_from_cfg(cfg) + csharp_count_cc_extras(&body_node, source),
+                cognitive: ts_cognitive_complexity(&body_node, &CSHARP_COGNITIVE_KINDS),
+                nd,
+                nd_position,
+                fo: callee_names.len(),
+                ns: ns_breakdown.total(),
+                ns_breakdown,
+                loc: calculate_loc_from_node(&func_node),
+                callee_names,
+                arrow_depth: ts_arrow_depth(
+                    &body_node,
+                    &["block"],
+                    CSHARP_NESTING_KINDS,
+                    CSHARP_CHAIN_KINDS,
+                    CSHARP_EXIT_KINDS,
+                ),
+                signature_complexity: crate::signature::csharp_signature(&func_node),
+                guard_clauses: ts_guard_clauses(
+                    &body_node,
+                    &["block"],
+                    CSHARP_NESTING_KINDS,
+                    CSHARP_CHAIN_KINDS,
+                    CSHARP_EXIT_KINDS,
+                ),
+                max_condition_ops: ts_max_condition_ops(
+                    &body_node,
+                    CSHARP_DECISION_KINDS,
+                    CSHARP_DECISION_OPERATORS,
+                ),
+                cc_breakdown: Some(cc_tally.breakdown),
+                decisions: cc_tally.decisions,
+                switches: ts_switches(&body_node, &CSHARP_SWITCH_KINDS, false),
+                await_in_loop: 0,
+            }
+        },
+    )
+    .unwrap_or(RawMetrics {
+        cc: 1,
+        cognitive: 0,
+        nd: 0,
+        nd_position: None,
+        fo: 0,
+        ns: 0,
+        ns_breakdown: NsBreakdown::default(),
+        loc: 0,
+        callee_names: vec![],
+        arrow_depth: 0,
+        signature_complexity: 0,
+        guard_clauses: 0,
+        max_condition_ops: 0,
+        cc_breakdown: None,
+        decisions: vec![],
+        switches: vec![],
+        await_in_loop: 0,
+    })
+}
+
+// ============================================================================
+// C Metrics Implementation
+// ============================================================================
+
+fn extract_c_metrics(function: &FunctionNode, cfg: &Cfg, nd_counts: NdCounts) -> RawMetrics {
+    let (_body_node_id, source) = function.body.as_c();
+    ts_with_function_body(
+        source,
+        tree_sitter_c::LANGUAGE.into(),
+        function.span.start,
+        &["function_definition"],
+        &["compound_statement"],
+        |func_node, body_node| {
+            let callee_names = c_extract_callees(&body_node, source);
+            let (nd, nd_position) = ts_nesting_depth(&body_node, C_NESTING_KINDS, nd_counts);
+            let ns_breakdown = ts_non_structured_exits(&body_node, C_EXIT_KINDS);
+            let cc_tally = ts_cc_breakdown(&body_node, C_DECISION_KINDS, TS_LOGICAL_OPERATORS);
+            RawMetrics {
+                cc: calculate_cc_from_cfg(cfg) + c_count_cc_extras(&body_node),
+                cognitive: ts_cognitive_complexity(&body_node, &C_COGNITIVE_KINDS),
+                nd,
+                nd_position,
+                fo: callee_names.len(),
+                ns: ns_breakdown.total(),
+                ns_breakdown,
+                loc: calculate_loc_from_node(&func_node),
+                callee_names,
+                arrow_depth: ts_arrow_depth(
+                    &body_node,
+                    &["compound_statement"],
+                    C_NESTING_KINDS,
+                    C_CHAIN_KINDS,
+                    C_EXIT_KINDS,
+                ),
+                signature_complexity: 0,
+                guard_clauses: ts_guard_clauses(
+                    &body_node,
+                    &["compound_statement"],
+                    C_NESTING_KINDS,
+                    C_CHAIN_KINDS,
+                    C_EXIT_KINDS,
+                ),
+                max_condition_ops: ts_max_condition_ops(
+                    &body_node,
+                    C_DECISION_KINDS,
+                    TS_LOGICAL_OPERATORS,
+                ),
+                cc_breakdown: Some(cc_tally.breakdown),
+                decisions: cc_tally.decisions,
+                switches: ts_switches(&body_node, &C_SWITCH_KINDS, false),
+                await_in_loop: 0,
+            }
+        },
+    )
+    .unwrap_or(RawMetrics {
+        cc: 1,
+        cognitive: 0,
+        nd: 0,
+        nd_position: None,
+        fo: 0,
+        ns: 0,
+        ns_breakdown: NsBreakdown::default(),
+        loc: 0,
+        callee_names: vec![],
+        arrow_depth: 0,
+        signature_complexity: 0,
+        guard_clauses: 0,
+        max_condition_ops: 0,
+        cc_breakdown: None,
+        decisions: vec![],
+        switches: vec![],
+        await_in_loop: 0,
+    })
+}
+
+/// Extract callee names from a C function body (call_expression nodes).
+fn c_extract_callees(body_node: &tree_sitter::Node, source: &str) -> Vec<String> {
+    fn collect(
+        node: tree_sitter::Node,
+        source: &str,
+        calls: &mut std::collections::HashSet<String>,
+    ) {
+        if node.kind() == "call_expression" {
+            // First child of call_expression is the function identifier
+            let child_count = node.child_count();
+            if child_count > 0 {
+                if let Some(callee) = node.child(0) {
+                    let text = &source[callee.start_byte()..callee.end_byte()];
+                    calls.insert(text.to_string());
+                }
+            }
+        }
+        let mut cursor = node.walk();
+        let children: Vec<_> = node.children(&mut cursor).collect();
+        for child in children {
+            collect(child, source, calls);
+        }
+    }
+
+    let mut calls = std::collections::HashSet::new();
+    collect(*body_node, source, &mut calls);
+    let mut result: Vec<String> = calls.into_iter().collect();
+    result.sort();
+    result
+}
+
+/// Count additional CC contributors in C (ternary expressions, boolean short-circuit operators).
+fn c_count_cc_extras(body_node: &tree_sitter::Node) -> usize {
+    fn count_extras(node: tree_sitter::Node, count: &mut usize) {
+        match node.kind() {
+            "conditional_expression" => {
+                *count += 1;
+            }
+            "binary_expression" => {
+                let mut cursor = node.walk();
+                for child in node.children(&mut cursor) {
+                    if child.kind() == "&&" || child.kind() == "||" {
+                        *count += 1;
+                        break;
+                    }
+                }
+            }
+            _ => {}
+        }
+        let mut cursor = node.walk();
+        for child in node.children(&mut cursor) {
+            count_extras(child, count);
+        }
+    }
+    let mut count = 0;
+    count_extras(*body_node, &mut count);
+    count
+}
+
+/// Extract callee names from a C# function body.
+fn csharp_extract_callees(body_node: &tree_sitter::Node, source: &str) -> Vec<String> {
+    fn collect(
+        node: tree_sitter::Node,
+        source: &str,
+        calls: &mut std::collections::HashSet<String>,
+    ) {
+        if node.kind() == "invocation_expression" {
+            let call_text = &source[node.start_byte()..node.end_byte()];
+            calls.insert(call_text.to_string());
+        }
+        let mut cursor = node.walk();
+        for child in node.children(&mut cursor) {
+            collect(child, source, calls);
+        }
+    }
+
+    let mut calls = std::collections::HashSet::new();
+    collect(*body_node, source, &mut calls);
+    let mut result: Vec<String> = calls.into_iter().collect();
+    result.sort();
+    result
+}
+
+/// Count additional CC contributors in C# (ternary, boolean operators, null-coalescing)
+fn csharp_count_cc_extras(body_node: &tree_sitter::Node, _source: &str) -> usize {
+    fn count_extras(node: tree_sitter::Node, count: &mut usize) {
+        match node.kind() {
+            "conditional_expression" => {
+                *count += 1;
+            }
+            "binary_expression" => {
+                let mut cursor = node.walk();
+                for child in node.children(&mut cursor) {
+                    if child.kind() == "&&" || child.kind() == "||" || child.kind() == "??" {
+                        *count += 1;
+                        break;
+                    }
+                }
+            }
+            _ => {}
+        }
+        let mut cursor = node.walk();
+        for child in node.children(&mut cursor) {
+            count_extras(child, count);
+        }
+    }
+
+    let mut count = 0;
+    count_extras(*body_node, &mut count);
+    count
+}
+
+// ============================================================================
+// C++ Metrics Implementation
+// ============================================================================
+
+/// Control structures that count toward ND.
+const CPP_NESTING_KINDS: &[&str] = &[
+    "if_statement",
+    "while_statement",
+    "do_statement",
+    "for_statement",
+    "for_range_loop",
+    "switch_statement",
+    "try_statement",
+];
+
+/// `if` and loops: the constructs that can form an arrow chain
+const CPP_CHAIN_KINDS: &[&str] = &[
+    "if_statement",
+    "while_statement",
+    "do_statement",
+    "for_statement",
+    "for_range_loop",
+];
+
+/// Statements that leave the current block early
+const CPP_EXIT_KINDS: &[&str] = &[
+    "return_statement",
+    "co_return_statement",
+    "throw_statement",
+    "break_statement",
+    "continue_statement",
+    "goto_statement",
+];
+
+/// Decision points (see `ts_cc_breakdown`)
+const CPP_DECISION_KINDS: &[(&str, DecisionKind)] = &[
+    ("if_statement", DecisionKind::If),
+    ("while_statement", DecisionKind::Loop),
+    ("do_statement", DecisionKind::Loop),
+    ("for_statement", DecisionKind::Loop),
+    ("for_range_loop", DecisionKind::Loop),
+    ("case_statement", DecisionKind::Case),
+    ("catch_clause", DecisionKind::Catch),
+    ("conditional_expression", DecisionKind::Ternary),
+];
+
+/// Cognitive complexity kinds (see `ts_cognitive_complexity`)
+const CPP_COGNITIVE_KINDS: CognitiveKinds = CognitiveKinds {
+    structural: &[
+        "while_statement",
+        "do_statement",
+        "for_statement",
+        "for_range_loop",
+        "switch_statement",
+        "catch_clause",
+        "conditional_expression",
+    ],
+    nesting_only: &["lambda_expression"],
+    jumps: &["goto_statement"],
+    labeled_jumps: &[],
+    operators: &["&&", "||"],
+};
+
+/// Extract metrics for C++ functions and lambdas using tree-sitter. Calls
+/// and CC extras are counted as in C (see `c_extract_callees` and
+/// `c_count_cc_extras`); `goto` and `catch` add to CC through the CFG.
+fn extract_cpp_metrics(function: &FunctionNode, cfg: &Cfg, nd_counts: NdCounts) -> RawMetrics {
+    let (_body_node_id, source) = function.body.as_cpp();
+    ts_with_function_body(
+        source,
+        tree_sitter_cpp::LANGUAGE.into(),
+        function.span.start,
+        crate::language::cpp::FUNCTION_KINDS,
+        // A function-try-block's body is the try statement
+        &["compound_statement", "try_statement"],
+        |func_node, body_node| {
+            let callee_names = c_extract_callees(&body_node, source);
+            let (nd, nd_position) = ts_nesting_depth(&body_node, CPP_NESTING_KINDS, nd_counts);
+            let ns_breakdown = ts_non_structured_exits(&body_node, CPP_EXIT_KINDS);
+            let cc_tally = ts_cc_breakdown(&body_node, CPP_DECISION_KINDS, TS_LOGICAL_OPERATORS);
+            RawMetrics {
+                cc: calculate_cc_from_cfg(cfg) + c_count_cc_extras(&body_node),
+                cognitive: ts_cognitive_complexity(&body_node, &CPP_COGNITIVE_KINDS),
+                nd,
+                nd_position,
+                fo: callee_names.len(),
+                ns: ns_breakdown.total(),
+                ns_breakdown,
+                loc: calculate_loc_from_node(&func_node),
+                callee_names,
+                arrow_depth: ts_arrow_depth(
+                    &body_node,
+                    &["compound_statement"],
+                    CPP_NESTING_KINDS,
+                    CPP_CHAIN_KINDS,
+                    CPP_EXIT_KINDS,
+                ),
+                signature_complexity: 0,
+                guard_clauses: ts_guard_clauses(
+                    &body_node,
+                    &["compound_statement"],
+                    CPP_NESTING_KINDS,
+                    CPP_CHAIN_KINDS,
+                    CPP_EXIT_KINDS,
+                ),
+                max_condition_ops: ts_max_condition_ops(
+                    &body_node,
+                    CPP_DECISION_KINDS,
+                    TS_LOGICAL_OPERATORS,
+                ),
+                cc_breakdown: Some(cc_tally.breakdown),
+                decisions: cc_tally.decisions,
+                switches: ts_switches(&body_node, &C_SWITCH_KINDS, false),
+                await_in_loop: 0,
+            }
+        },
+    )
+    .unwrap_or(RawMetrics {
+        cc: 1,
+        cognitive: 0,
+        nd: 0,
+        nd_position: None,
+        fo: 0,
+        ns: 0,
+        ns_breakdown: NsBreakdown::default(),
+        loc: 0,
+        callee_names: vec![],
+        arrow_depth: 0,
+        signature_complexity: 0,
+        guard_clauses: 0,
+        max_condition_ops: 0,
+        cc_breakdown: None,
+        decisions: vec![],
+        switches: vec![],
+        await_in_loop: 0,
+    })
+}
+
+// ============================================================================
+// Swift Metrics Implementation
+// ============================================================================
+
+/// Control structures that count toward ND.
+const SWIFT_NESTING_KINDS: &[&str] = &[
+    "if_statement",
+    "guard_statement",
+    "for_statement",
+    "while_statement",
+    "repeat_while_statement",
+    "switch_statement",
+    "do_statement",
+];
+
+/// `if` and loops: the constructs that can form an arrow chain
+const SWIFT_CHAIN_KINDS: &[&str] = &[
+    "if_statement",
+    "for_statement",
+    "while_statement",
+    "repeat_while_statement",
+];
+
+/// Switch statements (see `ts_switches`); a Swift `switch` must be exhaustive
+const SWIFT_SWITCH_KINDS: SwitchKinds = SwitchKinds {
+    statements: &["switch_statement"],
+    clauses: &["switch_entry"],
+    defaults: &[],
+    exhaustive: &["switch_statement"],
+};
+
+/// Decision points (see `ts_cc_breakdown`)
+const SWIFT_DECISION_KINDS: &[(&str, DecisionKind)] = &[
+    ("if_statement", DecisionKind::If),
+    ("guard_statement", DecisionKind::If),
+    ("for_statement", DecisionKind::Loop),
+    ("while_statement", DecisionKind::Loop),
+    ("repeat_while_statement", DecisionKind::Loop),
+    ("switch_entry", DecisionKind::Case),
+    ("catch_block", DecisionKind::Catch),
+    ("ternary_expression", DecisionKind::Ternary),
+    ("conjunction_expression", DecisionKind::And),
+    ("disjunction_expression", DecisionKind::Or),
+];
+
+/// Decision points of a Swift file outside every discovered function, such
+/// as top-level code in `main.swift` or a script
+pub(crate) fn swift_top_level_decisions(root: &tree_sitter::Node) -> usize {
+    ts_top_level_decisions(
+        root,
+        SWIFT_DECISION_KINDS,
+        &[],
+        crate::language::swift::FUNCTION_KINDS,
+    )
+}
+
+/// Structures other than `if` that cost 1 plus the nesting level in
+/// cognitive complexity (see `swift_cognitive_complexity`)
+const SWIFT_COGNITIVE_STRUCTURAL: &[&str] = &[
+    "guard_statement",
+    "for_statement",
+    "while_statement",
+    "repeat_while_statement",
+    "switch_statement",
+    "catch_block",
+    "ternary_expression",
+];
+
+/// Construct family of a Swift nesting kind. Swift's `do` is `do`/`catch`,
+/// not the `do`/`while` loop `ts_nesting_construct` maps it to.
+fn swift_nesting_construct(kind: &str) -> Option<NestingConstruct> {
+    match kind {
+        "guard_statement" => Some(NestingConstruct::If),
+        "repeat_while_statement" => Some(NestingConstruct::While),
+        "do_statement" => Some(NestingConstruct::Try),
+        _ => ts_nesting_construct(kind),
+    }
+}
+
+/// Extract metrics for Swift functions using tree-sitter
+fn extract_swift_metrics(function: &FunctionNode, cfg: &Cfg, nd_counts: NdCounts) -> RawMetrics {
+    let (_body_node_id, source) = function.body.as_swift();
+    ts_with_function_body(
+        source,
+        tree_sitter_swift::LANGUAGE.into(),
+        function.span.start,
+        crate::language::swift::FUNCTION_KINDS,
+        // Accessors hold their statements directly
+        &["function_body", "statements"],
+        |func_node, body_node| {
+            let callee_names = swift_extract_callees(&body_node, source);
+            let statements = swift_statements(body_node);
+            let (nd, nd_position) = ts_nesting_depth_by(
+                &body_node,
+                SWIFT_NESTING_KINDS,
+                nd_counts,
+                swift_nesting_construct,
+            );
+            let ns_breakdown = swift_non_structured_exits(&body_node, source);
+            let cc_tally = ts_cc_breakdown(&body_node, SWIFT_DECISION_KINDS, &[]);
+            RawMetrics {
+                cc: calculate_cc_from_cfg(cfg) + swift_count_cc_extras(&body_node),
+                cognitive: swift_cognitive_complexity(&body_node),
+                nd,
+                nd_position,
+                fo: callee_names.len(),
+                ns: ns_breakdown.total(),
+                ns_breakdown,
+                loc: calculate_loc_from_node(&func_node),
+                callee_names,
+                arrow_depth: swift_arrow_depth(&statements, source),
+                signature_complexity: 0,
+                guard_clauses: swift_guard_clauses(&statements, source),
+                max_condition_ops: ts_max_condition_ops(&body_node, SWIFT_DECISION_KINDS, &[]),
+                cc_breakdown: Some(cc_tally.breakdown),
+                decisions: cc_tally.decisions,
+                switches: ts_switches(&body_node, &SWIFT_SWITCH_KINDS, false),
+                await_in_loop: 0,
+            }
+        },
+    )
+    .unwrap_or(RawMetrics {
+        cc: 1,
+        cognitive: 0,
+        nd: 0,
+        nd_position: None,
+        fo: 0,
+        ns: 0,
+        ns_breakdown: NsBreakdown::default(),
+        loc: 0,
+        callee_names: vec![],
+        arrow_depth: 0,
+        signature_complexity: 0,
+        guard_clauses: 0,
+        max_condition_ops: 0,
+        cc_breakdown: None,
+        decisions: vec![],
+        switches: vec![],
+        await_in_loop: 0,
+    })
+}
+
+/// Statements of a Swift block: the named children of its `statements` node
+/// (comments skipped). `node` is a `statements` node, or a node whose first
+/// `{ ... }` block is wanted (`function_body`, `if`, loops).
+fn swift_statements(node: tree_sitter::Node) -> Vec<tree_sitter::Node> {
+    let statements = if node.kind() == "statements" {
+        Some(node)
+    } else {
+        crate::language::swift::blocks(node)
+            .into_iter()
+            .next()
+            .flatten()
+    };
+    let Some(statements) = statements else {
+        return vec![];
+    };
+    let mut cursor = statements.walk();
+    let result = statements
+        .named_children(&mut cursor)
+        .filter(|child| !child.kind().contains("comment"))
+        .collect();
+    result
+}
+
+/// `return`, `throw`, `break`, `continue`, or a call that never returns
+fn swift_is_exit(node: tree_sitter::Node, source: &str) -> bool {
+    use crate::language::swift::{control_transfer_keyword, is_fatal_call};
+    (node.kind() == "control_transfer_statement"
+        && control_transfer_keyword(node, source) != "yield")
+        || is_fatal_call(node, source)
+}
+
+/// Count non-structured exits: `return`, `throw`, `break`, `continue`, and
+/// `fatalError()` / `preconditionFailure()`
+fn swift_non_structured_exits(body_node: &tree_sitter::Node, source: &str) -> NsBreakdown {
+    use crate::language::swift::control_transfer_keyword;
+    fn recurse(node: tree_sitter::Node, source: &str, breakdown: &mut NsBreakdown) {
+        if swift_is_exit(node, source) {
+            breakdown.add(match control_transfer_keyword(node, source) {
+                "return" => ExitKind::Return,
+                "break" => ExitKind::Break,
+                "continue" => ExitKind::Continue,
+                // `throw`, and the fatal calls
+                _ => ExitKind::Throw,
+            });
+        }
+        let mut cursor = node.walk();
+        for child in node.children(&mut cursor) {
+            recurse(child, source, breakdown);
+        }
+    }
+    let mut breakdown = NsBreakdown::default();
+    recurse(*body_node, source, &mut breakdown);
+    breakdown
+}
+
+/// Count guard clauses (see `ts_guard_clauses`): leading `guard` statements,
+/// and leading `if`s without `else` whose block is a single exit, in the
+/// body and in each loop directly inside it
+fn swift_guard_clauses(statements: &[tree_sitter::Node], source: &str) -> usize {
+    let is_guard = |stmt: &tree_sitter::Node| match stmt.kind() {
+        "guard_statement" => true,
+        "if_statement" => {
+            ts_find_child_by_kind(*stmt, "else").is_none()
+                && matches!(swift_statements(*stmt).as_slice(), [only] if swift_is_exit(*only, source))
+        }
+        _ => false,
+    };
+    let leading = |stmts: &[tree_sitter::Node]| {
+        let mut count = 0;
+        for stmt in stmts {
+            if is_guard(stmt) {
+                count += 1;
+            } else if SWIFT_NESTING_KINDS.contains(&stmt.kind()) {
+                break;
+            }
+        }
+        count
+    };
+
+    let loop_guards: usize = statements
+        .iter()
+        .filter(|stmt| stmt.kind() != "if_statement" && SWIFT_CHAIN_KINDS.contains(&stmt.kind()))
+        .map(|stmt| leading(&swift_statements(*stmt)))
+        .sum();
+    leading(statements) + loop_guards
+}
+
+/// Calculate arrow depth (see `ts_arrow_depth`). A `guard` is a control
+/// structure that cannot chain, so it stops the arrow like an early return.
+fn swift_arrow_depth(statements: &[tree_sitter::Node], source: &str) -> usize {
+    let last = statements.len().saturating_sub(1);
+    let mut construct = None;
+    for (i, stmt) in statements.iter().enumerate() {
+        if SWIFT_NESTING_KINDS.contains(&stmt.kind()) {
+            if construct.is_some() {
+                return 0;
+            }
+            construct = Some(*stmt);
+        } else if swift_is_exit(*stmt, source) && i != last {
+            return 0;
+        }
+    }
+    match construct {
+        Some(c)
+            if SWIFT_CHAIN_KINDS.contains(&c.kind())
+                && ts_find_child_by_kind(c, "else").is_none() =>
+        {
+            1 + swift_arrow_depth(&swift_statements(c), source)
+        }
+        _ => 0,
+    }
+}
+
+/// Calculate cognitive complexity (see `ts_cognitive_complexity`).
+///
+/// tree-sitter-swift gives `if` no `consequence` / `alternative` fields: the
+/// blocks are inlined and an `else` token separates them. `&&` and `||` are
+/// `conjunction_expression` / `disjunction_expression` nodes, and a
+/// parenthesized expression is a one-element `tuple_expression`.
+fn swift_cognitive_complexity(body_node: &tree_sitter::Node) -> usize {
+    fn recurse(
+        node: tree_sitter::Node,
+        nesting: usize,
+        logical_parent: Option<&str>,
+        total: &mut usize,
+    ) {
+        let kind = node.kind();
+        if kind == "if_statement" {
+            if_chain(node, nesting, false, total);
+            return;
+        }
+        let mut inner = nesting;
+        let mut operator = None;
+        if SWIFT_COGNITIVE_STRUCTURAL.contains(&kind) {
+            *total += 1 + nesting;
+            inner += 1;
+        } else if matches!(kind, "lambda_literal" | "function_declaration") {
+            inner += 1;
+        } else if kind == "control_transfer_statement" {
+            // A labeled `break` / `continue` carries the label as a child
+            let jump = node
+                .child(0)
+                .is_some_and(|k| matches!(k.kind(), "break" | "continue"));
+            if jump && node.named_child_count() > 0 {
+                *total += 1;
+            }
+        } else if matches!(kind, "conjunction_expression" | "disjunction_expression") {
+            operator = Some(kind);
+            if operator != logical_parent {
+                *total += 1;
+            }
+        } else if kind == "tuple_expression" && node.named_child_count() == 1 {
+            // Parentheses do not end an operator sequence
+            operator = logical_parent;
+        }
+        let mut cursor = node.walk();
+        for child in node.children(&mut cursor) {
+            recurse(child, inner, operator, total);
+        }
+    }
+
+    /// An `if` and its `else if` / `else` chain
+    fn if_chain(node: tree_sitter::Node, nesting: usize, else_if: bool, total: &mut usize) {
+        *total += if else_if { 1 } else { 1 + nesting };
+        let mut after_else = false;
+        let mut cursor = node.walk();
+        for child in node.children(&mut cursor) {
+            match child.kind() {
+                "else" => after_else = true,
+                "if_statement" if after_else => if_chain(child, nesting, true, total),
+                // A plain `else` block
+                "{" if after_else => *total += 1,
+                "statements" => recurse(child, nesting + 1, None, total),
+                _ => recurse(child, nesting, None, total),
+            }
+        }
+    }
+
+    let mut total = 0;
+    recurse(*body_node, 0, None, &mut total);
+    total
+}
+
+/// Extract callee names from a Swift function body: the called expression of
+/// each `call_expression` (`fetch`, `client.fetch`)
+fn swift_extract_callees(body_node: &tree_sitter::Node, source: &str) -> Vec<String> {
+    fn collect(
+        node: tree_sitter::Node,
+        source: &str,
+        calls: &mut std::collections::HashSet<String>,
+    ) {
+        if node.kind() == "call_expression" {
+            if let Some(callee) = node
+                .child(0)
+                .filter(|c| matches!(c.kind(), "simple_identifier" | "navigation_expression"))
+            {
+                calls.insert(source[callee.start_byte()..callee.end_byte()].to_string());
+            }
+        }
+        let mut cursor = node.walk();
+        for child in node.children(&mut cursor) {
+            collect(child, source, calls);
+        }
+    }
+
+    let mut calls = std::collections::HashSet::new();
+    collect(*body_node, source, &mut calls);
+    let mut result: Vec<String> = calls.into_iter().collect();
+    result.sort();
+    result
+}
+
+/// Count additional CC contributors in Swift (ternary, `&&`, `||`); branches,
+/// loops, `case` entries, and `catch` blocks come from the CFG
+fn swift_count_cc_extras(body_node: &tree_sitter::Node) -> usize {
+    fn count_extras(node: tree_sitter::Node, count: &mut usize) {
+        if matches!(
+            node.kind(),
+            "ternary_expression" | "conjunction_expression" | "disjunction_expression"
+        ) {
+            *count += 1;
+        }
+        let mut cursor = node.walk();
+        for child in node.children(&mut cursor) {
+            count_extras(child, count);
+        }
+    }
+    let mut count = 0;
+    count_extras(*body_node, &mut count);
+    count
+}
+
+// ============================================================================
+// PHP Metrics Implementation
+// ============================================================================
+
+/// Control structures that count toward ND.
+const PHP_NESTING_KINDS: &[&str] = &[
+    "if_statement",
+    "while_statement",
+    "do_statement",
+    "for_statement",
+    "foreach_statement",
+    "switch_statement",
+    "match_expression",
+    "try_statement",
+];
+
+/// `if` and loops: the constructs that can form an arrow chain
+const PHP_CHAIN_KINDS: &[&str] = &[
+    "if_statement",
+    "while_statement",
+    "do_statement",
+    "for_statement",
+    "foreach_statement",
+];
+
+/// Statements and expressions that leave the current block early; `throw`
+/// is an expression in PHP 8
+const PHP_EXIT_KINDS: &[&str] = &[
+    "return_statement",
+    "break_statement",
+    "continue_statement",
+    "goto_statement",
+    "exit_statement",
+    "throw_expression",
+];
+
+/// Blocks: `{ ... }` and the `: ... endif;` alternative syntax
+const PHP_BLOCK_KINDS: &[&str] = &["compound_statement", "colon_block"];
+
+/// Switch statements (see `ts_switches`)
+const PHP_SWITCH_KINDS: SwitchKinds = SwitchKinds {
+    statements: &["switch_statement"],
+    clauses: &["case_statement", "default_statement"],
+    defaults: &["default_statement"],
+    exhaustive: &[],
+};
+
+/// Decision points (see `ts_cc_breakdown`)
+const PHP_DECISION_KINDS: &[(&str, DecisionKind)] = &[
+    ("if_statement", DecisionKind::If),
+    ("else_if_clause", DecisionKind::If),
+    ("while_statement", DecisionKind::Loop),
+    ("do_statement", DecisionKind::Loop),
+    ("for_statement", DecisionKind::Loop),
+    ("foreach_statement", DecisionKind::Loop),
+    ("case_statement", DecisionKind::Case),
+    ("default_statement", DecisionKind::Case),
+    ("catch_clause", DecisionKind::Catch),
+    ("match_conditional_expression", DecisionKind::MatchArm),
+    ("conditional_expression", DecisionKind::Ternary),
+];
+
+/// Short-circuit operator tokens: `&&` / `and`, `||` / `or`, and
+/// null-coalescing
+const PHP_DECISION_OPERATORS: &[(&str, DecisionKind)] = &[
+    ("&&", DecisionKind::And),
+    ("and", DecisionKind::And),
+    ("||", DecisionKind::Or),
+    ("or", DecisionKind::Or),
+    ("??", DecisionKind::Coalesce),
+];
+
+/// Decision points of a PHP file outside every function, method, and
+/// closure body
+pub(crate) fn php_top_level_decisions(root: &tree_sitter::Node) -> usize {
+    ts_top_level_decisions(
+        root,
+        PHP_DECISION_KINDS,
+        PHP_DECISION_OPERATORS,
+        crate::language::php::FUNCTION_KINDS,
+    )
+}
+
+/// Cognitive complexity kinds (see `ts_cognitive_complexity`); `break 2`
+/// and `continue 2` are the labeled jumps
+const PHP_COGNITIVE_KINDS: CognitiveKinds = CognitiveKinds {
+    structural: &[
+        "while_statement",
+        "do_statement",
+        "for_statement",
+        "foreach_statement",
+        "switch_statement",
+        "match_expression",
+        "catch_clause",
+        "conditional_expression",
+    ],
+    nesting_only: &["anonymous_function", "arrow_function"],
+    jumps: &["goto_statement"],
+    labeled_jumps: &["break_statement", "continue_statement"],
+    operators: &["&&", "||", "and", "or"],
+};
+
+/// Construct family of a PHP nesting kind
+fn php_nesting_construct(kind: &str) -> Option<NestingConstruct> {
+    match kind {
+        "match_expression" => Some(NestingConstruct::Match),
+        _ => ts_nesting_construct(kind),
+    }
+}
+
+/// Extract metrics for PHP functions using tree-sitter. The body is the
+/// `body` field: a block, or the expression of an arrow function.
+fn extract_php_metrics(function: &FunctionNode, cfg: &Cfg, nd_counts: NdCounts) -> RawMetrics {
+    use crate::language::php::FUNCTION_KINDS;
+    use crate::language::tree_sitter_utils::with_cached_php_tree;
+
+    let (_body_node_id, source) = function.body.as_php();
+    with_cached_php_tree(source, |root| {
+        let func_node = ts_find_function_by_start(root, function.span.start, FUNCTION_KINDS)?;
+        let body_node = func_node.child_by_field_name("body")?;
+        let callee_names = php_extract_callees(&body_node, source);
+        let (nd, nd_position) = ts_nesting_depth_by(
+            &body_node,
+            PHP_NESTING_KINDS,
+            nd_counts,
+            php_nesting_construct,
+        );
+        let ns_breakdown = ts_non_structured_exits(&body_node, PHP_EXIT_KINDS);
+        let cc_tally = ts_cc_breakdown(&body_node, PHP_DECISION_KINDS, PHP_DECISION_OPERATORS);
+        Some(RawMetrics {
+            cc: calculate_cc_from_cfg(cfg) + php_count_cc_extras(&body_node),
+            cognitive: ts_cognitive_complexity(&body_node, &PHP_COGNITIVE_KINDS),
+            nd,
+            nd_position,
+            fo: callee_names.len(),
+            ns: ns_breakdown.total(),
+            ns_breakdown,
+            loc: calculate_loc_from_node(&func_node),
+            callee_names,
+            arrow_depth: ts_arrow_depth(
+                &body_node,
+                PHP_BLOCK_KINDS,
+                PHP_NESTING_KINDS,
+                PHP_CHAIN_KINDS,
+                PHP_EXIT_KINDS,
+            ),
+            signature_complexity: 0,
+            guard_clauses: ts_guard_clauses(
+                &body_node,
+                PHP_BLOCK_KINDS,
+                PHP_NESTING_KINDS,
+                PHP_CHAIN_KINDS,
+                PHP_EXIT_KINDS,
+            ),
+            max_condition_ops: ts_max_condition_ops(
+                &body_node,
+                PHP_DECISION_KINDS,
+                PHP_DECISION_OPERATORS,
+            ),
+            cc_breakdown: Some(cc_tally.breakdown),
+            decisions: cc_tally.decisions,
+            switches: ts_switches(&body_node, &PHP_SWITCH_KINDS, false),
+            await_in_loop: 0,
+        })
+    })
+    .unwrap_or(RawMetrics {
+        cc: 1,
+        cognitive: 0,
+        nd: 0,
+        nd_position: None,
+        fo: 0,
+        ns: 0,
+        ns_breakdown: NsBreakdown::default(),
+        loc: 0,
+        callee_names: vec![],
+        arrow_depth: 0,
+        signature_complexity: 0,
+        guard_clauses: 0,
+        max_condition_ops: 0,
+        cc_breakdown: None,
+        decisions: vec![],
+        switches: vec![],
+        await_in_loop: 0,
+    })
+}
+
+/// Extract callee names from a PHP function body: the function of a plain
+/// call (`strlen`, `$callback`), or the receiver and method of a method or
+/// static call (`$this->save`, `$user?->name`, `Cache::get`)
+fn php_extract_callees(body_node: &tree_sitter::Node, source: &str) -> Vec<String> {
+    fn collect(
+        node: tree_sitter::Node,
+        source: &str,
+        calls: &mut std::collections::BTreeSet<String>,
+    ) {
+        let callee = match node.kind() {
+            "function_call_expression" => node
+                .child_by_field_name("function")
+                .map(|f| &source[f.start_byte()..f.end_byte()]),
+            "member_call_expression"
+            | "nullsafe_member_call_expression"
+            | "scoped_call_expression" => node
+                .child_by_field_name("name")
+                .map(|name| &source[node.start_byte()..name.end_byte()]),
+            _ => None,
+        };
+        if let Some(callee) = callee {
+            calls.insert(callee.to_string());
+        }
+        let mut cursor = node.walk();
+        for child in node.children(&mut cursor) {
+            collect(child, source, calls);
+        }
+    }
+
+    let mut calls = std::collections::BTreeSet::new();
+    collect(*body_node, source, &mut calls);
+    calls.into_iter().collect()
+}
+
+/// Count additional CC contributors in PHP (ternary, `&&` / `and`, `||` /
+/// `or`, `??`, `match` arms); branches, loops, `case` labels, and `catch`
+/// clauses come from the CFG
+fn php_count_cc_extras(body_node: &tree_sitter::Node) -> usize {
+    fn count_extras(node: tree_sitter::Node, count: &mut usize) {
+        match node.kind() {
+            "conditional_expression" | "match_conditional_expression" => {
+                *count += 1;
+            }
+            "binary_expression" => {
+                let mut cursor = node.walk();
+                let short_circuit = node
+                    .children(&mut cursor)
+                    .any(|child| matches!(child.kind(), "&&" | "||" | "and" | "or" | "??"));
+                if short_circuit {
+                    *count += 1;
+                }
+            }
+            _ => {}
+        }
+        let mut cursor = node.walk();
+        for child in node.children(&mut cursor) {
+            count_extras(child, count);
+        }
+    }
+    let mut count = 0;
+    count_extras(*body_node, &mut count);
+    count
+}
+
+// ============================================================================
+// Scala Metrics Implementation
+// ============================================================================
+
+/// Control expressions that count toward ND.
+const SCALA_NESTING_KINDS: &[&str] = &[
+    "if_expression",
+    "match_expression",
+    "for_expression",
+    "while_expression",
+    "do_while_expression",
+    "try_expression",
+];
+
+/// `if` and loops: the constructs that can form an arrow chain
+const SCALA_CHAIN_KINDS: &[&str] = &[
+    "if_expression",
+    "for_expression",
+    "while_expression",
+    "do_while_expression",
+];
+
+/// Expressions that leave the function early; Scala has no `break` or
+/// `continue` statements
+const SCALA_EXIT_KINDS: &[&str] = &["return_expression", "throw_expression"];
+
+/// Structures other than `if` that cost 1 plus the nesting level in
+/// cognitive complexity (see `scala_cognitive_complexity`)
+const SCALA_COGNITIVE_STRUCTURAL: &[&str] = &[
+    "match_expression",
+    "for_expression",
+    "while_expression",
+    "do_while_expression",
+    "catch_clause",
+];
+
+/// Construct family of a Scala nesting kind
+fn scala_nesting_construct(kind: &str) -> Option<NestingConstruct> {
+    match kind {
+        "if_expression" => Some(NestingConstruct::If),
+        "match_expression" => Some(NestingConstruct::Match),
+        "for_expression" => Some(NestingConstruct::For),
+        "while_expression" | "do_while_expression" => Some(NestingConstruct::While),
+        "try_expression" => Some(NestingConstruct::Try),
+        _ => None,
+    }
+}
+
+/// Extract metrics for Scala functions using tree-sitter. A `def` is measured
+/// under its body; a bound lambda as a whole.
+fn extract_scala_metrics(function: &FunctionNode, cfg: &Cfg, nd_counts: NdCounts) -> RawMetrics {
+    use crate::language::scala::{body_root, body_statements, FUNCTION_KINDS};
+    use crate::language::tree_sitter_utils::with_cached_scala_tree;
+
+    let (_body_node_id, source) = function.body.as_scala();
+    with_cached_scala_tree(source, |root| {
+        let func_node = ts_find_function_by_start(root, function.span.start, FUNCTION_KINDS)?;
+        let body_node = body_root(func_node)?;
+        let statements = body_statements(func_node);
+        let callee_names = scala_extract_callees(&body_node, source);
+        let (nd, nd_position) = ts_nesting_depth_by(
+            &body_node,
+            SCALA_NESTING_KINDS,
+            nd_counts,
+            scala_nesting_construct,
+        );
+        let ns_breakdown = ts_non_structured_exits(&body_node, SCALA_EXIT_KINDS);
+        let cc_tally = scala_cc_breakdown(&body_node, source);
+        Some(RawMetrics {
+            cc: calculate_cc_from_cfg(cfg) + scala_count_cc_extras(&body_node, source),
+            cognitive: scala_cognitive_complexity(&body_node, source),
+            nd,
+            nd_position,
+            fo: callee_names.len(),
+            ns: ns_breakdown.total(),
+            ns_breakdown,
+            loc: calculate_loc_from_node(&func_node),
+            callee_names,
+            arrow_depth: scala_arrow_depth(&statements),
+            signature_complexity: 0,
+            guard_clauses: scala_guard_clauses(&statements),
+            max_condition_ops: scala_max_condition_ops(&body_node, source),
+            cc_breakdown: Some(cc_tally.breakdown),
+            decisions: cc_tally.decisions,
+            switches: vec![],
+            await_in_loop: 0,
+        })
+    })
+    .unwrap_or(RawMetrics {
+        cc: 1,
+        cognitive: 0,
+        nd: 0,
+        nd_position: None,
+        fo: 0,
+        ns: 0,
+        ns_breakdown: NsBreakdown::default(),
+        loc: 0,
+        callee_names: vec![],
+        arrow_depth: 0,
+        signature_complexity: 0,
+        guard_clauses: 0,
+        max_condition_ops: 0,
+        cc_breakdown: None,
+        decisions: vec![],
+        switches: vec![],
+        await_in_loop: 0,
+    })
+}
+
+/// `&&` / `||` of an `infix_expression`. Scala operators are method names,
+/// so the operator is an identifier node rather than a token kind and
+/// `ts_decision_kind` cannot see it.
+fn scala_logical_operator(node: tree_sitter::Node, source: &str) -> Option<DecisionKind> {
+    if node.kind() != "infix_expression" {
+        return None;
+    }
+    let operator = node.child_by_field_name("operator")?;
+    match &source[operator.start_byte()..operator.end_byte()] {
+        "&&" => Some(DecisionKind::And),
+        "||" => Some(DecisionKind::Or),
+        _ => None,
+    }
+}
+
+/// Extract callee names from a Scala function body: the called expression of
+/// each `call_expression` (`validate`, `repo.save`, `Some`)
+fn scala_extract_callees(body_node: &tree_sitter::Node, source: &str) -> Vec<String> {
+    fn collect(
+        node: tree_sitter::Node,
+        source: &str,
+        calls: &mut std::collections::BTreeSet<String>,
+    ) {
+        if node.kind() == "call_expression" {
+            if let Some(callee) = node.child_by_field_name("function") {
+                calls.insert(source[callee.start_byte()..callee.end_byte()].to_string());
+            }
+        }
+        let mut cursor = node.walk();
+        for child in node.children(&mut cursor) {
+            collect(child, source, calls);
+        }
+    }
+
+    let mut calls = std::collections::BTreeSet::new();
+    collect(*body_node, source, &mut calls);
+    calls.into_iter().collect()
+}
+
+/// Count additional CC contributors in Scala (`&&`, `||`, and `if` guards on
+/// `case` clauses and `for` generators); branches, loops, `case` clauses, and
+/// `catch` cases come from the CFG
+fn scala_count_cc_extras(body_node: &tree_sitter::Node, source: &str) -> usize {
+    fn count_extras(node: tree_sitter::Node, source: &str, count: &mut usize) {
+        if node.kind() == "guard" || scala_logical_operator(node, source).is_some() {
+            *count += 1;
+        }
+        let mut cursor = node.walk();
+        for child in node.children(&mut cursor) {
+            count_extras(child, source, count);
+        }
+    }
+    let mut count = 0;
+    count_extras(*body_node, source, &mut count);
+    count
+}
+
+/// Tally CC decision points (see `ts_cc_breakdown`). A `case` is a match arm,
+/// or a catch when it belongs to a `catch` clause.
+fn scala_cc_breakdown(body_node: &tree_sitter::Node, source: &str) -> CcTally {
+    let mut tally = CcTally::default();
+    scala_visit_decisions(*body_node, source, false, &[], &mut |kind, node| {
+        tally.add_node(kind, node)
+    });
+    tally
+}
+
+/// Decision points of a Scala file outside every function and lambda, such
+/// as `object` and `class` bodies and top-level statements
+pub(crate) fn scala_top_level_decisions(root: &tree_sitter::Node, source: &str) -> usize {
+    let mut count = 0;
+    scala_visit_decisions(
+        *root,
+        source,
+        false,
+        crate::language::scala::FUNCTION_KINDS,
+        &mut |_, _| count += 1,
+    );
+    count
+}
+
+/// Call `visit` on each decision point under `node`, not descending into
+/// `skip` kinds. `in_catch` says whether a `case` is a catch.
+fn scala_visit_decisions<'a>(
+    node: tree_sitter::Node<'a>,
+    source: &str,
+    in_catch: bool,
+    skip: &[&str],
+    visit: &mut impl FnMut(DecisionKind, tree_sitter::Node<'a>),
+) {
+    let kind = match node.kind() {
+        "if_expression" | "guard" => Some(DecisionKind::If),
+        "for_expression" | "while_expression" | "do_while_expression" => Some(DecisionKind::Loop),
+        "case_clause" if in_catch => Some(DecisionKind::Catch),
+        "case_clause" => Some(DecisionKind::MatchArm),
+        _ => scala_logical_operator(node, source),
+    };
+    if let Some(kind) = kind {
+        visit(kind, node);
+    }
+    let in_catch = match node.kind() {
+        "catch_clause" => true,
+        "match_expression" => false,
+        _ => in_catch,
+    };
+    let mut cursor = node.walk();
+    for child in node.children(&mut cursor) {
+        if !skip.contains(&child.kind()) {
+            scala_visit_decisions(child, source, in_catch, skip, visit);
+        }
+    }
+}
+
+/// Largest number of `&&` / `||` operators in one boolean expression (see
+/// `ts_max_condition_ops`)
+fn scala_max_condition_ops(body_node: &tree_sitter::Node, source: &str) -> usize {
+    fn count(node: tree_sitter::Node, source: &str) -> usize {
+        let own = usize::from(scala_logical_operator(node, source).is_some());
+        let mut cursor = node.walk();
+        let nested: usize = node
+            .children(&mut cursor)
+            .map(|child| count(child, source))
+            .sum();
+        own + nested
+    }
+    fn recurse(node: tree_sitter::Node, source: &str, max: &mut usize) {
+        if scala_logical_operator(node, source).is_some() {
+            // The outermost operator's count covers every operator below it
+            *max = (*max).max(count(node, source));
+            return;
+        }
+        let mut cursor = node.walk();
+        for child in node.children(&mut cursor) {
+            recurse(child, source, max);
+        }
+    }
+    let mut max = 0;
+    recurse(*body_node, source, &mut max);
+    max
+}
+
+/// Calculate cognitive complexity (see `ts_cognitive_complexity`). `if` is
+/// `if_expression`, whose `else if` is an `if_expression` as the
+/// `alternative`; `&&` and `||` are `infix_expression`s.
+fn scala_cognitive_complexity(body_node: &tree_sitter::Node, source: &str) -> usize {
+    fn recurse(
+        node: tree_sitter::Node,
+        source: &str,
+        nesting: usize,
+        logical_parent: Option<DecisionKind>,
+        total: &mut usize,
+    ) {
+        let kind = node.kind();
+        if kind == "if_expression" {
+            if_chain(node, source, nesting, false, total);
+            return;
+        }
+        let mut inner = nesting;
+        let mut operator = None;
+        if SCALA_COGNITIVE_STRUCTURAL.contains(&kind) {
+            *total += 1 + nesting;
+            inner += 1;
+        } else if matches!(kind, "lambda_expression" | "function_definition") {
+            inner += 1;
+        } else if let Some(op) = scala_logical_operator(node, source) {
+            operator = Some(op);
+            if operator != logical_parent {
+                *total += 1;
+            }
+        } else if kind == "parenthesized_expression" {
+            // Parentheses do not end an operator sequence
+            operator = logical_parent;
+        }
+        let mut cursor = node.walk();
+        for child in node.children(&mut cursor) {
+            recurse(child, source, inner, operator, total);
+        }
+    }
+
+    /// An `if` and its `else if` / `else` chain
+    fn if_chain(
+        node: tree_sitter::Node,
+        source: &str,
+        nesting: usize,
+        else_if: bool,
+        total: &mut usize,
+    ) {
+        *total += if else_if { 1 } else { 1 + nesting };
+        let mut cursor = node.walk();
+        for (i, child) in node.children(&mut cursor).enumerate() {
+            match node.field_name_for_child(i as u32) {
+                Some("consequence") => recurse(child, source, nesting + 1, None, total),
+                Some("alternative") if child.kind() == "if_expression" => {
+                    if_chain(child, source, nesting, true, total)
+                }
+                Some("alternative") => {
+                    *total += 1;
+                    recurse(child, source, nesting + 1, None, total);
+                }
+                _ => recurse(child, source, nesting, None, total),
+            }
+        }
+    }
+
+    let mut total = 0;
+    recurse(*body_node, source, 0, None, &mut total);
+    total
+}
+
+/// Whether a statement leaves the function (`return` / `throw`)
+fn scala_is_exit(node: tree_sitter::Node) -> bool {
+    SCALA_EXIT_KINDS.contains(&node.kind())
+}
+
+/// Statements of the branch an `if` or loop runs: its `consequence` or `body`
+fn scala_inner_statements(construct: tree_sitter::Node) -> Vec<tree_sitter::Node> {
+    construct
+        .child_by_field_name("consequence")
+        .or_else(|| construct.child_by_field_name("body"))
+        .map(crate::language::scala::block_statements)
+        .unwrap_or_default()
+}
+
+/// Count guard clauses (see `ts_guard_clauses`): leading `if`s without
+/// `else` whose branch is a single `return` or `throw`, in the body and in
+/// each loop directly inside it
+fn scala_guard_clauses(statements: &[tree_sitter::Node]) -> usize {
+    let is_guard = |stmt: &tree_sitter::Node| {
+        stmt.kind() == "if_expression"
+            && stmt.child_by_field_name("alternative").is_none()
+            && matches!(scala_inner_statements(*stmt).as_slice(), [only] if scala_is_exit(*only))
+    };
+    let leading = |stmts: &[tree_sitter::Node]| {
+        let mut count = 0;
+        for stmt in stmts {
+            if is_guard(stmt) {
+                count += 1;
+            } else if SCALA_NESTING_KINDS.contains(&stmt.kind()) {
+                break;
+            }
+        }
+        count
+    };
+
+    let loop_guards: usize = statements
+        .iter()
+        .filter(|stmt| stmt.kind() != "if_expression" && SCALA_CHAIN_KINDS.contains(&stmt.kind()))
+        .map(|stmt| leading(&scala_inner_statements(*stmt)))
+        .sum();
+    leading(statements) + loop_guards
+}
+
+/// Calculate arrow depth (see `ts_arrow_depth`)
+fn scala_arrow_depth(statements: &[tree_sitter::Node]) -> usize {
+    let last = statements.len().saturating_sub(1);
+    let mut construct = None;
+    for (i, stmt) in statements.iter().enumerate() {
+        if SCALA_NESTING_KINDS.contains(&stmt.kind()) {
+            if construct.is_some() {
+                return 0;
+            }
+            construct = Some(*stmt);
+        } else if scala_is_exit(*stmt) && i != last {
+            return 0;
+        }
+    }
+    match construct {
+        Some(c)
+            if SCALA_CHAIN_KINDS.contains(&c.kind())
+                && c.child_by_field_name("alternative").is_none() =>
+        {
+            1 + scala_arrow_depth(&scala_inner_statements(c))
+        }
+        _ => 0,
+    }
+}
+
+// ============================================================================
+// Dart Metrics Implementation
+// ============================================================================
+
+/// Control statements and expressions that count toward ND. Collection `if`
+/// and `for` (`if (loading) Spinner()` in a widget list) nest like their
+/// statement forms; widget constructor calls nested in each other do not.
+const DART_NESTING_KINDS: &[&str] = &[
+    "if_statement",
+    "for_statement",
+    "while_statement",
+    "do_statement",
+    "switch_statement",
+    "switch_expression",
+    "try_statement",
+    "if_element",
+    "for_element",
+];
+
+/// `if` and loops: the constructs that can form an arrow chain
+const DART_CHAIN_KINDS: &[&str] = &[
+    "if_statement",
+    "for_statement",
+    "while_statement",
+    "do_statement",
+];
+
+/// Switch statements (see `ts_switches`)
+const DART_SWITCH_KINDS: SwitchKinds = SwitchKinds {
+    statements: &["switch_statement"],
+    clauses: &["switch_statement_case", "switch_statement_default"],
+    defaults: &["switch_statement_default"],
+    exhaustive: &[],
+};
+
+/// Statements and expressions that leave the function or loop early;
+/// `throw` and `rethrow` are expressions
+const DART_EXIT_KINDS: &[&str] = &[
+    "return_statement",
+    "throw_expression",
+    "rethrow_expression",
+    "break_statement",
+    "continue_statement",
+];
+
+/// Structures other than `if` and `try` that cost 1 plus the nesting level
+/// in cognitive complexity (see `dart_cognitive_complexity`)
+const DART_COGNITIVE_STRUCTURAL: &[&str] = &[
+    "for_statement",
+    "while_statement",
+    "do_statement",
+    "switch_statement",
+    "switch_expression",
+    "conditional_expression",
+    "if_element",
+    "for_element",
+];
+
+/// Construct family of a Dart nesting kind
+fn dart_nesting_construct(kind: &str) -> Option<NestingConstruct> {
+    match kind {
+        "if_statement" | "if_element" => Some(NestingConstruct::If),
+        "for_statement" | "for_element" => Some(NestingConstruct::For),
+        "while_statement" | "do_statement" => Some(NestingConstruct::While),
+        "switch_statement" | "switch_expression" => Some(NestingConstruct::Switch),
+        "try_statement" => Some(NestingConstruct::Try),
+        _ => None,
+    }
+}
+
+/// Extract metrics for Dart functions using tree-sitter, under the function's
+/// `function_body` (closures included, local functions measured on their
+/// own)
+fn extract_dart_metrics(function: &FunctionNode, cfg: &Cfg, nd_counts: NdCounts) -> RawMetrics {
+    use crate::language::dart::{body_statements, find_function, function_body};
+    use crate::language::tree_sitter_utils::with_cached_dart_tree;
+
+    let (_body_node_id, source) = function.body.as_dart();
+    with_cached_dart_tree(source, |root| {
+        let func_node = find_function(root, function.span.start)?;
+        let body_node = function_body(func_node)?;
+        let statements = body_statements(body_node);
+        let callee_names = dart_extract_callees(&body_node, source);
+        let (nd, nd_position) = ts_nesting_depth_by(
+            &body_node,
+            DART_NESTING_KINDS,
+            nd_counts,
+            dart_nesting_construct,
+        );
+        let ns_breakdown = ts_non_structured_exits(&body_node, DART_EXIT_KINDS);
+        // The signature and body are siblings, so LOC comes from the span
+        let loc = function
+            .span
+            .end_line
+            .saturating_sub(function.span.start_line)
+            + 1;
+        let cc_tally = dart_cc_breakdown(&body_node, source);
+        Some(RawMetrics {
+            cc: calculate_cc_from_cfg(cfg) + dart_count_cc_extras(&body_node, source),
+            cognitive: dart_cognitive_complexity(&body_node, source),
+            nd,
+            nd_position,
+            fo: callee_names.len(),
+            ns: ns_breakdown.total(),
+            ns_breakdown,
+            loc: loc as usize,
+            callee_names,
+            arrow_depth: dart_arrow_depth(&statements),
+            signature_complexity: 0,
+            guard_clauses: dart_guard_clauses(&statements),
+            max_condition_ops: dart_max_condition_ops(&body_node, source),
+            cc_breakdown: Some(cc_tally.breakdown),
+            decisions: cc_tally.decisions,
+            switches: ts_switches(&body_node, &DART_SWITCH_KINDS, false),
+            await_in_loop: 0,
+        })
+    })
+    .unwrap_or(RawMetrics {
+        cc: 1,
+        cognitive: 0,
+        nd: 0,
+        nd_position: None,
+        fo: 0,
+        ns: 0,
+        ns_breakdown: NsBreakdown::default(),
+        loc: 0,
+        callee_names: vec![],
+        arrow_depth: 0,
+        signature_complexity: 0,
+        guard_clauses: 0,
+        max_condition_ops: 0,
+        cc_breakdown: None,
+        decisions: vec![],
+        switches: vec![],
+        await_in_loop: 0,
+    })
+}
+
+/// The operator decision a token is: `&&`, `||`, or a null-aware `??`,
+/// `??=`, or `?.`
+fn dart_operator(node: tree_sitter::Node, source: &str) -> Option<DecisionKind> {
+    // Operator rules may be named leaves or wrap an anonymous token; only
+    // leaves count so each operator is seen once. `||` and `&&` also join
+    // patterns (`case 1 || 2`), which is not a decision.
+    if node.child_count() > 0
+        || node
+            .parent()
+            .is_some_and(|parent| parent.kind().contains("pattern"))
+    {
+        return None;
+    }
+    match node.kind() {
+        "&&" | "logical_and_operator" => Some(DecisionKind::And),
+        "||" | "logical_or_operator" => Some(DecisionKind::Or),
+        "??" | "??=" | "?." => Some(DecisionKind::Coalesce),
+        "assignment_operator" if &source[node.start_byte()..node.end_byte()] == "??=" => {
+            Some(DecisionKind::Coalesce)
+        }
+        _ => None,
+    }
+}
+
+/// Visit every CC decision point of a Dart function body, with the node it
+/// starts at and whether the CFG already counts it: statements outside
+/// closures are in the CFG,
+/// while expressions (`?:`, operators, collection `if` / `for`,
+/// switch-expression arms) and anything inside a closure are not. Local
+/// functions are skipped.
+fn dart_visit_decisions(
+    body_node: &tree_sitter::Node,
+    source: &str,
+    visit: &mut dyn FnMut(DecisionKind, tree_sitter::Node, bool),
+) {
+    use crate::language::dart::{is_closure, is_counted_arm, is_nested_definition, try_parts};
+
+    fn recurse(
+        node: tree_sitter::Node,
+        source: &str,
+        in_closure: bool,
+        visit: &mut dyn FnMut(DecisionKind, tree_sitter::Node, bool),
+    ) {
+        let in_cfg = !in_closure;
+        match node.kind() {
+            "if_statement" => visit(DecisionKind::If, node, in_cfg),
+            "for_statement" | "while_statement" | "do_statement" => {
+                visit(DecisionKind::Loop, node, in_cfg)
+            }
+            "switch_statement_case" => visit(DecisionKind::Case, node, in_cfg),
+            "try_statement" => {
+                for handler in try_parts(node).1 {
+                    visit(DecisionKind::Catch, handler, in_cfg);
+                }
+            }
+            "if_element" => visit(DecisionKind::If, node, false),
+            "for_element" => visit(DecisionKind::Loop, node, false),
+            "switch_expression_case" if is_counted_arm(node, source) => {
+                visit(DecisionKind::Case, node, false)
+            }
+            "conditional_expression" => visit(DecisionKind::Ternary, node, false),
+            _ => {
+                if let Some(kind) = dart_operator(node, source) {
+                    visit(kind, node, false);
+                }
+            }
+        }
+        let mut cursor = node.walk();
+        for child in node.children(&mut cursor) {
+            if !is_nested_definition(child) {
+                recurse(child, source, in_closure || is_closure(child), visit);
+            }
+        }
+    }
+    recurse(*body_node, source, false, visit);
+}
+
+/// Count additional CC contributors in Dart: every decision the CFG does not
+/// see (see `dart_visit_decisions`)
+fn dart_count_cc_extras(body_node: &tree_sitter::Node, source: &str) -> usize {
+    let mut count = 0;
+    dart_visit_decisions(body_node, source, &mut |_, _, in_cfg| {
+        if !in_cfg {
+            count += 1;
+        }
+    });
+    count
+}
+
+/// Tally CC decision points (see `ts_cc_breakdown`): each `on` / `catch`
+/// handler is a catch, and switch-expression arms are cases
+fn dart_cc_breakdown(body_node: &tree_sitter::Node, source: &str) -> CcTally {
+    let mut tally = CcTally::default();
+    dart_visit_decisions(body_node, source, &mut |kind, node, _| {
+        tally.add_node(kind, node)
+    });
+    tally
+}
+
+/// Largest number of `&&` / `||` operators in one boolean expression (see
+/// `ts_max_condition_ops`)
+fn dart_max_condition_ops(body_node: &tree_sitter::Node, source: &str) -> usize {
+    fn is_logical(node: tree_sitter::Node) -> bool {
+        matches!(
+            node.kind(),
+            "logical_and_expression" | "logical_or_expression"
+        )
+    }
+    fn count(node: tree_sitter::Node, source: &str) -> usize {
+        let own = usize::from(matches!(
+            dart_operator(node, source),
+            Some(DecisionKind::And | DecisionKind::Or)
+        ));
+        let mut cursor = node.walk();
+        let nested: usize = node
+            .children(&mut cursor)
+            .map(|child| count(child, source))
+            .sum();
+        own + nested
+    }
+    fn recurse(node: tree_sitter::Node, source: &str, max: &mut usize) {
+        if is_logical(node) {
+            // The outermost expression's count covers every operator below it
+            *max = (*max).max(count(node, source));
+            return;
+        }
+        let mut cursor = node.walk();
+        for child in node.children(&mut cursor) {
+            recurse(child, source, max);
+        }
+    }
+    let mut max = 0;
+    recurse(*body_node, source, &mut max);
+    max
+}
+
+/// Calculate cognitive complexity (see `ts_cognitive_complexity`). An
+/// `else if` is an `if_statement` as the `else` branch; each `on` /
+/// `catch` handler costs 1 plus the nesting level; closures and local
+/// functions nest their contents.
+fn dart_cognitive_complexity(body_node: &tree_sitter::Node, source: &str) -> usize {
+    use crate::language::dart::{if_branches, try_parts};
+
+    fn recurse(
+        node: tree_sitter::Node,
+        source: &str,
+        nesting: usize,
+        logical_parent: Option<DecisionKind>,
+        total: &mut usize,
+    ) {
+        let kind = node.kind();
+        if kind == "if_statement" {
+            if_chain(node, source, nesting, false, total);
+            return;
+        }
+        if kind == "try_statement" {
+            let (_, handlers) = try_parts(node);
+            let mut cursor = node.walk();
+            for child in node.children(&mut cursor) {
+                if handlers.contains(&child) {
+                    *total += 1 + nesting;
+                    recurse(child, source, nesting + 1, None, total);
+                } else {
+                    recurse(child, source, nesting, None, total);
+                }
+            }
+            return;
+        }
+        let mut inner = nesting;
+        let mut operator = None;
+        if DART_COGNITIVE_STRUCTURAL.contains(&kind) {
+            *total += 1 + nesting;
+            inner += 1;
+        } else if matches!(kind, "function_expression" | "lambda_expression") {
+            inner += 1;
+        } else if matches!(kind, "break_statement" | "continue_statement")
+            && node.named_child_count() > 0
+        {
+            // A labeled jump
+            *total += 1;
+        } else if matches!(kind, "logical_and_expression" | "logical_or_expression") {
+            let mut cursor = node.walk();
+            operator = node
+                .children(&mut cursor)
+                .find_map(|child| dart_operator(child, source));
+            if operator != logical_parent {
+                *total += 1;
+            }
+        } else if kind == "parenthesized_expression" {
+            // Parentheses do not end an operator sequence
+            operator = logical_parent;
+        }
+        let mut cursor = node.walk();
+        for child in node.children(&mut cursor) {
+            recurse(child, source, inner, operator, total);
+        }
+    }
+
+    /// An `if` and its `else if` / `else` chain
+    fn if_chain(
+        node: tree_sitter::Node,
+        source: &str,
+        nesting: usize,
+        else_if: bool,
+        total: &mut usize,
+    ) {
+        *total += if else_if { 1 } else { 1 + nesting };
+        let (consequence, alternative) = if_branches(node);
+        let mut cursor = node.walk();
+        for child in node.children(&mut cursor) {
+            if Some(child) == consequence {
+                recurse(child, source, nesting + 1, None, total);
+            } else if Some(child) == alternative {
+                if child.kind() == "if_statement" {
+                    if_chain(child, source, nesting, true, total);
+                } else {
+                    *total += 1;
+                    recurse(child, source, nesting + 1, None, total);
+                }
+            } else {
+                recurse(child, source, nesting, None, total);
+            }
+        }
+    }
+
+    let mut total = 0;
+    recurse(*body_node, source, 0, None, &mut total);
+    total
+}
+
+/// Statements of the branch an `if` or loop runs: its `then` branch or body
+fn dart_inner_statements(construct: tree_sitter::Node) -> Vec<tree_sitter::Node> {
+    use crate::language::dart::{block_statements, if_branches, loop_body};
+
+    let inner = if construct.kind() == "if_statement" {
+        if_branches(construct).0
+    } else {
+        loop_body(construct)
+    };
+    inner.map(block_statements).unwrap_or_default()
+}
+
+/// Whether an `if` statement has an `else` branch
+fn dart_has_else(construct: tree_sitter::Node) -> bool {
+    construct.kind() == "if_statement" && crate::language::dart::if_branches(construct).1.is_some()
+}
+
+/// Count guard clauses (see `ts_guard_clauses`): leading `if`s without
+/// `else` whose branch is a single exit, in the body and in each loop
+/// directly inside it
+fn dart_guard_clauses(statements: &[tree_sitter::Node]) -> usize {
+    let is_guard = |stmt: &tree_sitter::Node| {
+        stmt.kind() == "if_statement"
+            && !dart_has_else(*stmt)
+            && matches!(
+                dart_inner_statements(*stmt).as_slice(),
+                [only] if ts_is_exit(*only, DART_EXIT_KINDS)
+            )
+    };
+    let leading = |stmts: &[tree_sitter::Node]| {
+        let mut count = 0;
+        for stmt in stmts {
+            if is_guard(stmt) {
+                count += 1;
+            } else if DART_NESTING_KINDS.contains(&stmt.kind()) {
+                break;
+            }
+        }
+        count
+    };
+
+    let loop_guards: usize = statements
+        .iter()
+        .filter(|stmt| stmt.kind() != "if_statement" && DART_CHAIN_KINDS.contains(&stmt.kind()))
+        .map(|stmt| leading(&dart_inner_statements(*stmt)))
+        .sum();
+    leading(statements) + loop_guards
+}
+
+/// Calculate arrow depth (see `ts_arrow_depth`)
+fn dart_arrow_depth(statements: &[tree_sitter::Node]) -> usize {
+    let last = statements.len().saturating_sub(1);
+    let mut construct = None;
+    for (i, stmt) in statements.iter().enumerate() {
+        if DART_NESTING_KINDS.contains(&stmt.kind()) {
+            if construct.is_some() {
+                return 0;
+            }
+            construct = Some(*stmt);
+        } else if ts_is_exit(*stmt, DART_EXIT_KINDS) && i != last {
+            return 0;
+        }
+    }
+    match construct {
+        Some(c) if DART_CHAIN_KINDS.contains(&c.kind()) && !dart_has_else(c) => {
+            1 + dart_arrow_depth(&dart_inner_statements(c))
+        }
+        _ => 0,
+    }
+}
+
+/// Extract callee names from a Dart function body: the expression before
+/// each argument list (`validate`, `repo.save`, `Navigator.of`, widget
+/// constructors such as `Padding`), and the constructor of each `new` /
+/// `const` instance creation
+fn dart_extract_callees(body_node: &tree_sitter::Node, source: &str) -> Vec<String> {
+    fn collect(
+        node: tree_sitter::Node,
+        source: &str,
+        calls: &mut std::collections::BTreeSet<String>,
+    ) {
+        let callee = match node.kind() {
+            // A call is a `selector` holding the arguments, after the
+            // expression it calls
+            "selector" if ts_find_child_by_kind(node, "argument_part").is_some() => {
+                let mut start = None;
+                let mut sibling = node.prev_named_sibling();
+                while let Some(prev) = sibling {
+                    start = Some(prev.start_byte());
+                    if prev.kind() != "selector" {
+                        break;
+                    }
+                    sibling = prev.prev_named_sibling();
+                }
+                start.map(|start| source[start..node.start_byte()].trim())
+            }
+            // The type and any named constructor (`EdgeInsets.all`)
+            "new_expression" | "const_object_expression" => {
+                let ty = ts_find_child_by_kind(node, "type_identifier");
+                let arguments = ts_find_child_by_kind(node, "arguments");
+                ty.zip(arguments)
+                    .map(|(ty, arguments)| source[ty.start_byte()..arguments.start_byte()].trim())
+            }
+            _ => None,
+        };
+        if let Some(callee) = callee.filter(|callee| !callee.is_empty()) {
+            calls.insert(callee.to_string());
+        }
+        let mut cursor = node.walk();
+        for child in node.children(&mut cursor) {
+            collect(child, source, calls);
+        }
+    }
+
+    let mut calls = std::collections::BTreeSet::new();
+    collect(*body_node, source, &mut calls);
+    calls.into_iter().collect()
+}
+
+// ============================================================================
+// Elixir Metrics Implementation
+// ============================================================================
+
+/// Control calls that count toward ND
+const ELIXIR_NESTING_CALLS: &[&str] = &[
+    "if", "unless", "case", "cond", "with", "receive", "try", "for",
+];
+
+/// `if`, `unless`, and `for`: the constructs that can form an arrow chain
+const ELIXIR_CHAIN_CALLS: &[&str] = &["if", "unless", "for"];
+
+/// Calls that are syntax rather than calls to another function: definitions,
+/// control flow, exits, and the module directives
+const ELIXIR_SPECIAL_FORMS: &[&str] = &[
+    "def",
+    "defp",
+    "defmacro",
+    "defmacrop",
+    "defmodule",
+    "if",
+    "unless",
+    "case",
+    "cond",
+    "with",
+    "receive",
+    "try",
+    "for",
+    "raise",
+    "reraise",
+    "throw",
+    "exit",
+    "quote",
+    "unquote",
+    "import",
+    "alias",
+    "require",
+    "use",
+];
+
+/// Construct family of an Elixir nesting call. `case`, `cond`, `with`, and
+/// `receive` all choose a clause by pattern or condition, like `match`.
+fn elixir_nesting_construct(name: &str) -> Option<NestingConstruct> {
+    match name {
+        "if" | "unless" => Some(NestingConstruct::If),
+        "case" | "cond" | "with" | "receive" => Some(NestingConstruct::Match),
+        "try" => Some(NestingConstruct::Try),
+        "for" => Some(NestingConstruct::For),
+        _ => None,
+    }
+}
+
+/// Extract metrics for Elixir functions using tree-sitter, over every clause
+/// of the function (anonymous functions included, definitions inside
+/// `quote` measured on their own)
+fn extract_elixir_metrics(function: &FunctionNode, cfg: &Cfg, nd_counts: NdCounts) -> RawMetrics {
+    use crate::language::elixir::{do_statements, find_function};
+    use crate::language::tree_sitter_utils::with_cached_elixir_tree;
+
+    let (_body_node_id, source) = function.body.as_elixir();
+    with_cached_elixir_tree(source, |root| {
+        let clauses = find_function(root, function.span.start, source)?;
+        let bodies: Vec<Vec<tree_sitter::Node>> = clauses
+            .iter()
+            .map(|clause| do_statements(*clause, source))
+            .collect();
+        let callee_names = elixir_extract_callees(&bodies, source);
+        let (nd, nd_position) = elixir_nesting_depth(&clauses, source, nd_counts);
+        let ns_breakdown = elixir_non_structured_exits(&clauses, source);
+        // Clauses are siblings, so LOC comes from the span
+        let loc = function
+            .span
+            .end_line
+            .saturating_sub(function.span.start_line)
+            + 1;
+        let cc_tally = elixir_cc_breakdown(&clauses, source);
+        Some(RawMetrics {
+            cc: calculate_cc_from_cfg(cfg) + elixir_count_cc_extras(&clauses, source),
+            cognitive: elixir_cognitive_complexity(&clauses, source),
+            nd,
+            nd_position,
+            fo: callee_names.len(),
+            ns: ns_breakdown.total(),
+            ns_breakdown,
+            loc: loc as usize,
+            callee_names,
+            arrow_depth: bodies
+                .iter()
+                .map(|statements| elixir_arrow_depth(statements, source))
+                .max()
+                .unwrap_or(0),
+            signature_complexity: 0,
+            guard_clauses: bodies
+                .iter()
+                .map(|statements| elixir_guard_clauses(statements, source))
+                .sum(),
+            max_condition_ops: elixir_max_condition_ops(&clauses, source),
+            cc_breakdown: Some(cc_tally.breakdown),
+            decisions: cc_tally.decisions,
+            switches: vec![],
+            await_in_loop: 0,
+        })
+    })
+    .unwrap_or(RawMetrics {
+        cc: 1,
+        cognitive: 0,
+        nd: 0,
+        nd_position: None,
+        fo: 0,
+        ns: 0,
+        ns_breakdown: NsBreakdown::default(),
+        loc: 0,
+        callee_names: vec![],
+        arrow_depth: 0,
+        signature_complexity: 0,
+        guard_clauses: 0,
+        max_condition_ops: 0,
+        cc_breakdown: None,
+        decisions: vec![],
+        switches: vec![],
+        await_in_loop: 0,
+    })
+}
+
+/// The operator of a `binary_operator` node (`&&`, `when`, `|>`)
+fn elixir_binary_operator<'a>(node: tree_sitter::Node, source: &'a str) -> Option<&'a str> {
+    if node.kind() != "binary_operator" {
+        return None;
+    }
+    let op = node.child_by_field_name("operator")?;
+    Some(&source[op.start_byte()..op.end_byte()])
+}
+
+/// The decision a boolean operator is: `&&` / `and`, or `||` / `or`
+fn elixir_logical_operator(node: tree_sitter::Node, source: &str) -> Option<DecisionKind> {
+    match elixir_binary_operator(node, source)? {
+        "&&" | "and" => Some(DecisionKind::And),
+        "||" | "or" => Some(DecisionKind::Or),
+        _ => None,
+    }
+}
+
+/// Children of a clause to measure: everything but nested definitions
+fn elixir_children<'a>(node: tree_sitter::Node<'a>, source: &str) -> Vec<tree_sitter::Node<'a>> {
+    use crate::language::elixir::is_nested_definition;
+
+    let mut cursor = node.walk();
+    let children = node
+        .children(&mut cursor)
+        .filter(|child| !is_nested_definition(*child, source))
+        .collect();
+    children
+}
+
+/// Visit every CC decision point of an Elixir function, with the node it
+/// starts at and whether the CFG already counts it: each clause after the first, and control calls
+/// outside anonymous functions, are in the CFG; guards (`when`), boolean
+/// operators, comprehension filters, extra clauses of an anonymous
+/// function, and anything inside an anonymous function are not.
+fn elixir_visit_decisions(
+    clauses: &[tree_sitter::Node],
+    source: &str,
+    visit: &mut dyn FnMut(DecisionKind, tree_sitter::Node, bool),
+) {
+    use crate::language::elixir::{
+        call_name, clause_block, comprehension_filters, do_block, match_clauses, stab_clauses,
+    };
+
+    fn block_clauses(block: Option<tree_sitter::Node>) -> Vec<tree_sitter::Node> {
+        block.map(stab_clauses).unwrap_or_default()
+    }
+
+    fn recurse(
+        node: tree_sitter::Node,
+        source: &str,
+        in_closure: bool,
+        visit: &mut dyn FnMut(DecisionKind, tree_sitter::Node, bool),
+    ) {
+        let in_cfg = !in_closure;
+        let mut each = |kind, nodes: Vec<tree_sitter::Node>, in_cfg| {
+            for node in nodes {
+                visit(kind, node, in_cfg);
+            }
+        };
+        match call_name(node, source) {
+            Some("if" | "unless") => each(DecisionKind::If, vec![node], in_cfg),
+            Some("case" | "cond" | "receive") => {
+                let mut arms = block_clauses(do_block(node));
+                arms.extend(block_clauses(clause_block(node, "after_block")));
+                each(DecisionKind::MatchArm, arms, in_cfg);
+            }
+            Some("with") => {
+                let mut arms = match_clauses(node, source);
+                arms.extend(block_clauses(clause_block(node, "else_block")));
+                each(DecisionKind::MatchArm, arms, in_cfg);
+            }
+            Some("try") => {
+                let mut handlers = block_clauses(clause_block(node, "rescue_block"));
+                handlers.extend(block_clauses(clause_block(node, "catch_block")));
+                each(DecisionKind::Catch, handlers, in_cfg);
+                let arms = block_clauses(clause_block(node, "else_block"));
+                each(DecisionKind::MatchArm, arms, in_cfg);
+            }
+            Some("for") => {
+                each(DecisionKind::Loop, vec![node], in_cfg);
+                each(DecisionKind::If, comprehension_filters(node, source), false);
+            }
+            _ => {}
+        }
+        if node.kind() == "anonymous_function" {
+            let arms: Vec<_> = stab_clauses(node).into_iter().skip(1).collect();
+            each(DecisionKind::MatchArm, arms, false);
+        } else if elixir_binary_operator(node, source) == Some("when") {
+            each(DecisionKind::If, vec![node], false);
+        } else if let Some(kind) = elixir_logical_operator(node, source) {
+            each(kind, vec![node], false);
+        }
+        for child in elixir_children(node, source) {
+            let in_closure = in_closure || child.kind() == "anonymous_function";
+            recurse(child, source, in_closure, visit);
+        }
+    }
+
+    for (i, clause) in clauses.iter().enumerate() {
+        if i > 0 {
+            // Each clause after the first is another head to match
+            visit(DecisionKind::MatchArm, *clause, true);
+        }
+        for child in elixir_children(*clause, source) {
+            recurse(child, source, child.kind() == "anonymous_function", visit);
+        }
+    }
+}
+
+/// Count additional CC contributors in Elixir: every decision the CFG does
+/// not see (see `elixir_visit_decisions`)
+fn elixir_count_cc_extras(clauses: &[tree_sitter::Node], source: &str) -> usize {
+    let mut count = 0;
+    elixir_visit_decisions(clauses, source, &mut |_, _, in_cfg| {
+        if !in_cfg {
+            count += 1;
+        }
+    });
+    count
+}
+
+/// Tally CC decision points (see `ts_cc_breakdown`): clauses of `case`,
+/// `cond`, `receive`, and multi-clause functions, `with` matches, and
+/// `with` / `try` `else` clauses are match arms; guards are ifs; `rescue`
+/// and `catch` clauses are catches
+fn elixir_cc_breakdown(clauses: &[tree_sitter::Node], source: &str) -> CcTally {
+    let mut tally = CcTally::default();
+    elixir_visit_decisions(clauses, source, &mut |kind, node, _| {
+        tally.add_node(kind, node)
+    });
+    tally
+}
+
+/// Maximum nesting depth of control calls (see `ts_nesting_depth_by`),
+/// over every clause
+fn elixir_nesting_depth(
+    clauses: &[tree_sitter::Node],
+    source: &str,
+    nd_counts: NdCounts,
+) -> (usize, Option<NestPosition>) {
+    use crate::language::elixir::call_name;
+
+    fn recurse(
+        node: tree_sitter::Node,
+        source: &str,
+        nd_counts: NdCounts,
+        current: usize,
+        max: &mut usize,
+        line: &mut usize,
+    ) {
+        let nests = call_name(node, source).is_some_and(|name| {
+            ELIXIR_NESTING_CALLS.contains(&name)
+                && elixir_nesting_construct(name).map_or(true, |c| nd_counts.counts(c))
+        });
+        let next = if nests {
+            let d = current + 1;
+            if d > *max {
+                *max = d;
+                *line = node.start_position().row + 1;
+            }
+            d
+        } else {
+            current
+        };
+        for child in elixir_children(node, source) {
+            recurse(child, source, nd_counts, next, max, line);
+        }
+    }
+
+    let mut max_depth = 0;
+    let mut line = 0;
+    for clause in clauses {
+        for child in elixir_children(*clause, source) {
+            recurse(child, source, nd_counts, 0, &mut max_depth, &mut line);
+        }
+    }
+    let position = (max_depth > 0).then_some(NestPosition::Line(line as u32));
+    (max_depth, position)
+}
+
+/// Whether `node` is a call that raises or exits (`raise`, `reraise`,
+/// `throw`, `exit`)
+fn elixir_is_exit(node: tree_sitter::Node, source: &str) -> bool {
+    use crate::language::elixir::{call_name, EXIT_CALLS};
+
+    call_name(node, source).is_some_and(|name| EXIT_CALLS.contains(&name))
+}
+
+/// Count non-structured exits: Elixir has no `return`, `break`, or
+/// `continue`, so only calls that raise or exit count
+fn elixir_non_structured_exits(clauses: &[tree_sitter::Node], source: &str) -> NsBreakdown {
+    fn recurse(node: tree_sitter::Node, source: &str, breakdown: &mut NsBreakdown) {
+        if elixir_is_exit(node, source) {
+            breakdown.add(ExitKind::Throw);
+        }
+        for child in elixir_children(node, source) {
+            recurse(child, source, breakdown);
+        }
+    }
+    let mut breakdown = NsBreakdown::default();
+    for clause in clauses {
+        for child in elixir_children(*clause, source) {
+            recurse(child, source, &mut breakdown);
+        }
+    }
+    breakdown
+}
+
+/// Largest number of `&&` / `||` / `and` / `or` operators in one boolean
+/// expression (see `ts_max_condition_ops`)
+fn elixir_max_condition_ops(clauses: &[tree_sitter::Node], source: &str) -> usize {
+    fn count(node: tree_sitter::Node, source: &str) -> usize {
+        let own = usize::from(elixir_logical_operator(node, source).is_some());
+        let mut cursor = node.walk();
+        let nested: usize = node
+            .children(&mut cursor)
+            .map(|child| count(child, source))
+            .sum();
+        own + nested
+    }
+    fn recurse(node: tree_sitter::Node, source: &str, max: &mut usize) {
+        if elixir_logical_operator(node, source).is_some() {
+            // The outermost expression's count covers every operator below it
+            *max = (*max).max(count(node, source));
+            return;
+        }
+        for child in elixir_children(node, source) {
+            recurse(child, source, max);
+        }
+    }
+    let mut max = 0;
+    for clause in clauses {
+        for child in elixir_children(*clause, source) {
+            recurse(child, source, &mut max);
+        }
+    }
+    max
+}
+
+/// Calculate cognitive complexity (see `ts_cognitive_complexity`). `if`,
+/// `unless`, `case`, `cond`, `with`, `receive`, and `for` cost 1 plus the
+/// nesting level, and an `else` of `if` / `unless` costs 1; each `rescue` /
+/// `catch` block costs 1 plus the nesting level; anonymous functions nest
+/// their contents. Extra function clauses and guards cost nothing: they
+/// replace branching rather than add to it.
+fn elixir_cognitive_complexity(clauses: &[tree_sitter::Node], source: &str) -> usize {
+    use crate::language::elixir::{call_name, else_statements};
+
+    fn recurse(
+        node: tree_sitter::Node,
+        source: &str,
+        nesting: usize,
+        logical_parent: Option<DecisionKind>,
+        total: &mut usize,
+    ) {
+        let name = call_name(node, source);
+        if name.is_some_and(|name| ELIXIR_NESTING_CALLS.contains(&name)) {
+            let is_try = name == Some("try");
+            if !is_try {
+                *total += 1 + nesting;
+            }
+            if matches!(name, Some("if" | "unless")) && else_statements(node, source).is_some() {
+                *total += 1;
+            }
+            structure(node, source, nesting, is_try, total);
+            return;
+        }
+        let mut inner = nesting;
+        let mut operator = None;
+        if node.kind() == "anonymous_function" {
+            inner += 1;
+        } else if let Some(kind) = elixir_logical_operator(node, source) {
+            operator = Some(kind);
+            if operator != logical_parent {
+                *total += 1;
+            }
+        } else if node.kind() == "block" {
+            // Parentheses do not end an operator sequence
+            operator = logical_parent;
+        }
+        for child in elixir_children(node, source) {
+            recurse(child, source, inner, operator, total);
+        }
+    }
+
+    /// The parts of a control call: its arguments at the current nesting,
+    /// its blocks one level deeper. A `try` body stays at the current level
+    /// and each `rescue` / `catch` block is a structure of its own.
+    fn structure(
+        node: tree_sitter::Node,
+        source: &str,
+        nesting: usize,
+        is_try: bool,
+        total: &mut usize,
+    ) {
+        for child in elixir_children(node, source) {
+            match child.kind() {
+                "do_block" => {
+                    for part in elixir_children(child, source) {
+                        if is_try && matches!(part.kind(), "rescue_block" | "catch_block") {
+                            *total += 1 + nesting;
+                            recurse(part, source, nesting + 1, None, total);
+                        } else {
+                            let depth = if is_try { nesting } else { nesting + 1 };
+                            recurse(part, source, depth, None, total);
+                        }
+                    }
+                }
+                _ => recurse(child, source, nesting, None, total),
+            }
+        }
+    }
+
+    let mut total = 0;
+    for clause in clauses {
+        for child in elixir_children(*clause, source) {
+            recurse(child, source, 0, None, &mut total);
+        }
+    }
+    total
+}
+
+/// Whether an `if` / `unless` has an `else`
+fn elixir_has_else(construct: tree_sitter::Node, source: &str) -> bool {
+    use crate::language::elixir::{call_name, else_statements};
+
+    matches!(call_name(construct, source), Some("if" | "unless"))
+        && else_statements(construct, source).is_some()
+}
+
+/// Count guard clauses (see `ts_guard_clauses`): leading `if` / `unless`
+/// without `else` whose body is a single `raise`, in a clause body and in
+/// each `for` directly inside it
+fn elixir_guard_clauses(statements: &[tree_sitter::Node], source: &str) -> usize {
+    use crate::language::elixir::{call_name, do_statements};
+
+    let is_guard = |stmt: &tree_sitter::Node| {
+        matches!(call_name(*stmt, source), Some("if" | "unless"))
+            && !elixir_has_else(*stmt, source)
+            && matches!(
+                do_statements(*stmt, source).as_slice(),
+                [only] if elixir_is_exit(*only, source)
+            )
+    };
+    let leading = |stmts: &[tree_sitter::Node]| {
+        let mut count = 0;
+        for stmt in stmts {
+            if is_guard(stmt) {
+                count += 1;
+            } else if call_name(*stmt, source)
+                .is_some_and(|name| ELIXIR_NESTING_CALLS.contains(&name))
+            {
+                break;
+            }
+        }
+        count
+    };
+
+    let loop_guards: usize = statements
+        .iter()
+        .filter(|stmt| call_name(**stmt, source) == Some("for"))
+        .map(|stmt| leading(&do_statements(*stmt, source)))
+        .sum();
+    leading(statements) + loop_guards
+}
+
+/// Calculate arrow depth (see `ts_arrow_depth`) of one clause body
+fn elixir_arrow_depth(statements: &[tree_sitter::Node], source: &str) -> usize {
+    use crate::language::elixir::{call_name, do_statements};
+
+    let last = statements.len().saturating_sub(1);
+    let mut construct = None;
+    for (i, stmt) in statements.iter().enumerate() {
+        if call_name(*stmt, source).is_some_and(|name| ELIXIR_NESTING_CALLS.contains(&name)) {
+            if construct.is_some() {
+                return 0;
+            }
+            construct = Some(*stmt);
+        } else if elixir_is_exit(*stmt, source) && i != last {
+            return 0;
+        }
+    }
+    match construct {
+        Some(c)
+            if call_name(c, source).is_some_and(|name| ELIXIR_CHAIN_CALLS.contains(&name))
+                && !elixir_has_else(c, source) =>
+        {
+            1 + elixir_arrow_depth(&do_statements(c, source), source)
+        }
+        _ => 0,
+    }
+}
+
+/// Extract callee names from the bodies of an Elixir function's clauses:
+/// local (`validate`) and remote (`Repo.insert`, `conn.halt()`) calls,
+/// anonymous function calls (`callback.(x)`), and each stage of a
+/// pipe chain, including a bare function name (`|> normalize`) which is a
+/// call without parentheses. Definitions, control flow, and module
+/// directives are not calls.
+fn elixir_extract_callees(bodies: &[Vec<tree_sitter::Node>], source: &str) -> Vec<String> {
+    use crate::language::elixir::{call_arguments, call_name};
+
+    fn collect(
+        node: tree_sitter::Node,
+        source: &str,
+        calls: &mut std::collections::BTreeSet<String>,
+    ) {
+        let text = |n: tree_sitter::Node| source[n.start_byte()..n.end_byte()].trim();
+        let callee = match node.kind() {
+            "call" => match call_name(node, source) {
+                Some(name) if ELIXIR_SPECIAL_FORMS.contains(&name) => None,
+                Some(name) => Some(name),
+                // `user.name` without parentheses reads a field; a module's
+                // function (`String.trim`) is a call either way
+                None => node
+                    .child_by_field_name("target")
+                    .filter(|target| {
+                        target.kind() == "dot"
+                            && (call_arguments(node).is_some()
+                                || target
+                                    .child_by_field_name("left")
+                                    .is_some_and(|left| left.kind() == "alias"))
+                    })
+                    .map(|target| text(target).trim_end_matches('.').trim()),
+            },
+            "binary_operator" if elixir_binary_operator(node, source) == Some("|>") => node
+                .child_by_field_name("right")
+                .filter(|right| right.kind() == "identifier")
+                .map(text),
+            _ => None,
+        };
+        if let Some(callee) = callee.filter(|callee| !callee.is_empty()) {
+            calls.insert(callee.to_string());
+        }
+        for child in elixir_children(node, source) {
+            collect(child, source, calls);
+        }
+    }
+
+    let mut calls = std::collections::BTreeSet::new();
+    for statement in bodies.iter().flatten() {
+        collect(*statement, source, &mut calls);
+    }
+    calls.into_iter().collect()
+}
 
 // ============================================================================
-// Shared Tree-Sitter Metric Utilities
-//
-// Generic tree-sitter traversals parameterised by language-specific node-kind
-// sets. Per-language code is reduced to providing the right kind lists.
+// Lua Metrics Implementation
 // ============================================================================
 
-/// Find the first immediate child of `node` whose kind matches `kind`.
-fn ts_find_child_by_kind<'a>(
-    node: tree_sitter::Node<'a>,
-    kind: &str,
-) -> Option<tree_sitter::Node<'a>> {
+/// Control statements that count toward ND
+const LUA_NESTING_KINDS: &[&str] = &[
+    "if_statement",
+    "for_statement",
+    "while_statement",
+    "repeat_statement",
+];
+
+/// Statements that are CFG decision points
+const LUA_DECISION_KINDS: &[(&str, DecisionKind)] = &[
+    ("if_statement", DecisionKind::If),
+    ("elseif_statement", DecisionKind::If),
+    ("for_statement", DecisionKind::Loop),
+    ("while_statement", DecisionKind::Loop),
+    ("repeat_statement", DecisionKind::Loop),
+];
+
+/// `and` / `or` operator tokens (`binary_expression` nodes)
+const LUA_LOGICAL_OPERATORS: &[(&str, DecisionKind)] =
+    &[("and", DecisionKind::And), ("or", DecisionKind::Or)];
+
+/// Construct family of a Lua nesting kind; `repeat ... until` is a
+/// `while` with the test at the end
+fn lua_nesting_construct(kind: &str) -> Option<NestingConstruct> {
+    match kind {
+        "if_statement" => Some(NestingConstruct::If),
+        "for_statement" => Some(NestingConstruct::For),
+        "while_statement" | "repeat_statement" => Some(NestingConstruct::While),
+        _ => None,
+    }
+}
+
+/// Extract metrics for Lua functions using tree-sitter, over the function's
+/// own body (functions defined inside it are measured on their own)
+fn extract_lua_metrics(function: &FunctionNode, cfg: &Cfg, nd_counts: NdCounts) -> RawMetrics {
+    use crate::language::lua::{block_statements, find_function};
+    use crate::language::tree_sitter_utils::with_cached_lua_tree;
+
+    let (_body_node_id, source) = function.body.as_lua();
+    with_cached_lua_tree(source, |root| {
+        let func_node = find_function(root, function.span.start)?;
+        let statements = block_statements(func_node.child_by_field_name("body"));
+        let callee_names = lua_extract_callees(&func_node, source);
+        let (nd, nd_position) = lua_nesting_depth(&func_node, nd_counts);
+        let ns_breakdown = lua_non_structured_exits(&func_node, &statements, source);
+        let cc_tally = lua_cc_breakdown(&func_node);
+        Some(RawMetrics {
+            cc: calculate_cc_from_cfg(cfg) + lua_count_cc_extras(&func_node),
+            cognitive: lua_cognitive_complexity(&func_node),
+            nd,
+            nd_position,
+            fo: callee_names.len(),
+            ns: ns_breakdown.total(),
+            ns_breakdown,
+            loc: calculate_loc_from_node(&func_node),
+            callee_names,
+            arrow_depth: lua_arrow_depth(&statements, source),
+            signature_complexity: 0,
+            guard_clauses: lua_guard_clauses(&statements, source),
+            max_condition_ops: lua_max_condition_ops(&func_node),
+            cc_breakdown: Some(cc_tally.breakdown),
+            decisions: cc_tally.decisions,
+            switches: vec![],
+            await_in_loop: 0,
+        })
+    })
+    .unwrap_or(RawMetrics {
+        cc: 1,
+        cognitive: 0,
+        nd: 0,
+        nd_position: None,
+        fo: 0,
+        ns: 0,
+        ns_breakdown: NsBreakdown::default(),
+        loc: 0,
+        callee_names: vec![],
+        arrow_depth: 0,
+        signature_complexity: 0,
+        guard_clauses: 0,
+        max_condition_ops: 0,
+        cc_breakdown: None,
+        decisions: vec![],
+        switches: vec![],
+        await_in_loop: 0,
+    })
+}
+
+/// Children of a node to measure: everything but nested function
+/// definitions
+fn lua_children(node: tree_sitter::Node) -> Vec<tree_sitter::Node> {
+    use crate::language::lua::is_nested_definition;
+
     let mut cursor = node.walk();
-    let result = node
+    let children = node
         .children(&mut cursor)
-        .find(|child| child.kind() == kind);
-    result
+        .filter(|child| !is_nested_definition(*child))
+        .collect();
+    children
 }
 
-/// Find the first descendant that belongs to `func_kinds` at `start_byte`.
-fn ts_find_function_by_start<'a>(
-    root: tree_sitter::Node<'a>,
-    start_byte: usize,
-    func_kinds: &[&str],
-) -> Option<tree_sitter::Node<'a>> {
-    if func_kinds.contains(&root.kind()) && root.start_byte() == start_byte {
-        return Some(root);
+/// The decision an `and` / `or` expression is
+fn lua_logical_operator(node: tree_sitter::Node) -> Option<DecisionKind> {
+    ts_decision_kind(node, &[], LUA_LOGICAL_OPERATORS)
+}
+
+/// Visit every CC decision point of a Lua function, with the node it starts
+/// at and whether the CFG already counts it: statements are in the CFG,
+/// `and` / `or` are not
+fn lua_visit_decisions(
+    func_node: &tree_sitter::Node,
+    visit: &mut dyn FnMut(DecisionKind, tree_sitter::Node, bool),
+) {
+    fn recurse(
+        node: tree_sitter::Node,
+        visit: &mut dyn FnMut(DecisionKind, tree_sitter::Node, bool),
+    ) {
+        if let Some(kind) = ts_decision_kind(node, LUA_DECISION_KINDS, &[]) {
+            visit(kind, node, true);
+        } else if let Some(kind) = lua_logical_operator(node) {
+            visit(kind, node, false);
+        }
+        for child in lua_children(node) {
+            recurse(child, visit);
+        }
+    }
+    for child in lua_children(*func_node) {
+        recurse(child, visit);
+    }
+}
+
+/// Count additional CC contributors in Lua: `and` and `or`
+fn lua_count_cc_extras(func_node: &tree_sitter::Node) -> usize {
+    let mut count = 0;
+    lua_visit_decisions(func_node, &mut |_, _, in_cfg| {
+        if !in_cfg {
+            count += 1;
+        }
+    });
+    count
+}
+
+/// Tally CC decision points (see `ts_cc_breakdown`): an `elseif` is an `if`
+fn lua_cc_breakdown(func_node: &tree_sitter::Node) -> CcTally {
+    let mut tally = CcTally::default();
+    lua_visit_decisions(func_node, &mut |kind, node, _| tally.add_node(kind, node));
+    tally
+}
+
+/// Decision points of a Lua chunk outside every function: the walk skips
+/// nested definitions, so from the root it sees main-chunk code only
+pub(crate) fn lua_top_level_decisions(root: &tree_sitter::Node) -> usize {
+    lua_cc_breakdown(root).decisions.len()
+}
+
+/// Maximum nesting depth of control statements (see `ts_nesting_depth_by`)
+fn lua_nesting_depth(
+    func_node: &tree_sitter::Node,
+    nd_counts: NdCounts,
+) -> (usize, Option<NestPosition>) {
+    fn recurse(
+        node: tree_sitter::Node,
+        nd_counts: NdCounts,
+        current: usize,
+        max: &mut usize,
+        line: &mut usize,
+    ) {
+        let nests = LUA_NESTING_KINDS.contains(&node.kind())
+            && lua_nesting_construct(node.kind()).map_or(true, |c| nd_counts.counts(c));
+        let next = if nests {
+            let d = current + 1;
+            if d > *max {
+                *max = d;
+                *line = node.start_position().row + 1;
+            }
+            d
+        } else {
+            current
+        };
+        for child in lua_children(node) {
+            recurse(child, nd_counts, next, max, line);
+        }
+    }
+
+    let mut max_depth = 0;
+    let mut line = 0;
+    for child in lua_children(*func_node) {
+        recurse(child, nd_counts, 0, &mut max_depth, &mut line);
+    }
+    let position = (max_depth > 0).then_some(NestPosition::Line(line as u32));
+    (max_depth, position)
+}
+
+/// The exit a statement is: `return`, `break`, `goto`, or a call to
+/// `error`, which raises
+fn lua_exit_kind(node: tree_sitter::Node, source: &str) -> Option<ExitKind> {
+    match node.kind() {
+        "return_statement" => Some(ExitKind::Return),
+        "break_statement" => Some(ExitKind::Break),
+        "goto_statement" => Some(ExitKind::Goto),
+        _ if crate::language::lua::is_error_call(node, source) => Some(ExitKind::Throw),
+        _ => None,
+    }
+}
+
+/// Count non-structured exits. The `return` ending the function body is
+/// structured and does not count.
+fn lua_non_structured_exits(
+    func_node: &tree_sitter::Node,
+    statements: &[tree_sitter::Node],
+    source: &str,
+) -> NsBreakdown {
+    fn recurse(node: tree_sitter::Node, source: &str, breakdown: &mut NsBreakdown) {
+        if let Some(kind) = lua_exit_kind(node, source) {
+            breakdown.add(kind);
+        }
+        for child in lua_children(node) {
+            recurse(child, source, breakdown);
+        }
+    }
+    let mut breakdown = NsBreakdown::default();
+    for child in lua_children(*func_node) {
+        recurse(child, source, &mut breakdown);
+    }
+    if statements
+        .last()
+        .is_some_and(|last| last.kind() == "return_statement")
+    {
+        breakdown.remove(ExitKind::Return);
+    }
+    breakdown
+}
+
+/// Largest number of `and` / `or` operators in one boolean expression (see
+/// `ts_max_condition_ops`)
+fn lua_max_condition_ops(func_node: &tree_sitter::Node) -> usize {
+    fn count(node: tree_sitter::Node) -> usize {
+        let own = usize::from(lua_logical_operator(node).is_some());
+        let nested: usize = lua_children(node).into_iter().map(count).sum();
+        own + nested
+    }
+    fn recurse(node: tree_sitter::Node, max: &mut usize) {
+        if lua_logical_operator(node).is_some() {
+            // The outermost expression's count covers every operator below it
+            *max = (*max).max(count(node));
+            return;
+        }
+        for child in lua_children(node) {
+            recurse(child, max);
+        }
+    }
+    let mut max = 0;
+    for child in lua_children(*func_node) {
+        recurse(child, &mut max);
+    }
+    max
+}
+
+/// Calculate cognitive complexity (see `ts_cognitive_complexity`). `if` and
+/// loops cost 1 plus the nesting level, each `elseif` and `else` costs 1, and
+/// so does `goto`.
+fn lua_cognitive_complexity(func_node: &tree_sitter::Node) -> usize {
+    fn recurse(
+        node: tree_sitter::Node,
+        nesting: usize,
+        logical_parent: Option<DecisionKind>,
+        total: &mut usize,
+    ) {
+        let kind = node.kind();
+        if kind == "if_statement" {
+            if_chain(node, nesting, total);
+            return;
+        }
+        let mut inner = nesting;
+        let mut operator = None;
+        if matches!(
+            kind,
+            "for_statement" | "while_statement" | "repeat_statement"
+        ) {
+            *total += 1 + nesting;
+            inner += 1;
+        } else if kind == "goto_statement" {
+            *total += 1;
+        } else if let Some(op) = lua_logical_operator(node) {
+            operator = Some(op);
+            if operator != logical_parent {
+                *total += 1;
+            }
+        } else if kind == "parenthesized_expression" {
+            // Parentheses do not end an operator sequence
+            operator = logical_parent;
+        }
+        for child in lua_children(node) {
+            recurse(child, inner, operator, total);
+        }
+    }
+
+    /// An `if` and its `elseif` / `else` clauses
+    fn if_chain(node: tree_sitter::Node, nesting: usize, total: &mut usize) {
+        *total += 1 + nesting;
+        let mut cursor = node.walk();
+        for (i, child) in node.children(&mut cursor).enumerate() {
+            match node.field_name_for_child(i as u32) {
+                Some("consequence") => recurse(child, nesting + 1, None, total),
+                Some("alternative") => {
+                    *total += 1;
+                    let mut cursor = child.walk();
+                    for (j, part) in child.children(&mut cursor).enumerate() {
+                        let depth = match child.field_name_for_child(j as u32) {
+                            Some("consequence" | "body") => nesting + 1,
+                            _ => nesting,
+                        };
+                        recurse(part, depth, None, total);
+                    }
+                }
+                _ => recurse(child, nesting, None, total),
+            }
+        }
+    }
+
+    let mut total = 0;
+    for child in lua_children(*func_node) {
+        recurse(child, 0, None, &mut total);
+    }
+    total
+}
+
+/// Statements of the branch an `if` or loop runs: its `then` branch or body
+fn lua_inner_statements(construct: tree_sitter::Node) -> Vec<tree_sitter::Node> {
+    crate::language::lua::block_statements(
+        construct
+            .child_by_field_name("consequence")
+            .or_else(|| construct.child_by_field_name("body")),
+    )
+}
+
+/// Whether an `if` statement has an `elseif` or `else`
+fn lua_has_else(construct: tree_sitter::Node) -> bool {
+    construct.kind() == "if_statement" && construct.child_by_field_name("alternative").is_some()
+}
+
+/// Count guard clauses (see `ts_guard_clauses`): leading `if`s without
+/// `else` whose branch is a single exit, in the body and in each loop
+/// directly inside it
+fn lua_guard_clauses(statements: &[tree_sitter::Node], source: &str) -> usize {
+    let is_guard = |stmt: &tree_sitter::Node| {
+        stmt.kind() == "if_statement"
+            && !lua_has_else(*stmt)
+            && matches!(
+                lua_inner_statements(*stmt).as_slice(),
+                [only] if lua_exit_kind(*only, source).is_some()
+            )
+    };
+    let leading = |stmts: &[tree_sitter::Node]| {
+        let mut count = 0;
+        for stmt in stmts {
+            if is_guard(stmt) {
+                count += 1;
+            } else if LUA_NESTING_KINDS.contains(&stmt.kind()) {
+                break;
+            }
+        }
+        count
+    };
+
+    let loop_guards: usize = statements
+        .iter()
+        .filter(|stmt| stmt.kind() != "if_statement" && LUA_NESTING_KINDS.contains(&stmt.kind()))
+        .map(|stmt| leading(&lua_inner_statements(*stmt)))
+        .sum();
+    leading(statements) + loop_guards
+}
+
+/// Calculate arrow depth (see `ts_arrow_depth`)
+fn lua_arrow_depth(statements: &[tree_sitter::Node], source: &str) -> usize {
+    let last = statements.len().saturating_sub(1);
+    let mut construct = None;
+    for (i, stmt) in statements.iter().enumerate() {
+        if LUA_NESTING_KINDS.contains(&stmt.kind()) {
+            if construct.is_some() {
+                return 0;
+            }
+            construct = Some(*stmt);
+        } else if lua_exit_kind(*stmt, source).is_some() && i != last {
+            return 0;
+        }
+    }
+    match construct {
+        Some(c) if !lua_has_else(c) => 1 + lua_arrow_depth(&lua_inner_statements(c), source),
+        _ => 0,
+    }
+}
+
+/// Extract callee names from a Lua function body: the expression each call
+/// calls (`print`, `string.format`, `self:save`)
+fn lua_extract_callees(func_node: &tree_sitter::Node, source: &str) -> Vec<String> {
+    fn collect(
+        node: tree_sitter::Node,
+        source: &str,
+        calls: &mut std::collections::BTreeSet<String>,
+    ) {
+        if node.kind() == "function_call" {
+            if let Some(name) = node.child_by_field_name("name") {
+                let callee = source[name.start_byte()..name.end_byte()].trim();
+                if !callee.is_empty() {
+                    calls.insert(callee.to_string());
+                }
+            }
+        }
+        for child in lua_children(node) {
+            collect(child, source, calls);
+        }
+    }
+
+    let mut calls = std::collections::BTreeSet::new();
+    for child in lua_children(*func_node) {
+        collect(child, source, &mut calls);
+    }
+    calls.into_iter().collect()
+}
+
+// ============================================================================
+// Bash Metrics Implementation
+// ============================================================================
+
+/// Control statements that count toward ND
+const BASH_NESTING_KINDS: &[&str] = &[
+    "if_statement",
+    "while_statement",
+    "for_statement",
+    "c_style_for_statement",
+    "case_statement",
+];
+
+/// Statements that are CFG decision points; a `case` clause is one unless it
+/// is the catch-all `*)`
+const BASH_DECISION_KINDS: &[(&str, DecisionKind)] = &[
+    ("if_statement", DecisionKind::If),
+    ("elif_clause", DecisionKind::If),
+    ("while_statement", DecisionKind::Loop),
+    ("for_statement", DecisionKind::Loop),
+    ("c_style_for_statement", DecisionKind::Loop),
+];
+
+/// `&&` / `||` between commands (`list` nodes) and inside `[[ ... ]]`
+/// (`binary_expression` nodes)
+const BASH_LOGICAL_OPERATORS: &[(&str, DecisionKind)] =
+    &[("&&", DecisionKind::And), ("||", DecisionKind::Or)];
+
+/// Construct family of a Bash nesting kind; `until` is a `while` and
+/// `select` a `for`
+fn bash_nesting_construct(kind: &str) -> Option<NestingConstruct> {
+    match kind {
+        "if_statement" => Some(NestingConstruct::If),
+        "for_statement" | "c_style_for_statement" => Some(NestingConstruct::For),
+        "while_statement" => Some(NestingConstruct::While),
+        "case_statement" => Some(NestingConstruct::Switch),
+        _ => None,
+    }
+}
+
+/// Extract metrics for Bash functions using tree-sitter, over the function's
+/// own body (functions defined inside it are measured on their own)
+fn extract_bash_metrics(function: &FunctionNode, cfg: &Cfg, nd_counts: NdCounts) -> RawMetrics {
+    use crate::language::bash::{body_statements, find_function};
+    use crate::language::tree_sitter_utils::with_cached_bash_tree;
+
+    let (_body_node_id, source) = function.body.as_bash();
+    with_cached_bash_tree(source, |root| {
+        let func_node = find_function(root, function.span.start)?;
+        let statements = body_statements(func_node);
+        let callee_names = bash_extract_callees(&func_node, source);
+        let (nd, nd_position) = bash_nesting_depth(&func_node, nd_counts);
+        let ns_breakdown = bash_non_structured_exits(&func_node, &statements, source);
+        let cc_tally = bash_cc_breakdown(&func_node, source);
+        Some(RawMetrics {
+            cc: calculate_cc_from_cfg(cfg) + bash_count_cc_extras(&func_node, source),
+            cognitive: bash_cognitive_complexity(&func_node),
+            nd,
+            nd_position,
+            fo: callee_names.len(),
+            ns: ns_breakdown.total(),
+            ns_breakdown,
+            loc: calculate_loc_from_node(&func_node),
+            callee_names,
+            arrow_depth: bash_arrow_depth(&statements, source),
+            signature_complexity: 0,
+            guard_clauses: bash_guard_clauses(&statements, source),
+            max_condition_ops: bash_max_condition_ops(&func_node),
+            cc_breakdown: Some(cc_tally.breakdown),
+            decisions: cc_tally.decisions,
+            switches: vec![],
+            await_in_loop: 0,
+        })
+    })
+    .unwrap_or(RawMetrics {
+        cc: 1,
+        cognitive: 0,
+        nd: 0,
+        nd_position: None,
+        fo: 0,
+        ns: 0,
+        ns_breakdown: NsBreakdown::default(),
+        loc: 0,
+        callee_names: vec![],
+        arrow_depth: 0,
+        signature_complexity: 0,
+        guard_clauses: 0,
+        max_condition_ops: 0,
+        cc_breakdown: None,
+        decisions: vec![],
+        switches: vec![],
+        await_in_loop: 0,
+    })
+}
+
+/// Children of a node to measure: everything but nested function
+/// definitions
+fn bash_children(node: tree_sitter::Node) -> Vec<tree_sitter::Node> {
+    use crate::language::bash::is_nested_definition;
+
+    let mut cursor = node.walk();
+    let children = node
+        .children(&mut cursor)
+        .filter(|child| !is_nested_definition(*child))
+        .collect();
+    children
+}
+
+/// The decision an `&&` / `||` list or test expression is
+fn bash_logical_operator(node: tree_sitter::Node) -> Option<DecisionKind> {
+    if node.kind() != "list" && node.kind() != "binary_expression" {
+        return None;
     }
-    let mut cursor = root.walk();
-    for child in root.children(&mut cursor) {
-        if let Some(found) = ts_find_function_by_start(child, start_byte, func_kinds) {
-            return Some(found);
+    // The operator is a direct child; nested operands are separate nodes
+    let mut cursor = node.walk();
+    let operator = node.children(&mut cursor).find_map(|child| {
+        BASH_LOGICAL_OPERATORS
+            .iter()
+            .find(|(op, _)| *op == child.kind())
+            .map(|&(_, kind)| kind)
+    });
+    operator
+}
+
+/// Visit every CC decision point of a Bash function, with the node it starts
+/// at and whether the CFG already counts it. Statements are in the CFG
+/// unless they sit where it does not look, such as behind `&&` or inside
+/// `$( ... )`; `&&` and `||` never are.
+fn bash_visit_decisions(
+    func_node: &tree_sitter::Node,
+    source: &str,
+    visit: &mut dyn FnMut(DecisionKind, tree_sitter::Node, bool),
+) {
+    use crate::language::bash::{cfg_children, is_catch_all};
+
+    fn recurse(
+        node: tree_sitter::Node,
+        in_cfg: bool,
+        source: &str,
+        visit: &mut dyn FnMut(DecisionKind, tree_sitter::Node, bool),
+    ) {
+        if let Some(kind) = ts_decision_kind(node, BASH_DECISION_KINDS, &[]) {
+            visit(kind, node, in_cfg);
+        } else if node.kind() == "case_item" && !is_catch_all(node, source) {
+            visit(DecisionKind::Case, node, in_cfg);
+        } else if let Some(kind) = bash_logical_operator(node) {
+            visit(kind, node, false);
+        }
+        let visited: Vec<usize> = cfg_children(node).iter().map(|n| n.id()).collect();
+        for child in bash_children(node) {
+            recurse(
+                child,
+                in_cfg && visited.contains(&child.id()),
+                source,
+                visit,
+            );
         }
     }
-    None
+    recurse(*func_node, true, source, visit);
 }
 
-/// Calculate maximum nesting depth for the given control-structure node kinds.
-fn ts_nesting_depth(body_node: &tree_sitter::Node, nesting_kinds: &[&str]) -> usize {
-    fn recurse(node: tree_sitter::Node, kinds: &[&str], current: usize, max: &mut usize) {
-        let next = if kinds.contains(&node.kind()) {
+/// Count additional CC contributors in Bash: `&&`, `||`, and control
+/// statements the CFG does not reach
+fn bash_count_cc_extras(func_node: &tree_sitter::Node, source: &str) -> usize {
+    let mut count = 0;
+    bash_visit_decisions(func_node, source, &mut |_, _, in_cfg| {
+        if !in_cfg {
+            count += 1;
+        }
+    });
+    count
+}
+
+/// Tally CC decision points (see `ts_cc_breakdown`): an `elif` is an `if`
+/// and each `case` clause but `*)` is a `case`
+fn bash_cc_breakdown(func_node: &tree_sitter::Node, source: &str) -> CcTally {
+    let mut tally = CcTally::default();
+    bash_visit_decisions(func_node, source, &mut |kind, node, _| {
+        tally.add_node(kind, node)
+    });
+    tally
+}
+
+/// Decision points of a Bash script outside every function (see
+/// `lua_top_level_decisions`)
+pub(crate) fn bash_top_level_decisions(root: &tree_sitter::Node, source: &str) -> usize {
+    bash_cc_breakdown(root, source).decisions.len()
+}
+
+/// Maximum nesting depth of control statements (see `ts_nesting_depth_by`)
+fn bash_nesting_depth(
+    func_node: &tree_sitter::Node,
+    nd_counts: NdCounts,
+) -> (usize, Option<NestPosition>) {
+    fn recurse(
+        node: tree_sitter::Node,
+        nd_counts: NdCounts,
+        current: usize,
+        max: &mut usize,
+        line: &mut usize,
+    ) {
+        let nests = BASH_NESTING_KINDS.contains(&node.kind())
+            && bash_nesting_construct(node.kind()).map_or(true, |c| nd_counts.counts(c));
+        let next = if nests {
             let d = current + 1;
             if d > *max {
                 *max = d;
+                *line = node.start_position().row + 1;
             }
             d
         } else {
             current
         };
-        let mut cursor = node.walk();
-        for child in node.children(&mut cursor) {
-            recurse(child, kinds, next, max);
+        for child in bash_children(node) {
+            recurse(child, nd_counts, next, max, line);
         }
     }
+
     let mut max_depth = 0;
-    recurse(*body_node, nesting_kinds, 0, &mut max_depth);
-    max_depth
+    let mut line = 0;
+    for child in bash_children(*func_node) {
+        recurse(child, nd_counts, 0, &mut max_depth, &mut line);
+    }
+    let position = (max_depth > 0).then_some(NestPosition::Line(line as u32));
+    (max_depth, position)
 }
 
-/// Count exits whose node kind appears in `exit_kinds`.
-fn ts_non_structured_exits(body_node: &tree_sitter::Node, exit_kinds: &[&str]) -> usize {
-    fn recurse(node: tree_sitter::Node, kinds: &[&str], count: &mut usize) {
-        if kinds.contains(&node.kind()) {
-            *count += 1;
+/// The exit a command is: `return`, `break`, `continue`, or `exit`, which
+/// ends the script
+fn bash_exit_kind(node: tree_sitter::Node, source: &str) -> Option<ExitKind> {
+    match crate::language::bash::command_name(node, source)? {
+        "return" => Some(ExitKind::Return),
+        "exit" => Some(ExitKind::Throw),
+        "break" => Some(ExitKind::Break),
+        "continue" => Some(ExitKind::Continue),
+        _ => None,
+    }
+}
+
+/// Count non-structured exits. The `return` ending the function body is
+/// structured and does not count, and neither is an exit inside a subshell,
+/// a pipeline, or `$( ... )`, which only ends that process.
+fn bash_non_structured_exits(
+    func_node: &tree_sitter::Node,
+    statements: &[tree_sitter::Node],
+    source: &str,
+) -> NsBreakdown {
+    use crate::language::bash::SUBSHELL_KINDS;
+
+    fn recurse(node: tree_sitter::Node, source: &str, breakdown: &mut NsBreakdown) {
+        if let Some(kind) = bash_exit_kind(node, source) {
+            breakdown.add(kind);
         }
-        let mut cursor = node.walk();
-        for child in node.children(&mut cursor) {
-            recurse(child, kinds, count);
+        for child in bash_children(node) {
+            if !SUBSHELL_KINDS.contains(&child.kind()) {
+                recurse(child, source, breakdown);
+            }
         }
     }
-    let mut count = 0;
-    recurse(*body_node, exit_kinds, &mut count);
-    count
+    let mut breakdown = NsBreakdown::default();
+    for child in bash_children(*func_node) {
+        recurse(child, source, &mut breakdown);
+    }
+    if statements
+        .last()
+        .is_some_and(|last| bash_exit_kind(*last, source) == Some(ExitKind::Return))
+    {
+        breakdown.remove(ExitKind::Return);
+    }
+    breakdown
 }
 
-/// Parse `source` with `language`, locate the function starting at `start_byte`,
-/// find the first matching body child, and call `f(func_node, body_node)`.
-/// Returns `None` if the function or body cannot be found.
-fn ts_with_function_body<R>(
-    source: &str,
-    language: tree_sitter::Language,
-    start_byte: usize,
-    func_kinds: &[&str],
-    body_kinds: &[&str],
-    f: impl FnOnce(tree_sitter::Node, tree_sitter::Node) -> R,
-) -> Option<R> {
-    let mut parser = tree_sitter::Parser::new();
-    parser.set_language(&language).ok()?;
-    let tree = parser.parse(source, None)?;
-    let root = tree.root_node();
-    let func_node = ts_find_function_by_start(root, start_byte, func_kinds)?;
-    for kind in body_kinds {
-        if let Some(body_node) = ts_find_child_by_kind(func_node, kind) {
-            return Some(f(func_node, body_node));
+/// Largest number of `&&` / `||` operators in one command list or test
+/// (see `ts_max_condition_ops`)
+fn bash_max_condition_ops(func_node: &tree_sitter::Node) -> usize {
+    fn count(node: tree_sitter::Node) -> usize {
+        let own = usize::from(bash_logical_operator(node).is_some());
+        let nested: usize = bash_children(node).into_iter().map(count).sum();
+        own + nested
+    }
+    fn recurse(node: tree_sitter::Node, max: &mut usize) {
+        if bash_logical_operator(node).is_some() {
+            // The outermost expression's count covers every operator below it
+            *max = (*max).max(count(node));
+            return;
+        }
+        for child in bash_children(node) {
+            recurse(child, max);
         }
     }
-    None
+    let mut max = 0;
+    for child in bash_children(*func_node) {
+        recurse(child, &mut max);
+    }
+    max
+}
+
+/// Calculate cognitive complexity (see `ts_cognitive_complexity`). `if`,
+/// loops, and `case` cost 1 plus the nesting level, and each `elif` and
+/// `else` costs 1.
+fn bash_cognitive_complexity(func_node: &tree_sitter::Node) -> usize {
+    fn recurse(
+        node: tree_sitter::Node,
+        nesting: usize,
+        logical_parent: Option<DecisionKind>,
+        total: &mut usize,
+    ) {
+        let kind = node.kind();
+        if kind == "if_statement" {
+            if_chain(node, nesting, total);
+            return;
+        }
+        let mut inner = nesting;
+        let mut operator = None;
+        if BASH_NESTING_KINDS.contains(&kind) {
+            *total += 1 + nesting;
+            inner += 1;
+        } else if let Some(op) = bash_logical_operator(node) {
+            operator = Some(op);
+            if operator != logical_parent {
+                *total += 1;
+            }
+        } else if kind == "parenthesized_expression" {
+            // Parentheses do not end an operator sequence
+            operator = logical_parent;
+        }
+        for child in bash_children(node) {
+            recurse(child, inner, operator, total);
+        }
+    }
+
+    /// An `if` or `elif` clause: its condition at `nesting`, its branch one
+    /// level deeper, and any further `elif` / `else` clauses
+    fn clause(node: tree_sitter::Node, nesting: usize, total: &mut usize) {
+        let mut after_then = node.kind() == "else_clause";
+        for child in bash_children(node) {
+            match child.kind() {
+                "then" => after_then = true,
+                "elif_clause" | "else_clause" => {
+                    *total += 1;
+                    clause(child, nesting, total);
+                }
+                _ => {
+                    let depth = if after_then { nesting + 1 } else { nesting };
+                    recurse(child, depth, None, total);
+                }
+            }
+        }
+    }
+
+    fn if_chain(node: tree_sitter::Node, nesting: usize, total: &mut usize) {
+        *total += 1 + nesting;
+        clause(node, nesting, total);
+    }
+
+    let mut total = 0;
+    for child in bash_children(*func_node) {
+        recurse(child, 0, None, &mut total);
+    }
+    total
+}
+
+/// Statements of the branch an `if` or loop runs: its `then` branch or body
+fn bash_inner_statements(construct: tree_sitter::Node) -> Vec<tree_sitter::Node> {
+    use crate::language::bash::{branch_statements, loop_body};
+
+    match construct.kind() {
+        "if_statement" => branch_statements(construct),
+        "case_statement" => Vec::new(),
+        _ => loop_body(construct),
+    }
+}
+
+/// Whether an `if` statement has an `elif` or `else`
+fn bash_has_else(construct: tree_sitter::Node) -> bool {
+    construct.kind() == "if_statement"
+        && !crate::language::bash::if_alternatives(construct).is_empty()
+}
+
+/// Count guard clauses (see `ts_guard_clauses`): leading `if`s without
+/// `else` whose branch is a single exit, in the body and in each loop
+/// directly inside it
+fn bash_guard_clauses(statements: &[tree_sitter::Node], source: &str) -> usize {
+    let is_guard = |stmt: &tree_sitter::Node| {
+        stmt.kind() == "if_statement"
+            && !bash_has_else(*stmt)
+            && matches!(
+                bash_inner_statements(*stmt).as_slice(),
+                [only] if bash_exit_kind(*only, source).is_some()
+            )
+    };
+    let leading = |stmts: &[tree_sitter::Node]| {
+        let mut count = 0;
+        for stmt in stmts {
+            if is_guard(stmt) {
+                count += 1;
+            } else if BASH_NESTING_KINDS.contains(&stmt.kind()) {
+                break;
+            }
+        }
+        count
+    };
+
+    let loop_guards: usize = statements
+        .iter()
+        .filter(|stmt| {
+            !matches!(stmt.kind(), "if_statement" | "case_statement")
+                && BASH_NESTING_KINDS.contains(&stmt.kind())
+        })
+        .map(|stmt| leading(&bash_inner_statements(*stmt)))
+        .sum();
+    leading(statements) + loop_guards
+}
+
+/// Calculate arrow depth (see `ts_arrow_depth`)
+fn bash_arrow_depth(statements: &[tree_sitter::Node], source: &str) -> usize {
+    let last = statements.len().saturating_sub(1);
+    let mut construct = None;
+    for (i, stmt) in statements.iter().enumerate() {
+        if BASH_NESTING_KINDS.contains(&stmt.kind()) {
+            if construct.is_some() {
+                return 0;
+            }
+            construct = Some(*stmt);
+        } else if bash_exit_kind(*stmt, source).is_some() && i != last {
+            return 0;
+        }
+    }
+    match construct {
+        Some(c) if !bash_has_else(c) => 1 + bash_arrow_depth(&bash_inner_statements(c), source),
+        _ => 0,
+    }
+}
+
+/// Extract callee names from a Bash function body: the command each simple
+/// command runs (`echo`, `git`, `"$editor"`), other than `return`, `exit`,
+/// `break`, and `continue`
+fn bash_extract_callees(func_node: &tree_sitter::Node, source: &str) -> Vec<String> {
+    use crate::language::bash::{command_name, EXIT_COMMANDS};
+
+    fn collect(
+        node: tree_sitter::Node,
+        source: &str,
+        calls: &mut std::collections::BTreeSet<String>,
+    ) {
+        if let Some(callee) = command_name(node, source) {
+            if !callee.is_empty() && !EXIT_COMMANDS.contains(&callee) {
+                calls.insert(callee.to_string());
+            }
+        }
+        for child in bash_children(node) {
+            collect(child, source, calls);
+        }
+    }
+
+    let mut calls = std::collections::BTreeSet::new();
+    for child in bash_children(*func_node) {
+        collect(child, source, &mut calls);
+    }
+    calls.into_iter().collect()
 }
 
 // ============================================================================
-// Go Metrics Implementation
+// Zig Metrics Implementation
 // ============================================================================
 
-/// Extract metrics for Go functions using tree-sitter
-fn extract_go_metrics(function: &FunctionNode, cfg: &Cfg) -> RawMetrics {
-    let (_body_node_id, source) = function.body.as_go();
-    ts_with_function_body(
-        source,
-        tree_sitter_go::LANGUAGE.into(),
-        function.span.start,
-        &["function_declaration", "method_declaration"],
-        &["block"],
-        |func_node, body_node| {
-            let callee_names = go_extract_callees(&body_node, source);
-            RawMetrics {
-                cc: calculate_cc_from_cfg(cfg) + go_count_cc_extras(&body_node, source),
-                nd: ts_nesting_depth(
-                    &body_node,
-                    &[
-                        "if_statement",
-                        "for_statement",
-                        "switch_statement",
-                        "expression_switch_statement",
-                        "type_switch_statement",
-                        "select_statement",
-                    ],
-                ),
-                fo: callee_names.len(),
-                ns: go_non_structured_exits(&body_node, source),
-                loc: calculate_loc_from_node(&func_node),
-                callee_names,
-            }
-        },
-    )
+/// Construct family of a Zig control keyword (see `zig::control_keyword`)
+fn zig_nesting_construct(keyword: &str) -> Option<NestingConstruct> {
+    match keyword {
+        "if" => Some(NestingConstruct::If),
+        "for" => Some(NestingConstruct::For),
+        "while" => Some(NestingConstruct::While),
+        "switch" => Some(NestingConstruct::Switch),
+        _ => None,
+    }
+}
+
+/// Extract metrics for Zig functions using tree-sitter, over the function's
+/// own body (functions of a container declared inside it are measured on
+/// their own)
+fn extract_zig_metrics(function: &FunctionNode, cfg: &Cfg, nd_counts: NdCounts) -> RawMetrics {
+    use crate::language::tree_sitter_utils::with_cached_zig_tree;
+    use crate::language::zig::{block_statements, find_function, function_body};
+
+    let (_body_node_id, source) = function.body.as_zig();
+    with_cached_zig_tree(source, |root| {
+        let func_node = find_function(root, function.span.start)?;
+        let statements = block_statements(function_body(func_node)?);
+        let callee_names = zig_extract_callees(&func_node, source);
+        let (nd, nd_position) = zig_nesting_depth(&func_node, nd_counts);
+        let ns_breakdown = zig_non_structured_exits(&func_node, &statements, source);
+        let cc_tally = zig_cc_breakdown(&func_node);
+        Some(RawMetrics {
+            cc: calculate_cc_from_cfg(cfg) + zig_count_cc_extras(&func_node),
+            cognitive: zig_cognitive_complexity(&func_node, source),
+            nd,
+            nd_position,
+            fo: callee_names.len(),
+            ns: ns_breakdown.total(),
+            ns_breakdown,
+            loc: calculate_loc_from_node(&func_node),
+            callee_names,
+            arrow_depth: zig_arrow_depth(&statements, source),
+            signature_complexity: 0,
+            guard_clauses: zig_guard_clauses(&statements, source),
+            max_condition_ops: zig_max_condition_ops(&func_node),
+            cc_breakdown: Some(cc_tally.breakdown),
+            decisions: cc_tally.decisions,
+            switches: vec![],
+            await_in_loop: 0,
+        })
+    })
     .unwrap_or(RawMetrics {
         cc: 1,
+        cognitive: 0,
         nd: 0,
+        nd_position: None,
         fo: 0,
         ns: 0,
+        ns_breakdown: NsBreakdown::default(),
         loc: 0,
         callee_names: vec![],
+        arrow_depth: 0,
+        signature_complexity: 0,
+        guard_clauses: 0,
+        max_condition_ops: 0,
+        cc_breakdown: None,
+        decisions: vec![],
+        switches: vec![],
+        await_in_loop: 0,
     })
 }
 
-/// Extract callee names from a Go function body (function calls + go statements).
-/// Returns the unique set of callee name strings (used for both fo metric and call graph).
-fn go_extract_callees(body_node: &tree_sitter::Node, source: &str) -> Vec<String> {
-    use std::collections::HashSet;
+/// Children of a node to measure: everything but function declarations
+fn zig_children(node: tree_sitter::Node) -> Vec<tree_sitter::Node> {
+    use crate::language::zig::is_nested_definition;
 
-    fn collect(node: tree_sitter::Node, source: &str, calls: &mut HashSet<String>) {
-        match node.kind() {
-            "call_expression" => {
-                if let Some(func_node) = ts_find_child_by_kind(node, "identifier")
-                    .or_else(|| ts_find_child_by_kind(node, "selector_expression"))
-                {
-                    let func_text = &source[func_node.start_byte()..func_node.end_byte()];
-                    calls.insert(func_text.to_string());
-                }
+    let mut cursor = node.walk();
+    let children = node
+        .children(&mut cursor)
+        .filter(|child| !is_nested_definition(*child))
+        .collect();
+    children
+}
+
+/// The decision an `and` / `or` expression is
+fn zig_logical_operator(node: tree_sitter::Node) -> Option<DecisionKind> {
+    // The operator is a direct child; nested operands are separate nodes
+    let mut cursor = node.walk();
+    let operator = node
+        .children(&mut cursor)
+        .find_map(|child| match child.kind() {
+            "and" => Some(DecisionKind::And),
+            "or" => Some(DecisionKind::Or),
+            _ => None,
+        });
+    operator
+}
+
+/// Visit every CC decision point of a Zig function, with the node it starts
+/// at and whether the CFG already counts it. `if`, loops, and `switch`
+/// prongs other than `else` are in the CFG unless they are used as a value
+/// (`const x = if (a) b else c;`); the `and`, `or`, `orelse`, `catch`, and
+/// `try` operators never are.
+fn zig_visit_decisions(
+    func_node: &tree_sitter::Node,
+    visit: &mut dyn FnMut(DecisionKind, tree_sitter::Node, bool),
+) {
+    use crate::language::zig::{cfg_children, control_keyword, is_else_prong, is_prong};
+
+    fn recurse(
+        node: tree_sitter::Node,
+        in_cfg: bool,
+        visit: &mut dyn FnMut(DecisionKind, tree_sitter::Node, bool),
+    ) {
+        match control_keyword(node) {
+            Some("if") => visit(DecisionKind::If, node, in_cfg),
+            Some("for") | Some("while") => visit(DecisionKind::Loop, node, in_cfg),
+            Some(_) => {}
+            None if is_prong(node) && !is_else_prong(node) => {
+                visit(DecisionKind::Case, node, in_cfg)
             }
-            "go_statement" => {
-                // Go statements spawn goroutines, count as fan-out
-                calls.insert(format!("<go@{}>", node.start_byte()));
+            None => match node.kind() {
+                "and" => visit(DecisionKind::And, node, false),
+                "or" => visit(DecisionKind::Or, node, false),
+                "orelse" => visit(DecisionKind::Coalesce, node, false),
+                "catch" | "try" => visit(DecisionKind::Catch, node, false),
+                _ => {}
+            },
+        }
+        let visited: Vec<usize> = cfg_children(node).iter().map(|n| n.id()).collect();
+        for child in zig_children(node) {
+            recurse(child, in_cfg && visited.contains(&child.id()), visit);
+        }
+    }
+    recurse(*func_node, true, visit);
+}
+
+/// Count additional CC contributors in Zig: `and`, `or`, `orelse`, `catch`,
+/// `try`, and control expressions the CFG does not reach
+fn zig_count_cc_extras(func_node: &tree_sitter::Node) -> usize {
+    let mut count = 0;
+    zig_visit_decisions(func_node, &mut |_, _, in_cfg| {
+        if !in_cfg {
+            count += 1;
+        }
+    });
+    count
+}
+
+/// Tally CC decision points (see `ts_cc_breakdown`): each `switch` prong but
+/// `else` is a `case`, `orelse` a coalesce, and `try` a `catch`
+fn zig_cc_breakdown(func_node: &tree_sitter::Node) -> CcTally {
+    let mut tally = CcTally::default();
+    zig_visit_decisions(func_node, &mut |kind, node, _| tally.add_node(kind, node));
+    tally
+}
+
+/// Maximum nesting depth of control constructs (see `ts_nesting_depth_by`);
+/// an `else if` is at the depth of the `if` it continues
+fn zig_nesting_depth(
+    func_node: &tree_sitter::Node,
+    nd_counts: NdCounts,
+) -> (usize, Option<NestPosition>) {
+    use crate::language::zig::{control_keyword, is_else_if};
+
+    fn recurse(
+        node: tree_sitter::Node,
+        nd_counts: NdCounts,
+        current: usize,
+        max: &mut usize,
+        line: &mut usize,
+    ) {
+        let nests = control_keyword(node)
+            .and_then(zig_nesting_construct)
+            .is_some_and(|c| nd_counts.counts(c))
+            && !is_else_if(node);
+        let next = if nests {
+            let d = current + 1;
+            if d > *max {
+                *max = d;
+                *line = node.start_position().row + 1;
             }
+            d
+        } else {
+            current
+        };
+        for child in zig_children(node) {
+            recurse(child, nd_counts, next, max, line);
+        }
+    }
+
+    let mut max_depth = 0;
+    let mut line = 0;
+    for child in zig_children(*func_node) {
+        recurse(child, nd_counts, 0, &mut max_depth, &mut line);
+    }
+    let position = (max_depth > 0).then_some(NestPosition::Line(line as u32));
+    (max_depth, position)
+}
+
+/// The exit a statement is: `return`, `break`, `continue`, or `@panic(...)`
+/// and `unreachable`, which end the program
+fn zig_exit_kind(stmt: tree_sitter::Node, source: &str) -> Option<ExitKind> {
+    use crate::language::zig::{is_panic, jump_keyword, unwrap_statement};
+
+    match jump_keyword(unwrap_statement(stmt)) {
+        Some("return") => Some(ExitKind::Return),
+        Some("break") => Some(ExitKind::Break),
+        Some("continue") => Some(ExitKind::Continue),
+        _ => is_panic(stmt, source).then_some(ExitKind::Throw),
+    }
+}
+
+/// Count non-structured exits. `try` returns the error early, like Rust's
+/// `?`. The `return` ending the function body is structured and does not
+/// count.
+fn zig_non_structured_exits(
+    func_node: &tree_sitter::Node,
+    statements: &[tree_sitter::Node],
+    source: &str,
+) -> NsBreakdown {
+    use crate::language::zig::{is_panic, jump_keyword};
+
+    fn recurse(node: tree_sitter::Node, source: &str, breakdown: &mut NsBreakdown) {
+        if is_panic(node, source) {
+            breakdown.add(ExitKind::Throw);
+            return;
+        }
+        match (jump_keyword(node), node.kind()) {
+            (Some("return"), _) | (None, "try") => breakdown.add(ExitKind::Return),
+            (Some("break"), _) => breakdown.add(ExitKind::Break),
+            (Some("continue"), _) => breakdown.add(ExitKind::Continue),
             _ => {}
         }
-        let mut cursor = node.walk();
-        for child in node.children(&mut cursor) {
-            collect(child, source, calls);
+        for child in zig_children(node) {
+            recurse(child, source, breakdown);
         }
     }
+    let mut breakdown = NsBreakdown::default();
+    for child in zig_children(*func_node) {
+        recurse(child, source, &mut breakdown);
+    }
+    if statements
+        .last()
+        .is_some_and(|last| zig_exit_kind(*last, source) == Some(ExitKind::Return))
+    {
+        breakdown.remove(ExitKind::Return);
+    }
+    breakdown
+}
 
-    let mut calls = HashSet::new();
-    collect(*body_node, source, &mut calls);
-    let mut result: Vec<String> = calls.into_iter().collect();
-    result.sort();
-    result
+/// Largest number of `and` / `or` operators in one condition (see
+/// `ts_max_condition_ops`)
+fn zig_max_condition_ops(func_node: &tree_sitter::Node) -> usize {
+    fn count(node: tree_sitter::Node) -> usize {
+        let own = usize::from(zig_logical_operator(node).is_some());
+        let nested: usize = zig_children(node).into_iter().map(count).sum();
+        own + nested
+    }
+    fn recurse(node: tree_sitter::Node, max: &mut usize) {
+        if zig_logical_operator(node).is_some() {
+            // The outermost expression's count covers every operator below it
+            *max = (*max).max(count(node));
+            return;
+        }
+        for child in zig_children(node) {
+            recurse(child, max);
+        }
+    }
+    let mut max = 0;
+    for child in zig_children(*func_node) {
+        recurse(child, &mut max);
+    }
+    max
 }
 
-/// Calculate non-structured exits for Go function
-fn go_non_structured_exits(body_node: &tree_sitter::Node, source: &str) -> usize {
-    fn count_exits(node: tree_sitter::Node, source: &str, count: &mut usize) {
-        match node.kind() {
-            "return_statement" => *count += 1,
-            "defer_statement" => *count += 1,
-            "expression_statement" => {
-                if let Some(call) = ts_find_child_by_kind(node, "call_expression") {
-                    // Bare panic() call
-                    if let Some(ident) = ts_find_child_by_kind(call, "identifier") {
-                        let name = &source[ident.start_byte()..ident.end_byte()];
-                        if name == "panic" {
-                            *count += 1;
-                        }
-                    }
-                    // os.Exit / log.Fatal* via selector_expression
-                    else if let Some(sel) = ts_find_child_by_kind(call, "selector_expression") {
-                        if let Some(field) = ts_find_child_by_kind(sel, "field_identifier") {
-                            let field_name = &source[field.start_byte()..field.end_byte()];
-                            if matches!(field_name, "Exit" | "Fatal" | "Fatalf" | "Fatalln") {
-                                *count += 1;
-                            }
-                        }
-                    }
+/// Calculate cognitive complexity (see `ts_cognitive_complexity`). `if`,
+/// loops, `switch`, and `catch` cost 1 plus the nesting level, each
+/// `else if` and `else` costs 1, and so does a labeled `break` or
+/// `continue`. `orelse` and `try` are free: they handle a value without
+/// branching the reader's flow.
+fn zig_cognitive_complexity(func_node: &tree_sitter::Node, source: &str) -> usize {
+    use crate::language::zig::{control_keyword, is_control, jump_keyword, label};
+
+    fn recurse(
+        node: tree_sitter::Node,
+        nesting: usize,
+        logical_parent: Option<DecisionKind>,
+        source: &str,
+        total: &mut usize,
+    ) {
+        if control_keyword(node) == Some("if") {
+            *total += 1 + nesting;
+            if_chain(node, nesting, source, total);
+            return;
+        }
+        let mut inner = nesting;
+        let mut operator = None;
+        if is_control(node) {
+            *total += 1 + nesting;
+            inner += 1;
+        } else if node.kind() == "catch" {
+            *total += 1 + nesting;
+        } else if matches!(jump_keyword(node), Some("break") | Some("continue"))
+            && label(node, source).is_some()
+        {
+            *total += 1;
+        } else if let Some(op) = zig_logical_operator(node) {
+            operator = Some(op);
+            if operator != logical_parent {
+                *total += 1;
+            }
+        } else if node.kind() == "parenthesized_expression" {
+            // Parentheses do not end an operator sequence
+            operator = logical_parent;
+        }
+        for child in zig_children(node) {
+            recurse(child, inner, operator, source, total);
+        }
+    }
+
+    /// An `if`'s condition at `nesting`, its branch one level deeper, and
+    /// its `else`: a further `if` of the chain or a final branch
+    fn if_chain(node: tree_sitter::Node, nesting: usize, source: &str, total: &mut usize) {
+        use crate::language::zig::{control_branches, unwrap_statement};
+
+        let (body, otherwise) = control_branches(node);
+        for child in zig_children(node) {
+            if Some(child.id()) == otherwise.map(|n| n.id()) {
+                *total += 1;
+                let next = unwrap_statement(child);
+                if control_keyword(next) == Some("if") {
+                    if_chain(next, nesting, source, total);
+                } else {
+                    recurse(child, nesting + 1, None, source, total);
                 }
+            } else if Some(child.id()) == body.map(|n| n.id()) {
+                recurse(child, nesting + 1, None, source, total);
+            } else {
+                recurse(child, nesting, None, source, total);
             }
-            _ => {}
         }
+    }
 
-        // Recurse into children
-        let mut cursor = node.walk();
-        for child in node.children(&mut cursor) {
-            count_exits(child, source, count);
-        }
+    let mut total = 0;
+    for child in zig_children(*func_node) {
+        recurse(child, 0, None, source, &mut total);
     }
+    total
+}
 
-    let mut count = 0;
-    count_exits(*body_node, source, &mut count);
+/// Statements of the branch an `if` or loop runs: its `then` branch or body
+fn zig_inner_statements(construct: tree_sitter::Node) -> Vec<tree_sitter::Node> {
+    use crate::language::zig::{branch_statements, control_branches, control_keyword};
 
-    // Subtract 1 if the last statement is a return (final tail return)
-    // This is an approximation - would need more sophisticated AST analysis
-    if count > 0 {
-        let mut cursor = body_node.walk();
-        if let Some(last_child) = body_node.children(&mut cursor).last() {
-            if last_child.kind() == "return_statement" {
-                count = count.saturating_sub(1);
-            }
-        }
+    if control_keyword(construct) == Some("switch") {
+        return Vec::new();
     }
+    control_branches(construct)
+        .0
+        .map(branch_statements)
+        .unwrap_or_default()
+}
 
-    count
+/// Whether an `if` or loop has an `else` branch
+fn zig_has_else(construct: tree_sitter::Node) -> bool {
+    crate::language::zig::control_branches(construct)
+        .1
+        .is_some()
 }
 
-/// Count additional cyclomatic complexity contributors for Go
-fn go_count_cc_extras(body_node: &tree_sitter::Node, _source: &str) -> usize {
-    fn count_extras(node: tree_sitter::Node, count: &mut usize) {
-        match node.kind() {
-            // Count switch/select cases
-            "expression_case" | "default_case" | "communication_case" | "type_case" => {
-                *count += 1;
+/// Count guard clauses (see `ts_guard_clauses`): leading `if`s without
+/// `else` whose branch is a single exit, in the body and in each loop
+/// directly inside it
+fn zig_guard_clauses(statements: &[tree_sitter::Node], source: &str) -> usize {
+    use crate::language::zig::{control_keyword, is_control, unwrap_statement};
+
+    let is_guard = |stmt: &tree_sitter::Node| {
+        let node = unwrap_statement(*stmt);
+        control_keyword(node) == Some("if")
+            && !zig_has_else(node)
+            && matches!(
+                zig_inner_statements(node).as_slice(),
+                [only] if zig_exit_kind(*only, source).is_some()
+            )
+    };
+    let leading = |stmts: &[tree_sitter::Node]| {
+        let mut count = 0;
+        for stmt in stmts {
+            if is_guard(stmt) {
+                count += 1;
+            } else if is_control(unwrap_statement(*stmt)) {
+                break;
             }
-            // Count boolean operators — check the operator child directly to
-            // avoid false positives from nested logical operators in sub-expressions
-            "binary_expression" => {
-                let mut cursor = node.walk();
-                for child in node.children(&mut cursor) {
-                    if child.kind() == "&&" || child.kind() == "||" {
-                        *count += 1;
-                        break;
-                    }
-                }
+        }
+        count
+    };
+
+    let loop_guards: usize = statements
+        .iter()
+        .map(|stmt| unwrap_statement(*stmt))
+        .filter(|node| matches!(control_keyword(*node), Some("for") | Some("while")))
+        .map(|node| leading(&zig_inner_statements(node)))
+        .sum();
+    leading(statements) + loop_guards
+}
+
+/// Calculate arrow depth (see `ts_arrow_depth`)
+fn zig_arrow_depth(statements: &[tree_sitter::Node], source: &str) -> usize {
+    use crate::language::zig::{is_control, unwrap_statement};
+
+    let last = statements.len().saturating_sub(1);
+    let mut construct = None;
+    for (i, stmt) in statements.iter().enumerate() {
+        let node = unwrap_statement(*stmt);
+        if is_control(node) {
+            if construct.is_some() {
+                return 0;
             }
-            _ => {}
+            construct = Some(node);
+        } else if zig_exit_kind(*stmt, source).is_some() && i != last {
+            return 0;
         }
+    }
+    match construct {
+        Some(c) if !zig_has_else(c) => 1 + zig_arrow_depth(&zig_inner_statements(c), source),
+        _ => 0,
+    }
+}
 
-        // Recurse into children
-        let mut cursor = node.walk();
-        for child in node.children(&mut cursor) {
-            count_extras(child, count);
+/// Extract callee names from a Zig function body: the function each call
+/// names (`parse`, `self.next`, `std.mem.eql`) and each builtin it calls
+/// (`@intCast`)
+fn zig_extract_callees(func_node: &tree_sitter::Node, source: &str) -> Vec<String> {
+    use crate::language::zig::text;
+
+    fn collect(
+        node: tree_sitter::Node,
+        source: &str,
+        calls: &mut std::collections::BTreeSet<String>,
+    ) {
+        let callee = match node.kind() {
+            "call_expression" => node
+                .child_by_field_name("function")
+                .or_else(|| node.named_child(0))
+                .map(|function| text(function, source).trim()),
+            "builtin_function" => text(node, source).split('(').next().map(str::trim),
+            _ => None,
+        };
+        if let Some(callee) = callee.filter(|callee| !callee.is_empty()) {
+            calls.insert(callee.to_string());
+        }
+        for child in zig_children(node) {
+            collect(child, source, calls);
         }
     }
 
-    let mut count = 0;
-    count_extras(*body_node, &mut count);
-    count
+    let mut calls = std::collections::BTreeSet::new();
+    for child in zig_children(*func_node) {
+        collect(child, source, &mut calls);
+    }
+    calls.into_iter().collect()
 }
 
-// Note: Go metrics tests are integrated with cfg_builder tests
-
 // ============================================================================
-// Java Metrics Implementation
+// Haskell Metrics Implementation
 // ============================================================================
 
-/// Extract metrics for Java functions using tree-sitter
-fn extract_java_metrics(function: &FunctionNode, cfg: &Cfg) -> RawMetrics {
-    let (_body_node_id, source) = function.body.as_java();
-    ts_with_function_body(
-        source,
-        tree_sitter_java::LANGUAGE.into(),
-        function.span.start,
-        &["method_declaration", "constructor_declaration"],
-        &["block", "constructor_body"],
-        |func_node, body_node| {
-            let callee_names = java_extract_callees(&body_node, source);
-            RawMetrics {
-                cc: calculate_cc_from_cfg(cfg) + java_count_cc_extras(&body_node, source),
-                nd: ts_nesting_depth(
-                    &body_node,
-                    &[
-                        "if_statement",
-                        "while_statement",
-                        "do_statement",
-                        "for_statement",
-                        "enhanced_for_statement",
-                        "switch_statement",
-                        "switch_expression",
-                        "try_statement",
-                        "synchronized_statement",
-                    ],
-                ),
-                fo: callee_names.len(),
-                ns: ts_non_structured_exits(
-                    &body_node,
-                    &[
-                        "return_statement",
-                        "throw_statement",
-                        "break_statement",
-                        "continue_statement",
-                    ],
-                ),
-                loc: calculate_loc_from_node(&func_node),
-                callee_names,
-            }
-        },
-    )
+/// Construct family of a Haskell expression that counts toward ND: `if`,
+/// multi-way `if`, `when` / `unless`, and a guarded right-hand side are ifs
+/// (an `else if` continues its chain rather than nesting); `case`, `\case`,
+/// and `\cases` are matches
+fn haskell_nesting_construct(node: tree_sitter::Node, source: &str) -> Option<NestingConstruct> {
+    use crate::language::haskell::{has_guards, is_case, is_else_if, monadic_conditional};
+
+    match node.kind() {
+        "conditional" if is_else_if(node) => None,
+        "conditional" | "multi_way_if" => Some(NestingConstruct::If),
+        "function" | "bind" | "alternative" if has_guards(node) => Some(NestingConstruct::If),
+        _ if is_case(node) => Some(NestingConstruct::Match),
+        _ if monadic_conditional(node, source).is_some() => Some(NestingConstruct::If),
+        _ => None,
+    }
+}
+
+/// Extract metrics for Haskell functions using tree-sitter, over every
+/// equation of the function (lambdas and `let` functions included, `where`
+/// functions measured on their own)
+fn extract_haskell_metrics(function: &FunctionNode, cfg: &Cfg, nd_counts: NdCounts) -> RawMetrics {
+    use crate::language::haskell::find_function;
+    use crate::language::tree_sitter_utils::with_cached_haskell_tree;
+
+    let (_body_node_id, source) = function.body.as_haskell();
+    with_cached_haskell_tree(source, |root| {
+        let equations = find_function(root, function.span.start, source)?;
+        let callee_names = haskell_extract_callees(&equations, source);
+        let (nd, nd_position) = haskell_nesting_depth(&equations, source, nd_counts);
+        let ns_breakdown = haskell_non_structured_exits(&equations, source);
+        // Equations are siblings, so LOC comes from the span
+        let loc = function
+            .span
+            .end_line
+            .saturating_sub(function.span.start_line)
+            + 1;
+        let cc_tally = haskell_cc_breakdown(&equations, source);
+        Some(RawMetrics {
+            cc: calculate_cc_from_cfg(cfg) + haskell_count_cc_extras(&equations, source),
+            cognitive: haskell_cognitive_complexity(&equations, source),
+            nd,
+            nd_position,
+            fo: callee_names.len(),
+            ns: ns_breakdown.total(),
+            ns_breakdown,
+            loc: loc as usize,
+            callee_names,
+            arrow_depth: equations
+                .iter()
+                .map(|equation| haskell_arrow_depth(*equation, source))
+                .max()
+                .unwrap_or(0),
+            signature_complexity: 0,
+            guard_clauses: equations
+                .iter()
+                .map(|equation| haskell_guard_clauses(*equation, source))
+                .sum(),
+            max_condition_ops: haskell_max_condition_ops(&equations, source),
+            cc_breakdown: Some(cc_tally.breakdown),
+            decisions: cc_tally.decisions,
+            switches: vec![],
+            await_in_loop: 0,
+        })
+    })
     .unwrap_or(RawMetrics {
         cc: 1,
+        cognitive: 0,
         nd: 0,
+        nd_position: None,
         fo: 0,
         ns: 0,
+        ns_breakdown: NsBreakdown::default(),
         loc: 0,
         callee_names: vec![],
+        arrow_depth: 0,
+        signature_complexity: 0,
+        guard_clauses: 0,
+        max_condition_ops: 0,
+        cc_breakdown: None,
+        decisions: vec![],
+        switches: vec![],
+        await_in_loop: 0,
     })
 }
 
-/// Extract callee names from a Java function body.
-/// Returns the unique set of method invocation strings.
-fn java_extract_callees(body_node: &tree_sitter::Node, source: &str) -> Vec<String> {
-    fn collect(
-        node: tree_sitter::Node,
-        source: &str,
-        calls: &mut std::collections::HashSet<String>,
-    ) {
-        if node.kind() == "method_invocation" {
-            let method_text = &source[node.start_byte()..node.end_byte()];
-            calls.insert(method_text.to_string());
-        }
-        let mut cursor = node.walk();
-        for child in node.children(&mut cursor) {
-            collect(child, source, calls);
-        }
+/// The decision a boolean operator is: `&&` or `||`
+fn haskell_logical_operator(node: tree_sitter::Node, source: &str) -> Option<DecisionKind> {
+    match crate::language::haskell::infix_operator(node, source)? {
+        "&&" => Some(DecisionKind::And),
+        "||" => Some(DecisionKind::Or),
+        _ => None,
     }
+}
 
-    let mut calls = std::collections::HashSet::new();
-    collect(*body_node, source, &mut calls);
-    let mut result: Vec<String> = calls.into_iter().collect();
-    result.sort();
-    result
+/// Children of an equation to measure: everything but `where` functions
+fn haskell_children<'a>(node: tree_sitter::Node<'a>, source: &str) -> Vec<tree_sitter::Node<'a>> {
+    use crate::language::haskell::is_nested_definition;
+
+    let mut cursor = node.walk();
+    let children = node
+        .children(&mut cursor)
+        .filter(|child| !is_nested_definition(*child, source))
+        .collect();
+    children
 }
 
-/// Count additional CC contributors in Java
-/// (ternary expressions, boolean operators)
-fn java_count_cc_extras(body_node: &tree_sitter::Node, _source: &str) -> usize {
-    fn count_extras(node: tree_sitter::Node, count: &mut usize) {
-        match node.kind() {
-            // Ternary expressions (conditional_expression) add to CC
-            "ternary_expression" => {
-                *count += 1;
+/// Visit every CC decision point of a Haskell function, with the node it
+/// starts at and whether the CFG already counts it: each equation after the
+/// first, `if`, `case` alternatives other than a final catch-all, guards
+/// other than `otherwise`, and `when` / `unless` are in the CFG unless they
+/// are inside a lambda or a `let` function; boolean operators and the extra
+/// conditions of a guard (`| x > 0, even x`) are not.
+fn haskell_visit_decisions(
+    equations: &[tree_sitter::Node],
+    source: &str,
+    visit: &mut dyn FnMut(DecisionKind, tree_sitter::Node, bool),
+) {
+    use crate::language::haskell::{
+        case_alternatives, guard_conditions, is_case, is_catch_all_alternative, is_catch_all_guard,
+        is_closure, is_equation, is_first_equation, monadic_conditional, right_hand_sides,
+    };
+
+    fn recurse(
+        node: tree_sitter::Node,
+        source: &str,
+        in_closure: bool,
+        visit: &mut dyn FnMut(DecisionKind, tree_sitter::Node, bool),
+    ) {
+        // A closure's own equations and alternatives belong to it
+        let in_cfg = !in_closure && !is_closure(node, source);
+        if is_equation(node) && !is_first_equation(node, source) {
+            // Each equation after the first is another set of patterns to match
+            visit(DecisionKind::MatchArm, node, in_cfg);
+        }
+        if node.kind() == "conditional" {
+            visit(DecisionKind::If, node, in_cfg);
+        } else if is_case(node) {
+            let mut arms = case_alternatives(node);
+            if arms
+                .last()
+                .is_some_and(|last| is_catch_all_alternative(*last, source))
+            {
+                arms.pop();
             }
-            // Binary expressions with && or || add to CC — check the operator
-            // child directly to avoid false positives from nested sub-expressions
-            "binary_expression" => {
-                let mut cursor = node.walk();
-                for child in node.children(&mut cursor) {
-                    if child.kind() == "&&" || child.kind() == "||" {
-                        *count += 1;
-                        break;
+            for arm in arms {
+                visit(DecisionKind::MatchArm, arm, in_cfg);
+            }
+        } else if monadic_conditional(node, source).is_some() {
+            visit(DecisionKind::If, node, in_cfg);
+        } else if let Some(kind) = haskell_logical_operator(node, source) {
+            visit(kind, node, false);
+        }
+        if matches!(
+            node.kind(),
+            "function" | "bind" | "alternative" | "multi_way_if"
+        ) {
+            for side in right_hand_sides(node) {
+                match side.guards {
+                    Some(guards) if !is_catch_all_guard(guards, source) => {
+                        visit(DecisionKind::If, guards, in_cfg);
+                        for condition in guard_conditions(guards).into_iter().skip(1) {
+                            visit(DecisionKind::And, condition, false);
+                        }
                     }
+                    _ => break,
                 }
             }
-            _ => {}
         }
-
-        // Recursively check children
-        let mut cursor = node.walk();
-        for child in node.children(&mut cursor) {
-            count_extras(child, count);
+        for child in haskell_children(node, source) {
+            let in_closure = in_closure || is_closure(child, source);
+            recurse(child, source, in_closure, visit);
         }
     }
 
+    for equation in equations {
+        recurse(*equation, source, false, visit);
+    }
+}
+
+/// Count additional CC contributors in Haskell: every decision the CFG does
+/// not see (see `haskell_visit_decisions`)
+fn haskell_count_cc_extras(equations: &[tree_sitter::Node], source: &str) -> usize {
     let mut count = 0;
-    count_extras(*body_node, &mut count);
+    haskell_visit_decisions(equations, source, &mut |_, _, in_cfg| {
+        if !in_cfg {
+            count += 1;
+        }
+    });
     count
 }
 
-// ============================================================================
-// Python Metrics Implementation
-// ============================================================================
-
-/// Extract metrics for Python functions using tree-sitter
-fn extract_python_metrics(function: &FunctionNode, cfg: &Cfg) -> RawMetrics {
-    let (_body_node_id, source) = function.body.as_python();
-    ts_with_function_body(
-        source,
-        tree_sitter_python::LANGUAGE.into(),
-        function.span.start,
-        &["function_definition", "async_function_definition"],
-        &["block"],
-        |func_node, body_node| {
-            let callee_names = python_extract_callees(&body_node, source);
-            RawMetrics {
-                cc: calculate_cc_from_cfg(cfg) + python_count_cc_extras(&body_node, source),
-                nd: ts_nesting_depth(
-                    &body_node,
-                    &[
-                        "if_statement",
-                        "while_statement",
-                        "for_statement",
-                        "try_statement",
-                        "with_statement",
-                        "match_statement",
-                    ],
-                ),
-                fo: callee_names.len(),
-                ns: ts_non_structured_exits(
-                    &body_node,
-                    &[
-                        "return_statement",
-                        "raise_statement",
-                        "break_statement",
-                        "continue_statement",
-                    ],
-                ),
-                loc: calculate_loc_from_node(&func_node),
-                callee_names,
-            }
-        },
-    )
-    .unwrap_or(RawMetrics {
-        cc: 1,
-        nd: 0,
-        fo: 0,
-        ns: 0,
-        loc: 0,
-        callee_names: vec![],
-    })
+/// Tally CC decision points (see `ts_cc_breakdown`): equations after the
+/// first and `case` alternatives are match arms; `if`, guards, `when`, and
+/// `unless` are ifs; the extra conditions of a guard are ands
+fn haskell_cc_breakdown(equations: &[tree_sitter::Node], source: &str) -> CcTally {
+    let mut tally = CcTally::default();
+    haskell_visit_decisions(equations, source, &mut |kind, node, _| {
+        tally.add_node(kind, node)
+    });
+    tally
 }
 
-/// Extract callee names from a Python function body.
-/// Returns the unique set of call target strings.
-fn python_extract_callees(body_node: &tree_sitter::Node, source: &str) -> Vec<String> {
-    fn collect(
+/// Maximum nesting depth (see `ts_nesting_depth_by`), over every equation
+fn haskell_nesting_depth(
+    equations: &[tree_sitter::Node],
+    source: &str,
+    nd_counts: NdCounts,
+) -> (usize, Option<NestPosition>) {
+    fn recurse(
         node: tree_sitter::Node,
         source: &str,
-        calls: &mut std::collections::HashSet<String>,
+        nd_counts: NdCounts,
+        current: usize,
+        max: &mut usize,
+        line: &mut usize,
     ) {
-        if node.kind() == "call" {
-            let mut cursor = node.walk();
-            if let Some(func_node) = node.children(&mut cursor).next() {
-                let func_text = &source[func_node.start_byte()..func_node.end_byte()];
-                calls.insert(func_text.to_string());
-            };
+        let nests = haskell_nesting_construct(node, source).is_some_and(|c| nd_counts.counts(c));
+        let next = if nests {
+            let d = current + 1;
+            if d > *max {
+                *max = d;
+                *line = node.start_position().row + 1;
+            }
+            d
+        } else {
+            current
+        };
+        for child in haskell_children(node, source) {
+            recurse(child, source, nd_counts, next, max, line);
         }
-        let mut cursor = node.walk();
-        for child in node.children(&mut cursor) {
-            collect(child, source, calls);
+    }
+
+    let mut max_depth = 0;
+    let mut line = 0;
+    for equation in equations {
+        recurse(*equation, source, nd_counts, 0, &mut max_depth, &mut line);
+    }
+    let position = (max_depth > 0).then_some(NestPosition::Line(line as u32));
+    (max_depth, position)
+}
+
+/// Count non-structured exits: Haskell has no `return` statement (`return`
+/// in a `do` block wraps a value), `break`, or `continue`, so only
+/// expressions that raise or end the program count
+fn haskell_non_structured_exits(equations: &[tree_sitter::Node], source: &str) -> NsBreakdown {
+    use crate::language::haskell::is_exit;
+
+    fn recurse(node: tree_sitter::Node, source: &str, breakdown: &mut NsBreakdown) {
+        if is_exit(node, source) {
+            breakdown.add(ExitKind::Throw);
+        }
+        for child in haskell_children(node, source) {
+            recurse(child, source, breakdown);
         }
     }
+    let mut breakdown = NsBreakdown::default();
+    for equation in equations {
+        recurse(*equation, source, &mut breakdown);
+    }
+    breakdown
+}
 
-    let mut calls = std::collections::HashSet::new();
-    collect(*body_node, source, &mut calls);
-    let mut result: Vec<String> = calls.into_iter().collect();
-    result.sort();
-    result
+/// Largest number of `&&` / `||` operators in one boolean expression (see
+/// `ts_max_condition_ops`)
+fn haskell_max_condition_ops(equations: &[tree_sitter::Node], source: &str) -> usize {
+    fn count(node: tree_sitter::Node, source: &str) -> usize {
+        let own = usize::from(haskell_logical_operator(node, source).is_some());
+        let mut cursor = node.walk();
+        let nested: usize = node
+            .children(&mut cursor)
+            .map(|child| count(child, source))
+            .sum();
+        own + nested
+    }
+    fn recurse(node: tree_sitter::Node, source: &str, max: &mut usize) {
+        if haskell_logical_operator(node, source).is_some() {
+            // The outermost expression's count covers every operator below it
+            *max = (*max).max(count(node, source));
+            return;
+        }
+        for child in haskell_children(node, source) {
+            recurse(child, source, max);
+        }
+    }
+    let mut max = 0;
+    for equation in equations {
+        recurse(*equation, source, &mut max);
+    }
+    max
 }
 
-/// Count additional CC contributors in Python
-/// (comprehensions with if-filters, boolean operators, ternary expressions)
-fn python_count_cc_extras(body_node: &tree_sitter::Node, _source: &str) -> usize {
-    fn count_extras(node: tree_sitter::Node, count: &mut usize) {
-        match node.kind() {
-            // Boolean operators (and, or) add to CC
-            "boolean_operator" => {
-                *count += 1;
-            }
-            // Ternary expressions add to CC
-            "conditional_expression" => {
-                *count += 1;
-            }
-            // Comprehensions with if-filters add to CC
-            "list_comprehension"
-            | "dictionary_comprehension"
-            | "set_comprehension"
-            | "generator_expression" => {
-                // Check if it has an if_clause child
-                let mut cursor = node.walk();
-                for child in node.children(&mut cursor) {
-                    if child.kind() == "if_clause" {
-                        *count += 1;
-                        break;
-                    }
+/// Calculate cognitive complexity (see `ts_cognitive_complexity`). `if`,
+/// multi-way `if`, `case`, `\case`, `when`, `unless`, and each guarded
+/// right-hand side cost 1 plus the nesting level, with the code they choose
+/// one level deeper; an `else` costs 1, so `if ... then ... else ...` costs
+/// 2 at the top level, and an `else if` 1. A guard with several conditions
+/// costs 1 more. Lambdas and `let` functions nest their contents. Extra
+/// equations cost nothing: they replace branching rather than add to it.
+fn haskell_cognitive_complexity(equations: &[tree_sitter::Node], source: &str) -> usize {
+    use crate::language::haskell::{
+        case_scrutinee, guard_conditions, has_guards, is_case, is_closure, monadic_conditional,
+    };
+
+    fn recurse(
+        node: tree_sitter::Node,
+        source: &str,
+        nesting: usize,
+        logical_parent: Option<DecisionKind>,
+        total: &mut usize,
+    ) {
+        if node.kind() == "conditional" {
+            *total += 1 + nesting;
+            if_chain(node, source, nesting, total);
+            return;
+        }
+        let guarded =
+            matches!(node.kind(), "function" | "bind" | "alternative") && has_guards(node);
+        let structure = guarded
+            || is_case(node)
+            || node.kind() == "multi_way_if"
+            || monadic_conditional(node, source).is_some();
+        if structure {
+            *total += 1 + nesting;
+        }
+        let mut operator = None;
+        if let Some(kind) = haskell_logical_operator(node, source) {
+            operator = Some(kind);
+            if operator != logical_parent {
+                *total += 1;
+            }
+        } else if node.kind() == "parens" {
+            // Parentheses do not end an operator sequence
+            operator = logical_parent;
+        } else if node.kind() == "guards" && guard_conditions(node).len() > 1 {
+            *total += 1;
+        }
+
+        // What a construct tests stays at its nesting; the code it chooses
+        // is one level deeper, as is everything in a closure
+        let tested = case_scrutinee(node)
+            .or_else(|| monadic_conditional(node, source).map(|(condition, _)| condition));
+        let closure = is_closure(node, source);
+        for child in haskell_children(node, source) {
+            let is_tested = tested.is_some_and(|t| {
+                child.start_byte() <= t.start_byte() && t.end_byte() <= child.end_byte()
+            });
+            let chosen = structure && !is_tested && (!guarded || child.kind() == "match");
+            let depth = nesting + usize::from(closure || chosen);
+            recurse(child, source, depth, operator, total);
+        }
+    }
+
+    /// An `if`'s condition at `nesting`, its `then` branch one level deeper,
+    /// and its `else`: a further `if` of the chain or a final branch
+    fn if_chain(node: tree_sitter::Node, source: &str, nesting: usize, total: &mut usize) {
+        use crate::language::haskell::conditional_parts;
+
+        let (_, consequence, alternative) = conditional_parts(node);
+        for child in haskell_children(node, source) {
+            if Some(child.id()) == alternative.map(|n| n.id()) {
+                *total += 1;
+                if child.kind() == "conditional" {
+                    if_chain(child, source, nesting, total);
+                } else {
+                    recurse(child, source, nesting + 1, None, total);
                 }
+            } else if Some(child.id()) == consequence.map(|n| n.id()) {
+                recurse(child, source, nesting + 1, None, total);
+            } else {
+                recurse(child, source, nesting, None, total);
             }
-            _ => {}
         }
+    }
 
-        // Recursively check children
-        let mut cursor = node.walk();
-        for child in node.children(&mut cursor) {
-            count_extras(child, count);
+    let mut total = 0;
+    for equation in equations {
+        recurse(*equation, source, 0, None, &mut total);
+    }
+    total
+}
+
+/// Count guard clauses (see `ts_guard_clauses`): the leading guards of an
+/// equation whose expression raises (`| n < 0 = error "negative"`)
+fn haskell_guard_clauses(equation: tree_sitter::Node, source: &str) -> usize {
+    use crate::language::haskell::{is_catch_all_guard, is_exit, right_hand_sides};
+
+    right_hand_sides(equation)
+        .iter()
+        .take_while(|side| {
+            side.guards
+                .is_some_and(|guards| !is_catch_all_guard(guards, source))
+                && side.body.is_some_and(|body| is_exit(body, source))
+        })
+        .count()
+}
+
+/// Calculate arrow depth (see `ts_arrow_depth`) of one equation. `if`
+/// always has an `else` and guards end at `otherwise`, so only `when` /
+/// `unless` chains in `do` blocks form an arrow.
+fn haskell_arrow_depth(equation: tree_sitter::Node, source: &str) -> usize {
+    use crate::language::haskell::{do_statements, is_case, is_exit, monadic_conditional};
+
+    fn depth(statements: &[tree_sitter::Node], source: &str) -> usize {
+        let last = statements.len().saturating_sub(1);
+        let mut construct = None;
+        for (i, stmt) in statements.iter().enumerate() {
+            if matches!(stmt.kind(), "conditional" | "multi_way_if")
+                || is_case(*stmt)
+                || monadic_conditional(*stmt, source).is_some()
+            {
+                if construct.is_some() {
+                    return 0;
+                }
+                construct = Some(*stmt);
+            } else if is_exit(*stmt, source) && i != last {
+                return 0;
+            }
+        }
+        match construct.and_then(|c| monadic_conditional(c, source)) {
+            Some((_, action)) => 1 + depth(&do_statements(action), source),
+            None => 0,
         }
     }
 
-    let mut count = 0;
-    count_extras(*body_node, &mut count);
-    count
+    crate::language::haskell::right_hand_sides(equation)
+        .iter()
+        .filter(|side| side.guards.is_none())
+        .filter_map(|side| side.body)
+        .map(|body| depth(&do_statements(body), source))
+        .max()
+        .unwrap_or(0)
 }
 
-// Note: Python metrics tests are integrated with cfg_builder tests
+/// Extract callee names from the equations of a Haskell function: the
+/// function each application applies (`lookup k m`, `Map.insert k v m`,
+/// `print $ total xs`), each function used as an infix operator
+/// (`` x `elem` xs ``), and each action a `do` block runs by name
+/// (`line <- getLine`). Constructors (`Just x`) build values rather than
+/// call code, and `when`, `unless`, and the functions that raise or exit are
+/// control flow.
+fn haskell_extract_callees(equations: &[tree_sitter::Node], source: &str) -> Vec<String> {
+    use crate::language::haskell::{
+        application, do_statements, infix_operator, unqualified, variable_name, EXIT_FUNCTIONS,
+        EXIT_VALUES, MONADIC_CONDITIONALS,
+    };
 
-// ============================================================================
-// C# Metrics Implementation
-// ============================================================================
+    fn is_call(name: &str) -> bool {
+        let base = unqualified(name);
+        base.starts_with(|c: char| c.is_lowercase() || c == '_')
+            && !EXIT_FUNCTIONS.contains(&base)
+            && !EXIT_VALUES.contains(&base)
+            && !MONADIC_CONDITIONALS.contains(&base)
+    }
 
-/// Extract metrics for C# functions using tree-sitter
-fn extract_csharp_metrics(function: &FunctionNode, cfg: &Cfg) -> RawMetrics {
-    let (_body_node_id, source) = function.body.as_csharp();
-    ts_with_function_body(
-        source,
-        tree_sitter_c_sharp::LANGUAGE.into(),
-        function.span.start,
-        &[
-            "method_declaration",
-            "constructor_declaration",
-            "local_function_statement",
-            "operator_declaration",
-            "conversion_operator_declaration",
-        ],
-        &["block"],
-        |func_node, body_node| {
-            let callee_names = csharp_extract_callees(&body_node, source);
-            RawMetrics {
-                cc: calculate_cc_from_cfg(cfg) + csharp_count_cc_extras(&body_node, source),
-                nd: ts_nesting_depth(
-                    &body_node,
-                    &[
-                        "if_statement",
-                        "while_statement",
-                        "do_statement",
-                        "for_statement",
-                        "foreach_statement",
-                        "switch_statement",
-                        "try_statement",
-                    ],
-                ),
-                fo: callee_names.len(),
-                ns: ts_non_structured_exits(
-                    &body_node,
-                    &[
-                        "return_statement",
-                        "throw_statement",
-                        "break_statement",
-                        "continue_statement",
-                    ],
-                ),
-                loc: calculate_loc_from_node(&func_node),
-                callee_names,
+    fn collect(
+        node: tree_sitter::Node,
+        source: &str,
+        calls: &mut std::collections::BTreeSet<String>,
+    ) {
+        let mut callees = Vec::new();
+        match application(node, source) {
+            Some((function, _)) => callees.extend(variable_name(function, source)),
+            None => callees.extend(
+                infix_operator(node, source)
+                    .filter(|op| op.starts_with('`'))
+                    .map(|op| op.trim_matches('`').trim()),
+            ),
+        }
+        if node.kind() == "do" {
+            for statement in do_statements(node) {
+                // `x <- action` runs the action after the arrow
+                let action = match statement.kind() {
+                    "bind" => statement.child_by_field_name("expression").or_else(|| {
+                        let mut cursor = statement.walk();
+                        let last = statement.named_children(&mut cursor).last();
+                        last
+                    }),
+                    _ => Some(statement),
+                };
+                callees.extend(action.and_then(|action| variable_name(action, source)));
             }
-        },
-    )
-    .unwrap_or(RawMetrics {
-        cc: 1,
-        nd: 0,
-        fo: 0,
-        ns: 0,
-        loc: 0,
-        callee_names: vec![],
-    })
+        }
+        for callee in callees.into_iter().filter(|callee| is_call(callee)) {
+            calls.insert(callee.to_string());
+        }
+        for child in haskell_children(node, source) {
+            collect(child, source, calls);
+        }
+    }
+
+    let mut calls = std::collections::BTreeSet::new();
+    for equation in equations {
+        collect(*equation, source, &mut calls);
+    }
+    calls.into_iter().collect()
 }
 
 // ============================================================================
-// C Metrics Implementation
+// Perl Metrics Implementation
 // ============================================================================
 
-fn extract_c_metrics(function: &FunctionNode, cfg: &Cfg) -> RawMetrics {
-    let (_body_node_id, source) = function.body.as_c();
-    ts_with_function_body(
-        source,
-        tree_sitter_c::LANGUAGE.into(),
-        function.span.start,
-        &["function_definition"],
-        &["compound_statement"],
-        |func_node, body_node| {
-            let callee_names = c_extract_callees(&body_node, source);
-            RawMetrics {
-                cc: calculate_cc_from_cfg(cfg) + c_count_cc_extras(&body_node),
-                nd: ts_nesting_depth(
-                    &body_node,
-                    &[
-                        "if_statement",
-                        "while_statement",
-                        "do_statement",
-                        "for_statement",
-                        "switch_statement",
-                    ],
-                ),
-                fo: callee_names.len(),
-                ns: ts_non_structured_exits(
-                    &body_node,
-                    &[
-                        "return_statement",
-                        "break_statement",
-                        "continue_statement",
-                        "goto_statement",
-                    ],
-                ),
-                loc: calculate_loc_from_node(&func_node),
-                callee_names,
-            }
-        },
-    )
+/// Construct family of a Perl statement that counts toward ND: `if` /
+/// `unless` and the loop statements. Statement modifiers have no block, so
+/// they do not nest.
+fn perl_nesting_construct(kind: &str) -> Option<NestingConstruct> {
+    match kind {
+        "conditional_statement" => Some(NestingConstruct::If),
+        "for_statement" | "cstyle_for_statement" => Some(NestingConstruct::For),
+        "loop_statement" => Some(NestingConstruct::While),
+        _ => None,
+    }
+}
+
+/// Extract metrics for Perl subs using tree-sitter, over the sub's own body
+/// (subs defined inside it are measured on their own)
+fn extract_perl_metrics(function: &FunctionNode, cfg: &Cfg, nd_counts: NdCounts) -> RawMetrics {
+    use crate::language::perl::{body_statements, find_function};
+    use crate::language::tree_sitter_utils::with_cached_perl_tree;
+
+    let (_body_node_id, source) = function.body.as_perl();
+    with_cached_perl_tree(source, |root| {
+        let func_node = find_function(root, function.span.start)?;
+        let statements = body_statements(func_node);
+        let callee_names = perl_extract_callees(&func_node, source);
+        let (nd, nd_position) = perl_nesting_depth(&func_node, nd_counts);
+        let ns_breakdown = perl_non_structured_exits(&func_node, &statements, source);
+        let cc_tally = perl_cc_breakdown(&func_node, source);
+        Some(RawMetrics {
+            cc: calculate_cc_from_cfg(cfg) + perl_count_cc_extras(&func_node, source),
+            cognitive: perl_cognitive_complexity(&func_node, source),
+            nd,
+            nd_position,
+            fo: callee_names.len(),
+            ns: ns_breakdown.total(),
+            ns_breakdown,
+            loc: calculate_loc_from_node(&func_node),
+            callee_names,
+            arrow_depth: perl_arrow_depth(&statements, source),
+            signature_complexity: 0,
+            guard_clauses: perl_guard_clauses(&statements, source),
+            max_condition_ops: perl_max_condition_ops(&func_node, source),
+            cc_breakdown: Some(cc_tally.breakdown),
+            decisions: cc_tally.decisions,
+            switches: vec![],
+            await_in_loop: 0,
+        })
+    })
     .unwrap_or(RawMetrics {
         cc: 1,
+        cognitive: 0,
         nd: 0,
+        nd_position: None,
         fo: 0,
         ns: 0,
+        ns_breakdown: NsBreakdown::default(),
         loc: 0,
         callee_names: vec![],
+        arrow_depth: 0,
+        signature_complexity: 0,
+        guard_clauses: 0,
+        max_condition_ops: 0,
+        cc_breakdown: None,
+        decisions: vec![],
+        switches: vec![],
+        await_in_loop: 0,
     })
 }
 
-/// Extract callee names from a C function body (call_expression nodes).
-fn c_extract_callees(body_node: &tree_sitter::Node, source: &str) -> Vec<String> {
-    fn collect(
+/// Children of a node to measure: everything but nested sub definitions
+fn perl_children(node: tree_sitter::Node) -> Vec<tree_sitter::Node> {
+    use crate::language::perl::is_nested_definition;
+
+    let mut cursor = node.walk();
+    let children = node
+        .children(&mut cursor)
+        .filter(|child| !is_nested_definition(*child))
+        .collect();
+    children
+}
+
+/// The decision an operator expression is: `&&` / `and`, `||` / `or`, the
+/// defined-or `//`, or `?:`
+fn perl_operator_decision(node: tree_sitter::Node, source: &str) -> Option<DecisionKind> {
+    if node.kind() == "conditional_expression" {
+        return Some(DecisionKind::Ternary);
+    }
+    match crate::language::perl::logical_operator(node, source)? {
+        "&&" | "and" => Some(DecisionKind::And),
+        "||" | "or" => Some(DecisionKind::Or),
+        _ => Some(DecisionKind::Coalesce),
+    }
+}
+
+/// Whether the CFG visits `child` as part of the statements of `parent`: the
+/// statements of a block, the blocks and clauses of `if` / `unless` and
+/// loops, and the statement modifier of an expression statement. A block
+/// inside an expression (`do { ... }`, `eval { ... }`) is not.
+fn perl_in_cfg(parent: tree_sitter::Node, child: tree_sitter::Node) -> bool {
+    use crate::language::perl::{LOOP_KINDS, POSTFIX_CONDITIONAL, POSTFIX_LOOP_KINDS};
+
+    match parent.kind() {
+        "block" => true,
+        "conditional_statement" | "elsif" | "else" | "block_statement" => {
+            matches!(child.kind(), "block" | "elsif" | "else")
+        }
+        kind if LOOP_KINDS.contains(&kind) => child.kind() == "block",
+        "expression_statement" => {
+            child.kind() == POSTFIX_CONDITIONAL || POSTFIX_LOOP_KINDS.contains(&child.kind())
+        }
+        _ => false,
+    }
+}
+
+/// Visit every CC decision point of a Perl sub, with the node it starts at
+/// and whether the CFG already counts it: statements and statement modifiers
+/// the CFG reaches are in it; operators, and statements inside an expression
+/// such as `do { ... }`, are not
+fn perl_visit_decisions(
+    func_node: &tree_sitter::Node,
+    source: &str,
+    visit: &mut dyn FnMut(DecisionKind, tree_sitter::Node, bool),
+) {
+    use crate::language::perl::{
+        body_block, if_clauses, ClauseKind, LOOP_KINDS, POSTFIX_CONDITIONAL, POSTFIX_LOOP_KINDS,
+    };
+
+    fn recurse(
         node: tree_sitter::Node,
         source: &str,
-        calls: &mut std::collections::HashSet<String>,
+        in_cfg: bool,
+        visit: &mut dyn FnMut(DecisionKind, tree_sitter::Node, bool),
     ) {
-        if node.kind() == "call_expression" {
-            // First child of call_expression is the function identifier
-            let child_count = node.child_count();
-            if child_count > 0 {
-                if let Some(callee) = node.child(0) {
-                    let text = &source[callee.start_byte()..callee.end_byte()];
-                    calls.insert(text.to_string());
+        let kind = node.kind();
+        if kind == "conditional_statement" {
+            visit(DecisionKind::If, node, in_cfg);
+            for clause in if_clauses(node) {
+                if clause.kind == ClauseKind::Elsif {
+                    visit(DecisionKind::If, clause.start, in_cfg);
                 }
             }
+        } else if kind == POSTFIX_CONDITIONAL {
+            visit(DecisionKind::If, node, in_cfg);
+        } else if LOOP_KINDS.contains(&kind) || POSTFIX_LOOP_KINDS.contains(&kind) {
+            visit(DecisionKind::Loop, node, in_cfg);
+        } else if let Some(decision) = perl_operator_decision(node, source) {
+            visit(decision, node, false);
         }
-        let mut cursor = node.walk();
-        let children: Vec<_> = node.children(&mut cursor).collect();
-        for child in children {
-            collect(child, source, calls);
+        for child in perl_children(node) {
+            recurse(child, source, in_cfg && perl_in_cfg(node, child), visit);
         }
     }
 
-    let mut calls = std::collections::HashSet::new();
-    collect(*body_node, source, &mut calls);
-    let mut result: Vec<String> = calls.into_iter().collect();
-    result.sort();
-    result
+    let body = body_block(*func_node);
+    for child in perl_children(*func_node) {
+        recurse(child, source, Some(child) == body, visit);
+    }
 }
 
-/// Count additional CC contributors in C (ternary expressions, boolean short-circuit operators).
-fn c_count_cc_extras(body_node: &tree_sitter::Node) -> usize {
-    fn count_extras(node: tree_sitter::Node, count: &mut usize) {
-        match node.kind() {
-            "conditional_expression" => {
-                *count += 1;
-            }
-            "binary_expression" => {
-                let mut cursor = node.walk();
-                for child in node.children(&mut cursor) {
-                    if child.kind() == "&&" || child.kind() == "||" {
-                        *count += 1;
-                        break;
-                    }
-                }
+/// Count additional CC contributors in Perl: the decisions the CFG does not
+/// see (see `perl_visit_decisions`)
+fn perl_count_cc_extras(func_node: &tree_sitter::Node, source: &str) -> usize {
+    let mut count = 0;
+    perl_visit_decisions(func_node, source, &mut |_, _, in_cfg| {
+        if !in_cfg {
+            count += 1;
+        }
+    });
+    count
+}
+
+/// Tally CC decision points (see `ts_cc_breakdown`): an `elsif` and a
+/// statement modifier are each an `if` or a loop
+fn perl_cc_breakdown(func_node: &tree_sitter::Node, source: &str) -> CcTally {
+    let mut tally = CcTally::default();
+    perl_visit_decisions(func_node, source, &mut |kind, node, _| {
+        tally.add_node(kind, node)
+    });
+    tally
+}
+
+/// Decision points of a Perl file outside every sub (see
+/// `lua_top_level_decisions`)
+pub(crate) fn perl_top_level_decisions(root: &tree_sitter::Node, source: &str) -> usize {
+    perl_cc_breakdown(root, source).decisions.len()
+}
+
+/// Maximum nesting depth of control statements (see `ts_nesting_depth_by`)
+fn perl_nesting_depth(
+    func_node: &tree_sitter::Node,
+    nd_counts: NdCounts,
+) -> (usize, Option<NestPosition>) {
+    fn recurse(
+        node: tree_sitter::Node,
+        nd_counts: NdCounts,
+        current: usize,
+        max: &mut usize,
+        line: &mut usize,
+    ) {
+        let nests = perl_nesting_construct(node.kind()).is_some_and(|c| nd_counts.counts(c));
+        let next = if nests {
+            let d = current + 1;
+            if d > *max {
+                *max = d;
+                *line = node.start_position().row + 1;
             }
-            _ => {}
+            d
+        } else {
+            current
+        };
+        for child in perl_children(node) {
+            recurse(child, nd_counts, next, max, line);
         }
-        let mut cursor = node.walk();
-        for child in node.children(&mut cursor) {
-            count_extras(child, count);
+    }
+
+    let mut max_depth = 0;
+    let mut line = 0;
+    for child in perl_children(*func_node) {
+        recurse(child, nd_counts, 0, &mut max_depth, &mut line);
+    }
+    let position = (max_depth > 0).then_some(NestPosition::Line(line as u32));
+    (max_depth, position)
+}
+
+/// The exit an expression is: `return`, `last`, `next` / `redo`, `goto`, or
+/// a call to `die`, `croak`, `confess`, or `exit`
+fn perl_exit_kind(node: tree_sitter::Node, source: &str) -> Option<ExitKind> {
+    use crate::language::perl::{jump, Jump};
+
+    match jump(node, source)? {
+        Jump::Return => Some(ExitKind::Return),
+        Jump::Raise => Some(ExitKind::Throw),
+        Jump::Last => Some(ExitKind::Break),
+        Jump::Next => Some(ExitKind::Continue),
+        Jump::Goto => Some(ExitKind::Goto),
+    }
+}
+
+/// Count non-structured exits. A `return` statement ending the sub body is
+/// structured and does not count.
+fn perl_non_structured_exits(
+    func_node: &tree_sitter::Node,
+    statements: &[tree_sitter::Node],
+    source: &str,
+) -> NsBreakdown {
+    use crate::language::perl::{statement_jump, Jump};
+
+    fn recurse(node: tree_sitter::Node, source: &str, breakdown: &mut NsBreakdown) {
+        if let Some(kind) = perl_exit_kind(node, source) {
+            breakdown.add(kind);
+        }
+        for child in perl_children(node) {
+            recurse(child, source, breakdown);
         }
     }
-    let mut count = 0;
-    count_extras(*body_node, &mut count);
-    count
+    let mut breakdown = NsBreakdown::default();
+    for child in perl_children(*func_node) {
+        recurse(child, source, &mut breakdown);
+    }
+    if statements
+        .last()
+        .is_some_and(|last| statement_jump(*last, source) == Some(Jump::Return))
+    {
+        breakdown.remove(ExitKind::Return);
+    }
+    breakdown
 }
 
-/// Extract callee names from a C# function body.
-fn csharp_extract_callees(body_node: &tree_sitter::Node, source: &str) -> Vec<String> {
-    fn collect(
+/// Largest number of `&&` / `||` / `and` / `or` operators in one boolean
+/// expression (see `ts_max_condition_ops`)
+fn perl_max_condition_ops(func_node: &tree_sitter::Node, source: &str) -> usize {
+    fn is_and_or(node: tree_sitter::Node, source: &str) -> bool {
+        matches!(
+            perl_operator_decision(node, source),
+            Some(DecisionKind::And | DecisionKind::Or)
+        )
+    }
+    fn count(node: tree_sitter::Node, source: &str) -> usize {
+        let own = usize::from(is_and_or(node, source));
+        let nested: usize = perl_children(node)
+            .into_iter()
+            .map(|child| count(child, source))
+            .sum();
+        own + nested
+    }
+    fn recurse(node: tree_sitter::Node, source: &str, max: &mut usize) {
+        if is_and_or(node, source) {
+            // The outermost expression's count covers every operator below it
+            *max = (*max).max(count(node, source));
+            return;
+        }
+        for child in perl_children(node) {
+            recurse(child, source, max);
+        }
+    }
+    let mut max = 0;
+    for child in perl_children(*func_node) {
+        recurse(child, source, &mut max);
+    }
+    max
+}
+
+/// Calculate cognitive complexity (see `ts_cognitive_complexity`). `if` /
+/// `unless`, loops, and `?:` cost 1 plus the nesting level, each `elsif` and
+/// `else` costs 1, and so do a statement modifier and `goto`.
+fn perl_cognitive_complexity(func_node: &tree_sitter::Node, source: &str) -> usize {
+    use crate::language::perl::{
+        if_clauses, ClauseKind, LOOP_KINDS, POSTFIX_CONDITIONAL, POSTFIX_LOOP_KINDS,
+    };
+
+    fn recurse(
         node: tree_sitter::Node,
         source: &str,
-        calls: &mut std::collections::HashSet<String>,
+        nesting: usize,
+        logical_parent: Option<DecisionKind>,
+        total: &mut usize,
     ) {
-        if node.kind() == "invocation_expression" {
-            let call_text = &source[node.start_byte()..node.end_byte()];
-            calls.insert(call_text.to_string());
+        let kind = node.kind();
+        if kind == "conditional_statement" {
+            if_chain(node, source, nesting, total);
+            return;
         }
-        let mut cursor = node.walk();
-        for child in node.children(&mut cursor) {
-            collect(child, source, calls);
+        let mut inner = nesting;
+        let mut operator = None;
+        if LOOP_KINDS.contains(&kind) {
+            *total += 1 + nesting;
+            inner += 1;
+        } else if kind == POSTFIX_CONDITIONAL
+            || POSTFIX_LOOP_KINDS.contains(&kind)
+            || kind == "goto_expression"
+        {
+            *total += 1;
+        } else if let Some(decision) = perl_operator_decision(node, source) {
+            if decision == DecisionKind::Ternary {
+                *total += 1 + nesting;
+                inner += 1;
+            } else {
+                operator = Some(decision);
+                if operator != logical_parent {
+                    *total += 1;
+                }
+            }
+        } else if kind.contains("paren") {
+            // Parentheses do not end an operator sequence
+            operator = logical_parent;
+        }
+        for child in perl_children(node) {
+            recurse(child, source, inner, operator, total);
         }
     }
 
-    let mut calls = std::collections::HashSet::new();
-    collect(*body_node, source, &mut calls);
-    let mut result: Vec<String> = calls.into_iter().collect();
-    result.sort();
-    result
+    /// An `if` / `unless` and its `elsif` / `else` clauses: blocks are one
+    /// level deeper, conditions are not
+    fn if_chain(node: tree_sitter::Node, source: &str, nesting: usize, total: &mut usize) {
+        *total += 1 + nesting;
+        *total += if_clauses(node)
+            .iter()
+            .filter(|clause| clause.kind != ClauseKind::If)
+            .count();
+        for child in perl_children(node) {
+            match child.kind() {
+                "block" => recurse(child, source, nesting + 1, None, total),
+                "elsif" | "else" if child.is_named() => {
+                    for part in perl_children(child) {
+                        let depth = if part.kind() == "block" {
+                            nesting + 1
+                        } else {
+                            nesting
+                        };
+                        recurse(part, source, depth, None, total);
+                    }
+                }
+                _ => recurse(child, source, nesting, None, total),
+            }
+        }
+    }
+
+    let mut total = 0;
+    for child in perl_children(*func_node) {
+        recurse(child, source, 0, None, &mut total);
+    }
+    total
 }
 
-/// Count additional CC contributors in C# (ternary, boolean operators, null-coalescing)
-fn csharp_count_cc_extras(body_node: &tree_sitter::Node, _source: &str) -> usize {
-    fn count_extras(node: tree_sitter::Node, count: &mut usize) {
-        match node.kind() {
-            "conditional_expression" => {
-                *count += 1;
+/// Count guard clauses (see `ts_guard_clauses`): leading statements that
+/// leave early, in the body and in each loop directly inside it. A guard is
+/// an `if` / `unless` without `elsif` or `else` whose block is a single
+/// exit, or the idiomatic statement modifier on an exit (`return unless $x;`,
+/// `die "..." if $bad;`, `next if $seen{$_}++;`).
+fn perl_guard_clauses(statements: &[tree_sitter::Node], source: &str) -> usize {
+    use crate::language::perl::{
+        body_statements, has_alternative, jump, modified_expression, statement_expression,
+        statement_jump, POSTFIX_CONDITIONAL,
+    };
+
+    let is_guard = |stmt: &tree_sitter::Node| match stmt.kind() {
+        "conditional_statement" => {
+            !has_alternative(*stmt)
+                && matches!(
+                    body_statements(*stmt).as_slice(),
+                    [only] if statement_jump(*only, source).is_some()
+                )
+        }
+        "expression_statement" => statement_expression(*stmt)
+            .filter(|expression| expression.kind() == POSTFIX_CONDITIONAL)
+            .and_then(modified_expression)
+            .and_then(|expression| jump(expression, source))
+            .is_some(),
+        _ => false,
+    };
+    let leading = |stmts: &[tree_sitter::Node]| {
+        let mut count = 0;
+        for stmt in stmts {
+            if is_guard(stmt) {
+                count += 1;
+            } else if perl_nesting_construct(stmt.kind()).is_some() {
+                break;
             }
-            "binary_expression" => {
-                let mut cursor = node.walk();
-                for child in node.children(&mut cursor) {
-                    if child.kind() == "&&" || child.kind() == "||" || child.kind() == "??" {
-                        *count += 1;
-                        break;
-                    }
-                }
+        }
+        count
+    };
+
+    let loop_guards: usize = statements
+        .iter()
+        .filter(|stmt| {
+            stmt.kind() != "conditional_statement" && perl_nesting_construct(stmt.kind()).is_some()
+        })
+        .map(|stmt| leading(&body_statements(*stmt)))
+        .sum();
+    leading(statements) + loop_guards
+}
+
+/// Calculate arrow depth (see `ts_arrow_depth`)
+fn perl_arrow_depth(statements: &[tree_sitter::Node], source: &str) -> usize {
+    use crate::language::perl::{body_statements, has_alternative, statement_jump};
+
+    let last = statements.len().saturating_sub(1);
+    let mut construct = None;
+    for (i, stmt) in statements.iter().enumerate() {
+        if perl_nesting_construct(stmt.kind()).is_some() {
+            if construct.is_some() {
+                return 0;
             }
-            _ => {}
+            construct = Some(*stmt);
+        } else if statement_jump(*stmt, source).is_some() && i != last {
+            return 0;
         }
-        let mut cursor = node.walk();
-        for child in node.children(&mut cursor) {
-            count_extras(child, count);
+    }
+    match construct {
+        Some(c) if !has_alternative(c) => 1 + perl_arrow_depth(&body_statements(c), source),
+        _ => 0,
+    }
+}
+
+/// Extract callee names from a Perl sub body: the subs and methods it calls
+/// (`validate`, `Data::Dumper::Dumper`, `$self->save`). `die`, `croak`,
+/// `confess`, and `exit` are exits, not calls, and named unary operators
+/// (`shift`, `defined`, `ref`) are operators.
+fn perl_extract_callees(func_node: &tree_sitter::Node, source: &str) -> Vec<String> {
+    use crate::language::perl::{call_name, is_raise};
+
+    fn collect(
+        node: tree_sitter::Node,
+        source: &str,
+        calls: &mut std::collections::BTreeSet<String>,
+    ) {
+        let operator = matches!(
+            node.kind(),
+            "func0op_call_expression" | "func1op_call_expression"
+        );
+        if let Some(callee) = call_name(node, source).filter(|_| !operator) {
+            if !callee.is_empty() && !is_raise(&callee) {
+                calls.insert(callee);
+            }
+        }
+        for child in perl_children(node) {
+            collect(child, source, calls);
         }
     }
 
-    let mut count = 0;
-    count_extras(*body_node, &mut count);
-    count
+    let mut calls = std::collections::BTreeSet::new();
+    for child in perl_children(*func_node) {
+        collect(child, source, &mut calls);
+    }
+    calls.into_iter().collect()
 }
 
 // ========================================
@@ -1119,7 +7788,7 @@ fn csharp_count_cc_extras(body_node: &tree_sitter::Node, _source: &str) -> usize
 // ========================================
 
 /// Extract metrics for a Rust function
-fn extract_rust_metrics(function: &FunctionNode, cfg: &Cfg) -> RawMetrics {
+fn extract_rust_metrics(function: &FunctionNode, cfg: &Cfg, nd_counts: NdCounts) -> RawMetrics {
     let source = function.body.as_rust();
 
     // Parse the function source
@@ -1129,42 +7798,85 @@ fn extract_rust_metrics(function: &FunctionNode, cfg: &Cfg) -> RawMetrics {
             // Fallback on parse error
             return RawMetrics {
                 cc: calculate_cc_from_cfg(cfg),
+                cognitive: 0,
                 nd: 0,
+                nd_position: None,
                 fo: 0,
                 ns: 0,
+                ns_breakdown: NsBreakdown::default(),
                 loc: 0,
                 callee_names: vec![],
+                arrow_depth: 0,
+                signature_complexity: 0,
+                guard_clauses: 0,
+                max_condition_ops: 0,
+                cc_breakdown: None,
+                decisions: vec![],
+                switches: vec![],
+                await_in_loop: 0,
             };
         }
     };
 
     let base_cc = calculate_cc_from_cfg(cfg);
     let extra_cc = rust_count_cc_extras(&item_fn.block);
-    let nd = rust_nesting_depth(&item_fn.block);
+    let (nd, nd_line) = rust_nesting_depth(&item_fn.block, nd_counts);
+    // The source ends on the item's last line; count back to its first
+    let first_line = function
+        .span
+        .end_line
+        .saturating_sub(source.matches('\n').count() as u32);
     let callee_names = rust_extract_callees(&item_fn.block);
-    let ns = rust_non_structured_exits(&item_fn.block);
+    let ns_breakdown = rust_non_structured_exits(&item_fn.block);
+    let arrow_depth = rust_arrow_depth(&item_fn.block);
+    let guard_clauses = rust_guard_clauses(&item_fn.block);
 
+    let cc_tally = rust_cc_breakdown(&item_fn.block, first_line);
     RawMetrics {
         cc: base_cc + extra_cc,
+        cognitive: rust_cognitive_complexity(&item_fn.block),
         nd,
+        nd_position: nd_line.map(|line| NestPosition::Line(first_line + line as u32 - 1)),
         fo: callee_names.len(),
-        ns,
+        ns: ns_breakdown.total(),
+        ns_breakdown,
         loc: calculate_loc(source),
         callee_names,
+        arrow_depth,
+        signature_complexity: crate::signature::rust_signature(&item_fn.sig),
+        guard_clauses,
+        max_condition_ops: rust_max_condition_ops(&item_fn.block),
+        cc_breakdown: Some(cc_tally.breakdown),
+        decisions: cc_tally.decisions,
+        switches: vec![],
+        await_in_loop: 0,
     }
 }
 
-/// Calculate nesting depth for Rust function
-fn rust_nesting_depth(block: &syn::Block) -> usize {
+/// Calculate nesting depth for Rust function, plus the line (within the
+/// function source) of the first construct that reaches it
+fn rust_nesting_depth(block: &syn::Block, nd_counts: NdCounts) -> (usize, Option<usize>) {
+    use syn::spanned::Spanned;
     use syn::{Expr, Stmt};
 
-    fn calculate_depth(stmts: &[Stmt], current_depth: usize, max_depth: &mut usize) {
+    /// Maximum depth so far and the line of the construct that reached it
+    struct Deepest {
+        depth: usize,
+        line: usize,
+    }
+
+    fn calculate_depth(
+        stmts: &[Stmt],
+        current_depth: usize,
+        deepest: &mut Deepest,
+        nd_counts: NdCounts,
+    ) {
         for stmt in stmts {
             match stmt {
-                Stmt::Expr(expr, _) => expr_depth(expr, current_depth, max_depth),
+                Stmt::Expr(expr, _) => expr_depth(expr, current_depth, deepest, nd_counts),
                 Stmt::Local(local) => {
                     if let Some(init) = &local.init {
-                        expr_depth(&init.expr, current_depth, max_depth);
+                        expr_depth(&init.expr, current_depth, deepest, nd_counts);
                     }
                 }
                 _ => {}
@@ -1172,12 +7884,20 @@ fn rust_nesting_depth(block: &syn::Block) -> usize {
         }
     }
 
-    fn expr_depth(expr: &Expr, current_depth: usize, max_depth: &mut usize) {
-        let new_depth = match expr {
-            Expr::If(_) | Expr::Match(_) | Expr::Loop(_) | Expr::While(_) | Expr::ForLoop(_) => {
+    fn expr_depth(expr: &Expr, current_depth: usize, deepest: &mut Deepest, nd_counts: NdCounts) {
+        let construct = match expr {
+            Expr::If(_) => Some(NestingConstruct::If),
+            Expr::Match(_) => Some(NestingConstruct::Match),
+            Expr::Loop(_) | Expr::While(_) => Some(NestingConstruct::While),
+            Expr::ForLoop(_) => Some(NestingConstruct::For),
+            _ => None,
+        };
+        let new_depth = match construct {
+            Some(c) if nd_counts.counts(c) => {
                 let depth = current_depth + 1;
-                if depth > *max_depth {
-                    *max_depth = depth;
+                if depth > deepest.depth {
+                    deepest.depth = depth;
+                    deepest.line = expr.span().start().line;
                 }
                 depth
             }
@@ -1187,35 +7907,130 @@ fn rust_nesting_depth(block: &syn::Block) -> usize {
         // Recurse into sub-expressions
         match expr {
             Expr::If(expr_if) => {
-                calculate_depth(&expr_if.then_branch.stmts, new_depth, max_depth);
+                calculate_depth(&expr_if.then_branch.stmts, new_depth, deepest, nd_counts);
                 if let Some((_, else_expr)) = &expr_if.else_branch {
-                    expr_depth(else_expr, new_depth, max_depth);
+                    expr_depth(else_expr, new_depth, deepest, nd_counts);
                 }
             }
             Expr::Match(expr_match) => {
                 for arm in &expr_match.arms {
-                    expr_depth(&arm.body, new_depth, max_depth);
+                    expr_depth(&arm.body, new_depth, deepest, nd_counts);
                 }
             }
             Expr::Loop(expr_loop) => {
-                calculate_depth(&expr_loop.body.stmts, new_depth, max_depth);
+                calculate_depth(&expr_loop.body.stmts, new_depth, deepest, nd_counts);
             }
             Expr::While(expr_while) => {
-                calculate_depth(&expr_while.body.stmts, new_depth, max_depth);
+                calculate_depth(&expr_while.body.stmts, new_depth, deepest, nd_counts);
             }
             Expr::ForLoop(expr_for) => {
-                calculate_depth(&expr_for.body.stmts, new_depth, max_depth);
+                calculate_depth(&expr_for.body.stmts, new_depth, deepest, nd_counts);
             }
             Expr::Block(expr_block) => {
-                calculate_depth(&expr_block.block.stmts, new_depth, max_depth);
+                calculate_depth(&expr_block.block.stmts, new_depth, deepest, nd_counts);
             }
             _ => {}
         }
     }
 
-    let mut max_depth = 0;
-    calculate_depth(&block.stmts, 0, &mut max_depth);
-    max_depth
+    let mut deepest = Deepest { depth: 0, line: 0 };
+    calculate_depth(&block.stmts, 0, &mut deepest, nd_counts);
+    let line = (deepest.depth > 0).then_some(deepest.line);
+    (deepest.depth, line)
+}
+
+/// Calculate arrow depth for Rust (see `ts_arrow_depth` for the rules)
+fn rust_arrow_depth(block: &syn::Block) -> usize {
+    use syn::{Expr, Stmt};
+
+    fn level(stmts: &[Stmt]) -> usize {
+        let last = stmts.len().saturating_sub(1);
+        let mut construct = None;
+        for (i, stmt) in stmts.iter().enumerate() {
+            let Stmt::Expr(expr, _) = stmt else {
+                continue;
+            };
+            match expr {
+                Expr::If(_)
+                | Expr::Match(_)
+                | Expr::Loop(_)
+                | Expr::While(_)
+                | Expr::ForLoop(_) => {
+                    if construct.is_some() {
+                        return 0;
+                    }
+                    construct = Some(expr);
+                }
+                Expr::Return(_) | Expr::Break(_) | Expr::Continue(_) if i != last => return 0,
+                _ => {}
+            }
+        }
+        let body = match construct {
+            Some(Expr::If(e)) if e.else_branch.is_none() => &e.then_branch,
+            Some(Expr::Loop(e)) => &e.body,
+            Some(Expr::While(e)) => &e.body,
+            Some(Expr::ForLoop(e)) => &e.body,
+            _ => return 0,
+        };
+        1 + level(&body.stmts)
+    }
+
+    level(&block.stmts)
+}
+
+/// Count guard clauses for Rust (see `ts_guard_clauses` for the rules).
+/// `let ... else { ... }` always diverges, so it counts as a guard too.
+fn rust_guard_clauses(block: &syn::Block) -> usize {
+    use syn::{Expr, Stmt};
+
+    fn is_exit(stmt: &Stmt) -> bool {
+        matches!(
+            stmt,
+            Stmt::Expr(Expr::Return(_) | Expr::Break(_) | Expr::Continue(_), _)
+        )
+    }
+    fn is_guard(stmt: &Stmt) -> bool {
+        match stmt {
+            Stmt::Expr(Expr::If(e), _) if e.else_branch.is_none() => {
+                e.then_branch.stmts.len() == 1 && is_exit(&e.then_branch.stmts[0])
+            }
+            Stmt::Local(local) => local
+                .init
+                .as_ref()
+                .is_some_and(|init| init.diverge.is_some()),
+            _ => false,
+        }
+    }
+    fn leading(stmts: &[Stmt]) -> usize {
+        let mut count = 0;
+        for stmt in stmts {
+            match stmt {
+                _ if is_guard(stmt) => count += 1,
+                Stmt::Expr(
+                    Expr::If(_)
+                    | Expr::Match(_)
+                    | Expr::Loop(_)
+                    | Expr::While(_)
+                    | Expr::ForLoop(_),
+                    _,
+                ) => break,
+                _ => {}
+            }
+        }
+        count
+    }
+    let loop_guards: usize = block
+        .stmts
+        .iter()
+        .filter_map(|stmt| match stmt {
+            Stmt::Expr(Expr::Loop(e), _) => Some(&e.body),
+            Stmt::Expr(Expr::While(e), _) => Some(&e.body),
+            Stmt::Expr(Expr::ForLoop(e), _) => Some(&e.body),
+            _ => None,
+        })
+        .map(|body| leading(&body.stmts))
+        .sum();
+    leading(&block.stmts) + loop_guards
 }
 
 /// Extract callee names from a Rust function body.
@@ -1315,19 +8130,19 @@ fn rust_extract_callees(block: &syn::Block) -> Vec<String> {
 }
 
 /// Calculate non-structured exits for Rust function
-fn rust_non_structured_exits(block: &syn::Block) -> usize {
+fn rust_non_structured_exits(block: &syn::Block) -> NsBreakdown {
     use syn::{Expr, ExprMethodCall, Stmt};
 
-    fn count_exits(stmts: &[Stmt], count: &mut usize, is_tail: bool) {
+    fn count_exits(stmts: &[Stmt], breakdown: &mut NsBreakdown, is_tail: bool) {
         for (i, stmt) in stmts.iter().enumerate() {
             let is_last = i == stmts.len() - 1;
             match stmt {
                 Stmt::Expr(expr, _) => {
-                    expr_exits(expr, count, is_tail && is_last);
+                    expr_exits(expr, breakdown, is_tail && is_last);
                 }
                 Stmt::Local(local) => {
                     if let Some(init) = &local.init {
-                        expr_exits(&init.expr, count, false);
+                        expr_exits(&init.expr, breakdown, false);
                     }
                 }
                 _ => {}
@@ -1335,14 +8150,14 @@ fn rust_non_structured_exits(block: &syn::Block) -> usize {
         }
     }
 
-    fn expr_exits(expr: &Expr, count: &mut usize, is_tail: bool) {
+    fn expr_exits(expr: &Expr, breakdown: &mut NsBreakdown, is_tail: bool) {
         match expr {
             Expr::Return(_) if !is_tail => {
-                *count += 1;
+                breakdown.add(ExitKind::Return);
             }
             Expr::Try(_) => {
                 // ? operator counts as early return
-                *count += 1;
+                breakdown.add(ExitKind::Return);
             }
             Expr::MethodCall(ExprMethodCall { method, .. }) => {
                 // unwrap, expect, panic count as non-structured exits
@@ -1351,7 +8166,7 @@ fn rust_non_structured_exits(block: &syn::Block) -> usize {
                     method_name.as_str(),
                     "unwrap" | "expect" | "unwrap_or_else" | "unwrap_or"
                 ) {
-                    *count += 1;
+                    breakdown.add(ExitKind::Throw);
                 }
             }
             Expr::Macro(expr_macro) => {
@@ -1362,44 +8177,44 @@ fn rust_non_structured_exits(block: &syn::Block) -> usize {
                         macro_name.as_str(),
                         "panic" | "unreachable" | "unimplemented" | "todo"
                     ) {
-                        *count += 1;
+                        breakdown.add(ExitKind::Throw);
                     }
                 }
             }
             Expr::If(expr_if) => {
-                expr_exits(&expr_if.cond, count, false);
-                count_exits(&expr_if.then_branch.stmts, count, false);
+                expr_exits(&expr_if.cond, breakdown, false);
+                count_exits(&expr_if.then_branch.stmts, breakdown, false);
                 if let Some((_, else_expr)) = &expr_if.else_branch {
-                    expr_exits(else_expr, count, false);
+                    expr_exits(else_expr, breakdown, false);
                 }
             }
             Expr::Match(expr_match) => {
-                expr_exits(&expr_match.expr, count, false);
+                expr_exits(&expr_match.expr, breakdown, false);
                 for arm in &expr_match.arms {
-                    expr_exits(&arm.body, count, false);
+                    expr_exits(&arm.body, breakdown, false);
                 }
             }
             Expr::Loop(expr_loop) => {
-                count_exits(&expr_loop.body.stmts, count, false);
+                count_exits(&expr_loop.body.stmts, breakdown, false);
             }
             Expr::While(expr_while) => {
-                expr_exits(&expr_while.cond, count, false);
-                count_exits(&expr_while.body.stmts, count, false);
+                expr_exits(&expr_while.cond, breakdown, false);
+                count_exits(&expr_while.body.stmts, breakdown, false);
             }
             Expr::ForLoop(expr_for) => {
-                expr_exits(&expr_for.expr, count, false);
-                count_exits(&expr_for.body.stmts, count, false);
+                expr_exits(&expr_for.expr, breakdown, false);
+                count_exits(&expr_for.body.stmts, breakdown, false);
             }
             Expr::Block(expr_block) => {
-                count_exits(&expr_block.block.stmts, count, is_tail);
+                count_exits(&expr_block.block.stmts, breakdown, is_tail);
             }
             _ => {}
         }
     }
 
-    let mut count = 0;
-    count_exits(&block.stmts, &mut count, true);
-    count
+    let mut breakdown = NsBreakdown::default();
+    count_exits(&block.stmts, &mut breakdown, true);
+    breakdown
 }
 
 /// Count CC extras for Rust (match arms, boolean operators)
@@ -1456,16 +8271,668 @@ fn rust_count_cc_extras(block: &syn::Block) -> usize {
                 expr_extras(&expr_for.expr, count);
                 count_extras(&expr_for.body.stmts, count);
             }
-            Expr::Block(expr_block) => {
-                count_extras(&expr_block.block.stmts, count);
+            Expr::Block(expr_block) => {
+                count_extras(&expr_block.block.stmts, count);
+            }
+            _ => {}
+        }
+    }
+
+    let mut count = 0;
+    count_extras(&block.stmts, &mut count);
+    count
+}
+
+/// Tally Rust decision points: `if`, loops, match arms, `&&` / `||`
+fn rust_cc_breakdown(block: &syn::Block, first_line: u32) -> CcTally {
+    use syn::spanned::Spanned;
+    use syn::{BinOp, Expr, Stmt};
+
+    /// The tally so far and the file line the item's source starts on
+    struct Walk {
+        tally: CcTally,
+        first_line: u32,
+    }
+
+    impl Walk {
+        fn add(&mut self, kind: DecisionKind, node: &impl Spanned) {
+            let line = self.first_line + node.span().start().line as u32 - 1;
+            self.tally.add(kind, NestPosition::Line(line));
+        }
+    }
+
+    fn stmts_breakdown(stmts: &[Stmt], walk: &mut Walk) {
+        for stmt in stmts {
+            match stmt {
+                Stmt::Expr(expr, _) => expr_breakdown(expr, walk),
+                Stmt::Local(local) => {
+                    if let Some(init) = &local.init {
+                        expr_breakdown(&init.expr, walk);
+                    }
+                }
+                _ => {}
+            }
+        }
+    }
+
+    fn expr_breakdown(expr: &Expr, walk: &mut Walk) {
+        match expr {
+            Expr::Match(expr_match) => {
+                for arm in &expr_match.arms {
+                    walk.add(DecisionKind::MatchArm, &arm.pat);
+                }
+                expr_breakdown(&expr_match.expr, walk);
+                for arm in &expr_match.arms {
+                    expr_breakdown(&arm.body, walk);
+                }
+            }
+            Expr::Binary(expr_binary) => {
+                match expr_binary.op {
+                    BinOp::And(_) => walk.add(DecisionKind::And, &expr_binary.op),
+                    BinOp::Or(_) => walk.add(DecisionKind::Or, &expr_binary.op),
+                    _ => {}
+                }
+                expr_breakdown(&expr_binary.left, walk);
+                expr_breakdown(&expr_binary.right, walk);
+            }
+            Expr::If(expr_if) => {
+                walk.add(DecisionKind::If, expr);
+                expr_breakdown(&expr_if.cond, walk);
+                stmts_breakdown(&expr_if.then_branch.stmts, walk);
+                if let Some((_, else_expr)) = &expr_if.else_branch {
+                    expr_breakdown(else_expr, walk);
+                }
+            }
+            Expr::Loop(expr_loop) => {
+                walk.add(DecisionKind::Loop, expr);
+                stmts_breakdown(&expr_loop.body.stmts, walk);
+            }
+            Expr::While(expr_while) => {
+                walk.add(DecisionKind::Loop, expr);
+                expr_breakdown(&expr_while.cond, walk);
+                stmts_breakdown(&expr_while.body.stmts, walk);
+            }
+            Expr::ForLoop(expr_for) => {
+                walk.add(DecisionKind::Loop, expr);
+                expr_breakdown(&expr_for.expr, walk);
+                stmts_breakdown(&expr_for.body.stmts, walk);
+            }
+            Expr::Block(expr_block) => {
+                stmts_breakdown(&expr_block.block.stmts, walk);
+            }
+            _ => {}
+        }
+    }
+
+    let mut walk = Walk {
+        tally: CcTally::default(),
+        first_line,
+    };
+    stmts_breakdown(&block.stmts, &mut walk);
+    walk.tally
+}
+
+/// Largest number of `&&` / `||` operators in one Rust boolean expression,
+/// looking through parentheses and `!` (see `ts_max_condition_ops`). Match
+/// guards count as expressions of their own.
+fn rust_max_condition_ops(block: &syn::Block) -> usize {
+    use syn::{BinOp, Expr, Stmt};
+
+    fn boolean_ops(expr: &Expr) -> usize {
+        match expr {
+            Expr::Binary(expr_binary) => {
+                let own = usize::from(matches!(expr_binary.op, BinOp::And(_) | BinOp::Or(_)));
+                own + boolean_ops(&expr_binary.left) + boolean_ops(&expr_binary.right)
+            }
+            Expr::Paren(expr_paren) => boolean_ops(&expr_paren.expr),
+            Expr::Unary(expr_unary) => boolean_ops(&expr_unary.expr),
+            _ => 0,
+        }
+    }
+
+    fn stmts_max(stmts: &[Stmt], max: &mut usize) {
+        for stmt in stmts {
+            match stmt {
+                Stmt::Expr(expr, _) => expr_max(expr, max),
+                Stmt::Local(local) => {
+                    if let Some(init) = &local.init {
+                        expr_max(&init.expr, max);
+                    }
+                }
+                _ => {}
+            }
+        }
+    }
+
+    fn expr_max(expr: &Expr, max: &mut usize) {
+        match expr {
+            Expr::Binary(_) | Expr::Paren(_) | Expr::Unary(_) => {
+                *max = (*max).max(boolean_ops(expr));
+            }
+            Expr::Match(expr_match) => {
+                expr_max(&expr_match.expr, max);
+                for arm in &expr_match.arms {
+                    if let Some((_, guard)) = &arm.guard {
+                        expr_max(guard, max);
+                    }
+                    expr_max(&arm.body, max);
+                }
+            }
+            Expr::If(expr_if) => {
+                expr_max(&expr_if.cond, max);
+                stmts_max(&expr_if.then_branch.stmts, max);
+                if let Some((_, else_expr)) = &expr_if.else_branch {
+                    expr_max(else_expr, max);
+                }
+            }
+            Expr::Loop(expr_loop) => {
+                stmts_max(&expr_loop.body.stmts, max);
+            }
+            Expr::While(expr_while) => {
+                expr_max(&expr_while.cond, max);
+                stmts_max(&expr_while.body.stmts, max);
+            }
+            Expr::ForLoop(expr_for) => {
+                expr_max(&expr_for.expr, max);
+                stmts_max(&expr_for.body.stmts, max);
+            }
+            Expr::Block(expr_block) => {
+                stmts_max(&expr_block.block.stmts, max);
+            }
+            Expr::Return(expr_return) => {
+                if let Some(value) = &expr_return.expr {
+                    expr_max(value, max);
+                }
+            }
+            _ => {}
+        }
+    }
+
+    let mut max = 0;
+    stmts_max(&block.stmts, &mut max);
+    max
+}
+
+/// Calculate cognitive complexity for Rust (see `ts_cognitive_complexity` for
+/// the rules); a `match` costs once, not per arm
+fn rust_cognitive_complexity(block: &syn::Block) -> usize {
+    use syn::{BinOp, Expr, Stmt};
+
+    fn stmts_cognitive(stmts: &[Stmt], nesting: usize, total: &mut usize) {
+        for stmt in stmts {
+            match stmt {
+                Stmt::Expr(expr, _) => expr_cognitive(expr, nesting, None, total),
+                Stmt::Local(local) => {
+                    if let Some(init) = &local.init {
+                        expr_cognitive(&init.expr, nesting, None, total);
+                    }
+                }
+                _ => {}
+            }
+        }
+    }
+
+    fn if_chain(expr_if: &syn::ExprIf, nesting: usize, else_if: bool, total: &mut usize) {
+        *total += if else_if { 1 } else { 1 + nesting };
+        expr_cognitive(&expr_if.cond, nesting, None, total);
+        stmts_cognitive(&expr_if.then_branch.stmts, nesting + 1, total);
+        match expr_if.else_branch.as_ref().map(|(_, e)| e.as_ref()) {
+            Some(Expr::If(else_if)) => if_chain(else_if, nesting, true, total),
+            Some(else_expr) => {
+                *total += 1;
+                expr_cognitive(else_expr, nesting + 1, None, total);
+            }
+            None => {}
+        }
+    }
+
+    fn expr_cognitive(
+        expr: &Expr,
+        nesting: usize,
+        logical_parent: Option<&BinOp>,
+        total: &mut usize,
+    ) {
+        match expr {
+            Expr::If(expr_if) => if_chain(expr_if, nesting, false, total),
+            Expr::Match(expr_match) => {
+                *total += 1 + nesting;
+                expr_cognitive(&expr_match.expr, nesting, None, total);
+                for arm in &expr_match.arms {
+                    expr_cognitive(&arm.body, nesting + 1, None, total);
+                }
+            }
+            Expr::Loop(expr_loop) => {
+                *total += 1 + nesting;
+                stmts_cognitive(&expr_loop.body.stmts, nesting + 1, total);
+            }
+            Expr::While(expr_while) => {
+                *total += 1 + nesting;
+                expr_cognitive(&expr_while.cond, nesting, None, total);
+                stmts_cognitive(&expr_while.body.stmts, nesting + 1, total);
+            }
+            Expr::ForLoop(expr_for) => {
+                *total += 1 + nesting;
+                expr_cognitive(&expr_for.expr, nesting, None, total);
+                stmts_cognitive(&expr_for.body.stmts, nesting + 1, total);
+            }
+            Expr::Closure(closure) => {
+                expr_cognitive(&closure.body, nesting + 1, None, total);
+            }
+            Expr::Block(expr_block) => {
+                stmts_cognitive(&expr_block.block.stmts, nesting, total);
+            }
+            Expr::Binary(expr_binary) => {
+                let op = &expr_binary.op;
+                let logical = matches!(op, BinOp::And(_) | BinOp::Or(_));
+                if logical && logical_parent != Some(op) {
+                    *total += 1;
+                }
+                let parent = logical.then_some(op);
+                expr_cognitive(&expr_binary.left, nesting, parent, total);
+                expr_cognitive(&expr_binary.right, nesting, parent, total);
+            }
+            // Parentheses do not end an operator sequence
+            Expr::Paren(expr_paren) => {
+                expr_cognitive(&expr_paren.expr, nesting, logical_parent, total);
+            }
+            Expr::Unary(expr_unary) => {
+                expr_cognitive(&expr_unary.expr, nesting, None, total);
+            }
+            Expr::Break(expr_break) if expr_break.label.is_some() => *total += 1,
+            Expr::Continue(expr_continue) if expr_continue.label.is_some() => *total += 1,
+            _ => {}
+        }
+    }
+
+    let mut total = 0;
+    stmts_cognitive(&block.stmts, 0, &mut total);
+    total
+}
+
+// ========================================
+// SQL Metrics Extraction
+// ========================================
+
+/// Words followed by `(` that are not routine calls
+const SQL_NON_CALL_WORDS: &[&str] = &[
+    "ALL",
+    "AND",
+    "ANY",
+    "ARRAY",
+    "AS",
+    "BETWEEN",
+    "BINARY",
+    "BIT",
+    "BY",
+    "CASE",
+    "CHAR",
+    "CHARACTER",
+    "CHECK",
+    "CONFLICT",
+    "DATETIME2",
+    "DATETIMEOFFSET",
+    "DECIMAL",
+    "DEFAULT",
+    "ELSE",
+    "ELSIF",
+    "EXCEPT",
+    "EXEC",
+    "EXECUTE",
+    "EXISTS",
+    "FILTER",
+    "FLOAT",
+    "FROM",
+    "HAVING",
+    "IF",
+    "IN",
+    "INTERSECT",
+    "INTERVAL",
+    "INTO",
+    "IS",
+    "JOIN",
+    "KEY",
+    "LIKE",
+    "LIMIT",
+    "NCHAR",
+    "NOT",
+    "NUMERIC",
+    "NVARCHAR",
+    "ON",
+    "OR",
+    "OVER",
+    "QUERY",
+    "RAISERROR",
+    "RETURN",
+    "RETURNS",
+    "ROW",
+    "SELECT",
+    "SET",
+    "SOME",
+    "TABLE",
+    "THEN",
+    "TIME",
+    "TIMESTAMP",
+    "UNION",
+    "UNIQUE",
+    "USING",
+    "VALUES",
+    "VARBINARY",
+    "VARCHAR",
+    "VARYING",
+    "WHEN",
+    "WHERE",
+    "WHILE",
+    "WITH",
+    "WITHIN",
+];
+
+/// Words after which `name (` is a table, column list, or CTE, not a call
+const SQL_NON_CALL_PREFIXES: &[&str] = &[
+    "FUNCTION",
+    "INDEX",
+    "INSERT",
+    "INTO",
+    "PROCEDURE",
+    "REFERENCES",
+    "TABLE",
+    "TYPE",
+    "VIEW",
+    "WITH",
+];
+
+/// Words before a DDL `IF [NOT] EXISTS`, which is not a branch
+const SQL_DDL_IF_PREFIXES: &[&str] = &[
+    "COLUMN",
+    "CONCURRENTLY",
+    "CONSTRAINT",
+    "DATABASE",
+    "DOMAIN",
+    "EXTENSION",
+    "FUNCTION",
+    "INDEX",
+    "PROCEDURE",
+    "ROLE",
+    "SCHEMA",
+    "SEQUENCE",
+    "TABLE",
+    "TRIGGER",
+    "TYPE",
+    "VIEW",
+];
+
+/// T-SQL statement keywords: after `IF cond` / `WHILE cond` / `ELSE`, one of
+/// these (rather than `BEGIN`) starts a single-statement body
+const TSQL_STATEMENT_WORDS: &[&str] = &[
+    "BREAK",
+    "COMMIT",
+    "CONTINUE",
+    "DECLARE",
+    "DELETE",
+    "EXEC",
+    "EXECUTE",
+    "GOTO",
+    "INSERT",
+    "MERGE",
+    "PRINT",
+    "RAISERROR",
+    "RETURN",
+    "ROLLBACK",
+    "SELECT",
+    "SET",
+    "THROW",
+    "TRUNCATE",
+    "UPDATE",
+    "WAITFOR",
+];
+
+/// Extract metrics for a SQL function or procedure
+///
+/// The body is tokenized, not parsed, so CC is counted from decision keywords
+/// instead of the CFG: 1 + `IF`/`ELSIF` + each `WHEN` (`CASE` branches,
+/// `EXCEPTION WHEN` handlers, `EXIT WHEN`) + `LOOP`/`WHILE`/`FOR` +
+/// `BEGIN CATCH` + `AND`/`OR`. Arrow depth is not computed for SQL.
+fn extract_sql_metrics(function: &FunctionNode) -> RawMetrics {
+    let (source, dialect) = function.body.as_sql();
+    let tokens = crate::language::sql::lexer::tokenize(source);
+    let callee_names = sql_extract_callees(source, &tokens, dialect);
+    let loc = function
+        .span
+        .end_line
+        .saturating_sub(function.span.start_line)
+        + 1;
+
+    let ns_breakdown = sql_non_structured_exits(&tokens, dialect);
+    RawMetrics {
+        cc: 1 + sql_count_decisions(&tokens, dialect),
+        cognitive: 0,
+        nd: sql_nesting_depth(&tokens, dialect),
+        nd_position: None,
+        fo: callee_names.len(),
+        ns: ns_breakdown.total(),
+        ns_breakdown,
+        loc: loc as usize,
+        callee_names,
+        arrow_depth: 0,
+        signature_complexity: 0,
+        guard_clauses: 0,
+        max_condition_ops: 0,
+        cc_breakdown: None,
+        decisions: vec![],
+        switches: vec![],
+        await_in_loop: 0,
+    }
+}
+
+/// The upper-cased word at `i`, or "" when out of range or not a word
+fn sql_word_at(tokens: &[SqlToken], i: Option<usize>) -> &str {
+    i.and_then(|i| tokens.get(i))
+        .and_then(SqlToken::word)
+        .unwrap_or("")
+}
+
+/// `IF` that branches, as opposed to `END IF` or DDL `DROP TABLE IF EXISTS`
+fn sql_is_branch_if(tokens: &[SqlToken], i: usize) -> bool {
+    let prev = sql_word_at(tokens, i.checked_sub(1));
+    tokens[i].is_word("IF") && prev != "END" && !SQL_DDL_IF_PREFIXES.contains(&prev)
+}
+
+/// PL/pgSQL `FOR`/`FOREACH` loop, as opposed to `SELECT ... FOR UPDATE` or
+/// `OPEN cursor FOR query`
+fn sql_is_loop_for(tokens: &[SqlToken], i: usize) -> bool {
+    let next = sql_word_at(tokens, Some(i + 1));
+    !matches!(next, "UPDATE" | "SHARE" | "NO" | "KEY")
+        && sql_word_at(tokens, i.checked_sub(2)) != "OPEN"
+}
+
+/// Count decision points (CC - 1) in a SQL body
+fn sql_count_decisions(tokens: &[SqlToken], dialect: SqlDialect) -> usize {
+    let mut count = 0;
+    // `FOR ... LOOP` and `WHILE ... LOOP` are one loop, not two
+    let mut loop_header_pending = false;
+    // The `AND` of `BETWEEN a AND b` is not a boolean operator
+    let mut between_pending = false;
+
+    let postgres = dialect == SqlDialect::Postgres;
+    for (i, token) in tokens.iter().enumerate() {
+        let Some(word) = token.word() else {
+            continue;
+        };
+        match word {
+            "IF" if sql_is_branch_if(tokens, i) => count += 1,
+            "ELSIF" | "ELSEIF" if postgres => count += 1,
+            "WHEN" => count += 1,
+            "WHILE" => {
+                count += 1;
+                loop_header_pending = postgres;
+            }
+            "FOR" | "FOREACH" if postgres && sql_is_loop_for(tokens, i) => {
+                count += 1;
+                loop_header_pending = true;
+            }
+            "LOOP" if postgres && sql_word_at(tokens, i.checked_sub(1)) != "END" => {
+                if !std::mem::take(&mut loop_header_pending) {
+                    count += 1;
+                }
+            }
+            "CATCH" if !postgres && sql_word_at(tokens, i.checked_sub(1)) == "BEGIN" => count += 1,
+            "BETWEEN" => between_pending = true,
+            "AND" if between_pending => between_pending = false,
+            "AND" | "OR" => count += 1,
+            _ => {}
+        }
+    }
+
+    count
+}
+
+/// An open construct while scanning a SQL body for nesting depth
+#[derive(Clone, Copy, PartialEq, Eq)]
+enum SqlFrame {
+    /// `BEGIN ... END` that does not nest (routine body, `BEGIN TRY`)
+    Block,
+    /// `IF`, a loop, or a T-SQL `BEGIN` that is the body of one
+    Control,
+    /// `CASE ... END`
+    Case,
+}
+
+/// Maximum nesting of `IF`, loops, and `CASE` in a SQL body
+///
+/// PL/pgSQL closes every structure with `END [IF | LOOP | CASE]`. T-SQL
+/// control statements take either a `BEGIN ... END` block, which nests until
+/// its `END`, or a single statement, which nests one level for that statement.
+fn sql_nesting_depth(tokens: &[SqlToken], dialect: SqlDialect) -> usize {
+    let mut stack: Vec<SqlFrame> = Vec::new();
+    let mut max_depth = 0;
+    // T-SQL: an `IF`/`WHILE`/`ELSE` waiting for its body
+    let mut control_pending = false;
+    let mut parens = 0usize;
+
+    for (i, token) in tokens.iter().enumerate() {
+        if token.is_symbol('(') {
+            parens += 1;
+        } else if token.is_symbol(')') {
+            parens = parens.saturating_sub(1);
+        }
+        let Some(word) = token.word() else {
+            continue;
+        };
+        let depth = stack.iter().filter(|&&f| f != SqlFrame::Block).count();
+        let after_end = sql_word_at(tokens, i.checked_sub(1)) == "END";
+
+        let push = match (dialect, word) {
+            (_, "END") => {
+                stack.pop();
+                None
+            }
+            (_, "CASE") if !after_end => Some(SqlFrame::Case),
+            (SqlDialect::Postgres, "BEGIN") => Some(SqlFrame::Block),
+            (SqlDialect::Postgres, "IF") if sql_is_branch_if(tokens, i) => Some(SqlFrame::Control),
+            (SqlDialect::Postgres, "LOOP") if !after_end => Some(SqlFrame::Control),
+            (SqlDialect::Tsql, "BEGIN") => {
+                let next = sql_word_at(tokens, Some(i + 1));
+                (!matches!(next, "TRAN" | "TRANSACTION" | "DISTRIBUTED" | "DIALOG")).then(|| {
+                    if std::mem::take(&mut control_pending) {
+                        SqlFrame::Control
+                    } else {
+                        SqlFrame::Block
+                    }
+                })
+            }
+            (SqlDialect::Tsql, "IF") if sql_is_branch_if(tokens, i) => {
+                control_pending = true;
+                None
+            }
+            // `ELSE` inside `CASE ... END` is an expression, not a statement
+            (SqlDialect::Tsql, "WHILE" | "ELSE") if stack.last() != Some(&SqlFrame::Case) => {
+                control_pending = true;
+                None
             }
-            _ => {}
+            (SqlDialect::Tsql, w)
+                if control_pending && parens == 0 && TSQL_STATEMENT_WORDS.contains(&w) =>
+            {
+                control_pending = false;
+                max_depth = max_depth.max(depth + 1);
+                None
+            }
+            _ => None,
+        };
+
+        if let Some(frame) = push {
+            stack.push(frame);
+            max_depth = max_depth.max(depth + usize::from(frame != SqlFrame::Block));
         }
     }
 
-    let mut count = 0;
-    count_extras(&block.stmts, &mut count);
-    count
+    max_depth
+}
+
+/// Count non-structured exits in a SQL body
+///
+/// PL/pgSQL: `RETURN` (not `RETURN NEXT`/`RETURN QUERY`, which append rows),
+/// `RAISE` at exception level, `EXIT`, `CONTINUE`. T-SQL: `RETURN`, `THROW`,
+/// `RAISERROR`, `BREAK`, `CONTINUE`, `GOTO`.
+fn sql_non_structured_exits(tokens: &[SqlToken], dialect: SqlDialect) -> NsBreakdown {
+    let mut breakdown = NsBreakdown::default();
+    for (i, token) in tokens.iter().enumerate() {
+        let next = sql_word_at(tokens, Some(i + 1));
+        let kind = match (dialect, token.word().unwrap_or("")) {
+            (_, "RETURN") if !matches!(next, "NEXT" | "QUERY") => ExitKind::Return,
+            (_, "CONTINUE") if !matches!(next, "NEXT" | "QUERY") => ExitKind::Continue,
+            (SqlDialect::Postgres, "RAISE")
+                if !matches!(next, "NOTICE" | "DEBUG" | "LOG" | "INFO" | "WARNING") =>
+            {
+                ExitKind::Throw
+            }
+            (SqlDialect::Postgres, "EXIT") | (SqlDialect::Tsql, "BREAK") => ExitKind::Break,
+            (SqlDialect::Tsql, "THROW" | "RAISERROR") => ExitKind::Throw,
+            (SqlDialect::Tsql, "GOTO") => ExitKind::Goto,
+            _ => continue,
+        };
+        breakdown.add(kind);
+    }
+    breakdown
+}
+
+/// Extract callee names from a SQL body: `name(...)` calls (including
+/// `PERFORM` and `CALL` targets) and, in T-SQL, `EXEC [@rc =] name` targets.
+/// Names are returned as written, schema qualification included.
+fn sql_extract_callees(source: &str, tokens: &[SqlToken], dialect: SqlDialect) -> Vec<String> {
+    use crate::language::sql::parser::qualified_name;
+    use std::collections::HashSet;
+
+    let is_exec = |i: Option<usize>| matches!(sql_word_at(tokens, i), "EXEC" | "EXECUTE");
+    let mut calls = HashSet::new();
+    let mut i = 0;
+    while i < tokens.len() {
+        let token = &tokens[i];
+        let is_name = matches!(
+            token.kind,
+            SqlTokenKind::Word(_) | SqlTokenKind::QuotedIdent(_)
+        ) && !token.word().is_some_and(|w| w.starts_with('@'))
+            && !(i > 0 && tokens[i - 1].is_symbol('.'));
+        if !is_name {
+            i += 1;
+            continue;
+        }
+
+        let (name, next) = qualified_name(source, tokens, i);
+        let last_segment = name.rsplit('.').next().unwrap_or("").to_ascii_uppercase();
+        let exec_target = dialect == SqlDialect::Tsql
+            && (is_exec(i.checked_sub(1))
+                || (i >= 3 && tokens[i - 1].is_symbol('=') && is_exec(Some(i - 3))));
+        let call = tokens.get(next).is_some_and(|t| t.is_symbol('('))
+            && !SQL_NON_CALL_WORDS.contains(&last_segment.as_str())
+            && !SQL_NON_CALL_PREFIXES.contains(&sql_word_at(tokens, i.checked_sub(1)));
+        if exec_target || call {
+            calls.insert(name);
+        }
+        i = next;
+    }
+
+    let mut result: Vec<String> = calls.into_iter().collect();
+    result.sort();
+    result
 }
 
 #[cfg(test)]
@@ -1538,11 +9005,16 @@ mod tests {
                 local_index: 0,
             },
             name: Some("test".to_string()),
+            owner: None,
             span: SourceSpan::new(0, source.len(), 1, 1, 0),
             body: FunctionBody::Rust {
                 source: source.to_string(),
             },
             suppression_reason: None,
+            signature_complexity: 0,
+            params: 0,
+            is_public: false,
+            is_async: false,
         };
         let cfg = RustCfgBuilder.build(&func);
         (func, cfg)
@@ -1626,6 +9098,71 @@ func withDefer() {
         );
     }
 
+    #[test]
+    fn test_extract_go_ns_breakdown() {
+        let source = r#"package main
+func run(jobs []Job) {
+    defer cleanup()
+    for _, j := range jobs {
+        if j.Bad() {
+            panic("bad job")
+        }
+        if j.Done() {
+            return
+        }
+    }
+    log.Fatal("no job finished")
+}
+"#;
+        let (func, cfg) = go_function_and_cfg(source);
+        let m = extract_metrics(&func, &cfg);
+        let b = &m.ns_breakdown;
+        assert_eq!(b.get(ExitKind::Return), 1);
+        assert_eq!(b.get(ExitKind::Defer), 1);
+        // panic() and log.Fatal
+        assert_eq!(b.get(ExitKind::Throw), 2);
+        assert_eq!(b.get(ExitKind::Break), 0);
+        assert_eq!(m.ns, 4);
+        assert_eq!(b.total(), m.ns);
+    }
+
+    #[test]
+    fn test_extract_ecmascript_ns_breakdown() {
+        let source = r#"function scan(items: number[]): number {
+    for (const item of items) {
+        if (item < 0) throw new Error("negative");
+        if (item === 0) continue;
+        if (item > 100) break;
+        if (item === 42) return item;
+    }
+    return -1;
+}
+"#;
+        let (func, cfg) = ecmascript_function_and_cfg(source);
+        let m = extract_metrics(&func, &cfg);
+        let b = &m.ns_breakdown;
+        // The final `return -1` is not an early exit
+        assert_eq!(b.get(ExitKind::Return), 1);
+        assert_eq!(b.get(ExitKind::Throw), 1);
+        assert_eq!(b.get(ExitKind::Break), 1);
+        assert_eq!(b.get(ExitKind::Continue), 1);
+        assert_eq!(m.ns, 4);
+        assert_eq!(b.total(), m.ns);
+        assert_eq!(
+            serde_json::to_string(b).unwrap(),
+            r#"{"break":1,"continue":1,"return":1,"throw":1}"#
+        );
+    }
+
+    #[test]
+    fn test_ns_breakdown_empty_without_exits() {
+        let (func, cfg) = ecmascript_function_and_cfg("function f(a: number) { log(a); }\n");
+        let m = extract_metrics(&func, &cfg);
+        assert_eq!(m.ns, 0);
+        assert!(m.ns_breakdown.is_empty());
+        assert_eq!(serde_json::to_string(&m.ns_breakdown).unwrap(), "{}");
+    }
+
     #[test]
     fn test_extract_go_fallback_on_bad_source() {
         // A FunctionNode whose body source is empty/unparseable yields the fallback metrics.
@@ -1637,12 +9174,17 @@ func withDefer() {
                 local_index: 0,
             },
             name: Some("bad".to_string()),
+            owner: None,
             span: SourceSpan::new(0, 0, 1, 1, 0),
             body: FunctionBody::Go {
                 body_node: 0,
                 source: String::new(),
             },
             suppression_reason: None,
+            signature_complexity: 0,
+            params: 0,
+            is_public: false,
+            is_async: false,
         };
         let cfg = crate::cfg::Cfg::new();
         let m = extract_metrics(&func, &cfg);
@@ -1717,12 +9259,17 @@ func withDefer() {
                 local_index: 0,
             },
             name: Some("bad".to_string()),
+            owner: None,
             span: SourceSpan::new(0, 0, 1, 1, 0),
             body: FunctionBody::Java {
                 body_node: 0,
                 source: String::new(),
             },
             suppression_reason: None,
+            signature_complexity: 0,
+            params: 0,
+            is_public: false,
+            is_async: false,
         };
         let cfg = crate::cfg::Cfg::new();
         let m = extract_metrics(&func, &cfg);
@@ -1796,12 +9343,17 @@ func withDefer() {
                 local_index: 0,
             },
             name: Some("bad".to_string()),
+            owner: None,
             span: SourceSpan::new(0, 0, 1, 1, 0),
             body: FunctionBody::Python {
                 body_node: 0,
                 source: String::new(),
             },
             suppression_reason: None,
+            signature_complexity: 0,
+            params: 0,
+            is_public: false,
+            is_async: false,
         };
         let cfg = crate::cfg::Cfg::new();
         let m = extract_metrics(&func, &cfg);
@@ -1863,6 +9415,86 @@ func withDefer() {
         );
     }
 
+    #[test]
+    fn test_await_in_loop_ecmascript() {
+        let source = r#"async function sync(ids: string[], queue: any) {
+  const first = await load();
+  for (const id of await listIds(ids)) {
+    await save(id);
+  }
+  while (await queue.hasNext()) {
+    const item = await queue.next();
+    items.forEach(async (x) => await push(x));
+  }
+  for (let i = await start(); i < 3; i++) {
+    await tick(i);
+  }
+  return first;
+}"#;
+        let (func, cfg) = ecmascript_function_and_cfg(source);
+        let m = extract_metrics(&func, &cfg);
+        // save, hasNext, next, tick; not load, listIds, start, or the arrow's push
+        assert_eq!(m.await_in_loop, 4);
+        assert!(func.is_async);
+    }
+
+    #[test]
+    fn test_await_in_loop_ecmascript_for_await_and_promise_all() {
+        let source = r#"async function drain(stream: any, ids: string[]) {
+  for await (const chunk of stream) {
+    consume(chunk);
+  }
+  return Promise.all(ids.map((id) => fetchOne(id)));
+}"#;
+        let (func, cfg) = ecmascript_function_and_cfg(source);
+        let m = extract_metrics(&func, &cfg);
+        assert_eq!(m.await_in_loop, 0);
+    }
+
+    #[test]
+    fn test_rejection_handlers_count_as_catch() {
+        let chained = r#"function load(url: string) {
+  return fetch(url)
+    .then((res) => res.json())
+    .then(render, showError)
+    .catch((err) => log(err));
+}"#;
+        let (func, cfg) = ecmascript_function_and_cfg(chained);
+        let m = extract_metrics(&func, &cfg);
+        let breakdown = m.cc_breakdown.unwrap();
+        // `.then(render, showError)` and `.catch(...)`; the one-argument
+        // `.then` is not a branch
+        assert_eq!(breakdown.get(DecisionKind::Catch), 2);
+
+        let awaited = r#"async function load(url: string) {
+  try {
+    return await fetch(url);
+  } catch (err) {
+    log(err);
+  }
+}"#;
+        let (func, cfg) = ecmascript_function_and_cfg(awaited);
+        let m = extract_metrics(&func, &cfg);
+        assert_eq!(m.cc_breakdown.unwrap().get(DecisionKind::Catch), 1);
+        assert_eq!(m.await_in_loop, 0);
+    }
+
+    #[test]
+    fn test_rejection_handler_cc_matches_breakdown() {
+        let source = r#"function save(item: any) {
+  return api.put(item).catch(retry);
+}"#;
+        let (func, cfg) = ecmascript_function_and_cfg(source);
+        let m = extract_metrics(&func, &cfg);
+        let plain = r#"function save(item: any) {
+  return api.put(item).finally(retry);
+}"#;
+        let (plain_func, plain_cfg) = ecmascript_function_and_cfg(plain);
+        let plain_m = extract_metrics(&plain_func, &plain_cfg);
+        assert_eq!(m.cc, plain_m.cc + 1);
+        assert!(!func.is_async);
+    }
+
     // ── Rust ────────────────────────────────────────────────────────────────
 
     #[test]
@@ -1927,4 +9559,581 @@ func withDefer() {
         assert_eq!(m.fo, 2, "deduplicated: foo+bar = 2");
         assert_eq!(m.callee_names, vec!["bar", "foo"], "sorted callee_names");
     }
+
+    // ── Arrow depth ────────────────────────────────────────────────────────
+
+    #[test]
+    fn test_arrow_depth_go_nested_chain() {
+        let source = r#"package main
+func nested(x, y, z int) int {
+    if x > 0 {
+        if y > 0 && z > 0 {
+            if x > 10 || y > 10 {
+                return 1
+            }
+        }
+    }
+    return 0
+}
+"#;
+        let (func, cfg) = go_function_and_cfg(source);
+        let m = extract_metrics(&func, &cfg);
+        assert_eq!(m.arrow_depth, 3);
+        assert_eq!(m.nd, 3);
+    }
+
+    #[test]
+    fn test_arrow_depth_go_guard_clauses_flatten() {
+        // Same peak ND, but the early return breaks the chain at the top level
+        let source = r#"package main
+func guarded(x, y, z int) int {
+    if x <= 0 {
+        return 0
+    }
+    for i := 0; i < x; i++ {
+        if y > 0 {
+            if z > 0 {
+                return i
+            }
+        }
+    }
+    return 0
+}
+"#;
+        let (func, cfg) = go_function_and_cfg(source);
+        let m = extract_metrics(&func, &cfg);
+        assert_eq!(m.arrow_depth, 0);
+        assert_eq!(m.nd, 3);
+    }
+
+    #[test]
+    fn test_arrow_depth_stops_at_else() {
+        let source = r#"function f(a: any, b: any) {
+  let r = 0;
+  for (const x of a) {
+    if (b) {
+      r += x;
+    } else {
+      r -= x;
+    }
+  }
+  return r;
+}"#;
+        let (func, cfg) = ecmascript_function_and_cfg(source);
+        let m = extract_metrics(&func, &cfg);
+        // for counts, the if/else splits the body rather than burying it
+        assert_eq!(m.arrow_depth, 1);
+    }
+
+    #[test]
+    fn test_arrow_depth_ecmascript_plain_statements_allowed() {
+        let source = r#"function f(a: any, b: any, c: any) {
+  let result = "";
+  if (a) {
+    if (b) {
+      if (c) result = "deep";
+    }
+  }
+  return result;
+}"#;
+        let (func, cfg) = ecmascript_function_and_cfg(source);
+        let m = extract_metrics(&func, &cfg);
+        assert_eq!(m.arrow_depth, 3);
+    }
+
+    #[test]
+    fn test_arrow_depth_python_sibling_constructs() {
+        let source = r#"def f(rows):
+    total = 0
+    for row in rows:
+        for col in row:
+            if col == 0:
+                continue
+            if col > 100:
+                break
+            total += col
+    return total
+"#;
+        let (func, cfg) = python_function_and_cfg(source);
+        let m = extract_metrics(&func, &cfg);
+        // Two sibling ifs inside the inner loop end the chain after two loops
+        assert_eq!(m.arrow_depth, 2);
+    }
+
+    #[test]
+    fn test_arrow_depth_rust_nested_loops() {
+        let source = r#"fn f(m: &[&[i32]]) -> i32 {
+    let mut sum = 0;
+    for row in m {
+        for &x in *row {
+            if x > 0 {
+                sum += x;
+            }
+        }
+    }
+    sum
+}"#;
+        let (func, cfg) = rust_function_and_cfg(source);
+        let m = extract_metrics(&func, &cfg);
+        assert_eq!(m.arrow_depth, 3);
+    }
+
+    // ── Guard clauses ──────────────────────────────────────────────────────
+
+    #[test]
+    fn test_guard_clauses_go_error_checks() {
+        let source = r#"package main
+func load(path string) (int, error) {
+    if path == "" {
+        return 0, errEmpty
+    }
+    f, err := open(path)
+    if err != nil {
+        return 0, err
+    }
+    n := 0
+    for _, line := range f.lines {
+        if line == "" {
+            continue
+        }
+        n++
+    }
+    if n > 100 {
+        log(n)
+        return n, nil
+    }
+    return n, nil
+}
+"#;
+        let (func, cfg) = go_function_and_cfg(source);
+        let m = extract_metrics(&func, &cfg);
+        // Two leading guards plus the loop's continue; the last if comes after
+        // the loop and its body is not a lone exit
+        assert_eq!(m.guard_clauses, 3);
+    }
+
+    #[test]
+    fn test_guard_clauses_ecmascript_only_leading_and_shallow() {
+        let source = r#"function f(a: any, b: any) {
+  if (!a) return;
+  if (b) {
+    if (!a.ok) return;
+    a.run();
+  }
+  if (a.done) return;
+  return a.value;
+}"#;
+        let (func, cfg) = ecmascript_function_and_cfg(source);
+        let m = extract_metrics(&func, &cfg);
+        // The nested guard is a branch, and the last if no longer leads
+        assert_eq!(m.guard_clauses, 1);
+    }
+
+    #[test]
+    fn test_guard_clauses_else_is_not_a_guard() {
+        let source = r#"def f(x):
+    if x is None:
+        return 0
+    else:
+        x += 1
+    return x
+"#;
+        let (func, cfg) = python_function_and_cfg(source);
+        let m = extract_metrics(&func, &cfg);
+        assert_eq!(m.guard_clauses, 0);
+    }
+
+    #[test]
+    fn test_guard_clauses_rust_let_else() {
+        let source = r#"fn f(v: Option<&[i32]>) -> i32 {
+    let Some(items) = v else {
+        return 0;
+    };
+    if items.is_empty() {
+        return -1;
+    }
+    let mut sum = 0;
+    for &x in items {
+        if x < 0 {
+            continue;
+        }
+        sum += x;
+    }
+    sum
+}"#;
+        let (func, cfg) = rust_function_and_cfg(source);
+        let m = extract_metrics(&func, &cfg);
+        assert_eq!(m.guard_clauses, 3);
+    }
+
+    // ── ND construct selection ─────────────────────────────────────────────
+
+    fn nd_without(excluded: &[NestingConstruct]) -> NdCounts {
+        let kept: Vec<NestingConstruct> = NestingConstruct::ALL
+            .into_iter()
+            .filter(|c| !excluded.contains(c))
+            .collect();
+        NdCounts::from_constructs(&kept)
+    }
+
+    #[test]
+    fn test_nd_counts_ecmascript_switch_and_try() {
+        let source = r#"function f(xs: number[]) {
+  try {
+    for (const x of xs) {
+      switch (x) {
+        case 1:
+          if (x > 0) { log(x); }
+          break;
+      }
+    }
+  } catch (e) {}
+}"#;
+        let (func, cfg) = ecmascript_function_and_cfg(source);
+        assert_eq!(extract_metrics(&func, &cfg).nd, 4);
+        let nd = |excluded: &[NestingConstruct]| {
+            extract_metrics_with(&func, &cfg, nd_without(excluded)).nd
+        };
+        assert_eq!(nd(&[NestingConstruct::Switch]), 3);
+        assert_eq!(nd(&[NestingConstruct::Switch, NestingConstruct::Try]), 2);
+        // Excluding a construct the function does not use changes nothing
+        assert_eq!(nd(&[NestingConstruct::Match]), 4);
+    }
+
+    #[test]
+    fn test_nd_counts_rust_match() {
+        let source = r#"fn f(v: Option<i32>) -> i32 {
+    match v {
+        Some(x) => {
+            if x > 0 {
+                x
+            } else {
+                0
+            }
+        }
+        None => -1,
+    }
+}"#;
+        let (func, cfg) = rust_function_and_cfg(source);
+        assert_eq!(extract_metrics(&func, &cfg).nd, 2);
+        let counts = nd_without(&[NestingConstruct::Match]);
+        assert_eq!(extract_metrics_with(&func, &cfg, counts).nd, 1);
+    }
+
+    #[test]
+    fn test_nd_counts_python_with_always_counts() {
+        let source = r#"def f(path):
+    with open(path) as fh:
+        try:
+            return fh.read()
+        except OSError:
+            return None
+"#;
+        let (func, cfg) = python_function_and_cfg(source);
+        assert_eq!(extract_metrics(&func, &cfg).nd, 2);
+        let counts = NdCounts::from_constructs(&[]);
+        assert_eq!(extract_metrics_with(&func, &cfg, counts).nd, 1);
+    }
+
+    /// Helper: parse SQL source, return metrics for the first routine.
+    fn sql_metrics(source: &str) -> RawMetrics {
+        use crate::language::SqlParser;
+        let parser = SqlParser::new(None);
+        let module = parser.parse(source, "test.sql").unwrap();
+        let functions = module.discover_functions(0, source);
+        assert!(!functions.is_empty(), "expected at least one SQL routine");
+        let func = functions.into_iter().next().unwrap();
+        let cfg = crate::language::SqlCfgBuilder.build(&func);
+        extract_metrics(&func, &cfg)
+    }
+
+    #[test]
+    fn test_sql_plpgsql_non_decisions() {
+        let source = r#"CREATE FUNCTION f(p_id int) RETURNS SETOF int AS $$
+BEGIN
+    DROP TABLE IF EXISTS scratch;
+    PERFORM 1 FROM accounts WHERE id = p_id FOR UPDATE;
+    IF p_id BETWEEN 1 AND 10 THEN
+        RETURN NEXT p_id;
+    END IF;
+    RAISE NOTICE 'done';
+    RETURN;
+END;
+$$ LANGUAGE plpgsql;"#;
+        let m = sql_metrics(source);
+        // Only the IF branches: DDL IF EXISTS, FOR UPDATE, BETWEEN's AND, and END IF do not
+        assert_eq!(m.cc, 2);
+        assert_eq!(m.nd, 1);
+        // RETURN NEXT appends a row and RAISE NOTICE only logs; the bare RETURN exits
+        assert_eq!(m.ns, 1);
+    }
+
+    #[test]
+    fn test_sql_plpgsql_nested_loops_and_exits() {
+        let source = r#"CREATE FUNCTION f() RETURNS void AS $$
+BEGIN
+    LOOP
+        WHILE ready() LOOP
+            EXIT WHEN done();
+        END LOOP;
+    END LOOP;
+END;
+$$ LANGUAGE plpgsql;"#;
+        let m = sql_metrics(source);
+        // LOOP + WHILE ... LOOP (one loop) + EXIT WHEN
+        assert_eq!(m.cc, 4);
+        assert_eq!(m.nd, 2);
+        assert_eq!(m.ns, 1);
+        assert_eq!(m.callee_names, vec!["done", "ready"]);
+    }
+
+    #[test]
+    fn test_sql_tsql_case_else_and_single_statement_if() {
+        let source = r#"CREATE PROCEDURE dbo.p @x INT AS
+BEGIN
+    SELECT CASE WHEN @x > 0 THEN 'pos' ELSE 'neg' END;
+    SELECT 1;
+    IF @x IS NULL
+        RETURN;
+    EXEC @rc = dbo.other;
+END
+GO"#;
+        let m = sql_metrics(source);
+        // 1 + WHEN + IF
+        assert_eq!(m.cc, 3);
+        // CASE nests once; the ELSE inside it is not a statement ELSE
+        assert_eq!(m.nd, 1);
+        assert_eq!(m.ns, 1);
+        assert_eq!(m.callee_names, vec!["dbo.other"]);
+    }
+
+    #[test]
+    fn test_sql_ns_breakdown() {
+        let source = r#"CREATE PROCEDURE dbo.p @x INT AS
+BEGIN
+    WHILE @x > 0
+    BEGIN
+        IF @x = 5 BREAK;
+        IF @x = 7 GOTO done;
+        SET @x = @x - 1;
+    END
+    IF @x < 0 THROW 50000, 'negative', 1;
+    done:
+    RETURN;
+END
+GO"#;
+        let m = sql_metrics(source);
+        let b = &m.ns_breakdown;
+        assert_eq!(b.get(ExitKind::Break), 1);
+        assert_eq!(b.get(ExitKind::Goto), 1);
+        assert_eq!(b.get(ExitKind::Throw), 1);
+        assert_eq!(b.get(ExitKind::Return), 1);
+        assert_eq!(m.ns, 4);
+
+        // PL/pgSQL `EXIT` leaves a loop like `break`
+        let m = sql_metrics(
+            r#"CREATE FUNCTION f() RETURNS void AS $$
+BEGIN
+    LOOP
+        EXIT WHEN done();
+    END LOOP;
+END;
+$$ LANGUAGE plpgsql;"#,
+        );
+        assert_eq!(m.ns_breakdown.get(ExitKind::Break), 1);
+        assert_eq!(m.ns, 1);
+    }
+
+    /// Breakdown change between two versions of the same function
+    fn breakdown_change(before: &RawMetrics, after: &RawMetrics) -> Option<String> {
+        let before = before.cc_breakdown.as_ref().unwrap();
+        after.cc_breakdown.as_ref().unwrap().describe_change(before)
+    }
+
+    #[test]
+    fn test_cc_breakdown_ecmascript_single_added_if() {
+        let before = r#"function f(x: number) {
+  if (x > 0) { return 1; }
+  return 0;
+}"#;
+        let after = r#"function f(x: number) {
+  if (x > 0) { return 1; }
+  if (x < -10) { return -1; }
+  return 0;
+}"#;
+        let (func, cfg) = ecmascript_function_and_cfg(before);
+        let before = extract_metrics(&func, &cfg);
+        let (func, cfg) = ecmascript_function_and_cfg(after);
+        let after = extract_metrics(&func, &cfg);
+        assert_eq!(after.cc, before.cc + 1);
+        assert_eq!(breakdown_change(&before, &after).as_deref(), Some("+1 if"));
+        assert_eq!(breakdown_change(&before, &before), None);
+    }
+
+    #[test]
+    fn test_cc_breakdown_ecmascript_kinds() {
+        let before = r#"function f(x: number, y: boolean) {
+  switch (x) {
+    case 1: return 1;
+  }
+  return 0;
+}"#;
+        let after = r#"function f(x: number, y: boolean) {
+  switch (x) {
+    case 1: return 1;
+    case 2: return 2;
+    case 3: return 3;
+  }
+  if (x > 0 && y) { return 4; }
+  return 0;
+}"#;
+        let (func, cfg) = ecmascript_function_and_cfg(before);
+        let before = extract_metrics(&func, &cfg);
+        let (func, cfg) = ecmascript_function_and_cfg(after);
+        let after = extract_metrics(&func, &cfg);
+        assert_eq!(
+            breakdown_change(&before, &after).as_deref(),
+            Some("+1 if, +2 switch cases, +1 &&")
+        );
+        assert_eq!(
+            breakdown_change(&after, &before).as_deref(),
+            Some("-1 if, -2 switch cases, -1 &&")
+        );
+    }
+
+    #[test]
+    fn test_cc_breakdown_python_single_added_if() {
+        let before = "def f(x):\n    for i in x:\n        print(i)\n";
+        let after = "def f(x):\n    for i in x:\n        if i:\n            print(i)\n";
+        let (func, cfg) = python_function_and_cfg(before);
+        let before = extract_metrics(&func, &cfg);
+        let (func, cfg) = python_function_and_cfg(after);
+        let after = extract_metrics(&func, &cfg);
+        assert_eq!(
+            before
+                .cc_breakdown
+                .as_ref()
+                .unwrap()
+                .get(DecisionKind::Loop),
+            1
+        );
+        assert_eq!(breakdown_change(&before, &after).as_deref(), Some("+1 if"));
+    }
+
+    #[test]
+    fn test_cc_breakdown_rust_single_added_if() {
+        let before = r#"fn f(v: Option<i32>) -> i32 {
+    match v {
+        Some(x) => x,
+        None => 0,
+    }
+}"#;
+        let after = r#"fn f(v: Option<i32>) -> i32 {
+    match v {
+        Some(x) => {
+            if x > 0 {
+                return x;
+            }
+            0
+        }
+        None => 0,
+    }
+}"#;
+        let (func, cfg) = rust_function_and_cfg(before);
+        let before = extract_metrics(&func, &cfg);
+        let (func, cfg) = rust_function_and_cfg(after);
+        let after = extract_metrics(&func, &cfg);
+        assert_eq!(
+            before
+                .cc_breakdown
+                .as_ref()
+                .unwrap()
+                .get(DecisionKind::MatchArm),
+            2
+        );
+        assert_eq!(breakdown_change(&before, &after).as_deref(), Some("+1 if"));
+    }
+
+    #[test]
+    fn test_max_condition_ops_ecmascript_largest_single_expression() {
+        // `??` is not a boolean operator; two conditions are not summed
+        let source = r#"function f(a: boolean, b: boolean, c: boolean, d?: number) {
+  const n = d ?? 0;
+  if (!(a && b) || c) { return n; }
+  while (a && c) { a = false; }
+  return 0;
+}"#;
+        let (func, cfg) = ecmascript_function_and_cfg(source);
+        assert_eq!(extract_metrics(&func, &cfg).max_condition_ops, 2);
+    }
+
+    #[test]
+    fn test_max_condition_ops_python() {
+        let source = "def f(a, b, c):\n    if a and b or c:\n        return 1\n    x = a or b\n    return x\n";
+        let (func, cfg) = python_function_and_cfg(source);
+        assert_eq!(extract_metrics(&func, &cfg).max_condition_ops, 2);
+    }
+
+    #[test]
+    fn test_max_condition_ops_rust_guards_and_parens() {
+        let source = r#"fn f(v: Option<i32>, a: bool, b: bool) -> i32 {
+    if !(a && b) || a {
+        return 0;
+    }
+    match v {
+        Some(n) if (n > 0 && n < 10) || n == 20 || n == 30 => n,
+        _ => 0,
+    }
+}"#;
+        let (func, cfg) = rust_function_and_cfg(source);
+        assert_eq!(extract_metrics(&func, &cfg).max_condition_ops, 3);
+    }
+
+    #[test]
+    fn test_cognitive_ecmascript_else_if_chain_and_callbacks() {
+        // if +1, else if +1, else +1; the arrow in the else block nests the
+        // inner if two deep: +3
+        let source = r#"function f(xs: number[], y: number) {
+  if (y > 0) {
+    return 1;
+  } else if (y < 0) {
+    return -1;
+  } else {
+    xs.forEach((x) => {
+      if (x) { console.log(x); }
+    });
+  }
+  return 0;
+}"#;
+        let (func, cfg) = ecmascript_function_and_cfg(source);
+        assert_eq!(extract_metrics(&func, &cfg).cognitive, 6);
+    }
+
+    #[test]
+    fn test_cognitive_java_labeled_break() {
+        // for +1, nested for +2, if +3, labeled break +1
+        let source = r#"class T {
+    int f(int[][] grid) {
+        outer:
+        for (int[] row : grid) {
+            for (int v : row) {
+                if (v < 0) {
+                    break outer;
+                }
+            }
+        }
+        return 0;
+    }
+}"#;
+        let (func, cfg) = java_function_and_cfg(source);
+        assert_eq!(extract_metrics(&func, &cfg).cognitive, 7);
+    }
+
+    #[test]
+    fn test_cognitive_python_elif_and_mixed_operators() {
+        // if +1, elif +1, `and`/`or` sequences +2
+        let source = "def f(a, b, c):\n    if a and b or c:\n        return 1\n    elif a:\n        return 2\n    return 0\n";
+        let (func, cfg) = python_function_and_cfg(source);
+        assert_eq!(extract_metrics(&func, &cfg).cognitive, 4);
+    }
 }
@@ -284,7 +284,7 @@ fn load_source_tokens(files: &[String], repo_root: &Path) -> HashMap<String, Has
 }
 
 pub fn extract_models(source_root: &Path, repo_root: &Path) -> Result<Vec<ModelDecl>> {
-    let source_files = crate::collect_source_files(source_root)?;
+    let source_files = crate::collect_source_files(source_root, true)?;
     let mut models = Vec::new();
     for path in source_files {
         let language = match Language::from_path(&path) {
@@ -293,6 +293,7 @@ pub fn extract_models(source_root: &Path, repo_root: &Path) -> Result<Vec<ModelD
         };
         let source = std::fs::read_to_string(&path)
             .with_context(|| format!("failed to read {}", path.display()))?;
+        let source = crate::language::span::normalize_source(&source);
         let file = normalize_file(&path, repo_root);
         models.extend(extract_models_from_source(&source, language, file));
     }
@@ -478,6 +479,18 @@ fn extract_models_from_source(source: &str, language: Language, file: String) ->
         | Language::Vue => extract_regex_models(source, language, file, ECMASCRIPT_MODEL_PATTERNS),
         Language::CSharp => extract_regex_models(source, language, file, CSHARP_MODEL_PATTERNS),
         Language::C | Language::CHeader => vec![], // struct/typedef model detection not implemented
+        Language::Sql => vec![],                   // CREATE TABLE model detection not implemented
+        Language::Swift => vec![],                 // struct/class model detection not implemented
+        Language::Cpp => vec![],                   // class/struct model detection not implemented
+        Language::Php => vec![], // Eloquent/Doctrine model detection not implemented
+        Language::Scala => vec![], // case class model detection not implemented
+        Language::Dart => vec![], // class model detection not implemented
+        Language::Elixir => vec![], // Ecto schema detection not implemented
+        Language::Lua => vec![], // table-based class detection not implemented
+        Language::Bash => vec![], // shell scripts have no data models
+        Language::Zig => vec![], // struct model detection not implemented
+        Language::Haskell => vec![], // record type detection not implemented
+        Language::Perl => vec![], // blessed-hash class detection not implemented
     }
 }
 
@@ -658,8 +671,24 @@ mod tests {
                 cc: 1,
                 nd: 0,
                 fo: 0,
+                fi: 0,
                 ns: 0,
                 loc: 10,
+                signature_complexity: 0,
+                params: 0,
+                guard_clauses: 0,
+                max_condition_ops: 0,
+                cognitive: 0,
+                halstead: None,
+                maintainability: None,
+                sloc: None,
+                comment_lines: None,
+                blank_lines: None,
+                nd_line: None,
+                cc_lines: None,
+                is_async: false,
+                await_in_loop: 0,
+                ns_breakdown: None,
             },
             lrs,
             band: if lrs >= 8.0 {
@@ -693,6 +722,8 @@ mod tests {
             age_days: None,
             last_touch_days: None,
             explanation: None,
+            structure: None,
+            cc_breakdown: None,
         }
     }
 
@@ -717,6 +748,9 @@ mod tests {
             functions,
             summary: None,
             aggregates: None,
+            truncated: None,
+            total_functions: None,
+            top_level_decisions: Default::default(),
         }
     }
 
@@ -0,0 +1,334 @@
+//! Statistical outliers (`analyze --outliers`)
+//!
+//! Instead of fixed limits, each metric's distribution across the analyzed
+//! functions sets its own threshold: a function is an outlier on a metric
+//! when its value is above the mean plus two standard deviations
+//! ([`OUTLIER_Z_SCORE`]). A project whose functions are all long flags only
+//! the ones that are long even for it.
+//!
+//! Each outlier is reported with its z-score (standard deviations above the
+//! mean) and its percentile rank (the share of analyzed functions whose value
+//! is at or below it). The standard deviation is the population one; a metric
+//! on which every function has the same value has no outliers.
+//!
+//! Global invariants enforced:
+//! - Deterministic output ordering (metric, value descending, file, line, function)
+
+use crate::report::FunctionRiskReport;
+use anyhow::{Context, Result};
+use serde::Serialize;
+use std::path::Path;
+
+/// Standard deviations above the mean a value must exceed to be an outlier
+pub const OUTLIER_Z_SCORE: f64 = 2.0;
+
+/// Metrics checked for outliers, in report order
+pub const OUTLIER_METRICS: &[&str] = &["loc", "cc", "cognitive", "nd", "fo", "ns"];
+
+/// A metric's distribution across the analyzed functions
+#[derive(Debug, Clone, Serialize, PartialEq)]
+#[serde(rename_all = "snake_case")]
+pub struct MetricDistribution {
+    pub metric: String,
+    pub mean: f64,
+    pub std_dev: f64,
+    /// 95th percentile value
+    pub p95: u32,
+    /// Values above this are outliers: mean + 2σ
+    pub threshold: f64,
+}
+
+/// A function whose value on one metric is an outlier
+#[derive(Debug, Clone, Serialize, PartialEq)]
+#[serde(rename_all = "snake_case")]
+pub struct Outlier {
+    /// Path relative to the analysis base
+    pub file: String,
+    pub function: String,
+    pub line: u32,
+    pub metric: String,
+    pub value: u32,
+    pub z_score: f64,
+    /// Percentage of analyzed functions with a value at or below this one
+    pub percentile: f64,
+}
+
+/// Distributions and outliers of an analyzed set
+#[derive(Debug, Clone, Serialize, PartialEq)]
+#[serde(rename_all = "snake_case")]
+pub struct OutlierReport {
+    /// Number of analyzed functions
+    pub functions: usize,
+    pub distributions: Vec<MetricDistribution>,
+    pub outliers: Vec<Outlier>,
+}
+
+/// Value of `metric` (one of [`OUTLIER_METRICS`]) for a function
+fn metric_value(report: &FunctionRiskReport, metric: &str) -> u32 {
+    let m = &report.metrics;
+    match metric {
+        "loc" => m.loc,
+        "cc" => m.cc,
+        "cognitive" => m.cognitive,
+        "nd" => m.nd,
+        "fo" => m.fo,
+        "ns" => m.ns,
+        _ => 0,
+    }
+}
+
+/// Distribution of `values` (not empty)
+fn distribution(metric: &str, values: &[u32]) -> MetricDistribution {
+    let n = values.len();
+    let mean = values.iter().map(|&v| v as f64).sum::<f64>() / n as f64;
+    let variance = values
+        .iter()
+        .map(|&v| (v as f64 - mean).powi(2))
+        .sum::<f64>()
+        / n as f64;
+    let std_dev = variance.sqrt();
+    let mut sorted = values.to_vec();
+    sorted.sort_unstable();
+    MetricDistribution {
+        metric: metric.to_string(),
+        mean,
+        std_dev,
+        p95: sorted[(95 * (n - 1)) / 100],
+        threshold: mean + OUTLIER_Z_SCORE * std_dev,
+    }
+}
+
+/// The distributions and outliers of `reports` (every analyzed function,
+/// unfiltered). Paths are relative to `base` when they fall under it.
+pub fn find_outliers(reports: &[FunctionRiskReport], base: &Path) -> OutlierReport {
+    let mut distributions = Vec::new();
+    let mut outliers = Vec::new();
+    if reports.is_empty() {
+        return OutlierReport {
+            functions: 0,
+            distributions,
+            outliers,
+        };
+    }
+
+    for metric in OUTLIER_METRICS {
+        let values: Vec<u32> = reports.iter().map(|r| metric_value(r, metric)).collect();
+        let dist = distribution(metric, &values);
+        if dist.std_dev > 0.0 {
+            let mut flagged: Vec<Outlier> = reports
+                .iter()
+                .zip(&values)
+                .filter(|(_, &value)| value as f64 > dist.threshold)
+                .map(|(r, &value)| Outlier {
+                    file: crate::treemap::relative_path(&r.file, base),
+                    function: r.function.clone(),
+                    line: r.line,
+                    metric: metric.to_string(),
+                    value,
+                    z_score: (value as f64 - dist.mean) / dist.std_dev,
+                    percentile: 100.0 * values.iter().filter(|&&v| v <= value).count() as f64
+                        / values.len() as f64,
+                })
+                .collect();
+            flagged.sort_by(|a, b| {
+                b.value.cmp(&a.value).then_with(|| {
+                    (&a.file, a.line, &a.function).cmp(&(&b.file, b.line, &b.function))
+                })
+            });
+            outliers.extend(flagged);
+        }
+        distributions.push(dist);
+    }
+
+    OutlierReport {
+        functions: reports.len(),
+        distributions,
+        outliers,
+    }
+}
+
+/// Render the report as JSON
+pub fn render_outliers_json(report: &OutlierReport) -> Result<String> {
+    serde_json::to_string_pretty(report).context("failed to serialize outliers")
+}
+
+/// Render the report as text: a heading per metric with outliers, giving its
+/// distribution, then one `path:line  name  value  z  percentile` row each.
+pub fn render_outliers_text(report: &OutlierReport) -> String {
+    if report.outliers.is_empty() {
+        return format!(
+            "No outliers: no function is above mean + {OUTLIER_Z_SCORE}σ on any metric across {} analyzed function(s).\n",
+            report.functions
+        );
+    }
+    let mut out = format!(
+        "{} outlier(s) above mean + {OUTLIER_Z_SCORE}σ across {} analyzed function(s)\n",
+        report.outliers.len(),
+        report.functions
+    );
+    let locations: Vec<String> = report
+        .outliers
+        .iter()
+        .map(|o| format!("{}:{}", o.file, o.line))
+        .collect();
+    let width = locations.iter().map(String::len).max().unwrap_or(0);
+    for dist in &report.distributions {
+        let rows: Vec<(&Outlier, &String)> = report
+            .outliers
+            .iter()
+            .zip(&locations)
+            .filter(|(o, _)| o.metric == dist.metric)
+            .collect();
+        if rows.is_empty() {
+            continue;
+        }
+        out.push_str(&format!(
+            "\n{} (mean {:.1}, σ {:.1}, p95 {}, threshold {:.1})\n",
+            dist.metric, dist.mean, dist.std_dev, dist.p95, dist.threshold
+        ));
+        for (o, location) in rows {
+            out.push_str(&format!(
+                "  {location:<width$}  {}  {} {}  z {:.2}  p{:.1}\n",
+                o.function, o.metric, o.value, o.z_score, o.percentile
+            ));
+        }
+    }
+    out
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::language::Language;
+    use crate::report::{MetricsReport, RiskReport};
+    use crate::risk::RiskBand;
+
+    fn make_report(function: &str, line: u32, loc: u32, cc: u32, nd: u32) -> FunctionRiskReport {
+        FunctionRiskReport {
+            file: "/repo/src/a.rs".to_string(),
+            function: function.to_string(),
+            owner: None,
+            line,
+            language: Language::Rust,
+            metrics: MetricsReport {
+                cc,
+                cognitive: 0,
+                nd,
+                fo: 0,
+                fi: 0,
+                ns: 0,
+                loc,
+                signature_complexity: 0,
+                params: 0,
+                guard_clauses: 0,
+                max_condition_ops: 0,
+                halstead: None,
+                maintainability: None,
+                sloc: None,
+                comment_lines: None,
+                blank_lines: None,
+                nd_line: None,
+                cc_lines: None,
+                is_async: false,
+                await_in_loop: 0,
+                ns_breakdown: None,
+            },
+            risk: RiskReport {
+                r_cc: 1.0,
+                r_nd: 0.0,
+                r_fo: 0.0,
+                r_ns: 0.0,
+            },
+            lrs: 1.0,
+            band: RiskBand::Low,
+            risk_score: None,
+            suppression_reason: None,
+            patterns: vec![],
+            pattern_details: None,
+            callees: vec![],
+            explanation: None,
+            arrow_depth: 0,
+            aliases: vec![],
+            structure: None,
+            cc_breakdown: None,
+            is_public: false,
+            file_top_level_decisions: 0,
+        }
+    }
+
+    /// Twenty functions: LOC 10 except one of 100, CC 2 throughout, ND
+    /// spread evenly from 0 to 19
+    fn synthetic() -> Vec<FunctionRiskReport> {
+        (0..20u32)
+            .map(|i| {
+                let loc = if i == 7 { 100 } else { 10 };
+                make_report(&format!("f{i:02}"), i + 1, loc, 2, i)
+            })
+            .collect()
+    }
+
+    #[test]
+    fn test_outliers_over_synthetic_distribution() {
+        let report = find_outliers(&synthetic(), Path::new("/repo"));
+        assert_eq!(report.functions, 20);
+
+        // Only the long function stands out; an even spread and a constant
+        // metric have no outliers
+        assert_eq!(report.outliers.len(), 1);
+        let outlier = &report.outliers[0];
+        assert_eq!(outlier.function, "f07");
+        assert_eq!(outlier.file, "src/a.rs");
+        assert_eq!(outlier.metric, "loc");
+        assert_eq!(outlier.value, 100);
+        assert_eq!(outlier.percentile, 100.0);
+        // mean 14.5, σ = sqrt(384.75) ≈ 19.615
+        assert!((outlier.z_score - 4.359).abs() < 0.001);
+
+        let loc = &report.distributions[0];
+        assert_eq!(loc.metric, "loc");
+        assert_eq!(loc.mean, 14.5);
+        assert!((loc.std_dev - 19.615).abs() < 0.001);
+        assert!((loc.threshold - 53.730).abs() < 0.001);
+        assert_eq!(loc.p95, 10);
+
+        let cc = &report.distributions[1];
+        assert_eq!((cc.mean, cc.std_dev), (2.0, 0.0));
+        let nd = &report.distributions[3];
+        assert_eq!(nd.metric, "nd");
+        assert!((nd.std_dev - 5.766).abs() < 0.001);
+        assert_eq!(nd.p95, 18);
+    }
+
+    #[test]
+    fn test_outliers_sorted_by_value_then_location() {
+        let mut reports = synthetic();
+        reports.push(make_report("g", 30, 100, 2, 0));
+        reports.push(make_report("h", 40, 120, 2, 0));
+        let report = find_outliers(&reports, Path::new("/repo"));
+        let flagged: Vec<(&str, u32)> = report
+            .outliers
+            .iter()
+            .map(|o| (o.function.as_str(), o.value))
+            .collect();
+        assert_eq!(flagged, [("h", 120), ("f07", 100), ("g", 100)]);
+        assert_eq!(report.outliers[1].percentile, report.outliers[2].percentile);
+
+        let text = render_outliers_text(&report);
+        assert!(text.starts_with("3 outlier(s) above mean + 2σ across 22 analyzed function(s)\n"));
+        assert!(text.contains("\nloc (mean "));
+        assert!(text.contains("  src/a.rs:40  h  loc 120  z "));
+        assert!(!text.contains("\ncc ("));
+    }
+
+    #[test]
+    fn test_no_outliers() {
+        let reports: Vec<FunctionRiskReport> =
+            (0..5).map(|i| make_report("f", i + 1, 10, 1, 0)).collect();
+        let report = find_outliers(&reports, Path::new("/repo"));
+        assert!(report.outliers.is_empty());
+        assert!(render_outliers_text(&report).starts_with("No outliers"));
+        assert!(find_outliers(&[], Path::new("/repo"))
+            .distributions
+            .is_empty());
+    }
+}
@@ -0,0 +1,654 @@
+//! Declared parameter counts, computed at function discovery
+//!
+//! Every declared parameter counts once: each name of a Go group
+//! (`a, b int` is two), a variadic or rest parameter, and Python's default,
+//! keyword-only, `*args`, and `**kwargs` parameters. Receivers do not count:
+//! Go method receivers, Rust and Zig `self`, Python's `self` / `cls` on methods, C#
+//! extension-method `this`, Java receiver parameters, TypeScript's `this`
+//! annotation, and Perl's leading `$self` / `$class`. A destructured parameter is one parameter. A C or C++
+//! `(void)` list is none.
+//!
+//! Supported: every language except SQL, which reports 0.
+//!
+//! Global invariants enforced:
+//! - Formatting, comments, and whitespace must not affect results
+//! - Deterministic metric calculation
+
+use crate::language::tree_sitter_utils::find_child_by_kind;
+use swc_ecma_ast::{ArrowExpr, Function, Pat};
+use tree_sitter::Node;
+
+// ---------------------------------------------------------------------------
+// ECMAScript
+// ---------------------------------------------------------------------------
+
+/// Parameters of a function, method, or function expression
+pub fn ecmascript_function_params(function: &Function) -> usize {
+    function
+        .params
+        .iter()
+        .filter(|param| !is_this_param(&param.pat))
+        .count()
+}
+
+/// Parameters of an arrow function (which cannot declare `this`)
+pub fn ecmascript_arrow_params(arrow: &ArrowExpr) -> usize {
+    arrow.params.len()
+}
+
+/// TypeScript's `function f(this: Window)` only types `this`
+fn is_this_param(pat: &Pat) -> bool {
+    matches!(pat, Pat::Ident(ident) if &*ident.id.sym == "this")
+}
+
+// ---------------------------------------------------------------------------
+// Rust
+// ---------------------------------------------------------------------------
+
+/// Parameters of a function or method, not counting `self`
+pub fn rust_params(sig: &syn::Signature) -> usize {
+    sig.inputs
+        .iter()
+        .filter(|arg| matches!(arg, syn::FnArg::Typed(_)))
+        .count()
+}
+
+// ---------------------------------------------------------------------------
+// tree-sitter languages
+// ---------------------------------------------------------------------------
+
+/// Parameters of a Go function or method; the receiver is a separate list
+pub fn go_params(func_node: Node) -> usize {
+    let Some(list) = func_node.child_by_field_name("parameters") else {
+        return 0;
+    };
+    let mut cursor = list.walk();
+    let declarations: Vec<Node> = list.named_children(&mut cursor).collect();
+    declarations
+        .into_iter()
+        .map(|declaration| match declaration.kind() {
+            // `a, b int` names two; a type without a name is one
+            "parameter_declaration" => {
+                let mut cursor = declaration.walk();
+                let names = declaration
+                    .children_by_field_name("name", &mut cursor)
+                    .count();
+                names.max(1)
+            }
+            "variadic_parameter_declaration" => 1,
+            _ => 0,
+        })
+        .sum()
+}
+
+/// Parameters of a Java method or constructor
+pub fn java_params(func_node: Node) -> usize {
+    count_children(func_node, "formal_parameters", |param| {
+        matches!(param.kind(), "formal_parameter" | "spread_parameter")
+    })
+}
+
+/// Parameters of a Python function; on methods a leading `self` / `cls` is
+/// the receiver
+pub fn python_params(func_node: Node, source: &str) -> usize {
+    let Some(list) = func_node.child_by_field_name("parameters") else {
+        return 0;
+    };
+    let mut cursor = list.walk();
+    let params: Vec<Node> = list
+        .named_children(&mut cursor)
+        .filter(|param| {
+            !matches!(
+                param.kind(),
+                "keyword_separator" | "positional_separator" | "comment"
+            )
+        })
+        .collect();
+    let receiver = is_python_method(func_node)
+        && params.first().is_some_and(|first| {
+            first.kind() == "identifier"
+                && matches!(
+                    &source[first.start_byte()..first.end_byte()],
+                    "self" | "cls"
+                )
+        });
+    params.len() - usize::from(receiver)
+}
+
+/// A function defined directly in a class body (decorators allowed)
+fn is_python_method(func_node: Node) -> bool {
+    let mut current = func_node.parent();
+    while let Some(ancestor) = current {
+        match ancestor.kind() {
+            "class_definition" => return true,
+            "function_definition" | "async_function_definition" => return false,
+            _ => current = ancestor.parent(),
+        }
+    }
+    false
+}
+
+/// Parameters of a C# method, constructor, operator, or local function; an
+/// extension method's `this` parameter is the receiver
+pub fn csharp_params(func_node: Node, source: &str) -> usize {
+    count_children(func_node, "parameter_list", |param| {
+        matches!(param.kind(), "parameter" | "parameter_array")
+            && !has_this_modifier(*param, source)
+    })
+}
+
+/// `this string s` in an extension method
+fn has_this_modifier(param: Node, source: &str) -> bool {
+    let mut cursor = param.walk();
+    let this = param
+        .children(&mut cursor)
+        .any(|child| &source[child.start_byte()..child.end_byte()] == "this");
+    this
+}
+
+/// Parameters of a C function definition; `(void)` declares none
+pub fn c_params(func_node: Node, source: &str) -> usize {
+    // The function declarator may be wrapped in pointer declarators
+    // (`char *name(...)`)
+    let mut declarator = func_node.child_by_field_name("declarator");
+    while let Some(node) = declarator {
+        if node.kind() == "function_declarator" {
+            break;
+        }
+        declarator = node.child_by_field_name("declarator");
+    }
+    let Some(list) = declarator.and_then(|d| d.child_by_field_name("parameters")) else {
+        return 0;
+    };
+    c_family_params(list, source)
+}
+
+/// Parameters of a C++ function definition or lambda. Default-valued and
+/// pack (`Args... args`) parameters count; `(void)` declares none, and so
+/// does a lambda without a parameter list (`[] { ... }`).
+pub fn cpp_params(func_node: Node, source: &str) -> usize {
+    // Function declarators may be wrapped in pointer and reference
+    // declarators (`const T& name(...)`); reference declarators have no
+    // `declarator` field
+    let mut declarator = func_node.child_by_field_name("declarator");
+    while let Some(node) = declarator {
+        if matches!(
+            node.kind(),
+            "function_declarator" | "abstract_function_declarator"
+        ) {
+            break;
+        }
+        declarator = node
+            .child_by_field_name("declarator")
+            .or_else(|| node.named_child(node.named_child_count().saturating_sub(1)));
+    }
+    let Some(list) = declarator.and_then(|d| d.child_by_field_name("parameters")) else {
+        return 0;
+    };
+    c_family_params(list, source)
+}
+
+/// Count the parameters of a C or C++ `parameter_list`
+fn c_family_params(list: Node, source: &str) -> usize {
+    let mut cursor = list.walk();
+    let params: Vec<Node> = list
+        .named_children(&mut cursor)
+        .filter(|param| {
+            matches!(
+                param.kind(),
+                "parameter_declaration"
+                    | "optional_parameter_declaration"
+                    | "variadic_parameter_declaration"
+                    | "variadic_parameter"
+            )
+        })
+        .collect();
+    let is_void = params.len() == 1
+        && params[0].kind() == "parameter_declaration"
+        && params[0].child_by_field_name("declarator").is_none()
+        && params[0]
+            .child_by_field_name("type")
+            .is_some_and(|ty| &source[ty.start_byte()..ty.end_byte()] == "void");
+    if is_void {
+        0
+    } else {
+        params.len()
+    }
+}
+
+/// Parameters of a Swift function or initializer; accessors declare none
+pub fn swift_params(func_node: Node) -> usize {
+    let mut cursor = func_node.walk();
+    let count = func_node
+        .children(&mut cursor)
+        .filter(|child| child.kind() == "parameter")
+        .count();
+    count
+}
+
+/// Parameters of a PHP function, method, closure, or arrow function,
+/// including promoted constructor properties; a closure's `use` variables
+/// are not parameters
+pub fn php_params(func_node: Node) -> usize {
+    count_children(func_node, "formal_parameters", |param| {
+        matches!(
+            param.kind(),
+            "simple_parameter" | "variadic_parameter" | "property_promotion_parameter"
+        )
+    })
+}
+
+/// Parameters of a Scala `def` or bound lambda. Every parameter clause
+/// counts, `implicit` and `using` clauses included (`def f(a: Int)(b: Int)`
+/// has two); a lambda has its `(a, b)` bindings or a single bare name.
+pub fn scala_params(func_node: Node) -> usize {
+    let mut cursor = func_node.walk();
+    let mut count = 0;
+    for child in func_node.children(&mut cursor) {
+        match child.kind() {
+            "parameters" => {
+                let mut list_cursor = child.walk();
+                count += child
+                    .named_children(&mut list_cursor)
+                    .filter(|param| param.kind() == "parameter")
+                    .count();
+            }
+            "bindings" => {
+                let mut list_cursor = child.walk();
+                count += child
+                    .named_children(&mut list_cursor)
+                    .filter(|binding| binding.kind() == "binding")
+                    .count();
+            }
+            "identifier" | "wildcard" if func_node.kind() == "lambda_expression" => count += 1,
+            "=>" => break,
+            _ => {}
+        }
+    }
+    count
+}
+
+/// Parameters of a Dart function: required, optional positional (`[ ]`),
+/// and named (`{ }`) alike, including `this.x` constructor parameters. A
+/// function-typed parameter (`void onTap(int index)`) is one.
+pub fn dart_params(func_node: Node) -> usize {
+    fn count(node: Node) -> usize {
+        let mut cursor = node.walk();
+        let children: Vec<Node> = node.named_children(&mut cursor).collect();
+        children
+            .into_iter()
+            .map(|child| match child.kind() {
+                "formal_parameter" => 1,
+                _ => count(child),
+            })
+            .sum()
+    }
+
+    /// The first parameter list of the signature, not entering the body
+    fn parameter_list(node: Node) -> Option<Node> {
+        let mut cursor = node.walk();
+        let children: Vec<Node> = node.named_children(&mut cursor).collect();
+        children.into_iter().find_map(|child| match child.kind() {
+            "formal_parameter_list" => Some(child),
+            "function_body" => None,
+            _ => parameter_list(child),
+        })
+    }
+
+    parameter_list(func_node).map_or(0, count)
+}
+
+/// Parameters of an Elixir function: the arity of its first clause. A
+/// default argument (`opts \\ []`) and a pattern (`%{id: id}`) are one
+/// parameter each.
+pub fn elixir_params(clause: Node, source: &str) -> usize {
+    crate::language::elixir::clause_signature(clause, source).map_or(0, |(_, arity)| arity)
+}
+
+/// Parameters of a Lua function; a trailing `...` is one. The implicit
+/// `self` of a method (`function Account:deposit(v)`) is not declared, so it
+/// does not count.
+pub fn lua_params(func_node: Node) -> usize {
+    let Some(parameters) = func_node.child_by_field_name("parameters") else {
+        return 0;
+    };
+    let mut cursor = parameters.walk();
+    let count = parameters
+        .named_children(&mut cursor)
+        .filter(|param| !param.kind().contains("comment"))
+        .count();
+    count
+}
+
+/// Parameters of a Bash function, which declares none: the highest
+/// positional parameter its body reads (`$2` or `${2:-default}` is two).
+/// `$@` and `$*` do not count, and neither do functions defined inside it.
+pub fn bash_params(func_node: Node, source: &str) -> usize {
+    fn highest(node: Node, source: &str) -> usize {
+        let mut cursor = node.walk();
+        let mut max = 0;
+        for child in node.named_children(&mut cursor) {
+            if crate::language::bash::is_nested_definition(child) {
+                continue;
+            }
+            if child.kind() == "variable_name" && node.kind().ends_with("expansion") {
+                // Only a positional name (`1`, `10`) parses as a number
+                let position = source[child.byte_range()].parse().unwrap_or(0);
+                max = max.max(position);
+            }
+            max = max.max(highest(child, source));
+        }
+        max
+    }
+
+    func_node
+        .child_by_field_name("body")
+        .map_or(0, |body| highest(body, source))
+}
+
+/// Parameters of a Zig function, counting `comptime T: type` parameters; a
+/// leading `self` is the receiver of a method and does not count
+pub fn zig_params(func_node: Node, source: &str) -> usize {
+    let Some(parameters) = func_node
+        .child_by_field_name("parameters")
+        .or_else(|| find_child_by_kind(func_node, "parameters"))
+    else {
+        return 0;
+    };
+    let mut cursor = parameters.walk();
+    let params: Vec<Node> = parameters
+        .named_children(&mut cursor)
+        .filter(|param| !param.kind().contains("comment"))
+        .collect();
+    let has_self = params.first().is_some_and(|first| {
+        let name = source[first.byte_range()].split(':').next().unwrap_or("");
+        name.trim() == "self"
+    });
+    params.len() - usize::from(has_self)
+}
+
+/// Parameters of a Haskell function: the number of arguments its first
+/// equation matches (two for an operator defined infix, `a <+> b = ...`). A
+/// pattern (`(Circle r)`, `(x:xs)`) is one parameter, and a point-free
+/// definition (`total = sum . map price`) has none.
+pub fn haskell_params(equation: Node) -> usize {
+    crate::language::haskell::equation_arity(equation)
+}
+
+/// Parameters of a Perl sub: the entries of its signature (`sub f ($x, $y
+/// = 1, @rest)`), or without one, the variables its leading statements
+/// unpack from `@_` (`my ($x, $y) = @_;`, or `my $x = shift;` once per
+/// parameter). A leading `$self` or `$class` is the invocant of a method and
+/// does not count.
+pub fn perl_params(func_node: Node, source: &str) -> usize {
+    use crate::language::perl::{body_statements, statement_expression};
+
+    /// Variables declared in `node`, outermost first
+    fn variables<'s>(node: Node, source: &'s str, names: &mut Vec<&'s str>) {
+        let mut cursor = node.walk();
+        for child in node.named_children(&mut cursor) {
+            match child.kind() {
+                "scalar" | "array" | "hash" => names.push(&source[child.byte_range()]),
+                _ => variables(child, source, names),
+            }
+        }
+    }
+
+    let mut names = Vec::new();
+    let mut cursor = func_node.walk();
+    let signature = func_node
+        .children(&mut cursor)
+        .find(|child| child.kind().contains("signature"));
+    match signature {
+        Some(signature) => {
+            let mut cursor = signature.walk();
+            names.extend(
+                signature
+                    .named_children(&mut cursor)
+                    .filter(|param| !param.kind().contains("comment"))
+                    .map(|param| source[param.byte_range()].trim()),
+            );
+        }
+        None => {
+            for statement in body_statements(func_node) {
+                let Some(assignment) = statement_expression(statement)
+                    .filter(|expression| expression.kind() == "assignment_expression")
+                else {
+                    break;
+                };
+                let (Some(left), Some(right)) = (
+                    assignment.child_by_field_name("left"),
+                    assignment.child_by_field_name("right"),
+                ) else {
+                    break;
+                };
+                match source[right.byte_range()].trim() {
+                    "shift" | "shift()" | "shift @_" | "shift(@_)" => {
+                        variables(left, source, &mut names)
+                    }
+                    "@_" => {
+                        variables(left, source, &mut names);
+                        break;
+                    }
+                    _ => break,
+                }
+            }
+        }
+    }
+    let has_invocant = names.first().is_some_and(|first| {
+        matches!(
+            first.split('=').next().map(str::trim),
+            Some("$self" | "$class")
+        )
+    });
+    names.len() - usize::from(has_invocant)
+}
+
+/// Count children of `func_node`'s `list_kind` child that match `is_param`
+fn count_children(func_node: Node, list_kind: &str, is_param: impl Fn(&Node) -> bool) -> usize {
+    let Some(list) = find_child_by_kind(func_node, list_kind) else {
+        return 0;
+    };
+    let mut cursor = list.walk();
+    let count = list.named_children(&mut cursor).filter(is_param).count();
+    count
+}
+
+#[cfg(test)]
+mod tests {
+    use crate::language::parser::LanguageParser;
+    use crate::language::{
+        BashParser, CParser, CSharpParser, CppParser, DartParser, ElixirParser, GoParser,
+        HaskellParser, JavaParser, LuaParser, PerlParser, PhpParser, PythonParser, RustParser,
+        ScalaParser, SwiftParser, ZigParser,
+    };
+
+    fn params(parser: &dyn LanguageParser, source: &str, filename: &str) -> Vec<usize> {
+        let module = parser.parse(source, filename).unwrap();
+        module
+            .discover_functions(0, source)
+            .iter()
+            .map(|f| f.params)
+            .collect()
+    }
+
+    #[test]
+    fn test_go_grouped_variadic_and_receiver() {
+        let source = "package p\n\nfunc A(a, b int, c string) {}\nfunc B(xs ...int) {}\nfunc (s *S) C(a int) {}\nfunc D() {}\n";
+        assert_eq!(
+            params(&GoParser::new().unwrap(), source, "p.go"),
+            vec![3, 1, 1, 0]
+        );
+    }
+
+    #[test]
+    fn test_python_defaults_and_receiver() {
+        let source = "def f(a, b=1, *args, c, d: int = 2, **kwargs):\n    pass\n\nclass C:\n    def m(self, x):\n        pass\n\n    @staticmethod\n    def s(x, y):\n        pass\n\ndef g(a, /, b, *, c):\n    pass\n";
+        assert_eq!(
+            params(&PythonParser::new().unwrap(), source, "m.py"),
+            vec![6, 1, 2, 3]
+        );
+    }
+
+    #[test]
+    fn test_java_and_csharp() {
+        let java = "class A {\n  A(int x) {}\n  void f(int a, String... rest) {}\n}\n";
+        assert_eq!(
+            params(&JavaParser::new().unwrap(), java, "A.java"),
+            vec![1, 2]
+        );
+        let csharp = "static class E {\n  static int F(this string s, int a, params int[] xs) { return a; }\n}\n";
+        assert_eq!(
+            params(&CSharpParser::new().unwrap(), csharp, "E.cs"),
+            vec![2]
+        );
+    }
+
+    #[test]
+    fn test_c_void_and_variadic() {
+        let source = "int f(void) { return 0; }\nchar *g(int a, char *b) { return b; }\nint h(const char *fmt, ...) { return 0; }\n";
+        assert_eq!(
+            params(&CParser::new().unwrap(), source, "f.c"),
+            vec![0, 2, 2]
+        );
+    }
+
+    #[test]
+    fn test_rust_and_swift() {
+        let rust = "struct S;\nimpl S {\n    fn m(&self, a: i32, (b, c): (i32, i32)) {}\n}\n";
+        assert_eq!(params(&RustParser::new().unwrap(), rust, "s.rs"), vec![2]);
+        let swift = "func add(_ a: Int, to b: Int) -> Int {\n    return a + b\n}\n";
+        assert_eq!(
+            params(&SwiftParser::new().unwrap(), swift, "a.swift"),
+            vec![2]
+        );
+    }
+
+    #[test]
+    fn test_php_promoted_variadic_and_closure_use() {
+        let source = "<?php\nclass A {\n    public function __construct(private int $a, $b = 1) {}\n}\nfunction f(...$xs) {}\n$g = function ($x) use ($y) {};\n$h = fn() => 1;\n";
+        assert_eq!(
+            params(&PhpParser::new().unwrap(), source, "a.php"),
+            vec![2, 1, 1, 0]
+        );
+    }
+
+    #[test]
+    fn test_cpp_defaults_packs_and_lambdas() {
+        let source = "const std::string& name(int id, bool full = false) { return names[id]; }\ntemplate <typename... Args>\nvoid log(const char* fmt, Args... args) {}\nint f(void) {\n    auto g = [] { return 0; };\n    auto h = [](int a, int b) { return a + b; };\n    return g();\n}\n";
+        assert_eq!(
+            params(&CppParser::new().unwrap(), source, "a.cpp"),
+            vec![2, 2, 0, 0, 2]
+        );
+    }
+
+    #[test]
+    fn test_scala_clauses_and_lambdas() {
+        let source = "object A {\n  def f(a: Int, b: Int)(implicit ord: Ordering[Int]): Int = a\n  def g: Int = 1\n  val h = (x: Int, y: Int) => x + y\n  val k: Int => Int = x => x\n}\n";
+        assert_eq!(
+            params(&ScalaParser::new().unwrap(), source, "A.scala"),
+            vec![3, 0, 2, 5]
+        );
+    }
+
+    #[test]
+    fn test_dart_optional_and_named() {
+        let source = "class A {\n  A(this.x, {required this.y}) {}\n  int get x2 => x * 2;\n  set x3(int v) {}\n  void f(int a, [int b = 0]) {}\n  void g({int? c, void Function(int)? onTap}) {\n    int h(int d) => d;\n  }\n}\n";
+        assert_eq!(
+            params(&DartParser::new().unwrap(), source, "a.dart"),
+            vec![2, 0, 1, 2, 2, 1]
+        );
+    }
+
+    #[test]
+    fn test_elixir_defaults_patterns_and_clauses() {
+        let source = "defmodule A do\n  def f(a, b \\\\ 1), do: a + b\n  def g, do: 1\n  def h(%{id: id}, [x | _]) when is_integer(id), do: x\n  defp k(0), do: 0\n  defp k(n), do: n\nend\n";
+        assert_eq!(
+            params(&ElixirParser::new().unwrap(), source, "a.ex"),
+            vec![2, 0, 2, 1]
+        );
+    }
+
+    #[test]
+    fn test_lua_varargs_and_methods() {
+        let source = "function f(a, b, ...) end
+function Account:deposit(v) end
+local g = function() end
+";
+        assert_eq!(
+            params(&LuaParser::new().unwrap(), source, "a.lua"),
+            vec![3, 1, 0]
+        );
+    }
+
+    #[test]
+    fn test_bash_positional_parameters() {
+        let source = r#"f() { echo "$1" "${3:-none}"; }
+g() { echo "$@"; }
+h() {
+  inner() { echo "$5"; }
+  echo "$2"
+}
+"#;
+        assert_eq!(
+            params(&BashParser::new().unwrap(), source, "a.sh"),
+            vec![3, 0, 2, 1]
+        );
+    }
+
+    #[test]
+    fn test_zig_comptime_and_self() {
+        let source = "fn f(a: i32, comptime T: type, b: T) void {}
+const S = struct {
+    fn m(self: *S, x: u8) void {}
+    fn new() S {}
+};
+";
+        assert_eq!(
+            params(&ZigParser::new().unwrap(), source, "a.zig"),
+            vec![3, 1, 0]
+        );
+    }
+
+    #[test]
+    fn test_haskell_equations_operators_and_point_free() {
+        let source = "area (Circle r) = pi * r * r
+area (Square s) = s * s
+a <+> b = a + b
+total = sum . map price
+zipPairs (x:xs) (y:ys) = (x, y) : zipPairs xs ys
+zipPairs _ _ = []
+";
+        assert_eq!(
+            params(&HaskellParser::new().unwrap(), source, "a.hs"),
+            vec![1, 2, 0, 2]
+        );
+    }
+
+    #[test]
+    fn test_perl_unpacking_and_signatures() {
+        let source = r#"sub add {
+    my ($x, $y) = @_;
+    return $x + $y;
+}
+sub new {
+    my $class = shift;
+    my $name = shift;
+    return bless { name => $name }, $class;
+}
+sub greet ($self, $greeting = "hello", @names) { }
+sub total {
+    my $sum = 0;
+    my ($items) = @_;
+}
+sub scale {
+    my $factor = shift;
+    my $limit = 10;
+}
+"#;
+        assert_eq!(
+            params(&PerlParser::new().unwrap(), source, "a.pl"),
+            vec![2, 1, 2, 0, 1]
+        );
+    }
+}
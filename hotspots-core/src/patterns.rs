@@ -14,6 +14,9 @@ pub struct Tier1Input {
     pub fo: usize,
     pub ns: usize,
     pub loc: usize,
+    /// Nesting levels the whole body is buried under (see `metrics::RawMetrics`).
+    /// `None` when only persisted metrics are available (snapshot re-classification).
+    pub arrow_depth: Option<usize>,
 }
 
 /// Input for Tier 2 (enriched) pattern classification.
@@ -36,6 +39,7 @@ pub struct Tier2Input {
 /// so the type signature accommodates overrides without any API change.
 #[derive(Debug, Clone)]
 pub struct Thresholds {
+    pub arrow_code_depth: usize,
     pub complex_branching_cc: usize,
     pub complex_branching_nd: usize,
     pub deeply_nested_nd: usize,
@@ -64,6 +68,7 @@ pub struct Thresholds {
 impl Default for Thresholds {
     fn default() -> Self {
         Thresholds {
+            arrow_code_depth: 3,
             complex_branching_cc: 10,
             complex_branching_nd: 4,
             deeply_nested_nd: 5,
@@ -136,6 +141,9 @@ pub fn classify_detailed(t1: &Tier1Input, t2: &Tier2Input, th: &Thresholds) -> V
     let churn = check_churn_magnet(t1, t2, th);
 
     // Tier 1 — alphabetical
+    if let Some(d) = check_arrow_code(t1, th) {
+        results.push(d);
+    }
     if let Some(d) = check_complex_branching(t1, th) {
         results.push(d);
     }
@@ -191,8 +199,68 @@ pub fn classify_detailed(t1: &Tier1Input, t2: &Tier2Input, th: &Thresholds) -> V
     results
 }
 
+/// Detail for a Tier 1 label kept from analysis whose input is no longer
+/// available (arrow depth is not persisted in snapshots).
+///
+/// `triggered_by` is empty because the measured value is unknown.
+pub fn carried_detail(id: &str) -> PatternDetail {
+    PatternDetail {
+        id: id.to_string(),
+        tier: 1,
+        kind: "primitive".to_string(),
+        triggered_by: vec![],
+    }
+}
+
+/// How a function's branching is shaped, independent of how much of it
+/// there is: two functions with equal CC can read very differently.
+#[derive(Debug, Clone, Copy, PartialEq, Eq, Serialize, Deserialize)]
+#[serde(rename_all = "snake_case")]
+pub enum StructureQuality {
+    /// Preconditions handled up front by guard clauses; the rest stays flat
+    EarlyReturn,
+    /// Logic buried under nested conditionals and loops
+    DeeplyNested,
+}
+
+/// Guard clauses needed for `EarlyReturn`
+pub const EARLY_RETURN_MIN_GUARDS: usize = 2;
+/// Deepest nesting still considered flat for `EarlyReturn`
+pub const EARLY_RETURN_MAX_ND: usize = 2;
+/// Nesting depth at which a function is `DeeplyNested`, guards or not
+pub const DEEPLY_NESTED_MIN_ND: usize = 4;
+
+/// Structure quality indicator from ND and the guard clause count.
+///
+/// `EarlyReturn` when at least [`EARLY_RETURN_MIN_GUARDS`] guards keep ND at or
+/// below [`EARLY_RETURN_MAX_ND`]; `DeeplyNested` at ND ≥ [`DEEPLY_NESTED_MIN_ND`];
+/// `None` otherwise. Informational only — it does not feed LRS.
+pub fn structure_quality(nd: usize, guard_clauses: usize) -> Option<StructureQuality> {
+    if nd >= DEEPLY_NESTED_MIN_ND {
+        Some(StructureQuality::DeeplyNested)
+    } else if guard_clauses >= EARLY_RETURN_MIN_GUARDS && nd <= EARLY_RETURN_MAX_ND {
+        Some(StructureQuality::EarlyReturn)
+    } else {
+        None
+    }
+}
+
 // ---------- Tier 1 helpers ----------
 
+fn check_arrow_code(t: &Tier1Input, th: &Thresholds) -> Option<PatternDetail> {
+    let depth = t.arrow_depth?;
+    if depth >= th.arrow_code_depth {
+        Some(PatternDetail {
+            id: "arrow_code".to_string(),
+            tier: 1,
+            kind: "primitive".to_string(),
+            triggered_by: vec![tb("arrow_depth", ">=", depth, th.arrow_code_depth)],
+        })
+    } else {
+        None
+    }
+}
+
 fn check_complex_branching(t: &Tier1Input, th: &Thresholds) -> Option<PatternDetail> {
     if t.cc >= th.complex_branching_cc && t.nd >= th.complex_branching_nd {
         Some(PatternDetail {
@@ -425,6 +493,14 @@ mod tests {
             fo,
             ns,
             loc,
+            arrow_depth: None,
+        }
+    }
+
+    fn t1_arrow(nd: usize, arrow_depth: usize) -> Tier1Input {
+        Tier1Input {
+            arrow_depth: Some(arrow_depth),
+            ..t1(1, nd, 0, 0, 10)
         }
     }
 
@@ -464,6 +540,35 @@ mod tests {
         Thresholds::default()
     }
 
+    // ---------- arrow_code ----------
+
+    #[test]
+    fn arrow_code_below_threshold() {
+        let p = classify(&t1_arrow(4, 2), &t2_none(), &th());
+        assert!(!has(&p, "arrow_code"));
+    }
+
+    #[test]
+    fn arrow_code_at_threshold() {
+        let p = classify(&t1_arrow(3, 3), &t2_none(), &th());
+        assert!(has(&p, "arrow_code"));
+    }
+
+    #[test]
+    fn arrow_code_needs_shape_not_just_depth() {
+        // Deep nesting alone (no arrow depth known / shallow shape) does not fire
+        let p = classify(&t1(1, 6, 0, 0, 10), &t2_none(), &th());
+        assert!(!has(&p, "arrow_code"));
+        let p = classify(&t1_arrow(6, 0), &t2_none(), &th());
+        assert!(!has(&p, "arrow_code"));
+    }
+
+    #[test]
+    fn arrow_code_sorts_first_in_tier1() {
+        let p = classify(&t1_arrow(5, 5), &t2_none(), &th());
+        assert_eq!(p, vec!["arrow_code", "deeply_nested"]);
+    }
+
     // ---------- complex_branching ----------
 
     #[test]
@@ -999,4 +1104,29 @@ mod tests {
             .collect();
         assert_eq!(ids, detail_ids);
     }
+
+    // ---------- structure quality ----------
+
+    #[test]
+    fn structure_quality_early_return() {
+        assert_eq!(structure_quality(1, 3), Some(StructureQuality::EarlyReturn));
+        assert_eq!(structure_quality(2, 2), Some(StructureQuality::EarlyReturn));
+        // One guard is not a style
+        assert_eq!(structure_quality(1, 1), None);
+        // Guards up front, but the rest still nests
+        assert_eq!(structure_quality(3, 2), None);
+    }
+
+    #[test]
+    fn structure_quality_deeply_nested() {
+        assert_eq!(
+            structure_quality(4, 0),
+            Some(StructureQuality::DeeplyNested)
+        );
+        assert_eq!(
+            structure_quality(5, 3),
+            Some(StructureQuality::DeeplyNested)
+        );
+        assert_eq!(structure_quality(3, 0), None);
+    }
 }
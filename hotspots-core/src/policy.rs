@@ -147,9 +147,11 @@ fn compare_policy_results(a: &PolicyResult, b: &PolicyResult) -> Ordering {
     }
 }
 
-/// Iterate over delta entries that have not been suppressed
+/// Iterate over delta entries that have not been suppressed or exempted
 fn active_deltas(deltas: &[FunctionDeltaEntry]) -> impl Iterator<Item = &FunctionDeltaEntry> {
-    deltas.iter().filter(|e| e.suppression_reason.is_none())
+    deltas
+        .iter()
+        .filter(|e| e.suppression_reason.is_none() && e.exempt_reason.is_none())
 }
 
 /// Evaluate all policies on a delta
@@ -282,8 +284,6 @@ fn evaluate_excessive_risk_regression(
         PolicyMode::Off => unreachable!("handled above"),
     };
 
-    const REGRESSION_THRESHOLD: f64 = 1.0;
-
     for entry in active_deltas(deltas) {
         // Only check Modified functions
         if entry.status != FunctionStatus::Modified {
@@ -292,7 +292,7 @@ fn evaluate_excessive_risk_regression(
 
         // Check if delta.lrs exceeds threshold
         if let Some(delta) = &entry.delta {
-            if delta.lrs >= REGRESSION_THRESHOLD {
+            if delta.lrs >= crate::delta::REGRESSION_LRS_THRESHOLD {
                 let message = format!(
                     "Function {} regressed by {:.2} LRS",
                     entry.function_id, delta.lrs
@@ -579,11 +579,28 @@ mod tests {
                 cc: 4,
                 nd: 2,
                 fo: 2,
+                fi: 0,
                 ns: 1,
                 loc: 10,
+                signature_complexity: 0,
+                params: 0,
+                guard_clauses: 0,
+                max_condition_ops: 0,
+                cognitive: 0,
+                halstead: None,
+                maintainability: None,
+                sloc: None,
+                comment_lines: None,
+                blank_lines: None,
+                nd_line: None,
+                cc_lines: None,
+                is_async: false,
+                await_in_loop: 0,
+                ns_breakdown: None,
             },
             lrs: 3.9,
             band: RiskBand::parse(band).unwrap_or(RiskBand::Low),
+            cc_breakdown: None,
         });
 
         let after = after_band.map(|band| FunctionState {
@@ -591,11 +608,28 @@ mod tests {
                 cc: 6,
                 nd: 3,
                 fo: 3,
+                fi: 0,
                 ns: 1,
                 loc: 15,
+                signature_complexity: 0,
+                params: 0,
+                guard_clauses: 0,
+                max_condition_ops: 0,
+                cognitive: 0,
+                halstead: None,
+                maintainability: None,
+                sloc: None,
+                comment_lines: None,
+                blank_lines: None,
+                nd_line: None,
+                cc_lines: None,
+                is_async: false,
+                await_in_loop: 0,
+                ns_breakdown: None,
             },
             lrs: if band == "critical" { 10.5 } else { 6.2 },
             band: RiskBand::parse(band).unwrap_or(RiskBand::Low),
+            cc_breakdown: None,
         });
 
         let delta = delta_lrs.map(|lrs| FunctionDelta {
@@ -615,6 +649,8 @@ mod tests {
             band_transition: None,
             suppression_reason: None,
             rename_hint: None,
+            exempt_reason: None,
+            explanation: None,
         }
     }
 
@@ -995,8 +1031,24 @@ mod tests {
                 cc: 4,
                 nd: 2,
                 fo: 2,
+                fi: 0,
                 ns: 1,
                 loc: 10,
+                signature_complexity: 0,
+                params: 0,
+                guard_clauses: 0,
+                max_condition_ops: 0,
+                cognitive: 0,
+                halstead: None,
+                maintainability: None,
+                sloc: None,
+                comment_lines: None,
+                blank_lines: None,
+                nd_line: None,
+                cc_lines: None,
+                is_async: false,
+                await_in_loop: 0,
+                ns_breakdown: None,
             },
             lrs,
             band: if lrs >= 9.0 {
@@ -1008,6 +1060,7 @@ mod tests {
             } else {
                 RiskBand::Low
             },
+            cc_breakdown: None,
         });
 
         let after = after_lrs.map(|lrs| FunctionState {
@@ -1015,8 +1068,24 @@ mod tests {
                 cc: 6,
                 nd: 3,
                 fo: 3,
+                fi: 0,
                 ns: 1,
                 loc: 15,
+                signature_complexity: 0,
+                params: 0,
+                guard_clauses: 0,
+                max_condition_ops: 0,
+                cognitive: 0,
+                halstead: None,
+                maintainability: None,
+                sloc: None,
+                comment_lines: None,
+                blank_lines: None,
+                nd_line: None,
+                cc_lines: None,
+                is_async: false,
+                await_in_loop: 0,
+                ns_breakdown: None,
             },
             lrs,
             band: if lrs >= 9.0 {
@@ -1028,6 +1097,7 @@ mod tests {
             } else {
                 RiskBand::Low
             },
+            cc_breakdown: None,
         });
 
         let delta = match (before_lrs, after_lrs) {
@@ -1050,6 +1120,8 @@ mod tests {
             band_transition: None,
             suppression_reason: None,
             rename_hint: None,
+            exempt_reason: None,
+            explanation: None,
         }
     }
 
@@ -3,6 +3,10 @@
 //! Global invariants enforced:
 //! - Deterministic output ordering
 //! - Byte-for-byte identical output across runs
+//!
+//! JSON, JSONL, and SARIF output carries a `schema_version` and the
+//! `tool_version` that wrote it, so consumers can detect a shape they were not
+//! written for instead of misparsing it.
 
 use crate::ast::FunctionNode;
 use crate::language::Language;
@@ -11,18 +15,70 @@ use crate::risk::{RiskBand, RiskComponents};
 use owo_colors::OwoColorize;
 use serde::{Deserialize, Serialize};
 
+/// Version of the report output's shape (`analyze --format json` and
+/// `jsonl`, SARIF). Incremented whenever a field is added, removed, renamed,
+/// or changes type. Snapshot, delta, and diff output carry their own
+/// `schema_version`.
+pub const REPORT_SCHEMA_VERSION: u32 = 2;
+
+/// An output object with `schema_version` and `tool_version` ahead of its own
+/// fields
+#[derive(Serialize)]
+pub(crate) struct Versioned<'a, T> {
+    schema_version: u32,
+    tool_version: &'a str,
+    #[serde(flatten)]
+    output: &'a T,
+}
+
+impl<'a, T> Versioned<'a, T> {
+    pub(crate) fn new(schema_version: u32, tool_version: &'a str, output: &'a T) -> Self {
+        Versioned {
+            schema_version,
+            tool_version,
+            output,
+        }
+    }
+}
+
+/// An output object that has a `schema_version` of its own, with
+/// `tool_version` after its fields
+#[derive(Serialize)]
+pub(crate) struct WithToolVersion<'a, T> {
+    #[serde(flatten)]
+    output: &'a T,
+    tool_version: &'a str,
+}
+
+impl<'a, T> WithToolVersion<'a, T> {
+    pub(crate) fn new(output: &'a T, tool_version: &'a str) -> Self {
+        WithToolVersion {
+            output,
+            tool_version,
+        }
+    }
+}
+
 /// Complete risk report for a function
 #[derive(Debug, Clone, Serialize, Deserialize)]
 #[serde(rename_all = "snake_case")]
 pub struct FunctionRiskReport {
     pub file: String,
     pub function: String,
+    /// Type the function is a method of (see `FunctionNode::owner`); omitted
+    /// for free functions
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub owner: Option<String>,
     pub line: u32,
     pub language: Language,
     pub metrics: MetricsReport,
     pub risk: RiskReport,
     pub lrs: f64,
     pub band: RiskBand,
+    /// Composite risk score in [0, 1] (see `risk::calculate_risk_score`).
+    /// Only computed for `--sort risk-score`; omitted otherwise.
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub risk_score: Option<f64>,
     #[serde(skip_serializing_if = "Option::is_none")]
     pub suppression_reason: Option<String>,
     #[serde(skip_serializing_if = "Vec::is_empty", default)]
@@ -33,16 +89,215 @@ pub struct FunctionRiskReport {
     pub callees: Vec<String>,
     #[serde(skip_serializing_if = "Option::is_none")]
     pub explanation: Option<String>,
+    /// Arrow depth from analysis; kept so `--explain-patterns` can re-derive
+    /// `arrow_code` details. Not serialized.
+    #[serde(skip, default)]
+    pub arrow_depth: usize,
+    /// Other paths (symlinks) resolving to this function's file. Only populated
+    /// with `dedup_symlinks`; the function is analyzed and counted once.
+    #[serde(skip_serializing_if = "Vec::is_empty", default)]
+    pub aliases: Vec<String>,
+    /// Whether branching is flattened by guard clauses or buried in nesting
+    /// (see `patterns::structure_quality`). Omitted when neither.
+    #[serde(skip_serializing_if = "Option::is_none", default)]
+    pub structure: Option<crate::patterns::StructureQuality>,
+    /// Decision points behind CC, per construct; carried into snapshots so
+    /// `--explain-diff` can say what changed. Not serialized.
+    #[serde(skip, default)]
+    pub cc_breakdown: Option<crate::metrics::CcBreakdown>,
+    /// Part of its file's public API (see `FunctionNode::is_public`); lets
+    /// `--dead-code` skip functions callers outside the analyzed code may
+    /// use. Not serialized.
+    #[serde(skip, default)]
+    pub is_public: bool,
+    /// Decision points outside every function in its file (see
+    /// `ParsedModule::top_level_decisions`); carried into snapshots for
+    /// `file_cc`. Not serialized.
+    #[serde(skip, default)]
+    pub file_top_level_decisions: usize,
 }
 
 /// Metrics in report format
-#[derive(Debug, Clone, Serialize, Deserialize, PartialEq, Eq)]
+#[derive(Debug, Clone, Serialize, Deserialize, PartialEq)]
 pub struct MetricsReport {
     pub cc: u32,
+    /// Cognitive complexity. Not part of LRS; 0 when loaded from snapshots
+    /// that predate it.
+    #[serde(default)]
+    pub cognitive: u32,
     pub nd: u32,
     pub fo: u32,
+    /// Fan-in: distinct analyzed functions that call this one. Only computed
+    /// with `fan_in` (see `annotate_fan_in`); not part of LRS; omitted when 0.
+    #[serde(default, skip_serializing_if = "is_zero")]
+    pub fi: u32,
     pub ns: u32,
     pub loc: u32,
+    /// Type-parameter count plus deepest type nesting in the signature (Rust,
+    /// TypeScript, C#). Not part of LRS; omitted when 0.
+    #[serde(default, skip_serializing_if = "is_zero")]
+    pub signature_complexity: u32,
+    /// Declared parameters, excluding receivers (see `params`). Not part of
+    /// LRS; omitted when 0.
+    #[serde(default, skip_serializing_if = "is_zero")]
+    pub params: u32,
+    /// Leading early-exit guards (`if (!x) return;`) at shallow depth. Not
+    /// part of LRS; omitted when 0.
+    #[serde(default, skip_serializing_if = "is_zero")]
+    pub guard_clauses: u32,
+    /// Most `&&` / `||` operators in a single boolean expression. Not part of
+    /// LRS; omitted when 0.
+    #[serde(default, skip_serializing_if = "is_zero")]
+    pub max_condition_ops: u32,
+    /// Declared `async` (JavaScript/TypeScript). Not part of LRS; serialized
+    /// as `async` and omitted when false.
+    #[serde(rename = "async", default, skip_serializing_if = "std::ops::Not::not")]
+    pub is_async: bool,
+    /// `await` expressions inside loop bodies, each one waiting for the
+    /// previous iteration (JavaScript/TypeScript). Not part of LRS; omitted
+    /// when 0.
+    #[serde(default, skip_serializing_if = "is_zero")]
+    pub await_in_loop: u32,
+    /// Halstead operator/operand counts, volume, difficulty, and effort. Not
+    /// part of LRS; only computed with `--halstead`, omitted otherwise.
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub halstead: Option<crate::halstead::HalsteadMetrics>,
+    /// Maintainability index (0–100, lower is worse) from Halstead volume,
+    /// CC, and LOC; present exactly when `halstead` is.
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub maintainability: Option<f64>,
+    /// Lines of `loc` holding code, only comments, or nothing (see
+    /// `lines`). Only computed with `--line-counts`, omitted otherwise.
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub sloc: Option<u32>,
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub comment_lines: Option<u32>,
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub blank_lines: Option<u32>,
+    /// Line where `nd` is reached: the first construct at the deepest level.
+    /// Only recorded with `--explain`; omitted otherwise and when `nd` is 0.
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub nd_line: Option<u32>,
+    /// The function's first line and the lines holding the decision points
+    /// behind `cc`. Only recorded with `--explain`; omitted otherwise, for
+    /// SQL, and when the body could not be re-parsed.
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub cc_lines: Option<CcLines>,
+    /// `ns` per kind of exit (`return`, `throw`, `break`, …). Only recorded
+    /// with `--ns-breakdown`; omitted otherwise.
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub ns_breakdown: Option<crate::metrics::NsBreakdown>,
+}
+
+fn is_zero(n: &u32) -> bool {
+    *n == 0
+}
+
+/// Where a function's CC comes from, line by line
+#[derive(Debug, Clone, Serialize, Deserialize, PartialEq)]
+pub struct CcLines {
+    /// Source text of the function's first line, trimmed
+    pub signature: String,
+    /// Lines holding decision points, in line order
+    pub lines: Vec<CcLine>,
+}
+
+/// A source line holding decision points that add to CC
+#[derive(Debug, Clone, Serialize, Deserialize, PartialEq)]
+pub struct CcLine {
+    pub line: u32,
+    /// [`DecisionKind::name`](crate::metrics::DecisionKind::name) of each
+    /// decision point on the line, in source order
+    pub kinds: Vec<String>,
+    /// Source text of the line, trimmed
+    pub text: String,
+}
+
+impl CcLines {
+    /// Group `decisions` by file line, taking each line's text from `src`,
+    /// the source of the file holding `function`
+    pub fn new(
+        function: &FunctionNode,
+        decisions: &[crate::metrics::Decision],
+        src: &str,
+        source_map: &swc_common::SourceMap,
+    ) -> Self {
+        let text_of = |line: u32| {
+            src.lines()
+                .nth(line.saturating_sub(1) as usize)
+                .unwrap_or("")
+                .trim()
+                .to_string()
+        };
+        let mut by_line: std::collections::BTreeMap<u32, Vec<String>> =
+            std::collections::BTreeMap::new();
+        for decision in decisions {
+            let line = crate::metrics::nest_line(function, decision.position, source_map);
+            by_line
+                .entry(line)
+                .or_default()
+                .push(decision.kind.name().to_string());
+        }
+        CcLines {
+            signature: text_of(function.start_line(source_map)),
+            lines: by_line
+                .into_iter()
+                .map(|(line, kinds)| CcLine {
+                    line,
+                    kinds,
+                    text: text_of(line),
+                })
+                .collect(),
+        }
+    }
+}
+
+/// Render `--explain`'s annotated source for a function: its first line,
+/// then each line with decision points, labeled with their kinds
+/// (`if, and`; a kind repeated on one line reads `and ×2`). Empty without
+/// `cc_lines` or decision points.
+pub fn render_cc_lines(line: u32, metrics: &MetricsReport) -> String {
+    let Some(cc_lines) = metrics.cc_lines.as_ref().filter(|c| !c.lines.is_empty()) else {
+        return String::new();
+    };
+    let label = |kinds: &[String]| {
+        let mut parts: Vec<(&str, usize)> = Vec::new();
+        for kind in kinds {
+            match parts.last_mut() {
+                Some((last, n)) if *last == kind.as_str() => *n += 1,
+                _ => parts.push((kind.as_str(), 1)),
+            }
+        }
+        parts
+            .iter()
+            .map(|(kind, n)| {
+                if *n == 1 {
+                    kind.to_string()
+                } else {
+                    format!("{kind} \u{d7}{n}")
+                }
+            })
+            .collect::<Vec<_>>()
+            .join(", ")
+    };
+    let labels: Vec<String> = cc_lines.lines.iter().map(|l| label(&l.kinds)).collect();
+    let last_line = cc_lines.lines.last().map_or(line, |l| l.line);
+    let line_w = last_line.max(line).to_string().len();
+    let label_w = labels.iter().map(|l| l.chars().count()).max().unwrap_or(0);
+
+    let mut s = format!("         decision points (CC {}):\n", metrics.cc);
+    s.push_str(&format!(
+        "         {:>line_w$}  {:label_w$}  {}\n",
+        line, "", cc_lines.signature
+    ));
+    for (cc_line, label) in cc_lines.lines.iter().zip(&labels) {
+        let pad = label_w - label.chars().count();
+        s.push_str(&format!(
+            "         {:>line_w$}  {label}{:pad$}  {}\n",
+            cc_line.line, "", cc_line.text
+        ));
+    }
+    s
 }
 
 /// Risk components in report format
@@ -95,14 +350,31 @@ impl FunctionRiskReport {
         FunctionRiskReport {
             file,
             function: function_name,
+            owner: function.owner.clone(),
             line,
             language,
             metrics: MetricsReport {
                 cc: analysis.metrics.cc as u32,
+                cognitive: analysis.metrics.cognitive as u32,
                 nd: analysis.metrics.nd as u32,
                 fo: analysis.metrics.fo as u32,
+                fi: 0,
                 ns: analysis.metrics.ns as u32,
                 loc: analysis.metrics.loc as u32,
+                signature_complexity: analysis.metrics.signature_complexity as u32,
+                params: function.params as u32,
+                guard_clauses: analysis.metrics.guard_clauses as u32,
+                max_condition_ops: analysis.metrics.max_condition_ops as u32,
+                halstead: None,
+                maintainability: None,
+                sloc: None,
+                comment_lines: None,
+                blank_lines: None,
+                nd_line: None,
+                cc_lines: None,
+                is_async: function.is_async,
+                await_in_loop: analysis.metrics.await_in_loop as u32,
+                ns_breakdown: None,
             },
             risk: RiskReport {
                 r_cc: analysis.risk.r_cc,
@@ -112,30 +384,115 @@ impl FunctionRiskReport {
             },
             lrs: analysis.lrs,
             band: analysis.band,
+            risk_score: None,
             suppression_reason: function.suppression_reason.clone(),
             patterns: analysis.patterns,
             pattern_details: None,
             callees: analysis.metrics.callee_names,
             explanation: None,
+            arrow_depth: analysis.metrics.arrow_depth,
+            aliases: vec![],
+            structure: crate::patterns::structure_quality(
+                analysis.metrics.nd,
+                analysis.metrics.guard_clauses,
+            ),
+            cc_breakdown: analysis.metrics.cc_breakdown,
+            is_public: function.is_public,
+            file_top_level_decisions: 0,
         }
     }
 }
 
 /// Sort reports deterministically
 pub fn sort_reports(mut reports: Vec<FunctionRiskReport>) -> Vec<FunctionRiskReport> {
+    reports.sort_by(canonical_order);
+    reports
+}
+
+/// Canonical report order used by [`sort_reports`]. Total over distinct
+/// functions, so the result never depends on the order reports arrive in.
+pub(crate) fn canonical_order(
+    a: &FunctionRiskReport,
+    b: &FunctionRiskReport,
+) -> std::cmp::Ordering {
+    // 1. LRS descending
+    b.lrs
+        .partial_cmp(&a.lrs)
+        .unwrap_or(std::cmp::Ordering::Equal)
+        // 2. Location
+        .then_with(|| location_order(a, b))
+}
+
+/// Tie-break for every report order: file path, then start line, then
+/// function name, all ascending. Sorts by any key end with this, so functions
+/// with equal values come out the same on every run and in every format,
+/// whatever order the files were walked or analyzed in.
+pub fn location_order(a: &FunctionRiskReport, b: &FunctionRiskReport) -> std::cmp::Ordering {
+    a.file
+        .cmp(&b.file)
+        .then_with(|| a.line.cmp(&b.line))
+        .then_with(|| a.function.cmp(&b.function))
+}
+
+/// Report metric a function list can be ordered by (`--sort`)
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub enum SortMetric {
+    Lrs,
+    Cc,
+    Nd,
+    Fo,
+    Ns,
+    Cognitive,
+}
+
+impl SortMetric {
+    fn value(self, report: &FunctionRiskReport) -> f64 {
+        let m = &report.metrics;
+        match self {
+            SortMetric::Lrs => report.lrs,
+            SortMetric::Cc => m.cc as f64,
+            SortMetric::Nd => m.nd as f64,
+            SortMetric::Fo => m.fo as f64,
+            SortMetric::Ns => m.ns as f64,
+            SortMetric::Cognitive => m.cognitive as f64,
+        }
+    }
+}
+
+/// Order `reports` by `metric`, highest first unless `ascending`. Ties are
+/// broken by [`location_order`] in either direction.
+pub fn sort_reports_by(reports: &mut [FunctionRiskReport], metric: SortMetric, ascending: bool) {
     reports.sort_by(|a, b| {
-        // 1. LRS descending
-        b.lrs
-            .partial_cmp(&a.lrs)
-            .unwrap_or(std::cmp::Ordering::Equal)
-            // 2. File path ascending
-            .then_with(|| a.file.cmp(&b.file))
-            // 3. Line number ascending
-            .then_with(|| a.line.cmp(&b.line))
-            // 4. Function name ascending
-            .then_with(|| a.function.cmp(&b.function))
+        let by_metric = metric
+            .value(a)
+            .partial_cmp(&metric.value(b))
+            .unwrap_or(std::cmp::Ordering::Equal);
+        let by_metric = if ascending {
+            by_metric
+        } else {
+            by_metric.reverse()
+        };
+        by_metric.then_with(|| location_order(a, b))
     });
-    reports
+}
+
+/// Fill in each report's `risk_score` under `weights`; `churn` gives a
+/// function's churn when it is known.
+pub fn annotate_risk_scores(
+    reports: &mut [FunctionRiskReport],
+    weights: &crate::risk::RiskScoreWeights,
+    churn: impl Fn(&FunctionRiskReport) -> Option<usize>,
+) {
+    for report in reports.iter_mut() {
+        let risk = crate::risk::RiskComponents {
+            r_cc: report.risk.r_cc,
+            r_nd: report.risk.r_nd,
+            r_fo: report.risk.r_fo,
+            r_ns: report.risk.r_ns,
+        };
+        let score = crate::risk::calculate_risk_score(&risk, churn(report), weights);
+        report.risk_score = Some(score);
+    }
 }
 
 /// Render reports as text output
@@ -202,8 +559,15 @@ pub fn render_text(reports: &[FunctionRiskReport]) -> String {
 /// Render reports grouped by risk band (CRITICAL → HIGH → MODERATE/LOW).
 ///
 /// MODERATE and LOW are omitted unless `limit` is `usize::MAX` (i.e. `--top 0`).
-/// `color` enables ANSI codes — pass `false` when stdout is not a TTY.
-pub fn render_text_grouped(reports: &[FunctionRiskReport], limit: usize, color: bool) -> String {
+/// `more` is how many functions `--top` cut from `reports`, noted as
+/// "... N more" when not 0. `color` enables ANSI codes — pass `false` when
+/// stdout is not a TTY.
+pub fn render_text_grouped(
+    reports: &[FunctionRiskReport],
+    limit: usize,
+    more: usize,
+    color: bool,
+) -> String {
     let show_all = limit == usize::MAX;
     let mut output = String::new();
     let cwd = std::env::current_dir().ok();
@@ -275,6 +639,15 @@ pub fn render_text_grouped(reports: &[FunctionRiskReport], limit: usize, color:
             if let Some(exp) = &r.explanation {
                 s.push_str(&format!("         \u{2726} {}\n", exp));
             }
+            if let Some(nd_line) = r.metrics.nd_line {
+                s.push_str(&format!(
+                    "         deepest nesting (ND {}): {}:{}\n",
+                    r.metrics.nd,
+                    rel(&r.file),
+                    nd_line
+                ));
+            }
+            s.push_str(&render_cc_lines(r.line, &r.metrics));
         }
         s.push('\n');
         s
@@ -318,6 +691,10 @@ pub fn render_text_grouped(reports: &[FunctionRiskReport], limit: usize, color:
         ));
     }
 
+    if more > 0 {
+        output.push_str(&format!("... {} more\n\n", more));
+    }
+
     let sep = "─".repeat(60);
     output.push_str(&sep);
     output.push('\n');
@@ -335,12 +712,121 @@ pub fn render_text_grouped(reports: &[FunctionRiskReport], limit: usize, color:
     output
 }
 
-/// Render reports as JSON output
+/// Render one page of a sorted report list (`--sort`, `--offset`) as a flat
+/// table with each function's rank, LRS, and the sortable metrics.
+///
+/// `offset` is how many functions precede the page, so ranks start at
+/// `offset + 1`; `more` is how many follow it, noted as "... N more".
+pub fn render_text_ranked(reports: &[FunctionRiskReport], offset: usize, more: usize) -> String {
+    let cwd = std::env::current_dir().ok();
+    let location = |r: &FunctionRiskReport| -> String {
+        let file = cwd
+            .as_ref()
+            .and_then(|cwd| std::path::Path::new(&r.file).strip_prefix(cwd).ok())
+            .map(|p| p.to_string_lossy().into_owned())
+            .unwrap_or_else(|| r.file.clone());
+        format!("{}:{}", file, r.line)
+    };
+    let locations: Vec<String> = reports.iter().map(location).collect();
+    let col_w = locations
+        .iter()
+        .map(|l| l.len())
+        .max()
+        .unwrap_or(0)
+        .clamp("LOCATION".len(), 55);
+
+    let mut output = format!(
+        "{:>5}  {:>6}  {:>4}  {:>3}  {:>3}  {:>3}  {:>4}  {:<col_w$}  {}\n",
+        "RANK", "LRS", "CC", "ND", "FO", "NS", "COG", "LOCATION", "FUNCTION"
+    );
+    for (i, (r, loc)) in reports.iter().zip(&locations).enumerate() {
+        let m = &r.metrics;
+        output.push_str(&format!(
+            "{:>5}  {:>6.2}  {:>4}  {:>3}  {:>3}  {:>3}  {:>4}  {:<col_w$}  {}\n",
+            offset + i + 1,
+            r.lrs,
+            m.cc,
+            m.nd,
+            m.fo,
+            m.ns,
+            m.cognitive,
+            loc,
+            r.function
+        ));
+    }
+    if more > 0 {
+        output.push_str(&format!("... {} more\n", more));
+    }
+    output
+}
+
+/// Render reports as a bare JSON array, the `--save-baseline` file format
 pub fn render_json(reports: &[FunctionRiskReport]) -> String {
     // Use serde_json with sorted keys for deterministic output
     serde_json::to_string_pretty(reports).unwrap_or_else(|_| "[]".to_string())
 }
 
+/// JSON document printed by `--format json` in the default output mode
+#[derive(Serialize)]
+struct JsonReports<'a> {
+    schema_version: u32,
+    tool_version: &'a str,
+    truncated: bool,
+    total_functions: usize,
+    functions: &'a [FunctionRiskReport],
+}
+
+/// Render reports as the `--format json` document: `schema_version`,
+/// `tool_version`, `truncated` (always false), and `total_functions` alongside
+/// the `functions` array. `tool_version` is the version of the hotspots
+/// binary writing the output.
+pub fn render_json_report(reports: &[FunctionRiskReport], tool_version: &str) -> String {
+    render_json_capped(reports, reports.len(), tool_version)
+}
+
+/// Like [`render_json_report`], with at most `max_results` functions;
+/// `truncated` and `total_functions` say whether any were cut.
+///
+/// `reports` must already be sorted by risk (see `sort_reports`).
+pub fn render_json_capped(
+    reports: &[FunctionRiskReport],
+    max_results: usize,
+    tool_version: &str,
+) -> String {
+    let document = JsonReports {
+        schema_version: REPORT_SCHEMA_VERSION,
+        tool_version,
+        truncated: reports.len() > max_results,
+        total_functions: reports.len(),
+        functions: &reports[..reports.len().min(max_results)],
+    };
+    serde_json::to_string_pretty(&document).unwrap_or_else(|_| "{}".to_string())
+}
+
+/// One line of `--format jsonl` output in the default output mode
+#[derive(Serialize)]
+struct JsonlReport<'a> {
+    schema_version: u32,
+    tool_version: &'a str,
+    #[serde(flatten)]
+    report: &'a FunctionRiskReport,
+    /// Last line of the function, from `line` and `loc`
+    end_line: u32,
+}
+
+/// Render a report as one line of JSON (without the newline) that stands on
+/// its own: `schema_version`, `tool_version`, the report's fields, and
+/// `end_line`
+pub fn render_jsonl_line(report: &FunctionRiskReport, tool_version: &str) -> String {
+    let line = JsonlReport {
+        schema_version: REPORT_SCHEMA_VERSION,
+        tool_version,
+        report,
+        end_line: report.line + report.metrics.loc.saturating_sub(1),
+    };
+    serde_json::to_string(&line).unwrap_or_else(|_| "{}".to_string())
+}
+
 /// Truncate or pad string to fixed width
 fn truncate_or_pad(s: &str, width: usize) -> String {
     if s.len() > width {
@@ -360,14 +846,31 @@ mod tests {
         FunctionRiskReport {
             file: file.to_string(),
             function: function.to_string(),
+            owner: None,
             line,
             language: Language::TypeScript,
             metrics: MetricsReport {
                 cc: 5,
                 nd: 1,
                 fo: 2,
+                fi: 0,
                 ns: 0,
                 loc: 20,
+                signature_complexity: 0,
+                params: 0,
+                guard_clauses: 0,
+                max_condition_ops: 0,
+                cognitive: 0,
+                halstead: None,
+                maintainability: None,
+                sloc: None,
+                comment_lines: None,
+                blank_lines: None,
+                nd_line: None,
+                cc_lines: None,
+                is_async: false,
+                await_in_loop: 0,
+                ns_breakdown: None,
             },
             risk: RiskReport {
                 r_cc: 1.0,
@@ -377,14 +880,72 @@ mod tests {
             },
             lrs,
             band: RiskBand::High,
+            risk_score: None,
             suppression_reason: None,
             patterns: vec![],
             pattern_details: None,
             callees: vec![],
             explanation: None,
+            arrow_depth: 0,
+            aliases: vec![],
+            structure: None,
+            cc_breakdown: None,
+            is_public: false,
+            file_top_level_decisions: 0,
         }
     }
 
+    #[test]
+    fn test_risk_score_weights_reorder_functions() {
+        // Branchy but flat, versus few branches nested deep
+        let mut branchy = make_report("src/a.ts", "branchy", 1, 4.0);
+        branchy.risk = RiskReport {
+            r_cc: 5.0,
+            r_nd: 1.0,
+            r_fo: 0.0,
+            r_ns: 0.0,
+        };
+        let mut nested = make_report("src/b.ts", "nested", 1, 4.0);
+        nested.risk = RiskReport {
+            r_cc: 2.0,
+            r_nd: 6.0,
+            r_fo: 0.0,
+            r_ns: 0.0,
+        };
+        let ranked = |weights: crate::risk::RiskScoreWeights| {
+            let mut reports = vec![branchy.clone(), nested.clone()];
+            annotate_risk_scores(&mut reports, &weights, |_| None);
+            reports.sort_by(|a, b| b.risk_score.partial_cmp(&a.risk_score).unwrap());
+            reports
+                .iter()
+                .map(|r| r.function.clone())
+                .collect::<Vec<_>>()
+        };
+
+        let cc_heavy = crate::risk::RiskScoreWeights {
+            cc: 3.0,
+            nd: 0.5,
+            ..Default::default()
+        };
+        assert_eq!(ranked(cc_heavy), ["branchy", "nested"]);
+        let nd_heavy = crate::risk::RiskScoreWeights {
+            cc: 0.5,
+            nd: 3.0,
+            ..Default::default()
+        };
+        assert_eq!(ranked(nd_heavy), ["nested", "branchy"]);
+
+        // Scores are normalized into [0, 1], and churn counts when known
+        let mut reports = vec![branchy.clone()];
+        let weights = crate::risk::RiskScoreWeights::default();
+        annotate_risk_scores(&mut reports, &weights, |_| None);
+        let without_churn = reports[0].risk_score.unwrap();
+        annotate_risk_scores(&mut reports, &weights, |_| Some(63));
+        let with_churn = reports[0].risk_score.unwrap();
+        assert!((0.0..=1.0).contains(&without_churn));
+        assert!(with_churn > without_churn && with_churn <= 1.0);
+    }
+
     #[test]
     fn test_render_text_grouped_groups_by_band() {
         let mut critical = make_report("/repo/src/a.ts", "foo", 10, 12.0);
@@ -392,7 +953,7 @@ mod tests {
         let mut high = make_report("/repo/src/b.ts", "bar", 20, 7.0);
         high.band = RiskBand::High;
         let reports = vec![critical, high];
-        let out = render_text_grouped(&reports, 20, false);
+        let out = render_text_grouped(&reports, 20, 0, false);
         let crit_pos = out.find("CRITICAL").unwrap_or(usize::MAX);
         let high_pos = out.find("HIGH").unwrap_or(usize::MAX);
         assert!(
@@ -409,7 +970,7 @@ mod tests {
         r1.band = RiskBand::Critical;
         let mut r2 = make_report("/repo/src/b.ts", "bar", 20, 7.0);
         r2.band = RiskBand::High;
-        let out = render_text_grouped(&[r1, r2], 20, false);
+        let out = render_text_grouped(&[r1, r2], 20, 0, false);
         assert!(
             out.contains("2 functions shown"),
             "footer should show shown count"
@@ -420,7 +981,7 @@ mod tests {
     fn test_render_text_grouped_lower_omitted_by_default() {
         let mut r = make_report("/repo/src/a.ts", "foo", 10, 2.0);
         r.band = RiskBand::Low;
-        let out = render_text_grouped(&[r], 20, false);
+        let out = render_text_grouped(&[r], 20, 0, false);
         assert!(
             !out.contains("foo"),
             "low band should be omitted by default"
@@ -435,7 +996,7 @@ mod tests {
     fn test_render_text_grouped_lower_shown_when_show_all() {
         let mut r = make_report("/repo/src/a.ts", "foo", 10, 2.0);
         r.band = RiskBand::Low;
-        let out = render_text_grouped(&[r], usize::MAX, false);
+        let out = render_text_grouped(&[r], usize::MAX, 0, false);
         assert!(
             out.contains("foo"),
             "low band should be shown with show-all"
@@ -448,13 +1009,13 @@ mod tests {
         let mut r = make_report("/repo/src/a.ts", "foo", 10, 12.0);
         r.band = RiskBand::Critical;
         r.patterns = vec!["god_function".to_string(), "exit_heavy".to_string()];
-        let out = render_text_grouped(&[r], 20, false);
+        let out = render_text_grouped(&[r], 20, 0, false);
         assert!(out.contains("[god_function, exit_heavy]"));
     }
 
     #[test]
     fn test_render_text_grouped_empty() {
-        let out = render_text_grouped(&[], 20, false);
+        let out = render_text_grouped(&[], 20, 0, false);
         assert!(out.contains("0 functions shown"));
     }
 
@@ -462,7 +1023,7 @@ mod tests {
     fn test_render_text_grouped_color_emits_ansi() {
         let mut r = make_report("/repo/src/a.ts", "critical_fn", 1, 12.0);
         r.band = RiskBand::Critical;
-        let out = render_text_grouped(&[r], 10, true);
+        let out = render_text_grouped(&[r], 10, 0, true);
         assert!(
             out.contains("\x1b["),
             "color=true should emit ANSI escape codes"
@@ -473,10 +1034,129 @@ mod tests {
     fn test_render_text_grouped_no_color_plain() {
         let mut r = make_report("/repo/src/a.ts", "critical_fn", 1, 12.0);
         r.band = RiskBand::Critical;
-        let out = render_text_grouped(&[r], 10, false);
+        let out = render_text_grouped(&[r], 10, 0, false);
         assert!(
             !out.contains("\x1b["),
             "color=false must not emit ANSI escape codes"
         );
     }
+
+    #[test]
+    fn test_render_json_capped_marks_truncation() {
+        let reports = vec![
+            make_report("/repo/src/a.ts", "foo", 10, 12.0),
+            make_report("/repo/src/b.ts", "bar", 20, 7.0),
+            make_report("/repo/src/c.ts", "baz", 30, 3.0),
+        ];
+        let v: serde_json::Value =
+            serde_json::from_str(&render_json_capped(&reports, 2, "1.2.3")).unwrap();
+        assert_eq!(v["truncated"], true);
+        assert_eq!(v["total_functions"], 3);
+        let functions = v["functions"].as_array().unwrap();
+        assert_eq!(functions.len(), 2);
+        assert_eq!(functions[0]["function"], "foo");
+        assert_eq!(functions[1]["function"], "bar");
+    }
+
+    #[test]
+    fn test_render_json_capped_under_limit() {
+        let reports = vec![make_report("/repo/src/a.ts", "foo", 10, 12.0)];
+        let v: serde_json::Value =
+            serde_json::from_str(&render_json_capped(&reports, 5, "1.2.3")).unwrap();
+        assert_eq!(v["truncated"], false);
+        assert_eq!(v["total_functions"], 1);
+        assert_eq!(v["functions"].as_array().unwrap().len(), 1);
+    }
+
+    #[test]
+    fn test_render_json_report_is_versioned() {
+        let reports = vec![make_report("/repo/src/a.ts", "foo", 10, 12.0)];
+        let v: serde_json::Value =
+            serde_json::from_str(&render_json_report(&reports, "1.2.3")).unwrap();
+        assert_eq!(v["schema_version"], REPORT_SCHEMA_VERSION);
+        assert_eq!(v["tool_version"], "1.2.3");
+        assert_eq!(v["truncated"], false);
+        assert_eq!(v["total_functions"], 1);
+        assert_eq!(v["functions"][0]["function"], "foo");
+    }
+
+    #[test]
+    fn test_render_jsonl_line_is_versioned() {
+        let report = make_report("/repo/src/a.ts", "foo", 10, 12.0);
+        let v: serde_json::Value =
+            serde_json::from_str(&render_jsonl_line(&report, "1.2.3")).unwrap();
+        assert_eq!(v["schema_version"], REPORT_SCHEMA_VERSION);
+        assert_eq!(v["tool_version"], "1.2.3");
+        assert_eq!(v["function"], "foo");
+        assert_eq!(v["end_line"], 29);
+    }
+
+    fn with_cc(mut report: FunctionRiskReport, cc: u32) -> FunctionRiskReport {
+        report.metrics.cc = cc;
+        report
+    }
+
+    fn names(reports: &[FunctionRiskReport]) -> Vec<&str> {
+        reports.iter().map(|r| r.function.as_str()).collect()
+    }
+
+    #[test]
+    fn test_sort_reports_by_cc_descending() {
+        let mut reports = vec![
+            with_cc(make_report("/repo/src/a.ts", "low", 10, 9.0), 2),
+            with_cc(make_report("/repo/src/b.ts", "tie_b", 20, 3.0), 8),
+            with_cc(make_report("/repo/src/a.ts", "high", 30, 1.0), 12),
+            with_cc(make_report("/repo/src/a.ts", "tie_a", 40, 3.0), 8),
+        ];
+        sort_reports_by(&mut reports, SortMetric::Cc, false);
+        // Ties by file, then line
+        assert_eq!(names(&reports), vec!["high", "tie_a", "tie_b", "low"]);
+    }
+
+    #[test]
+    fn test_sort_reports_by_ascending_keeps_tie_order() {
+        let mut reports = vec![
+            with_cc(make_report("/repo/src/a.ts", "high", 10, 1.0), 12),
+            with_cc(make_report("/repo/src/b.ts", "tie_b", 20, 3.0), 8),
+            with_cc(make_report("/repo/src/a.ts", "tie_a", 40, 3.0), 8),
+        ];
+        sort_reports_by(&mut reports, SortMetric::Cc, true);
+        assert_eq!(names(&reports), vec!["tie_a", "tie_b", "high"]);
+
+        sort_reports_by(&mut reports, SortMetric::Lrs, true);
+        assert_eq!(names(&reports), vec!["high", "tie_a", "tie_b"]);
+    }
+
+    #[test]
+    fn test_render_text_ranked_numbers_from_offset() {
+        let reports = vec![
+            with_cc(make_report("/repo/src/a.ts", "first", 10, 9.0), 14),
+            make_report("/repo/src/b.ts", "second", 20, 7.0),
+        ];
+        let out = render_text_ranked(&reports, 20, 412);
+        let lines: Vec<&str> = out.lines().collect();
+        assert!(lines[0].contains("RANK") && lines[0].contains("COG"));
+        assert!(lines[1].trim_start().starts_with("21 "));
+        assert!(lines[1].contains("first") && lines[1].contains("14"));
+        assert!(lines[2].trim_start().starts_with("22 "));
+        assert_eq!(lines[3], "... 412 more");
+    }
+
+    #[test]
+    fn test_render_text_ranked_no_footer_on_last_page() {
+        let reports = vec![make_report("/repo/src/a.ts", "foo", 10, 9.0)];
+        let out = render_text_ranked(&reports, 0, 0);
+        assert!(!out.contains("more"));
+        assert_eq!(out.lines().count(), 2);
+    }
+
+    #[test]
+    fn test_render_text_grouped_notes_truncation() {
+        let mut r = make_report("/repo/src/a.ts", "foo", 10, 12.0);
+        r.band = RiskBand::Critical;
+        let out = render_text_grouped(&[r.clone()], 1, 5, false);
+        assert!(out.contains("... 5 more"));
+        let out = render_text_grouped(&[r], 1, 0, false);
+        assert!(!out.contains("more"));
+    }
 }
@@ -123,6 +123,61 @@ pub fn calculate_lrs_with_weights(risk: &RiskComponents, weights: &LrsWeights) -
         + weights.ns * risk.r_ns
 }
 
+/// Configurable weights for the composite risk score (`--sort risk-score`)
+#[derive(Debug, Clone, Copy, PartialEq)]
+pub struct RiskScoreWeights {
+    pub cc: f64,
+    pub nd: f64,
+    pub fo: f64,
+    pub ns: f64,
+    pub churn: f64,
+}
+
+impl Default for RiskScoreWeights {
+    fn default() -> Self {
+        RiskScoreWeights {
+            cc: 1.0,
+            nd: 0.8,
+            fo: 0.6,
+            ns: 0.7,
+            churn: 1.0,
+        }
+    }
+}
+
+/// Calculate the composite risk score, in [0, 1]
+///
+/// Each signal is normalized by a fixed cap, so a function's score does not
+/// depend on the rest of the analyzed set:
+/// - N_cc = min(log2(CC + 1), 6) / 6
+/// - N_nd = min(ND, 8) / 8
+/// - N_fo = min(log2(FO + 1), 6) / 6
+/// - N_ns = min(NS, 6) / 6
+/// - N_churn = min(log2(churn + 1), 6) / 6
+///
+/// The score is the weighted mean of the signals present: without churn
+/// (None), the other weights are renormalized. All-zero weights score 0.
+pub fn calculate_risk_score(
+    risk: &RiskComponents,
+    churn: Option<usize>,
+    weights: &RiskScoreWeights,
+) -> f64 {
+    let mut signals = vec![
+        (weights.cc, risk.r_cc / 6.0),
+        (weights.nd, risk.r_nd / 8.0),
+        (weights.fo, risk.r_fo / 6.0),
+        (weights.ns, risk.r_ns / 6.0),
+    ];
+    if let Some(churn) = churn {
+        signals.push((weights.churn, (churn as f64 + 1.0).log2().min(6.0) / 6.0));
+    }
+    let total: f64 = signals.iter().map(|(w, _)| w).sum();
+    if total <= 0.0 {
+        return 0.0;
+    }
+    signals.iter().map(|(w, n)| w * n).sum::<f64>() / total
+}
+
 /// Assign risk band based on LRS with default thresholds
 pub fn assign_risk_band(lrs: f64) -> RiskBand {
     assign_risk_band_with_thresholds(lrs, &RiskThresholds::default())
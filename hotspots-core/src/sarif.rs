@@ -5,9 +5,21 @@
 //!   critical → error
 //!   high     → warning
 //!   moderate → note
+//!
+//! Additionally emits one result per metric rule (`hotspots/cc`, `hotspots/nd`,
+//! ..., `hotspots/max_condition_ops`) for each function at or above that metric's configured threshold, so
+//! SARIF consumers can group and filter findings by metric and severity. Every
+//! rule carries its threshold in the rule's `properties` bag and remediation
+//! advice in `help`.
+//!
+//! Result regions span the whole function: 1-based `startLine` and `endLine`,
+//! taken from the parser's line mapping (the function's start line plus its
+//! line span).
 
-use crate::snapshot::Snapshot;
+use crate::risk::RiskThresholds;
+use crate::snapshot::{FunctionSnapshot, Snapshot};
 use serde::Serialize;
+use serde_json::json;
 use std::path::Path;
 
 const SARIF_SCHEMA: &str =
@@ -19,12 +31,220 @@ const RULE_CRITICAL: &str = "hotspots/critical-risk";
 const RULE_HIGH: &str = "hotspots/high-risk";
 const RULE_MODERATE: &str = "hotspots/moderate-risk";
 
+/// SARIF result level, as used in `defaultConfiguration.level`.
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub enum SarifLevel {
+    None,
+    Note,
+    Warning,
+    Error,
+}
+
+impl SarifLevel {
+    /// Parse a SARIF level name (`"none"`, `"note"`, `"warning"`, `"error"`).
+    pub fn parse(s: &str) -> Option<Self> {
+        match s {
+            "none" => Some(SarifLevel::None),
+            "note" => Some(SarifLevel::Note),
+            "warning" => Some(SarifLevel::Warning),
+            "error" => Some(SarifLevel::Error),
+            _ => None,
+        }
+    }
+
+    pub fn as_str(self) -> &'static str {
+        match self {
+            SarifLevel::None => "none",
+            SarifLevel::Note => "note",
+            SarifLevel::Warning => "warning",
+            SarifLevel::Error => "error",
+        }
+    }
+}
+
+/// Threshold and level for a single per-metric rule.
+///
+/// A function triggers the rule when its metric value is `>= threshold`.
+/// `SarifLevel::None` keeps the rule in the driver's rule list but emits
+/// no results for it.
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub struct MetricRule {
+    pub threshold: u32,
+    pub level: SarifLevel,
+}
+
+/// Per-metric SARIF rule configuration (config key `sarif`).
+///
+/// Defaults line up with the Tier 1 pattern thresholds where one exists
+/// (ND ≥ 5 is `deeply_nested`, NS ≥ 5 is `exit_heavy`, LOC ≥ 80 is
+/// `long_function`). Signature complexity ≥ 6 means roughly two type
+/// parameters over a four-level nested type; four boolean operators in one
+/// condition is one past the usual readability limit of three.
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub struct MetricRules {
+    pub cc: MetricRule,
+    pub nd: MetricRule,
+    pub fo: MetricRule,
+    pub ns: MetricRule,
+    pub loc: MetricRule,
+    pub signature_complexity: MetricRule,
+    pub max_condition_ops: MetricRule,
+}
+
+impl Default for MetricRules {
+    fn default() -> Self {
+        MetricRules {
+            cc: MetricRule {
+                threshold: 15,
+                level: SarifLevel::Warning,
+            },
+            nd: MetricRule {
+                threshold: 5,
+                level: SarifLevel::Warning,
+            },
+            fo: MetricRule {
+                threshold: 15,
+                level: SarifLevel::Note,
+            },
+            ns: MetricRule {
+                threshold: 5,
+                level: SarifLevel::Note,
+            },
+            loc: MetricRule {
+                threshold: 80,
+                level: SarifLevel::Note,
+            },
+            signature_complexity: MetricRule {
+                threshold: 6,
+                level: SarifLevel::Note,
+            },
+            max_condition_ops: MetricRule {
+                threshold: 4,
+                level: SarifLevel::Note,
+            },
+        }
+    }
+}
+
+/// Static description of a per-metric rule.
+struct MetricRuleDef {
+    metric: &'static str,
+    id: &'static str,
+    name: &'static str,
+    label: &'static str,
+    description: &'static str,
+    help: &'static str,
+}
+
+/// Per-metric rules, in emission order.
+const METRIC_RULES: [MetricRuleDef; 7] = [
+    MetricRuleDef {
+        metric: "cc",
+        id: "hotspots/cc",
+        name: "HighCyclomaticComplexity",
+        label: "CC",
+        description: "Cyclomatic complexity (independent paths through the function) is at or above the configured threshold.",
+        help: "Split the function along its decision points: extract each independent branch or case into a named helper, and replace flag-driven conditionals with lookup tables or polymorphism.",
+    },
+    MetricRuleDef {
+        metric: "nd",
+        id: "hotspots/nd",
+        name: "DeepNesting",
+        label: "ND",
+        description: "Maximum nesting depth of control structures is at or above the configured threshold.",
+        help: "Flatten the nesting: return early for invalid input (guard clauses), invert conditions, and move the innermost loop or branch body into a helper.",
+    },
+    MetricRuleDef {
+        metric: "fo",
+        id: "hotspots/fo",
+        name: "HighFanOut",
+        label: "FO",
+        description: "Number of distinct functions called is at or above the configured threshold.",
+        help: "The function coordinates too many collaborators. Group related calls behind a smaller interface, or split orchestration from the work it delegates.",
+    },
+    MetricRuleDef {
+        metric: "ns",
+        id: "hotspots/ns",
+        name: "ManyExits",
+        label: "NS",
+        description: "Number of non-structured exits (early returns, breaks, throws) is at or above the configured threshold.",
+        help: "Many early returns, breaks, and throws make control flow hard to follow. Consolidate exits, or extract the sections that need their own early exits into helpers.",
+    },
+    MetricRuleDef {
+        metric: "loc",
+        id: "hotspots/loc",
+        name: "LongFunction",
+        label: "LOC",
+        description: "Function length in lines is at or above the configured threshold.",
+        help: "Extract cohesive blocks of the function into well-named helpers until each piece does one thing.",
+    },
+    MetricRuleDef {
+        metric: "signature_complexity",
+        id: "hotspots/signature_complexity",
+        name: "ComplexSignature",
+        label: "signature complexity",
+        description: "Type-parameter count plus the nesting depth of parameter and return types is at or above the configured threshold.",
+        help: "Introduce type aliases or small wrapper types for deeply nested parameter and return types, and drop type parameters that callers never vary.",
+    },
+    MetricRuleDef {
+        metric: "max_condition_ops",
+        id: "hotspots/max_condition_ops",
+        name: "ComplexCondition",
+        label: "condition operators",
+        description: "The largest number of && / || operators in a single boolean expression is at or above the configured threshold.",
+        help: "Extract the condition, or each group of its clauses, into a well-named boolean variable or predicate function so the decision reads as one idea.",
+    },
+];
+
+impl MetricRules {
+    /// `(metric, rule)` pairs in emission order: `cc`, `nd`, `fo`, `ns`, `loc`,
+    /// `signature_complexity`, `max_condition_ops`.
+    pub fn iter(&self) -> impl Iterator<Item = (&'static str, MetricRule)> + '_ {
+        METRIC_RULES
+            .iter()
+            .map(move |def| (def.metric, self.get(def.metric)))
+    }
+
+    fn get(&self, metric: &str) -> MetricRule {
+        match metric {
+            "cc" => self.cc,
+            "nd" => self.nd,
+            "fo" => self.fo,
+            "ns" => self.ns,
+            "signature_complexity" => self.signature_complexity,
+            "max_condition_ops" => self.max_condition_ops,
+            _ => self.loc,
+        }
+    }
+}
+
+fn metric_value(f: &FunctionSnapshot, metric: &str) -> u32 {
+    match metric {
+        "cc" => f.metrics.cc,
+        "nd" => f.metrics.nd,
+        "fo" => f.metrics.fo,
+        "ns" => f.metrics.ns,
+        "signature_complexity" => f.metrics.signature_complexity,
+        "max_condition_ops" => f.metrics.max_condition_ops,
+        _ => f.metrics.loc,
+    }
+}
+
 #[derive(Serialize)]
 struct SarifOutput {
     #[serde(rename = "$schema")]
     schema: &'static str,
     version: &'static str,
     runs: Vec<SarifRun>,
+    properties: SarifLogProperties,
+}
+
+/// The log's property bag: hotspots' own `schema_version` for what it puts
+/// in the log, and the `tool_version` that wrote it
+#[derive(Serialize)]
+struct SarifLogProperties {
+    schema_version: u32,
+    tool_version: String,
 }
 
 #[derive(Serialize)]
@@ -59,6 +279,8 @@ struct SarifRule {
     default_configuration: SarifRuleConfig,
     #[serde(rename = "helpUri")]
     help_uri: &'static str,
+    help: SarifMessage,
+    properties: serde_json::Value,
 }
 
 #[derive(Serialize)]
@@ -104,10 +326,12 @@ struct SarifArtifact {
 struct SarifRegion {
     #[serde(rename = "startLine")]
     start_line: u32,
+    #[serde(rename = "endLine")]
+    end_line: u32,
 }
 
-fn rules() -> Vec<SarifRule> {
-    vec![
+fn rules(thresholds: &RiskThresholds, metric_rules: &MetricRules) -> Vec<SarifRule> {
+    let mut rules = vec![
         SarifRule {
             id: RULE_CRITICAL,
             name: "CriticalRiskFunction",
@@ -119,6 +343,10 @@ fn rules() -> Vec<SarifRule> {
             },
             default_configuration: SarifRuleConfig { level: "error" },
             help_uri: "https://hotspots.dev",
+            help: SarifMessage {
+                text: "Refactor before changing this function further: reduce its complexity (see the per-metric rules) and add tests around its current behavior first.".to_string(),
+            },
+            properties: json!({ "metric": "lrs", "threshold": thresholds.critical }),
         },
         SarifRule {
             id: RULE_HIGH,
@@ -131,6 +359,10 @@ fn rules() -> Vec<SarifRule> {
             },
             default_configuration: SarifRuleConfig { level: "warning" },
             help_uri: "https://hotspots.dev",
+            help: SarifMessage {
+                text: "Reduce complexity when you next change this function, and make sure its branches are covered by tests.".to_string(),
+            },
+            properties: json!({ "metric": "lrs", "threshold": thresholds.high }),
         },
         SarifRule {
             id: RULE_MODERATE,
@@ -143,8 +375,34 @@ fn rules() -> Vec<SarifRule> {
             },
             default_configuration: SarifRuleConfig { level: "note" },
             help_uri: "https://hotspots.dev",
+            help: SarifMessage {
+                text: "No action needed now; keep new branches and nesting out of this function.".to_string(),
+            },
+            properties: json!({ "metric": "lrs", "threshold": thresholds.moderate }),
         },
-    ]
+    ];
+    rules.extend(METRIC_RULES.iter().map(|def| {
+        let rule = metric_rules.get(def.metric);
+        SarifRule {
+            id: def.id,
+            name: def.name,
+            short_description: SarifMessage {
+                text: format!("{} at or above {}", def.label, rule.threshold),
+            },
+            full_description: SarifMessage {
+                text: def.description.to_string(),
+            },
+            default_configuration: SarifRuleConfig {
+                level: rule.level.as_str(),
+            },
+            help_uri: "https://hotspots.dev",
+            help: SarifMessage {
+                text: def.help.to_string(),
+            },
+            properties: json!({ "metric": def.metric, "threshold": rule.threshold }),
+        }
+    }));
+    rules
 }
 
 /// Strip `repo_root` from an absolute file path to produce a repo-relative URI.
@@ -163,28 +421,47 @@ fn to_relative_uri(file: &str, repo_root: &Path) -> String {
 
 /// Render a snapshot as SARIF 2.1.0 JSON.
 ///
-/// Only functions at moderate risk or above are emitted.
-/// `repo_root` is used to convert absolute file paths to repo-relative URIs,
+/// Band results are emitted for functions at moderate risk or above; metric
+/// results are emitted for each metric at or above its rule's threshold.
+/// `thresholds` only annotates the band rules — bands are already assigned
+/// on the snapshot. `repo_root` is used to convert absolute file paths to repo-relative URIs,
 /// which is required for GitHub code scanning to resolve locations correctly.
-pub fn render_sarif(snapshot: &Snapshot, repo_root: &Path) -> String {
+pub fn render_sarif(
+    snapshot: &Snapshot,
+    repo_root: &Path,
+    thresholds: &RiskThresholds,
+    metric_rules: &MetricRules,
+) -> String {
     let tool_version = snapshot.analysis.tool_version.clone();
 
-    let results: Vec<SarifResult> = snapshot
-        .functions
-        .iter()
-        .filter_map(|f| {
-            let (rule_id, level) = match f.band.as_str() {
-                "critical" => (RULE_CRITICAL, "error"),
-                "high" => (RULE_HIGH, "warning"),
-                "moderate" => (RULE_MODERATE, "note"),
-                _ => return None,
-            };
-
-            let name = f.function_id.rsplit("::").next().unwrap_or("<anonymous>");
+    let mut results: Vec<SarifResult> = Vec::new();
+    for f in &snapshot.functions {
+        let name = f.function_id.rsplit("::").next().unwrap_or("<anonymous>");
+        let start_line = f.line.max(1);
+        let end_line = start_line + f.metrics.loc.saturating_sub(1);
+        let location = || SarifLocation {
+            physical_location: SarifPhysicalLocation {
+                artifact_location: SarifArtifact {
+                    uri: to_relative_uri(&f.file, repo_root),
+                    uri_base_id: "%SRCROOT%",
+                },
+                region: SarifRegion {
+                    start_line,
+                    end_line,
+                },
+            },
+        };
+
+        let band_rule = match f.band.as_str() {
+            "critical" => Some((RULE_CRITICAL, "error")),
+            "high" => Some((RULE_HIGH, "warning")),
+            "moderate" => Some((RULE_MODERATE, "note")),
+            _ => None,
+        };
+        if let Some((rule_id, level)) = band_rule {
             let lrs = f.lrs;
             let cc = f.metrics.cc;
-
-            Some(SarifResult {
+            results.push(SarifResult {
                 rule_id,
                 level,
                 message: SarifMessage {
@@ -193,20 +470,30 @@ pub fn render_sarif(snapshot: &Snapshot, repo_root: &Path) -> String {
                         band = f.band.as_str(),
                     ),
                 },
-                locations: vec![SarifLocation {
-                    physical_location: SarifPhysicalLocation {
-                        artifact_location: SarifArtifact {
-                            uri: to_relative_uri(&f.file, repo_root),
-                            uri_base_id: "%SRCROOT%",
-                        },
-                        region: SarifRegion {
-                            start_line: f.line.max(1),
-                        },
-                    },
-                }],
-            })
-        })
-        .collect();
+                locations: vec![location()],
+            });
+        }
+
+        for def in &METRIC_RULES {
+            let rule = metric_rules.get(def.metric);
+            let value = metric_value(f, def.metric);
+            if rule.level == SarifLevel::None || value < rule.threshold {
+                continue;
+            }
+            results.push(SarifResult {
+                rule_id: def.id,
+                level: rule.level.as_str(),
+                message: SarifMessage {
+                    text: format!(
+                        "Function `{name}` has {label}={value} (threshold {threshold}).",
+                        label = def.label,
+                        threshold = rule.threshold,
+                    ),
+                },
+                locations: vec![location()],
+            });
+        }
+    }
 
     let output = SarifOutput {
         schema: SARIF_SCHEMA,
@@ -217,11 +504,15 @@ pub fn render_sarif(snapshot: &Snapshot, repo_root: &Path) -> String {
                     name: "hotspots",
                     version: tool_version,
                     information_uri: "https://hotspots.dev",
-                    rules: rules(),
+                    rules: rules(thresholds, metric_rules),
                 },
             },
             results,
         }],
+        properties: SarifLogProperties {
+            schema_version: crate::report::REPORT_SCHEMA_VERSION,
+            tool_version: snapshot.analysis.tool_version.clone(),
+        },
     };
 
     serde_json::to_string_pretty(&output).expect("SARIF serialization is infallible")
@@ -254,6 +545,9 @@ mod tests {
             functions,
             summary: None,
             aggregates: None,
+            truncated: None,
+            total_functions: None,
+            top_level_decisions: Default::default(),
         }
     }
 
@@ -267,8 +561,24 @@ mod tests {
                 cc,
                 nd: 0,
                 fo: 0,
+                fi: 0,
                 ns: 0,
                 loc: 10,
+                signature_complexity: 0,
+                params: 0,
+                guard_clauses: 0,
+                max_condition_ops: 0,
+                cognitive: 0,
+                halstead: None,
+                maintainability: None,
+                sloc: None,
+                comment_lines: None,
+                blank_lines: None,
+                nd_line: None,
+                cc_lines: None,
+                is_async: false,
+                await_in_loop: 0,
+                ns_breakdown: None,
             },
             lrs,
             band: crate::risk::RiskBand::parse(band).unwrap_or(crate::risk::RiskBand::Low),
@@ -298,13 +608,33 @@ mod tests {
             age_days: None,
             last_touch_days: None,
             explanation: None,
+            structure: None,
+            cc_breakdown: None,
         }
     }
 
+    fn render(snapshot: &Snapshot) -> String {
+        render_sarif(
+            snapshot,
+            Path::new("/repo"),
+            &RiskThresholds::default(),
+            &MetricRules::default(),
+        )
+    }
+
+    fn band_results(val: &serde_json::Value) -> Vec<&serde_json::Value> {
+        val["runs"][0]["results"]
+            .as_array()
+            .unwrap()
+            .iter()
+            .filter(|r| r["ruleId"].as_str().unwrap().ends_with("-risk"))
+            .collect()
+    }
+
     #[test]
     fn test_sarif_schema_and_version() {
         let snapshot = make_snapshot(vec![]);
-        let json = render_sarif(&snapshot, Path::new("/repo"));
+        let json = render(&snapshot);
         let val: serde_json::Value = serde_json::from_str(&json).unwrap();
         assert_eq!(val["version"], "2.1.0");
         assert!(val["$schema"]
@@ -313,13 +643,25 @@ mod tests {
             .contains("sarif-schema-2.1.0"));
     }
 
+    #[test]
+    fn test_sarif_log_properties_carry_versions() {
+        let snapshot = make_snapshot(vec![]);
+        let val: serde_json::Value = serde_json::from_str(&render(&snapshot)).unwrap();
+        assert_eq!(
+            val["properties"]["schema_version"],
+            crate::report::REPORT_SCHEMA_VERSION
+        );
+        assert_eq!(val["properties"]["tool_version"], "1.0.0");
+        assert_eq!(val["runs"][0]["tool"]["driver"]["version"], "1.0.0");
+    }
+
     #[test]
     fn test_sarif_low_risk_functions_omitted() {
         let snapshot = make_snapshot(vec![
             make_function("/repo/src/lib.rs", "low_fn", "low", 1.0, 2),
             make_function("/repo/src/lib.rs", "moderate_fn", "moderate", 4.0, 5),
         ]);
-        let json = render_sarif(&snapshot, Path::new("/repo"));
+        let json = render(&snapshot);
         let val: serde_json::Value = serde_json::from_str(&json).unwrap();
         let results = &val["runs"][0]["results"];
         assert_eq!(results.as_array().unwrap().len(), 1);
@@ -333,9 +675,9 @@ mod tests {
             make_function("/repo/b.rs", "high_fn", "high", 7.0, 10),
             make_function("/repo/c.rs", "moderate_fn", "moderate", 4.0, 5),
         ]);
-        let json = render_sarif(&snapshot, Path::new("/repo"));
+        let json = render(&snapshot);
         let val: serde_json::Value = serde_json::from_str(&json).unwrap();
-        let results = val["runs"][0]["results"].as_array().unwrap();
+        let results = band_results(&val);
         assert_eq!(results.len(), 3);
         let levels: Vec<&str> = results
             .iter()
@@ -355,7 +697,7 @@ mod tests {
             7.0,
             10,
         )]);
-        let json = render_sarif(&snapshot, Path::new("/repo"));
+        let json = render(&snapshot);
         let val: serde_json::Value = serde_json::from_str(&json).unwrap();
         let uri = val["runs"][0]["results"][0]["locations"][0]["physicalLocation"]
             ["artifactLocation"]["uri"]
@@ -367,15 +709,191 @@ mod tests {
     #[test]
     fn test_sarif_rules_present() {
         let snapshot = make_snapshot(vec![]);
-        let json = render_sarif(&snapshot, Path::new("/repo"));
+        let json = render(&snapshot);
         let val: serde_json::Value = serde_json::from_str(&json).unwrap();
         let rules = val["runs"][0]["tool"]["driver"]["rules"]
             .as_array()
             .unwrap();
-        assert_eq!(rules.len(), 3);
+        assert_eq!(rules.len(), 10);
         let ids: Vec<&str> = rules.iter().map(|r| r["id"].as_str().unwrap()).collect();
         assert!(ids.contains(&"hotspots/critical-risk"));
         assert!(ids.contains(&"hotspots/high-risk"));
         assert!(ids.contains(&"hotspots/moderate-risk"));
+        for metric in [
+            "cc",
+            "nd",
+            "fo",
+            "ns",
+            "loc",
+            "signature_complexity",
+            "max_condition_ops",
+        ] {
+            assert!(ids.contains(&format!("hotspots/{metric}").as_str()));
+        }
+    }
+
+    #[test]
+    fn test_sarif_rules_carry_configured_threshold_and_level() {
+        let metric_rules = MetricRules {
+            cc: MetricRule {
+                threshold: 12,
+                level: SarifLevel::Error,
+            },
+            ..MetricRules::default()
+        };
+        let json = render_sarif(
+            &make_snapshot(vec![]),
+            Path::new("/repo"),
+            &RiskThresholds::default(),
+            &metric_rules,
+        );
+        let val: serde_json::Value = serde_json::from_str(&json).unwrap();
+        let rules = val["runs"][0]["tool"]["driver"]["rules"]
+            .as_array()
+            .unwrap();
+        let cc = rules.iter().find(|r| r["id"] == "hotspots/cc").unwrap();
+        assert_eq!(cc["defaultConfiguration"]["level"], "error");
+        assert_eq!(cc["properties"]["metric"], "cc");
+        assert_eq!(cc["properties"]["threshold"], 12);
+        let critical = rules
+            .iter()
+            .find(|r| r["id"] == "hotspots/critical-risk")
+            .unwrap();
+        assert_eq!(critical["properties"]["threshold"], 9.0);
+    }
+
+    #[test]
+    fn test_sarif_metric_results_use_distinct_rule_ids() {
+        let mut f = make_function("/repo/a.rs", "big_fn", "low", 2.0, 20);
+        f.metrics.nd = 6;
+        f.metrics.loc = 40;
+        let json = render(&make_snapshot(vec![f]));
+        let val: serde_json::Value = serde_json::from_str(&json).unwrap();
+        let results = val["runs"][0]["results"].as_array().unwrap();
+        let ids: Vec<&str> = results
+            .iter()
+            .map(|r| r["ruleId"].as_str().unwrap())
+            .collect();
+        // Low band: no band result; CC 20 ≥ 15 and ND 6 ≥ 5 fire, LOC 40 < 80 does not
+        assert_eq!(ids, vec!["hotspots/cc", "hotspots/nd"]);
+        assert_eq!(results[0]["level"], "warning");
+        assert!(results[0]["message"]["text"]
+            .as_str()
+            .unwrap()
+            .contains("CC=20 (threshold 15)"));
+    }
+
+    #[test]
+    fn test_sarif_signature_complexity_rule() {
+        let mut f = make_function("/repo/a.rs", "generic_fn", "low", 1.0, 2);
+        f.metrics.signature_complexity = 7;
+        let json = render(&make_snapshot(vec![f]));
+        let val: serde_json::Value = serde_json::from_str(&json).unwrap();
+        let results = val["runs"][0]["results"].as_array().unwrap();
+        assert_eq!(results.len(), 1);
+        assert_eq!(results[0]["ruleId"], "hotspots/signature_complexity");
+        assert_eq!(results[0]["level"], "note");
+        assert!(results[0]["message"]["text"]
+            .as_str()
+            .unwrap()
+            .contains("signature complexity=7 (threshold 6)"));
+    }
+
+    #[test]
+    fn test_sarif_max_condition_ops_rule() {
+        let mut f = make_function("/repo/a.rs", "can_ship", "low", 1.0, 2);
+        f.metrics.max_condition_ops = 5;
+        let json = render(&make_snapshot(vec![f]));
+        let val: serde_json::Value = serde_json::from_str(&json).unwrap();
+        let results = val["runs"][0]["results"].as_array().unwrap();
+        assert_eq!(results.len(), 1);
+        assert_eq!(results[0]["ruleId"], "hotspots/max_condition_ops");
+        assert_eq!(results[0]["level"], "note");
+        assert!(results[0]["message"]["text"]
+            .as_str()
+            .unwrap()
+            .contains("condition operators=5 (threshold 4)"));
+    }
+
+    #[test]
+    fn test_sarif_level_none_suppresses_results() {
+        let metric_rules = MetricRules {
+            cc: MetricRule {
+                threshold: 1,
+                level: SarifLevel::None,
+            },
+            ..MetricRules::default()
+        };
+        let json = render_sarif(
+            &make_snapshot(vec![make_function("/repo/a.rs", "f", "low", 1.0, 30)]),
+            Path::new("/repo"),
+            &RiskThresholds::default(),
+            &metric_rules,
+        );
+        let val: serde_json::Value = serde_json::from_str(&json).unwrap();
+        assert!(val["runs"][0]["results"].as_array().unwrap().is_empty());
+    }
+
+    /// Spot-checks a hand-picked subset of the SARIF 2.1.0 schema's
+    /// constraints on the properties we emit: required members, the `level`
+    /// enum, property bags as objects, `startLine` ≥ 1, and `endLine` ≥
+    /// `startLine`. Every `ruleId` must also resolve to a rule in
+    /// `tool.driver.rules`. This is not validation against the full schema.
+    #[test]
+    fn test_sarif_required_members_and_value_constraints() {
+        let mut f = make_function("/repo/src/a.rs", "f", "critical", 10.0, 40);
+        f.metrics.nd = 8;
+        f.metrics.fo = 20;
+        f.metrics.ns = 9;
+        f.metrics.loc = 200;
+        f.line = 0;
+        let json = render(&make_snapshot(vec![f]));
+        let val: serde_json::Value = serde_json::from_str(&json).unwrap();
+
+        const LEVELS: [&str; 4] = ["none", "note", "warning", "error"];
+        assert_eq!(val["version"], "2.1.0");
+        let runs = val["runs"].as_array().expect("runs must be an array");
+        assert_eq!(runs.len(), 1);
+        let driver = &runs[0]["tool"]["driver"];
+        assert!(driver["name"].is_string(), "driver.name is required");
+
+        let rules = driver["rules"].as_array().unwrap();
+        let mut rule_ids = std::collections::HashSet::new();
+        for rule in rules {
+            let id = rule["id"].as_str().expect("rule.id is required");
+            assert!(rule_ids.insert(id), "duplicate rule id {id}");
+            assert!(rule["shortDescription"]["text"].is_string());
+            assert!(rule["fullDescription"]["text"].is_string());
+            assert!(rule["help"]["text"].is_string(), "{id} has no help");
+            let level = rule["defaultConfiguration"]["level"].as_str().unwrap();
+            assert!(LEVELS.contains(&level), "bad level {level}");
+            assert!(rule["properties"].is_object());
+        }
+
+        let results = runs[0]["results"].as_array().unwrap();
+        assert_eq!(results.len(), 6, "1 band result + 5 metric results");
+        for result in results {
+            let rule_id = result["ruleId"].as_str().unwrap();
+            assert!(rule_ids.contains(rule_id), "unknown ruleId {rule_id}");
+            assert!(LEVELS.contains(&result["level"].as_str().unwrap()));
+            assert!(result["message"]["text"].is_string());
+            let loc = &result["locations"][0]["physicalLocation"];
+            assert!(loc["artifactLocation"]["uri"].is_string());
+            let start = loc["region"]["startLine"].as_u64().unwrap();
+            assert!(start >= 1);
+            assert!(loc["region"]["endLine"].as_u64().unwrap() >= start);
+        }
+    }
+
+    #[test]
+    fn test_sarif_region_spans_function() {
+        let mut f = make_function("/repo/src/a.rs", "f", "high", 7.0, 5);
+        f.line = 12;
+        f.metrics.loc = 30;
+        let val: serde_json::Value =
+            serde_json::from_str(&render(&make_snapshot(vec![f]))).unwrap();
+        let region = &band_results(&val)[0]["locations"][0]["physicalLocation"]["region"];
+        assert_eq!(region["startLine"], 12);
+        assert_eq!(region["endLine"], 41);
     }
 }
@@ -0,0 +1,278 @@
+//! Severity levels (`--min-severity`, `[severity]` in the config)
+//!
+//! A function's severity buckets one metric — LRS by default, or the
+//! composite risk score, CC, ND, FO, NS, or cognitive complexity — into
+//! `ok`, `low`, `medium`, `high`, or `critical` by four cutoffs. Each cutoff
+//! is the lowest value of its level; anything below `low` is `ok`. Severity
+//! colors the tree and HTML views and drives `--min-severity`.
+//!
+//! Default cutoffs (low / medium / high / critical):
+//! - `lrs`: 1.5 / 3.0 / 6.0 / 9.0 (medium and up match the default risk bands)
+//! - `risk_score`: 0.2 / 0.4 / 0.6 / 0.8
+//! - `cc`, `fo`, `cognitive`: 5 / 10 / 15 / 25
+//! - `nd`: 2 / 3 / 5 / 7
+//! - `ns`: 2 / 3 / 5 / 8
+//!
+//! Global invariants enforced:
+//! - Severity depends only on the report and the scale
+//! - A value exactly at a cutoff belongs to that cutoff's level
+
+use crate::report::FunctionRiskReport;
+use serde::Serialize;
+
+/// Severity level, least severe first, so levels compare by severity
+#[derive(Debug, Clone, Copy, PartialEq, Eq, PartialOrd, Ord, Hash, Serialize)]
+#[serde(rename_all = "lowercase")]
+pub enum Severity {
+    Ok,
+    Low,
+    Medium,
+    High,
+    Critical,
+}
+
+impl Severity {
+    pub fn as_str(&self) -> &'static str {
+        match self {
+            Severity::Ok => "ok",
+            Severity::Low => "low",
+            Severity::Medium => "medium",
+            Severity::High => "high",
+            Severity::Critical => "critical",
+        }
+    }
+
+    pub fn parse(s: &str) -> Option<Severity> {
+        match s {
+            "ok" => Some(Severity::Ok),
+            "low" => Some(Severity::Low),
+            "medium" => Some(Severity::Medium),
+            "high" => Some(Severity::High),
+            "critical" => Some(Severity::Critical),
+            _ => None,
+        }
+    }
+}
+
+/// Metric a severity is derived from
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub enum SeverityMetric {
+    Lrs,
+    /// Composite risk score (see `risk::calculate_risk_score`)
+    RiskScore,
+    Cc,
+    Nd,
+    Fo,
+    Ns,
+    Cognitive,
+}
+
+impl SeverityMetric {
+    /// Config names, in the order they are documented
+    pub const NAMES: &'static [&'static str] =
+        &["lrs", "risk_score", "cc", "nd", "fo", "ns", "cognitive"];
+
+    pub fn as_str(&self) -> &'static str {
+        match self {
+            SeverityMetric::Lrs => "lrs",
+            SeverityMetric::RiskScore => "risk_score",
+            SeverityMetric::Cc => "cc",
+            SeverityMetric::Nd => "nd",
+            SeverityMetric::Fo => "fo",
+            SeverityMetric::Ns => "ns",
+            SeverityMetric::Cognitive => "cognitive",
+        }
+    }
+
+    pub fn parse(s: &str) -> Option<SeverityMetric> {
+        match s {
+            "lrs" => Some(SeverityMetric::Lrs),
+            "risk_score" => Some(SeverityMetric::RiskScore),
+            "cc" => Some(SeverityMetric::Cc),
+            "nd" => Some(SeverityMetric::Nd),
+            "fo" => Some(SeverityMetric::Fo),
+            "ns" => Some(SeverityMetric::Ns),
+            "cognitive" => Some(SeverityMetric::Cognitive),
+            _ => None,
+        }
+    }
+
+    /// The metric's value for `report`. A report without a `risk_score`
+    /// (see `report::annotate_risk_scores`) is scored without churn under
+    /// the default weights.
+    pub fn value(self, report: &FunctionRiskReport) -> f64 {
+        let m = &report.metrics;
+        match self {
+            SeverityMetric::Lrs => report.lrs,
+            SeverityMetric::RiskScore => report.risk_score.unwrap_or_else(|| {
+                let risk = crate::risk::RiskComponents {
+                    r_cc: report.risk.r_cc,
+                    r_nd: report.risk.r_nd,
+                    r_fo: report.risk.r_fo,
+                    r_ns: report.risk.r_ns,
+                };
+                crate::risk::calculate_risk_score(
+                    &risk,
+                    None,
+                    &crate::risk::RiskScoreWeights::default(),
+                )
+            }),
+            SeverityMetric::Cc => m.cc as f64,
+            SeverityMetric::Nd => m.nd as f64,
+            SeverityMetric::Fo => m.fo as f64,
+            SeverityMetric::Ns => m.ns as f64,
+            SeverityMetric::Cognitive => m.cognitive as f64,
+        }
+    }
+}
+
+/// Lowest value of each level above `ok`
+#[derive(Debug, Clone, Copy, PartialEq)]
+pub struct SeverityCutoffs {
+    pub low: f64,
+    pub medium: f64,
+    pub high: f64,
+    pub critical: f64,
+}
+
+impl SeverityCutoffs {
+    /// Default cutoffs for `metric`
+    pub fn defaults_for(metric: SeverityMetric) -> SeverityCutoffs {
+        let (low, medium, high, critical) = match metric {
+            SeverityMetric::Lrs => (1.5, 3.0, 6.0, 9.0),
+            SeverityMetric::RiskScore => (0.2, 0.4, 0.6, 0.8),
+            SeverityMetric::Cc | SeverityMetric::Fo | SeverityMetric::Cognitive => {
+                (5.0, 10.0, 15.0, 25.0)
+            }
+            SeverityMetric::Nd => (2.0, 3.0, 5.0, 7.0),
+            SeverityMetric::Ns => (2.0, 3.0, 5.0, 8.0),
+        };
+        SeverityCutoffs {
+            low,
+            medium,
+            high,
+            critical,
+        }
+    }
+}
+
+/// A metric and the cutoffs that bucket it into severity levels
+#[derive(Debug, Clone, Copy, PartialEq)]
+pub struct SeverityScale {
+    pub metric: SeverityMetric,
+    pub cutoffs: SeverityCutoffs,
+}
+
+impl Default for SeverityScale {
+    fn default() -> Self {
+        SeverityScale {
+            metric: SeverityMetric::Lrs,
+            cutoffs: SeverityCutoffs::defaults_for(SeverityMetric::Lrs),
+        }
+    }
+}
+
+impl SeverityScale {
+    /// Level of `value` on this scale
+    pub fn classify(&self, value: f64) -> Severity {
+        let c = &self.cutoffs;
+        if value >= c.critical {
+            Severity::Critical
+        } else if value >= c.high {
+            Severity::High
+        } else if value >= c.medium {
+            Severity::Medium
+        } else if value >= c.low {
+            Severity::Low
+        } else {
+            Severity::Ok
+        }
+    }
+
+    /// Severity of `report` on this scale
+    pub fn severity(&self, report: &FunctionRiskReport) -> Severity {
+        self.classify(self.metric.value(report))
+    }
+}
+
+/// `--min-severity`: keeps reports at or above `min` on `scale`
+#[derive(Debug, Clone, Copy)]
+pub struct SeverityFilter {
+    pub min: Severity,
+    pub scale: SeverityScale,
+}
+
+impl SeverityFilter {
+    pub fn keeps(&self, report: &FunctionRiskReport) -> bool {
+        self.scale.severity(report) >= self.min
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn test_bucket_boundaries() {
+        let scale = SeverityScale::default();
+        assert_eq!(scale.classify(0.0), Severity::Ok);
+        assert_eq!(scale.classify(1.49), Severity::Ok);
+        assert_eq!(scale.classify(1.5), Severity::Low);
+        assert_eq!(scale.classify(2.99), Severity::Low);
+        assert_eq!(scale.classify(3.0), Severity::Medium);
+        assert_eq!(scale.classify(5.99), Severity::Medium);
+        assert_eq!(scale.classify(6.0), Severity::High);
+        assert_eq!(scale.classify(8.99), Severity::High);
+        assert_eq!(scale.classify(9.0), Severity::Critical);
+        assert_eq!(scale.classify(100.0), Severity::Critical);
+    }
+
+    #[test]
+    fn test_custom_cutoffs_and_metric() {
+        let scale = SeverityScale {
+            metric: SeverityMetric::Nd,
+            cutoffs: SeverityCutoffs::defaults_for(SeverityMetric::Nd),
+        };
+        assert_eq!(scale.classify(1.0), Severity::Ok);
+        assert_eq!(scale.classify(2.0), Severity::Low);
+        assert_eq!(scale.classify(3.0), Severity::Medium);
+        assert_eq!(scale.classify(4.0), Severity::Medium);
+        assert_eq!(scale.classify(5.0), Severity::High);
+        assert_eq!(scale.classify(7.0), Severity::Critical);
+
+        let tight = SeverityScale {
+            metric: SeverityMetric::Lrs,
+            cutoffs: SeverityCutoffs {
+                low: 1.0,
+                medium: 2.0,
+                high: 2.5,
+                critical: 4.0,
+            },
+        };
+        assert_eq!(tight.classify(0.99), Severity::Ok);
+        assert_eq!(tight.classify(2.5), Severity::High);
+        assert_eq!(tight.classify(3.99), Severity::High);
+        assert_eq!(tight.classify(4.0), Severity::Critical);
+    }
+
+    #[test]
+    fn test_levels_order_and_round_trip() {
+        assert!(Severity::Ok < Severity::Low);
+        assert!(Severity::Low < Severity::Medium);
+        assert!(Severity::Medium < Severity::High);
+        assert!(Severity::High < Severity::Critical);
+        for level in [
+            Severity::Ok,
+            Severity::Low,
+            Severity::Medium,
+            Severity::High,
+            Severity::Critical,
+        ] {
+            assert_eq!(Severity::parse(level.as_str()), Some(level));
+        }
+        assert_eq!(Severity::parse("moderate"), None);
+        for name in SeverityMetric::NAMES {
+            assert_eq!(SeverityMetric::parse(name).map(|m| m.as_str()), Some(*name));
+        }
+    }
+}
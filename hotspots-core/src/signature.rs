@@ -0,0 +1,348 @@
+//! Signature complexity for languages with rich type syntax
+//!
+//! `signature_complexity` = number of declared type parameters + the deepest
+//! type nesting found in any parameter type or the return type.
+//!
+//! Each generic argument list, array/slice, tuple, and function type adds one
+//! level of nesting; references, pointers, nullable markers, parentheses, and
+//! unions are transparent. A plain type (`i32`, `string`, `Status`) has depth
+//! 0, so `fn f(x: i32) -> bool` scores 0 and
+//! `fn f<T, E>() -> Result<Vec<Option<T>>, E>` scores 2 + 3 = 5.
+//!
+//! Supported: Rust (`syn`), TypeScript (SWC), C# (tree-sitter). Other
+//! languages report 0.
+//!
+//! Global invariants enforced:
+//! - Formatting, comments, and whitespace must not affect results
+//! - Deterministic metric calculation
+
+use swc_ecma_ast::{
+    ArrowExpr, Function, Pat, TsFnOrConstructorType, TsFnParam, TsType, TsTypeAnn, TsTypeParamDecl,
+    TsUnionOrIntersectionType,
+};
+
+// ---------------------------------------------------------------------------
+// ECMAScript (TypeScript annotations)
+// ---------------------------------------------------------------------------
+
+/// Signature complexity of a function, method, or function expression
+pub fn ecmascript_function_signature(function: &Function) -> usize {
+    ecmascript_signature(
+        function.type_params.as_deref(),
+        function.params.iter().map(|p| &p.pat),
+        function.return_type.as_deref(),
+    )
+}
+
+/// Signature complexity of an arrow function
+pub fn ecmascript_arrow_signature(arrow: &ArrowExpr) -> usize {
+    ecmascript_signature(
+        arrow.type_params.as_deref(),
+        arrow.params.iter(),
+        arrow.return_type.as_deref(),
+    )
+}
+
+fn ecmascript_signature<'a>(
+    type_params: Option<&TsTypeParamDecl>,
+    params: impl Iterator<Item = &'a Pat>,
+    return_type: Option<&TsTypeAnn>,
+) -> usize {
+    let type_param_count = type_params.map_or(0, |decl| decl.params.len());
+    let depth = params
+        .filter_map(pat_type_ann)
+        .chain(return_type)
+        .map(|ann| ts_type_depth(&ann.type_ann))
+        .max()
+        .unwrap_or(0);
+    type_param_count + depth
+}
+
+fn pat_type_ann(pat: &Pat) -> Option<&TsTypeAnn> {
+    match pat {
+        Pat::Ident(binding) => binding.type_ann.as_deref(),
+        Pat::Array(array) => array.type_ann.as_deref(),
+        Pat::Rest(rest) => rest.type_ann.as_deref(),
+        Pat::Object(object) => object.type_ann.as_deref(),
+        Pat::Assign(assign) => pat_type_ann(&assign.left),
+        _ => None,
+    }
+}
+
+fn fn_param_type_ann(param: &TsFnParam) -> Option<&TsTypeAnn> {
+    match param {
+        TsFnParam::Ident(binding) => binding.type_ann.as_deref(),
+        TsFnParam::Array(array) => array.type_ann.as_deref(),
+        TsFnParam::Rest(rest) => rest.type_ann.as_deref(),
+        TsFnParam::Object(object) => object.type_ann.as_deref(),
+    }
+}
+
+fn ts_type_depth(ty: &TsType) -> usize {
+    match ty {
+        TsType::TsTypeRef(type_ref) => type_ref
+            .type_params
+            .as_ref()
+            .map_or(0, |args| 1 + max_ts_depth(args.params.iter().map(|t| &**t))),
+        TsType::TsArrayType(array) => 1 + ts_type_depth(&array.elem_type),
+        TsType::TsTupleType(tuple) => 1 + max_ts_depth(tuple.elem_types.iter().map(|e| &*e.ty)),
+        TsType::TsFnOrConstructorType(fn_type) => {
+            let (params, ret) = match fn_type {
+                TsFnOrConstructorType::TsFnType(f) => (&f.params, &f.type_ann),
+                TsFnOrConstructorType::TsConstructorType(c) => (&c.params, &c.type_ann),
+            };
+            let types = params
+                .iter()
+                .filter_map(fn_param_type_ann)
+                .chain(std::iter::once(&**ret))
+                .map(|ann| &*ann.type_ann);
+            1 + max_ts_depth(types)
+        }
+        TsType::TsUnionOrIntersectionType(TsUnionOrIntersectionType::TsUnionType(union)) => {
+            max_ts_depth(union.types.iter().map(|t| &**t))
+        }
+        TsType::TsUnionOrIntersectionType(TsUnionOrIntersectionType::TsIntersectionType(
+            intersection,
+        )) => max_ts_depth(intersection.types.iter().map(|t| &**t)),
+        TsType::TsParenthesizedType(paren) => ts_type_depth(&paren.type_ann),
+        TsType::TsOptionalType(optional) => ts_type_depth(&optional.type_ann),
+        TsType::TsRestType(rest) => ts_type_depth(&rest.type_ann),
+        TsType::TsTypeOperator(op) => ts_type_depth(&op.type_ann),
+        _ => 0,
+    }
+}
+
+fn max_ts_depth<'a>(types: impl Iterator<Item = &'a TsType>) -> usize {
+    types.map(ts_type_depth).max().unwrap_or(0)
+}
+
+// ---------------------------------------------------------------------------
+// Rust
+// ---------------------------------------------------------------------------
+
+/// Signature complexity of a Rust function signature.
+///
+/// Type and const generic parameters count; lifetimes do not.
+pub fn rust_signature(sig: &syn::Signature) -> usize {
+    let type_param_count = sig
+        .generics
+        .params
+        .iter()
+        .filter(|p| !matches!(p, syn::GenericParam::Lifetime(_)))
+        .count();
+    let inputs = sig.inputs.iter().filter_map(|arg| match arg {
+        syn::FnArg::Typed(pat_type) => Some(&*pat_type.ty),
+        syn::FnArg::Receiver(_) => None,
+    });
+    let depth = inputs
+        .chain(return_type(&sig.output))
+        .map(rust_type_depth)
+        .max()
+        .unwrap_or(0);
+    type_param_count + depth
+}
+
+fn return_type(output: &syn::ReturnType) -> Option<&syn::Type> {
+    match output {
+        syn::ReturnType::Default => None,
+        syn::ReturnType::Type(_, ty) => Some(ty),
+    }
+}
+
+fn rust_type_depth(ty: &syn::Type) -> usize {
+    use syn::Type;
+
+    match ty {
+        Type::Path(type_path) => rust_path_depth(&type_path.path),
+        Type::Reference(r) => rust_type_depth(&r.elem),
+        Type::Ptr(p) => rust_type_depth(&p.elem),
+        Type::Paren(p) => rust_type_depth(&p.elem),
+        Type::Group(g) => rust_type_depth(&g.elem),
+        Type::Slice(s) => 1 + rust_type_depth(&s.elem),
+        Type::Array(a) => 1 + rust_type_depth(&a.elem),
+        Type::Tuple(t) if t.elems.is_empty() => 0,
+        Type::Tuple(t) => 1 + t.elems.iter().map(rust_type_depth).max().unwrap_or(0),
+        Type::BareFn(f) => {
+            let inner = f
+                .inputs
+                .iter()
+                .map(|arg| &arg.ty)
+                .chain(return_type(&f.output))
+                .map(rust_type_depth)
+                .max()
+                .unwrap_or(0);
+            1 + inner
+        }
+        Type::ImplTrait(t) => rust_bounds_depth(&t.bounds),
+        Type::TraitObject(t) => rust_bounds_depth(&t.bounds),
+        _ => 0,
+    }
+}
+
+fn rust_bounds_depth<'a>(bounds: impl IntoIterator<Item = &'a syn::TypeParamBound>) -> usize {
+    bounds
+        .into_iter()
+        .filter_map(|bound| match bound {
+            syn::TypeParamBound::Trait(t) => Some(rust_path_depth(&t.path)),
+            _ => None,
+        })
+        .max()
+        .unwrap_or(0)
+}
+
+/// Depth of a path's generic arguments (`Vec<Option<T>>` → 2, `Fn(A) -> B` → 1)
+fn rust_path_depth(path: &syn::Path) -> usize {
+    path.segments
+        .iter()
+        .map(|segment| match &segment.arguments {
+            syn::PathArguments::None => 0,
+            syn::PathArguments::AngleBracketed(args) => {
+                let types: Vec<&syn::Type> = args
+                    .args
+                    .iter()
+                    .filter_map(|arg| match arg {
+                        syn::GenericArgument::Type(ty) => Some(ty),
+                        syn::GenericArgument::AssocType(assoc) => Some(&assoc.ty),
+                        _ => None,
+                    })
+                    .collect();
+                if types.is_empty() {
+                    // Lifetime- or const-only arguments (`Cow<'a>`) add no nesting
+                    0
+                } else {
+                    1 + types.into_iter().map(rust_type_depth).max().unwrap_or(0)
+                }
+            }
+            syn::PathArguments::Parenthesized(args) => {
+                let inner = args
+                    .inputs
+                    .iter()
+                    .chain(return_type(&args.output))
+                    .map(rust_type_depth)
+                    .max()
+                    .unwrap_or(0);
+                1 + inner
+            }
+        })
+        .max()
+        .unwrap_or(0)
+}
+
+// ---------------------------------------------------------------------------
+// C# (tree-sitter)
+// ---------------------------------------------------------------------------
+
+/// Type node kinds that add a level of nesting; everything else is transparent
+const CSHARP_NESTING_TYPE_KINDS: &[&str] = &[
+    "generic_name",
+    "array_type",
+    "tuple_type",
+    "function_pointer_type",
+];
+
+/// Signature complexity of a C# method, constructor, local function, or
+/// operator declaration node.
+pub fn csharp_signature(func_node: &tree_sitter::Node) -> usize {
+    let mut cursor = func_node.walk();
+    let mut type_param_count = 0;
+    let mut depth = 0;
+    for child in func_node.named_children(&mut cursor) {
+        match child.kind() {
+            "type_parameter_list" => {
+                let mut inner = child.walk();
+                type_param_count += child
+                    .named_children(&mut inner)
+                    .filter(|c| c.kind() == "type_parameter")
+                    .count();
+            }
+            "parameter_list" => {
+                let mut inner = child.walk();
+                for param in child.named_children(&mut inner) {
+                    if let Some(ty) = param.child_by_field_name("type") {
+                        depth = depth.max(csharp_type_depth(&ty));
+                    }
+                }
+            }
+            _ => {}
+        }
+    }
+    if let Some(ret) = func_node
+        .child_by_field_name("returns")
+        .or_else(|| func_node.child_by_field_name("type"))
+    {
+        depth = depth.max(csharp_type_depth(&ret));
+    }
+    type_param_count + depth
+}
+
+fn csharp_type_depth(node: &tree_sitter::Node) -> usize {
+    let own = usize::from(CSHARP_NESTING_TYPE_KINDS.contains(&node.kind()));
+    let mut cursor = node.walk();
+    let inner = node
+        .named_children(&mut cursor)
+        .map(|child| csharp_type_depth(&child))
+        .max()
+        .unwrap_or(0);
+    own + inner
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    fn rust(src: &str) -> usize {
+        let item_fn: syn::ItemFn = syn::parse_str(src).unwrap();
+        rust_signature(&item_fn.sig)
+    }
+
+    #[test]
+    fn test_rust_plain_signature_is_zero() {
+        assert_eq!(rust("fn f(x: i32, s: &str) -> bool { true }"), 0);
+        assert_eq!(rust("fn f(&self) {}"), 0);
+    }
+
+    #[test]
+    fn test_rust_nested_generics() {
+        assert_eq!(rust("fn f(x: Option<i32>) {}"), 1);
+        assert_eq!(rust("fn f(m: &[&[i32]]) {}"), 2);
+        assert_eq!(
+            rust("fn f<T, E>() -> Result<Vec<Option<T>>, E> { todo!() }"),
+            5
+        );
+    }
+
+    #[test]
+    fn test_rust_lifetimes_do_not_count() {
+        assert_eq!(rust("fn f<'a>(x: &'a str) -> &'a str { x }"), 0);
+        assert_eq!(rust("fn f<'a, T>(x: Cow<'a, T>) {}"), 2);
+    }
+
+    #[test]
+    fn test_rust_function_types() {
+        assert_eq!(rust("fn f(cb: fn(i32) -> Vec<u8>) {}"), 2);
+        assert_eq!(rust("fn f(cb: Box<dyn Fn(i32) -> bool>) {}"), 2);
+        assert_eq!(rust("fn f(it: impl Iterator<Item = (u8, u8)>) {}"), 2);
+    }
+
+    fn typescript(src: &str) -> Vec<usize> {
+        use swc_common::{sync::Lrc, SourceMap};
+
+        let cm: Lrc<SourceMap> = Default::default();
+        let module = crate::parser::parse_source(src, &cm, "test.ts").unwrap();
+        crate::discover::discover_functions(&module, 0, src, &cm)
+            .iter()
+            .map(|f| f.signature_complexity)
+            .collect()
+    }
+
+    #[test]
+    fn test_typescript_signatures() {
+        let src = r#"
+            function plain(a: number, b: string): boolean { return true; }
+            function arr(items: number[]): number { return 0; }
+            function deep<K, V>(m: Map<K, Array<Promise<V>>>): void {}
+            const cb = (f: (x: string[]) => void | null): Promise<void> => f([]);
+        "#;
+        assert_eq!(typescript(src), vec![0, 1, 5, 2]);
+    }
+}
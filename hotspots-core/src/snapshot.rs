@@ -11,12 +11,12 @@
 
 use crate::git::GitContext;
 use crate::language::Language;
-use crate::report::{FunctionRiskReport, MetricsReport};
+use crate::report::{FunctionRiskReport, MetricsReport, WithToolVersion};
 use crate::risk::RiskBand;
 use anyhow::{Context, Result};
 use rayon::prelude::*;
 use serde::{Deserialize, Serialize};
-use std::collections::HashMap;
+use std::collections::{BTreeMap, HashMap};
 use std::path::{Path, PathBuf};
 
 #[cfg(test)]
@@ -127,6 +127,21 @@ pub struct CallGraphMetrics {
     pub dependency_depth: Option<usize>,
     #[serde(skip_serializing_if = "Option::is_none")]
     pub neighbor_churn: Option<usize>,
+    /// Calls itself, or is in a call cycle with other functions
+    #[serde(default, skip_serializing_if = "std::ops::Not::not")]
+    pub recursive: bool,
+    /// Function IDs of the cycle's members, this one included, sorted; empty
+    /// unless `recursive`
+    #[serde(default, skip_serializing_if = "Vec::is_empty")]
+    pub cycle: Vec<String>,
+    /// Distinct callees that match no analyzed function (standard library,
+    /// dependencies); never edges, so not in `fan_out`
+    #[serde(default, skip_serializing_if = "is_zero")]
+    pub external_calls: usize,
+}
+
+fn is_zero(n: &usize) -> bool {
+    *n == 0
 }
 
 /// Function entry in snapshot
@@ -258,6 +273,104 @@ pub struct FunctionSnapshot {
     /// None unless `--explain` was passed and a trained ranker is present.
     #[serde(skip_serializing_if = "Option::is_none")]
     pub explanation: Option<String>,
+    /// Guard-clause vs. deep-nesting indicator, carried over from the analysis
+    /// report. None for snapshots loaded from the database.
+    #[serde(skip_serializing_if = "Option::is_none", default)]
+    pub structure: Option<crate::patterns::StructureQuality>,
+    /// Decision points behind CC, per construct (e.g. `{"if": 3, "and": 1}`).
+    /// Diffed against the baseline by `--explain-diff`. None for snapshots
+    /// written before it was recorded and for SQL.
+    #[serde(skip_serializing_if = "Option::is_none", default)]
+    pub cc_breakdown: Option<crate::metrics::CcBreakdown>,
+}
+
+/// Each file's decision points outside every function, keyed by the file's
+/// path as in [`FunctionSnapshot::file`]. Files with none are left out.
+pub fn top_level_decisions_by_file(reports: &[FunctionRiskReport]) -> BTreeMap<String, usize> {
+    reports
+        .iter()
+        .filter(|r| r.file_top_level_decisions > 0)
+        .map(|r| (r.file.replace('\\', "/"), r.file_top_level_decisions))
+        .collect()
+}
+
+impl From<FunctionRiskReport> for FunctionSnapshot {
+    /// Build a function snapshot from an analysis report.
+    ///
+    /// Function ID is `<relative_file_path>::<symbol>` where:
+    /// - `relative_file_path` is normalized to use `/` separators
+    /// - `symbol` is the function name (or `<anonymous>` for anonymous functions)
+    ///
+    /// All git/call-graph enrichment fields start as `None`.
+    fn from(report: FunctionRiskReport) -> Self {
+        // Normalize file path to use `/` separators
+        let normalized_file = report.file.replace('\\', "/");
+
+        // Extract function name for function_id
+        // Use the function name from report, or derive from file/line if needed
+        let function_symbol = if report.function.starts_with("<anonymous>") {
+            "<anonymous>"
+        } else {
+            &report.function
+        };
+
+        // Build function_id: <relative_file_path>::<symbol>
+        let function_id = format!("{}::{}", normalized_file, function_symbol);
+
+        FunctionSnapshot {
+            function_id,
+            file: normalized_file,
+            line: report.line,
+            language: report.language,
+            metrics: report.metrics,
+            lrs: report.lrs,
+            band: report.band,
+            suppression_reason: report.suppression_reason,
+            churn: None,           // Churn will be populated separately if available
+            touch_count_30d: None, // Touch count will be populated separately if available
+            days_since_last_change: None, // Days since last change will be populated separately if available
+            callgraph: None, // Call graph metrics will be populated separately if available
+            activity_risk: None,
+            risk_factors: None,
+            percentile: None,
+            driver: None,
+            driver_detail: None,
+            quadrant: None,
+            patterns: report.patterns,
+            pattern_details: None,
+            subsystem: None,
+            authors_90d: None,
+            directed_coupling: None,
+            jaccard_label_stability: None,
+            convention_bug_fix_count: None,
+            burst_score: None,
+            commit_count: None,
+            author_count: None,
+            author_entropy: None,
+            isolation_rate: None,
+            age_days: None,
+            last_touch_days: None,
+            explanation: None,
+            structure: report.structure,
+            cc_breakdown: report.cc_breakdown,
+        }
+    }
+}
+
+impl FunctionSnapshot {
+    /// Rewrite `file`, and the file part of `function_id`, with `map`
+    pub fn map_path(&mut self, map: impl Fn(&str) -> String) {
+        self.function_id = map_function_id(&self.function_id, &map);
+        self.file = map(&self.file);
+    }
+}
+
+/// Rewrite the file part of a `<file>::<symbol>` function ID with `map`
+fn map_function_id(function_id: &str, map: impl Fn(&str) -> String) -> String {
+    match function_id.split_once("::") {
+        Some((file, symbol)) => format!("{}::{}", map(file), symbol),
+        None => function_id.to_string(),
+    }
 }
 
 /// Risk distribution by band
@@ -309,6 +422,17 @@ pub struct Snapshot {
     pub summary: Option<SnapshotSummary>,
     #[serde(skip_serializing_if = "Option::is_none")]
     pub aggregates: Option<crate::aggregates::SnapshotAggregates>,
+    /// Whether `--max-results` cut `functions` short. Only set when a cap was requested.
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub truncated: Option<bool>,
+    /// Function count before the `--max-results` cap. Only set when a cap was requested.
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub total_functions: Option<usize>,
+    /// Decision points outside every function, per file that has any (see
+    /// [`top_level_decisions_by_file`]); added to the file's `file_cc`. Not
+    /// serialized, so snapshots loaded from disk have none.
+    #[serde(skip)]
+    pub top_level_decisions: BTreeMap<String, usize>,
 }
 
 /// Index entry for a commit
@@ -453,61 +577,10 @@ impl Snapshot {
     /// - `relative_file_path` is normalized to use `/` separators
     /// - `symbol` is the function name (or `<anonymous>` for anonymous functions)
     pub fn new(git_context: GitContext, reports: Vec<FunctionRiskReport>) -> Self {
+        let top_level_decisions = top_level_decisions_by_file(&reports);
         // Normalize paths and build function snapshots
-        let mut functions: Vec<FunctionSnapshot> = reports
-            .into_iter()
-            .map(|report| {
-                // Normalize file path to use `/` separators
-                let normalized_file = report.file.replace('\\', "/");
-
-                // Extract function name for function_id
-                // Use the function name from report, or derive from file/line if needed
-                let function_symbol = if report.function.starts_with("<anonymous>") {
-                    "<anonymous>"
-                } else {
-                    &report.function
-                };
-
-                // Build function_id: <relative_file_path>::<symbol>
-                let function_id = format!("{}::{}", normalized_file, function_symbol);
-
-                FunctionSnapshot {
-                    function_id,
-                    file: normalized_file,
-                    line: report.line,
-                    language: report.language,
-                    metrics: report.metrics,
-                    lrs: report.lrs,
-                    band: report.band,
-                    suppression_reason: report.suppression_reason,
-                    churn: None, // Churn will be populated separately if available
-                    touch_count_30d: None, // Touch count will be populated separately if available
-                    days_since_last_change: None, // Days since last change will be populated separately if available
-                    callgraph: None, // Call graph metrics will be populated separately if available
-                    activity_risk: None,
-                    risk_factors: None,
-                    percentile: None,
-                    driver: None,
-                    driver_detail: None,
-                    quadrant: None,
-                    patterns: report.patterns,
-                    pattern_details: None,
-                    subsystem: None,
-                    authors_90d: None,
-                    directed_coupling: None,
-                    jaccard_label_stability: None,
-                    convention_bug_fix_count: None,
-                    burst_score: None,
-                    commit_count: None,
-                    author_count: None,
-                    author_entropy: None,
-                    isolation_rate: None,
-                    age_days: None,
-                    last_touch_days: None,
-                    explanation: None,
-                }
-            })
-            .collect();
+        let mut functions: Vec<FunctionSnapshot> =
+            reports.into_iter().map(FunctionSnapshot::from).collect();
 
         // Sort functions deterministically by function_id (ASCII lexical ordering)
         functions.sort_by(|a, b| a.function_id.cmp(&b.function_id));
@@ -522,6 +595,9 @@ impl Snapshot {
             functions,
             summary: None,
             aggregates: None, // Aggregates are computed on-demand, not stored
+            truncated: None,
+            total_functions: None,
+            top_level_decisions,
         }
     }
 
@@ -835,6 +911,7 @@ impl Snapshot {
         // live in memory at once, regardless of total index count.
         const CHUNK_SIZE: usize = 512;
         let mut completed = 0usize;
+        let paths = RepoPaths::new(repo_root);
 
         for chunk in indices.chunks(CHUNK_SIZE) {
             // Phase A: check cache for this chunk; collect misses.
@@ -842,12 +919,8 @@ impl Snapshot {
             let mut chunk_misses: Vec<(usize, String, String, u32, u32)> = Vec::new();
             for &i in chunk {
                 let function = &self.functions[i];
-                let rel =
-                    if let Ok(r) = std::path::Path::new(&function.file).strip_prefix(repo_root) {
-                        r.to_string_lossy().replace('\\', "/")
-                    } else {
-                        function.file.replace('\\', "/")
-                    };
+                // Portable so cache keys match across OSes and checkouts
+                let rel = paths.portable(&function.file);
                 let start_line = function.line;
                 let end_line =
                     (start_line + function.metrics.loc.saturating_sub(1)).max(start_line);
@@ -1114,6 +1187,7 @@ impl Snapshot {
             call_graph.betweenness_centrality()
         };
         let scc_info = call_graph.find_strongly_connected_components();
+        let cycles = call_graph.find_cycles();
         let dependency_depths = call_graph.compute_dependency_depth();
         // Precompute fan-in counts in O(N+E) to avoid O(N*E) repeated fan_in() calls below
         let fan_in_map = call_graph.build_fan_in_map();
@@ -1135,6 +1209,7 @@ impl Snapshot {
             if call_graph.contains(function_id) {
                 let (scc_id, scc_size) = scc_info.get(function_id).copied().unwrap_or((0, 1));
                 let dependency_depth = dependency_depths.get(function_id).copied().flatten();
+                let cycle = cycles.get(function_id).cloned().unwrap_or_default();
 
                 // Compute neighbor churn: sum of churn for all callees
                 let neighbor_churn = if let Some(callees) = call_graph.callees_of(function_id) {
@@ -1160,6 +1235,9 @@ impl Snapshot {
                     is_entrypoint: call_graph.is_entry_point(function_id),
                     dependency_depth,
                     neighbor_churn,
+                    recursive: !cycle.is_empty(),
+                    cycle,
+                    external_calls: call_graph.external_calls(function_id),
                 });
             }
         }
@@ -1230,14 +1308,19 @@ impl Snapshot {
     /// Tier 1–only patterns carried from the analysis report. Must be called
     /// after `populate_churn()`, `populate_callgraph()`, and
     /// `populate_touch_metrics()` for accurate Tier 2 patterns.
+    ///
+    /// `arrow_code` is the exception: arrow depth is not persisted, so the
+    /// label is kept from the analysis report rather than recomputed.
     pub fn populate_patterns(&mut self, thresholds: &crate::patterns::Thresholds) {
         for function in &mut self.functions {
+            let arrow_code = function.patterns.iter().any(|p| p == "arrow_code");
             let t1 = crate::patterns::Tier1Input {
                 cc: function.metrics.cc as usize,
                 nd: function.metrics.nd as usize,
                 fo: function.metrics.fo as usize,
                 ns: function.metrics.ns as usize,
                 loc: function.metrics.loc as usize,
+                arrow_depth: None,
             };
             // churn_lines is intentionally None here: function.churn is file-level
             // (all functions in a file share the same total), not per-function. Using
@@ -1264,6 +1347,10 @@ impl Snapshot {
                 is_entrypoint,
             };
             function.patterns = crate::patterns::classify(&t1, &t2, thresholds);
+            if arrow_code {
+                // Alphabetically first among Tier 1 patterns
+                function.patterns.insert(0, "arrow_code".to_string());
+            }
         }
     }
 
@@ -1279,6 +1366,7 @@ impl Snapshot {
                 fo: function.metrics.fo as usize,
                 ns: function.metrics.ns as usize,
                 loc: function.metrics.loc as usize,
+                arrow_depth: None,
             };
             let (fan_in, scc_size, neighbor_churn, is_entrypoint) =
                 if let Some(ref cg) = function.callgraph {
@@ -1299,8 +1387,11 @@ impl Snapshot {
                 neighbor_churn,
                 is_entrypoint,
             };
-            function.pattern_details =
-                Some(crate::patterns::classify_detailed(&t1, &t2, thresholds));
+            let mut details = crate::patterns::classify_detailed(&t1, &t2, thresholds);
+            if function.patterns.iter().any(|p| p == "arrow_code") {
+                details.insert(0, crate::patterns::carried_detail("arrow_code"));
+            }
+            function.pattern_details = Some(details);
         }
     }
 
@@ -1480,21 +1571,46 @@ impl Snapshot {
         });
     }
 
+    /// Keep only the `max` riskiest functions (`activity_risk`, falling back to
+    /// `lrs`) and record `truncated` / `total_functions` so consumers can tell
+    /// data was cut.
+    ///
+    /// Call after summaries and aggregates are computed so they still describe
+    /// the full snapshot.
+    pub fn cap_functions(&mut self, max: usize) {
+        let total = self.functions.len();
+        self.functions.sort_by(|a, b| {
+            let a_score = a.activity_risk.unwrap_or(a.lrs);
+            let b_score = b.activity_risk.unwrap_or(b.lrs);
+            b_score
+                .partial_cmp(&a_score)
+                .unwrap_or(std::cmp::Ordering::Equal)
+                .then_with(|| a.function_id.cmp(&b.function_id))
+        });
+        self.functions.truncate(max);
+        self.truncated = Some(total > max);
+        self.total_functions = Some(total);
+    }
+
     /// Serialize snapshot as JSONL (one JSON object per line, no outer array)
     ///
-    /// Each line embeds the commit context alongside function data,
+    /// Each line embeds the commit context, `schema_version`, and
+    /// `tool_version` alongside function data,
     /// suitable for streaming ingestion (DuckDB, jq -s, etc.)
-    pub fn to_jsonl(&self) -> Result<String> {
+    pub fn to_jsonl(&self, tool_version: &str) -> Result<String> {
         let commit_json =
             serde_json::to_value(&self.commit).context("failed to serialize commit")?;
 
         let mut lines = Vec::with_capacity(self.functions.len());
         for func in &self.functions {
             let mut obj = serde_json::to_value(func).context("failed to serialize function")?;
-            // Embed commit context in each row
-            obj.as_object_mut()
-                .context("serialized function is not a JSON object")?
-                .insert("commit".to_string(), commit_json.clone());
+            // Embed commit context and versions in each row
+            let row = obj
+                .as_object_mut()
+                .context("serialized function is not a JSON object")?;
+            row.insert("commit".to_string(), commit_json.clone());
+            row.insert("schema_version".to_string(), self.schema_version.into());
+            row.insert("tool_version".to_string(), tool_version.into());
             lines.push(serde_json::to_string(&obj).context("failed to serialize JSONL line")?);
         }
 
@@ -1507,27 +1623,39 @@ impl Snapshot {
     }
 
     /// Write the snapshot as pretty-printed JSON directly to `writer` without
-    /// building an intermediate `String`.
+    /// building an intermediate `String`, with a top-level `tool_version`
+    /// after its fields (unlike the persisted [`Snapshot::to_json`]).
     ///
     /// Use this instead of `to_json()` + `println!` when outputting large
     /// snapshots — it avoids allocating a potentially hundreds-of-MB string.
-    pub fn write_json_to<W: std::io::Write>(&self, writer: &mut W) -> Result<()> {
-        serde_json::to_writer_pretty(&mut *writer, self)
+    pub fn write_json_to<W: std::io::Write>(
+        &self,
+        writer: &mut W,
+        tool_version: &str,
+    ) -> Result<()> {
+        serde_json::to_writer_pretty(&mut *writer, &WithToolVersion::new(self, tool_version))
             .context("failed to write snapshot JSON")?;
         writeln!(writer).context("failed to write trailing newline")
     }
 
     /// Write the snapshot as JSONL (one JSON object per function) directly to
     /// `writer`, without building an intermediate `String`.
-    pub fn write_jsonl_to<W: std::io::Write>(&self, writer: &mut W) -> Result<()> {
+    pub fn write_jsonl_to<W: std::io::Write>(
+        &self,
+        writer: &mut W,
+        tool_version: &str,
+    ) -> Result<()> {
         let commit_json =
             serde_json::to_value(&self.commit).context("failed to serialize commit")?;
 
         for func in &self.functions {
             let mut obj = serde_json::to_value(func).context("failed to serialize function")?;
-            obj.as_object_mut()
-                .context("serialized function is not a JSON object")?
-                .insert("commit".to_string(), commit_json.clone());
+            let row = obj
+                .as_object_mut()
+                .context("serialized function is not a JSON object")?;
+            row.insert("commit".to_string(), commit_json.clone());
+            row.insert("schema_version".to_string(), self.schema_version.into());
+            row.insert("tool_version".to_string(), tool_version.into());
             serde_json::to_writer(writer as &mut dyn std::io::Write, &obj)
                 .context("failed to write JSONL line")?;
             writeln!(writer).context("failed to write JSONL newline")?;
@@ -1559,6 +1687,13 @@ impl Snapshot {
     pub fn commit_sha(&self) -> &str {
         &self.commit.sha
     }
+
+    /// Rewrite every function's path with `map` (see [`RepoPaths`])
+    pub fn map_paths(&mut self, map: impl Fn(&str) -> String) {
+        for function in &mut self.functions {
+            function.map_path(&map);
+        }
+    }
 }
 
 /// Returns (top_1_pct_share, top_5_pct_share, top_10_pct_share) from a
@@ -2086,6 +2221,18 @@ pub struct DeltaSnapshot {
     pub summary: Option<SnapshotSummary>,
 }
 
+impl DeltaSnapshot {
+    /// Rewrite every function path, including removed IDs, with `map`
+    pub fn map_paths(&mut self, map: impl Fn(&str) -> String) {
+        for function in self.added.iter_mut().chain(self.modified.iter_mut()) {
+            function.map_path(&map);
+        }
+        for id in &mut self.removed {
+            *id = map_function_id(id, &map);
+        }
+    }
+}
+
 /// Compute a delta from `base` to `current`.
 ///
 /// The returned `DeltaSnapshot` encodes only the functions that were added,
@@ -2173,19 +2320,98 @@ pub fn apply_delta(base: Snapshot, delta: DeltaSnapshot) -> Snapshot {
         functions: result,
         summary: delta.summary,
         aggregates: None,
+        truncated: None,
+        total_functions: None,
+        top_level_decisions: Default::default(),
     }
 }
 
 /// Persist a delta snapshot to `<sha>.delta.json.zst`.
+///
+/// Paths are written in portable form (see [`RepoPaths`]).
 pub fn persist_delta(repo_root: &Path, delta: &DeltaSnapshot) -> Result<()> {
     let path = delta_snapshot_path(repo_root, &delta.commit.sha);
-    let json = serde_json::to_string_pretty(delta).context("failed to serialize delta snapshot")?;
+    let paths = RepoPaths::new(repo_root);
+    let mut portable = delta.clone();
+    portable.map_paths(|p| paths.portable(p));
+    let json =
+        serde_json::to_string_pretty(&portable).context("failed to serialize delta snapshot")?;
     let compressed =
         zstd::encode_all(json.as_bytes(), 3).context("failed to compress delta snapshot")?;
     atomic_write_bytes(&path, &compressed)
         .with_context(|| format!("failed to persist delta snapshot: {}", path.display()))
 }
 
+/// Converts function paths between their in-memory form (as analyzed, usually
+/// absolute) and the portable form stored in snapshots and delta snapshots:
+/// `/` separators, relative to the repository root, no leading `./`.
+///
+/// Portable paths make `.hotspots/` artifacts match across operating systems
+/// and checkout locations. Paths outside the root are kept as written, with
+/// `/` separators.
+pub struct RepoPaths {
+    /// `/`-terminated root prefixes: the root as given, then its canonical
+    /// form when that differs (e.g. macOS `/tmp` → `/private/tmp`)
+    prefixes: Vec<String>,
+}
+
+impl RepoPaths {
+    pub fn new(repo_root: &Path) -> Self {
+        let prefix = |root: &Path| {
+            let root: PathBuf = root.components().collect();
+            format!(
+                "{}/",
+                root.to_string_lossy()
+                    .replace('\\', "/")
+                    .trim_end_matches('/')
+            )
+        };
+        let mut prefixes = vec![prefix(repo_root)];
+        if let Ok(canonical) = repo_root.canonicalize() {
+            let canonical = prefix(&canonical);
+            if canonical != prefixes[0] {
+                prefixes.push(canonical);
+            }
+        }
+        RepoPaths { prefixes }
+    }
+
+    /// Portable form of `path`
+    pub fn portable(&self, path: &str) -> String {
+        let path = path.replace('\\', "/");
+        let rel = self
+            .prefixes
+            .iter()
+            .find_map(|prefix| path.strip_prefix(prefix.as_str()))
+            .unwrap_or(&path);
+        rel.strip_prefix("./").unwrap_or(rel).to_string()
+    }
+
+    /// Portable form of a `file::symbol` function id
+    pub fn portable_id(&self, function_id: &str) -> String {
+        map_function_id(function_id, |file| self.portable(file))
+    }
+
+    /// In-memory form of a stored path: portable paths are joined onto the
+    /// root. Absolute paths (written by versions before portable paths, or
+    /// from another machine) are only normalized to `/` separators.
+    pub fn anchored(&self, path: &str) -> String {
+        let rel = self.portable(path);
+        if is_absolute_portable(&rel) {
+            rel
+        } else {
+            format!("{}{}", self.prefixes[0], rel)
+        }
+    }
+}
+
+/// `/`-rooted or drive-letter (`C:/`) path
+fn is_absolute_portable(path: &str) -> bool {
+    let bytes = path.as_bytes();
+    path.starts_with('/')
+        || (bytes.len() >= 3 && bytes[0].is_ascii_alphabetic() && &bytes[1..3] == b":/")
+}
+
 /// Get the path to the `.hotspots` directory in the repository root
 pub fn hotspots_dir(repo_root: &Path) -> PathBuf {
     repo_root.join(".hotspots")
@@ -2232,9 +2458,13 @@ pub fn snapshot_path_existing(repo_root: &Path, commit_sha: &str) -> Option<Path
 /// (`.delta.json.zst`), and transparent reconstruction of delta chains.
 /// Returns `None` if no snapshot or delta file exists for the SHA.
 pub fn load_snapshot(repo_root: &Path, commit_sha: &str) -> Result<Option<Snapshot>> {
+    let paths = RepoPaths::new(repo_root);
+
     // Full snapshot takes priority.
     if let Some(path) = snapshot_path_existing(repo_root, commit_sha) {
-        return Ok(Some(read_snapshot_file(&path)?));
+        let mut snapshot = read_snapshot_file(&path)?;
+        snapshot.map_paths(|p| paths.anchored(p));
+        return Ok(Some(snapshot));
     }
 
     // Fall back to delta reconstruction.
@@ -2245,8 +2475,9 @@ pub fn load_snapshot(repo_root: &Path, commit_sha: &str) -> Result<Option<Snapsh
         let bytes = zstd::decode_all(compressed.as_slice())
             .with_context(|| format!("failed to decompress delta: {}", dpath.display()))?;
         let json = String::from_utf8(bytes).context("delta snapshot contains invalid UTF-8")?;
-        let delta: DeltaSnapshot = serde_json::from_str(&json)
+        let mut delta: DeltaSnapshot = serde_json::from_str(&json)
             .with_context(|| format!("failed to parse delta: {}", dpath.display()))?;
+        delta.map_paths(|p| paths.anchored(p));
         let base = load_snapshot(repo_root, &delta.base_sha)?.ok_or_else(|| {
             anyhow::anyhow!(
                 "base snapshot {} not found for delta {}",
@@ -2364,13 +2595,19 @@ pub fn persist_snapshot(repo_root: &Path, snapshot: &Snapshot, force: bool) -> R
     // difference due to the float parser's rounding). Both the on-disk snapshot
     // (already round-tripped once) and the freshly-computed snapshot are brought
     // to the same canonical representation before comparing.
-    let canonical_json = Snapshot::from_json(&snapshot.to_json()?)
-        .context("failed to normalize snapshot for canonical form")?
-        .to_json()?;
+    // Paths are stored in portable form so snapshots match across OSes and
+    // checkouts; `load_snapshot` anchors them at the repo root again.
+    let paths = RepoPaths::new(repo_root);
+    let mut canonical = Snapshot::from_json(&snapshot.to_json()?)
+        .context("failed to normalize snapshot for canonical form")?;
+    canonical.map_paths(|p| paths.portable(p));
+    let canonical_json = canonical.to_json()?;
 
     if !force {
-        if let Some(existing) = load_snapshot(repo_root, snapshot.commit_sha())? {
-            // Compare canonical forms (both normalized through one parse-reserialize cycle)
+        if let Some(mut existing) = load_snapshot(repo_root, snapshot.commit_sha())? {
+            // Compare canonical forms (both normalized through one parse-reserialize
+            // cycle, both with portable paths)
+            existing.map_paths(|p| paths.portable(p));
             if existing.to_json()? == canonical_json {
                 return Ok(());
             }
@@ -2497,14 +2734,31 @@ mod tests {
         let report = FunctionRiskReport {
             file: "src/foo.ts".to_string(),
             function: "handler".to_string(),
+            owner: None,
             line: 42,
             language: Language::TypeScript,
             metrics: MetricsReport {
                 cc: 5,
                 nd: 2,
                 fo: 3,
+                fi: 0,
                 ns: 1,
                 loc: 10,
+                signature_complexity: 0,
+                params: 0,
+                guard_clauses: 0,
+                max_condition_ops: 0,
+                cognitive: 0,
+                halstead: None,
+                maintainability: None,
+                sloc: None,
+                comment_lines: None,
+                blank_lines: None,
+                nd_line: None,
+                cc_lines: None,
+                is_async: false,
+                await_in_loop: 0,
+                ns_breakdown: None,
             },
             risk: RiskReport {
                 r_cc: 2.0,
@@ -2514,11 +2768,18 @@ mod tests {
             },
             lrs: 4.8,
             band: RiskBand::Moderate,
+            risk_score: None,
             suppression_reason: None,
             patterns: vec![],
             pattern_details: None,
             callees: vec![],
             explanation: None,
+            arrow_depth: 0,
+            aliases: vec![],
+            structure: None,
+            cc_breakdown: None,
+            is_public: false,
+            file_top_level_decisions: 0,
         };
 
         Snapshot::new(git_context, vec![report])
@@ -2540,12 +2801,74 @@ mod tests {
         assert_eq!(deserialized.functions.len(), snapshot.functions.len());
     }
 
+    #[test]
+    fn test_snapshot_output_carries_tool_version() {
+        let snapshot = create_test_snapshot();
+
+        let mut out = Vec::new();
+        snapshot.write_json_to(&mut out, "1.2.3").unwrap();
+        let json: serde_json::Value = serde_json::from_slice(&out).unwrap();
+        assert_eq!(json["schema_version"], SNAPSHOT_SCHEMA_VERSION);
+        assert_eq!(json["tool_version"], "1.2.3");
+        // Output still reads back as a snapshot
+        Snapshot::from_json(std::str::from_utf8(&out).unwrap()).unwrap();
+
+        let mut out = Vec::new();
+        snapshot.write_jsonl_to(&mut out, "1.2.3").unwrap();
+        let line: serde_json::Value =
+            serde_json::from_str(std::str::from_utf8(&out).unwrap().lines().next().unwrap())
+                .unwrap();
+        assert_eq!(line["schema_version"], SNAPSHOT_SCHEMA_VERSION);
+        assert_eq!(line["tool_version"], "1.2.3");
+        assert_eq!(line["function_id"], "src/foo.ts::handler");
+    }
+
     #[test]
     fn test_function_id_format() {
         let snapshot = create_test_snapshot();
         assert_eq!(snapshot.functions[0].function_id, "src/foo.ts::handler");
     }
 
+    #[test]
+    fn test_cap_functions_records_truncation() {
+        let mut snapshot = create_test_snapshot();
+        let mut low = snapshot.functions[0].clone();
+        low.function_id = "src/foo.ts::low".to_string();
+        low.lrs = 1.0;
+        let mut high = snapshot.functions[0].clone();
+        high.function_id = "src/foo.ts::high".to_string();
+        high.lrs = 9.0;
+        snapshot.functions.push(low);
+        snapshot.functions.push(high);
+
+        snapshot.cap_functions(2);
+
+        assert_eq!(snapshot.functions.len(), 2);
+        assert_eq!(snapshot.functions[0].function_id, "src/foo.ts::high");
+        assert_eq!(snapshot.functions[1].function_id, "src/foo.ts::handler");
+        assert_eq!(snapshot.truncated, Some(true));
+        assert_eq!(snapshot.total_functions, Some(3));
+
+        let json = snapshot.to_json().expect("should serialize");
+        assert!(json.contains("\"truncated\": true"));
+        assert!(json.contains("\"total_functions\": 3"));
+    }
+
+    #[test]
+    fn test_cap_functions_under_limit_not_truncated() {
+        let mut snapshot = create_test_snapshot();
+        snapshot.cap_functions(10);
+        assert_eq!(snapshot.functions.len(), 1);
+        assert_eq!(snapshot.truncated, Some(false));
+        assert_eq!(snapshot.total_functions, Some(1));
+    }
+
+    #[test]
+    fn test_uncapped_snapshot_omits_truncation_fields() {
+        let json = create_test_snapshot().to_json().expect("should serialize");
+        assert!(!json.contains("\"truncated\""));
+    }
+
     #[test]
     fn test_snapshot_enricher_with_churn() {
         use crate::git::FileChurn;
@@ -2715,4 +3038,40 @@ mod tests {
         assert_eq!(calls[0], (0, 1));
         assert_eq!(*calls.last().unwrap(), (1, 1));
     }
+
+    #[test]
+    fn test_repo_paths_portable() {
+        let paths = RepoPaths::new(Path::new("/work/repo"));
+        assert_eq!(paths.portable("/work/repo/src/a.ts"), "src/a.ts");
+        assert_eq!(paths.portable("src\\api\\b.ts"), "src/api/b.ts");
+        assert_eq!(paths.portable("./src/a.ts"), "src/a.ts");
+        assert_eq!(paths.portable("/work/repo/./src/a.ts"), "src/a.ts");
+        // Outside the root: kept, with `/` separators
+        assert_eq!(paths.portable("/elsewhere/x.ts"), "/elsewhere/x.ts");
+        assert_eq!(
+            paths.portable("/work/repository/x.ts"),
+            "/work/repository/x.ts"
+        );
+    }
+
+    #[test]
+    fn test_repo_paths_anchored() {
+        let paths = RepoPaths::new(Path::new("/work/repo/."));
+        assert_eq!(paths.anchored("src\\a.ts"), "/work/repo/src/a.ts");
+        assert_eq!(paths.anchored("/work/repo/src/a.ts"), "/work/repo/src/a.ts");
+        // Absolute paths from elsewhere are not re-rooted
+        assert_eq!(paths.anchored("C:\\work\\a.ts"), "C:/work/a.ts");
+        assert_eq!(paths.anchored("/elsewhere/x.ts"), "/elsewhere/x.ts");
+    }
+
+    #[test]
+    fn test_map_path_rewrites_function_id() {
+        let mut function = create_test_snapshot().functions.remove(0);
+        function.function_id = "src\\foo.ts::Foo::bar".to_string();
+        function.file = "src\\foo.ts".to_string();
+        let paths = RepoPaths::new(Path::new("/repo"));
+        function.map_path(|p| paths.anchored(p));
+        assert_eq!(function.file, "/repo/src/foo.ts");
+        assert_eq!(function.function_id, "/repo/src/foo.ts::Foo::bar");
+    }
 }
@@ -0,0 +1,212 @@
+//! Per-file summary (`analyze --summary`)
+//!
+//! One line per file instead of one per function: how many functions it has,
+//! their total and mean CC, the deepest nesting, and its riskiest function.
+//! Quicker to scan than the full listing when deciding which file to open.
+//!
+//! Global invariants enforced:
+//! - A file's function count and total CC equal those of its functions
+//! - The worst function is the file's first in canonical report order
+//! - Deterministic output ordering (sort key descending, then path)
+
+use crate::report::{canonical_order, FunctionRiskReport};
+use anyhow::{Context, Result};
+use serde::Serialize;
+use std::collections::BTreeMap;
+use std::path::Path;
+
+/// What files are ranked by
+#[derive(Debug, Clone, Copy, Default, PartialEq, Eq)]
+pub enum SummarySort {
+    /// Sum of the functions' CC
+    #[default]
+    Total,
+    /// Highest CC of any one function
+    Max,
+}
+
+/// Complexity of one file
+#[derive(Debug, Clone, Serialize, PartialEq)]
+pub struct FileSummary {
+    /// Path relative to the analysis base
+    pub file: String,
+    /// Number of functions in the file
+    pub functions: usize,
+    /// Sum of the functions' CC
+    pub total_cc: u32,
+    /// `total_cc / functions`
+    pub mean_cc: f64,
+    /// Highest CC of any one function
+    pub max_cc: u32,
+    /// Deepest nesting among the functions
+    pub max_nd: u32,
+    /// The file's riskiest function (highest LRS)
+    pub worst_function: String,
+    /// Start line of the worst function
+    pub worst_line: u32,
+    /// LRS of the worst function
+    pub worst_lrs: f64,
+}
+
+/// Summarize `reports` (every analyzed function, unfiltered) per file, ranked
+/// by `sort`. Paths are relative to `base` when they fall under it.
+pub fn file_summaries(
+    reports: &[FunctionRiskReport],
+    base: &Path,
+    sort: SummarySort,
+) -> Vec<FileSummary> {
+    let mut by_file: BTreeMap<&str, Vec<&FunctionRiskReport>> = BTreeMap::new();
+    for report in reports {
+        by_file.entry(&report.file).or_default().push(report);
+    }
+
+    let mut summaries: Vec<FileSummary> = by_file
+        .into_iter()
+        .map(|(file, mut functions)| {
+            functions.sort_by(|a, b| canonical_order(a, b));
+            let worst = functions[0];
+            let total_cc: u32 = functions.iter().map(|r| r.metrics.cc).sum();
+            FileSummary {
+                file: crate::treemap::relative_path(file, base),
+                functions: functions.len(),
+                total_cc,
+                mean_cc: f64::from(total_cc) / functions.len() as f64,
+                max_cc: functions.iter().map(|r| r.metrics.cc).max().unwrap_or(0),
+                max_nd: functions.iter().map(|r| r.metrics.nd).max().unwrap_or(0),
+                worst_function: worst.function.clone(),
+                worst_line: worst.line,
+                worst_lrs: worst.lrs,
+            }
+        })
+        .collect();
+
+    summaries.sort_by(|a, b| {
+        let key = |s: &FileSummary| match sort {
+            SummarySort::Total => (s.total_cc, s.max_cc),
+            SummarySort::Max => (s.max_cc, s.total_cc),
+        };
+        key(b).cmp(&key(a)).then_with(|| a.file.cmp(&b.file))
+    });
+    summaries
+}
+
+/// Render the summaries as JSON
+pub fn render_summary_json(summaries: &[FileSummary]) -> Result<String> {
+    serde_json::to_string_pretty(summaries).context("failed to serialize file summary")
+}
+
+/// Render the summaries as a text table, one row per file
+pub fn render_summary_text(summaries: &[FileSummary]) -> String {
+    use std::fmt::Write;
+
+    if summaries.is_empty() {
+        return "No functions found.\n".to_string();
+    }
+    let width = summaries
+        .iter()
+        .map(|s| s.file.len())
+        .max()
+        .unwrap_or(0)
+        .max("File".len());
+    let mut out = String::new();
+    let _ = writeln!(
+        out,
+        "{:<width$} {:>9} {:>8} {:>8} {:>6} {:>6}  Worst function",
+        "File", "Functions", "Total CC", "Mean CC", "Max CC", "Max ND"
+    );
+    let _ = writeln!(out, "{}", "-".repeat(width + 58));
+    for s in summaries {
+        let _ = writeln!(
+            out,
+            "{:<width$} {:>9} {:>8} {:>8.2} {:>6} {:>6}  {} (line {}, LRS {:.2})",
+            s.file,
+            s.functions,
+            s.total_cc,
+            s.mean_cc,
+            s.max_cc,
+            s.max_nd,
+            s.worst_function,
+            s.worst_line,
+            s.worst_lrs
+        );
+    }
+    out
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::language::Language;
+    use crate::report::{MetricsReport, RiskReport};
+    use crate::risk::RiskBand;
+
+    fn make_report(file: &str, function: &str, cc: u32, lrs: f64) -> FunctionRiskReport {
+        FunctionRiskReport {
+            file: file.to_string(),
+            function: function.to_string(),
+            owner: None,
+            line: 1,
+            language: Language::Go,
+            metrics: MetricsReport {
+                cc,
+                cognitive: 0,
+                nd: 0,
+                fo: 0,
+                fi: 0,
+                ns: 0,
+                loc: 1,
+                signature_complexity: 0,
+                params: 0,
+                guard_clauses: 0,
+                max_condition_ops: 0,
+                halstead: None,
+                maintainability: None,
+                sloc: None,
+                comment_lines: None,
+                blank_lines: None,
+                nd_line: None,
+                cc_lines: None,
+                is_async: false,
+                await_in_loop: 0,
+                ns_breakdown: None,
+            },
+            risk: RiskReport {
+                r_cc: 0.0,
+                r_nd: 0.0,
+                r_fo: 0.0,
+                r_ns: 0.0,
+            },
+            lrs,
+            band: RiskBand::Low,
+            risk_score: None,
+            suppression_reason: None,
+            patterns: vec![],
+            pattern_details: None,
+            callees: vec![],
+            explanation: None,
+            arrow_depth: 0,
+            aliases: vec![],
+            structure: None,
+            cc_breakdown: None,
+            is_public: false,
+            file_top_level_decisions: 0,
+        }
+    }
+
+    #[test]
+    fn test_equal_files_fall_back_to_path() {
+        let reports = vec![
+            make_report("/repo/b.go", "f", 4, 2.0),
+            make_report("/repo/a.go", "g", 4, 2.0),
+        ];
+        let summaries = file_summaries(&reports, Path::new("/repo"), SummarySort::Max);
+        let files: Vec<&str> = summaries.iter().map(|s| s.file.as_str()).collect();
+        assert_eq!(files, ["a.go", "b.go"]);
+
+        let text = render_summary_text(&summaries);
+        assert!(text.starts_with("File "));
+        assert!(text
+            .contains("\na.go         1        4     4.00      4      0  g (line 1, LRS 2.00)\n"));
+        assert_eq!(render_summary_text(&[]), "No functions found.\n");
+    }
+}
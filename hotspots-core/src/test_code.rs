@@ -0,0 +1,198 @@
+//! Test code detection
+//!
+//! Test code is left out of analysis unless `--include-tests` is given. Files
+//! are classified by name alone ([`TEST_FILE_PATTERNS`]) and functions by
+//! their own declaration ([`is_test_function`]), never by what a file
+//! imports, so production code that uses a test helper is still analyzed.
+//!
+//! - JavaScript/TypeScript: `*.test.*` and `*.spec.*` files, and files under
+//!   `__tests__`, `__mocks__`, or `__snapshots__`
+//! - Python: `test_*.py`, `*_test.py`, and `conftest.py` files
+//! - Go: `*_test.go` files, and `TestXxx`, `BenchmarkXxx`, `FuzzXxx`, and
+//!   `TestMain` functions taking a `*testing.T`, `B`, `F`, or `M`
+//! - Rust: functions marked `#[test]` (or another attribute named `test`,
+//!   such as `#[tokio::test]`), `#[bench]`, or `#[cfg(test)]`. Functions in a
+//!   `#[cfg(test)] mod` are never discovered in the first place; methods of a
+//!   `#[cfg(test)] impl` are not recognized.
+
+use crate::ast::FunctionNode;
+use crate::language::Language;
+
+/// File patterns of test code, matched like `exclude` patterns
+pub const TEST_FILE_PATTERNS: &[&str] = &[
+    // JS/TS test conventions
+    "**/*.test.ts",
+    "**/*.test.tsx",
+    "**/*.test.js",
+    "**/*.test.jsx",
+    "**/*.spec.ts",
+    "**/*.spec.tsx",
+    "**/*.spec.js",
+    "**/*.spec.jsx",
+    "**/__tests__/**",
+    "**/__mocks__/**",
+    "**/__snapshots__/**",
+    // Python test conventions
+    "**/test_*.py",
+    "**/*_test.py",
+    "**/conftest.py",
+    // Go test convention
+    "**/*_test.go",
+];
+
+/// Whether `function`, discovered in `src`, is a test function. Languages
+/// whose tests are recognized by file name only are never test functions.
+pub fn is_test_function(function: &FunctionNode, src: &str, language: Language) -> bool {
+    match language {
+        Language::Go => src
+            .get(function.span.start..function.span.end)
+            .is_some_and(is_go_test_function),
+        Language::Rust => {
+            // The span starts at the outer attributes, the body at `fn`
+            let body = function.body.as_rust();
+            let attrs_end = function.span.end.saturating_sub(body.len());
+            src.get(function.span.start..attrs_end)
+                .is_some_and(has_rust_test_attribute)
+        }
+        _ => false,
+    }
+}
+
+/// Whether a Go function declaration is one `go test` runs: `func TestXxx(t
+/// *testing.T)`, where `Xxx` does not start with a lowercase letter, and
+/// likewise `Benchmark` (`*testing.B`), `Fuzz` (`*testing.F`), and
+/// `TestMain(m *testing.M)`. Methods are never tests.
+fn is_go_test_function(declaration: &str) -> bool {
+    let Some(rest) = declaration.trim_start().strip_prefix("func") else {
+        return false;
+    };
+    let rest = rest.trim_start();
+    let Some(open) = rest.find('(') else {
+        return false;
+    };
+    let name = rest[..open].trim();
+    let Some(close) = rest[open..].find(')') else {
+        return false;
+    };
+    let param_type = rest[open + 1..open + close]
+        .split_whitespace()
+        .collect::<Vec<_>>();
+    let param_type = match param_type.as_slice() {
+        [_, ty] => *ty,
+        _ => return false,
+    };
+
+    let expected = if name == "TestMain" {
+        "*testing.M"
+    } else if has_test_suffix(name, "Test") {
+        "*testing.T"
+    } else if has_test_suffix(name, "Benchmark") {
+        "*testing.B"
+    } else if has_test_suffix(name, "Fuzz") {
+        "*testing.F"
+    } else {
+        return false;
+    };
+    param_type == expected
+}
+
+/// Whether `name` is `prefix` followed by nothing or by a character other
+/// than a lowercase letter (`Test`, `TestParse`, `Test_parse`, but not
+/// `Testdata`)
+fn has_test_suffix(name: &str, prefix: &str) -> bool {
+    name.strip_prefix(prefix)
+        .is_some_and(|rest| !rest.starts_with(|c: char| c.is_lowercase()))
+}
+
+/// Whether Rust outer attributes mark a test: an attribute whose path ends in
+/// `test` or is `bench`, or a `cfg` that requires `test`
+fn has_rust_test_attribute(attrs: &str) -> bool {
+    use syn::parse::Parser;
+
+    let Ok(attrs) = syn::Attribute::parse_outer.parse_str(attrs) else {
+        return false;
+    };
+    attrs.iter().any(|attr| {
+        let path = attr.path();
+        if path.is_ident("cfg") {
+            return attr
+                .parse_args::<syn::Meta>()
+                .is_ok_and(|meta| cfg_requires_test(&meta));
+        }
+        path.is_ident("bench") || path.segments.last().is_some_and(|s| s.ident == "test")
+    })
+}
+
+/// Whether a `cfg` predicate only holds when compiling tests: `test`, or an
+/// `all(...)` with such a predicate. `not(test)` and `any(test, ...)` also
+/// hold outside tests.
+fn cfg_requires_test(predicate: &syn::Meta) -> bool {
+    use syn::punctuated::Punctuated;
+
+    match predicate {
+        syn::Meta::Path(path) => path.is_ident("test"),
+        syn::Meta::List(list) if list.path.is_ident("all") => list
+            .parse_args_with(Punctuated::<syn::Meta, syn::Token![,]>::parse_terminated)
+            .is_ok_and(|predicates| predicates.iter().any(cfg_requires_test)),
+        _ => false,
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn test_go_test_functions() {
+        assert!(is_go_test_function("func TestParse(t *testing.T) {}"));
+        assert!(is_go_test_function("func Test(t *testing.T) {}"));
+        assert!(is_go_test_function("func Test_parse(t *testing.T) {}"));
+        assert!(is_go_test_function("func BenchmarkParse(b *testing.B) {}"));
+        assert!(is_go_test_function("func FuzzParse(f *testing.F) {}"));
+        assert!(is_go_test_function("func TestMain(m *testing.M) {}"));
+    }
+
+    #[test]
+    fn test_go_non_test_functions() {
+        // Lowercase after the prefix: not a test name
+        assert!(!is_go_test_function("func Testdata(t *testing.T) {}"));
+        // Test helpers taking a *testing.T under another name
+        assert!(!is_go_test_function(
+            "func AssertEqual(t *testing.T, a, b int) {}"
+        ));
+        // Test names without a testing parameter
+        assert!(!is_go_test_function("func TestParse() {}"));
+        assert!(!is_go_test_function("func TestParse(s string) {}"));
+        assert!(!is_go_test_function("func BenchmarkParse(t *testing.T) {}"));
+        // Methods
+        assert!(!is_go_test_function(
+            "func (s *Suite) TestParse(t *testing.T) {}"
+        ));
+    }
+
+    #[test]
+    fn test_rust_test_attributes() {
+        assert!(has_rust_test_attribute("#[test]\n"));
+        assert!(has_rust_test_attribute("#[tokio::test]\n"));
+        assert!(has_rust_test_attribute("#[bench]\n"));
+        assert!(has_rust_test_attribute("#[cfg(test)]\n"));
+        assert!(has_rust_test_attribute(
+            "#[cfg(all(test, feature = \"slow\"))]\n"
+        ));
+        assert!(has_rust_test_attribute(
+            "/// Checks parsing\n#[test]\n#[should_panic]\n"
+        ));
+    }
+
+    #[test]
+    fn test_rust_non_test_attributes() {
+        assert!(!has_rust_test_attribute(""));
+        assert!(!has_rust_test_attribute("/// Parses input\n"));
+        assert!(!has_rust_test_attribute("#[inline]\n"));
+        assert!(!has_rust_test_attribute("#[cfg(not(test))]\n"));
+        assert!(!has_rust_test_attribute(
+            "#[cfg(any(test, feature = \"x\"))]\n"
+        ));
+        assert!(!has_rust_test_attribute("#[cfg(feature = \"test\")]\n"));
+    }
+}
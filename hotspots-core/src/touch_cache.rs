@@ -1,7 +1,8 @@
 //! On-disk cache for per-function git touch metrics.
 //!
-//! Cache key: `"{sha}:{file}:{start}:{end}"` where file is a repo-relative path
-//! and start/end are 1-based line numbers. Value: `(touch_count_30d, days_since_last_change)`.
+//! Cache key: `"{sha}:{file}:{start}:{end}"` where file is a portable path
+//! (see `snapshot::RepoPaths`: repo-relative, `/` separators) and start/end
+//! are 1-based line numbers. Value: `(touch_count_30d, days_since_last_change)`.
 //!
 //! **Line range shift behavior:** If surrounding code changes and a function's line
 //! range moves, the cache key will not match (start/end differ) — it is a miss.
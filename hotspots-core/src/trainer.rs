@@ -1471,8 +1471,24 @@ mod tests {
                     cc: 1,
                     nd: 0,
                     fo: 0,
+                    fi: 0,
                     ns: 0,
                     loc: 10,
+                    signature_complexity: 0,
+                    params: 0,
+                    guard_clauses: 0,
+                    max_condition_ops: 0,
+                    cognitive: 0,
+                    halstead: None,
+                    maintainability: None,
+                    sloc: None,
+                    comment_lines: None,
+                    blank_lines: None,
+                    nd_line: None,
+                    cc_lines: None,
+                    is_async: false,
+                    await_in_loop: 0,
+                    ns_breakdown: None,
                 },
                 lrs: 0.0,
                 band: RiskBand::Low,
@@ -1502,6 +1518,8 @@ mod tests {
                 age_days: None,
                 last_touch_days: None,
                 explanation: None,
+                structure: None,
+                cc_breakdown: None,
             })
             .collect();
 
@@ -1525,6 +1543,9 @@ mod tests {
             functions,
             summary: None,
             aggregates: None,
+            truncated: None,
+            total_functions: None,
+            top_level_decisions: Default::default(),
         }
     }
 
@@ -1646,8 +1667,24 @@ mod tests {
                     cc: 1,
                     nd: 0,
                     fo: 0,
+                    fi: 0,
                     ns: 0,
                     loc: 10,
+                    signature_complexity: 0,
+                    params: 0,
+                    guard_clauses: 0,
+                    max_condition_ops: 0,
+                    cognitive: 0,
+                    halstead: None,
+                    maintainability: None,
+                    sloc: None,
+                    comment_lines: None,
+                    blank_lines: None,
+                    nd_line: None,
+                    cc_lines: None,
+                    is_async: false,
+                    await_in_loop: 0,
+                    ns_breakdown: None,
                 },
                 lrs: (i as f64) / (counts.len() as f64),
                 band: RiskBand::Low,
@@ -1677,6 +1714,8 @@ mod tests {
                 age_days: Some(30.0),
                 last_touch_days: Some(1.0),
                 explanation: None,
+                structure: None,
+                cc_breakdown: None,
             })
             .collect();
 
@@ -1700,6 +1739,9 @@ mod tests {
             functions,
             summary: None,
             aggregates: None,
+            truncated: None,
+            total_functions: None,
+            top_level_decisions: Default::default(),
         }
     }
 
@@ -1771,8 +1813,24 @@ mod tests {
                 cc: 1,
                 nd: 0,
                 fo: 0,
+                fi: 0,
                 ns: 0,
                 loc: 10,
+                signature_complexity: 0,
+                params: 0,
+                guard_clauses: 0,
+                max_condition_ops: 0,
+                cognitive: 0,
+                halstead: None,
+                maintainability: None,
+                sloc: None,
+                comment_lines: None,
+                blank_lines: None,
+                nd_line: None,
+                cc_lines: None,
+                is_async: false,
+                await_in_loop: 0,
+                ns_breakdown: None,
             },
             lrs: 0.0,
             band: RiskBand::Low,
@@ -1802,6 +1860,8 @@ mod tests {
             age_days: None,
             last_touch_days: None,
             explanation: None,
+            structure: None,
+            cc_breakdown: None,
         };
         assert_eq!(cold_start_features(&func), [0.0; 8]);
     }
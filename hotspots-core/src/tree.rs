@@ -0,0 +1,270 @@
+//! File tree view (`--format tree`)
+//!
+//! Functions grouped under the file that defines them, for browsing in a
+//! terminal: each file is a header, with its functions indented beneath it
+//! showing their LRS, severity, and metrics, colored by severity.
+//!
+//! Global invariants enforced:
+//! - Every report appears exactly once, under its own file
+//! - Files are ordered by their first function in the input, so with reports
+//!   in LRS order the riskiest file comes first
+//! - A file's functions keep their input order
+
+use crate::report::FunctionRiskReport;
+use crate::severity::{Severity, SeverityScale};
+use owo_colors::OwoColorize;
+use std::collections::HashMap;
+use std::path::Path;
+
+/// Longest function name the columns are padded to; longer names push their
+/// row's metrics right
+const MAX_NAME_WIDTH: usize = 40;
+
+/// Render `reports` as a tree of files and their functions. Paths are
+/// relative to `base` when they fall under it, and each function's severity
+/// is rated on `scale`. `more` is how many functions
+/// `--top` cut from `reports`, noted as "... N more" when not 0. `color`
+/// enables ANSI codes — pass `false` when stdout is not a TTY or `NO_COLOR`
+/// is set.
+pub fn render_tree(
+    reports: &[FunctionRiskReport],
+    base: &Path,
+    scale: &SeverityScale,
+    more: usize,
+    color: bool,
+) -> String {
+    use std::fmt::Write;
+
+    if reports.is_empty() {
+        return "No functions found.\n".to_string();
+    }
+
+    let mut files: Vec<(&str, Vec<&FunctionRiskReport>)> = Vec::new();
+    let mut index: HashMap<&str, usize> = HashMap::new();
+    for report in reports {
+        let i = *index.entry(report.file.as_str()).or_insert_with(|| {
+            files.push((report.file.as_str(), Vec::new()));
+            files.len() - 1
+        });
+        files[i].1.push(report);
+    }
+
+    let mut out = String::new();
+    for (file, functions) in &files {
+        let header = crate::treemap::relative_path(file, base);
+        let _ = writeln!(
+            out,
+            "{}",
+            if color {
+                header.bold().to_string()
+            } else {
+                header
+            }
+        );
+        let name_width = functions
+            .iter()
+            .map(|r| r.function.chars().count())
+            .max()
+            .unwrap_or(0)
+            .min(MAX_NAME_WIDTH);
+        let line_width = functions
+            .iter()
+            .map(|r| r.line.to_string().len())
+            .max()
+            .unwrap_or(0);
+        for (i, r) in functions.iter().enumerate() {
+            let branch = if i + 1 == functions.len() {
+                "└─"
+            } else {
+                "├─"
+            };
+            // Pad before painting, so escape codes do not upset the columns
+            let severity = scale.severity(r);
+            let risk = paint(
+                &format!("{:>6.2}  {:<8}", r.lrs, severity.as_str()),
+                severity,
+                color,
+            );
+            let m = &r.metrics;
+            let _ = write!(
+                out,
+                "  {} {}  {:<name_width$}  line {:<line_width$}  cc {:<3} nd {:<2} fo {:<3} ns {}",
+                branch, risk, r.function, r.line, m.cc, m.nd, m.fo, m.ns,
+            );
+            if !r.patterns.is_empty() {
+                let _ = write!(out, "  [{}]", r.patterns.join(", "));
+            }
+            out.push('\n');
+        }
+        out.push('\n');
+    }
+
+    if more > 0 {
+        let _ = writeln!(out, "... {} more\n", more);
+    }
+    let _ = writeln!(out, "{}", "─".repeat(60));
+    let _ = writeln!(
+        out,
+        "{} {} in {} {}",
+        reports.len(),
+        if reports.len() == 1 {
+            "function"
+        } else {
+            "functions"
+        },
+        files.len(),
+        if files.len() == 1 { "file" } else { "files" }
+    );
+    out
+}
+
+/// Color `text` by severity: critical red, high yellow, medium a plain
+/// yellow, low green, and ok dimmed
+fn paint(text: &str, severity: Severity, color: bool) -> String {
+    if !color {
+        return text.to_string();
+    }
+    match severity {
+        Severity::Critical => text.red().bold().to_string(),
+        Severity::High => text.yellow().bold().to_string(),
+        Severity::Medium => text.yellow().to_string(),
+        Severity::Low => text.green().to_string(),
+        Severity::Ok => text.dimmed().to_string(),
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::language::Language;
+    use crate::report::{MetricsReport, RiskReport};
+    use crate::risk::RiskBand;
+
+    fn make_report(file: &str, function: &str, line: u32, lrs: f64) -> FunctionRiskReport {
+        FunctionRiskReport {
+            file: file.to_string(),
+            function: function.to_string(),
+            owner: None,
+            line,
+            language: Language::Go,
+            metrics: MetricsReport {
+                cc: 2,
+                cognitive: 0,
+                nd: 1,
+                fo: 0,
+                fi: 0,
+                ns: 0,
+                loc: 1,
+                signature_complexity: 0,
+                params: 0,
+                guard_clauses: 0,
+                max_condition_ops: 0,
+                halstead: None,
+                maintainability: None,
+                sloc: None,
+                comment_lines: None,
+                blank_lines: None,
+                nd_line: None,
+                cc_lines: None,
+                is_async: false,
+                await_in_loop: 0,
+                ns_breakdown: None,
+            },
+            risk: RiskReport {
+                r_cc: 0.0,
+                r_nd: 0.0,
+                r_fo: 0.0,
+                r_ns: 0.0,
+            },
+            lrs,
+            band: RiskBand::Low,
+            risk_score: None,
+            suppression_reason: None,
+            patterns: vec![],
+            pattern_details: None,
+            callees: vec![],
+            explanation: None,
+            arrow_depth: 0,
+            aliases: vec![],
+            structure: None,
+            cc_breakdown: None,
+            is_public: false,
+            file_top_level_decisions: 0,
+        }
+    }
+
+    #[test]
+    fn test_functions_grouped_under_their_file() {
+        let reports = vec![
+            make_report("/repo/src/a.go", "run", 10, 9.5),
+            make_report("/repo/src/b.go", "parse", 4, 3.0),
+            make_report("/repo/src/a.go", "stop", 120, 1.25),
+        ];
+        let out = render_tree(
+            &reports,
+            Path::new("/repo"),
+            &SeverityScale::default(),
+            0,
+            false,
+        );
+        let expected = format!(
+            "src/a.go\n  \
+             ├─   9.50  critical  run   line 10   cc 2   nd 1  fo 0   ns 0\n  \
+             └─   1.25  ok        stop  line 120  cc 2   nd 1  fo 0   ns 0\n\
+             \n\
+             src/b.go\n  \
+             └─   3.00  medium    parse  line 4  cc 2   nd 1  fo 0   ns 0\n\
+             \n\
+             {}\n\
+             3 functions in 2 files\n",
+            "─".repeat(60)
+        );
+        assert_eq!(out, expected);
+    }
+
+    #[test]
+    fn test_more_patterns_and_empty() {
+        let mut r = make_report("/elsewhere/a.go", "f", 1, 7.0);
+        r.patterns = vec!["god_function".to_string()];
+        let scale = SeverityScale::default();
+        let out = render_tree(&[r], Path::new("/repo"), &scale, 3, false);
+        assert!(out.starts_with("/elsewhere/a.go\n"));
+        assert!(out.contains("ns 0  [god_function]\n"));
+        assert!(out.contains("... 3 more\n"));
+        assert!(out.ends_with("1 function in 1 file\n"));
+        assert_eq!(
+            render_tree(&[], Path::new("/repo"), &scale, 0, false),
+            "No functions found.\n"
+        );
+    }
+
+    #[test]
+    fn test_severity_follows_scale_metric() {
+        use crate::severity::{SeverityCutoffs, SeverityMetric};
+
+        // LRS 12 would be critical, but on a CC scale CC 2 is ok
+        let scale = SeverityScale {
+            metric: SeverityMetric::Cc,
+            cutoffs: SeverityCutoffs::defaults_for(SeverityMetric::Cc),
+        };
+        let r = make_report("/repo/a.go", "f", 1, 12.0);
+        let out = render_tree(&[r], Path::new("/repo"), &scale, 0, false);
+        assert!(out.contains("  12.00  ok        f  line 1"));
+    }
+
+    #[test]
+    fn test_color_only_when_enabled() {
+        let r = make_report("/repo/a.go", "f", 1, 12.0);
+        let scale = SeverityScale::default();
+        let plain = render_tree(
+            std::slice::from_ref(&r),
+            Path::new("/repo"),
+            &scale,
+            0,
+            false,
+        );
+        assert!(!plain.contains("\x1b["));
+        let colored = render_tree(&[r], Path::new("/repo"), &scale, 0, true);
+        assert!(colored.contains("\x1b["));
+    }
+}
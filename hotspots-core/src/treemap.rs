@@ -0,0 +1,341 @@
+//! Hierarchical treemap export (`--format treemap`)
+//!
+//! Reports are nested as directories → files → functions so a front-end
+//! (e.g. a D3 treemap) can render them without further processing. Every node
+//! carries a size (SLOC) and a risk color:
+//!
+//! - function: `size` is its LOC, `risk` and `max_risk` are its LRS
+//! - file / directory: `size` is the sum of its children's sizes, `risk` the
+//!   size-weighted mean of their risk, `max_risk` the largest function LRS
+//!
+//! Global invariants enforced:
+//! - Parent size = sum of children sizes
+//! - Deterministic output ordering (directories and files by name, functions
+//!   by line)
+
+use crate::report::FunctionRiskReport;
+use crate::risk::RiskBand;
+use serde::Serialize;
+use std::collections::BTreeMap;
+use std::path::Path;
+
+/// Node kind in the treemap hierarchy
+#[derive(Debug, Clone, Copy, PartialEq, Eq, Serialize)]
+#[serde(rename_all = "lowercase")]
+pub enum TreemapKind {
+    Directory,
+    File,
+    Function,
+}
+
+/// A treemap node. Leaves (functions) have no `children`.
+#[derive(Debug, Clone, Serialize)]
+pub struct TreemapNode {
+    pub name: String,
+    pub kind: TreemapKind,
+    /// Path relative to the analysis base (directories and files only)
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub path: Option<String>,
+    /// Start line (functions only)
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub line: Option<u32>,
+    /// Lines of code; for directories and files, the sum over children
+    pub size: u64,
+    /// LRS; for directories and files, the size-weighted mean over children
+    pub risk: f64,
+    /// Highest function LRS in this subtree
+    pub max_risk: f64,
+    /// Risk band (functions only)
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub band: Option<RiskBand>,
+    #[serde(skip_serializing_if = "Vec::is_empty")]
+    pub children: Vec<TreemapNode>,
+}
+
+/// Directory contents while grouping reports by path
+#[derive(Default)]
+struct DirEntry<'a> {
+    dirs: BTreeMap<String, DirEntry<'a>>,
+    files: BTreeMap<String, Vec<&'a FunctionRiskReport>>,
+}
+
+/// Build the treemap rooted at `base`.
+///
+/// File paths are made relative to `base` when they fall under it; the root
+/// node is named after `base`'s last component.
+pub fn build_treemap(reports: &[FunctionRiskReport], base: &Path) -> TreemapNode {
+    let mut root = DirEntry::default();
+    for report in reports {
+        let rel = relative_path(&report.file, base);
+        let mut parts: Vec<&str> = rel.split('/').filter(|p| !p.is_empty()).collect();
+        let Some(file_name) = parts.pop() else {
+            continue;
+        };
+        let mut dir = &mut root;
+        for part in parts {
+            dir = dir.dirs.entry(part.to_string()).or_default();
+        }
+        dir.files
+            .entry(file_name.to_string())
+            .or_default()
+            .push(report);
+    }
+
+    let root_name = base
+        .file_name()
+        .map(|n| n.to_string_lossy().into_owned())
+        .unwrap_or_else(|| ".".to_string());
+    directory_node(root_name, String::new(), root)
+}
+
+/// Render the treemap as pretty-printed JSON
+pub fn render_treemap(reports: &[FunctionRiskReport], base: &Path) -> String {
+    serde_json::to_string_pretty(&build_treemap(reports, base)).unwrap_or_else(|_| "{}".to_string())
+}
+
+fn directory_node(name: String, path: String, entry: DirEntry<'_>) -> TreemapNode {
+    let join = |child: &str| {
+        if path.is_empty() {
+            child.to_string()
+        } else {
+            format!("{path}/{child}")
+        }
+    };
+
+    let mut children: Vec<TreemapNode> = entry
+        .dirs
+        .into_iter()
+        .map(|(dir_name, dir)| {
+            let dir_path = join(&dir_name);
+            directory_node(dir_name, dir_path, dir)
+        })
+        .collect();
+    children.extend(entry.files.into_iter().map(|(file_name, functions)| {
+        let file_path = join(&file_name);
+        file_node(file_name, file_path, functions)
+    }));
+
+    rollup(name, TreemapKind::Directory, Some(path), children)
+}
+
+fn file_node(name: String, path: String, mut functions: Vec<&FunctionRiskReport>) -> TreemapNode {
+    functions.sort_by(|a, b| {
+        a.line
+            .cmp(&b.line)
+            .then_with(|| a.function.cmp(&b.function))
+    });
+    let children = functions
+        .into_iter()
+        .map(|f| TreemapNode {
+            name: f.function.clone(),
+            kind: TreemapKind::Function,
+            path: None,
+            line: Some(f.line),
+            size: u64::from(f.metrics.loc),
+            risk: f.lrs,
+            max_risk: f.lrs,
+            band: Some(f.band),
+            children: vec![],
+        })
+        .collect();
+    rollup(name, TreemapKind::File, Some(path), children)
+}
+
+/// Aggregate node whose size and risk derive from `children`
+fn rollup(
+    name: String,
+    kind: TreemapKind,
+    path: Option<String>,
+    children: Vec<TreemapNode>,
+) -> TreemapNode {
+    let size: u64 = children.iter().map(|c| c.size).sum();
+    let risk = if size > 0 {
+        children.iter().map(|c| c.risk * c.size as f64).sum::<f64>() / size as f64
+    } else if children.is_empty() {
+        0.0
+    } else {
+        children.iter().map(|c| c.risk).sum::<f64>() / children.len() as f64
+    };
+    let max_risk = children.iter().map(|c| c.max_risk).fold(0.0, f64::max);
+    TreemapNode {
+        name,
+        kind,
+        path,
+        line: None,
+        size,
+        risk,
+        max_risk,
+        band: None,
+        children,
+    }
+}
+
+pub(crate) fn relative_path(file: &str, base: &Path) -> String {
+    Path::new(file)
+        .strip_prefix(base)
+        .map(|p| p.to_string_lossy().replace('\\', "/"))
+        .unwrap_or_else(|_| file.replace('\\', "/"))
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::language::Language;
+    use crate::report::{MetricsReport, RiskReport};
+
+    fn make_report(
+        file: &str,
+        function: &str,
+        line: u32,
+        loc: u32,
+        lrs: f64,
+    ) -> FunctionRiskReport {
+        FunctionRiskReport {
+            file: file.to_string(),
+            function: function.to_string(),
+            owner: None,
+            line,
+            language: Language::TypeScript,
+            metrics: MetricsReport {
+                cc: 1,
+                nd: 0,
+                fo: 0,
+                fi: 0,
+                ns: 0,
+                loc,
+                signature_complexity: 0,
+                params: 0,
+                guard_clauses: 0,
+                max_condition_ops: 0,
+                cognitive: 0,
+                halstead: None,
+                maintainability: None,
+                sloc: None,
+                comment_lines: None,
+                blank_lines: None,
+                nd_line: None,
+                cc_lines: None,
+                is_async: false,
+                await_in_loop: 0,
+                ns_breakdown: None,
+            },
+            risk: RiskReport {
+                r_cc: 0.0,
+                r_nd: 0.0,
+                r_fo: 0.0,
+                r_ns: 0.0,
+            },
+            lrs,
+            band: RiskBand::Low,
+            risk_score: None,
+            suppression_reason: None,
+            patterns: vec![],
+            pattern_details: None,
+            callees: vec![],
+            explanation: None,
+            arrow_depth: 0,
+            aliases: vec![],
+            structure: None,
+            cc_breakdown: None,
+            is_public: false,
+            file_top_level_decisions: 0,
+        }
+    }
+
+    /// repo/
+    ///   src/
+    ///     api/handlers.ts   handle (10 LOC, 6.0), route (30 LOC, 2.0)
+    ///     util.ts           clamp (20 LOC, 1.0)
+    ///   main.ts             main (40 LOC, 4.0)
+    fn fixture() -> Vec<FunctionRiskReport> {
+        vec![
+            make_report("/repo/main.ts", "main", 1, 40, 4.0),
+            make_report("/repo/src/api/handlers.ts", "route", 20, 30, 2.0),
+            make_report("/repo/src/util.ts", "clamp", 3, 20, 1.0),
+            make_report("/repo/src/api/handlers.ts", "handle", 5, 10, 6.0),
+        ]
+    }
+
+    fn child<'a>(node: &'a TreemapNode, name: &str) -> &'a TreemapNode {
+        node.children
+            .iter()
+            .find(|c| c.name == name)
+            .unwrap_or_else(|| panic!("{} has no child {name}", node.name))
+    }
+
+    #[test]
+    fn test_nesting() {
+        let root = build_treemap(&fixture(), Path::new("/repo"));
+        assert_eq!(root.name, "repo");
+        assert_eq!(root.kind, TreemapKind::Directory);
+        let names: Vec<&str> = root.children.iter().map(|c| c.name.as_str()).collect();
+        // Directories before files
+        assert_eq!(names, vec!["src", "main.ts"]);
+
+        let src = child(&root, "src");
+        assert_eq!(src.path.as_deref(), Some("src"));
+        let handlers = child(child(src, "api"), "handlers.ts");
+        assert_eq!(handlers.kind, TreemapKind::File);
+        assert_eq!(handlers.path.as_deref(), Some("src/api/handlers.ts"));
+        let functions: Vec<&str> = handlers.children.iter().map(|c| c.name.as_str()).collect();
+        assert_eq!(functions, vec!["handle", "route"]);
+        assert_eq!(handlers.children[0].kind, TreemapKind::Function);
+        assert_eq!(handlers.children[0].line, Some(5));
+        assert!(handlers.children[0].children.is_empty());
+    }
+
+    #[test]
+    fn test_rollup_arithmetic() {
+        let root = build_treemap(&fixture(), Path::new("/repo"));
+        let src = child(&root, "src");
+        let handlers = child(child(src, "api"), "handlers.ts");
+
+        assert_eq!(handlers.size, 40);
+        // (10 × 6.0 + 30 × 2.0) / 40
+        assert!((handlers.risk - 3.0).abs() < 1e-9);
+        assert_eq!(handlers.max_risk, 6.0);
+
+        assert_eq!(src.size, 60);
+        // (40 × 3.0 + 20 × 1.0) / 60
+        assert!((src.risk - 140.0 / 60.0).abs() < 1e-9);
+        assert_eq!(src.max_risk, 6.0);
+
+        assert_eq!(root.size, 100);
+        // (60 × 140/60 + 40 × 4.0) / 100 = total LOC-weighted LRS
+        assert!((root.risk - 3.0).abs() < 1e-9);
+        assert_eq!(root.max_risk, 6.0);
+
+        fn assert_sums(node: &TreemapNode) {
+            if !node.children.is_empty() {
+                assert_eq!(
+                    node.size,
+                    node.children.iter().map(|c| c.size).sum::<u64>(),
+                    "size of {}",
+                    node.name
+                );
+                node.children.iter().for_each(assert_sums);
+            }
+        }
+        assert_sums(&root);
+    }
+
+    #[test]
+    fn test_json_shape() {
+        let json: serde_json::Value =
+            serde_json::from_str(&render_treemap(&fixture(), Path::new("/repo"))).unwrap();
+        assert_eq!(json["kind"], "directory");
+        let main = &json["children"][1];
+        assert_eq!(main["kind"], "file");
+        assert_eq!(main["children"][0]["kind"], "function");
+        assert_eq!(main["children"][0]["band"], "low");
+        assert!(main["children"][0].get("children").is_none());
+    }
+
+    #[test]
+    fn test_empty() {
+        let root = build_treemap(&[], Path::new("/repo"));
+        assert_eq!(root.size, 0);
+        assert_eq!(root.risk, 0.0);
+        assert!(root.children.is_empty());
+    }
+}
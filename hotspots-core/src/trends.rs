@@ -445,6 +445,7 @@ mod tests {
             .map(|f| FunctionRiskReport {
                 file: f.file.clone(),
                 function: f.function_id.split("::").last().unwrap_or("").to_string(),
+                owner: None,
                 line: f.line,
                 language: f.language,
                 metrics: f.metrics.clone(),
@@ -456,11 +457,18 @@ mod tests {
                 },
                 lrs: f.lrs,
                 band: f.band,
+                risk_score: None,
                 suppression_reason: None,
                 patterns: vec![],
                 pattern_details: None,
                 callees: vec![],
                 explanation: None,
+                arrow_depth: 0,
+                aliases: vec![],
+                structure: None,
+                cc_breakdown: None,
+                is_public: false,
+                file_top_level_decisions: 0,
             })
             .collect();
 
@@ -482,8 +490,24 @@ mod tests {
                         cc: 1,
                         nd: 0,
                         fo: 0,
+                        fi: 0,
                         ns: 0,
                         loc: 10,
+                        signature_complexity: 0,
+                        params: 0,
+                        guard_clauses: 0,
+                        max_condition_ops: 0,
+                        cognitive: 0,
+                        halstead: None,
+                        maintainability: None,
+                        sloc: None,
+                        comment_lines: None,
+                        blank_lines: None,
+                        nd_line: None,
+                        cc_lines: None,
+                        is_async: false,
+                        await_in_loop: 0,
+                        ns_breakdown: None,
                     },
                     lrs: 1.0,
                     band: crate::risk::RiskBand::Low,
@@ -513,6 +537,8 @@ mod tests {
                     age_days: None,
                     last_touch_days: None,
                     explanation: None,
+                    structure: None,
+                    cc_breakdown: None,
                 }],
             ),
             create_test_snapshot(
@@ -527,8 +553,24 @@ mod tests {
                         cc: 2,
                         nd: 1,
                         fo: 0,
+                        fi: 0,
                         ns: 0,
                         loc: 10,
+                        signature_complexity: 0,
+                        params: 0,
+                        guard_clauses: 0,
+                        max_condition_ops: 0,
+                        cognitive: 0,
+                        halstead: None,
+                        maintainability: None,
+                        sloc: None,
+                        comment_lines: None,
+                        blank_lines: None,
+                        nd_line: None,
+                        cc_lines: None,
+                        is_async: false,
+                        await_in_loop: 0,
+                        ns_breakdown: None,
                     },
                     lrs: 3.0,
                     band: crate::risk::RiskBand::Moderate,
@@ -558,6 +600,8 @@ mod tests {
                     age_days: None,
                     last_touch_days: None,
                     explanation: None,
+                    structure: None,
+                    cc_breakdown: None,
                 }],
             ),
         ];
@@ -584,8 +628,24 @@ mod tests {
                         cc: 1,
                         nd: 0,
                         fo: 0,
+                        fi: 0,
                         ns: 0,
                         loc: 10,
+                        signature_complexity: 0,
+                        params: 0,
+                        guard_clauses: 0,
+                        max_condition_ops: 0,
+                        cognitive: 0,
+                        halstead: None,
+                        maintainability: None,
+                        sloc: None,
+                        comment_lines: None,
+                        blank_lines: None,
+                        nd_line: None,
+                        cc_lines: None,
+                        is_async: false,
+                        await_in_loop: 0,
+                        ns_breakdown: None,
                     },
                     lrs: 1.0,
                     band: crate::risk::RiskBand::Low,
@@ -615,6 +675,8 @@ mod tests {
                     age_days: None,
                     last_touch_days: None,
                     explanation: None,
+                    structure: None,
+                    cc_breakdown: None,
                 }],
             ),
             create_test_snapshot(
@@ -629,8 +691,24 @@ mod tests {
                         cc: 1,
                         nd: 0,
                         fo: 0,
+                        fi: 0,
                         ns: 0,
                         loc: 10,
+                        signature_complexity: 0,
+                        params: 0,
+                        guard_clauses: 0,
+                        max_condition_ops: 0,
+                        cognitive: 0,
+                        halstead: None,
+                        maintainability: None,
+                        sloc: None,
+                        comment_lines: None,
+                        blank_lines: None,
+                        nd_line: None,
+                        cc_lines: None,
+                        is_async: false,
+                        await_in_loop: 0,
+                        ns_breakdown: None,
                     },
                     lrs: 1.0,
                     band: crate::risk::RiskBand::Low,
@@ -660,6 +738,8 @@ mod tests {
                     age_days: None,
                     last_touch_days: None,
                     explanation: None,
+                    structure: None,
+                    cc_breakdown: None,
                 }],
             ),
         ];
@@ -685,8 +765,24 @@ mod tests {
                             cc: 10,
                             nd: 5,
                             fo: 3,
+                            fi: 0,
                             ns: 2,
                             loc: 20,
+                            signature_complexity: 0,
+                            params: 0,
+                            guard_clauses: 0,
+                            max_condition_ops: 0,
+                            cognitive: 0,
+                            halstead: None,
+                            maintainability: None,
+                            sloc: None,
+                            comment_lines: None,
+                            blank_lines: None,
+                            nd_line: None,
+                            cc_lines: None,
+                            is_async: false,
+                            await_in_loop: 0,
+                            ns_breakdown: None,
                         },
                         lrs: 15.0,
                         band: crate::risk::RiskBand::High,
@@ -716,6 +812,8 @@ mod tests {
                         age_days: None,
                         last_touch_days: None,
                         explanation: None,
+                        structure: None,
+                        cc_breakdown: None,
                     },
                     FunctionSnapshot {
                         function_id: "src/bar.ts::func2".to_string(),
@@ -726,8 +824,24 @@ mod tests {
                             cc: 5,
                             nd: 2,
                             fo: 1,
+                            fi: 0,
                             ns: 0,
                             loc: 10,
+                            signature_complexity: 0,
+                            params: 0,
+                            guard_clauses: 0,
+                            max_condition_ops: 0,
+                            cognitive: 0,
+                            halstead: None,
+                            maintainability: None,
+                            sloc: None,
+                            comment_lines: None,
+                            blank_lines: None,
+                            nd_line: None,
+                            cc_lines: None,
+                            is_async: false,
+                            await_in_loop: 0,
+                            ns_breakdown: None,
                         },
                         lrs: 5.0,
                         band: crate::risk::RiskBand::Moderate,
@@ -757,6 +871,8 @@ mod tests {
                         age_days: None,
                         last_touch_days: None,
                         explanation: None,
+                        structure: None,
+                        cc_breakdown: None,
                     },
                 ],
             ),
@@ -773,8 +889,24 @@ mod tests {
                             cc: 12,
                             nd: 6,
                             fo: 4,
+                            fi: 0,
                             ns: 2,
                             loc: 25,
+                            signature_complexity: 0,
+                            params: 0,
+                            guard_clauses: 0,
+                            max_condition_ops: 0,
+                            cognitive: 0,
+                            halstead: None,
+                            maintainability: None,
+                            sloc: None,
+                            comment_lines: None,
+                            blank_lines: None,
+                            nd_line: None,
+                            cc_lines: None,
+                            is_async: false,
+                            await_in_loop: 0,
+                            ns_breakdown: None,
                         },
                         lrs: 18.0,
                         band: crate::risk::RiskBand::High,
@@ -804,6 +936,8 @@ mod tests {
                         age_days: None,
                         last_touch_days: None,
                         explanation: None,
+                        structure: None,
+                        cc_breakdown: None,
                     },
                     FunctionSnapshot {
                         function_id: "src/bar.ts::func2".to_string(),
@@ -814,8 +948,24 @@ mod tests {
                             cc: 5,
                             nd: 2,
                             fo: 1,
+                            fi: 0,
                             ns: 0,
                             loc: 10,
+                            signature_complexity: 0,
+                            params: 0,
+                            guard_clauses: 0,
+                            max_condition_ops: 0,
+                            cognitive: 0,
+                            halstead: None,
+                            maintainability: None,
+                            sloc: None,
+                            comment_lines: None,
+                            blank_lines: None,
+                            nd_line: None,
+                            cc_lines: None,
+                            is_async: false,
+                            await_in_loop: 0,
+                            ns_breakdown: None,
                         },
                         lrs: 5.0,
                         band: crate::risk::RiskBand::Moderate,
@@ -845,6 +995,8 @@ mod tests {
                         age_days: None,
                         last_touch_days: None,
                         explanation: None,
+                        structure: None,
+                        cc_breakdown: None,
                     },
                 ],
             ),
@@ -6,7 +6,11 @@
 use hotspots_core::language::Language;
 use hotspots_core::report::{FunctionRiskReport, MetricsReport, RiskReport};
 use hotspots_core::risk::RiskBand;
-use hotspots_core::{delta, git, snapshot};
+use hotspots_core::{delta, git, snapshot, AnalysisOptions};
+use rand::rngs::SmallRng;
+use rand::seq::SliceRandom;
+use rand::SeedableRng;
+use std::path::PathBuf;
 use tempfile::TempDir;
 
 /// Create a test snapshot with given SHA
@@ -27,14 +31,31 @@ fn create_test_snapshot(sha: &str, parent_sha: &str) -> snapshot::Snapshot {
     let report = FunctionRiskReport {
         file: "src/foo.ts".to_string(),
         function: "handler".to_string(),
+        owner: None,
         line: 42,
         language: Language::TypeScript,
         metrics: MetricsReport {
             cc: 5,
             nd: 2,
             fo: 3,
+            fi: 0,
             ns: 1,
             loc: 10,
+            signature_complexity: 0,
+            params: 0,
+            guard_clauses: 0,
+            max_condition_ops: 0,
+            cognitive: 0,
+            halstead: None,
+            maintainability: None,
+            sloc: None,
+            comment_lines: None,
+            blank_lines: None,
+            nd_line: None,
+            cc_lines: None,
+            is_async: false,
+            await_in_loop: 0,
+            ns_breakdown: None,
         },
         risk: RiskReport {
             r_cc: 2.0,
@@ -44,11 +65,18 @@ fn create_test_snapshot(sha: &str, parent_sha: &str) -> snapshot::Snapshot {
         },
         lrs: 4.8,
         band: RiskBand::Moderate,
+        risk_score: None,
         suppression_reason: None,
         patterns: vec![],
         pattern_details: None,
         callees: vec![],
         explanation: None,
+        arrow_depth: 0,
+        aliases: vec![],
+        structure: None,
+        cc_breakdown: None,
+        is_public: false,
+        file_top_level_decisions: 0,
     };
 
     snapshot::Snapshot::new(git_context, vec![report])
@@ -162,14 +190,31 @@ fn test_delta_single_parent_only() {
     let report = FunctionRiskReport {
         file: "src/foo.ts".to_string(),
         function: "handler".to_string(),
+        owner: None,
         line: 42,
         language: Language::TypeScript,
         metrics: MetricsReport {
             cc: 5,
             nd: 2,
             fo: 3,
+            fi: 0,
             ns: 1,
             loc: 10,
+            signature_complexity: 0,
+            params: 0,
+            guard_clauses: 0,
+            max_condition_ops: 0,
+            cognitive: 0,
+            halstead: None,
+            maintainability: None,
+            sloc: None,
+            comment_lines: None,
+            blank_lines: None,
+            nd_line: None,
+            cc_lines: None,
+            is_async: false,
+            await_in_loop: 0,
+            ns_breakdown: None,
         },
         risk: RiskReport {
             r_cc: 2.0,
@@ -179,11 +224,18 @@ fn test_delta_single_parent_only() {
         },
         lrs: 4.8,
         band: RiskBand::Moderate,
+        risk_score: None,
         suppression_reason: None,
         patterns: vec![],
         pattern_details: None,
         callees: vec![],
         explanation: None,
+        arrow_depth: 0,
+        aliases: vec![],
+        structure: None,
+        cc_breakdown: None,
+        is_public: false,
+        file_top_level_decisions: 0,
     };
 
     let merge_snapshot = snapshot::Snapshot::new(git_context, vec![report]);
@@ -245,14 +297,31 @@ fn test_delta_negative_deltas_allowed() {
     let report = FunctionRiskReport {
         file: "src/foo.ts".to_string(),
         function: "handler".to_string(),
+        owner: None,
         line: 42,
         language: Language::TypeScript,
         metrics: MetricsReport {
             cc: 3,
             nd: 1,
             fo: 1,
+            fi: 0,
             ns: 0,
             loc: 10,
+            signature_complexity: 0,
+            params: 0,
+            guard_clauses: 0,
+            max_condition_ops: 0,
+            cognitive: 0,
+            halstead: None,
+            maintainability: None,
+            sloc: None,
+            comment_lines: None,
+            blank_lines: None,
+            nd_line: None,
+            cc_lines: None,
+            is_async: false,
+            await_in_loop: 0,
+            ns_breakdown: None,
         }, // Lower than parent
         risk: RiskReport {
             r_cc: 2.0,
@@ -262,11 +331,18 @@ fn test_delta_negative_deltas_allowed() {
         },
         lrs: 2.5, // Lower than parent
         band: RiskBand::Low,
+        risk_score: None,
         suppression_reason: None,
         patterns: vec![],
         pattern_details: None,
         callees: vec![],
         explanation: None,
+        arrow_depth: 0,
+        aliases: vec![],
+        structure: None,
+        cc_breakdown: None,
+        is_public: false,
+        file_top_level_decisions: 0,
     };
 
     let current = snapshot::Snapshot::new(git_context, vec![report]);
@@ -324,3 +400,115 @@ fn test_delta_deleted_functions_explicit() {
         "deleted function must have no 'after' state"
     );
 }
+
+/// Every supported source file under tests/fixtures, in sorted order.
+fn fixture_files() -> Vec<PathBuf> {
+    let root = PathBuf::from(env!("CARGO_MANIFEST_DIR"))
+        .parent()
+        .unwrap()
+        .join("tests")
+        .join("fixtures");
+    let mut files: Vec<PathBuf> = walkdir::WalkDir::new(root)
+        .into_iter()
+        .filter_map(Result::ok)
+        .map(|e| e.into_path())
+        .filter(|p| {
+            p.is_file()
+                && Language::from_path(p).is_some()
+                && !p.to_string_lossy().ends_with(".d.ts")
+        })
+        .collect();
+    files.sort();
+    files
+}
+
+/// Reports and the snapshot built from them, serialized, for one file order.
+fn analyze_in_order(files: &[PathBuf], top_n: Option<usize>) -> (String, String) {
+    let reports = hotspots_core::analyze_files(
+        files,
+        AnalysisOptions {
+            min_lrs: None,
+            top_n,
+        },
+        None,
+        None,
+    )
+    .expect("analysis should succeed");
+    let json = hotspots_core::render_json(&reports);
+
+    let git_context = git::GitContext {
+        head_sha: "abc123".to_string(),
+        parent_shas: vec!["def456".to_string()],
+        timestamp: 1705600000,
+        branch: Some("main".to_string()),
+        is_detached: false,
+        message: None,
+        author: None,
+        is_fix_commit: None,
+        is_revert_commit: None,
+        ticket_ids: vec![],
+    };
+    let mut snapshot = snapshot::Snapshot::new(git_context, reports);
+    snapshot.compute_summary(false);
+    (json, snapshot.to_json().expect("snapshot should serialize"))
+}
+
+#[test]
+fn test_analysis_independent_of_file_order() {
+    let files = fixture_files();
+    assert!(files.len() > 20, "expected the full fixture corpus");
+
+    for top_n in [None, Some(15)] {
+        let (baseline_reports, baseline_snapshot) = analyze_in_order(&files, top_n);
+
+        let mut reversed = files.clone();
+        reversed.reverse();
+        let mut orders = vec![reversed];
+        for seed in 0..6u64 {
+            let mut shuffled = files.clone();
+            shuffled.shuffle(&mut SmallRng::seed_from_u64(seed));
+            orders.push(shuffled);
+        }
+
+        for (i, order) in orders.iter().enumerate() {
+            let (reports, snapshot) = analyze_in_order(order, top_n);
+            assert!(
+                reports == baseline_reports,
+                "report differs for permutation {i} (top_n {top_n:?})"
+            );
+            assert!(
+                snapshot == baseline_snapshot,
+                "snapshot differs for permutation {i} (top_n {top_n:?})"
+            );
+        }
+    }
+}
+
+/// Files are analyzed in parallel (`--jobs`); a single worker must produce
+/// the same reports and snapshot as many
+#[test]
+fn test_parallel_analysis_matches_serial() {
+    let files = fixture_files();
+    let in_pool = |threads: usize, top_n: Option<usize>| {
+        rayon::ThreadPoolBuilder::new()
+            .num_threads(threads)
+            .build()
+            .expect("thread pool should build")
+            .install(|| analyze_in_order(&files, top_n))
+    };
+
+    for top_n in [None, Some(15)] {
+        let (serial_reports, serial_snapshot) = in_pool(1, top_n);
+        for threads in [2, 8] {
+            let (reports, snapshot) = in_pool(threads, top_n);
+            assert!(
+                reports == serial_reports,
+                "report differs with {threads} threads (top_n {top_n:?})"
+            );
+            assert!(
+                snapshot == serial_snapshot,
+                "snapshot differs with {threads} threads (top_n {top_n:?})"
+            );
+        }
+    }
+}
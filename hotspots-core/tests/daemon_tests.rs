@@ -0,0 +1,130 @@
+//! Daemon integration tests - a real daemon on a unix socket in a temp directory
+#![cfg(unix)]
+
+use hotspots_core::daemon::{self, Daemon, Request};
+use hotspots_core::{analyze_with_config, config, render_json, AnalysisOptions};
+use std::path::{Path, PathBuf};
+use std::time::{Duration, Instant};
+
+fn fixtures_dir() -> PathBuf {
+    PathBuf::from(env!("CARGO_MANIFEST_DIR"))
+        .parent()
+        .unwrap()
+        .join("tests")
+        .join("fixtures")
+}
+
+fn analyze_request(path: &Path) -> Request {
+    Request::AnalyzePath {
+        path: path.to_path_buf(),
+        root: None,
+        config: None,
+        min_lrs: None,
+        top_n: None,
+        dedup_symlinks: false,
+        public_only: false,
+        include_tests: false,
+        no_gitignore: false,
+        halstead: false,
+        line_counts: false,
+        nd_lines: false,
+        cc_lines: false,
+        separate_closures: false,
+        ns_breakdown: false,
+        fan_in: false,
+        sql_dialect: None,
+        cc_mode: None,
+        no_fo_methods: false,
+        anon_naming: None,
+        include: Vec::new(),
+        exclude: Vec::new(),
+    }
+}
+
+fn timed(socket: &Path, request: &Request) -> (daemon::Response, Duration) {
+    let start = Instant::now();
+    let response = daemon::request(socket, request).expect("daemon request failed");
+    (response, start.elapsed())
+}
+
+#[test]
+fn test_daemon_second_request_served_warm() {
+    let dir = tempfile::tempdir().unwrap();
+    let socket = dir.path().join("hotspots.sock");
+    let listener = daemon::bind(&socket).unwrap();
+    let server = std::thread::spawn(move || Daemon::new().serve(&listener));
+
+    let fixtures = fixtures_dir();
+    let request = analyze_request(&fixtures);
+    let (first, cold) = timed(&socket, &request);
+    let (second, warm) = timed(&socket, &request);
+
+    let first_stats = first.stats.unwrap();
+    let second_stats = second.stats.unwrap();
+    assert!(first_stats.files > 0);
+    assert_eq!(first_stats.cache_hits, 0);
+    assert_eq!(second_stats.cache_hits, second_stats.files);
+    assert!(
+        warm < cold,
+        "warm request ({warm:?}) should beat the cold one ({cold:?})"
+    );
+
+    // Served results match in-process analysis, cold or warm
+    let resolved = config::load_and_resolve(&fixtures, None).unwrap();
+    let expected = analyze_with_config(
+        &fixtures,
+        AnalysisOptions {
+            min_lrs: None,
+            top_n: None,
+        },
+        Some(&resolved),
+    )
+    .unwrap();
+    assert_eq!(render_json(&first.reports.unwrap()), render_json(&expected));
+    assert_eq!(
+        render_json(&second.reports.unwrap()),
+        render_json(&expected)
+    );
+
+    daemon::request(&socket, &Request::Shutdown).unwrap();
+    server.join().unwrap().unwrap();
+}
+
+#[test]
+fn test_daemon_reanalyzes_modified_file() {
+    let dir = tempfile::tempdir().unwrap();
+    let src = dir.path().join("src");
+    std::fs::create_dir(&src).unwrap();
+    let file = src.join("a.ts");
+    std::fs::write(&file, "function a() { return 1; }\n").unwrap();
+
+    let socket = dir.path().join("hotspots.sock");
+    let listener = daemon::bind(&socket).unwrap();
+    let server = std::thread::spawn(move || Daemon::new().serve(&listener));
+
+    let request = analyze_request(&src);
+    let first = daemon::request(&socket, &request).unwrap();
+    assert_eq!(first.reports.unwrap()[0].metrics.cc, 1);
+
+    // Different size, so the stamp changes even within mtime granularity
+    std::fs::write(
+        &file,
+        "function a(x: number) { if (x > 0) { return 1; } return 0; }\n",
+    )
+    .unwrap();
+    let second = daemon::request(&socket, &request).unwrap();
+    assert_eq!(second.stats.unwrap().cache_hits, 0);
+    assert_eq!(second.reports.unwrap()[0].metrics.cc, 2);
+
+    daemon::request(&socket, &Request::Shutdown).unwrap();
+    server.join().unwrap().unwrap();
+}
+
+#[test]
+fn test_bind_refuses_live_socket() {
+    let dir = tempfile::tempdir().unwrap();
+    let socket = dir.path().join("hotspots.sock");
+    let _listener = daemon::bind(&socket).unwrap();
+    let err = daemon::bind(&socket).unwrap_err();
+    assert!(err.to_string().contains("already listening"));
+}
@@ -1,14 +1,15 @@
 //! Integration tests for the diff pipeline.
 //!
 //! Covers Delta computation with two persisted snapshots, filtering,
-//! --top sort order, JSONL serialization, and aggregate attachment.
+//! --top sort order, JSONL serialization, and aggregate attachment, plus the
+//! report-level `--diff-against` diff.
 
-use hotspots_core::delta::{Delta, FunctionStatus};
+use hotspots_core::delta::{parse_previous_results, Delta, FunctionStatus, ReportDiff};
 use hotspots_core::git::GitContext;
 use hotspots_core::language::Language;
 use hotspots_core::report::{FunctionRiskReport, MetricsReport, RiskReport};
 use hotspots_core::risk::RiskBand;
-use hotspots_core::snapshot::{self, Snapshot};
+use hotspots_core::snapshot::{self, FunctionSnapshot, Snapshot};
 use tempfile::TempDir;
 
 // ---------------------------------------------------------------------------
@@ -34,14 +35,31 @@ fn make_report(file: &str, func: &str, cc: u32, lrs: f64, band: &str) -> Functio
     FunctionRiskReport {
         file: file.to_string(),
         function: func.to_string(),
+        owner: None,
         line: 1,
         language: Language::TypeScript,
         metrics: MetricsReport {
             cc,
             nd: 1,
             fo: 1,
+            fi: 0,
             ns: 1,
             loc: 20,
+            signature_complexity: 0,
+            params: 0,
+            guard_clauses: 0,
+            max_condition_ops: 0,
+            cognitive: 0,
+            halstead: None,
+            maintainability: None,
+            sloc: None,
+            comment_lines: None,
+            blank_lines: None,
+            nd_line: None,
+            cc_lines: None,
+            is_async: false,
+            await_in_loop: 0,
+            ns_breakdown: None,
         },
         risk: RiskReport {
             r_cc: 1.0,
@@ -51,11 +69,18 @@ fn make_report(file: &str, func: &str, cc: u32, lrs: f64, band: &str) -> Functio
         },
         lrs,
         band: RiskBand::parse(band).unwrap_or(RiskBand::Low),
+        risk_score: None,
         suppression_reason: None,
         patterns: vec![],
         pattern_details: None,
         callees: vec![],
         explanation: None,
+        arrow_depth: 0,
+        aliases: vec![],
+        structure: None,
+        cc_breakdown: None,
+        is_public: false,
+        file_top_level_decisions: 0,
     }
 }
 
@@ -257,7 +282,7 @@ fn test_diff_to_jsonl() {
         .deltas
         .retain(|e| e.status != FunctionStatus::Unchanged);
 
-    let jsonl = delta.to_jsonl().expect("to_jsonl failed");
+    let jsonl = delta.to_jsonl("1.2.3").expect("to_jsonl failed");
     let lines: Vec<&str> = jsonl.lines().collect();
 
     assert_eq!(
@@ -277,6 +302,8 @@ fn test_diff_to_jsonl() {
             v.get("status").is_some(),
             "each JSONL entry should have status"
         );
+        assert_eq!(v["schema_version"], delta.schema_version);
+        assert_eq!(v["tool_version"], "1.2.3");
     }
 }
 
@@ -316,5 +343,204 @@ fn test_diff_delta_aggregates_attached() {
         1,
         "one file should appear in file-level aggregates"
     );
-    assert_eq!(agg.files[0].file, "src/i.ts");
+    // Loaded snapshots anchor their portable paths at the repo root
+    assert_eq!(
+        agg.files[0].file,
+        format!("{}/src/i.ts", tmp.path().display())
+    );
+}
+
+#[test]
+fn test_diff_matches_backslash_path_baseline() {
+    // A baseline written with Windows separators must line up with a
+    // forward-slash run: same functions compare as modified/unchanged, not as
+    // deleted + new.
+    let tmp = TempDir::new().unwrap();
+    init_repo(tmp.path());
+    let file = |rel: &str| format!("{}/{rel}", tmp.path().display());
+
+    let base = Snapshot::new(
+        git_ctx("base007", "root007"),
+        vec![
+            make_report("src/api/billing.ts", "charge", 5, 3.0, "low"),
+            make_report("src/api/billing.ts", "refund", 4, 2.0, "low"),
+        ],
+    );
+    let windows_json = base
+        .to_json()
+        .unwrap()
+        .replace("src/api/billing.ts", "src\\\\api\\\\billing.ts");
+    assert!(windows_json.contains(r#""file": "src\\api\\billing.ts""#));
+    let legacy_path = snapshot::snapshots_dir(tmp.path()).join("base007.json");
+    snapshot::atomic_write(&legacy_path, &windows_json).unwrap();
+
+    let loaded = snapshot::load_snapshot(tmp.path(), "base007")
+        .expect("load failed")
+        .expect("snapshot not found");
+    assert_eq!(loaded.functions[0].file, file("src/api/billing.ts"));
+    assert_eq!(
+        loaded.functions[0].function_id,
+        format!("{}::charge", file("src/api/billing.ts"))
+    );
+
+    let head = Snapshot::new(
+        git_ctx("head007", "base007"),
+        vec![
+            make_report(&file("src/api/billing.ts"), "charge", 9, 6.0, "moderate"),
+            make_report(&file("src/api/billing.ts"), "refund", 4, 2.0, "low"),
+        ],
+    );
+    let delta = hotspots_core::delta::compute_delta(tmp.path(), &head).expect("delta failed");
+    assert!(!delta.baseline);
+    let statuses: Vec<(&str, &FunctionStatus)> = delta
+        .deltas
+        .iter()
+        .map(|e| (e.function_id.rsplit("::").next().unwrap(), &e.status))
+        .collect();
+    assert_eq!(
+        statuses,
+        vec![
+            ("charge", &FunctionStatus::Modified),
+            ("refund", &FunctionStatus::Unchanged)
+        ]
+    );
+
+    // Re-persisting writes the normalized, repo-relative form
+    snapshot::persist_snapshot(tmp.path(), &loaded, true).unwrap();
+    let rewritten = snapshot::load_snapshot(tmp.path(), "base007")
+        .unwrap()
+        .unwrap();
+    assert_eq!(rewritten.functions, loaded.functions);
+    let compressed = std::fs::read(snapshot::snapshot_path(tmp.path(), "base007")).unwrap();
+    let stored = String::from_utf8(zstd::decode_all(compressed.as_slice()).unwrap()).unwrap();
+    assert!(stored.contains(r#""function_id": "src/api/billing.ts::charge""#));
+}
+
+// ---------------------------------------------------------------------------
+// --diff-against (report diff)
+// ---------------------------------------------------------------------------
+
+fn to_functions(reports: Vec<FunctionRiskReport>) -> Vec<FunctionSnapshot> {
+    reports.into_iter().map(FunctionSnapshot::from).collect()
+}
+
+#[test]
+fn test_report_diff_additions_removals_and_changes() {
+    let previous = to_functions(vec![
+        make_report("src/a.ts", "kept", 3, 2.0, "low"),
+        make_report("src/a.ts", "grown", 4, 2.5, "low"),
+        make_report("src/b.ts", "gone", 2, 1.5, "low"),
+    ]);
+    let current = to_functions(vec![
+        make_report("src/a.ts", "kept", 3, 2.0, "low"),
+        make_report("src/a.ts", "grown", 11, 7.0, "high"),
+        make_report("src/c.ts", "fresh", 6, 4.0, "moderate"),
+    ]);
+
+    let diff = ReportDiff::new(&previous, &current);
+
+    assert_eq!(diff.unchanged_count, 1);
+
+    assert_eq!(diff.added.len(), 1);
+    assert_eq!(diff.added[0].function_id, "src/c.ts::fresh");
+    assert!(diff.added[0].before.is_none());
+    assert_eq!(diff.added[0].after.as_ref().unwrap().metrics.cc, 6);
+
+    assert_eq!(diff.removed.len(), 1);
+    assert_eq!(diff.removed[0].function_id, "src/b.ts::gone");
+    assert!(diff.removed[0].after.is_none());
+
+    assert_eq!(diff.changed.len(), 1);
+    let changed = &diff.changed[0];
+    assert_eq!(changed.function_id, "src/a.ts::grown");
+    assert_eq!(changed.before.as_ref().unwrap().metrics.cc, 4);
+    assert_eq!(changed.after.as_ref().unwrap().metrics.cc, 11);
+    assert_eq!(changed.delta.as_ref().unwrap().cc, 7);
+    let bt = changed.band_transition.as_ref().unwrap();
+    assert_eq!(bt.from, "low");
+    assert_eq!(bt.to, "high");
+}
+
+#[test]
+fn test_report_diff_identical_is_empty() {
+    let reports = vec![make_report("src/a.ts", "f", 3, 2.0, "low")];
+    let diff = ReportDiff::new(&to_functions(reports.clone()), &to_functions(reports));
+    assert!(diff.is_empty());
+    assert_eq!(diff.unchanged_count, 1);
+
+    let json: serde_json::Value = serde_json::from_str(&diff.to_json("1.2.3").unwrap()).unwrap();
+    assert_eq!(json["added"].as_array().unwrap().len(), 0);
+    assert_eq!(json["unchanged_count"], 1);
+}
+
+#[test]
+fn test_parse_previous_results_accepts_report_array_and_snapshot() {
+    let reports = vec![
+        make_report("src/a.ts", "f", 3, 2.0, "low"),
+        make_report("src/a.ts", "g", 5, 3.0, "moderate"),
+    ];
+
+    // Flat array from `--save-baseline`
+    let from_array =
+        parse_previous_results(&hotspots_core::render_json(&reports)).expect("array parses");
+    assert_eq!(from_array.len(), 2);
+    assert_eq!(from_array[0].function_id, "src/a.ts::f");
+
+    // Versioned document from `hotspots analyze --format json`
+    let from_document =
+        parse_previous_results(&hotspots_core::render_json_report(&reports, "1.2.3"))
+            .expect("report document parses");
+    assert!(ReportDiff::new(&from_array, &from_document).is_empty());
+
+    // Full snapshot JSON
+    let snap = Snapshot::new(git_ctx("prev000", "root000"), reports);
+    let from_snapshot = parse_previous_results(&snap.to_json().unwrap()).expect("snapshot parses");
+    assert_eq!(from_snapshot.len(), 2);
+
+    assert!(ReportDiff::new(&from_array, &from_snapshot).is_empty());
+}
+
+#[test]
+fn test_parse_previous_results_rejects_garbage() {
+    assert!(parse_previous_results("not json").is_err());
+    assert!(parse_previous_results(r#"{"unexpected": true}"#).is_err());
+}
+
+#[test]
+fn test_report_diff_regressions_against_baseline() {
+    let mut shifted = make_report("src/a.ts", "shifted", 8, 5.0, "moderate");
+    let previous = to_functions(vec![
+        shifted.clone(),
+        make_report("src/a.ts", "grown", 4, 2.5, "low"),
+        make_report("src/a.ts", "nudged", 6, 4.0, "moderate"),
+        make_report("src/b.ts", "gone", 20, 9.0, "critical"),
+    ]);
+    // Code moved down the file: same function id and metrics, new line
+    shifted.line = 40;
+    let current = to_functions(vec![
+        shifted,
+        make_report("src/a.ts", "grown", 11, 7.0, "high"),
+        make_report("src/a.ts", "nudged", 7, 4.5, "moderate"),
+        make_report("src/c.ts", "risky", 12, 8.0, "high"),
+        make_report("src/c.ts", "tame", 2, 1.0, "low"),
+    ]);
+
+    let diff = ReportDiff::new(&previous, &current);
+    let ids: Vec<&str> = diff
+        .regressions()
+        .iter()
+        .map(|e| e.function_id.as_str())
+        .collect();
+    // New functions count only in the high/critical band; removed ones never
+    assert_eq!(ids, vec!["src/c.ts::risky", "src/a.ts::grown"]);
+
+    let json: serde_json::Value = serde_json::from_str(
+        &hotspots_core::delta::render_regressions_json(&diff.regressions()).unwrap(),
+    )
+    .unwrap();
+    assert_eq!(json[0]["function_id"], "src/c.ts::risky");
+    assert_eq!(json[0]["status"], "new");
+
+    let unchanged = ReportDiff::new(&current, &current);
+    assert!(unchanged.regressions().is_empty());
 }
@@ -10,7 +10,7 @@
 //! - Assert relationships only
 //! - Fail loudly on invariant violation
 
-use hotspots_core::{analyze, delta, git, snapshot, AnalysisOptions};
+use hotspots_core::{analyze, churn, delta, git, snapshot, AnalysisOptions};
 use std::fs;
 use std::path::{Path, PathBuf};
 use std::process::Command;
@@ -421,3 +421,212 @@ fn test_force_push_does_not_corrupt_history() {
         "snapshot1 content must be unchanged after reset (immutability)"
     );
 }
+
+#[test]
+fn test_shallow_clone_warns_and_keeps_complexity() {
+    let origin = create_temp_git_repo();
+    let origin_path = origin.path();
+    for i in 0..3 {
+        create_ts_file(
+            origin_path,
+            "src/app.ts",
+            &format!(
+                "export function pick(a: number, b: number): number {{\n  if (a > b) {{\n    return a + {i};\n  }}\n  return b;\n}}\n"
+            ),
+        );
+        git_commit(origin_path, &format!("commit {i}"));
+    }
+
+    let full = git::history_depth(origin_path).expect("history depth should be readable");
+    assert!(!full.shallow);
+    assert_eq!(full.commits, 3);
+    assert!(full.warning().is_none(), "full history must not warn");
+
+    let clone_dir = tempfile::tempdir().expect("failed to create temp directory");
+    let clone_path = clone_dir.path().join("shallow");
+    let origin_url = format!("file://{}", origin_path.display());
+    git_command(
+        clone_dir.path(),
+        &["clone", "--depth", "1", &origin_url, "shallow"],
+    );
+
+    let depth = git::history_depth(&clone_path).expect("history depth should be readable");
+    assert!(depth.shallow, "depth-1 clone must be detected as shallow");
+    assert_eq!(depth.commits, 1);
+    let warning = depth.warning().expect("shallow clone must warn");
+    assert!(warning.contains("git fetch --unshallow"), "{warning}");
+    assert!(warning.contains("churn"), "{warning}");
+
+    // Complexity-only results are still produced from the shallow clone
+    let reports = analyze(
+        &clone_path.join("src"),
+        AnalysisOptions {
+            min_lrs: None,
+            top_n: None,
+        },
+    )
+    .expect("analysis should succeed on a shallow clone");
+    assert_eq!(reports.len(), 1);
+    assert_eq!(reports[0].function, "pick");
+    assert_eq!(reports[0].metrics.cc, 2);
+    let context = git::extract_git_context_at(&clone_path).expect("git context should resolve");
+    let snapshot = snapshot::Snapshot::new(context, reports);
+    assert_eq!(snapshot.functions.len(), 1);
+}
+
+#[test]
+fn test_regressions_only_clean_and_regressed() {
+    let temp_repo = create_temp_git_repo();
+    let repo_path = temp_repo.path();
+
+    create_ts_file(repo_path, "simple.ts", "function simple() { return 1; }");
+    git_commit(repo_path, "Initial commit");
+    let snapshot1 = create_snapshot_for_commit(repo_path);
+    snapshot::persist_snapshot(repo_path, &snapshot1, false).expect("failed to persist snapshot1");
+
+    // Clean: a commit that leaves the function untouched reports nothing
+    create_ts_file(repo_path, "README.md", "docs\n");
+    git_commit(repo_path, "Docs only");
+    let snapshot2 = create_snapshot_for_commit(repo_path);
+    snapshot::persist_snapshot(repo_path, &snapshot2, false).expect("failed to persist snapshot2");
+    let clean = delta::compute_delta(repo_path, &snapshot2).expect("failed to compute delta");
+    assert!(clean.regressions().is_empty());
+    assert_eq!(delta::render_regressions_text(&clean.regressions()), "");
+
+    // Regressed: deep nesting and branching push LRS well past the threshold
+    create_ts_file(
+        repo_path,
+        "simple.ts",
+        "function simple(a: number, b: number) { if (a > 0) { if (b > 0) { if (a > b) { \
+         for (let i = 0; i < a; i++) { if (i % 2 === 0 && b > 1) { return i; } } } } } \
+         return a > 1 ? 2 : 1; }",
+    );
+    git_commit(repo_path, "Increase complexity");
+    let snapshot3 = create_snapshot_for_commit(repo_path);
+    let regressed = delta::compute_delta(repo_path, &snapshot3).expect("failed to compute delta");
+    let regressions = regressed.regressions();
+    assert_eq!(regressions.len(), 1, "delta: {:?}", regressed);
+    let text = delta::render_regressions_text(&regressions);
+    assert!(
+        text.starts_with("**Hotspots: 1 regressed function**\n\n- `"),
+        "{text}"
+    );
+    assert!(text.contains("simple.ts::simple"), "{text}");
+}
+
+#[test]
+fn test_churn_attributes_hunks_by_line_range_across_renames() {
+    let temp_repo = create_temp_git_repo();
+    let repo_path = temp_repo.path();
+
+    let alpha = "function alpha(x: number) {\n  return x + 1;\n}\n\n";
+    let beta = |body: &str| {
+        format!("function beta(x: number) {{\n  if (x > 0) {{\n    {body}\n  }}\n  return 0;\n}}\n")
+    };
+    create_ts_file(repo_path, "a.ts", &format!("{alpha}{}", beta("return x;")));
+    git_commit(repo_path, "Add alpha and beta");
+    create_ts_file(
+        repo_path,
+        "a.ts",
+        &format!("{alpha}{}", beta("return x * 2;")),
+    );
+    git_commit(repo_path, "Change beta");
+    create_ts_file(
+        repo_path,
+        "a.ts",
+        &format!("{alpha}{}", beta("return x * 3;")),
+    );
+    git_commit(repo_path, "Change beta again");
+
+    // Rename, and shift every line down without touching either function
+    git_command(repo_path, &["mv", "a.ts", "b.ts"]);
+    create_ts_file(
+        repo_path,
+        "b.ts",
+        &format!("// Math helpers\n\n{alpha}{}", beta("return x * 3;")),
+    );
+    git_commit(repo_path, "Rename and add header");
+
+    let reports = analyze(
+        &repo_path.join("b.ts"),
+        AnalysisOptions {
+            min_lrs: None,
+            top_n: None,
+        },
+    )
+    .expect("failed to analyze");
+    let report = churn::compute_churn_report(Some(repo_path), &reports, 90, churn::ChurnMetric::Cc);
+    assert!(report.git);
+    let scores: Vec<(&str, usize, u64)> = report
+        .functions
+        .iter()
+        .map(|f| (f.function.as_str(), f.churn, f.hotspot_score))
+        .collect();
+    // beta: added, then changed twice (CC 2); alpha: only added (CC 1)
+    assert_eq!(scores, vec![("beta", 3, 6), ("alpha", 1, 1)]);
+}
+
+#[test]
+fn test_changed_files_limit_analysis_to_the_diff() {
+    let temp_repo = create_temp_git_repo();
+    let repo_path = temp_repo.path();
+
+    create_ts_file(
+        repo_path,
+        "src/edited.ts",
+        "function edited() { return 1; }",
+    );
+    create_ts_file(
+        repo_path,
+        "src/old_name.ts",
+        "function moved() { return 2; }",
+    );
+    create_ts_file(
+        repo_path,
+        "src/deleted.ts",
+        "function deleted() { return 3; }",
+    );
+    create_ts_file(
+        repo_path,
+        "src/untouched.ts",
+        "function untouched() { return 4; }",
+    );
+    git_commit(repo_path, "Initial commit");
+
+    create_ts_file(
+        repo_path,
+        "src/edited.ts",
+        "function edited(a: number) { if (a > 0) { return a; } return 1; }",
+    );
+    git_command(repo_path, &["mv", "src/old_name.ts", "src/new_name.ts"]);
+    git_command(repo_path, &["rm", "-q", "src/deleted.ts"]);
+
+    // Deleted files are skipped, renamed files follow their new path
+    let mut changed = git::changed_files_at(repo_path, "HEAD").expect("failed to diff");
+    changed.sort();
+    assert_eq!(
+        changed,
+        vec![
+            repo_path.join("src/edited.ts"),
+            repo_path.join("src/new_name.ts"),
+        ]
+    );
+
+    // With the list in place of the directory walk, only those files are analyzed
+    let mut resolved = hotspots_core::config::HotspotsConfig::default()
+        .resolve()
+        .expect("failed to resolve config");
+    resolved.files = Some(changed);
+    let reports = hotspots_core::analyze_with_config(
+        repo_path,
+        AnalysisOptions {
+            min_lrs: None,
+            top_n: None,
+        },
+        Some(&resolved),
+    )
+    .expect("failed to analyze");
+    let mut functions: Vec<&str> = reports.iter().map(|r| r.function.as_str()).collect();
+    functions.sort();
+    assert_eq!(functions, vec!["edited", "moved"]);
+}
@@ -1,6 +1,7 @@
 //! Golden file tests - verify output matches expected snapshots
 
-use hotspots_core::{analyze, render_json, AnalysisOptions};
+use hotspots_core::config::HotspotsConfig;
+use hotspots_core::{analyze, analyze_with_config, render_json, AnalysisOptions, ResolvedConfig};
 use std::fs;
 use std::path::PathBuf;
 
@@ -236,6 +237,159 @@ fn test_go_golden_specific() {
     test_go_golden("go_specific");
 }
 
+#[test]
+fn test_go_golden_methods() {
+    test_go_golden("methods");
+}
+
+/// Methods are named and owned by their receiver type, so same-named methods
+/// of different types (`Calculator.Add`, `Container.Add`) stay apart
+#[test]
+fn test_go_golden_method_owners() {
+    let reports = analyze(
+        &fixture_path("go/methods.go"),
+        AnalysisOptions {
+            min_lrs: None,
+            top_n: None,
+        },
+    )
+    .unwrap();
+    let mut owned: Vec<(&str, Option<&str>)> = reports
+        .iter()
+        .map(|r| (r.function.as_str(), r.owner.as_deref()))
+        .collect();
+    owned.sort();
+    assert_eq!(
+        owned,
+        [
+            ("Calculator.Add", Some("Calculator")),
+            ("Calculator.GetValue", Some("Calculator")),
+            ("Calculator.IsPositive", Some("Calculator")),
+            ("Calculator.Process", Some("Calculator")),
+            ("Calculator.SetValue", Some("Calculator")),
+            ("Container.Add", Some("Container")),
+            ("Container.Get", Some("Container")),
+            ("SimpleWorker.Stop", Some("SimpleWorker")),
+            ("SimpleWorker.Work", Some("SimpleWorker")),
+        ]
+    );
+}
+
+/// `nd_counts` without `switch`: the same fixture as `go-switch.json`, but every
+/// function drops to ND 0 (and LRS by 0.8 per level)
+#[test]
+fn test_go_golden_switch_not_counted_toward_nd() {
+    let fixture = fixture_path("go/switch.go");
+    let golden = golden_path("go-switch-nd-without-switch.json");
+    let project_root = project_root();
+
+    let config: HotspotsConfig =
+        serde_json::from_str(r#"{"nd_counts": {"go": ["if", "for", "while", "try", "match"]}}"#)
+            .unwrap();
+    let resolved = config.resolve().unwrap();
+    let options = AnalysisOptions {
+        min_lrs: None,
+        top_n: None,
+    };
+
+    let reports = analyze_with_config(&fixture, options, Some(&resolved))
+        .unwrap_or_else(|e| panic!("Failed to analyze {}: {}", fixture.display(), e));
+    let default_reports = analyze(
+        &fixture,
+        AnalysisOptions {
+            min_lrs: None,
+            top_n: None,
+        },
+    )
+    .unwrap();
+    let nd = |reports: &[hotspots_core::FunctionRiskReport], name: &str| {
+        reports
+            .iter()
+            .find(|r| r.function == name)
+            .unwrap()
+            .metrics
+            .nd
+    };
+    assert_eq!(nd(&default_reports, "NestedSwitch"), 2);
+    assert_eq!(nd(&reports, "NestedSwitch"), 0);
+
+    let output = render_json(&reports);
+    let expected = read_golden("go-switch-nd-without-switch.json");
+
+    let mut output_json: serde_json::Value =
+        serde_json::from_str(&output).unwrap_or_else(|e| panic!("Output is not valid JSON: {}", e));
+    let mut expected_json: serde_json::Value = serde_json::from_str(&expected)
+        .unwrap_or_else(|e| panic!("Golden file {} is not valid JSON: {}", golden.display(), e));
+
+    normalize_paths(&mut output_json, &project_root);
+    normalize_paths(&mut expected_json, &project_root);
+
+    assert_eq!(
+        output_json, expected_json,
+        "Output does not match golden file for switch without switch ND"
+    );
+}
+
+/// `cc_mode` over `go/switch.go`: CC per function under `cases`, `statement`,
+/// and `mccabe`. Every function is 3 apart from its switches (see
+/// `go-switch.json`); each switch then adds one per clause, one in all, or
+/// one per clause plus the path past a switch without `default`, minus one.
+#[test]
+fn test_go_golden_switch_cc_modes() {
+    let fixture = fixture_path("go/switch.go");
+    let expected: &[(&str, [usize; 3])] = &[
+        ("SimpleSwitch", [6, 4, 5]),
+        ("SwitchNoDefault", [5, 4, 5]),
+        ("SwitchWithFallthrough", [6, 4, 5]),
+        ("NestedSwitch", [7, 5, 7]),
+        ("ExpressionSwitch", [5, 4, 5]),
+        ("TypeSwitch", [6, 4, 5]),
+        ("SwitchMultipleValues", [5, 4, 5]),
+    ];
+
+    // The default mode is the built-in count recorded in the golden file
+    let golden: serde_json::Value = serde_json::from_str(&read_golden("go-switch.json")).unwrap();
+    for (name, [cases, _, _]) in expected {
+        let entry = golden
+            .as_array()
+            .unwrap()
+            .iter()
+            .find(|f| f["function"] == *name)
+            .unwrap_or_else(|| panic!("{} missing from go-switch.json", name));
+        assert_eq!(entry["metrics"]["cc"], *cases, "golden CC of {}", name);
+    }
+
+    for (i, mode) in ["cases", "statement", "mccabe"].into_iter().enumerate() {
+        let config: HotspotsConfig =
+            serde_json::from_str(&format!(r#"{{"cc_mode": "{mode}"}}"#)).unwrap();
+        let resolved = config.resolve().unwrap();
+        let reports = analyze_with_config(
+            &fixture,
+            AnalysisOptions {
+                min_lrs: None,
+                top_n: None,
+            },
+            Some(&resolved),
+        )
+        .unwrap_or_else(|e| panic!("Failed to analyze {}: {}", fixture.display(), e));
+        assert_eq!(reports.len(), expected.len());
+        for (name, ccs) in expected {
+            let report = reports
+                .iter()
+                .find(|r| r.function == *name)
+                .unwrap_or_else(|| panic!("{} not found", name));
+            assert_eq!(
+                report.metrics.cc as usize, ccs[i],
+                "CC of {} with cc_mode {}",
+                name, mode
+            );
+        }
+    }
+
+    let config: HotspotsConfig = serde_json::from_str(r#"{"cc_mode": "edges"}"#).unwrap();
+    assert!(config.resolve().is_err());
+}
+
 #[test]
 fn test_go_golden_determinism() {
     // Test that running Go analysis twice produces identical output
@@ -674,6 +828,157 @@ fn test_python_golden_determinism() {
     );
 }
 
+/// Pin the `--format markdown` layout: summary line, then the table, names
+/// as code spans so `__init__` is not read as bold
+#[test]
+fn test_golden_markdown() {
+    use hotspots_core::markdown::render_markdown;
+
+    let reports = analyze(
+        &fixture_path("python/classes.py"),
+        AnalysisOptions {
+            min_lrs: None,
+            top_n: None,
+        },
+    )
+    .unwrap();
+    let output = render_markdown(&reports, &project_root(), None, None, 20);
+    let expected = read_golden("python-classes.md").replace("\r\n", "\n");
+    assert_eq!(output, expected);
+
+    // Rows past the cap are counted, not listed; the summary still covers all
+    let capped = render_markdown(&reports, &project_root(), None, None, 2);
+    assert!(capped.starts_with("**Hotspots:** 1 file analyzed, 6 functions, 2 high"));
+    assert!(capped.contains("| 2 | `method_with_exception_handling` |"));
+    assert!(!capped.contains("`static_method`"));
+    assert!(capped.ends_with("\n_Showing the top 2 of 6 functions._\n"));
+
+    let filtered = render_markdown(&reports, &project_root(), Some(5.0), None, 20);
+    assert!(filtered.contains("| 4 | `class_method` |"));
+    assert!(!filtered.contains("`instance_method`"));
+    assert!(!filtered.contains("_Showing"));
+
+    // --min-severity narrows the table the same way
+    let high = hotspots_core::severity::SeverityFilter {
+        min: hotspots_core::severity::Severity::High,
+        scale: hotspots_core::severity::SeverityScale::default(),
+    };
+    let severe = render_markdown(&reports, &project_root(), None, Some(&high), 20);
+    assert!(severe.starts_with("**Hotspots:** 1 file analyzed, 6 functions"));
+    assert!(severe.contains("| 2 | `method_with_exception_handling` |"));
+    assert!(!severe.contains("`static_method`"));
+}
+
+/// Split CSV text into records of fields, undoing RFC 4180 quoting
+fn parse_csv(text: &str) -> Vec<Vec<String>> {
+    let mut records = Vec::new();
+    let mut record = Vec::new();
+    let mut field = String::new();
+    let mut quoted = false;
+    let mut chars = text.chars().peekable();
+    while let Some(c) = chars.next() {
+        match (quoted, c) {
+            (true, '"') if chars.peek() == Some(&'"') => {
+                chars.next();
+                field.push('"');
+            }
+            (true, '"') => quoted = false,
+            (true, c) => field.push(c),
+            (false, '"') => quoted = true,
+            (false, ',') => record.push(std::mem::take(&mut field)),
+            (false, '\n') => {
+                record.push(std::mem::take(&mut field));
+                records.push(std::mem::take(&mut record));
+            }
+            (false, c) => field.push(c),
+        }
+    }
+    records
+}
+
+/// `--format csv` parses back to the documented header and the known metrics
+#[test]
+fn test_golden_csv_round_trip() {
+    use hotspots_core::csv::{render_csv, CsvColumns, BASE_COLUMNS};
+
+    let reports = analyze(
+        &fixture_path("python/classes.py"),
+        AnalysisOptions {
+            min_lrs: None,
+            top_n: None,
+        },
+    )
+    .unwrap();
+    let output = render_csv(&reports, &project_root(), CsvColumns::default());
+    let records = parse_csv(&output);
+    assert_eq!(records[0], BASE_COLUMNS);
+    assert_eq!(records.len(), 7, "header plus one row per function");
+    assert!(records.iter().all(|r| r.len() == BASE_COLUMNS.len()));
+
+    let column = |name: &str| BASE_COLUMNS.iter().position(|c| *c == name).unwrap();
+    let row = records
+        .iter()
+        .find(|r| r[column("function")] == "async_method")
+        .expect("async_method row");
+    let field = |name: &str| row[column(name)].as_str();
+    assert_eq!(field("file"), "tests/fixtures/python/classes.py");
+    assert_eq!(field("start_line"), "39");
+    assert_eq!(field("end_line"), "44");
+    assert_eq!(
+        [field("cc"), field("nd"), field("fo"), field("ns")],
+        ["4", "2", "3", "2"]
+    );
+    assert_eq!(field("band"), "high");
+    let lrs: f64 = field("lrs").parse().unwrap();
+    assert!((lrs - 6.521928094887363).abs() < 1e-9, "lrs {lrs}");
+}
+
+/// `--format gitlab` has the shape GitLab's Code Quality widget reads, with
+/// fingerprints that do not change between runs
+#[test]
+fn test_golden_gitlab_code_quality_shape() {
+    use hotspots_core::gitlab::render_gitlab;
+    use hotspots_core::sarif::MetricRules;
+    use hotspots_core::severity::SeverityScale;
+
+    let reports = analyze(
+        &fixture_path("pathological.ts"),
+        AnalysisOptions {
+            min_lrs: None,
+            top_n: None,
+        },
+    )
+    .unwrap();
+    let render = || {
+        render_gitlab(
+            &reports,
+            &project_root(),
+            &MetricRules::default(),
+            &SeverityScale::default(),
+        )
+    };
+    let output = render();
+    assert_eq!(output, render(), "output should be deterministic");
+
+    let issues: Vec<serde_json::Value> = serde_json::from_str(&output).unwrap();
+    // CC 20 >= 15 and ND 6 >= 5
+    assert_eq!(issues.len(), 2, "{output}");
+    for issue in &issues {
+        assert!(issue["description"].is_string(), "{issue}");
+        assert_eq!(issue["fingerprint"].as_str().unwrap().len(), 16, "{issue}");
+        assert_eq!(issue["severity"], "blocker", "LRS 11.29 is critical");
+        assert_eq!(issue["location"]["path"], "tests/fixtures/pathological.ts");
+        assert_eq!(issue["location"]["lines"]["begin"], 2);
+    }
+    assert_eq!(issues[0]["check_name"], "hotspots/cc");
+    assert_eq!(
+        issues[0]["description"],
+        "pathological has cc 20 (threshold 15)"
+    );
+    assert_eq!(issues[1]["check_name"], "hotspots/nd");
+    assert_ne!(issues[0]["fingerprint"], issues[1]["fingerprint"]);
+}
+
 // Call graph golden tests — verify fan-out deduplication and LOC
 
 #[test]
@@ -739,6 +1044,30 @@ fn test_go_golden_call_graph_deduplication() {
         "Go middle fo should be 1 (deduplicated)"
     );
     assert_eq!(top.metrics.fo, 2);
+    // Fan-in is opt-in, so plain analysis leaves it at 0
+    assert_eq!(helper.metrics.fi, 0);
+
+    let mut config = ResolvedConfig::defaults().unwrap();
+    config.fan_in = true;
+    let options = AnalysisOptions {
+        min_lrs: None,
+        top_n: None,
+    };
+    let reports =
+        analyze_with_config(&fixture, options, Some(&config)).expect("analysis should succeed");
+    let fi = |name: &str| {
+        reports
+            .iter()
+            .find(|r| r.function == name)
+            .unwrap()
+            .metrics
+            .fi
+    };
+
+    // helper is called by middle (twice, counted once) and top
+    assert_eq!(fi("helper"), 2, "Go helper fi should be 2 (deduplicated)");
+    assert_eq!(fi("middle"), 1);
+    assert_eq!(fi("top"), 0);
 }
 
 /// Cross-language extended metrics determinism: LOC and fo must be identical across runs
@@ -1088,3 +1417,2221 @@ fn test_c_golden_determinism() {
     let json2 = render_json(&reports2);
     assert_eq!(json1, json2, "C analysis is not deterministic");
 }
+
+// Halstead tests
+
+/// (function, n1, n2, N1, N2, volume, difficulty, effort)
+type HalsteadRow = (&'static str, u32, u32, u32, u32, f64, f64, f64);
+
+/// Halstead metrics of functions in `go/simple.go`
+const GO_SIMPLE_HALSTEAD: &[HalsteadRow] = &[
+    // func ( ) { := = } / Simple x 1 _
+    (
+        "Simple",
+        7,
+        4,
+        7,
+        5,
+        41.513179423647,
+        4.375,
+        181.620159978456,
+    ),
+    // func ( ) { if < return - } * / EarlyReturn x int 0 1 2
+    ("EarlyReturn", 10, 6, 13, 9, 88.0, 7.5, 660.0),
+];
+
+#[test]
+fn test_go_golden_halstead() {
+    let fixture = fixture_path("go/simple.go");
+    let config: HotspotsConfig = serde_json::from_str("{}").unwrap();
+    let mut resolved = config.resolve().unwrap();
+    resolved.halstead = true;
+    let reports = analyze_with_config(
+        &fixture,
+        AnalysisOptions {
+            min_lrs: None,
+            top_n: None,
+        },
+        Some(&resolved),
+    )
+    .unwrap();
+
+    for &(name, n1, n2, big_n1, big_n2, volume, difficulty, effort) in GO_SIMPLE_HALSTEAD {
+        let report = reports.iter().find(|r| r.function == name).unwrap();
+        let h = report
+            .metrics
+            .halstead
+            .unwrap_or_else(|| panic!("{name} has no Halstead metrics"));
+        assert_eq!(
+            (
+                h.distinct_operators,
+                h.distinct_operands,
+                h.total_operators,
+                h.total_operands
+            ),
+            (n1, n2, big_n1, big_n2),
+            "Halstead counts of {name}"
+        );
+        assert!((h.volume - volume).abs() < 1e-9, "volume of {name}");
+        assert!(
+            (h.difficulty - difficulty).abs() < 1e-9,
+            "difficulty of {name}"
+        );
+        assert!((h.effort - effort).abs() < 1e-9, "effort of {name}");
+    }
+
+    // Off by default, and then absent from JSON
+    let default_reports = analyze(
+        &fixture,
+        AnalysisOptions {
+            min_lrs: None,
+            top_n: None,
+        },
+    )
+    .unwrap();
+    assert!(default_reports.iter().all(|r| r.metrics.halstead.is_none()));
+    assert!(!render_json(&default_reports).contains("halstead"));
+}
+
+/// (fixture, function, loc, sloc, comment_lines, blank_lines)
+type LineCountRow = (&'static str, &'static str, u32, u32, u32, u32);
+
+/// Line breakdown of Go functions; fixture comments state the expectations
+const GO_LINE_COUNTS: &[LineCountRow] = &[
+    ("go/simple.go", "Simple", 4, 4, 0, 0),
+    ("go/simple.go", "MultipleReturns", 9, 9, 0, 0),
+    ("go/line_counts.go", "Commented", 11, 6, 4, 1),
+    // The raw string's comment-like and empty lines are source
+    ("go/line_counts.go", "Template", 6, 6, 0, 0),
+];
+
+#[test]
+fn test_go_golden_line_counts() {
+    let config: HotspotsConfig = serde_json::from_str("{}").unwrap();
+    let mut resolved = config.resolve().unwrap();
+    resolved.line_counts = true;
+
+    for &(fixture, name, loc, sloc, comment_lines, blank_lines) in GO_LINE_COUNTS {
+        let reports = analyze_with_config(
+            &fixture_path(fixture),
+            AnalysisOptions {
+                min_lrs: None,
+                top_n: None,
+            },
+            Some(&resolved),
+        )
+        .unwrap();
+        let m = &reports.iter().find(|r| r.function == name).unwrap().metrics;
+        assert_eq!(
+            (m.loc, m.sloc, m.comment_lines, m.blank_lines),
+            (loc, Some(sloc), Some(comment_lines), Some(blank_lines)),
+            "line counts of {name}"
+        );
+    }
+
+    // Off by default, and then absent from JSON
+    let default_reports = analyze(
+        &fixture_path("go/line_counts.go"),
+        AnalysisOptions {
+            min_lrs: None,
+            top_n: None,
+        },
+    )
+    .unwrap();
+    assert!(default_reports.iter().all(|r| r.metrics.sloc.is_none()));
+    assert!(!render_json(&default_reports).contains("sloc"));
+}
+
+/// (fixture, function, nd, nd_line)
+type NdLineRow = (&'static str, &'static str, u32, u32);
+
+/// Where ND is reached: the first construct, in source order, at the deepest
+/// level
+const ND_LINES: &[NdLineRow] = &[
+    // if > for > if > switch > if
+    ("go/boolean_ops.go", "DeeplyNested", 5, 83),
+    // for > for > if > switch > the `if` in `case 3`
+    ("go/boolean_ops.go", "PathologicalComplexity", 5, 118),
+    // The inner `if` of the `then` branch comes before the `else` branch's
+    ("nested-branching.ts", "nested", 2, 4),
+    // Lines within the `<script>` block carry its offset in the file
+    ("vue/complex-logic.vue", "filterAndRank", 4, 40),
+    // Attributes above the method do not shift the line
+    ("rust/attributes.rs", "Attributed::sum_positive", 2, 34),
+    ("rust/attributes.rs", "Plain::sum_positive", 2, 58),
+];
+
+#[test]
+fn test_golden_nd_line() {
+    let config: HotspotsConfig = serde_json::from_str("{}").unwrap();
+    let mut resolved = config.resolve().unwrap();
+    resolved.nd_lines = true;
+
+    for &(fixture, name, nd, nd_line) in ND_LINES {
+        let reports = analyze_with_config(
+            &fixture_path(fixture),
+            AnalysisOptions {
+                min_lrs: None,
+                top_n: None,
+            },
+            Some(&resolved),
+        )
+        .unwrap();
+        let m = &reports.iter().find(|r| r.function == name).unwrap().metrics;
+        assert_eq!(
+            (m.nd, m.nd_line),
+            (nd, Some(nd_line)),
+            "deepest nest of {name}"
+        );
+    }
+
+    // No nesting, no line
+    let reports = analyze_with_config(
+        &fixture_path("go/simple.go"),
+        AnalysisOptions {
+            min_lrs: None,
+            top_n: None,
+        },
+        Some(&resolved),
+    )
+    .unwrap();
+    let simple = &reports
+        .iter()
+        .find(|r| r.function == "Simple")
+        .unwrap()
+        .metrics;
+    assert_eq!((simple.nd, simple.nd_line), (0, None));
+
+    // Off by default, and then absent from JSON
+    let default_reports = analyze(
+        &fixture_path("go/boolean_ops.go"),
+        AnalysisOptions {
+            min_lrs: None,
+            top_n: None,
+        },
+    )
+    .unwrap();
+    assert!(default_reports.iter().all(|r| r.metrics.nd_line.is_none()));
+    assert!(!render_json(&default_reports).contains("nd_line"));
+}
+
+/// The lines behind a function's CC: every decision point of the Go fixture
+/// is on a line its comment names
+#[test]
+fn test_golden_cc_lines() {
+    let config: HotspotsConfig = serde_json::from_str("{}").unwrap();
+    let mut resolved = config.resolve().unwrap();
+    resolved.cc_lines = true;
+
+    let reports = analyze_with_config(
+        &fixture_path("go/cc_lines.go"),
+        AnalysisOptions {
+            min_lrs: None,
+            top_n: None,
+        },
+        Some(&resolved),
+    )
+    .unwrap();
+    let classify = reports.iter().find(|r| r.function == "Classify").unwrap();
+    let cc_lines = classify.metrics.cc_lines.as_ref().unwrap();
+    assert_eq!(
+        cc_lines.signature,
+        "func Classify(items []int, strict bool) string {"
+    );
+    let lines: Vec<(u32, Vec<&str>)> = cc_lines
+        .lines
+        .iter()
+        .map(|l| (l.line, l.kinds.iter().map(String::as_str).collect()))
+        .collect();
+    assert_eq!(
+        lines,
+        vec![
+            (7, vec!["loop"]),
+            (8, vec!["if", "and", "and"]),
+            (10, vec!["if", "or"]),
+            (15, vec!["case"]),
+            (17, vec!["case"]),
+            (19, vec!["case"]),
+        ]
+    );
+    assert_eq!(cc_lines.lines[2].text, "} else if strict || item == 0 {");
+
+    let text = hotspots_core::report::render_cc_lines(classify.line, &classify.metrics);
+    let rows: Vec<&str> = text.lines().collect();
+    assert_eq!(rows.len(), 8);
+    assert!(rows[0].ends_with(&format!("decision points (CC {}):", classify.metrics.cc)));
+    assert!(rows[1].ends_with(" 5              func Classify(items []int, strict bool) string {"));
+    assert!(rows[3].ends_with(" 8  if, and \u{d7}2  if item > 0 && item < 100 && item != 7 {"));
+
+    // Straight-line code has only its signature, and prints nothing
+    let straight = reports.iter().find(|r| r.function == "Straight").unwrap();
+    assert!(straight.metrics.cc_lines.as_ref().unwrap().lines.is_empty());
+    assert!(hotspots_core::report::render_cc_lines(straight.line, &straight.metrics).is_empty());
+
+    // Off by default, and then absent from JSON
+    let default_reports = analyze(
+        &fixture_path("go/cc_lines.go"),
+        AnalysisOptions {
+            min_lrs: None,
+            top_n: None,
+        },
+    )
+    .unwrap();
+    assert!(default_reports.iter().all(|r| r.metrics.cc_lines.is_none()));
+    assert!(!render_json(&default_reports).contains("cc_lines"));
+}
+
+/// (fixture, function, ns_breakdown as JSON)
+type NsBreakdownRow = (&'static str, &'static str, &'static str);
+
+/// What the NS of the Go golden fixtures is made of; every other function in
+/// them has NS 0 and an empty breakdown. Go counts every `return`, including
+/// the last, and neither `break` nor `continue`.
+const GO_NS_BREAKDOWN: &[NsBreakdownRow] = &[
+    ("simple", "IfElse", r#"{"return":2}"#),
+    ("simple", "EarlyReturn", r#"{"return":2}"#),
+    ("simple", "MultipleReturns", r#"{"return":3}"#),
+    ("switch", "SimpleSwitch", r#"{"return":3}"#),
+    ("switch", "SwitchWithFallthrough", r#"{"return":1}"#),
+    ("go_specific", "WithDefer", r#"{"defer":1}"#),
+    ("go_specific", "MultipleDefers", r#"{"defer":3}"#),
+    ("go_specific", "ConditionalDefer", r#"{"defer":1}"#),
+    ("go_specific", "GoroutineAndDefer", r#"{"defer":1}"#),
+    ("go_specific", "WithPanic", r#"{"throw":1}"#),
+    ("go_specific", "WithRecover", r#"{"defer":1}"#),
+    ("go_specific", "SelectInLoop", r#"{"return":1}"#),
+    (
+        "go_specific",
+        "ComplexGoFunction",
+        r#"{"defer":1,"return":3}"#,
+    ),
+];
+
+#[test]
+fn test_go_golden_ns_breakdown() {
+    let config: HotspotsConfig = serde_json::from_str("{}").unwrap();
+    let mut resolved = config.resolve().unwrap();
+    resolved.ns_breakdown = true;
+
+    for fixture in ["simple", "loops", "switch", "go_specific"] {
+        let path = fixture_path(&format!("go/{fixture}.go"));
+        let reports = analyze_with_config(
+            &path,
+            AnalysisOptions {
+                min_lrs: None,
+                top_n: None,
+            },
+            Some(&resolved),
+        )
+        .unwrap();
+
+        // The aggregate is unchanged: without the breakdown, output matches
+        // the golden file
+        let mut output_json: serde_json::Value =
+            serde_json::from_str(&render_json(&reports)).unwrap();
+        for report in output_json.as_array_mut().unwrap() {
+            let metrics = report["metrics"].as_object_mut().unwrap();
+            assert!(metrics.remove("ns_breakdown").is_some());
+        }
+        let mut expected_json: serde_json::Value =
+            serde_json::from_str(&read_golden(&format!("go-{fixture}.json"))).unwrap();
+        normalize_paths(&mut output_json, &project_root());
+        normalize_paths(&mut expected_json, &project_root());
+        assert_eq!(output_json, expected_json, "golden output of {fixture}");
+
+        for report in &reports {
+            let breakdown = report.metrics.ns_breakdown.as_ref().unwrap();
+            assert_eq!(
+                breakdown.total(),
+                report.metrics.ns as usize,
+                "breakdown of {} totals NS",
+                report.function
+            );
+            let expected = GO_NS_BREAKDOWN
+                .iter()
+                .find(|&&(f, name, _)| f == fixture && name == report.function)
+                .map_or("{}", |&(_, _, json)| json);
+            assert_eq!(
+                serde_json::to_value(breakdown).unwrap(),
+                serde_json::from_str::<serde_json::Value>(expected).unwrap(),
+                "NS breakdown of {}",
+                report.function
+            );
+        }
+    }
+
+    // Off by default, and then absent from JSON
+    let default_reports = analyze(
+        &fixture_path("go/go_specific.go"),
+        AnalysisOptions {
+            min_lrs: None,
+            top_n: None,
+        },
+    )
+    .unwrap();
+    assert!(default_reports
+        .iter()
+        .all(|r| r.metrics.ns_breakdown.is_none()));
+    assert!(!render_json(&default_reports).contains("ns_breakdown"));
+}
+
+/// Declared parameters of Go functions; fixture comments state the expectations
+#[test]
+fn test_go_golden_params() {
+    let reports = analyze(
+        &fixture_path("go/params.go"),
+        AnalysisOptions {
+            min_lrs: None,
+            top_n: None,
+        },
+    )
+    .unwrap();
+    let params: Vec<(&str, u32)> = reports
+        .iter()
+        .map(|r| (r.function.as_str(), r.metrics.params))
+        .collect();
+    for (name, expected) in [
+        ("Grouped", 3),
+        ("Variadic", 2),
+        ("Point.Translate", 2),
+        ("Point.Origin", 0),
+    ] {
+        assert!(
+            params.contains(&(name, expected)),
+            "{name} should declare {expected} params: {params:?}"
+        );
+    }
+
+    // Omitted from JSON when 0
+    let json: serde_json::Value = serde_json::from_str(&render_json(&reports)).unwrap();
+    let origin = json
+        .as_array()
+        .unwrap()
+        .iter()
+        .find(|r| r["function"] == "Point.Origin")
+        .unwrap();
+    assert!(origin["metrics"].get("params").is_none());
+}
+
+/// Method calls count toward Go FO like function calls, keyed by the call's
+/// text, unless `fo_methods` is off; fixture comments state the expectations
+#[test]
+fn test_go_golden_method_call_fan_out() {
+    fn find<'a>(
+        reports: &'a [hotspots_core::FunctionRiskReport],
+        name: &str,
+    ) -> &'a hotspots_core::FunctionRiskReport {
+        reports
+            .iter()
+            .find(|r| r.function == name)
+            .unwrap_or_else(|| panic!("missing {name}"))
+    }
+
+    let fixture = fixture_path("go/method_calls.go");
+    let options = || AnalysisOptions {
+        min_lrs: None,
+        top_n: None,
+    };
+    let with_methods = analyze(&fixture, options()).unwrap();
+    let config: HotspotsConfig = serde_json::from_str(r#"{"fo_methods": false}"#).unwrap();
+    let resolved = config.resolve().unwrap();
+    let without_methods = analyze_with_config(&fixture, options(), Some(&resolved)).unwrap();
+
+    for (name, fo, fo_without_methods) in [
+        ("Store.Get", 0, 0),
+        ("Store.Put", 0, 0),
+        ("Cache.Refresh", 4, 1),
+        ("Merge", 2, 0),
+        ("Cache.Lookup", 2, 0),
+        ("Cache.Backing", 0, 0),
+        ("Load", 1, 0),
+        ("Describe", 2, 1),
+    ] {
+        assert_eq!(find(&with_methods, name).metrics.fo, fo, "{name}");
+        assert_eq!(
+            find(&without_methods, name).metrics.fo,
+            fo_without_methods,
+            "{name} without method calls"
+        );
+    }
+    assert_eq!(
+        find(&with_methods, "Cache.Refresh").callees,
+        ["c.store.Get", "c.store.Put", "s.Get", "strings.ToLower"]
+    );
+    assert_eq!(
+        find(&with_methods, "Cache.Lookup").callees,
+        ["c.Backing", "c.Backing().Get"]
+    );
+    assert_eq!(
+        find(&without_methods, "Cache.Refresh").callees,
+        ["strings.ToLower"]
+    );
+    assert_eq!(find(&without_methods, "Describe").callees, ["conv.Itoa"]);
+}
+
+/// Methods of a generic type are named after the receiver type and measure
+/// the same as identical methods of a plain type; fixture comments state the
+/// expectations
+#[test]
+fn test_go_golden_generic_methods() {
+    let reports = analyze(
+        &fixture_path("go/generics.go"),
+        AnalysisOptions {
+            min_lrs: None,
+            top_n: None,
+        },
+    )
+    .unwrap();
+    let find = |name: &str| {
+        reports
+            .iter()
+            .find(|r| r.function == name)
+            .unwrap_or_else(|| panic!("missing {name}"))
+    };
+    let mut names: Vec<&str> = reports.iter().map(|r| r.function.as_str()).collect();
+    names.sort();
+    assert_eq!(
+        names,
+        [
+            "IntStack.Len",
+            "IntStack.Pop",
+            "IntStack.Push",
+            "Map",
+            "Pair.Swap",
+            "Stack.Len",
+            "Stack.Pop",
+            "Stack.Push"
+        ]
+    );
+
+    for method in ["Push", "Pop", "Len"] {
+        let generic = find(&format!("Stack.{method}"));
+        let plain = find(&format!("IntStack.{method}"));
+        assert_eq!(
+            (generic.metrics.cc, generic.metrics.nd, generic.metrics.fo),
+            (plain.metrics.cc, plain.metrics.nd, plain.metrics.fo),
+            "{method}"
+        );
+        assert_eq!(
+            (generic.metrics.ns, generic.metrics.params, generic.lrs),
+            (plain.metrics.ns, plain.metrics.params, plain.lrs),
+            "{method}"
+        );
+    }
+
+    // (function, fo, ns, params)
+    for (name, fo, ns, params) in [
+        ("Stack.Push", 1, 0, 1),
+        ("Stack.Pop", 1, 2, 0),
+        ("Stack.Len", 1, 1, 0),
+        ("Pair.Swap", 0, 0, 1),
+        ("Map", 4, 1, 2),
+    ] {
+        let r = find(name);
+        assert_eq!(
+            (r.metrics.fo, r.metrics.ns, r.metrics.params),
+            (fo, ns, params),
+            "{name}"
+        );
+    }
+}
+
+// Swift golden tests
+
+/// (function, cc, nd, fo, ns)
+type SwiftMetrics = (&'static str, u32, u32, u32, u32);
+
+/// Check every function of a Swift fixture
+fn test_swift_metrics(fixture_name: &str, expected: &[SwiftMetrics]) {
+    let fixture = fixture_path(&format!("swift/{}.swift", fixture_name));
+    let reports = analyze(
+        &fixture,
+        AnalysisOptions {
+            min_lrs: None,
+            top_n: None,
+        },
+    )
+    .unwrap_or_else(|e| panic!("Failed to analyze {}: {}", fixture.display(), e));
+
+    assert_eq!(
+        reports.len(),
+        expected.len(),
+        "function count of swift/{}",
+        fixture_name
+    );
+    for &(name, cc, nd, fo, ns) in expected {
+        let report = reports
+            .iter()
+            .find(|r| r.function == name)
+            .unwrap_or_else(|| panic!("swift/{fixture_name} has no function {name}"));
+        let m = &report.metrics;
+        assert_eq!(
+            (m.cc, m.nd, m.fo, m.ns),
+            (cc, nd, fo, ns),
+            "(cc, nd, fo, ns) of {name} in swift/{fixture_name}"
+        );
+    }
+}
+
+#[test]
+fn test_swift_golden_simple() {
+    test_swift_metrics(
+        "simple",
+        &[
+            ("simple", 3, 0, 1, 0),
+            ("singleBranch", 4, 1, 0, 1),
+            ("ifElse", 4, 1, 0, 2),
+            ("earlyReturn", 4, 1, 0, 2),
+            ("multipleReturns", 5, 1, 0, 3),
+            ("guardReturn", 4, 1, 0, 2),
+        ],
+    );
+}
+
+#[test]
+fn test_swift_golden_loops() {
+    test_swift_metrics(
+        "loops",
+        &[
+            ("simpleLoop", 4, 1, 1, 0),
+            ("loopWithCondition", 5, 2, 1, 0),
+            ("nestedLoops", 5, 2, 1, 0),
+            ("loopWithBreak", 5, 2, 0, 1),
+            ("loopWithContinue", 5, 2, 1, 1),
+            ("whileLoop", 4, 1, 0, 0),
+            ("repeatLoop", 4, 1, 0, 0),
+        ],
+    );
+}
+
+#[test]
+fn test_swift_golden_switch() {
+    test_swift_metrics(
+        "switch",
+        &[
+            ("simpleSwitch", 6, 1, 0, 3),
+            ("nestedSwitch", 7, 2, 1, 0),
+            ("whereSwitch", 6, 1, 0, 3),
+            ("switchMultipleValues", 6, 1, 0, 3),
+        ],
+    );
+}
+
+#[test]
+fn test_swift_golden_specific() {
+    test_swift_metrics(
+        "swift_specific",
+        &[
+            ("area.get", 3, 0, 0, 0),
+            ("isSquare.get", 1, 0, 0, 1),
+            ("isSquare.set", 4, 1, 0, 0),
+            ("scaled", 4, 1, 1, 2),
+            ("init", 3, 0, 1, 0),
+            ("increment", 4, 1, 0, 0),
+            // Two catch branches; fatalError() never returns
+            ("load", 5, 1, 2, 3),
+            // Only the ternary inside the closure
+            ("describe", 2, 0, 1, 1),
+            ("contains", 6, 3, 0, 2),
+        ],
+    );
+}
+
+#[test]
+fn test_swift_golden_determinism() {
+    let fixture = fixture_path("swift/swift_specific.swift");
+
+    let options = AnalysisOptions {
+        min_lrs: None,
+        top_n: None,
+    };
+    let reports1 = analyze(&fixture, options).unwrap();
+    let options = AnalysisOptions {
+        min_lrs: None,
+        top_n: None,
+    };
+    let reports2 = analyze(&fixture, options).unwrap();
+
+    let json1 = render_json(&reports1);
+    let json2 = render_json(&reports2);
+    assert_eq!(json1, json2, "Swift analysis is not deterministic");
+}
+
+// PHP golden tests
+
+/// (function, cc, nd, fo, ns)
+type PhpMetrics = (&'static str, u32, u32, u32, u32);
+
+/// Check every function of a PHP fixture
+fn test_php_metrics(fixture_name: &str, expected: &[PhpMetrics]) {
+    let fixture = fixture_path(&format!("php/{}.php", fixture_name));
+    let reports = analyze(
+        &fixture,
+        AnalysisOptions {
+            min_lrs: None,
+            top_n: None,
+        },
+    )
+    .unwrap_or_else(|e| panic!("Failed to analyze {}: {}", fixture.display(), e));
+
+    assert_eq!(
+        reports.len(),
+        expected.len(),
+        "function count of php/{}",
+        fixture_name
+    );
+    for &(name, cc, nd, fo, ns) in expected {
+        let report = reports
+            .iter()
+            .find(|r| r.function == name)
+            .unwrap_or_else(|| panic!("php/{fixture_name} has no function {name}"));
+        let m = &report.metrics;
+        assert_eq!(
+            (m.cc, m.nd, m.fo, m.ns),
+            (cc, nd, fo, ns),
+            "(cc, nd, fo, ns) of {name} in php/{fixture_name}"
+        );
+    }
+}
+
+#[test]
+fn test_php_golden_simple() {
+    test_php_metrics(
+        "simple",
+        &[
+            ("simple", 3, 0, 0, 0),
+            ("singleBranch", 4, 1, 0, 1),
+            ("ifElse", 4, 1, 0, 2),
+            ("earlyReturn", 4, 1, 0, 2),
+            ("multipleReturns", 5, 1, 0, 3),
+            ("classify", 5, 1, 0, 3),
+        ],
+    );
+}
+
+#[test]
+fn test_php_golden_loops() {
+    test_php_metrics(
+        "loops",
+        &[
+            ("simpleLoop", 4, 1, 0, 0),
+            ("loopWithCondition", 5, 2, 0, 0),
+            ("nestedLoops", 5, 2, 0, 0),
+            ("loopWithBreak", 5, 2, 0, 1),
+            ("loopWithContinue", 5, 2, 0, 1),
+            ("foreachLoop", 4, 1, 0, 0),
+            ("whileLoop", 4, 1, 0, 0),
+            ("doWhileLoop", 4, 1, 0, 0),
+        ],
+    );
+}
+
+#[test]
+fn test_php_golden_switch() {
+    test_php_metrics(
+        "switch",
+        &[
+            ("simpleSwitch", 6, 1, 0, 3),
+            ("switchNoDefault", 5, 1, 0, 2),
+            ("switchFallthrough", 6, 1, 0, 2),
+            ("nestedSwitch", 7, 2, 0, 4),
+            ("switchMultipleValues", 7, 1, 0, 3),
+            // Two conditional arms; the `match` itself adds no CFG branch
+            ("matchStatus", 3, 1, 0, 1),
+        ],
+    );
+}
+
+#[test]
+fn test_php_golden_specific() {
+    test_php_metrics(
+        "php_specific",
+        &[
+            ("__construct", 1, 0, 0, 0),
+            // `??` adds one
+            ("total", 5, 1, 0, 1),
+            // Two catch branches; `throw` is an exit
+            ("load", 5, 1, 1, 3),
+            ("label", 2, 0, 0, 1),
+            // `and` and `or` count like `&&` and `||`
+            ("isOverdue", 3, 0, 0, 1),
+            ("validate", 4, 1, 1, 2),
+            ("double", 1, 0, 0, 0),
+            ("withinLimit", 2, 0, 2, 1),
+            // The HTML around and inside the loop adds nothing
+            ("renderItems", 4, 1, 1, 0),
+        ],
+    );
+}
+
+#[test]
+fn test_php_golden_determinism() {
+    let fixture = fixture_path("php/php_specific.php");
+
+    let options = AnalysisOptions {
+        min_lrs: None,
+        top_n: None,
+    };
+    let reports1 = analyze(&fixture, options).unwrap();
+    let options = AnalysisOptions {
+        min_lrs: None,
+        top_n: None,
+    };
+    let reports2 = analyze(&fixture, options).unwrap();
+
+    let json1 = render_json(&reports1);
+    let json2 = render_json(&reports2);
+    assert_eq!(json1, json2, "PHP analysis is not deterministic");
+}
+
+// C++ golden tests
+
+/// (function, cc, nd, fo, ns)
+type CppMetrics = (&'static str, u32, u32, u32, u32);
+
+/// Check every function of a C++ fixture
+fn test_cpp_metrics(fixture_name: &str, expected: &[CppMetrics]) {
+    let fixture = fixture_path(&format!("cpp/{}.cpp", fixture_name));
+    let reports = analyze(
+        &fixture,
+        AnalysisOptions {
+            min_lrs: None,
+            top_n: None,
+        },
+    )
+    .unwrap_or_else(|e| panic!("Failed to analyze {}: {}", fixture.display(), e));
+
+    assert_eq!(
+        reports.len(),
+        expected.len(),
+        "function count of cpp/{}",
+        fixture_name
+    );
+    for &(name, cc, nd, fo, ns) in expected {
+        let report = reports
+            .iter()
+            .find(|r| r.function == name)
+            .unwrap_or_else(|| panic!("cpp/{fixture_name} has no function {name}"));
+        let m = &report.metrics;
+        assert_eq!(
+            (m.cc, m.nd, m.fo, m.ns),
+            (cc, nd, fo, ns),
+            "(cc, nd, fo, ns) of {name} in cpp/{fixture_name}"
+        );
+    }
+}
+
+#[test]
+fn test_cpp_golden_simple() {
+    test_cpp_metrics(
+        "simple",
+        &[
+            // `std::cout <<` is an operator, not a call
+            ("simple", 3, 0, 0, 0),
+            ("singleBranch", 4, 1, 0, 1),
+            ("ifElse", 4, 1, 0, 2),
+            ("earlyReturn", 4, 1, 0, 2),
+            ("multipleReturns", 5, 1, 0, 3),
+        ],
+    );
+}
+
+#[test]
+fn test_cpp_golden_loops() {
+    test_cpp_metrics(
+        "loops",
+        &[
+            ("simpleLoop", 4, 1, 0, 0),
+            ("loopWithCondition", 5, 2, 0, 0),
+            ("nestedLoops", 5, 2, 0, 0),
+            ("loopWithBreak", 5, 2, 0, 1),
+            ("loopWithContinue", 5, 2, 0, 1),
+            ("rangeLoop", 4, 1, 0, 0),
+            ("whileLoop", 4, 1, 0, 0),
+            ("doWhileLoop", 4, 1, 0, 0),
+        ],
+    );
+}
+
+#[test]
+fn test_cpp_golden_switch() {
+    test_cpp_metrics(
+        "switch",
+        &[
+            ("simpleSwitch", 6, 1, 0, 3),
+            ("switchNoDefault", 5, 1, 0, 2),
+            ("switchFallthrough", 6, 1, 0, 2),
+            ("nestedSwitch", 7, 2, 0, 4),
+            ("switchMultipleValues", 7, 1, 0, 3),
+        ],
+    );
+}
+
+#[test]
+fn test_cpp_golden_specific() {
+    test_cpp_metrics(
+        "cpp_specific",
+        &[
+            // Names carry their namespace and class
+            ("shop::Cart::total", 4, 1, 0, 1),
+            // `throw` is an exit
+            ("shop::Cart::add", 4, 1, 1, 1),
+            // Analyzed once at the template definition
+            ("shop::clampTo", 3, 0, 0, 1),
+            // Each catch clause adds one
+            ("shop::parseQuantity", 5, 1, 1, 3),
+            // The lambda's `&&` and `return` also count toward the enclosing function
+            ("countExpensive", 4, 0, 3, 2),
+            ("isExpensive", 2, 0, 0, 1),
+        ],
+    );
+}
+
+#[test]
+fn test_cpp_golden_determinism() {
+    let fixture = fixture_path("cpp/cpp_specific.cpp");
+
+    let options = AnalysisOptions {
+        min_lrs: None,
+        top_n: None,
+    };
+    let reports1 = analyze(&fixture, options).unwrap();
+    let options = AnalysisOptions {
+        min_lrs: None,
+        top_n: None,
+    };
+    let reports2 = analyze(&fixture, options).unwrap();
+
+    let json1 = render_json(&reports1);
+    let json2 = render_json(&reports2);
+    assert_eq!(json1, json2, "C++ analysis is not deterministic");
+}
+
+// Scala golden tests
+
+/// (function, cc, nd, fo, ns)
+type ScalaMetrics = (&'static str, u32, u32, u32, u32);
+
+/// Check every function of a Scala fixture
+fn test_scala_metrics(fixture_name: &str, expected: &[ScalaMetrics]) {
+    let fixture = fixture_path(&format!("scala/{}.scala", fixture_name));
+    let reports = analyze(
+        &fixture,
+        AnalysisOptions {
+            min_lrs: None,
+            top_n: None,
+        },
+    )
+    .unwrap_or_else(|e| panic!("Failed to analyze {}: {}", fixture.display(), e));
+
+    assert_eq!(
+        reports.len(),
+        expected.len(),
+        "function count of scala/{}",
+        fixture_name
+    );
+    for &(name, cc, nd, fo, ns) in expected {
+        let report = reports
+            .iter()
+            .find(|r| r.function == name)
+            .unwrap_or_else(|| panic!("scala/{fixture_name} has no function {name}"));
+        let m = &report.metrics;
+        assert_eq!(
+            (m.cc, m.nd, m.fo, m.ns),
+            (cc, nd, fo, ns),
+            "(cc, nd, fo, ns) of {name} in scala/{fixture_name}"
+        );
+    }
+}
+
+#[test]
+fn test_scala_golden_simple() {
+    test_scala_metrics(
+        "simple",
+        &[
+            ("simple", 3, 0, 0, 0),
+            ("singleBranch", 4, 1, 0, 1),
+            ("ifElse", 4, 1, 0, 0),
+            // `else if` is an `if` inside the `else`
+            ("classify", 5, 2, 0, 0),
+            ("bothPositive", 4, 0, 0, 0),
+            ("validate", 4, 1, 0, 1),
+        ],
+    );
+}
+
+#[test]
+fn test_scala_golden_loops() {
+    test_scala_metrics(
+        "loops",
+        &[
+            ("simpleLoop", 4, 1, 1, 0),
+            ("loopWithGuard", 5, 1, 0, 0),
+            ("nestedLoops", 5, 2, 1, 0),
+            ("whileLoop", 4, 1, 0, 0),
+            ("doWhileLoop", 4, 1, 0, 0),
+            // Two guards
+            ("comprehension", 6, 1, 0, 0),
+            ("countdown", 4, 1, 1, 0),
+        ],
+    );
+}
+
+#[test]
+fn test_scala_golden_match() {
+    test_scala_metrics(
+        "match",
+        &[
+            // Each case adds one
+            ("describe", 6, 1, 0, 0),
+            ("area", 6, 1, 1, 0),
+            ("classifyWithGuards", 9, 1, 0, 0),
+            ("nestedMatch", 7, 2, 0, 0),
+            ("parse", 7, 1, 2, 0),
+        ],
+    );
+}
+
+#[test]
+fn test_scala_golden_specific() {
+    test_scala_metrics(
+        "scala_specific",
+        &[
+            ("deposit", 3, 0, 1, 0),
+            ("fee", 4, 1, 0, 0),
+            ("withdraw", 6, 2, 2, 0),
+            // Two catch cases; `throw` is an exit
+            ("load", 5, 1, 4, 1),
+            ("read", 3, 0, 1, 0),
+            ("firstPositive", 5, 2, 1, 1),
+            ("shout", 3, 0, 0, 0),
+            ("total", 4, 0, 1, 0),
+            ("greet", 4, 1, 0, 0),
+        ],
+    );
+}
+
+#[test]
+fn test_scala_golden_determinism() {
+    let fixture = fixture_path("scala/scala_specific.scala");
+
+    let options = AnalysisOptions {
+        min_lrs: None,
+        top_n: None,
+    };
+    let reports1 = analyze(&fixture, options).unwrap();
+    let options = AnalysisOptions {
+        min_lrs: None,
+        top_n: None,
+    };
+    let reports2 = analyze(&fixture, options).unwrap();
+
+    let json1 = render_json(&reports1);
+    let json2 = render_json(&reports2);
+    assert_eq!(json1, json2, "Scala analysis is not deterministic");
+}
+
+// Dart golden tests
+
+/// (function, cc, nd, fo, ns)
+type DartMetrics = (&'static str, u32, u32, u32, u32);
+
+/// Check every function of a Dart fixture
+fn test_dart_metrics(fixture_name: &str, expected: &[DartMetrics]) {
+    let fixture = fixture_path(&format!("dart/{}.dart", fixture_name));
+    let reports = analyze(
+        &fixture,
+        AnalysisOptions {
+            min_lrs: None,
+            top_n: None,
+        },
+    )
+    .unwrap_or_else(|e| panic!("Failed to analyze {}: {}", fixture.display(), e));
+
+    assert_eq!(
+        reports.len(),
+        expected.len(),
+        "function count of dart/{}",
+        fixture_name
+    );
+    for &(name, cc, nd, fo, ns) in expected {
+        let report = reports
+            .iter()
+            .find(|r| r.function == name)
+            .unwrap_or_else(|| panic!("dart/{fixture_name} has no function {name}"));
+        let m = &report.metrics;
+        assert_eq!(
+            (m.cc, m.nd, m.fo, m.ns),
+            (cc, nd, fo, ns),
+            "(cc, nd, fo, ns) of {name} in dart/{fixture_name}"
+        );
+    }
+}
+
+#[test]
+fn test_dart_golden_simple() {
+    test_dart_metrics(
+        "simple",
+        &[
+            ("simple", 3, 0, 0, 0),
+            ("singleBranch", 4, 1, 0, 2),
+            ("ifElse", 4, 1, 0, 2),
+            // `else if` is an `if` inside the `else`
+            ("classify", 5, 2, 0, 3),
+            ("bothPositive", 4, 0, 0, 0),
+            ("ternary", 4, 0, 0, 0),
+            ("validate", 4, 1, 2, 2),
+        ],
+    );
+}
+
+#[test]
+fn test_dart_golden_loops() {
+    test_dart_metrics(
+        "loops",
+        &[
+            ("sum", 4, 1, 0, 1),
+            ("sumPositive", 5, 2, 0, 2),
+            ("countdown", 5, 1, 0, 1),
+            ("firstNegative", 6, 3, 0, 2),
+            ("drain", 5, 2, 2, 1),
+            // Collection `for` and `if` are a loop and a branch, and nest
+            ("labels", 6, 2, 0, 0),
+        ],
+    );
+}
+
+#[test]
+fn test_dart_golden_switch() {
+    test_dart_metrics(
+        "switch",
+        &[
+            // Each non-default case adds one, empty ones included
+            ("describe", 7, 1, 0, 4),
+            ("log", 5, 1, 1, 1),
+            // Every arm but the bare `_`
+            ("size", 6, 1, 0, 0),
+            ("grade", 7, 2, 0, 5),
+        ],
+    );
+}
+
+#[test]
+fn test_dart_golden_specific() {
+    test_dart_metrics(
+        "dart_specific",
+        &[
+            ("Account", 4, 1, 1, 1),
+            ("balance", 3, 0, 0, 0),
+            ("limit", 4, 0, 0, 0),
+            // `?.` and `??=`
+            ("nickname", 5, 0, 1, 1),
+            ("withdraw", 5, 1, 1, 1),
+            // Two `on` handlers; `rethrow` is an exit
+            ("load", 5, 1, 4, 3),
+            // The local function's `if` and `return`s also count toward
+            // the enclosing function's ND and NS, but not its CC
+            ("total", 3, 1, 2, 3),
+            ("clamp", 4, 1, 0, 2),
+        ],
+    );
+}
+
+#[test]
+fn test_dart_golden_flutter_build() {
+    test_dart_metrics(
+        "flutter_build",
+        &[
+            // Nested widget constructors add nothing to ND; the collection
+            // `for` inside the collection `if` is depth 2. CC counts `?.`,
+            // `??`, the collection `if`, `&&`, and the collection `for`.
+            ("build", 8, 2, 10, 1),
+            ("createState", 3, 0, 1, 0),
+            // The `if` in the `setState` closure belongs to `_increment`
+            ("_increment", 4, 1, 1, 0),
+        ],
+    );
+}
+
+#[test]
+fn test_dart_golden_determinism() {
+    let fixture = fixture_path("dart/flutter_build.dart");
+
+    let options = AnalysisOptions {
+        min_lrs: None,
+        top_n: None,
+    };
+    let reports1 = analyze(&fixture, options).unwrap();
+    let options = AnalysisOptions {
+        min_lrs: None,
+        top_n: None,
+    };
+    let reports2 = analyze(&fixture, options).unwrap();
+
+    let json1 = render_json(&reports1);
+    let json2 = render_json(&reports2);
+    assert_eq!(json1, json2, "Dart analysis is not deterministic");
+}
+
+// Elixir golden tests
+
+/// (function, cc, nd, fo, ns)
+type ElixirMetrics = (&'static str, u32, u32, u32, u32);
+
+/// Check every function of an Elixir fixture
+fn test_elixir_metrics(fixture_name: &str, expected: &[ElixirMetrics]) {
+    let fixture = fixture_path(&format!("elixir/{}.ex", fixture_name));
+    let reports = analyze(
+        &fixture,
+        AnalysisOptions {
+            min_lrs: None,
+            top_n: None,
+        },
+    )
+    .unwrap_or_else(|e| panic!("Failed to analyze {}: {}", fixture.display(), e));
+
+    assert_eq!(
+        reports.len(),
+        expected.len(),
+        "function count of elixir/{}",
+        fixture_name
+    );
+    for &(name, cc, nd, fo, ns) in expected {
+        let report = reports
+            .iter()
+            .find(|r| r.function == name)
+            .unwrap_or_else(|| panic!("elixir/{fixture_name} has no function {name}"));
+        let m = &report.metrics;
+        assert_eq!(
+            (m.cc, m.nd, m.fo, m.ns),
+            (cc, nd, fo, ns),
+            "(cc, nd, fo, ns) of {name} in elixir/{fixture_name}"
+        );
+    }
+}
+
+#[test]
+fn test_elixir_golden_simple() {
+    test_elixir_metrics(
+        "simple",
+        &[
+            ("simple", 3, 0, 0, 0),
+            ("single_branch", 4, 1, 0, 0),
+            ("if_else", 4, 1, 0, 0),
+            // `and` in the condition; `user.active` reads a field
+            ("check", 5, 1, 0, 1),
+            ("both_positive", 5, 0, 0, 0),
+            // Every stage of the pipe is a call, the bare `sanitize` too
+            ("normalize", 3, 0, 3, 0),
+        ],
+    );
+}
+
+#[test]
+fn test_elixir_golden_multi_clause() {
+    test_elixir_metrics(
+        "multi_clause",
+        &[
+            // Three clauses are one function with two extra branches
+            ("area", 5, 0, 0, 0),
+            // Two extra clauses, the guard, and its `and`
+            ("fib", 7, 0, 1, 0),
+            ("describe", 6, 0, 0, 0),
+            // The anonymous function's second clause and its guard
+            ("total", 5, 0, 1, 0),
+        ],
+    );
+}
+
+#[test]
+fn test_elixir_golden_with_chains() {
+    test_elixir_metrics(
+        "with_chains",
+        &[
+            // One branch per `<-`
+            ("place_order", 6, 1, 4, 0),
+            // Two `<-` (a plain `=` cannot fail) and three `else` clauses
+            ("place_order_safe", 8, 1, 2, 1),
+            ("charge", 6, 2, 3, 0),
+        ],
+    );
+}
+
+#[test]
+fn test_elixir_golden_control() {
+    test_elixir_metrics(
+        "control",
+        &[
+            ("grade", 7, 1, 0, 0),
+            ("read_config", 5, 1, 3, 0),
+            // The `after` clause is a branch too; `exit` leaves
+            ("await_reply", 6, 1, 0, 1),
+            // The loop, its two filters, and the `if`
+            ("evens", 7, 2, 1, 0),
+        ],
+    );
+}
+
+#[test]
+fn test_elixir_golden_determinism() {
+    let fixture = fixture_path("elixir/with_chains.ex");
+
+    let options = AnalysisOptions {
+        min_lrs: None,
+        top_n: None,
+    };
+    let reports1 = analyze(&fixture, options).unwrap();
+    let options = AnalysisOptions {
+        min_lrs: None,
+        top_n: None,
+    };
+    let reports2 = analyze(&fixture, options).unwrap();
+
+    let json1 = render_json(&reports1);
+    let json2 = render_json(&reports2);
+    assert_eq!(json1, json2, "Elixir analysis is not deterministic");
+}
+
+// Lua golden tests
+
+/// (function, cc, nd, fo, ns)
+type LuaMetrics = (&'static str, u32, u32, u32, u32);
+
+/// Check every function of a Lua fixture. Anonymous functions are named
+/// `<anonymous>:<line>`.
+fn test_lua_metrics(fixture_name: &str, expected: &[LuaMetrics]) {
+    let fixture = fixture_path(&format!("lua/{}.lua", fixture_name));
+    let reports = analyze(
+        &fixture,
+        AnalysisOptions {
+            min_lrs: None,
+            top_n: None,
+        },
+    )
+    .unwrap_or_else(|e| panic!("Failed to analyze {}: {}", fixture.display(), e));
+
+    assert_eq!(
+        reports.len(),
+        expected.len(),
+        "function count of lua/{}",
+        fixture_name
+    );
+    let display_name = |r: &hotspots_core::FunctionRiskReport| {
+        if r.function.starts_with("<anonymous>@") {
+            format!("<anonymous>:{}", r.line)
+        } else {
+            r.function.clone()
+        }
+    };
+    for &(name, cc, nd, fo, ns) in expected {
+        let report = reports
+            .iter()
+            .find(|r| display_name(r) == name)
+            .unwrap_or_else(|| panic!("lua/{fixture_name} has no function {name}"));
+        let m = &report.metrics;
+        assert_eq!(
+            (m.cc, m.nd, m.fo, m.ns),
+            (cc, nd, fo, ns),
+            "(cc, nd, fo, ns) of {name} in lua/{fixture_name}"
+        );
+    }
+}
+
+#[test]
+fn test_lua_golden_simple() {
+    test_lua_metrics(
+        "simple",
+        &[
+            ("simple", 3, 0, 0, 0),
+            ("single_branch", 4, 1, 0, 0),
+            ("if_else", 4, 1, 0, 2),
+            ("early_return", 4, 1, 0, 1),
+            // Each `elseif` is one more branch
+            ("sign", 5, 1, 0, 3),
+            ("describe", 6, 1, 1, 0),
+        ],
+    );
+}
+
+#[test]
+fn test_lua_golden_loops() {
+    test_lua_metrics(
+        "loops",
+        &[
+            ("sum", 4, 1, 1, 0),
+            ("count_up", 4, 1, 1, 0),
+            ("first_negative", 5, 2, 0, 1),
+            ("drain", 5, 2, 2, 1),
+            // `repeat ... until` runs its body before the test
+            ("countdown", 4, 1, 0, 0),
+            ("grid", 5, 2, 1, 0),
+        ],
+    );
+}
+
+#[test]
+fn test_lua_golden_boolean_ops() {
+    test_lua_metrics(
+        "boolean_ops",
+        &[
+            ("with_and", 5, 1, 1, 0),
+            ("with_or", 5, 1, 1, 0),
+            ("multiple_ops", 7, 1, 0, 1),
+            // `or` as a default value and `and` / `or` as a ternary branch too
+            ("default_name", 4, 0, 0, 0),
+            ("label", 5, 0, 0, 0),
+            ("nested_with_ops", 8, 3, 0, 1),
+        ],
+    );
+}
+
+#[test]
+fn test_lua_golden_specific() {
+    test_lua_metrics(
+        "lua_specific",
+        &[
+            ("Account.new", 4, 0, 1, 0),
+            // `error` raises
+            ("Account:withdraw", 4, 1, 1, 1),
+            // Named after its table field
+            ("start", 3, 0, 0, 0),
+            ("M.on_event", 4, 1, 2, 0),
+            ("M.retry", 5, 1, 1, 1),
+            // The comparator is a function of its own
+            ("M.sorted", 3, 0, 2, 0),
+            ("<anonymous>:46", 1, 0, 0, 0),
+            ("M.outer", 4, 1, 2, 0),
+            ("inner", 4, 1, 0, 1),
+        ],
+    );
+}
+
+#[test]
+fn test_lua_golden_determinism() {
+    let fixture = fixture_path("lua/lua_specific.lua");
+
+    let options = AnalysisOptions {
+        min_lrs: None,
+        top_n: None,
+    };
+    let reports1 = analyze(&fixture, options).unwrap();
+    let options = AnalysisOptions {
+        min_lrs: None,
+        top_n: None,
+    };
+    let reports2 = analyze(&fixture, options).unwrap();
+
+    let json1 = render_json(&reports1);
+    let json2 = render_json(&reports2);
+    assert_eq!(json1, json2, "Lua analysis is not deterministic");
+}
+
+// Bash golden tests
+
+/// (function, cc, nd, fo, ns)
+type BashMetrics = (&'static str, u32, u32, u32, u32);
+
+/// Check every function of a Bash fixture
+fn test_bash_metrics(fixture_name: &str, expected: &[BashMetrics]) {
+    let fixture = fixture_path(&format!("bash/{}.sh", fixture_name));
+    let reports = analyze(
+        &fixture,
+        AnalysisOptions {
+            min_lrs: None,
+            top_n: None,
+        },
+    )
+    .unwrap_or_else(|e| panic!("Failed to analyze {}: {}", fixture.display(), e));
+
+    assert_eq!(
+        reports.len(),
+        expected.len(),
+        "function count of bash/{}",
+        fixture_name
+    );
+    for &(name, cc, nd, fo, ns) in expected {
+        let report = reports
+            .iter()
+            .find(|r| r.function == name)
+            .unwrap_or_else(|| panic!("bash/{fixture_name} has no function {name}"));
+        let m = &report.metrics;
+        assert_eq!(
+            (m.cc, m.nd, m.fo, m.ns),
+            (cc, nd, fo, ns),
+            "(cc, nd, fo, ns) of {name} in bash/{fixture_name}"
+        );
+    }
+}
+
+#[test]
+fn test_bash_golden_simple() {
+    test_bash_metrics(
+        "simple",
+        &[
+            ("greet", 3, 0, 1, 0),
+            ("single_branch", 4, 1, 1, 0),
+            ("if_else", 4, 1, 1, 0),
+            ("early_return", 4, 1, 1, 1),
+            // Each `elif` is one more branch
+            ("sign", 5, 1, 1, 0),
+            // `function name` form; `|| exit` is a branch and `exit` a throw
+            ("require_root", 4, 0, 2, 1),
+        ],
+    );
+}
+
+#[test]
+fn test_bash_golden_loops() {
+    // The fixture has no shebang
+    test_bash_metrics(
+        "loops",
+        &[
+            ("print_all", 4, 1, 1, 0),
+            ("count_down", 4, 1, 0, 0),
+            ("first_missing", 5, 2, 1, 1),
+            // The loop is redirected from a file
+            ("skip_blank", 5, 1, 2, 1),
+            ("grid", 5, 2, 1, 0),
+            ("choose", 4, 1, 1, 1),
+        ],
+    );
+}
+
+#[test]
+fn test_bash_golden_boolean_ops() {
+    test_bash_metrics(
+        "boolean_ops",
+        &[
+            ("with_and", 5, 1, 1, 0),
+            ("with_or", 4, 0, 1, 0),
+            ("multiple_ops", 6, 1, 0, 1),
+            ("chained", 6, 0, 2, 0),
+        ],
+    );
+}
+
+#[test]
+fn test_bash_golden_deploy() {
+    test_bash_metrics(
+        "deploy",
+        &[
+            ("usage", 3, 0, 1, 1),
+            ("_log", 3, 0, 1, 0),
+            // No `*)`, so a value can match no clause
+            ("confirm", 5, 1, 1, 2),
+            // `exit` inside the subshell does not leave the function
+            ("deploy_to", 8, 2, 5, 3),
+            ("rollback", 4, 1, 3, 1),
+            // Each pipeline command runs in a subshell, so `return` there
+            // only ends the loop's subshell
+            ("find_release", 5, 2, 4, 0),
+            // Nine clauses, one of them the catch-all `*)`
+            ("main", 11, 1, 7, 0),
+        ],
+    );
+}
+
+#[test]
+fn test_bash_visibility_and_lines() {
+    let fixture = fixture_path("bash/deploy.sh");
+    let reports = analyze(
+        &fixture,
+        AnalysisOptions {
+            min_lrs: None,
+            top_n: None,
+        },
+    )
+    .unwrap();
+    let public: Vec<(&str, bool)> = reports
+        .iter()
+        .filter(|r| matches!(r.function.as_str(), "_log" | "main" | "find_release"))
+        .map(|r| (r.function.as_str(), r.is_public))
+        .collect();
+    assert!(public.contains(&("main", true)));
+    assert!(public.contains(&("_log", false)));
+    // Defined only once `rollback` runs
+    assert!(public.contains(&("find_release", false)));
+    let main = reports.iter().find(|r| r.function == "main").unwrap();
+    assert_eq!(main.line, 63);
+}
+
+#[test]
+fn test_bash_golden_determinism() {
+    let fixture = fixture_path("bash/deploy.sh");
+
+    let options = AnalysisOptions {
+        min_lrs: None,
+        top_n: None,
+    };
+    let reports1 = analyze(&fixture, options).unwrap();
+    let options = AnalysisOptions {
+        min_lrs: None,
+        top_n: None,
+    };
+    let reports2 = analyze(&fixture, options).unwrap();
+
+    let json1 = render_json(&reports1);
+    let json2 = render_json(&reports2);
+    assert_eq!(json1, json2, "Bash analysis is not deterministic");
+}
+
+// Zig golden tests
+
+/// (function, cc, nd, fo, ns)
+type ZigMetrics = (&'static str, u32, u32, u32, u32);
+
+/// Check every function of a Zig fixture
+fn test_zig_metrics(fixture_name: &str, expected: &[ZigMetrics]) {
+    let fixture = fixture_path(&format!("zig/{}.zig", fixture_name));
+    let reports = analyze(
+        &fixture,
+        AnalysisOptions {
+            min_lrs: None,
+            top_n: None,
+        },
+    )
+    .unwrap_or_else(|e| panic!("Failed to analyze {}: {}", fixture.display(), e));
+
+    assert_eq!(
+        reports.len(),
+        expected.len(),
+        "function count of zig/{}",
+        fixture_name
+    );
+    for &(name, cc, nd, fo, ns) in expected {
+        let report = reports
+            .iter()
+            .find(|r| r.function == name)
+            .unwrap_or_else(|| panic!("zig/{fixture_name} has no function {name}"));
+        let m = &report.metrics;
+        assert_eq!(
+            (m.cc, m.nd, m.fo, m.ns),
+            (cc, nd, fo, ns),
+            "(cc, nd, fo, ns) of {name} in zig/{fixture_name}"
+        );
+    }
+}
+
+#[test]
+fn test_zig_golden_simple() {
+    test_zig_metrics(
+        "simple",
+        &[
+            ("simple", 3, 0, 0, 0),
+            ("singleBranch", 4, 1, 0, 0),
+            ("ifElse", 4, 1, 0, 2),
+            ("earlyReturn", 4, 1, 0, 1),
+            // An `else if` continues the chain without nesting
+            ("sign", 5, 1, 0, 3),
+            // Both `if`s of the initializer count
+            ("describe", 5, 1, 1, 0),
+        ],
+    );
+}
+
+#[test]
+fn test_zig_golden_loops() {
+    test_zig_metrics(
+        "loops",
+        &[
+            ("simpleLoop", 4, 1, 0, 0),
+            ("loopWithCondition", 5, 2, 0, 0),
+            ("nestedLoops", 5, 2, 0, 0),
+            ("loopWithBreak", 5, 2, 0, 1),
+            ("loopWithContinue", 5, 2, 1, 1),
+            ("indexedLoop", 4, 1, 0, 0),
+            ("infiniteLoop", 5, 2, 0, 1),
+            ("contains", 5, 2, 0, 2),
+            ("totalLength", 4, 1, 0, 0),
+        ],
+    );
+}
+
+#[test]
+fn test_zig_golden_boolean_ops() {
+    test_zig_metrics(
+        "boolean_ops",
+        &[
+            ("log", 3, 0, 0, 0),
+            ("withAnd", 5, 1, 1, 0),
+            ("withOr", 5, 1, 1, 0),
+            ("multipleBooleanOps", 7, 1, 0, 1),
+            ("complexBooleanExpression", 7, 1, 0, 1),
+            ("nestedWithBooleanOps", 8, 3, 0, 1),
+            // Two `orelse`s; the `return` behind the second is an early exit
+            ("withOrelse", 5, 0, 0, 1),
+        ],
+    );
+}
+
+#[test]
+fn test_zig_golden_switch() {
+    test_zig_metrics(
+        "switch",
+        &[
+            ("Shape.area", 5, 1, 0, 0),
+            ("log", 3, 0, 0, 0),
+            // The `else` prong is not a branch of its own
+            ("simpleSwitch", 5, 1, 0, 3),
+            ("switchNoElse", 6, 1, 1, 0),
+            ("nestedSwitch", 7, 2, 1, 0),
+            ("classify", 6, 1, 0, 0),
+        ],
+    );
+}
+
+#[test]
+fn test_zig_golden_zig_specific() {
+    test_zig_metrics(
+        "zig_specific",
+        &[
+            ("Parser.next", 4, 1, 0, 1),
+            // `catch` and `try` are branches; `try` is an early return
+            ("Parser.parseNumber", 7, 2, 3, 3),
+            ("sumUntil", 7, 3, 0, 2),
+            ("firstEven", 5, 2, 1, 2),
+            ("checkSize", 4, 1, 2, 0),
+            ("fieldCount", 5, 2, 1, 0),
+            ("List", 3, 0, 1, 0),
+            ("List.get", 4, 1, 0, 0),
+            ("unwrapOrPanic", 4, 1, 1, 2),
+        ],
+    );
+}
+
+#[test]
+fn test_zig_visibility_and_owners() {
+    let fixture = fixture_path("zig/zig_specific.zig");
+    let reports = analyze(
+        &fixture,
+        AnalysisOptions {
+            min_lrs: None,
+            top_n: None,
+        },
+    )
+    .unwrap();
+    let public: Vec<(&str, bool)> = reports
+        .iter()
+        .map(|r| (r.function.as_str(), r.is_public))
+        .collect();
+    assert!(public.contains(&("Parser.next", true)));
+    assert!(public.contains(&("sumUntil", true)));
+    assert!(public.contains(&("firstEven", false)));
+    assert!(public.contains(&("List.get", true)));
+    let get = reports.iter().find(|r| r.function == "List.get").unwrap();
+    assert_eq!(get.owner.as_deref(), Some("List"));
+    let next = reports
+        .iter()
+        .find(|r| r.function == "Parser.next")
+        .unwrap();
+    assert_eq!(next.line, 9);
+}
+
+#[test]
+fn test_zig_golden_determinism() {
+    let fixture = fixture_path("zig/zig_specific.zig");
+
+    let options = AnalysisOptions {
+        min_lrs: None,
+        top_n: None,
+    };
+    let reports1 = analyze(&fixture, options).unwrap();
+    let options = AnalysisOptions {
+        min_lrs: None,
+        top_n: None,
+    };
+    let reports2 = analyze(&fixture, options).unwrap();
+
+    let json1 = render_json(&reports1);
+    let json2 = render_json(&reports2);
+    assert_eq!(json1, json2, "Zig analysis is not deterministic");
+}
+
+// Haskell golden tests
+
+/// (function, cc, nd, fo, ns)
+type HaskellMetrics = (&'static str, u32, u32, u32, u32);
+
+/// Check every function of a Haskell fixture
+fn test_haskell_metrics(fixture_name: &str, expected: &[HaskellMetrics]) {
+    let fixture = fixture_path(&format!("haskell/{}.hs", fixture_name));
+    let reports = analyze(
+        &fixture,
+        AnalysisOptions {
+            min_lrs: None,
+            top_n: None,
+        },
+    )
+    .unwrap_or_else(|e| panic!("Failed to analyze {}: {}", fixture.display(), e));
+
+    assert_eq!(
+        reports.len(),
+        expected.len(),
+        "function count of haskell/{}",
+        fixture_name
+    );
+    for &(name, cc, nd, fo, ns) in expected {
+        let report = reports
+            .iter()
+            .find(|r| r.function == name)
+            .unwrap_or_else(|| panic!("haskell/{fixture_name} has no function {name}"));
+        let m = &report.metrics;
+        assert_eq!(
+            (m.cc, m.nd, m.fo, m.ns),
+            (cc, nd, fo, ns),
+            "(cc, nd, fo, ns) of {name} in haskell/{fixture_name}"
+        );
+    }
+}
+
+#[test]
+fn test_haskell_golden_simple() {
+    test_haskell_metrics(
+        "simple",
+        &[
+            ("double", 3, 0, 0, 0),
+            ("sign", 4, 1, 0, 0),
+            // An `else if` continues the chain without nesting
+            ("classify", 5, 1, 0, 0),
+            ("inRange", 4, 0, 0, 0),
+            ("validUser", 5, 0, 2, 0),
+            // The `if` of a value bound by `where` counts
+            ("describe", 4, 1, 2, 0),
+            ("main", 3, 0, 2, 0),
+        ],
+    );
+}
+
+#[test]
+fn test_haskell_golden_guards() {
+    test_haskell_metrics(
+        "guards",
+        &[
+            // `otherwise` is not a branch of its own
+            ("bmi", 6, 1, 0, 0),
+            // Each equation after the first is a branch
+            ("fib", 6, 1, 1, 1),
+            ("factorial", 4, 0, 1, 0),
+            // The second condition of a guard counts like `&&`
+            ("grade", 7, 1, 0, 0),
+            ("lookupAge", 7, 1, 2, 0),
+            ("clamp", 5, 1, 0, 0),
+            ("isVowel", 4, 0, 1, 0),
+        ],
+    );
+}
+
+#[test]
+fn test_haskell_golden_case() {
+    test_haskell_metrics(
+        "case",
+        &[
+            ("area", 6, 1, 1, 0),
+            // The final `_` alternative is the default
+            ("describe", 6, 2, 0, 0),
+            ("parseCommand", 6, 1, 4, 1),
+            ("direction", 6, 1, 0, 0),
+            ("fizzbuzz", 6, 1, 2, 0),
+            ("combine", 7, 2, 0, 0),
+        ],
+    );
+}
+
+#[test]
+fn test_haskell_golden_do_notation() {
+    test_haskell_metrics(
+        "do_notation",
+        &[
+            // `exitFailure` leaves the function; `when` is not a call
+            ("main", 5, 1, 8, 1),
+            ("report", 5, 2, 2, 0),
+            ("safeRead", 4, 1, 3, 0),
+            // The lambda's `case` counts
+            ("processAll", 5, 1, 3, 0),
+        ],
+    );
+}
+
+#[test]
+fn test_haskell_golden_where_clauses() {
+    test_haskell_metrics(
+        "where_clauses",
+        &[
+            ("mean", 4, 1, 4, 0),
+            ("variance", 3, 0, 4, 0),
+            ("sumSquares", 4, 0, 2, 0),
+            ("square", 3, 0, 0, 0),
+            ("histogram", 3, 0, 1, 0),
+            // The equations and guard of the `let`-bound `bump` count
+            ("go", 6, 1, 3, 0),
+        ],
+    );
+}
+
+#[test]
+fn test_haskell_visibility_and_owners() {
+    let fixture = fixture_path("haskell/classes.hs");
+    let reports = analyze(
+        &fixture,
+        AnalysisOptions {
+            min_lrs: None,
+            top_n: None,
+        },
+    )
+    .unwrap();
+    let functions: Vec<(&str, Option<&str>, bool, u32)> = reports
+        .iter()
+        .map(|r| {
+            (
+                r.function.as_str(),
+                r.owner.as_deref(),
+                r.is_public,
+                r.metrics.cc,
+            )
+        })
+        .collect();
+    let expected = [
+        ("perimeter", Some("HasArea"), true, 3),
+        ("area", Some("Shape"), true, 4),
+        ("perimeter", Some("Shape"), true, 5),
+        ("describe", None, true, 4),
+        ("unitCircle", None, false, 3),
+    ];
+    assert_eq!(functions.len(), expected.len());
+    for function in expected {
+        assert!(functions.contains(&function), "missing {:?}", function);
+    }
+
+    let fixture = fixture_path("haskell/where_clauses.hs");
+    let reports = analyze(
+        &fixture,
+        AnalysisOptions {
+            min_lrs: None,
+            top_n: None,
+        },
+    )
+    .unwrap();
+    let public: Vec<(&str, bool)> = reports
+        .iter()
+        .map(|r| (r.function.as_str(), r.is_public))
+        .collect();
+    assert!(public.contains(&("mean", true)));
+    assert!(public.contains(&("sumSquares", false)));
+    assert!(public.contains(&("go", false)));
+
+    let fixture = fixture_path("haskell/guards.hs");
+    let reports = analyze(
+        &fixture,
+        AnalysisOptions {
+            min_lrs: None,
+            top_n: None,
+        },
+    )
+    .unwrap();
+    // A function's span runs from its first equation to its last
+    let fib = reports.iter().find(|r| r.function == "fib").unwrap();
+    assert_eq!(fib.line, 14);
+    assert_eq!(fib.metrics.loc, 5);
+}
+
+#[test]
+fn test_haskell_golden_determinism() {
+    let fixture = fixture_path("haskell/case.hs");
+
+    let options = AnalysisOptions {
+        min_lrs: None,
+        top_n: None,
+    };
+    let reports1 = analyze(&fixture, options).unwrap();
+    let options = AnalysisOptions {
+        min_lrs: None,
+        top_n: None,
+    };
+    let reports2 = analyze(&fixture, options).unwrap();
+
+    let json1 = render_json(&reports1);
+    let json2 = render_json(&reports2);
+    assert_eq!(json1, json2, "Haskell analysis is not deterministic");
+}
+
+// Perl golden tests
+
+/// (function, cc, nd, fo, ns)
+type PerlMetrics = (&'static str, u32, u32, u32, u32);
+
+/// Check every sub of a Perl fixture. Anonymous subs passed as arguments are
+/// named `<anonymous>:<line>`.
+fn test_perl_metrics(fixture_name: &str, expected: &[PerlMetrics]) {
+    let fixture = fixture_path(&format!("perl/{}", fixture_name));
+    let reports = analyze(
+        &fixture,
+        AnalysisOptions {
+            min_lrs: None,
+            top_n: None,
+        },
+    )
+    .unwrap_or_else(|e| panic!("Failed to analyze {}: {}", fixture.display(), e));
+
+    assert_eq!(
+        reports.len(),
+        expected.len(),
+        "function count of perl/{}",
+        fixture_name
+    );
+    let display_name = |r: &hotspots_core::FunctionRiskReport| {
+        if r.function.starts_with("<anonymous>@") {
+            format!("<anonymous>:{}", r.line)
+        } else {
+            r.function.clone()
+        }
+    };
+    for &(name, cc, nd, fo, ns) in expected {
+        let report = reports
+            .iter()
+            .find(|r| display_name(r) == name)
+            .unwrap_or_else(|| panic!("perl/{fixture_name} has no sub {name}"));
+        let m = &report.metrics;
+        assert_eq!(
+            (m.cc, m.nd, m.fo, m.ns),
+            (cc, nd, fo, ns),
+            "(cc, nd, fo, ns) of {name} in perl/{fixture_name}"
+        );
+    }
+}
+
+#[test]
+fn test_perl_golden_simple() {
+    test_perl_metrics(
+        "simple.pl",
+        &[
+            ("simple", 3, 0, 0, 0),
+            ("single_branch", 4, 1, 0, 0),
+            ("if_else", 4, 1, 0, 2),
+            // Each `elsif` is one more branch
+            ("sign", 5, 1, 0, 3),
+            ("describe", 6, 1, 1, 0),
+            ("with_default", 4, 1, 1, 0),
+        ],
+    );
+}
+
+#[test]
+fn test_perl_golden_loops() {
+    test_perl_metrics(
+        "loops.pl",
+        &[
+            ("sum", 4, 1, 1, 0),
+            ("count_up", 4, 1, 1, 0),
+            ("first_negative", 5, 2, 0, 1),
+            // `last if ...` is a branch and a break, but does not nest
+            ("drain", 5, 1, 3, 1),
+            ("countdown", 4, 1, 0, 0),
+            ("grid", 6, 2, 1, 1),
+        ],
+    );
+}
+
+#[test]
+fn test_perl_golden_boolean_ops() {
+    test_perl_metrics(
+        "boolean_ops.pl",
+        &[
+            ("with_and", 5, 1, 0, 1),
+            ("with_or", 5, 1, 1, 0),
+            // `or die` is a branch and an exit; `die` is not a call
+            ("word_ops", 5, 0, 3, 1),
+            ("default_name", 4, 0, 0, 0),
+            ("label", 4, 0, 0, 0),
+            ("nested_with_ops", 9, 3, 0, 1),
+        ],
+    );
+}
+
+#[test]
+fn test_perl_golden_statement_modifiers() {
+    test_perl_metrics(
+        "modifiers.pl",
+        &[
+            // `defined` is an operator, not a call
+            ("check", 7, 0, 1, 2),
+            ("retry", 5, 0, 1, 0),
+            ("collect", 6, 1, 2, 2),
+            ("log_if_verbose", 4, 0, 2, 0),
+        ],
+    );
+}
+
+#[test]
+fn test_perl_golden_subs() {
+    test_perl_metrics(
+        "subs.pm",
+        &[
+            ("by_name", 3, 0, 1, 0),
+            ("new", 4, 0, 1, 0),
+            // The anonymous `$check` is a sub of its own
+            ("register", 5, 0, 3, 1),
+            ("check", 4, 0, 1, 0),
+            // So is the named `area_of` defined inside it
+            ("total_area", 4, 1, 2, 0),
+            ("area_of", 4, 0, 0, 1),
+            ("on_error", 4, 0, 1, 0),
+            ("<anonymous>:51", 3, 0, 1, 0),
+            ("clamp", 5, 0, 0, 2),
+            ("_reset", 3, 0, 1, 0),
+        ],
+    );
+}
+
+#[test]
+fn test_perl_packages_and_visibility() {
+    let fixture = fixture_path("perl/subs.pm");
+    let reports = analyze(
+        &fixture,
+        AnalysisOptions {
+            min_lrs: None,
+            top_n: None,
+        },
+    )
+    .unwrap();
+    let functions: Vec<(&str, Option<&str>, bool)> = reports
+        .iter()
+        .map(|r| (r.function.as_str(), r.owner.as_deref(), r.is_public))
+        .collect();
+    let registry = Some("Shapes::Registry");
+    for expected in [
+        // A lexical `my $by_name = sub { ... }` is not part of the package
+        ("by_name", registry, false),
+        ("new", registry, true),
+        ("register", registry, true),
+        ("check", registry, false),
+        ("area_of", registry, false),
+        ("clamp", Some("Shapes::Util"), true),
+        // The block form of `package` ends with its block
+        ("_reset", registry, false),
+    ] {
+        assert!(functions.contains(&expected), "missing {:?}", expected);
+    }
+}
+
+#[test]
+fn test_perl_golden_determinism() {
+    let fixture = fixture_path("perl/subs.pm");
+
+    let options = AnalysisOptions {
+        min_lrs: None,
+        top_n: None,
+    };
+    let reports1 = analyze(&fixture, options).unwrap();
+    let options = AnalysisOptions {
+        min_lrs: None,
+        top_n: None,
+    };
+    let reports2 = analyze(&fixture, options).unwrap();
+
+    let json1 = render_json(&reports1);
+    let json2 = render_json(&reports2);
+    assert_eq!(json1, json2, "Perl analysis is not deterministic");
+}
+
+// Cognitive complexity tests
+
+/// Cognitive complexity per function of `go/boolean_ops.go`
+const GO_BOOLEAN_OPS_COGNITIVE: &[(&str, u32)] = &[
+    ("WithAnd", 2),
+    ("WithOr", 2),
+    ("MultipleBooleanOps", 3),
+    ("ComplexBooleanExpression", 4),
+    ("NestedWithBooleanOps", 8),
+    ("SwitchWithBooleanOps", 3),
+    ("LoopWithBooleanOps", 5),
+    ("DeeplyNested", 15),
+    ("PathologicalComplexity", 25),
+];
+
+/// `go/boolean_ops.go` and its port to every other language
+const BOOLEAN_OPS_MIRRORS: &[&str] = &[
+    "go/boolean_ops.go",
+    "cognitive/boolean_ops.ts",
+    "cognitive/BooleanOps.java",
+    "cognitive/boolean_ops.py",
+    "cognitive/BooleanOps.cs",
+    "cognitive/boolean_ops.c",
+    "cognitive/boolean_ops.rs",
+    "cognitive/boolean_ops.swift",
+    "cognitive/boolean_ops.php",
+    "cognitive/boolean_ops.cpp",
+];
+
+/// Every language scores the same code shape the same, even where CC counts
+/// switch cases or boolean operators differently
+#[test]
+fn test_cognitive_mirrors_go_boolean_ops() {
+    for fixture_name in BOOLEAN_OPS_MIRRORS {
+        let fixture = fixture_path(fixture_name);
+        let reports = analyze(
+            &fixture,
+            AnalysisOptions {
+                min_lrs: None,
+                top_n: None,
+            },
+        )
+        .unwrap_or_else(|e| panic!("Failed to analyze {}: {}", fixture.display(), e));
+        for (name, expected) in GO_BOOLEAN_OPS_COGNITIVE {
+            let report = reports
+                .iter()
+                .find(|r| r.function == *name)
+                .unwrap_or_else(|| panic!("{fixture_name} has no function {name}"));
+            assert_eq!(
+                report.metrics.cognitive, *expected,
+                "cognitive complexity of {name} in {fixture_name}"
+            );
+        }
+    }
+}
+
+/// `(a > 0 && b > 0) || (c > 0 && d > 0)` is one condition with three
+/// operators in every language, parentheses or not
+#[test]
+fn test_max_condition_ops_complex_boolean_expression() {
+    for fixture_name in BOOLEAN_OPS_MIRRORS {
+        let fixture = fixture_path(fixture_name);
+        let reports = analyze(
+            &fixture,
+            AnalysisOptions {
+                min_lrs: None,
+                top_n: None,
+            },
+        )
+        .unwrap_or_else(|e| panic!("Failed to analyze {}: {}", fixture.display(), e));
+        let report = reports
+            .iter()
+            .find(|r| r.function == "ComplexBooleanExpression")
+            .unwrap_or_else(|| panic!("{fixture_name} has no function ComplexBooleanExpression"));
+        assert_eq!(
+            report.metrics.max_condition_ops, 3,
+            "max_condition_ops of ComplexBooleanExpression in {fixture_name}"
+        );
+    }
+}
+
+/// A flat switch is cheap to read but expensive in CC; deep nesting is the
+/// opposite
+#[test]
+fn test_cognitive_diverges_from_cc() {
+    let reports = analyze(
+        &fixture_path("go/boolean_ops.go"),
+        AnalysisOptions {
+            min_lrs: None,
+            top_n: None,
+        },
+    )
+    .unwrap();
+    let metrics = |name: &str| {
+        let m = &reports.iter().find(|r| r.function == name).unwrap().metrics;
+        (m.cc, m.cognitive)
+    };
+    let (switch_cc, switch_cognitive) = metrics("SwitchWithBooleanOps");
+    let (nested_cc, nested_cognitive) = metrics("DeeplyNested");
+    assert!(switch_cc > switch_cognitive);
+    assert!(nested_cognitive > nested_cc);
+}
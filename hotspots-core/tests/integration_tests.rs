@@ -1,6 +1,10 @@
 //! Integration tests for hotspots analysis
 
-use hotspots_core::{analyze, analyze_with_progress, render_json, AnalysisOptions};
+use hotspots_core::language::Language;
+use hotspots_core::{
+    aggregates, analyze, analyze_path, analyze_source, analyze_with_config, analyze_with_progress,
+    git, render_json, snapshot, treemap, AnalysisOptions, AnalyzeOptions,
+};
 use std::path::PathBuf;
 use std::sync::{Arc, Mutex};
 
@@ -126,6 +130,50 @@ fn test_deterministic_output() {
     assert_eq!(json1, json2, "Output should be byte-for-byte identical");
 }
 
+/// Functions with identical metrics come out by file, then line, then name,
+/// for the default order and for a re-sort by another metric, however the
+/// reports were ordered before
+#[test]
+fn test_ties_ordered_by_location() {
+    use hotspots_core::report::{sort_reports_by, SortMetric};
+
+    let temp = tempfile::tempdir().unwrap();
+    let same =
+        "function f(x: number): number {\n  if (x > 0) {\n    return x;\n  }\n  return -x;\n}\n";
+    for name in ["c.ts", "a.ts", "b.ts"] {
+        let source = format!("{same}\n{}", same.replace("function f", "function g"));
+        std::fs::write(temp.path().join(name), source).unwrap();
+    }
+    let options = AnalysisOptions {
+        min_lrs: None,
+        top_n: None,
+    };
+    let reports = analyze(temp.path(), options).unwrap();
+    let order = |reports: &[hotspots_core::FunctionRiskReport]| -> Vec<(String, u32)> {
+        reports
+            .iter()
+            .map(|r| {
+                let file = std::path::Path::new(&r.file).file_name().unwrap();
+                (file.to_string_lossy().into_owned(), r.line)
+            })
+            .collect()
+    };
+    let expected: Vec<(String, u32)> = ["a.ts", "b.ts", "c.ts"]
+        .iter()
+        .flat_map(|f| [(f.to_string(), 1), (f.to_string(), 8)])
+        .collect();
+    assert_eq!(order(&reports), expected);
+
+    for ascending in [false, true] {
+        let mut forward = reports.clone();
+        let mut backward: Vec<_> = reports.iter().rev().cloned().collect();
+        sort_reports_by(&mut forward, SortMetric::Cc, ascending);
+        sort_reports_by(&mut backward, SortMetric::Cc, ascending);
+        assert_eq!(order(&forward), expected);
+        assert_eq!(render_json(&forward), render_json(&backward));
+    }
+}
+
 /// Angular-style TypeScript with class decorators (@Component, @Injectable, @Input)
 /// must parse without error and produce function reports with correct metrics.
 /// Decorators must not inflate CC or be counted as functions.
@@ -304,3 +352,1664 @@ fn test_whitespace_invariance() {
     assert_eq!(lrs1, lrs2);
     assert_eq!(cc1, cc2);
 }
+
+/// Go blank identifiers: `_ = f()` still counts the call to `f`; `_ = x`
+/// contributes nothing to any metric.
+#[test]
+fn test_go_blank_identifiers() {
+    let path = fixture_path("go/blank_identifiers.go");
+    let options = AnalysisOptions {
+        min_lrs: None,
+        top_n: None,
+    };
+
+    let reports = analyze(&path, options).unwrap();
+    let get = |name: &str| reports.iter().find(|r| r.function == name).unwrap();
+
+    assert_eq!(get("discardCall").metrics.fo, 1);
+    let value = get("discardValue");
+    assert_eq!(value.metrics.cc, 1);
+    assert_eq!(value.metrics.nd, 0);
+    assert_eq!(value.metrics.fo, 0);
+    assert_eq!(value.metrics.ns, 0);
+    assert_eq!(value.metrics.cc, get("compute").metrics.cc);
+    assert_eq!(get("discardMany").metrics.fo, 2);
+}
+
+/// PL/pgSQL: each `CASE WHEN`, the `FOR ... LOOP`, `IF`/`ELSIF`, `AND`/`OR`, and
+/// the `EXCEPTION WHEN` handler are decision points; `BETWEEN ... AND` is not.
+#[test]
+fn test_sql_plpgsql_case_and_loop() {
+    let path = fixture_path("sql/plpgsql_case_loop.sql");
+    let options = AnalysisOptions {
+        min_lrs: None,
+        top_n: None,
+    };
+
+    let reports = analyze(&path, options).unwrap();
+    assert_eq!(reports.len(), 2);
+    let get = |name: &str| reports.iter().find(|r| r.function == name).unwrap();
+
+    let discount = get("billing.apply_discount");
+    assert_eq!(discount.language, Language::Sql);
+    assert_eq!(discount.line, 3);
+    // 1 + FOR + AND + 2 WHEN + OR + IF + ELSIF + EXCEPTION WHEN
+    assert_eq!(discount.metrics.cc, 9);
+    // CASE inside the loop
+    assert_eq!(discount.metrics.nd, 2);
+    assert_eq!(discount.metrics.fo, 1);
+    assert_eq!(discount.metrics.ns, 2);
+    assert_eq!(discount.metrics.loc, 33);
+
+    let count = get("billing.order_count");
+    assert_eq!(count.metrics.cc, 1);
+    assert_eq!(count.metrics.nd, 0);
+}
+
+/// T-SQL: `WHILE`, `IF`, and `BEGIN CATCH` branch; `EXEC` targets are callees.
+#[test]
+fn test_sql_tsql_procedure() {
+    let path = fixture_path("sql/tsql_procedures.sql");
+    let options = AnalysisOptions {
+        min_lrs: None,
+        top_n: None,
+    };
+
+    let reports = analyze(&path, options).unwrap();
+    assert_eq!(reports.len(), 1);
+    let proc = &reports[0];
+    assert_eq!(proc.function, "dbo.ProcessQueue");
+    assert_eq!(proc.metrics.cc, 4);
+    assert_eq!(proc.metrics.nd, 2);
+    assert_eq!(proc.metrics.fo, 2);
+    assert_eq!(proc.metrics.ns, 2);
+    assert_eq!(proc.metrics.loc, 24);
+}
+
+/// The treemap of a fixture directory nests files under it and rolls their
+/// functions' LOC up to the root.
+#[test]
+fn test_treemap_fixture_directory() {
+    let path = fixture_path("sql");
+    let options = AnalysisOptions {
+        min_lrs: None,
+        top_n: None,
+    };
+
+    let reports = analyze(&path, options).unwrap();
+    let root = treemap::build_treemap(&reports, &path);
+    assert_eq!(root.name, "sql");
+    let files: Vec<&str> = root.children.iter().map(|c| c.name.as_str()).collect();
+    assert_eq!(files, vec!["plpgsql_case_loop.sql", "tsql_procedures.sql"]);
+    assert_eq!(root.children[0].children.len(), 2);
+
+    let total_loc: u64 = reports.iter().map(|r| u64::from(r.metrics.loc)).sum();
+    assert_eq!(root.size, total_loc);
+    assert_eq!(root.size, root.children.iter().map(|f| f.size).sum::<u64>());
+    let max_lrs = reports.iter().map(|r| r.lrs).fold(0.0, f64::max);
+    assert_eq!(root.max_risk, max_lrs);
+}
+
+/// Mutual recursion across two files and direct recursion are both flagged,
+/// with the cycle's members; a caller of the cycle is not.
+#[test]
+fn test_recursion_fixture_cycles() {
+    let path = fixture_path("recursion");
+    let options = AnalysisOptions {
+        min_lrs: None,
+        top_n: None,
+    };
+    let reports = analyze(&path, options).unwrap();
+    let graph = hotspots_core::build_call_graph(&reports, &path).unwrap();
+
+    let git_context = git::GitContext {
+        head_sha: "abc123".to_string(),
+        parent_shas: vec![],
+        timestamp: 1705600000,
+        branch: None,
+        is_detached: false,
+        message: None,
+        author: None,
+        is_fix_commit: None,
+        is_revert_commit: None,
+        ticket_ids: vec![],
+    };
+    let mut snapshot = snapshot::Snapshot::new(git_context, reports);
+    snapshot.populate_callgraph(&graph, 1000, 256);
+    let callgraph = |name: &str| {
+        snapshot
+            .functions
+            .iter()
+            .find(|f| f.function_id.ends_with(&format!("::{name}")))
+            .and_then(|f| f.callgraph.clone())
+            .unwrap_or_else(|| panic!("no call graph metrics for {name}"))
+    };
+    let id = |file: &str, name: &str| format!("{}::{name}", path.join(file).display());
+
+    let is_even = callgraph("isEven");
+    let is_odd = callgraph("isOdd");
+    assert!(is_even.recursive && is_odd.recursive);
+    let expected = vec![id("even.ts", "isEven"), id("odd.ts", "isOdd")];
+    assert_eq!(is_even.cycle, expected);
+    assert_eq!(is_odd.cycle, expected);
+
+    let factorial = callgraph("factorial");
+    assert!(factorial.recursive);
+    assert_eq!(factorial.cycle, vec![id("even.ts", "factorial")]);
+    assert_eq!(factorial.fan_in, 0, "a self-call is not a caller");
+
+    let parity = callgraph("parity");
+    assert!(!parity.recursive);
+    assert!(parity.cycle.is_empty());
+}
+
+/// A Go call into another file of the same package resolves to that file,
+/// not to a namesake elsewhere; a standard library call counts as external.
+#[test]
+fn test_cross_file_fixture_resolves_within_package() {
+    let path = fixture_path("cross-file");
+    let options = AnalysisOptions {
+        min_lrs: None,
+        top_n: None,
+    };
+    let reports = analyze(&path, options).unwrap();
+    let graph = hotspots_core::build_call_graph(&reports, &path).unwrap();
+    let id = |file: &str, name: &str| format!("{}::{name}", path.join(file).display());
+
+    let top = id("app/top.go", "top");
+    let callees: Vec<&str> = graph.callees_of(&top).unwrap().collect();
+    assert_eq!(callees, vec![id("app/middle.go", "middle")]);
+    assert_eq!(graph.fan_in(&id("app/middle.go", "middle")), 1);
+    assert_eq!(graph.fan_in(&id("alt/middle.go", "middle")), 0);
+    assert_eq!(graph.fan_in(&id("app/middle.go", "helper")), 1);
+
+    assert_eq!(graph.external_calls(&top), 1);
+    assert_eq!(graph.external_calls(&id("app/middle.go", "middle")), 0);
+}
+
+/// Go package- and receiver-qualified calls (`store.New()`, `c.store.Get()`,
+/// `c.Lookup()`) resolve against `Type.Method` names; only the standard
+/// library call stays external.
+#[test]
+fn test_cross_file_fixture_resolves_selector_calls() {
+    let path = fixture_path("cross-file");
+    let options = AnalysisOptions {
+        min_lrs: None,
+        top_n: None,
+    };
+    let reports = analyze(&path, options).unwrap();
+    let graph = hotspots_core::build_call_graph(&reports, &path).unwrap();
+    let id = |file: &str, name: &str| format!("{}::{name}", path.join(file).display());
+
+    let new_client = id("app/client.go", "NewClient");
+    let callees: Vec<&str> = graph.callees_of(&new_client).unwrap().collect();
+    assert_eq!(callees, vec![id("store/store.go", "New")]);
+    assert_eq!(graph.external_calls(&new_client), 0);
+
+    let lookup = id("app/client.go", "Client.Lookup");
+    let callees: Vec<&str> = graph.callees_of(&lookup).unwrap().collect();
+    assert_eq!(callees, vec![id("store/store.go", "Store.Get")]);
+    assert_eq!(graph.external_calls(&lookup), 1);
+
+    let has = id("app/client.go", "Client.Has");
+    let callees: Vec<&str> = graph.callees_of(&has).unwrap().collect();
+    assert_eq!(callees, vec![lookup.clone()]);
+    assert_eq!(graph.fan_in(&id("store/store.go", "Store.Get")), 1);
+}
+
+/// `file_cc` treats a multi-function file as one unit: 1 + Σ(cc − 1), plus
+/// the decision points outside every function. Go has no statements outside
+/// functions; the TS and Python fixtures branch at module (and class) scope,
+/// and the C# one in top-level statements.
+#[test]
+fn test_file_cc_multi_function_fixture() {
+    for (fixture, top_level) in [
+        ("go/simple.go", 0),
+        ("file-cc/module_level.ts", 3),
+        ("file-cc/module_level.py", 4),
+        ("file-cc/top_level.cs", 3),
+    ] {
+        let path = fixture_path(fixture);
+        let options = AnalysisOptions {
+            min_lrs: None,
+            top_n: None,
+        };
+        let reports = analyze(&path, options).unwrap();
+        assert!(
+            reports.len() > 1,
+            "{fixture} should have multiple functions"
+        );
+        assert!(
+            reports
+                .iter()
+                .all(|r| r.file_top_level_decisions == top_level),
+            "{fixture}: top-level decision points"
+        );
+        let expected = 1
+            + top_level
+            + reports
+                .iter()
+                .map(|r| r.metrics.cc as usize - 1)
+                .sum::<usize>();
+        let sum_cc: usize = reports.iter().map(|r| r.metrics.cc as usize).sum();
+
+        let git_context = git::GitContext {
+            head_sha: "abc123".to_string(),
+            parent_shas: vec![],
+            timestamp: 1705600000,
+            branch: Some("main".to_string()),
+            is_detached: false,
+            message: None,
+            author: None,
+            is_fix_commit: None,
+            is_revert_commit: None,
+            ticket_ids: vec![],
+        };
+        let snapshot = snapshot::Snapshot::new(git_context, reports);
+        let views =
+            aggregates::compute_file_risk_views(&snapshot.functions, &snapshot.top_level_decisions);
+
+        assert_eq!(views.len(), 1);
+        assert_eq!(views[0].file_cc, expected, "{fixture}: file_cc");
+        if top_level == 0 {
+            assert!(
+                views[0].file_cc < sum_cc,
+                "file_cc must differ from the plain sum of function CCs"
+            );
+        }
+    }
+}
+
+/// Rust metrics come from the written function only: outer attributes, doc
+/// comments and macro arguments add nothing. The fixture's `Attributed` and
+/// `Plain` impls have identical bodies and differ only in attributes.
+#[test]
+fn test_rust_attributes_do_not_affect_metrics() {
+    let path = fixture_path("rust/attributes.rs");
+    let options = AnalysisOptions {
+        min_lrs: None,
+        top_n: None,
+    };
+
+    let reports = analyze(&path, options).unwrap();
+    let metrics = |name: &str| {
+        reports
+            .iter()
+            .find(|r| r.function == name)
+            .unwrap_or_else(|| panic!("missing {name}"))
+            .metrics
+            .clone()
+    };
+
+    for method in ["new", "sum_positive", "check"] {
+        let attributed = metrics(&format!("Attributed::{method}"));
+        let plain = metrics(&format!("Plain::{method}"));
+        assert_eq!(attributed.cc, plain.cc, "{method}: cc");
+        assert_eq!(attributed.nd, plain.nd, "{method}: nd");
+        assert_eq!(attributed.fo, plain.fo, "{method}: fo");
+        assert_eq!(attributed.ns, plain.ns, "{method}: ns");
+        assert_eq!(attributed.loc, plain.loc, "{method}: loc");
+    }
+
+    // LOC spans `pub fn` to the closing brace; attribute and doc lines are excluded
+    assert_eq!(metrics("Attributed::new").loc, 3);
+    assert_eq!(metrics("Attributed::sum_positive").loc, 9);
+    assert_eq!(metrics("Attributed::check").loc, 4);
+}
+
+/// Signature complexity counts type parameters plus type nesting depth, and is
+/// reported (and serialized) only when non-zero.
+#[test]
+fn test_rust_signature_complexity() {
+    let path = fixture_path("rust/generics.rs");
+    let options = AnalysisOptions {
+        min_lrs: None,
+        top_n: None,
+    };
+
+    let reports = analyze(&path, options).unwrap();
+    let signature = |name: &str| {
+        reports
+            .iter()
+            .find(|r| r.function == name)
+            .unwrap_or_else(|| panic!("missing {name}"))
+            .metrics
+            .signature_complexity
+    };
+
+    assert_eq!(signature("plain"), 0);
+    assert_eq!(signature("single_generic"), 1);
+    // <K, V, E> + Result<HashMap<K, Vec<Option<Box<V>>>>, E>
+    assert_eq!(signature("load_index"), 8);
+    let default_threshold = hotspots_core::sarif::MetricRules::default()
+        .signature_complexity
+        .threshold;
+    assert!(signature("load_index") >= default_threshold);
+
+    let json: serde_json::Value = serde_json::from_str(&render_json(&reports)).unwrap();
+    let metrics = |name: &str| {
+        json.as_array()
+            .unwrap()
+            .iter()
+            .find(|r| r["function"] == name)
+            .unwrap()["metrics"]
+            .clone()
+    };
+    assert!(metrics("plain").get("signature_complexity").is_none());
+    assert_eq!(metrics("load_index")["signature_complexity"], 8);
+}
+
+/// Guard-heavy and nesting-heavy versions of the same function share a CC but
+/// get opposite structure indicators.
+#[test]
+fn test_guard_clauses_structure_quality() {
+    use hotspots_core::patterns::StructureQuality;
+
+    for (fixture, guarded, nested) in [
+        ("guard-clauses.ts", "saveGuarded", "saveNested"),
+        ("python/guard_clauses.py", "save_guarded", "save_nested"),
+    ] {
+        let path = fixture_path(fixture);
+        let options = AnalysisOptions {
+            min_lrs: None,
+            top_n: None,
+        };
+
+        let reports = analyze(&path, options).unwrap();
+        let find = |name: &str| {
+            reports
+                .iter()
+                .find(|r| r.function == name)
+                .unwrap_or_else(|| panic!("missing {name} in {fixture}"))
+        };
+        let (guarded_report, nested_report) = (find(guarded), find(nested));
+
+        assert_eq!(guarded_report.metrics.cc, 6, "{fixture}");
+        assert_eq!(nested_report.metrics.cc, 6, "{fixture}");
+        // Three leading guards plus the `continue` guard inside the loop
+        assert_eq!(guarded_report.metrics.guard_clauses, 4, "{fixture}");
+        assert_eq!(guarded_report.metrics.nd, 2, "{fixture}");
+        assert_eq!(nested_report.metrics.guard_clauses, 0, "{fixture}");
+        assert_eq!(nested_report.metrics.nd, 5, "{fixture}");
+        assert_eq!(
+            guarded_report.structure,
+            Some(StructureQuality::EarlyReturn),
+            "{fixture}"
+        );
+        assert_eq!(
+            nested_report.structure,
+            Some(StructureQuality::DeeplyNested),
+            "{fixture}"
+        );
+
+        let json: serde_json::Value = serde_json::from_str(&render_json(&reports)).unwrap();
+        let entry = |name: &str| {
+            json.as_array()
+                .unwrap()
+                .iter()
+                .find(|r| r["function"] == name)
+                .unwrap()
+                .clone()
+        };
+        assert_eq!(entry(guarded)["metrics"]["guard_clauses"], 4);
+        assert_eq!(entry(guarded)["structure"], "early_return");
+        assert!(entry(nested)["metrics"].get("guard_clauses").is_none());
+        assert_eq!(entry(nested)["structure"], "deeply_nested");
+    }
+}
+
+#[test]
+fn test_graphql_resolvers_grouped_by_type() {
+    let dir = fixture_path("graphql");
+    let options = AnalysisOptions {
+        min_lrs: None,
+        top_n: None,
+    };
+    let reports = analyze(&dir, options).unwrap();
+    let sdl = std::fs::read_to_string(dir.join("schema.graphql")).unwrap();
+
+    let map =
+        hotspots_core::graphql::compute_resolver_map(&dir, &reports, None, Some(&sdl)).unwrap();
+    let fields = |type_name: &str| -> Vec<(String, Option<bool>)> {
+        let mut fields: Vec<(String, Option<bool>)> = map
+            .types
+            .iter()
+            .find(|t| t.type_name == type_name)
+            .unwrap_or_else(|| panic!("missing type {type_name}"))
+            .fields
+            .iter()
+            .map(|f| (f.field.clone(), f.in_schema))
+            .collect();
+        fields.sort();
+        fields
+    };
+
+    let names: Vec<&str> = map.types.iter().map(|t| t.type_name.as_str()).collect();
+    assert_eq!(names.len(), 3, "types: {names:?}");
+    assert_eq!(
+        fields("Query"),
+        vec![
+            ("user".to_string(), Some(true)),
+            ("users".to_string(), Some(true)),
+            ("viewer".to_string(), Some(true)),
+        ]
+    );
+    assert_eq!(
+        fields("Mutation"),
+        vec![("banUser".to_string(), Some(true))]
+    );
+    // `nickname` is implemented but not declared in the schema
+    assert_eq!(
+        fields("User"),
+        vec![
+            ("fullName".to_string(), Some(true)),
+            ("initials".to_string(), Some(true)),
+            ("nickname".to_string(), Some(false)),
+        ]
+    );
+
+    // Resolvers carry the metrics of the function that implements them, not of
+    // callbacks nested inside it
+    let query = map.types.iter().find(|t| t.type_name == "Query").unwrap();
+    let user = query.fields.iter().find(|f| f.field == "user").unwrap();
+    assert_eq!(user.function, "user");
+    assert!(user.metrics.cc >= 4);
+    let users = query.fields.iter().find(|f| f.field == "users").unwrap();
+    assert!(users.file.ends_with("resolvers.ts"));
+    let viewer = query.fields.iter().find(|f| f.field == "viewer").unwrap();
+    assert_eq!(viewer.function, "queryResolver.Viewer");
+
+    // Helpers outside the resolver map are not tagged
+    assert!(map
+        .types
+        .iter()
+        .flat_map(|t| &t.fields)
+        .all(|f| f.function != "formatName" && f.function != "helper"));
+}
+
+#[test]
+fn test_graphql_resolver_glob_restricts_files() {
+    let dir = fixture_path("graphql");
+    let options = AnalysisOptions {
+        min_lrs: None,
+        top_n: None,
+    };
+    let reports = analyze(&dir, options).unwrap();
+
+    let map =
+        hotspots_core::graphql::compute_resolver_map(&dir, &reports, Some("*.go"), None).unwrap();
+    let mut names: Vec<&str> = map.types.iter().map(|t| t.type_name.as_str()).collect();
+    names.sort();
+    assert_eq!(names, vec!["Query", "User"]);
+    assert!(map
+        .types
+        .iter()
+        .flat_map(|t| &t.fields)
+        .all(|f| f.in_schema.is_none() && f.file.ends_with(".go")));
+}
+
+#[cfg(unix)]
+#[test]
+fn test_dedup_symlinks_counts_function_once() {
+    use std::os::unix::fs::symlink;
+
+    let temp = tempfile::tempdir().unwrap();
+    let root = temp.path().join("repo");
+    let src = root.join("src");
+    std::fs::create_dir_all(&src).unwrap();
+    std::fs::write(
+        src.join("shared.ts"),
+        "export function shared(x: number): number {\n  if (x > 0) {\n    return x;\n  }\n  return -x;\n}\n",
+    )
+    .unwrap();
+    std::fs::create_dir_all(root.join("packages/app")).unwrap();
+    symlink(src.join("shared.ts"), root.join("packages/app/shared.ts")).unwrap();
+    symlink(&src, root.join("packages/lib")).unwrap();
+
+    let options = || AnalysisOptions {
+        min_lrs: None,
+        top_n: None,
+    };
+
+    // Default discovery skips symlinks, so only the real file is seen
+    let reports = analyze(&root, options()).unwrap();
+    assert_eq!(reports.len(), 1);
+    assert!(reports[0].aliases.is_empty());
+
+    let mut config = hotspots_core::ResolvedConfig::defaults().unwrap();
+    config.dedup_symlinks = true;
+    let reports = analyze_with_config(&root, options(), Some(&config)).unwrap();
+    assert_eq!(
+        reports.len(),
+        1,
+        "symlinked duplicates must be counted once"
+    );
+    let report = &reports[0];
+    assert_eq!(report.function, "shared");
+    assert_eq!(
+        report.file,
+        src.join("shared.ts").to_string_lossy(),
+        "reported under the real path"
+    );
+    let expected: Vec<String> = [
+        root.join("packages/app/shared.ts"),
+        root.join("packages/lib/shared.ts"),
+    ]
+    .iter()
+    .map(|p| p.to_string_lossy().to_string())
+    .collect();
+    assert_eq!(report.aliases, expected);
+}
+
+#[cfg(unix)]
+#[test]
+fn test_dedup_symlinks_follows_links_out_of_tree() {
+    use std::os::unix::fs::symlink;
+
+    let temp = tempfile::tempdir().unwrap();
+    let shared = temp.path().join("shared");
+    let root = temp.path().join("repo");
+    std::fs::create_dir_all(&shared).unwrap();
+    std::fs::create_dir_all(&root).unwrap();
+    std::fs::write(
+        shared.join("util.ts"),
+        "export function util() {\n  return 1;\n}\n",
+    )
+    .unwrap();
+    symlink(shared.join("util.ts"), root.join("a.ts")).unwrap();
+    symlink(shared.join("util.ts"), root.join("b.ts")).unwrap();
+    // A link cycle must not hang discovery
+    symlink(&root, root.join("loop")).unwrap();
+
+    let mut config = hotspots_core::ResolvedConfig::defaults().unwrap();
+    config.dedup_symlinks = true;
+    let reports = analyze_with_config(
+        &root,
+        AnalysisOptions {
+            min_lrs: None,
+            top_n: None,
+        },
+        Some(&config),
+    )
+    .unwrap();
+    assert_eq!(reports.len(), 1);
+    assert_eq!(reports[0].file, root.join("a.ts").to_string_lossy());
+    assert_eq!(
+        reports[0].aliases,
+        vec![root.join("b.ts").to_string_lossy().to_string()]
+    );
+}
+
+/// `public_only` keeps exactly each language's public API; the fixtures list
+/// their expected partition in a header comment.
+#[test]
+fn test_public_only_partition_per_language() {
+    let cases: &[(&str, &[&str], &[&str])] = &[
+        (
+            "visibility.ts",
+            &["createOrder", "cancelOrder", "refund", "submit"],
+            &["total", "validate", "audit"],
+        ),
+        (
+            "go/visibility.go",
+            &["NewStore", "Store.Add"],
+            &["Store.trim", "validate"],
+        ),
+        (
+            "rust/visibility.rs",
+            &["Ledger::new", "Ledger::balance", "Ledger::default", "open"],
+            &["Ledger::compact", "Ledger::reset", "checksum"],
+        ),
+        (
+            "java/Visibility.java",
+            &["Visibility", "total", "greetAll"],
+            &["clamp", "reset", "packagePrivate"],
+        ),
+        (
+            "csharp/Visibility.cs",
+            &["Account", "Deposit", "Total"],
+            &["Apply", "Round", "Audit"],
+        ),
+        (
+            "python/visibility.py",
+            &["__init__", "add", "checkout"],
+            &["_recalculate", "get", "_log", "_helper"],
+        ),
+        ("c/visibility.c", &["area"], &["clamp"]),
+    ];
+
+    let options = || AnalysisOptions {
+        min_lrs: None,
+        top_n: None,
+    };
+    let names = |reports: Vec<hotspots_core::FunctionRiskReport>| {
+        let mut names: Vec<String> = reports.into_iter().map(|r| r.function).collect();
+        names.sort();
+        names
+    };
+    let sorted = |list: &[&str]| {
+        let mut list: Vec<String> = list.iter().map(|s| s.to_string()).collect();
+        list.sort();
+        list
+    };
+
+    let mut config = hotspots_core::ResolvedConfig::defaults().unwrap();
+    config.public_only = true;
+    for (fixture, public, private) in cases {
+        let path = fixture_path(fixture);
+
+        let all = names(analyze(&path, options()).unwrap());
+        let mut expected_all = sorted(public);
+        expected_all.extend(sorted(private));
+        expected_all.sort();
+        assert_eq!(all, expected_all, "all functions in {fixture}");
+
+        let public_only = names(analyze_with_config(&path, options(), Some(&config)).unwrap());
+        assert_eq!(public_only, sorted(public), "public functions in {fixture}");
+    }
+}
+
+/// Only the unexported, uncalled helper is a dead-code candidate: entry
+/// points, exported functions, and functions called directly or as methods
+/// are not
+#[test]
+fn test_dead_code_lists_unexported_uncalled_go_helper() {
+    use hotspots_core::dead_code::find_dead_code;
+
+    let path = fixture_path("go/dead_code.go");
+    let options = AnalysisOptions {
+        min_lrs: None,
+        top_n: None,
+    };
+    let reports = analyze(&path, options).unwrap();
+    assert_eq!(reports.len(), 6);
+
+    let config = hotspots_core::ResolvedConfig::defaults().unwrap();
+    let base = path.parent().unwrap();
+    let dead = find_dead_code(&reports, &config.entry_points, base);
+    let names: Vec<&str> = dead.iter().map(|d| d.function.as_str()).collect();
+    assert_eq!(names, ["unusedHelper"]);
+    assert_eq!((dead[0].file.as_str(), dead[0].line), ("dead_code.go", 35));
+}
+
+/// Under the default rule only the function that is long, branchy, and calls
+/// many others at once is a god function; the others each cross only some of
+/// the thresholds
+#[test]
+fn test_god_functions_meet_every_threshold_by_default() {
+    use hotspots_core::god_functions::{find_god_functions, GodFunctionRule};
+
+    let path = fixture_path("god-functions.ts");
+    let options = AnalysisOptions {
+        min_lrs: None,
+        top_n: None,
+    };
+    let reports = analyze(&path, options).unwrap();
+    assert_eq!(reports.len(), 3);
+    let base = path.parent().unwrap();
+    let names = |rule: &GodFunctionRule| -> Vec<String> {
+        find_god_functions(&reports, rule, base)
+            .god_functions
+            .into_iter()
+            .map(|g| g.function)
+            .collect()
+    };
+
+    let report = find_god_functions(&reports, &GodFunctionRule::default(), base);
+    assert_eq!(names(&GodFunctionRule::default()), ["processOrder"]);
+    let met: Vec<&str> = report.god_functions[0]
+        .met
+        .iter()
+        .map(|m| m.metric.as_str())
+        .collect();
+    assert_eq!(met, ["loc", "cc", "fo"]);
+
+    // The long function that calls many others meets two of the three;
+    // the branchy one only CC
+    let two_of_three = GodFunctionRule {
+        min_matched: 2,
+        ..GodFunctionRule::default()
+    };
+    assert_eq!(names(&two_of_three), ["processOrder", "renderReport"]);
+}
+
+/// A component's rollup CC is the sum of the CC of every function it owns
+#[test]
+fn test_component_rollups_sum_callback_cc() {
+    use hotspots_core::components::{component_rollups, Framework};
+
+    let options = || AnalysisOptions {
+        min_lrs: None,
+        top_n: None,
+    };
+
+    // React: every function but the module-level helper belongs to ProductList
+    let reports = analyze(&fixture_path("tsx/component-rollup.tsx"), options()).unwrap();
+    let callbacks: Vec<_> = reports
+        .iter()
+        .filter(|r| r.function != "formatPrice")
+        .collect();
+    assert!(callbacks.len() >= 5, "component plus its callbacks");
+    let rollups = component_rollups(&reports);
+    assert_eq!(rollups.len(), 1);
+    assert_eq!(rollups[0].component, "ProductList");
+    assert_eq!(rollups[0].framework, Framework::React);
+    assert_eq!(rollups[0].functions, callbacks.len());
+    assert_eq!(
+        rollups[0].cc,
+        callbacks.iter().map(|r| r.metrics.cc).sum::<u32>()
+    );
+
+    // Vue: the SFC is one component owning all of its functions
+    let reports = analyze(&fixture_path("vue/component-rollup.vue"), options()).unwrap();
+    assert!(reports.len() >= 5, "data, computed, watcher, methods");
+    let rollups = component_rollups(&reports);
+    assert_eq!(rollups.len(), 1);
+    assert_eq!(rollups[0].component, "component-rollup");
+    assert_eq!(rollups[0].framework, Framework::Vue);
+    assert_eq!(rollups[0].functions, reports.len());
+    assert_eq!(
+        rollups[0].cc,
+        reports.iter().map(|r| r.metrics.cc).sum::<u32>()
+    );
+}
+
+/// Async functions carry the `async` flag, awaits that run once per loop
+/// iteration are counted, and promise callbacks are functions of their own.
+#[test]
+fn test_async_functions_and_promise_chains() {
+    let options = AnalysisOptions {
+        min_lrs: None,
+        top_n: None,
+    };
+    let reports = analyze(&fixture_path("async-promises.ts"), options).unwrap();
+    let find = |name: &str| {
+        reports
+            .iter()
+            .find(|r| r.function == name)
+            .unwrap_or_else(|| panic!("missing {name}"))
+    };
+
+    for (name, is_async, await_in_loop) in [
+        ("syncSequential", true, 2),
+        ("syncParallel", true, 0),
+        ("loadWithAwait", true, 0),
+        ("loadWithChain", false, 0),
+        ("waitForJob", true, 2),
+    ] {
+        let metrics = &find(name).metrics;
+        assert_eq!(metrics.is_async, is_async, "{name}");
+        assert_eq!(metrics.await_in_loop, await_in_loop, "{name}");
+    }
+
+    // Three `map` / `filter` callbacks and two in the `.then().catch()` chain
+    let callbacks: Vec<_> = reports
+        .iter()
+        .filter(|r| r.function.starts_with("<anonymous>@"))
+        .collect();
+    assert_eq!(callbacks.len(), 5);
+    assert!(callbacks.iter().all(|r| !r.metrics.is_async));
+
+    let json: serde_json::Value = serde_json::from_str(&render_json(&reports)).unwrap();
+    let metrics = |name: &str| {
+        json.as_array()
+            .unwrap()
+            .iter()
+            .find(|r| r["function"] == name)
+            .unwrap()["metrics"]
+            .clone()
+    };
+    assert_eq!(metrics("syncSequential")["async"], true);
+    assert_eq!(metrics("syncSequential")["await_in_loop"], 2);
+    assert!(metrics("syncParallel").get("await_in_loop").is_none());
+    assert!(metrics("loadWithChain").get("async").is_none());
+}
+
+/// `separate_closures` reports Go closures on their own and leaves closure
+/// bodies out of the enclosing function's metrics, in Go and TypeScript
+#[test]
+fn test_separate_closures() {
+    let options = || AnalysisOptions {
+        min_lrs: None,
+        top_n: None,
+    };
+    let mut config = hotspots_core::ResolvedConfig::defaults().unwrap();
+    config.separate_closures = true;
+    let by_line = |mut reports: Vec<hotspots_core::FunctionRiskReport>| {
+        reports.sort_by_key(|r| r.line);
+        reports
+    };
+    let find = |reports: &[hotspots_core::FunctionRiskReport], name: &str| {
+        reports
+            .iter()
+            .find(|r| r.function == name)
+            .unwrap_or_else(|| panic!("missing {name}"))
+            .clone()
+    };
+
+    let path = fixture_path("go/closures.go");
+    let folded = by_line(analyze(&path, options()).unwrap());
+    let separate = by_line(analyze_with_config(&path, options(), Some(&config)).unwrap());
+    let names = |reports: &[hotspots_core::FunctionRiskReport]| -> Vec<String> {
+        reports.iter().map(|r| r.function.clone()).collect()
+    };
+    assert_eq!(names(&folded), ["ProcessAll", "makeFilter", "describe"]);
+    assert_eq!(
+        names(&separate),
+        [
+            "ProcessAll",
+            "ProcessAll.go",
+            "makeFilter",
+            "makeFilter.check",
+            "makeFilter.return",
+            "makeFilter.return.even",
+            "describe",
+        ]
+    );
+
+    // The goroutine's `if`, calls, `defer`, and `return` move to the closure
+    let (before, after) = (find(&folded, "ProcessAll"), find(&separate, "ProcessAll"));
+    assert_eq!((before.metrics.nd, after.metrics.nd), (2, 1));
+    assert_eq!(before.metrics.fo, after.metrics.fo + 3);
+    assert_eq!(before.metrics.ns, after.metrics.ns + 2);
+    assert_eq!(after.metrics.loc, before.metrics.loc);
+    // `||` in `check` and `&&` in the returned closure
+    assert_eq!(
+        find(&folded, "makeFilter").metrics.cc,
+        find(&separate, "makeFilter").metrics.cc + 2
+    );
+    let goroutine = find(&separate, "ProcessAll.go");
+    assert_eq!(goroutine.metrics.nd, 1);
+    assert_eq!(goroutine.metrics.params, 1);
+    assert!(!goroutine.is_public);
+    assert!(find(&separate, "makeFilter.return")
+        .callees
+        .contains(&"check".to_string()));
+    assert_eq!(
+        find(&folded, "describe").metrics,
+        find(&separate, "describe").metrics
+    );
+
+    // The other naming schemes name the same closures, with the same metrics
+    for (naming, expected) in [
+        (
+            hotspots_core::language::AnonNaming::Line,
+            [
+                "ProcessAll.line15",
+                "makeFilter.line31",
+                "makeFilter.line37",
+                "makeFilter.line37.line38",
+            ],
+        ),
+        (
+            hotspots_core::language::AnonNaming::Index,
+            [
+                "ProcessAll.func1",
+                "makeFilter.func1",
+                "makeFilter.func2",
+                "makeFilter.func2.1",
+            ],
+        ),
+    ] {
+        config.anon_naming = naming;
+        let renamed = by_line(analyze_with_config(&path, options(), Some(&config)).unwrap());
+        let closures: Vec<String> = names(&renamed)
+            .into_iter()
+            .filter(|name| name.contains('.'))
+            .collect();
+        assert_eq!(closures, expected, "{naming:?}");
+        assert_eq!(
+            find(&renamed, expected[0]).metrics,
+            goroutine.metrics,
+            "{naming:?}"
+        );
+    }
+
+    // TypeScript already reports each arrow; only the parent changes
+    let path = fixture_path("closures.ts");
+    let folded = by_line(analyze(&path, options()).unwrap());
+    let separate = by_line(analyze_with_config(&path, options(), Some(&config)).unwrap());
+    assert_eq!(folded.len(), 3);
+    assert_eq!(names(&folded), names(&separate));
+    let (before, after) = (&folded[0].metrics, &separate[0].metrics);
+    // `bus.on`, `store.save`, `store.clear`; then `bus.on` alone
+    assert_eq!((before.fo, after.fo), (3, 1));
+    // The handler's `||` no longer counts
+    assert_eq!(before.cc, after.cc + 1);
+    for (handler, same) in folded[1..].iter().zip(&separate[1..]) {
+        assert_eq!(handler.metrics, same.metrics, "{}", handler.function);
+    }
+}
+
+/// JS/TS functions without a declared name take the name of their binding
+#[test]
+fn test_anonymous_functions_named_from_bindings() {
+    let options = || AnalysisOptions {
+        min_lrs: None,
+        top_n: None,
+    };
+    let names = |name: &str| -> Vec<String> {
+        let mut reports = analyze(&fixture_path(name), options()).unwrap();
+        reports.sort_by_key(|r| r.line);
+        reports.into_iter().map(|r| r.function).collect()
+    };
+
+    let ts = names("anonymous-exports.ts");
+    assert_eq!(
+        &ts[..1],
+        ["default"],
+        "export default function takes the name `default`"
+    );
+    assert!(ts[1].starts_with("<anonymous>@"), "map callback: {}", ts[1]);
+    assert_eq!(
+        &ts[2..],
+        ["formatPrice", "onSubmit", "onReset", "validate", "render"]
+    );
+
+    assert_eq!(
+        names("js/commonjs-exports.js"),
+        ["default", "parse", "render"]
+    );
+
+    // Distinct names give distinct, line-independent baseline IDs
+    let ids: std::collections::HashSet<String> =
+        analyze(&fixture_path("anonymous-exports.ts"), options())
+            .unwrap()
+            .into_iter()
+            .filter(|r| !r.function.starts_with("<anonymous>"))
+            .map(|r| snapshot::FunctionSnapshot::from(r).function_id)
+            .collect();
+    assert_eq!(ids.len(), 6);
+}
+
+/// SARIF regions are 1-based and span each function exactly as the parser
+/// located it: `startLine` holds the declaration, `endLine` its closing brace.
+#[test]
+fn test_sarif_regions_match_parsed_lines() {
+    use hotspots_core::sarif::{MetricRule, MetricRules, SarifLevel};
+
+    // Every function trips the CC rule, so every function gets a result
+    let metric_rules = MetricRules {
+        cc: MetricRule {
+            threshold: 1,
+            level: SarifLevel::Warning,
+        },
+        ..MetricRules::default()
+    };
+    for (fixture, keyword) in [("go/boolean_ops.go", "func"), ("java/Loops.java", "(")] {
+        let path = fixture_path(fixture);
+        let source = std::fs::read_to_string(&path).unwrap();
+        let lines: Vec<&str> = source.lines().collect();
+        let reports = analyze(
+            &path,
+            AnalysisOptions {
+                min_lrs: None,
+                top_n: None,
+            },
+        )
+        .unwrap();
+        let context = git::GitContext {
+            head_sha: "abc123".to_string(),
+            parent_shas: vec![],
+            timestamp: 0,
+            branch: None,
+            is_detached: false,
+            message: None,
+            author: None,
+            is_fix_commit: None,
+            is_revert_commit: None,
+            ticket_ids: vec![],
+        };
+        let snapshot = snapshot::Snapshot::new(context, reports);
+        let repo_root = path.parent().unwrap();
+        let sarif = hotspots_core::sarif::render_sarif(
+            &snapshot,
+            repo_root,
+            &hotspots_core::risk::RiskThresholds::default(),
+            &metric_rules,
+        );
+        let val: serde_json::Value = serde_json::from_str(&sarif).unwrap();
+
+        let results: Vec<&serde_json::Value> = val["runs"][0]["results"]
+            .as_array()
+            .unwrap()
+            .iter()
+            .filter(|r| r["ruleId"] == "hotspots/cc")
+            .collect();
+        assert_eq!(results.len(), snapshot.functions.len(), "{fixture}");
+        for result in results {
+            let message = result["message"]["text"].as_str().unwrap();
+            let name = message.split('`').nth(1).unwrap();
+            let region = &result["locations"][0]["physicalLocation"]["region"];
+            let start = region["startLine"].as_u64().unwrap() as usize;
+            let end = region["endLine"].as_u64().unwrap() as usize;
+            let declaration = lines[start - 1];
+            assert!(
+                declaration.contains(name) && declaration.contains(keyword),
+                "{fixture}: line {start} is not {name}'s declaration: {declaration:?}"
+            );
+            assert_eq!(
+                lines[end - 1].trim(),
+                "}",
+                "{fixture}: line {end} does not close {name}"
+            );
+        }
+    }
+}
+
+#[test]
+fn test_analyze_source_in_memory() {
+    let src = "\
+package main
+
+func add(a, b int) int {
+\treturn a + b
+}
+
+func clamp(x int) int {
+\tif x < 0 {
+\t\treturn 0
+\t}
+\treturn x
+}
+";
+    let functions = analyze_source(Language::Go, src).unwrap();
+    let spans: Vec<(&str, u32, u32)> = functions
+        .iter()
+        .map(|f| (f.name.as_str(), f.start_line, f.end_line))
+        .collect();
+    assert_eq!(spans, vec![("add", 3, 5), ("clamp", 7, 12)]);
+    assert_eq!((functions[0].nd, functions[0].ns), (0, 1));
+    assert_eq!((functions[1].nd, functions[1].ns), (1, 2));
+
+    assert!(analyze_source(Language::Rust, "fn broken( {").is_err());
+}
+
+#[test]
+fn test_crlf_line_numbers_match_lf() {
+    for (fixture, language, expected) in [
+        (
+            "line-endings/crlf.ts",
+            Language::TypeScript,
+            [("first", 3, 8), ("second", 11, 19)],
+        ),
+        (
+            "line-endings/crlf.py",
+            Language::Python,
+            [("first", 3, 6), ("second", 9, 13)],
+        ),
+        // A lone CR is a line break, as editors show it
+        (
+            "line-endings/mixed.go",
+            Language::Go,
+            [("add", 5, 7), ("clamp", 9, 14)],
+        ),
+    ] {
+        let path = fixture_path(fixture);
+        let src = std::fs::read_to_string(&path).unwrap();
+        assert!(src.contains("\r\n"), "{fixture} lost its CRLF line endings");
+        let lf = src.replace("\r\n", "\n").replace('\r', "\n");
+
+        let from_crlf = analyze_source(language, &src).unwrap();
+        let from_lf = analyze_source(language, &lf).unwrap();
+        let spans: Vec<(&str, u32, u32)> = from_crlf
+            .iter()
+            .map(|f| (f.name.as_str(), f.start_line, f.end_line))
+            .collect();
+        assert_eq!(spans, expected, "spans of {fixture}");
+        assert_eq!(
+            from_crlf, from_lf,
+            "{fixture} differs from its LF equivalent"
+        );
+
+        let reports = analyze(
+            &path,
+            AnalysisOptions {
+                min_lrs: None,
+                top_n: None,
+            },
+        )
+        .unwrap();
+        let mut lines: Vec<(&str, u32)> = reports
+            .iter()
+            .map(|r| (r.function.as_str(), r.line))
+            .collect();
+        lines.sort_by_key(|&(_, line)| line);
+        let expected_lines: Vec<(&str, u32)> = expected
+            .iter()
+            .map(|&(name, start, _)| (name, start))
+            .collect();
+        assert_eq!(lines, expected_lines, "lines of {fixture}");
+    }
+
+    // The suppression comment is found on the line above the function
+    let reports = analyze(
+        &fixture_path("line-endings/crlf.ts"),
+        AnalysisOptions {
+            min_lrs: None,
+            top_n: None,
+        },
+    )
+    .unwrap();
+    let second = reports.iter().find(|r| r.function == "second").unwrap();
+    assert_eq!(second.suppression_reason.as_deref(), Some("legacy parser"));
+}
+
+#[test]
+fn test_bom_and_multibyte_line_numbers() {
+    for (fixture, language, expected) in [
+        (
+            "unicode/bom.py",
+            Language::Python,
+            [("prix_réduit", 4, 8), ("étiquette", 11, 16)],
+        ),
+        (
+            "unicode/bom.ts",
+            Language::TypeScript,
+            [("naïve", 5, 10), ("résumé", 12, 20)],
+        ),
+        (
+            "unicode/bom.go",
+            Language::Go,
+            [("Größe", 4, 6), ("Zähle", 8, 16)],
+        ),
+    ] {
+        let path = fixture_path(fixture);
+        let src = std::fs::read_to_string(&path).unwrap();
+        let without_bom = src
+            .strip_prefix('\u{feff}')
+            .unwrap_or_else(|| panic!("{fixture} lost its byte order mark"));
+
+        let from_bom = analyze_source(language, &src).unwrap();
+        let spans: Vec<(&str, u32, u32)> = from_bom
+            .iter()
+            .map(|f| (f.name.as_str(), f.start_line, f.end_line))
+            .collect();
+        assert_eq!(spans, expected, "spans of {fixture}");
+        assert_eq!(
+            from_bom,
+            analyze_source(language, without_bom).unwrap(),
+            "{fixture} differs from its BOM-less equivalent"
+        );
+
+        let reports = analyze(
+            &path,
+            AnalysisOptions {
+                min_lrs: None,
+                top_n: None,
+            },
+        )
+        .unwrap();
+        let mut lines: Vec<(&str, u32)> = reports
+            .iter()
+            .map(|r| (r.function.as_str(), r.line))
+            .collect();
+        lines.sort_by_key(|&(_, line)| line);
+        let expected_lines: Vec<(&str, u32)> = expected
+            .iter()
+            .map(|&(name, start, _)| (name, start))
+            .collect();
+        assert_eq!(lines, expected_lines, "lines of {fixture}");
+    }
+}
+
+#[test]
+fn test_analyze_source_matches_file_analysis() {
+    let path = fixture_path("go/simple.go");
+    let src = std::fs::read_to_string(&path).unwrap();
+    let from_source = analyze_source(Language::Go, &src).unwrap();
+    let from_file = analyze(
+        &path,
+        AnalysisOptions {
+            min_lrs: None,
+            top_n: None,
+        },
+    )
+    .unwrap();
+
+    assert_eq!(from_source.len(), from_file.len());
+    for f in &from_source {
+        let report = from_file.iter().find(|r| r.function == f.name).unwrap();
+        assert_eq!(f.start_line, report.line);
+        let m = &report.metrics;
+        assert_eq!((f.cc, f.nd, f.fo, f.ns), (m.cc, m.nd, m.fo, m.ns));
+    }
+}
+
+#[test]
+fn test_analyze_path_groups_by_file() {
+    let files = analyze_path(&fixture_path("go"), &AnalyzeOptions::default()).unwrap();
+    let paths: Vec<&PathBuf> = files.iter().map(|f| &f.path).collect();
+    let mut sorted = paths.clone();
+    sorted.sort();
+    assert_eq!(paths, sorted, "files are ordered by path");
+
+    let simple = files
+        .iter()
+        .find(|f| f.path.ends_with("go/simple.go"))
+        .unwrap();
+    assert_eq!(simple.language, Language::Go);
+    let names: Vec<&str> = simple.functions.iter().map(|f| f.name.as_str()).collect();
+    assert_eq!(
+        names,
+        vec![
+            "Simple",
+            "SingleBranch",
+            "IfElse",
+            "EarlyReturn",
+            "MultipleReturns"
+        ],
+        "functions are ordered by start line"
+    );
+}
+
+/// A directory's rollup covers its own files and every subdirectory
+#[test]
+fn test_dir_rollups_sum_nested_directories() {
+    use hotspots_core::dirs::dir_rollups;
+
+    let root = fixture_path("dir-rollup");
+    let reports = analyze(
+        &root,
+        AnalysisOptions {
+            min_lrs: None,
+            top_n: None,
+        },
+    )
+    .unwrap();
+    let tree = dir_rollups(&reports, &root);
+
+    // main 2, run 3, stop 1, clamp 3, route 3
+    assert_eq!(tree.path, ".");
+    assert_eq!((tree.functions, tree.total_cc, tree.max_nd), (5, 12, 2));
+    assert!((tree.mean_cc - 2.4).abs() < 1e-9);
+
+    let children: Vec<(&str, usize, u32)> = tree
+        .children
+        .iter()
+        .map(|c| (c.path.as_str(), c.functions, c.total_cc))
+        .collect();
+    assert_eq!(children, vec![("core", 3, 7), ("api", 1, 3)]);
+
+    let core = &tree.children[0];
+    assert_eq!(core.max_nd, 2);
+    assert_eq!(core.children.len(), 1);
+    assert_eq!(core.children[0].path, "core/util");
+    assert_eq!(
+        (core.children[0].functions, core.children[0].total_cc),
+        (1, 3)
+    );
+}
+
+/// Per-file summaries aggregate every function in the file and rank files by
+/// total or by worst single function
+#[test]
+fn test_file_summaries_aggregate_per_file() {
+    use hotspots_core::summary::{file_summaries, render_summary_text, SummarySort};
+
+    let root = fixture_path("file-summary");
+    let reports = analyze(
+        &root,
+        AnalysisOptions {
+            min_lrs: None,
+            top_n: None,
+        },
+    )
+    .unwrap();
+
+    // many.ts: a 2, b 2, c 3 (nested), d 2; big.ts: classify 6, identity 1
+    let by_total = file_summaries(&reports, &root, SummarySort::Total);
+    let rows: Vec<(&str, usize, u32, u32, u32, &str)> = by_total
+        .iter()
+        .map(|s| {
+            (
+                s.file.as_str(),
+                s.functions,
+                s.total_cc,
+                s.max_cc,
+                s.max_nd,
+                s.worst_function.as_str(),
+            )
+        })
+        .collect();
+    assert_eq!(
+        rows,
+        vec![
+            ("many.ts", 4, 9, 3, 2, "c"),
+            ("big.ts", 2, 7, 6, 1, "classify"),
+        ]
+    );
+    assert!((by_total[0].mean_cc - 2.25).abs() < 1e-9);
+    assert!((by_total[1].mean_cc - 3.5).abs() < 1e-9);
+    assert_eq!(by_total[0].worst_line, 15);
+
+    let by_max = file_summaries(&reports, &root, SummarySort::Max);
+    let files: Vec<&str> = by_max.iter().map(|s| s.file.as_str()).collect();
+    assert_eq!(files, vec!["big.ts", "many.ts"]);
+
+    let text = render_summary_text(&by_max);
+    assert_eq!(
+        text.lines().count(),
+        4,
+        "header, rule, and one row per file"
+    );
+    assert!(text.contains("classify (line 1, LRS "));
+}
+
+/// The tree view lists each file once, as a header followed by its functions
+#[test]
+fn test_tree_groups_functions_under_file_headers() {
+    use hotspots_core::tree::render_tree;
+
+    let root = fixture_path("dir-rollup");
+    let reports = analyze(
+        &root,
+        AnalysisOptions {
+            min_lrs: None,
+            top_n: None,
+        },
+    )
+    .unwrap();
+    let scale = hotspots_core::severity::SeverityScale::default();
+    let output = render_tree(&reports, &root, &scale, 0, false);
+    assert!(!output.contains("\x1b["), "no color unless asked for");
+
+    // (file, functions) in output order; a row is `├─ LRS SEVERITY NAME ...`
+    let mut files: Vec<(String, Vec<String>)> = Vec::new();
+    for line in output.lines().take_while(|line| !line.starts_with('─')) {
+        if let Some(row) = line
+            .strip_prefix("  ├─ ")
+            .or_else(|| line.strip_prefix("  └─ "))
+        {
+            let name = row.split_whitespace().nth(2).unwrap().to_string();
+            files.last_mut().expect("row before any file").1.push(name);
+        } else if !line.is_empty() {
+            assert!(!line.starts_with(' '), "unexpected line {line:?}");
+            files.push((line.to_string(), Vec::new()));
+        }
+    }
+
+    let mut expected: Vec<(String, Vec<String>)> = Vec::new();
+    for report in &reports {
+        let file = std::path::Path::new(&report.file)
+            .strip_prefix(&root)
+            .unwrap()
+            .to_string_lossy()
+            .into_owned();
+        match expected.iter_mut().find(|(f, _)| *f == file) {
+            Some((_, functions)) => functions.push(report.function.clone()),
+            None => expected.push((file, vec![report.function.clone()])),
+        }
+    }
+    assert_eq!(files, expected);
+    assert_eq!(files.len(), 4);
+    let engine = files.iter().find(|(f, _)| f == "core/engine.ts").unwrap();
+    assert_eq!(engine.1, ["run", "stop"], "riskiest first within a file");
+    assert!(output.ends_with("5 functions in 4 files\n"));
+}
+
+/// Streamed JSONL lines each parse on their own and carry the same reports
+/// as collected analysis, file by file
+#[test]
+fn test_streamed_jsonl_lines_round_trip() {
+    use hotspots_core::{analyze_streaming, render_jsonl_line, FunctionRiskReport};
+
+    let root = fixture_path("go");
+    let options = || AnalysisOptions {
+        min_lrs: None,
+        top_n: None,
+    };
+    let mut lines = Vec::new();
+    let mut files = Vec::new();
+    analyze_streaming(&root, options(), None, |reports| {
+        files.push(reports[0].file.clone());
+        lines.extend(reports.iter().map(|r| render_jsonl_line(r, "1.2.3")));
+        Ok(())
+    })
+    .unwrap();
+
+    let collected = analyze(&root, options()).unwrap();
+    assert_eq!(lines.len(), collected.len());
+    let mut sorted_files = files.clone();
+    sorted_files.sort();
+    assert_eq!(files, sorted_files, "files are delivered in walk order");
+
+    for line in &lines {
+        assert!(!line.contains('\n'));
+        let value: serde_json::Value = serde_json::from_str(line).unwrap();
+        for key in [
+            "schema_version",
+            "tool_version",
+            "file",
+            "function",
+            "line",
+            "end_line",
+            "metrics",
+            "lrs",
+        ] {
+            assert!(value.get(key).is_some(), "{key} missing from {line}");
+        }
+        let streamed: FunctionRiskReport = serde_json::from_value(value.clone()).unwrap();
+        let expected = collected
+            .iter()
+            .find(|r| r.file == streamed.file && r.line == streamed.line)
+            .unwrap();
+        assert_eq!(streamed.function, expected.function);
+        assert_eq!(streamed.metrics, expected.metrics);
+        assert_eq!(
+            value["end_line"],
+            expected.line + expected.metrics.loc - 1,
+            "end_line of {}",
+            expected.function
+        );
+    }
+}
+
+#[test]
+fn test_gitignored_files_are_never_reported() {
+    let root = fixture_path("gitignore");
+    let options = || AnalysisOptions {
+        min_lrs: None,
+        top_n: None,
+    };
+    let functions = |reports: Vec<hotspots_core::FunctionRiskReport>| {
+        let mut names: Vec<String> = reports.into_iter().map(|r| r.function).collect();
+        names.sort();
+        names
+    };
+
+    let reports = analyze(&root, options()).unwrap();
+    for report in &reports {
+        for ignored in ["api.gen.ts", "artifacts", "lib/local.go"] {
+            assert!(
+                !report.file.replace('\\', "/").contains(ignored),
+                "{} is ignored by .gitignore",
+                report.file
+            );
+        }
+    }
+    // keep.gen.ts is re-included by `!`, lib/scratch.py by lib/.gitignore, and
+    // lib/sub/local.go is below the anchored `/local.go`
+    assert_eq!(
+        functions(reports),
+        ["Clamp", "SubLocal", "keptClient", "lib_scratch", "start"]
+    );
+
+    let mut config = hotspots_core::ResolvedConfig::defaults().unwrap();
+    config.gitignore = false;
+    let reports = analyze_with_config(&root, options(), Some(&config)).unwrap();
+    assert_eq!(
+        functions(reports),
+        [
+            "Clamp",
+            "LocalOnly",
+            "SubLocal",
+            "bundled",
+            "generatedClient",
+            "keptClient",
+            "lib_scratch",
+            "scratch",
+            "start"
+        ]
+    );
+}
+
+/// Test files and test functions are skipped by default; production code
+/// that imports a test helper, or is merely named like a test, is not
+#[test]
+fn test_test_code_is_excluded_unless_included() {
+    let root = fixture_path("test-detection");
+    let options = || AnalysisOptions {
+        min_lrs: None,
+        top_n: None,
+    };
+    let functions = |reports: Vec<hotspots_core::FunctionRiskReport>| {
+        let mut names: Vec<String> = reports.into_iter().map(|r| r.function).collect();
+        names.sort();
+        names
+    };
+
+    let mut config = hotspots_core::ResolvedConfig::defaults().unwrap();
+    let reports = analyze_with_config(&root, options(), Some(&config)).unwrap();
+    assert_eq!(
+        functions(reports),
+        [
+            "AssertFields",
+            "Parse",
+            "TestdataDir",
+            "clock",
+            "parse",
+            "parse",
+            "parse",
+            "parseMocked",
+            "test_fixture"
+        ]
+    );
+
+    // Functions in `#[cfg(test)] mod tests` are never discovered
+    config.include_tests = true;
+    let reports = analyze_with_config(&root, options(), Some(&config)).unwrap();
+    assert_eq!(
+        functions(reports),
+        [
+            "AssertFields",
+            "BenchmarkParse",
+            "Parse",
+            "TestParse",
+            "TestdataDir",
+            "clock",
+            "expectEmpty",
+            "fixture",
+            "newInput",
+            "parse",
+            "parse",
+            "parse",
+            "parseMocked",
+            "parsesFields",
+            "parses_async",
+            "parses_fixture",
+            "renderFixture",
+            "sample",
+            "test_fixture",
+            "test_parse",
+            "test_parse_empty"
+        ]
+    );
+}
+
+/// A syntax error skips only the functions containing it: the rest of the
+/// file, and of the run, are still analyzed
+#[test]
+fn test_syntax_error_skips_only_functions_containing_it() {
+    use hotspots_core::analysis::analyze_file_detailed;
+    use swc_common::{sync::Lrc, SourceMap};
+
+    let options = || AnalysisOptions {
+        min_lrs: None,
+        top_n: None,
+    };
+    let cm: Lrc<SourceMap> = Default::default();
+    let analysis = analyze_file_detailed(
+        &fixture_path("python/syntax_error.py"),
+        &cm,
+        0,
+        &options(),
+        None,
+    )
+    .expect("a syntax error must not fail the file");
+    assert!(analysis.parse_errors > 0);
+    let names: Vec<&str> = analysis
+        .reports
+        .iter()
+        .map(|r| r.function.as_str())
+        .collect();
+    assert!(names.contains(&"clean"), "{names:?}");
+    assert!(names.contains(&"after_broken"), "{names:?}");
+    assert!(!names.contains(&"broken"), "{names:?}");
+
+    let reports = analyze(&fixture_path("python"), options()).unwrap();
+    assert!(reports.iter().any(|r| r.file.ends_with("simple.py")));
+    assert!(reports.iter().any(|r| r.function == "after_broken"));
+}
+
+#[test]
+fn test_analysis_cache_reuses_unchanged_files() {
+    use hotspots_core::analyze_files_with_stats;
+
+    let temp = tempfile::tempdir().unwrap();
+    let mut files = Vec::new();
+    for name in [
+        "call-graph.ts",
+        "anonymous-exports.ts",
+        "pathological.ts",
+        "go/boolean_ops.go",
+        "rust/attributes.rs",
+    ] {
+        let dest = temp.path().join(name.replace('/', "_"));
+        std::fs::copy(fixture_path(name), &dest).unwrap();
+        files.push(dest);
+    }
+
+    let mut resolved = hotspots_core::config::load_and_resolve(temp.path(), None).unwrap();
+    resolved.fan_in = true;
+    resolved.halstead = true;
+    let options = || AnalysisOptions {
+        min_lrs: Some(1.0),
+        top_n: None,
+    };
+    let uncached = render_json(
+        &analyze_files_with_stats(&files, options(), Some(&resolved), None)
+            .unwrap()
+            .0,
+    );
+
+    resolved.analysis_cache = true;
+    let (first, stats) =
+        analyze_files_with_stats(&files, options(), Some(&resolved), None).unwrap();
+    assert_eq!((stats.files, stats.cache_hits), (5, 0));
+    assert!(temp
+        .path()
+        .join(".hotspots/analysis-cache.json.zst")
+        .exists());
+
+    // Every file is loaded from the cache, and the output is unchanged
+    let (second, stats) =
+        analyze_files_with_stats(&files, options(), Some(&resolved), None).unwrap();
+    assert_eq!((stats.files, stats.cache_hits), (5, 5));
+    assert_eq!(render_json(&first), uncached);
+    assert_eq!(render_json(&second), uncached);
+    assert!(
+        second.iter().any(|r| r.metrics.fi > 0),
+        "callees survive the cache"
+    );
+
+    // An edited file is reparsed; the rest still hit
+    std::fs::write(&files[2], "function edited(x: number) { return x; }\n").unwrap();
+    let (_, stats) = analyze_files_with_stats(&files, options(), Some(&resolved), None).unwrap();
+    assert_eq!(stats.cache_hits, 4);
+
+    // Changing config that affects results invalidates the whole cache
+    resolved.weight_cc += 1.0;
+    let (_, stats) = analyze_files_with_stats(&files, options(), Some(&resolved), None).unwrap();
+    assert_eq!(stats.cache_hits, 0);
+}
@@ -90,16 +90,35 @@ fn test_suppression_missing_reason_policy() {
                 cc: 1,
                 nd: 0,
                 fo: 0,
+                fi: 0,
                 ns: 0,
                 loc: 10,
+                signature_complexity: 0,
+                params: 0,
+                guard_clauses: 0,
+                max_condition_ops: 0,
+                cognitive: 0,
+                halstead: None,
+                maintainability: None,
+                sloc: None,
+                comment_lines: None,
+                blank_lines: None,
+                nd_line: None,
+                cc_lines: None,
+                is_async: false,
+                await_in_loop: 0,
+                ns_breakdown: None,
             },
             lrs: 1.0,
             band: RiskBand::Low,
+            cc_breakdown: None,
         }),
         delta: None,
         band_transition: None,
         suppression_reason: Some(String::new()), // Empty reason
         rename_hint: None,
+        exempt_reason: None,
+        explanation: None,
     };
 
     let delta = Delta {
@@ -158,16 +177,35 @@ fn test_suppressed_function_excluded_from_critical_introduction() {
                 cc: 20,
                 nd: 10,
                 fo: 5,
+                fi: 0,
                 ns: 3,
                 loc: 50,
+                signature_complexity: 0,
+                params: 0,
+                guard_clauses: 0,
+                max_condition_ops: 0,
+                cognitive: 0,
+                halstead: None,
+                maintainability: None,
+                sloc: None,
+                comment_lines: None,
+                blank_lines: None,
+                nd_line: None,
+                cc_lines: None,
+                is_async: false,
+                await_in_loop: 0,
+                ns_breakdown: None,
             },
             lrs: 50.0,
             band: RiskBand::Critical,
+            cc_breakdown: None,
         }),
         delta: None,
         band_transition: None,
         suppression_reason: Some("legacy code, will refactor".to_string()), // Suppressed with reason
         rename_hint: None,
+        exempt_reason: None,
+        explanation: None,
     };
 
     let delta = Delta {
@@ -222,16 +260,35 @@ fn test_unsuppressed_function_triggers_critical_introduction() {
                 cc: 20,
                 nd: 10,
                 fo: 5,
+                fi: 0,
                 ns: 3,
                 loc: 50,
+                signature_complexity: 0,
+                params: 0,
+                guard_clauses: 0,
+                max_condition_ops: 0,
+                cognitive: 0,
+                halstead: None,
+                maintainability: None,
+                sloc: None,
+                comment_lines: None,
+                blank_lines: None,
+                nd_line: None,
+                cc_lines: None,
+                is_async: false,
+                await_in_loop: 0,
+                ns_breakdown: None,
             },
             lrs: 50.0,
             band: RiskBand::Critical,
+            cc_breakdown: None,
         }),
         delta: None,
         band_transition: None,
         suppression_reason: None, // NOT suppressed
         rename_hint: None,
+        exempt_reason: None,
+        explanation: None,
     };
 
     let delta = Delta {
@@ -271,3 +328,102 @@ fn test_unsuppressed_function_triggers_critical_introduction() {
     assert_eq!(results.failed[0].id, PolicyId::CriticalIntroduction);
     assert_eq!(results.failed[0].severity, PolicySeverity::Blocking);
 }
+
+#[test]
+fn test_exempt_function_excluded_from_gating_but_reported() {
+    use hotspots_core::config::HotspotsConfig;
+    use hotspots_core::delta::FunctionState;
+    use hotspots_core::report::MetricsReport;
+
+    let critical_entry = |function_id: &str| FunctionDeltaEntry {
+        function_id: function_id.to_string(),
+        status: FunctionStatus::New,
+        before: None,
+        after: Some(FunctionState {
+            metrics: MetricsReport {
+                cc: 20,
+                nd: 10,
+                fo: 5,
+                fi: 0,
+                ns: 3,
+                loc: 50,
+                signature_complexity: 0,
+                params: 0,
+                guard_clauses: 0,
+                max_condition_ops: 0,
+                cognitive: 0,
+                halstead: None,
+                maintainability: None,
+                sloc: None,
+                comment_lines: None,
+                blank_lines: None,
+                nd_line: None,
+                cc_lines: None,
+                is_async: false,
+                await_in_loop: 0,
+                ns_breakdown: None,
+            },
+            lrs: 50.0,
+            band: RiskBand::Critical,
+            cc_breakdown: None,
+        }),
+        delta: None,
+        band_transition: None,
+        suppression_reason: None,
+        rename_hint: None,
+        exempt_reason: None,
+        explanation: None,
+    };
+
+    let mut delta = Delta {
+        schema_version: 1,
+        commit: hotspots_core::delta::DeltaCommitInfo {
+            sha: "abc123".to_string(),
+            parent: "parent123".to_string(),
+        },
+        baseline: false,
+        deltas: vec![critical_entry("test.ts::critical")],
+        policy: None,
+        aggregates: None,
+    };
+
+    let git_context = GitContext {
+        head_sha: "abc123".to_string(),
+        parent_shas: vec!["parent123".to_string()],
+        timestamp: 1705600000,
+        branch: Some("main".to_string()),
+        is_detached: false,
+        message: Some("test commit".to_string()),
+        author: Some("Test Author".to_string()),
+        is_fix_commit: Some(false),
+        is_revert_commit: Some(false),
+        ticket_ids: vec![],
+    };
+
+    let snapshot = Snapshot::new(git_context, vec![]);
+    let config: HotspotsConfig = serde_json::from_str(
+        r#"{"exempt": [{"function": "test.ts::critical", "reason": "vendored parser"}]}"#,
+    )
+    .unwrap();
+    let config = config.resolve().unwrap();
+
+    delta.apply_exemptions(&config.exempt, Path::new("."));
+    let results = evaluate_policies(&delta, &snapshot, Path::new("."), &config)
+        .unwrap()
+        .unwrap();
+
+    // Exempt: no blocking failure, no warning
+    assert_eq!(results.failed.len(), 0);
+    assert_eq!(results.warnings.len(), 0);
+    assert!(delta.regressions().is_empty());
+
+    // Still reported, flagged as exempt
+    assert_eq!(delta.deltas.len(), 1);
+    assert_eq!(
+        delta.deltas[0].exempt_reason.as_deref(),
+        Some("vendored parser")
+    );
+    let json: serde_json::Value = serde_json::from_str(&delta.to_json("1.2.3").unwrap()).unwrap();
+    assert_eq!(json["deltas"][0]["exempt_reason"], "vendored parser");
+    assert_eq!(json["deltas"][0]["after"]["metrics"]["cc"], 20);
+}
@@ -61,8 +61,24 @@ fn make_func(file: &str, name: &str, line: u32) -> FunctionSnapshot {
             cc: 2,
             nd: 1,
             fo: 0,
+            fi: 0,
             ns: 0,
             loc: 10,
+            signature_complexity: 0,
+            params: 0,
+            guard_clauses: 0,
+            max_condition_ops: 0,
+            cognitive: 0,
+            halstead: None,
+            maintainability: None,
+            sloc: None,
+            comment_lines: None,
+            blank_lines: None,
+            nd_line: None,
+            cc_lines: None,
+            is_async: false,
+            await_in_loop: 0,
+            ns_breakdown: None,
         },
         lrs: 1.0,
         band: RiskBand::Low,
@@ -92,6 +108,8 @@ fn make_func(file: &str, name: &str, line: u32) -> FunctionSnapshot {
         age_days: None,
         last_touch_days: None,
         explanation: None,
+        structure: None,
+        cc_breakdown: None,
     }
 }
 
@@ -116,6 +134,9 @@ fn make_snapshot(functions: Vec<FunctionSnapshot>) -> Snapshot {
         functions,
         summary: None,
         aggregates: None,
+        truncated: None,
+        total_functions: None,
+        top_level_decisions: Default::default(),
     }
 }
 
@@ -155,6 +176,9 @@ fn extract_features_with_churn_and_callgraph() {
         is_entrypoint: false,
         dependency_depth: None,
         neighbor_churn: None,
+        recursive: false,
+        cycle: vec![],
+        external_calls: 0,
     });
     func.activity_risk = Some(3.5);
 
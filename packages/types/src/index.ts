@@ -332,6 +332,113 @@ export interface HotspotsOutput {
   policy_results?: PolicyResults;
 }
 
+/**
+ * Per-metric risk components behind LRS
+ */
+export interface RiskComponents {
+  r_cc: number;
+  r_nd: number;
+  r_fo: number;
+  r_ns: number;
+}
+
+/**
+ * One function in the report output of `hotspots analyze --format json`
+ */
+export interface ReportFunction {
+  /** Path to the source file containing the function */
+  file: string;
+
+  /**
+   * Function name; Go and Rust methods include their receiver type
+   * (`Type.Method`, `Type::method`)
+   */
+  function: string;
+
+  /** Class, struct, or receiver type the method is declared in (omitted for free functions) */
+  owner?: string;
+
+  /** Line number where the function is defined */
+  line: number;
+
+  /** Programming language of the source file */
+  language: string;
+
+  /** Raw complexity metrics (CC, ND, FO, NS) */
+  metrics: Metrics;
+
+  /** Per-metric risk components behind LRS */
+  risk: RiskComponents;
+
+  /** Logarithmic Risk Score */
+  lrs: number;
+
+  /** Risk band classification based on LRS thresholds */
+  band: RiskBand;
+
+  /** Composite risk score in [0, 1], only present with `--sort risk-score` */
+  risk_score?: number;
+
+  /** Reason provided via // hotspots-ignore comment */
+  suppression_reason?: string;
+
+  /** Detected code patterns (omitted when empty) */
+  patterns?: string[];
+}
+
+/**
+ * Report output of `hotspots analyze --format json` without `--mode`
+ *
+ * @example
+ * ```typescript
+ * const output: ReportOutput = {
+ *   schema_version: 2,
+ *   tool_version: "1.33.1",
+ *   truncated: false,
+ *   total_functions: 1,
+ *   functions: [
+ *     {
+ *       file: "src/api.ts",
+ *       function: "handleRequest",
+ *       line: 42,
+ *       language: "TypeScript",
+ *       metrics: { cc: 8, nd: 2, fo: 4, ns: 2 },
+ *       risk: { r_cc: 3.17, r_nd: 2.0, r_fo: 2.32, r_ns: 2.0 },
+ *       lrs: 7.2,
+ *       band: "high"
+ *     }
+ *   ]
+ * };
+ * ```
+ */
+export interface ReportOutput {
+  /**
+   * Report schema version, bumped whenever a report field is added, removed,
+   * renamed, or changes type
+   */
+  schema_version: number;
+
+  /**
+   * Version of Hotspots that wrote the output
+   */
+  tool_version: string;
+
+  /**
+   * Whether `--top` cut `functions` short of `total_functions`
+   */
+  truncated: boolean;
+
+  /**
+   * Number of functions reported before `--top` was applied
+   */
+  total_functions: number;
+
+  /**
+   * Per-function reports, highest risk first
+   */
+  functions: ReportFunction[];
+}
+
 //
 // Type Guards
 //
@@ -350,6 +457,21 @@ export function isHotspotsOutput(obj: unknown): obj is HotspotsOutput {
   );
 }
 
+/**
+ * Type guard to check if an object is a valid ReportOutput
+ */
+export function isReportOutput(obj: unknown): obj is ReportOutput {
+  if (typeof obj !== "object" || obj === null) return false;
+  const o = obj as any;
+  return (
+    typeof o.schema_version === "number" &&
+    typeof o.tool_version === "string" &&
+    typeof o.truncated === "boolean" &&
+    typeof o.total_functions === "number" &&
+    Array.isArray(o.functions)
+  );
+}
+
 /**
  * Type guard to check if an object is a valid FunctionReport
  */
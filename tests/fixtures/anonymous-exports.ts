@@ -0,0 +1,28 @@
+// Functions without a declared name are named after where they are bound:
+// default, formatPrice, onSubmit, onReset, validate, render; the map
+// callback stays anonymous
+
+export default function (items: number[]): number[] {
+  return items.map((n) => n * 2);
+}
+
+const formatPrice = (cents: number): string => {
+  if (cents < 0) {
+    return "-";
+  }
+  return (cents / 100).toFixed(2);
+};
+
+export const handlers = {
+  onSubmit: (value: string): boolean => value.length > 0,
+  onReset: function (): void {
+    console.log("reset");
+  },
+  validate(value: string): boolean {
+    return formatPrice(value.length) !== "-";
+  },
+};
+
+export class PriceView {
+  render = (): string => formatPrice(100);
+}
@@ -0,0 +1,49 @@
+// Async loops and promise chains
+
+// Awaits one request per id: each waits for the previous one
+async function syncSequential(ids: string[]): Promise<number> {
+  let saved = 0;
+  for (const id of ids) {
+    const record = await fetchRecord(id);
+    if (record.dirty) {
+      await saveRecord(record);
+      saved += 1;
+    }
+  }
+  return saved;
+}
+
+// Same work, started together
+async function syncParallel(ids: string[]): Promise<number> {
+  const records = await Promise.all(ids.map((id) => fetchRecord(id)));
+  const dirty = records.filter((record) => record.dirty);
+  await Promise.all(dirty.map((record) => saveRecord(record)));
+  return dirty.length;
+}
+
+// Error path written with try/catch around await
+async function loadWithAwait(url: string): Promise<string> {
+  try {
+    const response = await fetch(url);
+    return await response.text();
+  } catch (err) {
+    return "";
+  }
+}
+
+// The same error path written as a promise chain
+function loadWithChain(url: string): Promise<string> {
+  return fetch(url)
+    .then((response) => response.text())
+    .catch((err) => "");
+}
+
+// Polls until the job settles
+async function waitForJob(job: { poll(): Promise<string> }): Promise<string> {
+  let status = await job.poll();
+  while (status === "pending") {
+    await delay(100);
+    status = await job.poll();
+  }
+  return status;
+}
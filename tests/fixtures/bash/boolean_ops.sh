@@ -0,0 +1,23 @@
+#!/bin/sh
+# `&&` and `||` between commands and inside `[[ ... ]]`
+
+with_and() {
+  if [ -f "$1" ] && [ -r "$1" ]; then
+    cat "$1"
+  fi
+}
+
+with_or() {
+  [ -d "$1" ] || mkdir -p "$1"
+}
+
+multiple_ops() {
+  if [[ -n "$1" && -n "$2" || -n "$3" ]]; then
+    return 0
+  fi
+  return 1
+}
+
+chained() {
+  make build && make test && make install || echo "failed" >&2
+}
@@ -0,0 +1,95 @@
+#!/usr/bin/env bash
+# Deploy script: a big `case`, nested conditionals, subshells, and pipelines
+set -euo pipefail
+
+usage() {
+  echo "usage: deploy.sh <command> [env]" >&2
+  exit 2
+}
+
+_log() {
+  printf '[deploy] %s\n' "$*"
+}
+
+confirm() {
+  read -r -p "Continue? [y/N] " answer
+  case "$answer" in
+    y | Y | yes) return 0 ;;
+    n | N | no) return 1 ;;
+  esac
+  return 1
+}
+
+deploy_to() {
+  local env="$1"
+  if [ "$env" = production ]; then
+    if [ "$(git rev-parse --abbrev-ref HEAD)" != main ]; then
+      _log "refusing to deploy from a branch"
+      return 1
+    fi
+    if ! confirm; then
+      return 1
+    fi
+  elif [ "$env" != staging ]; then
+    _log "unknown environment: $env"
+    return 2
+  fi
+  (
+    cd build || exit 1
+    ./upload.sh "$env"
+  )
+  _log "deployed to $env"
+}
+
+rollback() {
+  local target="$1"
+  find_release() {
+    ls releases | sort -r | while read -r release; do
+      if [ "$release" = "$target" ]; then
+        echo "$release"
+        return 0
+      fi
+    done
+  }
+  local release
+  release="$(find_release)"
+  if [ -z "$release" ]; then
+    _log "no release $target"
+    return 1
+  fi
+  ln -sfn "releases/$release" current
+}
+
+main() {
+  case "$1" in
+    build)
+      make build
+      ;;
+    test | check)
+      make test
+      ;;
+    deploy)
+      deploy_to "${2:-staging}"
+      ;;
+    rollback)
+      rollback "$2"
+      ;;
+    status)
+      git log -1 --oneline
+      ;;
+    logs)
+      tail -n 100 /var/log/app.log
+      ;;
+    clean)
+      rm -rf build
+      ;;
+    -h | --help)
+      usage
+      ;;
+    *)
+      usage
+      ;;
+  esac
+}
+
+main "$@"
@@ -0,0 +1,45 @@
+# Loops of every kind. This script has no shebang line.
+
+print_all() {
+  for arg in "$@"; do
+    echo "$arg"
+  done
+}
+
+count_down() {
+  local n="$1"
+  until [ "$n" -le 0 ]; do
+    n=$((n - 1))
+  done
+}
+
+first_missing() {
+  for f in "$@"; do
+    if [ ! -e "$f" ]; then
+      echo "$f"
+      break
+    fi
+  done
+}
+
+skip_blank() {
+  while read -r line; do
+    [[ -z "$line" ]] && continue
+    printf '%s\n' "$line"
+  done < "$1"
+}
+
+grid() {
+  for ((i = 0; i < 3; i++)); do
+    for ((j = 0; j < 3; j++)); do
+      printf '%d,%d ' "$i" "$j"
+    done
+  done
+}
+
+choose() {
+  select opt in start stop; do
+    echo "$opt"
+    break
+  done
+}
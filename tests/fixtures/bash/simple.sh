@@ -0,0 +1,44 @@
+#!/usr/bin/env bash
+# Straight-line code, branches, and early returns
+
+greet() {
+  local name="$1"
+  echo "hello, $name"
+}
+
+single_branch() {
+  if [ -n "$1" ]; then
+    echo "$1"
+  fi
+}
+
+if_else() {
+  if [ "$1" -gt 0 ]; then
+    echo positive
+  else
+    echo non-positive
+  fi
+}
+
+early_return() {
+  if [ -z "$1" ]; then
+    return 1
+  fi
+  echo "$1"
+  return 0
+}
+
+sign() {
+  if [ "$1" -gt 0 ]; then
+    echo 1
+  elif [ "$1" -lt 0 ]; then
+    echo -1
+  else
+    echo 0
+  fi
+}
+
+function require_root {
+  [ "$(id -u)" -eq 0 ] || exit 1
+  echo root
+}
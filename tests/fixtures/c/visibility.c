@@ -0,0 +1,12 @@
+/* Public API partition for --public-only:
+ * public:  area
+ * private: clamp (static)
+ */
+
+static int clamp(int x) {
+    return x < 0 ? 0 : x;
+}
+
+int area(int w, int h) {
+    return clamp(w) * clamp(h);
+}
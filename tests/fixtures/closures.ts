@@ -0,0 +1,12 @@
+// Handlers registered from inside a function
+
+export function registerHandlers(bus: any, store: any): boolean {
+  bus.on("save", (item: any) => {
+    if (!item || !item.id) {
+      return;
+    }
+    store.save(item);
+  });
+  bus.on("clear", () => store.clear());
+  return bus.size > 0 && store.ready;
+}
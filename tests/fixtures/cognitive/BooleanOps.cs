@@ -0,0 +1,164 @@
+using System;
+
+// Mirrors tests/fixtures/go/boolean_ops.go: cognitive complexity must match
+// the Go fixture function for function, whatever CC says.
+public class BooleanOps
+{
+    public static void WithAnd(int x, int y)
+    {
+        if (x > 0 && y > 0)
+        {
+            Console.WriteLine("both positive");
+        }
+    }
+
+    public static void WithOr(int x, int y)
+    {
+        if (x > 0 || y > 0)
+        {
+            Console.WriteLine("at least one positive");
+        }
+    }
+
+    public static int MultipleBooleanOps(int x, int y, int z)
+    {
+        if (x > 0 && y > 0 && z > 0 || x < 0)
+        {
+            return 1;
+        }
+        return 0;
+    }
+
+    public static bool ComplexBooleanExpression(int a, int b, int c, int d)
+    {
+        if ((a > 0 && b > 0) || (c > 0 && d > 0))
+        {
+            return true;
+        }
+        return false;
+    }
+
+    public static int NestedWithBooleanOps(int x, int y, int z)
+    {
+        if (x > 0)
+        {
+            if (y > 0 && z > 0)
+            {
+                if (x > 10 || y > 10)
+                {
+                    return 1;
+                }
+            }
+        }
+        return 0;
+    }
+
+    public static bool SwitchWithBooleanOps(int x, int y)
+    {
+        bool ok;
+        switch (x)
+        {
+            case 1:
+                ok = x > 0 && y > 0;
+                break;
+            case 2:
+                ok = x > 0 || y > 0;
+                break;
+            default:
+                ok = false;
+                break;
+        }
+        return ok;
+    }
+
+    public static int LoopWithBooleanOps(int[] items)
+    {
+        int count = 0;
+        for (int i = 0; i < items.Length; i++)
+        {
+            if (i > 0 && items[i] > 0 || items[i] < 0)
+            {
+                count++;
+            }
+        }
+        return count;
+    }
+
+    public static void DeeplyNested(int x)
+    {
+        if (x > 0)
+        {
+            for (int i = 0; i < x; i++)
+            {
+                if (i > 5)
+                {
+                    switch (i)
+                    {
+                        case 6:
+                            if (i % 2 == 0)
+                            {
+                                Console.WriteLine("deep");
+                            }
+                            break;
+                    }
+                }
+            }
+        }
+    }
+
+    public static int PathologicalComplexity(int x, int y, int z)
+    {
+        int result = 0;
+
+        // Multiple early returns
+        if (x < 0)
+        {
+            return -1;
+        }
+        if (y < 0)
+        {
+            return -2;
+        }
+        if (z < 0)
+        {
+            return -3;
+        }
+
+        // Nested loops with conditions
+        for (int i = 0; i < x; i++)
+        {
+            for (int j = 0; j < y; j++)
+            {
+                if (i > 0 && j > 0 || i < 0)
+                {
+                    switch (i + j)
+                    {
+                        case 1:
+                            result++;
+                            break;
+                        case 2:
+                            result += 2;
+                            break;
+                        case 3:
+                            if (z > 0 && result > 0)
+                            {
+                                result *= 2;
+                            }
+                            break;
+                        default:
+                            result--;
+                            break;
+                    }
+                }
+            }
+        }
+
+        // More bool operators
+        if (result > 100 && x > 10 || result < 0 && y > 5)
+        {
+            return result * 2;
+        }
+
+        return result;
+    }
+}
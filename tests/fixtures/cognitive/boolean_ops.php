@@ -0,0 +1,133 @@
+<?php
+// Mirrors tests/fixtures/go/boolean_ops.go: cognitive complexity must match
+// the Go fixture function for function, whatever CC says.
+
+function WithAnd($x, $y)
+{
+    if ($x > 0 && $y > 0) {
+        echo "both positive";
+    }
+}
+
+function WithOr($x, $y)
+{
+    if ($x > 0 || $y > 0) {
+        echo "at least one positive";
+    }
+}
+
+function MultipleBooleanOps($x, $y, $z)
+{
+    if ($x > 0 && $y > 0 && $z > 0 || $x < 0) {
+        return 1;
+    }
+    return 0;
+}
+
+function ComplexBooleanExpression($a, $b, $c, $d)
+{
+    if (($a > 0 && $b > 0) || ($c > 0 && $d > 0)) {
+        return true;
+    }
+    return false;
+}
+
+function NestedWithBooleanOps($x, $y, $z)
+{
+    if ($x > 0) {
+        if ($y > 0 && $z > 0) {
+            if ($x > 10 || $y > 10) {
+                return 1;
+            }
+        }
+    }
+    return 0;
+}
+
+function SwitchWithBooleanOps($x, $y)
+{
+    switch ($x) {
+        case 1:
+            $ok = $x > 0 && $y > 0;
+            break;
+        case 2:
+            $ok = $x > 0 || $y > 0;
+            break;
+        default:
+            $ok = false;
+    }
+    return $ok;
+}
+
+function LoopWithBooleanOps(array $items)
+{
+    $count = 0;
+    foreach ($items as $i => $item) {
+        if ($i > 0 && $item > 0 || $item < 0) {
+            $count++;
+        }
+    }
+    return $count;
+}
+
+function DeeplyNested($x)
+{
+    if ($x > 0) {
+        for ($i = 0; $i < $x; $i++) {
+            if ($i > 5) {
+                switch ($i) {
+                    case 6:
+                        if ($i % 2 == 0) {
+                            echo "deep";
+                        }
+                }
+            }
+        }
+    }
+}
+
+function PathologicalComplexity($x, $y, $z)
+{
+    $result = 0;
+
+    // Multiple early returns
+    if ($x < 0) {
+        return -1;
+    }
+    if ($y < 0) {
+        return -2;
+    }
+    if ($z < 0) {
+        return -3;
+    }
+
+    // Nested loops with conditions
+    for ($i = 0; $i < $x; $i++) {
+        for ($j = 0; $j < $y; $j++) {
+            if ($i > 0 && $j > 0 || $i < 0) {
+                switch ($i + $j) {
+                    case 1:
+                        $result++;
+                        break;
+                    case 2:
+                        $result += 2;
+                        break;
+                    case 3:
+                        if ($z > 0 && $result > 0) {
+                            $result *= 2;
+                        }
+                        break;
+                    default:
+                        $result--;
+                }
+            }
+        }
+    }
+
+    // More boolean operators
+    if ($result > 100 && $x > 10 || $result < 0 && $y > 5) {
+        return $result * 2;
+    }
+
+    return $result;
+}
@@ -0,0 +1,94 @@
+# Mirrors tests/fixtures/go/boolean_ops.go: cognitive complexity must match
+# the Go fixture function for function, whatever CC says.
+
+
+def WithAnd(x, y):
+    if x > 0 and y > 0:
+        print("both positive")
+
+
+def WithOr(x, y):
+    if x > 0 or y > 0:
+        print("at least one positive")
+
+
+def MultipleBooleanOps(x, y, z):
+    if x > 0 and y > 0 and z > 0 or x < 0:
+        return 1
+    return 0
+
+
+def ComplexBooleanExpression(a, b, c, d):
+    if (a > 0 and b > 0) or (c > 0 and d > 0):
+        return True
+    return False
+
+
+def NestedWithBooleanOps(x, y, z):
+    if x > 0:
+        if y > 0 and z > 0:
+            if x > 10 or y > 10:
+                return 1
+    return 0
+
+
+def SwitchWithBooleanOps(x, y):
+    match x:
+        case 1:
+            ok = x > 0 and y > 0
+        case 2:
+            ok = x > 0 or y > 0
+        case _:
+            ok = False
+    return ok
+
+
+def LoopWithBooleanOps(items):
+    count = 0
+    for i, item in enumerate(items):
+        if i > 0 and item > 0 or item < 0:
+            count += 1
+    return count
+
+
+def DeeplyNested(x):
+    if x > 0:
+        for i in range(x):
+            if i > 5:
+                match i:
+                    case 6:
+                        if i % 2 == 0:
+                            print("deep")
+
+
+def PathologicalComplexity(x, y, z):
+    result = 0
+
+    # Multiple early returns
+    if x < 0:
+        return -1
+    if y < 0:
+        return -2
+    if z < 0:
+        return -3
+
+    # Nested loops with conditions
+    for i in range(x):
+        for j in range(y):
+            if i > 0 and j > 0 or i < 0:
+                match i + j:
+                    case 1:
+                        result += 1
+                    case 2:
+                        result += 2
+                    case 3:
+                        if z > 0 and result > 0:
+                            result *= 2
+                    case _:
+                        result -= 1
+
+    # More boolean operators
+    if result > 100 and x > 10 or result < 0 and y > 5:
+        return result * 2
+
+    return result
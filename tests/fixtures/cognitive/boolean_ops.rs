@@ -0,0 +1,115 @@
+// Mirrors tests/fixtures/go/boolean_ops.go: cognitive complexity must match
+// the Go fixture function for function, whatever CC says.
+#![allow(non_snake_case)]
+
+fn WithAnd(x: i32, y: i32) {
+    if x > 0 && y > 0 {
+        println!("both positive");
+    }
+}
+
+fn WithOr(x: i32, y: i32) {
+    if x > 0 || y > 0 {
+        println!("at least one positive");
+    }
+}
+
+fn MultipleBooleanOps(x: i32, y: i32, z: i32) -> i32 {
+    if x > 0 && y > 0 && z > 0 || x < 0 {
+        return 1;
+    }
+    0
+}
+
+fn ComplexBooleanExpression(a: i32, b: i32, c: i32, d: i32) -> bool {
+    if (a > 0 && b > 0) || (c > 0 && d > 0) {
+        return true;
+    }
+    false
+}
+
+fn NestedWithBooleanOps(x: i32, y: i32, z: i32) -> i32 {
+    if x > 0 {
+        if y > 0 && z > 0 {
+            if x > 10 || y > 10 {
+                return 1;
+            }
+        }
+    }
+    0
+}
+
+fn SwitchWithBooleanOps(x: i32, y: i32) -> bool {
+    match x {
+        1 => x > 0 && y > 0,
+        2 => x > 0 || y > 0,
+        _ => false,
+    }
+}
+
+fn LoopWithBooleanOps(items: &[i32]) -> i32 {
+    let mut count = 0;
+    for (i, &item) in items.iter().enumerate() {
+        if i > 0 && item > 0 || item < 0 {
+            count += 1;
+        }
+    }
+    count
+}
+
+fn DeeplyNested(x: i32) {
+    if x > 0 {
+        for i in 0..x {
+            if i > 5 {
+                match i {
+                    6 => {
+                        if i % 2 == 0 {
+                            println!("deep");
+                        }
+                    }
+                    _ => {}
+                }
+            }
+        }
+    }
+}
+
+fn PathologicalComplexity(x: i32, y: i32, z: i32) -> i32 {
+    let mut result = 0;
+
+    // Multiple early returns
+    if x < 0 {
+        return -1;
+    }
+    if y < 0 {
+        return -2;
+    }
+    if z < 0 {
+        return -3;
+    }
+
+    // Nested loops with conditions
+    for i in 0..x {
+        for j in 0..y {
+            if i > 0 && j > 0 || i < 0 {
+                match i + j {
+                    1 => result += 1,
+                    2 => result += 2,
+                    3 => {
+                        if z > 0 && result > 0 {
+                            result *= 2;
+                        }
+                    }
+                    _ => result -= 1,
+                }
+            }
+        }
+    }
+
+    // More boolean operators
+    if result > 100 && x > 10 || result < 0 && y > 5 {
+        return result * 2;
+    }
+
+    result
+}
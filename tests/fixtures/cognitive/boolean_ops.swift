@@ -0,0 +1,121 @@
+// Mirrors tests/fixtures/go/boolean_ops.go: cognitive complexity must match
+// the Go fixture function for function, whatever CC says.
+
+func WithAnd(x: Int, y: Int) {
+    if x > 0 && y > 0 {
+        print("both positive")
+    }
+}
+
+func WithOr(x: Int, y: Int) {
+    if x > 0 || y > 0 {
+        print("at least one positive")
+    }
+}
+
+func MultipleBooleanOps(x: Int, y: Int, z: Int) -> Int {
+    if x > 0 && y > 0 && z > 0 || x < 0 {
+        return 1
+    }
+    return 0
+}
+
+func ComplexBooleanExpression(a: Int, b: Int, c: Int, d: Int) -> Bool {
+    if (a > 0 && b > 0) || (c > 0 && d > 0) {
+        return true
+    }
+    return false
+}
+
+func NestedWithBooleanOps(x: Int, y: Int, z: Int) -> Int {
+    if x > 0 {
+        if y > 0 && z > 0 {
+            if x > 10 || y > 10 {
+                return 1
+            }
+        }
+    }
+    return 0
+}
+
+func SwitchWithBooleanOps(x: Int, y: Int) -> Bool {
+    var ok: Bool
+    switch x {
+    case 1:
+        ok = x > 0 && y > 0
+    case 2:
+        ok = x > 0 || y > 0
+    default:
+        ok = false
+    }
+    return ok
+}
+
+func LoopWithBooleanOps(items: [Int]) -> Int {
+    var count = 0
+    for (i, item) in items.enumerated() {
+        if i > 0 && item > 0 || item < 0 {
+            count += 1
+        }
+    }
+    return count
+}
+
+func DeeplyNested(x: Int) {
+    if x > 0 {
+        for i in 0..<x {
+            if i > 5 {
+                switch i {
+                case 6:
+                    if i % 2 == 0 {
+                        print("deep")
+                    }
+                default:
+                    break
+                }
+            }
+        }
+    }
+}
+
+func PathologicalComplexity(x: Int, y: Int, z: Int) -> Int {
+    var result = 0
+
+    // Multiple early returns
+    if x < 0 {
+        return -1
+    }
+    if y < 0 {
+        return -2
+    }
+    if z < 0 {
+        return -3
+    }
+
+    // Nested loops with conditions
+    for i in 0..<x {
+        for j in 0..<y {
+            if i > 0 && j > 0 || i < 0 {
+                switch i + j {
+                case 1:
+                    result += 1
+                case 2:
+                    result += 2
+                case 3:
+                    if z > 0 && result > 0 {
+                        result *= 2
+                    }
+                default:
+                    result -= 1
+                }
+            }
+        }
+    }
+
+    // More boolean operators
+    if result > 100 && x > 10 || result < 0 && y > 5 {
+        return result * 2
+    }
+
+    return result
+}
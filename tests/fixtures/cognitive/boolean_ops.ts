@@ -0,0 +1,120 @@
+// Mirrors tests/fixtures/go/boolean_ops.go: cognitive complexity must match
+// the Go fixture function for function, whatever CC says.
+
+export function WithAnd(x: number, y: number): void {
+  if (x > 0 && y > 0) {
+    console.log("both positive");
+  }
+}
+
+export function WithOr(x: number, y: number): void {
+  if (x > 0 || y > 0) {
+    console.log("at least one positive");
+  }
+}
+
+export function MultipleBooleanOps(x: number, y: number, z: number): number {
+  if (x > 0 && y > 0 && z > 0 || x < 0) {
+    return 1;
+  }
+  return 0;
+}
+
+export function ComplexBooleanExpression(a: number, b: number, c: number, d: number): boolean {
+  if ((a > 0 && b > 0) || (c > 0 && d > 0)) {
+    return true;
+  }
+  return false;
+}
+
+export function NestedWithBooleanOps(x: number, y: number, z: number): number {
+  if (x > 0) {
+    if (y > 0 && z > 0) {
+      if (x > 10 || y > 10) {
+        return 1;
+      }
+    }
+  }
+  return 0;
+}
+
+export function SwitchWithBooleanOps(x: number, y: number): string {
+  switch (true) {
+    case x > 0 && y > 0:
+      return "both positive";
+    case x > 0 || y > 0:
+      return "one positive";
+    default:
+      return "none positive";
+  }
+}
+
+export function LoopWithBooleanOps(items: number[]): number {
+  let count = 0;
+  for (let i = 0; i < items.length; i++) {
+    if (i > 0 && items[i] > 0 || items[i] < 0) {
+      count++;
+    }
+  }
+  return count;
+}
+
+export function DeeplyNested(x: number): void {
+  if (x > 0) {
+    for (let i = 0; i < x; i++) {
+      if (i > 5) {
+        switch (i) {
+          case 6:
+            if (i % 2 === 0) {
+              console.log("deep");
+            }
+        }
+      }
+    }
+  }
+}
+
+export function PathologicalComplexity(x: number, y: number, z: number): number {
+  let result = 0;
+
+  // Multiple early returns
+  if (x < 0) {
+    return -1;
+  }
+  if (y < 0) {
+    return -2;
+  }
+  if (z < 0) {
+    return -3;
+  }
+
+  // Nested loops with conditions
+  for (let i = 0; i < x; i++) {
+    for (let j = 0; j < y; j++) {
+      if (i > 0 && j > 0 || i < 0) {
+        switch (i + j) {
+          case 1:
+            result++;
+            break;
+          case 2:
+            result += 2;
+            break;
+          case 3:
+            if (z > 0 && result > 0) {
+              result *= 2;
+            }
+            break;
+          default:
+            result--;
+        }
+      }
+    }
+  }
+
+  // More boolean operators
+  if (result > 100 && x > 10 || result < 0 && y > 5) {
+    return result * 2;
+  }
+
+  return result;
+}
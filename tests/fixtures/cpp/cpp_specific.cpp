@@ -0,0 +1,55 @@
+// Namespaces, classes, templates, lambdas, and exception handling
+
+#include <algorithm>
+#include <stdexcept>
+#include <string>
+#include <vector>
+
+namespace shop {
+
+class Cart {
+public:
+    double total() const {
+        double sum = 0.0;
+        for (const auto& item : items_) {
+            sum += item.price;
+        }
+        return sum;
+    }
+
+    void add(const Item& item);
+
+private:
+    std::vector<Item> items_;
+};
+
+void Cart::add(const Item& item) {
+    if (item.price < 0) {
+        throw std::invalid_argument{"negative price"};
+    }
+    items_.push_back(item);
+}
+
+template <typename T>
+T clampTo(T value, T low, T high) {
+    return (value < low) ? low : ((high < value) ? high : value);
+}
+
+int parseQuantity(const std::string& text) {
+    try {
+        return std::stoi(text);
+    } catch (const std::invalid_argument&) {
+        return 0;
+    } catch (const std::out_of_range&) {
+        return -1;
+    }
+}
+
+}  // namespace shop
+
+int countExpensive(const std::vector<shop::Item>& items, double limit) {
+    auto isExpensive = [limit](const shop::Item& item) {
+        return item.price > limit && item.inStock;
+    };
+    return std::count_if(items.begin(), items.end(), isExpensive);
+}
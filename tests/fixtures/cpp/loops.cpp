@@ -0,0 +1,63 @@
+// for, range-based for, while, and do-while loops
+
+#include <iostream>
+#include <vector>
+
+void simpleLoop() {
+    for (int i = 0; i < 10; i++) {
+        std::cout << i;
+    }
+}
+
+void loopWithCondition() {
+    for (int i = 0; i < 10; i++) {
+        if (i > 5) {
+            std::cout << i;
+        }
+    }
+}
+
+void nestedLoops() {
+    for (int i = 0; i < 10; i++) {
+        for (int j = 0; j < 10; j++) {
+            std::cout << i + j;
+        }
+    }
+}
+
+void loopWithBreak() {
+    for (int i = 0; i < 10; i++) {
+        if (i > 5) {
+            break;
+        }
+    }
+}
+
+void loopWithContinue() {
+    for (int i = 0; i < 10; i++) {
+        if (i % 2 == 0) {
+            continue;
+        }
+        std::cout << i;
+    }
+}
+
+void rangeLoop(const std::vector<int>& xs) {
+    for (int x : xs) {
+        std::cout << x;
+    }
+}
+
+void whileLoop() {
+    int i = 0;
+    while (i < 10) {
+        i++;
+    }
+}
+
+void doWhileLoop() {
+    int i = 0;
+    do {
+        i++;
+    } while (i < 10);
+}
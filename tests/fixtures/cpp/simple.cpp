@@ -0,0 +1,41 @@
+// Straight-line code and simple branches
+
+#include <iostream>
+
+void simple() {
+    int x = 1;
+    std::cout << x;
+}
+
+int singleBranch(int x) {
+    int y = x;
+    if (y > 0) {
+        y += 1;
+    }
+    return y;
+}
+
+int ifElse(int x) {
+    if (x > 0) {
+        return x + 1;
+    } else {
+        return x - 1;
+    }
+}
+
+int earlyReturn(int x) {
+    if (x < 0) {
+        return -1;
+    }
+    return x * 2;
+}
+
+int multipleReturns(int x) {
+    if (x < 0) {
+        return -1;
+    }
+    if (x == 0) {
+        return 0;
+    }
+    return x * 2;
+}
@@ -0,0 +1,68 @@
+// switch cases fall through without a break
+
+#include <iostream>
+
+const char* simpleSwitch(int x) {
+    switch (x) {
+        case 1:
+            return "one";
+        case 2:
+            return "two";
+        default:
+            return "other";
+    }
+}
+
+void switchNoDefault(int x) {
+    switch (x) {
+        case 1:
+            std::cout << "one";
+            break;
+        case 2:
+            std::cout << "two";
+            break;
+    }
+}
+
+void switchFallthrough(int x) {
+    switch (x) {
+        case 1:
+            std::cout << "one";
+        case 2:
+            std::cout << "one or two";
+            break;
+        default:
+            std::cout << "other";
+            break;
+    }
+}
+
+void nestedSwitch(int x, int y) {
+    switch (x) {
+        case 1:
+            switch (y) {
+                case 1:
+                    std::cout << "1,1";
+                    break;
+                default:
+                    std::cout << "1,other";
+                    break;
+            }
+            break;
+        default:
+            std::cout << "other";
+            break;
+    }
+}
+
+const char* switchMultipleValues(int x) {
+    switch (x) {
+        case 1:
+        case 2:
+            return "low";
+        case 3:
+            return "mid";
+        default:
+            return "other";
+    }
+}
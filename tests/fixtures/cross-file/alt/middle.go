@@ -0,0 +1,20 @@
+package alt
+
+// A namesake of app's middle in another package, complex enough to be
+// reported first: top must not resolve to it.
+// Expected: fan_in=0
+func middle(n int) int {
+	total := 0
+	for i := 0; i < n; i++ {
+		if i%2 == 0 {
+			if i%3 == 0 {
+				total += i
+			} else {
+				total -= i
+			}
+		} else if i%5 == 0 {
+			total *= 2
+		}
+	}
+	return total
+}
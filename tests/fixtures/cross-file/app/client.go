@@ -0,0 +1,27 @@
+package app
+
+import (
+	"strings"
+
+	"example.com/cross-file/store"
+)
+
+type Client struct {
+	store *store.Store
+}
+
+// Expected: edge to store/store.go::New (package-qualified call)
+func NewClient() *Client {
+	return &Client{store: store.New()}
+}
+
+// Expected: edge to store/store.go::Store.Get (receiver-qualified call),
+// external_calls=1 (strings.ToUpper)
+func (c *Client) Lookup(key string) string {
+	return strings.ToUpper(c.store.Get(key))
+}
+
+// Expected: edge to Client.Lookup in the same file
+func (c *Client) Has(key string) bool {
+	return c.Lookup(key) != ""
+}
@@ -0,0 +1,11 @@
+package app
+
+// Expected: fan_in=1 (top), edge to helper in the same file
+func middle(n int) int {
+	return helper(n) + 1
+}
+
+// Expected: fan_in=1 (middle)
+func helper(n int) int {
+	return n * 2
+}
@@ -0,0 +1,10 @@
+package app
+
+import "fmt"
+
+// Calls middle, defined in another file of the same package, and one
+// standard library function.
+// Expected: edge to app/middle.go::middle, external_calls=1 (fmt.Println)
+func top(n int) {
+	fmt.Println(middle(n))
+}
@@ -0,0 +1,16 @@
+package store
+
+// Store is reached from app only through selector calls.
+type Store struct {
+	items map[string]string
+}
+
+// Expected: fan_in=1 (app NewClient via store.New)
+func New() *Store {
+	return &Store{items: map[string]string{}}
+}
+
+// Expected: fan_in=1 (app Client.Lookup via c.store.Get)
+func (s *Store) Get(key string) string {
+	return s.items[key]
+}
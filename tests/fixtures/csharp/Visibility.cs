@@ -0,0 +1,36 @@
+// Public API partition for --public-only:
+// public:  Account (constructor), Deposit, Total (interface default)
+// private: Apply, Round (local function), Audit (internal)
+
+public class Account
+{
+    public Account()
+    {
+    }
+
+    public decimal Deposit(decimal amount)
+    {
+        return Apply(amount);
+    }
+
+    private decimal Apply(decimal amount)
+    {
+        decimal Round(decimal value)
+        {
+            return value;
+        }
+        return Round(amount);
+    }
+
+    internal void Audit()
+    {
+    }
+}
+
+public interface ILedger
+{
+    decimal Total()
+    {
+        return 0;
+    }
+}
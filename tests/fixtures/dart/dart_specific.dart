@@ -0,0 +1,57 @@
+// Constructors, getters and setters, null-aware operators, `on` / `catch`,
+// and local functions
+
+import 'dart:io';
+
+class Account {
+  Account(this.owner, this._balance) {
+    if (_balance < 0) {
+      throw ArgumentError.value(_balance, 'balance');
+    }
+  }
+
+  final String owner;
+  int _balance;
+  int _limit = 0;
+
+  int get balance => _balance;
+
+  set limit(int value) {
+    _limit = value < 0 ? 0 : value;
+  }
+
+  String nickname(Map<String, String>? aliases) {
+    var name = aliases?.values.first;
+    name ??= owner;
+    return name.trim();
+  }
+
+  void withdraw(int amount) {
+    if (amount <= 0 || amount > _balance + _limit) {
+      throw StateError('invalid amount');
+    }
+    _balance -= amount;
+  }
+
+  Future<String> load(String path) async {
+    try {
+      return await read(path);
+    } on FormatException {
+      return '';
+    } on IOException catch (e) {
+      log(e.toString());
+      rethrow;
+    } finally {
+      close(path);
+    }
+  }
+
+  int total(List<int> deposits) {
+    int clamp(int value) {
+      if (value < 0) return 0;
+      return value;
+    }
+
+    return deposits.map(clamp).fold(0, (sum, d) => sum + d);
+  }
+}
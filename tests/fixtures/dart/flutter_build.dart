@@ -0,0 +1,57 @@
+// Flutter widgets: a widget tree nests deeply, but only control flow counts
+// toward ND, and closures belong to the method that builds them
+
+import 'package:flutter/material.dart';
+
+class ProfileCard extends StatelessWidget {
+  const ProfileCard({super.key, required this.user, this.onTap});
+
+  final User? user;
+  final VoidCallback? onTap;
+
+  @override
+  Widget build(BuildContext context) {
+    final theme = Theme.of(context);
+    return Card(
+      child: Padding(
+        padding: const EdgeInsets.all(16),
+        child: Column(
+          crossAxisAlignment: CrossAxisAlignment.start,
+          children: [
+            Text(user?.name ?? 'Anonymous', style: theme.textTheme.titleLarge),
+            if (user != null && user!.verified)
+              Row(
+                children: [
+                  const Icon(Icons.verified),
+                  for (final badge in user!.badges) Chip(label: Text(badge)),
+                ],
+              ),
+            TextButton(
+              onPressed: onTap,
+              child: const Text('Open'),
+            ),
+          ],
+        ),
+      ),
+    );
+  }
+}
+
+class Counter extends StatefulWidget {
+  const Counter({super.key});
+
+  @override
+  State<Counter> createState() => _CounterState();
+}
+
+class _CounterState extends State<Counter> {
+  int _count = 0;
+
+  void _increment() {
+    setState(() {
+      if (_count < 10) {
+        _count++;
+      }
+    });
+  }
+}
@@ -0,0 +1,51 @@
+// Loops, jumps, and collection `for` / `if`
+
+int sum(List<int> items) {
+  var total = 0;
+  for (final item in items) {
+    total += item;
+  }
+  return total;
+}
+
+int sumPositive(List<int> items) {
+  var total = 0;
+  for (var i = 0; i < items.length; i++) {
+    if (items[i] < 0) continue;
+    total += items[i];
+  }
+  return total;
+}
+
+int countdown(int n) {
+  while (n > 0) {
+    n--;
+  }
+  do {
+    n++;
+  } while (n < 3);
+  return n;
+}
+
+int? firstNegative(List<List<int>> rows) {
+  for (final row in rows) {
+    for (final value in row) {
+      if (value < 0) {
+        return value;
+      }
+    }
+  }
+  return null;
+}
+
+void drain(List<int> queue) {
+  while (true) {
+    if (queue.isEmpty) break;
+    print(queue.removeLast());
+  }
+}
+
+List<String> labels(List<int> ids, bool showAll) => [
+      for (final id in ids)
+        if (showAll || id > 0) 'item $id',
+    ];
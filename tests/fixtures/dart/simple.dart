@@ -0,0 +1,39 @@
+// Straight-line code and branches
+
+int simple(int x) => x + 1;
+
+int singleBranch(int x) {
+  if (x > 0) {
+    return 1;
+  }
+  return 0;
+}
+
+String ifElse(int x) {
+  if (x > 0) {
+    return 'positive';
+  } else {
+    return 'non-positive';
+  }
+}
+
+String classify(int x) {
+  if (x > 0) {
+    return 'positive';
+  } else if (x < 0) {
+    return 'negative';
+  } else {
+    return 'zero';
+  }
+}
+
+bool bothPositive(int a, int b) => a > 0 && b > 0;
+
+String ternary(int x) => x > 0 ? 'positive' : 'non-positive';
+
+String validate(String name) {
+  if (name.isEmpty) {
+    throw ArgumentError('empty name');
+  }
+  return name.trim();
+}
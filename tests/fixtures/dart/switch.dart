@@ -0,0 +1,48 @@
+// `switch` statements and switch expressions
+
+String describe(int code) {
+  switch (code) {
+    case 200:
+      return 'ok';
+    case 301:
+    case 302:
+      return 'redirect';
+    case 404:
+      return 'not found';
+    default:
+      return 'unknown';
+  }
+}
+
+void log(int level, String message) {
+  switch (level) {
+    case 0:
+      print('debug: $message');
+      break;
+    case 1:
+      print('info: $message');
+  }
+  print('done');
+}
+
+String size(int n) => switch (n) {
+      0 => 'none',
+      1 => 'one',
+      _ when n < 0 => 'invalid',
+      _ => 'many',
+    };
+
+String grade(int score) {
+  switch (score ~/ 10) {
+    case 10:
+    case 9:
+      return 'A';
+    case 8:
+      if (score % 10 >= 5) {
+        return 'B+';
+      }
+      return 'B';
+    default:
+      return 'C';
+  }
+}
@@ -0,0 +1,9 @@
+export function route(path: string): string {
+  if (path === "/") {
+    return "home";
+  }
+  if (path === "/about") {
+    return "about";
+  }
+  return "missing";
+}
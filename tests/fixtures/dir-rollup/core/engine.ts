@@ -0,0 +1,11 @@
+export function run(items: number[]): number {
+  let total = 0;
+  for (const item of items) {
+    if (item > 0) {
+      total += item;
+    }
+  }
+  return total;
+}
+
+export function stop(): void {}
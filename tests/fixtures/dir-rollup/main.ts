@@ -0,0 +1,6 @@
+export function main(x: number): number {
+  if (x > 0) {
+    return 1;
+  }
+  return 0;
+}
@@ -0,0 +1,38 @@
+# `cond`, `try`, `receive`, and comprehensions
+
+defmodule Control do
+  def grade(score) do
+    cond do
+      score >= 90 -> "A"
+      score >= 80 -> "B"
+      score >= 70 -> "C"
+      true -> "F"
+    end
+  end
+
+  def read_config(path) do
+    try do
+      path |> File.read!() |> Jason.decode!()
+    rescue
+      e in File.Error -> {:error, e.reason}
+      Jason.DecodeError -> {:error, :invalid_json}
+    after
+      Logger.debug("read #{path}")
+    end
+  end
+
+  def await_reply(ref, timeout) do
+    receive do
+      {^ref, :ok} -> :ok
+      {^ref, {:error, reason}} -> {:error, reason}
+    after
+      timeout -> exit(:timeout)
+    end
+  end
+
+  def evens(numbers) do
+    for n <- numbers, rem(n, 2) == 0, n > 0 do
+      if n > 100, do: :big, else: n
+    end
+  end
+end
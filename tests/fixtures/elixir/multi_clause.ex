@@ -0,0 +1,25 @@
+# Multi-clause functions: each clause after the first is a branch
+
+defmodule Shapes do
+  def area({:circle, r}), do: 3.14159 * r * r
+  def area({:square, side}), do: side * side
+  def area({:rect, w, h}), do: w * h
+
+  def fib(0), do: 0
+  def fib(1), do: 1
+
+  def fib(n) when is_integer(n) and n > 1 do
+    fib(n - 1) + fib(n - 2)
+  end
+
+  defp describe(%{name: name, role: :admin}), do: "#{name} (admin)"
+  defp describe(%{name: name}) when byte_size(name) > 0, do: name
+  defp describe(_), do: "anonymous"
+
+  def total(items) do
+    Enum.reduce(items, 0, fn
+      %{qty: qty, price: price}, acc when qty > 0 -> acc + qty * price
+      _, acc -> acc
+    end)
+  end
+end
@@ -0,0 +1,32 @@
+# Straight-line code, branches, boolean operators, and pipes
+
+defmodule Simple do
+  def simple(x), do: x + 1
+
+  def single_branch(x) do
+    if x > 0 do
+      :positive
+    end
+  end
+
+  def if_else(x) do
+    if x > 0, do: "positive", else: "non-positive"
+  end
+
+  def check(user) do
+    unless user.active and user.verified do
+      raise ArgumentError, "inactive user"
+    end
+
+    user
+  end
+
+  def both_positive(a, b), do: (a > 0 && b > 0) || a == b
+
+  defp normalize(name) do
+    name
+    |> String.trim()
+    |> String.downcase()
+    |> sanitize
+  end
+end
@@ -0,0 +1,36 @@
+# `with` chains: each `<-` match and each `else` clause is a branch
+
+defmodule Checkout do
+  def place_order(params) do
+    with {:ok, cart} <- fetch_cart(params),
+         {:ok, user} <- fetch_user(params),
+         :ok <- validate(cart, user) do
+      Orders.create(cart, user)
+    end
+  end
+
+  def place_order_safe(params) do
+    with {:ok, cart} <- fetch_cart(params),
+         total = Cart.total(cart),
+         true <- total > 0 do
+      {:ok, total}
+    else
+      {:error, :not_found} -> {:error, "cart not found"}
+      false -> {:error, "empty cart"}
+      _ -> raise "unexpected"
+    end
+  end
+
+  def charge(order) do
+    case Payments.charge(order) do
+      {:ok, receipt} ->
+        with {:ok, _} <- Mailer.send_receipt(receipt) do
+          {:ok, receipt}
+        end
+
+      {:error, reason} ->
+        Logger.error("charge failed: #{reason}")
+        {:error, reason}
+    end
+  end
+end
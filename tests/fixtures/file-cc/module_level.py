@@ -0,0 +1,31 @@
+# Module- and class-level code with four decision points (`or`, `if`, `for`,
+# and a conditional expression); the branching inside the functions and the
+# lambda is counted by their own CC only
+import os
+
+VERBOSE = os.environ.get("DEBUG") == "1" or os.environ.get("VERBOSE") == "1"
+
+if VERBOSE:
+    print("loading")
+
+for name in ("a", "b"):
+    os.environ.setdefault(name.upper(), "")
+
+
+class Registry:
+    label = "verbose" if VERBOSE else "quiet"
+
+    def register(self, key):
+        if not key:
+            raise ValueError("empty key")
+
+
+def clamp(x, high):
+    if x < 0:
+        return 0
+    if x > high:
+        return high
+    return x
+
+
+in_range = lambda x: x > 0 and x < 5
@@ -0,0 +1,30 @@
+// Module-scope code with three decision points (`||`, `if`, `for...of`);
+// the branching inside the functions is counted by their own CC only
+
+const verbose = process.env.DEBUG === "1" || process.env.VERBOSE === "1";
+
+if (verbose) {
+  console.log("registering handlers");
+}
+
+for (const key of ["a", "b"]) {
+  register(key);
+}
+
+export function register(key: string): void {
+  if (key.length === 0) {
+    throw new Error("empty key");
+  }
+}
+
+export function clamp(x: number, max: number): number {
+  if (x < 0) {
+    return 0;
+  }
+  if (x > max) {
+    return max;
+  }
+  return x;
+}
+
+export const inRange = (x: number): boolean => x > 0 && x < 5;
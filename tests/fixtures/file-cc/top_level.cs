@@ -0,0 +1,31 @@
+using System;
+
+// Top-level statements with three decision points (`&&`, `if`, `foreach`);
+// the branching inside the methods is counted by their own CC only
+var verbose = args.Length > 0 && args[0] == "-v";
+if (verbose)
+{
+    Console.WriteLine("verbose");
+}
+
+foreach (var arg in args)
+{
+    Console.WriteLine(Greeter.Greet(arg));
+}
+
+static class Greeter
+{
+    public static string Greet(string name)
+    {
+        if (string.IsNullOrEmpty(name))
+        {
+            return "hello";
+        }
+        return "hello " + name;
+    }
+
+    public static int Clamp(int value)
+    {
+        return value < 0 ? 0 : value;
+    }
+}
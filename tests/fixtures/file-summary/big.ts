@@ -0,0 +1,22 @@
+export function classify(x: number): string {
+  if (x < 0) {
+    return "negative";
+  }
+  if (x === 0) {
+    return "zero";
+  }
+  if (x < 10) {
+    return "small";
+  }
+  if (x < 100) {
+    return "medium";
+  }
+  if (x < 1000) {
+    return "large";
+  }
+  return "huge";
+}
+
+export function identity(x: number): number {
+  return x;
+}
@@ -0,0 +1,30 @@
+export function a(x: number): number {
+  if (x > 0) {
+    return 1;
+  }
+  return 0;
+}
+
+export function b(x: number): number {
+  if (x > 1) {
+    return 1;
+  }
+  return 0;
+}
+
+export function c(x: number): number {
+  if (x > 2) {
+    if (x > 3) {
+      return 2;
+    }
+    return 1;
+  }
+  return 0;
+}
+
+export function d(x: number): number {
+  if (x > 4) {
+    return 1;
+  }
+  return 0;
+}
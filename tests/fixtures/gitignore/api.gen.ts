@@ -0,0 +1,3 @@
+export function generatedClient(id: string): string {
+  return `/api/${id}`;
+}
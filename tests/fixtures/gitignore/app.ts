@@ -0,0 +1,6 @@
+export function start(port: number): string {
+  if (port > 0) {
+    return `listening on ${port}`;
+  }
+  return "disabled";
+}
@@ -0,0 +1,3 @@
+function bundled(x) {
+  return x + 1;
+}
@@ -0,0 +1,3 @@
+export function keptClient(id: string): string {
+  return `/keep/${id}`;
+}
@@ -0,0 +1,5 @@
+package lib
+
+func LocalOnly() int {
+	return 1
+}
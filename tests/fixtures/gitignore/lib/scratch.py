@@ -0,0 +1,2 @@
+def lib_scratch(value):
+    return value + 1
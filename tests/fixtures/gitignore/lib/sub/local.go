@@ -0,0 +1,5 @@
+package sub
+
+func SubLocal() int {
+	return 2
+}
@@ -0,0 +1,2 @@
+def scratch(value):
+    return value * 2
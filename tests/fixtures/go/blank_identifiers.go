@@ -0,0 +1,29 @@
+package blank
+
+import (
+	"fmt"
+	_ "net/http/pprof"
+)
+
+// compute has fo=0
+func compute() int {
+	return 1
+}
+
+// discardCall has fo=1: the result is discarded but compute is still called
+func discardCall() {
+	_ = compute()
+}
+
+// discardValue has fo=0: `_ = x` is a no-op for every metric
+func discardValue(x int) {
+	_ = x
+}
+
+// discardMany has fo=2: blanks on either side of a tuple assignment add nothing
+func discardMany(x int) {
+	_, _ = compute(), x
+	for _, v := range []int{x} {
+		fmt.Println(v)
+	}
+}
@@ -1,16 +1,16 @@
 package callgraph
 
-// helper has fo=0 (no calls)
+// helper has fo=0 (no calls), fi=2 (called by middle and top)
 func helper() int {
 	return 1
 }
 
-// middle has fo=1 (calls helper twice, deduplicated to 1 unique callee)
+// middle has fo=1 (calls helper twice, deduplicated to 1 unique callee), fi=1 (called by top)
 func middle() int {
 	return helper() + helper()
 }
 
-// top has fo=2 (calls helper + middle)
+// top has fo=2 (calls helper + middle), fi=0
 func top() int {
 	return helper() + middle()
 }
@@ -0,0 +1,28 @@
+package fixtures
+
+// Classify has decision points on known lines
+// Expected cc_lines: 7 loop, 8 if + and x2, 10 if + or, 15/17/19 case
+func Classify(items []int, strict bool) string {
+	count := 0
+	for _, item := range items {
+		if item > 0 && item < 100 && item != 7 {
+			count++
+		} else if strict || item == 0 {
+			return "invalid"
+		}
+	}
+	switch count {
+	case 0:
+		return "empty"
+	case 1, 2:
+		return "few"
+	default:
+		return "many"
+	}
+}
+
+// Straight has no decision points
+// Expected cc_lines: none
+func Straight(x int) int {
+	return x + 1
+}
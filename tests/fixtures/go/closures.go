@@ -0,0 +1,51 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Fans work out to goroutines; the closure does the branching
+func ProcessAll(items []int) int {
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	total := 0
+	for _, item := range items {
+		wg.Add(1)
+		go func(n int) {
+			defer wg.Done()
+			if n < 0 {
+				return
+			}
+			mu.Lock()
+			total += n
+			mu.Unlock()
+		}(item)
+	}
+	wg.Wait()
+	return total
+}
+
+// Builds a predicate from a helper closure and a nested one
+func makeFilter(limit int) func(int) bool {
+	check := func(n int) bool {
+		if n > limit || n < 0 {
+			return false
+		}
+		return true
+	}
+	return func(n int) bool {
+		even := func() bool {
+			return n%2 == 0
+		}
+		return check(n) && even()
+	}
+}
+
+// No closures: the same with or without --separate-closures
+func describe(n int) string {
+	if n > 0 {
+		return fmt.Sprintf("positive %d", n)
+	}
+	return "non-positive"
+}
@@ -0,0 +1,40 @@
+// Dead-code candidates for --dead-code:
+// listed:     unusedHelper
+// not listed: init, main (entry points), Exported (public), format (called),
+//             flush (called as a method)
+package main
+
+import "fmt"
+
+type buffer struct {
+	lines []string
+}
+
+func init() {
+	fmt.Println("ready")
+}
+
+func main() {
+	b := &buffer{}
+	b.flush(format("start"))
+}
+
+func (b *buffer) flush(line string) {
+	b.lines = append(b.lines, line)
+	fmt.Println(b.lines)
+}
+
+func format(s string) string {
+	return "[" + s + "]"
+}
+
+func Exported() string {
+	return format("api")
+}
+
+func unusedHelper(n int) int {
+	if n > 0 {
+		return n * 2
+	}
+	return 0
+}
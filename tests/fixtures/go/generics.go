@@ -0,0 +1,77 @@
+package fixtures
+
+// Methods of a generic type and of a plain type with the same bodies: type
+// parameters on the receiver must not change names beyond the receiver type,
+// nor any metric.
+// Expected names: Stack.Push, Stack.Pop, Stack.Len, Pair.Swap,
+//                 IntStack.Push, IntStack.Pop, IntStack.Len, Map
+
+type Stack[T any] struct {
+	items []T
+}
+
+type IntStack struct {
+	items []int
+}
+
+type Pair[K comparable, V any] struct {
+	key   K
+	value V
+}
+
+// Pointer receiver with a type parameter
+// Expected: params=1, FO=1 (append)
+func (s *Stack[T]) Push(item T) {
+	s.items = append(s.items, item)
+}
+
+// Expected: params=0, FO=1 (len), NS=2
+func (s *Stack[T]) Pop() (T, bool) {
+	var zero T
+	if len(s.items) == 0 {
+		return zero, false
+	}
+	item := s.items[len(s.items)-1]
+	s.items = s.items[:len(s.items)-1]
+	return item, true
+}
+
+// Value receiver with a type parameter
+// Expected: params=0, FO=1 (len), NS=1
+func (s Stack[T]) Len() int {
+	return len(s.items)
+}
+
+// Receiver with two type parameters
+// Expected: params=1, FO=0
+func (p *Pair[K, V]) Swap(other *Pair[K, V]) {
+	p.key, other.key = other.key, p.key
+}
+
+func (s *IntStack) Push(item int) {
+	s.items = append(s.items, item)
+}
+
+func (s *IntStack) Pop() (int, bool) {
+	var zero int
+	if len(s.items) == 0 {
+		return zero, false
+	}
+	item := s.items[len(s.items)-1]
+	s.items = s.items[:len(s.items)-1]
+	return item, true
+}
+
+func (s IntStack) Len() int {
+	return len(s.items)
+}
+
+// Generic function: type parameters are not parameters
+// Expected: params=2, FO=4 (make, len, append, f)
+func Map[T, U any](xs []T, f func(T) U) []U {
+	out := make([]U, 0, len(xs))
+	for _, x := range xs {
+		out = append(out, f(x))
+	}
+	return out
+}
@@ -0,0 +1,24 @@
+package fixtures
+
+// Commented mixes code, comments, and a blank line
+// Expected: LOC=11, SLOC=6, comment=4, blank=1
+func Commented(x int) int {
+	// Guard against negatives
+	if x < 0 {
+		return 0 // clamp
+	}
+
+	/*
+	   Double the rest
+	*/
+	return x * 2
+}
+
+// Template returns a raw string whose lines look like comments and blanks
+// Expected: LOC=6, SLOC=6, comment=0, blank=0
+func Template() string {
+	return `
+// not a comment
+
+/* nor this */`
+}
@@ -0,0 +1,67 @@
+package fixtures
+
+import (
+	conv "strconv"
+	"strings"
+)
+
+type Store struct {
+	items map[string]int
+}
+
+type Cache struct {
+	store *Store
+}
+
+type Reader interface {
+	Read(key string) int
+}
+
+// Expected: FO=0
+func (s *Store) Get(key string) int {
+	return s.items[key]
+}
+
+// Expected: FO=0
+func (s *Store) Put(key string, v int) {
+	s.items[key] = v
+}
+
+// Method calls on two receivers, a repeated call, and a package function
+// Expected: FO=4 (strings.ToLower, c.store.Get, c.store.Put, s.Get); the
+// second c.store.Get adds nothing. Without method calls FO=1 (strings.ToLower)
+func (c *Cache) Refresh(s *Store, key string) {
+	k := strings.ToLower(key)
+	v := c.store.Get(k)
+	c.store.Put(k, v+s.Get(k))
+	c.store.Get(k)
+}
+
+// The same method on two receivers counts twice
+// Expected: FO=2 (a.Get, b.Get); without method calls FO=0
+func Merge(a, b *Store, key string) int {
+	return a.Get(key) + b.Get(key)
+}
+
+// Each segment of a chain is a call of its own
+// Expected: FO=2 (c.Backing, c.Backing().Get); without method calls FO=0
+func (c *Cache) Lookup(key string) int {
+	return c.Backing().Get(key)
+}
+
+// Expected: FO=0
+func (c *Cache) Backing() *Store {
+	return c.store
+}
+
+// An interface method counts once, whatever implementation runs
+// Expected: FO=1 (r.Read); without method calls FO=0
+func Load(r Reader, key string) int {
+	return r.Read(key)
+}
+
+// A package imported under an alias is still a package, not a receiver
+// Expected: FO=2 (conv.Itoa, s.Get); without method calls FO=1 (conv.Itoa)
+func Describe(s *Store, key string) string {
+	return conv.Itoa(s.Get(key))
+}
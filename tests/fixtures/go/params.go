@@ -0,0 +1,32 @@
+package fixtures
+
+import "fmt"
+
+type Point struct {
+	X, Y int
+}
+
+// Grouped declares three parameters in two groups
+// Expected: params=3
+func Grouped(a, b int, label string) string {
+	return fmt.Sprintf("%s: %d", label, a+b)
+}
+
+// Variadic counts the variadic parameter once
+// Expected: params=2
+func Variadic(format string, args ...interface{}) string {
+	return fmt.Sprintf(format, args...)
+}
+
+// Translate excludes its receiver
+// Expected: params=2
+func (p *Point) Translate(dx, dy int) {
+	p.X += dx
+	p.Y += dy
+}
+
+// Origin declares nothing but its receiver
+// Expected: params=0
+func (p Point) Origin() bool {
+	return p.X == 0 && p.Y == 0
+}
@@ -0,0 +1,26 @@
+// Public API partition for --public-only:
+// public:  NewStore, Store.Add
+// private: Store.trim, validate
+package visibility
+
+type Store struct {
+	items []string
+}
+
+func NewStore() *Store {
+	return &Store{}
+}
+
+func (s *Store) Add(item string) {
+	if validate(item) {
+		s.items = append(s.items, item)
+	}
+}
+
+func (s *Store) trim() {
+	s.items = s.items[:0]
+}
+
+func validate(item string) bool {
+	return item != ""
+}
@@ -0,0 +1,157 @@
+// --god-functions fixtures: processOrder is long, branches a lot, and calls
+// many functions at once; renderReport is only long and calls many
+// functions, and classify only branches a lot
+
+export function processOrder(order: Order, user: User): Result {
+  validateOrder(order);
+  const total = computeTotal(order);
+  let discount = 0;
+
+  if (!user) {
+    return fail("missing user");
+  }
+  if (user.banned) {
+    logEvent("banned", user.id);
+    return fail("banned");
+  }
+  if (user.vip) {
+    discount = applyDiscount(total, 0.2);
+  } else if (user.orders > 10) {
+    discount = applyDiscount(total, 0.1);
+  } else if (order.coupon) {
+    discount = applyDiscount(total, 0.05);
+  }
+
+  for (const item of order.items) {
+    if (item.quantity <= 0) {
+      logEvent("empty-item", item.id);
+      continue;
+    }
+    if (item.backordered && !item.substitute) {
+      notifyWarehouse(item);
+    }
+    updateInventory(item);
+  }
+
+  const charged = chargeCard(user.card, total - discount);
+  if (!charged) {
+    logEvent("charge-failed", user.id);
+    return fail("payment declined");
+  }
+
+  if (order.express) {
+    scheduleDelivery(order, "express");
+  } else if (order.pickup) {
+    scheduleDelivery(order, "pickup");
+  } else {
+    scheduleDelivery(order, "standard");
+  }
+
+  if (user.email && user.notifications) {
+    sendEmail(user.email, "Order confirmed");
+  }
+  if (order.gift) {
+    sendEmail(order.gift.recipient, "A gift is on its way");
+  }
+
+  recordMetrics("order", total);
+  if (total > 1000) {
+    recordMetrics("large-order", total);
+  }
+  if (order.items.length > 20) {
+    logEvent("bulk-order", order.id);
+  }
+
+  return {
+    ok: true,
+    total: total - discount,
+    discount,
+  };
+}
+
+export function renderReport(report: Report): string {
+  const lines: string[] = [];
+  lines.push(renderHeader(report.header));
+  lines.push("");
+  lines.push("---");
+  lines.push(pageBreak);
+  lines.push("");
+  lines.push(renderSummary(report.summary));
+  lines.push("");
+  lines.push("---");
+  lines.push(pageBreak);
+  lines.push("");
+  lines.push(renderOwners(report.owners));
+  lines.push("");
+  lines.push("---");
+  lines.push(pageBreak);
+  lines.push("");
+  lines.push(renderFiles(report.files));
+  lines.push("");
+  lines.push("---");
+  lines.push(pageBreak);
+  lines.push("");
+  lines.push(renderFunctions(report.functions));
+  lines.push("");
+  lines.push("---");
+  lines.push(pageBreak);
+  lines.push("");
+  lines.push(renderPatterns(report.patterns));
+  lines.push("");
+  lines.push("---");
+  lines.push(pageBreak);
+  lines.push("");
+  lines.push(renderTrends(report.trends));
+  lines.push("");
+  lines.push("---");
+  lines.push(pageBreak);
+  lines.push("");
+  lines.push(renderChurn(report.churn));
+  lines.push("");
+  lines.push("---");
+  lines.push(pageBreak);
+  lines.push("");
+  lines.push(renderCoverage(report.coverage));
+  lines.push("");
+  lines.push("---");
+  lines.push(pageBreak);
+  lines.push("");
+  lines.push(renderBudgets(report.budgets));
+  lines.push("");
+  lines.push("---");
+  lines.push(pageBreak);
+  lines.push("");
+  lines.push(renderPolicies(report.policies));
+  lines.push("");
+  lines.push("---");
+  lines.push(pageBreak);
+  lines.push("");
+  lines.push(renderFooter(report.footer));
+  lines.push("");
+  lines.push("---");
+  lines.push(pageBreak);
+  lines.push("");
+  return lines.join("\n");
+}
+
+export function classify(code: string): number {
+  switch (code) {
+    case "a": return 1;
+    case "b": return 2;
+    case "c": return 3;
+    case "d": return 4;
+    case "e": return 5;
+    case "f": return 6;
+    case "g": return 7;
+    case "h": return 8;
+    case "i": return 9;
+    case "j": return 10;
+    case "k": return 11;
+    case "l": return 12;
+    case "m": return 13;
+    case "n": return 14;
+    case "o": return 15;
+    case "p": return 16;
+    default: return 0;
+  }
+}
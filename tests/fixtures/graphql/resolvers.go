@@ -0,0 +1,22 @@
+package graph
+
+import "context"
+
+type queryResolver struct{}
+
+type userResolver struct{}
+
+func (r *queryResolver) Viewer(ctx context.Context) (*User, error) {
+	if ctx == nil {
+		return nil, nil
+	}
+	return &User{}, nil
+}
+
+func (r *userResolver) Initials(ctx context.Context, obj *User) (string, error) {
+	return obj.First[:1], nil
+}
+
+func helper() int {
+	return 1
+}
@@ -0,0 +1,58 @@
+// GraphQL resolver map fixture for `--mode resolvers`.
+
+interface Ctx {
+  db: { find(id: string): User | null; all(): User[] };
+  viewer?: User;
+}
+
+interface User {
+  id: string;
+  first: string;
+  last?: string;
+  admin: boolean;
+  banned: boolean;
+}
+
+function formatName(first: string, last?: string): string {
+  return last ? `${first} ${last}` : first;
+}
+
+export const resolvers = {
+  Query: {
+    user(_parent: unknown, args: { id: string }, ctx: Ctx) {
+      const found = ctx.db.find(args.id);
+      if (!found) {
+        return null;
+      }
+      if (found.banned && !(ctx.viewer && ctx.viewer.admin)) {
+        return null;
+      }
+      return found;
+    },
+    users: (_parent: unknown, args: { admins?: boolean }, ctx: Ctx) => {
+      const all = ctx.db.all();
+      return args.admins ? all.filter((u) => u.admin) : all;
+    },
+  },
+  Mutation: {
+    banUser(_parent: unknown, args: { id: string }, ctx: Ctx) {
+      if (!ctx.viewer || !ctx.viewer.admin) {
+        throw new Error("forbidden");
+      }
+      const found = ctx.db.find(args.id);
+      if (!found) {
+        throw new Error("not found");
+      }
+      for (const u of ctx.db.all()) {
+        if (u.id === found.id) {
+          u.banned = true;
+        }
+      }
+      return found;
+    },
+  },
+  User: {
+    fullName: (user: User) => formatName(user.first, user.last),
+    nickname: (user: User) => user.first.toLowerCase(),
+  },
+};
@@ -0,0 +1,36 @@
+// Guard clauses vs. nesting: both functions do the same work with CC 6.
+
+function saveGuarded(user: User | null, items: Item[]): number {
+  if (!user) return -1;
+  if (!user.active) {
+    return -2;
+  }
+  if (items.length === 0) throw new Error("nothing to save");
+  let saved = 0;
+  for (const item of items) {
+    if (item.skip) continue;
+    saved += store(user, item);
+  }
+  return saved;
+}
+
+function saveNested(user: User | null, items: Item[]): number {
+  let saved = -1;
+  if (user) {
+    if (user.active) {
+      if (items.length > 0) {
+        saved = 0;
+        for (const item of items) {
+          if (!item.skip) {
+            saved += store(user, item);
+          }
+        }
+      } else {
+        throw new Error("nothing to save");
+      }
+    } else {
+      saved = -2;
+    }
+  }
+  return saved;
+}
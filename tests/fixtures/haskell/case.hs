@@ -0,0 +1,53 @@
+-- `case` alternatives, `\case`, and multi-way `if`
+
+{-# LANGUAGE LambdaCase #-}
+{-# LANGUAGE MultiWayIf #-}
+
+module Case where
+
+data Shape = Circle Double | Rect Double Double | Triangle Double Double Double
+
+area :: Shape -> Double
+area shape = case shape of
+  Circle r -> pi * r * r
+  Rect w h -> w * h
+  Triangle a b c ->
+    let s = (a + b + c) / 2
+     in sqrt (s * (s - a) * (s - b) * (s - c))
+
+-- A final `_` alternative is the default, not a branch of its own
+describe :: Maybe Int -> String
+describe m = case m of
+  Just 0 -> "zero"
+  Just n
+    | n > 0 -> "positive"
+    | otherwise -> "negative"
+  _ -> "nothing"
+
+parseCommand :: String -> Either String Int
+parseCommand input = case words input of
+  ["add", n] -> Right (read n)
+  ["neg", n] -> Right (negate (read n))
+  [] -> Left "empty"
+  other -> error ("unknown command: " ++ unwords other)
+
+direction :: Int -> String
+direction = \case
+  0 -> "north"
+  1 -> "east"
+  2 -> "south"
+  _ -> "west"
+
+fizzbuzz :: Int -> String
+fizzbuzz n = if
+  | n `mod` 15 == 0 -> "FizzBuzz"
+  | n `mod` 3 == 0 -> "Fizz"
+  | n `mod` 5 == 0 -> "Buzz"
+  | otherwise -> show n
+
+combine :: Maybe Int -> Maybe Int -> Int
+combine a b = case a of
+  Nothing -> 0
+  Just x -> case b of
+    Nothing -> x
+    Just y -> x + y
@@ -0,0 +1,26 @@
+-- Class and instance methods are reported under the class, or the type of
+-- the instance
+
+module Shapes (Shape (..), HasArea (..), describe) where
+
+data Shape = Circle Double | Square Double
+
+class HasArea a where
+  area :: a -> Double
+  perimeter :: a -> Double
+  perimeter _ = 0
+
+instance HasArea Shape where
+  area (Circle r) = pi * r * r
+  area (Square s) = s * s
+  perimeter shape = case shape of
+    Circle r -> 2 * pi * r
+    Square s -> 4 * s
+
+describe :: Shape -> String
+describe s
+  | area s > 100 = "large"
+  | otherwise = "small"
+
+unitCircle :: Shape
+unitCircle = Circle 1
@@ -0,0 +1,42 @@
+-- `do` blocks: statements run in order; `when` and `unless` run an action
+-- only when a condition holds
+
+module Main (main) where
+
+import Control.Monad (forM_, unless, when)
+import System.Directory (doesFileExist)
+import System.Environment (getArgs)
+import System.Exit (exitFailure)
+
+main :: IO ()
+main = do
+  args <- getArgs
+  when (null args) $ do
+    putStrLn "usage: tool FILE"
+    exitFailure
+  contents <- readFile (head args)
+  let count = length (lines contents)
+  unless (count > 0) $ putStrLn "empty file"
+  report count
+
+report :: Int -> IO ()
+report n = do
+  putStrLn ("lines: " ++ show n)
+  when (n > 1000) $
+    when (n > 100000) $
+      putStrLn "very large file"
+
+safeRead :: FilePath -> IO (Maybe String)
+safeRead path = do
+  exists <- doesFileExist path
+  if exists
+    then Just <$> readFile path
+    else return Nothing
+
+-- The lambda's `case` belongs to the function, outside its control flow
+processAll :: [FilePath] -> IO ()
+processAll paths = forM_ paths $ \path -> do
+  result <- safeRead path
+  case result of
+    Just text -> putStrLn text
+    Nothing -> putStrLn ("missing: " ++ path)
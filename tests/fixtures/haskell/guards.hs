@@ -0,0 +1,46 @@
+-- Guards and multi-equation functions: each equation after the first is a
+-- branch, and so is each guard other than `otherwise`
+
+module Guards (bmi, fib, factorial, grade, lookupAge, clamp) where
+
+bmi :: Double -> String
+bmi x
+  | x < 18.5 = "underweight"
+  | x < 25.0 = "normal"
+  | x < 30.0 = "overweight"
+  | otherwise = "obese"
+
+fib :: Int -> Integer
+fib 0 = 0
+fib 1 = 1
+fib n
+  | n < 0 = error "negative argument"
+  | otherwise = fib (n - 1) + fib (n - 2)
+
+factorial :: Integer -> Integer
+factorial 0 = 1
+factorial n = n * factorial (n - 1)
+
+-- A guard with two conditions
+grade :: Int -> Char
+grade score
+  | score >= 90, score <= 100 = 'A'
+  | score >= 80 = 'B'
+  | score >= 70 = 'C'
+  | otherwise = 'F'
+
+-- Without `otherwise`, a value no guard accepts falls to the next equation
+lookupAge :: String -> [(String, Int)] -> Int
+lookupAge name people
+  | Just age <- lookup name people, age >= 0 = age
+  | null people = -1
+lookupAge _ _ = 0
+
+clamp :: Int -> Int -> Int -> Int
+clamp lo hi x
+  | x < lo = lo
+  | x > hi = hi
+  | otherwise = x
+
+isVowel :: Char -> Bool
+isVowel c = c `elem` "aeiou" || c `elem` "AEIOU"
@@ -0,0 +1,34 @@
+-- Simple functions: straight-line code, if/then/else, and boolean operators
+
+module Simple where
+
+double :: Int -> Int
+double x = x * 2
+
+sign :: Int -> String
+sign x = if x > 0 then "positive" else "non-positive"
+
+-- An `else if` continues the chain without nesting
+classify :: Int -> String
+classify x =
+  if x > 0
+    then "positive"
+    else
+      if x < 0
+        then "negative"
+        else "zero"
+
+inRange :: Int -> Int -> Int -> Bool
+inRange lo hi x = x >= lo && x <= hi
+
+validUser :: String -> Int -> Bool
+validUser name age = not (null name) && (age >= 18 || age == 0)
+
+-- A value bound by `where` belongs to the function
+describe :: Int -> String
+describe n = "value: " ++ show n ++ suffix
+  where
+    suffix = if even n then " (even)" else " (odd)"
+
+main :: IO ()
+main = putStrLn (sign 3)
@@ -0,0 +1,31 @@
+-- Functions in `where` clauses are measured on their own; values bound by
+-- `where` and functions bound by `let` belong to the enclosing function
+
+module Stats (mean, variance, histogram) where
+
+mean :: [Double] -> Double
+mean xs
+  | null xs = 0
+  | otherwise = total / count
+  where
+    total = sum xs
+    count = fromIntegral (length xs)
+
+variance :: [Double] -> Double
+variance xs = sumSquares xs / fromIntegral (length xs)
+  where
+    m = mean xs
+    sumSquares [] = 0
+    sumSquares (y : ys) = square (y - m) + sumSquares ys
+    square d = d * d
+
+histogram :: [Int] -> [(Int, Int)]
+histogram = go []
+  where
+    go acc [] = reverse acc
+    go acc (x : xs) =
+      let bump [] = [(x, 1)]
+          bump ((k, n) : rest)
+            | k == x = (k, n + 1) : rest
+            | otherwise = (k, n) : bump rest
+       in go (bump acc) xs
@@ -0,0 +1,35 @@
+// Public API partition for --public-only:
+// public:  Visibility (constructor), total, greetAll (interface default)
+// private: clamp, reset (protected), packagePrivate
+
+public class Visibility {
+    public Visibility() {
+    }
+
+    public int total(int[] xs) {
+        int sum = 0;
+        for (int x : xs) {
+            sum += clamp(x);
+        }
+        return sum;
+    }
+
+    private int clamp(int x) {
+        return x < 0 ? 0 : x;
+    }
+
+    protected void reset() {
+    }
+
+    int packagePrivate() {
+        return 0;
+    }
+}
+
+interface Greeter {
+    String greet(String name);
+
+    default String greetAll(String[] names) {
+        return String.join(", ", names);
+    }
+}
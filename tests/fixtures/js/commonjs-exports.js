@@ -0,0 +1,15 @@
+// CommonJS exports are named after the property they are assigned to;
+// `module.exports` itself is the default export: default, parse, render
+
+module.exports = function (input) {
+  return input.trim();
+};
+
+module.exports.parse = function (input) {
+  if (!input) {
+    return null;
+  }
+  return JSON.parse(input);
+};
+
+exports.render = (value) => String(value);
@@ -0,0 +1,13 @@
+# Windows line endings: every line of this file ends with CRLF
+
+def first(x):
+    if x > 0:
+        return x
+    return -x
+
+
+def second(items):
+    count = 0
+    for item in items:
+        count += 1
+    return count
@@ -0,0 +1,19 @@
+// Windows line endings: every line of this file ends with CRLF
+
+export function first(x: number): number {
+  if (x > 0) {
+    return x;
+  }
+  return -x;
+}
+
+// hotspots-ignore: legacy parser
+export function second(items: string[]): number {
+	let count = 0;
+	for (const item of items) {
+		if (item.length > 0) {
+			count++;
+		}
+	}
+	return count;
+}
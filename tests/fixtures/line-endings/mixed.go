@@ -0,0 +1,12 @@
+package main
+
+// Mixed line endings: CRLF, and a lone CR after add
+
+func add(a, b int) int {
+	return a + b
+}func clamp(x int) int {
+	if x < 0 {
+		return 0
+	}
+	return x
+}
@@ -0,0 +1,41 @@
+-- `and` / `or` in conditions and as value defaults
+
+local function with_and(x, y)
+  if x > 0 and y > 0 then
+    print("both positive")
+  end
+end
+
+local function with_or(x, y)
+  if x > 0 or y > 0 then
+    print("at least one positive")
+  end
+end
+
+local function multiple_ops(x, y, z)
+  if x > 0 and y > 0 and z > 0 or x < 0 then
+    return 1
+  end
+  return 0
+end
+
+local function default_name(opts)
+  local name = opts.name or "anonymous"
+  return name
+end
+
+local function label(x)
+  local text = x > 0 and "positive" or "non-positive"
+  return text
+end
+
+local function nested_with_ops(x, y, z)
+  if x > 0 then
+    if y > 0 and z > 0 then
+      if x > 10 or y > 10 then
+        return 1
+      end
+    end
+  end
+  return 0
+end
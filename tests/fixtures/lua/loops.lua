@@ -0,0 +1,49 @@
+-- Numeric and generic `for`, `while`, `repeat ... until`, and `break`
+
+local function sum(items)
+  local total = 0
+  for _, item in ipairs(items) do
+    total = total + item
+  end
+  return total
+end
+
+local function count_up(n)
+  for i = 1, n do
+    print(i)
+  end
+end
+
+local function first_negative(items)
+  for i = 1, #items do
+    if items[i] < 0 then
+      return i
+    end
+  end
+  return nil
+end
+
+local function drain(queue)
+  while #queue > 0 do
+    local item = table.remove(queue)
+    if item == nil then
+      break
+    end
+    process(item)
+  end
+end
+
+local function countdown(n)
+  repeat
+    n = n - 1
+  until n <= 0
+  return n
+end
+
+local function grid(rows, cols)
+  for r = 1, rows do
+    for c = 1, cols do
+      print(r, c)
+    end
+  end
+end
@@ -0,0 +1,66 @@
+-- Function kinds and naming: methods, table fields, anonymous and local
+-- functions, `error`, and `goto`
+
+local Account = {}
+Account.__index = Account
+
+function Account.new(balance)
+  local self = setmetatable({}, Account)
+  self.balance = balance or 0
+  return self
+end
+
+function Account:withdraw(amount)
+  if amount > self.balance then
+    error("insufficient funds")
+  end
+  self.balance = self.balance - amount
+end
+
+local M = {}
+
+M.handlers = {
+  start = function(state)
+    state.running = true
+  end,
+}
+
+M.on_event = function(event)
+  for _, handler in pairs(M.handlers) do
+    handler(event)
+  end
+end
+
+function M.retry(task, attempts)
+  local n = 0
+  ::again::
+  n = n + 1
+  if not task() and n < attempts then
+    goto again
+  end
+  return n
+end
+
+function M.sorted(items)
+  local copy = { table.unpack(items) }
+  table.sort(copy, function(a, b)
+    return a > b
+  end)
+  return copy
+end
+
+function M.outer(xs)
+  local function inner(x)
+    if x > 0 then
+      return x
+    end
+    return 0
+  end
+  local total = 0
+  for _, x in ipairs(xs) do
+    total = total + inner(x)
+  end
+  return total
+end
+
+return M
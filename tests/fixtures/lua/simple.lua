@@ -0,0 +1,51 @@
+-- Straight-line code, branches, and early returns
+
+local function simple()
+  local x = 1
+  return x
+end
+
+local function single_branch(x)
+  if x > 0 then
+    x = x + 1
+  end
+  return x
+end
+
+local function if_else(x)
+  if x > 0 then
+    return x + 1
+  else
+    return x - 1
+  end
+end
+
+local function early_return(x)
+  if x < 0 then
+    return -1
+  end
+  return x * 2
+end
+
+local function sign(x)
+  if x > 0 then
+    return 1
+  elseif x < 0 then
+    return -1
+  else
+    return 0
+  end
+end
+
+local function describe(n)
+  local label = "many"
+  if n == 0 then
+    label = "none"
+  elseif n == 1 then
+    label = "one"
+  elseif n < 5 then
+    label = "few"
+  end
+  print(label)
+  return label
+end
@@ -95,7 +95,7 @@ function godAndLong(a: any, b: any, c: any, d: any, e: any): any {
 }
 
 // Triggers: complex_branching (CC>=10, ND>=4) but NOT deeply_nested (ND<5) or exit_heavy (NS<5).
-// Uses independent ifs at depth 4; switch breaks inflate NS so we avoid them.
+// Uses independent ifs at depth 4 (the a/b/c chain also triggers arrow_code); switch breaks inflate NS.
 function complexBranching(a: number, b: number, c: number, d: number): string {
   let result = "";
   if (a > 0) {
@@ -116,7 +116,7 @@ function complexBranching(a: number, b: number, c: number, d: number): string {
   return result;
 }
 
-// Triggers: deeply_nested alone (ND>=5, CC<10). No early returns.
+// Triggers: deeply_nested (ND>=5, CC<10). No early returns, so also arrow_code.
 function deeplyNested(a: any, b: any, c: any, d: any, e: any): string {
   let result = "";
   if (a) {
@@ -0,0 +1,51 @@
+# Boolean operators, defined-or, and the conditional operator
+
+use strict;
+use warnings;
+
+sub with_and {
+    my ($x, $y) = @_;
+    if ($x > 0 && $y > 0) {
+        return 1;
+    }
+    return 0;
+}
+
+sub with_or {
+    my ($x, $y) = @_;
+    if ($x < 0 || $y < 0) {
+        report($x, $y);
+    }
+}
+
+sub word_ops {
+    my ($path) = @_;
+    open_file($path) or die "cannot open $path";
+    ready($path) and start($path);
+    return 1;
+}
+
+sub default_name {
+    my ($name) = @_;
+    my $display = $name // "guest";
+    return $display;
+}
+
+sub label {
+    my ($n) = @_;
+    return $n > 0 ? "positive" : "non-positive";
+}
+
+sub nested_with_ops {
+    my ($x, $y, $z) = @_;
+    if ($x && $y) {
+        if ($y || $z) {
+            if ($x && $z) {
+                return 1;
+            }
+        }
+    }
+    return 0;
+}
+
+1;
@@ -0,0 +1,59 @@
+# Loop statements and loop control
+
+use strict;
+use warnings;
+
+sub sum {
+    my (@items) = @_;
+    my $total = 0;
+    foreach my $item (@items) {
+        $total += weight($item);
+    }
+    return $total;
+}
+
+sub count_up {
+    my ($n) = @_;
+    for (my $i = 0; $i < $n; $i++) {
+        tick($i);
+    }
+}
+
+sub first_negative {
+    my (@items) = @_;
+    for my $item (@items) {
+        if ($item < 0) {
+            return $item;
+        }
+    }
+    return;
+}
+
+sub drain {
+    my ($queue) = @_;
+    while (has_items($queue)) {
+        my $item = take($queue);
+        last if $item->{stop};
+        process($item);
+    }
+}
+
+sub countdown {
+    my ($n) = @_;
+    until ($n <= 0) {
+        $n--;
+    }
+    return $n;
+}
+
+sub grid {
+    my ($rows, $cols) = @_;
+    for my $r (1 .. $rows) {
+        for my $c (1 .. $cols) {
+            next if $r == $c;
+            plot($r, $c);
+        }
+    }
+}
+
+1;
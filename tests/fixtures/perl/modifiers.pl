@@ -0,0 +1,38 @@
+# Statement modifiers: each is a branch or a loop around one statement
+
+use strict;
+use warnings;
+
+sub check {
+    my ($x) = @_;
+    return 0 unless defined $x;
+    die "negative" if $x < 0;
+    step($_) for 1 .. $x;
+    $x-- while $x > 10;
+    return $x;
+}
+
+sub retry {
+    my ($task) = @_;
+    my $tries = 0;
+    $tries++ until attempt($task) || $tries >= 3;
+    return $tries;
+}
+
+sub collect {
+    my (@rows) = @_;
+    my @kept;
+    foreach my $row (@rows) {
+        next unless $row;
+        last if is_sentinel($row);
+        keep(\@kept, $row);
+    }
+    return @kept;
+}
+
+sub log_if_verbose {
+    my ($message) = @_;
+    write_log($message) if verbose();
+}
+
+1;
@@ -0,0 +1,62 @@
+# Straight-line code, branches, and early returns
+
+use strict;
+use warnings;
+
+sub simple {
+    my ($x) = @_;
+    my $y = $x + 1;
+    return $y;
+}
+
+sub single_branch {
+    my ($x) = @_;
+    if ($x > 0) {
+        $x = $x + 1;
+    }
+    return $x;
+}
+
+sub if_else {
+    my ($x) = @_;
+    if ($x > 0) {
+        return $x + 1;
+    } else {
+        return $x - 1;
+    }
+}
+
+sub sign {
+    my ($x) = @_;
+    if ($x > 0) {
+        return 1;
+    } elsif ($x < 0) {
+        return -1;
+    } else {
+        return 0;
+    }
+}
+
+sub describe {
+    my ($n) = @_;
+    my $label = "many";
+    if ($n == 0) {
+        $label = "none";
+    } elsif ($n == 1) {
+        $label = "one";
+    } elsif ($n < 5) {
+        $label = "few";
+    }
+    log_message($label);
+    return $label;
+}
+
+sub with_default {
+    my ($config) = @_;
+    unless ($config) {
+        $config = default_config();
+    }
+    return $config;
+}
+
+1;
@@ -0,0 +1,67 @@
+package Shapes::Registry;
+
+# Named, anonymous, and nested subs across packages
+
+use strict;
+use warnings;
+
+my $by_name = sub {
+    my ($x, $y) = @_;
+    return compare_names($x, $y);
+};
+
+sub new {
+    my ($class, %args) = @_;
+    my $self = { shapes => [], strict => $args{strict} // 0 };
+    return $class->build($self);
+}
+
+sub register {
+    my ($self, $shape) = @_;
+    die "shape required" unless $shape;
+    my $check = sub {
+        my ($s) = @_;
+        return is_valid($s) ? 1 : 0;
+    };
+    add_shape($self, $shape) if is_valid($shape);
+    return apply_check($check, $self);
+}
+
+sub total_area {
+    my ($self) = @_;
+    my $total = 0;
+    sub area_of {
+        my ($shape) = @_;
+        return $shape->{w} * $shape->{h} if $shape->{kind} eq "rect";
+        return 0;
+    }
+    for my $shape (shapes($self)) {
+        $total += area_of($shape);
+    }
+    return $total;
+}
+
+my %handlers = (
+    on_error => sub {
+        my ($err) = @_;
+        report_error($err) if $err;
+    },
+);
+
+sort_shapes(sub { compare_areas($_[0], $_[1]) });
+
+package Shapes::Util {
+    sub clamp {
+        my ($v, $lo, $hi) = @_;
+        return $lo if $v < $lo;
+        return $hi if $v > $hi;
+        return $v;
+    }
+}
+
+sub _reset {
+    my ($self) = @_;
+    clear_shapes($self);
+}
+
+1;
@@ -0,0 +1,69 @@
+<?php
+// for, foreach, while, and do-while loops
+
+function simpleLoop()
+{
+    for ($i = 0; $i < 10; $i++) {
+        echo $i;
+    }
+}
+
+function loopWithCondition()
+{
+    for ($i = 0; $i < 10; $i++) {
+        if ($i > 5) {
+            echo $i;
+        }
+    }
+}
+
+function nestedLoops()
+{
+    for ($i = 0; $i < 10; $i++) {
+        for ($j = 0; $j < 10; $j++) {
+            echo $i + $j;
+        }
+    }
+}
+
+function loopWithBreak()
+{
+    for ($i = 0; $i < 10; $i++) {
+        if ($i > 5) {
+            break;
+        }
+    }
+}
+
+function loopWithContinue()
+{
+    for ($i = 0; $i < 10; $i++) {
+        if ($i % 2 == 0) {
+            continue;
+        }
+        echo $i;
+    }
+}
+
+function foreachLoop(array $items)
+{
+    foreach ($items as $item) {
+        echo $item;
+    }
+}
+
+function whileLoop()
+{
+    $i = 0;
+    while ($i < 10) {
+        $i++;
+    }
+}
+
+function doWhileLoop()
+{
+    $i = 0;
+    do {
+        $i++;
+    } while ($i < 10);
+}
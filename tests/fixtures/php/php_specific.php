@@ -0,0 +1,71 @@
+<?php
+// Classes, exceptions, word operators, null-coalescing, closures, arrow
+// functions, and a template that mixes HTML with PHP
+
+namespace App\Billing;
+
+use App\Models\Invoice;
+
+final class InvoiceService
+{
+    public function __construct(private Repository $repository)
+    {
+    }
+
+    public function total(array $items): float
+    {
+        $sum = 0.0;
+        foreach ($items as $item) {
+            $sum += $item->price ?? 0.0;
+        }
+        return $sum;
+    }
+
+    public function load(int $id): ?Invoice
+    {
+        try {
+            return $this->repository->find($id);
+        } catch (NotFoundException $e) {
+            return null;
+        } catch (\PDOException $e) {
+            throw new ServiceException("lookup failed", 0, $e);
+        }
+    }
+
+    private function label(?Invoice $invoice): string
+    {
+        return $invoice?->paid ? "paid" : "open";
+    }
+
+    public static function isOverdue(Invoice $invoice, int $now): bool
+    {
+        return $invoice->due < $now and !$invoice->paid or $invoice->disputed;
+    }
+}
+
+function validate($value)
+{
+    if (!is_numeric($value)) {
+        throw new \InvalidArgumentException("not a number");
+    }
+    return (int) $value;
+}
+
+$double = fn($x) => $x * 2;
+
+$withinLimit = function (array $xs) use ($limit) {
+    return count($xs) > 0 ? max($xs) <= $limit : false;
+};
+?>
+<ul>
+<?php
+function renderItems(array $items)
+{
+    foreach ($items as $item) {
+        ?>
+        <li><?= htmlspecialchars($item) ?></li>
+        <?php
+    }
+}
+?>
+</ul>
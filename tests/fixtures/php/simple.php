@@ -0,0 +1,56 @@
+<?php
+// Straight-line code and simple branches
+
+function simple()
+{
+    $x = 1;
+    echo $x;
+}
+
+function singleBranch($x)
+{
+    $y = $x;
+    if ($y > 0) {
+        $y += 1;
+    }
+    return $y;
+}
+
+function ifElse($x)
+{
+    if ($x > 0) {
+        return $x + 1;
+    } else {
+        return $x - 1;
+    }
+}
+
+function earlyReturn($x)
+{
+    if ($x < 0) {
+        return -1;
+    }
+    return $x * 2;
+}
+
+function multipleReturns($x)
+{
+    if ($x < 0) {
+        return -1;
+    }
+    if ($x == 0) {
+        return 0;
+    }
+    return $x * 2;
+}
+
+function classify($x)
+{
+    if ($x < 0) {
+        return "negative";
+    } elseif ($x == 0) {
+        return "zero";
+    } else {
+        return "positive";
+    }
+}
@@ -0,0 +1,81 @@
+<?php
+// switch cases fall through without a break; match arms never do
+
+function simpleSwitch($x)
+{
+    switch ($x) {
+        case 1:
+            return "one";
+        case 2:
+            return "two";
+        default:
+            return "other";
+    }
+}
+
+function switchNoDefault($x)
+{
+    switch ($x) {
+        case 1:
+            echo "one";
+            break;
+        case 2:
+            echo "two";
+            break;
+    }
+}
+
+function switchFallthrough($x)
+{
+    switch ($x) {
+        case 1:
+            echo "one";
+        case 2:
+            echo "one or two";
+            break;
+        default:
+            echo "other";
+            break;
+    }
+}
+
+function nestedSwitch($x, $y)
+{
+    switch ($x) {
+        case 1:
+            switch ($y) {
+                case 1:
+                    echo "1,1";
+                    break;
+                default:
+                    echo "1,other";
+                    break;
+            }
+            break;
+        default:
+            echo "other";
+            break;
+    }
+}
+
+function switchMultipleValues($x)
+{
+    switch ($x) {
+        case 1:
+        case 2:
+            return "low";
+        case 3:
+            return "mid";
+        default:
+            return "other";
+    }
+}
+
+function matchStatus($code)
+{
+    return match ($code) {
+        200, 201 => "ok",
+        404 => "missing",
+        default => "error",
+    };
+}
@@ -0,0 +1,32 @@
+# Guard clauses vs. nesting: both functions do the same work with CC 6.
+
+
+def save_guarded(user, items):
+    if user is None:
+        return -1
+    if not user.active:
+        return -2
+    if not items:
+        raise ValueError("nothing to save")
+    saved = 0
+    for item in items:
+        if item.skip:
+            continue
+        saved += store(user, item)
+    return saved
+
+
+def save_nested(user, items):
+    saved = -1
+    if user is not None:
+        if user.active:
+            if items:
+                saved = 0
+                for item in items:
+                    if not item.skip:
+                        saved += store(user, item)
+            else:
+                raise ValueError("nothing to save")
+        else:
+            saved = -2
+    return saved
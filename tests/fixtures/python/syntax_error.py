@@ -0,0 +1,19 @@
+"""Syntax errors: functions containing one are skipped, the rest analyzed"""
+
+
+# Expected: cc=2
+def clean(x):
+    if x > 0:
+        return x
+    return -x
+
+
+# The assignment is missing its right operand: skipped
+def broken(x):
+    total = x +
+    return total
+
+
+# Expected: cc=1
+def after_broken(items):
+    return len(items)
@@ -0,0 +1,32 @@
+# Public API partition for --public-only:
+# public:  __init__, add, checkout
+# private: _recalculate, get (private class), _log (nested), _helper
+
+
+class Cart:
+    def __init__(self):
+        self.items = []
+
+    def add(self, item):
+        self.items.append(item)
+        self._recalculate()
+
+    def _recalculate(self):
+        return len(self.items)
+
+
+class _Cache:
+    def get(self, key):
+        return key
+
+
+def checkout(cart):
+    def _log(message):
+        print(message)
+
+    _log("checkout")
+    return cart.items
+
+
+def _helper():
+    return None
@@ -0,0 +1,18 @@
+// Fixture: mutual recursion across two files (isEven <-> isOdd in odd.ts)
+// and direct recursion (factorial)
+import { isOdd } from "./odd";
+
+export function isEven(n: number): boolean {
+  if (n === 0) {
+    return true;
+  }
+  return isOdd(n - 1);
+}
+
+export function factorial(n: number): number {
+  return n <= 1 ? 1 : n * factorial(n - 1);
+}
+
+export function parity(n: number): string {
+  return isEven(n) ? "even" : "odd";
+}
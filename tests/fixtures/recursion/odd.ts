@@ -0,0 +1,9 @@
+// Fixture: the other half of the isEven <-> isOdd cycle in even.ts
+import { isEven } from "./even";
+
+export function isOdd(n: number): boolean {
+  if (n === 0) {
+    return false;
+  }
+  return isEven(n - 1);
+}
@@ -0,0 +1,69 @@
+// Rust attributes and macros: metrics come from the written body only.
+// `Attributed` and `Plain` have identical method bodies; only the attributes
+// and doc comments differ, so every metric must match pairwise.
+
+#[derive(Debug, Clone, PartialEq, Eq, Hash, Default)]
+#[cfg_attr(feature = "serde", derive(serde::Serialize, serde::Deserialize))]
+#[allow(dead_code)]
+pub struct Attributed {
+    value: i32,
+    items: Vec<i32>,
+}
+
+pub struct Plain {
+    value: i32,
+    items: Vec<i32>,
+}
+
+impl Attributed {
+    /// Creates a new value.
+    ///
+    /// Doc comments are attributes to the parser and must not count as LOC.
+    #[must_use]
+    #[inline]
+    pub fn new(value: i32) -> Self {
+        Self { value, items: Vec::new() }
+    }
+
+    #[cfg_attr(feature = "tracing", tracing::instrument(skip(self), fields(len = self.items.len())))]
+    #[allow(clippy::needless_range_loop, clippy::cognitive_complexity)]
+    #[deprecated(since = "1.0.0", note = "use `total` instead")]
+    pub fn sum_positive(&self) -> i32 {
+        let mut total = 0;
+        for item in &self.items {
+            if *item > 0 {
+                total += item;
+            }
+        }
+        total
+    }
+
+    /// Macro arguments are opaque token streams: the `&&` inside
+    /// `debug_assert!` and the patterns in `matches!` add no complexity.
+    #[inline(always)]
+    pub fn check(&self, flag: bool) -> bool {
+        debug_assert!(self.value >= 0 && self.items.len() < 100);
+        matches!(self.value, 1 | 2 | 3) || flag
+    }
+}
+
+impl Plain {
+    pub fn new(value: i32) -> Self {
+        Self { value, items: Vec::new() }
+    }
+
+    pub fn sum_positive(&self) -> i32 {
+        let mut total = 0;
+        for item in &self.items {
+            if *item > 0 {
+                total += item;
+            }
+        }
+        total
+    }
+
+    pub fn check(&self, flag: bool) -> bool {
+        debug_assert!(self.value >= 0 && self.items.len() < 100);
+        matches!(self.value, 1 | 2 | 3) || flag
+    }
+}
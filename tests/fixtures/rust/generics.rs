@@ -0,0 +1,27 @@
+// Rust signature complexity: type parameters plus type nesting depth.
+// Bodies are trivial so only the signatures differ.
+
+use std::collections::HashMap;
+
+fn plain(x: i32, name: &str) -> bool {
+    x > 0 && !name.is_empty()
+}
+
+fn single_generic(items: Vec<i32>) -> usize {
+    items.len()
+}
+
+// 3 type parameters; the return type nests 5 levels deep:
+// Result<_> → HashMap<_> → Vec<_> → Option<_> → Box<_>
+fn load_index<K, V, E>(
+    keys: &[K],
+) -> Result<HashMap<K, Vec<Option<Box<V>>>>, E>
+where
+    K: std::hash::Hash + Eq + Clone,
+{
+    let mut index = HashMap::new();
+    for key in keys {
+        index.insert(key.clone(), Vec::new());
+    }
+    Ok(index)
+}
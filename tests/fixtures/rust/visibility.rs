@@ -0,0 +1,41 @@
+// Public API partition for --public-only:
+// public:  Ledger::new, Ledger::balance, Ledger::default (trait impl), open
+// private: Ledger::compact, Ledger::reset (pub(crate)), checksum
+
+pub struct Ledger {
+    entries: Vec<i64>,
+}
+
+impl Ledger {
+    pub fn new() -> Self {
+        Ledger {
+            entries: Vec::new(),
+        }
+    }
+
+    pub fn balance(&self) -> i64 {
+        self.entries.iter().sum()
+    }
+
+    fn compact(&mut self) {
+        self.entries.retain(|e| *e != 0);
+    }
+
+    pub(crate) fn reset(&mut self) {
+        self.entries.clear();
+    }
+}
+
+impl Default for Ledger {
+    fn default() -> Self {
+        Self::new()
+    }
+}
+
+fn checksum(entries: &[i64]) -> i64 {
+    entries.iter().fold(0, |acc, e| acc ^ e)
+}
+
+pub fn open() -> Ledger {
+    Ledger::new()
+}
@@ -0,0 +1,56 @@
+// Loops and for-comprehensions; `if` guards on generators add a branch
+
+object Loops {
+  def simpleLoop(items: List[Int]): Unit = {
+    for (item <- items) {
+      println(item)
+    }
+  }
+
+  def loopWithGuard(items: List[Int]): Int = {
+    var total = 0
+    for (item <- items if item > 0) {
+      total += item
+    }
+    total
+  }
+
+  def nestedLoops(rows: Int, cols: Int): Unit = {
+    for (i <- 0 until rows) {
+      for (j <- 0 until cols) {
+        println(i * j)
+      }
+    }
+  }
+
+  def whileLoop(n: Int): Int = {
+    var i = 0
+    while (i < n) {
+      i += 1
+    }
+    i
+  }
+
+  def doWhileLoop(n: Int): Int = {
+    var i = 0
+    do {
+      i += 1
+    } while (i < n)
+    i
+  }
+
+  def comprehension(xs: List[Int], ys: List[Int]): List[Int] =
+    for {
+      x <- xs
+      if x % 2 == 0
+      y <- ys
+      if y > x
+    } yield x + y
+
+  // Scala 3 indentation syntax
+  def countdown(n: Int): Unit =
+    var i = n
+    while i > 0 do
+      println(i)
+      i -= 1
+}
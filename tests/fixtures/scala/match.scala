@@ -0,0 +1,45 @@
+// Pattern matching: every case clause adds a branch, and so does each guard
+
+sealed trait Shape
+case class Circle(r: Double) extends Shape
+case class Rect(w: Double, h: Double) extends Shape
+case class Triangle(a: Double, b: Double, c: Double) extends Shape
+
+object Shapes {
+  def describe(x: Int): String = x match {
+    case 0 => "zero"
+    case 1 => "one"
+    case _ => "many"
+  }
+
+  def area(shape: Shape): Double = shape match {
+    case Circle(r) => math.Pi * r * r
+    case Rect(w, h) => w * h
+    case Triangle(a, b, c) =>
+      val s = (a + b + c) / 2
+      math.sqrt(s * (s - a) * (s - b) * (s - c))
+  }
+
+  def classifyWithGuards(n: Int): String = n match {
+    case 0 => "zero"
+    case x if x < 0 => "negative"
+    case x if x % 2 == 0 => "even"
+    case _ => "odd"
+  }
+
+  def nestedMatch(a: Option[Int], b: Option[Int]): Int = a match {
+    case Some(x) =>
+      b match {
+        case Some(y) => x + y
+        case None => x
+      }
+    case None => 0
+  }
+
+  // Scala 3 indentation syntax
+  def parse(input: String): Either[String, Int] =
+    input.toIntOption match
+      case Some(n) if n >= 0 => Right(n)
+      case Some(n) => Left(s"negative: $n")
+      case None => Left("not a number")
+}
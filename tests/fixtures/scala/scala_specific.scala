@@ -0,0 +1,59 @@
+// Scala-specific constructs: bound lambdas, catch cases, early returns from
+// loops, and Scala 3 syntax
+
+package demo
+
+import scala.util.control.NonFatal
+
+class Account(private var balance: Int) {
+  def deposit(amount: Int): Unit = {
+    require(amount > 0, "amount must be positive")
+    balance += amount
+  }
+
+  val fee = (amount: Int) => if (amount > 1000) amount / 100 else 0
+
+  def withdraw(amount: Int): Either[String, Int] =
+    if amount <= 0 then Left("invalid amount")
+    else if amount > balance && balance >= 0 then Left("insufficient funds")
+    else
+      balance -= amount
+      Right(balance)
+}
+
+object Loader {
+  def load(path: String): Option[String] = {
+    try {
+      Some(read(path))
+    } catch {
+      case _: java.io.FileNotFoundException => None
+      case NonFatal(e) =>
+        log(e.getMessage)
+        throw e
+    } finally {
+      close(path)
+    }
+  }
+
+  private def read(path: String): String = scala.io.Source.fromFile(path).mkString
+
+  def firstPositive(xs: List[Int]): Option[Int] = {
+    for (x <- xs) {
+      if (x > 0) return Some(x)
+    }
+    None
+  }
+
+  val shout: String => String = s => s.toUpperCase
+
+  // The lambda passed to `filter` is part of `total`
+  def total(xs: List[Int]): Int =
+    xs.filter(x => x > 0 || x == -1).sum
+}
+
+trait Greeter:
+  def name: String
+
+  def greet(loud: Boolean): String =
+    if loud then s"HELLO, ${name.toUpperCase}!"
+    else s"Hello, $name"
@@ -0,0 +1,34 @@
+// Straight-line code and branches, in brace style
+
+object Simple {
+  def simple(x: Int): Int = x + 1
+
+  def singleBranch(x: Int): Int = {
+    if (x > 0) {
+      return 1
+    }
+    0
+  }
+
+  def ifElse(x: Int): String =
+    if (x > 0) "positive" else "non-positive"
+
+  def classify(x: Int): String = {
+    if (x > 0) {
+      "positive"
+    } else if (x < 0) {
+      "negative"
+    } else {
+      "zero"
+    }
+  }
+
+  def bothPositive(a: Int, b: Int): Boolean = a > 0 && b > 0
+
+  def validate(name: String): String = {
+    if (name.isEmpty) {
+      throw new IllegalArgumentException("empty name")
+    }
+    name.trim
+  }
+}
@@ -0,0 +1,41 @@
+-- Order pricing routines (PL/pgSQL)
+
+CREATE OR REPLACE FUNCTION billing.apply_discount(p_order_id integer)
+RETURNS numeric
+LANGUAGE plpgsql
+AS $$
+DECLARE
+    v_total numeric := 0;
+    v_line record;
+BEGIN
+    FOR v_line IN
+        SELECT quantity, unit_price, category
+        FROM order_lines
+        WHERE order_id = p_order_id AND quantity > 0
+    LOOP
+        v_total := v_total + CASE
+            WHEN v_line.category = 'bulk' THEN v_line.quantity * v_line.unit_price * 0.9
+            WHEN v_line.category = 'clearance' OR v_line.quantity > 100
+                THEN v_line.quantity * v_line.unit_price * 0.5
+            ELSE v_line.quantity * v_line.unit_price
+        END;
+    END LOOP;
+
+    IF v_total > 1000 THEN
+        v_total := v_total - 50;
+    ELSIF v_total BETWEEN 500 AND 1000 THEN
+        v_total := v_total - 20;
+    END IF;
+
+    RETURN round(v_total, 2);
+EXCEPTION
+    WHEN division_by_zero THEN
+        RETURN 0;
+END;
+$$;
+
+CREATE FUNCTION billing.order_count() RETURNS bigint AS $$
+BEGIN
+    RETURN (SELECT count(*) FROM orders);
+END;
+$$ LANGUAGE plpgsql;
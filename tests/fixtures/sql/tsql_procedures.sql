@@ -0,0 +1,27 @@
+-- Queue processing (T-SQL)
+
+CREATE PROCEDURE dbo.ProcessQueue
+    @BatchSize INT = 100
+AS
+BEGIN
+    SET NOCOUNT ON;
+    DECLARE @Processed INT = 0;
+
+    WHILE @Processed < @BatchSize
+    BEGIN
+        IF EXISTS (SELECT 1 FROM dbo.Queue WHERE Status = 'pending')
+        BEGIN
+            BEGIN TRY
+                EXEC dbo.ProcessNext;
+                SET @Processed = @Processed + 1;
+            END TRY
+            BEGIN CATCH
+                EXEC dbo.LogError @Message = 'process failed';
+                BREAK;
+            END CATCH
+        END
+        ELSE
+            RETURN;
+    END
+END
+GO
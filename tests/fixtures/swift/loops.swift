@@ -0,0 +1,54 @@
+// for-in, while, and repeat-while loops
+
+func simpleLoop() {
+    for i in 0..<10 {
+        print(i)
+    }
+}
+
+func loopWithCondition() {
+    for i in 0..<10 {
+        if i > 5 {
+            print(i)
+        }
+    }
+}
+
+func nestedLoops() {
+    for i in 0..<10 {
+        for j in 0..<10 {
+            print(i + j)
+        }
+    }
+}
+
+func loopWithBreak() {
+    for i in 0..<10 {
+        if i > 5 {
+            break
+        }
+    }
+}
+
+func loopWithContinue() {
+    for i in 0..<10 {
+        if i % 2 == 0 {
+            continue
+        }
+        print(i)
+    }
+}
+
+func whileLoop() {
+    var i = 0
+    while i < 10 {
+        i += 1
+    }
+}
+
+func repeatLoop() {
+    var i = 0
+    repeat {
+        i += 1
+    } while i < 10
+}
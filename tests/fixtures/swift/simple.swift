@@ -0,0 +1,46 @@
+// Straight-line code and simple branches
+
+func simple() {
+    let x = 1
+    print(x)
+}
+
+func singleBranch(x: Int) -> Int {
+    var y = x
+    if y > 0 {
+        y += 1
+    }
+    return y
+}
+
+func ifElse(x: Int) -> Int {
+    if x > 0 {
+        return x + 1
+    } else {
+        return x - 1
+    }
+}
+
+func earlyReturn(x: Int) -> Int {
+    if x < 0 {
+        return -1
+    }
+    return x * 2
+}
+
+func multipleReturns(x: Int) -> Int {
+    if x < 0 {
+        return -1
+    }
+    if x == 0 {
+        return 0
+    }
+    return x * 2
+}
+
+func guardReturn(x: Int?) -> Int {
+    guard let value = x else {
+        return 0
+    }
+    return value * 2
+}
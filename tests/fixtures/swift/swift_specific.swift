@@ -0,0 +1,80 @@
+// Computed properties, initializers, extensions, guard, do/catch, closures,
+// and labeled statements
+
+struct Rect {
+    var width: Double
+    var height: Double
+
+    var area: Double {
+        width * height
+    }
+
+    var isSquare: Bool {
+        get {
+            return width == height
+        }
+        set {
+            if newValue {
+                height = width
+            }
+        }
+    }
+}
+
+extension Rect {
+    func scaled(by factor: Double) -> Rect {
+        guard factor > 0 else {
+            return self
+        }
+        return Rect(width: width * factor, height: height * factor)
+    }
+}
+
+final class Counter {
+    private var count: Int
+
+    init(start: Int) {
+        count = max(start, 0)
+    }
+
+    func increment(times: Int) {
+        var remaining = times
+        repeat {
+            count += 1
+            remaining -= 1
+        } while remaining > 0
+    }
+}
+
+enum LoadError: Error {
+    case missing
+}
+
+func load(path: String) -> String {
+    do {
+        return try read(path)
+    } catch LoadError.missing {
+        return ""
+    } catch {
+        fatalError("unreadable: \(path)")
+    }
+}
+
+func describe(values: [Int]) -> [String] {
+    return values.map { value in
+        value > 0 ? "positive" : "non-positive"
+    }
+}
+
+func contains(grid: [[Int]], target: Int) -> Bool {
+    var found = false
+    outer: for row in grid {
+        for value in row {
+            if value == target {
+                found = true
+                break outer
+            }
+        }
+    }
+    return found
+}
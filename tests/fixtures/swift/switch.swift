@@ -0,0 +1,48 @@
+// Swift cases do not fall through, and a switch must be exhaustive
+
+func simpleSwitch(x: Int) -> String {
+    switch x {
+    case 1:
+        return "one"
+    case 2:
+        return "two"
+    default:
+        return "other"
+    }
+}
+
+func nestedSwitch(x: Int, y: Int) {
+    switch x {
+    case 1:
+        switch y {
+        case 1:
+            print("1,1")
+        default:
+            print("1,other")
+        }
+    default:
+        print("other")
+    }
+}
+
+func whereSwitch(x: Int) -> String {
+    switch x {
+    case let n where n < 0:
+        return "negative"
+    case 0:
+        return "zero"
+    default:
+        return "positive"
+    }
+}
+
+func switchMultipleValues(x: Int) -> String {
+    switch x {
+    case 1, 2, 3:
+        return "low"
+    case 4, 5, 6:
+        return "high"
+    default:
+        return "other"
+    }
+}
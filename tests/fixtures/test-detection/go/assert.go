@@ -0,0 +1,11 @@
+package parser
+
+import "testing"
+
+// AssertFields is a helper for other packages' tests. It takes a *testing.T
+// but is not itself a test.
+func AssertFields(t *testing.T, got, want []string) {
+	if len(got) != len(want) {
+		t.Fatalf("got %d fields, want %d", len(got), len(want))
+	}
+}
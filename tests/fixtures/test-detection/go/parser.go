@@ -0,0 +1,20 @@
+package parser
+
+import "strings"
+
+// Parse splits a comma-separated list, skipping empty fields.
+func Parse(input string) []string {
+	var fields []string
+	for _, field := range strings.Split(input, ",") {
+		if field != "" {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// TestdataDir is production code despite its name: "Test" is followed by a
+// lowercase letter.
+func TestdataDir() string {
+	return "testdata"
+}
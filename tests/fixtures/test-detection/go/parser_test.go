@@ -0,0 +1,20 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	AssertFields(t, Parse("a,,b"), []string{"a", "b"})
+}
+
+func BenchmarkParse(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		Parse("a,b,c")
+	}
+}
+
+func newInput(n int) string {
+	return strings.Repeat("a,", n)
+}
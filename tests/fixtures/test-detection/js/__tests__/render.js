@@ -0,0 +1,3 @@
+export function renderFixture() {
+  return "a,b";
+}
@@ -0,0 +1,9 @@
+import { mockReader } from "./__mocks__/reader";
+
+export function parse(text) {
+  return text.split(",").filter((field) => field !== "");
+}
+
+export function parseMocked() {
+  return parse(mockReader());
+}
@@ -0,0 +1,5 @@
+import { parse } from "./parser";
+
+export function expectEmpty(text: string): void {
+  expect(parse(text)).toEqual([]);
+}
@@ -0,0 +1,5 @@
+import { parse } from "./parser";
+
+test("parses", function parsesFields() {
+  expect(parse("a,,b")).toEqual(["a", "b"]);
+});
@@ -0,0 +1,10 @@
+from tests.helpers import make_fixture
+
+
+def parse(text):
+    return [field for field in text.split(",") if field]
+
+
+def test_fixture():
+    # Named like a test, but this is not a test file
+    return parse(make_fixture())
@@ -0,0 +1,5 @@
+from parser import parse
+
+
+def test_parse_empty():
+    assert parse("") == []
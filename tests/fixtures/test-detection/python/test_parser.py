@@ -0,0 +1,5 @@
+from parser import parse
+
+
+def test_parse():
+    assert parse("a,,b") == ["a", "b"]
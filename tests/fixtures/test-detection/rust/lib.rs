@@ -0,0 +1,35 @@
+/// Split a comma-separated list, skipping empty fields
+pub fn parse(input: &str) -> Vec<&str> {
+    input.split(',').filter(|f| !f.is_empty()).collect()
+}
+
+#[cfg(not(test))]
+fn clock() -> u64 {
+    42
+}
+
+#[cfg(test)]
+fn fixture() -> &'static str {
+    "a,,b"
+}
+
+#[test]
+fn parses_fixture() {
+    assert_eq!(parse(fixture()), vec!["a", "b"]);
+}
+
+/// Runs on the async runtime
+#[tokio::test]
+async fn parses_async() {
+    assert!(parse("").is_empty());
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn parses_empty() {
+        assert!(parse("").is_empty());
+    }
+}
@@ -0,0 +1,36 @@
+// Component rollup: ProductList's complexity is the sum of its own CC and its
+// callbacks' (handleSelect and the filter, map, and onClick arrows).
+// formatPrice sits outside the component and is not rolled up.
+import { useState } from "react";
+
+function formatPrice(cents: number): string {
+  return cents < 0 ? "-" : `$${(cents / 100).toFixed(2)}`;
+}
+
+export function ProductList(props: { items: Array<{ id: number; price: number; visible: boolean }> }) {
+  const [selected, setSelected] = useState<number | null>(null);
+
+  const handleSelect = (id: number) => {
+    if (id === selected) {
+      setSelected(null);
+    } else if (id > 0) {
+      setSelected(id);
+    }
+  };
+
+  if (props.items.length === 0) {
+    return <p>No products</p>;
+  }
+
+  return (
+    <ul>
+      {props.items
+        .filter((item) => item.visible && item.price > 0)
+        .map((item) => (
+          <li key={item.id} onClick={() => handleSelect(item.id)}>
+            {formatPrice(item.price)}
+          </li>
+        ))}
+    </ul>
+  );
+}
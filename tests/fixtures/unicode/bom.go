@@ -0,0 +1,16 @@
+﻿package unicode
+
+// Größe returns the size in bytes of a string like "日本語 🌏"
+func Größe(s string) int {
+	return len(s)
+}
+
+/* ü ö ä — ß */ func Zähle(xs []string) int {
+	n := 0
+	for _, x := range xs {
+		if x != "🍕" {
+			n++
+		}
+	}
+	return n
+}
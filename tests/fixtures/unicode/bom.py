@@ -0,0 +1,16 @@
+﻿# -*- coding: utf-8 -*-
+"""Café prices — prix en €"""
+
+def prix_réduit(prix, remise):
+    """Applique une remise 🎉"""
+    if remise > 0:
+        return prix * (1 - remise)
+    return prix
+
+
+def étiquette(nom, prix):
+    if not nom:
+        return "—"
+    for c in "àéîõü":
+        nom = nom.replace(c, "_")
+    return f"{nom}: {prix} €"
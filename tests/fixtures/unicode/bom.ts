@@ -0,0 +1,20 @@
+﻿// Emoji and accented text before and inside functions: 🚀 ✨ 日本語
+
+const greeting = "こんにちは 👋";
+
+export function naïve(input: string): string {
+  if (input.length === 0) {
+    return "∅";
+  }
+  return `${greeting} ${input} 🎉`;
+}
+
+export function résumé(items: string[]): number {
+  let count = 0;
+  for (const item of items) {
+    if (item.startsWith("✓")) {
+      count++;
+    }
+  }
+  return count;
+}
@@ -0,0 +1,30 @@
+// Public API partition for --public-only:
+// public:  createOrder, cancelOrder, refund, submit
+// private: total (nested), validate, audit (private method)
+
+export function createOrder(items: string[]): number {
+  const total = (): number => items.length;
+  return total();
+}
+
+function validate(items: string[]): boolean {
+  return items.length > 0;
+}
+
+export const cancelOrder = (id: number): boolean => id > 0;
+
+function refund(id: number): number {
+  return id;
+}
+
+export class OrderService {
+  submit(id: number): boolean {
+    return validate([String(id)]);
+  }
+
+  private audit(id: number): void {
+    console.log(id);
+  }
+}
+
+export { refund };
@@ -0,0 +1,48 @@
+// `and`, `or`, and `orelse` (mirrors go/boolean_ops.go)
+
+fn log(message: []const u8) void {
+    _ = message;
+}
+
+fn withAnd(x: i32, y: i32) void {
+    if (x > 0 and y > 0) {
+        log("both positive");
+    }
+}
+
+fn withOr(x: i32, y: i32) void {
+    if (x > 0 or y > 0) {
+        log("at least one positive");
+    }
+}
+
+fn multipleBooleanOps(x: i32, y: i32, z: i32) i32 {
+    if (x > 0 and y > 0 and z > 0 or x < 0) {
+        return 1;
+    }
+    return 0;
+}
+
+fn complexBooleanExpression(a: i32, b: i32, c: i32, d: i32) bool {
+    if ((a > 0 and b > 0) or (c > 0 and d > 0)) {
+        return true;
+    }
+    return false;
+}
+
+fn nestedWithBooleanOps(x: i32, y: i32, z: i32) i32 {
+    if (x > 0) {
+        if (y > 0 and z > 0) {
+            if (x > 10 or y > 10) {
+                return 1;
+            }
+        }
+    }
+    return 0;
+}
+
+// Each `orelse` is a branch on null
+fn withOrelse(maybe: ?i32, fallback: ?i32) i32 {
+    const value = maybe orelse fallback orelse return 0;
+    return value;
+}
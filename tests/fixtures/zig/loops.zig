@@ -0,0 +1,78 @@
+// `for` and `while` loops (mirrors go/loops.go)
+
+fn simpleLoop() void {
+    var i: usize = 0;
+    while (i < 10) : (i += 1) {
+        _ = i;
+    }
+}
+
+fn loopWithCondition() void {
+    var i: usize = 0;
+    while (i < 10) : (i += 1) {
+        if (i > 5) {
+            _ = i;
+        }
+    }
+}
+
+fn nestedLoops(rows: []const []const u8) usize {
+    var total: usize = 0;
+    for (rows) |row| {
+        for (row) |c| {
+            total += c;
+        }
+    }
+    return total;
+}
+
+fn loopWithBreak(items: []const i32) void {
+    for (items) |item| {
+        if (item > 5) {
+            break;
+        }
+    }
+}
+
+fn loopWithContinue(items: []const i32) i32 {
+    var sum: i32 = 0;
+    for (items) |item| {
+        if (@mod(item, 2) == 0) continue;
+        sum += item;
+    }
+    return sum;
+}
+
+fn indexedLoop(items: []const i32) void {
+    for (items, 0..) |item, i| {
+        _ = item;
+        _ = i;
+    }
+}
+
+fn infiniteLoop() void {
+    var i: usize = 0;
+    while (true) {
+        if (i > 10) break;
+        i += 1;
+    }
+}
+
+// The `else` branch runs when the loop ends without `break`
+fn contains(items: []const i32, needle: i32) bool {
+    for (items) |item| {
+        if (item == needle) break;
+    } else {
+        return false;
+    }
+    return true;
+}
+
+// Unrolled at compile time, but still a loop to read
+fn totalLength(comptime names: []const []const u8) usize {
+    var n: usize = 0;
+    inline for (names) |name| {
+        n += name.len;
+    }
+    return n;
+}
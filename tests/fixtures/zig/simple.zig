@@ -0,0 +1,46 @@
+// Straight-line code, branches, and early returns (mirrors go/simple.go)
+
+const std = @import("std");
+
+fn simple() i32 {
+    const x: i32 = 1;
+    return x;
+}
+
+fn singleBranch(x: i32) i32 {
+    var y = x;
+    if (y > 0) {
+        y += 1;
+    }
+    return y;
+}
+
+fn ifElse(x: i32) i32 {
+    if (x > 0) {
+        return x + 1;
+    } else {
+        return x - 1;
+    }
+}
+
+fn earlyReturn(x: i32) i32 {
+    if (x < 0) return -1;
+    return x * 2;
+}
+
+fn sign(x: i32) i32 {
+    if (x > 0) {
+        return 1;
+    } else if (x < 0) {
+        return -1;
+    } else {
+        return 0;
+    }
+}
+
+// `if` used as a value is part of the statement
+fn describe(n: u32) []const u8 {
+    const label = if (n == 0) "none" else if (n == 1) "one" else "many";
+    std.debug.print("{s}\n", .{label});
+    return label;
+}
@@ -0,0 +1,59 @@
+// `switch` statements and expressions (mirrors go/switch.go)
+
+const Color = enum { red, green, blue };
+
+const Shape = union(enum) {
+    circle: f32,
+    rect: struct { w: f32, h: f32 },
+
+    // A `switch` on a tagged union, capturing each payload
+    fn area(self: Shape) f32 {
+        return switch (self) {
+            .circle => |r| 3.14 * r * r,
+            .rect => |s| s.w * s.h,
+        };
+    }
+};
+
+fn log(message: []const u8) void {
+    _ = message;
+}
+
+fn simpleSwitch(x: i32) []const u8 {
+    switch (x) {
+        1 => return "one",
+        2 => return "two",
+        else => return "other",
+    }
+}
+
+// Exhaustive over the enum: every prong is a branch
+fn switchNoElse(c: Color) void {
+    switch (c) {
+        .red => log("red"),
+        .green => log("green"),
+        .blue => {},
+    }
+}
+
+fn nestedSwitch(x: i32, y: i32) void {
+    switch (x) {
+        1 => switch (y) {
+            1 => log("1,1"),
+            2 => log("1,2"),
+            else => {},
+        },
+        2 => log("2"),
+        else => {},
+    }
+}
+
+// Ranges and lists of values are one prong each
+fn classify(c: u8) []const u8 {
+    return switch (c) {
+        '0'...'9' => "digit",
+        'a'...'z', 'A'...'Z' => "letter",
+        ' ', '\t', '\n' => "space",
+        else => "other",
+    };
+}
@@ -0,0 +1,91 @@
+// Error handling, labels, `comptime`, containers, and generic types
+
+const std = @import("std");
+
+pub const Parser = struct {
+    input: []const u8,
+    pos: usize = 0,
+
+    pub fn next(self: *Parser) ?u8 {
+        if (self.pos >= self.input.len) return null;
+        defer self.pos += 1;
+        return self.input[self.pos];
+    }
+
+    // `catch` handles an error and `try` returns it
+    pub fn parseNumber(self: *Parser) !u32 {
+        var value: u32 = 0;
+        while (self.next()) |c| {
+            if (c == ' ') break;
+            const digit = std.fmt.charToDigit(c, 10) catch return error.InvalidDigit;
+            value = try std.math.mul(u32, value, 10);
+            value += digit;
+        }
+        return value;
+    }
+};
+
+pub fn sumUntil(rows: []const []const i32, limit: i32) i32 {
+    var total: i32 = 0;
+    outer: for (rows) |row| {
+        for (row) |x| {
+            if (total + x > limit) break :outer;
+            if (x < 0) continue :outer;
+            total += x;
+        }
+    }
+    return total;
+}
+
+// A labeled block used as a value is part of the statement
+fn firstEven(items: []const i32) ?i32 {
+    const found = blk: {
+        for (items) |x| {
+            if (@rem(x, 2) == 0) break :blk x;
+        }
+        break :blk null;
+    };
+    return found;
+}
+
+// The `comptime` block is measured with the rest of the body
+fn checkSize(comptime T: type) usize {
+    comptime {
+        if (@sizeOf(T) > 64) @compileError("type too large");
+    }
+    return @sizeOf(T);
+}
+
+fn fieldCount(comptime T: type) usize {
+    var count: usize = 0;
+    inline for (std.meta.fields(T)) |field| {
+        if (field.name.len > 0) count += 1;
+    }
+    return count;
+}
+
+// A generic type: its methods are named after the function
+pub fn List(comptime T: type) type {
+    return struct {
+        items: []T,
+        len: usize,
+
+        const Self = @This();
+
+        pub fn get(self: Self, i: usize) ?T {
+            return if (i < self.len) self.items[i] else null;
+        }
+    };
+}
+
+fn unwrapOrPanic(value: ?u32) u32 {
+    if (value) |v| return v;
+    @panic("no value");
+}
+
+extern fn write(fd: i32, buf: [*]const u8, len: usize) isize;
+
+test "parser reads a number" {
+    var p = Parser{ .input = "42" };
+    try std.testing.expectEqual(@as(u32, 42), try p.parseNumber());
+}